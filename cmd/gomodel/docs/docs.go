@@ -15,6 +15,161 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/api/v1/anomalies": {
+            "get": {
+                "description": "Usage anomalies detected since the gateway started, newest first. Answers 503 unless anomaly detection is enabled in config.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Usage anomalies",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only anomalies not acknowledged yet",
+                        "name": "unacknowledged",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/admin.AnomaliesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/anomalies/{id}/acknowledge": {
+            "post": {
+                "description": "Marks a usage anomaly as acknowledged. Acknowledging it again keeps the first acknowledgement time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Acknowledge a usage anomaly",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Anomaly ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/anomaly.Anomaly"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/audit/by-response-id/{id}": {
+            "get": {
+                "description": "Matches the response ID returned to the client (chat or Responses \"id\") and, for converted providers whose IDs the gateway synthesizes, the ID the provider issued.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Find the audit log entry that produced a response",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Response ID, e.g. chatcmpl-abc123",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auditlog.LogEntry"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
         "/admin/api/v1/audit/conversation": {
             "get": {
                 "produces": [
@@ -94,6 +249,12 @@ const docTemplate = `{
                         "name": "end_date",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Filter by requested model selector",
@@ -130,12 +291,30 @@ const docTemplate = `{
                         "name": "error_type",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Filter by client-facing or provider-issued response ID",
+                        "name": "response_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by exact gateway request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Filter by status code",
                         "name": "status_code",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "description": "Filter by the status code the provider returned",
+                        "name": "upstream_status_code",
+                        "in": "query"
+                    },
                     {
                         "type": "boolean",
                         "description": "Filter by stream mode (true/false)",
@@ -188,50 +367,27 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/cache/overview": {
-            "get": {
+        "/admin/api/v1/audit/re-encrypt": {
+            "post": {
+                "description": "Rewraps the data keys of up to limit audit log entries that were encrypted with an older master key so they use the active key. Call it again with the returned next cursor until done is true; entries whose key is no longer configured are listed as failures and skipped.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get cached-only usage overview",
+                "summary": "Re-encrypt audit log entries with the active key",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of days (default 30)",
-                        "name": "days",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Grouping interval: daily, weekly, monthly, yearly (default daily)",
-                        "name": "interval",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
+                        "description": "Cursor returned by the previous call",
+                        "name": "after",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (cache overview always uses cached mode)",
-                        "name": "cache_mode",
+                        "type": "integer",
+                        "description": "Entries to process (default 500, max 5000)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -239,7 +395,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/usage.CacheOverview"
+                            "$ref": "#/definitions/auditlog.ReEncryptResult"
                         }
                     },
                     "400": {
@@ -268,30 +424,43 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/models/categories": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+        "/admin/api/v1/audit/{id}": {
+            "delete": {
+                "description": "Removes the entry entirely. The removal is recorded in the redaction log with the acting admin key hash.",
                 "tags": [
                     "admin"
                 ],
-                "summary": "List model categories with counts",
+                "summary": "Permanently delete an audit log entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Audit log entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/providers.CategoryCount"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     }
                 },
                 "security": [
@@ -301,74 +470,127 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/usage/daily": {
-            "get": {
+        "/admin/api/v1/audit/{id}/redact": {
+            "post": {
+                "description": "Replaces the captured request and response bodies with a redaction marker and drops captured headers. Metadata is kept, and the redaction is recorded with the acting admin key hash.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get usage breakdown by period",
+                "summary": "Redact the bodies and headers of an audit log entry",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of days (default 30)",
-                        "name": "days",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
+                        "description": "Audit log entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auditlog.LogEntry"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Grouping interval: daily, weekly, monthly, yearly (default daily)",
-                        "name": "interval",
-                        "in": "query"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
-                        "in": "query"
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
-                        "name": "cache_mode",
-                        "in": "query"
+                        "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/admin/api/v1/cache": {
+            "get": {
+                "description": "Files in the local model cache directory, oldest first, with their sizes, ages and schema versions, and the directory's size and age bounds. Answers 503 when the model cache is kept in Redis.",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List local cache files",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/usage.DailyUsage"
-                            }
+                            "$ref": "#/definitions/gomodel_internal_cache_modelcache.CacheDirReport"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Removes every file in the local model cache directory. The model registry keeps serving from memory and writes the cache again on its next refresh. Answers 503 when the model cache is kept in Redis.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Purge local cache files",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/admin.ModelCachePurgeResponse"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     }
                 },
                 "security": [
@@ -378,7 +600,7 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/usage/log": {
+        "/admin/api/v1/cache/overview": {
             "get": {
                 "produces": [
                     "application/json"
@@ -386,7 +608,7 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get paginated usage log entries",
+                "summary": "Get cached-only usage overview",
                 "parameters": [
                     {
                         "type": "integer",
@@ -408,44 +630,32 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Filter by model name",
-                        "name": "model",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by provider name or provider type",
-                        "name": "provider",
+                        "description": "Grouping interval: daily, weekly, monthly, yearly (default daily)",
+                        "name": "interval",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
+                        "description": "First day of weekly buckets: monday or sunday (default monday)",
+                        "name": "week_start",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
-                        "name": "cache_mode",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search across model, provider, request_id, provider_id",
-                        "name": "search",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size (default 50, max 200)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Offset for pagination",
-                        "name": "offset",
+                        "description": "Cache mode filter: uncached, cached, all (cache overview always uses cached mode)",
+                        "name": "cache_mode",
                         "in": "query"
                     }
                 ],
@@ -453,7 +663,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/usage.UsageLogResult"
+                            "$ref": "#/definitions/usage.CacheOverview"
                         }
                     },
                     "400": {
@@ -467,6 +677,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     }
                 },
                 "security": [
@@ -476,55 +692,70 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/usage/models": {
+        "/admin/api/v1/chaos/rules": {
             "get": {
+                "description": "Active fault injection rules and kill switch state. Answers 503 unless fault injection is enabled in config.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get usage breakdown by model",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of days (default 30)",
-                        "name": "days",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
+                "summary": "Fault injection rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/admin.ChaosRulesResponse"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
-                        "in": "query"
-                    },
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Replaces the fault injection rules and/or flips the kill switch. Setting active to false stops every fault immediately, including in-flight streams.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replace fault injection rules",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
-                        "name": "cache_mode",
-                        "in": "query"
+                        "description": "Rules and kill switch",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.updateChaosRulesRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/usage.ModelUsage"
-                            }
+                            "$ref": "#/definitions/admin.ChaosRulesResponse"
                         }
                     },
                     "400": {
@@ -538,6 +769,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
                     }
                 },
                 "security": [
@@ -547,62 +784,31 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/usage/summary": {
+        "/admin/api/v1/config/effective": {
             "get": {
+                "description": "Returns the configuration after merging the GOMODEL_PROFILE profile over the base config.yaml and applying env vars, keyed like config.yaml. API keys, secrets, passwords in URLs and provider extra headers are masked. Providers discovered only from env vars are listed by /admin/api/v1/providers/status instead.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get usage summary",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of days (default 30)",
-                        "name": "days",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
-                        "name": "cache_mode",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Get the effective configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/usage.UsageSummary"
+                            "$ref": "#/definitions/admin.EffectiveConfigResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
@@ -615,65 +821,31 @@ const docTemplate = `{
                 ]
             }
         },
-        "/admin/api/v1/usage/user-paths": {
+        "/admin/api/v1/deferred": {
             "get": {
+                "description": "Number of stored deferred requests per status. Depth counts queued and running requests.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Get usage breakdown by user path",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of days (default 30)",
-                        "name": "days",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by tracked user path subtree",
-                        "name": "user_path",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
-                        "name": "cache_mode",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Deferred request queue depth",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/usage.UserPathUsage"
-                            }
+                            "$ref": "#/definitions/admin.DeferredQueueResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/core.GatewayError"
                         }
@@ -686,101 +858,101 @@ const docTemplate = `{
                 ]
             }
         },
-        "/health": {
+        "/admin/api/v1/diagnostics": {
             "get": {
+                "description": "Goroutine count, memory stats, model registry refresh health and the audit log and usage write pipelines (buffer depth, oldest unwritten entry age, last flush and drops). Status is degraded while a pipeline lags behind its warning threshold or its last flush failed, or while the registry is uninitialized or a provider's model fetch fails.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "system"
+                    "admin"
                 ],
-                "summary": "Health check",
+                "summary": "Get gateway diagnostics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/admin.DiagnosticsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/p/{provider}/{endpoint}": {
+        "/admin/api/v1/errors/summary": {
             "get": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Get error analytics grouped by provider, model, error type and status",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Series bucket interval: hourly or daily (default daily)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of most frequent normalized error messages (default 0, max 50)",
+                        "name": "top_messages",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "201": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "202": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "204": {
-                        "description": "No Content passthrough response",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/auditlog.ErrorSummary"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -789,79 +961,70 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "put": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/admin/api/v1/experiments": {
+            "get": {
+                "description": "Configured experiments with per-variant weights, assignments made by this gateway instance since startup, and tracked usage for the requested period.",
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "List active A/B experiments",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "201": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "202": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "204": {
-                        "description": "No Content passthrough response",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/admin.ExperimentResponse"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -870,79 +1033,86 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "post": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+            }
+        },
+        "/admin/api/v1/logging/level": {
+            "put": {
+                "description": "Sets the global log level, or a single component's level when component is set.",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Change log level at runtime",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "Log level change",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.setLogLevelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/logging.LevelsSnapshot"
                         }
                     },
-                    "201": {
-                        "description": "Opaque upstream response body",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "202": {
-                        "description": "Opaque upstream response body",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "204": {
-                        "description": "No Content passthrough response",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/maintenance": {
+            "get": {
+                "description": "Returns whether maintenance mode rejects model requests, its message and expected end, and the number of model requests in flight.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/admin.MaintenanceResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -952,78 +1122,122 @@ const docTemplate = `{
                     }
                 ]
             },
-            "delete": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+            "put": {
+                "description": "Turns maintenance mode on or off. While it is on, model endpoints answer 503 with a Retry-After header and the message; requests already running finish normally. With duration_seconds it ends by itself. With drain, the call waits up to 25s for in-flight model requests before answering.",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Switch maintenance mode",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "Maintenance mode switch",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.updateMaintenanceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/admin.MaintenanceResponse"
                         }
                     },
-                    "201": {
-                        "description": "Opaque upstream response body",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "202": {
-                        "description": "Opaque upstream response body",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "204": {
-                        "description": "No Content passthrough response",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/model-groups": {
+            "get": {
+                "description": "Configured model groups with the member requests for each group go to right now and why the members ahead of it are skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List model groups and their current resolution",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/modelgroups.Resolution"
+                            }
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/models/categories": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List model categories with counts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/providers.CategoryCount"
+                            }
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "304": {
+                        "description": "Not modified; the If-None-Match header matches the current counts"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1032,79 +1246,55 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "options": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+            }
+        },
+        "/admin/api/v1/provenance/verify": {
+            "post": {
+                "description": "Checks that a response body matches its signed provenance: the content hash, request ID and model must all match the HMAC signature. Answers 503 unless provenance embedding is configured with a secret.",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Verify response provenance",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "Response body and claimed provenance",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.verifyProvenanceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "201": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "202": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "204": {
-                        "description": "No Content passthrough response",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/provenance.Result"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1113,79 +1303,68 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "head": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+            }
+        },
+        "/admin/api/v1/providers/{name}/probe": {
+            "post": {
+                "description": "Sends a battery of tiny requests (1-token chat, streaming chat, chat with a trivial tool, JSON-mode chat, Responses API, one-string embedding) to the provider and stores the resulting capability profile on its status. Usage entries are labeled gomodel_internal=capability_probe and the run is capped by the configured token budget. With apply, routing rejects requests needing a capability found unsupported.",
                 "consumes": [
-                    "application/json",
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Probe provider capabilities",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
+                        "description": "Configured provider name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
-                        "in": "path",
-                        "required": true
+                        "description": "Probe settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.probeProviderRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.CapabilityProfile"
                         }
                     },
-                    "201": {
-                        "description": "Opaque upstream response body",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "202": {
-                        "description": "Opaque upstream response body",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "204": {
-                        "description": "No Content passthrough response",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1194,79 +1373,56 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "patch": {
-                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
-                "consumes": [
-                    "application/json",
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/admin/api/v1/requests/{request_id}": {
+            "get": {
+                "description": "Joins the audit log entries, the usage record, timing, route, applied transformations, moderation result and usage anomalies of one request. Sections that cannot be assembled, for example usage that was not recorded, are null and listed in missing with the reason. Answers 404 when neither store has the request ID.",
                 "produces": [
-                    "application/json",
-                    "application/octet-stream",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "passthrough"
+                    "admin"
                 ],
-                "summary": "Provider passthrough",
+                "summary": "Trace a request by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider type",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
-                        "name": "endpoint",
+                        "description": "Gateway request ID (X-Request-ID)",
+                        "name": "request_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "201": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "202": {
-                        "description": "Opaque upstream response body",
-                        "schema": {
-                            "type": "file"
-                        }
-                    },
-                    "204": {
-                        "description": "No Content passthrough response",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/admin.RequestTrace"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1277,58 +1433,48 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/batches": {
+        "/admin/api/v1/response-ids/{id}": {
             "get": {
+                "description": "Returns the provider response ID, provider, model and gateway request ID behind a response ID issued by response sanitization. Only the most recent response_sanitization.max_mappings IDs are remembered. Answers 503 unless response sanitization is enabled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "batch"
+                    "admin"
                 ],
-                "summary": "List batches",
+                "summary": "Resolve a gateway-issued response ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Pagination cursor",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum items to return (1-100, default 20)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Gateway-issued response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.BatchListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/sanitize.Mapping"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1337,52 +1483,49 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/api/v1/scoreboard": {
+            "get": {
+                "description": "Request count, error rate, p50/p95 latency, stream TTFT and tokens/sec observed by this gateway instance.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "batch"
+                    "admin"
                 ],
-                "summary": "Create a native provider batch",
+                "summary": "Get rolling performance stats per provider and model",
                 "parameters": [
                     {
-                        "description": "Batch request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.BatchRequest"
-                        }
+                        "type": "string",
+                        "description": "Time window: 5m, 1h or 24h (default 5m)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this model",
+                        "name": "model",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.BatchResponse"
+                            "$ref": "#/definitions/scoreboard.Snapshot"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1393,59 +1536,83 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/batches/{id}": {
+        "/admin/api/v1/stream-samples": {
             "get": {
+                "description": "Lists the streamed responses sampled for review, newest first, without their prompt and response text.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "batch"
+                    "admin"
                 ],
-                "summary": "Get a batch",
+                "summary": "List stream samples",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Batch ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by requested or resolved model",
+                        "name": "model",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 25, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.BatchResponse"
+                            "$ref": "#/definitions/auditlog.StreamSampleListResult"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1456,22 +1623,20 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/batches/{id}/cancel": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/api/v1/stream-samples/{id}": {
+            "get": {
+                "description": "Returns a sampled streamed response with its complete prompt and response text.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "batch"
+                    "admin"
                 ],
-                "summary": "Cancel a batch",
+                "summary": "Get a stream sample",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Batch ID",
+                        "description": "Stream sample ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1481,37 +1646,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.BatchResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/auditlog.StreamSample"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1520,67 +1673,82 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        },
-        "/v1/batches/{id}/results": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Removes a stream sample and returns its size to the sample byte budget.",
                 "tags": [
-                    "batch"
+                    "admin"
                 ],
-                "summary": "Get batch results",
+                "summary": "Delete a stream sample",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Batch ID",
+                        "description": "Stream sample ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/core.BatchResultsResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api/v1/templates": {
+            "get": {
+                "description": "Returns the latest version of every prompt template with the list of its stored versions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List prompt templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/prompttemplates.View"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1591,59 +1759,51 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/chat/completions": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/api/v1/templates/{name}": {
+            "get": {
+                "description": "Returns every stored version of a prompt template, oldest first.",
                 "produces": [
-                    "application/json",
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "chat"
+                    "admin"
                 ],
-                "summary": "Create a chat completion",
+                "summary": "List prompt template versions",
                 "parameters": [
                     {
-                        "description": "Chat completion request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.ChatRequest"
-                        }
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JSON response or SSE stream when stream=true",
-                        "schema": {
-                            "$ref": "#/definitions/core.ChatResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/prompttemplates.Template"
+                            }
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "429": {
-                        "description": "Too Many Requests",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1652,10 +1812,9 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        },
-        "/v1/embeddings": {
+            },
             "post": {
+                "description": "Stores the body as the next version of the named template, creating the template when it does not exist. Versions are immutable.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1663,49 +1822,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "embeddings"
+                    "admin"
                 ],
-                "summary": "Create embeddings",
+                "summary": "Create a prompt template version",
                 "parameters": [
                     {
-                        "description": "Embeddings request",
-                        "name": "request",
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template version",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/core.EmbeddingRequest"
+                            "$ref": "#/definitions/admin.createTemplateVersionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/core.EmbeddingResponse"
+                            "$ref": "#/definitions/prompttemplates.Template"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1714,72 +1874,42 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        },
-        "/v1/files": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Removes every version of a prompt template.",
                 "tags": [
-                    "files"
+                    "admin"
                 ],
-                "summary": "List files",
+                "summary": "Delete a prompt template",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider filter",
-                        "name": "provider",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "File purpose filter",
-                        "name": "purpose",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Pagination cursor",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum items to return (1-100, default 20)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/core.FileListResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1788,37 +1918,30 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "post": {
-                "consumes": [
-                    "multipart/form-data"
-                ],
+            }
+        },
+        "/admin/api/v1/templates/{name}/versions/{version}": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "admin"
                 ],
-                "summary": "Upload a file",
+                "summary": "Get a prompt template version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Provider override when multiple providers are configured",
-                        "name": "provider",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "File purpose",
-                        "name": "purpose",
-                        "in": "formData",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Template version",
+                        "name": "version",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -1826,25 +1949,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.FileObject"
+                            "$ref": "#/definitions/prompttemplates.Template"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1853,61 +1982,55 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        },
-        "/v1/files/{id}": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
+            },
+            "delete": {
+                "description": "Removes one version of a prompt template. Requests that do not pin a version use the latest remaining one.",
                 "tags": [
-                    "files"
+                    "admin"
                 ],
-                "summary": "Get file metadata",
+                "summary": "Delete a prompt template version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "File ID",
-                        "name": "id",
+                        "description": "Template name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Provider override",
-                        "name": "provider",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Template version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/core.FileObject"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1916,27 +2039,64 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "delete": {
+            }
+        },
+        "/admin/api/v1/usage/daily": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "admin"
                 ],
-                "summary": "Delete a file",
+                "summary": "Get usage breakdown by period",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "File ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Provider override",
-                        "name": "provider",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Grouping interval: daily, weekly, monthly, yearly (default daily)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of weekly buckets: monday or sunday (default monday)",
+                        "name": "week_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
                         "in": "query"
                     }
                 ],
@@ -1944,31 +2104,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.FileDeleteResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/usage.DailyUsage"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -1979,59 +2130,80 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/files/{id}/content": {
+        "/admin/api/v1/usage/groups": {
             "get": {
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "files"
+                    "admin"
                 ],
-                "summary": "Download file content",
+                "summary": "Get usage grouped by a request label or end user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "File ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Grouping: user or label:\u003ckey\u003e",
+                        "name": "group_by",
+                        "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Provider override",
-                        "name": "provider",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Raw file content",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/usage.LabelUsage"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2042,95 +2214,112 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/models": {
+        "/admin/api/v1/usage/log": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "models"
+                    "admin"
                 ],
-                "summary": "List available models",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/core.ModelsResponse"
-                        }
+                "summary": "Get paginated usage log entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
                     },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    }
-                },
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ]
-            }
-        },
-        "/v1/responses": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json",
-                    "text/event-stream"
-                ],
-                "tags": [
-                    "responses"
-                ],
-                "summary": "Create a model response (Responses API)",
-                "parameters": [
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
                     {
-                        "description": "Responses API request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.ResponsesRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "JSON response or SSE stream when stream=true",
-                        "schema": {
-                            "$ref": "#/definitions/core.ResponsesResponse"
-                        }
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by model name",
+                        "name": "model",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    {
+                        "type": "string",
+                        "description": "Filter by provider name or provider type",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by hash of the user request field",
+                        "name": "user_hash",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by exact gateway request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search across model, provider, request_id, provider_id",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/usage.UsageLogResult"
                         }
                     },
-                    "429": {
-                        "description": "Too Many Requests",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2141,58 +2330,79 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/responses/compact": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/api/v1/usage/models": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "responses"
+                    "admin"
                 ],
-                "summary": "Compact response input",
+                "summary": "Get usage breakdown by model",
                 "parameters": [
                     {
-                        "description": "Response compact request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.ResponseCompactRequest"
-                        }
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group by the served or the requested model: served, requested (default served)",
+                        "name": "model_by",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponseCompactResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/usage.ModelUsage"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "501": {
-                        "description": "Not Implemented",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2203,58 +2413,27 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/responses/input_tokens": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/api/v1/usage/prompt-cache": {
+            "get": {
+                "description": "Requests sent with a cacheable system prompt prefix, their provider cache hit rate, cached tokens and estimated savings per prefix, observed by this gateway instance since startup.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "responses"
-                ],
-                "summary": "Count response input tokens",
-                "parameters": [
-                    {
-                        "description": "Response input token request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.ResponseInputTokensRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "Get prompt cache hit rates and savings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponseInputTokensResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/promptcache.Report"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "501": {
-                        "description": "Not Implemented",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2265,59 +2444,50 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/responses/{id}": {
+        "/admin/api/v1/usage/summary": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "responses"
+                    "admin"
                 ],
-                "summary": "Get a response",
+                "summary": "Get usage summary",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Response ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Provider override for native lookups",
-                        "name": "provider",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
                         "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Fields to include in the response",
-                        "name": "include",
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
                         "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Fields to include in the response",
-                        "name": "include[]",
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Whether to include obfuscated response data",
-                        "name": "include_obfuscation",
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Input item offset for providers that support it",
-                        "name": "starting_after",
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
                         "in": "query"
                     }
                 ],
@@ -2325,37 +2495,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponsesResponse"
+                            "$ref": "#/definitions/usage.UsageSummary"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "501": {
-                        "description": "Not Implemented",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2364,27 +2516,52 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            },
-            "delete": {
+            }
+        },
+        "/admin/api/v1/usage/user-paths": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "responses"
+                    "admin"
                 ],
-                "summary": "Delete a response",
+                "summary": "Get usage breakdown by user path",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Response ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Provider override for native deletion",
-                        "name": "provider",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone for day boundaries (default UTC)",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tracked user path subtree",
+                        "name": "user_path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode filter: uncached, cached, all (default uncached)",
+                        "name": "cache_mode",
                         "in": "query"
                     }
                 ],
@@ -2392,37 +2569,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponseDeleteResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/usage.UserPathUsage"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "501": {
-                        "description": "Not Implemented",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
-                        }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "$ref": "#/definitions/core.GatewayError"
                         }
                     }
                 },
@@ -2433,57 +2595,90 @@ const docTemplate = `{
                 ]
             }
         },
-        "/v1/responses/{id}/cancel": {
-            "post": {
+        "/health": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "responses"
+                    "system"
                 ],
-                "summary": "Cancel a response",
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.healthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/p/{provider}/{endpoint}": {
+            "get": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Response ID",
-                        "name": "id",
+                        "description": "Provider type",
+                        "name": "provider",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Provider override for native cancellation",
-                        "name": "provider",
-                        "in": "query"
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponsesResponse"
+                            "type": "file"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "201": {
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "file"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "202": {
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/core.OpenAIErrorEnvelope"
                         }
                     },
-                    "501": {
-                        "description": "Not Implemented",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.OpenAIErrorEnvelope"
                         }
@@ -2500,101 +2695,71 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        },
-        "/v1/responses/{id}/input_items": {
-            "get": {
+            },
+            "put": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "responses"
+                    "passthrough"
                 ],
-                "summary": "List response input items",
+                "summary": "Provider passthrough",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Response ID",
-                        "name": "id",
+                        "description": "Provider type",
+                        "name": "provider",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Provider override for native lookups",
-                        "name": "provider",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Pagination cursor",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Fields to include in listed input items",
-                        "name": "include",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Fields to include in listed input items",
-                        "name": "include[]",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Maximum items to return (1-100, default 20)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
-                        "type": "string",
-                        "description": "Sort order: asc or desc",
-                        "name": "order",
-                        "in": "query"
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.ResponseInputItemListResponse"
+                            "type": "file"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "201": {
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "file"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "202": {
+                        "description": "Opaque upstream response body",
                         "schema": {
-                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/core.OpenAIErrorEnvelope"
                         }
                     },
-                    "501": {
-                        "description": "Not Implemented",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/core.OpenAIErrorEnvelope"
                         }
@@ -2611,412 +2776,4913 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ]
-            }
-        }
-    },
-    "definitions": {
-        "auditlog.ConversationResult": {
-            "type": "object",
+            },
+            "post": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider type",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider type",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "options": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider type",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "head": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider type",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Runtime-configurable passthrough endpoint under /p/{provider}/{endpoint}; enabled by default via server.enable_passthrough_routes. The endpoint path is opaque and may proxy JSON, binary, or SSE responses with upstream status codes preserved. For multi-segment provider endpoints, clients that rely on OpenAPI-generated path handling should URL-encode embedded slashes in the endpoint parameter. A leading v1/ segment is normalized away by default so /p/{provider}/v1/... and /p/{provider}/... map to the same upstream path relative to the provider base URL.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "passthrough"
+                ],
+                "summary": "Provider passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider type",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider-native endpoint path relative to the provider base URL. URL-encode embedded / characters when using generated clients.",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "201": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content passthrough response",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/assistants/{path}": {
+            "get": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/batches": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "List batches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum items to return (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Create a native provider batch",
+                "parameters": [
+                    {
+                        "description": "Batch request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/batches/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Get a batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/batches/{id}/cancel": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Cancel a batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/batches/{id}/results": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Get batch results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.BatchResultsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/chat/completions": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Create a chat completion",
+                "parameters": [
+                    {
+                        "description": "Chat completion request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON response or SSE stream when stream=true",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/chat/completions/compare": {
+            "post": {
+                "description": "Sends one chat request to every listed model concurrently. Results keep the order of models and report per-model failures without failing the whole request. With stream=true, chunks from all models are multiplexed into one SSE stream of comparison events tagged with the model index.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "chat"
+                ],
+                "summary": "Compare chat completions across models",
+                "parameters": [
+                    {
+                        "description": "Chat request plus the models to compare",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatComparisonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON response, or SSE stream of core.ChatComparisonStreamEvent when stream=true",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/deferred/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deferred"
+                ],
+                "summary": "Get a deferred request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deferred request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.DeferredRequestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/embeddings": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "embeddings"
+                ],
+                "summary": "Create embeddings",
+                "parameters": [
+                    {
+                        "description": "Embeddings request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.EmbeddingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.EmbeddingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/files": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "List files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider filter",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File purpose filter",
+                        "name": "purpose",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum items to return (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.FileListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Upload a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider override when multiple providers are configured",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File purpose",
+                        "name": "purpose",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.FileObject"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/files/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Get file metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.FileObject"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Delete a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.FileDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/files/{id}/content": {
+            "get": {
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Download file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Raw file content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/models": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "models"
+                ],
+                "summary": "List available models",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Also list model groups as group:\u003cname\u003e entries",
+                        "name": "extended",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ModelsResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified; the If-None-Match header matches the current listing"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Create a model response (Responses API)",
+                "parameters": [
+                    {
+                        "description": "Responses API request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponsesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JSON response or SSE stream when stream=true",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponsesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses/compact": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Compact response input",
+                "parameters": [
+                    {
+                        "description": "Response compact request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseCompactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseCompactResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses/input_tokens": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Count response input tokens",
+                "parameters": [
+                    {
+                        "description": "Response input token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseInputTokensRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseInputTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Get a response",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override for native lookups",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Fields to include in the response",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Fields to include in the response",
+                        "name": "include[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether to include obfuscated response data",
+                        "name": "include_obfuscation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Input item offset for providers that support it",
+                        "name": "starting_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponsesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Delete a response",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override for native deletion",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses/{id}/cancel": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "Cancel a response",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override for native cancellation",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponsesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/responses/{id}/input_items": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "responses"
+                ],
+                "summary": "List response input items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Response ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider override for native lookups",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Fields to include in listed input items",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Fields to include in listed input items",
+                        "name": "include[]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum items to return (1-100, default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order: asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ResponseInputItemListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/threads/{path}": {
+            "get": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "assistants"
+                ],
+                "summary": "OpenAI Assistants passthrough",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Assistants API path below /v1/assistants or /v1/threads",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Opaque upstream response body",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/usage": {
+            "get": {
+                "description": "Returns the token and cost usage recorded for the managed API key that authenticates the request. Only that key's usage is ever returned; the key cannot be chosen by query parameter. Responses are cached for 30 seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Get usage of the calling API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of days including today, 1-90 (default 30)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.keyUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/core.OpenAIErrorEnvelope"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "admin.AnomaliesResponse": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/anomaly.Anomaly"
+                    }
+                }
+            }
+        },
+        "admin.ChaosRulesResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active is false while the kill switch is on.",
+                    "type": "boolean"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chaos.Rule"
+                    }
+                }
+            }
+        },
+        "admin.DeferredQueueResponse": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "depth": {
+                    "description": "Depth is the number of requests waiting for or undergoing replay.",
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.DiagnosticsResponse": {
+            "type": "object",
+            "properties": {
+                "issues": {
+                    "description": "Issues explains a degraded status, one line per problem.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pipelines": {
+                    "$ref": "#/definitions/admin.PipelineDiagnostics"
+                },
+                "registry": {
+                    "$ref": "#/definitions/admin.RegistryDiagnostics"
+                },
+                "runtime": {
+                    "$ref": "#/definitions/admin.RuntimeDiagnostics"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.EffectiveConfigResponse": {
+            "type": "object",
+            "properties": {
+                "config": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "profile": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.ExperimentResponse": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.ExperimentVariantResponse"
+                    }
+                }
+            }
+        },
+        "admin.ExperimentVariantResponse": {
+            "type": "object",
+            "properties": {
+                "assignments": {
+                    "type": "integer"
+                },
+                "control": {
+                    "type": "boolean"
+                },
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                },
+                "weight_share": {
+                    "type": "number"
+                }
+            }
+        },
+        "admin.MaintenanceResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "drained": {
+                    "type": "boolean"
+                },
+                "in_flight": {
+                    "description": "InFlight is the number of model requests still running.",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "until": {
+                    "description": "Until is when an activation with a duration ends by itself.",
+                    "type": "string"
+                }
+            }
+        },
+        "admin.ModelCachePurgeResponse": {
+            "type": "object",
+            "properties": {
+                "freed_bytes": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gomodel_internal_cache_modelcache.RemovedCacheFile"
+                    }
+                }
+            }
+        },
+        "admin.PipelineDiagnostics": {
+            "type": "object",
+            "properties": {
+                "audit_log": {
+                    "$ref": "#/definitions/pipelinestats.Snapshot"
+                },
+                "usage": {
+                    "$ref": "#/definitions/pipelinestats.Snapshot"
+                }
+            }
+        },
+        "admin.RegistryDiagnostics": {
+            "type": "object",
+            "properties": {
+                "failing_providers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.RegistryProviderDiagnosis"
+                    }
+                },
+                "initialized": {
+                    "type": "boolean"
+                },
+                "model_count": {
+                    "type": "integer"
+                },
+                "provider_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RegistryProviderDiagnosis": {
+            "type": "object",
+            "properties": {
+                "last_model_fetch_at": {
+                    "type": "string"
+                },
+                "last_model_fetch_error": {
+                    "type": "string"
+                },
+                "last_model_fetch_success_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.RequestTrace": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts lists the audit log entries for the request ID, oldest first.\nClients that retry with the same X-Request-ID produce more than one.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.RequestTraceAttempt"
+                    }
+                },
+                "audit": {
+                    "description": "Audit is the newest audit log entry recorded for the request ID.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.LogEntry"
+                        }
+                    ],
+                    "x-nullable": true
+                },
+                "flags": {
+                    "$ref": "#/definitions/admin.RequestTraceFlags"
+                },
+                "missing": {
+                    "description": "Missing maps every null section or flag, such as \"usage\" or\n\"flags.moderation\", to the reason it is absent.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "route": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/admin.RequestTraceRoute"
+                        }
+                    ],
+                    "x-nullable": true
+                },
+                "timing": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/admin.RequestTraceTiming"
+                        }
+                    ],
+                    "x-nullable": true
+                },
+                "transformations": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/admin.RequestTraceTransformations"
+                        }
+                    ],
+                    "x-nullable": true
+                },
+                "usage": {
+                    "description": "Usage is the newest usage record for the request ID, with token counts,\ncosts and provider-reported details such as cached and reasoning tokens\nin raw_data.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/usage.UsageLogEntry"
+                        }
+                    ],
+                    "x-nullable": true
+                }
+            }
+        },
+        "admin.RequestTraceAttempt": {
+            "type": "object",
+            "properties": {
+                "duration_ns": {
+                    "type": "integer"
+                },
+                "error_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_name": {
+                    "type": "string"
+                },
+                "resolved_model": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.RequestTraceFlags": {
+            "type": "object",
+            "properties": {
+                "anomalies": {
+                    "description": "Anomalies were detected for the request's model, provider or API key\nin the minute the request was recorded.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/anomaly.Anomaly"
+                    },
+                    "x-nullable": true
+                },
+                "moderation": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ModerationSnapshot"
+                        }
+                    ],
+                    "x-nullable": true
+                },
+                "redaction": {
+                    "$ref": "#/definitions/auditlog.RedactionSnapshot"
+                }
+            }
+        },
+        "admin.RequestTraceRoute": {
+            "type": "object",
+            "properties": {
+                "alias_used": {
+                    "type": "boolean"
+                },
+                "cache_type": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_name": {
+                    "type": "string"
+                },
+                "requested_model": {
+                    "type": "string"
+                },
+                "resolved_model": {
+                    "type": "string"
+                },
+                "served_model": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "upstream_status_code": {
+                    "type": "integer"
+                },
+                "workflow_version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.RequestTraceTiming": {
+            "type": "object",
+            "properties": {
+                "duration_ns": {
+                    "type": "integer"
+                },
+                "gateway_overhead_ns": {
+                    "description": "GatewayOverheadNs is the time spent outside the upstream call. It is\nomitted when the upstream duration was not recorded.",
+                    "type": "integer"
+                },
+                "pacing_delay_ns": {
+                    "type": "integer"
+                },
+                "stream": {
+                    "$ref": "#/definitions/auditlog.StreamTimingSnapshot"
+                },
+                "upstream_duration_ns": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.RequestTraceTransformations": {
+            "type": "object",
+            "properties": {
+                "chaos": {
+                    "$ref": "#/definitions/auditlog.ChaosSnapshot"
+                },
+                "context_overflow": {
+                    "$ref": "#/definitions/auditlog.ContextOverflowSnapshot"
+                },
+                "experiment": {
+                    "$ref": "#/definitions/auditlog.ExperimentSnapshot"
+                },
+                "failover": {
+                    "$ref": "#/definitions/auditlog.FailoverSnapshot"
+                },
+                "guardrail_canaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.GuardrailCanarySnapshot"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "prompt_compression": {
+                    "$ref": "#/definitions/auditlog.PromptCompressionSnapshot"
+                },
+                "prompt_template": {
+                    "$ref": "#/definitions/auditlog.PromptTemplateSnapshot"
+                },
+                "workflow_features": {
+                    "$ref": "#/definitions/auditlog.WorkflowFeaturesSnapshot"
+                }
+            }
+        },
+        "admin.RuntimeDiagnostics": {
+            "type": "object",
+            "properties": {
+                "goroutines": {
+                    "type": "integer"
+                },
+                "heap_alloc_bytes": {
+                    "type": "integer"
+                },
+                "heap_inuse_bytes": {
+                    "type": "integer"
+                },
+                "last_gc_at": {
+                    "type": "string"
+                },
+                "num_gc": {
+                    "type": "integer"
+                },
+                "sys_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.createTemplateVersionRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/prompttemplates.Message"
+                    }
+                },
+                "variables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/prompttemplates.Variable"
+                    }
+                }
+            }
+        },
+        "admin.probeProviderRequest": {
+            "type": "object",
+            "properties": {
+                "apply": {
+                    "description": "Apply makes routing reject requests that need a capability the probe\nfound unsupported, until the next probe replaces the profile.",
+                    "type": "boolean"
+                },
+                "checks": {
+                    "description": "Checks limits the battery; empty runs every check.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "embedding_model": {
+                    "description": "EmbeddingModel is the model of the embeddings check; empty uses model.",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "Model is the chat model the checks use.",
+                    "type": "string"
+                },
+                "token_budget": {
+                    "description": "TokenBudget lowers the configured token budget for this run.",
+                    "type": "integer"
+                }
+            }
+        },
+        "admin.setLogLevelRequest": {
+            "type": "object",
+            "properties": {
+                "component": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.updateChaosRulesRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active flips the kill switch when set.",
+                    "type": "boolean"
+                },
+                "rules": {
+                    "description": "Rules replaces every rule when set.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/chaos.Rule"
+                    }
+                }
+            }
+        },
+        "admin.updateMaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "drain": {
+                    "description": "Drain waits for in-flight model requests to finish before answering.",
+                    "type": "boolean"
+                },
+                "duration_seconds": {
+                    "description": "DurationSeconds ends maintenance by itself after this many seconds.\nZero keeps it on until it is disabled.",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "Enabled turns maintenance mode on or off.",
+                    "type": "boolean"
+                },
+                "message": {
+                    "description": "Message is returned to rejected clients.",
+                    "type": "string"
+                }
+            }
+        },
+        "admin.verifyProvenanceRequest": {
+            "type": "object",
+            "properties": {
+                "provenance": {
+                    "description": "Provenance is the claimed provenance. When omitted, the\ngomodel_provenance object embedded in Response is verified.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/provenance.Provenance"
+                        }
+                    ]
+                },
+                "response": {
+                    "description": "Response is the response body as the client received it.",
+                    "type": "object"
+                }
+            }
+        },
+        "anomaly.Anomaly": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "boolean"
+                },
+                "acknowledged_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "baseline": {
+                    "description": "Baseline is the series' average for the metric's window. It is nil\nwhile the series has less history than the configured minimum.",
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Rule is the rule that fired.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.AnomalyRule"
+                        }
+                    ]
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "Subject is the model, provider name or managed API key ID.",
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "auditlog.CapabilityProbeSnapshot": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokens_used": {
+                    "type": "integer"
+                },
+                "unsupported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auditlog.ChaosSnapshot": {
+            "type": "object",
+            "properties": {
+                "effect": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ComparisonSnapshot": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ContextOverflowSnapshot": {
+            "type": "object",
+            "properties": {
+                "dropped_messages": {
+                    "type": "integer"
+                },
+                "estimated_tokens": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "strategy": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ConversationResult": {
+            "type": "object",
+            "properties": {
+                "anchor_id": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.LogEntry"
+                    }
+                }
+            }
+        },
+        "auditlog.DataResidencySnapshot": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "requirement": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.EmbeddingCacheSnapshot": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ErrorGroup": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "error_type": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ErrorMessageCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ErrorSeriesPoint": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ErrorSummary": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.ErrorGroup"
+                    }
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.ErrorSeriesPoint"
+                    }
+                },
+                "top_messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.ErrorMessageCount"
+                    }
+                },
+                "total_errors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ExperimentSnapshot": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "variant": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.FailoverSnapshot": {
+            "type": "object",
+            "properties": {
+                "target_model": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.GuardrailCanarySnapshot": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.LogData": {
+            "type": "object",
+            "properties": {
+                "api_key_hash": {
+                    "type": "string"
+                },
+                "body_log_opt_out": {
+                    "description": "BodyLogOptOut marks a request whose client opted out of body capture\nwith core.NoBodyLogHeader; its RequestBody and ResponseBody are empty.",
+                    "type": "boolean"
+                },
+                "capability_probe": {
+                    "description": "CapabilityProbe marks an admin request that ran a capability probe,\nwhose provider requests were internal traffic.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.CapabilityProbeSnapshot"
+                        }
+                    ]
+                },
+                "chaos": {
+                    "description": "Chaos marks a request whose response was faulted on purpose by a fault\ninjection rule.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ChaosSnapshot"
+                        }
+                    ]
+                },
+                "comparison": {
+                    "description": "Comparison links a sub-request to the multi-model chat comparison that\nfanned it out. The comparison ID is also used as the request ID.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ComparisonSnapshot"
+                        }
+                    ]
+                },
+                "context_overflow": {
+                    "description": "ContextOverflow records how the gateway shortened or rejected a prompt\nthat did not fit the model's context window.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ContextOverflowSnapshot"
+                        }
+                    ]
+                },
+                "data_residency": {
+                    "description": "DataResidency records the data residency requirement of the request and\nthe provider instance that satisfied it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.DataResidencySnapshot"
+                        }
+                    ]
+                },
+                "decryption_error": {
+                    "description": "DecryptionError is set by readers when Sealed could not be decrypted,\nfor example because its key is no longer configured. It is never stored.",
+                    "type": "string"
+                },
+                "embedding_cache": {
+                    "description": "EmbeddingCache records how many embeddings inputs were served from the\nembeddings cache and how many were sent upstream.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.EmbeddingCacheSnapshot"
+                        }
+                    ]
+                },
+                "error_message": {
+                    "description": "Error details (message can be long, so kept in JSON)",
+                    "type": "string"
+                },
+                "experiment": {
+                    "description": "Experiment records the A/B experiment variant that selected the model.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ExperimentSnapshot"
+                        }
+                    ]
+                },
+                "failover": {
+                    "description": "Failover captures runtime redirect details when translated execution\nmoved from the primary selector to a configured failover target.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.FailoverSnapshot"
+                        }
+                    ]
+                },
+                "guardrail_canaries": {
+                    "description": "GuardrailCanaries records, for each guardrail in canary rollout that\nthe request reached, whether the guardrail was applied.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.GuardrailCanarySnapshot"
+                    }
+                },
+                "idempotent_replay_of": {
+                    "description": "IdempotentReplayOf is the request id of the original request when this\nrequest was answered from it because it repeated its Idempotency-Key.",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Labels holds the cost allocation labels of the request, taken from\nX-GoModel-Label-* headers and the request metadata object.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "maintenance": {
+                    "description": "Maintenance records the maintenance mode state an admin request set.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.MaintenanceSnapshot"
+                        }
+                    ]
+                },
+                "max_tokens": {
+                    "type": "integer"
+                },
+                "model_group": {
+                    "description": "ModelGroup records the model group the request targeted and the member\nthat served it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ModelGroupSnapshot"
+                        }
+                    ]
+                },
+                "moderation": {
+                    "description": "Moderation records the moderation pre-check of a request that was let\nthrough with flagged categories or because the check failed open.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.ModerationSnapshot"
+                        }
+                    ]
+                },
+                "options": {
+                    "description": "Options holds the effective value of every request option when the\nrequest set any through X-GoModel-Options or a standalone option\nheader; unset options show their defaults.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "pacing_delay_ns": {
+                    "description": "PacingDelayNs is the time the request spent queued by provider request\npacing before reaching upstream, summed over retries and failovers.\nIt is part of UpstreamDurationNs for the call that was paced.",
+                    "type": "integer"
+                },
+                "prompt_compression": {
+                    "description": "PromptCompression records which prompt compression passes changed the\nrequest and the estimated prompt tokens they saved.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.PromptCompressionSnapshot"
+                        }
+                    ]
+                },
+                "prompt_template": {
+                    "description": "PromptTemplate records the stored prompt template rendered into the\nrequest messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.PromptTemplateSnapshot"
+                        }
+                    ]
+                },
+                "redaction": {
+                    "description": "Redaction records who removed the bodies and headers of this entry and\nwhen. It is nil for entries that were never redacted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.RedactionSnapshot"
+                        }
+                    ]
+                },
+                "request_body": {
+                    "description": "Optional bodies (when LOGGING_LOG_BODIES=true)\nStored as interface{} so MongoDB serializes as native BSON documents (queryable/readable)\ninstead of BSON Binary (base64 in Compass)"
+                },
+                "request_body_bytes": {
+                    "description": "RequestBodyBytes and RequestBodySHA256 describe a request body that was\npiped to the provider without being buffered. The hash is only set when\nthe provider read the whole body.",
+                    "type": "integer"
+                },
+                "request_body_sha256": {
+                    "type": "string"
+                },
+                "request_body_too_big_to_handle": {
+                    "description": "Body capture status flags (set when body exceeds 1MB limit)",
+                    "type": "boolean"
+                },
+                "request_headers": {
+                    "description": "Optional headers (when LOGGING_LOG_HEADERS=true)\nSensitive headers are auto-redacted",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "request_images": {
+                    "description": "RequestImages summarizes the inline base64 images of the request. Their\npayloads are replaced by placeholders in RequestBody.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.InlineImage"
+                    }
+                },
+                "response_body": {},
+                "response_body_too_big_to_handle": {
+                    "type": "boolean"
+                },
+                "response_headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "sealed": {
+                    "description": "Sealed holds the bodies, and optionally the headers, encrypted at rest\nwhen audit log encryption is enabled. Readers decrypt it back into the\nplain fields.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.SealedFields"
+                        }
+                    ]
+                },
+                "stream_backpressure": {
+                    "description": "StreamBackpressure records how far a streamed response filled its\nper-connection send buffer and whether a slow client stalled it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.StreamBackpressureSnapshot"
+                        }
+                    ]
+                },
+                "stream_format": {
+                    "description": "StreamFormat is the format a streamed response was re-encoded to for\nthe client, such as \"ndjson\". It is empty for SSE; captured stream\nbodies are always the SSE events.",
+                    "type": "string"
+                },
+                "stream_sample_id": {
+                    "description": "StreamSampleID links a streamed response to the stream sample holding\nits complete text.",
+                    "type": "string"
+                },
+                "stream_timing": {
+                    "description": "StreamTiming records how regularly the provider delivered the chunks of\na streamed response.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.StreamTimingSnapshot"
+                        }
+                    ]
+                },
+                "temperature": {
+                    "description": "Request parameters",
+                    "type": "number"
+                },
+                "unlisted_model": {
+                    "description": "UnlistedModel is set when the model was missing from the model registry\nand the request reached a provider that allows unlisted models.",
+                    "type": "boolean"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "description": "Identity",
+                    "type": "string"
+                },
+                "user_hash": {
+                    "description": "UserHash is the hash of the user request field. User holds the raw\nvalue and is only recorded when the gateway is configured to keep it.",
+                    "type": "string"
+                },
+                "workflow_features": {
+                    "description": "WorkflowFeatures captures the request-time effective workflow features\nafter runtime caps were applied. This keeps audit views historically accurate\neven if the active process config changes later.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.WorkflowFeaturesSnapshot"
+                        }
+                    ]
+                }
+            }
+        },
+        "auditlog.LogEntry": {
+            "type": "object",
+            "properties": {
+                "alias_used": {
+                    "type": "boolean"
+                },
+                "auth_key_id": {
+                    "type": "string"
+                },
+                "auth_method": {
+                    "type": "string"
+                },
+                "cache_type": {
+                    "type": "string"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "data": {
+                    "description": "Data contains flexible request/response information as JSON",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auditlog.LogData"
+                        }
+                    ]
+                },
+                "duration_ns": {
+                    "description": "DurationNs is the request duration in nanoseconds",
+                    "type": "integer"
+                },
+                "error_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is a unique identifier for this log entry (UUID)",
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "canonical provider type used for routing and filters",
+                    "type": "string"
+                },
+                "provider_name": {
+                    "type": "string"
+                },
+                "provider_response_id": {
+                    "type": "string"
+                },
+                "redacted": {
+                    "description": "Redacted reports whether an administrator removed the bodies and headers\nof this entry. Readers derive it from Data.Redaction.",
+                    "type": "boolean"
+                },
+                "request_id": {
+                    "description": "Extracted fields for efficient filtering (indexed in relational DBs)",
+                    "type": "string"
+                },
+                "requested_model": {
+                    "description": "Core fields (indexed for queries)",
+                    "type": "string"
+                },
+                "resolved_model": {
+                    "type": "string"
+                },
+                "response_id": {
+                    "description": "ResponseID is the response ID returned to the client (chat \"id\" or\nResponses \"id\"). ProviderResponseID is the ID the provider issued when\nit differs, e.g. the Anthropic message ID behind a Responses stream\nwhose ID the gateway synthesized. Both are indexed for support lookups.",
+                    "type": "string"
+                },
+                "served_model": {
+                    "description": "model reported by the provider response",
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "description": "Timestamp is when the request started",
+                    "type": "string"
+                },
+                "upstream_duration_ns": {
+                    "description": "UpstreamDurationNs is the provider call duration in nanoseconds. For\nstreaming requests it measures the time until response headers arrived.",
+                    "type": "integer"
+                },
+                "upstream_status_code": {
+                    "description": "UpstreamStatusCode is the HTTP status the provider returned, before the\ngateway mapped it to StatusCode. Zero when no provider call was made.",
+                    "type": "integer"
+                },
+                "user_path": {
+                    "type": "string"
+                },
+                "workflow_version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.LogListResult": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.LogEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.MaintenanceSnapshot": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "drain": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ModelGroupSnapshot": {
+            "type": "object",
+            "properties": {
+                "fallback": {
+                    "type": "boolean"
+                },
+                "member": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ModerationSnapshot": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "flagged": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scores": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "unavailable": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "auditlog.PromptCompressionSnapshot": {
+            "type": "object",
+            "properties": {
+                "estimated_tokens": {
+                    "type": "integer"
+                },
+                "passes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tokens_saved": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.PromptTemplateSnapshot": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.ReEncryptFailure": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.ReEncryptResult": {
+            "type": "object",
+            "properties": {
+                "active_key_id": {
+                    "type": "string"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "failures": {
+                    "description": "Failures lists the entries whose data key could not be unwrapped,\nusually because their key is no longer configured.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.ReEncryptFailure"
+                    }
+                },
+                "next": {
+                    "description": "Next is the cursor to pass to the following call. It is empty when Done.",
+                    "type": "string"
+                },
+                "rewrapped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.RedactionSnapshot": {
+            "type": "object",
+            "properties": {
+                "redacted_at": {
+                    "type": "string"
+                },
+                "redacted_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.SealedFields": {
+            "type": "object",
+            "properties": {
+                "ciphertext": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "wrapped_key": {
+                    "description": "WrappedKey is the nonce-prefixed data key encrypted by the master key.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "auditlog.StreamBackpressureSnapshot": {
+            "type": "object",
+            "properties": {
+                "buffer_high_water_bytes": {
+                    "type": "integer"
+                },
+                "stalls": {
+                    "type": "integer"
+                },
+                "terminated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "auditlog.StreamSample": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "description": "Bytes is the stored size of Prompt and Response, counted against the\nsample byte budget.",
+                    "type": "integer"
+                },
+                "duration_ns": {
+                    "description": "DurationNs is the time from request start until the stream closed and\nFirstTokenNs the time until the first response text arrived.",
+                    "type": "integer"
+                },
+                "finish_reason": {
+                    "type": "string"
+                },
+                "first_token_ns": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "log_id": {
+                    "type": "string"
+                },
+                "opt_in": {
+                    "description": "OptIn reports whether the client asked for the sample instead of it\nbeing picked at random.",
+                    "type": "boolean"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_name": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "requested_model": {
+                    "type": "string"
+                },
+                "resolved_model": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "user_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "auditlog.StreamSampleListResult": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auditlog.StreamSample"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.StreamTimingSnapshot": {
+            "type": "object",
+            "properties": {
+                "chunks": {
+                    "type": "integer"
+                },
+                "max_gap_ms": {
+                    "type": "integer"
+                },
+                "p95_gap_ms": {
+                    "type": "integer"
+                },
+                "stalls": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auditlog.WorkflowFeaturesSnapshot": {
+            "type": "object",
+            "properties": {
+                "audit": {
+                    "type": "boolean"
+                },
+                "cache": {
+                    "type": "boolean"
+                },
+                "fallback": {
+                    "type": "boolean"
+                },
+                "guardrails": {
+                    "type": "boolean"
+                },
+                "usage": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "chaos.Effect": {
+            "type": "string",
+            "enum": [
+                "latency",
+                "error",
+                "truncate_stream",
+                "corrupt_stream"
+            ],
+            "x-enum-varnames": [
+                "EffectLatency",
+                "EffectError",
+                "EffectTruncateStream",
+                "EffectCorruptStream"
+            ]
+        },
+        "chaos.Rule": {
+            "type": "object",
+            "properties": {
+                "after_chunks": {
+                    "type": "integer"
+                },
+                "effect": {
+                    "$ref": "#/definitions/chaos.Effect"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "config.AnomalyRule": {
+            "type": "object",
+            "properties": {
+                "above": {
+                    "description": "Above fires when the metric exceeds this value. 0 disables it.",
+                    "type": "number"
+                },
+                "metric": {
+                    "description": "Metric is requests_per_minute, tokens_per_minute or cost_per_hour.",
+                    "type": "string"
+                },
+                "min_value": {
+                    "description": "MinValue keeps Multiple from firing while the metric is at or below\nit, so series with a near-zero baseline do not fire on a few requests.",
+                    "type": "number"
+                },
+                "multiple": {
+                    "description": "Multiple fires when the metric exceeds this multiple of the baseline.\n0 disables it.",
+                    "type": "number"
+                },
+                "scope": {
+                    "description": "Scope limits the rule to model, provider or key series. Empty applies\nit to all three.",
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.BatchResponse"
+                    }
+                },
+                "first_id": {
+                    "type": "string"
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "last_id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "completion_window": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "input_file_id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.BatchRequestItem"
+                    }
+                }
+            }
+        },
+        "core.BatchRequestCounts": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.BatchRequestItem": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "object"
+                },
+                "custom_id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled_at": {
+                    "type": "integer"
+                },
+                "cancelling_at": {
+                    "type": "integer"
+                },
+                "completed_at": {
+                    "type": "integer"
+                },
+                "completion_window": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "endpoint": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "in_progress_at": {
+                    "type": "integer"
+                },
+                "input_file_id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "object": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_batch_id": {
+                    "type": "string"
+                },
+                "request_counts": {
+                    "$ref": "#/definitions/core.BatchRequestCounts"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.BatchResultItem"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "usage": {
+                    "description": "Gateway extension: optional usage/result snapshots persisted by the gateway.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/core.BatchUsageSummary"
+                        }
+                    ]
+                }
+            }
+        },
+        "core.BatchResultItem": {
+            "type": "object",
             "properties": {
-                "anchor_id": {
+                "custom_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "$ref": "#/definitions/core.BatchError"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "response": {},
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchResultsResponse": {
+            "type": "object",
+            "properties": {
+                "batch_id": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.BatchResultItem"
+                    }
+                },
+                "object": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BatchUsageSummary": {
+            "type": "object",
+            "properties": {
+                "input_cost": {
+                    "type": "number"
+                },
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "output_cost": {
+                    "type": "number"
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "number"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.CapabilityCheck": {
+            "type": "object",
+            "properties": {
+                "capability": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/core.CapabilityCheckStatus"
+                },
+                "tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.CapabilityCheckStatus": {
+            "type": "string",
+            "enum": [
+                "supported",
+                "unsupported",
+                "inconclusive",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "CapabilitySupported",
+                "CapabilityUnsupported",
+                "CapabilityInconclusive",
+                "CapabilitySkipped"
+            ]
+        },
+        "core.CapabilityProfile": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Applied profiles make routing reject requests that need a capability\nthe probe found unsupported.",
+                    "type": "boolean"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.CapabilityCheck"
+                    }
+                },
+                "embedding_model": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "probed_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "token_budget": {
+                    "type": "integer"
+                },
+                "tokens_used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ChatComparisonRequest": {
+            "type": "object",
+            "properties": {
+                "max_tokens": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Message"
+                    }
+                },
+                "model": {
+                    "type": "string"
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "parallel_tool_calls": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
+                    "type": "string"
+                },
+                "reasoning": {
+                    "$ref": "#/definitions/core.Reasoning"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "stream_options": {
+                    "$ref": "#/definitions/core.StreamOptions"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "tool_choice": {
+                    "description": "string or object"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": {}
+                    }
+                }
+            }
+        },
+        "core.ChatComparisonResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "estimated_cost": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ChatComparisonResult"
+                    }
+                }
+            }
+        },
+        "core.ChatComparisonResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/core.OpenAIErrorObject"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "response": {
+                    "$ref": "#/definitions/core.ChatResponse"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "usage": {
+                    "$ref": "#/definitions/core.Usage"
+                }
+            }
+        },
+        "core.ChatRequest": {
+            "type": "object",
+            "properties": {
+                "max_tokens": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Message"
+                    }
+                },
+                "model": {
                     "type": "string"
                 },
-                "entries": {
+                "parallel_tool_calls": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
+                    "type": "string"
+                },
+                "reasoning": {
+                    "$ref": "#/definitions/core.Reasoning"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "stream_options": {
+                    "$ref": "#/definitions/core.StreamOptions"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "tool_choice": {
+                    "description": "string or object"
+                },
+                "tools": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/auditlog.LogEntry"
+                        "type": "object",
+                        "additionalProperties": {}
                     }
                 }
             }
         },
-        "auditlog.FailoverSnapshot": {
+        "core.ChatResponse": {
             "type": "object",
             "properties": {
-                "target_model": {
+                "choices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.Choice"
+                    }
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "system_fingerprint": {
                     "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/core.Usage"
                 }
             }
         },
-        "auditlog.LogData": {
+        "core.Choice": {
             "type": "object",
             "properties": {
-                "api_key_hash": {
+                "finish_reason": {
                     "type": "string"
                 },
-                "error_message": {
-                    "description": "Error details (message can be long, so kept in JSON)",
+                "index": {
+                    "type": "integer"
+                },
+                "logprobs": {
+                    "type": "object"
+                },
+                "message": {
+                    "$ref": "#/definitions/core.ResponseMessage"
+                }
+            }
+        },
+        "core.CompletionTokensDetails": {
+            "type": "object",
+            "properties": {
+                "accepted_prediction_tokens": {
+                    "type": "integer"
+                },
+                "audio_tokens": {
+                    "type": "integer"
+                },
+                "reasoning_tokens": {
+                    "type": "integer"
+                },
+                "rejected_prediction_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ContentPart": {
+            "type": "object",
+            "properties": {
+                "image_url": {
+                    "$ref": "#/definitions/core.ImageURLContent"
+                },
+                "input_audio": {
+                    "$ref": "#/definitions/core.InputAudioContent"
+                },
+                "text": {
                     "type": "string"
                 },
-                "failover": {
-                    "description": "Failover captures runtime redirect details when translated execution\nmoved from the primary selector to a configured failover target.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/auditlog.FailoverSnapshot"
-                        }
-                    ]
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.DeferredRequestResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
                 },
-                "max_tokens": {
+                "completed_at": {
                     "type": "integer"
                 },
-                "request_body": {
-                    "description": "Optional bodies (when LOGGING_LOG_BODIES=true)\nStored as interface{} so MongoDB serializes as native BSON documents (queryable/readable)\ninstead of BSON Binary (base64 in Compass)"
+                "created_at": {
+                    "type": "integer"
                 },
-                "request_body_too_big_to_handle": {
-                    "description": "Body capture status flags (set when body exceeds 1MB limit)",
-                    "type": "boolean"
+                "endpoint": {
+                    "type": "string"
                 },
-                "request_headers": {
-                    "description": "Optional headers (when LOGGING_LOG_HEADERS=true)\nSensitive headers are auto-redacted",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "expires_at": {
+                    "type": "integer"
                 },
-                "response_body": {},
-                "response_body_too_big_to_handle": {
-                    "type": "boolean"
+                "id": {
+                    "type": "string"
                 },
-                "response_headers": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "last_error": {
+                    "description": "LastError is the error message of the most recent failed attempt.",
+                    "type": "string"
                 },
-                "temperature": {
-                    "description": "Request parameters",
-                    "type": "number"
+                "max_attempts": {
+                    "type": "integer"
                 },
-                "user_agent": {
-                    "description": "Identity",
+                "next_attempt_at": {
+                    "description": "NextAttemptAt is set while the request waits for its next replay.",
+                    "type": "integer"
+                },
+                "object": {
                     "type": "string"
                 },
-                "workflow_features": {
-                    "description": "WorkflowFeatures captures the request-time effective workflow features\nafter runtime caps were applied. This keeps audit views historically accurate\neven if the active process config changes later.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/auditlog.WorkflowFeaturesSnapshot"
-                        }
-                    ]
+                "response": {
+                    "type": "object"
+                },
+                "status": {
+                    "description": "Status is one of queued, running, succeeded, failed or expired.",
+                    "type": "string"
+                },
+                "status_code": {
+                    "description": "StatusCode and Response hold the final upstream-facing response once the\nrequest succeeded or failed permanently.",
+                    "type": "integer"
                 }
             }
         },
-        "auditlog.LogEntry": {
+        "core.EmbeddingData": {
             "type": "object",
             "properties": {
-                "alias_used": {
-                    "type": "boolean"
+                "embedding": {
+                    "type": "object"
                 },
-                "auth_key_id": {
-                    "type": "string"
+                "index": {
+                    "type": "integer"
                 },
-                "auth_method": {
+                "object": {
                     "type": "string"
+                }
+            }
+        },
+        "core.EmbeddingRequest": {
+            "type": "object",
+            "properties": {
+                "dimensions": {
+                    "type": "integer"
                 },
-                "cache_type": {
+                "encoding_format": {
                     "type": "string"
                 },
-                "client_ip": {
+                "input": {},
+                "model": {
                     "type": "string"
                 },
+                "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
+                    "type": "string"
+                }
+            }
+        },
+        "core.EmbeddingResponse": {
+            "type": "object",
+            "properties": {
                 "data": {
-                    "description": "Data contains flexible request/response information as JSON",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/auditlog.LogData"
-                        }
-                    ]
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.EmbeddingData"
+                    }
                 },
-                "duration_ns": {
-                    "description": "DurationNs is the request duration in nanoseconds",
+                "model": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/core.EmbeddingUsage"
+                }
+            }
+        },
+        "core.EmbeddingUsage": {
+            "type": "object",
+            "properties": {
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
                     "type": "integer"
+                }
+            }
+        },
+        "core.ErrorType": {
+            "type": "string",
+            "enum": [
+                "provider_error",
+                "rate_limit_error",
+                "invalid_request_error",
+                "authentication_error",
+                "not_found_error",
+                "overloaded_error",
+                "content_filter_error"
+            ],
+            "x-enum-varnames": [
+                "ErrorTypeProvider",
+                "ErrorTypeRateLimit",
+                "ErrorTypeInvalidRequest",
+                "ErrorTypeAuthentication",
+                "ErrorTypeNotFound",
+                "ErrorTypeOverloaded",
+                "ErrorTypeContentFilter"
+            ]
+        },
+        "core.FileDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.FileListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.FileObject"
+                    }
                 },
-                "error_type": {
-                    "type": "string"
+                "has_more": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "description": "ID is a unique identifier for this log entry (UUID)",
+                "object": {
                     "type": "string"
+                }
+            }
+        },
+        "core.FileObject": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
                 },
-                "method": {
-                    "type": "string"
+                "created_at": {
+                    "type": "integer"
                 },
-                "path": {
+                "filename": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "canonical provider type used for routing and filters",
+                "id": {
                     "type": "string"
                 },
-                "provider_name": {
+                "object": {
                     "type": "string"
                 },
-                "request_id": {
-                    "description": "Extracted fields for efficient filtering (indexed in relational DBs)",
+                "provider": {
+                    "description": "Gateway enrichment for multi-provider deployments.",
                     "type": "string"
                 },
-                "requested_model": {
-                    "description": "Core fields (indexed for queries)",
+                "purpose": {
                     "type": "string"
                 },
-                "resolved_model": {
+                "status": {
                     "type": "string"
                 },
-                "status_code": {
-                    "type": "integer"
-                },
-                "stream": {
-                    "type": "boolean"
-                },
-                "timestamp": {
-                    "description": "Timestamp is when the request started",
+                "status_details": {
                     "type": "string"
-                },
-                "user_path": {
+                }
+            }
+        },
+        "core.FunctionCall": {
+            "type": "object",
+            "properties": {
+                "arguments": {
                     "type": "string"
                 },
-                "workflow_version_id": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "auditlog.LogListResult": {
+        "core.GatewayError": {
             "type": "object",
             "properties": {
-                "entries": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/auditlog.LogEntry"
-                    }
+                "code": {
+                    "type": "string",
+                    "x-nullable": true
                 },
-                "limit": {
-                    "type": "integer"
+                "message": {
+                    "type": "string"
                 },
-                "offset": {
-                    "type": "integer"
+                "param": {
+                    "type": "string",
+                    "x-nullable": true
                 },
-                "total": {
+                "provider": {
+                    "type": "string"
+                },
+                "provider_error": {
+                    "description": "ProviderError is the raw error object returned by the upstream provider."
+                },
+                "status_code": {
                     "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/core.ErrorType"
                 }
             }
         },
-        "auditlog.WorkflowFeaturesSnapshot": {
+        "core.ImageURLContent": {
             "type": "object",
             "properties": {
-                "audit": {
-                    "type": "boolean"
-                },
-                "cache": {
-                    "type": "boolean"
-                },
-                "fallback": {
-                    "type": "boolean"
+                "detail": {
+                    "type": "string"
                 },
-                "guardrails": {
-                    "type": "boolean"
+                "media_type": {
+                    "type": "string"
                 },
-                "usage": {
-                    "type": "boolean"
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "core.BatchError": {
+        "core.InlineImage": {
             "type": "object",
             "properties": {
-                "message": {
+                "bytes": {
+                    "description": "Bytes is the decoded size of the image.",
+                    "type": "integer"
+                },
+                "media_type": {
                     "type": "string"
                 },
-                "type": {
+                "sha256": {
+                    "description": "SHA256 is the hex digest of the base64 payload, so identical images\nshare a hash without decoding them.",
                     "type": "string"
                 }
             }
         },
-        "core.BatchListResponse": {
+        "core.InputAudioContent": {
             "type": "object",
             "properties": {
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.BatchResponse"
-                    }
-                },
-                "first_id": {
-                    "type": "string"
-                },
-                "has_more": {
-                    "type": "boolean"
-                },
-                "last_id": {
                     "type": "string"
                 },
-                "object": {
+                "format": {
                     "type": "string"
                 }
             }
         },
-        "core.BatchRequest": {
+        "core.Message": {
             "type": "object",
             "properties": {
-                "completion_window": {
-                    "type": "string"
+                "content": {
+                    "description": "ContentSchema documents that ` + "`" + `content` + "`" + ` accepts either a plain string\nor an array of ContentPart values.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ContentPart"
+                    },
+                    "x-oneof": "[{\"type\":\"null\"},{\"type\":\"string\"},{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/core.ContentPart\"}}]"
                 },
-                "endpoint": {
+                "role": {
                     "type": "string"
                 },
-                "input_file_id": {
+                "tool_call_id": {
                     "type": "string"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "requests": {
+                "tool_calls": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.BatchRequestItem"
+                        "$ref": "#/definitions/core.ToolCall"
                     }
                 }
             }
         },
-        "core.BatchRequestCounts": {
+        "core.Model": {
             "type": "object",
             "properties": {
-                "completed": {
+                "created": {
                     "type": "integer"
                 },
-                "failed": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "metadata": {
+                    "description": "Metadata holds optional enrichment data (display name, pricing, capabilities, etc.).\nMay be nil if the model was not found in the external registry.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/core.ModelMetadata"
+                        }
+                    ]
+                },
+                "object": {
+                    "type": "string"
+                },
+                "owned_by": {
+                    "type": "string"
                 }
             }
         },
-        "core.BatchRequestItem": {
+        "core.ModelCategory": {
+            "type": "string",
+            "enum": [
+                "all",
+                "text_generation",
+                "embedding",
+                "image",
+                "audio",
+                "video",
+                "utility"
+            ],
+            "x-enum-varnames": [
+                "CategoryAll",
+                "CategoryTextGeneration",
+                "CategoryEmbedding",
+                "CategoryImage",
+                "CategoryAudio",
+                "CategoryVideo",
+                "CategoryUtility"
+            ]
+        },
+        "core.ModelMetadata": {
             "type": "object",
             "properties": {
-                "body": {
-                    "type": "object"
+                "capabilities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
                 },
-                "custom_id": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ModelCategory"
+                    }
+                },
+                "context_window": {
+                    "type": "integer"
+                },
+                "description": {
                     "type": "string"
                 },
-                "method": {
+                "display_name": {
                     "type": "string"
                 },
-                "url": {
+                "family": {
                     "type": "string"
+                },
+                "max_output_tokens": {
+                    "type": "integer"
+                },
+                "modes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pricing": {
+                    "$ref": "#/definitions/core.ModelPricing"
+                },
+                "rankings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/core.ModelRanking"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "core.BatchResponse": {
+        "core.ModelPricing": {
             "type": "object",
             "properties": {
-                "cancelled_at": {
-                    "type": "integer"
+                "audio_input_per_mtok": {
+                    "type": "number"
                 },
-                "cancelling_at": {
-                    "type": "integer"
+                "audio_output_per_mtok": {
+                    "type": "number"
+                },
+                "batch_input_per_mtok": {
+                    "type": "number"
                 },
-                "completed_at": {
-                    "type": "integer"
+                "batch_output_per_mtok": {
+                    "type": "number"
                 },
-                "completion_window": {
-                    "type": "string"
+                "cache_write_per_mtok": {
+                    "type": "number"
                 },
-                "created_at": {
-                    "type": "integer"
+                "cached_input_per_mtok": {
+                    "type": "number"
                 },
-                "endpoint": {
+                "currency": {
                     "type": "string"
                 },
-                "failed_at": {
-                    "type": "integer"
+                "input_per_image": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "string"
+                "input_per_mtok": {
+                    "type": "number"
                 },
-                "in_progress_at": {
-                    "type": "integer"
+                "output_per_mtok": {
+                    "type": "number"
                 },
-                "input_file_id": {
-                    "type": "string"
+                "per_character_input": {
+                    "type": "number"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "per_image": {
+                    "type": "number"
                 },
-                "object": {
-                    "type": "string"
+                "per_page": {
+                    "type": "number"
                 },
-                "provider": {
-                    "type": "string"
+                "per_request": {
+                    "type": "number"
                 },
-                "provider_batch_id": {
-                    "type": "string"
+                "per_second_input": {
+                    "type": "number"
                 },
-                "request_counts": {
-                    "$ref": "#/definitions/core.BatchRequestCounts"
+                "per_second_output": {
+                    "type": "number"
                 },
-                "results": {
+                "reasoning_output_per_mtok": {
+                    "type": "number"
+                },
+                "tiers": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.BatchResultItem"
+                        "$ref": "#/definitions/core.ModelPricingTier"
                     }
-                },
-                "status": {
-                    "type": "string"
-                },
-                "usage": {
-                    "description": "Gateway extension: optional usage/result snapshots persisted by the gateway.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/core.BatchUsageSummary"
-                        }
-                    ]
                 }
             }
         },
-        "core.BatchResultItem": {
+        "core.ModelPricingTier": {
             "type": "object",
             "properties": {
-                "custom_id": {
-                    "type": "string"
-                },
-                "error": {
-                    "$ref": "#/definitions/core.BatchError"
+                "input_per_mtok": {
+                    "type": "number"
                 },
-                "index": {
-                    "type": "integer"
+                "output_per_mtok": {
+                    "type": "number"
                 },
-                "model": {
+                "up_to_mtok": {
+                    "type": "number"
+                }
+            }
+        },
+        "core.ModelRanking": {
+            "type": "object",
+            "properties": {
+                "as_of": {
                     "type": "string"
                 },
-                "provider": {
-                    "type": "string"
+                "elo": {
+                    "type": "number"
                 },
-                "response": {},
-                "status_code": {
+                "rank": {
                     "type": "integer"
-                },
-                "url": {
-                    "type": "string"
                 }
             }
         },
-        "core.BatchResultsResponse": {
+        "core.ModelsResponse": {
             "type": "object",
             "properties": {
-                "batch_id": {
-                    "type": "string"
-                },
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.BatchResultItem"
+                        "$ref": "#/definitions/core.Model"
                     }
                 },
                 "object": {
@@ -3024,1055 +7690,1054 @@ const docTemplate = `{
                 }
             }
         },
-        "core.BatchUsageSummary": {
+        "core.OpenAIErrorEnvelope": {
             "type": "object",
+            "required": [
+                "error"
+            ],
             "properties": {
-                "input_cost": {
-                    "type": "number"
-                },
-                "input_tokens": {
-                    "type": "integer"
-                },
-                "output_cost": {
-                    "type": "number"
-                },
-                "output_tokens": {
-                    "type": "integer"
-                },
-                "total_cost": {
-                    "type": "number"
-                },
-                "total_tokens": {
-                    "type": "integer"
+                "error": {
+                    "$ref": "#/definitions/core.OpenAIErrorObject"
                 }
             }
         },
-        "core.ChatRequest": {
+        "core.OpenAIErrorObject": {
             "type": "object",
+            "required": [
+                "code",
+                "message",
+                "param",
+                "type"
+            ],
             "properties": {
-                "max_tokens": {
-                    "type": "integer"
-                },
-                "messages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.Message"
-                    }
-                },
-                "model": {
-                    "type": "string"
-                },
-                "parallel_tool_calls": {
-                    "type": "boolean"
+                "code": {
+                    "type": "string",
+                    "x-nullable": true
                 },
-                "provider": {
-                    "description": "Gateway routing hint; stripped before upstream execution.",
+                "message": {
                     "type": "string"
                 },
-                "reasoning": {
-                    "$ref": "#/definitions/core.Reasoning"
-                },
-                "stream": {
-                    "type": "boolean"
-                },
-                "stream_options": {
-                    "$ref": "#/definitions/core.StreamOptions"
-                },
-                "temperature": {
-                    "type": "number"
+                "param": {
+                    "type": "string",
+                    "x-nullable": true
                 },
-                "tool_choice": {
-                    "description": "string or object"
+                "provider_error": {
+                    "description": "ProviderError is the raw upstream error object, present when the error came from a provider."
                 },
-                "tools": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": {}
-                    }
+                "type": {
+                    "$ref": "#/definitions/core.ErrorType"
                 }
             }
         },
-        "core.ChatResponse": {
+        "core.PromptTokensDetails": {
             "type": "object",
             "properties": {
-                "choices": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.Choice"
-                    }
-                },
-                "created": {
+                "audio_tokens": {
                     "type": "integer"
                 },
-                "id": {
-                    "type": "string"
-                },
-                "model": {
-                    "type": "string"
-                },
-                "object": {
-                    "type": "string"
-                },
-                "provider": {
-                    "type": "string"
+                "cached_tokens": {
+                    "type": "integer"
                 },
-                "system_fingerprint": {
-                    "type": "string"
+                "image_tokens": {
+                    "type": "integer"
                 },
-                "usage": {
-                    "$ref": "#/definitions/core.Usage"
+                "text_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "core.Choice": {
+        "core.Reasoning": {
             "type": "object",
             "properties": {
-                "finish_reason": {
+                "effort": {
+                    "description": "Effort controls how much reasoning effort the model should use.\nValid values are \"low\", \"medium\", and \"high\".",
                     "type": "string"
-                },
-                "index": {
-                    "type": "integer"
-                },
-                "logprobs": {
-                    "type": "object"
-                },
-                "message": {
-                    "$ref": "#/definitions/core.ResponseMessage"
                 }
             }
         },
-        "core.CompletionTokensDetails": {
+        "core.ResponseCompactRequest": {
             "type": "object",
             "properties": {
-                "accepted_prediction_tokens": {
-                    "type": "integer"
+                "input": {
+                    "description": "string or []ResponsesInputElement — see docs for array form"
                 },
-                "audio_tokens": {
-                    "type": "integer"
+                "instructions": {
+                    "type": "string"
                 },
-                "reasoning_tokens": {
-                    "type": "integer"
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
+                    "type": "string"
                 },
-                "rejected_prediction_tokens": {
-                    "type": "integer"
+                "reasoning": {
+                    "$ref": "#/definitions/core.Reasoning"
                 }
             }
         },
-        "core.ContentPart": {
+        "core.ResponseCompactResponse": {
             "type": "object",
             "properties": {
-                "image_url": {
-                    "$ref": "#/definitions/core.ImageURLContent"
+                "created_at": {
+                    "type": "integer"
                 },
-                "input_audio": {
-                    "$ref": "#/definitions/core.InputAudioContent"
+                "error": {
+                    "$ref": "#/definitions/core.ResponsesError"
                 },
-                "text": {
+                "id": {
                     "type": "string"
                 },
-                "type": {
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "object": {
+                    "type": "string"
+                },
+                "output": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ResponsesOutputItem"
+                    }
+                },
+                "provider": {
                     "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/core.ResponsesUsage"
                 }
             }
         },
-        "core.EmbeddingData": {
+        "core.ResponseDeleteResponse": {
             "type": "object",
             "properties": {
-                "embedding": {
-                    "type": "object"
+                "deleted": {
+                    "type": "boolean"
                 },
-                "index": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
                 "object": {
                     "type": "string"
                 }
             }
         },
-        "core.EmbeddingRequest": {
+        "core.ResponseInputItemListResponse": {
             "type": "object",
             "properties": {
-                "dimensions": {
-                    "type": "integer"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
                 },
-                "encoding_format": {
+                "first_id": {
                     "type": "string"
                 },
-                "input": {},
-                "model": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "last_id": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "Gateway routing hint; stripped before upstream execution.",
+                "object": {
                     "type": "string"
                 }
             }
         },
-        "core.EmbeddingResponse": {
+        "core.ResponseInputTokensRequest": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.EmbeddingData"
-                    }
+                "input": {
+                    "description": "string or []ResponsesInputElement — see docs for array form"
                 },
-                "model": {
+                "instructions": {
                     "type": "string"
                 },
-                "object": {
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "model": {
                     "type": "string"
                 },
                 "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
                     "type": "string"
                 },
-                "usage": {
-                    "$ref": "#/definitions/core.EmbeddingUsage"
+                "reasoning": {
+                    "$ref": "#/definitions/core.Reasoning"
                 }
             }
         },
-        "core.EmbeddingUsage": {
+        "core.ResponseInputTokensResponse": {
             "type": "object",
             "properties": {
-                "prompt_tokens": {
+                "input_tokens": {
                     "type": "integer"
                 },
-                "total_tokens": {
-                    "type": "integer"
+                "object": {
+                    "type": "string"
                 }
             }
         },
-        "core.ErrorType": {
-            "type": "string",
-            "enum": [
-                "provider_error",
-                "rate_limit_error",
-                "invalid_request_error",
-                "authentication_error",
-                "not_found_error"
-            ],
-            "x-enum-varnames": [
-                "ErrorTypeProvider",
-                "ErrorTypeRateLimit",
-                "ErrorTypeInvalidRequest",
-                "ErrorTypeAuthentication",
-                "ErrorTypeNotFound"
-            ]
-        },
-        "core.FileDeleteResponse": {
+        "core.ResponseMessage": {
             "type": "object",
             "properties": {
-                "deleted": {
-                    "type": "boolean"
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ContentPart"
+                    },
+                    "x-oneof": "[{\"type\":\"null\"},{\"type\":\"string\"},{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/core.ContentPart\"}}]"
                 },
-                "id": {
+                "role": {
                     "type": "string"
                 },
-                "object": {
-                    "type": "string"
+                "tool_calls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ToolCall"
+                    }
                 }
             }
         },
-        "core.FileListResponse": {
+        "core.ResponsesContentItem": {
             "type": "object",
             "properties": {
-                "data": {
+                "annotations": {
+                    "description": "Providers can return structured annotation objects here (for example\ncitations from native tools), so keep the payload shape liberal.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.FileObject"
+                        "type": "object"
                     }
                 },
-                "has_more": {
-                    "type": "boolean"
+                "image_url": {
+                    "$ref": "#/definitions/core.ImageURLContent"
                 },
-                "object": {
+                "input_audio": {
+                    "$ref": "#/definitions/core.InputAudioContent"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "\"output_text\", \"input_image\", \"input_audio\", etc.",
                     "type": "string"
                 }
             }
         },
-        "core.FileObject": {
+        "core.ResponsesError": {
             "type": "object",
             "properties": {
-                "bytes": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "integer"
+                "code": {
+                    "type": "string"
                 },
-                "filename": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ResponsesOutputItem": {
+            "type": "object",
+            "properties": {
+                "arguments": {
                     "type": "string"
                 },
-                "id": {
+                "call_id": {
                     "type": "string"
                 },
-                "object": {
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ResponsesContentItem"
+                    }
+                },
+                "id": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "Gateway enrichment for multi-provider deployments.",
+                "name": {
                     "type": "string"
                 },
-                "purpose": {
+                "role": {
                     "type": "string"
                 },
                 "status": {
                     "type": "string"
                 },
-                "status_details": {
+                "type": {
+                    "description": "\"message\", \"function_call\", etc.",
                     "type": "string"
                 }
             }
         },
-        "core.FunctionCall": {
+        "core.ResponsesRequest": {
             "type": "object",
             "properties": {
-                "arguments": {
+                "input": {
+                    "description": "string or []ResponsesInputElement — see docs for array form"
+                },
+                "instructions": {
                     "type": "string"
                 },
-                "name": {
+                "max_output_tokens": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "model": {
                     "type": "string"
+                },
+                "parallel_tool_calls": {
+                    "type": "boolean"
+                },
+                "provider": {
+                    "description": "Gateway routing hint; stripped before upstream execution.",
+                    "type": "string"
+                },
+                "reasoning": {
+                    "$ref": "#/definitions/core.Reasoning"
+                },
+                "stream": {
+                    "type": "boolean"
+                },
+                "stream_options": {
+                    "$ref": "#/definitions/core.StreamOptions"
+                },
+                "temperature": {
+                    "type": "number"
+                },
+                "tool_choice": {
+                    "description": "string or object"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "additionalProperties": {}
+                    }
                 }
             }
         },
-        "core.GatewayError": {
+        "core.ResponsesResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string",
-                    "x-nullable": true
+                "created_at": {
+                    "type": "integer"
                 },
-                "message": {
+                "error": {
+                    "$ref": "#/definitions/core.ResponsesError"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "object": {
+                    "description": "\"response\"",
                     "type": "string"
                 },
-                "param": {
-                    "type": "string",
-                    "x-nullable": true
+                "output": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ResponsesOutputItem"
+                    }
                 },
                 "provider": {
                     "type": "string"
                 },
-                "status_code": {
-                    "type": "integer"
+                "status": {
+                    "description": "\"completed\", \"failed\", \"in_progress\"",
+                    "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/core.ErrorType"
+                "usage": {
+                    "$ref": "#/definitions/core.ResponsesUsage"
                 }
             }
         },
-        "core.ImageURLContent": {
+        "core.ResponsesUsage": {
             "type": "object",
             "properties": {
-                "detail": {
-                    "type": "string"
+                "completion_tokens_details": {
+                    "$ref": "#/definitions/core.CompletionTokensDetails"
                 },
-                "media_type": {
-                    "type": "string"
+                "input_tokens": {
+                    "type": "integer"
                 },
-                "url": {
-                    "type": "string"
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "prompt_tokens_details": {
+                    "$ref": "#/definitions/core.PromptTokensDetails"
+                },
+                "raw_usage": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "core.InputAudioContent": {
+        "core.StreamOptions": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "string"
-                },
-                "format": {
-                    "type": "string"
+                "include_usage": {
+                    "description": "IncludeUsage requests token usage information in streaming responses.\nWhen true, the final streaming chunk will include usage statistics.",
+                    "type": "boolean"
                 }
             }
         },
-        "core.Message": {
+        "core.ToolCall": {
             "type": "object",
             "properties": {
-                "content": {
-                    "description": "ContentSchema documents that ` + "`" + `content` + "`" + ` accepts either a plain string\nor an array of ContentPart values.",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ContentPart"
-                    },
-                    "x-oneof": "[{\"type\":\"null\"},{\"type\":\"string\"},{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/core.ContentPart\"}}]"
+                "function": {
+                    "$ref": "#/definitions/core.FunctionCall"
                 },
-                "role": {
+                "id": {
                     "type": "string"
                 },
-                "tool_call_id": {
+                "type": {
                     "type": "string"
-                },
-                "tool_calls": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ToolCall"
-                    }
                 }
             }
         },
-        "core.Model": {
+        "core.Usage": {
             "type": "object",
             "properties": {
-                "created": {
+                "completion_tokens": {
                     "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "completion_tokens_details": {
+                    "$ref": "#/definitions/core.CompletionTokensDetails"
                 },
-                "metadata": {
-                    "description": "Metadata holds optional enrichment data (display name, pricing, capabilities, etc.).\nMay be nil if the model was not found in the external registry.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/core.ModelMetadata"
-                        }
-                    ]
+                "prompt_tokens": {
+                    "type": "integer"
                 },
-                "object": {
-                    "type": "string"
+                "prompt_tokens_details": {
+                    "$ref": "#/definitions/core.PromptTokensDetails"
                 },
-                "owned_by": {
-                    "type": "string"
+                "raw_usage": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "core.ModelCategory": {
-            "type": "string",
-            "enum": [
-                "all",
-                "text_generation",
-                "embedding",
-                "image",
-                "audio",
-                "video",
-                "utility"
-            ],
-            "x-enum-varnames": [
-                "CategoryAll",
-                "CategoryTextGeneration",
-                "CategoryEmbedding",
-                "CategoryImage",
-                "CategoryAudio",
-                "CategoryVideo",
-                "CategoryUtility"
-            ]
-        },
-        "core.ModelMetadata": {
+        "gomodel_internal_cache_modelcache.CacheDirReport": {
             "type": "object",
             "properties": {
-                "capabilities": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "boolean"
-                    }
-                },
-                "categories": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ModelCategory"
-                    }
-                },
-                "context_window": {
-                    "type": "integer"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "display_name": {
+                "current_file": {
                     "type": "string"
                 },
-                "family": {
+                "dir": {
                     "type": "string"
                 },
-                "max_output_tokens": {
-                    "type": "integer"
-                },
-                "modes": {
+                "files": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/gomodel_internal_cache_modelcache.CacheFile"
                     }
                 },
-                "pricing": {
-                    "$ref": "#/definitions/core.ModelPricing"
+                "max_age_seconds": {
+                    "type": "integer"
                 },
-                "rankings": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/core.ModelRanking"
-                    }
+                "max_bytes": {
+                    "type": "integer"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "schema_version": {
+                    "type": "integer"
+                },
+                "total_bytes": {
+                    "type": "integer"
                 }
             }
         },
-        "core.ModelPricing": {
+        "gomodel_internal_cache_modelcache.CacheFile": {
             "type": "object",
             "properties": {
-                "audio_input_per_mtok": {
-                    "type": "number"
-                },
-                "audio_output_per_mtok": {
-                    "type": "number"
-                },
-                "batch_input_per_mtok": {
-                    "type": "number"
-                },
-                "batch_output_per_mtok": {
-                    "type": "number"
-                },
-                "cache_write_per_mtok": {
-                    "type": "number"
+                "age_seconds": {
+                    "type": "integer"
                 },
-                "cached_input_per_mtok": {
-                    "type": "number"
+                "current": {
+                    "description": "Current marks the model cache file the gateway reads and writes.",
+                    "type": "boolean"
                 },
-                "currency": {
+                "modified_at": {
                     "type": "string"
                 },
-                "input_per_image": {
-                    "type": "number"
-                },
-                "input_per_mtok": {
-                    "type": "number"
-                },
-                "output_per_mtok": {
-                    "type": "number"
-                },
-                "per_character_input": {
-                    "type": "number"
-                },
-                "per_image": {
-                    "type": "number"
-                },
-                "per_page": {
-                    "type": "number"
-                },
-                "per_request": {
-                    "type": "number"
-                },
-                "per_second_input": {
-                    "type": "number"
-                },
-                "per_second_output": {
-                    "type": "number"
+                "name": {
+                    "type": "string"
                 },
-                "reasoning_output_per_mtok": {
-                    "type": "number"
+                "schema_version": {
+                    "description": "SchemaVersion is the version in the file name; 0 for temp files and the\nlegacy unversioned models.json.",
+                    "type": "integer"
                 },
-                "tiers": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ModelPricingTier"
-                    }
+                "size_bytes": {
+                    "type": "integer"
                 }
             }
         },
-        "core.ModelPricingTier": {
+        "gomodel_internal_cache_modelcache.RemovedCacheFile": {
             "type": "object",
             "properties": {
-                "input_per_mtok": {
-                    "type": "number"
+                "age_seconds": {
+                    "type": "integer"
                 },
-                "output_per_mtok": {
-                    "type": "number"
+                "current": {
+                    "description": "Current marks the model cache file the gateway reads and writes.",
+                    "type": "boolean"
                 },
-                "up_to_mtok": {
-                    "type": "number"
-                }
-            }
-        },
-        "core.ModelRanking": {
-            "type": "object",
-            "properties": {
-                "as_of": {
+                "modified_at": {
                     "type": "string"
                 },
-                "elo": {
-                    "type": "number"
+                "name": {
+                    "type": "string"
                 },
-                "rank": {
+                "reason": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "description": "SchemaVersion is the version in the file name; 0 for temp files and the\nlegacy unversioned models.json.",
                     "type": "integer"
-                }
-            }
-        },
-        "core.ModelsResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.Model"
-                    }
                 },
-                "object": {
-                    "type": "string"
+                "size_bytes": {
+                    "type": "integer"
                 }
             }
         },
-        "core.OpenAIErrorEnvelope": {
+        "logging.LevelsSnapshot": {
             "type": "object",
-            "required": [
-                "error"
-            ],
             "properties": {
-                "error": {
-                    "$ref": "#/definitions/core.OpenAIErrorObject"
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "level": {
+                    "type": "string"
                 }
             }
         },
-        "core.OpenAIErrorObject": {
+        "maintenance.State": {
             "type": "object",
-            "required": [
-                "code",
-                "message",
-                "param",
-                "type"
-            ],
             "properties": {
-                "code": {
-                    "type": "string",
-                    "x-nullable": true
+                "active": {
+                    "type": "boolean"
+                },
+                "in_flight": {
+                    "description": "InFlight is the number of model requests still running.",
+                    "type": "integer"
                 },
                 "message": {
                     "type": "string"
                 },
-                "param": {
-                    "type": "string",
-                    "x-nullable": true
+                "since": {
+                    "type": "string"
                 },
-                "type": {
-                    "$ref": "#/definitions/core.ErrorType"
+                "until": {
+                    "description": "Until is when an activation with a duration ends by itself.",
+                    "type": "string"
                 }
             }
         },
-        "core.PromptTokensDetails": {
+        "modelgroups.MemberStatus": {
             "type": "object",
             "properties": {
-                "audio_tokens": {
-                    "type": "integer"
+                "eligible": {
+                    "type": "boolean"
                 },
-                "cached_tokens": {
-                    "type": "integer"
+                "max_cost_per_1k": {
+                    "type": "number"
                 },
-                "image_tokens": {
+                "max_latency_ms": {
                     "type": "integer"
                 },
-                "text_tokens": {
+                "model": {
+                    "type": "string"
+                },
+                "provider_name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "selections": {
                     "type": "integer"
                 }
             }
         },
-        "core.Reasoning": {
+        "modelgroups.Resolution": {
             "type": "object",
             "properties": {
-                "effort": {
-                    "description": "Effort controls how much reasoning effort the model should use.\nValid values are \"low\", \"medium\", and \"high\".",
+                "fallback": {
+                    "description": "Fallback is set when no member is eligible and Selected is the first\nmember regardless.",
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/modelgroups.MemberStatus"
+                    }
+                },
+                "selected": {
+                    "description": "Selected is the member requests for the group go to right now.",
                     "type": "string"
                 }
             }
         },
-        "core.ResponseCompactRequest": {
+        "pipelinestats.Snapshot": {
             "type": "object",
             "properties": {
-                "input": {
-                    "description": "string or []ResponsesInputElement — see docs for array form"
+                "buffer_size": {
+                    "type": "integer"
                 },
-                "instructions": {
-                    "type": "string"
+                "buffered": {
+                    "description": "Buffered is the number of entries waiting in the in-memory buffer and\nBufferSize its capacity.",
+                    "type": "integer"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "dropped": {
+                    "type": "integer"
                 },
-                "model": {
+                "flushes": {
+                    "type": "integer"
+                },
+                "lag_threshold_seconds": {
+                    "description": "LagThresholdSeconds is the configured write lag warning bound, 0 when\nlag warnings are disabled.",
+                    "type": "number"
+                },
+                "lagging": {
+                    "description": "Lagging reports whether OldestPendingSeconds exceeds the threshold.",
+                    "type": "boolean"
+                },
+                "last_flush_at": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "Gateway routing hint; stripped before upstream execution.",
+                "last_flush_error": {
                     "type": "string"
                 },
-                "reasoning": {
-                    "$ref": "#/definitions/core.Reasoning"
+                "last_flush_error_active": {
+                    "type": "boolean"
+                },
+                "last_flush_error_at": {
+                    "type": "string"
+                },
+                "last_flush_seconds": {
+                    "type": "number"
+                },
+                "oldest_pending_seconds": {
+                    "description": "OldestPendingSeconds is how long the oldest entry not yet written to\nthe store has been waiting, 0 when nothing is pending.",
+                    "type": "number"
+                },
+                "spilled": {
+                    "description": "Spilled is the number of entries waiting in an on-disk spill queue.",
+                    "type": "integer"
+                },
+                "written": {
+                    "type": "integer"
                 }
             }
         },
-        "core.ResponseCompactResponse": {
+        "promptcache.Counts": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "cache_write_tokens": {
                     "type": "integer"
                 },
-                "error": {
-                    "$ref": "#/definitions/core.ResponsesError"
+                "cached_tokens": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "estimated_savings_usd": {
+                    "description": "EstimatedSavingsUSD is the input cost saved by cache reads minus the\ncache write surcharge, for models with known pricing.",
+                    "type": "number"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "hit_rate": {
+                    "type": "number"
                 },
-                "object": {
-                    "type": "string"
+                "hits": {
+                    "description": "Hits counts observed requests that read the prefix from the cache.",
+                    "type": "integer"
                 },
-                "output": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ResponsesOutputItem"
-                    }
+                "input_tokens": {
+                    "type": "integer"
                 },
-                "provider": {
-                    "type": "string"
+                "observed": {
+                    "description": "Observed counts those requests whose usage was recorded.",
+                    "type": "integer"
                 },
-                "usage": {
-                    "$ref": "#/definitions/core.ResponsesUsage"
+                "requests": {
+                    "description": "Requests counts requests sent with the prefix marked cacheable.",
+                    "type": "integer"
                 }
             }
         },
-        "core.ResponseDeleteResponse": {
+        "promptcache.PrefixStats": {
             "type": "object",
             "properties": {
-                "deleted": {
-                    "type": "boolean"
+                "cache_write_tokens": {
+                    "type": "integer"
                 },
-                "id": {
+                "cached_tokens": {
+                    "type": "integer"
+                },
+                "chars": {
+                    "type": "integer"
+                },
+                "estimated_savings_usd": {
+                    "description": "EstimatedSavingsUSD is the input cost saved by cache reads minus the\ncache write surcharge, for models with known pricing.",
+                    "type": "number"
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "description": "Hits counts observed requests that read the prefix from the cache.",
+                    "type": "integer"
+                },
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "key": {
                     "type": "string"
                 },
-                "object": {
+                "last_seen": {
+                    "type": "string"
+                },
+                "observed": {
+                    "description": "Observed counts those requests whose usage was recorded.",
+                    "type": "integer"
+                },
+                "preview": {
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "Requests counts requests sent with the prefix marked cacheable.",
+                    "type": "integer"
+                },
+                "source": {
                     "type": "string"
                 }
             }
         },
-        "core.ResponseInputItemListResponse": {
+        "promptcache.Report": {
             "type": "object",
             "properties": {
-                "data": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "prefixes": {
                     "type": "array",
                     "items": {
-                        "type": "object"
+                        "$ref": "#/definitions/promptcache.PrefixStats"
                     }
                 },
-                "first_id": {
-                    "type": "string"
-                },
-                "has_more": {
-                    "type": "boolean"
-                },
-                "last_id": {
+                "totals": {
+                    "$ref": "#/definitions/promptcache.Counts"
+                }
+            }
+        },
+        "prompttemplates.Message": {
+            "type": "object",
+            "properties": {
+                "content": {
                     "type": "string"
                 },
-                "object": {
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "core.ResponseInputTokensRequest": {
+        "prompttemplates.Template": {
             "type": "object",
             "properties": {
-                "input": {
-                    "description": "string or []ResponsesInputElement — see docs for array form"
+                "created_at": {
+                    "type": "string"
                 },
-                "instructions": {
+                "description": {
                     "type": "string"
                 },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/prompttemplates.Message"
                     }
                 },
-                "model": {
+                "name": {
                     "type": "string"
                 },
-                "provider": {
-                    "description": "Gateway routing hint; stripped before upstream execution.",
-                    "type": "string"
+                "variables": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/prompttemplates.Variable"
+                    }
                 },
-                "reasoning": {
-                    "$ref": "#/definitions/core.Reasoning"
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "core.ResponseInputTokensResponse": {
+        "prompttemplates.Variable": {
             "type": "object",
             "properties": {
-                "input_tokens": {
-                    "type": "integer"
+                "default": {},
+                "description": {
+                    "type": "string"
                 },
-                "object": {
+                "name": {
                     "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
                 }
             }
         },
-        "core.ResponseMessage": {
+        "prompttemplates.View": {
             "type": "object",
             "properties": {
-                "content": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "messages": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.ContentPart"
-                    },
-                    "x-oneof": "[{\"type\":\"null\"},{\"type\":\"string\"},{\"type\":\"array\",\"items\":{\"$ref\":\"#/definitions/core.ContentPart\"}}]"
+                        "$ref": "#/definitions/prompttemplates.Message"
+                    }
                 },
-                "role": {
+                "name": {
                     "type": "string"
                 },
-                "tool_calls": {
+                "variables": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/core.ToolCall"
+                        "$ref": "#/definitions/prompttemplates.Variable"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
                     }
                 }
             }
         },
-        "core.ResponsesContentItem": {
+        "provenance.Provenance": {
             "type": "object",
             "properties": {
-                "annotations": {
-                    "description": "Providers can return structured annotation objects here (for example\ncitations from native tools), so keep the payload shape liberal.",
-                    "type": "array",
-                    "items": {
-                        "type": "object"
-                    }
+                "content_sha256": {
+                    "type": "string"
                 },
-                "image_url": {
-                    "$ref": "#/definitions/core.ImageURLContent"
+                "model": {
+                    "type": "string"
                 },
-                "input_audio": {
-                    "$ref": "#/definitions/core.InputAudioContent"
+                "provider": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
                 },
-                "text": {
+                "signature": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "\"output_text\", \"input_image\", \"input_audio\", etc.",
+                "timestamp": {
                     "type": "string"
                 }
             }
         },
-        "core.ResponsesError": {
+        "provenance.Result": {
             "type": "object",
             "properties": {
-                "code": {
+                "reason": {
                     "type": "string"
                 },
-                "message": {
-                    "type": "string"
+                "valid": {
+                    "type": "boolean"
                 }
             }
         },
-        "core.ResponsesOutputItem": {
+        "providers.CategoryCount": {
             "type": "object",
             "properties": {
-                "arguments": {
-                    "type": "string"
-                },
-                "call_id": {
-                    "type": "string"
-                },
-                "content": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ResponsesContentItem"
-                    }
-                },
-                "id": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "role": {
-                    "type": "string"
+                "category": {
+                    "$ref": "#/definitions/core.ModelCategory"
                 },
-                "status": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
                 },
-                "type": {
-                    "description": "\"message\", \"function_call\", etc.",
+                "display_name": {
                     "type": "string"
                 }
             }
         },
-        "core.ResponsesRequest": {
+        "sanitize.Mapping": {
             "type": "object",
             "properties": {
-                "input": {
-                    "description": "string or []ResponsesInputElement — see docs for array form"
-                },
-                "instructions": {
+                "created_at": {
                     "type": "string"
                 },
-                "max_output_tokens": {
-                    "type": "integer"
-                },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "gateway_id": {
+                    "type": "string"
                 },
                 "model": {
                     "type": "string"
                 },
-                "parallel_tool_calls": {
-                    "type": "boolean"
-                },
                 "provider": {
-                    "description": "Gateway routing hint; stripped before upstream execution.",
                     "type": "string"
                 },
-                "reasoning": {
-                    "$ref": "#/definitions/core.Reasoning"
-                },
-                "stream": {
-                    "type": "boolean"
-                },
-                "stream_options": {
-                    "$ref": "#/definitions/core.StreamOptions"
-                },
-                "temperature": {
-                    "type": "number"
-                },
-                "tool_choice": {
-                    "description": "string or object"
+                "provider_id": {
+                    "type": "string"
                 },
-                "tools": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "additionalProperties": {}
-                    }
+                "request_id": {
+                    "type": "string"
                 }
             }
         },
-        "core.ResponsesResponse": {
+        "scoreboard.ModelStats": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "integer"
+                "error_rate": {
+                    "type": "number"
                 },
-                "error": {
-                    "$ref": "#/definitions/core.ResponsesError"
+                "errors": {
+                    "type": "integer"
                 },
-                "id": {
+                "last_seen": {
                     "type": "string"
                 },
-                "model": {
-                    "type": "string"
+                "latency_p50_ms": {
+                    "type": "number"
                 },
-                "object": {
-                    "description": "\"response\"",
-                    "type": "string"
+                "latency_p95_ms": {
+                    "type": "number"
                 },
-                "output": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/core.ResponsesOutputItem"
-                    }
+                "model": {
+                    "type": "string"
                 },
                 "provider": {
                     "type": "string"
                 },
-                "status": {
-                    "description": "\"completed\", \"failed\", \"in_progress\"",
+                "provider_type": {
                     "type": "string"
                 },
-                "usage": {
-                    "$ref": "#/definitions/core.ResponsesUsage"
-                }
-            }
-        },
-        "core.ResponsesUsage": {
-            "type": "object",
-            "properties": {
-                "completion_tokens_details": {
-                    "$ref": "#/definitions/core.CompletionTokensDetails"
+                "requests": {
+                    "type": "integer"
                 },
-                "input_tokens": {
+                "stall_rate": {
+                    "type": "number"
+                },
+                "stalled_streams": {
                     "type": "integer"
                 },
-                "output_tokens": {
+                "streams": {
                     "type": "integer"
                 },
-                "prompt_tokens_details": {
-                    "$ref": "#/definitions/core.PromptTokensDetails"
+                "tokens_per_second": {
+                    "type": "number"
                 },
-                "raw_usage": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "ttft_p50_ms": {
+                    "type": "number"
                 },
-                "total_tokens": {
-                    "type": "integer"
+                "ttft_p95_ms": {
+                    "type": "number"
                 }
             }
         },
-        "core.StreamOptions": {
+        "scoreboard.Snapshot": {
             "type": "object",
             "properties": {
-                "include_usage": {
-                    "description": "IncludeUsage requests token usage information in streaming responses.\nWhen true, the final streaming chunk will include usage statistics.",
-                    "type": "boolean"
+                "generated_at": {
+                    "type": "string"
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scoreboard.ModelStats"
+                    }
+                },
+                "window": {
+                    "$ref": "#/definitions/scoreboard.Window"
                 }
             }
         },
-        "core.ToolCall": {
+        "scoreboard.Window": {
+            "type": "string",
+            "enum": [
+                "5m",
+                "1h",
+                "24h",
+                "5m"
+            ],
+            "x-enum-varnames": [
+                "Window5m",
+                "Window1h",
+                "Window24h",
+                "DefaultWindow"
+            ]
+        },
+        "server.healthResponse": {
             "type": "object",
             "properties": {
-                "function": {
-                    "$ref": "#/definitions/core.FunctionCall"
-                },
-                "id": {
-                    "type": "string"
+                "maintenance": {
+                    "$ref": "#/definitions/maintenance.State"
                 },
-                "type": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "core.Usage": {
+        "server.keyUsageResponse": {
             "type": "object",
             "properties": {
-                "completion_tokens": {
-                    "type": "integer"
-                },
-                "completion_tokens_details": {
-                    "$ref": "#/definitions/core.CompletionTokensDetails"
-                },
-                "prompt_tokens": {
-                    "type": "integer"
+                "auth_key_id": {
+                    "type": "string"
                 },
-                "prompt_tokens_details": {
-                    "$ref": "#/definitions/core.PromptTokensDetails"
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/usage.DailyUsage"
+                    }
                 },
-                "raw_usage": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "end_date": {
+                    "type": "string"
                 },
-                "total_tokens": {
-                    "type": "integer"
-                }
-            }
-        },
-        "providers.CategoryCount": {
-            "type": "object",
-            "properties": {
-                "category": {
-                    "$ref": "#/definitions/core.ModelCategory"
+                "object": {
+                    "type": "string"
                 },
-                "count": {
-                    "type": "integer"
+                "start_date": {
+                    "type": "string"
                 },
-                "display_name": {
+                "status": {
                     "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/usage.UsageSummary"
                 }
             }
         },
@@ -4174,6 +8839,41 @@ const docTemplate = `{
                 }
             }
         },
+        "usage.LabelUsage": {
+            "type": "object",
+            "properties": {
+                "input_cost": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "output_cost": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_cost": {
+                    "type": "number",
+                    "x-nullable": true
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "usage.ModelUsage": {
             "type": "object",
             "properties": {
@@ -4206,6 +8906,9 @@ const docTemplate = `{
         "usage.UsageLogEntry": {
             "type": "object",
             "properties": {
+                "auth_key_id": {
+                    "type": "string"
+                },
                 "cache_type": {
                     "type": "string"
                 },
@@ -4218,12 +8921,24 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "image_bytes": {
+                    "type": "integer"
+                },
+                "image_count": {
+                    "type": "integer"
+                },
                 "input_cost": {
                     "type": "number"
                 },
                 "input_tokens": {
                     "type": "integer"
                 },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "model": {
                     "type": "string"
                 },
@@ -4249,6 +8964,18 @@ const docTemplate = `{
                 "request_id": {
                     "type": "string"
                 },
+                "requested_model": {
+                    "type": "string"
+                },
+                "served_model": {
+                    "type": "string"
+                },
+                "template_name": {
+                    "type": "string"
+                },
+                "template_version": {
+                    "type": "integer"
+                },
                 "timestamp": {
                     "type": "string"
                 },
@@ -4258,6 +8985,12 @@ const docTemplate = `{
                 "total_tokens": {
                     "type": "integer"
                 },
+                "user": {
+                    "type": "string"
+                },
+                "user_hash": {
+                    "type": "string"
+                },
                 "user_path": {
                     "type": "string"
                 }
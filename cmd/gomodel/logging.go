@@ -1,54 +1,46 @@
 package main
 
 import (
-	"fmt"
 	"io"
-	"log/slog"
-	"os"
-	"strings"
-	"time"
 
-	"github.com/lmittmann/tint"
+	"gomodel/config"
+	"gomodel/internal/logging"
 )
 
-const (
-	envLogFormat = "LOG_FORMAT"
-	envLogLevel  = "LOG_LEVEL"
-)
+// configureBootstrapLogging installs a logger for the messages written before
+// the config is loaded, such as config errors. It only honours LOG_FORMAT, so
+// those messages already use the final output format.
+func configureBootstrapLogging(w io.Writer, isTTY bool, getenv func(string) string) {
+	logging.Configure(w, isTTY, logging.Options{Format: getenv("LOG_FORMAT")})
+}
 
-func configureLogging(w io.Writer, isTTY bool) error {
-	level, err := parseLogLevel(os.Getenv(envLogLevel))
+// configureLogging installs the process logger from the logging section of the
+// loaded config.
+func configureLogging(w io.Writer, isTTY bool, cfg config.LogConfig) error {
+	opts, err := logOptions(cfg)
 	if err != nil {
 		return err
 	}
 
-	slog.SetDefault(slog.New(newLogHandler(w, isTTY, os.Getenv(envLogFormat), level)))
+	logging.Configure(w, isTTY, opts)
 	return nil
 }
 
-func newLogHandler(w io.Writer, isTTY bool, format string, level slog.Level) slog.Handler {
-	format = strings.ToLower(strings.TrimSpace(format))
-	if (isTTY && format != "json") || format == "text" {
-		return tint.NewHandler(w, &tint.Options{
-			Level:      level,
-			TimeFormat: time.Kitchen,
-			NoColor:    !isTTY,
-		})
+func logOptions(cfg config.LogConfig) (logging.Options, error) {
+	level, err := logging.ParseLevel(cfg.Level)
+	if err != nil {
+		return logging.Options{}, err
 	}
-	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
-}
 
-func parseLogLevel(raw string) (slog.Level, error) {
-	switch strings.ToLower(strings.TrimSpace(raw)) {
-	case "", "info", "inf":
-		return slog.LevelInfo, nil
-	case "debug", "dbg":
-		return slog.LevelDebug, nil
-	case "warn", "warning":
-		return slog.LevelWarn, nil
-	case "error", "err":
-		return slog.LevelError, nil
-	default:
-		return 0, fmt.Errorf("invalid %s %q: supported values are debug, info, warn, error", envLogLevel, raw)
+	componentLevels, err := logging.ParseComponentLevels(cfg.ComponentLevels)
+	if err != nil {
+		return logging.Options{}, err
 	}
+
+	return logging.Options{
+		Level:           level,
+		Format:          cfg.Format,
+		ComponentLevels: componentLevels,
+		DebugSampleRate: max(cfg.DebugSampleRate, 0),
+	}, nil
 }
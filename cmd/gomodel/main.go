@@ -80,6 +80,7 @@ func startApplication(application lifecycleApp, addr string) error {
 // @name           Authorization
 func main() {
 	versionFlag := flag.Bool("version", false, "Print version information")
+	printConfigFlag := flag.Bool("print-config", false, "Print the effective configuration with secrets masked and exit")
 	flag.Parse()
 
 	if *versionFlag {
@@ -89,7 +90,15 @@ func main() {
 
 	_ = godotenv.Load()
 
-	if err := configureLogging(os.Stderr, term.IsTerminal(int(os.Stderr.Fd()))); err != nil {
+	isTTY := term.IsTerminal(int(os.Stderr.Fd()))
+	configureBootstrapLogging(os.Stderr, isTTY, os.Getenv)
+
+	result, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+	if err := configureLogging(os.Stderr, isTTY, result.Config.Logging); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to configure logging: %v\n", err)
 		os.Exit(1)
 	}
@@ -99,11 +108,26 @@ func main() {
 		"commit", version.Commit,
 		"build_date", version.Date,
 	)
+	for _, warning := range result.Warnings {
+		slog.Warn("ignoring config key", "path", warning.Path, "problem", warning.Message)
+	}
 
-	result, err := config.Load()
-	if err != nil {
-		slog.Error("failed to load config", "error", err)
-		os.Exit(1)
+	if *printConfigFlag {
+		effective, err := result.EffectiveYAML()
+		if err != nil {
+			slog.Error("failed to render config", "error", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(effective))
+		os.Exit(0)
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(context.Background(), result.Config.Storage, flag.Args()[1:], os.Stdout); err != nil {
+			slog.Error("migration failed", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
 	}
 
 	factory := providers.NewProviderFactory()
@@ -123,6 +147,11 @@ func main() {
 	factory.Add(xai.Registration)
 	factory.Add(zai.Registration)
 
+	if err := config.CheckProviderTypes(result.RawProviders, factory.RegisteredTypes()); err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
 	application, err := app.New(context.Background(), app.Config{
 		AppConfig: result,
 		Factory:   factory,
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"gomodel/config"
+	"gomodel/internal/migrations"
+	"gomodel/internal/storage"
+)
+
+const migrateUsage = "usage: gomodel migrate up | down [steps] | status"
+
+// runMigrate implements `gomodel migrate`, applying, reverting or listing
+// schema migrations against the configured storage.
+func runMigrate(ctx context.Context, cfg config.StorageConfig, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+		if len(args) > 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of steps %q: must be a positive integer", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+
+	conn, err := storage.New(ctx, cfg.BackendConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	defer conn.Close()
+	migrator, err := migrations.ForStorage(conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "reverted %04d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s schema version %d (latest known: %d)\n", status.Dialect, status.Current, status.Latest)
+		for _, applied := range status.Applied {
+			fmt.Fprintf(out, "  applied  %04d %s (%s)\n", applied.Version, applied.Name, applied.AppliedAt.Format("2006-01-02 15:04:05Z07:00"))
+		}
+		for _, pending := range status.Pending {
+			fmt.Fprintf(out, "  pending  %04d %s\n", pending.Version, pending.Name)
+		}
+		for _, version := range status.Unknown {
+			fmt.Fprintf(out, "  unknown  %04d (applied by a newer gomodel)\n", version)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+)
+
+func TestRunMigrate(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.StorageConfig{
+		Type:   "sqlite",
+		SQLite: config.SQLiteStorageConfig{Path: filepath.Join(t.TempDir(), "gomodel.db")},
+	}
+
+	var out bytes.Buffer
+	if err := runMigrate(ctx, cfg, []string{"status"}, &out); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), "sqlite schema version 0") || !strings.Contains(out.String(), "pending  0001 baseline") {
+		t.Fatalf("status before up = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runMigrate(ctx, cfg, []string{"up"}, &out); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if !strings.Contains(out.String(), "applied 0001 baseline") {
+		t.Fatalf("up output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runMigrate(ctx, cfg, []string{"up"}, &out); err != nil {
+		t.Fatalf("second up: %v", err)
+	}
+	if !strings.Contains(out.String(), "schema is up to date") {
+		t.Fatalf("second up output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runMigrate(ctx, cfg, []string{"down"}, &out); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if !strings.Contains(out.String(), "reverted 0002 usage jobs") {
+		t.Fatalf("down output = %q", out.String())
+	}
+
+	if err := runMigrate(ctx, cfg, []string{"down"}, &out); err == nil || !strings.Contains(err.Error(), "cannot be reverted") {
+		t.Fatalf("down error = %v, want irreversible baseline", err)
+	}
+}
+
+func TestRunMigrate_InvalidArguments(t *testing.T) {
+	for _, args := range [][]string{nil, {"sideways"}, {"up", "now"}, {"down", "0"}, {"down", "x"}} {
+		if err := runMigrate(context.Background(), config.StorageConfig{Type: "sqlite"}, args, &bytes.Buffer{}); err == nil {
+			t.Errorf("runMigrate(%q) succeeded", args)
+		}
+	}
+}
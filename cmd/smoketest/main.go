@@ -0,0 +1,113 @@
+// Package main provides a CLI that smoke-tests every provider configured in a
+// running gateway.
+// Usage:
+//
+//	GOMODEL_MASTER_KEY=sk-xxx go run ./cmd/smoketest \
+//	  -url=https://gomodel.example.com \
+//	  -budget=2000 \
+//	  -junit=smoke.xml
+//
+// It picks one model per provider from /v1/models (or the -models list), runs
+// non-streaming chat, streaming chat and responses against each, prints a
+// table and exits non-zero when any check fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gomodel/internal/smoke"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	baseURL := flag.String("url", envOr("GOMODEL_URL", "http://localhost:8080"), "Gateway base URL")
+	apiKey := flag.String("key", os.Getenv("GOMODEL_MASTER_KEY"), "Gateway API key (default $GOMODEL_MASTER_KEY)")
+	models := flag.String("models", "", "Comma-separated provider/model selectors to test instead of one model per provider")
+	checks := flag.String("checks", strings.Join(smoke.DefaultChecks, ","), "Comma-separated checks to run")
+	maxTokens := flag.Int("max-tokens", 64, "Output token cap per request")
+	maxLatency := flag.Duration("max-latency", 30*time.Second, "Fail checks slower than this")
+	budget := flag.Int("budget", 0, "Cap on total estimated tokens for the run (0 = unlimited)")
+	junit := flag.String("junit", "", "Write a JUnit XML report to this path")
+	timeout := flag.Duration("timeout", 10*time.Minute, "Overall run timeout")
+	flag.Parse()
+
+	runner, err := smoke.NewRunner(smoke.Config{
+		BaseURL:    *baseURL,
+		APIKey:     *apiKey,
+		Models:     splitList(*models),
+		Checks:     splitList(*checks),
+		MaxTokens:  *maxTokens,
+		MaxLatency: *maxLatency,
+		Budget:     *budget,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	targets, err := runner.Targets(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	results := runner.Run(ctx, targets)
+
+	if err := smoke.WriteTable(os.Stdout, results); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: write table: %v\n", err)
+		return 1
+	}
+	if *budget > 0 {
+		fmt.Printf("tokens used: ~%d of %d budget\n", runner.Spent(), *budget)
+	}
+	if *junit != "" {
+		if err := writeJUnit(*junit, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	if smoke.Failed(results) {
+		return 1
+	}
+	return 0
+}
+
+func writeJUnit(path string, results []smoke.Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create junit report: %w", err)
+	}
+	if err := smoke.WriteJUnit(file, results); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write junit report: %w", err)
+	}
+	return file.Close()
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"gomodel/internal/httpclient"
+	"gomodel/internal/storage"
+)
+
+// Problem is one finding of config validation, located by its YAML path
+// ("providers.openai.base_url", "experiments[0].name").
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError reports every problem found in a config at once.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid config: " + e.Problems[0].String()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config: %d problems:", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  ")
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// yamlDocument mirrors Config for YAML unmarshaling, using RawProviderConfig
+// for providers so nullable resilience overrides are preserved.
+type yamlDocument struct {
+	*Config      `yaml:",inline"`
+	RawProviders map[string]RawProviderConfig `yaml:"providers"`
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	yamlUnmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+	yamlDocumentType    = reflect.TypeFor[yamlDocument]()
+)
+
+// unknownKeys reports the mapping keys of a parsed config.yaml that match no
+// field, with a suggestion for likely typos.
+func unknownKeys(root *yaml.Node) []Problem {
+	var problems []Problem
+	walkYAMLKeys(root, yamlDocumentType, "", &problems)
+	return problems
+}
+
+func walkYAMLKeys(node *yaml.Node, t reflect.Type, path string, problems *[]Problem) {
+	for node != nil && (node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return
+		}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node == nil || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode || t == durationType {
+			return
+		}
+		fields, open := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if key == "<<" {
+				walkYAMLKeys(value, t, path, problems)
+				continue
+			}
+			field, ok := fields[key]
+			if !ok {
+				if !open {
+					*problems = append(*problems, unknownKeyProblem(joinYAMLPath(path, key), key, fields))
+				}
+				continue
+			}
+			walkYAMLKeys(value, field, joinYAMLPath(path, key), problems)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkYAMLKeys(node.Content[i+1], t.Elem(), joinYAMLPath(path, node.Content[i].Value), problems)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			walkYAMLKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct to their field types, following
+// inline fields the way yaml.v3 does. open reports an inline map, which
+// accepts any key.
+func yamlFields(t reflect.Type) (fields map[string]reflect.Type, open bool) {
+	fields = make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if slices.Contains(strings.Split(opts, ","), "inline") {
+			inner := f.Type
+			for inner.Kind() == reflect.Pointer {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Map {
+				open = true
+				continue
+			}
+			innerFields, innerOpen := yamlFields(inner)
+			for k, v := range innerFields {
+				fields[k] = v
+			}
+			open = open || innerOpen
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields, open
+}
+
+func unknownKeyProblem(path, key string, fields map[string]reflect.Type) Problem {
+	candidates := make([]string, 0, len(fields))
+	for name := range fields {
+		candidates = append(candidates, name)
+	}
+	message := "unknown key"
+	if suggestion := didYouMean(key, candidates); suggestion != "" {
+		message += ", did you mean " + suggestion + "?"
+	}
+	return Problem{Path: path, Message: message}
+}
+
+// didYouMean returns the candidate closest to s by edit distance, or "" when
+// none is close enough to be a likely typo.
+func didYouMean(s string, candidates []string) string {
+	lower := strings.ToLower(s)
+	limit := max(1, len(lower)/3)
+	best, bestDistance := "", limit+1
+	sort.Strings(candidates)
+	for _, c := range candidates {
+		if d := editDistance(lower, strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance is the edit distance between a and b, counting a swap of two
+// adjacent characters as one edit (optimal string alignment).
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func joinYAMLPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// checkValues reports config values that can never work: a malformed port,
+// malformed provider base URLs, unknown storage types and negative durations.
+// Problems are sorted by path.
+func checkValues(cfg *Config, rawProviders map[string]RawProviderConfig) []Problem {
+	var problems []Problem
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, Problem{Path: "server.port", Message: fmt.Sprintf("must be a number between 1 and 65535, got %q", cfg.Server.Port)})
+	}
+	switch cfg.Storage.Type {
+	case storage.TypeSQLite, storage.TypePostgreSQL, storage.TypeMongoDB:
+	default:
+		valid := []string{storage.TypeSQLite, storage.TypePostgreSQL, storage.TypeMongoDB}
+		problems = append(problems, enumProblem("storage.type", "storage type", cfg.Storage.Type, valid))
+	}
+	negativeDurations(reflect.ValueOf(cfg).Elem(), "", &problems)
+
+	for name, provider := range rawProviders {
+		path := "providers." + name
+		if provider.BaseURL != "" {
+			if err := httpclient.ValidateBaseURL(provider.BaseURL); err != nil {
+				problems = append(problems, Problem{Path: path + ".base_url", Message: err.Error()})
+			}
+		}
+		negativeDurations(reflect.ValueOf(provider), path, &problems)
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems
+}
+
+// negativeDurations reports every time.Duration below zero reachable from v.
+func negativeDurations(v reflect.Value, path string, problems *[]Problem) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			negativeDurations(v.Elem(), path, problems)
+		}
+	case reflect.Int64:
+		if v.Type() == durationType && v.Int() < 0 {
+			*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf("must not be negative, got %s", time.Duration(v.Int()))})
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get("yaml")
+			if !f.IsExported() || tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			fieldPath := path
+			if !slices.Contains(strings.Split(opts, ","), "inline") {
+				if name == "" {
+					name = strings.ToLower(f.Name)
+				}
+				fieldPath = joinYAMLPath(path, name)
+			}
+			negativeDurations(v.Field(i), fieldPath, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			negativeDurations(v.Index(i), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		for _, k := range keys {
+			if k.Kind() == reflect.String {
+				negativeDurations(v.MapIndex(k), joinYAMLPath(path, k.String()), problems)
+			}
+		}
+	}
+}
+
+func enumProblem(path, what, got string, valid []string) Problem {
+	message := fmt.Sprintf("unknown %s %q (valid: %s)", what, got, strings.Join(valid, ", "))
+	if suggestion := didYouMean(got, slices.Clone(valid)); suggestion != "" {
+		message = fmt.Sprintf("unknown %s %q, did you mean %s?", what, got, suggestion)
+	}
+	return Problem{Path: path, Message: message}
+}
+
+// CheckProviderTypes reports YAML providers whose type is not one of the
+// registered provider types. Startup calls it once the provider factories
+// are registered, since the config package does not know them.
+func CheckProviderTypes(rawProviders map[string]RawProviderConfig, registered []string) error {
+	registered = slices.Sorted(slices.Values(registered))
+	var problems []Problem
+	for name, provider := range rawProviders {
+		if provider.Type == "" || slices.Contains(registered, provider.Type) {
+			continue
+		}
+		problems = append(problems, enumProblem("providers."+name+".type", "provider type", provider.Type, registered))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return &ValidationError{Problems: problems}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_BrokenConfigs(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "top-level typo",
+			yaml: "providrs:\n  openai:\n    type: openai\n",
+			want: "invalid config: providrs: unknown key, did you mean providers?",
+		},
+		{
+			name: "provider key typo",
+			yaml: "providers:\n  openai:\n    type: openai\n    base_uri: https://api.openai.com/v1\n",
+			want: "invalid config: providers.openai.base_uri: unknown key, did you mean base_url?",
+		},
+		{
+			name: "unknown key without a close match",
+			yaml: "server:\n  flux_capacitor: true\n",
+			want: "invalid config: server.flux_capacitor: unknown key",
+		},
+		{
+			name: "key in a list item",
+			yaml: "experiments:\n  - name: split\n    modle: gpt-4o\n",
+			want: "invalid config: experiments[0].modle: unknown key, did you mean model?",
+		},
+		{
+			name: "nested pointer section",
+			yaml: "resilience:\n  retry:\n    max_retires: 3\n",
+			want: "invalid config: resilience.retry.max_retires: unknown key, did you mean max_retries?",
+		},
+		{
+			name: "port not numeric",
+			yaml: "server:\n  port: http\n",
+			want: `invalid config: server.port: must be a number between 1 and 65535, got "http"`,
+		},
+		{
+			name: "port from env out of range",
+			env:  map[string]string{"PORT": "70000"},
+			want: `invalid config: server.port: must be a number between 1 and 65535, got "70000"`,
+		},
+		{
+			name: "base url without scheme",
+			yaml: "providers:\n  openai:\n    type: openai\n    base_url: api.openai.com/v1\n",
+			want: `invalid config: providers.openai.base_url: must be an absolute http or https URL, got "api.openai.com/v1"`,
+		},
+		{
+			name: "base url with query string",
+			yaml: "providers:\n  azure:\n    type: azure\n    base_url: https://acme.openai.azure.com/openai?api-version=2024-10-21\n",
+			want: `invalid config: providers.azure.base_url: must not have a query string, got "https://acme.openai.azure.com/openai?api-version=2024-10-21"; use extra_query for query parameters`,
+		},
+		{
+			name: "base url with fragment",
+			yaml: "providers:\n  openai:\n    type: openai\n    base_url: https://proxy.internal/openai/v1#chat\n",
+			want: `invalid config: providers.openai.base_url: must not have a fragment, got "https://proxy.internal/openai/v1#chat"`,
+		},
+		{
+			name: "storage type typo",
+			yaml: "storage:\n  type: postgres\n",
+			want: `invalid config: storage.type: unknown storage type "postgres", did you mean postgresql?`,
+		},
+		{
+			name: "storage type unknown",
+			yaml: "storage:\n  type: dynamodb\n",
+			want: `invalid config: storage.type: unknown storage type "dynamodb" (valid: sqlite, postgresql, mongodb)`,
+		},
+		{
+			name: "negative duration",
+			yaml: "idempotency:\n  ttl: -1h\n",
+			want: "invalid config: idempotency.ttl: must not be negative, got -1h0m0s",
+		},
+		{
+			name: "all problems at once",
+			yaml: "providrs: {}\nserver:\n  port: abc\nproviders:\n  openai:\n    base_uri: x\n    base_url: ftp://files\n",
+			want: "invalid config: 4 problems:\n" +
+				"  providrs: unknown key, did you mean providers?\n" +
+				"  providers.openai.base_uri: unknown key, did you mean base_url?\n" +
+				`  providers.openai.base_url: must be an absolute http or https URL, got "ftp://files"` + "\n" +
+				`  server.port: must be a number between 1 and 65535, got "abc"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearAllConfigEnvVars(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			withTempDir(t, func(dir string) {
+				if tt.yaml != "" {
+					if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(tt.yaml), 0644); err != nil {
+						t.Fatalf("Failed to write config.yaml: %v", err)
+					}
+				}
+				_, err := Load()
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("Load() error = %v, want a *ValidationError", err)
+				}
+				if err.Error() != tt.want {
+					t.Fatalf("Load() error =\n%s\nwant\n%s", err, tt.want)
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_StrictConfigOffWarnsOnUnknownKeys(t *testing.T) {
+	clearAllConfigEnvVars(t)
+	t.Setenv("STRICT_CONFIG", "false")
+
+	withTempDir(t, func(dir string) {
+		yaml := "server:\n  prot: \"9000\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if len(result.Warnings) != 1 || result.Warnings[0].String() != "server.prot: unknown key, did you mean port?" {
+			t.Fatalf("Warnings = %v", result.Warnings)
+		}
+	})
+}
+
+func TestLoad_StrictConfigOffStillRejectsBadValues(t *testing.T) {
+	clearAllConfigEnvVars(t)
+	t.Setenv("STRICT_CONFIG", "false")
+
+	withTempDir(t, func(dir string) {
+		yaml := "strict_config: false\nstorage:\n  type: mysql\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() accepted storage type mysql")
+		}
+	})
+}
+
+func TestCheckProviderTypes(t *testing.T) {
+	raw := map[string]RawProviderConfig{
+		"openai":  {Type: "openai"},
+		"claude":  {Type: "antropic"},
+		"bedrock": {Type: "bedrock"},
+		"default": {},
+	}
+	err := CheckProviderTypes(raw, []string{"openai", "anthropic", "gemini"})
+	want := "invalid config: 2 problems:\n" +
+		`  providers.bedrock.type: unknown provider type "bedrock" (valid: anthropic, gemini, openai)` + "\n" +
+		`  providers.claude.type: unknown provider type "antropic", did you mean anthropic?`
+	if err == nil || err.Error() != want {
+		t.Fatalf("CheckProviderTypes() = %v, want\n%s", err, want)
+	}
+	if err := CheckProviderTypes(map[string]RawProviderConfig{"openai": {Type: "openai"}}, []string{"openai"}); err != nil {
+		t.Fatalf("CheckProviderTypes() = %v, want nil", err)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"base_url", "api_key", "type", "models"}
+	for input, want := range map[string]string{
+		"base_uri": "base_url",
+		"BASE_URL": "base_url",
+		"apikey":   "api_key",
+		"typ":      "type",
+		"zzz":      "",
+		"timeout":  "",
+	} {
+		if got := didYouMean(input, candidates); got != want {
+			t.Errorf("didYouMean(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
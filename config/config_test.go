@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -34,8 +35,8 @@ func clearProviderEnvVars(t *testing.T) {
 func clearAllConfigEnvVars(t *testing.T) {
 	t.Helper()
 	for _, key := range []string{
-		"PORT", "GOMODEL_MASTER_KEY", "BODY_SIZE_LIMIT", "SWAGGER_ENABLED", "PPROF_ENABLED", "ENABLE_PASSTHROUGH_ROUTES", "ALLOW_PASSTHROUGH_V1_ALIAS", "ENABLED_PASSTHROUGH_PROVIDERS",
-		"GOMODEL_CACHE_DIR", "CACHE_REFRESH_INTERVAL",
+		"PORT", "GOMODEL_MASTER_KEY", "GOMODEL_PROFILE", "BODY_SIZE_LIMIT", "SWAGGER_ENABLED", "PPROF_ENABLED", "PLAYGROUND_ENABLED", "ENABLE_PASSTHROUGH_ROUTES", "ALLOW_PASSTHROUGH_V1_ALIAS", "ENABLED_PASSTHROUGH_PROVIDERS", "ENABLE_ASSISTANTS_PASSTHROUGH", "MAX_REQUEST_IMAGES", "MAX_IMAGE_SIZE", "STRICT_OPENAI_COMPAT", "STRICT_REQUEST_OPTIONS", "RECORD_RAW_USER", "STREAM_BUFFER_SIZE", "STREAM_STALL_TIMEOUT", "STREAM_STALL_POLICY", "STREAM_STALL_MAX_PAUSE", "STREAM_CHUNK_STALL_THRESHOLD", "STREAMING_BODY_THRESHOLD", "BUFFERED_BODY_LIMIT",
+		"GOMODEL_CACHE_DIR", "GOMODEL_CACHE_MAX_AGE", "GOMODEL_CACHE_MAX_SIZE", "CACHE_REFRESH_INTERVAL",
 		"REDIS_URL", "REDIS_KEY_MODELS", "REDIS_KEY_RESPONSES", "REDIS_TTL_MODELS", "REDIS_TTL_RESPONSES",
 		"RESPONSE_CACHE_SIMPLE_ENABLED",
 		"SEMANTIC_CACHE_ENABLED", "SEMANTIC_CACHE_THRESHOLD", "SEMANTIC_CACHE_TTL", "SEMANTIC_CACHE_MAX_CONV_MESSAGES",
@@ -45,19 +46,63 @@ func clearAllConfigEnvVars(t *testing.T) {
 		"SEMANTIC_CACHE_PGVECTOR_URL", "SEMANTIC_CACHE_PGVECTOR_TABLE", "SEMANTIC_CACHE_PGVECTOR_DIMENSION",
 		"SEMANTIC_CACHE_PINECONE_HOST", "SEMANTIC_CACHE_PINECONE_API_KEY", "SEMANTIC_CACHE_PINECONE_NAMESPACE", "SEMANTIC_CACHE_PINECONE_DIMENSION",
 		"SEMANTIC_CACHE_WEAVIATE_URL", "SEMANTIC_CACHE_WEAVIATE_CLASS", "SEMANTIC_CACHE_WEAVIATE_API_KEY",
-		"STORAGE_TYPE", "SQLITE_PATH", "POSTGRES_URL", "POSTGRES_MAX_CONNS",
+		"STORAGE_TYPE", "STORAGE_AUTO_MIGRATE", "SQLITE_PATH", "POSTGRES_URL", "POSTGRES_MAX_CONNS",
 		"MONGODB_URL", "MONGODB_DATABASE",
 		"METRICS_ENABLED", "METRICS_ENDPOINT",
-		"LOGGING_ENABLED", "LOGGING_LOG_BODIES", "LOGGING_LOG_HEADERS",
+		"LOG_LEVEL", "LOG_FORMAT", "LOG_COMPONENT_LEVELS", "LOG_DEBUG_SAMPLE_RATE",
+		"LOGGING_ENABLED", "LOGGING_LOG_BODIES", "LOGGING_LOG_HEADERS", "LOGGING_IGNORE_NO_BODY_LOG_HEADER",
 		"LOGGING_ONLY_MODEL_INTERACTIONS", "LOGGING_BUFFER_SIZE",
 		"LOGGING_FLUSH_INTERVAL", "LOGGING_RETENTION_DAYS",
+		"LOGGING_FAILURE_MODE", "LOGGING_SPILL_DIR", "LOGGING_SPILL_MAX_BYTES",
+		"LOGGING_DRAIN_TIMEOUT", "LOGGING_WRITE_LAG_WARNING", "LOGGING_LOOKUP_CACHE_ENTRIES", "LOGGING_LOOKUP_CACHE_TTL", "LOGGING_MAX_REQUEST_BODY_BYTES", "LOGGING_MAX_RESPONSE_BODY_BYTES",
+		"LOGGING_STREAM_SAMPLE_RATE", "LOGGING_STREAM_SAMPLE_OPT_IN", "LOGGING_STREAM_SAMPLE_MAX_PER_DAY", "LOGGING_STREAM_SAMPLE_MAX_BYTES", "LOGGING_ENCRYPTION_HEADERS",
 		"USAGE_ENABLED", "ENFORCE_RETURNING_USAGE_DATA",
-		"USAGE_BUFFER_SIZE", "USAGE_FLUSH_INTERVAL", "USAGE_RETENTION_DAYS",
+		"USAGE_BUFFER_SIZE", "USAGE_FLUSH_INTERVAL", "USAGE_RETENTION_DAYS", "USAGE_DRAIN_TIMEOUT", "USAGE_WRITE_LAG_WARNING",
+		"USAGE_JOB_BATCH_SIZE", "USAGE_JOB_BATCH_DELAY", "USAGE_CONTENT_ANALYSIS", "USAGE_CONTENT_SAMPLE_CHARS",
 		"GUARDRAILS_ENABLED", "ENABLE_GUARDRAILS_FOR_BATCH_PROCESSING",
 		"FEATURE_FALLBACK_MODE", "FALLBACK_MANUAL_RULES_PATH",
 		"MODEL_OVERRIDES_ENABLED", "MODELS_ENABLED_BY_DEFAULT", "KEEP_ONLY_ALIASES_AT_MODELS_ENDPOINT",
-		"HTTP_TIMEOUT", "HTTP_RESPONSE_HEADER_TIMEOUT",
+		"HTTP_TIMEOUT", "HTTP_RESPONSE_HEADER_TIMEOUT", "HTTP_MAX_IDLE_CONNS_PER_HOST", "HTTP_MAX_CONNS_PER_HOST", "HTTP_IDLE_CONN_TIMEOUT", "HTTP_TLS_HANDSHAKE_TIMEOUT", "HTTP_FORCE_ATTEMPT_HTTP2",
 		"WORKFLOW_REFRESH_INTERVAL",
+		"COMPARISON_MAX_MODELS", "COMPARISON_MAX_CONCURRENCY", "COMPARISON_MAX_ESTIMATED_COST",
+		"SCOREBOARD_ENABLED", "SCOREBOARD_MAX_MODELS",
+		"CONTEXT_OVERFLOW_STRATEGY",
+		"PROMPT_COMPRESSION_PASSES",
+		"PROMPT_COMPRESSION_DATA_URI_MIN_BYTES",
+		"DEFAULT_MAX_TOKENS", "DEFAULT_MAX_TOKENS_DYNAMIC_ENABLED", "DEFAULT_MAX_TOKENS_DYNAMIC_PERCENTILE",
+		"DEFAULT_MAX_TOKENS_DYNAMIC_HEADROOM", "DEFAULT_MAX_TOKENS_DYNAMIC_WINDOW", "DEFAULT_MAX_TOKENS_DYNAMIC_MIN_SAMPLES",
+		"FIRST_TOKEN_TIMEOUT",
+		"DEFERRED_ENABLED", "DEFERRED_TTL", "DEFERRED_MAX_ATTEMPTS",
+		"DEFERRED_INITIAL_BACKOFF", "DEFERRED_MAX_BACKOFF", "DEFERRED_POLL_INTERVAL",
+		"DEFERRED_CALLBACKS_ENABLED", "DEFERRED_CALLBACK_ALLOWED_HOSTS",
+		"IDEMPOTENCY_ENABLED", "IDEMPOTENCY_TTL", "IDEMPOTENCY_MAX_ENTRIES",
+		"IDEMPOTENCY_MAX_BODY_BYTES", "IDEMPOTENCY_STREAM_DUPLICATES",
+		"UPSTREAM_RESPONSE_HEADERS_ALLOW", "UPSTREAM_RESPONSE_HEADERS_PREFIX",
+		"ANOMALY_DETECTION_ENABLED", "ANOMALY_DETECTION_BASELINE_WINDOW", "ANOMALY_DETECTION_MIN_BASELINE",
+		"ANOMALY_DETECTION_COOLDOWN", "ANOMALY_DETECTION_MAX_SERIES", "ANOMALY_DETECTION_MAX_RECORDS",
+		"CHAOS_ENABLED",
+		"PROVENANCE_ENABLED", "PROVENANCE_EMBED", "PROVENANCE_SECRET",
+		"STREAM_LIMITS_MAX_BYTES", "STREAM_LIMITS_MAX_DURATION", "STREAM_LIMITS_GRACE",
+		"ACCESS_LOG_ENABLED", "ACCESS_LOG_FORMAT", "ACCESS_LOG_OUTPUT", "ACCESS_LOG_FIELDS", "ACCESS_LOG_MAX_SIZE_MB", "ACCESS_LOG_MAX_AGE", "ACCESS_LOG_MAX_BACKUPS",
+		"SELF_PROTECTION_ENABLED", "SELF_PROTECTION_ACTION", "SELF_PROTECTION_PATTERNS", "SELF_PROTECTION_WEBHOOK_URL",
+		"SECRET_REDACTION_ENABLED", "SECRET_REDACTION_ACTION",
+		"RESPONSE_TRANSFORMS_STREAM_WINDOW",
+		"RESPONSE_SANITIZATION_ENABLED", "RESPONSE_SANITIZATION_MAX_MAPPINGS",
+		"MAINTENANCE_ENABLED", "MAINTENANCE_MESSAGE", "MAINTENANCE_RETRY_AFTER",
+		"CAPABILITY_PROBE_TOKEN_BUDGET", "STRICT_CONFIG",
+		"KEY_HEALTH_ENABLED", "KEY_HEALTH_INTERVAL", "KEY_HEALTH_TIMEOUT", "KEY_HEALTH_FAILURE_THRESHOLD",
+		"KEY_HEALTH_EXPIRY_WARNING", "KEY_HEALTH_WEBHOOK_URL",
+		"MEMORY_BUDGET_BYTES", "MEMORY_RSS_CEILING_BYTES", "MEMORY_RSS_WARN_RATIO", "MEMORY_CHECK_INTERVAL",
+		"DEPRECATIONS_ENABLED", "DEPRECATIONS_FILE", "DEPRECATIONS_AFTER_SUNSET", "DEPRECATIONS_WARN_INTERVAL",
+		"WEBSOCKET_ENABLED", "WEBSOCKET_PING_INTERVAL", "WEBSOCKET_MAX_DURATION",
+		"MODERATION_ENABLED", "MODERATION_MODEL", "MODERATION_PROVIDER", "MODERATION_THRESHOLD",
+		"MODERATION_ACTION", "MODERATION_MODELS", "MODERATION_PATHS", "MODERATION_SKIP_KEYS",
+		"MODERATION_FAIL_OPEN", "MODERATION_EXPOSE_SCORES", "MODERATION_TIMEOUT",
+		"PROMPT_CACHING_ENABLED", "PROMPT_CACHING_MODELS", "PROMPT_CACHING_AUTO_DETECT", "PROMPT_CACHING_MIN_REPEATS",
+		"PROMPT_CACHING_MIN_CHARS", "PROMPT_CACHING_MAX_PREFIXES",
+		"SERVER_TOOLS_ENABLED", "SERVER_TOOLS_MAX_ITERATIONS", "SERVER_TOOLS_TIMEOUT", "SERVER_TOOLS_MAX_OUTPUT_BYTES",
+		"ADMIN_ENDPOINTS_ENABLED", "ADMIN_UI_ENABLED", "ADMIN_MAX_QUERY_DAYS",
+		"EMBEDDING_CACHE_ENABLED", "EMBEDDING_CACHE_MAX_ENTRIES", "EMBEDDING_CACHE_MAX_BYTES", "EMBEDDING_CACHE_TTL",
 	} {
 		t.Setenv(key, "")
 		os.Unsetenv(key)
@@ -89,6 +134,9 @@ func TestBuildDefaultConfig(t *testing.T) {
 	if cfg.Server.PprofEnabled {
 		t.Error("expected Server.PprofEnabled=false")
 	}
+	if cfg.Server.PlaygroundEnabled {
+		t.Error("expected Server.PlaygroundEnabled=false")
+	}
 	if !cfg.Server.EnablePassthroughRoutes {
 		t.Error("expected Server.EnablePassthroughRoutes=true")
 	}
@@ -152,6 +200,9 @@ func TestBuildDefaultConfig(t *testing.T) {
 	if cfg.Usage.RetentionDays != 90 {
 		t.Errorf("expected Usage.RetentionDays=90, got %d", cfg.Usage.RetentionDays)
 	}
+	if cfg.Usage.DrainTimeout != 10 {
+		t.Errorf("expected Usage.DrainTimeout=10, got %d", cfg.Usage.DrainTimeout)
+	}
 	if cfg.Metrics.Endpoint != "/metrics" {
 		t.Errorf("expected Metrics.Endpoint=/metrics, got %s", cfg.Metrics.Endpoint)
 	}
@@ -226,6 +277,7 @@ func TestLoad_YAMLOverridesDefaults(t *testing.T) {
 server:
   port: "3000"
   pprof_enabled: true
+  playground_enabled: true
 models:
   enabled_by_default: false
   overrides_enabled: false
@@ -257,6 +309,9 @@ logging:
 		if !cfg.Server.PprofEnabled {
 			t.Error("expected Server.PprofEnabled=true from YAML")
 		}
+		if !cfg.Server.PlaygroundEnabled {
+			t.Error("expected Server.PlaygroundEnabled=true from YAML")
+		}
 		if cfg.Models.EnabledByDefault {
 			t.Error("expected Models.EnabledByDefault=false from YAML")
 		}
@@ -391,6 +446,57 @@ fallback:
 	})
 }
 
+func TestLoad_ModelCategories(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+models:
+  categories:
+    - id: " restricted "
+      display_name: Restricted
+      models: [" gpt-4o* ", "openai/o1"]
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Models.Categories
+		if len(got) != 1 || got[0].ID != "restricted" || got[0].DisplayName != "Restricted" {
+			t.Fatalf("Models.Categories = %+v", got)
+		}
+		if len(got[0].Models) != 2 || got[0].Models[0] != "gpt-4o*" || got[0].Models[1] != "openai/o1" {
+			t.Fatalf("Models.Categories[0].Models = %q", got[0].Models)
+		}
+	})
+}
+
+func TestValidateModelCategories_Rejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []ModelCategoryConfig
+	}{
+		{name: "empty id", categories: []ModelCategoryConfig{{Models: []string{"*"}}}},
+		{name: "uppercase id", categories: []ModelCategoryConfig{{ID: "Restricted", Models: []string{"*"}}}},
+		{name: "built-in id", categories: []ModelCategoryConfig{{ID: "embedding", Models: []string{"*"}}}},
+		{name: "all", categories: []ModelCategoryConfig{{ID: "all", Models: []string{"*"}}}},
+		{name: "duplicate id", categories: []ModelCategoryConfig{{ID: "a", Models: []string{"x"}}, {ID: "a", Models: []string{"y"}}}},
+		{name: "no patterns", categories: []ModelCategoryConfig{{ID: "a"}}},
+		{name: "blank pattern", categories: []ModelCategoryConfig{{ID: "a", Models: []string{" "}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateModelCategories(tt.categories); err == nil {
+				t.Fatal("ValidateModelCategories() error = nil")
+			}
+		})
+	}
+}
+
 func TestLoad_ManualFallbackModeAllowsMissingManualRulesPath(t *testing.T) {
 	clearAllConfigEnvVars(t)
 
@@ -995,6 +1101,54 @@ func TestLoad_HTTPConfig(t *testing.T) {
 	})
 }
 
+func TestLoad_HTTPConnectionPool(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.HTTP.PoolOptions(nil)
+		if got.MaxIdleConnsPerHost != 256 || got.MaxConnsPerHost != 0 || got.IdleConnTimeout != 2*time.Minute ||
+			got.TLSHandshakeTimeout != 10*time.Second || got.ForceAttemptHTTP2 == nil || !*got.ForceAttemptHTTP2 {
+			t.Fatalf("default pool = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		t.Setenv("HTTP_MAX_CONNS_PER_HOST", "64")
+		t.Setenv("HTTP_IDLE_CONN_TIMEOUT", "5m")
+		yaml := `
+http:
+  max_idle_conns_per_host: 512
+providers:
+  internal:
+    type: openai
+    api_key: "sk-internal"
+    http:
+      max_conns_per_host: 8
+      force_attempt_http2: false
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		global := result.Config.HTTP.PoolOptions(nil)
+		if global.MaxIdleConnsPerHost != 512 || global.MaxConnsPerHost != 64 || global.IdleConnTimeout != 5*time.Minute {
+			t.Fatalf("global pool = %+v, want YAML and env values", global)
+		}
+		got := result.Config.HTTP.PoolOptions(result.RawProviders["internal"].HTTP)
+		if got.MaxIdleConnsPerHost != 512 || got.MaxConnsPerHost != 8 || got.IdleConnTimeout != 5*time.Minute || *got.ForceAttemptHTTP2 {
+			t.Fatalf("provider pool = %+v, want the provider overrides on top of the global pool", got)
+		}
+	})
+}
+
 func TestLoad_WorkflowRefreshInterval(t *testing.T) {
 	clearAllConfigEnvVars(t)
 
@@ -1039,7 +1193,7 @@ workflows:
 	})
 }
 
-func TestLoad_CacheDir(t *testing.T) {
+func TestLoad_ComparisonLimits(t *testing.T) {
 	clearAllConfigEnvVars(t)
 
 	withTempDir(t, func(_ string) {
@@ -1047,22 +1201,2240 @@ func TestLoad_CacheDir(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Load() failed: %v", err)
 		}
-		if result.Config.Cache.Model.Local == nil {
-			t.Error("expected Cache.Model.Local to be set by default")
+		got := result.Config.Comparison
+		if got.MaxModels != 5 || got.MaxConcurrency != 4 || got.MaxEstimatedCost != 0 {
+			t.Fatalf("Comparison = %+v, want defaults {5 4 0}", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+comparison:
+  max_models: 8
+  max_concurrency: 2
+  max_estimated_cost: 0.5
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("COMPARISON_MAX_CONCURRENCY", "3")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Comparison
+		if got.MaxModels != 8 || got.MaxConcurrency != 3 || got.MaxEstimatedCost != 0.5 {
+			t.Fatalf("Comparison = %+v, want {8 3 0.5}", got)
 		}
 	})
+}
+
+func TestLoad_InlineImageLimits(t *testing.T) {
+	clearAllConfigEnvVars(t)
 
 	withTempDir(t, func(_ string) {
-		t.Setenv("GOMODEL_CACHE_DIR", "/tmp/gomodel-cache")
+		t.Setenv("MAX_REQUEST_IMAGES", "4")
+		t.Setenv("MAX_IMAGE_SIZE", "5M")
 
 		result, err := Load()
 		if err != nil {
 			t.Fatalf("Load() failed: %v", err)
 		}
-		if result.Config.Cache.Model.Local == nil || result.Config.Cache.Model.Local.CacheDir != "/tmp/gomodel-cache" {
-			t.Errorf("expected Cache.Model.Local.CacheDir=/tmp/gomodel-cache, got %v", result.Config.Cache.Model.Local)
+		if got := result.Config.Server; got.MaxRequestImages != 4 || got.MaxImageSize != "5M" {
+			t.Fatalf("Server image limits = %d, %q; want 4, \"5M\"", got.MaxRequestImages, got.MaxImageSize)
+		}
+	})
+
+	for name, env := range map[string][2]string{
+		"negative image count": {"MAX_REQUEST_IMAGES", "-1"},
+		"invalid image size":   {"MAX_IMAGE_SIZE", "five megabytes"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(_ string) {
+				t.Setenv(env[0], env[1])
+				if _, err := Load(); err == nil || !strings.Contains(err.Error(), env[0]) {
+					t.Fatalf("Load() error = %v, want an error naming %s", err, env[0])
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_StreamBackpressure(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Server
+		if got.StreamBufferSize != "256K" || got.StreamStallTimeout != 30*time.Second || got.StreamStallPolicy != "pause" {
+			t.Fatalf("stream defaults = %q, %s, %q; want \"256K\", 30s, \"pause\"", got.StreamBufferSize, got.StreamStallTimeout, got.StreamStallPolicy)
+		}
+		if got.StreamChunkStallThreshold != 10*time.Second {
+			t.Fatalf("StreamChunkStallThreshold = %s, want 10s", got.StreamChunkStallThreshold)
+		}
+		if got.StreamStallMaxPause != 5*time.Minute {
+			t.Fatalf("StreamStallMaxPause = %s, want 5m", got.StreamStallMaxPause)
+		}
+
+		t.Setenv("STREAM_BUFFER_SIZE", "64K")
+		t.Setenv("STREAM_STALL_TIMEOUT", "5s")
+		t.Setenv("STREAM_STALL_POLICY", "terminate")
+		t.Setenv("STREAM_CHUNK_STALL_THRESHOLD", "2s")
+		t.Setenv("STREAM_STALL_MAX_PAUSE", "1m")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got = result.Config.Server
+		if got.StreamBufferSize != "64K" || got.StreamStallTimeout != 5*time.Second || got.StreamStallPolicy != "terminate" {
+			t.Fatalf("stream settings = %q, %s, %q; want \"64K\", 5s, \"terminate\"", got.StreamBufferSize, got.StreamStallTimeout, got.StreamStallPolicy)
+		}
+		if got.StreamChunkStallThreshold != 2*time.Second {
+			t.Fatalf("StreamChunkStallThreshold = %s, want 2s", got.StreamChunkStallThreshold)
+		}
+		if got.StreamStallMaxPause != time.Minute {
+			t.Fatalf("StreamStallMaxPause = %s, want 1m", got.StreamStallMaxPause)
+		}
+	})
+
+	for name, env := range map[string][2]string{
+		"invalid buffer size":  {"STREAM_BUFFER_SIZE", "lots"},
+		"zero stall timeout":   {"STREAM_STALL_TIMEOUT", "0s"},
+		"unknown stall policy": {"STREAM_STALL_POLICY", "drop"},
+		"zero max pause":       {"STREAM_STALL_MAX_PAUSE", "0s"},
+		"zero chunk threshold": {"STREAM_CHUNK_STALL_THRESHOLD", "0s"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(_ string) {
+				t.Setenv(env[0], env[1])
+				if _, err := Load(); err == nil || !strings.Contains(err.Error(), env[0]) {
+					t.Fatalf("Load() error = %v, want an error naming %s", err, env[0])
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_RequestBodyStreaming(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Server; got.StreamingBodyThreshold != "" || got.BufferedBodyLimit != "" {
+			t.Fatalf("defaults = %q, %q; want both empty", got.StreamingBodyThreshold, got.BufferedBodyLimit)
+		}
+
+		t.Setenv("STREAMING_BODY_THRESHOLD", "1M")
+		t.Setenv("BUFFERED_BODY_LIMIT", "8M")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Server; got.StreamingBodyThreshold != "1M" || got.BufferedBodyLimit != "8M" {
+			t.Fatalf("settings = %q, %q; want \"1M\", \"8M\"", got.StreamingBodyThreshold, got.BufferedBodyLimit)
+		}
+	})
+
+	for _, name := range []string{"STREAMING_BODY_THRESHOLD", "BUFFERED_BODY_LIMIT"} {
+		t.Run("invalid "+name, func(t *testing.T) {
+			withTempDir(t, func(_ string) {
+				t.Setenv(name, "huge")
+				if _, err := Load(); err == nil || !strings.Contains(err.Error(), name) {
+					t.Fatalf("Load() error = %v, want an error naming %s", err, name)
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_WriteLagWarning(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Logging.WriteLagWarning; got != 60 {
+			t.Errorf("expected Logging.WriteLagWarning=60, got %d", got)
+		}
+		if got := result.Config.Usage.WriteLagWarning; got != 60 {
+			t.Errorf("expected Usage.WriteLagWarning=60, got %d", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_WRITE_LAG_WARNING", "15")
+		t.Setenv("USAGE_WRITE_LAG_WARNING", "0")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Logging.WriteLagWarning; got != 15 {
+			t.Errorf("expected Logging.WriteLagWarning=15, got %d", got)
+		}
+		if got := result.Config.Usage.WriteLagWarning; got != 0 {
+			t.Errorf("expected Usage.WriteLagWarning=0, got %d", got)
+		}
+	})
+}
+
+func TestLoad_LoggingLookupCache(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Logging.LookupCacheEntries; got != 256 {
+			t.Errorf("expected Logging.LookupCacheEntries=256, got %d", got)
+		}
+		if got := result.Config.Logging.LookupCacheTTL; got != 300 {
+			t.Errorf("expected Logging.LookupCacheTTL=300, got %d", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_LOOKUP_CACHE_ENTRIES", "0")
+		t.Setenv("LOGGING_LOOKUP_CACHE_TTL", "30")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Logging.LookupCacheEntries; got != 0 {
+			t.Errorf("expected Logging.LookupCacheEntries=0, got %d", got)
+		}
+		if got := result.Config.Logging.LookupCacheTTL; got != 30 {
+			t.Errorf("expected Logging.LookupCacheTTL=30, got %d", got)
+		}
+	})
+}
+
+func TestLoad_LoggingLevels(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.Level != "info" || got.Format != "" || got.ComponentLevels != "" || got.DebugSampleRate != 0 {
+			t.Fatalf("Logging levels = %q %q %q %d, want defaults", got.Level, got.Format, got.ComponentLevels, got.DebugSampleRate)
+		}
+	})
+
+	t.Run("yaml and env overrides", func(t *testing.T) {
+		clearAllConfigEnvVars(t)
+		withTempDir(t, func(dir string) {
+			yaml := `
+logging:
+  level: debug
+  format: text
+  component_levels: registry=warn
+  debug_sample_rate: 5
+`
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+				t.Fatalf("Failed to write config.yaml: %v", err)
+			}
+			t.Setenv("LOG_LEVEL", "warn")
+			t.Setenv("LOG_COMPONENT_LEVELS", "auditlog=error,server=debug")
+
+			result, err := Load()
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			got := result.Config.Logging
+			if got.Level != "warn" || got.Format != "text" || got.ComponentLevels != "auditlog=error,server=debug" || got.DebugSampleRate != 5 {
+				t.Fatalf("Logging levels = %q %q %q %d", got.Level, got.Format, got.ComponentLevels, got.DebugSampleRate)
+			}
+		})
+	})
+
+	invalid := map[string]string{
+		"LOG_LEVEL":             "trace",
+		"LOG_FORMAT":            "xml",
+		"LOG_COMPONENT_LEVELS":  "registry",
+		"LOG_DEBUG_SAMPLE_RATE": "-1",
+	}
+	for key, value := range invalid {
+		t.Run(key, func(t *testing.T) {
+			clearAllConfigEnvVars(t)
+			withTempDir(t, func(_ string) {
+				t.Setenv(key, value)
+				if _, err := Load(); err == nil {
+					t.Fatalf("Load() succeeded with %s=%q", key, value)
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_LoggingFailureMode(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.FailureMode != LogFailureModeDrop || got.SpillDir != "data/audit-spill" ||
+			got.SpillMaxBytes != 100*1024*1024 || got.DrainTimeout != 10 {
+			t.Fatalf("Logging failure settings = %q %q %d %d, want defaults",
+				got.FailureMode, got.SpillDir, got.SpillMaxBytes, got.DrainTimeout)
 		}
 	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_FAILURE_MODE", " Spill ")
+		t.Setenv("LOGGING_SPILL_DIR", "/var/lib/gomodel/spill")
+		t.Setenv("LOGGING_SPILL_MAX_BYTES", "1048576")
+		t.Setenv("LOGGING_DRAIN_TIMEOUT", "3")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.FailureMode != LogFailureModeSpill || got.SpillDir != "/var/lib/gomodel/spill" ||
+			got.SpillMaxBytes != 1048576 || got.DrainTimeout != 3 {
+			t.Fatalf("Logging failure settings = %q %q %d %d", got.FailureMode, got.SpillDir, got.SpillMaxBytes, got.DrainTimeout)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_FAILURE_MODE", "retry-forever")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an unknown logging failure mode")
+		}
+	})
+}
+
+func TestLoad_ProviderSigning(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+providers:
+  internal:
+    type: openai
+    api_key: "sk-internal"
+    base_url: "https://models.internal/v1"
+    signing:
+      type: HMAC-SHA256
+      secret: ${INTERNAL_SIGNING_SECRET}
+      include_timestamp: true
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("INTERNAL_SIGNING_SECRET", "s3cret")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["internal"].Signing
+		want := RequestSigningConfig{
+			Type:             RequestSigningHMACSHA256,
+			Header:           DefaultRequestSigningHeader,
+			Secret:           "s3cret",
+			IncludeTimestamp: true,
+			TimestampHeader:  DefaultRequestSigningTimestampHeader,
+		}
+		if got == nil || *got != want {
+			t.Fatalf("Signing = %+v, want %+v", got, want)
+		}
+	})
+
+	for name, signing := range map[string]string{
+		"unset secret":     "type: hmac-sha256\n      secret: ${UNSET_SIGNING_SECRET}",
+		"unsupported type": "type: rsa\n      secret: s3cret",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  internal:\n    type: openai\n    api_key: sk\n    signing:\n      " + signing + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with invalid signing config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderAcceptEncoding(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+providers:
+  openai:
+    type: openai
+    api_key: "sk-test"
+    accept_encoding: [ZSTD, " br ", gzip, zstd]
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["openai"].AcceptEncoding
+		if want := []string{"zstd", "br", "gzip"}; !slices.Equal(got, want) {
+			t.Fatalf("AcceptEncoding = %v, want %v", got, want)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    accept_encoding: [compress]\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an unsupported accept_encoding")
+		}
+	})
+}
+
+func TestLoad_ProviderTransport(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		caFile := filepath.Join(dir, "ca.pem")
+		if err := os.WriteFile(caFile, []byte("placeholder"), 0644); err != nil {
+			t.Fatalf("Failed to write CA file: %v", err)
+		}
+		yaml := `
+providers:
+  internal:
+    type: openai
+    api_key: "sk-internal"
+    proxy_url: " socks5://proxy.corp:1080 "
+    no_proxy: [localhost, .corp]
+    tls:
+      ca_file: ` + caFile + `
+      insecure_skip_verify: true
+      unsafe: true
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["internal"].TransportOptions()
+		if got.ProxyURL != "socks5://proxy.corp:1080" || !slices.Equal(got.NoProxy, []string{"localhost", ".corp"}) {
+			t.Fatalf("unexpected proxy settings: %+v", got)
+		}
+		if got.CAFile != caFile || !got.InsecureSkipVerify {
+			t.Fatalf("unexpected TLS settings: %+v", got)
+		}
+	})
+
+	for name, transport := range map[string]string{
+		"unsupported scheme":      "proxy_url: ftp://proxy.corp:21",
+		"proxy without host":      "proxy_url: http://",
+		"insecure without unsafe": "tls:\n      insecure_skip_verify: true",
+		"missing CA bundle":       "tls:\n      ca_file: /nonexistent/ca.pem",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  internal:\n    type: openai\n    api_key: sk\n    " + transport + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with invalid transport config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderPacing(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    pacing:\n      requests_per_second: 5\n      tokens_per_second: 20000\n      on_timeout: \" FAIL \"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["openai"].Pacing
+		if got == nil || got.RequestsPerSecond != 5 || got.TokensPerSecond != 20000 {
+			t.Fatalf("Pacing = %+v, want the configured rates", got)
+		}
+		if got.MaxWait != DefaultPacingMaxWait || got.OnTimeout != PacingOnTimeoutFail {
+			t.Fatalf("Pacing = %+v, want the default max wait and the fail policy", got)
+		}
+	})
+
+	for name, pacing := range map[string]string{
+		"no rate":           "max_wait: 1s",
+		"negative rate":     "requests_per_second: -1",
+		"negative max wait": "requests_per_second: 1\n      max_wait: -1s",
+		"unknown policy":    "requests_per_second: 1\n      on_timeout: drop",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    pacing:\n      " + pacing + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid pacing config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderConcurrency(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    concurrency:\n      max_in_flight: 8\n      model_weights:\n        gpt-4o: 3\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["openai"].Concurrency
+		if got == nil || got.MaxInFlight != 8 || got.ModelWeights["gpt-4o"] != 3 {
+			t.Fatalf("Concurrency = %+v, want the configured limit and weight", got)
+		}
+		if got.MaxWait != DefaultConcurrencyMaxWait {
+			t.Fatalf("MaxWait = %s, want the default %s", got.MaxWait, DefaultConcurrencyMaxWait)
+		}
+	})
+
+	for name, concurrency := range map[string]string{
+		"no limit":          "max_wait: 1s",
+		"negative limit":    "max_in_flight: -1",
+		"negative max wait": "max_in_flight: 1\n      max_wait: -1s",
+		"zero weight":       "max_in_flight: 1\n      model_weights:\n        gpt-4o: 0",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    concurrency:\n      " + concurrency + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid concurrency config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderQuirks(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    quirks:\n      disable: [reasoning_chat_params]\n      rules:\n        - name: stream_options\n          endpoints: [/chat/completions]\n          omit_empty: [stream_options]\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["openai"].Quirks
+		if got == nil || len(got.Disable) != 1 || len(got.Rules) != 1 || got.Rules[0].OmitEmpty[0] != "stream_options" {
+			t.Fatalf("Quirks = %+v, want the configured overrides", got)
+		}
+	})
+
+	for name, quirks := range map[string]string{
+		"unnamed rule":       "rules:\n        - drop: [user]",
+		"duplicate rule":     "rules:\n        - name: a\n          drop: [user]\n        - name: a\n          drop: [n]",
+		"empty rule":         "rules:\n        - name: a\n          models: [gpt-*]",
+		"bad model pattern":  "rules:\n        - name: a\n          models: [\"gpt-[\"]\n          drop: [user]",
+		"relative endpoint":  "rules:\n        - name: a\n          endpoints: [chat/completions]\n          drop: [user]",
+		"array rename":       "rules:\n        - name: a\n          rename:\n            messages.#.name: messages.#.user",
+		"empty path segment": "rules:\n        - name: a\n          drop: [reasoning..effort]",
+		"nested known field": "known_fields: [reasoning.effort]",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    quirks:\n      " + quirks + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid quirks config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderExtraRequestParams(t *testing.T) {
+	clearAllConfigEnvVars(t)
+	t.Setenv("CF_ACCESS_SECRET", "cf-secret")
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    extra_headers:\n      cf-access-client-secret: ${CF_ACCESS_SECRET}\n    extra_query:\n      route: eu\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.RawProviders["openai"]
+		if got.ExtraHeaders["Cf-Access-Client-Secret"] != "cf-secret" || got.ExtraQuery["route"] != "eu" {
+			t.Fatalf("ExtraHeaders = %v, ExtraQuery = %v, want the expanded header and the query parameter", got.ExtraHeaders, got.ExtraQuery)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  anthropic:\n    type: anthropic\n    api_key: sk\n    extra_headers_override: true\n    extra_headers:\n      anthropic-version: \"2024-01-01\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err != nil {
+			t.Fatalf("Load() failed with extra_headers_override: %v", err)
+		}
+	})
+
+	for name, extra := range map[string]string{
+		"provider header":   "extra_headers:\n      Authorization: Bearer other",
+		"signing header":    "signing:\n      type: hmac-sha256\n      secret: s\n    extra_headers:\n      X-Signature: x",
+		"client header":     "extra_headers_override: true\n    extra_headers:\n      Host: example.com",
+		"invalid name":      "extra_headers:\n      \"X Bad\": x",
+		"CRLF in value":     "extra_headers:\n      X-Route: \"a\\r\\nX-Injected: b\"",
+		"empty query param": "extra_query:\n      \"\": x",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    " + extra + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with invalid extra request params")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_ProviderDataResidency(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai-eu:\n    type: openai\n    api_key: sk\n    data_residency: \" EU \"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.RawProviders["openai-eu"].DataResidency; got != "eu" {
+			t.Fatalf("DataResidency = %q, want eu", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    data_residency: eu/west\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an invalid data_residency")
+		}
+	})
+}
+
+func TestLoad_Chaos(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+chaos:
+  rules:
+    - name: slow-chat
+      path: /v1/chat/completions
+      effect: latency
+      latency: 2s
+      probability: 0.5
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Chaos
+		if got.Enabled {
+			t.Fatal("Chaos.Enabled = true, want fault injection off unless explicitly enabled")
+		}
+		if len(got.Rules) != 1 || got.Rules[0].Latency != 2*time.Second || got.Rules[0].Probability != 0.5 {
+			t.Fatalf("Chaos.Rules = %+v, want the slow-chat rule", got.Rules)
+		}
+
+		t.Setenv("CHAOS_ENABLED", "true")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if !result.Config.Chaos.Enabled {
+			t.Fatal("Chaos.Enabled = false, want CHAOS_ENABLED to enable it")
+		}
+	})
+}
+
+func TestLoad_ResponseSanitization(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.ResponseSanitization; got.Enabled || got.MaxMappings != 100000 {
+			t.Fatalf("ResponseSanitization = %+v, want disabled with 100000 mappings", got)
+		}
+
+		t.Setenv("RESPONSE_SANITIZATION_ENABLED", "true")
+		t.Setenv("RESPONSE_SANITIZATION_MAX_MAPPINGS", "50")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.ResponseSanitization; !got.Enabled || got.MaxMappings != 50 {
+			t.Fatalf("ResponseSanitization = %+v, want enabled with 50 mappings", got)
+		}
+
+		t.Setenv("RESPONSE_SANITIZATION_MAX_MAPPINGS", "0")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "RESPONSE_SANITIZATION_MAX_MAPPINGS") {
+			t.Fatalf("Load() error = %v, want an error naming RESPONSE_SANITIZATION_MAX_MAPPINGS", err)
+		}
+	})
+}
+
+func TestLoad_Maintenance(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Maintenance; got.Enabled || got.RetryAfter != 60*time.Second {
+			t.Fatalf("Maintenance = %+v, want disabled with a 60s Retry-After", got)
+		}
+
+		t.Setenv("MAINTENANCE_ENABLED", "true")
+		t.Setenv("MAINTENANCE_MESSAGE", "rotating keys")
+		t.Setenv("MAINTENANCE_RETRY_AFTER", "30s")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Maintenance; !got.Enabled || got.Message != "rotating keys" || got.RetryAfter != 30*time.Second {
+			t.Fatalf("Maintenance = %+v, want enabled with the env message and 30s", got)
+		}
+
+		t.Setenv("MAINTENANCE_RETRY_AFTER", "0s")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "MAINTENANCE_RETRY_AFTER") {
+			t.Fatalf("Load() error = %v, want an error naming MAINTENANCE_RETRY_AFTER", err)
+		}
+	})
+}
+
+func TestLoad_StreamLimits(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.StreamLimits; got.MaxBytes != 0 || got.MaxDuration != 0 || got.Grace != 10*time.Second {
+			t.Fatalf("StreamLimits = %+v, want no limits with a 10s grace", got)
+		}
+
+		yaml := `
+stream_limits:
+  max_bytes: 104857600
+  models:
+    - model: o3
+      max_duration: 30m
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("STREAM_LIMITS_MAX_DURATION", "10m")
+		t.Setenv("STREAM_LIMITS_GRACE", "5s")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.StreamLimits
+		if got.MaxBytes != 104857600 || got.MaxDuration != 10*time.Minute || got.Grace != 5*time.Second {
+			t.Fatalf("StreamLimits = %+v", got)
+		}
+		if len(got.Models) != 1 || got.Models[0].Model != "o3" || got.Models[0].MaxDuration != 30*time.Minute {
+			t.Fatalf("StreamLimits.Models = %+v", got.Models)
+		}
+
+		t.Setenv("STREAM_LIMITS_MAX_BYTES", "-1")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "STREAM_LIMITS_MAX_BYTES") {
+			t.Fatalf("Load() error = %v, want a STREAM_LIMITS_MAX_BYTES error", err)
+		}
+	})
+}
+
+func TestLoad_Deprecations(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Deprecations; got.Enabled || got.AfterSunset != "warn" || got.WarnInterval != time.Hour {
+			t.Fatalf("Deprecations = %+v, want disabled with warn and 1h", got)
+		}
+
+		yaml := `
+deprecations:
+  enabled: true
+  file: deprecations.yaml
+  models:
+    - provider: openai
+      model: gpt-4-0613
+      sunset: "2025-06-06"
+      replacement: gpt-4o
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("DEPRECATIONS_AFTER_SUNSET", "reject")
+		t.Setenv("DEPRECATIONS_WARN_INTERVAL", "10m")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Deprecations
+		if !got.Enabled || got.File != "deprecations.yaml" || got.AfterSunset != "reject" || got.WarnInterval != 10*time.Minute {
+			t.Fatalf("Deprecations = %+v", got)
+		}
+		want := ModelDeprecationConfig{Provider: "openai", Model: "gpt-4-0613", Sunset: "2025-06-06", Replacement: "gpt-4o"}
+		if len(got.Models) != 1 || got.Models[0] != want {
+			t.Fatalf("Deprecations.Models = %+v", got.Models)
+		}
+	})
+}
+
+func TestLoad_AccessLog(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		want := AccessLogConfig{Format: "combined", Output: "stdout", MaxSizeMB: 100, MaxAge: 24 * time.Hour, MaxBackups: 7}
+		if got := result.Config.AccessLog; got.Enabled || got.Format != want.Format || got.Output != want.Output ||
+			got.MaxSizeMB != want.MaxSizeMB || got.MaxAge != want.MaxAge || got.MaxBackups != want.MaxBackups || len(got.Fields) != 0 {
+			t.Fatalf("AccessLog = %+v, want defaults %+v", got, want)
+		}
+
+		yaml := `
+access_log:
+  enabled: true
+  format: json
+  output: /var/log/gomodel/access.log
+  fields: [time, status, request_id]
+  max_size_mb: 50
+  max_age: 12h
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("ACCESS_LOG_MAX_BACKUPS", "3")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.AccessLog
+		if !got.Enabled || got.Format != "json" || got.Output != "/var/log/gomodel/access.log" ||
+			got.MaxSizeMB != 50 || got.MaxAge != 12*time.Hour || got.MaxBackups != 3 {
+			t.Fatalf("AccessLog = %+v", got)
+		}
+		if len(got.Fields) != 3 || got.Fields[2] != "request_id" {
+			t.Fatalf("AccessLog.Fields = %q", got.Fields)
+		}
+	})
+}
+
+func TestLoad_SelfProtection(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.SelfProtection; got.Enabled || got.Action != "reject" {
+			t.Fatalf("SelfProtection = %+v, want disabled with the reject action", got)
+		}
+
+		t.Setenv("SELF_PROTECTION_ENABLED", "true")
+		t.Setenv("SELF_PROTECTION_ACTION", "flag")
+		t.Setenv("SELF_PROTECTION_PATTERNS", "gomodel:8080,gateway.internal")
+		t.Setenv("SELF_PROTECTION_WEBHOOK_URL", "https://hooks.example.com/gomodel")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.SelfProtection
+		if !got.Enabled || got.Action != "flag" || got.WebhookURL != "https://hooks.example.com/gomodel" {
+			t.Fatalf("SelfProtection = %+v", got)
+		}
+		if len(got.Patterns) != 2 || got.Patterns[0] != "gomodel:8080" || got.Patterns[1] != "gateway.internal" {
+			t.Fatalf("SelfProtection.Patterns = %q", got.Patterns)
+		}
+	})
+}
+
+func TestLoad_SecretRedaction(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.SecretRedaction; got.Enabled || got.Action != "mask" {
+			t.Fatalf("SecretRedaction = %+v, want disabled with the mask action", got)
+		}
+
+		yaml := `
+secret_redaction:
+  detectors:
+    jwt: log
+    private_key: block
+  patterns:
+    - name: internal_token
+      pattern: "itk_[a-z0-9]{32}"
+      action: block
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("SECRET_REDACTION_ENABLED", "true")
+		t.Setenv("SECRET_REDACTION_ACTION", "log")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.SecretRedaction
+		if !got.Enabled || got.Action != "log" || got.Detectors["jwt"] != "log" || got.Detectors["private_key"] != "block" {
+			t.Fatalf("SecretRedaction = %+v", got)
+		}
+		if len(got.Patterns) != 1 || got.Patterns[0] != (SecretPatternConfig{Name: "internal_token", Pattern: "itk_[a-z0-9]{32}", Action: "block"}) {
+			t.Fatalf("SecretRedaction.Patterns = %+v", got.Patterns)
+		}
+	})
+}
+
+func TestLoad_ResponseTransforms(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+response_transforms:
+  pipelines:
+    - name: reasoning
+      model: deepseek-r1
+      transformers:
+        - type: strip_xml_tags
+          tags: ["think"]
+        - type: truncate_chars
+          max_chars: 4000
+          marker: " [truncated]"
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.ResponseTransforms
+		if got.StreamWindow != 256 {
+			t.Fatalf("ResponseTransforms.StreamWindow = %d, want 256", got.StreamWindow)
+		}
+		if len(got.Pipelines) != 1 || got.Pipelines[0].Model != "deepseek-r1" || len(got.Pipelines[0].Transformers) != 2 {
+			t.Fatalf("ResponseTransforms.Pipelines = %+v", got.Pipelines)
+		}
+		if truncate := got.Pipelines[0].Transformers[1]; truncate.MaxChars != 4000 || truncate.Marker != " [truncated]" {
+			t.Fatalf("truncate transformer = %+v", truncate)
+		}
+
+		t.Setenv("RESPONSE_TRANSFORMS_STREAM_WINDOW", "64")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.ResponseTransforms.StreamWindow; got != 64 {
+			t.Fatalf("ResponseTransforms.StreamWindow = %d, want 64", got)
+		}
+	})
+}
+
+func TestLoad_CapabilityProbe(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.CapabilityProbe.TokenBudget; got != 1000 {
+			t.Fatalf("CapabilityProbe.TokenBudget = %d, want 1000", got)
+		}
+
+		t.Setenv("CAPABILITY_PROBE_TOKEN_BUDGET", "-1")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "CAPABILITY_PROBE_TOKEN_BUDGET") {
+			t.Fatalf("Load() error = %v, want an error naming CAPABILITY_PROBE_TOKEN_BUDGET", err)
+		}
+	})
+}
+
+func TestLoad_KeyHealth(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.KeyHealth
+		if got.Enabled || got.Interval != 15*time.Minute || got.Timeout != 10*time.Second || got.FailureThreshold != 2 || got.ExpiryWarning != 168*time.Hour {
+			t.Fatalf("KeyHealth = %+v, want disabled 15m/10s/2/168h defaults", got)
+		}
+
+		t.Setenv("KEY_HEALTH_ENABLED", "true")
+		t.Setenv("KEY_HEALTH_INTERVAL", "5m")
+		t.Setenv("KEY_HEALTH_FAILURE_THRESHOLD", "3")
+		t.Setenv("KEY_HEALTH_WEBHOOK_URL", "https://hooks.example.com/keys")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got = result.Config.KeyHealth
+		if !got.Enabled || got.Interval != 5*time.Minute || got.FailureThreshold != 3 || got.WebhookURL != "https://hooks.example.com/keys" {
+			t.Fatalf("KeyHealth = %+v, want the env overrides", got)
+		}
+
+		t.Setenv("KEY_HEALTH_INTERVAL", "10s")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "key_health.interval") {
+			t.Fatalf("Load() error = %v, want an error naming key_health.interval", err)
+		}
+
+		t.Setenv("KEY_HEALTH_INTERVAL", "5m")
+		t.Setenv("KEY_HEALTH_WEBHOOK_URL", "hooks.example.com")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "key_health.webhook_url") {
+			t.Fatalf("Load() error = %v, want an error naming key_health.webhook_url", err)
+		}
+	})
+}
+
+func TestLoad_LoggingBodyCaptureLimits(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.MaxRequestBodyBytes != 1024*1024 || got.MaxResponseBodyBytes != 1024*1024 || len(got.BodyCapturePaths) != 0 {
+			t.Fatalf("Logging body capture = %d %d %v, want 1 MiB defaults without path overrides",
+				got.MaxRequestBodyBytes, got.MaxResponseBodyBytes, got.BodyCapturePaths)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+logging:
+  max_request_body_bytes: 65536
+  body_capture_paths:
+    /v1/responses:
+      request: 4194304
+      response: 8388608
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("LOGGING_MAX_RESPONSE_BODY_BYTES", "2097152")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.MaxRequestBodyBytes != 65536 || got.MaxResponseBodyBytes != 2097152 {
+			t.Fatalf("Logging body capture = %d %d, want 65536 2097152", got.MaxRequestBodyBytes, got.MaxResponseBodyBytes)
+		}
+		if want := (LogBodyCaptureLimits{Request: 4194304, Response: 8388608}); got.BodyCapturePaths["/v1/responses"] != want {
+			t.Fatalf("BodyCapturePaths = %v, want /v1/responses override", got.BodyCapturePaths)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_MAX_REQUEST_BODY_BYTES", "16777217")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a request body capture limit above the ceiling")
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+logging:
+  body_capture_paths:
+    v1/chat:
+      request: 1024
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a body capture path that does not start with /")
+		}
+	})
+}
+
+func TestLoad_LoggingStreamSamples(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.StreamSampleRate != 0 || got.StreamSampleOptIn || got.StreamSampleMaxPerDay != 100 || got.StreamSampleMaxBytes != 64*1024*1024 {
+			t.Fatalf("Logging stream samples = %v %v %d %d, want disabled defaults",
+				got.StreamSampleRate, got.StreamSampleOptIn, got.StreamSampleMaxPerDay, got.StreamSampleMaxBytes)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_STREAM_SAMPLE_RATE", "0.05")
+		t.Setenv("LOGGING_STREAM_SAMPLE_OPT_IN", "true")
+		t.Setenv("LOGGING_STREAM_SAMPLE_MAX_PER_DAY", "20")
+		t.Setenv("LOGGING_STREAM_SAMPLE_MAX_BYTES", "1048576")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging
+		if got.StreamSampleRate != 0.05 || !got.StreamSampleOptIn || got.StreamSampleMaxPerDay != 20 || got.StreamSampleMaxBytes != 1048576 {
+			t.Fatalf("Logging stream samples = %v %v %d %d", got.StreamSampleRate, got.StreamSampleOptIn, got.StreamSampleMaxPerDay, got.StreamSampleMaxBytes)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("LOGGING_STREAM_SAMPLE_RATE", "1.5")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a stream sample rate above 1")
+		}
+	})
+}
+
+func TestLoad_LoggingEncryption(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		t.Setenv("AUDIT_KEY_2", "c2Vjb25kLWtleQ==")
+		t.Setenv("LOGGING_ENCRYPTION_HEADERS", "true")
+		yaml := `
+logging:
+  encryption:
+    keys:
+      - id: k1
+        secret: Zmlyc3Qta2V5
+      - id: k2
+        secret: ${AUDIT_KEY_2}
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Logging.Encryption
+		if len(got.Keys) != 2 || got.Keys[0].ID != "k1" || got.Keys[1].ID != "k2" || got.Keys[1].Secret != "c2Vjb25kLWtleQ==" {
+			t.Fatalf("Logging.Encryption.Keys = %+v", got.Keys)
+		}
+		if !got.Headers {
+			t.Fatal("Logging.Encryption.Headers = false, want true")
+		}
+	})
+}
+
+func TestLoad_StorageAutoMigrate(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if !result.Config.Storage.AutoMigrate {
+			t.Fatal("Storage.AutoMigrate = false by default, want true")
+		}
+
+		t.Setenv("STORAGE_AUTO_MIGRATE", "false")
+		result, err = Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if result.Config.Storage.AutoMigrate {
+			t.Fatal("Storage.AutoMigrate = true, want false from env")
+		}
+	})
+}
+
+func TestLoad_Scoreboard(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Scoreboard
+		if !got.Enabled || got.MaxModels != 100 {
+			t.Fatalf("Scoreboard = %+v, want enabled with 100 models", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("SCOREBOARD_ENABLED", "false")
+		t.Setenv("SCOREBOARD_MAX_MODELS", "25")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Scoreboard
+		if got.Enabled || got.MaxModels != 25 {
+			t.Fatalf("Scoreboard = %+v, want disabled with 25 models", got)
+		}
+	})
+}
+
+func TestLoad_ContextOverflow(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.ContextOverflow
+		if got.Strategy != ContextOverflowOff || len(got.Models) != 0 {
+			t.Fatalf("ContextOverflow = %+v, want off with no model overrides", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+context_overflow:
+  strategy: reject
+  models:
+    " gpt-4o ": Truncate_Oldest
+    anthropic/claude-sonnet-4: middle_out
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("CONTEXT_OVERFLOW_STRATEGY", "middle_out")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.ContextOverflow
+		if got.Strategy != ContextOverflowMiddleOut {
+			t.Fatalf("Strategy = %q, want env override middle_out", got.Strategy)
+		}
+		if got.Models["gpt-4o"] != ContextOverflowTruncateOldest || got.Models["anthropic/claude-sonnet-4"] != ContextOverflowMiddleOut {
+			t.Fatalf("Models = %v, want normalized per-model strategies", got.Models)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("CONTEXT_OVERFLOW_STRATEGY", "summarize")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an unknown context overflow strategy")
+		}
+	})
+}
+
+func TestLoad_PromptCompression(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.PromptCompression
+		if len(got.Passes) != 0 || len(got.Models) != 0 || got.DataURIMinBytes != 1024 {
+			t.Fatalf("PromptCompression = %+v, want no passes and a 1024 byte data URI threshold", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+prompt_compression:
+  passes: [whitespace]
+  data_uri_min_bytes: 4096
+  models:
+    " gpt-4o ": [Dedupe, data_uri]
+    anthropic/claude-sonnet-4: []
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("PROMPT_COMPRESSION_PASSES", "whitespace, include_system")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.PromptCompression
+		if !slices.Equal(got.Passes, []string{"whitespace", "include_system"}) {
+			t.Fatalf("Passes = %v, want env override", got.Passes)
+		}
+		if got.DataURIMinBytes != 4096 {
+			t.Fatalf("DataURIMinBytes = %d, want 4096", got.DataURIMinBytes)
+		}
+		if !slices.Equal(got.Models["gpt-4o"], []string{"dedupe", "data_uri"}) {
+			t.Fatalf("Models[gpt-4o] = %v, want normalized passes", got.Models["gpt-4o"])
+		}
+		if passes, ok := got.Models["anthropic/claude-sonnet-4"]; !ok || len(passes) != 0 {
+			t.Fatalf("Models = %v, want an empty pass list kept to disable compression", got.Models)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("PROMPT_COMPRESSION_PASSES", "whitespace,summarize")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an unknown prompt compression pass")
+		}
+	})
+}
+
+func TestLoad_FirstToken(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.FirstToken; got.Timeout != 0 || len(got.Providers) != 0 || len(got.Models) != 0 {
+			t.Fatalf("FirstToken = %+v, want the deadline off", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := `
+first_token:
+  timeout: 10s
+  providers:
+    " openai ": 5s
+  models:
+    azure/gpt-4o: 3s
+    gpt-4o-mini: 0s
+`
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("FIRST_TOKEN_TIMEOUT", "8s")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.FirstToken
+		if got.Timeout != 8*time.Second {
+			t.Fatalf("Timeout = %s, want env override", got.Timeout)
+		}
+		if got.Providers["openai"] != 5*time.Second {
+			t.Fatalf("Providers = %v, want trimmed openai key", got.Providers)
+		}
+		if timeout, ok := got.Models["gpt-4o-mini"]; got.Models["azure/gpt-4o"] != 3*time.Second || !ok || timeout != 0 {
+			t.Fatalf("Models = %v, want azure/gpt-4o and a zero entry kept to turn the deadline off", got.Models)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("FIRST_TOKEN_TIMEOUT", "-1s")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a negative first-token timeout")
+		}
+	})
+}
+
+func TestLoad_DefaultMaxTokens(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.DefaultMaxTokens
+		if got.Default != 0 || len(got.Models) != 0 || got.Dynamic.Enabled {
+			t.Fatalf("DefaultMaxTokens = %+v, want no defaults", got)
+		}
+		if got.Dynamic.Percentile != 95 || got.Dynamic.Headroom != 1.5 || got.Dynamic.Window != 24*time.Hour || got.Dynamic.MinSamples != 50 {
+			t.Fatalf("Dynamic defaults = %+v", got.Dynamic)
+		}
+	})
+
+	t.Run("yaml and env overrides", func(t *testing.T) {
+		clearAllConfigEnvVars(t)
+
+		withTempDir(t, func(dir string) {
+			yaml := `
+default_max_tokens:
+  default: 4096
+  models:
+    " gpt-4o-mini ": 1024
+    anthropic/claude-sonnet-4: 8192
+  dynamic:
+    enabled: true
+    percentile: 99
+`
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+				t.Fatalf("Failed to write config.yaml: %v", err)
+			}
+			t.Setenv("DEFAULT_MAX_TOKENS", "2048")
+			t.Setenv("DEFAULT_MAX_TOKENS_DYNAMIC_WINDOW", "6h")
+
+			result, err := Load()
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			got := result.Config.DefaultMaxTokens
+			if got.Default != 2048 {
+				t.Fatalf("Default = %d, want env override", got.Default)
+			}
+			if got.Models["gpt-4o-mini"] != 1024 || got.Models["anthropic/claude-sonnet-4"] != 8192 {
+				t.Fatalf("Models = %v, want trimmed keys", got.Models)
+			}
+			if !got.Dynamic.Enabled || got.Dynamic.Percentile != 99 || got.Dynamic.Window != 6*time.Hour || got.Dynamic.MinSamples != 50 {
+				t.Fatalf("Dynamic = %+v, want YAML and env overrides", got.Dynamic)
+			}
+		})
+	})
+
+	for name, yaml := range map[string]string{
+		"negative default":     "default_max_tokens:\n  default: -1\n",
+		"zero model default":   "default_max_tokens:\n  models:\n    gpt-4o: 0\n",
+		"percentile above 100": "default_max_tokens:\n  dynamic:\n    percentile: 101\n",
+		"headroom below 1":     "default_max_tokens:\n  dynamic:\n    headroom: 0.5\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearAllConfigEnvVars(t)
+
+			withTempDir(t, func(dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid default_max_tokens config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_Memory(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Memory
+		if got.BudgetBytes != 0 || got.RSSCeilingBytes != 0 || got.RSSWarnRatio != 0.9 || got.CheckInterval != 30*time.Second {
+			t.Fatalf("Memory = %+v, want disabled limits with defaults", got)
+		}
+	})
+
+	t.Run("yaml and env overrides", func(t *testing.T) {
+		clearAllConfigEnvVars(t)
+
+		withTempDir(t, func(dir string) {
+			yaml := `
+memory:
+  budget_bytes: 268435456
+  rss_warn_ratio: 0.8
+`
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+				t.Fatalf("Failed to write config.yaml: %v", err)
+			}
+			t.Setenv("MEMORY_RSS_CEILING_BYTES", "1073741824")
+			t.Setenv("MEMORY_CHECK_INTERVAL", "5s")
+
+			result, err := Load()
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			got := result.Config.Memory
+			if got.BudgetBytes != 268435456 || got.RSSCeilingBytes != 1073741824 || got.RSSWarnRatio != 0.8 || got.CheckInterval != 5*time.Second {
+				t.Fatalf("Memory = %+v, want YAML and env overrides", got)
+			}
+		})
+	})
+
+	for name, yaml := range map[string]string{
+		"negative budget":    "memory:\n  budget_bytes: -1\n",
+		"negative ceiling":   "memory:\n  rss_ceiling_bytes: -1\n",
+		"warn ratio above 1": "memory:\n  rss_warn_ratio: 1.5\n",
+		"zero interval":      "memory:\n  check_interval: 0s\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearAllConfigEnvVars(t)
+
+			withTempDir(t, func(dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid memory config")
+				}
+			})
+		})
+	}
+}
+
+func TestLoad_Deferred(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Deferred
+		if got.Enabled || got.TTL != 24*time.Hour || got.MaxAttempts != 10 ||
+			got.InitialBackoff != 10*time.Second || got.MaxBackoff != 5*time.Minute || got.PollInterval != 5*time.Second {
+			t.Fatalf("Deferred defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "deferred:\n  enabled: true\n  ttl: 1h\n  max_attempts: 3\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("DEFERRED_MAX_BACKOFF", "30s")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Deferred
+		if !got.Enabled || got.TTL != time.Hour || got.MaxAttempts != 3 || got.MaxBackoff != 30*time.Second {
+			t.Fatalf("Deferred = %+v, want YAML and env overrides", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("DEFERRED_MAX_BACKOFF", "1s")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with max_backoff below initial_backoff")
+		}
+	})
+
+	t.Run("callbacks", func(t *testing.T) {
+		clearAllConfigEnvVars(t)
+
+		withTempDir(t, func(dir string) {
+			yaml := "deferred:\n  callbacks:\n    enabled: true\n    allowed_hosts: [\" Hooks.Example.com \", \"*.partner.io\"]\n"
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+				t.Fatalf("Failed to write config.yaml: %v", err)
+			}
+
+			result, err := Load()
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			got := result.Config.Deferred.Callbacks
+			if !got.Enabled || !slices.Equal(got.AllowedHosts, []string{"hooks.example.com", "*.partner.io"}) {
+				t.Fatalf("Callbacks = %+v, want normalized allowed hosts", got)
+			}
+			for host, want := range map[string]bool{
+				"hooks.example.com": true,
+				"HOOKS.example.com": true,
+				"api.partner.io":    true,
+				"partner.io":        false,
+				"evil.example.com":  false,
+				"":                  false,
+			} {
+				if got.AllowsHost(host) != want {
+					t.Errorf("AllowsHost(%q) = %v, want %v", host, !want, want)
+				}
+			}
+		})
+
+		withTempDir(t, func(_ string) {
+			t.Setenv("DEFERRED_CALLBACKS_ENABLED", "true")
+			if _, err := Load(); err == nil {
+				t.Fatal("Load() succeeded with callbacks enabled and no allowed hosts")
+			}
+		})
+
+		withTempDir(t, func(_ string) {
+			t.Setenv("DEFERRED_CALLBACK_ALLOWED_HOSTS", "https://hooks.example.com/path")
+			if _, err := Load(); err == nil {
+				t.Fatal("Load() succeeded with a URL as an allowed host")
+			}
+		})
+	})
+}
+
+func TestLoad_UsageJobs(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Usage
+		if got.JobBatchSize != 500 || got.JobBatchDelay != 100*time.Millisecond {
+			t.Fatalf("usage job defaults = %d, %s", got.JobBatchSize, got.JobBatchDelay)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "usage:\n  job_batch_size: 200\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("USAGE_JOB_BATCH_DELAY", "0s")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Usage
+		if got.JobBatchSize != 200 || got.JobBatchDelay != 0 {
+			t.Fatalf("usage jobs = %d, %s, want YAML and env overrides", got.JobBatchSize, got.JobBatchDelay)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("USAGE_JOB_BATCH_SIZE", "1000")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with job_batch_size above 900")
+		}
+	})
+}
+
+func TestLoad_UsageContentAnalysis(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Usage
+		if !got.ContentAnalysis || got.ContentSampleChars != 500 {
+			t.Fatalf("content analysis defaults = %t, %d", got.ContentAnalysis, got.ContentSampleChars)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "usage:\n  content_sample_chars: 200\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("USAGE_CONTENT_ANALYSIS", "false")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Usage
+		if got.ContentAnalysis || got.ContentSampleChars != 200 {
+			t.Fatalf("content analysis = %t, %d, want YAML and env overrides", got.ContentAnalysis, got.ContentSampleChars)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("USAGE_CONTENT_SAMPLE_CHARS", "0")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with content_sample_chars 0")
+		}
+	})
+}
+
+func TestLoad_Idempotency(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Idempotency
+		if got.Enabled || got.TTL != 24*time.Hour || got.MaxEntries != 10000 ||
+			got.MaxBodyBytes != 1<<20 || got.StreamDuplicates != IdempotencyStreamAttach {
+			t.Fatalf("Idempotency defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "idempotency:\n  enabled: true\n  ttl: 1h\n  stream_duplicates: Reject\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("IDEMPOTENCY_MAX_ENTRIES", "50")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Idempotency
+		if !got.Enabled || got.TTL != time.Hour || got.MaxEntries != 50 || got.StreamDuplicates != IdempotencyStreamReject {
+			t.Fatalf("Idempotency = %+v, want YAML and env overrides", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("IDEMPOTENCY_STREAM_DUPLICATES", "queue")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with an unknown stream_duplicates mode")
+		}
+	})
+}
+
+func TestLoad_UpstreamHeaders(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(dir string) {
+		yaml := "upstream_response_headers:\n  allow: [openai-processing-ms]\n" +
+			"providers:\n  anthropic:\n    type: anthropic\n    api_key: sk\n    upstream_response_headers: [\" anthropic-ratelimit-*\"]\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("UPSTREAM_RESPONSE_HEADERS_PREFIX", "X-Upstream-")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.UpstreamHeaders
+		if len(got.Allow) != 1 || got.Allow[0] != "openai-processing-ms" || got.Prefix != "X-Upstream-" {
+			t.Fatalf("UpstreamHeaders = %+v, want YAML allow and env prefix", got)
+		}
+		if provider := result.RawProviders["anthropic"].UpstreamResponseHeaders; len(provider) != 1 || provider[0] != "anthropic-ratelimit-*" {
+			t.Fatalf("provider UpstreamResponseHeaders = %v, want the trimmed pattern", provider)
+		}
+	})
+
+	for name, env := range map[string]map[string]string{
+		"denied header":  {"UPSTREAM_RESPONSE_HEADERS_ALLOW": "x-ratelimit-limit-requests,Set-Cookie"},
+		"bare wildcard":  {"UPSTREAM_RESPONSE_HEADERS_ALLOW": "*"},
+		"invalid name":   {"UPSTREAM_RESPONSE_HEADERS_ALLOW": "x:bad"},
+		"invalid prefix": {"UPSTREAM_RESPONSE_HEADERS_PREFIX": "X Upstream "},
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(_ string) {
+				for key, value := range env {
+					t.Setenv(key, value)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid upstream header config")
+				}
+			})
+		})
+	}
+
+	withTempDir(t, func(dir string) {
+		yaml := "providers:\n  openai:\n    type: openai\n    api_key: sk\n    upstream_response_headers: [authorization]\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a denied provider upstream header")
+		}
+	})
+}
+
+func TestLoad_AnomalyDetection(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Anomalies
+		if got.Enabled || got.BaselineWindow != 24*time.Hour || got.MinBaseline != time.Hour || got.Cooldown != 15*time.Minute ||
+			got.MaxSeries != 500 || got.MaxRecords != 1000 || len(got.Rules) != len(DefaultAnomalyRules()) {
+			t.Fatalf("Anomalies defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "anomaly_detection:\n  enabled: true\n  rules:\n    - metric: Cost_Per_Hour\n      scope: key\n      above: 50\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("ANOMALY_DETECTION_BASELINE_WINDOW", "6h")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Anomalies
+		want := AnomalyRule{Metric: AnomalyMetricCostPerHour, Scope: AnomalyScopeKey, Above: 50}
+		if !got.Enabled || got.BaselineWindow != 6*time.Hour || len(got.Rules) != 1 || got.Rules[0] != want {
+			t.Fatalf("Anomalies = %+v, want YAML rules and env baseline window", got)
+		}
+	})
+
+	for name, rule := range map[string]string{
+		"unknown metric":   "metric: latency\n      above: 1",
+		"unknown scope":    "metric: cost_per_hour\n      scope: user\n      above: 1",
+		"no threshold":     "metric: cost_per_hour",
+		"multiple too low": "metric: cost_per_hour\n      multiple: 0.5",
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(dir string) {
+				yaml := "anomaly_detection:\n  rules:\n    - " + rule + "\n"
+				if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+					t.Fatalf("Failed to write config.yaml: %v", err)
+				}
+				if _, err := Load(); err == nil {
+					t.Fatal("Load() succeeded with an invalid anomaly rule")
+				}
+			})
+		})
+	}
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("ANOMALY_DETECTION_MIN_BASELINE", "24h")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with min_baseline equal to baseline_window")
+		}
+	})
+}
+
+func TestLoad_WebSocket(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.WebSocket
+		if got.Enabled || got.PingInterval != 30*time.Second || got.MaxDuration != 30*time.Minute {
+			t.Fatalf("WebSocket defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "websocket:\n  enabled: true\n  ping_interval: 10s\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("WEBSOCKET_MAX_DURATION", "5m")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.WebSocket
+		if !got.Enabled || got.PingInterval != 10*time.Second || got.MaxDuration != 5*time.Minute {
+			t.Fatalf("WebSocket = %+v, want YAML ping interval and env max duration", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("WEBSOCKET_PING_INTERVAL", "1h")
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "websocket.ping_interval") {
+			t.Fatalf("Load() error = %v, want one naming websocket.ping_interval", err)
+		}
+	})
+}
+
+func TestLoad_Moderation(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Moderation
+		if got.Enabled || got.Threshold != 0.5 || got.Action != "block" || got.Timeout != 10*time.Second {
+			t.Fatalf("Moderation defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "moderation:\n  enabled: true\n  model: openai/omni-guard\n  categories:\n    Violence: Flag\n    sexual_minors: block\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("MODERATION_SKIP_KEYS", "key-1, ,key-2")
+		t.Setenv("MODERATION_FAIL_OPEN", "true")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Moderation
+		if !got.Enabled || got.Model != "openai/omni-guard" || !got.FailOpen {
+			t.Fatalf("Moderation = %+v", got)
+		}
+		if got.Categories["violence"] != "flag" || got.Categories["sexual_minors"] != "block" {
+			t.Fatalf("Categories = %v, want normalized names and actions", got.Categories)
+		}
+		if strings.Join(got.SkipKeys, ",") != "key-1,key-2" {
+			t.Fatalf("SkipKeys = %q", got.SkipKeys)
+		}
+	})
+
+	for _, tt := range []struct{ env, value, want string }{
+		{"MODERATION_ENABLED", "true", "moderation.model"},
+		{"MODERATION_ACTION", "warn", "moderation.action"},
+		{"MODERATION_THRESHOLD", "1.5", "moderation.threshold"},
+		{"MODERATION_PATHS", "/v1/embeddings", "moderation.paths"},
+	} {
+		withTempDir(t, func(_ string) {
+			clearAllConfigEnvVars(t)
+			t.Setenv(tt.env, tt.value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() with %s=%s error = %v, want one naming %s", tt.env, tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_ServerTools(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.ServerTools
+		if got.Enabled || got.MaxIterations != 5 || got.Timeout != 10*time.Second || got.MaxOutputBytes != 16384 {
+			t.Fatalf("ServerTools defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "server_tools:\n  enabled: true\n  grants:\n    - tools: [\" calculator \", \"\"]\n      models: [openai/gpt-4o]\n      keys: [key-1]\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("SERVER_TOOLS_MAX_ITERATIONS", "3")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.ServerTools
+		if !got.Enabled || got.MaxIterations != 3 || len(got.Grants) != 1 {
+			t.Fatalf("ServerTools = %+v", got)
+		}
+		if grant := got.Grants[0]; strings.Join(grant.Tools, ",") != "calculator" || grant.Models[0] != "openai/gpt-4o" || grant.Keys[0] != "key-1" {
+			t.Fatalf("grant = %+v, want trimmed tools", grant)
+		}
+	})
+
+	for _, tt := range []struct{ yaml, want string }{
+		{"server_tools:\n  max_iterations: 1\n", "server_tools.max_iterations"},
+		{"server_tools:\n  timeout: 0s\n", "server_tools.timeout"},
+		{"server_tools:\n  grants:\n    - models: [gpt-4o]\n", "server_tools.grants[0].tools"},
+	} {
+		withTempDir(t, func(dir string) {
+			clearAllConfigEnvVars(t)
+			if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(tt.yaml), 0644); err != nil {
+				t.Fatalf("Failed to write config.yaml: %v", err)
+			}
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() with %q error = %v, want one naming %s", tt.yaml, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_PromptCaching(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.PromptCaching
+		if got.Enabled || !got.AutoDetect || got.MinRepeats != 3 || got.MinChars != 4096 || got.MaxPrefixes != 1000 {
+			t.Fatalf("PromptCaching defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "prompt_caching:\n  enabled: true\n  prefixes:\n    - \"You are a support agent.\"\n    - \"  \"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("PROMPT_CACHING_MODELS", "claude-sonnet-4-5, ,openai/gpt-4o")
+		t.Setenv("PROMPT_CACHING_AUTO_DETECT", "false")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.PromptCaching
+		if !got.Enabled || got.AutoDetect {
+			t.Fatalf("PromptCaching = %+v", got)
+		}
+		if strings.Join(got.Models, ",") != "claude-sonnet-4-5,openai/gpt-4o" {
+			t.Fatalf("Models = %q", got.Models)
+		}
+		if len(got.Prefixes) != 1 || got.Prefixes[0] != "You are a support agent." {
+			t.Fatalf("Prefixes = %q, want blank prefixes dropped", got.Prefixes)
+		}
+	})
+
+	for _, tt := range []struct{ env, value, want string }{
+		{"PROMPT_CACHING_MIN_REPEATS", "0", "prompt_caching.min_repeats"},
+		{"PROMPT_CACHING_MIN_CHARS", "0", "prompt_caching.min_chars"},
+		{"PROMPT_CACHING_MAX_PREFIXES", "0", "prompt_caching.max_prefixes"},
+	} {
+		withTempDir(t, func(_ string) {
+			clearAllConfigEnvVars(t)
+			t.Setenv(tt.env, tt.value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() with %s=%s error = %v, want one naming %s", tt.env, tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_AdminMaxQueryDays(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Admin.MaxQueryDays; got != 366 {
+			t.Fatalf("MaxQueryDays = %d, want 366", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("ADMIN_MAX_QUERY_DAYS", "90")
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if got := result.Config.Admin.MaxQueryDays; got != 90 {
+			t.Fatalf("MaxQueryDays = %d, want 90", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("ADMIN_MAX_QUERY_DAYS", "0")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with max_query_days 0")
+		}
+	})
+}
+
+func TestLoad_EmbeddingCache(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Cache.Embeddings
+		if got.Enabled || got.MaxEntries != 100000 || got.MaxBytes != 256<<20 || got.TTL != 0 {
+			t.Fatalf("Cache.Embeddings defaults = %+v", got)
+		}
+	})
+
+	withTempDir(t, func(dir string) {
+		yaml := "cache:\n  embeddings:\n    max_entries: 10\n    models:\n      text-embedding-3-small:\n        enabled: true\n        ttl: 60\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		t.Setenv("EMBEDDING_CACHE_TTL", "3600")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		got := result.Config.Cache.Embeddings
+		model := got.Models["text-embedding-3-small"]
+		if got.Enabled || got.MaxEntries != 10 || got.TTL != 3600 || model.Enabled == nil || !*model.Enabled || model.TTL == nil || *model.TTL != 60 {
+			t.Fatalf("Cache.Embeddings = %+v, want YAML and env overrides", got)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("EMBEDDING_CACHE_MAX_BYTES", "-1")
+		if _, err := Load(); err == nil {
+			t.Fatal("Load() succeeded with a negative max_bytes")
+		}
+	})
+}
+
+func TestLoad_CacheDir(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if result.Config.Cache.Model.Local == nil {
+			t.Error("expected Cache.Model.Local to be set by default")
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("GOMODEL_CACHE_DIR", "/tmp/gomodel-cache")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if result.Config.Cache.Model.Local == nil || result.Config.Cache.Model.Local.CacheDir != "/tmp/gomodel-cache" {
+			t.Errorf("expected Cache.Model.Local.CacheDir=/tmp/gomodel-cache, got %v", result.Config.Cache.Model.Local)
+		}
+	})
+}
+
+func TestLoad_StrictRequestOptions(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if !result.Config.Server.StrictRequestOptions {
+			t.Fatal("StrictRequestOptions = false, want true by default")
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("STRICT_REQUEST_OPTIONS", "false")
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if result.Config.Server.StrictRequestOptions {
+			t.Fatal("StrictRequestOptions = true, want false from env")
+		}
+	})
+}
+
+func TestLoad_LocalCacheBounds(t *testing.T) {
+	clearAllConfigEnvVars(t)
+
+	withTempDir(t, func(_ string) {
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		local := result.Config.Cache.Model.Local
+		if local.MaxAge != DefaultLocalCacheMaxAge || local.MaxSize != DefaultLocalCacheMaxSize {
+			t.Fatalf("bounds = %s, %q; want the defaults", local.MaxAge, local.MaxSize)
+		}
+	})
+
+	withTempDir(t, func(_ string) {
+		t.Setenv("GOMODEL_CACHE_MAX_AGE", "48h")
+		t.Setenv("GOMODEL_CACHE_MAX_SIZE", "8M")
+
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		local := result.Config.Cache.Model.Local
+		if local.MaxAge != 48*time.Hour || local.MaxSize != "8M" {
+			t.Fatalf("bounds = %s, %q; want 48h, 8M", local.MaxAge, local.MaxSize)
+		}
+	})
+
+	for name, tt := range map[string]struct{ env, value, want string }{
+		"negative age": {"GOMODEL_CACHE_MAX_AGE", "-1h", "max_age"},
+		"invalid size": {"GOMODEL_CACHE_MAX_SIZE", "lots", "GOMODEL_CACHE_MAX_SIZE"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTempDir(t, func(_ string) {
+				t.Setenv(tt.env, tt.value)
+				if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("Load() error = %v, want one naming %s", err, tt.want)
+				}
+			})
+		})
+	}
 }
 
 func TestLoad_LoggingOnlyModelInteractionsDefault(t *testing.T) {
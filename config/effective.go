@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maskedValue replaces credentials in the effective config.
+const maskedValue = "********"
+
+// sensitiveKeys are config keys whose values are credentials.
+var sensitiveKeys = map[string]bool{
+	"api_key":    true,
+	"master_key": true,
+	"secret":     true,
+	"password":   true,
+}
+
+// opaqueMaps are config keys whose map values may carry credentials, such as
+// an Authorization header, and are masked entirely.
+var opaqueMaps = map[string]bool{
+	"extra_headers": true,
+	"extra_query":   true,
+}
+
+// EffectiveYAML renders the loaded configuration, after the profile merge and
+// env overrides, as config.yaml with credentials masked. Providers are the
+// ones declared in config.yaml; providers discovered from env vars only are
+// not included.
+func (r *LoadResult) EffectiveYAML() ([]byte, error) {
+	node, err := r.effectiveNode()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(node)
+}
+
+// Effective returns the masked effective configuration of EffectiveYAML as a
+// generic map, keyed like config.yaml. The selected profile is in r.Profile.
+func (r *LoadResult) Effective() (map[string]any, error) {
+	node, err := r.effectiveNode()
+	if err != nil {
+		return nil, err
+	}
+	effective := map[string]any{}
+	if err := node.Decode(&effective); err != nil {
+		return nil, fmt.Errorf("failed to render effective config: %w", err)
+	}
+	return effective, nil
+}
+
+func (r *LoadResult) effectiveNode() (*yaml.Node, error) {
+	if r == nil || r.Config == nil {
+		return nil, fmt.Errorf("failed to render effective config: no config loaded")
+	}
+	var node yaml.Node
+	if err := node.Encode(yamlDocument{Config: r.Config, RawProviders: r.RawProviders}); err != nil {
+		return nil, fmt.Errorf("failed to render effective config: %w", err)
+	}
+	if r.Profile != "" {
+		node.HeadComment = "Profile: " + r.Profile
+	}
+	maskSecrets(&node)
+	return &node, nil
+}
+
+// maskSecrets masks credential values and the passwords of URLs in place.
+func maskSecrets(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := strings.ToLower(node.Content[i].Value), node.Content[i+1]
+			switch {
+			case value.Kind == yaml.ScalarNode && isSensitiveKey(key):
+				if value.Value != "" {
+					value.Tag, value.Value = "!!str", maskedValue
+				}
+			case value.Kind == yaml.MappingNode && opaqueMaps[key]:
+				for j := 1; j < len(value.Content); j += 2 {
+					value.Content[j].Tag, value.Content[j].Value = "!!str", maskedValue
+				}
+			default:
+				maskSecrets(value)
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, child := range node.Content {
+			maskSecrets(child)
+		}
+	case yaml.ScalarNode:
+		node.Value = maskURLPassword(node.Value)
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeys[key] || strings.HasSuffix(key, "_api_key") ||
+		strings.HasSuffix(key, "_secret") || strings.HasSuffix(key, "_password")
+}
+
+// maskURLPassword masks the password of a URL such as a database connection
+// string and returns other values unchanged.
+func maskURLPassword(value string) string {
+	if !strings.Contains(value, "://") || !strings.Contains(value, "@") {
+		return value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	if _, ok := parsed.User.Password(); !ok {
+		return value
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadResult_EffectiveMasksSecrets(t *testing.T) {
+	yaml := `
+server:
+  master_key: "master-secret"
+storage:
+  type: postgresql
+  postgresql:
+    url: "postgres://gomodel:db-password@db:5432/gomodel"
+providers:
+  openai:
+    type: openai
+    api_key: "sk-live"
+    extra_headers:
+      X-Upstream-Token: "upstream-token"
+profiles:
+  prod:
+    provenance:
+      secret: "provenance-secret"
+`
+	result, err := loadProfile(t, yaml, "prod")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	rendered, err := result.EffectiveYAML()
+	if err != nil {
+		t.Fatalf("EffectiveYAML() error = %v", err)
+	}
+	out := string(rendered)
+	for _, secret := range []string{"master-secret", "db-password", "sk-live", "upstream-token", "provenance-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("effective config leaks %q", secret)
+		}
+	}
+	if !strings.HasPrefix(out, "# Profile: prod\n") {
+		t.Errorf("effective config does not start with the profile comment:\n%s", out[:min(len(out), 80)])
+	}
+	if !strings.Contains(out, "postgres://gomodel:xxxxx@db:5432/gomodel") {
+		t.Error("effective config does not keep the masked database URL")
+	}
+
+	effective, err := result.Effective()
+	if err != nil {
+		t.Fatalf("Effective() error = %v", err)
+	}
+	openai := effective["providers"].(map[string]any)["openai"].(map[string]any)
+	if openai["api_key"] != maskedValue || openai["type"] != "openai" {
+		t.Fatalf("openai = %v, want a masked key and the type", openai)
+	}
+	server := effective["server"].(map[string]any)
+	if server["master_key"] != maskedValue || server["stream_stall_timeout"] != "30s" {
+		t.Fatalf("server = %v, want a masked master key and readable durations", server)
+	}
+}
+
+func TestLoadResult_EffectiveKeepsEmptySecretsEmpty(t *testing.T) {
+	result, err := loadProfile(t, "", "")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	effective, err := result.Effective()
+	if err != nil {
+		t.Fatalf("Effective() error = %v", err)
+	}
+	if got := effective["server"].(map[string]any)["master_key"]; got != "" {
+		t.Fatalf("master_key = %v, want an unset key to stay empty", got)
+	}
+}
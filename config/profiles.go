@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnvVar names the environment variable that selects a config.yaml
+// profile.
+const ProfileEnvVar = "GOMODEL_PROFILE"
+
+// profilesKey is the top-level config.yaml section holding named overlays.
+const profilesKey = "profiles"
+
+// takeProfiles removes the profiles section from a parsed config.yaml and
+// returns it keyed by profile name, reporting unknown keys inside every
+// profile. A profile set to null is an empty overlay.
+func takeProfiles(root *yaml.Node) (map[string]*yaml.Node, []Problem, error) {
+	doc := documentMapping(root)
+	if doc == nil {
+		return nil, nil, nil
+	}
+	idx := mappingKeyIndex(doc, profilesKey)
+	if idx < 0 {
+		return nil, nil, nil
+	}
+	section := resolveAlias(doc.Content[idx+1])
+	doc.Content = slices.Delete(doc.Content, idx, idx+2)
+	if isNullNode(section) {
+		return nil, nil, nil
+	}
+	if section.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("failed to parse config.yaml: %s must be a mapping of profile names to overlays", profilesKey)
+	}
+
+	profiles := make(map[string]*yaml.Node, len(section.Content)/2)
+	var problems []Problem
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		name, overlay := section.Content[i].Value, resolveAlias(section.Content[i+1])
+		if isNullNode(overlay) {
+			overlay = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		if overlay.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("failed to parse config.yaml: %s.%s must be a mapping", profilesKey, name)
+		}
+		profiles[name] = overlay
+		walkYAMLKeys(overlay, yamlDocumentType, profilesKey+"."+name, &problems)
+	}
+	return profiles, problems, nil
+}
+
+// applyProfile overlays the named profile onto the base document. An empty
+// name keeps the base config; a name config.yaml does not define is an error.
+func applyProfile(root *yaml.Node, profiles map[string]*yaml.Node, name string) error {
+	if name == "" {
+		return nil
+	}
+	overlay, ok := profiles[name]
+	if !ok {
+		defined := make([]string, 0, len(profiles))
+		for profile := range profiles {
+			defined = append(defined, profile)
+		}
+		slices.Sort(defined)
+		if len(defined) == 0 {
+			return fmt.Errorf("unknown config profile %q (%s): config.yaml defines no profiles", name, ProfileEnvVar)
+		}
+		return fmt.Errorf("unknown config profile %q (%s): config.yaml defines %s", name, ProfileEnvVar, strings.Join(defined, ", "))
+	}
+	doc := documentMapping(root)
+	if doc == nil {
+		return nil
+	}
+	mergeYAMLMapping(doc, overlay)
+	return nil
+}
+
+// mergeYAMLMapping deep-merges src into dst. Mappings merge key by key, so a
+// profile can override one field of one provider; a null value (~ or null)
+// deletes the key, restoring the built-in default; any other value, including
+// a sequence, replaces the base value.
+func mergeYAMLMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], resolveAlias(src.Content[i+1])
+		idx := mappingKeyIndex(dst, key.Value)
+		switch {
+		case isNullNode(value):
+			if idx >= 0 {
+				dst.Content = slices.Delete(dst.Content, idx, idx+2)
+			}
+		case idx >= 0 && value.Kind == yaml.MappingNode && resolveAlias(dst.Content[idx+1]).Kind == yaml.MappingNode:
+			// Copy the base mapping so merging into it never changes an
+			// anchor another key still refers to.
+			base := copyYAMLNode(resolveAlias(dst.Content[idx+1]))
+			mergeYAMLMapping(base, value)
+			dst.Content[idx+1] = base
+		case idx >= 0:
+			dst.Content[idx+1] = copyYAMLNode(value)
+		default:
+			dst.Content = append(dst.Content, copyYAMLNode(key), copyYAMLNode(value))
+		}
+	}
+}
+
+// documentMapping returns the top-level mapping of a parsed document, or nil
+// when the document is not a mapping.
+func documentMapping(root *yaml.Node) *yaml.Node {
+	node := root
+	if node != nil && node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+// mappingKeyIndex returns the index of key in a mapping node's Content, or -1.
+func mappingKeyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNullNode(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// copyYAMLNode deep-copies node, resolving aliases into copies of their
+// targets.
+func copyYAMLNode(node *yaml.Node) *yaml.Node {
+	node = resolveAlias(node)
+	if node == nil {
+		return nil
+	}
+	copied := *node
+	copied.Anchor = ""
+	if node.Content != nil {
+		copied.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			copied.Content[i] = copyYAMLNode(child)
+		}
+	}
+	return &copied
+}
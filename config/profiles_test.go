@@ -0,0 +1,279 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesBaseYAML = `
+server:
+  port: "8080"
+  body_size_limit: "10M"
+  enabled_passthrough_providers: ["openai", "anthropic"]
+providers:
+  openai:
+    type: openai
+    api_key: "sk-base"
+    base_url: "https://api.openai.com/v1"
+    extra_headers:
+      X-Team: base
+  anthropic:
+    type: anthropic
+    api_key: "sk-ant"
+logging:
+  enabled: true
+  buffer_size: 500
+profiles:
+  staging:
+    server:
+      port: "9090"
+    providers:
+      openai:
+        base_url: "https://staging.example.com/v1"
+  prod:
+    server:
+      body_size_limit: ~
+      enabled_passthrough_providers: ["openai"]
+    providers:
+      anthropic: null
+      groq:
+        type: groq
+        api_key: "gsk-prod"
+    logging:
+      buffer_size: 2000
+  empty: ~
+`
+
+// loadProfile loads yaml as config.yaml with the given GOMODEL_PROFILE.
+func loadProfile(t *testing.T, yaml, profile string) (*LoadResult, error) {
+	t.Helper()
+	clearAllConfigEnvVars(t)
+	if profile != "" {
+		t.Setenv(ProfileEnvVar, profile)
+	}
+	var result *LoadResult
+	var err error
+	withTempDir(t, func(dir string) {
+		if yaml != "" {
+			if writeErr := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); writeErr != nil {
+				t.Fatalf("Failed to write config.yaml: %v", writeErr)
+			}
+		}
+		result, err = Load()
+	})
+	return result, err
+}
+
+func TestLoad_ProfileNotSelectedUsesBase(t *testing.T) {
+	result, err := loadProfile(t, profilesBaseYAML, "")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	cfg := result.Config
+	if cfg.Server.Port != "8080" || cfg.Server.BodySizeLimit != "10M" || cfg.Logging.BufferSize != 500 {
+		t.Fatalf("server/logging = %q, %q, %d; want the base values", cfg.Server.Port, cfg.Server.BodySizeLimit, cfg.Logging.BufferSize)
+	}
+	if len(result.RawProviders) != 2 || result.RawProviders["openai"].BaseURL != "https://api.openai.com/v1" {
+		t.Fatalf("providers = %+v, want the two base providers", result.RawProviders)
+	}
+	if result.Profile != "" {
+		t.Fatalf("Profile = %q, want none", result.Profile)
+	}
+}
+
+func TestLoad_ProfileOverridesOneProviderField(t *testing.T) {
+	result, err := loadProfile(t, profilesBaseYAML, "staging")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if result.Config.Server.Port != "9090" || result.Config.Server.BodySizeLimit != "10M" {
+		t.Fatalf("server = %q, %q; want the staging port over the base body limit", result.Config.Server.Port, result.Config.Server.BodySizeLimit)
+	}
+	openai := result.RawProviders["openai"]
+	if openai.BaseURL != "https://staging.example.com/v1" || openai.APIKey != "sk-base" || openai.Type != "openai" {
+		t.Fatalf("openai = %+v, want the staging base URL with the base type and key", openai)
+	}
+	if openai.ExtraHeaders["X-Team"] != "base" {
+		t.Fatalf("openai extra headers = %v, want the base headers kept", openai.ExtraHeaders)
+	}
+	if result.RawProviders["anthropic"].APIKey != "sk-ant" {
+		t.Fatalf("anthropic = %+v, want it untouched", result.RawProviders["anthropic"])
+	}
+	if result.Profile != "staging" {
+		t.Fatalf("Profile = %q, want staging", result.Profile)
+	}
+}
+
+func TestLoad_ProfileDeletesAddsAndReplaces(t *testing.T) {
+	result, err := loadProfile(t, profilesBaseYAML, "prod")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	cfg := result.Config
+	if cfg.Server.BodySizeLimit != "" {
+		t.Fatalf("BodySizeLimit = %q, want the default after deletion", cfg.Server.BodySizeLimit)
+	}
+	if got := cfg.Server.EnabledPassthroughProviders; len(got) != 1 || got[0] != "openai" {
+		t.Fatalf("EnabledPassthroughProviders = %v, want the profile list to replace the base list", got)
+	}
+	if cfg.Logging.BufferSize != 2000 || !cfg.Logging.Enabled {
+		t.Fatalf("logging = %d, %v; want the prod buffer size and the base enabled flag", cfg.Logging.BufferSize, cfg.Logging.Enabled)
+	}
+	if _, ok := result.RawProviders["anthropic"]; ok {
+		t.Fatal("anthropic was not deleted by the prod profile")
+	}
+	if result.RawProviders["groq"].APIKey != "gsk-prod" || result.RawProviders["openai"].APIKey != "sk-base" {
+		t.Fatalf("providers = %+v, want groq added and openai kept", result.RawProviders)
+	}
+}
+
+func TestLoad_EmptyProfileKeepsBase(t *testing.T) {
+	result, err := loadProfile(t, profilesBaseYAML, "empty")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if result.Config.Server.Port != "8080" || len(result.RawProviders) != 2 {
+		t.Fatalf("port %q with %d providers, want the base config", result.Config.Server.Port, len(result.RawProviders))
+	}
+}
+
+func TestLoad_EnvVarsWinOverProfile(t *testing.T) {
+	clearAllConfigEnvVars(t)
+	t.Setenv("PORT", "7070")
+	t.Setenv(ProfileEnvVar, "staging")
+	withTempDir(t, func(dir string) {
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(profilesBaseYAML), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if result.Config.Server.Port != "7070" {
+			t.Fatalf("Port = %q, want the PORT env var over the profile", result.Config.Server.Port)
+		}
+	})
+}
+
+func TestLoad_ProfileValuesAreEnvExpanded(t *testing.T) {
+	yaml := `
+providers:
+  openai:
+    type: openai
+    api_key: "${BASE_OPENAI_KEY}"
+profiles:
+  staging:
+    providers:
+      openai:
+        api_key: "${STAGING_OPENAI_KEY}"
+        base_url: "${STAGING_OPENAI_URL:-https://staging.example.com/v1}"
+`
+	clearAllConfigEnvVars(t)
+	t.Setenv("BASE_OPENAI_KEY", "sk-base")
+	t.Setenv("STAGING_OPENAI_KEY", "sk-staging")
+	t.Setenv(ProfileEnvVar, "staging")
+	withTempDir(t, func(dir string) {
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+			t.Fatalf("Failed to write config.yaml: %v", err)
+		}
+		result, err := Load()
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		openai := result.RawProviders["openai"]
+		if openai.APIKey != "sk-staging" || openai.BaseURL != "https://staging.example.com/v1" {
+			t.Fatalf("openai = %+v, want the expanded staging key and default URL", openai)
+		}
+	})
+}
+
+func TestLoad_ProfileKeepsUnresolvedPlaceholders(t *testing.T) {
+	yaml := `
+providers:
+  openai:
+    type: openai
+    api_key: "sk-base"
+profiles:
+  staging:
+    providers:
+      openai:
+        api_key: "${MISSING_STAGING_KEY}"
+`
+	result, err := loadProfile(t, yaml, "staging")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	// The placeholder replaces the base key, so the providers package drops
+	// the provider instead of silently using the base credentials.
+	if got := result.RawProviders["openai"].APIKey; got != "${MISSING_STAGING_KEY}" {
+		t.Fatalf("api_key = %q, want the unresolved placeholder", got)
+	}
+}
+
+func TestLoad_ProfileMergeLeavesAnchorsIntact(t *testing.T) {
+	yaml := `
+providers:
+  openai:
+    type: openai
+    api_key: "sk-1"
+    extra_headers: &shared
+      X-Team: base
+  openai-eu:
+    type: openai
+    api_key: "sk-2"
+    extra_headers: *shared
+profiles:
+  prod:
+    providers:
+      openai:
+        extra_headers:
+          X-Team: prod
+`
+	result, err := loadProfile(t, yaml, "prod")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got := result.RawProviders["openai"].ExtraHeaders["X-Team"]; got != "prod" {
+		t.Fatalf("openai X-Team = %q, want prod", got)
+	}
+	if got := result.RawProviders["openai-eu"].ExtraHeaders["X-Team"]; got != "base" {
+		t.Fatalf("openai-eu X-Team = %q, want the anchored base value", got)
+	}
+}
+
+func TestLoad_ProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		profile string
+		want    string
+	}{
+		{name: "unknown profile", yaml: profilesBaseYAML, profile: "qa", want: `unknown config profile "qa" (GOMODEL_PROFILE): config.yaml defines empty, prod, staging`},
+		{name: "no profiles", yaml: "server:\n  port: \"8080\"\n", profile: "prod", want: "config.yaml defines no profiles"},
+		{name: "no config file", profile: "prod", want: "no config.yaml found"},
+		{name: "profiles not a mapping", yaml: "profiles: [prod]\n", want: "profiles must be a mapping"},
+		{name: "profile not a mapping", yaml: "profiles:\n  prod: [1]\n", want: "profiles.prod must be a mapping"},
+		{name: "unknown key in unselected profile", yaml: "profiles:\n  prod:\n    server:\n      prot: \"9000\"\n", want: "profiles.prod.server.prot: unknown key, did you mean port?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadProfile(t, tt.yaml, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_ProfileUnknownKeysWarnWithoutStrictConfig(t *testing.T) {
+	yaml := "strict_config: false\nprofiles:\n  prod:\n    logging:\n      enabeld: true\n"
+	result, err := loadProfile(t, yaml, "prod")
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Path != "profiles.prod.logging.enabeld" {
+		t.Fatalf("Warnings = %v, want the profile key", result.Warnings)
+	}
+}
@@ -0,0 +1,397 @@
+// Package accesslog writes one line per HTTP request in Common Log Format,
+// Combined Log Format or JSON, for log pipelines that ingest standard access
+// logs.
+//
+// It is independent of the audit log: it keeps no bodies or headers, has no
+// storage backend and writes each line synchronously when the request ends,
+// which for streamed responses is when the stream completes. Lines are built
+// in pooled buffers so logging a request allocates next to nothing.
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"gomodel/config"
+)
+
+// Format is the layout of a log line.
+type Format string
+
+const (
+	// FormatCLF is the Common Log Format.
+	FormatCLF Format = "clf"
+	// FormatCombined is the Combined Log Format: CLF plus referer and user
+	// agent.
+	FormatCombined Format = "combined"
+	// FormatJSON writes one JSON object per line.
+	FormatJSON Format = "json"
+)
+
+// Field names accepted in AccessLogConfig.Fields.
+const (
+	FieldTime      = "time"
+	FieldRemoteIP  = "remote_ip"
+	FieldHost      = "host"
+	FieldMethod    = "method"
+	FieldURI       = "uri"
+	FieldProtocol  = "protocol"
+	FieldStatus    = "status"
+	FieldBytes     = "bytes"
+	FieldLatencyMS = "latency_ms"
+	FieldRequestID = "request_id"
+	FieldReferer   = "referer"
+	FieldUserAgent = "user_agent"
+)
+
+// Fields lists every field in the order JSON lines carry them by default.
+var Fields = []string{
+	FieldTime,
+	FieldRemoteIP,
+	FieldHost,
+	FieldMethod,
+	FieldURI,
+	FieldProtocol,
+	FieldStatus,
+	FieldBytes,
+	FieldLatencyMS,
+	FieldRequestID,
+	FieldReferer,
+	FieldUserAgent,
+}
+
+// Entry describes one finished request.
+type Entry struct {
+	// Time is when the request started.
+	Time      time.Time
+	RemoteIP  string
+	Host      string
+	Method    string
+	URI       string
+	Protocol  string
+	Status    int
+	Bytes     int64
+	Latency   time.Duration
+	RequestID string
+	Referer   string
+	UserAgent string
+}
+
+// Logger writes access log lines.
+type Logger struct {
+	format Format
+	fields []string
+	output string
+
+	mu     sync.Mutex
+	out    io.Writer
+	closer io.Closer
+
+	writeFailed atomic.Bool
+}
+
+// New builds the Logger for cfg, opening its output. It returns nil when
+// access logging is disabled.
+func New(cfg config.AccessLogConfig) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	output := strings.TrimSpace(cfg.Output)
+	if cfg.MaxSizeMB < 0 {
+		return nil, fmt.Errorf("max_size_mb must not be negative")
+	}
+	if cfg.MaxBackups < 0 {
+		return nil, fmt.Errorf("max_backups must not be negative")
+	}
+	logger, err := newLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch output {
+	case "", "stdout":
+		logger.output, logger.out = "stdout", os.Stdout
+	case "stderr":
+		logger.output, logger.out = "stderr", os.Stderr
+	default:
+		file, err := OpenRotatingFile(output, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxAge, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		logger.output, logger.out, logger.closer = output, file, file
+	}
+	return logger, nil
+}
+
+// NewWriter builds a Logger for cfg that writes to w instead of cfg.Output.
+func NewWriter(cfg config.AccessLogConfig, w io.Writer) (*Logger, error) {
+	logger, err := newLogger(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.output, logger.out = "writer", w
+	return logger, nil
+}
+
+func newLogger(cfg config.AccessLogConfig) (*Logger, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(cfg.Format)))
+	switch format {
+	case "":
+		format = FormatCombined
+	case FormatCLF, FormatCombined, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown format %q: must be one of clf, combined, json", cfg.Format)
+	}
+
+	fields := make([]string, 0, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	if format == FormatJSON && len(fields) == 0 {
+		fields = Fields
+	}
+	return &Logger{format: format, fields: fields}, nil
+}
+
+// Format returns the line format.
+func (l *Logger) Format() Format {
+	return l.format
+}
+
+// Output names where lines are written: "stdout", "stderr" or a file path.
+func (l *Logger) Output() string {
+	return l.output
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// maxPooledBuffer keeps buffers grown by unusually long lines out of the pool.
+const maxPooledBuffer = 64 * 1024
+
+// Log writes the line for e.
+func (l *Logger) Log(e *Entry) {
+	if l == nil {
+		return
+	}
+	bufp := bufferPool.Get().(*[]byte)
+	buf := l.appendEntry((*bufp)[:0], e)
+
+	l.mu.Lock()
+	_, err := l.out.Write(buf)
+	l.mu.Unlock()
+	if err != nil && !l.writeFailed.Swap(true) {
+		slog.Error("access log write failed; further failures are not reported", "output", l.output, "error", err)
+	}
+
+	if cap(buf) <= maxPooledBuffer {
+		*bufp = buf
+		bufferPool.Put(bufp)
+	}
+}
+
+// Close closes the output file, if any.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closer.Close()
+}
+
+func (l *Logger) appendEntry(buf []byte, e *Entry) []byte {
+	if l.format == FormatJSON {
+		return l.appendJSON(buf, e)
+	}
+
+	// host ident authuser [date] "request" status bytes
+	buf = appendDash(buf, e.RemoteIP)
+	buf = append(buf, " - - ["...)
+	buf = e.Time.AppendFormat(buf, "02/Jan/2006:15:04:05 -0700")
+	buf = append(buf, "] \""...)
+	buf = appendQuoted(buf, e.Method)
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, e.URI)
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, e.Protocol)
+	buf = append(buf, "\" "...)
+	buf = strconv.AppendInt(buf, int64(e.Status), 10)
+	buf = append(buf, ' ')
+	if e.Bytes > 0 {
+		buf = strconv.AppendInt(buf, e.Bytes, 10)
+	} else {
+		buf = append(buf, '-')
+	}
+	if l.format == FormatCombined {
+		buf = append(buf, " \""...)
+		buf = appendQuoted(buf, dash(e.Referer))
+		buf = append(buf, "\" \""...)
+		buf = appendQuoted(buf, dash(e.UserAgent))
+		buf = append(buf, '"')
+	}
+	for _, field := range l.fields {
+		buf = append(buf, ' ')
+		buf = append(buf, field...)
+		buf = append(buf, '=')
+		buf = l.appendTextValue(buf, field, e)
+	}
+	return append(buf, '\n')
+}
+
+func (l *Logger) appendTextValue(buf []byte, field string, e *Entry) []byte {
+	switch field {
+	case FieldStatus, FieldBytes, FieldLatencyMS:
+		return appendNumber(buf, field, e)
+	case FieldTime:
+		return e.Time.AppendFormat(buf, time.RFC3339Nano)
+	}
+	buf = append(buf, '"')
+	buf = appendQuoted(buf, stringField(field, e))
+	return append(buf, '"')
+}
+
+func (l *Logger) appendJSON(buf []byte, e *Entry) []byte {
+	buf = append(buf, '{')
+	for i, field := range l.fields {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '"')
+		buf = append(buf, field...)
+		buf = append(buf, "\":"...)
+		switch field {
+		case FieldStatus, FieldBytes, FieldLatencyMS:
+			buf = appendNumber(buf, field, e)
+		case FieldTime:
+			buf = append(buf, '"')
+			buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+			buf = append(buf, '"')
+		default:
+			buf = appendJSONString(buf, stringField(field, e))
+		}
+	}
+	return append(buf, "}\n"...)
+}
+
+func appendNumber(buf []byte, field string, e *Entry) []byte {
+	switch field {
+	case FieldStatus:
+		return strconv.AppendInt(buf, int64(e.Status), 10)
+	case FieldBytes:
+		return strconv.AppendInt(buf, e.Bytes, 10)
+	default:
+		return strconv.AppendFloat(buf, float64(e.Latency.Microseconds())/1000, 'f', 3, 64)
+	}
+}
+
+func stringField(field string, e *Entry) string {
+	switch field {
+	case FieldRemoteIP:
+		return e.RemoteIP
+	case FieldHost:
+		return e.Host
+	case FieldMethod:
+		return e.Method
+	case FieldURI:
+		return e.URI
+	case FieldProtocol:
+		return e.Protocol
+	case FieldRequestID:
+		return e.RequestID
+	case FieldReferer:
+		return e.Referer
+	case FieldUserAgent:
+		return e.UserAgent
+	}
+	return ""
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func appendDash(buf []byte, s string) []byte {
+	if s == "" {
+		return append(buf, '-')
+	}
+	return appendQuoted(buf, s)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendQuoted appends s for use inside a quoted CLF field, escaping quotes,
+// backslashes and control bytes the way Apache httpd does.
+func appendQuoted(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf = append(buf, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			buf = append(buf, '\\', 'x', hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// appendJSONString appends s as a JSON string. Invalid UTF-8 becomes U+FFFD.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, '\\', 'n')
+			case c == '\r':
+				buf = append(buf, '\\', 'r')
+			case c == '\t':
+				buf = append(buf, '\\', 't')
+			case c < 0x20:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, `�`...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
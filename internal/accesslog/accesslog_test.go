@@ -0,0 +1,139 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:      time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("", 2*3600)),
+		RemoteIP:  "203.0.113.7",
+		Host:      "gateway.example.com",
+		Method:    "POST",
+		URI:       "/v1/chat/completions?trace=1",
+		Protocol:  "HTTP/1.1",
+		Status:    200,
+		Bytes:     1234,
+		Latency:   1500 * time.Microsecond,
+		RequestID: "req-1",
+		Referer:   "",
+		UserAgent: `openai-python/1.0 "beta"`,
+	}
+}
+
+func logLine(t *testing.T, cfg config.AccessLogConfig, entry *Entry) string {
+	t.Helper()
+	var out bytes.Buffer
+	logger, err := NewWriter(cfg, &out)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	logger.Log(entry)
+	return out.String()
+}
+
+func TestLog_CommonLogFormat(t *testing.T) {
+	entry := testEntry()
+	entry.Bytes = 0
+	got := logLine(t, config.AccessLogConfig{Format: "clf"}, entry)
+	want := `203.0.113.7 - - [04/Mar/2026:05:06:07 +0200] "POST /v1/chat/completions?trace=1 HTTP/1.1" 200 -` + "\n"
+	if got != want {
+		t.Fatalf("line = %q, want %q", got, want)
+	}
+}
+
+func TestLog_CombinedWithExtraFields(t *testing.T) {
+	got := logLine(t, config.AccessLogConfig{Fields: []string{"request_id", "latency_ms"}}, testEntry())
+	want := `203.0.113.7 - - [04/Mar/2026:05:06:07 +0200] "POST /v1/chat/completions?trace=1 HTTP/1.1" 200 1234 "-" "openai-python/1.0 \"beta\"" request_id="req-1" latency_ms=1.500` + "\n"
+	if got != want {
+		t.Fatalf("line = %q, want %q", got, want)
+	}
+}
+
+func TestLog_JSONAllFields(t *testing.T) {
+	entry := testEntry()
+	entry.URI = "/v1/models?q=\x01\xff"
+	line := logLine(t, config.AccessLogConfig{Format: "json"}, entry)
+	if !strings.HasSuffix(line, "}\n") {
+		t.Fatalf("line = %q, want one JSON object per line", line)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(line), &got); err != nil {
+		t.Fatalf("decode %q: %v", line, err)
+	}
+	if len(got) != len(Fields) {
+		t.Fatalf("fields = %v, want all of %v", got, Fields)
+	}
+	if got["status"] != float64(200) || got["bytes"] != float64(1234) || got["latency_ms"] != 1.5 ||
+		got["request_id"] != "req-1" || got["user_agent"] != `openai-python/1.0 "beta"` ||
+		got["uri"] != "/v1/models?q=\x01�" || got["time"] != "2026-03-04T05:06:07+02:00" {
+		t.Fatalf("line = %s", line)
+	}
+}
+
+func TestLog_JSONSelectedFields(t *testing.T) {
+	got := logLine(t, config.AccessLogConfig{Format: "json", Fields: []string{"status", "request_id", "status"}}, testEntry())
+	if want := `{"status":200,"request_id":"req-1"}` + "\n"; got != want {
+		t.Fatalf("line = %q, want %q", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	logger, err := New(config.AccessLogConfig{})
+	if err != nil || logger != nil {
+		t.Fatalf("New(disabled) = %v, %v; want nil, nil", logger, err)
+	}
+	logger.Log(testEntry())
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() on nil logger = %v", err)
+	}
+
+	for _, cfg := range []config.AccessLogConfig{
+		{Enabled: true, Format: "xml"},
+		{Enabled: true, Fields: []string{"cookie"}},
+		{Enabled: true, MaxBackups: -1},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+
+	logger, err = New(config.AccessLogConfig{Enabled: true, Format: "JSON"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if logger.Format() != FormatJSON || logger.Output() != "stdout" {
+		t.Fatalf("logger = %s to %s, want json to stdout", logger.Format(), logger.Output())
+	}
+}
+
+func BenchmarkLogJSON(b *testing.B) {
+	logger, err := NewWriter(config.AccessLogConfig{Format: "json"}, io.Discard)
+	if err != nil {
+		b.Fatal(err)
+	}
+	entry := testEntry()
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Log(entry)
+	}
+}
+
+func BenchmarkLogCombined(b *testing.B) {
+	logger, err := NewWriter(config.AccessLogConfig{Format: "combined", Fields: []string{"request_id", "latency_ms"}}, io.Discard)
+	if err != nil {
+		b.Fatal(err)
+	}
+	entry := testEntry()
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Log(entry)
+	}
+}
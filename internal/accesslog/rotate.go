@@ -0,0 +1,175 @@
+package accesslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files; it sorts chronologically.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile appends to a file that is rotated once a write would grow it
+// past maxSize bytes or once it has been written for maxAge. The rotated file
+// is renamed with the rotation time inserted before its extension
+// (access-20260102T150405.000.log) and only the newest maxBackups rotated
+// files are kept. Zero disables the corresponding limit.
+//
+// The age of a file counts from when it was opened, so a restart starts a new
+// age period without rotating.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	now        func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens path for appending, creating it and its directory
+// when missing.
+func OpenRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	return openRotatingFile(path, maxSize, maxAge, maxBackups, time.Now)
+}
+
+func openRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int, now func() time.Time) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, maxBackups: maxBackups, now: now}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create access log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open access log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat access log: %w", err)
+	}
+	f.file, f.size, f.opened = file, info.Size(), f.now()
+	return nil
+}
+
+// Write appends p, rotating the file first when a limit is reached. A single
+// write is never split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if f.size > 0 && f.due(int64(len(p))) {
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+func (f *RotatingFile) due(next int64) bool {
+	if f.maxSize > 0 && f.size+next > f.maxSize {
+		return true
+	}
+	return f.maxAge > 0 && f.now().Sub(f.opened) >= f.maxAge
+}
+
+// rotate renames the current file and opens a new one. When the rename
+// fails, writing continues in the current file and the error is returned.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		f.file = nil
+		return fmt.Errorf("close access log: %w", err)
+	}
+	f.file = nil
+	renameErr := os.Rename(f.path, f.backupName(f.now()))
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("rotate access log: %w", renameErr)
+	}
+	f.prune()
+	return nil
+}
+
+func (f *RotatingFile) backupName(at time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + at.UTC().Format(backupTimeFormat) + ext
+}
+
+// Backups returns the rotated files, oldest first.
+func (f *RotatingFile) Backups() ([]string, error) {
+	ext := filepath.Ext(f.path)
+	matches, err := filepath.Glob(globEscape(strings.TrimSuffix(f.path, ext)) + "-*" + globEscape(ext))
+	if err != nil {
+		return nil, err
+	}
+	backups := matches[:0]
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, strings.TrimSuffix(f.path, ext)+"-"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// prune removes the oldest rotated files beyond maxBackups. Failures only
+// leave extra files behind, so they are not reported.
+func (f *RotatingFile) prune() {
+	if f.maxBackups <= 0 {
+		return
+	}
+	backups, err := f.Backups()
+	if err != nil || len(backups) <= f.maxBackups {
+		return
+	}
+	for _, name := range backups[:len(backups)-f.maxBackups] {
+		_ = os.Remove(name)
+	}
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
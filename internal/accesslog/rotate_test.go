@@ -0,0 +1,151 @@
+package accesslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "access.log")
+	clock := &testClock{now: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	f, err := openRotatingFile(path, 10, 0, 0, clock.Now)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"line-1\n", "line-2\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+	// A write larger than the limit goes to a fresh file whole.
+	if _, err := f.Write([]byte("a-much-longer-line\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	backups, err := f.Backups()
+	if err != nil {
+		t.Fatalf("Backups() error = %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2", backups)
+	}
+	if want := filepath.Join(filepath.Dir(path), "access-20260102T030406.000.log"); backups[0] != want {
+		t.Fatalf("first backup = %s, want %s", backups[0], want)
+	}
+	if got := readFile(t, backups[0]); got != "line-1\n" {
+		t.Fatalf("first backup = %q", got)
+	}
+	if got := readFile(t, backups[1]); got != "line-2\n" {
+		t.Fatalf("second backup = %q", got)
+	}
+	if got := readFile(t, path); got != "a-much-longer-line\n" {
+		t.Fatalf("current file = %q", got)
+	}
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	clock := &testClock{now: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	f, err := openRotatingFile(path, 0, time.Hour, 0, clock.Now)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+
+	write := func(line string) {
+		t.Helper()
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	write("first\n")
+	clock.now = clock.now.Add(59 * time.Minute)
+	write("second\n")
+	clock.now = clock.now.Add(time.Minute)
+	write("third\n")
+
+	backups, _ := f.Backups()
+	if len(backups) != 1 || readFile(t, backups[0]) != "first\nsecond\n" {
+		t.Fatalf("backups = %v", backups)
+	}
+	if got := readFile(t, path); got != "third\n" {
+		t.Fatalf("current file = %q", got)
+	}
+}
+
+func TestRotatingFile_KeepsNewestBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	// Files that only share the prefix are never pruned.
+	unrelated := filepath.Join(dir, "access-old.log")
+	if err := os.WriteFile(unrelated, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clock := &testClock{now: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	f, err := openRotatingFile(path, 1, 0, 2, clock.Now)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+
+	for i := range 5 {
+		if _, err := f.Write([]byte{byte('a' + i), '\n'}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	backups, _ := f.Backups()
+	var contents []string
+	for _, backup := range backups {
+		contents = append(contents, readFile(t, backup))
+	}
+	if got := strings.Join(contents, ""); got != "c\nd\n" {
+		t.Fatalf("backups hold %q, want the two newest", got)
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Fatalf("unrelated file removed: %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte("before\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenRotatingFile(path, 1024, 0, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	if _, err := f.Write([]byte("after\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := readFile(t, path); got != "before\nafter\n" {
+		t.Fatalf("file = %q", got)
+	}
+	if _, err := f.Write([]byte("closed\n")); err == nil {
+		t.Fatal("Write() after Close() succeeded")
+	}
+}
@@ -33,11 +33,12 @@
                 name: '',
                 description: '',
                 user_path: '',
+                role: 'admin',
                 expires_at: ''
             },
 
             defaultAuthKeyForm() {
-                return { name: '', description: '', user_path: '', expires_at: '' };
+                return { name: '', description: '', user_path: '', role: 'admin', expires_at: '' };
             },
 
             authKeyUserPathValidationError(value) {
@@ -186,7 +187,8 @@
                 const payload = {
                     name,
                     description: String(this.authKeyForm.description || '').trim() || undefined,
-                    user_path: userPath || undefined
+                    user_path: userPath || undefined,
+                    role: this.authKeyForm.role || undefined
                 };
                 if (this.authKeyForm.expires_at) {
                     payload.expires_at = this.authKeyForm.expires_at + 'T23:59:59Z';
@@ -2,12 +2,19 @@
 package admin
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"runtime"
 	"slices"
 	"sort"
 	"strconv"
@@ -17,14 +24,35 @@ import (
 
 	"github.com/labstack/echo/v5"
 
+	"gomodel/config"
 	"gomodel/internal/aliases"
+	"gomodel/internal/anomaly"
 	"gomodel/internal/auditlog"
 	"gomodel/internal/authkeys"
+	"gomodel/internal/cache/modelcache"
+	"gomodel/internal/chaos"
 	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/deprecations"
+	"gomodel/internal/experiments"
 	"gomodel/internal/guardrails"
+	"gomodel/internal/inspect"
+	"gomodel/internal/logging"
+	"gomodel/internal/maintenance"
+	"gomodel/internal/memgovernor"
+	"gomodel/internal/modelgroups"
 	"gomodel/internal/modeloverrides"
+	"gomodel/internal/pipelinestats"
+	"gomodel/internal/probe"
+	"gomodel/internal/promptcache"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/provenance"
 	"gomodel/internal/providers"
+	"gomodel/internal/sanitize"
+	"gomodel/internal/scoreboard"
+	"gomodel/internal/statebundle"
 	"gomodel/internal/usage"
+	"gomodel/internal/usagejobs"
 	"gomodel/internal/workflows"
 )
 
@@ -35,13 +63,35 @@ type Handler struct {
 	registry            *providers.ModelRegistry
 	authKeys            *authkeys.Service
 	aliases             *aliases.Service
+	templates           *prompttemplates.Service
 	modelOverrides      *modeloverrides.Service
 	workflows           *workflows.Service
 	guardrails          guardrails.Catalog
 	guardrailDefs       *guardrails.Service
 	runtimeConfig       DashboardConfigResponse
+	effectiveConfig     EffectiveConfigResponse
 	runtimeRefresher    RuntimeRefresher
 	configuredProviders []providers.SanitizedProviderConfig
+	scoreboard          *scoreboard.Scoreboard
+	promptCache         *promptcache.Tracker
+	experiments         *experiments.Service
+	modelGroups         *modelgroups.Resolver
+	deferred            *deferred.Service
+	usageJobs           *usagejobs.Service
+	chaos               *chaos.Injector
+	provenance          *provenance.Signer
+	sanitizer           *sanitize.Sanitizer
+	maintenance         *maintenance.Mode
+	inspector           *inspect.Inspector
+	deprecations        *deprecations.Registry
+	prober              *probe.Prober
+	streamSamples       *auditlog.StreamSampler
+	anomalies           *anomaly.Detector
+	modelCache          *modelcache.LocalCache
+	auditPipeline       PipelineStatsSource
+	usagePipeline       PipelineStatsSource
+	memory              *memgovernor.Governor
+	maxQueryDays        int
 
 	mutationMu sync.Mutex
 }
@@ -52,6 +102,9 @@ type Option func(*Handler)
 const (
 	DashboardConfigFeatureFallbackMode  = "FEATURE_FALLBACK_MODE"
 	DashboardConfigLoggingEnabled       = "LOGGING_ENABLED"
+	DashboardConfigLoggingMaxRequest    = "LOGGING_MAX_REQUEST_BODY_BYTES"
+	DashboardConfigLoggingMaxResponse   = "LOGGING_MAX_RESPONSE_BODY_BYTES"
+	DashboardConfigLoggingBodyPaths     = "LOGGING_BODY_CAPTURE_PATHS"
 	DashboardConfigUsageEnabled         = "USAGE_ENABLED"
 	DashboardConfigGuardrailsEnabled    = "GUARDRAILS_ENABLED"
 	DashboardConfigCacheEnabled         = "CACHE_ENABLED"
@@ -61,20 +114,37 @@ const (
 
 // DashboardConfigResponse is the allowlisted runtime config contract exposed to the dashboard UI.
 type DashboardConfigResponse struct {
-	FeatureFallbackMode  string `json:"FEATURE_FALLBACK_MODE,omitempty"`
-	LoggingEnabled       string `json:"LOGGING_ENABLED,omitempty"`
-	UsageEnabled         string `json:"USAGE_ENABLED,omitempty"`
-	GuardrailsEnabled    string `json:"GUARDRAILS_ENABLED,omitempty"`
-	CacheEnabled         string `json:"CACHE_ENABLED,omitempty"`
-	RedisURL             string `json:"REDIS_URL,omitempty"`
-	SemanticCacheEnabled string `json:"SEMANTIC_CACHE_ENABLED,omitempty"`
+	FeatureFallbackMode string `json:"FEATURE_FALLBACK_MODE,omitempty"`
+	LoggingEnabled      string `json:"LOGGING_ENABLED,omitempty"`
+	// Effective audit log body capture limits in bytes, including per-path overrides.
+	LoggingMaxRequestBodyBytes  string                                `json:"LOGGING_MAX_REQUEST_BODY_BYTES,omitempty"`
+	LoggingMaxResponseBodyBytes string                                `json:"LOGGING_MAX_RESPONSE_BODY_BYTES,omitempty"`
+	LoggingBodyCapturePaths     map[string]auditlog.BodyCaptureLimits `json:"LOGGING_BODY_CAPTURE_PATHS,omitempty"`
+	UsageEnabled                string                                `json:"USAGE_ENABLED,omitempty"`
+	GuardrailsEnabled           string                                `json:"GUARDRAILS_ENABLED,omitempty"`
+	CacheEnabled                string                                `json:"CACHE_ENABLED,omitempty"`
+	RedisURL                    string                                `json:"REDIS_URL,omitempty"`
+	SemanticCacheEnabled        string                                `json:"SEMANTIC_CACHE_ENABLED,omitempty"`
+}
+
+// EffectiveConfigResponse is the fully merged configuration the gateway runs
+// with, keyed like config.yaml, with credentials masked.
+type EffectiveConfigResponse struct {
+	Profile string         `json:"profile,omitempty"`
+	Config  map[string]any `json:"config"`
 }
 
 type providerStatusSummaryResponse struct {
-	Total         int    `json:"total"`
-	Healthy       int    `json:"healthy"`
-	Degraded      int    `json:"degraded"`
-	Unhealthy     int    `json:"unhealthy"`
+	Total     int `json:"total"`
+	Healthy   int `json:"healthy"`
+	Degraded  int `json:"degraded"`
+	Unhealthy int `json:"unhealthy"`
+	// Uninitialized counts lazy providers not constructed yet. They are
+	// included in Degraded but do not affect OverallStatus.
+	Uninitialized int `json:"uninitialized"`
+	// KeyInvalid counts providers whose API key the key health checks found
+	// invalid. They are included in Unhealthy.
+	KeyInvalid    int    `json:"key_invalid"`
 	OverallStatus string `json:"overall_status"`
 }
 
@@ -92,6 +162,38 @@ type providerStatusItemResponse struct {
 type providerStatusResponse struct {
 	Summary   providerStatusSummaryResponse `json:"summary"`
 	Providers []providerStatusItemResponse  `json:"providers"`
+	// ModelsVersion identifies the model registry snapshot the gateway serves.
+	ModelsVersion string `json:"models_version,omitempty"`
+}
+
+// Provider quota statuses. Unknown means the provider never reported its
+// account limits, which is not the same as having none left.
+const (
+	ProviderQuotaStatusKnown   = "known"
+	ProviderQuotaStatusUnknown = "unknown"
+)
+
+// Provider quota on-demand fetch outcomes.
+const (
+	ProviderQuotaFetchOK          = "ok"
+	ProviderQuotaFetchFailed      = "failed"
+	ProviderQuotaFetchUnsupported = "unsupported"
+)
+
+type providerQuotaFetchResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type providerQuotaResponse struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// RateLimits are the most recent limits the provider reported, from live
+	// traffic or the on-demand fetch.
+	RateLimits *core.RateLimits `json:"rate_limits,omitempty"`
+	// Fetch reports the on-demand fetch requested with refresh=true.
+	Fetch *providerQuotaFetchResponse `json:"fetch,omitempty"`
 }
 
 const (
@@ -126,6 +228,28 @@ type RuntimeRefresher interface {
 	RefreshRuntime(ctx context.Context) (RuntimeRefreshReport, error)
 }
 
+// PipelineStatsSource reports the health of a buffered write pipeline.
+type PipelineStatsSource interface {
+	PipelineStats() pipelinestats.Snapshot
+}
+
+// WithWritePipelines adds the audit log and usage write pipelines to the
+// diagnostics endpoint. Either may be nil when that pipeline is disabled.
+func WithWritePipelines(audit, usage PipelineStatsSource) Option {
+	return func(h *Handler) {
+		h.auditPipeline = audit
+		h.usagePipeline = usage
+	}
+}
+
+// WithMemoryGovernor adds the sizes of the in-memory stores and the process
+// memory ceiling to the diagnostics endpoint.
+func WithMemoryGovernor(governor *memgovernor.Governor) Option {
+	return func(h *Handler) {
+		h.memory = governor
+	}
+}
+
 // WithAuditReader enables audit log read endpoints.
 func WithAuditReader(reader auditlog.Reader) Option {
 	return func(h *Handler) {
@@ -133,6 +257,119 @@ func WithAuditReader(reader auditlog.Reader) Option {
 	}
 }
 
+// WithStreamSamples enables the stream sample endpoints and removes samples
+// together with the audit log entries they belong to.
+func WithStreamSamples(sampler *auditlog.StreamSampler) Option {
+	return func(h *Handler) {
+		h.streamSamples = sampler
+	}
+}
+
+// WithScoreboard enables the model performance scoreboard endpoint.
+func WithScoreboard(board *scoreboard.Scoreboard) Option {
+	return func(h *Handler) {
+		h.scoreboard = board
+	}
+}
+
+// WithPromptCache enables the prompt cache statistics endpoint.
+func WithPromptCache(tracker *promptcache.Tracker) Option {
+	return func(h *Handler) {
+		h.promptCache = tracker
+	}
+}
+
+// WithAnomalies enables the usage anomaly endpoints.
+func WithAnomalies(detector *anomaly.Detector) Option {
+	return func(h *Handler) {
+		h.anomalies = detector
+	}
+}
+
+// WithLocalModelCache enables the local cache directory endpoints.
+func WithLocalModelCache(cache *modelcache.LocalCache) Option {
+	return func(h *Handler) {
+		h.modelCache = cache
+	}
+}
+
+// WithExperiments enables the A/B experiment report endpoint.
+func WithExperiments(service *experiments.Service) Option {
+	return func(h *Handler) {
+		h.experiments = service
+	}
+}
+
+// WithModelGroups enables the model group resolution endpoint.
+func WithModelGroups(resolver *modelgroups.Resolver) Option {
+	return func(h *Handler) {
+		h.modelGroups = resolver
+	}
+}
+
+// WithDeferred enables the deferred request queue endpoint.
+func WithDeferred(service *deferred.Service) Option {
+	return func(h *Handler) {
+		h.deferred = service
+	}
+}
+
+// WithUsageJobs enables the bulk usage correction endpoints.
+func WithUsageJobs(service *usagejobs.Service) Option {
+	return func(h *Handler) {
+		h.usageJobs = service
+	}
+}
+
+// WithChaos enables the fault injection rule endpoints.
+func WithChaos(injector *chaos.Injector) Option {
+	return func(h *Handler) {
+		h.chaos = injector
+	}
+}
+
+// WithProvenance enables the provenance verification endpoint.
+func WithProvenance(signer *provenance.Signer) Option {
+	return func(h *Handler) {
+		h.provenance = signer
+	}
+}
+
+// WithResponseSanitization enables the gateway response ID lookup endpoint.
+func WithResponseSanitization(sanitizer *sanitize.Sanitizer) Option {
+	return func(h *Handler) {
+		h.sanitizer = sanitizer
+	}
+}
+
+// WithMaintenance enables the maintenance mode endpoints.
+func WithMaintenance(mode *maintenance.Mode) Option {
+	return func(h *Handler) {
+		h.maintenance = mode
+	}
+}
+
+// WithInspector enables the live request inspector endpoints.
+func WithInspector(inspector *inspect.Inspector) Option {
+	return func(h *Handler) {
+		h.inspector = inspector
+	}
+}
+
+// WithDeprecations enables the deprecations view.
+func WithDeprecations(registry *deprecations.Registry) Option {
+	return func(h *Handler) {
+		h.deprecations = registry
+	}
+}
+
+// WithCapabilityProbe enables provider capability probes.
+func WithCapabilityProbe(prober *probe.Prober) Option {
+	return func(h *Handler) {
+		h.prober = prober
+	}
+}
+
 // WithAliases enables alias administration endpoints.
 func WithAliases(service *aliases.Service) Option {
 	return func(h *Handler) {
@@ -140,6 +377,13 @@ func WithAliases(service *aliases.Service) Option {
 	}
 }
 
+// WithPromptTemplates enables prompt template administration endpoints.
+func WithPromptTemplates(service *prompttemplates.Service) Option {
+	return func(h *Handler) {
+		h.templates = service
+	}
+}
+
 // WithAuthKeys enables managed auth key administration endpoints.
 func WithAuthKeys(service *authkeys.Service) Option {
 	return func(h *Handler) {
@@ -183,6 +427,13 @@ func WithDashboardRuntimeConfig(values DashboardConfigResponse) Option {
 	}
 }
 
+// WithEffectiveConfig enables the effective config endpoint.
+func WithEffectiveConfig(values EffectiveConfigResponse) Option {
+	return func(h *Handler) {
+		h.effectiveConfig = values
+	}
+}
+
 // WithRuntimeRefresher enables manual runtime refresh from the admin API.
 func WithRuntimeRefresher(refresher RuntimeRefresher) Option {
 	return func(h *Handler) {
@@ -197,6 +448,16 @@ func WithConfiguredProviders(configs []providers.SanitizedProviderConfig) Option
 	}
 }
 
+// WithMaxQueryDays bounds the date window of usage and audit log queries.
+// Values below 1 keep the default of 366 days.
+func WithMaxQueryDays(days int) Option {
+	return func(h *Handler) {
+		if days > 0 {
+			h.maxQueryDays = days
+		}
+	}
+}
+
 // NewHandler creates a new admin API handler.
 // usageReader may be nil if usage tracking is not available.
 func NewHandler(reader usage.UsageReader, registry *providers.ModelRegistry, options ...Option) *Handler {
@@ -204,6 +465,7 @@ func NewHandler(reader usage.UsageReader, registry *providers.ModelRegistry, opt
 		usageReader:   reader,
 		registry:      registry,
 		runtimeConfig: DashboardConfigResponse{},
+		maxQueryDays:  defaultMaxQueryDays,
 	}
 
 	for _, opt := range options {
@@ -217,17 +479,21 @@ func NewHandler(reader usage.UsageReader, registry *providers.ModelRegistry, opt
 
 func normalizeDashboardRuntimeConfig(values DashboardConfigResponse) DashboardConfigResponse {
 	return DashboardConfigResponse{
-		FeatureFallbackMode:  strings.TrimSpace(values.FeatureFallbackMode),
-		LoggingEnabled:       strings.TrimSpace(values.LoggingEnabled),
-		UsageEnabled:         strings.TrimSpace(values.UsageEnabled),
-		GuardrailsEnabled:    strings.TrimSpace(values.GuardrailsEnabled),
-		CacheEnabled:         strings.TrimSpace(values.CacheEnabled),
-		RedisURL:             strings.TrimSpace(values.RedisURL),
-		SemanticCacheEnabled: strings.TrimSpace(values.SemanticCacheEnabled),
+		FeatureFallbackMode:         strings.TrimSpace(values.FeatureFallbackMode),
+		LoggingEnabled:              strings.TrimSpace(values.LoggingEnabled),
+		LoggingMaxRequestBodyBytes:  strings.TrimSpace(values.LoggingMaxRequestBodyBytes),
+		LoggingMaxResponseBodyBytes: strings.TrimSpace(values.LoggingMaxResponseBodyBytes),
+		LoggingBodyCapturePaths:     maps.Clone(values.LoggingBodyCapturePaths),
+		UsageEnabled:                strings.TrimSpace(values.UsageEnabled),
+		GuardrailsEnabled:           strings.TrimSpace(values.GuardrailsEnabled),
+		CacheEnabled:                strings.TrimSpace(values.CacheEnabled),
+		RedisURL:                    strings.TrimSpace(values.RedisURL),
+		SemanticCacheEnabled:        strings.TrimSpace(values.SemanticCacheEnabled),
 	}
 }
 
 func cloneDashboardRuntimeConfig(values DashboardConfigResponse) DashboardConfigResponse {
+	values.LoggingBodyCapturePaths = maps.Clone(values.LoggingBodyCapturePaths)
 	return values
 }
 
@@ -241,6 +507,13 @@ func cloneConfiguredProviders(configs []providers.SanitizedProviderConfig) []pro
 		if len(configs[i].Models) > 0 {
 			cloned[i].Models = append([]string(nil), configs[i].Models...)
 		}
+		if configs[i].Signing != nil {
+			signing := *configs[i].Signing
+			cloned[i].Signing = &signing
+		}
+		if len(configs[i].AcceptEncoding) > 0 {
+			cloned[i].AcceptEncoding = append([]string(nil), configs[i].AcceptEncoding...)
+		}
 	}
 	return cloned
 }
@@ -255,14 +528,22 @@ var validIntervals = map[string]bool{
 const (
 	dashboardTimeZoneHeader = "X-GoModel-Timezone"
 	defaultDashboardTZ      = "UTC"
+
+	// statePassphraseHeader carries the passphrase that seals or opens the
+	// secrets of a state bundle. It is a header so it stays out of access logs.
+	statePassphraseHeader = "X-GoModel-State-Passphrase"
 )
 
 var timeNow = time.Now
 
+// defaultMaxQueryDays bounds query date windows when no limit is configured.
+const defaultMaxQueryDays = 366
+
 // parseUsageParams extracts UsageQueryParams from the request query string.
-// Returns an error if date parameters are provided but malformed.
-func parseUsageParams(c *echo.Context) (usage.UsageQueryParams, error) {
-	params, err := parseDateRangeParams(c)
+// Returns an error if date parameters are provided but malformed or span more
+// than maxDays.
+func parseUsageParams(c *echo.Context, maxDays int) (usage.UsageQueryParams, error) {
+	params, err := parseDateRangeParams(c, maxDays)
 	if err != nil {
 		return params, err
 	}
@@ -274,15 +555,61 @@ func parseUsageParams(c *echo.Context) (usage.UsageQueryParams, error) {
 	}
 	params.CacheMode = c.QueryParam("cache_mode")
 
+	switch weekStart := strings.ToLower(strings.TrimSpace(c.QueryParam("week_start"))); weekStart {
+	case "", usage.WeekStartMonday, usage.WeekStartSunday:
+		params.WeekStart = weekStart
+	default:
+		return params, core.NewInvalidRequestError("invalid week_start: must be monday or sunday", nil)
+	}
+
 	userPath, err := normalizeUserPathQueryParam("user_path", c.QueryParam("user_path"))
 	if err != nil {
 		return params, err
 	}
 	params.UserPath = userPath
 
+	switch modelBy := strings.ToLower(strings.TrimSpace(c.QueryParam("model_by"))); modelBy {
+	case "", usage.ModelByServed, usage.ModelByRequested:
+		params.ModelBy = modelBy
+	default:
+		return params, core.NewInvalidRequestError("invalid model_by: must be served or requested", nil)
+	}
+
+	labels, err := parseUsageLabelFilters(c)
+	if err != nil {
+		return params, err
+	}
+	params.Labels = labels
+
 	return params, nil
 }
 
+// usageLabelFilterPrefix prefixes usage query parameters that filter on a
+// request label: label.team=growth.
+const usageLabelFilterPrefix = "label."
+
+func parseUsageLabelFilters(c *echo.Context) (map[string]string, error) {
+	var labels map[string]string
+	for name, values := range c.QueryParams() {
+		rawKey, ok := strings.CutPrefix(name, usageLabelFilterPrefix)
+		if !ok {
+			continue
+		}
+		key, err := core.NormalizeRequestLabelKey(rawKey)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid label filter: "+err.Error(), err)
+		}
+		if len(values) != 1 || strings.TrimSpace(values[0]) == "" {
+			return nil, core.NewInvalidRequestError("label filter "+name+" requires exactly one value", nil)
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = strings.TrimSpace(values[0])
+	}
+	return labels, nil
+}
+
 func normalizeUserPathQueryParam(fieldName, raw string) (string, error) {
 	userPath, err := core.NormalizeUserPath(raw)
 	if err != nil {
@@ -291,12 +618,43 @@ func normalizeUserPathQueryParam(fieldName, raw string) (string, error) {
 	return userPath, nil
 }
 
-// parseDateRangeParams extracts common date range query params.
-// Returns an error if date parameters are provided but malformed.
-func parseDateRangeParams(c *echo.Context) (usage.UsageQueryParams, error) {
+// parseUserHashQueryParam validates a user_hash filter. Hashes are the
+// lowercase hex prefix recorded by the gateway.
+func parseUserHashQueryParam(raw string) (string, error) {
+	hash := strings.ToLower(strings.TrimSpace(raw))
+	if hash == "" {
+		return "", nil
+	}
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != core.RequestUserHashLength {
+		return "", core.NewInvalidRequestError("invalid user_hash: must be "+strconv.Itoa(core.RequestUserHashLength)+" hex characters", err)
+	}
+	return hash, nil
+}
+
+// parseCursorParam returns the cursor query param of a log listing. A
+// cursor replaces offset, so the two cannot be combined.
+func parseCursorParam(c *echo.Context, offset int) (string, error) {
+	cursor := strings.TrimSpace(c.QueryParam("cursor"))
+	if cursor != "" && offset > 0 {
+		return "", core.NewInvalidRequestError("cursor and offset cannot be combined", nil)
+	}
+	return cursor, nil
+}
+
+// parseDateRangeParams extracts common date range query params. It is the one
+// place query windows are bounded: days must be a positive integer, end_date
+// may not precede start_date, and no window may span more than maxDays days
+// (defaultMaxQueryDays when maxDays is below 1).
+func parseDateRangeParams(c *echo.Context, maxDays int) (usage.UsageQueryParams, error) {
 	var params usage.UsageQueryParams
+	if maxDays < 1 {
+		maxDays = defaultMaxQueryDays
+	}
 
-	timeZone, location := dashboardTimeZone(c)
+	timeZone, location, err := requestTimeZone(c)
+	if err != nil {
+		return params, err
+	}
 	params.TimeZone = timeZone
 
 	now := timeNow().In(location)
@@ -332,14 +690,25 @@ func parseDateRangeParams(c *echo.Context) (usage.UsageQueryParams, error) {
 		if !endParsed {
 			params.EndDate = today
 		}
+		if params.EndDate.Before(params.StartDate) {
+			return params, core.NewInvalidRequestError("end_date must not be before start_date", nil)
+		}
+		if span := calendarDaysBetween(params.StartDate, params.EndDate) + 1; span > maxDays {
+			return params, core.NewInvalidRequestError("date range spans "+strconv.Itoa(span)+" days; at most "+strconv.Itoa(maxDays)+" days can be queried", nil)
+		}
 		return params, nil
 	}
 
 	days := 30
-	if d := c.QueryParam("days"); d != "" {
-		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 {
-			days = parsed
+	if d := strings.TrimSpace(c.QueryParam("days")); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 {
+			return params, core.NewInvalidRequestError("invalid days: must be a positive integer", nil)
+		}
+		if parsed > maxDays {
+			return params, core.NewInvalidRequestError("invalid days: at most "+strconv.Itoa(maxDays)+" days can be queried", nil)
 		}
+		days = parsed
 	}
 	params.EndDate = today
 	params.StartDate = today.AddDate(0, 0, -(days - 1))
@@ -347,18 +716,35 @@ func parseDateRangeParams(c *echo.Context) (usage.UsageQueryParams, error) {
 	return params, nil
 }
 
-func dashboardTimeZone(c *echo.Context) (string, *time.Location) {
+// calendarDaysBetween counts the midnights from start to end, both midnights
+// in the same location; rounding absorbs DST-shortened and lengthened days.
+func calendarDaysBetween(start, end time.Time) int {
+	return int((end.Sub(start) + 12*time.Hour) / (24 * time.Hour))
+}
+
+// requestTimeZone resolves the zone that day boundaries are computed in: the
+// tz query parameter when present, otherwise the dashboard's timezone header,
+// otherwise UTC. An unknown tz is rejected; an unknown header falls back to UTC.
+func requestTimeZone(c *echo.Context) (string, *time.Location, error) {
+	if value := strings.TrimSpace(c.QueryParam("tz")); value != "" {
+		location, err := time.LoadLocation(value)
+		if err != nil || strings.EqualFold(value, "local") {
+			return "", nil, core.NewInvalidRequestError("invalid tz: unknown IANA time zone "+strconv.Quote(value), nil)
+		}
+		return location.String(), location, nil
+	}
+
 	value := strings.TrimSpace(c.Request().Header.Get(dashboardTimeZoneHeader))
 	if value == "" {
-		return defaultDashboardTZ, time.UTC
+		return defaultDashboardTZ, time.UTC, nil
 	}
 
 	location, err := time.LoadLocation(value)
 	if err != nil {
-		return defaultDashboardTZ, time.UTC
+		return defaultDashboardTZ, time.UTC, nil
 	}
 
-	return location.String(), location
+	return location.String(), location, nil
 }
 
 // handleError converts errors to appropriate HTTP responses, matching the
@@ -386,6 +772,7 @@ func handleError(c *echo.Context, err error) error {
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
 // @Success      200  {object}  usage.UsageSummary
@@ -397,7 +784,7 @@ func (h *Handler) UsageSummary(c *echo.Context) error {
 		return c.JSON(http.StatusOK, usage.UsageSummary{})
 	}
 
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, h.maxQueryDays)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -413,13 +800,14 @@ func (h *Handler) UsageSummary(c *echo.Context) error {
 func usageSliceResponse[T any](
 	c *echo.Context,
 	reader usage.UsageReader,
+	maxDays int,
 	fetch func(context.Context, usage.UsageQueryParams) ([]T, error),
 ) error {
 	if reader == nil {
 		return c.JSON(http.StatusOK, []T{})
 	}
 
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, maxDays)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -443,7 +831,9 @@ func usageSliceResponse[T any](
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        interval    query     string  false  "Grouping interval: daily, weekly, monthly, yearly (default daily)"
+// @Param        week_start  query     string  false  "First day of weekly buckets: monday or sunday (default monday)"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
 // @Success      200  {array}   usage.DailyUsage
@@ -451,7 +841,7 @@ func usageSliceResponse[T any](
 // @Failure      401  {object}  core.GatewayError
 // @Router       /admin/api/v1/usage/daily [get]
 func (h *Handler) DailyUsage(c *echo.Context) error {
-	return usageSliceResponse(c, h.usageReader, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.DailyUsage, error) {
+	return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.DailyUsage, error) {
 		return h.usageReader.GetDailyUsage(ctx, params)
 	})
 }
@@ -465,14 +855,16 @@ func (h *Handler) DailyUsage(c *echo.Context) error {
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
+// @Param        model_by    query     string  false  "Group by the served or the requested model: served, requested (default served)"
 // @Success      200  {array}   usage.ModelUsage
 // @Failure      400  {object}  core.GatewayError
 // @Failure      401  {object}  core.GatewayError
 // @Router       /admin/api/v1/usage/models [get]
 func (h *Handler) UsageByModel(c *echo.Context) error {
-	return usageSliceResponse(c, h.usageReader, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.ModelUsage, error) {
+	return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.ModelUsage, error) {
 		return h.usageReader.GetUsageByModel(ctx, params)
 	})
 }
@@ -486,6 +878,7 @@ func (h *Handler) UsageByModel(c *echo.Context) error {
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
 // @Success      200  {array}   usage.UserPathUsage
@@ -493,11 +886,78 @@ func (h *Handler) UsageByModel(c *echo.Context) error {
 // @Failure      401  {object}  core.GatewayError
 // @Router       /admin/api/v1/usage/user-paths [get]
 func (h *Handler) UsageByUserPath(c *echo.Context) error {
-	return usageSliceResponse(c, h.usageReader, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.UserPathUsage, error) {
+	return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.UserPathUsage, error) {
 		return h.usageReader.GetUsageByUserPath(ctx, params)
 	})
 }
 
+// UsageContentStats handles GET /admin/api/v1/usage/content-stats
+//
+// @Summary      Get response language and length statistics
+// @Description  Aggregates the content analysis of chat and Responses output per day, model, provider and language. Entries recorded with content analysis off are not counted.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days        query     int     false  "Number of days (default 30)"
+// @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
+// @Param        user_path   query     string  false  "Filter by tracked user path subtree"
+// @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
+// @Param        model_by    query     string  false  "Group by the served or the requested model: served, requested (default served)"
+// @Success      200  {array}   usage.ContentStats
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/usage/content-stats [get]
+func (h *Handler) UsageContentStats(c *echo.Context) error {
+	return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.ContentStats, error) {
+		return h.usageReader.GetContentStats(ctx, params)
+	})
+}
+
+// usageGroupByLabelPrefix selects label grouping in group_by=label:<key>.
+const usageGroupByLabelPrefix = "label:"
+
+// usageGroupByUser selects grouping by the hash of the user request field.
+const usageGroupByUser = "user"
+
+// UsageGroups handles GET /admin/api/v1/usage/groups
+//
+// @Summary      Get usage grouped by a request label or end user
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        group_by    query     string  true   "Grouping: user or label:<key>"
+// @Param        days        query     int     false  "Number of days (default 30)"
+// @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
+// @Param        user_path   query     string  false  "Filter by tracked user path subtree"
+// @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
+// @Success      200  {array}   usage.LabelUsage
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/usage/groups [get]
+func (h *Handler) UsageGroups(c *echo.Context) error {
+	groupBy := strings.TrimSpace(c.QueryParam("group_by"))
+	if groupBy == usageGroupByUser {
+		return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.LabelUsage, error) {
+			return h.usageReader.GetUsageByUser(ctx, params)
+		})
+	}
+	rawKey, ok := strings.CutPrefix(groupBy, usageGroupByLabelPrefix)
+	if !ok {
+		return handleError(c, core.NewInvalidRequestError("group_by must be user or label:<key>", nil))
+	}
+	key, err := core.NormalizeRequestLabelKey(rawKey)
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid group_by: "+err.Error(), err))
+	}
+	return usageSliceResponse(c, h.usageReader, h.maxQueryDays, func(ctx context.Context, params usage.UsageQueryParams) ([]usage.LabelUsage, error) {
+		return h.usageReader.GetUsageByLabel(ctx, params, key)
+	})
+}
+
 // UsageLog handles GET /admin/api/v1/usage/log
 //
 // @Summary      Get paginated usage log entries
@@ -507,13 +967,17 @@ func (h *Handler) UsageByUserPath(c *echo.Context) error {
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        model       query     string  false  "Filter by model name"
 // @Param        provider    query     string  false  "Filter by provider name or provider type"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (default uncached)"
+// @Param        user_hash   query     string  false  "Filter by hash of the user request field"
+// @Param        request_id  query     string  false  "Filter by exact gateway request ID"
 // @Param        search      query     string  false  "Search across model, provider, request_id, provider_id"
 // @Param        limit       query     int     false  "Page size (default 50, max 200)"
 // @Param        offset      query     int     false  "Offset for pagination"
+// @Param        cursor      query     string  false  "next_cursor of the previous page; preferred over offset"
 // @Success      200  {object}  usage.UsageLogResult
 // @Failure      400  {object}  core.GatewayError
 // @Failure      401  {object}  core.GatewayError
@@ -525,7 +989,12 @@ func (h *Handler) UsageLog(c *echo.Context) error {
 		})
 	}
 
-	baseParams, err := parseUsageParams(c)
+	baseParams, err := parseUsageParams(c, h.maxQueryDays)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	userHash, err := parseUserHashQueryParam(c.QueryParam("user_hash"))
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -535,6 +1004,8 @@ func (h *Handler) UsageLog(c *echo.Context) error {
 		Model:            c.QueryParam("model"),
 		Provider:         c.QueryParam("provider"),
 		Search:           c.QueryParam("search"),
+		UserHash:         userHash,
+		RequestID:        strings.TrimSpace(c.QueryParam("request_id")),
 	}
 
 	if l := c.QueryParam("limit"); l != "" {
@@ -548,6 +1019,10 @@ func (h *Handler) UsageLog(c *echo.Context) error {
 		}
 	}
 
+	if params.Cursor, err = parseCursorParam(c, params.Offset); err != nil {
+		return handleError(c, err)
+	}
+
 	result, err := h.usageReader.GetUsageLog(c.Request().Context(), params)
 	if err != nil {
 		return handleError(c, err)
@@ -569,7 +1044,9 @@ func (h *Handler) UsageLog(c *echo.Context) error {
 // @Param        days        query     int     false  "Number of days (default 30)"
 // @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        interval    query     string  false  "Grouping interval: daily, weekly, monthly, yearly (default daily)"
+// @Param        week_start  query     string  false  "First day of weekly buckets: monday or sunday (default monday)"
 // @Param        user_path   query     string  false  "Filter by tracked user path subtree"
 // @Param        cache_mode  query     string  false  "Cache mode filter: uncached, cached, all (cache overview always uses cached mode)"
 // @Success      200  {object}  usage.CacheOverview
@@ -587,7 +1064,7 @@ func (h *Handler) CacheOverview(c *echo.Context) error {
 		})
 	}
 
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, h.maxQueryDays)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -616,17 +1093,22 @@ func (h *Handler) CacheOverview(c *echo.Context) error {
 // @Param        days         query     int     false  "Number of days (default 30)"
 // @Param        start_date   query     string  false  "Start date (YYYY-MM-DD)"
 // @Param        end_date     query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz           query     string  false  "IANA time zone for day boundaries (default UTC)"
 // @Param        requested_model  query     string  false  "Filter by requested model selector"
 // @Param        provider     query     string  false  "Filter by provider name or provider type"
 // @Param        method       query     string  false  "Filter by HTTP method"
 // @Param        path         query     string  false  "Filter by request path"
 // @Param        user_path    query     string  false  "Filter by tracked user path subtree"
 // @Param        error_type   query     string  false  "Filter by error type"
+// @Param        response_id  query     string  false  "Filter by client-facing or provider-issued response ID"
+// @Param        request_id   query     string  false  "Filter by exact gateway request ID"
 // @Param        status_code  query     int     false  "Filter by status code"
+// @Param        upstream_status_code  query     int     false  "Filter by the status code the provider returned"
 // @Param        stream       query     bool    false  "Filter by stream mode (true/false)"
 // @Param        search       query     string  false  "Search across request_id/requested_model/provider/method/path/error_type/error_message"
 // @Param        limit        query     int     false  "Page size (default 25, max 100)"
 // @Param        offset       query     int     false  "Offset for pagination"
+// @Param        cursor       query     string  false  "next_cursor of the previous page; preferred over offset"
 // @Success      200  {object}  auditlog.LogListResult
 // @Failure      400  {object}  core.GatewayError
 // @Failure      401  {object}  core.GatewayError
@@ -638,7 +1120,7 @@ func (h *Handler) AuditLog(c *echo.Context) error {
 		})
 	}
 
-	dateRange, err := parseDateRangeParams(c)
+	dateRange, err := parseDateRangeParams(c, h.maxQueryDays)
 	if err != nil {
 		return handleError(c, err)
 	}
@@ -663,6 +1145,8 @@ func (h *Handler) AuditLog(c *echo.Context) error {
 		Path:           c.QueryParam("path"),
 		UserPath:       userPath,
 		ErrorType:      c.QueryParam("error_type"),
+		ResponseID:     strings.TrimSpace(c.QueryParam("response_id")),
+		RequestID:      strings.TrimSpace(c.QueryParam("request_id")),
 		Search:         c.QueryParam("search"),
 	}
 
@@ -673,6 +1157,13 @@ func (h *Handler) AuditLog(c *echo.Context) error {
 		}
 		params.StatusCode = &parsed
 	}
+	if sc := c.QueryParam("upstream_status_code"); sc != "" {
+		parsed, err := strconv.Atoi(sc)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("invalid upstream_status_code, expected integer", nil))
+		}
+		params.UpstreamStatusCode = &parsed
+	}
 
 	if stream := c.QueryParam("stream"); stream != "" {
 		parsed, err := strconv.ParseBool(stream)
@@ -693,6 +1184,10 @@ func (h *Handler) AuditLog(c *echo.Context) error {
 		}
 	}
 
+	if params.Cursor, err = parseCursorParam(c, params.Offset); err != nil {
+		return handleError(c, err)
+	}
+
 	result, err := h.auditReader.GetLogs(c.Request().Context(), params)
 	if err != nil {
 		return handleError(c, err)
@@ -701,6 +1196,78 @@ func (h *Handler) AuditLog(c *echo.Context) error {
 	if result.Entries == nil {
 		result.Entries = []auditlog.LogEntry{}
 	}
+	auditMetadataOnly(c, result.Entries)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// ErrorSummary handles GET /admin/api/v1/errors/summary
+//
+// @Summary      Get error analytics grouped by provider, model, error type and status
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days          query     int     false  "Number of days (default 30)"
+// @Param        start_date    query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date      query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz            query     string  false  "IANA time zone for day boundaries (default UTC)"
+// @Param        interval      query     string  false  "Series bucket interval: hourly or daily (default daily)"
+// @Param        top_messages  query     int     false  "Number of most frequent normalized error messages (default 0, max 50)"
+// @Success      200  {object}  auditlog.ErrorSummary
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/errors/summary [get]
+func (h *Handler) ErrorSummary(c *echo.Context) error {
+	interval, ok := auditlog.NormalizeErrorInterval(c.QueryParam("interval"))
+	if !ok {
+		return handleError(c, core.NewInvalidRequestError("invalid interval parameter: expected hourly or daily", nil))
+	}
+
+	topMessages := 0
+	if n := c.QueryParam("top_messages"); n != "" {
+		parsed, err := strconv.Atoi(n)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("invalid top_messages, expected integer", nil))
+		}
+		if parsed < 0 || parsed > 50 {
+			return handleError(c, core.NewInvalidRequestError("invalid top_messages parameter: top_messages must be between 0 and 50", nil))
+		}
+		topMessages = parsed
+	}
+
+	if h.auditReader == nil {
+		return c.JSON(http.StatusOK, auditlog.ErrorSummary{
+			Interval: interval,
+			Groups:   []auditlog.ErrorGroup{},
+			Series:   []auditlog.ErrorSeriesPoint{},
+		})
+	}
+
+	dateRange, err := parseDateRangeParams(c, h.maxQueryDays)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	result, err := h.auditReader.GetErrorSummary(c.Request().Context(), auditlog.ErrorSummaryParams{
+		QueryParams: auditlog.QueryParams{
+			StartDate: dateRange.StartDate,
+			EndDate:   dateRange.EndDate,
+		},
+		Interval:    interval,
+		TopMessages: topMessages,
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+	if result == nil {
+		result = &auditlog.ErrorSummary{Interval: interval}
+	}
+	if result.Groups == nil {
+		result.Groups = []auditlog.ErrorGroup{}
+	}
+	if result.Series == nil {
+		result.Series = []auditlog.ErrorSeriesPoint{}
+	}
 
 	return c.JSON(http.StatusOK, result)
 }
@@ -755,152 +1322,2071 @@ func (h *Handler) AuditConversation(c *echo.Context) error {
 	if result.Entries == nil {
 		result.Entries = []auditlog.LogEntry{}
 	}
+	auditMetadataOnly(c, result.Entries)
 
 	return c.JSON(http.StatusOK, result)
 }
 
-// ListModels handles GET /admin/api/v1/models
-// Supports optional ?category= query param for filtering by model category.
+// AuditLogEntry handles GET /admin/api/v1/audit/{id}
 //
-// @Summary      List all registered models with provider info
+// @Summary      Get an audit log entry
+// @Description  Returns one audit log entry by ID. Callers below the admin role get it without headers or bodies.
 // @Tags         admin
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {array}  providers.ModelWithProvider
+// @Param        id   path      string  true  "Audit log entry ID"
+// @Success      200  {object}  auditlog.LogEntry
 // @Failure      401  {object}  core.GatewayError
-// @Router       /admin/api/v1/models [get]
-type modelAccessResponse struct {
-	Selector         string                   `json:"selector"`
-	DefaultEnabled   bool                     `json:"default_enabled"`
-	EffectiveEnabled bool                     `json:"effective_enabled"`
-	UserPaths        []string                 `json:"user_paths,omitempty"`
-	Override         *modeloverrides.Override `json:"override,omitempty"`
-}
-
-type modelInventoryResponse struct {
-	providers.ModelWithProvider
-	Access modelAccessResponse `json:"access"`
-}
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/audit/{id} [get]
+func (h *Handler) AuditLogEntry(c *echo.Context) error {
+	if h.auditReader == nil {
+		return handleError(c, h.auditLogUnavailableError())
+	}
 
-func (h *Handler) ListModels(c *echo.Context) error {
-	if h.registry == nil {
-		return c.JSON(http.StatusOK, []modelInventoryResponse{})
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return handleError(c, core.NewInvalidRequestError("audit log id is required", nil))
 	}
 
-	cat := core.ModelCategory(c.QueryParam("category"))
-	if cat != "" && cat != core.CategoryAll {
-		if !isValidCategory(cat) {
-			return handleError(c, core.NewInvalidRequestError("invalid category: "+string(cat), nil))
+	entry, err := h.auditReader.GetLogByID(c.Request().Context(), id)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if entry == nil {
+		return handleError(c, core.NewNotFoundError("audit log entry not found: "+id))
+	}
+	entries := []auditlog.LogEntry{*entry}
+	auditMetadataOnly(c, entries)
+	return c.JSON(http.StatusOK, entries[0])
+}
+
+// AuditLogByResponseID handles GET /admin/api/v1/audit/by-response-id/{id}
+//
+// @Summary      Find the audit log entry that produced a response
+// @Description  Matches the response ID returned to the client (chat or Responses "id") and, for converted providers whose IDs the gateway synthesizes, the ID the provider issued.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Response ID, e.g. chatcmpl-abc123"
+// @Success      200  {object}  auditlog.LogEntry
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/audit/by-response-id/{id} [get]
+func (h *Handler) AuditLogByResponseID(c *echo.Context) error {
+	if h.auditReader == nil {
+		return handleError(c, h.auditLogUnavailableError())
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return handleError(c, core.NewInvalidRequestError("response id is required", nil))
+	}
+
+	entry, err := h.auditReader.GetLogByResponseID(c.Request().Context(), id)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if entry == nil {
+		return handleError(c, core.NewNotFoundError("no audit log entry for response id: "+id))
+	}
+	entries := []auditlog.LogEntry{*entry}
+	auditMetadataOnly(c, entries)
+	return c.JSON(http.StatusOK, entries[0])
+}
+
+// requestTraceLimit bounds the audit log entries and usage records read for
+// one request ID.
+const requestTraceLimit = 20
+
+// RequestTrace joins what the gateway recorded about one request. A section
+// that could not be assembled is null, and Missing says why.
+type RequestTrace struct {
+	RequestID string `json:"request_id"`
+	// Audit is the newest audit log entry recorded for the request ID.
+	Audit *auditlog.LogEntry `json:"audit" extensions:"x-nullable"`
+	// Attempts lists the audit log entries for the request ID, oldest first.
+	// Clients that retry with the same X-Request-ID produce more than one.
+	Attempts []RequestTraceAttempt `json:"attempts"`
+	// Usage is the newest usage record for the request ID, with token counts,
+	// costs and provider-reported details such as cached and reasoning tokens
+	// in raw_data.
+	Usage           *usage.UsageLogEntry         `json:"usage" extensions:"x-nullable"`
+	Timing          *RequestTraceTiming          `json:"timing" extensions:"x-nullable"`
+	Route           *RequestTraceRoute           `json:"route" extensions:"x-nullable"`
+	Transformations *RequestTraceTransformations `json:"transformations" extensions:"x-nullable"`
+	Flags           RequestTraceFlags            `json:"flags"`
+	// Missing maps every null section or flag, such as "usage" or
+	// "flags.moderation", to the reason it is absent.
+	Missing map[string]string `json:"missing"`
+}
+
+// RequestTraceAttempt summarizes one audit log entry of a traced request.
+type RequestTraceAttempt struct {
+	ID            string    `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	StatusCode    int       `json:"status_code"`
+	ErrorType     string    `json:"error_type,omitempty"`
+	DurationNs    int64     `json:"duration_ns"`
+	Provider      string    `json:"provider,omitempty"`
+	ProviderName  string    `json:"provider_name,omitempty"`
+	ResolvedModel string    `json:"resolved_model,omitempty"`
+}
+
+// RequestTraceTiming breaks down where the time of a request went.
+type RequestTraceTiming struct {
+	DurationNs         int64 `json:"duration_ns"`
+	UpstreamDurationNs int64 `json:"upstream_duration_ns,omitempty"`
+	// GatewayOverheadNs is the time spent outside the upstream call. It is
+	// omitted when the upstream duration was not recorded.
+	GatewayOverheadNs *int64                         `json:"gateway_overhead_ns,omitempty"`
+	PacingDelayNs     int64                          `json:"pacing_delay_ns,omitempty"`
+	Stream            *auditlog.StreamTimingSnapshot `json:"stream,omitempty"`
+}
+
+// RequestTraceRoute describes where a request was sent. Source is "audit"
+// when it comes from the audit log entry and "usage" when only the usage
+// record was found.
+type RequestTraceRoute struct {
+	Source             string `json:"source"`
+	Method             string `json:"method,omitempty"`
+	Path               string `json:"path,omitempty"`
+	Stream             bool   `json:"stream,omitempty"`
+	RequestedModel     string `json:"requested_model,omitempty"`
+	ResolvedModel      string `json:"resolved_model,omitempty"`
+	ServedModel        string `json:"served_model,omitempty"`
+	Provider           string `json:"provider,omitempty"`
+	ProviderName       string `json:"provider_name,omitempty"`
+	AliasUsed          bool   `json:"alias_used,omitempty"`
+	WorkflowVersionID  string `json:"workflow_version_id,omitempty"`
+	CacheType          string `json:"cache_type,omitempty"`
+	StatusCode         int    `json:"status_code,omitempty"`
+	UpstreamStatusCode int    `json:"upstream_status_code,omitempty"`
+}
+
+// RequestTraceTransformations lists what the gateway applied to a request
+// before sending it, as captured in its audit log entry.
+type RequestTraceTransformations struct {
+	WorkflowFeatures  *auditlog.WorkflowFeaturesSnapshot  `json:"workflow_features,omitempty"`
+	Experiment        *auditlog.ExperimentSnapshot        `json:"experiment,omitempty"`
+	PromptTemplate    *auditlog.PromptTemplateSnapshot    `json:"prompt_template,omitempty"`
+	ContextOverflow   *auditlog.ContextOverflowSnapshot   `json:"context_overflow,omitempty"`
+	PromptCompression *auditlog.PromptCompressionSnapshot `json:"prompt_compression,omitempty"`
+	Failover          *auditlog.FailoverSnapshot          `json:"failover,omitempty"`
+	Chaos             *auditlog.ChaosSnapshot             `json:"chaos,omitempty"`
+	GuardrailCanaries []auditlog.GuardrailCanarySnapshot  `json:"guardrail_canaries,omitempty"`
+	Labels            map[string]string                   `json:"labels,omitempty"`
+}
+
+// RequestTraceFlags holds the moderation result and usage anomalies of a
+// traced request.
+type RequestTraceFlags struct {
+	Moderation *auditlog.ModerationSnapshot `json:"moderation" extensions:"x-nullable"`
+	// Anomalies were detected for the request's model, provider or API key
+	// in the minute the request was recorded.
+	Anomalies []anomaly.Anomaly           `json:"anomalies" extensions:"x-nullable"`
+	Redaction *auditlog.RedactionSnapshot `json:"redaction,omitempty"`
+}
+
+// RequestTrace handles GET /admin/api/v1/requests/{request_id}
+//
+// @Summary      Trace a request by ID
+// @Description  Joins the audit log entries, the usage record, timing, route, applied transformations, moderation result and usage anomalies of one request. Sections that cannot be assembled, for example usage that was not recorded, are null and listed in missing with the reason. Answers 404 when neither store has the request ID.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request_id  path      string  true  "Gateway request ID (X-Request-ID)"
+// @Success      200  {object}  admin.RequestTrace
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/requests/{request_id} [get]
+func (h *Handler) RequestTrace(c *echo.Context) error {
+	if h.auditReader == nil && h.usageReader == nil {
+		return handleError(c, featureUnavailableError("request tracing needs audit log or usage storage"))
+	}
+
+	requestID := strings.TrimSpace(c.Param("request_id"))
+	if requestID == "" {
+		return handleError(c, core.NewInvalidRequestError("request id is required", nil))
+	}
+
+	ctx := c.Request().Context()
+	trace := RequestTrace{
+		RequestID: requestID,
+		Attempts:  []RequestTraceAttempt{},
+		Missing:   map[string]string{},
+	}
+	var lookupErr error
+
+	switch {
+	case h.auditReader == nil:
+		trace.Missing["audit"] = "audit logging is disabled"
+	default:
+		result, err := h.auditReader.GetLogs(ctx, auditlog.LogQueryParams{RequestID: requestID, Limit: requestTraceLimit})
+		switch {
+		case err != nil:
+			slog.Warn("request trace audit log lookup failed", "request_id", requestID, "error", err)
+			trace.Missing["audit"] = "audit log lookup failed"
+			lookupErr = err
+		case result == nil || len(result.Entries) == 0:
+			trace.Missing["audit"] = "no audit log entry recorded for this request ID"
+		default:
+			entries := result.Entries
+			auditMetadataOnly(c, entries)
+			// GetLogs returns the newest entry first.
+			trace.Audit = &entries[0]
+			for i := len(entries) - 1; i >= 0; i-- {
+				trace.Attempts = append(trace.Attempts, requestTraceAttempt(entries[i]))
+			}
 		}
 	}
 
-	var models []providers.ModelWithProvider
-	if cat != "" && cat != core.CategoryAll {
-		models = h.registry.ListModelsWithProviderByCategory(cat)
+	switch {
+	case h.usageReader == nil:
+		trace.Missing["usage"] = "usage tracking is disabled"
+	default:
+		result, err := h.usageReader.GetUsageLog(ctx, usage.UsageLogParams{
+			UsageQueryParams: usage.UsageQueryParams{CacheMode: usage.CacheModeAll},
+			RequestID:        requestID,
+			Limit:            requestTraceLimit,
+		})
+		switch {
+		case err != nil:
+			slog.Warn("request trace usage lookup failed", "request_id", requestID, "error", err)
+			trace.Missing["usage"] = "usage lookup failed"
+			lookupErr = err
+		case result == nil || len(result.Entries) == 0:
+			trace.Missing["usage"] = missingUsageReason(trace.Audit)
+		default:
+			trace.Usage = &result.Entries[0]
+		}
+	}
+
+	if trace.Audit == nil && trace.Usage == nil {
+		if lookupErr != nil {
+			return handleError(c, lookupErr)
+		}
+		return handleError(c, core.NewNotFoundError("no audit log entry or usage record for request id: "+requestID))
+	}
+
+	trace.Route = requestTraceRoute(trace.Audit, trace.Usage)
+	if trace.Audit == nil {
+		trace.Missing["timing"] = "timing comes from the audit log entry, which is unavailable"
+		trace.Missing["transformations"] = "transformations come from the audit log entry, which is unavailable"
+		trace.Missing["flags.moderation"] = "moderation results come from the audit log entry, which is unavailable"
 	} else {
-		models = h.registry.ListModelsWithProvider()
+		trace.Timing = requestTraceTiming(trace.Audit)
+		data := trace.Audit.Data
+		switch {
+		case data == nil:
+			trace.Missing["transformations"] = "the audit log entry has no captured details"
+			trace.Missing["flags.moderation"] = "the audit log entry has no captured details"
+		default:
+			trace.Transformations = requestTraceTransformations(data)
+			trace.Flags.Moderation = data.Moderation
+			trace.Flags.Redaction = data.Redaction
+			if data.Moderation == nil {
+				trace.Missing["flags.moderation"] = "moderation did not run for this request"
+			}
+		}
+	}
+	if h.anomalies == nil {
+		trace.Missing["flags.anomalies"] = "anomaly detection is disabled"
+	} else {
+		trace.Flags.Anomalies = requestAnomalies(h.anomalies.List(false), trace.Audit, trace.Usage)
 	}
 
-	if models == nil {
-		models = []providers.ModelWithProvider{}
+	return c.JSON(http.StatusOK, trace)
+}
+
+// missingUsageReason explains why a request with the given audit log entry,
+// possibly nil, has no usage record.
+func missingUsageReason(entry *auditlog.LogEntry) string {
+	switch {
+	case entry == nil:
+		return "no usage recorded for this request ID"
+	case entry.Data != nil && entry.Data.WorkflowFeatures != nil && !entry.Data.WorkflowFeatures.Usage:
+		return "usage tracking was disabled for this request by its workflow"
+	case entry.StatusCode >= http.StatusBadRequest:
+		return fmt.Sprintf("no usage recorded; the request failed with status %d", entry.StatusCode)
+	default:
+		return "no usage recorded for this request ID"
 	}
-	if h.modelOverrides == nil {
-		response := make([]modelInventoryResponse, 0, len(models))
-		for _, model := range models {
-			selector := core.ModelSelector{
-				Provider: strings.TrimSpace(model.ProviderName),
-				Model:    strings.TrimSpace(model.Model.ID),
+}
+
+func requestTraceAttempt(entry auditlog.LogEntry) RequestTraceAttempt {
+	return RequestTraceAttempt{
+		ID:            entry.ID,
+		Timestamp:     entry.Timestamp,
+		StatusCode:    entry.StatusCode,
+		ErrorType:     entry.ErrorType,
+		DurationNs:    entry.DurationNs,
+		Provider:      entry.Provider,
+		ProviderName:  entry.ProviderName,
+		ResolvedModel: entry.ResolvedModel,
+	}
+}
+
+func requestTraceTiming(entry *auditlog.LogEntry) *RequestTraceTiming {
+	timing := &RequestTraceTiming{
+		DurationNs:         entry.DurationNs,
+		UpstreamDurationNs: entry.UpstreamDurationNs,
+	}
+	if entry.UpstreamDurationNs > 0 && entry.DurationNs >= entry.UpstreamDurationNs {
+		overhead := entry.DurationNs - entry.UpstreamDurationNs
+		timing.GatewayOverheadNs = &overhead
+	}
+	if entry.Data != nil {
+		timing.PacingDelayNs = entry.Data.PacingDelayNs
+		timing.Stream = entry.Data.StreamTiming
+	}
+	return timing
+}
+
+// requestTraceRoute prefers the audit log entry, which records the whole
+// route, and falls back to the usage record.
+func requestTraceRoute(entry *auditlog.LogEntry, record *usage.UsageLogEntry) *RequestTraceRoute {
+	if entry != nil {
+		return &RequestTraceRoute{
+			Source:             "audit",
+			Method:             entry.Method,
+			Path:               entry.Path,
+			Stream:             entry.Stream,
+			RequestedModel:     entry.RequestedModel,
+			ResolvedModel:      entry.ResolvedModel,
+			ServedModel:        entry.ServedModel,
+			Provider:           entry.Provider,
+			ProviderName:       entry.ProviderName,
+			AliasUsed:          entry.AliasUsed,
+			WorkflowVersionID:  entry.WorkflowVersionID,
+			CacheType:          entry.CacheType,
+			StatusCode:         entry.StatusCode,
+			UpstreamStatusCode: entry.UpstreamStatusCode,
+		}
+	}
+	return &RequestTraceRoute{
+		Source:         "usage",
+		Path:           record.Endpoint,
+		RequestedModel: record.RequestedModel,
+		ResolvedModel:  record.Model,
+		ServedModel:    record.ServedModel,
+		Provider:       record.Provider,
+		ProviderName:   record.ProviderName,
+		CacheType:      record.CacheType,
+	}
+}
+
+func requestTraceTransformations(data *auditlog.LogData) *RequestTraceTransformations {
+	return &RequestTraceTransformations{
+		WorkflowFeatures:  data.WorkflowFeatures,
+		Experiment:        data.Experiment,
+		PromptTemplate:    data.PromptTemplate,
+		ContextOverflow:   data.ContextOverflow,
+		PromptCompression: data.PromptCompression,
+		Failover:          data.Failover,
+		Chaos:             data.Chaos,
+		GuardrailCanaries: data.GuardrailCanaries,
+		Labels:            data.Labels,
+	}
+}
+
+// requestAnomalies returns the anomalies detected for the model, provider or
+// API key of a request in the minute it was recorded. The detector buckets
+// usage per minute under the usage record's timestamp, so that record is
+// preferred over the audit log entry.
+func requestAnomalies(all []anomaly.Anomaly, entry *auditlog.LogEntry, record *usage.UsageLogEntry) []anomaly.Anomaly {
+	var at time.Time
+	subjects := map[string]string{}
+	if entry != nil {
+		at = entry.Timestamp
+		subjects[config.AnomalyScopeModel] = entry.ResolvedModel
+		subjects[config.AnomalyScopeProvider] = cmp.Or(entry.ProviderName, entry.Provider)
+		subjects[config.AnomalyScopeKey] = entry.AuthKeyID
+	}
+	if record != nil {
+		at = record.Timestamp
+		subjects[config.AnomalyScopeModel] = record.Model
+		subjects[config.AnomalyScopeProvider] = cmp.Or(record.ProviderName, record.Provider)
+		subjects[config.AnomalyScopeKey] = record.AuthKeyID
+	}
+
+	minute := at.Unix() / 60
+	found := []anomaly.Anomaly{}
+	for _, a := range all {
+		if subject := subjects[a.Scope]; subject != "" && a.Subject == subject && a.DetectedAt.Unix()/60 == minute {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// RedactAuditLog handles POST /admin/api/v1/audit/{id}/redact
+//
+// @Summary      Redact the bodies and headers of an audit log entry
+// @Description  Replaces the captured request and response bodies with a redaction marker and drops captured headers. Metadata is kept, and the redaction is recorded with the acting admin key hash.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Audit log entry ID"
+// @Success      200  {object}  auditlog.LogEntry
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      409  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/audit/{id}/redact [post]
+func (h *Handler) RedactAuditLog(c *echo.Context) error {
+	redactor, err := h.auditRedactor()
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return handleError(c, core.NewInvalidRequestError("audit log id is required", nil))
+	}
+
+	if err := h.deleteStreamSamplesForLog(c.Request().Context(), id); err != nil {
+		return handleError(c, err)
+	}
+	entry, err := redactor.RedactLog(c.Request().Context(), id, auditActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auditlog.ErrNotFound):
+			return handleError(c, core.NewNotFoundError("audit log entry not found: "+id))
+		case errors.Is(err, auditlog.ErrAlreadyRedacted):
+			return handleError(c, core.NewInvalidRequestErrorWithStatus(http.StatusConflict, "audit log entry already redacted: "+id, err))
+		case errors.Is(err, auditlog.ErrRedactionUnsupported):
+			return handleError(c, featureUnavailableError(err.Error()))
+		}
+		return handleError(c, err)
+	}
+	if entry == nil {
+		return handleError(c, core.NewNotFoundError("audit log entry not found: "+id))
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// DeleteAuditLog handles DELETE /admin/api/v1/audit/{id}
+//
+// @Summary      Permanently delete an audit log entry
+// @Description  Removes the entry entirely. The removal is recorded in the redaction log with the acting admin key hash.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Audit log entry ID"
+// @Success      204
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/audit/{id} [delete]
+func (h *Handler) DeleteAuditLog(c *echo.Context) error {
+	var deleteFunc func(context.Context, string) error
+	redactor, unavailableErr := h.auditRedactor()
+	if unavailableErr == nil {
+		actor := auditActor(c)
+		deleteFunc = func(ctx context.Context, id string) error {
+			if err := h.deleteStreamSamplesForLog(ctx, id); err != nil {
+				return err
 			}
-			response = append(response, modelInventoryResponse{
-				ModelWithProvider: model,
-				Access: modelAccessResponse{
-					Selector:         selector.QualifiedModel(),
-					DefaultEnabled:   true,
-					EffectiveEnabled: true,
-				},
-			})
+			return redactor.DeleteLog(ctx, id, actor)
 		}
-		return c.JSON(http.StatusOK, response)
 	}
+	return deactivateByID(c, unavailableErr, "audit log", auditlog.ErrNotFound, "audit log entry not found: ", deleteFunc, func(err error) error {
+		if errors.Is(err, auditlog.ErrRedactionUnsupported) {
+			return featureUnavailableError(err.Error())
+		}
+		return err
+	})
+}
 
-	response := make([]modelInventoryResponse, 0, len(models))
-	for _, model := range models {
-		selector := core.ModelSelector{
-			Provider: strings.TrimSpace(model.ProviderName),
-			Model:    strings.TrimSpace(model.Model.ID),
+// auditRedactor returns the audit reader as a Redactor, or the error to
+// answer with when audit logs are unavailable or cannot be redacted.
+func (h *Handler) auditRedactor() (auditlog.Redactor, error) {
+	if h.auditReader == nil {
+		return nil, h.auditLogUnavailableError()
+	}
+	redactor, ok := h.auditReader.(auditlog.Redactor)
+	if !ok {
+		return nil, featureUnavailableError("audit log storage does not support redaction")
+	}
+	return redactor, nil
+}
+
+// auditReEncrypter is implemented by audit readers that decrypt sealed
+// entries and can migrate them to the active key.
+type auditReEncrypter interface {
+	ReEncrypt(ctx context.Context, after string, limit int) (*auditlog.ReEncryptResult, error)
+}
+
+// ReEncryptAuditLogs handles POST /admin/api/v1/audit/re-encrypt
+//
+// @Summary      Re-encrypt audit log entries with the active key
+// @Description  Rewraps the data keys of up to limit audit log entries that were encrypted with an older master key so they use the active key. Call it again with the returned next cursor until done is true; entries whose key is no longer configured are listed as failures and skipped.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        after  query     string  false  "Cursor returned by the previous call"
+// @Param        limit  query     int     false  "Entries to process (default 500, max 5000)"
+// @Success      200    {object}  auditlog.ReEncryptResult
+// @Failure      400    {object}  core.GatewayError
+// @Failure      401    {object}  core.GatewayError
+// @Failure      503    {object}  core.GatewayError
+// @Router       /admin/api/v1/audit/re-encrypt [post]
+func (h *Handler) ReEncryptAuditLogs(c *echo.Context) error {
+	if h.auditReader == nil {
+		return handleError(c, h.auditLogUnavailableError())
+	}
+	reEncrypter, ok := h.auditReader.(auditReEncrypter)
+	if !ok {
+		return handleError(c, featureUnavailableError("audit log encryption is not configured"))
+	}
+
+	limit := auditlog.DefaultReEncryptLimit
+	if l := c.QueryParam("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("invalid limit, expected integer", nil))
 		}
-		effective := h.modelOverrides.EffectiveState(selector)
-		access := modelAccessResponse{
-			Selector:         effective.Selector,
-			DefaultEnabled:   effective.DefaultEnabled,
-			EffectiveEnabled: effective.Enabled,
-			UserPaths:        append([]string(nil), effective.UserPaths...),
+		if parsed < 1 || parsed > auditlog.MaxReEncryptLimit {
+			return handleError(c, core.NewInvalidRequestError(fmt.Sprintf("invalid limit parameter: limit must be between 1 and %d", auditlog.MaxReEncryptLimit), nil))
 		}
-		if override, ok := h.modelOverrides.Get(selector.QualifiedModel()); ok && override != nil {
-			overrideCopy := *override
-			access.Override = &overrideCopy
+		limit = parsed
+	}
+
+	result, err := reEncrypter.ReEncrypt(c.Request().Context(), strings.TrimSpace(c.QueryParam("after")), limit)
+	if err != nil {
+		if errors.Is(err, auditlog.ErrEncryptionDisabled) {
+			return handleError(c, featureUnavailableError(err.Error()))
 		}
-		response = append(response, modelInventoryResponse{
-			ModelWithProvider: model,
-			Access:            access,
-		})
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// auditActor identifies the admin performing an audit log change by the same
+// short key hash the audit log records for requests.
+func auditActor(c *echo.Context) string {
+	return auditlog.HashAPIKey(c.Request().Header.Get("Authorization"))
+}
+
+// deleteStreamSamplesForLog removes the stream samples of an audit log entry
+// before its bodies are redacted or the entry is deleted.
+func (h *Handler) deleteStreamSamplesForLog(ctx context.Context, logID string) error {
+	if h.streamSamples == nil {
+		return nil
+	}
+	return h.streamSamples.DeleteForLog(ctx, logID)
+}
+
+func streamSamplesUnavailableError() error {
+	return featureUnavailableError("stream sampling is unavailable; set LOGGING_STREAM_SAMPLE_RATE or LOGGING_STREAM_SAMPLE_OPT_IN")
+}
+
+// StreamSamples handles GET /admin/api/v1/stream-samples
+//
+// @Summary      List stream samples
+// @Description  Lists the streamed responses sampled for review, newest first, without their prompt and response text.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days         query     int     false  "Number of days (default 30)"
+// @Param        start_date   query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date     query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz           query     string  false  "IANA time zone for day boundaries (default UTC)"
+// @Param        model        query     string  false  "Filter by requested or resolved model"
+// @Param        limit        query     int     false  "Page size (default 25, max 100)"
+// @Param        offset       query     int     false  "Offset for pagination"
+// @Success      200  {object}  auditlog.StreamSampleListResult
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/stream-samples [get]
+func (h *Handler) StreamSamples(c *echo.Context) error {
+	if h.streamSamples == nil {
+		return handleError(c, streamSamplesUnavailableError())
+	}
+
+	dateRange, err := parseDateRangeParams(c, h.maxQueryDays)
+	if err != nil {
+		return handleError(c, err)
+	}
+	params := auditlog.StreamSampleQueryParams{
+		QueryParams: auditlog.QueryParams{
+			StartDate: dateRange.StartDate,
+			EndDate:   dateRange.EndDate,
+		},
+		Model: strings.TrimSpace(c.QueryParam("model")),
+	}
+	if l := c.QueryParam("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			params.Limit = parsed
+		}
+	}
+	if o := c.QueryParam("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			params.Offset = parsed
+		}
+	}
+
+	result, err := h.streamSamples.List(c.Request().Context(), params)
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// StreamSample handles GET /admin/api/v1/stream-samples/{id}
+//
+// @Summary      Get a stream sample
+// @Description  Returns a sampled streamed response with its complete prompt and response text.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Stream sample ID"
+// @Success      200  {object}  auditlog.StreamSample
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/stream-samples/{id} [get]
+func (h *Handler) StreamSample(c *echo.Context) error {
+	if h.streamSamples == nil {
+		return handleError(c, streamSamplesUnavailableError())
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return handleError(c, core.NewInvalidRequestError("stream sample id is required", nil))
 	}
+	sample, err := h.streamSamples.Get(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, auditlog.ErrStreamSampleNotFound) {
+			return handleError(c, core.NewNotFoundError("stream sample not found: "+id))
+		}
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, sample)
+}
+
+// DeleteStreamSample handles DELETE /admin/api/v1/stream-samples/{id}
+//
+// @Summary      Delete a stream sample
+// @Description  Removes a stream sample and returns its size to the sample byte budget.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Stream sample ID"
+// @Success      204
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/stream-samples/{id} [delete]
+func (h *Handler) DeleteStreamSample(c *echo.Context) error {
+	var unavailableErr error
+	var deleteFunc func(context.Context, string) error
+	if h.streamSamples == nil {
+		unavailableErr = streamSamplesUnavailableError()
+	} else {
+		deleteFunc = h.streamSamples.Delete
+	}
+	return deactivateByID(c, unavailableErr, "stream sample", auditlog.ErrStreamSampleNotFound, "stream sample not found: ", deleteFunc, func(err error) error { return err })
+}
+
+// ListModels handles GET /admin/api/v1/models
+// Supports optional ?category= query param for filtering by a built-in or
+// configured custom model category.
+//
+// @Summary      List all registered models with provider info
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category  query  string  false  "Filter by built-in or custom model category"
+// @Success      200  {array}  providers.ModelWithProvider
+// @Success      304  "Not modified; the If-None-Match header matches the current listing"
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/models [get]
+type modelAccessResponse struct {
+	Selector         string                   `json:"selector"`
+	DefaultEnabled   bool                     `json:"default_enabled"`
+	EffectiveEnabled bool                     `json:"effective_enabled"`
+	UserPaths        []string                 `json:"user_paths,omitempty"`
+	Override         *modeloverrides.Override `json:"override,omitempty"`
+}
+
+type modelInventoryResponse struct {
+	providers.ModelWithProvider
+	Access modelAccessResponse `json:"access"`
+}
+
+func (h *Handler) ListModels(c *echo.Context) error {
+	if h.registry == nil {
+		return c.JSON(http.StatusOK, []modelInventoryResponse{})
+	}
+
+	cat := core.ModelCategory(c.QueryParam("category"))
+	if cat != "" && cat != core.CategoryAll {
+		if !h.registry.IsKnownCategory(cat) {
+			return handleError(c, core.NewInvalidRequestError("invalid category: "+string(cat), nil))
+		}
+	}
+
+	var models []providers.ModelWithProvider
+	if cat != "" && cat != core.CategoryAll {
+		models = h.registry.ListModelsWithProviderByCategory(cat)
+	} else {
+		models = h.registry.ListModelsWithProvider()
+	}
+
+	if models == nil {
+		models = []providers.ModelWithProvider{}
+	}
+	if h.modelOverrides == nil {
+		response := make([]modelInventoryResponse, 0, len(models))
+		for _, model := range models {
+			selector := core.ModelSelector{
+				Provider: strings.TrimSpace(model.ProviderName),
+				Model:    strings.TrimSpace(model.Model.ID),
+			}
+			response = append(response, modelInventoryResponse{
+				ModelWithProvider: model,
+				Access: modelAccessResponse{
+					Selector:         selector.QualifiedModel(),
+					DefaultEnabled:   true,
+					EffectiveEnabled: true,
+				},
+			})
+		}
+		return writeModelListing(c, response)
+	}
+
+	response := make([]modelInventoryResponse, 0, len(models))
+	for _, model := range models {
+		selector := core.ModelSelector{
+			Provider: strings.TrimSpace(model.ProviderName),
+			Model:    strings.TrimSpace(model.Model.ID),
+		}
+		effective := h.modelOverrides.EffectiveState(selector)
+		access := modelAccessResponse{
+			Selector:         effective.Selector,
+			DefaultEnabled:   effective.DefaultEnabled,
+			EffectiveEnabled: effective.Enabled,
+			UserPaths:        append([]string(nil), effective.UserPaths...),
+		}
+		if override, ok := h.modelOverrides.Get(selector.QualifiedModel()); ok && override != nil {
+			overrideCopy := *override
+			access.Override = &overrideCopy
+		}
+		response = append(response, modelInventoryResponse{
+			ModelWithProvider: model,
+			Access:            access,
+		})
+	}
+
+	return writeModelListing(c, response)
+}
+
+// writeModelListing answers a model listing with ETag and Cache-Control
+// headers so dashboards can revalidate it with If-None-Match.
+func writeModelListing(c *echo.Context, v any) error {
+	return core.WriteCacheableJSON(c.Response(), c.Request(), core.ModelListCacheControl, v)
+}
+
+// ListCategories handles GET /admin/api/v1/models/categories
+//
+// @Summary      List model categories with counts
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   providers.CategoryCount
+// @Success      304  "Not modified; the If-None-Match header matches the current counts"
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/models/categories [get]
+func (h *Handler) ListCategories(c *echo.Context) error {
+	if h.registry == nil {
+		return c.JSON(http.StatusOK, []providers.CategoryCount{})
+	}
+
+	return writeModelListing(c, h.registry.GetCategoryCounts())
+}
+
+// DashboardConfig handles GET /admin/api/v1/dashboard/config
+func (h *Handler) DashboardConfig(c *echo.Context) error {
+	return c.JSON(http.StatusOK, cloneDashboardRuntimeConfig(h.runtimeConfig))
+}
+
+// EffectiveConfig handles GET /admin/api/v1/config/effective
+//
+// @Summary      Get the effective configuration
+// @Description  Returns the configuration after merging the GOMODEL_PROFILE profile over the base config.yaml and applying env vars, keyed like config.yaml. API keys, secrets, passwords in URLs and provider extra headers are masked. Providers discovered only from env vars are listed by /admin/api/v1/providers/status instead.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  EffectiveConfigResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/config/effective [get]
+func (h *Handler) EffectiveConfig(c *echo.Context) error {
+	if h.effectiveConfig.Config == nil {
+		return handleError(c, featureUnavailableError("effective config is unavailable"))
+	}
+	return c.JSON(http.StatusOK, h.effectiveConfig)
+}
+
+// Scoreboard handles GET /admin/api/v1/scoreboard
+//
+// @Summary      Get rolling performance stats per provider and model
+// @Description  Request count, error rate, p50/p95 latency, stream TTFT and tokens/sec observed by this gateway instance.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        window  query     string  false  "Time window: 5m, 1h or 24h (default 5m)"
+// @Param        model   query     string  false  "Only include this model"
+// @Success      200  {object}  scoreboard.Snapshot
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/scoreboard [get]
+func (h *Handler) Scoreboard(c *echo.Context) error {
+	window, ok := scoreboard.ParseWindow(c.QueryParam("window"))
+	if !ok {
+		return handleError(c, core.NewInvalidRequestError("invalid window parameter: expected 5m, 1h or 24h", nil))
+	}
+
+	snapshot := h.scoreboard.Snapshot(window)
+	if model := strings.TrimSpace(c.QueryParam("model")); model != "" {
+		snapshot.Models = slices.DeleteFunc(snapshot.Models, func(stats scoreboard.ModelStats) bool {
+			return stats.Model != model
+		})
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// PromptCacheUsage handles GET /admin/api/v1/usage/prompt-cache
+//
+// @Summary      Get prompt cache hit rates and savings
+// @Description  Requests sent with a cacheable system prompt prefix, their provider cache hit rate, cached tokens and estimated savings per prefix, observed by this gateway instance since startup.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  promptcache.Report
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/usage/prompt-cache [get]
+func (h *Handler) PromptCacheUsage(c *echo.Context) error {
+	return c.JSON(http.StatusOK, h.promptCache.Report())
+}
+
+// ModelCacheFiles handles GET /admin/api/v1/cache
+//
+// @Summary      List local cache files
+// @Description  Files in the local model cache directory, oldest first, with their sizes, ages and schema versions, and the directory's size and age bounds. Answers 503 when the model cache is kept in Redis.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  modelcache.CacheDirReport
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/cache [get]
+func (h *Handler) ModelCacheFiles(c *echo.Context) error {
+	if h.modelCache == nil {
+		return handleError(c, modelCacheUnavailableError())
+	}
+	report, err := h.modelCache.Files(time.Now())
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// ModelCachePurgeResponse lists the files a purge removed.
+type ModelCachePurgeResponse struct {
+	Removed    []modelcache.RemovedCacheFile `json:"removed"`
+	FreedBytes int64                         `json:"freed_bytes"`
+}
+
+// PurgeModelCache handles DELETE /admin/api/v1/cache
+//
+// @Summary      Purge local cache files
+// @Description  Removes every file in the local model cache directory. The model registry keeps serving from memory and writes the cache again on its next refresh. Answers 503 when the model cache is kept in Redis.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ModelCachePurgeResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/cache [delete]
+func (h *Handler) PurgeModelCache(c *echo.Context) error {
+	if h.modelCache == nil {
+		return handleError(c, modelCacheUnavailableError())
+	}
+	removed, err := h.modelCache.Purge(time.Now())
+	if err != nil {
+		return handleError(c, err)
+	}
+	response := ModelCachePurgeResponse{Removed: removed}
+	for _, file := range removed {
+		response.FreedBytes += file.SizeBytes
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// ExperimentVariantResponse reports one experiment variant with its traffic
+// and usage split.
+type ExperimentVariantResponse struct {
+	Name         string   `json:"name"`
+	Model        string   `json:"model,omitempty"`
+	Provider     string   `json:"provider,omitempty"`
+	Control      bool     `json:"control"`
+	Weight       int      `json:"weight"`
+	WeightShare  float64  `json:"weight_share"`
+	Assignments  int64    `json:"assignments"`
+	Requests     int      `json:"requests"`
+	InputTokens  int64    `json:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens"`
+	TotalTokens  int64    `json:"total_tokens"`
+	TotalCost    *float64 `json:"total_cost" extensions:"x-nullable"`
+}
+
+// ExperimentResponse reports one configured A/B experiment.
+type ExperimentResponse struct {
+	Name     string                      `json:"name"`
+	Model    string                      `json:"model"`
+	Unit     string                      `json:"unit"`
+	Variants []ExperimentVariantResponse `json:"variants"`
+}
+
+// Experiments handles GET /admin/api/v1/experiments
+//
+// @Summary      List active A/B experiments
+// @Description  Configured experiments with per-variant weights, assignments made by this gateway instance since startup, and tracked usage for the requested period.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days        query     int     false  "Number of days (default 30)"
+// @Param        start_date  query     string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query     string  false  "End date (YYYY-MM-DD)"
+// @Param        tz          query     string  false  "IANA time zone for day boundaries (default UTC)"
+// @Param        user_path   query     string  false  "Filter by tracked user path subtree"
+// @Success      200  {array}   ExperimentResponse
+// @Failure      400  {object}  core.GatewayError
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/experiments [get]
+func (h *Handler) Experiments(c *echo.Context) error {
+	configured := h.experiments.Experiments()
+	if len(configured) == 0 {
+		return c.JSON(http.StatusOK, []ExperimentResponse{})
+	}
+
+	usageByVariant := make(map[[2]string]usage.ExperimentVariantUsage)
+	if h.usageReader != nil {
+		params, err := parseUsageParams(c, h.maxQueryDays)
+		if err != nil {
+			return handleError(c, err)
+		}
+		rows, err := h.usageReader.GetUsageByExperiment(c.Request().Context(), params)
+		if err != nil {
+			return handleError(c, err)
+		}
+		for _, row := range rows {
+			usageByVariant[[2]string{row.Experiment, row.Variant}] = row
+		}
+	}
+
+	result := make([]ExperimentResponse, 0, len(configured))
+	for _, experiment := range configured {
+		totalWeight := 0
+		for _, variant := range experiment.Variants {
+			totalWeight += variant.Weight
+		}
+		item := ExperimentResponse{
+			Name:     experiment.Name,
+			Model:    experiment.Model,
+			Unit:     string(experiment.Unit),
+			Variants: make([]ExperimentVariantResponse, 0, len(experiment.Variants)),
+		}
+		for _, variant := range experiment.Variants {
+			row := usageByVariant[[2]string{experiment.Name, variant.Name}]
+			item.Variants = append(item.Variants, ExperimentVariantResponse{
+				Name:         variant.Name,
+				Model:        variant.Model,
+				Provider:     variant.Provider,
+				Control:      variant.Control(),
+				Weight:       variant.Weight,
+				WeightShare:  float64(variant.Weight) / float64(totalWeight),
+				Assignments:  variant.Assignments(),
+				Requests:     row.Requests,
+				InputTokens:  row.InputTokens,
+				OutputTokens: row.OutputTokens,
+				TotalTokens:  row.TotalTokens,
+				TotalCost:    row.TotalCost,
+			})
+		}
+		result = append(result, item)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// ModelGroups handles GET /admin/api/v1/model-groups
+//
+// @Summary      List model groups and their current resolution
+// @Description  Configured model groups with the member requests for each group go to right now and why the members ahead of it are skipped.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   modelgroups.Resolution
+// @Failure      401  {object}  core.GatewayError
+// @Router       /admin/api/v1/model-groups [get]
+func (h *Handler) ModelGroups(c *echo.Context) error {
+	resolutions := h.modelGroups.Resolutions()
+	if resolutions == nil {
+		resolutions = []modelgroups.Resolution{}
+	}
+	return c.JSON(http.StatusOK, resolutions)
+}
+
+// DeferredQueueResponse reports the deferred request queue.
+type DeferredQueueResponse struct {
+	// Depth is the number of requests waiting for or undergoing replay.
+	Depth  int            `json:"depth"`
+	Counts map[string]int `json:"counts"`
+}
+
+// Deferred handles GET /admin/api/v1/deferred
+//
+// @Summary      Deferred request queue depth
+// @Description  Number of stored deferred requests per status. Depth counts queued and running requests.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  DeferredQueueResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/deferred [get]
+func (h *Handler) Deferred(c *echo.Context) error {
+	if h.deferred == nil {
+		return handleError(c, featureUnavailableError("deferred execution is unavailable"))
+	}
+
+	counts, err := h.deferred.Depth(c.Request().Context())
+	if err != nil {
+		return handleError(c, err)
+	}
+	resp := DeferredQueueResponse{Counts: make(map[string]int, len(deferred.Statuses))}
+	for _, status := range deferred.Statuses {
+		resp.Counts[string(status)] = counts[status]
+		if !status.Final() {
+			resp.Depth += counts[status]
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// usageJobRequest selects the usage rows of a bulk correction job.
+type usageJobRequest struct {
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date"`
+	Model         string `json:"model"`
+	Provider      string `json:"provider"`
+	FromAuthKeyID string `json:"from_auth_key_id"`
+	ToAuthKeyID   string `json:"to_auth_key_id"`
+	// DryRun counts the rows the job would process without submitting it.
+	DryRun bool `json:"dry_run"`
+}
+
+// UsageJobDryRunResponse reports the rows a bulk job would process.
+type UsageJobDryRunResponse struct {
+	DryRun       bool           `json:"dry_run"`
+	Kind         usagejobs.Kind `json:"kind"`
+	AffectedRows int64          `json:"affected_rows"`
+}
+
+// RecomputeUsageCosts handles POST /admin/api/v1/usage/recompute-costs
+//
+// @Summary      Recompute usage costs
+// @Description  Submits a background job that prices the usage rows of a date range again with the current model pricing, for example after a pricing fix. The rows can be narrowed by model and provider. Answers 202 with the job; poll GET /admin/api/v1/jobs/{id} for progress. With dry_run, answers 200 with the number of rows the job would process.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      usageJobRequest  true  "Rows to recompute"
+// @Success      202   {object}  usagejobs.Job
+// @Success      200   {object}  UsageJobDryRunResponse
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/usage/recompute-costs [post]
+func (h *Handler) RecomputeUsageCosts(c *echo.Context) error {
+	return h.submitUsageJob(c, usagejobs.KindRecomputeCosts)
+}
+
+// ReattributeUsage handles POST /admin/api/v1/usage/reattribute
+//
+// @Summary      Move usage to another API key
+// @Description  Submits a background job that moves the usage rows of from_auth_key_id to to_auth_key_id, optionally narrowed by date range, model and provider. Answers 202 with the job; poll GET /admin/api/v1/jobs/{id} for progress. With dry_run, answers 200 with the number of rows the job would move.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      usageJobRequest  true  "Rows to move"
+// @Success      202   {object}  usagejobs.Job
+// @Success      200   {object}  UsageJobDryRunResponse
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/usage/reattribute [post]
+func (h *Handler) ReattributeUsage(c *echo.Context) error {
+	return h.submitUsageJob(c, usagejobs.KindReattribute)
+}
+
+func (h *Handler) submitUsageJob(c *echo.Context, kind usagejobs.Kind) error {
+	if h.usageJobs == nil {
+		return handleError(c, featureUnavailableError("usage jobs are unavailable"))
+	}
+
+	var req usageJobRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	params := usagejobs.Params{
+		StartDate:     strings.TrimSpace(req.StartDate),
+		EndDate:       strings.TrimSpace(req.EndDate),
+		Model:         strings.TrimSpace(req.Model),
+		Provider:      strings.TrimSpace(req.Provider),
+		FromAuthKeyID: strings.TrimSpace(req.FromAuthKeyID),
+		ToAuthKeyID:   strings.TrimSpace(req.ToAuthKeyID),
+	}
+
+	ctx := c.Request().Context()
+	if req.DryRun {
+		count, err := h.usageJobs.DryRun(ctx, kind, params)
+		if err != nil {
+			return handleError(c, usageJobWriteError(err))
+		}
+		return c.JSON(http.StatusOK, UsageJobDryRunResponse{DryRun: true, Kind: kind, AffectedRows: count})
+	}
+
+	job, err := h.usageJobs.Submit(ctx, kind, params, auditActor(c))
+	if err != nil {
+		return handleError(c, usageJobWriteError(err))
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// UsageJob handles GET /admin/api/v1/jobs/{id}
+//
+// @Summary      Get a bulk usage job
+// @Description  Status and progress of a usage cost recompute or reattribution job.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  usagejobs.Job
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/jobs/{id} [get]
+func (h *Handler) UsageJob(c *echo.Context) error {
+	if h.usageJobs == nil {
+		return handleError(c, featureUnavailableError("usage jobs are unavailable"))
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	job, err := h.usageJobs.Get(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, usagejobs.ErrNotFound) {
+			return handleError(c, core.NewNotFoundError("usage job not found: "+id))
+		}
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// DeprecationsResponse lists the known model deprecations with their usage,
+// sunset soonest first.
+type DeprecationsResponse struct {
+	AfterSunset  string                `json:"after_sunset"`
+	Deprecations []deprecations.Report `json:"deprecations"`
+}
+
+// Deprecations handles GET /admin/api/v1/deprecations
+//
+// @Summary      Model deprecations
+// @Description  Deprecated models with their sunset date and replacement, whether their provider still lists them, and the requests sent for them since the gateway started: the last 24 hours, in total, and the auth keys sending the most. Answers 503 unless deprecations are enabled in config.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  DeprecationsResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/deprecations [get]
+func (h *Handler) Deprecations(c *echo.Context) error {
+	if h.deprecations == nil {
+		return handleError(c, deprecationsUnavailableError())
+	}
+	return c.JSON(http.StatusOK, DeprecationsResponse{
+		AfterSunset:  h.deprecations.AfterSunset(),
+		Deprecations: h.deprecations.Reports(),
+	})
+}
+
+// AnomaliesResponse lists detected usage anomalies, newest first.
+type AnomaliesResponse struct {
+	Anomalies []anomaly.Anomaly `json:"anomalies"`
+}
+
+// Anomalies handles GET /admin/api/v1/anomalies
+//
+// @Summary      Usage anomalies
+// @Description  Usage anomalies detected since the gateway started, newest first. Answers 503 unless anomaly detection is enabled in config.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        unacknowledged  query     bool  false  "Only anomalies not acknowledged yet"
+// @Success      200             {object}  AnomaliesResponse
+// @Failure      400             {object}  core.GatewayError
+// @Failure      401             {object}  core.GatewayError
+// @Failure      503             {object}  core.GatewayError
+// @Router       /admin/api/v1/anomalies [get]
+func (h *Handler) Anomalies(c *echo.Context) error {
+	if h.anomalies == nil {
+		return handleError(c, anomaliesUnavailableError())
+	}
+	unacknowledged := false
+	if raw := c.QueryParam("unacknowledged"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("unacknowledged must be true or false", err))
+		}
+		unacknowledged = parsed
+	}
+	return c.JSON(http.StatusOK, AnomaliesResponse{Anomalies: h.anomalies.List(unacknowledged)})
+}
+
+// AcknowledgeAnomaly handles POST /admin/api/v1/anomalies/{id}/acknowledge
+//
+// @Summary      Acknowledge a usage anomaly
+// @Description  Marks a usage anomaly as acknowledged. Acknowledging it again keeps the first acknowledgement time.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Anomaly ID"
+// @Success      200  {object}  anomaly.Anomaly
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/anomalies/{id}/acknowledge [post]
+func (h *Handler) AcknowledgeAnomaly(c *echo.Context) error {
+	if h.anomalies == nil {
+		return handleError(c, anomaliesUnavailableError())
+	}
+	acknowledged, ok := h.anomalies.Acknowledge(c.Param("id"))
+	if !ok {
+		return handleError(c, core.NewNotFoundError("anomaly not found: "+c.Param("id")))
+	}
+	return c.JSON(http.StatusOK, acknowledged)
+}
+
+// ChaosRulesResponse reports the fault injection state.
+type ChaosRulesResponse struct {
+	// Active is false while the kill switch is on.
+	Active bool         `json:"active"`
+	Rules  []chaos.Rule `json:"rules"`
+}
+
+type updateChaosRulesRequest struct {
+	// Active flips the kill switch when set.
+	Active *bool `json:"active,omitempty"`
+	// Rules replaces every rule when set.
+	Rules *[]chaos.Rule `json:"rules,omitempty"`
+}
+
+// ChaosRules handles GET /admin/api/v1/chaos/rules
+//
+// @Summary      Fault injection rules
+// @Description  Active fault injection rules and kill switch state. Answers 503 unless fault injection is enabled in config.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  ChaosRulesResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/chaos/rules [get]
+func (h *Handler) ChaosRules(c *echo.Context) error {
+	if h.chaos == nil {
+		return handleError(c, chaosUnavailableError())
+	}
+	return c.JSON(http.StatusOK, ChaosRulesResponse{Active: h.chaos.Active(), Rules: h.chaos.Rules()})
+}
+
+// UpdateChaosRules handles PUT /admin/api/v1/chaos/rules
+//
+// @Summary      Replace fault injection rules
+// @Description  Replaces the fault injection rules and/or flips the kill switch. Setting active to false stops every fault immediately, including in-flight streams.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      updateChaosRulesRequest  true  "Rules and kill switch"
+// @Success      200   {object}  ChaosRulesResponse
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/chaos/rules [put]
+func (h *Handler) UpdateChaosRules(c *echo.Context) error {
+	if h.chaos == nil {
+		return handleError(c, chaosUnavailableError())
+	}
+
+	var req updateChaosRulesRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	if req.Active == nil && req.Rules == nil {
+		return handleError(c, core.NewInvalidRequestError("active or rules is required", nil))
+	}
+	if req.Rules != nil {
+		if _, err := h.chaos.SetRules(*req.Rules); err != nil {
+			return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+		}
+	}
+	if req.Active != nil {
+		h.chaos.SetActive(*req.Active)
+	}
+
+	resp := ChaosRulesResponse{Active: h.chaos.Active(), Rules: h.chaos.Rules()}
+	slog.Warn("fault injection rules changed", "active", resp.Active, "rules", len(resp.Rules), "request_id", strings.TrimSpace(core.GetRequestID(c.Request().Context())))
+	return c.JSON(http.StatusOK, resp)
+}
+
+type verifyProvenanceRequest struct {
+	// Response is the response body as the client received it.
+	Response json.RawMessage `json:"response" swaggertype:"object"`
+	// Provenance is the claimed provenance. When omitted, the
+	// gomodel_provenance object embedded in Response is verified.
+	Provenance *provenance.Provenance `json:"provenance,omitempty"`
+}
+
+// VerifyProvenance handles POST /admin/api/v1/provenance/verify
+//
+// @Summary      Verify response provenance
+// @Description  Checks that a response body matches its signed provenance: the content hash, request ID and model must all match the HMAC signature. Answers 503 unless provenance embedding is configured with a secret.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      verifyProvenanceRequest  true  "Response body and claimed provenance"
+// @Success      200   {object}  provenance.Result
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/provenance/verify [post]
+func (h *Handler) VerifyProvenance(c *echo.Context) error {
+	if !h.provenance.CanVerify() {
+		return handleError(c, featureUnavailableError("provenance verification is unavailable; set provenance.enabled and provenance.secret or PROVENANCE_ENABLED=true and PROVENANCE_SECRET to enable it"))
+	}
+
+	var req verifyProvenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	if len(bytes.TrimSpace(req.Response)) == 0 || bytes.Equal(bytes.TrimSpace(req.Response), []byte("null")) {
+		return handleError(c, core.NewInvalidRequestError("response is required", nil))
+	}
+	return c.JSON(http.StatusOK, h.provenance.Verify(req.Response, req.Provenance))
+}
+
+// ResponseIDMapping handles GET /admin/api/v1/response-ids/{id}
+//
+// @Summary      Resolve a gateway-issued response ID
+// @Description  Returns the provider response ID, provider, model and gateway request ID behind a response ID issued by response sanitization. Only the most recent response_sanitization.max_mappings IDs are remembered. Answers 503 unless response sanitization is enabled.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Gateway-issued response ID"
+// @Success      200  {object}  sanitize.Mapping
+// @Failure      401  {object}  core.GatewayError
+// @Failure      404  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/response-ids/{id} [get]
+func (h *Handler) ResponseIDMapping(c *echo.Context) error {
+	if h.sanitizer == nil {
+		return handleError(c, featureUnavailableError("response ID lookup is unavailable; set response_sanitization.enabled or RESPONSE_SANITIZATION_ENABLED=true to enable it"))
+	}
+
+	id := strings.TrimSpace(c.Param("id"))
+	if id == "" {
+		return handleError(c, core.NewInvalidRequestError("response id is required", nil))
+	}
+	mapping, ok := h.sanitizer.Lookup(id)
+	if !ok {
+		return handleError(c, core.NewNotFoundError("response id not found: "+id))
+	}
+	return c.JSON(http.StatusOK, mapping)
+}
+
+// maintenanceDrainTimeout bounds how long enabling maintenance with drain
+// waits for in-flight requests, below the admin API write timeout.
+const maintenanceDrainTimeout = 25 * time.Second
+
+type updateMaintenanceRequest struct {
+	// Enabled turns maintenance mode on or off.
+	Enabled *bool `json:"enabled"`
+	// Message is returned to rejected clients.
+	Message string `json:"message,omitempty"`
+	// DurationSeconds ends maintenance by itself after this many seconds.
+	// Zero keeps it on until it is disabled.
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// Drain waits for in-flight model requests to finish before answering.
+	Drain bool `json:"drain,omitempty"`
+}
+
+// MaintenanceResponse is the maintenance mode state. Drained is set when the
+// request asked to drain: false means requests were still running when the
+// wait timed out.
+type MaintenanceResponse struct {
+	maintenance.State
+	Drained *bool `json:"drained,omitempty"`
+}
+
+// Maintenance handles GET /admin/api/v1/maintenance
+//
+// @Summary      Get maintenance mode
+// @Description  Returns whether maintenance mode rejects model requests, its message and expected end, and the number of model requests in flight.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  MaintenanceResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/maintenance [get]
+func (h *Handler) Maintenance(c *echo.Context) error {
+	if h.maintenance == nil {
+		return handleError(c, maintenanceUnavailableError())
+	}
+	return c.JSON(http.StatusOK, MaintenanceResponse{State: h.maintenance.State()})
+}
+
+// UpdateMaintenance handles PUT /admin/api/v1/maintenance
+//
+// @Summary      Switch maintenance mode
+// @Description  Turns maintenance mode on or off. While it is on, model endpoints answer 503 with a Retry-After header and the message; requests already running finish normally. With duration_seconds it ends by itself. With drain, the call waits up to 25s for in-flight model requests before answering.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      updateMaintenanceRequest  true  "Maintenance mode switch"
+// @Success      200   {object}  MaintenanceResponse
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/maintenance [put]
+func (h *Handler) UpdateMaintenance(c *echo.Context) error {
+	if h.maintenance == nil {
+		return handleError(c, maintenanceUnavailableError())
+	}
+
+	var req updateMaintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	if req.Enabled == nil {
+		return handleError(c, core.NewInvalidRequestError("enabled is required", nil))
+	}
+	if req.DurationSeconds < 0 {
+		return handleError(c, core.NewInvalidRequestError("duration_seconds must not be negative", nil))
+	}
+
+	var resp MaintenanceResponse
+	if *req.Enabled {
+		resp.State = h.maintenance.Enable(req.Message, time.Duration(req.DurationSeconds)*time.Second)
+	} else {
+		resp.State = h.maintenance.Disable()
+	}
+	auditlog.EnrichEntryWithMaintenance(c, auditlog.MaintenanceSnapshot{
+		Active:  resp.Active,
+		Message: resp.Message,
+		Until:   resp.Until,
+		Drain:   req.Drain,
+	})
+	slog.Warn("maintenance mode changed",
+		"active", resp.Active,
+		"message", resp.Message,
+		"until", resp.Until,
+		"auth_key_id", strings.TrimSpace(core.GetAuthKeyID(c.Request().Context())),
+		"request_id", strings.TrimSpace(core.GetRequestID(c.Request().Context())),
+	)
+
+	if req.Drain && resp.Active {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), maintenanceDrainTimeout)
+		drained := h.maintenance.Drain(ctx) == nil
+		cancel()
+		resp.State = h.maintenance.State()
+		resp.Drained = &drained
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+type startInspectionRequest struct {
+	// Model, Provider and Path narrow the capture; empty matches everything.
+	// Model matches the requested or resolved model, Provider the provider
+	// name or type, and Path is a prefix of the request path.
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Path     string `json:"path,omitempty"`
+	// DurationSeconds ends the capture; default 300, at most 3600.
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// MaxSamples ends the capture once reached; default 50, at most 1000.
+	MaxSamples int `json:"max_samples,omitempty"`
+	// PreviewChars is the length of each preview; default 500, at most 4000.
+	PreviewChars int `json:"preview_chars,omitempty"`
+}
+
+// InspectionSamplesResponse holds the state of the current or last
+// inspection and its samples, newest first.
+type InspectionSamplesResponse struct {
+	State   inspect.State    `json:"state"`
+	Samples []inspect.Sample `json:"samples"`
+}
+
+// Inspection handles GET /admin/api/v1/inspect
+//
+// @Summary      Get live request inspection
+// @Description  Returns the state of the current or last live request inspection: its filter, when it started and expires, who started it, and how many samples it captured.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  inspect.State
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/inspect [get]
+func (h *Handler) Inspection(c *echo.Context) error {
+	if h.inspector == nil {
+		return handleError(c, inspectorUnavailableError())
+	}
+	return c.JSON(http.StatusOK, h.inspector.State())
+}
+
+// StartInspection handles POST /admin/api/v1/inspect/start
+//
+// @Summary      Start live request inspection
+// @Description  Captures redacted previews of matching model requests and responses into memory until the duration or the sample count runs out. Previews hold the first preview_chars characters of the content with secrets masked, whatever the audit log body settings, and are never stored. Starting replaces any running inspection and discards its samples.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      startInspectionRequest  true  "Filter and limits"
+// @Success      200   {object}  inspect.State
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/inspect/start [post]
+func (h *Handler) StartInspection(c *echo.Context) error {
+	if h.inspector == nil {
+		return handleError(c, inspectorUnavailableError())
+	}
+
+	var req startInspectionRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	if req.DurationSeconds < 0 || req.MaxSamples < 0 || req.PreviewChars < 0 {
+		return handleError(c, core.NewInvalidRequestError("duration_seconds, max_samples and preview_chars must not be negative", nil))
+	}
+	ctx := c.Request().Context()
+	state, err := h.inspector.Start(inspect.Options{
+		Filter:       inspect.Filter{Model: req.Model, Provider: req.Provider, Path: req.Path},
+		Duration:     time.Duration(req.DurationSeconds) * time.Second,
+		MaxSamples:   req.MaxSamples,
+		PreviewChars: req.PreviewChars,
+		StartedBy:    core.GetAuthKeyID(ctx),
+	})
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+	}
+
+	auditlog.EnrichEntryWithInspection(c, auditlog.InspectionSnapshot{
+		Action:       "start",
+		Model:        state.Filter.Model,
+		Provider:     state.Filter.Provider,
+		Path:         state.Filter.Path,
+		MaxSamples:   state.MaxSamples,
+		PreviewChars: state.PreviewChars,
+		ExpiresAt:    state.ExpiresAt,
+	})
+	slog.Warn("live request inspection started",
+		"model", state.Filter.Model,
+		"provider", state.Filter.Provider,
+		"path", state.Filter.Path,
+		"max_samples", state.MaxSamples,
+		"expires_at", state.ExpiresAt,
+		"auth_key_id", strings.TrimSpace(core.GetAuthKeyID(ctx)),
+		"request_id", strings.TrimSpace(core.GetRequestID(ctx)),
+	)
+	return c.JSON(http.StatusOK, state)
+}
+
+// StopInspection handles POST /admin/api/v1/inspect/stop
+//
+// @Summary      Stop live request inspection
+// @Description  Ends the running inspection, if any, and discards every captured sample.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  inspect.State
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/inspect/stop [post]
+func (h *Handler) StopInspection(c *echo.Context) error {
+	if h.inspector == nil {
+		return handleError(c, inspectorUnavailableError())
+	}
+	state := h.inspector.Stop()
+	auditlog.EnrichEntryWithInspection(c, auditlog.InspectionSnapshot{Action: "stop"})
+	ctx := c.Request().Context()
+	slog.Warn("live request inspection stopped",
+		"captured", state.Captured,
+		"auth_key_id", strings.TrimSpace(core.GetAuthKeyID(ctx)),
+		"request_id", strings.TrimSpace(core.GetRequestID(ctx)),
+	)
+	return c.JSON(http.StatusOK, state)
+}
+
+// InspectionSamples handles GET /admin/api/v1/inspect/samples
+//
+// @Summary      Live request inspection samples
+// @Description  Returns the redacted request and response previews captured by the current or last inspection, newest first. Samples stay available after the inspection ends until the next one starts or it is stopped.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  InspectionSamplesResponse
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/inspect/samples [get]
+func (h *Handler) InspectionSamples(c *echo.Context) error {
+	if h.inspector == nil {
+		return handleError(c, inspectorUnavailableError())
+	}
+	return c.JSON(http.StatusOK, InspectionSamplesResponse{
+		State:   h.inspector.State(),
+		Samples: h.inspector.Samples(),
+	})
+}
+
+func inspectorUnavailableError() error {
+	return featureUnavailableError("live request inspection is unavailable")
+}
+
+func deprecationsUnavailableError() error {
+	return featureUnavailableError("deprecation tracking is unavailable; set deprecations.enabled or DEPRECATIONS_ENABLED=true to enable it")
+}
+
+func maintenanceUnavailableError() error {
+	return featureUnavailableError("maintenance mode is unavailable")
+}
+
+func modelCacheUnavailableError() error {
+	return featureUnavailableError("the local model cache is unavailable; the model cache is kept in redis")
+}
+
+func anomaliesUnavailableError() error {
+	return featureUnavailableError("anomaly detection is unavailable; set anomaly_detection.enabled or ANOMALY_DETECTION_ENABLED=true with usage tracking on to enable it")
+}
+
+func chaosUnavailableError() error {
+	return featureUnavailableError("fault injection is unavailable; set chaos.enabled or CHAOS_ENABLED=true to enable it")
+}
+
+// capabilityProbeTimeout bounds a capability probe run, below the admin API
+// write timeout. Checks still running when it passes are inconclusive.
+const capabilityProbeTimeout = 25 * time.Second
+
+type probeProviderRequest struct {
+	// Model is the chat model the checks use.
+	Model string `json:"model"`
+	// EmbeddingModel is the model of the embeddings check; empty uses model.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	// Checks limits the battery; empty runs every check.
+	Checks []string `json:"checks,omitempty"`
+	// TokenBudget lowers the configured token budget for this run.
+	TokenBudget int `json:"token_budget,omitempty"`
+	// Apply makes routing reject requests that need a capability the probe
+	// found unsupported, until the next probe replaces the profile.
+	Apply bool `json:"apply,omitempty"`
+}
+
+// ProbeProvider handles POST /admin/api/v1/providers/{name}/probe
+//
+// @Summary      Probe provider capabilities
+// @Description  Sends a battery of tiny requests (1-token chat, streaming chat, chat with a trivial tool, JSON-mode chat, Responses API, one-string embedding) to the provider and stores the resulting capability profile on its status. Usage entries are labeled gomodel_internal=capability_probe and the run is capped by the configured token budget. With apply, routing rejects requests needing a capability found unsupported.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string                true  "Configured provider name"
+// @Param        body  body      probeProviderRequest  true  "Probe settings"
+// @Success      200   {object}  core.CapabilityProfile
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      404   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/providers/{name}/probe [post]
+func (h *Handler) ProbeProvider(c *echo.Context) error {
+	if h.registry == nil {
+		return handleError(c, featureUnavailableError("provider registry is unavailable"))
+	}
+	if h.prober == nil {
+		return handleError(c, featureUnavailableError("capability probing is unavailable"))
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return handleError(c, core.NewInvalidRequestError("provider name is required", nil))
+	}
+	var req probeProviderRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	if strings.TrimSpace(req.Model) == "" {
+		return handleError(c, core.NewInvalidRequestError("model is required", nil))
+	}
+	if req.TokenBudget < 0 {
+		return handleError(c, core.NewInvalidRequestError("token_budget must not be negative", nil))
+	}
+	if err := probe.ValidateChecks(req.Checks); err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+	}
+	provider := h.registry.ProviderByName(name)
+	if provider == nil {
+		return handleError(c, core.NewNotFoundError("provider not found: "+name))
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), capabilityProbeTimeout)
+	defer cancel()
+	requestID := strings.TrimSpace(core.GetRequestID(c.Request().Context()))
+	profile, err := h.prober.Run(ctx, provider, probe.Options{
+		Model:          req.Model,
+		EmbeddingModel: req.EmbeddingModel,
+		Checks:         req.Checks,
+		TokenBudget:    req.TokenBudget,
+		ProviderName:   name,
+		ProviderType:   strings.TrimSpace(h.registry.GetProviderTypeForName(name)),
+		RequestID:      requestID,
+	})
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+	}
+	profile.Applied = req.Apply
+	h.registry.RecordCapabilityProfile(name, profile)
+
+	snapshot := auditlog.CapabilityProbeSnapshot{
+		Provider:   name,
+		Model:      profile.Model,
+		TokensUsed: profile.TokensUsed,
+		Applied:    profile.Applied,
+	}
+	for _, check := range profile.Checks {
+		switch check.Status {
+		case core.CapabilitySupported:
+			snapshot.Supported = append(snapshot.Supported, check.Capability)
+		case core.CapabilityUnsupported:
+			snapshot.Unsupported = append(snapshot.Unsupported, check.Capability)
+		}
+	}
+	auditlog.EnrichEntryWithCapabilityProbe(c, snapshot)
+	slog.Info("provider capability probe finished",
+		"provider", name,
+		"model", profile.Model,
+		"supported", snapshot.Supported,
+		"unsupported", snapshot.Unsupported,
+		"tokens_used", profile.TokensUsed,
+		"applied", profile.Applied,
+		"request_id", requestID,
+	)
+	return c.JSON(http.StatusOK, profile)
+}
+
+// ProviderStatus handles GET /admin/api/v1/providers/status
+func (h *Handler) ProviderStatus(c *echo.Context) error {
+	return c.JSON(http.StatusOK, h.buildProviderStatusResponse())
+}
+
+// ProviderQuota handles GET /admin/api/v1/providers/{name}/quota
+//
+// It returns the account limits the provider last reported in rate-limit
+// response headers. With refresh=true, providers that can query their limits
+// are asked first; a failed fetch still returns the previously observed
+// limits.
+func (h *Handler) ProviderQuota(c *echo.Context) error {
+	if h.registry == nil {
+		return handleError(c, featureUnavailableError("provider registry is unavailable"))
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return handleError(c, core.NewInvalidRequestError("provider name is required", nil))
+	}
+	refresh := false
+	if raw := c.QueryParam("refresh"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("invalid refresh value, expected true or false", nil))
+		}
+		refresh = parsed
+	}
+
+	provider := h.registry.ProviderByName(name)
+	if provider == nil {
+		return handleError(c, core.NewNotFoundError("provider not found: "+name))
+	}
+
+	resp := providerQuotaResponse{
+		Name:   name,
+		Type:   strings.TrimSpace(h.registry.GetProviderTypeForName(name)),
+		Status: ProviderQuotaStatusUnknown,
+	}
+	if refresh {
+		resp.Fetch = h.fetchProviderQuota(c.Request().Context(), name, provider)
+	}
+	for _, snapshot := range h.registry.ProviderRuntimeSnapshots() {
+		if snapshot.Name == name && snapshot.RateLimits != nil && !snapshot.RateLimits.IsEmpty() {
+			resp.RateLimits = snapshot.RateLimits
+			resp.Status = ProviderQuotaStatusKnown
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) fetchProviderQuota(ctx context.Context, name string, provider core.Provider) *providerQuotaFetchResponse {
+	fetcher, ok := provider.(core.RateLimitFetcher)
+	if !ok {
+		return &providerQuotaFetchResponse{Status: ProviderQuotaFetchUnsupported}
+	}
+	limits, err := fetcher.FetchRateLimits(ctx)
+	if err != nil {
+		slog.Warn("provider quota fetch failed", "provider", name, "error", err)
+		return &providerQuotaFetchResponse{Status: ProviderQuotaFetchFailed, Error: err.Error()}
+	}
+	h.registry.RecordRateLimits(name, limits)
+	return &providerQuotaFetchResponse{Status: ProviderQuotaFetchOK}
+}
+
+// RefreshRuntime handles POST /admin/api/v1/runtime/refresh
+func (h *Handler) RefreshRuntime(c *echo.Context) error {
+	if h.runtimeRefresher == nil {
+		return handleError(c, featureUnavailableError("runtime refresh is unavailable"))
+	}
+
+	report, err := h.runtimeRefresher.RefreshRuntime(c.Request().Context())
+	if err != nil {
+		if gatewayErr, ok := errors.AsType[*core.GatewayError](err); ok {
+			return handleError(c, gatewayErr)
+		}
+		return handleError(c, core.NewProviderError("runtime_refresh", http.StatusInternalServerError, "runtime refresh failed", err))
+	}
+	if report.Status == "" {
+		report.Status = RuntimeRefreshStatusOK
+	}
+	if report.Steps == nil {
+		report.Steps = []RuntimeRefreshStep{}
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// Diagnostics statuses.
+const (
+	DiagnosticsStatusOK       = "ok"
+	DiagnosticsStatusDegraded = "degraded"
+)
+
+// DiagnosticsResponse aggregates process, registry and write pipeline health.
+type DiagnosticsResponse struct {
+	Status string `json:"status"`
+	// Issues explains a degraded status, one line per problem.
+	Issues    []string             `json:"issues"`
+	Runtime   RuntimeDiagnostics   `json:"runtime"`
+	Registry  *RegistryDiagnostics `json:"registry,omitempty"`
+	Pipelines PipelineDiagnostics  `json:"pipelines"`
+	// AuditLookupCache is the audit log by-ID lookup cache; nil when it is
+	// disabled.
+	AuditLookupCache *auditlog.LookupCacheStats `json:"audit_lookup_cache,omitempty"`
+	// Memory holds the size of every in-memory store and the process memory
+	// ceiling; nil when the memory governor is not configured.
+	Memory *memgovernor.Snapshot `json:"memory,omitempty"`
+}
+
+// RuntimeDiagnostics holds Go runtime figures for the gateway process.
+type RuntimeDiagnostics struct {
+	Goroutines     int        `json:"goroutines"`
+	HeapAllocBytes uint64     `json:"heap_alloc_bytes"`
+	HeapInuseBytes uint64     `json:"heap_inuse_bytes"`
+	SysBytes       uint64     `json:"sys_bytes"`
+	NumGC          uint32     `json:"num_gc"`
+	LastGCAt       *time.Time `json:"last_gc_at,omitempty"`
+}
 
-	return c.JSON(http.StatusOK, response)
+// RegistryDiagnostics summarizes model registry refresh health.
+type RegistryDiagnostics struct {
+	Initialized      bool                        `json:"initialized"`
+	ModelCount       int                         `json:"model_count"`
+	ProviderCount    int                         `json:"provider_count"`
+	FailingProviders []RegistryProviderDiagnosis `json:"failing_providers"`
 }
 
-// isValidCategory returns true if cat is a recognized model category.
-func isValidCategory(cat core.ModelCategory) bool {
-	return slices.Contains(core.AllCategories(), cat)
+// RegistryProviderDiagnosis describes a provider whose last model fetch failed.
+type RegistryProviderDiagnosis struct {
+	Name                    string     `json:"name"`
+	LastModelFetchError     string     `json:"last_model_fetch_error"`
+	LastModelFetchAt        *time.Time `json:"last_model_fetch_at,omitempty"`
+	LastModelFetchSuccessAt *time.Time `json:"last_model_fetch_success_at,omitempty"`
 }
 
-// ListCategories handles GET /admin/api/v1/models/categories
+// auditLookupCache is implemented by audit readers that cache by-ID lookups.
+type auditLookupCache interface {
+	LookupCacheStats() auditlog.LookupCacheStats
+}
+
+// PipelineDiagnostics holds the audit log and usage write pipelines. A nil
+// pipeline is disabled.
+type PipelineDiagnostics struct {
+	AuditLog *pipelinestats.Snapshot `json:"audit_log"`
+	Usage    *pipelinestats.Snapshot `json:"usage"`
+}
+
+// Diagnostics handles GET /admin/api/v1/diagnostics
 //
-// @Summary      List model categories with counts
+// @Summary      Get gateway diagnostics
+// @Description  Goroutine count, memory stats, model registry refresh health and the audit log and usage write pipelines (buffer depth, oldest unwritten entry age, last flush and drops), the hits and misses of the audit log lookup cache, and the estimated size and evictions of every in-memory store under the memory budget. Status is degraded while a pipeline lags behind its warning threshold or its last flush failed, while the registry is uninitialized or a provider's model fetch fails, or while process memory is near the configured ceiling.
 // @Tags         admin
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {array}   providers.CategoryCount
+// @Success      200  {object}  DiagnosticsResponse
 // @Failure      401  {object}  core.GatewayError
-// @Router       /admin/api/v1/models/categories [get]
-func (h *Handler) ListCategories(c *echo.Context) error {
-	if h.registry == nil {
-		return c.JSON(http.StatusOK, []providers.CategoryCount{})
+// @Router       /admin/api/v1/diagnostics [get]
+func (h *Handler) Diagnostics(c *echo.Context) error {
+	resp := DiagnosticsResponse{
+		Status:  DiagnosticsStatusOK,
+		Issues:  []string{},
+		Runtime: runtimeDiagnostics(),
+	}
+
+	if h.registry != nil {
+		registry := h.registryDiagnostics()
+		if !registry.Initialized {
+			resp.Issues = append(resp.Issues, "model registry is not initialized")
+		}
+		for _, provider := range registry.FailingProviders {
+			resp.Issues = append(resp.Issues, fmt.Sprintf("provider %s model fetch failed: %s", provider.Name, provider.LastModelFetchError))
+		}
+		resp.Registry = &registry
+	}
+
+	resp.Pipelines.AuditLog = pipelineDiagnostics(h.auditPipeline, "audit log", &resp.Issues)
+	resp.Pipelines.Usage = pipelineDiagnostics(h.usagePipeline, "usage", &resp.Issues)
+	if cache, ok := h.auditReader.(auditLookupCache); ok {
+		stats := cache.LookupCacheStats()
+		resp.AuditLookupCache = &stats
+	}
+	if h.memory != nil {
+		memory := h.memory.Snapshot()
+		if memory.NearRSSCeiling {
+			resp.Issues = append(resp.Issues, fmt.Sprintf("process memory %d bytes is near the %d byte ceiling", memory.ProcessBytes, memory.RSSCeilingBytes))
+		}
+		resp.Memory = &memory
 	}
 
-	return c.JSON(http.StatusOK, h.registry.GetCategoryCounts())
+	if len(resp.Issues) > 0 {
+		resp.Status = DiagnosticsStatusDegraded
+	}
+	return c.JSON(http.StatusOK, resp)
 }
 
-// DashboardConfig handles GET /admin/api/v1/dashboard/config
-func (h *Handler) DashboardConfig(c *echo.Context) error {
-	return c.JSON(http.StatusOK, cloneDashboardRuntimeConfig(h.runtimeConfig))
+func runtimeDiagnostics() RuntimeDiagnostics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	diag := RuntimeDiagnostics{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapInuseBytes: mem.HeapInuse,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		diag.LastGCAt = &lastGC
+	}
+	return diag
 }
 
-// ProviderStatus handles GET /admin/api/v1/providers/status
-func (h *Handler) ProviderStatus(c *echo.Context) error {
-	return c.JSON(http.StatusOK, h.buildProviderStatusResponse())
+func (h *Handler) registryDiagnostics() RegistryDiagnostics {
+	snapshots := h.registry.ProviderRuntimeSnapshots()
+	diag := RegistryDiagnostics{
+		Initialized:      h.registry.IsInitialized(),
+		ModelCount:       h.registry.ModelCount(),
+		ProviderCount:    len(snapshots),
+		FailingProviders: []RegistryProviderDiagnosis{},
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.LastModelFetchError == "" {
+			continue
+		}
+		diag.FailingProviders = append(diag.FailingProviders, RegistryProviderDiagnosis{
+			Name:                    snapshot.Name,
+			LastModelFetchError:     snapshot.LastModelFetchError,
+			LastModelFetchAt:        snapshot.LastModelFetchAt,
+			LastModelFetchSuccessAt: snapshot.LastModelFetchSuccessAt,
+		})
+	}
+	return diag
 }
 
-// RefreshRuntime handles POST /admin/api/v1/runtime/refresh
-func (h *Handler) RefreshRuntime(c *echo.Context) error {
-	if h.runtimeRefresher == nil {
-		return handleError(c, featureUnavailableError("runtime refresh is unavailable"))
+// pipelineDiagnostics snapshots source and appends its problems to issues.
+func pipelineDiagnostics(source PipelineStatsSource, name string, issues *[]string) *pipelinestats.Snapshot {
+	if source == nil {
+		return nil
+	}
+	stats := source.PipelineStats()
+	if stats.Lagging {
+		*issues = append(*issues, fmt.Sprintf("%s write lag %s exceeds %s",
+			name, stats.OldestPending().Round(time.Millisecond), time.Duration(stats.LagThresholdSeconds*float64(time.Second))))
 	}
+	if stats.LastFlushErrorActive {
+		*issues = append(*issues, fmt.Sprintf("%s last flush failed: %s", name, stats.LastFlushError))
+	}
+	return &stats
+}
 
-	report, err := h.runtimeRefresher.RefreshRuntime(c.Request().Context())
-	if err != nil {
-		if gatewayErr, ok := errors.AsType[*core.GatewayError](err); ok {
-			return handleError(c, gatewayErr)
-		}
-		return handleError(c, core.NewProviderError("runtime_refresh", http.StatusInternalServerError, "runtime refresh failed", err))
+// SetLogLevel handles PUT /admin/api/v1/logging/level
+//
+// @Summary      Change log level at runtime
+// @Description  Sets the global log level, or a single component's level when component is set.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      setLogLevelRequest  true  "Log level change"
+// @Success      200   {object}  logging.LevelsSnapshot
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Router       /admin/api/v1/logging/level [put]
+func (h *Handler) SetLogLevel(c *echo.Context) error {
+	var req setLogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
 	}
-	if report.Status == "" {
-		report.Status = RuntimeRefreshStatusOK
+	if strings.TrimSpace(req.Level) == "" {
+		return handleError(c, core.NewInvalidRequestError("level is required", nil))
 	}
-	if report.Steps == nil {
-		report.Steps = []RuntimeRefreshStep{}
+
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
 	}
-	return c.JSON(http.StatusOK, report)
+	if err := logging.SetLevel(req.Component, level); err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+	}
+
+	snapshot := logging.Levels()
+	slog.Info("log level changed", "level", req.Level, "component", req.Component, "request_id", strings.TrimSpace(core.GetRequestID(c.Request().Context())))
+	return c.JSON(http.StatusOK, snapshot)
 }
 
 func (h *Handler) buildProviderStatusResponse() providerStatusResponse {
@@ -974,17 +3460,24 @@ func (h *Handler) buildProviderStatusResponse() providerStatusResponse {
 			resp.Summary.Healthy++
 		case "unhealthy":
 			resp.Summary.Unhealthy++
+		case "key_invalid":
+			resp.Summary.Unhealthy++
+			resp.Summary.KeyInvalid++
 		default:
 			resp.Summary.Degraded++
 		}
+		if runtime.Initialization == providers.ProviderInitUninitialized {
+			resp.Summary.Uninitialized++
+		}
 	}
 
+	active := resp.Summary.Total - resp.Summary.Uninitialized
 	switch {
-	case resp.Summary.Total == 0:
+	case active == 0:
 		resp.Summary.OverallStatus = "degraded"
-	case resp.Summary.Healthy == resp.Summary.Total:
+	case resp.Summary.Healthy == active:
 		resp.Summary.OverallStatus = "healthy"
-	case resp.Summary.Unhealthy == resp.Summary.Total:
+	case resp.Summary.Unhealthy == active:
 		resp.Summary.OverallStatus = "unhealthy"
 	default:
 		resp.Summary.OverallStatus = "degraded"
@@ -993,6 +3486,9 @@ func (h *Handler) buildProviderStatusResponse() providerStatusResponse {
 	if resp.Providers == nil {
 		resp.Providers = []providerStatusItemResponse{}
 	}
+	if h.registry != nil {
+		resp.ModelsVersion = h.registry.ModelsVersion()
+	}
 	return resp
 }
 
@@ -1011,6 +3507,13 @@ func classifyProviderStatus(cfg providers.SanitizedProviderConfig, runtime provi
 	}
 
 	switch {
+	case runtime.KeyHealth != nil && runtime.KeyHealth.Status == core.KeyHealthInvalid:
+		reason = fmt.Sprintf("the provider rejected the API key on %d consecutive key health checks", runtime.KeyHealth.ConsecutiveAuthFailures)
+		return "key_invalid", "Key invalid", reason, strings.TrimSpace(runtime.KeyHealth.LastError)
+	case runtime.Initialization == providers.ProviderInitFailed:
+		return "unhealthy", "Init failed", "lazy provider construction failed; the next routed request retries it", strings.TrimSpace(runtime.InitError)
+	case runtime.Initialization == providers.ProviderInitUninitialized:
+		return "degraded", "Uninitialized", "lazy provider is constructed on its first routed request or admin refresh", lastError
 	case runtime.DiscoveredModelCount > 0 && modelFetchError == "":
 		if usingCachedModels {
 			return "degraded", "Starting", "serving cached model inventory while live refresh finishes", lastError
@@ -1040,6 +3543,17 @@ type upsertAliasRequest struct {
 	Enabled        *bool  `json:"enabled,omitempty"`
 }
 
+type createTemplateVersionRequest struct {
+	Description string                     `json:"description,omitempty"`
+	Messages    []prompttemplates.Message  `json:"messages"`
+	Variables   []prompttemplates.Variable `json:"variables,omitempty"`
+}
+
+type setLogLevelRequest struct {
+	Level     string `json:"level"`
+	Component string `json:"component,omitempty"`
+}
+
 type upsertModelOverrideRequest struct {
 	UserPaths []string `json:"user_paths,omitempty"`
 }
@@ -1062,10 +3576,12 @@ type createWorkflowRequest struct {
 }
 
 type createAuthKeyRequest struct {
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	UserPath    string     `json:"user_path,omitempty"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	UserPath      string     `json:"user_path,omitempty"`
+	Role          string     `json:"role,omitempty"`
+	DataResidency string     `json:"data_residency,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
 }
 
 func featureUnavailableError(message string) error {
@@ -1077,6 +3593,10 @@ func (h *Handler) aliasesUnavailableError() error {
 	return featureUnavailableError("aliases feature is unavailable")
 }
 
+func (h *Handler) templatesUnavailableError() error {
+	return featureUnavailableError("prompt templates feature is unavailable")
+}
+
 func (h *Handler) modelOverridesUnavailableError() error {
 	return featureUnavailableError("model overrides feature is unavailable")
 }
@@ -1093,6 +3613,10 @@ func (h *Handler) workflowsUnavailableError() error {
 	return featureUnavailableError("workflows feature is unavailable")
 }
 
+func (h *Handler) auditLogUnavailableError() error {
+	return featureUnavailableError("audit log storage is unavailable")
+}
+
 func aliasWriteError(err error) error {
 	if err == nil {
 		return nil
@@ -1103,6 +3627,16 @@ func aliasWriteError(err error) error {
 	return err
 }
 
+func templateWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if prompttemplates.IsValidationError(err) {
+		return core.NewInvalidRequestError(err.Error(), err)
+	}
+	return err
+}
+
 func modelOverrideWriteError(err error) error {
 	if err == nil {
 		return nil
@@ -1133,6 +3667,16 @@ func authKeyWriteError(err error) error {
 	return err
 }
 
+func usageJobWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if usagejobs.IsValidationError(err) {
+		return core.NewInvalidRequestError(err.Error(), err)
+	}
+	return err
+}
+
 func guardrailWriteError(err error) error {
 	if err == nil {
 		return nil
@@ -1291,10 +3835,12 @@ func (h *Handler) CreateAuthKey(c *echo.Context) error {
 	}
 
 	issued, err := h.authKeys.Create(c.Request().Context(), authkeys.CreateInput{
-		Name:        req.Name,
-		Description: req.Description,
-		UserPath:    userPath,
-		ExpiresAt:   req.ExpiresAt,
+		Name:          req.Name,
+		Description:   req.Description,
+		UserPath:      userPath,
+		Role:          authkeys.Role(req.Role),
+		DataResidency: req.DataResidency,
+		ExpiresAt:     req.ExpiresAt,
 	})
 	if err != nil {
 		return handleError(c, authKeyWriteError(err))
@@ -1397,6 +3943,187 @@ func (h *Handler) DeleteAlias(c *echo.Context) error {
 	)
 }
 
+// ListTemplates handles GET /admin/api/v1/templates
+//
+// @Summary      List prompt templates
+// @Description  Returns the latest version of every prompt template with the list of its stored versions.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   prompttemplates.View
+// @Failure      401  {object}  core.GatewayError
+// @Failure      503  {object}  core.GatewayError
+// @Router       /admin/api/v1/templates [get]
+func (h *Handler) ListTemplates(c *echo.Context) error {
+	if h.templates == nil {
+		return handleError(c, h.templatesUnavailableError())
+	}
+	return c.JSON(http.StatusOK, h.templates.List())
+}
+
+// ListTemplateVersions handles GET /admin/api/v1/templates/{name}
+//
+// @Summary      List prompt template versions
+// @Description  Returns every stored version of a prompt template, oldest first.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Template name"
+// @Success      200   {array}   prompttemplates.Template
+// @Failure      401   {object}  core.GatewayError
+// @Failure      404   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/templates/{name} [get]
+func (h *Handler) ListTemplateVersions(c *echo.Context) error {
+	if h.templates == nil {
+		return handleError(c, h.templatesUnavailableError())
+	}
+	name, err := decodeTemplatePathName(c.Param("name"))
+	if err != nil {
+		return handleError(c, err)
+	}
+	versions, ok := h.templates.Versions(name)
+	if !ok {
+		return handleError(c, core.NewNotFoundError("template not found: "+name))
+	}
+	return c.JSON(http.StatusOK, versions)
+}
+
+// CreateTemplateVersion handles POST /admin/api/v1/templates/{name}
+//
+// @Summary      Create a prompt template version
+// @Description  Stores the body as the next version of the named template, creating the template when it does not exist. Versions are immutable.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string                        true  "Template name"
+// @Param        body  body      createTemplateVersionRequest  true  "Template version"
+// @Success      201   {object}  prompttemplates.Template
+// @Failure      400   {object}  core.GatewayError
+// @Failure      401   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/templates/{name} [post]
+func (h *Handler) CreateTemplateVersion(c *echo.Context) error {
+	if h.templates == nil {
+		return handleError(c, h.templatesUnavailableError())
+	}
+	name, err := decodeTemplatePathName(c.Param("name"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	var req createTemplateVersionRequest
+	if err := c.Bind(&req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+
+	created, err := h.templates.Create(c.Request().Context(), prompttemplates.Template{
+		Name:        name,
+		Description: req.Description,
+		Messages:    req.Messages,
+		Variables:   req.Variables,
+	})
+	if err != nil {
+		return handleError(c, templateWriteError(err))
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetTemplateVersion handles GET /admin/api/v1/templates/{name}/versions/{version}
+//
+// @Summary      Get a prompt template version
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name     path      string   true  "Template name"
+// @Param        version  path      integer  true  "Template version"
+// @Success      200      {object}  prompttemplates.Template
+// @Failure      400      {object}  core.GatewayError
+// @Failure      401      {object}  core.GatewayError
+// @Failure      404      {object}  core.GatewayError
+// @Failure      503      {object}  core.GatewayError
+// @Router       /admin/api/v1/templates/{name}/versions/{version} [get]
+func (h *Handler) GetTemplateVersion(c *echo.Context) error {
+	if h.templates == nil {
+		return handleError(c, h.templatesUnavailableError())
+	}
+	name, version, err := decodeTemplateVersionPath(c)
+	if err != nil {
+		return handleError(c, err)
+	}
+	tpl, ok := h.templates.Get(name, version)
+	if !ok {
+		return handleError(c, core.NewNotFoundError("template "+name+" has no version "+strconv.Itoa(version)))
+	}
+	return c.JSON(http.StatusOK, tpl)
+}
+
+// DeleteTemplate handles DELETE /admin/api/v1/templates/{name}
+//
+// @Summary      Delete a prompt template
+// @Description  Removes every version of a prompt template.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        name  path  string  true  "Template name"
+// @Success      204
+// @Failure      401   {object}  core.GatewayError
+// @Failure      404   {object}  core.GatewayError
+// @Failure      503   {object}  core.GatewayError
+// @Router       /admin/api/v1/templates/{name} [delete]
+func (h *Handler) DeleteTemplate(c *echo.Context) error {
+	var unavailableErr error
+	var deleteFunc func(context.Context, string) error
+	if h.templates == nil {
+		unavailableErr = h.templatesUnavailableError()
+	} else {
+		deleteFunc = func(ctx context.Context, name string) error {
+			return h.templates.Delete(ctx, name, 0)
+		}
+	}
+	return deleteByName(
+		c,
+		unavailableErr,
+		"name",
+		decodeTemplatePathName,
+		deleteFunc,
+		prompttemplates.ErrNotFound,
+		"template not found: ",
+		templateWriteError,
+	)
+}
+
+// DeleteTemplateVersion handles DELETE /admin/api/v1/templates/{name}/versions/{version}
+//
+// @Summary      Delete a prompt template version
+// @Description  Removes one version of a prompt template. Requests that do not pin a version use the latest remaining one.
+// @Tags         admin
+// @Security     BearerAuth
+// @Param        name     path  string   true  "Template name"
+// @Param        version  path  integer  true  "Template version"
+// @Success      204
+// @Failure      400      {object}  core.GatewayError
+// @Failure      401      {object}  core.GatewayError
+// @Failure      404      {object}  core.GatewayError
+// @Failure      503      {object}  core.GatewayError
+// @Router       /admin/api/v1/templates/{name}/versions/{version} [delete]
+func (h *Handler) DeleteTemplateVersion(c *echo.Context) error {
+	if h.templates == nil {
+		return handleError(c, h.templatesUnavailableError())
+	}
+	name, version, err := decodeTemplateVersionPath(c)
+	if err != nil {
+		return handleError(c, err)
+	}
+	if err := h.templates.Delete(c.Request().Context(), name, version); err != nil {
+		if errors.Is(err, prompttemplates.ErrNotFound) {
+			return handleError(c, core.NewNotFoundError("template "+name+" has no version "+strconv.Itoa(version)))
+		}
+		return handleError(c, templateWriteError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // ListGuardrailTypes handles GET /admin/api/v1/guardrails/types
 func (h *Handler) ListGuardrailTypes(c *echo.Context) error {
 	if h.guardrailDefs == nil {
@@ -1496,6 +4223,39 @@ func (h *Handler) DeleteGuardrail(c *echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GuardrailCanary handles GET /admin/api/v1/guardrails/{name}/canary
+//
+// It compares the requests a guardrail in canary rollout was applied to with
+// the requests it skipped, from the canary decisions recorded in audit logs.
+// Removed guardrails can still be queried while their entries are retained.
+func (h *Handler) GuardrailCanary(c *echo.Context) error {
+	if h.auditReader == nil {
+		return handleError(c, h.auditLogUnavailableError())
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return handleError(c, core.NewInvalidRequestError("guardrail name is required", nil))
+	}
+
+	dateRange, err := parseDateRangeParams(c, h.maxQueryDays)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	stats, err := h.auditReader.GetGuardrailCanaryStats(c.Request().Context(), auditlog.GuardrailCanaryParams{
+		QueryParams: auditlog.QueryParams{
+			StartDate: dateRange.StartDate,
+			EndDate:   dateRange.EndDate,
+		},
+		Guardrail: name,
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
 // ListWorkflows handles GET /admin/api/v1/workflows
 func (h *Handler) ListWorkflows(c *echo.Context) error {
 	if h.workflows == nil {
@@ -1619,6 +4379,114 @@ func (h *Handler) DeactivateWorkflow(c *echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// stateServices returns the stores a state bundle covers.
+func (h *Handler) stateServices() statebundle.Services {
+	services := statebundle.Services{
+		Guardrails:      h.guardrailDefs,
+		Aliases:         h.aliases,
+		ModelOverrides:  h.modelOverrides,
+		PromptTemplates: h.templates,
+		Workflows:       h.workflows,
+		AuthKeys:        h.authKeys,
+	}
+	if h.registry != nil {
+		services.Catalog = h.registry
+	}
+	return services
+}
+
+// ExportState handles GET /admin/api/v1/state/export
+//
+// @Summary      Export gateway state
+// @Description  Returns the admin-managed state kept in storage (guardrails, aliases, model overrides, prompt templates, workflows and auth keys) as a versioned JSON bundle for disaster recovery. Auth key tokens are never exported: the bundle carries their SHA-256 hashes, sealed with AES-256-GCM when the X-GoModel-State-Passphrase header is set. Sections of disabled features are null.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-GoModel-State-Passphrase  header    string  false  "Passphrase that seals the auth key hashes"
+// @Success      200                         {object}  statebundle.Bundle
+// @Failure      401                         {object}  core.GatewayError
+// @Router       /admin/api/v1/state/export [get]
+func (h *Handler) ExportState(c *echo.Context) error {
+	passphrase := c.Request().Header.Get(statePassphraseHeader)
+
+	h.mutationMu.Lock()
+	bundle, err := h.stateServices().Export(c.Request().Context(), passphrase)
+	h.mutationMu.Unlock()
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, bundle)
+}
+
+// ImportState handles POST /admin/api/v1/state/import
+//
+// @Summary      Import gateway state
+// @Description  Applies a state bundle written by GET /admin/api/v1/state/export. In merge mode the bundle's entities are created or updated and the rest are kept; in replace mode every entity of a section in the bundle that the bundle does not contain is removed (auth keys are deactivated instead, and the global workflow is kept). Absent sections are never touched. The bundle is checked for referential integrity first: with problems nothing is applied and the answer is 400, or 200 with the problems in a dry run. With dry_run, answers with the changes the import would apply.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        mode                        query     string              false  "merge (default) or replace"
+// @Param        dry_run                     query     bool                false  "Report the changes without applying them"
+// @Param        X-GoModel-State-Passphrase  header    string              false  "Passphrase of a bundle exported with one"
+// @Param        body                        body      statebundle.Bundle  true   "State bundle"
+// @Success      200                         {object}  statebundle.Report
+// @Failure      400                         {object}  core.GatewayError
+// @Failure      401                         {object}  core.GatewayError
+// @Router       /admin/api/v1/state/import [post]
+func (h *Handler) ImportState(c *echo.Context) error {
+	mode, err := statebundle.ParseMode(c.QueryParam("mode"))
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+	}
+	dryRun := false
+	if raw := c.QueryParam("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError("invalid dry_run value, expected true or false", nil))
+		}
+		dryRun = parsed
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	bundle, warnings, err := statebundle.Decode(body)
+	if err != nil {
+		return handleError(c, stateImportError(err))
+	}
+
+	h.mutationMu.Lock()
+	defer h.mutationMu.Unlock()
+
+	ctx := c.Request().Context()
+	report, err := h.stateServices().Import(ctx, bundle, statebundle.ImportOptions{
+		Mode:       mode,
+		DryRun:     dryRun,
+		Passphrase: c.Request().Header.Get(statePassphraseHeader),
+	})
+	if !dryRun {
+		// Guardrail writes change the compiled workflows even when the
+		// import stopped halfway.
+		if refreshErr := h.refreshWorkflowsAfterGuardrailChange(ctx); refreshErr != nil && err == nil {
+			err = refreshErr
+		}
+	}
+	if err != nil {
+		return handleError(c, stateImportError(err))
+	}
+	report.Warnings = append(warnings, report.Warnings...)
+	return c.JSON(http.StatusOK, report)
+}
+
+func stateImportError(err error) error {
+	if statebundle.IsValidationError(err) {
+		return core.NewInvalidRequestError(err.Error(), err)
+	}
+	return err
+}
+
 func (h *Handler) refreshWorkflowsAfterGuardrailChange(ctx context.Context) error {
 	if h.workflows == nil {
 		return nil
@@ -1730,6 +4598,30 @@ func decodeAliasPathName(raw string) (string, error) {
 	return name, nil
 }
 
+func decodeTemplatePathName(raw string) (string, error) {
+	name, err := url.PathUnescape(strings.TrimSpace(raw))
+	if err != nil {
+		return "", core.NewInvalidRequestError("invalid template name", err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", core.NewInvalidRequestError("template name is required", nil)
+	}
+	return name, nil
+}
+
+func decodeTemplateVersionPath(c *echo.Context) (string, int, error) {
+	name, err := decodeTemplatePathName(c.Param("name"))
+	if err != nil {
+		return "", 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(c.Param("version")))
+	if err != nil || version < 1 {
+		return "", 0, core.NewInvalidRequestError("template version must be a positive integer", err)
+	}
+	return name, version, nil
+}
+
 func decodeModelOverridePathSelector(raw string) (string, error) {
 	selector, err := url.PathUnescape(strings.TrimSpace(raw))
 	if err != nil {
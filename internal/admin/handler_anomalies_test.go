@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/anomaly"
+	"gomodel/internal/usage"
+)
+
+func newAnomalyTestDetector(t *testing.T) *anomaly.Detector {
+	t.Helper()
+	detector := anomaly.New(config.AnomalyDetectionConfig{
+		Enabled:        true,
+		BaselineWindow: 24 * time.Hour,
+		MinBaseline:    time.Hour,
+		Cooldown:       time.Hour,
+		MaxSeries:      10,
+		MaxRecords:     10,
+		Rules:          []config.AnomalyRule{{Metric: config.AnomalyMetricRequestsPerMinute, Scope: config.AnomalyScopeModel, Above: 1}},
+	})
+	now := time.Now()
+	for range 2 {
+		detector.Observe(&usage.UsageEntry{Timestamp: now, Model: "gpt-4o", Provider: "openai"})
+	}
+	return detector
+}
+
+func listAnomalies(t *testing.T, h *Handler, path string) (*httptest.ResponseRecorder, AnomaliesResponse) {
+	t.Helper()
+	c, rec := newHandlerContext(path)
+	if err := h.Anomalies(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var body AnomaliesResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return rec, body
+}
+
+func TestAnomalies_UnavailableWhenDisabled(t *testing.T) {
+	h := NewHandler(nil, nil)
+	if rec, _ := listAnomalies(t, h, "/admin/api/v1/anomalies"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestAnomalies_ListAndAcknowledge(t *testing.T) {
+	h := NewHandler(nil, nil, WithAnomalies(newAnomalyTestDetector(t)))
+
+	rec, body := listAnomalies(t, h, "/admin/api/v1/anomalies")
+	if rec.Code != http.StatusOK || len(body.Anomalies) != 1 || body.Anomalies[0].Subject != "gpt-4o" {
+		t.Fatalf("GET anomalies = %d %s", rec.Code, rec.Body.String())
+	}
+	id := body.Anomalies[0].ID
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/anomalies/"+id+"/acknowledge", nil)
+	ackRec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, ackRec)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: id}})
+	if err := h.AcknowledgeAnomaly(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var acked anomaly.Anomaly
+	if err := json.Unmarshal(ackRec.Body.Bytes(), &acked); err != nil || ackRec.Code != http.StatusOK || !acked.Acknowledged {
+		t.Fatalf("acknowledge = %d %s", ackRec.Code, ackRec.Body.String())
+	}
+
+	if _, body := listAnomalies(t, h, "/admin/api/v1/anomalies?unacknowledged=true"); len(body.Anomalies) != 0 {
+		t.Fatalf("unacknowledged anomalies = %+v, want none", body.Anomalies)
+	}
+	if rec, _ := listAnomalies(t, h, "/admin/api/v1/anomalies?unacknowledged=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid filter, got %d", rec.Code)
+	}
+}
+
+func TestAcknowledgeAnomaly_UnknownID(t *testing.T) {
+	h := NewHandler(nil, nil, WithAnomalies(newAnomalyTestDetector(t)))
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/anomalies/missing/acknowledge", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+	if err := h.AcknowledgeAnomaly(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,153 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/authkeys"
+)
+
+func getAuditLogEntry(t *testing.T, h *Handler, id string, role authkeys.Role) *httptest.ResponseRecorder {
+	t.Helper()
+
+	c, rec := newHandlerContext("/admin/api/v1/audit/" + id)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: id}})
+	if role != "" {
+		c.Set(authkeys.RoleContextKey, role)
+	}
+	if err := h.AuditLogEntry(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func decodeAuditLogEntry(t *testing.T, rec *httptest.ResponseRecorder) auditlog.LogEntry {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var entry auditlog.LogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	return entry
+}
+
+func capturedAuditEntry() *auditlog.LogEntry {
+	return &auditlog.LogEntry{
+		ID:        "log-1",
+		Timestamp: time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC),
+		Data: &auditlog.LogData{
+			RequestHeaders: map[string]string{"User-Agent": "sdk"},
+			RequestBody:    map[string]any{"model": "gpt-4o"},
+		},
+	}
+}
+
+func TestAuditLogEntry_Unavailable(t *testing.T) {
+	if rec := getAuditLogEntry(t, NewHandler(nil, nil), "log-1", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestAuditLogEntry_NotFound(t *testing.T) {
+	reader := &mockAuditReader{}
+	h := NewHandler(nil, nil, WithAuditReader(auditlog.NewCachingReader(reader, 8, time.Minute)))
+
+	for range 2 {
+		if rec := getAuditLogEntry(t, h, "missing", ""); rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", rec.Code)
+		}
+	}
+	if reader.logByIDCalls != 2 {
+		t.Fatalf("store reads = %d, want 2: missing entries are not cached", reader.logByIDCalls)
+	}
+}
+
+func TestAuditLogEntry_CacheHitAndMiss(t *testing.T) {
+	reader := &mockAuditReader{logByID: capturedAuditEntry()}
+	h := NewHandler(nil, nil, WithAuditReader(auditlog.NewCachingReader(reader, 8, time.Minute)))
+
+	first := decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", ""))
+	// A scoped caller gets the cached entry without bodies, and stripping
+	// them must not change what the next admin sees.
+	scoped := decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", authkeys.RoleReadAuditMetadata))
+	second := decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", ""))
+
+	if reader.logByIDCalls != 1 {
+		t.Fatalf("store reads = %d, want 1", reader.logByIDCalls)
+	}
+	if first.Data == nil || first.Data.RequestBody == nil || second.Data == nil || second.Data.RequestHeaders["User-Agent"] != "sdk" {
+		t.Fatalf("admin entries lost captured data: first = %+v, second = %+v", first.Data, second.Data)
+	}
+	if scoped.Data == nil || scoped.Data.RequestBody != nil || scoped.Data.RequestHeaders != nil {
+		t.Fatalf("scoped entry kept captured data: %+v", scoped.Data)
+	}
+
+	c, rec := newHandlerContext("/admin/api/v1/diagnostics")
+	if err := h.Diagnostics(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var diag DiagnosticsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &diag); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	want := auditlog.LookupCacheStats{Entries: 1, MaxEntries: 8, Hits: 2, Misses: 1}
+	if diag.AuditLookupCache == nil || *diag.AuditLookupCache != want {
+		t.Fatalf("audit_lookup_cache = %+v, want %+v", diag.AuditLookupCache, want)
+	}
+}
+
+func TestAuditLogEntry_RedactionInvalidatesCache(t *testing.T) {
+	reader := &mockAuditReader{logByID: capturedAuditEntry()}
+	h := NewHandler(nil, nil, WithAuditReader(auditlog.NewCachingReader(reader, 8, time.Minute)))
+	decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", ""))
+
+	redacted := &auditlog.LogEntry{
+		ID:       "log-1",
+		Redacted: true,
+		Data:     &auditlog.LogData{RequestBody: auditlog.RedactedMarker},
+	}
+	reader.redactResult = redacted
+	reader.logByID = redacted
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("redact: expected 200, got %d", rec.Code)
+	}
+
+	entry := decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", ""))
+	if !entry.Redacted || entry.Data.RequestBody != auditlog.RedactedMarker {
+		t.Fatalf("entry after redaction = %+v, want the redacted entry", entry)
+	}
+	if reader.logByIDCalls != 2 {
+		t.Fatalf("store reads = %d, want 2", reader.logByIDCalls)
+	}
+}
+
+func TestAuditLogEntry_DeletionInvalidatesCache(t *testing.T) {
+	reader := &mockAuditReader{logByID: capturedAuditEntry()}
+	h := NewHandler(nil, nil, WithAuditReader(auditlog.NewCachingReader(reader, 8, time.Minute)))
+	decodeAuditLogEntry(t, getAuditLogEntry(t, h, "log-1", ""))
+
+	reader.logByID = nil
+	c, rec := newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/log-1", "log-1")
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", rec.Code)
+	}
+	if rec := getAuditLogEntry(t, h, "log-1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after deletion, got %d", rec.Code)
+	}
+}
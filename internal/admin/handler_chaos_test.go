@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/chaos"
+)
+
+func putChaosRules(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/api/v1/chaos/rules", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	if err := h.UpdateChaosRules(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func TestChaosRules_UnavailableWhenDisabled(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/chaos/rules")
+
+	if err := h.ChaosRules(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if rec := putChaosRules(t, h, `{"active":false}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for PUT, got %d", rec.Code)
+	}
+}
+
+func TestChaosRules_ReplaceAndKillSwitch(t *testing.T) {
+	injector, err := chaos.New(config.ChaosConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("chaos.New() error = %v", err)
+	}
+	h := NewHandler(nil, nil, WithChaos(injector))
+
+	rec := putChaosRules(t, h, `{"rules":[{"name":"outage","model":"gpt-4o","effect":"error","status":503}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body ChaosRulesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !body.Active || len(body.Rules) != 1 || body.Rules[0].Probability != 1 {
+		t.Fatalf("response = %+v, want one active rule with probability 1", body)
+	}
+
+	if rec := putChaosRules(t, h, `{"rules":[{"name":"bad","effect":"explode"}]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid rule, got %d", rec.Code)
+	}
+	if rec := putChaosRules(t, h, `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty update, got %d", rec.Code)
+	}
+
+	rec = putChaosRules(t, h, `{"active":false}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if injector.Active() || len(injector.Rules()) != 1 {
+		t.Fatalf("active = %v, rules = %v, want kill switch on with rules kept", injector.Active(), injector.Rules())
+	}
+
+	c, getRec := newHandlerContext("/admin/api/v1/chaos/rules")
+	if err := h.ChaosRules(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(getRec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Active || len(body.Rules) != 1 || body.Rules[0].Name != "outage" {
+		t.Fatalf("GET response = %+v, want inactive with the outage rule", body)
+	}
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/deprecations"
+)
+
+func getDeprecations(t *testing.T, h *Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/api/v1/deprecations", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	if err := h.Deprecations(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func TestDeprecations_UnavailableWhenDisabled(t *testing.T) {
+	if rec := getDeprecations(t, NewHandler(nil, nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestDeprecations_ListsEntries(t *testing.T) {
+	registry, err := deprecations.New(config.DeprecationsConfig{
+		Enabled:     true,
+		AfterSunset: "reject",
+		Models: []config.ModelDeprecationConfig{
+			{Provider: "openai", Model: "gpt-4-0613", Sunset: "2999-06-06", Replacement: "gpt-4o"},
+			{Model: "legacy-*", Sunset: "2020-01-01"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("deprecations.New() error = %v", err)
+	}
+	match, _ := registry.Lookup(deprecations.Target{Providers: []string{"openai"}, Models: []string{"gpt-4-0613"}})
+	registry.Record(match, "key-1")
+
+	rec := getDeprecations(t, NewHandler(nil, nil, WithDeprecations(registry)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp DeprecationsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.AfterSunset != "reject" || len(resp.Deprecations) != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	legacy, gpt4 := resp.Deprecations[0], resp.Deprecations[1]
+	if legacy.Model != "legacy-*" || !legacy.SunsetPassed || legacy.Upstream != deprecations.UpstreamUnknown {
+		t.Fatalf("legacy report = %+v", legacy)
+	}
+	if gpt4.Replacement != "gpt-4o" || gpt4.RequestsTotal != 1 || gpt4.RequestsRecent != 1 || len(gpt4.TopKeys) != 1 {
+		t.Fatalf("gpt-4 report = %+v", gpt4)
+	}
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/cache"
+	"gomodel/internal/memgovernor"
+	"gomodel/internal/pipelinestats"
+)
+
+type staticPipeline pipelinestats.Snapshot
+
+func (p staticPipeline) PipelineStats() pipelinestats.Snapshot {
+	return pipelinestats.Snapshot(p)
+}
+
+func getDiagnostics(t *testing.T, h *Handler) DiagnosticsResponse {
+	t.Helper()
+
+	c, rec := newHandlerContext("/admin/api/v1/diagnostics")
+	if err := h.Diagnostics(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp DiagnosticsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestDiagnostics_Healthy(t *testing.T) {
+	flushedAt := time.Now().UTC()
+	healthy := staticPipeline{BufferSize: 1000, Written: 42, Flushes: 3, LastFlushAt: &flushedAt, LagThresholdSeconds: 60}
+	h := NewHandler(nil, nil, WithWritePipelines(healthy, healthy))
+
+	resp := getDiagnostics(t, h)
+	if resp.Status != DiagnosticsStatusOK || len(resp.Issues) != 0 {
+		t.Fatalf("status = %s %v, want ok without issues", resp.Status, resp.Issues)
+	}
+	if resp.Runtime.Goroutines == 0 || resp.Runtime.HeapAllocBytes == 0 || resp.Runtime.SysBytes == 0 {
+		t.Fatalf("runtime = %+v, want goroutine and memory figures", resp.Runtime)
+	}
+	if resp.Pipelines.AuditLog == nil || resp.Pipelines.AuditLog.Written != 42 || resp.Pipelines.Usage == nil {
+		t.Fatalf("pipelines = %+v, want both pipelines reported", resp.Pipelines)
+	}
+	if resp.Registry != nil {
+		t.Fatalf("registry = %+v, want none without a registry", resp.Registry)
+	}
+}
+
+func TestDiagnostics_DisabledPipelines(t *testing.T) {
+	resp := getDiagnostics(t, NewHandler(nil, nil))
+	if resp.Status != DiagnosticsStatusOK || resp.Pipelines.AuditLog != nil || resp.Pipelines.Usage != nil {
+		t.Fatalf("response = %+v, want ok with no pipelines", resp)
+	}
+}
+
+func TestDiagnostics_Degraded(t *testing.T) {
+	failedAt := time.Now().UTC()
+	lagging := staticPipeline{
+		Buffered:             900,
+		BufferSize:           1000,
+		OldestPendingSeconds: 95,
+		LagThresholdSeconds:  60,
+		Lagging:              true,
+		Dropped:              12,
+	}
+	failing := staticPipeline{
+		BufferSize:           1000,
+		LastFlushAt:          &failedAt,
+		LastFlushError:       "database is locked",
+		LastFlushErrorAt:     &failedAt,
+		LastFlushErrorActive: true,
+	}
+	registry := newQuotaRegistry(&quotaFetchingProvider{})
+	h := NewHandler(nil, registry, WithWritePipelines(lagging, failing))
+
+	resp := getDiagnostics(t, h)
+	if resp.Status != DiagnosticsStatusDegraded || len(resp.Issues) != 3 {
+		t.Fatalf("status = %s %v, want degraded with three issues", resp.Status, resp.Issues)
+	}
+	issues := strings.Join(resp.Issues, "\n")
+	for _, want := range []string{"model registry is not initialized", "audit log write lag 1m35s exceeds 1m0s", "usage last flush failed: database is locked"} {
+		if !strings.Contains(issues, want) {
+			t.Fatalf("issues = %q, want %q", issues, want)
+		}
+	}
+	if resp.Registry == nil || resp.Registry.Initialized || resp.Registry.ProviderCount != 1 {
+		t.Fatalf("registry = %+v, want one uninitialized provider", resp.Registry)
+	}
+	if !resp.Pipelines.AuditLog.Lagging || resp.Pipelines.AuditLog.Dropped != 12 {
+		t.Fatalf("audit pipeline = %+v, want the lagging fixture", resp.Pipelines.AuditLog)
+	}
+}
+
+func TestDiagnostics_Memory(t *testing.T) {
+	store := cache.NewLRUStore(0, 0)
+	_ = store.Set(context.Background(), "key", []byte("value"), 0)
+	// Any running process is past 90% of a one-byte ceiling.
+	governor := memgovernor.New(config.MemoryConfig{BudgetBytes: 1 << 20, RSSCeilingBytes: 1, RSSWarnRatio: 0.9, CheckInterval: time.Second})
+	governor.Register("embeddings_cache", memgovernor.PriorityLow, store)
+
+	resp := getDiagnostics(t, NewHandler(nil, nil, WithMemoryGovernor(governor)))
+	if resp.Status != DiagnosticsStatusDegraded || len(resp.Issues) != 1 || !strings.Contains(resp.Issues[0], "near the 1 byte ceiling") {
+		t.Fatalf("status = %s %v, want degraded near the memory ceiling", resp.Status, resp.Issues)
+	}
+	if resp.Memory == nil || resp.Memory.BudgetBytes != 1<<20 || len(resp.Memory.Stores) != 1 {
+		t.Fatalf("memory = %+v, want the budget and one store", resp.Memory)
+	}
+	if got := resp.Memory.Stores[0]; got.Name != "embeddings_cache" || got.Priority != "low" || got.Entries != 1 || got.Bytes <= 0 {
+		t.Fatalf("store = %+v, want the embeddings cache with one entry", got)
+	}
+
+	if resp := getDiagnostics(t, NewHandler(nil, nil)); resp.Memory != nil {
+		t.Fatalf("memory = %+v, want none without a governor", resp.Memory)
+	}
+}
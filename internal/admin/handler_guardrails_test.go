@@ -11,6 +11,7 @@ import (
 
 	"github.com/labstack/echo/v5"
 
+	"gomodel/internal/auditlog"
 	"gomodel/internal/core"
 	"gomodel/internal/guardrails"
 	"gomodel/internal/workflows"
@@ -439,3 +440,52 @@ func TestDeleteGuardrailIgnoresDisabledWorkflowGuardrailRefs(t *testing.T) {
 		t.Fatal("Get(policy-system) = present, want deleted guardrail")
 	}
 }
+
+func TestGuardrailCanary(t *testing.T) {
+	stats := &auditlog.GuardrailCanaryStats{
+		Guardrail: "policy-system",
+		Arms: []auditlog.GuardrailCanaryArm{
+			{Arm: auditlog.GuardrailCanaryArmApplied, Requests: 10, Errors: 2, ErrorRate: 0.2},
+			{Arm: auditlog.GuardrailCanaryArmSkipped, Requests: 90, Errors: 9, ErrorRate: 0.1},
+		},
+	}
+	reader := &mockAuditReader{guardrailCanaryStats: stats}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+
+	c, rec := newHandlerContext("/admin/api/v1/guardrails/policy-system/canary?start_date=2026-03-01&end_date=2026-03-10")
+	c.SetPath("/admin/api/v1/guardrails/:name/canary")
+	c.SetPathValues(echo.PathValues{{Name: "name", Value: "policy-system"}})
+
+	if err := h.GuardrailCanary(c); err != nil {
+		t.Fatalf("GuardrailCanary() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if reader.lastGuardrailCanary.Guardrail != "policy-system" {
+		t.Fatalf("guardrail = %q, want policy-system", reader.lastGuardrailCanary.Guardrail)
+	}
+	if got := reader.lastGuardrailCanary.StartDate.Format("2006-01-02"); got != "2026-03-01" {
+		t.Fatalf("start date = %s, want 2026-03-01", got)
+	}
+	var got auditlog.GuardrailCanaryStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(got.Arms) != 2 || got.Arms[0].Requests != 10 || got.Arms[1].ErrorRate != 0.1 {
+		t.Fatalf("stats = %+v", got)
+	}
+}
+
+func TestGuardrailCanary_AuditUnavailable(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/guardrails/policy-system/canary")
+	c.SetPathValues(echo.PathValues{{Name: "name", Value: "policy-system"}})
+
+	if err := h.GuardrailCanary(c); err != nil {
+		t.Fatalf("GuardrailCanary() error = %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/inspect"
+)
+
+func callInspect(t *testing.T, handler echo.HandlerFunc, method, body string) (*httptest.ResponseRecorder, *auditlog.LogEntry) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/admin/api/v1/inspect", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(core.WithAuthKeyID(req.Context(), "key-oncall"))
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	entry := &auditlog.LogEntry{}
+	c.Set(string(auditlog.LogEntryKey), entry)
+
+	if err := handler(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec, entry
+}
+
+func TestInspection_StartRecordsWhoAndStop(t *testing.T) {
+	h := NewHandler(nil, nil, WithInspector(inspect.New(nil)))
+
+	rec, entry := callInspect(t, h.StartInspection, http.MethodPost, `{"model":"gpt-4o","duration_seconds":120,"max_samples":20}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var state inspect.State
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !state.Active || state.StartedBy != "key-oncall" || state.MaxSamples != 20 || state.PreviewChars != inspect.DefaultPreviewChars {
+		t.Fatalf("state = %+v", state)
+	}
+	got := entry.Data.Inspection
+	if got == nil || got.Action != "start" || got.Model != "gpt-4o" || got.MaxSamples != 20 || got.ExpiresAt == nil || !got.ExpiresAt.Equal(*state.ExpiresAt) {
+		t.Fatalf("audit inspection = %+v, want the started filter and limits", got)
+	}
+
+	rec, _ = callInspect(t, h.InspectionSamples, http.MethodGet, "")
+	var samples InspectionSamplesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &samples); err != nil {
+		t.Fatalf("failed to decode samples: %v", err)
+	}
+	if !samples.State.Active || samples.Samples == nil || len(samples.Samples) != 0 {
+		t.Fatalf("samples = %+v, want an active state and an empty list", samples)
+	}
+
+	rec, entry = callInspect(t, h.StopInspection, http.MethodPost, "")
+	if rec.Code != http.StatusOK || entry.Data.Inspection == nil || entry.Data.Inspection.Action != "stop" {
+		t.Fatalf("stop = %d %s, audit %+v", rec.Code, rec.Body.String(), entry.Data)
+	}
+	if state := h.inspector.State(); state.Active {
+		t.Fatal("inspection still active after stop")
+	}
+}
+
+func TestInspection_RejectsInvalidRequests(t *testing.T) {
+	h := NewHandler(nil, nil, WithInspector(inspect.New(nil)))
+	for name, body := range map[string]string{
+		"negative duration": `{"duration_seconds":-1}`,
+		"too long":          `{"duration_seconds":7200}`,
+		"too many samples":  `{"max_samples":5000}`,
+		"long previews":     `{"preview_chars":10000}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec, entry := callInspect(t, h.StartInspection, http.MethodPost, body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if entry.Data != nil && entry.Data.Inspection != nil {
+				t.Fatalf("rejected start was audited as started: %+v", entry.Data.Inspection)
+			}
+		})
+	}
+
+	unavailable := NewHandler(nil, nil)
+	if rec, _ := callInspect(t, unavailable.InspectionSamples, http.MethodGet, ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an inspector, got %d", rec.Code)
+	}
+}
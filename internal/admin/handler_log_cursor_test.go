@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/storage"
+	"gomodel/internal/usage"
+)
+
+func newLogCursorStorage(t *testing.T) storage.SQLiteStorage {
+	t.Helper()
+	st, err := storage.NewSQLite(storage.SQLiteConfig{Path: filepath.Join(t.TempDir(), "logs.db")})
+	if err != nil {
+		t.Fatalf("new sqlite storage: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+// logFixtureTime spaces fixture rows so that pairs share a second and the
+// page order depends on the ID tiebreak.
+func logFixtureTime(base time.Time, i int) time.Time {
+	return base.Add(-time.Duration(i/2) * time.Second)
+}
+
+// pageThrough requests pages of 4 entries until next_cursor runs out, calling
+// between after the first page, and returns the IDs in the order received.
+func pageThrough(t *testing.T, page func(*testing.T, *Handler, url.Values) (int, []string, string), h *Handler, between func()) []string {
+	t.Helper()
+	var ids []string
+	query := url.Values{"limit": {"4"}}
+	for n := 0; ; n++ {
+		status, pageIDs, next := page(t, h, query)
+		if status != http.StatusOK {
+			t.Fatalf("page %d status = %d", n, status)
+		}
+		ids = append(ids, pageIDs...)
+		if n == 0 {
+			between()
+		}
+		if next == "" {
+			return ids
+		}
+		query.Set("cursor", next)
+		if n > 20 {
+			t.Fatal("pagination did not terminate")
+		}
+	}
+}
+
+func assertEachOnce(t *testing.T, got []string, want []string) {
+	t.Helper()
+	seen := make(map[string]int, len(got))
+	for _, id := range got {
+		seen[id]++
+	}
+	for _, id := range want {
+		if seen[id] != 1 {
+			t.Fatalf("entry %s seen %d times; pages = %v", id, seen[id], got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("received %d entries, want the %d that existed when paging started: %v", len(got), len(want), got)
+	}
+}
+
+func auditLogPage(t *testing.T, h *Handler, query url.Values) (int, []string, string) {
+	t.Helper()
+	c, rec := newHandlerContext("/admin/api/v1/audit/log?" + query.Encode())
+	if err := h.AuditLog(c); err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	var result auditlog.LogListResult
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode AuditLog page: %v", err)
+		}
+	}
+	ids := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		ids = append(ids, entry.ID)
+	}
+	return rec.Code, ids, result.NextCursor
+}
+
+func usageLogPage(t *testing.T, h *Handler, query url.Values) (int, []string, string) {
+	t.Helper()
+	c, rec := newHandlerContext("/admin/api/v1/usage/log?" + query.Encode())
+	if err := h.UsageLog(c); err != nil {
+		t.Fatalf("UsageLog() error = %v", err)
+	}
+	var result usage.UsageLogResult
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode UsageLog page: %v", err)
+		}
+	}
+	ids := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		ids = append(ids, entry.ID)
+	}
+	return rec.Code, ids, result.NextCursor
+}
+
+func TestAuditLog_CursorPagesStayStableWhileEntriesArrive(t *testing.T) {
+	st := newLogCursorStorage(t)
+	store, err := auditlog.NewSQLiteStore(st.DB(), 0)
+	if err != nil {
+		t.Fatalf("new audit store: %v", err)
+	}
+	reader, err := auditlog.NewSQLiteReader(st.DB())
+	if err != nil {
+		t.Fatalf("new audit reader: %v", err)
+	}
+
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	var fixture []*auditlog.LogEntry
+	var want []string
+	for i := range 11 {
+		id := fmt.Sprintf("log-%02d", i)
+		fixture = append(fixture, &auditlog.LogEntry{ID: id, Timestamp: logFixtureTime(base, i), Method: http.MethodPost, Path: "/v1/chat/completions"})
+		want = append(want, id)
+	}
+	if err := store.WriteBatch(context.Background(), fixture); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	got := pageThrough(t, auditLogPage, h, func() {
+		// Newer entries shift every offset; the cursor must not notice.
+		var arrivals []*auditlog.LogEntry
+		for i := range 3 {
+			arrivals = append(arrivals, &auditlog.LogEntry{ID: fmt.Sprintf("new-%d", i), Timestamp: base.Add(time.Minute), Method: http.MethodPost})
+		}
+		if err := store.WriteBatch(context.Background(), arrivals); err != nil {
+			t.Fatalf("write arrivals: %v", err)
+		}
+	})
+	assertEachOnce(t, got, want)
+}
+
+func TestUsageLog_CursorPagesStayStableWhileEntriesArrive(t *testing.T) {
+	st := newLogCursorStorage(t)
+	store, err := usage.NewSQLiteStore(st.DB(), 0)
+	if err != nil {
+		t.Fatalf("new usage store: %v", err)
+	}
+	reader, err := usage.NewSQLiteReader(st.DB())
+	if err != nil {
+		t.Fatalf("new usage reader: %v", err)
+	}
+
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	var fixture []*usage.UsageEntry
+	var want []string
+	for i := range 11 {
+		id := fmt.Sprintf("usage-%02d", i)
+		fixture = append(fixture, &usage.UsageEntry{ID: id, RequestID: id, Timestamp: logFixtureTime(base, i), Model: "gpt-4o", Provider: "openai"})
+		want = append(want, id)
+	}
+	if err := store.WriteBatch(context.Background(), fixture); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	h := NewHandler(reader, nil)
+	got := pageThrough(t, usageLogPage, h, func() {
+		var arrivals []*usage.UsageEntry
+		for i := range 3 {
+			id := fmt.Sprintf("new-%d", i)
+			arrivals = append(arrivals, &usage.UsageEntry{ID: id, RequestID: id, Timestamp: base.Add(time.Minute), Model: "gpt-4o", Provider: "openai"})
+		}
+		if err := store.WriteBatch(context.Background(), arrivals); err != nil {
+			t.Fatalf("write arrivals: %v", err)
+		}
+	})
+	assertEachOnce(t, got, want)
+}
+
+func TestLogCursor_RejectsOtherFiltersAndOffset(t *testing.T) {
+	st := newLogCursorStorage(t)
+	store, err := usage.NewSQLiteStore(st.DB(), 0)
+	if err != nil {
+		t.Fatalf("new usage store: %v", err)
+	}
+	reader, err := usage.NewSQLiteReader(st.DB())
+	if err != nil {
+		t.Fatalf("new usage reader: %v", err)
+	}
+	base := time.Now().UTC().Add(-time.Hour)
+	var fixture []*usage.UsageEntry
+	for i := range 3 {
+		id := fmt.Sprintf("usage-%d", i)
+		fixture = append(fixture, &usage.UsageEntry{ID: id, RequestID: id, Timestamp: base, Model: "gpt-4o", Provider: "openai"})
+	}
+	if err := store.WriteBatch(context.Background(), fixture); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	h := NewHandler(reader, nil)
+
+	status, _, next := usageLogPage(t, h, url.Values{"limit": {"1"}, "model": {"gpt-4o"}})
+	if status != http.StatusOK || next == "" {
+		t.Fatalf("first page status = %d, next_cursor = %q", status, next)
+	}
+
+	for name, query := range map[string]url.Values{
+		"other filters": {"limit": {"1"}, "model": {"gpt-4o-mini"}, "cursor": {next}},
+		"with offset":   {"limit": {"1"}, "model": {"gpt-4o"}, "cursor": {next}, "offset": {"1"}},
+		"malformed":     {"limit": {"1"}, "model": {"gpt-4o"}, "cursor": {"not-a-cursor"}},
+	} {
+		if status, _, _ := usageLogPage(t, h, query); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, status)
+		}
+	}
+	// The page size is not a filter, so it may change between pages.
+	if status, ids, _ := usageLogPage(t, h, url.Values{"limit": {"5"}, "model": {"gpt-4o"}, "cursor": {next}}); status != http.StatusOK || len(ids) != 2 {
+		t.Fatalf("resized page status = %d, ids = %v; want the 2 remaining entries", status, ids)
+	}
+}
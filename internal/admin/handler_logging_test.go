@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/logging"
+)
+
+func putLogLevel(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/api/v1/logging/level", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	if err := h.SetLogLevel(c); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	return rec
+}
+
+func TestSetLogLevel_ChangesGlobalLevel(t *testing.T) {
+	previous := logging.Levels().Level
+	t.Cleanup(func() {
+		level, _ := logging.ParseLevel(previous)
+		_ = logging.SetLevel("", level)
+	})
+
+	h := NewHandler(nil, nil)
+	rec := putLogLevel(t, h, `{"level":"debug"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var snapshot logging.LevelsSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if snapshot.Level != "debug" {
+		t.Fatalf("level = %q, want debug", snapshot.Level)
+	}
+	if got := logging.Levels().Level; got != "debug" {
+		t.Fatalf("effective level = %q, want debug", got)
+	}
+}
+
+func TestSetLogLevel_RejectsInvalidInput(t *testing.T) {
+	h := NewHandler(nil, nil)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing level", body: `{}`},
+		{name: "unknown level", body: `{"level":"trace"}`},
+		{name: "unknown component", body: `{"level":"warn","component":"nope"}`},
+		{name: "malformed body", body: `{"level":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := putLogLevel(t, h, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
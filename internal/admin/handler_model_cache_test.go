@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomodel/internal/cache/modelcache"
+)
+
+func TestModelCache_UnavailableWithoutLocalCache(t *testing.T) {
+	h := NewHandler(nil, nil)
+
+	c, rec := newHandlerContext("/admin/api/v1/cache")
+	if err := h.ModelCacheFiles(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("list: expected 503, got %d", rec.Code)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/cache")
+	if err := h.PurgeModelCache(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("purge: expected 503, got %d", rec.Code)
+	}
+}
+
+func TestModelCache_ListAndPurge(t *testing.T) {
+	dir := t.TempDir()
+	cache := modelcache.NewManagedLocalCache(dir, modelcache.CleanupPolicy{MaxBytes: 1 << 20})
+	if err := cache.Set(context.Background(), &modelcache.ModelCache{Providers: map[string]modelcache.CachedProvider{}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pricing.v1.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write artifact: %v", err)
+	}
+	h := NewHandler(nil, nil, WithLocalModelCache(cache))
+
+	c, rec := newHandlerContext("/admin/api/v1/cache")
+	if err := h.ModelCacheFiles(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report modelcache.CacheDirReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if rec.Code != http.StatusOK || report.CurrentFile != modelcache.LocalCacheFileName() || len(report.Files) != 2 || report.TotalBytes == 0 {
+		t.Fatalf("report = %d %+v, want both cache files", rec.Code, report)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/cache")
+	if err := h.PurgeModelCache(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var purged ModelCachePurgeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &purged); err != nil {
+		t.Fatalf("decode purge: %v", err)
+	}
+	if rec.Code != http.StatusOK || len(purged.Removed) != 2 || purged.FreedBytes != report.TotalBytes {
+		t.Fatalf("purge = %d %+v, want both files removed and %d bytes freed", rec.Code, purged, report.TotalBytes)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("cache directory still holds %d files", len(entries))
+	}
+}
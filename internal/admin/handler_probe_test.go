@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/probe"
+)
+
+func postProbe(t *testing.T, h *Handler, name, body string) (*httptest.ResponseRecorder, *auditlog.LogEntry) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/providers/"+name+"/probe", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetPathValues(echo.PathValues{{Name: "name", Value: name}})
+	entry := &auditlog.LogEntry{}
+	c.Set(string(auditlog.LogEntryKey), entry)
+
+	if err := h.ProbeProvider(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec, entry
+}
+
+func TestProbeProvider_StoresAppliedProfile(t *testing.T) {
+	registry := newQuotaRegistry(&handlerMockProvider{})
+	h := NewHandler(nil, registry, WithCapabilityProbe(probe.New(config.CapabilityProbeConfig{TokenBudget: 100}, nil, nil)))
+
+	rec, entry := postProbe(t, h, "openai_primary", `{"model":"tiny-chat","checks":["chat","embeddings"],"apply":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var profile core.CapabilityProfile
+	if err := json.Unmarshal(rec.Body.Bytes(), &profile); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(profile.Checks) != 2 || profile.Checks[0].Status != core.CapabilitySupported || profile.Checks[1].Status != core.CapabilityUnsupported || !profile.Applied {
+		t.Fatalf("profile = %+v, want chat supported, embeddings unsupported, applied", profile)
+	}
+
+	if supported, known := registry.ProbedCapability("openai_primary", core.CapabilityEmbeddings); supported || !known {
+		t.Fatalf("ProbedCapability(embeddings) = %v, %v; want known unsupported", supported, known)
+	}
+	var stored *core.CapabilityProfile
+	for _, snapshot := range registry.ProviderRuntimeSnapshots() {
+		if snapshot.Name == "openai_primary" {
+			stored = snapshot.Capabilities
+		}
+	}
+	if stored == nil || stored.Model != "tiny-chat" {
+		t.Fatalf("provider status capabilities = %+v, want the probe profile", stored)
+	}
+
+	got := entry.Data.CapabilityProbe
+	if got == nil || got.Provider != "openai_primary" || len(got.Supported) != 1 || len(got.Unsupported) != 1 || !got.Applied {
+		t.Fatalf("audit capability_probe = %+v, want one supported and one unsupported check", got)
+	}
+}
+
+func TestProbeProvider_RejectsInvalidRequests(t *testing.T) {
+	registry := newQuotaRegistry(&handlerMockProvider{})
+	h := NewHandler(nil, registry, WithCapabilityProbe(probe.New(config.CapabilityProbeConfig{}, nil, nil)))
+
+	cases := map[string]struct {
+		name string
+		body string
+		want int
+	}{
+		"missing model":    {name: "openai_primary", body: `{}`, want: http.StatusBadRequest},
+		"unknown check":    {name: "openai_primary", body: `{"model":"m","checks":["vision"]}`, want: http.StatusBadRequest},
+		"unknown provider": {name: "missing", body: `{"model":"m"}`, want: http.StatusNotFound},
+	}
+	for label, tc := range cases {
+		if rec, _ := postProbe(t, h, tc.name, tc.body); rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", label, tc.want, rec.Code)
+		}
+	}
+
+	if rec, _ := postProbe(t, NewHandler(nil, registry), "openai_primary", `{"model":"m"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a prober, got %d", rec.Code)
+	}
+}
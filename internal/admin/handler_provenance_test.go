@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/provenance"
+)
+
+func postVerifyProvenance(t *testing.T, h *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/provenance/verify", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+
+	if err := h.VerifyProvenance(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func TestVerifyProvenance_UnavailableWithoutSecret(t *testing.T) {
+	for name, h := range map[string]*Handler{
+		"disabled":  NewHandler(nil, nil),
+		"no secret": NewHandler(nil, nil, WithProvenance(provenance.New(config.ProvenanceConfig{Enabled: true}))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if rec := postVerifyProvenance(t, h, `{"response":{}}`); rec.Code != http.StatusServiceUnavailable {
+				t.Fatalf("expected 503, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestVerifyProvenance_DetectsTamperedContent(t *testing.T) {
+	signer := provenance.New(config.ProvenanceConfig{Enabled: true, Embed: true, Secret: "secret"})
+	h := NewHandler(nil, nil, WithProvenance(signer))
+
+	signed, ok := signer.Embed([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"message":{"content":"yes"}}]}`), signer.Stamp("req-1", "openai", "gpt-4o"))
+	if !ok {
+		t.Fatal("Embed() ok = false")
+	}
+	claimed, err := provenance.Extract(signed)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	claimedJSON, err := json.Marshal(claimed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantValid bool
+	}{
+		{name: "embedded", body: `{"response":` + string(signed) + `}`, wantCode: http.StatusOK, wantValid: true},
+		{
+			name:      "claimed separately",
+			body:      `{"response":{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"message":{"content":"yes"}}]},"provenance":` + string(claimedJSON) + `}`,
+			wantCode:  http.StatusOK,
+			wantValid: true,
+		},
+		{name: "tampered", body: `{"response":` + strings.Replace(string(signed), `"yes"`, `"no"`, 1) + `}`, wantCode: http.StatusOK},
+		{name: "missing response", body: `{}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postVerifyProvenance(t, h, tt.body)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var result provenance.Result
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if result.Valid != tt.wantValid {
+				t.Fatalf("valid = %v (%s), want %v", result.Valid, result.Reason, tt.wantValid)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/providers"
+)
+
+type quotaFetchingProvider struct {
+	handlerMockProvider
+	limits core.RateLimits
+	err    error
+	calls  int
+}
+
+func (p *quotaFetchingProvider) FetchRateLimits(_ context.Context) (core.RateLimits, error) {
+	p.calls++
+	if p.err != nil {
+		return core.RateLimits{}, p.err
+	}
+	return p.limits, nil
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func getProviderQuota(t *testing.T, h *Handler, name, query string) (*providerQuotaResponse, int) {
+	t.Helper()
+
+	path := "/admin/api/v1/providers/" + name + "/quota"
+	if query != "" {
+		path += "?" + query
+	}
+	c, rec := newHandlerContext(path)
+	c.SetPathValues(echo.PathValues{{Name: "name", Value: name}})
+	if err := h.ProviderQuota(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		return nil, rec.Code
+	}
+	var resp providerQuotaResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return &resp, rec.Code
+}
+
+func newQuotaRegistry(provider core.Provider) *providers.ModelRegistry {
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(provider, "openai_primary", "openai")
+	return registry
+}
+
+func TestProviderQuota_ReturnsObservedLimits(t *testing.T) {
+	registry := newQuotaRegistry(&handlerMockProvider{})
+	observedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	registry.RecordRateLimits("openai_primary", core.RateLimits{
+		Requests:   &core.RateLimitWindow{Limit: int64Ptr(500), Remaining: int64Ptr(499)},
+		ObservedAt: observedAt,
+	})
+	registry.RecordRateLimits("openai_primary", core.RateLimits{
+		Tokens:     &core.RateLimitWindow{Limit: int64Ptr(30000), Remaining: int64Ptr(29000)},
+		ObservedAt: observedAt.Add(time.Second),
+	})
+
+	resp, code := getProviderQuota(t, NewHandler(nil, registry), "openai_primary", "")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.Status != ProviderQuotaStatusKnown || resp.Type != "openai" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.RateLimits == nil || resp.RateLimits.Requests == nil || resp.RateLimits.Tokens == nil {
+		t.Fatalf("expected merged request and token limits, got %+v", resp.RateLimits)
+	}
+	if got := *resp.RateLimits.Tokens.Remaining; got != 29000 {
+		t.Fatalf("tokens remaining = %d, want 29000", got)
+	}
+	if !resp.RateLimits.ObservedAt.Equal(observedAt.Add(time.Second)) {
+		t.Fatalf("observed_at = %v, want latest observation", resp.RateLimits.ObservedAt)
+	}
+	if resp.Fetch != nil {
+		t.Fatalf("expected no fetch without refresh, got %+v", resp.Fetch)
+	}
+}
+
+func TestProviderQuota_UnknownWithoutObservations(t *testing.T) {
+	resp, code := getProviderQuota(t, NewHandler(nil, newQuotaRegistry(&handlerMockProvider{})), "openai_primary", "refresh=true")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.Status != ProviderQuotaStatusUnknown || resp.RateLimits != nil {
+		t.Fatalf("expected unknown quota, got %+v", resp)
+	}
+	if resp.Fetch == nil || resp.Fetch.Status != ProviderQuotaFetchUnsupported {
+		t.Fatalf("expected unsupported fetch, got %+v", resp.Fetch)
+	}
+}
+
+func TestProviderQuota_RefreshRecordsFetchedLimits(t *testing.T) {
+	provider := &quotaFetchingProvider{limits: core.RateLimits{
+		Requests:   &core.RateLimitWindow{Limit: int64Ptr(50), Remaining: int64Ptr(10)},
+		ObservedAt: time.Now().UTC(),
+	}}
+
+	resp, _ := getProviderQuota(t, NewHandler(nil, newQuotaRegistry(provider)), "openai_primary", "refresh=true")
+	if provider.calls != 1 {
+		t.Fatalf("expected one fetch, got %d", provider.calls)
+	}
+	if resp.Status != ProviderQuotaStatusKnown || resp.Fetch == nil || resp.Fetch.Status != ProviderQuotaFetchOK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := *resp.RateLimits.Requests.Remaining; got != 10 {
+		t.Fatalf("requests remaining = %d, want 10", got)
+	}
+}
+
+func TestProviderQuota_FetchErrorKeepsObservedLimits(t *testing.T) {
+	provider := &quotaFetchingProvider{err: errors.New("upstream unavailable")}
+	registry := newQuotaRegistry(provider)
+	registry.RecordRateLimits("openai_primary", core.RateLimits{
+		Requests:   &core.RateLimitWindow{Remaining: int64Ptr(7)},
+		ObservedAt: time.Now().UTC(),
+	})
+
+	resp, code := getProviderQuota(t, NewHandler(nil, registry), "openai_primary", "refresh=true")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.Fetch == nil || resp.Fetch.Status != ProviderQuotaFetchFailed || resp.Fetch.Error != "upstream unavailable" {
+		t.Fatalf("expected failed fetch, got %+v", resp.Fetch)
+	}
+	if resp.Status != ProviderQuotaStatusKnown || *resp.RateLimits.Requests.Remaining != 7 {
+		t.Fatalf("expected previously observed limits, got %+v", resp)
+	}
+}
+
+func TestProviderQuota_RejectsUnknownProviderAndInvalidRefresh(t *testing.T) {
+	h := NewHandler(nil, newQuotaRegistry(&handlerMockProvider{}))
+
+	if _, code := getProviderQuota(t, h, "missing", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown provider, got %d", code)
+	}
+	if _, code := getProviderQuota(t, h, "openai_primary", "refresh=maybe"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid refresh, got %d", code)
+	}
+	if _, code := getProviderQuota(t, NewHandler(nil, nil), "openai_primary", ""); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without registry, got %d", code)
+	}
+}
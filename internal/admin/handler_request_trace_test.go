@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/usage"
+)
+
+func getRequestTrace(t *testing.T, h *Handler, requestID string) (*httptest.ResponseRecorder, RequestTrace) {
+	t.Helper()
+
+	c, rec := newHandlerContext("/admin/api/v1/requests/" + requestID)
+	c.SetPathValues(echo.PathValues{{Name: "request_id", Value: requestID}})
+	if err := h.RequestTrace(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var trace RequestTrace
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &trace); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return rec, trace
+}
+
+func TestRequestTrace_UnavailableWithoutReaders(t *testing.T) {
+	if rec, _ := getRequestTrace(t, NewHandler(nil, nil), "req-1"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestRequestTrace_JoinsAuditUsageAndAnomalies(t *testing.T) {
+	detector := newAnomalyTestDetector(t)
+	detectedAt := detector.List(false)[0].DetectedAt
+
+	cost := 0.0042
+	auditReader := &mockAuditReader{logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{
+		{
+			ID:                 "log-2",
+			Timestamp:          detectedAt,
+			RequestID:          "req-1",
+			DurationNs:         int64(900 * time.Millisecond),
+			UpstreamDurationNs: int64(800 * time.Millisecond),
+			RequestedModel:     "smart",
+			ResolvedModel:      "gpt-4o",
+			Provider:           "openai",
+			ProviderName:       "openai-main",
+			AliasUsed:          true,
+			StatusCode:         http.StatusOK,
+			Method:             http.MethodPost,
+			Path:               "/v1/chat/completions",
+			Data: &auditlog.LogData{
+				Experiment:      &auditlog.ExperimentSnapshot{Name: "prompt-v2", Variant: "b"},
+				ContextOverflow: &auditlog.ContextOverflowSnapshot{Strategy: "truncate", DroppedMessages: 3},
+				Moderation:      &auditlog.ModerationSnapshot{Flagged: []string{"harassment"}},
+				StreamTiming:    &auditlog.StreamTimingSnapshot{Chunks: 12, MaxGapMs: 40, P95GapMs: 30},
+				RequestBody:     map[string]any{"model": "smart"},
+			},
+		},
+		{ID: "log-1", Timestamp: detectedAt.Add(-time.Second), RequestID: "req-1", StatusCode: http.StatusBadGateway, ErrorType: "provider_error"},
+	}}}
+	usageReader := &mockUsageReader{usageLog: &usage.UsageLogResult{Entries: []usage.UsageLogEntry{{
+		ID:           "usage-1",
+		RequestID:    "req-1",
+		Timestamp:    detectedAt,
+		Model:        "gpt-4o",
+		Provider:     "openai",
+		ProviderName: "openai-main",
+		InputTokens:  120,
+		OutputTokens: 30,
+		TotalTokens:  150,
+		TotalCost:    &cost,
+		RawData:      map[string]any{"cached_tokens": float64(64)},
+	}}}}
+	h := NewHandler(usageReader, nil, WithAuditReader(auditReader), WithAnomalies(detector))
+
+	rec, trace := getRequestTrace(t, h, "req-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if auditReader.lastQuery.RequestID != "req-1" || usageReader.lastUsageLog.RequestID != "req-1" || usageReader.lastUsageLog.CacheMode != usage.CacheModeAll {
+		t.Fatalf("lookups = audit %+v, usage %+v; want both by request ID, usage across cache modes", auditReader.lastQuery, usageReader.lastUsageLog)
+	}
+	if len(trace.Missing) != 0 {
+		t.Fatalf("missing = %v, want every section present", trace.Missing)
+	}
+	if trace.Audit == nil || trace.Audit.ID != "log-2" || trace.Audit.Data.RequestBody == nil {
+		t.Fatalf("audit = %+v, want the newest entry with its body for an admin caller", trace.Audit)
+	}
+	if len(trace.Attempts) != 2 || trace.Attempts[0].ID != "log-1" || trace.Attempts[0].ErrorType != "provider_error" || trace.Attempts[1].ID != "log-2" {
+		t.Fatalf("attempts = %+v, want log-1 then log-2", trace.Attempts)
+	}
+	if trace.Usage == nil || trace.Usage.TotalTokens != 150 || trace.Usage.TotalCost == nil || trace.Usage.RawData["cached_tokens"] != float64(64) {
+		t.Fatalf("usage = %+v, want the usage record with costs and raw data", trace.Usage)
+	}
+	if trace.Timing == nil || trace.Timing.GatewayOverheadNs == nil || *trace.Timing.GatewayOverheadNs != int64(100*time.Millisecond) ||
+		trace.Timing.Stream == nil || trace.Timing.Stream.Chunks != 12 {
+		t.Fatalf("timing = %+v, want 100ms gateway overhead and the stream timing", trace.Timing)
+	}
+	if r := trace.Route; r == nil || r.Source != "audit" || r.RequestedModel != "smart" || r.ResolvedModel != "gpt-4o" || r.ProviderName != "openai-main" || !r.AliasUsed {
+		t.Fatalf("route = %+v, want the audited smart -> gpt-4o route on openai-main", trace.Route)
+	}
+	if tr := trace.Transformations; tr == nil || tr.Experiment == nil || tr.Experiment.Variant != "b" || tr.ContextOverflow == nil || tr.ContextOverflow.DroppedMessages != 3 {
+		t.Fatalf("transformations = %+v, want the experiment and context overflow", trace.Transformations)
+	}
+	if trace.Flags.Moderation == nil || len(trace.Flags.Moderation.Flagged) != 1 {
+		t.Fatalf("moderation = %+v, want the harassment flag", trace.Flags.Moderation)
+	}
+	if len(trace.Flags.Anomalies) != 1 || trace.Flags.Anomalies[0].Subject != "gpt-4o" {
+		t.Fatalf("anomalies = %+v, want the gpt-4o anomaly", trace.Flags.Anomalies)
+	}
+}
+
+func TestRequestTrace_PartialData(t *testing.T) {
+	t.Run("audit without usage", func(t *testing.T) {
+		auditReader := &mockAuditReader{logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{
+			{ID: "log-1", RequestID: "req-1", Provider: "openai", StatusCode: http.StatusBadGateway, DurationNs: 5000},
+		}}}
+		h := NewHandler(&mockUsageReader{usageLog: &usage.UsageLogResult{Entries: []usage.UsageLogEntry{}}}, nil, WithAuditReader(auditReader))
+
+		rec, trace := getRequestTrace(t, h, "req-1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), `"usage":null`) || !strings.Contains(trace.Missing["usage"], "status 502") {
+			t.Fatalf("usage = %s, missing %q; want an explicit null with the failure status", rec.Body.String(), trace.Missing["usage"])
+		}
+		if trace.Timing == nil || trace.Timing.DurationNs != 5000 || trace.Timing.GatewayOverheadNs != nil {
+			t.Fatalf("timing = %+v, want the duration without an overhead", trace.Timing)
+		}
+		if trace.Transformations != nil || trace.Missing["transformations"] == "" || trace.Missing["flags.moderation"] == "" {
+			t.Fatalf("missing = %v, want reasons for the transformations and moderation of an entry without details", trace.Missing)
+		}
+		if trace.Flags.Anomalies != nil || trace.Missing["flags.anomalies"] != "anomaly detection is disabled" {
+			t.Fatalf("anomalies = %+v, missing %q", trace.Flags.Anomalies, trace.Missing["flags.anomalies"])
+		}
+	})
+
+	t.Run("usage without audit", func(t *testing.T) {
+		usageReader := &mockUsageReader{usageLog: &usage.UsageLogResult{Entries: []usage.UsageLogEntry{
+			{ID: "usage-1", RequestID: "req-1", Model: "gpt-4o", Provider: "openai", Endpoint: "/v1/chat/completions", TotalTokens: 10},
+		}}}
+		h := NewHandler(usageReader, nil, WithAuditReader(&mockAuditReader{logErr: errors.New("database is locked")}))
+
+		rec, trace := getRequestTrace(t, h, "req-1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if trace.Audit != nil || trace.Missing["audit"] != "audit log lookup failed" || len(trace.Attempts) != 0 {
+			t.Fatalf("audit = %+v, missing %q; want null with the lookup failure", trace.Audit, trace.Missing["audit"])
+		}
+		if trace.Route == nil || trace.Route.Source != "usage" || trace.Route.ResolvedModel != "gpt-4o" || trace.Route.Path != "/v1/chat/completions" {
+			t.Fatalf("route = %+v, want the route from the usage record", trace.Route)
+		}
+		for _, section := range []string{"timing", "transformations", "flags.moderation"} {
+			if trace.Missing[section] == "" {
+				t.Fatalf("missing = %v, want a reason for %s", trace.Missing, section)
+			}
+		}
+		if !strings.Contains(rec.Body.String(), `"timing":null`) || !strings.Contains(rec.Body.String(), `"transformations":null`) {
+			t.Fatalf("body = %s, want explicit nulls", rec.Body.String())
+		}
+	})
+}
+
+func TestRequestTrace_NotFound(t *testing.T) {
+	h := NewHandler(
+		&mockUsageReader{usageLog: &usage.UsageLogResult{Entries: []usage.UsageLogEntry{}}},
+		nil,
+		WithAuditReader(&mockAuditReader{logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{}}}),
+	)
+	if rec, _ := getRequestTrace(t, h, "req-missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	failing := NewHandler(nil, nil, WithAuditReader(&mockAuditReader{logErr: errors.New("database is locked")}))
+	if rec, _ := getRequestTrace(t, failing, "req-1"); rec.Code == http.StatusOK || rec.Code == http.StatusNotFound {
+		t.Fatalf("expected the lookup error, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+	"gomodel/internal/sanitize"
+)
+
+func getResponseIDMapping(t *testing.T, h *Handler, id string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/api/v1/response-ids/"+id, nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: id}})
+
+	if err := h.ResponseIDMapping(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func TestResponseIDMapping_UnavailableWhenDisabled(t *testing.T) {
+	if rec := getResponseIDMapping(t, NewHandler(nil, nil), "gm-1"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestResponseIDMapping_ResolvesGatewayID(t *testing.T) {
+	sanitizer := sanitize.New(config.ResponseSanitizationConfig{Enabled: true})
+	h := NewHandler(nil, nil, WithResponseSanitization(sanitizer))
+
+	body, ok := sanitizer.Response("req-1", "openai-main").Rewrite([]byte(`{"id":"chatcmpl-upstream","model":"gpt-4o"}`))
+	if !ok {
+		t.Fatal("Rewrite() ok = false")
+	}
+	gatewayID := gjson.GetBytes(body, "id").String()
+
+	rec := getResponseIDMapping(t, h, gatewayID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got sanitize.Mapping
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.GatewayID != gatewayID || got.ProviderID != "chatcmpl-upstream" || got.Provider != "openai-main" || got.Model != "gpt-4o" || got.RequestID != "req-1" {
+		t.Fatalf("mapping = %+v, want chatcmpl-upstream from openai-main for req-1", got)
+	}
+
+	if rec := getResponseIDMapping(t, h, "gm-unknown"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown id, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func newRoleScopedAdminServer(role authkeys.Role, entry *auditlog.LogEntry) (*echo.Echo, *auditlog.LogEntry) {
+	h := NewHandler(
+		&mockUsageReader{summary: &usage.UsageSummary{TotalRequests: 3}},
+		nil,
+		WithAuditReader(&mockAuditReader{
+			logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{*entry}, Total: 1},
+		}),
+	)
+
+	auditEntry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			c.Set(string(auditlog.LogEntryKey), auditEntry)
+			c.Set(authkeys.RoleContextKey, role)
+			return next(c)
+		}
+	})
+	adminAPI := e.Group("/admin/api/v1", RequireRole)
+	adminAPI.GET("/usage/summary", h.UsageSummary)
+	adminAPI.GET("/audit/log", h.AuditLog)
+	adminAPI.DELETE("/audit/:id", h.DeleteAuditLog)
+	adminAPI.GET("/auth-keys", h.ListAuthKeys)
+	return e, auditEntry
+}
+
+func TestRequireRole_RouteClasses(t *testing.T) {
+	routes := []struct {
+		class  string
+		method string
+		path   string
+	}{
+		{class: "usage", method: http.MethodGet, path: "/admin/api/v1/usage/summary"},
+		{class: "audit metadata", method: http.MethodGet, path: "/admin/api/v1/audit/log"},
+		{class: "mutating", method: http.MethodDelete, path: "/admin/api/v1/audit/log-1"},
+		{class: "unlisted read", method: http.MethodGet, path: "/admin/api/v1/auth-keys"},
+	}
+	allowed := map[authkeys.Role]map[string]bool{
+		authkeys.RoleReadUsage:         {"usage": true},
+		authkeys.RoleReadAuditMetadata: {"usage": true, "audit metadata": true},
+		authkeys.RoleAdmin:             {"usage": true, "audit metadata": true, "mutating": true, "unlisted read": true},
+	}
+
+	for role, classes := range allowed {
+		for _, route := range routes {
+			t.Run(string(role)+" "+route.class, func(t *testing.T) {
+				e, auditEntry := newRoleScopedAdminServer(role, &auditlog.LogEntry{ID: "log-1"})
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(route.method, route.path, nil))
+
+				if !classes[route.class] {
+					if rec.Code != http.StatusForbidden {
+						t.Fatalf("status = %d, want 403; body=%s", rec.Code, rec.Body.String())
+					}
+					var body map[string]map[string]any
+					if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+						t.Fatalf("failed to decode error body: %v", err)
+					}
+					if body["error"]["type"] != string(core.ErrorTypeAuthentication) || body["error"]["code"] != "insufficient_role" {
+						t.Fatalf("error = %v, want authentication_error with insufficient_role code", body["error"])
+					}
+					if auditEntry.ErrorType != string(core.ErrorTypeAuthentication) {
+						t.Fatalf("audit error type = %q, want the denial recorded", auditEntry.ErrorType)
+					}
+					return
+				}
+				if rec.Code == http.StatusForbidden || rec.Code == http.StatusUnauthorized {
+					t.Fatalf("status = %d, want access granted; body=%s", rec.Code, rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestRequireRole_AuditBodiesRequireAdmin(t *testing.T) {
+	entry := &auditlog.LogEntry{
+		ID:             "log-1",
+		RequestedModel: "gpt-4o",
+		Data: &auditlog.LogData{
+			RequestHeaders: map[string]string{"X-Test": "1"},
+			RequestBody:    map[string]any{"prompt": "secret"},
+			ResponseBody:   map[string]any{"answer": "secret"},
+			ErrorMessage:   "upstream failed",
+		},
+	}
+
+	tests := []struct {
+		role       authkeys.Role
+		wantBodies bool
+	}{
+		{role: authkeys.RoleReadAuditMetadata},
+		{role: authkeys.RoleAdmin, wantBodies: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			e, _ := newRoleScopedAdminServer(tt.role, entry)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/api/v1/audit/log", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+			}
+
+			var result auditlog.LogListResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			data := result.Entries[0].Data
+			if result.Entries[0].RequestedModel != "gpt-4o" || data.ErrorMessage != "upstream failed" {
+				t.Fatalf("entry = %+v, want metadata kept", result.Entries[0])
+			}
+			hasBodies := data.RequestBody != nil || data.ResponseBody != nil || data.RequestHeaders != nil
+			if hasBodies != tt.wantBodies {
+				t.Fatalf("bodies present = %v, want %v", hasBodies, tt.wantBodies)
+			}
+		})
+	}
+	if entry.Data.RequestBody == nil {
+		t.Fatal("stripping bodies mutated the stored entry")
+	}
+}
+
+func TestMinimumRole_DefaultsToAdmin(t *testing.T) {
+	if got := MinimumRole(http.MethodPost, "/admin/api/v1/usage/summary"); got != authkeys.RoleAdmin {
+		t.Fatalf("MinimumRole(POST usage) = %q, want admin", got)
+	}
+	if got := MinimumRole(http.MethodGet, "/admin/api/v1/workflows"); got != authkeys.RoleAdmin {
+		t.Fatalf("MinimumRole(GET workflows) = %q, want admin", got)
+	}
+	if got := MinimumRole(http.MethodGet, "/admin/api/v1/usage/daily"); got != authkeys.RoleReadUsage {
+		t.Fatalf("MinimumRole(GET usage/daily) = %q, want read_usage", got)
+	}
+}
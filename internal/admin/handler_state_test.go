@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/statebundle"
+)
+
+func TestExportStateAndImportState(t *testing.T) {
+	h := newAliasHandler(t)
+	e := echo.New()
+
+	putReq := httptest.NewRequest(http.MethodPut, "/admin/api/v1/aliases/smart", bytes.NewBufferString(`{"target_model":"gpt-4o"}`))
+	putReq.Header.Set("Content-Type", "application/json")
+	putCtx := e.NewContext(putReq, httptest.NewRecorder())
+	putCtx.SetPathValues(echo.PathValues{{Name: "name", Value: "smart"}})
+	if err := h.UpsertAlias(putCtx); err != nil {
+		t.Fatalf("UpsertAlias() error = %v", err)
+	}
+
+	exportRec := httptest.NewRecorder()
+	if err := h.ExportState(e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/api/v1/state/export", nil), exportRec)); err != nil {
+		t.Fatalf("ExportState() error = %v", err)
+	}
+	if exportRec.Code != http.StatusOK {
+		t.Fatalf("export status = %d, want 200", exportRec.Code)
+	}
+	var bundle map[string]json.RawMessage
+	if err := json.Unmarshal(exportRec.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if string(bundle["guardrails"]) != "null" {
+		t.Fatalf("guardrails section = %s, want null for a disabled feature", bundle["guardrails"])
+	}
+
+	target := newAliasHandler(t)
+	importReq := httptest.NewRequest(http.MethodPost, "/admin/api/v1/state/import?mode=replace&dry_run=true", bytes.NewReader(exportRec.Body.Bytes()))
+	importRec := httptest.NewRecorder()
+	importCtx := e.NewContext(importReq, importRec)
+	if err := target.ImportState(importCtx); err != nil {
+		t.Fatalf("ImportState() error = %v", err)
+	}
+	if importRec.Code != http.StatusOK {
+		t.Fatalf("import status = %d, want 200: %s", importRec.Code, importRec.Body.String())
+	}
+	var report statebundle.Report
+	if err := json.Unmarshal(importRec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if !report.DryRun || report.Applied || report.Mode != statebundle.ModeReplace {
+		t.Fatalf("report = %+v, want unapplied replace dry run", report)
+	}
+	if len(report.Sections) != 1 || len(report.Sections[0].Created) != 1 {
+		t.Fatalf("report sections = %+v, want one created alias", report.Sections)
+	}
+	if len(target.aliases.List()) != 0 {
+		t.Fatal("dry run created aliases")
+	}
+}
+
+func TestImportStateRejectsNewerSchemaVersion(t *testing.T) {
+	h := newAliasHandler(t)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api/v1/state/import", bytes.NewBufferString(`{"schema_version":99}`))
+	rec := httptest.NewRecorder()
+	if err := h.ImportState(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("ImportState() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
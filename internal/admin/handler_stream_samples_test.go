@@ -0,0 +1,238 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+)
+
+// memoryStreamSampleStore is an in-memory auditlog.StreamSampleStore.
+type memoryStreamSampleStore struct {
+	samples    map[string]auditlog.StreamSample
+	lastParams auditlog.StreamSampleQueryParams
+	deleteErr  error
+}
+
+func newMemoryStreamSampleStore(samples ...auditlog.StreamSample) *memoryStreamSampleStore {
+	store := &memoryStreamSampleStore{samples: make(map[string]auditlog.StreamSample)}
+	for _, sample := range samples {
+		store.samples[sample.ID] = sample
+	}
+	return store
+}
+
+func (s *memoryStreamSampleStore) CreateStreamSample(_ context.Context, sample *auditlog.StreamSample) error {
+	s.samples[sample.ID] = *sample
+	return nil
+}
+
+func (s *memoryStreamSampleStore) GetStreamSample(_ context.Context, id string) (*auditlog.StreamSample, error) {
+	sample, ok := s.samples[id]
+	if !ok {
+		return nil, auditlog.ErrStreamSampleNotFound
+	}
+	return &sample, nil
+}
+
+func (s *memoryStreamSampleStore) ListStreamSamples(_ context.Context, params auditlog.StreamSampleQueryParams) (*auditlog.StreamSampleListResult, error) {
+	s.lastParams = params
+	result := &auditlog.StreamSampleListResult{Samples: []auditlog.StreamSample{}}
+	for _, sample := range s.samples {
+		result.Samples = append(result.Samples, sample)
+	}
+	result.Total = len(result.Samples)
+	return result, nil
+}
+
+func (s *memoryStreamSampleStore) DeleteStreamSample(_ context.Context, id string) (int64, error) {
+	sample, ok := s.samples[id]
+	if !ok {
+		return 0, auditlog.ErrStreamSampleNotFound
+	}
+	delete(s.samples, id)
+	return sample.Bytes, nil
+}
+
+func (s *memoryStreamSampleStore) DeleteStreamSamplesForLog(_ context.Context, logID string) (int64, error) {
+	if s.deleteErr != nil {
+		return 0, s.deleteErr
+	}
+	var freed int64
+	for id, sample := range s.samples {
+		if sample.LogID == logID {
+			freed += sample.Bytes
+			delete(s.samples, id)
+		}
+	}
+	return freed, nil
+}
+
+func (s *memoryStreamSampleStore) StreamSampleUsage(_ context.Context, _ time.Time) (int, int64, error) {
+	return 0, 0, nil
+}
+
+func newTestStreamSampler(t *testing.T, store auditlog.StreamSampleStore) *auditlog.StreamSampler {
+	t.Helper()
+	sampler, err := auditlog.NewStreamSampler(context.Background(), store, auditlog.StreamSampleConfig{Rate: 1})
+	if err != nil {
+		t.Fatalf("NewStreamSampler() error = %v", err)
+	}
+	return sampler
+}
+
+func TestStreamSamples_Unavailable(t *testing.T) {
+	h := NewHandler(nil, nil)
+
+	c, rec := newHandlerContext("/admin/api/v1/stream-samples")
+	if err := h.StreamSamples(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestStreamSamples_ListsWithoutText(t *testing.T) {
+	store := newMemoryStreamSampleStore(auditlog.StreamSample{
+		ID: "s1", LogID: "log-1", RequestedModel: "gpt-4o", Bytes: 10, Prompt: "prompt", Response: "response",
+	})
+	h := NewHandler(nil, nil, WithStreamSamples(newTestStreamSampler(t, store)))
+
+	c, rec := newHandlerContext("/admin/api/v1/stream-samples?start_date=2026-03-01&end_date=2026-03-02&model=gpt-4o&limit=5&offset=10")
+	if err := h.StreamSamples(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	params := store.lastParams
+	if params.Model != "gpt-4o" || params.Limit != 5 || params.Offset != 10 {
+		t.Fatalf("unexpected query params: %+v", params)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !params.StartDate.Equal(want) {
+		t.Fatalf("start date = %v, want %v", params.StartDate, want)
+	}
+
+	var result auditlog.StreamSampleListResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Total != 1 || result.Samples[0].Prompt != "" || result.Samples[0].Response != "" {
+		t.Fatalf("expected listing without text, got %+v", result)
+	}
+}
+
+func TestStreamSamples_RejectsInvalidDate(t *testing.T) {
+	h := NewHandler(nil, nil, WithStreamSamples(newTestStreamSampler(t, newMemoryStreamSampleStore())))
+
+	c, rec := newHandlerContext("/admin/api/v1/stream-samples?start_date=yesterday")
+	if err := h.StreamSamples(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestStreamSample_ReturnsFullText(t *testing.T) {
+	store := newMemoryStreamSampleStore(auditlog.StreamSample{ID: "s1", LogID: "log-1", Prompt: "prompt", Response: "response"})
+	h := NewHandler(nil, nil, WithStreamSamples(newTestStreamSampler(t, store)))
+
+	c, rec := newHandlerContext("/admin/api/v1/stream-samples/s1")
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: "s1"}})
+	if err := h.StreamSample(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var sample auditlog.StreamSample
+	if err := json.Unmarshal(rec.Body.Bytes(), &sample); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if sample.Prompt != "prompt" || sample.Response != "response" {
+		t.Fatalf("expected full text, got %+v", sample)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/stream-samples/missing")
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+	if err := h.StreamSample(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestDeleteStreamSample(t *testing.T) {
+	store := newMemoryStreamSampleStore(auditlog.StreamSample{ID: "s1", LogID: "log-1"})
+	h := NewHandler(nil, nil, WithStreamSamples(newTestStreamSampler(t, store)))
+
+	c, rec := newAuditMutationContext(http.MethodDelete, "/admin/api/v1/stream-samples/s1", "s1")
+	if err := h.DeleteStreamSample(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if _, ok := store.samples["s1"]; ok {
+		t.Fatal("expected sample to be deleted")
+	}
+
+	c, rec = newAuditMutationContext(http.MethodDelete, "/admin/api/v1/stream-samples/s1", "s1")
+	if err := h.DeleteStreamSample(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestAuditLogMutations_DeleteStreamSamples(t *testing.T) {
+	store := newMemoryStreamSampleStore(
+		auditlog.StreamSample{ID: "s1", LogID: "log-1"},
+		auditlog.StreamSample{ID: "s2", LogID: "log-2"},
+		auditlog.StreamSample{ID: "s3", LogID: "log-3"},
+	)
+	reader := &mockAuditReader{redactResult: &auditlog.LogEntry{ID: "log-1", Redacted: true}}
+	h := NewHandler(nil, nil, WithAuditReader(reader), WithStreamSamples(newTestStreamSampler(t, store)))
+
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	c, rec = newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/log-2", "log-2")
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if len(store.samples) != 1 || store.samples["s3"].LogID != "log-3" {
+		t.Fatalf("expected only the unrelated sample to remain, got %+v", store.samples)
+	}
+
+	store.deleteErr = errors.New("database down")
+	reader.lastMutationID = ""
+	c, rec = newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-3/redact", "log-3")
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if reader.lastMutationID != "" {
+		t.Fatal("expected redaction to stop when samples cannot be deleted")
+	}
+}
@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	_ "modernc.org/sqlite"
+
+	"gomodel/internal/prompttemplates"
+)
+
+func newTemplateHandler(t *testing.T) *Handler {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := prompttemplates.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	service, err := prompttemplates.NewService(store)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	return NewHandler(nil, nil, WithPromptTemplates(service))
+}
+
+func newTemplateContext(method, path, body string, params echo.PathValues) (*echo.Context, *httptest.ResponseRecorder) {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetPathValues(params)
+	return c, rec
+}
+
+func TestTemplateVersionLifecycle(t *testing.T) {
+	h := newTemplateHandler(t)
+	name := echo.PathValues{{Name: "name", Value: "support"}}
+	body := `{"description":"support bot","messages":[{"role":"system","content":"You support {{.product}}."}],"variables":[{"name":"product","required":true}]}`
+
+	for want := 1; want <= 2; want++ {
+		c, rec := newTemplateContext(http.MethodPost, "/admin/api/v1/templates/support", body, name)
+		if err := h.CreateTemplateVersion(c); err != nil {
+			t.Fatalf("CreateTemplateVersion() error = %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d, want 201; body = %s", rec.Code, rec.Body.String())
+		}
+		var created prompttemplates.Template
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+		if created.Name != "support" || created.Version != want {
+			t.Fatalf("created = %s v%d, want support v%d", created.Name, created.Version, want)
+		}
+	}
+
+	listCtx, listRec := newHandlerContext("/admin/api/v1/templates")
+	if err := h.ListTemplates(listCtx); err != nil {
+		t.Fatalf("ListTemplates() error = %v", err)
+	}
+	var views []prompttemplates.View
+	if err := json.Unmarshal(listRec.Body.Bytes(), &views); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(views) != 1 || views[0].Version != 2 || len(views[0].Versions) != 2 {
+		t.Fatalf("views = %+v, want support with versions 1 and 2", views)
+	}
+
+	versionPath := echo.PathValues{{Name: "name", Value: "support"}, {Name: "version", Value: "1"}}
+	getCtx, getRec := newTemplateContext(http.MethodGet, "/admin/api/v1/templates/support/versions/1", "", versionPath)
+	if err := h.GetTemplateVersion(getCtx); err != nil {
+		t.Fatalf("GetTemplateVersion() error = %v", err)
+	}
+	if getRec.Code != http.StatusOK {
+		t.Fatalf("get status = %d, want 200", getRec.Code)
+	}
+
+	deleteCtx, deleteRec := newTemplateContext(http.MethodDelete, "/admin/api/v1/templates/support/versions/1", "", versionPath)
+	if err := h.DeleteTemplateVersion(deleteCtx); err != nil {
+		t.Fatalf("DeleteTemplateVersion() error = %v", err)
+	}
+	if deleteRec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want 204", deleteRec.Code)
+	}
+
+	missingCtx, missingRec := newTemplateContext(http.MethodGet, "/admin/api/v1/templates/support/versions/1", "", versionPath)
+	if err := h.GetTemplateVersion(missingCtx); err != nil {
+		t.Fatalf("GetTemplateVersion() error = %v", err)
+	}
+	if missingRec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted status = %d, want 404", missingRec.Code)
+	}
+}
+
+func TestCreateTemplateVersionRejectsInvalidTemplates(t *testing.T) {
+	h := newTemplateHandler(t)
+	c, rec := newTemplateContext(http.MethodPost, "/admin/api/v1/templates/support",
+		`{"messages":[{"role":"user","content":"{{.product"}]}`,
+		echo.PathValues{{Name: "name", Value: "support"}})
+
+	if err := h.CreateTemplateVersion(c); err != nil {
+		t.Fatalf("CreateTemplateVersion() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetTemplateVersionRejectsInvalidVersion(t *testing.T) {
+	h := newTemplateHandler(t)
+	c, rec := newTemplateContext(http.MethodGet, "/admin/api/v1/templates/support/versions/zero", "",
+		echo.PathValues{{Name: "name", Value: "support"}, {Name: "version", Value: "zero"}})
+
+	if err := h.GetTemplateVersion(c); err != nil {
+		t.Fatalf("GetTemplateVersion() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestTemplatesEndpointsReturn503WhenServiceUnavailable(t *testing.T) {
+	h := NewHandler(nil, nil)
+
+	c, rec := newHandlerContext("/admin/api/v1/templates")
+	if err := h.ListTemplates(c); err != nil {
+		t.Fatalf("ListTemplates() error = %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
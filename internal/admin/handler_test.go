@@ -7,15 +7,22 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v5"
 
+	"gomodel/config"
 	"gomodel/internal/auditlog"
 	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/experiments"
+	"gomodel/internal/modelgroups"
+	"gomodel/internal/promptcache"
 	"gomodel/internal/providers"
+	"gomodel/internal/scoreboard"
 	"gomodel/internal/usage"
 )
 
@@ -25,28 +32,47 @@ type mockUsageReader struct {
 	daily             []usage.DailyUsage
 	modelUsage        []usage.ModelUsage
 	userPathUsage     []usage.UserPathUsage
+	experimentUsage   []usage.ExperimentVariantUsage
+	labelUsage        []usage.LabelUsage
+	contentStats      []usage.ContentStats
 	usageLog          *usage.UsageLogResult
 	cacheOverview     *usage.CacheOverview
 	lastUsageLog      usage.UsageLogParams
 	lastCacheOverview usage.UsageQueryParams
+	lastLabelParams   usage.UsageQueryParams
+	lastLabel         string
+	lastContentStats  usage.UsageQueryParams
 	summaryErr        error
 	dailyErr          error
 	modelUsageErr     error
 	userPathUsageErr  error
+	experimentErr     error
 	usageLogErr       error
 	cacheErr          error
 }
 
 type mockAuditReader struct {
-	logResult           *auditlog.LogListResult
-	logErr              error
-	lastQuery           auditlog.LogQueryParams
-	logByID             *auditlog.LogEntry
-	logByIDErr          error
-	conversationResult  *auditlog.ConversationResult
-	conversationErr     error
-	lastConversationID  string
-	lastConversationLim int
+	logResult            *auditlog.LogListResult
+	logErr               error
+	lastQuery            auditlog.LogQueryParams
+	logByID              *auditlog.LogEntry
+	logByIDErr           error
+	logByIDCalls         int
+	logsByResponseID     map[string]*auditlog.LogEntry
+	conversationResult   *auditlog.ConversationResult
+	conversationErr      error
+	lastConversationID   string
+	lastConversationLim  int
+	errorSummary         *auditlog.ErrorSummary
+	errorSummaryErr      error
+	lastErrorSummary     auditlog.ErrorSummaryParams
+	guardrailCanaryStats *auditlog.GuardrailCanaryStats
+	lastGuardrailCanary  auditlog.GuardrailCanaryParams
+	redactResult         *auditlog.LogEntry
+	redactErr            error
+	deleteErr            error
+	lastMutationID       string
+	lastMutationActor    string
 }
 
 type mockRuntimeRefresher struct {
@@ -88,6 +114,25 @@ func (m *mockUsageReader) GetUsageByUserPath(_ context.Context, _ usage.UsageQue
 	return m.userPathUsage, nil
 }
 
+func (m *mockUsageReader) GetUsageByExperiment(_ context.Context, _ usage.UsageQueryParams) ([]usage.ExperimentVariantUsage, error) {
+	if m.experimentErr != nil {
+		return nil, m.experimentErr
+	}
+	return m.experimentUsage, nil
+}
+
+func (m *mockUsageReader) GetUsageByLabel(_ context.Context, params usage.UsageQueryParams, label string) ([]usage.LabelUsage, error) {
+	m.lastLabelParams = params
+	m.lastLabel = label
+	return m.labelUsage, nil
+}
+
+func (m *mockUsageReader) GetUsageByUser(_ context.Context, params usage.UsageQueryParams) ([]usage.LabelUsage, error) {
+	m.lastLabelParams = params
+	m.lastLabel = usage.UserGroupLabel
+	return m.labelUsage, nil
+}
+
 func (m *mockUsageReader) GetUsageLog(_ context.Context, params usage.UsageLogParams) (*usage.UsageLogResult, error) {
 	m.lastUsageLog = params
 	if m.usageLogErr != nil {
@@ -96,6 +141,11 @@ func (m *mockUsageReader) GetUsageLog(_ context.Context, params usage.UsageLogPa
 	return m.usageLog, nil
 }
 
+func (m *mockUsageReader) GetContentStats(_ context.Context, params usage.UsageQueryParams) ([]usage.ContentStats, error) {
+	m.lastContentStats = params
+	return m.contentStats, nil
+}
+
 func (m *mockUsageReader) GetCacheOverview(_ context.Context, params usage.UsageQueryParams) (*usage.CacheOverview, error) {
 	m.lastCacheOverview = params
 	if m.cacheErr != nil {
@@ -113,12 +163,17 @@ func (m *mockAuditReader) GetLogs(_ context.Context, params auditlog.LogQueryPar
 }
 
 func (m *mockAuditReader) GetLogByID(_ context.Context, _ string) (*auditlog.LogEntry, error) {
+	m.logByIDCalls++
 	if m.logByIDErr != nil {
 		return nil, m.logByIDErr
 	}
 	return m.logByID, nil
 }
 
+func (m *mockAuditReader) GetLogByResponseID(_ context.Context, responseID string) (*auditlog.LogEntry, error) {
+	return m.logsByResponseID[responseID], nil
+}
+
 func (m *mockAuditReader) GetConversation(_ context.Context, logID string, limit int) (*auditlog.ConversationResult, error) {
 	m.lastConversationID = logID
 	m.lastConversationLim = limit
@@ -128,6 +183,34 @@ func (m *mockAuditReader) GetConversation(_ context.Context, logID string, limit
 	return m.conversationResult, nil
 }
 
+func (m *mockAuditReader) GetErrorSummary(_ context.Context, params auditlog.ErrorSummaryParams) (*auditlog.ErrorSummary, error) {
+	m.lastErrorSummary = params
+	if m.errorSummaryErr != nil {
+		return nil, m.errorSummaryErr
+	}
+	return m.errorSummary, nil
+}
+
+func (m *mockAuditReader) GetGuardrailCanaryStats(_ context.Context, params auditlog.GuardrailCanaryParams) (*auditlog.GuardrailCanaryStats, error) {
+	m.lastGuardrailCanary = params
+	return m.guardrailCanaryStats, nil
+}
+
+func (m *mockAuditReader) RedactLog(_ context.Context, id, actor string) (*auditlog.LogEntry, error) {
+	m.lastMutationID = id
+	m.lastMutationActor = actor
+	if m.redactErr != nil {
+		return nil, m.redactErr
+	}
+	return m.redactResult, nil
+}
+
+func (m *mockAuditReader) DeleteLog(_ context.Context, id, actor string) error {
+	m.lastMutationID = id
+	m.lastMutationActor = actor
+	return m.deleteErr
+}
+
 // handlerMockProvider implements core.Provider for ListModels registry testing.
 type handlerMockProvider struct {
 	models *core.ModelsResponse
@@ -577,6 +660,73 @@ func TestUsageByUserPath_Error(t *testing.T) {
 	}
 }
 
+// --- UsageGroups handler tests ---
+
+func TestUsageGroups_GroupsByLabelWithFilters(t *testing.T) {
+	cost := 1.5
+	reader := &mockUsageReader{
+		labelUsage: []usage.LabelUsage{
+			{Label: "team", Value: "growth", Requests: 3, TotalTokens: 90, TotalCost: &cost},
+		},
+	}
+	h := NewHandler(reader, nil)
+	c, rec := newHandlerContext("/admin/api/v1/usage/groups?group_by=label:Team&label.env=prod")
+
+	if err := h.UsageGroups(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reader.lastLabel != "team" {
+		t.Errorf("label = %q, want team", reader.lastLabel)
+	}
+	if got := reader.lastLabelParams.Labels["env"]; got != "prod" {
+		t.Errorf("label filter env = %q, want prod", got)
+	}
+	var groups []usage.LabelUsage
+	if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Value != "growth" || groups[0].Requests != 3 {
+		t.Fatalf("groups = %+v, want the growth group", groups)
+	}
+}
+
+func TestUsageGroups_GroupsByUser(t *testing.T) {
+	reader := &mockUsageReader{
+		labelUsage: []usage.LabelUsage{
+			{Label: usage.UserGroupLabel, Value: "0123456789abcdef", Requests: 2},
+		},
+	}
+	h := NewHandler(reader, nil)
+	c, rec := newHandlerContext("/admin/api/v1/usage/groups?group_by=user")
+
+	if err := h.UsageGroups(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reader.lastLabel != usage.UserGroupLabel {
+		t.Errorf("grouped by %q, want user", reader.lastLabel)
+	}
+}
+
+func TestUsageGroups_RejectsInvalidGroupBy(t *testing.T) {
+	for _, query := range []string{"", "?group_by=model", "?group_by=label:", "?group_by=label:bad%20key", "?group_by=label:team&label.bad%20key=x"} {
+		h := NewHandler(&mockUsageReader{}, nil)
+		c, rec := newHandlerContext("/admin/api/v1/usage/groups" + query)
+
+		if err := h.UsageGroups(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("query %q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
 // --- UsageLog handler tests ---
 
 func TestUsageLog_NilReader(t *testing.T) {
@@ -729,6 +879,36 @@ func TestUsageLog_WithFilters(t *testing.T) {
 	}
 }
 
+func TestUsageLog_FiltersByUserHash(t *testing.T) {
+	reader := &mockUsageReader{usageLog: &usage.UsageLogResult{Entries: []usage.UsageLogEntry{}}}
+	h := NewHandler(reader, nil)
+	c, rec := newHandlerContext("/admin/api/v1/usage/log?user_hash=0123456789ABCDEF")
+
+	if err := h.UsageLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if reader.lastUsageLog.UserHash != "0123456789abcdef" {
+		t.Errorf("user_hash filter = %q, want 0123456789abcdef", reader.lastUsageLog.UserHash)
+	}
+}
+
+func TestUsageLog_RejectsInvalidUserHash(t *testing.T) {
+	for _, hash := range []string{"abc", "zzzzzzzzzzzzzzzz", "0123456789abcdef00"} {
+		h := NewHandler(&mockUsageReader{}, nil)
+		c, rec := newHandlerContext("/admin/api/v1/usage/log?user_hash=" + hash)
+
+		if err := h.UsageLog(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("user_hash %q: expected 400, got %d", hash, rec.Code)
+		}
+	}
+}
+
 // --- AuditLog handler tests ---
 
 func TestAuditLog_NilReader(t *testing.T) {
@@ -865,7 +1045,7 @@ func TestAuditLog_WithFilters(t *testing.T) {
 	}
 
 	h := NewHandler(nil, nil, WithAuditReader(reader))
-	c, rec := newHandlerContext("/admin/api/v1/audit/log?model=gpt-4&provider=openai&method=post&path=/v1/chat/completions&user_path=/team&error_type=provider_error&status_code=502&stream=true&search=timeout&limit=10&offset=5")
+	c, rec := newHandlerContext("/admin/api/v1/audit/log?model=gpt-4&provider=openai&method=post&path=/v1/chat/completions&user_path=/team&error_type=provider_error&response_id=chatcmpl-abc&status_code=502&upstream_status_code=529&stream=true&search=timeout&limit=10&offset=5")
 
 	if err := h.AuditLog(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -892,9 +1072,15 @@ func TestAuditLog_WithFilters(t *testing.T) {
 	if reader.lastQuery.ErrorType != "provider_error" {
 		t.Errorf("expected error_type provider_error, got %q", reader.lastQuery.ErrorType)
 	}
+	if reader.lastQuery.ResponseID != "chatcmpl-abc" {
+		t.Errorf("expected response_id chatcmpl-abc, got %q", reader.lastQuery.ResponseID)
+	}
 	if reader.lastQuery.StatusCode == nil || *reader.lastQuery.StatusCode != 502 {
 		t.Errorf("expected status_code 502, got %+v", reader.lastQuery.StatusCode)
 	}
+	if reader.lastQuery.UpstreamStatusCode == nil || *reader.lastQuery.UpstreamStatusCode != 529 {
+		t.Errorf("expected upstream_status_code 529, got %+v", reader.lastQuery.UpstreamStatusCode)
+	}
 	if reader.lastQuery.Stream == nil || !*reader.lastQuery.Stream {
 		t.Errorf("expected stream filter true, got %+v", reader.lastQuery.Stream)
 	}
@@ -924,6 +1110,24 @@ func TestAuditLog_InvalidStatusCode(t *testing.T) {
 	}
 }
 
+func TestAuditLog_InvalidUpstreamStatusCode(t *testing.T) {
+	reader := &mockAuditReader{
+		logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{}},
+	}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newHandlerContext("/admin/api/v1/audit/log?upstream_status_code=overloaded")
+
+	if err := h.AuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+	if !containsString(rec.Body.String(), "upstream_status_code") {
+		t.Errorf("expected upstream_status_code in body, got: %s", rec.Body.String())
+	}
+}
+
 func TestAuditLog_InvalidStream(t *testing.T) {
 	reader := &mockAuditReader{
 		logResult: &auditlog.LogListResult{Entries: []auditlog.LogEntry{}},
@@ -957,6 +1161,173 @@ func TestAuditLog_Error(t *testing.T) {
 	}
 }
 
+// --- ErrorSummary handler tests ---
+
+func TestErrorSummary_NilReader(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/errors/summary?interval=hourly")
+
+	if err := h.ErrorSummary(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+
+	var result auditlog.ErrorSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if result.Interval != auditlog.ErrorIntervalHourly {
+		t.Errorf("expected hourly interval, got %q", result.Interval)
+	}
+	if result.Groups == nil || result.Series == nil || result.TotalErrors != 0 {
+		t.Errorf("expected empty groups and series, got %+v", result)
+	}
+}
+
+func TestErrorSummary_Success(t *testing.T) {
+	reader := &mockAuditReader{
+		errorSummary: &auditlog.ErrorSummary{
+			TotalErrors: 3,
+			Interval:    auditlog.ErrorIntervalHourly,
+			Groups: []auditlog.ErrorGroup{
+				{Provider: "openai-primary", Model: "gpt-5", ErrorType: "rate_limit_error", StatusCode: 429, Count: 3},
+			},
+			Series: []auditlog.ErrorSeriesPoint{
+				{Bucket: "2026-03-10T09:00:00Z", Count: 3},
+			},
+			TopMessages: []auditlog.ErrorMessageCount{
+				{Message: "retry after <n>s", Count: 3},
+			},
+		},
+	}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newHandlerContext("/admin/api/v1/errors/summary?start_date=2026-03-01&end_date=2026-03-10&interval=HOURLY&top_messages=5")
+
+	if err := h.ErrorSummary(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	params := reader.lastErrorSummary
+	if params.Interval != auditlog.ErrorIntervalHourly || params.TopMessages != 5 {
+		t.Errorf("unexpected params: %+v", params)
+	}
+	if params.StartDate.Format("2006-01-02") != "2026-03-01" || params.EndDate.Format("2006-01-02") != "2026-03-10" {
+		t.Errorf("unexpected date range: %v - %v", params.StartDate, params.EndDate)
+	}
+
+	var result auditlog.ErrorSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if result.TotalErrors != 3 || len(result.Groups) != 1 || len(result.Series) != 1 || len(result.TopMessages) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Groups[0].Provider != "openai-primary" || result.Groups[0].ErrorType != "rate_limit_error" {
+		t.Errorf("unexpected group: %+v", result.Groups[0])
+	}
+}
+
+func TestErrorSummary_NilSlicesBecomeEmpty(t *testing.T) {
+	reader := &mockAuditReader{errorSummary: &auditlog.ErrorSummary{Interval: auditlog.ErrorIntervalDaily}}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newHandlerContext("/admin/api/v1/errors/summary")
+
+	if err := h.ErrorSummary(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsString(rec.Body.String(), `"groups":[]`) || !containsString(rec.Body.String(), `"series":[]`) {
+		t.Errorf("expected empty arrays in body, got: %s", rec.Body.String())
+	}
+	if reader.lastErrorSummary.Interval != auditlog.ErrorIntervalDaily {
+		t.Errorf("expected daily default interval, got %q", reader.lastErrorSummary.Interval)
+	}
+}
+
+func TestErrorSummary_InvalidParams(t *testing.T) {
+	for _, query := range []string{"interval=weekly", "top_messages=abc", "top_messages=51", "top_messages=-1"} {
+		reader := &mockAuditReader{errorSummary: &auditlog.ErrorSummary{}}
+		h := NewHandler(nil, nil, WithAuditReader(reader))
+		c, rec := newHandlerContext("/admin/api/v1/errors/summary?" + query)
+
+		if err := h.ErrorSummary(c); err != nil {
+			t.Fatalf("%s: unexpected error: %v", query, err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+		if !containsString(rec.Body.String(), "invalid_request_error") {
+			t.Errorf("%s: expected invalid_request_error in body, got: %s", query, rec.Body.String())
+		}
+	}
+}
+
+func TestErrorSummary_Error(t *testing.T) {
+	reader := &mockAuditReader{
+		errorSummaryErr: core.NewProviderError("test", http.StatusBadGateway, "upstream failed", nil),
+	}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newHandlerContext("/admin/api/v1/errors/summary")
+
+	if err := h.ErrorSummary(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", rec.Code)
+	}
+}
+
+func getAuditLogByResponseID(t *testing.T, h *Handler, id string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	c, rec := newHandlerContext("/admin/api/v1/audit/by-response-id/" + id)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: id}})
+	if err := h.AuditLogByResponseID(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return rec
+}
+
+func TestAuditLogByResponseID_UnavailableWithoutReader(t *testing.T) {
+	if rec := getAuditLogByResponseID(t, NewHandler(nil, nil), "chatcmpl-abc"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestAuditLogByResponseID_HitAndMiss(t *testing.T) {
+	entry := &auditlog.LogEntry{
+		ID:                 "log-1",
+		RequestID:          "req-1",
+		Provider:           "anthropic",
+		ResponseID:         "resp_123",
+		ProviderResponseID: "msg_01",
+	}
+	reader := &mockAuditReader{logsByResponseID: map[string]*auditlog.LogEntry{"resp_123": entry, "msg_01": entry}}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+
+	for _, id := range []string{"resp_123", "msg_01"} {
+		rec := getAuditLogByResponseID(t, h, id)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", id, rec.Code, rec.Body.String())
+		}
+		var got auditlog.LogEntry
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if got.ID != "log-1" || got.RequestID != "req-1" || got.ResponseID != "resp_123" || got.ProviderResponseID != "msg_01" {
+			t.Fatalf("%s: entry = %+v, want log-1 with both response IDs", id, got)
+		}
+	}
+
+	if rec := getAuditLogByResponseID(t, h, "chatcmpl-missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown response id, got %d", rec.Code)
+	}
+}
+
 func TestAuditConversation_NilReader(t *testing.T) {
 	h := NewHandler(nil, nil)
 	c, rec := newHandlerContext("/admin/api/v1/audit/conversation?log_id=log-1")
@@ -1067,91 +1438,321 @@ func TestAuditConversation_InvalidLimit(t *testing.T) {
 	}
 }
 
-func TestAuditConversation_Error(t *testing.T) {
-	reader := &mockAuditReader{
-		conversationErr: core.NewProviderError("test", http.StatusBadGateway, "upstream failed", nil),
-	}
-	h := NewHandler(nil, nil, WithAuditReader(reader))
-	c, rec := newHandlerContext("/admin/api/v1/audit/conversation?log_id=log-1")
+// --- Audit redaction handler tests ---
 
-	if err := h.AuditConversation(c); err != nil {
+func newAuditMutationContext(method, path, id string) (*echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer admin-key")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: id}})
+	return c, rec
+}
+
+func TestRedactAuditLog_NilReader(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
+
+	if err := h.RedactAuditLog(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if rec.Code != http.StatusBadGateway {
-		t.Errorf("expected 502, got %d", rec.Code)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
 	}
 }
 
-// --- ListModels handler tests ---
-
-func TestListModels_NilRegistry(t *testing.T) {
-	h := NewHandler(nil, nil)
-	c, rec := newHandlerContext("/admin/api/v1/models")
+func TestAuditLogMutations_ReaderWithoutRedaction(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(struct{ auditlog.Reader }{&mockAuditReader{}}))
 
-	if err := h.ListModels(c); err != nil {
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
+	if err := h.RedactAuditLog(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected 200, got %d", rec.Code)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("redact: expected 503, got %d", rec.Code)
 	}
-	if rec.Body.String() != "[]\n" {
-		t.Errorf("expected empty JSON array, got: %q", rec.Body.String())
+
+	c, rec = newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/log-1", "log-1")
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("delete: expected 503, got %d", rec.Code)
 	}
 }
 
-func TestListModels_WithModels(t *testing.T) {
-	registry := providers.NewModelRegistry()
-	mock := &handlerMockProvider{
-		models: &core.ModelsResponse{
-			Object: "list",
-			Data: []core.Model{
-				{ID: "gpt-4", Object: "model", OwnedBy: "openai"},
-				{ID: "claude-3", Object: "model", OwnedBy: "anthropic"},
+func TestRedactAuditLog_Success(t *testing.T) {
+	reader := &mockAuditReader{
+		redactResult: &auditlog.LogEntry{
+			ID:       "log-1",
+			Redacted: true,
+			Data: &auditlog.LogData{
+				RequestBody: auditlog.RedactedMarker,
+				Redaction:   &auditlog.RedactionSnapshot{RedactedAt: time.Now().UTC(), RedactedBy: "hash"},
 			},
 		},
 	}
-	registry.RegisterProviderWithType(mock, "test")
-	if err := registry.Initialize(context.Background()); err != nil {
-		t.Fatalf("failed to initialize registry: %v", err)
-	}
-
-	h := NewHandler(nil, registry)
-	c, rec := newHandlerContext("/admin/api/v1/models")
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
 
-	if err := h.ListModels(c); err != nil {
+	if err := h.RedactAuditLog(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if rec.Code != http.StatusOK {
-		t.Errorf("expected 200, got %d", rec.Code)
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if reader.lastMutationID != "log-1" {
+		t.Errorf("expected redaction of log-1, got %q", reader.lastMutationID)
+	}
+	if want := auditlog.HashAPIKey("Bearer admin-key"); reader.lastMutationActor != want {
+		t.Errorf("actor = %q, want %q", reader.lastMutationActor, want)
 	}
 
-	var models []providers.ModelWithProvider
-	if err := json.Unmarshal(rec.Body.Bytes(), &models); err != nil {
+	var entry map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &entry); err != nil {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
-	if len(models) != 2 {
-		t.Fatalf("expected 2 models, got %d", len(models))
-	}
-	// Should be sorted by model ID
-	if models[0].Model.ID != "claude-3" {
-		t.Errorf("expected first model to be claude-3, got %s", models[0].Model.ID)
+	if entry["redacted"] != true {
+		t.Errorf("expected redacted=true, got %v", entry["redacted"])
 	}
-	if models[1].Model.ID != "gpt-4" {
-		t.Errorf("expected second model to be gpt-4, got %s", models[1].Model.ID)
+}
+
+func TestRedactAuditLog_NotFound(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(&mockAuditReader{redactErr: auditlog.ErrNotFound}))
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/missing/redact", "missing")
+
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if models[0].ProviderType != "test" {
-		t.Errorf("expected provider type 'test', got %s", models[0].ProviderType)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
 	}
 }
 
-func TestListModels_EmptyRegistry(t *testing.T) {
-	// A registry with no providers initialized — ListModelsWithProvider returns nil
-	registry := providers.NewModelRegistry()
-
-	h := NewHandler(nil, registry)
-	c, rec := newHandlerContext("/admin/api/v1/models")
+func TestRedactAuditLog_AlreadyRedacted(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(&mockAuditReader{redactErr: auditlog.ErrAlreadyRedacted}))
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
 
-	if err := h.ListModels(c); err != nil {
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestRedactAuditLog_Error(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(&mockAuditReader{redactErr: errors.New("database down")}))
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/log-1/redact", "log-1")
+
+	if err := h.RedactAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestDeleteAuditLog_NilReader(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/log-1", "log-1")
+
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestDeleteAuditLog_Success(t *testing.T) {
+	reader := &mockAuditReader{}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/log-1", "log-1")
+
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+	if reader.lastMutationID != "log-1" || reader.lastMutationActor != auditlog.HashAPIKey("Bearer admin-key") {
+		t.Errorf("unexpected delete call: id=%q actor=%q", reader.lastMutationID, reader.lastMutationActor)
+	}
+}
+
+func TestDeleteAuditLog_NotFound(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(&mockAuditReader{deleteErr: auditlog.ErrNotFound}))
+	c, rec := newAuditMutationContext(http.MethodDelete, "/admin/api/v1/audit/missing", "missing")
+
+	if err := h.DeleteAuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestReEncryptAuditLogs_Unavailable(t *testing.T) {
+	for name, reader := range map[string]auditlog.Reader{
+		"plain reader":     &mockAuditReader{},
+		"no keys":          auditlog.NewDecryptingReader(&mockAuditReader{}, nil),
+		"storage disabled": nil,
+	} {
+		h := NewHandler(nil, nil, WithAuditReader(reader))
+		c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/re-encrypt", "")
+
+		if err := h.ReEncryptAuditLogs(c); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected 503, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestReEncryptAuditLogs_InvalidLimit(t *testing.T) {
+	h := NewHandler(nil, nil, WithAuditReader(auditlog.NewDecryptingReader(&mockAuditReader{}, nil)))
+	c, rec := newAuditMutationContext(http.MethodPost, "/admin/api/v1/audit/re-encrypt?limit=0", "")
+
+	if err := h.ReEncryptAuditLogs(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestAuditConversation_Error(t *testing.T) {
+	reader := &mockAuditReader{
+		conversationErr: core.NewProviderError("test", http.StatusBadGateway, "upstream failed", nil),
+	}
+	h := NewHandler(nil, nil, WithAuditReader(reader))
+	c, rec := newHandlerContext("/admin/api/v1/audit/conversation?log_id=log-1")
+
+	if err := h.AuditConversation(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", rec.Code)
+	}
+}
+
+// --- ListModels handler tests ---
+
+func TestListModels_NilRegistry(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/models")
+
+	if err := h.ListModels(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "[]\n" {
+		t.Errorf("expected empty JSON array, got: %q", rec.Body.String())
+	}
+}
+
+func TestListModels_WithModels(t *testing.T) {
+	registry := providers.NewModelRegistry()
+	mock := &handlerMockProvider{
+		models: &core.ModelsResponse{
+			Object: "list",
+			Data: []core.Model{
+				{ID: "gpt-4", Object: "model", OwnedBy: "openai"},
+				{ID: "claude-3", Object: "model", OwnedBy: "anthropic"},
+			},
+		},
+	}
+	registry.RegisterProviderWithType(mock, "test")
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize registry: %v", err)
+	}
+
+	h := NewHandler(nil, registry)
+	c, rec := newHandlerContext("/admin/api/v1/models")
+
+	if err := h.ListModels(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+
+	var models []providers.ModelWithProvider
+	if err := json.Unmarshal(rec.Body.Bytes(), &models); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(models))
+	}
+	// Should be sorted by model ID
+	if models[0].Model.ID != "claude-3" {
+		t.Errorf("expected first model to be claude-3, got %s", models[0].Model.ID)
+	}
+	if models[1].Model.ID != "gpt-4" {
+		t.Errorf("expected second model to be gpt-4, got %s", models[1].Model.ID)
+	}
+	if models[0].ProviderType != "test" {
+		t.Errorf("expected provider type 'test', got %s", models[0].ProviderType)
+	}
+}
+
+func TestListModels_ConditionalRequest(t *testing.T) {
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithType(&handlerMockProvider{
+		models: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "gpt-4", Object: "model", OwnedBy: "openai"}},
+		},
+	}, "test")
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("failed to initialize registry: %v", err)
+	}
+	h := NewHandler(nil, registry)
+
+	c, rec := newHandlerContext("/admin/api/v1/models")
+	if err := h.ListModels(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q; want 200 with an ETag", rec.Code, etag)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/models")
+	c.Request().Header.Set("If-None-Match", etag)
+	if err := h.ListModels(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", rec.Code)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/providers/status")
+	if err := h.ProviderStatus(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var status providerStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if status.ModelsVersion != registry.ModelsVersion() {
+		t.Errorf("models_version = %q, want %q", status.ModelsVersion, registry.ModelsVersion())
+	}
+}
+
+func TestListModels_EmptyRegistry(t *testing.T) {
+	// A registry with no providers initialized — ListModelsWithProvider returns nil
+	registry := providers.NewModelRegistry()
+
+	h := NewHandler(nil, registry)
+	c, rec := newHandlerContext("/admin/api/v1/models")
+
+	if err := h.ListModels(c); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if rec.Code != http.StatusOK {
@@ -1195,12 +1796,37 @@ func TestListModels_WithCategoryFilter(t *testing.T) {
 		},
 	}
 	registry.RegisterProviderWithType(mock, "openai")
+	registry.SetCustomCategories([]config.ModelCategoryConfig{
+		{ID: "restricted", DisplayName: "Restricted", Models: []string{"gpt-*", "openai/dall-e-3"}},
+		{ID: "internal-finetune", Models: []string{"gpt-4o"}},
+	})
 	if err := registry.Initialize(context.Background()); err != nil {
 		t.Fatalf("failed to initialize registry: %v", err)
 	}
 
 	h := NewHandler(nil, registry)
 
+	t.Run("FilterCustomCategory", func(t *testing.T) {
+		c, rec := newHandlerContext("/admin/api/v1/models?category=restricted")
+		if err := h.ListModels(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var models []providers.ModelWithProvider
+		if err := json.Unmarshal(rec.Body.Bytes(), &models); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if len(models) != 2 || models[0].Model.ID != "dall-e-3" || models[1].Model.ID != "gpt-4o" {
+			t.Fatalf("expected dall-e-3 and gpt-4o, got %+v", models)
+		}
+		want := []core.ModelCategory{core.CategoryTextGeneration, "restricted", "internal-finetune"}
+		if got := models[1].Model.Metadata.Categories; !slices.Equal(got, want) {
+			t.Errorf("gpt-4o categories = %v, want %v", got, want)
+		}
+	})
+
 	t.Run("FilterTextGeneration", func(t *testing.T) {
 		c, rec := newHandlerContext("/admin/api/v1/models?category=text_generation")
 		if err := h.ListModels(c); err != nil {
@@ -1261,6 +1887,9 @@ func TestListModels_InvalidCategory(t *testing.T) {
 		},
 	}
 	registry.RegisterProviderWithType(mock, "openai")
+	registry.SetCustomCategories([]config.ModelCategoryConfig{
+		{ID: "restricted", Models: []string{"gpt-*"}},
+	})
 	if err := registry.Initialize(context.Background()); err != nil {
 		t.Fatalf("failed to initialize registry: %v", err)
 	}
@@ -1374,6 +2003,10 @@ func TestListCategories_WithModels(t *testing.T) {
 		},
 	}
 	registry.RegisterProviderWithType(mock, "openai")
+	registry.SetCustomCategories([]config.ModelCategoryConfig{
+		{ID: "restricted", DisplayName: "Restricted", Models: []string{"*"}},
+		{ID: "internal-finetune", Models: []string{"ft:*"}},
+	})
 	if err := registry.Initialize(context.Background()); err != nil {
 		t.Fatalf("failed to initialize registry: %v", err)
 	}
@@ -1392,8 +2025,8 @@ func TestListCategories_WithModels(t *testing.T) {
 	if err := json.Unmarshal(rec.Body.Bytes(), &cats); err != nil {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
-	if len(cats) != 7 {
-		t.Fatalf("expected 7 categories, got %d", len(cats))
+	if len(cats) != 9 {
+		t.Fatalf("expected 9 categories, got %d", len(cats))
 	}
 
 	// Find "all" count
@@ -1404,6 +2037,14 @@ func TestListCategories_WithModels(t *testing.T) {
 			}
 		}
 	}
+
+	// Custom categories follow the built-in ones in configured order.
+	if got := cats[7]; got.Category != "restricted" || got.DisplayName != "Restricted" || got.Count != 2 {
+		t.Errorf("cats[7] = %+v, want restricted with 2 models", got)
+	}
+	if got := cats[8]; got.Category != "internal-finetune" || got.DisplayName != "internal-finetune" || got.Count != 0 {
+		t.Errorf("cats[8] = %+v, want empty internal-finetune", got)
+	}
 }
 
 func TestProviderStatus_DistinguishesProvidersWithSameTypeByName(t *testing.T) {
@@ -1535,6 +2176,46 @@ func TestProviderStatus_DistinguishesProvidersWithSameTypeByName(t *testing.T) {
 	}
 }
 
+func TestProviderStatus_ReportsInvalidKey(t *testing.T) {
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(&handlerMockProvider{
+		models: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "gpt-4o", Object: "model"}},
+		},
+	}, "openai", "openai")
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	registry.RecordKeyHealth("openai", &core.KeyHealth{
+		Status:                  core.KeyHealthInvalid,
+		LastCheckAt:             time.Now().UTC(),
+		ConsecutiveAuthFailures: 2,
+		LastError:               "Incorrect API key provided",
+	})
+
+	h := NewHandler(nil, registry)
+	c, rec := newHandlerContext("/admin/api/v1/providers/status")
+	if err := h.ProviderStatus(c); err != nil {
+		t.Fatalf("ProviderStatus() error = %v", err)
+	}
+
+	var body providerStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if body.Summary.Unhealthy != 1 || body.Summary.KeyInvalid != 1 || body.Summary.OverallStatus != "unhealthy" {
+		t.Fatalf("summary = %+v, want one unhealthy provider with an invalid key", body.Summary)
+	}
+	provider := body.Providers[0]
+	if provider.Status != "key_invalid" || provider.StatusLabel != "Key invalid" || provider.LastError != "Incorrect API key provided" {
+		t.Fatalf("provider = %q (%q, %q), want key_invalid with the upstream error", provider.Status, provider.StatusLabel, provider.LastError)
+	}
+	if provider.Runtime.KeyHealth == nil || provider.Runtime.KeyHealth.ConsecutiveAuthFailures != 2 {
+		t.Fatalf("runtime.key_health = %+v, want the recorded key health", provider.Runtime.KeyHealth)
+	}
+}
+
 func TestClassifyProviderStatus_RegisteredZeroModelProviderIsConfigured(t *testing.T) {
 	status, label, reason, _ := classifyProviderStatus(
 		providers.SanitizedProviderConfig{Name: "openai"},
@@ -1576,6 +2257,33 @@ func TestClassifyProviderStatus_DerivesCachedModelInventory(t *testing.T) {
 	}
 }
 
+func TestClassifyProviderStatus_LazyInitialization(t *testing.T) {
+	status, label, _, _ := classifyProviderStatus(
+		providers.SanitizedProviderConfig{Name: "anthropic", Lazy: true},
+		providers.ProviderRuntimeSnapshot{
+			Name:           "anthropic",
+			Registered:     true,
+			Initialization: providers.ProviderInitUninitialized,
+		},
+	)
+	if status != "degraded" || label != "Uninitialized" {
+		t.Fatalf("status = %q (%q), want degraded (Uninitialized)", status, label)
+	}
+
+	status, label, _, lastError := classifyProviderStatus(
+		providers.SanitizedProviderConfig{Name: "anthropic", Lazy: true},
+		providers.ProviderRuntimeSnapshot{
+			Name:           "anthropic",
+			Registered:     true,
+			Initialization: providers.ProviderInitFailed,
+			InitError:      "missing api key",
+		},
+	)
+	if status != "unhealthy" || label != "Init failed" || lastError != "missing api key" {
+		t.Fatalf("status = %q (%q, %q), want unhealthy (Init failed, missing api key)", status, label, lastError)
+	}
+}
+
 func TestDashboardConfig_ReturnsAllowlistedRuntimeFlags(t *testing.T) {
 	h := NewHandler(nil, nil, WithDashboardRuntimeConfig(DashboardConfigResponse{
 		FeatureFallbackMode:  "auto",
@@ -1625,6 +2333,33 @@ func TestDashboardConfig_ReturnsAllowlistedRuntimeFlags(t *testing.T) {
 	}
 }
 
+func TestEffectiveConfig(t *testing.T) {
+	h := NewHandler(nil, nil, WithEffectiveConfig(EffectiveConfigResponse{
+		Profile: "prod",
+		Config:  map[string]any{"server": map[string]any{"port": "8080", "master_key": "********"}},
+	}))
+	c, rec := newHandlerContext("/admin/api/v1/config/effective")
+
+	if err := h.EffectiveConfig(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var body EffectiveConfigResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &body) != nil {
+		t.Fatalf("response = %d %s", rec.Code, rec.Body.String())
+	}
+	if body.Profile != "prod" || body.Config["server"].(map[string]any)["port"] != "8080" {
+		t.Fatalf("body = %+v, want the profile and config", body)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/config/effective")
+	if err := NewHandler(nil, nil).EffectiveConfig(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status without effective config = %d, want 503", rec.Code)
+	}
+}
+
 func TestRefreshRuntime_ReturnsReport(t *testing.T) {
 	started := time.Date(2026, 4, 11, 12, 0, 0, 0, time.UTC)
 	refresher := &mockRuntimeRefresher{
@@ -1952,7 +2687,7 @@ func newContext(query string) *echo.Context {
 
 func TestParseUsageParams_DaysDefault(t *testing.T) {
 	c := newContext("")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -1973,6 +2708,141 @@ func TestParseUsageParams_DaysDefault(t *testing.T) {
 	}
 }
 
+func TestParseUsageParams_TzQueryOverridesHeaderAcrossYearBoundary(t *testing.T) {
+	originalTimeNow := timeNow
+	timeNow = func() time.Time {
+		return time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+	}
+	defer func() {
+		timeNow = originalTimeNow
+	}()
+
+	c := newContext("tz=America/Los_Angeles&days=7")
+	c.Request().Header.Set(dashboardTimeZoneHeader, "Europe/Warsaw")
+
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	// 02:00 UTC on New Year's Day is still 2025-12-31 in Los Angeles.
+	expectedEnd := time.Date(2025, 12, 31, 0, 0, 0, 0, location)
+	if params.TimeZone != "America/Los_Angeles" {
+		t.Errorf("expected timezone %q, got %q", "America/Los_Angeles", params.TimeZone)
+	}
+	if !params.EndDate.Equal(expectedEnd) {
+		t.Errorf("expected end date %v, got %v", expectedEnd, params.EndDate)
+	}
+	if !params.StartDate.Equal(expectedEnd.AddDate(0, 0, -6)) {
+		t.Errorf("expected start date %v, got %v", expectedEnd.AddDate(0, 0, -6), params.StartDate)
+	}
+}
+
+func TestParseUsageParams_TzDateBoundariesAcrossDST(t *testing.T) {
+	c := newContext("tz=America/New_York&start_date=2026-03-08&end_date=2026-03-08")
+
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The spring-forward day is 23 hours long: midnight EST to midnight EDT.
+	start := params.StartDate.UTC()
+	endExclusive := params.EndDate.AddDate(0, 0, 1).UTC()
+	if want := time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC); !endExclusive.Equal(want) {
+		t.Errorf("end exclusive = %v, want %v", endExclusive, want)
+	}
+}
+
+func TestParseUsageParams_RejectsUnknownTz(t *testing.T) {
+	for _, tz := range []string{"Mars/Olympus_Mons", "Local"} {
+		_, err := parseUsageParams(newContext("tz="+tz), defaultMaxQueryDays)
+		var gatewayErr *core.GatewayError
+		if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusBadRequest {
+			t.Fatalf("tz=%s: err = %v, want 400 invalid request", tz, err)
+		}
+	}
+}
+
+func TestParseUsageParams_UnknownTimezoneHeaderFallsBackToUTC(t *testing.T) {
+	c := newContext("")
+	c.Request().Header.Set(dashboardTimeZoneHeader, "Mars/Olympus_Mons")
+
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.TimeZone != "UTC" {
+		t.Errorf("expected UTC fallback, got %q", params.TimeZone)
+	}
+}
+
+func TestParseUsageParams_WeekStart(t *testing.T) {
+	params, err := parseUsageParams(newContext("interval=weekly&week_start=Sunday"), defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.WeekStart != usage.WeekStartSunday {
+		t.Errorf("WeekStart = %q, want %q", params.WeekStart, usage.WeekStartSunday)
+	}
+
+	params, err = parseUsageParams(newContext("interval=weekly"), defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.WeekStart != "" {
+		t.Errorf("WeekStart = %q, want default", params.WeekStart)
+	}
+
+	if _, err := parseUsageParams(newContext("week_start=friday"), defaultMaxQueryDays); err == nil {
+		t.Fatal("expected error for week_start=friday")
+	}
+}
+
+func TestParseUsageParams_ModelBy(t *testing.T) {
+	params, err := parseUsageParams(newContext("model_by=Requested"), defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.ModelBy != usage.ModelByRequested {
+		t.Errorf("ModelBy = %q, want %q", params.ModelBy, usage.ModelByRequested)
+	}
+
+	params, err = parseUsageParams(newContext(""), defaultMaxQueryDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.ModelBy != "" {
+		t.Errorf("ModelBy = %q, want default", params.ModelBy)
+	}
+
+	if _, err := parseUsageParams(newContext("model_by=resolved"), defaultMaxQueryDays); err == nil {
+		t.Fatal("expected error for model_by=resolved")
+	}
+}
+
+func TestDailyUsage_UnknownTzReturns400(t *testing.T) {
+	h := NewHandler(&mockUsageReader{}, nil)
+	c, rec := newHandlerContext("/admin/api/v1/usage/daily?tz=Nowhere/Special")
+
+	if err := h.DailyUsage(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "invalid tz") {
+		t.Fatalf("body = %s, want invalid tz message", rec.Body.String())
+	}
+}
+
 func TestParseUsageParams_UsesTimezoneHeaderForDefaultRange(t *testing.T) {
 	originalTimeNow := timeNow
 	timeNow = func() time.Time {
@@ -1985,7 +2855,7 @@ func TestParseUsageParams_UsesTimezoneHeaderForDefaultRange(t *testing.T) {
 	c := newContext("")
 	c.Request().Header.Set(dashboardTimeZoneHeader, "Europe/Warsaw")
 
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2011,7 +2881,7 @@ func TestParseUsageParams_UsesTimezoneHeaderForDefaultRange(t *testing.T) {
 
 func TestParseUsageParams_DaysExplicit(t *testing.T) {
 	c := newContext("days=7")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2030,7 +2900,7 @@ func TestParseUsageParams_DaysExplicit(t *testing.T) {
 
 func TestParseUsageParams_StartAndEndDate(t *testing.T) {
 	c := newContext("start_date=2026-01-01&end_date=2026-01-31")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2048,7 +2918,7 @@ func TestParseUsageParams_StartAndEndDate(t *testing.T) {
 
 func TestParseUsageParams_OnlyStartDate(t *testing.T) {
 	c := newContext("start_date=2026-01-15")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2067,7 +2937,7 @@ func TestParseUsageParams_OnlyStartDate(t *testing.T) {
 
 func TestParseUsageParams_OnlyEndDate(t *testing.T) {
 	c := newContext("end_date=2026-02-10")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2085,7 +2955,7 @@ func TestParseUsageParams_OnlyEndDate(t *testing.T) {
 
 func TestParseUsageParams_InvalidStartDate(t *testing.T) {
 	c := newContext("start_date=invalid")
-	_, err := parseUsageParams(c)
+	_, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err == nil {
 		t.Fatal("expected error for invalid start_date, got nil")
 	}
@@ -2101,7 +2971,7 @@ func TestParseUsageParams_InvalidStartDate(t *testing.T) {
 
 func TestParseUsageParams_InvalidEndDate(t *testing.T) {
 	c := newContext("start_date=2026-01-01&end_date=also-invalid")
-	_, err := parseUsageParams(c)
+	_, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err == nil {
 		t.Fatal("expected error for invalid end_date, got nil")
 	}
@@ -2115,9 +2985,90 @@ func TestParseUsageParams_InvalidEndDate(t *testing.T) {
 	}
 }
 
+func TestParseUsageParams_RejectsOutOfBoundsWindows(t *testing.T) {
+	originalTimeNow := timeNow
+	timeNow = func() time.Time { return time.Date(2026, 4, 7, 12, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = originalTimeNow }()
+
+	testCases := []struct {
+		name    string
+		query   string
+		maxDays int
+	}{
+		{name: "zero days", query: "days=0"},
+		{name: "negative days", query: "days=-5"},
+		{name: "non-integer days", query: "days=abc"},
+		{name: "days over default cap", query: "days=36500"},
+		{name: "days over configured cap", query: "days=31", maxDays: 30},
+		{name: "end before start", query: "start_date=2026-03-10&end_date=2026-03-01"},
+		{name: "range over default cap", query: "start_date=2016-01-01&end_date=2026-01-01"},
+		{name: "range over configured cap", query: "start_date=2026-01-01&end_date=2026-01-31", maxDays: 30},
+		{name: "open start over cap", query: "start_date=2024-01-01"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maxDays := tc.maxDays
+			if maxDays == 0 {
+				maxDays = defaultMaxQueryDays
+			}
+			_, err := parseUsageParams(newContext(tc.query), maxDays)
+
+			var gatewayErr *core.GatewayError
+			if !errors.As(err, &gatewayErr) {
+				t.Fatalf("expected GatewayError, got %v", err)
+			}
+			if gatewayErr.HTTPStatusCode() != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", gatewayErr.HTTPStatusCode())
+			}
+		})
+	}
+}
+
+func TestParseUsageParams_AcceptsWindowsAtCap(t *testing.T) {
+	originalTimeNow := timeNow
+	timeNow = func() time.Time { return time.Date(2026, 4, 7, 12, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = originalTimeNow }()
+
+	for _, query := range []string{
+		"days=366",
+		"start_date=2025-04-07&end_date=2026-04-07",
+		"start_date=2026-03-01&end_date=2026-03-01",
+	} {
+		if _, err := parseUsageParams(newContext(query), defaultMaxQueryDays); err != nil {
+			t.Errorf("%s: unexpected error: %v", query, err)
+		}
+	}
+	// Thirty calendar days across the US spring-forward change.
+	if _, err := parseUsageParams(newContext("tz=America/New_York&start_date=2026-03-01&end_date=2026-03-30"), 30); err != nil {
+		t.Errorf("unexpected error across DST: %v", err)
+	}
+}
+
+func TestHandlers_ApplyConfiguredMaxQueryDays(t *testing.T) {
+	reader := &mockUsageReader{summary: &usage.UsageSummary{}}
+	h := NewHandler(reader, nil, WithMaxQueryDays(7), WithAuditReader(&mockAuditReader{}))
+
+	c, rec := newHandlerContext("/admin/api/v1/usage/summary?days=8")
+	if err := h.UsageSummary(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("usage summary: expected 400, got %d", rec.Code)
+	}
+
+	c, rec = newHandlerContext("/admin/api/v1/audit/log?start_date=2026-01-01&end_date=2026-01-08")
+	if err := h.AuditLog(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("audit log: expected 400, got %d", rec.Code)
+	}
+}
+
 func TestParseUsageParams_InvalidUserPath(t *testing.T) {
 	c := newContext("user_path=/team/../alpha")
-	_, err := parseUsageParams(c)
+	_, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err == nil {
 		t.Fatal("expected error for invalid user_path, got nil")
 	}
@@ -2136,7 +3087,7 @@ func TestParseUsageParams_InvalidUserPath(t *testing.T) {
 
 func TestParseUsageParams_IntervalWeekly(t *testing.T) {
 	c := newContext("interval=weekly")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2148,7 +3099,7 @@ func TestParseUsageParams_IntervalWeekly(t *testing.T) {
 
 func TestParseUsageParams_IntervalMonthly(t *testing.T) {
 	c := newContext("interval=monthly")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2160,7 +3111,7 @@ func TestParseUsageParams_IntervalMonthly(t *testing.T) {
 
 func TestParseUsageParams_IntervalInvalid(t *testing.T) {
 	c := newContext("interval=hourly")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2172,7 +3123,7 @@ func TestParseUsageParams_IntervalInvalid(t *testing.T) {
 
 func TestParseUsageParams_IntervalEmpty(t *testing.T) {
 	c := newContext("")
-	params, err := parseUsageParams(c)
+	params, err := parseUsageParams(c, defaultMaxQueryDays)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -2190,3 +3141,339 @@ var _ = func() usage.UsageQueryParams {
 		Interval:  "daily",
 	}
 }
+
+// --- Scoreboard handler tests ---
+
+func TestScoreboard_NilBoardReturnsEmptySnapshot(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/scoreboard")
+
+	if err := h.Scoreboard(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var result scoreboard.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if result.Window != scoreboard.Window5m || result.Models == nil || len(result.Models) != 0 {
+		t.Errorf("expected empty 5m snapshot, got %+v", result)
+	}
+}
+
+func TestScoreboard_FiltersByModel(t *testing.T) {
+	board := scoreboard.New()
+	board.Record(scoreboard.Observation{Provider: "openai", Model: "gpt-5", Latency: 200 * time.Millisecond})
+	board.Record(scoreboard.Observation{Provider: "azure", Model: "gpt-5", Latency: 100 * time.Millisecond})
+	board.Record(scoreboard.Observation{Provider: "anthropic", Model: "claude", Latency: time.Second})
+
+	h := NewHandler(nil, nil, WithScoreboard(board))
+	c, rec := newHandlerContext("/admin/api/v1/scoreboard?window=1h&model=gpt-5")
+
+	if err := h.Scoreboard(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var result scoreboard.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if result.Window != scoreboard.Window1h {
+		t.Errorf("expected 1h window, got %q", result.Window)
+	}
+	if len(result.Models) != 2 || result.Models[0].Provider != "azure" || result.Models[1].Provider != "openai" {
+		t.Fatalf("expected gpt-5 providers fastest first, got %+v", result.Models)
+	}
+}
+
+func TestScoreboard_InvalidWindow(t *testing.T) {
+	h := NewHandler(nil, nil, WithScoreboard(scoreboard.New()))
+	c, rec := newHandlerContext("/admin/api/v1/scoreboard?window=7d")
+
+	if err := h.Scoreboard(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestPromptCacheUsage(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		h := NewHandler(nil, nil)
+		c, rec := newHandlerContext("/admin/api/v1/usage/prompt-cache")
+
+		if err := h.PromptCacheUsage(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result promptcache.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if rec.Code != http.StatusOK || result.Enabled || result.Prefixes == nil || len(result.Prefixes) != 0 {
+			t.Fatalf("expected 200 with an empty disabled report, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("hits", func(t *testing.T) {
+		tracker := promptcache.New(config.PromptCachingConfig{
+			Enabled: true, Prefixes: []string{"You are a support agent."}, MinRepeats: 3, MinChars: 4096, MaxPrefixes: 10,
+		}, nil)
+		tracker.Match("req-1", "", "gpt-4o", "You are a support agent. Be brief.")
+		tracker.Observe(&usage.UsageEntry{RequestID: "req-1", InputTokens: 1200, RawData: map[string]any{"prompt_cached_tokens": 1024}})
+
+		h := NewHandler(nil, nil, WithPromptCache(tracker))
+		c, rec := newHandlerContext("/admin/api/v1/usage/prompt-cache")
+
+		if err := h.PromptCacheUsage(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result promptcache.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if !result.Enabled || len(result.Prefixes) != 1 || result.Prefixes[0].Hits != 1 || result.Totals.CachedTokens != 1024 {
+			t.Fatalf("expected one prefix with a hit, got %s", rec.Body.String())
+		}
+	})
+}
+
+func TestUsageContentStats(t *testing.T) {
+	reader := &mockUsageReader{
+		contentStats: []usage.ContentStats{
+			{Date: "2026-04-07", Model: "gpt-4o", Provider: "openai", Language: "en", Responses: 3, TotalChars: 2400, TotalWords: 410, MaxChars: 1800,
+				Lengths: usage.ContentLengthBuckets{Short: 1, Medium: 1, Long: 1}},
+		},
+	}
+	h := NewHandler(reader, nil)
+	c, rec := newHandlerContext("/admin/api/v1/usage/content-stats?days=7&model_by=requested")
+
+	if err := h.UsageContentStats(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var result []usage.ContentStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(result) != 1 || result[0].Language != "en" || result[0].Lengths.Long != 1 {
+		t.Fatalf("unexpected content stats: %s", rec.Body.String())
+	}
+	if reader.lastContentStats.ModelBy != usage.ModelByRequested {
+		t.Errorf("expected model_by=requested to reach the reader, got %q", reader.lastContentStats.ModelBy)
+	}
+}
+
+func TestExperiments_NoServiceReturnsEmptyList(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/experiments")
+
+	if err := h.Experiments(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+		t.Fatalf("expected empty list, got %s", body)
+	}
+}
+
+func TestExperiments_ReportsVariantTrafficAndUsage(t *testing.T) {
+	service, err := experiments.New([]config.ExperimentConfig{{
+		Name:  "smart-model",
+		Model: "smart",
+		Variants: []config.ExperimentVariantConfig{
+			{Name: "control", Weight: 3},
+			{Name: "candidate", Model: "claude-sonnet-4", Provider: "anthropic", Weight: 1},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("experiments.New() error = %v", err)
+	}
+	experiment, _ := service.Lookup("smart")
+	experiment.Assign("key-1")
+
+	cost := 0.25
+	reader := &mockUsageReader{experimentUsage: []usage.ExperimentVariantUsage{
+		{Experiment: "smart-model", Variant: "candidate", Requests: 4, InputTokens: 100, OutputTokens: 50, TotalTokens: 150, TotalCost: &cost},
+		{Experiment: "removed", Variant: "control", Requests: 9},
+	}}
+	h := NewHandler(reader, nil, WithExperiments(service))
+	c, rec := newHandlerContext("/admin/api/v1/experiments?days=7")
+
+	if err := h.Experiments(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var result []ExperimentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "smart-model" || result[0].Unit != "api_key" {
+		t.Fatalf("unexpected experiments: %+v", result)
+	}
+	variants := result[0].Variants
+	if len(variants) != 2 {
+		t.Fatalf("expected 2 variants, got %+v", variants)
+	}
+	if !variants[0].Control || variants[0].WeightShare != 0.75 || variants[0].Requests != 0 {
+		t.Errorf("unexpected control variant: %+v", variants[0])
+	}
+	candidate := variants[1]
+	if candidate.Control || candidate.WeightShare != 0.25 || candidate.Requests != 4 || candidate.TotalTokens != 150 {
+		t.Errorf("unexpected candidate variant: %+v", candidate)
+	}
+	if candidate.TotalCost == nil || *candidate.TotalCost != cost {
+		t.Errorf("expected candidate cost %v, got %v", cost, candidate.TotalCost)
+	}
+	if variants[0].Assignments+candidate.Assignments != 1 {
+		t.Errorf("expected one recorded assignment, got %d and %d", variants[0].Assignments, candidate.Assignments)
+	}
+}
+
+func TestExperiments_UsageReaderError(t *testing.T) {
+	service, err := experiments.New([]config.ExperimentConfig{{
+		Name:  "smart-model",
+		Model: "smart",
+		Variants: []config.ExperimentVariantConfig{
+			{Name: "control", Weight: 1},
+			{Name: "candidate", Model: "gpt-5", Weight: 1},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("experiments.New() error = %v", err)
+	}
+	h := NewHandler(&mockUsageReader{experimentErr: errors.New("db down")}, nil, WithExperiments(service))
+	c, rec := newHandlerContext("/admin/api/v1/experiments")
+
+	if err := h.Experiments(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+}
+
+func TestModelGroups_NoResolverReturnsEmptyList(t *testing.T) {
+	c, rec := newHandlerContext("/admin/api/v1/model-groups")
+	if err := NewHandler(nil, nil).ModelGroups(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || body != "[]" {
+		t.Fatalf("expected 200 with an empty list, got %d %s", rec.Code, body)
+	}
+}
+
+func TestModelGroups_ReportsResolutionAsProviderHealthChanges(t *testing.T) {
+	registry := providers.NewModelRegistry()
+	for _, name := range []string{"anthropic", "openai"} {
+		registry.RegisterProviderWithNameAndType(&handlerMockProvider{models: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "model-" + name, Object: "model", OwnedBy: name}},
+		}}, name, name)
+	}
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	service, err := modelgroups.New([]config.ModelGroupConfig{{
+		Name: "coding",
+		Members: []config.ModelGroupMemberConfig{
+			{Model: "model-anthropic", Provider: "anthropic"},
+			{Model: "model-openai", Provider: "openai"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("modelgroups.New() error = %v", err)
+	}
+	h := NewHandler(nil, registry, WithModelGroups(modelgroups.NewResolver(service, nil, registry, nil)))
+
+	resolve := func() modelgroups.Resolution {
+		t.Helper()
+		c, rec := newHandlerContext("/admin/api/v1/model-groups")
+		if err := h.ModelGroups(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result []modelgroups.Resolution
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || len(result) != 1 {
+			t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+		}
+		return result[0]
+	}
+
+	if got := resolve(); got.Selected != "anthropic/model-anthropic" || !got.Members[0].Eligible {
+		t.Fatalf("resolution = %+v, want the first member", got)
+	}
+
+	registry.RecordCircuitState("anthropic", true, time.Now().Add(time.Minute))
+	got := resolve()
+	if got.Selected != "openai/model-openai" || got.Members[0].Reason != "circuit breaker open" || got.Fallback {
+		t.Fatalf("resolution = %+v, want the second member while the first circuit is open", got)
+	}
+
+	registry.RecordCircuitState("anthropic", false, time.Time{})
+	if got := resolve(); got.Selected != "anthropic/model-anthropic" {
+		t.Fatalf("resolution = %+v, want the first member once its circuit closed", got)
+	}
+}
+
+func TestDeferred_UnavailableWithoutService(t *testing.T) {
+	h := NewHandler(nil, nil)
+	c, rec := newHandlerContext("/admin/api/v1/deferred")
+
+	if err := h.Deferred(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestDeferred_ReportsQueueDepth(t *testing.T) {
+	service, err := deferred.NewService(deferred.NewMemoryStore(), config.DeferredConfig{
+		Enabled:        true,
+		TTL:            time.Hour,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		PollInterval:   time.Second,
+	})
+	if err != nil {
+		t.Fatalf("deferred.NewService() error = %v", err)
+	}
+	for range 2 {
+		if _, err := service.Enqueue(context.Background(), &deferred.Request{Method: http.MethodPost, Path: "/v1/chat/completions"}, "upstream down"); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	h := NewHandler(nil, nil, WithDeferred(service))
+	c, rec := newHandlerContext("/admin/api/v1/deferred")
+
+	if err := h.Deferred(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body DeferredQueueResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Depth != 2 || body.Counts["queued"] != 2 || body.Counts["succeeded"] != 0 {
+		t.Fatalf("response = %+v, want depth 2 with 2 queued", body)
+	}
+	if _, ok := body.Counts["expired"]; !ok {
+		t.Fatalf("counts missing expired status: %+v", body.Counts)
+	}
+}
@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/storage"
+	"gomodel/internal/usage"
+	"gomodel/internal/usagejobs"
+)
+
+func newUsageJobHandler(t *testing.T) *Handler {
+	t.Helper()
+	st, err := storage.NewSQLite(storage.SQLiteConfig{Path: filepath.Join(t.TempDir(), "usage.db")})
+	if err != nil {
+		t.Fatalf("new sqlite storage: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	usageStore, err := usage.NewSQLiteStore(st.DB(), 0)
+	if err != nil {
+		t.Fatalf("new sqlite usage store: %v", err)
+	}
+	entries := []*usage.UsageEntry{
+		{ID: "usage-1", Timestamp: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), Model: "gpt-4o", Provider: "openai", AuthKeyID: "key-a"},
+		{ID: "usage-2", Timestamp: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC), Model: "gpt-4o", Provider: "openai", AuthKeyID: "key-a"},
+		{ID: "usage-3", Timestamp: time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC), Model: "gpt-4o", Provider: "openai", AuthKeyID: "key-b"},
+	}
+	if err := usageStore.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("write usage entries: %v", err)
+	}
+
+	result, err := usagejobs.NewWithSharedStorage(context.Background(), st, nil, config.UsageConfig{JobBatchSize: 500})
+	if err != nil {
+		t.Fatalf("new usage jobs: %v", err)
+	}
+	return NewHandler(nil, nil, WithUsageJobs(result.Service))
+}
+
+func newUsageJobContext(method, path, body string) (*echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestUsageJobs_UnavailableWithoutService(t *testing.T) {
+	h := NewHandler(nil, nil)
+
+	for name, call := range map[string]func(*echo.Context) error{
+		"RecomputeUsageCosts": h.RecomputeUsageCosts,
+		"ReattributeUsage":    h.ReattributeUsage,
+		"UsageJob":            h.UsageJob,
+	} {
+		c, rec := newUsageJobContext(http.MethodPost, "/admin/api/v1/usage/reattribute", `{}`)
+		if err := call(c); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: status = %d, want 503", name, rec.Code)
+		}
+	}
+}
+
+func TestReattributeUsage_DryRunThenSubmit(t *testing.T) {
+	h := newUsageJobHandler(t)
+	body := `{"from_auth_key_id":"key-a","to_auth_key_id":"key-c","start_date":"2026-01-01","end_date":"2026-01-31"}`
+
+	c, rec := newUsageJobContext(http.MethodPost, "/admin/api/v1/usage/reattribute", strings.TrimSuffix(body, "}")+`,"dry_run":true}`)
+	if err := h.ReattributeUsage(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var dryRun UsageJobDryRunResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &dryRun); err != nil {
+		t.Fatalf("decode dry run: %v", err)
+	}
+	if !dryRun.DryRun || dryRun.Kind != usagejobs.KindReattribute || dryRun.AffectedRows != 2 {
+		t.Fatalf("dry run = %+v, want 2 affected rows", dryRun)
+	}
+
+	c, rec = newUsageJobContext(http.MethodPost, "/admin/api/v1/usage/reattribute", body)
+	if err := h.ReattributeUsage(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("submit status = %d, want 202; body = %s", rec.Code, rec.Body.String())
+	}
+	var job usagejobs.Job
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if job.Status != usagejobs.StatusQueued || job.Total != 2 || job.Actor == "" {
+		t.Fatalf("job = %+v, want a queued job of 2 rows with an actor", job)
+	}
+
+	c, rec = newUsageJobContext(http.MethodGet, "/admin/api/v1/jobs/"+job.ID, "")
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: job.ID}})
+	if err := h.UsageJob(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), job.ID) {
+		t.Fatalf("get job status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUsageJobs_RejectsInvalidInputAndUnknownJobs(t *testing.T) {
+	h := newUsageJobHandler(t)
+
+	c, rec := newUsageJobContext(http.MethodPost, "/admin/api/v1/usage/recompute-costs", `{"model":"gpt-4o"}`)
+	if err := h.RecomputeUsageCosts(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("recompute without dates: status = %d, want 400", rec.Code)
+	}
+
+	c, rec = newUsageJobContext(http.MethodPost, "/admin/api/v1/usage/reattribute", `{"from_auth_key_id":"key-a","to_auth_key_id":"key-a"}`)
+	if err := h.ReattributeUsage(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("reattribute to the same key: status = %d, want 400", rec.Code)
+	}
+
+	c, rec = newUsageJobContext(http.MethodGet, "/admin/api/v1/jobs/usagejob_missing", "")
+	c.SetPathValues(echo.PathValues{{Name: "id", Value: "usagejob_missing"}})
+	if err := h.UsageJob(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown job: status = %d, want 404", rec.Code)
+	}
+}
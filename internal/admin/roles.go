@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/core"
+)
+
+// routeRoles lists the admin API routes open to scoped roles, keyed by method
+// and route path. Every other route, including all mutating endpoints,
+// requires authkeys.RoleAdmin.
+var routeRoles = map[string]authkeys.Role{
+	"GET /admin/api/v1/usage/summary":            authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/daily":              authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/models":             authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/user-paths":         authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/groups":             authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/log":                authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/prompt-cache":       authkeys.RoleReadUsage,
+	"GET /admin/api/v1/usage/content-stats":      authkeys.RoleReadUsage,
+	"GET /admin/api/v1/cache/overview":           authkeys.RoleReadUsage,
+	"GET /admin/api/v1/scoreboard":               authkeys.RoleReadUsage,
+	"GET /admin/api/v1/experiments":              authkeys.RoleReadUsage,
+	"GET /admin/api/v1/model-groups":             authkeys.RoleReadUsage,
+	"GET /admin/api/v1/deferred":                 authkeys.RoleReadUsage,
+	"GET /admin/api/v1/jobs/:id":                 authkeys.RoleReadUsage,
+	"GET /admin/api/v1/anomalies":                authkeys.RoleReadUsage,
+	"GET /admin/api/v1/deprecations":             authkeys.RoleReadUsage,
+	"GET /admin/api/v1/providers/status":         authkeys.RoleReadUsage,
+	"GET /admin/api/v1/providers/:name/quota":    authkeys.RoleReadUsage,
+	"GET /admin/api/v1/models":                   authkeys.RoleReadUsage,
+	"GET /admin/api/v1/models/categories":        authkeys.RoleReadUsage,
+	"GET /admin/api/v1/audit/log":                authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/audit/conversation":       authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/audit/by-response-id/:id": authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/audit/:id":                authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/requests/:request_id":     authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/errors/summary":           authkeys.RoleReadAuditMetadata,
+	"GET /admin/api/v1/guardrails/:name/canary":  authkeys.RoleReadAuditMetadata,
+}
+
+// MinimumRole returns the least privileged role allowed to call the admin API
+// route registered for method and path.
+func MinimumRole(method, path string) authkeys.Role {
+	if role, ok := routeRoles[method+" "+path]; ok {
+		return role
+	}
+	return authkeys.RoleAdmin
+}
+
+// RequireRole is the admin API group middleware that rejects callers whose
+// role is below the route's MinimumRole with a 403.
+func RequireRole(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		role := callerRole(c)
+		required := MinimumRole(c.Request().Method, c.Path())
+		if role.Allows(required) {
+			return next(c)
+		}
+		message := fmt.Sprintf("role %s cannot access this endpoint, %s is required", role, required)
+		auditlog.EnrichEntryWithError(c, string(core.ErrorTypeAuthentication), message)
+		return handleError(c, core.NewForbiddenError(message).WithCode("insufficient_role"))
+	}
+}
+
+// callerRole returns the role the auth middleware attached to c. Requests
+// that never passed through it run without authentication and get admin.
+func callerRole(c *echo.Context) authkeys.Role {
+	if role, ok := c.Get(authkeys.RoleContextKey).(authkeys.Role); ok {
+		return role
+	}
+	return authkeys.RoleAdmin
+}
+
+// auditMetadataOnly drops captured headers and bodies from entries unless the
+// caller has admin access.
+func auditMetadataOnly(c *echo.Context, entries []auditlog.LogEntry) {
+	if callerRole(c).Allows(authkeys.RoleAdmin) {
+		return
+	}
+	for i := range entries {
+		if entries[i].Data == nil {
+			continue
+		}
+		data := *entries[i].Data
+		data.RequestHeaders = nil
+		data.ResponseHeaders = nil
+		data.RequestBody = nil
+		data.ResponseBody = nil
+		entries[i].Data = &data
+	}
+}
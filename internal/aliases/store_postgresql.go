@@ -24,6 +24,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the aliases table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS aliases (
 			name TEXT PRIMARY KEY,
@@ -36,15 +47,15 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create aliases table: %w", err)
+		return fmt.Errorf("failed to create aliases table: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_aliases_enabled ON aliases(enabled)`); err != nil {
-		return nil, fmt.Errorf("failed to create aliases enabled index: %w", err)
+		return fmt.Errorf("failed to create aliases enabled index: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_aliases_updated_at ON aliases(updated_at DESC)`); err != nil {
-		return nil, fmt.Errorf("failed to create aliases updated_at index: %w", err)
+		return fmt.Errorf("failed to create aliases updated_at index: %w", err)
 	}
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 func (s *PostgreSQLStore) List(ctx context.Context) ([]Alias, error) {
@@ -19,7 +19,18 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
-	_, err := db.Exec(`
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the aliases table and its indexes. It
+// is idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS aliases (
 			name TEXT PRIMARY KEY,
 			target_model TEXT NOT NULL,
@@ -31,15 +42,15 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create aliases table: %w", err)
+		return fmt.Errorf("failed to create aliases table: %w", err)
 	}
-	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_aliases_enabled ON aliases(enabled)`); err != nil {
-		return nil, fmt.Errorf("failed to create aliases enabled index: %w", err)
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_aliases_enabled ON aliases(enabled)`); err != nil {
+		return fmt.Errorf("failed to create aliases enabled index: %w", err)
 	}
-	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_aliases_updated_at ON aliases(updated_at DESC)`); err != nil {
-		return nil, fmt.Errorf("failed to create aliases updated_at index: %w", err)
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_aliases_updated_at ON aliases(updated_at DESC)`); err != nil {
+		return fmt.Errorf("failed to create aliases updated_at index: %w", err)
 	}
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 func (s *SQLiteStore) List(ctx context.Context) ([]Alias, error) {
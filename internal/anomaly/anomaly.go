@@ -0,0 +1,444 @@
+// Package anomaly detects unusual usage, such as an agent stuck in a loop,
+// from the usage records the gateway writes.
+//
+// Usage is bucketed per minute in memory for every model, provider and
+// managed API key seen recently. Each new record updates its series and is
+// checked against the configured rules, which compare requests per minute,
+// tokens per minute or cost per hour with an absolute value or a multiple of
+// the series' own baseline: its average over the baseline window. Detection
+// never queries storage on the request path; after a restart, Warm refills
+// the buckets from the usage store.
+package anomaly
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gomodel/config"
+	"gomodel/internal/logging"
+	"gomodel/internal/usage"
+)
+
+// anomalyLogger logs detected anomalies and baseline warm-up.
+var anomalyLogger = logging.Component(logging.ComponentUsage)
+
+const (
+	// warmPageSize is the usage log page size read by Warm.
+	warmPageSize = 200
+	// maxWarmEntries bounds how many usage records Warm reads.
+	maxWarmEntries = 100000
+	// seriesOverheadBytes estimates a series beyond its buckets: the key
+	// strings, the map slot and the fired-rule times.
+	seriesOverheadBytes = 256
+	// recordBytes estimates one recorded anomaly.
+	recordBytes = 512
+)
+
+// Metric values indexed by metricIndex.
+const (
+	metricRequests = iota
+	metricTokens
+	metricCost
+	metricCount
+)
+
+// Anomaly is a rule that fired for one series.
+type Anomaly struct {
+	ID         string    `json:"id"`
+	DetectedAt time.Time `json:"detected_at"`
+	Metric     string    `json:"metric"`
+	Scope      string    `json:"scope"`
+	// Subject is the model, provider name or managed API key ID.
+	Subject string  `json:"subject"`
+	Value   float64 `json:"value"`
+	// Baseline is the series' average for the metric's window. It is nil
+	// while the series has less history than the configured minimum.
+	Baseline  *float64 `json:"baseline,omitempty" extensions:"x-nullable"`
+	Threshold float64  `json:"threshold"`
+	// Rule is the rule that fired.
+	Rule           config.AnomalyRule `json:"rule"`
+	Acknowledged   bool               `json:"acknowledged"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at,omitempty" extensions:"x-nullable"`
+}
+
+type seriesKey struct {
+	scope   string
+	subject string
+}
+
+// series holds one subject's usage in a ring of per-minute buckets.
+type series struct {
+	buckets   [][metricCount]float64
+	totals    [metricCount]float64
+	head      int64 // unix minute of the newest bucket
+	first     int64 // unix minute of the oldest usage seen
+	used      uint64
+	lastFired map[int]time.Time // by rule index
+}
+
+// Detector tracks usage series and the anomalies found in them.
+type Detector struct {
+	cfg        config.AnomalyDetectionConfig
+	size       int64 // buckets per series
+	minHistory int64 // minutes of history needed for a baseline
+
+	mu      sync.Mutex
+	series  map[seriesKey]*series
+	ticks   uint64
+	records []Anomaly // oldest first
+
+	now func() time.Time
+}
+
+// New returns a detector for cfg, or nil when anomaly detection is disabled.
+func New(cfg config.AnomalyDetectionConfig) *Detector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Detector{
+		cfg:        cfg,
+		size:       max(int64(cfg.BaselineWindow/time.Minute), 2),
+		minHistory: max(int64(cfg.MinBaseline/time.Minute), 1),
+		series:     make(map[seriesKey]*series),
+		now:        time.Now,
+	}
+}
+
+// Observe adds a usage record to its series and checks the rules. Records
+// older than the newest bucket of a series only update its baseline.
+func (d *Detector) Observe(entry *usage.UsageEntry) {
+	if d == nil || entry == nil {
+		return
+	}
+	var cost float64
+	if entry.TotalCost != nil {
+		cost = *entry.TotalCost
+	}
+	provider := entry.ProviderName
+	if provider == "" {
+		provider = entry.Provider
+	}
+	found := d.observe(entry.Timestamp, entry.Model, provider, entry.AuthKeyID,
+		[metricCount]float64{1, float64(entry.TotalTokens), cost}, true)
+	for _, a := range found {
+		attrs := []any{
+			"id", a.ID,
+			"metric", a.Metric,
+			"scope", a.Scope,
+			"subject", a.Subject,
+			"value", a.Value,
+			"threshold", a.Threshold,
+		}
+		if a.Baseline != nil {
+			attrs = append(attrs, "baseline", *a.Baseline)
+		}
+		anomalyLogger.Warn("usage anomaly detected", attrs...)
+	}
+}
+
+func (d *Detector) observe(at time.Time, model, provider, keyID string, values [metricCount]float64, check bool) []Anomaly {
+	if at.IsZero() {
+		at = d.now()
+	}
+	minute := at.Unix() / 60
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var found []Anomaly
+	for _, key := range []seriesKey{
+		{scope: config.AnomalyScopeModel, subject: model},
+		{scope: config.AnomalyScopeProvider, subject: provider},
+		{scope: config.AnomalyScopeKey, subject: keyID},
+	} {
+		if key.subject == "" {
+			continue
+		}
+		s := d.seriesFor(key, minute)
+		if !s.add(minute, values, d.size) || !check || minute != s.head {
+			continue
+		}
+		found = append(found, d.check(key, s, at)...)
+	}
+	return found
+}
+
+// seriesFor returns the series for key, creating it and evicting the least
+// recently used series when the limit is reached.
+func (d *Detector) seriesFor(key seriesKey, minute int64) *series {
+	d.ticks++
+	if s, ok := d.series[key]; ok {
+		s.used = d.ticks
+		return s
+	}
+	if len(d.series) >= d.cfg.MaxSeries {
+		var oldest seriesKey
+		var oldestUsed uint64
+		for k, s := range d.series {
+			if oldestUsed == 0 || s.used < oldestUsed {
+				oldest, oldestUsed = k, s.used
+			}
+		}
+		delete(d.series, oldest)
+	}
+	s := &series{
+		buckets: make([][metricCount]float64, d.size),
+		head:    minute,
+		first:   minute,
+		used:    d.ticks,
+	}
+	d.series[key] = s
+	return s
+}
+
+// add records values in the bucket for minute, advancing the ring when the
+// minute is newer than the head. It reports false for minutes that already
+// fell out of the ring.
+func (s *series) add(minute int64, values [metricCount]float64, size int64) bool {
+	switch {
+	case minute > s.head:
+		if minute-s.head >= size {
+			clear(s.buckets)
+			s.totals = [metricCount]float64{}
+		} else {
+			for m := s.head + 1; m <= minute; m++ {
+				bucket := &s.buckets[m%size]
+				for i := range bucket {
+					s.totals[i] -= bucket[i]
+				}
+				*bucket = [metricCount]float64{}
+			}
+		}
+		s.head = minute
+	case minute <= s.head-size:
+		return false
+	}
+	bucket := &s.buckets[minute%size]
+	for i, v := range values {
+		bucket[i] += v
+		s.totals[i] += v
+	}
+	s.first = min(s.first, minute)
+	return true
+}
+
+// sum returns a metric's total over the newest window buckets.
+func (s *series) sum(metric int, window, size int64) float64 {
+	var total float64
+	for m := s.head - window + 1; m <= s.head; m++ {
+		total += s.buckets[m%size][metric]
+	}
+	return total
+}
+
+// check evaluates the rules for the series whose newest bucket was just
+// updated and records the anomalies that fire.
+func (d *Detector) check(key seriesKey, s *series, at time.Time) []Anomaly {
+	var found []Anomaly
+	covered := min(s.head-s.first+1, d.size)
+	for i, rule := range d.cfg.Rules {
+		if rule.Scope != "" && rule.Scope != key.scope {
+			continue
+		}
+		metric, window := metricWindow(rule.Metric)
+		value := s.sum(metric, window, d.size)
+
+		var baseline *float64
+		if history := covered - window; history >= d.minHistory {
+			avg := max(s.totals[metric]-value, 0) / float64(history) * float64(window)
+			baseline = &avg
+		}
+
+		threshold, fired := 0.0, false
+		if rule.Above > 0 && value > rule.Above {
+			threshold, fired = rule.Above, true
+		}
+		if rule.Multiple > 0 && baseline != nil && value > rule.MinValue {
+			if limit := rule.Multiple * *baseline; value > limit && (!fired || limit < threshold) {
+				threshold, fired = limit, true
+			}
+		}
+		if !fired {
+			continue
+		}
+		if last, ok := s.lastFired[i]; ok && at.Sub(last) < d.cfg.Cooldown {
+			continue
+		}
+		if s.lastFired == nil {
+			s.lastFired = make(map[int]time.Time)
+		}
+		s.lastFired[i] = at
+
+		a := Anomaly{
+			ID:         uuid.NewString(),
+			DetectedAt: at.UTC(),
+			Metric:     rule.Metric,
+			Scope:      key.scope,
+			Subject:    key.subject,
+			Value:      value,
+			Baseline:   baseline,
+			Threshold:  threshold,
+			Rule:       rule,
+		}
+		d.records = append(d.records, a)
+		if len(d.records) > d.cfg.MaxRecords {
+			d.records = slices.Delete(d.records, 0, len(d.records)-d.cfg.MaxRecords)
+		}
+		found = append(found, a)
+	}
+	return found
+}
+
+// metricWindow returns the bucket index of a metric and how many minutes its
+// value covers.
+func metricWindow(metric string) (int, int64) {
+	switch metric {
+	case config.AnomalyMetricTokensPerMinute:
+		return metricTokens, 1
+	case config.AnomalyMetricCostPerHour:
+		return metricCost, 60
+	default:
+		return metricRequests, 1
+	}
+}
+
+// List returns the recorded anomalies, newest first. With unacknowledged set,
+// acknowledged anomalies are left out.
+func (d *Detector) List(unacknowledged bool) []Anomaly {
+	if d == nil {
+		return []Anomaly{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make([]Anomaly, 0, len(d.records))
+	for i := len(d.records) - 1; i >= 0; i-- {
+		if unacknowledged && d.records[i].Acknowledged {
+			continue
+		}
+		result = append(result, d.records[i])
+	}
+	return result
+}
+
+// Acknowledge marks an anomaly as acknowledged. It reports false when no
+// anomaly has the ID. Acknowledging twice keeps the first time.
+func (d *Detector) Acknowledge(id string) (Anomaly, bool) {
+	if d == nil {
+		return Anomaly{}, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.records {
+		a := &d.records[i]
+		if a.ID != id {
+			continue
+		}
+		if !a.Acknowledged {
+			now := d.now().UTC()
+			a.Acknowledged = true
+			a.AcknowledgedAt = &now
+		}
+		return *a, true
+	}
+	return Anomaly{}, false
+}
+
+// MemoryUsage returns the number of tracked series and the estimated bytes
+// of the series and the recorded anomalies.
+func (d *Detector) MemoryUsage() (int, int64) {
+	if d == nil {
+		return 0, 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	perSeries := d.size*metricCount*8 + seriesOverheadBytes
+	return len(d.series), int64(len(d.series))*perSeries + int64(len(d.records))*recordBytes
+}
+
+// Evict drops the least recently used fraction of the series, rounded up. A
+// dropped series rebuilds its baseline from new usage. Recorded anomalies,
+// bounded by max_records, are kept.
+func (d *Detector) Evict(fraction float64) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := min(int(math.Ceil(fraction*float64(len(d.series)))), len(d.series))
+	if n == 0 {
+		return
+	}
+	keys := make([]seriesKey, 0, len(d.series))
+	for key := range d.series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int { return cmp.Compare(d.series[a].used, d.series[b].used) })
+	for _, key := range keys[:n] {
+		delete(d.series, key)
+	}
+}
+
+// Warm refills the series from the usage records of the baseline window, so
+// baselines survive a restart. It does not check rules and returns the
+// number of records read.
+func (d *Detector) Warm(ctx context.Context, reader usage.UsageReader) (int, error) {
+	if d == nil || reader == nil {
+		return 0, nil
+	}
+	end := d.now().UTC()
+	start := end.Add(-d.cfg.BaselineWindow)
+	params := usage.UsageLogParams{
+		UsageQueryParams: usage.UsageQueryParams{StartDate: start, EndDate: end, CacheMode: usage.CacheModeAll},
+		Limit:            warmPageSize,
+	}
+	read := 0
+	for read < maxWarmEntries {
+		page, err := reader.GetUsageLog(ctx, params)
+		if err != nil {
+			return read, err
+		}
+		for _, entry := range page.Entries {
+			// Pages are newest first; the range is day-precise.
+			if entry.Timestamp.Before(start) {
+				return read, nil
+			}
+			var cost float64
+			if entry.TotalCost != nil {
+				cost = *entry.TotalCost
+			}
+			d.observe(entry.Timestamp, entry.Model, entry.ProviderName, entry.AuthKeyID,
+				[metricCount]float64{1, float64(entry.TotalTokens), cost}, false)
+			read++
+		}
+		if len(page.Entries) < warmPageSize {
+			break
+		}
+		params.Offset += len(page.Entries)
+	}
+	return read, nil
+}
+
+// observedLogger passes every usage record it writes to the detector.
+type observedLogger struct {
+	usage.LoggerInterface
+	detector *Detector
+}
+
+func (l *observedLogger) Write(entry *usage.UsageEntry) {
+	l.LoggerInterface.Write(entry)
+	l.detector.Observe(entry)
+}
+
+// WrapLogger returns a usage logger that also feeds d. A nil detector returns
+// logger unchanged.
+func (d *Detector) WrapLogger(logger usage.LoggerInterface) usage.LoggerInterface {
+	if d == nil || logger == nil {
+		return logger
+	}
+	return &observedLogger{LoggerInterface: logger, detector: d}
+}
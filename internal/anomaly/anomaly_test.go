@@ -0,0 +1,217 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/usage"
+)
+
+var testStart = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestDetector(rules ...config.AnomalyRule) *Detector {
+	d := New(config.AnomalyDetectionConfig{
+		Enabled:        true,
+		BaselineWindow: 6 * time.Hour,
+		MinBaseline:    time.Hour,
+		Cooldown:       15 * time.Minute,
+		MaxSeries:      100,
+		MaxRecords:     100,
+		Rules:          rules,
+	})
+	d.now = func() time.Time { return testStart.Add(24 * time.Hour) }
+	return d
+}
+
+// drive sends perMinute(m) requests in each minute m of [from, to), each
+// with the given tokens and cost.
+func drive(d *Detector, from, to int, perMinute func(minute int) int, tokens int, cost float64) {
+	for m := from; m < to; m++ {
+		at := testStart.Add(time.Duration(m) * time.Minute)
+		for i := range perMinute(m) {
+			d.Observe(&usage.UsageEntry{
+				Timestamp:   at.Add(time.Duration(i) * time.Millisecond),
+				Model:       "gpt-4o",
+				Provider:    "openai",
+				AuthKeyID:   "key-1",
+				TotalTokens: tokens,
+				TotalCost:   &cost,
+			})
+		}
+	}
+}
+
+func constant(n int) func(int) int { return func(int) int { return n } }
+
+func anomaliesFor(d *Detector, scope string) []Anomaly {
+	var result []Anomaly
+	for _, a := range d.List(false) {
+		if a.Scope == scope {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func TestDetector_SpikeAboveBaselineMultipleFires(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Scope: config.AnomalyScopeKey, Multiple: 5, MinValue: 10})
+
+	drive(d, 0, 120, constant(4), 100, 0.01)
+	if got := d.List(false); len(got) != 0 {
+		t.Fatalf("steady traffic fired %v", got)
+	}
+
+	drive(d, 120, 121, constant(40), 100, 0.01)
+	got := anomaliesFor(d, config.AnomalyScopeKey)
+	if len(got) != 1 {
+		t.Fatalf("spike fired %d anomalies, want 1", len(got))
+	}
+	a := got[0]
+	if a.Subject != "key-1" || a.Metric != config.AnomalyMetricRequestsPerMinute || a.Baseline == nil || *a.Baseline != 4 || a.Threshold != 20 || a.Value != 21 {
+		t.Fatalf("anomaly = %+v, want key-1 firing at 21 requests above 5x a baseline of 4", a)
+	}
+}
+
+func TestDetector_GradualRampDoesNotFire(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricTokensPerMinute, Multiple: 3, MinValue: 100})
+
+	// Doubling over three hours stays within 3x of the moving baseline.
+	drive(d, 0, 180, func(m int) int { return 2 + m/90 }, 500, 0)
+	if got := d.List(false); len(got) != 0 {
+		t.Fatalf("ramp fired %d anomalies: %+v", len(got), got[0])
+	}
+}
+
+func TestDetector_SustainedLoadNeedsAbsoluteThreshold(t *testing.T) {
+	d := newTestDetector(
+		config.AnomalyRule{Metric: config.AnomalyMetricCostPerHour, Scope: config.AnomalyScopeModel, Multiple: 2},
+		config.AnomalyRule{Metric: config.AnomalyMetricCostPerHour, Scope: config.AnomalyScopeModel, Above: 50},
+	)
+
+	// $1 per minute from the first minute: the baseline is the load itself,
+	// so only the absolute rule fires, once per cooldown.
+	drive(d, 0, 180, constant(1), 1000, 1)
+	got := anomaliesFor(d, config.AnomalyScopeModel)
+	if len(got) == 0 {
+		t.Fatal("sustained $60/h did not fire the $50/h rule")
+	}
+	for _, a := range got {
+		if a.Rule.Above != 50 || a.Threshold != 50 || a.Subject != "gpt-4o" {
+			t.Fatalf("anomaly = %+v, want only the absolute rule for gpt-4o", a)
+		}
+	}
+	// Fires at minute 50, then every 15 minutes while the load lasts.
+	if want := 1 + (179-50)/15; len(got) != want {
+		t.Fatalf("fired %d times, want %d with a 15m cooldown", len(got), want)
+	}
+}
+
+func TestDetector_MultipleWaitsForMinimumBaseline(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Multiple: 5, MinValue: 10})
+
+	drive(d, 0, 30, constant(1), 10, 0)
+	drive(d, 30, 31, constant(50), 10, 0)
+	if got := d.List(false); len(got) != 0 {
+		t.Fatalf("spike within the first hour fired %d anomalies", len(got))
+	}
+}
+
+func TestDetector_MinValueSuppressesSmallSeries(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Multiple: 5, MinValue: 10})
+
+	drive(d, 0, 120, func(m int) int { return m % 60 / 59 }, 10, 0) // one request per hour
+	drive(d, 120, 121, constant(8), 10, 0)
+	if got := d.List(false); len(got) != 0 {
+		t.Fatalf("8 requests against min_value 10 fired %d anomalies", len(got))
+	}
+}
+
+func TestDetector_Acknowledge(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Scope: config.AnomalyScopeProvider, Above: 5})
+	drive(d, 0, 1, constant(6), 10, 0)
+
+	all := d.List(false)
+	if len(all) != 1 {
+		t.Fatalf("List() = %d anomalies, want 1", len(all))
+	}
+	acked, ok := d.Acknowledge(all[0].ID)
+	if !ok || !acked.Acknowledged || acked.AcknowledgedAt == nil {
+		t.Fatalf("Acknowledge() = %+v, %v", acked, ok)
+	}
+	if got := d.List(true); len(got) != 0 {
+		t.Fatalf("List(unacknowledged) = %v, want empty", got)
+	}
+	if _, ok := d.Acknowledge("missing"); ok {
+		t.Fatal("Acknowledge() found an unknown ID")
+	}
+}
+
+type fakeUsageReader struct {
+	usage.UsageReader
+	entries []usage.UsageLogEntry // newest first
+}
+
+func (r *fakeUsageReader) GetUsageLog(_ context.Context, params usage.UsageLogParams) (*usage.UsageLogResult, error) {
+	end := min(params.Offset+params.Limit, len(r.entries))
+	return &usage.UsageLogResult{Entries: r.entries[params.Offset:end], Total: len(r.entries), Limit: params.Limit, Offset: params.Offset}, nil
+}
+
+func TestDetector_WarmRestoresBaselines(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Scope: config.AnomalyScopeKey, Multiple: 5, MinValue: 10})
+	now := d.now()
+
+	reader := &fakeUsageReader{}
+	for m := 1; m <= 120; m++ {
+		for range 4 {
+			reader.entries = append(reader.entries, usage.UsageLogEntry{
+				Timestamp:    now.Add(-time.Duration(m) * time.Minute),
+				Model:        "gpt-4o",
+				ProviderName: "openai",
+				AuthKeyID:    "key-1",
+				TotalTokens:  10,
+			})
+		}
+	}
+	// Older than the baseline window: ignored.
+	reader.entries = append(reader.entries, usage.UsageLogEntry{Timestamp: now.Add(-7 * time.Hour), Model: "old"})
+
+	read, err := d.Warm(context.Background(), reader)
+	if err != nil || read != 480 {
+		t.Fatalf("Warm() = %d, %v, want 480 records", read, err)
+	}
+	if got := d.List(false); len(got) != 0 {
+		t.Fatalf("Warm() fired %d anomalies", len(got))
+	}
+
+	for range 30 {
+		d.Observe(&usage.UsageEntry{Timestamp: now, Model: "gpt-4o", Provider: "openai", AuthKeyID: "key-1"})
+	}
+	got := anomaliesFor(d, config.AnomalyScopeKey)
+	if len(got) != 1 || got[0].Baseline == nil || *got[0].Baseline != 4 {
+		t.Fatalf("anomalies after warm = %+v, want one against the restored baseline of 4", got)
+	}
+}
+
+func TestDetector_EvictsLeastRecentlyUsedSeries(t *testing.T) {
+	d := newTestDetector(config.AnomalyRule{Metric: config.AnomalyMetricRequestsPerMinute, Above: 100})
+	d.cfg.MaxSeries = 3
+
+	d.Observe(&usage.UsageEntry{Timestamp: testStart, Model: "a", Provider: "p"})
+	d.Observe(&usage.UsageEntry{Timestamp: testStart, Model: "b", Provider: "p"})
+	d.Observe(&usage.UsageEntry{Timestamp: testStart, Model: "c", Provider: "p"})
+
+	if len(d.series) != 3 {
+		t.Fatalf("tracked %d series, want 3", len(d.series))
+	}
+	if _, ok := d.series[seriesKey{scope: config.AnomalyScopeModel, subject: "a"}]; ok {
+		t.Fatal("least recently used series was kept")
+	}
+}
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	if d := New(config.AnomalyDetectionConfig{}); d != nil {
+		t.Fatalf("New() = %v, want nil when disabled", d)
+	}
+}
@@ -10,26 +10,57 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"gomodel/config"
+	"gomodel/internal/accesslog"
 	"gomodel/internal/admin"
 	"gomodel/internal/admin/dashboard"
 	"gomodel/internal/aliases"
+	"gomodel/internal/anomaly"
 	"gomodel/internal/auditlog"
 	"gomodel/internal/authkeys"
 	"gomodel/internal/batch"
+	"gomodel/internal/cache/modelcache"
+	"gomodel/internal/chaos"
 	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/deprecations"
+	"gomodel/internal/embeddingcache"
+	"gomodel/internal/experiments"
 	"gomodel/internal/fallback"
+	"gomodel/internal/gateway"
 	"gomodel/internal/guardrails"
+	"gomodel/internal/idempotency"
+	"gomodel/internal/inspect"
+	"gomodel/internal/keyhealth"
+	"gomodel/internal/maintenance"
+	"gomodel/internal/maxtokens"
+	"gomodel/internal/memgovernor"
+	"gomodel/internal/migrations"
+	"gomodel/internal/modelgroups"
 	"gomodel/internal/modeloverrides"
+	"gomodel/internal/moderation"
+	"gomodel/internal/playground"
+	"gomodel/internal/probe"
+	"gomodel/internal/promptcache"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/provenance"
 	"gomodel/internal/providers"
 	"gomodel/internal/responsecache"
+	"gomodel/internal/responsetransform"
+	"gomodel/internal/sanitize"
+	"gomodel/internal/scoreboard"
+	"gomodel/internal/secretscan"
+	"gomodel/internal/selfprotect"
 	"gomodel/internal/server"
 	"gomodel/internal/storage"
+	"gomodel/internal/tools"
 	"gomodel/internal/usage"
+	"gomodel/internal/usagejobs"
 	"gomodel/internal/workflows"
 )
 
@@ -42,10 +73,29 @@ type App struct {
 	usage          *usage.Result
 	batch          *batch.Result
 	aliases        *aliases.Result
+	templates      *prompttemplates.Result
 	modelOverrides *modeloverrides.Result
 	authKeys       *authkeys.Result
 	guardrails     *guardrails.Result
 	workflows      *workflows.Result
+	deferred       *deferred.Result
+	usageJobs      *usagejobs.Result
+	experiments    *experiments.Service
+	modelGroups    *modelgroups.Service
+	chaos          *chaos.Injector
+	selfProtection *selfprotect.Guard
+	secrets        *secretscan.Scanner
+	accessLog      *accesslog.Logger
+	deprecations   *deprecations.Registry
+	transforms     *responsetransform.Service
+	provenance     *provenance.Signer
+	sanitizer      *sanitize.Sanitizer
+	maintenance    *maintenance.Mode
+	inspector      *inspect.Inspector
+	anomalies      *anomaly.Detector
+	keyHealth      *keyhealth.Checker
+	maxTokens      *maxtokens.Policy
+	memory         *memgovernor.Governor
 	server         *server.Server
 
 	shutdownMu  sync.Mutex
@@ -84,8 +134,82 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 
 	appCfg := cfg.AppConfig.Config
 
+	experimentService, err := experiments.New(appCfg.Experiments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid experiments config: %w", err)
+	}
+
+	modelGroupService, err := modelgroups.New(appCfg.ModelGroups)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model_groups config: %w", err)
+	}
+
+	chaosInjector, err := chaos.New(appCfg.Chaos)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chaos config: %w", err)
+	}
+	if chaosInjector != nil {
+		slog.Warn("fault injection is enabled; do not run this configuration in production", "rules", len(chaosInjector.Rules()))
+	}
+
+	selfProtection, err := selfprotect.New(appCfg.SelfProtection)
+	if err != nil {
+		return nil, fmt.Errorf("invalid self_protection config: %w", err)
+	}
+	if selfProtection != nil {
+		slog.Info("self-protection enabled", "action", selfProtection.Action(), "patterns", len(selfProtection.Patterns()))
+	}
+
+	secrets, err := secretscan.New(appCfg.SecretRedaction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret_redaction config: %w", err)
+	}
+	if secrets != nil {
+		slog.Info("secret redaction enabled", "detectors", secrets.Detectors())
+	}
+
+	accessLog, err := accesslog.New(appCfg.AccessLog)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access_log config: %w", err)
+	}
+	if accessLog != nil {
+		slog.Info("access log enabled", "format", accessLog.Format(), "output", accessLog.Output())
+	}
+
+	deprecationRegistry, err := deprecations.New(appCfg.Deprecations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deprecations config: %w", err)
+	}
+	if deprecationRegistry != nil {
+		slog.Info("deprecation warnings enabled", "deprecations", deprecationRegistry.Len(), "after_sunset", deprecationRegistry.AfterSunset())
+	}
+
+	responseTransforms, err := responsetransform.New(appCfg.ResponseTransforms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response_transforms config: %w", err)
+	}
+	if responseTransforms != nil {
+		slog.Info("response transforms enabled", "pipelines", len(responseTransforms.Pipelines()))
+	}
+
 	app := &App{
-		config: appCfg,
+		config:         appCfg,
+		experiments:    experimentService,
+		modelGroups:    modelGroupService,
+		chaos:          chaosInjector,
+		selfProtection: selfProtection,
+		secrets:        secrets,
+		accessLog:      accessLog,
+		deprecations:   deprecationRegistry,
+		transforms:     responseTransforms,
+		provenance:     provenance.New(appCfg.Provenance),
+		sanitizer:      sanitize.New(appCfg.ResponseSanitization),
+		maintenance:    maintenance.New(appCfg.Maintenance),
+		inspector:      inspect.New(secrets),
+	}
+
+	if err := prepareStorageSchema(ctx, appCfg.Storage); err != nil {
+		return nil, fmt.Errorf("failed to prepare storage schema: %w", err)
 	}
 
 	providerResult, err := providers.Init(ctx, cfg.AppConfig, cfg.Factory)
@@ -93,6 +217,13 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize providers: %w", err)
 	}
 	app.providers = providerResult
+	if app.deprecations != nil {
+		registry := providerResult.Registry
+		registry.OnRefresh(func() { app.deprecations.CrossCheck(registry) })
+		if registry.IsInitialized() {
+			app.deprecations.CrossCheck(registry)
+		}
+	}
 
 	// Initialize audit logging
 	auditResult, err := auditlog.New(ctx, appCfg)
@@ -134,6 +265,9 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		return nil, fmt.Errorf("usage tracking initialization returned nil result")
 	}
 	app.usage = usageResult
+	app.memory = memgovernor.New(appCfg.Memory)
+	app.initAnomalyDetection(ctx, appCfg.Anomalies, auditResult.Storage)
+	app.initMaxTokensDefaults(ctx, appCfg.DefaultMaxTokens, auditResult.Storage)
 
 	// Initialize batch lifecycle storage.
 	var batchResult *batch.Result
@@ -296,6 +430,59 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 	}
 	app.authKeys = authKeyResult
 
+	var templateResult *prompttemplates.Result
+	sharedTemplateStorage := firstSharedStorage(
+		auditResult.Storage,
+		usageResult.Storage,
+		batchResult.Storage,
+		aliasResult.Storage,
+		modelOverrideResult.Storage,
+		guardrailResult.Storage,
+		workflowResult.Storage,
+		authKeyResult.Storage,
+	)
+	if sharedTemplateStorage != nil {
+		templateResult, err = prompttemplates.NewWithSharedStorage(ctx, appCfg, sharedTemplateStorage)
+	} else {
+		templateResult, err = prompttemplates.New(ctx, appCfg)
+	}
+	if err != nil {
+		closeErr := errors.Join(app.authKeys.Close(), workflowResult.Close(), app.guardrails.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+		if closeErr != nil {
+			return nil, fmt.Errorf("failed to initialize prompt templates: %w (also: close error: %v)", err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to initialize prompt templates: %w", err)
+	}
+	app.templates = templateResult
+
+	if appCfg.Deferred.Enabled {
+		var deferredResult *deferred.Result
+		sharedDeferredStorage := firstSharedStorage(
+			auditResult.Storage,
+			usageResult.Storage,
+			batchResult.Storage,
+			aliasResult.Storage,
+			modelOverrideResult.Storage,
+			guardrailResult.Storage,
+			workflowResult.Storage,
+			authKeyResult.Storage,
+			templateResult.Storage,
+		)
+		if sharedDeferredStorage != nil {
+			deferredResult, err = deferred.NewWithSharedStorage(ctx, sharedDeferredStorage, appCfg.Deferred)
+		} else {
+			deferredResult, err = deferred.New(ctx, appCfg)
+		}
+		if err != nil {
+			closeErr := errors.Join(app.templates.Close(), app.authKeys.Close(), workflowResult.Close(), app.guardrails.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+			if closeErr != nil {
+				return nil, fmt.Errorf("failed to initialize deferred requests: %w (also: close error: %v)", err, closeErr)
+			}
+			return nil, fmt.Errorf("failed to initialize deferred requests: %w", err)
+		}
+		app.deferred = deferredResult
+	}
+
 	// Log configuration status after auth has been initialized so the startup
 	// message reflects both bootstrap and managed auth modes.
 	app.logStartupInfo()
@@ -323,6 +510,20 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 	}
 	batchRequestPreparer := server.ComposeBatchRequestPreparers(providerAsNativeFileRouter(provider), batchRequestPreparers...)
 
+	var board *scoreboard.Scoreboard
+	if appCfg.Scoreboard.Enabled {
+		board = scoreboard.New(scoreboard.WithMaxModels(appCfg.Scoreboard.MaxModels))
+		app.memory.Register("scoreboard", memgovernor.PriorityNormal, board)
+	}
+
+	requestModelResolver := modelResolver(app.aliases.Service, app.experiments)
+	var modelGroupResolver *modelgroups.Resolver
+	if app.modelGroups != nil {
+		modelGroupResolver = modelgroups.NewResolver(app.modelGroups, requestModelResolver, providerResult.Registry, board)
+		requestModelResolver = modelGroupResolver
+		slog.Info("model groups enabled", "groups", len(app.modelGroups.Groups()))
+	}
+
 	// Create server
 	allowPassthroughV1Alias := appCfg.Server.AllowPassthroughV1Alias
 	serverCfg := &server.Config{
@@ -335,13 +536,14 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		AuditLogger:                     auditResult.Logger,
 		UsageLogger:                     usageResult.Logger,
 		PricingResolver:                 providerResult.Registry,
-		ModelResolver:                   app.aliases.Service,
+		ModelResolver:                   requestModelResolver,
 		ModelAuthorizer:                 app.modelOverrides.Service,
 		FallbackResolver:                fallback.NewResolver(appCfg.Fallback, providerResult.Registry),
 		WorkflowPolicyResolver:          workflowResult.Service,
 		TranslatedRequestPatcher:        translatedRequestPatcher,
 		BatchRequestPreparer:            batchRequestPreparer,
 		ExposedModelLister:              app.aliases.Service,
+		ModelGroupLister:                modelGroupLister(app.modelGroups),
 		KeepOnlyAliasesAtModelsEndpoint: appCfg.Models.KeepOnlyAliasesAtModelsEndpoint,
 		PassthroughSemanticEnrichers:    cfg.Factory.PassthroughSemanticEnrichers(),
 		BatchStore:                      batchResult.Store,
@@ -349,7 +551,93 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		DisablePassthroughRoutes:        !appCfg.Server.EnablePassthroughRoutes,
 		EnabledPassthroughProviders:     appCfg.Server.EnabledPassthroughProviders,
 		AllowPassthroughV1Alias:         &allowPassthroughV1Alias,
+		EnableAssistantsPassthrough:     appCfg.Server.EnableAssistantsPassthrough,
 		SwaggerEnabled:                  appCfg.Server.SwaggerEnabled,
+		ComparisonLimits: server.ComparisonLimits{
+			MaxModels:        appCfg.Comparison.MaxModels,
+			MaxConcurrency:   appCfg.Comparison.MaxConcurrency,
+			MaxEstimatedCost: appCfg.Comparison.MaxEstimatedCost,
+		},
+		ContextOverflow:   contextOverflowConfig(appCfg.ContextOverflow, providerResult.Registry),
+		PromptCompression: promptCompressionConfig(appCfg.PromptCompression),
+		FirstToken: gateway.FirstTokenConfig{
+			Timeout:   appCfg.FirstToken.Timeout,
+			Providers: appCfg.FirstToken.Providers,
+			Models:    appCfg.FirstToken.Models,
+		},
+		InlineImageLimits:    inlineImageLimits(appCfg.Server),
+		PromptTemplates:      app.templates.Service,
+		StrictOpenAICompat:   appCfg.Server.StrictOpenAICompat,
+		StrictRequestOptions: appCfg.Server.StrictRequestOptions,
+		RecordRawUser:        appCfg.Server.RecordRawUser,
+		StreamBackpressure:   streamBackpressure(appCfg.Server, appCfg.StreamLimits),
+		RequestBodyStreaming: requestBodyStreaming(appCfg.Server),
+	}
+	if app.deferred != nil {
+		serverCfg.Deferred = app.deferred.Service
+	}
+	if usageResult.Logger.Config().Enabled {
+		reader, err := newUsageReader(auditResult.Storage, usageResult.Storage)
+		if err != nil {
+			slog.Warn("failed to initialize usage reader for GET /v1/usage", "error", err)
+		} else {
+			serverCfg.UsageReader = reader
+		}
+	}
+	if service := idempotency.New(appCfg.Idempotency); service != nil {
+		serverCfg.Idempotency = service
+		app.memory.Register("idempotency", memgovernor.PriorityHigh, service)
+		slog.Info("idempotency keys enabled",
+			"ttl", appCfg.Idempotency.TTL,
+			"max_entries", appCfg.Idempotency.MaxEntries,
+			"stream_duplicates", appCfg.Idempotency.StreamDuplicates)
+	}
+	if policy := upstreamHeaderPolicy(appCfg.UpstreamHeaders, cfg.AppConfig.RawProviders); policy != nil {
+		serverCfg.UpstreamResponseHeaders = policy
+		slog.Info("upstream response header passthrough enabled",
+			"allow", appCfg.UpstreamHeaders.Allow,
+			"prefix", appCfg.UpstreamHeaders.Prefix)
+	}
+	if appCfg.WebSocket.Enabled {
+		serverCfg.WebSocket = &server.WebSocketConfig{
+			PingInterval: appCfg.WebSocket.PingInterval,
+			MaxDuration:  appCfg.WebSocket.MaxDuration,
+		}
+		slog.Info("websocket chat streaming enabled",
+			"ping_interval", appCfg.WebSocket.PingInterval,
+			"max_duration", appCfg.WebSocket.MaxDuration)
+	}
+	serverCfg.Chaos = app.chaos
+	serverCfg.SelfProtection = app.selfProtection
+	serverCfg.SecretRedaction = app.secrets
+	serverCfg.AccessLog = app.accessLog
+	serverCfg.ResponseTransforms = app.transforms
+	serverCfg.Provenance = app.provenance
+	serverCfg.ResponseSanitization = app.sanitizer
+	serverCfg.Maintenance = app.maintenance
+	serverCfg.Inspector = app.inspector
+	serverCfg.Deprecations = app.deprecations
+	serverCfg.MaxTokensDefaults = app.maxTokens
+	if embeddingCache := embeddingcache.New(appCfg.Cache.Embeddings); embeddingCache != nil {
+		serverCfg.EmbeddingCache = embeddingCache
+		app.memory.Register("embeddings_cache", memgovernor.PriorityLow, embeddingCache)
+		slog.Info("embeddings cache enabled",
+			"max_entries", appCfg.Cache.Embeddings.MaxEntries,
+			"max_bytes", appCfg.Cache.Embeddings.MaxBytes)
+	}
+
+	if board != nil {
+		serverCfg.Scoreboard = board
+	}
+
+	promptCache := promptcache.New(appCfg.PromptCaching, providerResult.Registry)
+	if promptCache != nil {
+		serverCfg.PromptCache = promptCache
+		app.memory.Register("prompt_cache", memgovernor.PriorityNormal, promptCache)
+		slog.Info("prompt caching enabled",
+			"models", appCfg.PromptCaching.Models,
+			"prefixes", len(appCfg.PromptCaching.Prefixes),
+			"auto_detect", appCfg.PromptCaching.AutoDetect)
 	}
 
 	// Initialize admin API and dashboard (behind separate feature flags)
@@ -360,18 +648,50 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 	}
 	usageEnabledForDashboard := usageResult.Logger.Config().Enabled
 	if adminCfg.EndpointsEnabled {
+		if usageEnabledForDashboard {
+			usageJobsResult, jobsErr := newUsageJobs(ctx, auditResult.Storage, usageResult.Storage, providerResult.Registry, appCfg.Usage)
+			if jobsErr != nil {
+				slog.Warn("failed to initialize usage jobs", "error", jobsErr)
+			}
+			app.usageJobs = usageJobsResult
+		}
+		localModelCache, _ := providerResult.Cache.(*modelcache.LocalCache)
 		adminHandler, dashHandler, adminErr := initAdmin(
 			auditResult.Storage,
 			usageResult.Storage,
+			auditResult.StreamSamples,
+			auditResult.Keyring,
+			appCfg.Logging,
 			providerResult.Registry,
 			providerResult.ConfiguredProviders,
 			authKeyResult.Service,
 			app.aliases.Service,
+			app.templates.Service,
 			app.modelOverrides.Service,
 			workflowResult.Service,
 			app.guardrails.Service,
+			app.experiments,
+			modelGroupResolver,
+			deferredService(app.deferred),
+			usageJobsService(app.usageJobs),
+			app.chaos,
+			app.provenance,
+			app.sanitizer,
+			app.maintenance,
+			app.inspector,
+			app.deprecations,
+			probe.New(appCfg.CapabilityProbe, usageResult.Logger, providerResult.Registry),
 			app,
 			dashboardRuntimeConfig(appCfg, usageEnabledForDashboard),
+			effectiveConfig(cfg.AppConfig),
+			board,
+			promptCache,
+			app.anomalies,
+			localModelCache,
+			pipelineStatsSource(auditResult.Logger),
+			pipelineStatsSource(usageResult.Logger),
+			app.memory,
+			adminCfg.MaxQueryDays,
 			adminCfg.UIEnabled,
 		)
 		if adminErr != nil {
@@ -393,6 +713,15 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 	if appCfg.Server.SwaggerEnabled {
 		slog.Info("swagger UI enabled", "path", "/swagger/index.html")
 	}
+	if appCfg.Server.PlaygroundEnabled {
+		playgroundHandler, err := playground.New()
+		if err != nil {
+			slog.Warn("failed to initialize playground", "error", err)
+		} else {
+			serverCfg.PlaygroundHandler = playgroundHandler
+			slog.Info("playground enabled", "url", fmt.Sprintf("http://localhost:%s/playground", appCfg.Server.Port))
+		}
+	}
 	if appCfg.Server.PprofEnabled {
 		slog.Info("pprof enabled", "path", "/debug/pprof/")
 	}
@@ -430,7 +759,7 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		if app.batch != nil {
 			batchCloseErr = app.batch.Close()
 		}
-		closeErr := errors.Join(workflowsCloseErr, guardrailsCloseErr, authKeysCloseErr, aliasCloseErr, modelOverridesCloseErr, batchCloseErr, app.usage.Close(), app.audit.Close(), app.providers.Close())
+		closeErr := errors.Join(app.deferred.Close(), workflowsCloseErr, guardrailsCloseErr, app.templates.Close(), authKeysCloseErr, aliasCloseErr, modelOverridesCloseErr, batchCloseErr, app.usage.Close(), app.audit.Close(), app.providers.Close())
 		if closeErr != nil {
 			return nil, fmt.Errorf("failed to initialize response cache: %w (also: close error: %v)", err, closeErr)
 		}
@@ -439,7 +768,7 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 	serverCfg.ResponseCacheMiddleware = rcm
 
 	internalGuardrailExecutor := server.NewInternalChatCompletionExecutor(provider, server.InternalChatCompletionExecutorConfig{
-		ModelResolver:          app.aliases.Service,
+		ModelResolver:          requestModelResolver,
 		ModelAuthorizer:        app.modelOverrides.Service,
 		WorkflowPolicyResolver: workflowResult.Service,
 		FallbackResolver:       serverCfg.FallbackResolver,
@@ -449,21 +778,85 @@ func New(ctx context.Context, cfg Config) (*App, error) {
 		ResponseCache:          rcm,
 	})
 	if err := guardrailResult.Service.SetExecutor(ctx, internalGuardrailExecutor); err != nil {
-		closeErr := errors.Join(rcm.Close(), app.workflows.Close(), app.guardrails.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+		closeErr := errors.Join(rcm.Close(), app.deferred.Close(), app.workflows.Close(), app.guardrails.Close(), app.templates.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
 		if closeErr != nil {
 			return nil, fmt.Errorf("failed to wire internal guardrail executor: %w (also: close error: %v)", err, closeErr)
 		}
 		return nil, fmt.Errorf("failed to wire internal guardrail executor: %w", err)
 	}
 	if err := workflowResult.Service.Refresh(ctx); err != nil {
-		closeErr := errors.Join(rcm.Close(), app.workflows.Close(), app.guardrails.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+		closeErr := errors.Join(rcm.Close(), app.deferred.Close(), app.workflows.Close(), app.guardrails.Close(), app.templates.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
 		if closeErr != nil {
 			return nil, fmt.Errorf("failed to refresh workflows after wiring internal guardrail executor: %w (also: close error: %v)", err, closeErr)
 		}
 		return nil, fmt.Errorf("failed to refresh workflows after wiring internal guardrail executor: %w", err)
 	}
 
+	if appCfg.Moderation.Enabled {
+		moderator, err := moderation.NewChatModerator(internalGuardrailExecutor, appCfg.Moderation.Model, appCfg.Moderation.Provider)
+		if err != nil {
+			closeErr := errors.Join(rcm.Close(), app.deferred.Close(), app.workflows.Close(), app.guardrails.Close(), app.templates.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+			if closeErr != nil {
+				return nil, fmt.Errorf("failed to initialize moderation: %w (also: close error: %v)", err, closeErr)
+			}
+			return nil, fmt.Errorf("failed to initialize moderation: %w", err)
+		}
+		serverCfg.Moderation = moderationConfig(appCfg.Moderation, moderator)
+		slog.Info("moderation pre-check enabled",
+			"model", appCfg.Moderation.Model,
+			"action", appCfg.Moderation.Action,
+			"fail_open", appCfg.Moderation.FailOpen,
+		)
+	}
+
+	if appCfg.ServerTools.Enabled {
+		serverTools, err := serverToolsConfig(appCfg.ServerTools)
+		if err != nil {
+			closeErr := errors.Join(rcm.Close(), app.deferred.Close(), app.workflows.Close(), app.guardrails.Close(), app.templates.Close(), app.authKeys.Close(), app.modelOverrides.Close(), app.aliases.Close(), app.batch.Close(), app.usage.Close(), app.audit.Close(), app.providers.Close())
+			if closeErr != nil {
+				return nil, fmt.Errorf("failed to initialize server tools: %w (also: close error: %v)", err, closeErr)
+			}
+			return nil, fmt.Errorf("failed to initialize server tools: %w", err)
+		}
+		serverCfg.ServerTools = serverTools
+		slog.Info("server-side tools enabled",
+			"grants", len(serverTools.Grants),
+			"max_iterations", serverTools.MaxIterations,
+			"timeout", serverTools.Timeout,
+		)
+	}
+
 	app.server = server.New(provider, serverCfg)
+	if app.deferred != nil {
+		app.deferred.StartWorker(app.server.DeferredExecutor())
+		slog.Info("deferred execution enabled",
+			"ttl", appCfg.Deferred.TTL,
+			"max_attempts", appCfg.Deferred.MaxAttempts,
+		)
+	}
+	if app.usageJobs != nil {
+		app.usageJobs.StartWorker()
+	}
+	keyHealth, err := keyhealth.New(appCfg.KeyHealth, providerResult.Registry, usageResult.Logger)
+	if err != nil {
+		slog.Warn("failed to initialize key health checks", "error", err)
+	} else if keyHealth != nil {
+		app.keyHealth = keyHealth
+		keyHealth.Start()
+		slog.Info("key health checks enabled",
+			"interval", appCfg.KeyHealth.Interval,
+			"failure_threshold", appCfg.KeyHealth.FailureThreshold,
+			"webhook", appCfg.KeyHealth.WebhookURL != "",
+		)
+	}
+	if appCfg.Memory.BudgetBytes > 0 || appCfg.Memory.RSSCeilingBytes > 0 {
+		app.memory.Start()
+		slog.Info("memory guardrails enabled",
+			"budget_bytes", appCfg.Memory.BudgetBytes,
+			"rss_ceiling_bytes", appCfg.Memory.RSSCeilingBytes,
+			"check_interval", appCfg.Memory.CheckInterval,
+		)
+	}
 
 	return app, nil
 }
@@ -560,10 +953,11 @@ func (a *App) startServer(ctx context.Context, address string, start func(contex
 // Shutdown gracefully tears down app components in dependency order.
 // Order:
 // 1. Cancel HTTP server context and wait for the server to stop.
-// 2. Provider subsystem close (stops model refresh loop and cache resources).
-// 3. Batch store close.
-// 4. Usage logger close (flushes pending usage records).
-// 5. Audit logger close (flushes pending audit records).
+// 2. Deferred worker stop (no replays run once providers begin closing).
+// 3. Provider subsystem close (stops model refresh loop and cache resources).
+// 4. Batch store close.
+// 5. Usage logger close (flushes pending usage records).
+// 6. Audit logger close (flushes pending audit records).
 //
 // Shutdown is idempotent and safe for repeated calls; after the first call, subsequent calls are no-ops.
 // It attempts every close step, aggregates failures, and returns a joined error if any step fails.
@@ -605,7 +999,33 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 2. Close providers (stops model refresh and provider-owned resources)
+	// Deliver queued self-protection webhook events; no request can add more.
+	a.selfProtection.Close()
+	if err := a.accessLog.Close(); err != nil {
+		slog.Error("access log close error", "error", err)
+		errs = append(errs, fmt.Errorf("access log close: %w", err))
+	}
+
+	// Stop key health checks before the providers they call.
+	a.keyHealth.Close()
+	a.memory.Close()
+
+	// 2. Stop the deferred worker before the providers it replays through,
+	// and the usage job worker before the storage it writes to.
+	if a.deferred != nil {
+		if err := a.deferred.Close(); err != nil {
+			slog.Error("deferred close error", "error", err)
+			errs = append(errs, fmt.Errorf("deferred close: %w", err))
+		}
+	}
+	if a.usageJobs != nil {
+		if err := a.usageJobs.Close(); err != nil {
+			slog.Error("usage jobs close error", "error", err)
+			errs = append(errs, fmt.Errorf("usage jobs close: %w", err))
+		}
+	}
+
+	// 3. Close providers (stops model refresh and provider-owned resources)
 	if a.providers != nil {
 		if err := a.providers.Close(); err != nil {
 			slog.Error("providers close error", "error", err)
@@ -613,7 +1033,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 3. Close aliases subsystem.
+	// 4. Close aliases subsystem.
 	if a.aliases != nil {
 		if err := a.aliases.Close(); err != nil {
 			slog.Error("aliases close error", "error", err)
@@ -621,7 +1041,15 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 4. Close workflows subsystem.
+	// 5. Close prompt templates subsystem.
+	if a.templates != nil {
+		if err := a.templates.Close(); err != nil {
+			slog.Error("prompt templates close error", "error", err)
+			errs = append(errs, fmt.Errorf("prompt templates close: %w", err))
+		}
+	}
+
+	// 6. Close workflows subsystem.
 	if a.workflows != nil {
 		if err := a.workflows.Close(); err != nil {
 			slog.Error("workflows close error", "error", err)
@@ -629,7 +1057,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 5. Close model overrides subsystem.
+	// 7. Close model overrides subsystem.
 	if a.modelOverrides != nil {
 		if err := a.modelOverrides.Close(); err != nil {
 			slog.Error("model overrides close error", "error", err)
@@ -637,7 +1065,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 6. Close reusable guardrails subsystem.
+	// 8. Close reusable guardrails subsystem.
 	if a.guardrails != nil {
 		if err := a.guardrails.Close(); err != nil {
 			slog.Error("guardrails close error", "error", err)
@@ -645,7 +1073,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 7. Close managed auth keys subsystem.
+	// 9. Close managed auth keys subsystem.
 	if a.authKeys != nil {
 		if err := a.authKeys.Close(); err != nil {
 			slog.Error("auth keys close error", "error", err)
@@ -653,7 +1081,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 8. Close batch store (flushes pending entries)
+	// 10. Close batch store (flushes pending entries)
 	if a.batch != nil {
 		if err := a.batch.Close(); err != nil {
 			slog.Error("batch store close error", "error", err)
@@ -661,7 +1089,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 9. Close usage tracking (flushes pending entries)
+	// 11. Close usage tracking (flushes pending entries)
 	if a.usage != nil {
 		if err := a.usage.Close(); err != nil {
 			slog.Error("usage logger close error", "error", err)
@@ -669,7 +1097,7 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	// 10. Close audit logging (flushes pending logs)
+	// 12. Close audit logging (flushes pending logs)
 	if a.audit != nil {
 		if err := a.audit.Close(); err != nil {
 			slog.Error("audit logger close error", "error", err)
@@ -738,48 +1166,218 @@ func (a *App) logStartupInfo() {
 
 }
 
+// deferredService returns the deferred request service, or nil when deferred
+// execution is disabled.
+func deferredService(result *deferred.Result) *deferred.Service {
+	if result == nil {
+		return nil
+	}
+	return result.Service
+}
+
+// usageJobsService returns the usage job service, or nil when bulk usage
+// jobs are unavailable.
+func usageJobsService(result *usagejobs.Result) *usagejobs.Service {
+	if result == nil {
+		return nil
+	}
+	return result.Service
+}
+
+// newUsageJobs creates the bulk usage job service on the storage that holds
+// the usage data the admin API reads, preferring audit storage like
+// newUsageReader. Returns nil when there is no storage.
+func newUsageJobs(ctx context.Context, auditStorage, usageStorage storage.Storage, pricing usage.PricingResolver, cfg config.UsageConfig) (*usagejobs.Result, error) {
+	store := auditStorage
+	if store == nil {
+		store = usageStorage
+	}
+	if store == nil {
+		return nil, nil
+	}
+	return usagejobs.NewWithSharedStorage(ctx, store, pricing, cfg)
+}
+
+// modelResolver returns the selector resolver used for workflow resolution:
+// the alias service, wrapped with experiment assignment when experiments are
+// configured.
+func modelResolver(aliasService *aliases.Service, experimentService *experiments.Service) gateway.ModelResolver {
+	if experimentService == nil {
+		return aliasService
+	}
+	if aliasService == nil {
+		return experiments.NewResolver(experimentService, nil)
+	}
+	return experiments.NewResolver(experimentService, aliasService)
+}
+
+// modelGroupLister returns the model groups listed by GET /v1/models, or nil
+// when none are configured.
+func modelGroupLister(service *modelgroups.Service) server.ExposedModelLister {
+	if service == nil {
+		return nil
+	}
+	return service
+}
+
+// newUsageReader creates a usage reader on the first available storage
+// connection. It returns nil when there is no storage.
+func newUsageReader(auditStorage, usageStorage storage.Storage) (usage.UsageReader, error) {
+	var store storage.Storage
+	if auditStorage != nil {
+		store = auditStorage
+	} else if usageStorage != nil {
+		store = usageStorage
+	}
+	if store == nil {
+		return nil, nil
+	}
+	reader, err := usage.NewReader(store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create usage reader: %w", err)
+	}
+	return reader, nil
+}
+
+// initAnomalyDetection starts usage anomaly detection when it is enabled and
+// usage tracking is on. Every usage record then passes through the detector,
+// and its baselines are warmed from the usage store in the background.
+func (a *App) initAnomalyDetection(ctx context.Context, cfg config.AnomalyDetectionConfig, auditStorage storage.Storage) {
+	if !cfg.Enabled {
+		return
+	}
+	if !a.usage.Logger.Config().Enabled {
+		slog.Warn("anomaly detection needs usage tracking; set USAGE_ENABLED=true to use it")
+		return
+	}
+	a.anomalies = anomaly.New(cfg)
+	a.memory.Register("anomaly_baselines", memgovernor.PriorityNormal, a.anomalies)
+	a.usage.Logger = a.anomalies.WrapLogger(a.usage.Logger)
+	slog.Info("usage anomaly detection enabled",
+		"baseline_window", cfg.BaselineWindow,
+		"rules", len(cfg.Rules))
+
+	reader, err := newUsageReader(auditStorage, a.usage.Storage)
+	if err != nil || reader == nil {
+		slog.Warn("anomaly baselines start empty: usage store is not readable", "error", err)
+		return
+	}
+	detector := a.anomalies
+	go func() {
+		warmCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), anomalyWarmTimeout)
+		defer cancel()
+		read, err := detector.Warm(warmCtx, reader)
+		if err != nil {
+			slog.Warn("failed to warm anomaly baselines from usage store", "records", read, "error", err)
+			return
+		}
+		slog.Info("anomaly baselines warmed from usage store", "records", read)
+	}()
+}
+
+// anomalyWarmTimeout bounds reading recent usage into anomaly baselines at
+// startup.
+const anomalyWarmTimeout = 2 * time.Minute
+
+// initMaxTokensDefaults builds the max_tokens default policy. With dynamic
+// defaults, every usage record then passes through the policy, and its
+// completion lengths are warmed from the usage store in the background.
+// Without usage tracking, dynamic defaults fall back to the static ones.
+func (a *App) initMaxTokensDefaults(ctx context.Context, cfg config.DefaultMaxTokensConfig, auditStorage storage.Storage) {
+	policy := maxtokens.New(cfg)
+	if policy == nil {
+		return
+	}
+	a.maxTokens = policy
+	slog.Info("max_tokens defaults enabled",
+		"default", cfg.Default,
+		"models", len(cfg.Models),
+		"dynamic", cfg.Dynamic.Enabled)
+	if !policy.DynamicEnabled() {
+		return
+	}
+	if !a.usage.Logger.Config().Enabled {
+		slog.Warn("dynamic max_tokens defaults need usage tracking; set USAGE_ENABLED=true to use them")
+		return
+	}
+	a.usage.Logger = policy.WrapLogger(a.usage.Logger)
+	a.memory.Register("max_tokens_samples", memgovernor.PriorityNormal, policy)
+
+	reader, err := newUsageReader(auditStorage, a.usage.Storage)
+	if err != nil || reader == nil {
+		slog.Warn("dynamic max_tokens defaults start empty: usage store is not readable", "error", err)
+		return
+	}
+	go func() {
+		warmCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), anomalyWarmTimeout)
+		defer cancel()
+		read, err := policy.Warm(warmCtx, reader)
+		if err != nil {
+			slog.Warn("failed to warm max_tokens defaults from usage store", "records", read, "error", err)
+			return
+		}
+		slog.Info("max_tokens defaults warmed from usage store", "records", read)
+	}()
+}
+
 // initAdmin creates the admin API handler and optionally the dashboard handler.
 // Returns nil dashboard handler if uiEnabled is false.
 func initAdmin(
 	auditStorage, usageStorage storage.Storage,
+	streamSamples *auditlog.StreamSampler,
+	auditKeyring *auditlog.Keyring,
+	logCfg config.LogConfig,
 	registry *providers.ModelRegistry,
 	configuredProviders []providers.SanitizedProviderConfig,
 	authKeyService *authkeys.Service,
 	aliasService *aliases.Service,
+	templateService *prompttemplates.Service,
 	modelOverrideService *modeloverrides.Service,
 	workflowService *workflows.Service,
 	guardrailService *guardrails.Service,
+	experimentService *experiments.Service,
+	modelGroupResolver *modelgroups.Resolver,
+	deferredService *deferred.Service,
+	usageJobService *usagejobs.Service,
+	chaosInjector *chaos.Injector,
+	provenanceSigner *provenance.Signer,
+	sanitizer *sanitize.Sanitizer,
+	maintenanceMode *maintenance.Mode,
+	inspector *inspect.Inspector,
+	deprecationRegistry *deprecations.Registry,
+	prober *probe.Prober,
 	runtimeRefresher admin.RuntimeRefresher,
 	runtimeConfig admin.DashboardConfigResponse,
+	effectiveConfig admin.EffectiveConfigResponse,
+	board *scoreboard.Scoreboard,
+	promptCache *promptcache.Tracker,
+	anomalies *anomaly.Detector,
+	modelCache *modelcache.LocalCache,
+	auditPipeline, usagePipeline admin.PipelineStatsSource,
+	memory *memgovernor.Governor,
+	maxQueryDays int,
 	uiEnabled bool,
 ) (*admin.Handler, *dashboard.Handler, error) {
-	// Find a storage connection for reading usage data
-	var store storage.Storage
-	if auditStorage != nil {
-		store = auditStorage
-	} else if usageStorage != nil {
-		store = usageStorage
-	}
-
-	// Create usage reader (may be nil if no storage)
-	var reader usage.UsageReader
-	if store != nil {
-		var err error
-		reader, err = usage.NewReader(store)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create usage reader: %w", err)
-		}
+	reader, err := newUsageReader(auditStorage, usageStorage)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create audit reader (only from audit storage, because the usage-only storage
 	// schema may not include the audit_logs table/collection).
 	var auditReader auditlog.Reader
 	if auditStorage != nil {
-		var err error
-		auditReader, err = auditlog.NewReader(auditStorage)
+		storeReader, err := auditlog.NewReader(auditStorage)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create audit reader: %w", err)
 		}
+		// Sealed entries are decrypted here, so the stores never see keys.
+		if storeReader != nil {
+			auditReader = auditlog.NewDecryptingReader(storeReader, auditKeyring)
+			// Cached entries are kept decrypted, so repeated views skip both
+			// the store and decryption.
+			auditReader = auditlog.NewCachingReader(auditReader, logCfg.LookupCacheEntries, time.Duration(logCfg.LookupCacheTTL)*time.Second)
+		}
 	}
 
 	adminHandler := admin.NewHandler(
@@ -787,18 +1385,38 @@ func initAdmin(
 		registry,
 		admin.WithConfiguredProviders(configuredProviders),
 		admin.WithAuditReader(auditReader),
+		admin.WithStreamSamples(streamSamples),
 		admin.WithAuthKeys(authKeyService),
 		admin.WithAliases(aliasService),
+		admin.WithPromptTemplates(templateService),
 		admin.WithModelOverrides(modelOverrideService),
 		admin.WithWorkflows(workflowService),
 		admin.WithGuardrailService(guardrailService),
+		admin.WithExperiments(experimentService),
+		admin.WithModelGroups(modelGroupResolver),
+		admin.WithDeferred(deferredService),
+		admin.WithUsageJobs(usageJobService),
+		admin.WithChaos(chaosInjector),
+		admin.WithProvenance(provenanceSigner),
+		admin.WithResponseSanitization(sanitizer),
+		admin.WithMaintenance(maintenanceMode),
+		admin.WithInspector(inspector),
+		admin.WithDeprecations(deprecationRegistry),
+		admin.WithCapabilityProbe(prober),
 		admin.WithRuntimeRefresher(runtimeRefresher),
 		admin.WithDashboardRuntimeConfig(runtimeConfig),
+		admin.WithEffectiveConfig(effectiveConfig),
+		admin.WithScoreboard(board),
+		admin.WithPromptCache(promptCache),
+		admin.WithAnomalies(anomalies),
+		admin.WithLocalModelCache(modelCache),
+		admin.WithWritePipelines(auditPipeline, usagePipeline),
+		admin.WithMemoryGovernor(memory),
+		admin.WithMaxQueryDays(maxQueryDays),
 	)
 
 	var dashHandler *dashboard.Handler
 	if uiEnabled {
-		var err error
 		dashHandler, err = dashboard.New()
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to initialize dashboard: %w", err)
@@ -828,6 +1446,15 @@ func configGuardrailDefinitions(cfg config.GuardrailsConfig) ([]guardrails.Defin
 			return nil, fmt.Errorf("guardrail rule #%d (%q): type is required", i, name)
 		}
 
+		var canary *guardrails.Canary
+		if rule.Canary != nil {
+			canary = &guardrails.Canary{
+				Percent: rule.Canary.Percent,
+				Salt:    rule.Canary.Salt,
+				Unit:    rule.Canary.Unit,
+			}
+		}
+
 		var rawConfig []byte
 		var err error
 		switch ruleType {
@@ -835,6 +1462,7 @@ func configGuardrailDefinitions(cfg config.GuardrailsConfig) ([]guardrails.Defin
 			rawConfig, err = json.Marshal(map[string]any{
 				"mode":    rule.SystemPrompt.Mode,
 				"content": rule.SystemPrompt.Content,
+				"canary":  canary,
 			})
 		case "llm_based_altering":
 			rawConfig, err = json.Marshal(map[string]any{
@@ -844,6 +1472,7 @@ func configGuardrailDefinitions(cfg config.GuardrailsConfig) ([]guardrails.Defin
 				"roles":               rule.LLMBasedAltering.Roles,
 				"skip_content_prefix": rule.LLMBasedAltering.SkipContentPrefix,
 				"max_tokens":          rule.LLMBasedAltering.MaxTokens,
+				"canary":              canary,
 			})
 		default:
 			return nil, fmt.Errorf("guardrail rule #%d (%q): unsupported type %q", i, name, ruleType)
@@ -912,8 +1541,19 @@ func defaultWorkflowInput(cfg *config.Config, availableGuardrails []string, conf
 	}
 }
 
+// effectiveConfig renders the masked effective config for the admin API. The
+// endpoint stays unavailable when it cannot be rendered.
+func effectiveConfig(result *config.LoadResult) admin.EffectiveConfigResponse {
+	effective, err := result.Effective()
+	if err != nil {
+		slog.Warn("effective config endpoint disabled", "error", err)
+		return admin.EffectiveConfigResponse{}
+	}
+	return admin.EffectiveConfigResponse{Profile: result.Profile, Config: effective}
+}
+
 func dashboardRuntimeConfig(cfg *config.Config, usageEnabled bool) admin.DashboardConfigResponse {
-	return admin.DashboardConfigResponse{
+	values := admin.DashboardConfigResponse{
 		FeatureFallbackMode:  dashboardFallbackModeValue(cfg),
 		LoggingEnabled:       dashboardEnabledValue(cfg != nil && cfg.Logging.Enabled),
 		UsageEnabled:         dashboardEnabledValue(cfg != nil && cfg.Usage.Enabled),
@@ -922,6 +1562,19 @@ func dashboardRuntimeConfig(cfg *config.Config, usageEnabled bool) admin.Dashboa
 		RedisURL:             dashboardEnabledValue(simpleResponseCacheConfigured(cfg)),
 		SemanticCacheEnabled: dashboardEnabledValue(semanticResponseCacheConfigured(cfg)),
 	}
+	if cfg != nil && cfg.Logging.Enabled {
+		bodyCapture := auditlog.BodyCaptureFromConfig(cfg.Logging)
+		limits := bodyCapture.LimitsFor("")
+		values.LoggingMaxRequestBodyBytes = strconv.FormatInt(limits.Request, 10)
+		values.LoggingMaxResponseBodyBytes = strconv.FormatInt(limits.Response, 10)
+		if len(bodyCapture.Paths) > 0 {
+			values.LoggingBodyCapturePaths = make(map[string]auditlog.BodyCaptureLimits, len(bodyCapture.Paths))
+			for prefix := range bodyCapture.Paths {
+				values.LoggingBodyCapturePaths[prefix] = bodyCapture.LimitsFor(prefix)
+			}
+		}
+	}
+	return values
 }
 
 func cacheAnalyticsConfigured(cfg *config.Config, usageEnabled bool) bool {
@@ -981,6 +1634,135 @@ func workflowRefreshInterval(cfg *config.Config) time.Duration {
 	return cfg.Workflows.RefreshInterval
 }
 
+// inlineImageLimits converts the server image limits. MaxImageSize was
+// validated when the config was loaded.
+func inlineImageLimits(cfg config.ServerConfig) core.InlineImageLimits {
+	maxImageBytes, _ := config.ParseBodySizeLimitBytes(cfg.MaxImageSize) //nolint:errcheck
+	return core.InlineImageLimits{
+		MaxImages:     cfg.MaxRequestImages,
+		MaxImageBytes: maxImageBytes,
+	}
+}
+
+// streamBackpressure converts the server stream buffer settings and the
+// stream limits. StreamBufferSize was validated when the config was loaded.
+func streamBackpressure(cfg config.ServerConfig, limits config.StreamLimitsConfig) server.StreamBackpressure {
+	bufferSize, _ := config.ParseBodySizeLimitBytes(cfg.StreamBufferSize) //nolint:errcheck
+	models := make(map[string]server.StreamModelLimits, len(limits.Models))
+	for _, model := range limits.Models {
+		models[strings.TrimSpace(model.Model)] = server.StreamModelLimits{MaxBytes: model.MaxBytes, MaxDuration: model.MaxDuration}
+	}
+	return server.StreamBackpressure{
+		BufferSize:          int(bufferSize),
+		StallTimeout:        cfg.StreamStallTimeout,
+		Policy:              server.StreamStallPolicy(cfg.StreamStallPolicy),
+		MaxPause:            cfg.StreamStallMaxPause,
+		ChunkStallThreshold: cfg.StreamChunkStallThreshold,
+		Limits: server.StreamLimits{
+			MaxBytes:    limits.MaxBytes,
+			MaxDuration: limits.MaxDuration,
+			Grace:       limits.Grace,
+			Models:      models,
+		},
+	}
+}
+
+// requestBodyStreaming converts the large request body settings. Both sizes
+// were validated when the config was loaded.
+func requestBodyStreaming(cfg config.ServerConfig) server.RequestBodyStreaming {
+	threshold, _ := config.ParseBodySizeLimitBytes(cfg.StreamingBodyThreshold) //nolint:errcheck
+	bufferedLimit, _ := config.ParseBodySizeLimitBytes(cfg.BufferedBodyLimit)  //nolint:errcheck
+	return server.RequestBodyStreaming{
+		Threshold:     threshold,
+		BufferedLimit: bufferedLimit,
+	}
+}
+
+// upstreamHeaderPolicy returns the upstream response header passthrough
+// policy, or nil when neither the global config nor any provider allows a
+// header.
+func upstreamHeaderPolicy(cfg config.UpstreamHeadersConfig, rawProviders map[string]config.RawProviderConfig) *core.UpstreamHeaderPolicy {
+	enabled := len(cfg.Allow) > 0
+	for _, provider := range rawProviders {
+		enabled = enabled || len(provider.UpstreamResponseHeaders) > 0
+	}
+	if !enabled {
+		return nil
+	}
+	return &core.UpstreamHeaderPolicy{Allow: cfg.Allow, Prefix: cfg.Prefix}
+}
+
+func contextOverflowConfig(cfg config.ContextOverflowConfig, resolver gateway.ContextWindowResolver) gateway.ContextOverflowConfig {
+	models := make(map[string]core.ContextOverflowStrategy, len(cfg.Models))
+	for model, strategy := range cfg.Models {
+		models[model] = core.ContextOverflowStrategy(strategy)
+	}
+	return gateway.ContextOverflowConfig{
+		Strategy:        core.ContextOverflowStrategy(cfg.Strategy),
+		ModelStrategies: models,
+		Resolver:        resolver,
+	}
+}
+
+func promptCompressionConfig(cfg config.PromptCompressionConfig) gateway.PromptCompressionConfig {
+	models := make(map[string]core.PromptCompressionPasses, len(cfg.Models))
+	for model, passes := range cfg.Models {
+		models[model] = promptCompressionPassSet(passes)
+	}
+	return gateway.PromptCompressionConfig{
+		Passes:          promptCompressionPassSet(cfg.Passes),
+		ModelPasses:     models,
+		DataURIMinBytes: cfg.DataURIMinBytes,
+	}
+}
+
+func moderationConfig(cfg config.ModerationConfig, moderator gateway.Moderator) gateway.ModerationConfig {
+	actions := make(map[string]core.ModerationAction, len(cfg.Categories))
+	for category, action := range cfg.Categories {
+		actions[category] = core.ModerationAction(action)
+	}
+	return gateway.ModerationConfig{
+		Moderator:     moderator,
+		Threshold:     cfg.Threshold,
+		DefaultAction: core.ModerationAction(cfg.Action),
+		Actions:       actions,
+		Models:        cfg.Models,
+		Paths:         cfg.Paths,
+		SkipKeys:      cfg.SkipKeys,
+		FailOpen:      cfg.FailOpen,
+		ExposeScores:  cfg.ExposeScores,
+		Timeout:       cfg.Timeout,
+	}
+}
+
+// serverToolsConfig converts the server_tools config, rejecting grants of
+// tools that are not registered in this build.
+func serverToolsConfig(cfg config.ServerToolsConfig) (gateway.ServerToolsConfig, error) {
+	grants := make([]gateway.ServerToolGrant, len(cfg.Grants))
+	for i, grant := range cfg.Grants {
+		for _, name := range grant.Tools {
+			if _, ok := tools.Lookup(name); !ok {
+				return gateway.ServerToolsConfig{}, fmt.Errorf("server_tools.grants[%d]: unknown tool %q (registered: %s)", i, name, strings.Join(tools.Names(), ", "))
+			}
+		}
+		grants[i] = gateway.ServerToolGrant{Tools: grant.Tools, Models: grant.Models, Keys: grant.Keys}
+	}
+	return gateway.ServerToolsConfig{
+		Grants:         grants,
+		MaxIterations:  cfg.MaxIterations,
+		Timeout:        cfg.Timeout,
+		MaxOutputBytes: cfg.MaxOutputBytes,
+	}, nil
+}
+
+func promptCompressionPassSet(passes []string) core.PromptCompressionPasses {
+	set := make(core.PromptCompressionPasses, len(passes))
+	for _, pass := range passes {
+		set[core.PromptCompressionPass(pass)] = true
+	}
+	return set
+}
+
 func responseCacheConfigured(cfg config.ResponseCacheConfig) bool {
 	return simpleResponseCacheConfiguredFromResponse(cfg) || semanticResponseCacheConfiguredFromResponse(cfg)
 }
@@ -1032,6 +1814,34 @@ func fallbackModeEnabled(mode config.FallbackMode) bool {
 	}
 }
 
+// pipelineStatsSource returns logger as a write pipeline stats source, or nil
+// when the logger is disabled and keeps no pipeline.
+func pipelineStatsSource(logger any) admin.PipelineStatsSource {
+	if source, ok := logger.(admin.PipelineStatsSource); ok {
+		return source
+	}
+	return nil
+}
+
+// prepareStorageSchema applies or checks schema migrations before any store
+// is opened, on a short-lived connection of its own.
+func prepareStorageSchema(ctx context.Context, cfg config.StorageConfig) error {
+	conn, err := storage.New(ctx, cfg.BackendConfig())
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := migrations.Prepare(ctx, conn, cfg.AutoMigrate)
+	if err != nil {
+		return err
+	}
+	for _, migration := range applied {
+		slog.Info("applied schema migration", "version", migration.Version, "name", migration.Name)
+	}
+	return nil
+}
+
 func firstSharedStorage(candidates ...storage.Storage) storage.Storage {
 	for _, candidate := range candidates {
 		if candidate != nil {
@@ -10,6 +10,7 @@ import (
 
 	"gomodel/config"
 	"gomodel/internal/admin"
+	"gomodel/internal/auditlog"
 	"gomodel/internal/core"
 	"gomodel/internal/guardrails"
 	"gomodel/internal/modeloverrides"
@@ -476,6 +477,35 @@ func TestDashboardRuntimeConfig_ExposesFeatureAvailabilityFlags(t *testing.T) {
 	}
 }
 
+func TestDashboardRuntimeConfig_ExposesEffectiveBodyCaptureLimits(t *testing.T) {
+	cfg := &config.Config{
+		Logging: config.LogConfig{
+			Enabled:              true,
+			MaxResponseBodyBytes: 4096,
+			BodyCapturePaths: map[string]config.LogBodyCaptureLimits{
+				"/v1/responses": {Request: 2 * 1024 * 1024},
+			},
+		},
+	}
+
+	values := dashboardRuntimeConfig(cfg, false)
+	if got := values.LoggingMaxRequestBodyBytes; got != "1048576" {
+		t.Fatalf("dashboardRuntimeConfig()[%q] = %q, want 1048576", admin.DashboardConfigLoggingMaxRequest, got)
+	}
+	if got := values.LoggingMaxResponseBodyBytes; got != "4096" {
+		t.Fatalf("dashboardRuntimeConfig()[%q] = %q, want 4096", admin.DashboardConfigLoggingMaxResponse, got)
+	}
+	want := auditlog.BodyCaptureLimits{Request: 2 * 1024 * 1024, Response: 4096}
+	if got := values.LoggingBodyCapturePaths["/v1/responses"]; got != want {
+		t.Fatalf("dashboardRuntimeConfig()[%q] = %+v, want %+v", admin.DashboardConfigLoggingBodyPaths, got, want)
+	}
+
+	cfg.Logging.Enabled = false
+	if values := dashboardRuntimeConfig(cfg, false); values.LoggingMaxRequestBodyBytes != "" || values.LoggingBodyCapturePaths != nil {
+		t.Fatalf("dashboardRuntimeConfig() = %+v, want no body capture limits with logging disabled", values)
+	}
+}
+
 func TestDashboardRuntimeConfig_HidesCacheAnalyticsWhenUsageDisabled(t *testing.T) {
 	cfg := &config.Config{
 		Usage: config.UsageConfig{
@@ -76,6 +76,7 @@ func (a *App) RefreshRuntime(ctx context.Context) (admin.RuntimeRefreshReport, e
 		if registry == nil {
 			return runtimeRefreshStepResult{err: fmt.Errorf("model registry is unavailable")}
 		}
+		registry.InitializeAllLazyProviders(ctx)
 		err := registry.Refresh(ctx)
 		issueCount := providerRefreshIssueCount(registry.ProviderRuntimeSnapshots())
 		switch {
@@ -236,7 +237,8 @@ func runtimeRefreshStepStatus(steps []admin.RuntimeRefreshStep, name string) str
 func providerRefreshIssueCount(snapshots []providers.ProviderRuntimeSnapshot) int {
 	var count int
 	for _, snapshot := range snapshots {
-		if strings.TrimSpace(snapshot.LastModelFetchError) != "" || strings.TrimSpace(snapshot.LastAvailabilityError) != "" {
+		if strings.TrimSpace(snapshot.LastModelFetchError) != "" || strings.TrimSpace(snapshot.LastAvailabilityError) != "" ||
+			snapshot.Initialization == providers.ProviderInitFailed {
 			count++
 		}
 	}
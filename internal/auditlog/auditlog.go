@@ -5,9 +5,10 @@ package auditlog
 import (
 	"context"
 	"encoding/json"
-	"log/slog"
 	"strings"
 	"time"
+
+	"gomodel/internal/core"
 )
 
 // LogStore defines the interface for audit log storage backends.
@@ -49,13 +50,21 @@ type LogEntry struct {
 	// Core fields (indexed for queries)
 	RequestedModel    string `json:"requested_model" bson:"requested_model,omitempty"`
 	ResolvedModel     string `json:"resolved_model,omitempty" bson:"resolved_model,omitempty"`
-	Provider          string `json:"provider" bson:"provider"` // canonical provider type used for routing and filters
+	ServedModel       string `json:"served_model,omitempty" bson:"served_model,omitempty"` // model reported by the provider response
+	Provider          string `json:"provider" bson:"provider"`                             // canonical provider type used for routing and filters
 	ProviderName      string `json:"provider_name,omitempty" bson:"provider_name,omitempty"`
 	AliasUsed         bool   `json:"alias_used,omitempty" bson:"alias_used,omitempty"`
 	WorkflowVersionID string `json:"workflow_version_id,omitempty" bson:"workflow_version_id,omitempty"`
 	CacheType         string `json:"cache_type,omitempty" bson:"cache_type,omitempty"`
 	StatusCode        int    `json:"status_code" bson:"status_code"`
 
+	// UpstreamStatusCode is the HTTP status the provider returned, before the
+	// gateway mapped it to StatusCode. Zero when no provider call was made.
+	UpstreamStatusCode int `json:"upstream_status_code,omitempty" bson:"upstream_status_code,omitempty"`
+	// UpstreamDurationNs is the provider call duration in nanoseconds. For
+	// streaming requests it measures the time until response headers arrived.
+	UpstreamDurationNs int64 `json:"upstream_duration_ns,omitempty" bson:"upstream_duration_ns,omitempty"`
+
 	// Extracted fields for efficient filtering (indexed in relational DBs)
 	RequestID  string `json:"request_id,omitempty" bson:"request_id,omitempty"`
 	AuthKeyID  string `json:"auth_key_id,omitempty" bson:"auth_key_id,omitempty"`
@@ -67,8 +76,23 @@ type LogEntry struct {
 	Stream     bool   `json:"stream,omitempty" bson:"stream,omitempty"`
 	ErrorType  string `json:"error_type,omitempty" bson:"error_type,omitempty"`
 
+	// ResponseID is the response ID returned to the client (chat "id" or
+	// Responses "id"). ProviderResponseID is the ID the provider issued when
+	// it differs, e.g. the Anthropic message ID behind a Responses stream
+	// whose ID the gateway synthesized. Both are indexed for support lookups.
+	ResponseID         string `json:"response_id,omitempty" bson:"response_id,omitempty"`
+	ProviderResponseID string `json:"provider_response_id,omitempty" bson:"provider_response_id,omitempty"`
+
+	// Redacted reports whether an administrator removed the bodies and headers
+	// of this entry. Readers derive it from Data.Redaction.
+	Redacted bool `json:"redacted,omitempty" bson:"-"`
+
 	// Data contains flexible request/response information as JSON
 	Data *LogData `json:"data,omitempty" bson:"data,omitempty"`
+
+	// sampleRequest holds what a stream sample needs from the request while
+	// stream sampling is enabled. It is never stored.
+	sampleRequest *streamSampleRequest
 }
 
 // LogData contains flexible request/response information.
@@ -88,6 +112,134 @@ type LogData struct {
 	// moved from the primary selector to a configured failover target.
 	Failover *FailoverSnapshot `json:"failover,omitempty" bson:"failover,omitempty"`
 
+	// Comparison links a sub-request to the multi-model chat comparison that
+	// fanned it out. The comparison ID is also used as the request ID.
+	Comparison *ComparisonSnapshot `json:"comparison,omitempty" bson:"comparison,omitempty"`
+
+	// ContextOverflow records how the gateway shortened or rejected a prompt
+	// that did not fit the model's context window.
+	ContextOverflow *ContextOverflowSnapshot `json:"context_overflow,omitempty" bson:"context_overflow,omitempty"`
+
+	// PromptCompression records which prompt compression passes changed the
+	// request and the estimated prompt tokens they saved.
+	PromptCompression *PromptCompressionSnapshot `json:"prompt_compression,omitempty" bson:"prompt_compression,omitempty"`
+
+	// Moderation records the moderation pre-check of a request that was let
+	// through with flagged categories or because the check failed open.
+	Moderation *ModerationSnapshot `json:"moderation,omitempty" bson:"moderation,omitempty"`
+
+	// ToolLoop records the model calls and server-side tool runs of a request
+	// answered through the server-side tool loop.
+	ToolLoop *ToolLoopSnapshot `json:"tool_loop,omitempty" bson:"tool_loop,omitempty"`
+
+	// EmbeddingCache records how many embeddings inputs were served from the
+	// embeddings cache and how many were sent upstream.
+	EmbeddingCache *EmbeddingCacheSnapshot `json:"embedding_cache,omitempty" bson:"embedding_cache,omitempty"`
+
+	// Experiment records the A/B experiment variant that selected the model.
+	Experiment *ExperimentSnapshot `json:"experiment,omitempty" bson:"experiment,omitempty"`
+
+	// ModelGroup records the model group the request targeted and the member
+	// that served it.
+	ModelGroup *ModelGroupSnapshot `json:"model_group,omitempty" bson:"model_group,omitempty"`
+
+	// DataResidency records the data residency requirement of the request and
+	// the provider instance that satisfied it.
+	DataResidency *DataResidencySnapshot `json:"data_residency,omitempty" bson:"data_residency,omitempty"`
+
+	// UnlistedModel is set when the model was missing from the model registry
+	// and the request reached a provider that allows unlisted models.
+	UnlistedModel bool `json:"unlisted_model,omitempty" bson:"unlisted_model,omitempty"`
+
+	// Labels holds the cost allocation labels of the request, taken from
+	// X-GoModel-Label-* headers and the request metadata object.
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+
+	// Options holds the effective value of every request option when the
+	// request set any through X-GoModel-Options or a standalone option
+	// header; unset options show their defaults.
+	Options map[string]string `json:"options,omitempty" bson:"options,omitempty"`
+
+	// PromptTemplate records the stored prompt template rendered into the
+	// request messages.
+	PromptTemplate *PromptTemplateSnapshot `json:"prompt_template,omitempty" bson:"prompt_template,omitempty"`
+
+	// UserHash is the hash of the user request field. User holds the raw
+	// value and is only recorded when the gateway is configured to keep it.
+	UserHash string `json:"user_hash,omitempty" bson:"user_hash,omitempty"`
+	User     string `json:"user,omitempty" bson:"user,omitempty"`
+
+	// Chaos marks a request whose response was faulted on purpose by a fault
+	// injection rule.
+	Chaos *ChaosSnapshot `json:"chaos,omitempty" bson:"chaos,omitempty"`
+
+	// SelfProtection marks a request whose content referenced the gateway's
+	// admin API or an internal host.
+	SelfProtection *SelfProtectionSnapshot `json:"self_protection,omitempty" bson:"self_protection,omitempty"`
+
+	// SecretRedaction records the secrets found in the request content by
+	// detector. RequestBody holds the request with the masked ones replaced.
+	SecretRedaction *SecretRedactionSnapshot `json:"secret_redaction,omitempty" bson:"secret_redaction,omitempty"`
+
+	// ResponseTransforms records the response transform pipeline that
+	// rewrote the response sent to the client. ResponseBody keeps the
+	// response as the provider returned it.
+	ResponseTransforms *ResponseTransformSnapshot `json:"response_transforms,omitempty" bson:"response_transforms,omitempty"`
+
+	// Maintenance records the maintenance mode state an admin request set.
+	Maintenance *MaintenanceSnapshot `json:"maintenance,omitempty" bson:"maintenance,omitempty"`
+
+	// Inspection records the live request inspection an admin request
+	// started or stopped. The entry's auth key and timestamp say who and
+	// when.
+	Inspection *InspectionSnapshot `json:"inspection,omitempty" bson:"inspection,omitempty"`
+
+	// CapabilityProbe marks an admin request that ran a capability probe,
+	// whose provider requests were internal traffic.
+	CapabilityProbe *CapabilityProbeSnapshot `json:"capability_probe,omitempty" bson:"capability_probe,omitempty"`
+
+	// PacingDelayNs is the time the request spent queued by provider request
+	// pacing before reaching upstream, summed over retries and failovers.
+	// It is part of UpstreamDurationNs for the call that was paced.
+	PacingDelayNs int64 `json:"pacing_delay_ns,omitempty" bson:"pacing_delay_ns,omitempty"`
+
+	// GuardrailCanaries records, for each guardrail in canary rollout that
+	// the request reached, whether the guardrail was applied.
+	GuardrailCanaries []GuardrailCanarySnapshot `json:"guardrail_canaries,omitempty" bson:"guardrail_canaries,omitempty"`
+
+	// Redaction records who removed the bodies and headers of this entry and
+	// when. It is nil for entries that were never redacted.
+	Redaction *RedactionSnapshot `json:"redaction,omitempty" bson:"redaction,omitempty"`
+
+	// BodyLogOptOut marks a request whose client opted out of body capture
+	// with core.NoBodyLogHeader; its RequestBody and ResponseBody are empty.
+	BodyLogOptOut bool `json:"body_log_opt_out,omitempty" bson:"body_log_opt_out,omitempty"`
+
+	// IdempotentReplayOf is the request id of the original request when this
+	// request was answered from it because it repeated its Idempotency-Key.
+	IdempotentReplayOf string `json:"idempotent_replay_of,omitempty" bson:"idempotent_replay_of,omitempty"`
+
+	// StreamSampleID links a streamed response to the stream sample holding
+	// its complete text.
+	StreamSampleID string `json:"stream_sample_id,omitempty" bson:"stream_sample_id,omitempty"`
+
+	// StreamBackpressure records how far a streamed response filled its
+	// per-connection send buffer and whether a slow client stalled it.
+	StreamBackpressure *StreamBackpressureSnapshot `json:"stream_backpressure,omitempty" bson:"stream_backpressure,omitempty"`
+
+	// StreamTiming records how regularly the provider delivered the chunks of
+	// a streamed response.
+	StreamTiming *StreamTimingSnapshot `json:"stream_timing,omitempty" bson:"stream_timing,omitempty"`
+
+	// StreamLimit records a streamed response ended by the stream size or
+	// duration limit.
+	StreamLimit *StreamLimitSnapshot `json:"stream_limit,omitempty" bson:"stream_limit,omitempty"`
+
+	// StreamFormat is the format a streamed response was re-encoded to for
+	// the client, such as "ndjson". It is empty for SSE; captured stream
+	// bodies are always the SSE events.
+	StreamFormat string `json:"stream_format,omitempty" bson:"stream_format,omitempty"`
+
 	// Request parameters
 	Temperature *float64 `json:"temperature,omitempty" bson:"temperature,omitempty"`
 	MaxTokens   *int     `json:"max_tokens,omitempty" bson:"max_tokens,omitempty"`
@@ -106,9 +258,28 @@ type LogData struct {
 	RequestBody  any `json:"request_body,omitempty" bson:"request_body,omitempty"`
 	ResponseBody any `json:"response_body,omitempty" bson:"response_body,omitempty"`
 
+	// Sealed holds the bodies, and optionally the headers, encrypted at rest
+	// when audit log encryption is enabled. Readers decrypt it back into the
+	// plain fields.
+	Sealed *SealedFields `json:"sealed,omitempty" bson:"sealed,omitempty"`
+
+	// DecryptionError is set by readers when Sealed could not be decrypted,
+	// for example because its key is no longer configured. It is never stored.
+	DecryptionError string `json:"decryption_error,omitempty" bson:"-"`
+
+	// RequestImages summarizes the inline base64 images of the request. Their
+	// payloads are replaced by placeholders in RequestBody.
+	RequestImages []core.InlineImage `json:"request_images,omitempty" bson:"request_images,omitempty"`
+
 	// Body capture status flags (set when body exceeds 1MB limit)
 	RequestBodyTooBigToHandle  bool `json:"request_body_too_big_to_handle,omitempty" bson:"request_body_too_big_to_handle,omitempty"`
 	ResponseBodyTooBigToHandle bool `json:"response_body_too_big_to_handle,omitempty" bson:"response_body_too_big_to_handle,omitempty"`
+
+	// RequestBodyBytes and RequestBodySHA256 describe a request body that was
+	// piped to the provider without being buffered. The hash is only set when
+	// the provider read the whole body.
+	RequestBodyBytes  int64  `json:"request_body_bytes,omitempty" bson:"request_body_bytes,omitempty"`
+	RequestBodySHA256 string `json:"request_body_sha256,omitempty" bson:"request_body_sha256,omitempty"`
 }
 
 // WorkflowFeaturesSnapshot stores the effective workflow feature state that
@@ -129,6 +300,202 @@ type FailoverSnapshot struct {
 	TargetModel string `json:"target_model,omitempty" bson:"target_model,omitempty"`
 }
 
+// ComparisonSnapshot identifies one model's sub-request within a chat
+// comparison. Index is the model's position in the requested models list.
+type ComparisonSnapshot struct {
+	ID    string `json:"id" bson:"id"`
+	Index int    `json:"index" bson:"index"`
+}
+
+// ContextOverflowSnapshot stores the context overflow strategy applied to one
+// request. EstimatedTokens is the prompt estimate before any truncation.
+type ContextOverflowSnapshot struct {
+	Strategy        string `json:"strategy" bson:"strategy"`
+	EstimatedTokens int    `json:"estimated_tokens" bson:"estimated_tokens"`
+	Limit           int    `json:"limit" bson:"limit"`
+	DroppedMessages int    `json:"dropped_messages" bson:"dropped_messages"`
+}
+
+// ExperimentSnapshot stores the A/B experiment assignment of one request.
+type ExperimentSnapshot struct {
+	Name    string `json:"name" bson:"name"`
+	Variant string `json:"variant" bson:"variant"`
+}
+
+// ModelGroupSnapshot stores the model group member chosen for one request.
+type ModelGroupSnapshot struct {
+	Name     string `json:"name" bson:"name"`
+	Member   string `json:"member" bson:"member"`
+	Fallback bool   `json:"fallback,omitempty" bson:"fallback,omitempty"`
+}
+
+// PromptTemplateSnapshot stores the prompt template version rendered into one
+// request.
+type PromptTemplateSnapshot struct {
+	Name    string `json:"name" bson:"name"`
+	Version int    `json:"version" bson:"version"`
+}
+
+// StreamBackpressureSnapshot stores the send buffer state of one streamed
+// response. Stalls counts the times the buffer stayed full past the stall
+// timeout; Terminated is set when the gateway ended the stream because of it.
+type StreamBackpressureSnapshot struct {
+	BufferHighWaterBytes int  `json:"buffer_high_water_bytes" bson:"buffer_high_water_bytes"`
+	Stalls               int  `json:"stalls,omitempty" bson:"stalls,omitempty"`
+	Terminated           bool `json:"terminated,omitempty" bson:"terminated,omitempty"`
+}
+
+// StreamLimitSnapshot stores where a stream limit cut a streamed response:
+// the limit that fired ("bytes" or "duration") and the bytes relayed and time
+// elapsed at the cutoff.
+type StreamLimitSnapshot struct {
+	Limit      string `json:"limit" bson:"limit"`
+	Bytes      int64  `json:"bytes" bson:"bytes"`
+	DurationMs int64  `json:"duration_ms" bson:"duration_ms"`
+}
+
+// StreamTimingSnapshot stores the upstream chunk timing of one streamed
+// response. Gaps leave out the wait for the first chunk and time paused for a
+// slow client; Stalls counts gaps of at least the chunk stall threshold.
+type StreamTimingSnapshot struct {
+	Chunks   int   `json:"chunks" bson:"chunks"`
+	MaxGapMs int64 `json:"max_gap_ms" bson:"max_gap_ms"`
+	P95GapMs int64 `json:"p95_gap_ms" bson:"p95_gap_ms"`
+	Stalls   int   `json:"stalls,omitempty" bson:"stalls,omitempty"`
+}
+
+// DataResidencySnapshot stores the data residency requirement of one request.
+// Provider is empty when no provider satisfied the requirement.
+type DataResidencySnapshot struct {
+	Requirement string `json:"requirement" bson:"requirement"`
+	Provider    string `json:"provider,omitempty" bson:"provider,omitempty"`
+}
+
+// ChaosSnapshot stores the fault injection rule applied to one request.
+type ChaosSnapshot struct {
+	Rule   string `json:"rule" bson:"rule"`
+	Effect string `json:"effect" bson:"effect"`
+}
+
+// SelfProtectionSnapshot stores the self-protection match of one request:
+// the action taken, the pattern and the JSON path of the matching value.
+type SelfProtectionSnapshot struct {
+	Action   string `json:"action" bson:"action"`
+	Pattern  string `json:"pattern" bson:"pattern"`
+	Location string `json:"location" bson:"location"`
+}
+
+// SecretRedactionSnapshot stores how many secrets each detector found in one
+// request and whether a blocking detector rejected it. It never holds the
+// secrets.
+type SecretRedactionSnapshot struct {
+	Detections []core.SecretDetection `json:"detections" bson:"detections"`
+	Blocked    bool                   `json:"blocked,omitempty" bson:"blocked,omitempty"`
+}
+
+// ResponseTransformSnapshot stores the response transform pipeline applied to
+// one response and how many replacements each of its transformers made.
+type ResponseTransformSnapshot struct {
+	Pipeline     string                        `json:"pipeline" bson:"pipeline"`
+	Transformers []ResponseTransformerSnapshot `json:"transformers" bson:"transformers"`
+}
+
+// ResponseTransformerSnapshot stores the replacement count of one response
+// transformer.
+type ResponseTransformerSnapshot struct {
+	Name         string `json:"name" bson:"name"`
+	Replacements int    `json:"replacements" bson:"replacements"`
+}
+
+// MaintenanceSnapshot stores the maintenance mode state set by one admin
+// request. Until is set when maintenance ends by itself.
+type MaintenanceSnapshot struct {
+	Active  bool       `json:"active" bson:"active"`
+	Message string     `json:"message,omitempty" bson:"message,omitempty"`
+	Until   *time.Time `json:"until,omitempty" bson:"until,omitempty"`
+	Drain   bool       `json:"drain,omitempty" bson:"drain,omitempty"`
+}
+
+// InspectionSnapshot stores the live request inspection started or stopped
+// by one admin request. The previews it captures are never audited.
+type InspectionSnapshot struct {
+	Action       string     `json:"action" bson:"action"`
+	Model        string     `json:"model,omitempty" bson:"model,omitempty"`
+	Provider     string     `json:"provider,omitempty" bson:"provider,omitempty"`
+	Path         string     `json:"path,omitempty" bson:"path,omitempty"`
+	MaxSamples   int        `json:"max_samples,omitempty" bson:"max_samples,omitempty"`
+	PreviewChars int        `json:"preview_chars,omitempty" bson:"preview_chars,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// CapabilityProbeSnapshot summarizes one capability probe run: the checks
+// the provider passed and failed, and the tokens the probe spent.
+type CapabilityProbeSnapshot struct {
+	Provider    string   `json:"provider" bson:"provider"`
+	Model       string   `json:"model" bson:"model"`
+	Supported   []string `json:"supported,omitempty" bson:"supported,omitempty"`
+	Unsupported []string `json:"unsupported,omitempty" bson:"unsupported,omitempty"`
+	TokensUsed  int      `json:"tokens_used" bson:"tokens_used"`
+	Applied     bool     `json:"applied,omitempty" bson:"applied,omitempty"`
+}
+
+// GuardrailCanarySnapshot stores the canary decision of one guardrail for one
+// request.
+type GuardrailCanarySnapshot struct {
+	Name    string `json:"name" bson:"name"`
+	Applied bool   `json:"applied" bson:"applied"`
+}
+
+// EmbeddingCacheSnapshot stores the embeddings cache hits and misses of one
+// request.
+type EmbeddingCacheSnapshot struct {
+	Hits   int `json:"hits" bson:"hits"`
+	Misses int `json:"misses" bson:"misses"`
+}
+
+// PromptCompressionSnapshot stores the prompt compression applied to one
+// request. EstimatedTokens is the prompt estimate before compression.
+type PromptCompressionSnapshot struct {
+	Passes          []string `json:"passes" bson:"passes"`
+	EstimatedTokens int      `json:"estimated_tokens" bson:"estimated_tokens"`
+	TokensSaved     int      `json:"tokens_saved" bson:"tokens_saved"`
+}
+
+// ModerationSnapshot stores the moderation pre-check outcome of one request.
+// Scores holds every category score the moderation backend returned.
+type ModerationSnapshot struct {
+	Flagged     []string           `json:"flagged,omitempty" bson:"flagged,omitempty"`
+	Scores      map[string]float64 `json:"scores,omitempty" bson:"scores,omitempty"`
+	Unavailable bool               `json:"unavailable,omitempty" bson:"unavailable,omitempty"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// ToolLoopSnapshot stores the server-side tool loop of one request: every
+// model call, in order, and the tools the gateway ran between them.
+type ToolLoopSnapshot struct {
+	Iterations int                    `json:"iterations" bson:"iterations"`
+	Turns      []ToolLoopTurnSnapshot `json:"turns,omitempty" bson:"turns,omitempty"`
+}
+
+// ToolLoopTurnSnapshot stores one model call of a tool loop.
+type ToolLoopTurnSnapshot struct {
+	ResponseID   string                 `json:"response_id,omitempty" bson:"response_id,omitempty"`
+	Model        string                 `json:"model,omitempty" bson:"model,omitempty"`
+	InputTokens  int                    `json:"input_tokens" bson:"input_tokens"`
+	OutputTokens int                    `json:"output_tokens" bson:"output_tokens"`
+	Calls        []ToolLoopCallSnapshot `json:"calls,omitempty" bson:"calls,omitempty"`
+}
+
+// ToolLoopCallSnapshot stores one server-side tool run of a tool loop.
+type ToolLoopCallSnapshot struct {
+	CallID      string `json:"call_id,omitempty" bson:"call_id,omitempty"`
+	Name        string `json:"name" bson:"name"`
+	DurationMs  int64  `json:"duration_ms" bson:"duration_ms"`
+	OutputBytes int    `json:"output_bytes" bson:"output_bytes"`
+	Truncated   bool   `json:"truncated,omitempty" bson:"truncated,omitempty"`
+	Error       string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
 // marshalLogData marshals the Data field to JSON for SQL storage.
 // Returns nil if data is nil, or "{}" if marshaling fails.
 // This is used by PostgreSQL and SQLite stores.
@@ -138,7 +505,7 @@ func marshalLogData(data *LogData, entryID string) []byte {
 	}
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
-		slog.Warn("failed to marshal log data", "error", err, "id", entryID)
+		auditLogger.Warn("failed to marshal log data", "error", err, "id", entryID)
 		return []byte("{}")
 	}
 	return dataJSON
@@ -173,6 +540,7 @@ var RedactedHeaders = []string{
 	"x-access-token",
 	"proxy-authorization",
 	"x-gomodel-key",
+	"x-gomodel-state-passphrase",
 }
 
 // redactedHeadersSet is built once at package init for O(1) lookups.
@@ -213,6 +581,10 @@ type Config struct {
 	// LogHeaders enables logging of request/response headers
 	LogHeaders bool
 
+	// IgnoreNoBodyLogHeader captures bodies even for requests that opt out
+	// with core.NoBodyLogHeader
+	IgnoreNoBodyLogHeader bool
+
 	// BufferSize is the number of log entries to buffer before flushing
 	BufferSize int
 
@@ -225,8 +597,63 @@ type Config struct {
 	// OnlyModelInteractions limits logging to AI model endpoints only
 	// When true, only /v1/chat/completions, /v1/responses, /v1/embeddings, /v1/files, and /v1/batches are logged
 	OnlyModelInteractions bool
+
+	// BodyCapture bounds captured request and response bodies, globally and
+	// per path prefix. Zero values fall back to MaxBodyCapture.
+	BodyCapture BodyCaptureConfig
+
+	// StreamSamples controls full-text sampling of streamed responses.
+	StreamSamples StreamSampleConfig
+
+	// FailureMode selects what happens when the store rejects a batch:
+	// FailureModeDrop (default), FailureModeSpill or FailureModeBlock
+	FailureMode string
+
+	// SpillDir is the directory of the on-disk queue used by FailureModeSpill
+	SpillDir string
+
+	// SpillMaxBytes caps the on-disk queue; the oldest batches are dropped beyond it (0 = unlimited)
+	SpillMaxBytes int64
+
+	// DrainTimeout bounds how long Flush and Close retry spilled or blocked batches
+	DrainTimeout time.Duration
+
+	// RetryInitialBackoff and RetryMaxBackoff bound the exponential backoff
+	// between store retries in spill and block modes
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff     time.Duration
+
+	// WriteLagWarning logs a warning while the oldest unwritten entry is
+	// older than this (0 = disabled)
+	WriteLagWarning time.Duration
+
+	// Encryption seals captured bodies and headers before they are written
+	// (nil = plaintext)
+	Encryption *Keyring
 }
 
+// Store failure modes for Config.FailureMode.
+const (
+	// FailureModeDrop logs and drops batches the store rejects.
+	FailureModeDrop = "drop"
+	// FailureModeSpill writes rejected batches to a local disk queue and
+	// replays them in order once the store recovers.
+	FailureModeSpill = "spill"
+	// FailureModeBlock retries rejected batches until the store recovers.
+	// Writes block once the buffer is full instead of dropping entries.
+	FailureModeBlock = "block"
+)
+
+// Defaults for store failure handling.
+const (
+	DefaultSpillDir            = "data/audit-spill"
+	DefaultSpillMaxBytes       = 100 * 1024 * 1024
+	DefaultDrainTimeout        = 10 * time.Second
+	DefaultRetryInitialBackoff = time.Second
+	DefaultRetryMaxBackoff     = time.Minute
+	DefaultWriteLagWarning     = time.Minute
+)
+
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() Config {
 	return Config{
@@ -237,5 +664,12 @@ func DefaultConfig() Config {
 		FlushInterval:         5 * time.Second,
 		RetentionDays:         30,
 		OnlyModelInteractions: true,
+		FailureMode:           FailureModeDrop,
+		SpillDir:              DefaultSpillDir,
+		SpillMaxBytes:         DefaultSpillMaxBytes,
+		DrainTimeout:          DefaultDrainTimeout,
+		RetryInitialBackoff:   DefaultRetryInitialBackoff,
+		RetryMaxBackoff:       DefaultRetryMaxBackoff,
+		WriteLagWarning:       DefaultWriteLagWarning,
 	}
 }
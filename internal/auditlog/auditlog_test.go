@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/labstack/echo/v5"
 )
 
@@ -978,6 +979,143 @@ data: [DONE]
 	}
 }
 
+func TestStreamLogObserverRecordsServedModelWithoutBodies(t *testing.T) {
+	streamContent := `data: {"id":"chatcmpl-123","model":"gpt-4o-2024-08-06","choices":[{"delta":{"content":"Hello"}}]}
+
+data: [DONE]
+
+`
+	logger := &capturingLogger{cfg: Config{Enabled: true}}
+	entry := &LogEntry{
+		ID:             "test-entry",
+		Timestamp:      time.Now(),
+		RequestedModel: "gpt-4o",
+	}
+
+	observedStream := streaming.NewObservedSSEStream(
+		io.NopCloser(strings.NewReader(streamContent)),
+		NewStreamLogObserver(logger, entry, "/v1/chat/completions"),
+	)
+	if _, err := io.Copy(io.Discard, observedStream); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	if err := observedStream.Close(); err != nil {
+		t.Fatalf("failed to close stream: %v", err)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(logger.entries))
+	}
+	if got := logger.entries[0].ServedModel; got != "gpt-4o-2024-08-06" {
+		t.Fatalf("ServedModel = %q, want gpt-4o-2024-08-06", got)
+	}
+}
+
+func TestStreamLogObserverRecordsResponseIDs(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		stream             string
+		wantResponseID     string
+		wantProviderRespID string
+	}{
+		{
+			name: "chat chunks",
+			path: "/v1/chat/completions",
+			stream: `data: {"id":"chatcmpl-123","model":"gpt-4o","choices":[{"delta":{"content":"Hi"}}]}
+
+data: {"id":"chatcmpl-123","model":"gpt-4o","choices":[],"usage":{"total_tokens":3}}
+
+data: [DONE]
+
+`,
+			wantResponseID: "chatcmpl-123",
+		},
+		{
+			name: "converted responses stream",
+			path: "/v1/responses",
+			stream: `event: response.created
+data: {"type":"response.created","response":{"id":"resp_abc","status":"in_progress"}}
+
+event: response.output_text.delta
+data: {"type":"response.output_text.delta","delta":"Hi"}
+
+event: response.completed
+data: {"type":"response.completed","response":{"id":"resp_abc","status":"completed","provider_response_id":"msg_01"}}
+
+data: [DONE]
+
+`,
+			wantResponseID:     "resp_abc",
+			wantProviderRespID: "msg_01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &capturingLogger{cfg: Config{Enabled: true}}
+			entry := &LogEntry{ID: "test-entry", Timestamp: time.Now()}
+
+			observedStream := streaming.NewObservedSSEStream(
+				io.NopCloser(strings.NewReader(tt.stream)),
+				NewStreamLogObserver(logger, entry, tt.path),
+			)
+			if _, err := io.Copy(io.Discard, observedStream); err != nil {
+				t.Fatalf("failed to read stream: %v", err)
+			}
+			if err := observedStream.Close(); err != nil {
+				t.Fatalf("failed to close stream: %v", err)
+			}
+
+			if len(logger.entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(logger.entries))
+			}
+			got := logger.entries[0]
+			if got.ResponseID != tt.wantResponseID || got.ProviderResponseID != tt.wantProviderRespID {
+				t.Fatalf("response IDs = %q/%q, want %q/%q", got.ResponseID, got.ProviderResponseID, tt.wantResponseID, tt.wantProviderRespID)
+			}
+		})
+	}
+}
+
+func TestStreamLogObserverCapturesResponsesAnnotations(t *testing.T) {
+	streamContent := `event: response.output_text.delta
+data: {"type":"response.output_text.delta","delta":"See example."}
+
+event: response.output_text.annotation.added
+data: {"type":"response.output_text.annotation.added","annotation_index":0,"annotation":{"type":"url_citation","url":"https://example.com","start_index":4,"end_index":11}}
+
+data: [DONE]
+
+`
+	logger := &capturingLogger{cfg: Config{Enabled: true, LogBodies: true}}
+	entry := &LogEntry{ID: "test-entry", Timestamp: time.Now(), Data: &LogData{}}
+
+	observedStream := streaming.NewObservedSSEStream(
+		io.NopCloser(strings.NewReader(streamContent)),
+		NewStreamLogObserver(logger, entry, "/v1/responses"),
+	)
+	if _, err := io.Copy(io.Discard, observedStream); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+	if err := observedStream.Close(); err != nil {
+		t.Fatalf("failed to close stream: %v", err)
+	}
+
+	body, ok := logger.entries[0].Data.ResponseBody.(map[string]any)
+	if !ok {
+		t.Fatalf("ResponseBody = %T, want map", logger.entries[0].Data.ResponseBody)
+	}
+	output := body["output"].([]map[string]any)
+	text := output[0]["content"].([]map[string]any)[0]
+	annotations, _ := text["annotations"].([]any)
+	if len(annotations) != 1 {
+		t.Fatalf("annotations = %v, want one captured annotation", text["annotations"])
+	}
+	if annotation := annotations[0].(map[string]any); annotation["url"] != "https://example.com" {
+		t.Fatalf("annotation = %v, want url https://example.com", annotation)
+	}
+}
+
 func TestNewStreamLogObserverNilInputs(t *testing.T) {
 	if observer := NewStreamLogObserver(nil, &LogEntry{}, "/v1/chat/completions"); observer != nil {
 		t.Error("expected nil observer with nil logger")
@@ -1205,7 +1343,7 @@ func TestHashAPIKey(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := hashAPIKey(tt.authHeader)
+			result := HashAPIKey(tt.authHeader)
 			if tt.wantEmpty {
 				if result != "" {
 					t.Errorf("expected empty string, got %q", result)
@@ -1222,14 +1360,14 @@ func TestHashAPIKey(t *testing.T) {
 	}
 
 	// Test consistency - same input should produce same hash
-	hash1 := hashAPIKey("Bearer test-key")
-	hash2 := hashAPIKey("Bearer test-key")
+	hash1 := HashAPIKey("Bearer test-key")
+	hash2 := HashAPIKey("Bearer test-key")
 	if hash1 != hash2 {
 		t.Error("same input should produce same hash")
 	}
 
 	// Test different inputs produce different hashes
-	hash3 := hashAPIKey("Bearer different-key")
+	hash3 := HashAPIKey("Bearer different-key")
 	if hash1 == hash3 {
 		t.Error("different inputs should produce different hashes")
 	}
@@ -1260,6 +1398,12 @@ func compressBrotli(data []byte) []byte {
 	return buf.Bytes()
 }
 
+func compressZstd(data []byte) []byte {
+	w, _ := zstd.NewWriter(nil)
+	defer w.Close()
+	return w.EncodeAll(data, nil)
+}
+
 func TestDecompressBody(t *testing.T) {
 	originalData := []byte(`{"message": "hello world", "count": 42}`)
 
@@ -1299,6 +1443,12 @@ func TestDecompressBody(t *testing.T) {
 			compressFunc:     compressBrotli,
 			shouldDecompress: true,
 		},
+		{
+			name:             "zstd encoding",
+			encoding:         "zstd",
+			compressFunc:     compressZstd,
+			shouldDecompress: true,
+		},
 		{
 			name:             "gzip with extra spaces",
 			encoding:         "  gzip  ",
@@ -1356,67 +1506,88 @@ func TestDecompressBodyInvalidData(t *testing.T) {
 	}
 }
 
+// bodyCaptureTestLimits covers the default limit and a smaller and a larger
+// configured one.
+var bodyCaptureTestLimits = []struct {
+	name       string
+	configured int64
+	effective  int
+}{
+	{"default", 0, MaxBodyCapture},
+	{"small", 4096, 4096},
+	{"large", 2 * MaxBodyCapture, 2 * MaxBodyCapture},
+}
+
 func TestResponseBodyCapture_Write_SingleLargeChunk(t *testing.T) {
-	// A single Write call larger than MaxBodyCapture should be capped
-	capture := &responseBodyCapture{
-		ResponseWriter: &discardWriter{},
-		body:           &bytes.Buffer{},
-	}
+	for _, tt := range bodyCaptureTestLimits {
+		t.Run(tt.name, func(t *testing.T) {
+			// A single Write call larger than the limit should be capped
+			capture := &responseBodyCapture{
+				ResponseWriter: &discardWriter{},
+				body:           &bytes.Buffer{},
+				limit:          tt.configured,
+			}
 
-	// Write a chunk larger than MaxBodyCapture in one call
-	largeData := bytes.Repeat([]byte("x"), int(MaxBodyCapture)+1024)
-	n, err := capture.Write(largeData)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if n != len(largeData) {
-		t.Errorf("expected %d bytes written to underlying writer, got %d", len(largeData), n)
-	}
+			largeData := bytes.Repeat([]byte("x"), tt.effective+1024)
+			n, err := capture.Write(largeData)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if n != len(largeData) {
+				t.Errorf("expected %d bytes written to underlying writer, got %d", len(largeData), n)
+			}
 
-	// Buffer should be capped at exactly MaxBodyCapture
-	if capture.body.Len() != int(MaxBodyCapture) {
-		t.Errorf("expected buffer size %d, got %d", MaxBodyCapture, capture.body.Len())
-	}
-	if !capture.truncated {
-		t.Error("expected truncated flag to be set")
+			// Buffer should be capped at exactly the limit
+			if capture.body.Len() != tt.effective {
+				t.Errorf("expected buffer size %d, got %d", tt.effective, capture.body.Len())
+			}
+			if !capture.truncated {
+				t.Error("expected truncated flag to be set")
+			}
+		})
 	}
 }
 
 func TestResponseBodyCapture_Write_MultipleChunksOverflow(t *testing.T) {
-	capture := &responseBodyCapture{
-		ResponseWriter: &discardWriter{},
-		body:           &bytes.Buffer{},
-	}
+	for _, tt := range bodyCaptureTestLimits {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := &responseBodyCapture{
+				ResponseWriter: &discardWriter{},
+				body:           &bytes.Buffer{},
+				limit:          tt.configured,
+			}
 
-	// Write chunks that collectively exceed MaxBodyCapture
-	chunkSize := int(MaxBodyCapture) / 2
-	chunk := bytes.Repeat([]byte("a"), chunkSize)
+			// Write chunks that collectively exceed the limit
+			chunkSize := tt.effective / 2
+			chunk := bytes.Repeat([]byte("a"), chunkSize)
 
-	// First chunk: should fit entirely
-	_, _ = capture.Write(chunk)
-	if capture.truncated {
-		t.Error("should not be truncated after first chunk")
-	}
-	if capture.body.Len() != chunkSize {
-		t.Errorf("expected buffer size %d, got %d", chunkSize, capture.body.Len())
-	}
+			// First chunk: should fit entirely
+			_, _ = capture.Write(chunk)
+			if capture.truncated {
+				t.Error("should not be truncated after first chunk")
+			}
+			if capture.body.Len() != chunkSize {
+				t.Errorf("expected buffer size %d, got %d", chunkSize, capture.body.Len())
+			}
 
-	// Second chunk: fits exactly (no data lost, so truncated remains false)
-	_, _ = capture.Write(chunk)
-	if capture.truncated {
-		t.Error("should not be truncated when buffer is exactly at limit")
-	}
-	if capture.body.Len() != int(MaxBodyCapture) {
-		t.Errorf("expected buffer at %d, got %d", MaxBodyCapture, capture.body.Len())
-	}
+			// Second chunk: fits exactly (no data lost, so truncated remains false)
+			_, _ = capture.Write(chunk)
+			if capture.truncated {
+				t.Error("should not be truncated when buffer is exactly at limit")
+			}
+			if capture.body.Len() != tt.effective {
+				t.Errorf("expected buffer at %d, got %d", tt.effective, capture.body.Len())
+			}
 
-	// Third chunk: entirely skipped, truncated flag set
-	_, _ = capture.Write(chunk)
-	if !capture.truncated {
-		t.Error("should be truncated after third chunk is rejected")
-	}
-	if capture.body.Len() != int(MaxBodyCapture) {
-		t.Errorf("expected buffer still at %d after third chunk, got %d", MaxBodyCapture, capture.body.Len())
+			// Third chunk: entirely skipped, truncated flag set
+			_, _ = capture.Write(chunk)
+			if !capture.truncated {
+				t.Error("should be truncated after third chunk is rejected")
+			}
+			if capture.body.Len() != tt.effective {
+				t.Errorf("expected buffer still at %d after third chunk, got %d", tt.effective, capture.body.Len())
+			}
+		})
 	}
 }
 
@@ -1445,25 +1616,25 @@ func TestResponseBodyCapture_Write_SkipsWhenDisabled(t *testing.T) {
 	}
 }
 
-func TestHasResponseBodyCaptureHandlesWrappedAndCyclicWriters(t *testing.T) {
+func TestFindResponseBodyCaptureHandlesWrappedAndCyclicWriters(t *testing.T) {
 	capture := &responseBodyCapture{
 		ResponseWriter: &discardWriter{},
 		body:           &bytes.Buffer{},
 	}
 	wrapped := &unwrapTestWriter{ResponseWriter: &discardWriter{}, next: capture}
-	if !hasResponseBodyCapture(wrapped) {
+	if findResponseBodyCapture(wrapped) != capture {
 		t.Fatal("expected wrapped responseBodyCapture to be detected")
 	}
 
 	self := &selfUnwrapTestWriter{ResponseWriter: &discardWriter{}}
-	if hasResponseBodyCapture(self) {
+	if findResponseBodyCapture(self) != nil {
 		t.Fatal("expected self-unwrapping writer not to report responseBodyCapture")
 	}
 
 	first := &unwrapTestWriter{ResponseWriter: &discardWriter{}}
 	second := &unwrapTestWriter{ResponseWriter: &discardWriter{}, next: first}
 	first.next = second
-	if hasResponseBodyCapture(first) {
+	if findResponseBodyCapture(first) != nil {
 		t.Fatal("expected cyclic wrapper chain not to report responseBodyCapture")
 	}
 }
@@ -1516,6 +1687,17 @@ func (w *selfUnwrapTestWriter) Unwrap() http.ResponseWriter {
 }
 
 func TestLimitedReaderRequestBodyCapture(t *testing.T) {
+	for _, tt := range bodyCaptureTestLimits {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := BodyCaptureConfig{Paths: map[string]BodyCaptureLimits{
+				"/v1/chat/": {Request: tt.configured},
+			}}
+			testLimitedReaderRequestBodyCapture(t, capture.LimitsFor("/v1/chat/completions").Request)
+		})
+	}
+}
+
+func testLimitedReaderRequestBodyCapture(t *testing.T, limit int64) {
 	t.Run("chunked request body under limit is captured", func(t *testing.T) {
 		body := `{"model":"gpt-4","messages":[]}`
 		req, _ := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body))
@@ -1523,13 +1705,13 @@ func TestLimitedReaderRequestBodyCapture(t *testing.T) {
 
 		entry := &LogEntry{Data: &LogData{}}
 		// Simulate the middleware body capture logic
-		limitedReader := io.LimitReader(req.Body, MaxBodyCapture+1)
+		limitedReader := io.LimitReader(req.Body, limit+1)
 		bodyBytes, err := io.ReadAll(limitedReader)
 		if err != nil {
 			t.Fatalf("unexpected read error: %v", err)
 		}
 
-		if int64(len(bodyBytes)) > MaxBodyCapture {
+		if int64(len(bodyBytes)) > limit {
 			t.Fatal("body should be under limit")
 		}
 
@@ -1547,20 +1729,20 @@ func TestLimitedReaderRequestBodyCapture(t *testing.T) {
 	})
 
 	t.Run("chunked request body over limit sets flag and preserves downstream body", func(t *testing.T) {
-		// Create a body larger than MaxBodyCapture
-		largeBody := strings.Repeat("x", int(MaxBodyCapture)+100)
+		// Create a body larger than the limit
+		largeBody := strings.Repeat("x", int(limit)+100)
 		req, _ := http.NewRequest("POST", "/v1/chat/completions", strings.NewReader(largeBody))
 		req.ContentLength = -1 // Simulate chunked encoding
 
 		entry := &LogEntry{Data: &LogData{}}
 
-		limitedReader := io.LimitReader(req.Body, MaxBodyCapture+1)
+		limitedReader := io.LimitReader(req.Body, limit+1)
 		bodyBytes, err := io.ReadAll(limitedReader)
 		if err != nil {
 			t.Fatalf("unexpected read error: %v", err)
 		}
 
-		if int64(len(bodyBytes)) <= MaxBodyCapture {
+		if int64(len(bodyBytes)) <= limit {
 			t.Fatal("body should exceed limit")
 		}
 
@@ -1585,18 +1767,18 @@ func TestLimitedReaderRequestBodyCapture(t *testing.T) {
 	})
 
 	t.Run("overflow path propagates Close to original body", func(t *testing.T) {
-		largeBody := strings.Repeat("x", int(MaxBodyCapture)+100)
+		largeBody := strings.Repeat("x", int(limit)+100)
 		tracker := &trackingReadCloser{Reader: strings.NewReader(largeBody)}
 		req, _ := http.NewRequest("POST", "/v1/chat/completions", tracker)
 		req.ContentLength = -1
 
 		// Drive the overflow reconstruction path
-		limitedReader := io.LimitReader(req.Body, MaxBodyCapture+1)
+		limitedReader := io.LimitReader(req.Body, limit+1)
 		bodyBytes, err := io.ReadAll(limitedReader)
 		if err != nil {
 			t.Fatalf("unexpected read error: %v", err)
 		}
-		if int64(len(bodyBytes)) <= MaxBodyCapture {
+		if int64(len(bodyBytes)) <= limit {
 			t.Fatal("body should exceed limit")
 		}
 
@@ -1625,15 +1807,15 @@ func TestLimitedReaderRequestBodyCapture(t *testing.T) {
 	})
 
 	t.Run("io.LimitReader caps memory allocation", func(t *testing.T) {
-		// Verify that io.LimitReader prevents reading more than MaxBodyCapture+1 bytes
-		largeBody := strings.Repeat("z", int(MaxBodyCapture)*3)
-		reader := io.LimitReader(strings.NewReader(largeBody), MaxBodyCapture+1)
+		// Verify that io.LimitReader prevents reading more than limit+1 bytes
+		largeBody := strings.Repeat("z", int(limit)*3)
+		reader := io.LimitReader(strings.NewReader(largeBody), limit+1)
 		data, err := io.ReadAll(reader)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if int64(len(data)) != MaxBodyCapture+1 {
-			t.Errorf("expected exactly %d bytes, got %d", MaxBodyCapture+1, len(data))
+		if int64(len(data)) != limit+1 {
+			t.Errorf("expected exactly %d bytes, got %d", limit+1, len(data))
 		}
 	})
 }
@@ -0,0 +1,61 @@
+package auditlog
+
+import (
+	"maps"
+	"strings"
+)
+
+// BodyCaptureLimits bounds how many bytes of a request and a response body
+// are captured. A zero value means "inherit": MaxBodyCapture for the global
+// limits, the global limit for a path override.
+type BodyCaptureLimits struct {
+	Request  int64 `json:"request"`
+	Response int64 `json:"response"`
+}
+
+// BodyCaptureConfig holds the global body capture limits and per-path
+// overrides keyed by path prefix. The longest matching prefix wins.
+type BodyCaptureConfig struct {
+	BodyCaptureLimits
+	Paths map[string]BodyCaptureLimits
+}
+
+// LimitsFor returns the effective capture limits for path. Both limits are
+// always positive and never exceed MaxBodyCaptureCeiling.
+func (c BodyCaptureConfig) LimitsFor(path string) BodyCaptureLimits {
+	limits := BodyCaptureLimits{
+		Request:  resolveBodyCaptureLimit(c.Request, MaxBodyCapture),
+		Response: resolveBodyCaptureLimit(c.Response, MaxBodyCapture),
+	}
+
+	matched := ""
+	for prefix := range c.Paths {
+		if len(prefix) > len(matched) && strings.HasPrefix(path, prefix) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return limits
+	}
+	override := c.Paths[matched]
+	limits.Request = resolveBodyCaptureLimit(override.Request, limits.Request)
+	limits.Response = resolveBodyCaptureLimit(override.Response, limits.Response)
+	return limits
+}
+
+// Clone returns a copy of c that does not share the Paths map.
+func (c BodyCaptureConfig) Clone() BodyCaptureConfig {
+	c.Paths = maps.Clone(c.Paths)
+	return c
+}
+
+func resolveBodyCaptureLimit(limit, fallback int64) int64 {
+	switch {
+	case limit <= 0:
+		return fallback
+	case limit > MaxBodyCaptureCeiling:
+		return MaxBodyCaptureCeiling
+	default:
+		return limit
+	}
+}
@@ -0,0 +1,186 @@
+package auditlog
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func TestBodyCaptureConfig_LimitsFor(t *testing.T) {
+	cfg := BodyCaptureConfig{
+		BodyCaptureLimits: BodyCaptureLimits{Request: 2048, Response: 4096},
+		Paths: map[string]BodyCaptureLimits{
+			"/v1/":           {Response: 8192},
+			"/v1/responses":  {Request: 4 * MaxBodyCapture, Response: 8 * MaxBodyCapture},
+			"/v1/embeddings": {Request: 256, Response: 64},
+			"/p/":            {Response: 2 * MaxBodyCaptureCeiling},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want BodyCaptureLimits
+	}{
+		{"/health", BodyCaptureLimits{Request: 2048, Response: 4096}},
+		{"/v1/chat/completions", BodyCaptureLimits{Request: 2048, Response: 8192}},
+		{"/v1/responses/resp_1", BodyCaptureLimits{Request: 4 * MaxBodyCapture, Response: 8 * MaxBodyCapture}},
+		{"/v1/embeddings", BodyCaptureLimits{Request: 256, Response: 64}},
+		{"/p/openai/chat", BodyCaptureLimits{Request: 2048, Response: MaxBodyCaptureCeiling}},
+	}
+	for _, tt := range tests {
+		if got := cfg.LimitsFor(tt.path); got != tt.want {
+			t.Errorf("LimitsFor(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+
+	if got := (BodyCaptureConfig{}).LimitsFor("/v1/chat/completions"); got.Request != MaxBodyCapture || got.Response != MaxBodyCapture {
+		t.Fatalf("zero config LimitsFor() = %+v, want MaxBodyCapture for both", got)
+	}
+}
+
+func TestPopulateRequestData_UsesPerPathRequestLimit(t *testing.T) {
+	body := []byte(`{"input":"` + strings.Repeat("x", 200) + `"}`)
+	cfg := Config{
+		LogBodies: true,
+		BodyCapture: BodyCaptureConfig{Paths: map[string]BodyCaptureLimits{
+			"/v1/embeddings": {Request: 128},
+		}},
+	}
+
+	for _, tt := range []struct {
+		path    string
+		tooBig  bool
+		hasBody bool
+	}{
+		{"/v1/embeddings", true, false},
+		{"/v1/chat/completions", false, true},
+	} {
+		req := httptest.NewRequest("POST", tt.path, nil)
+		snapshot := core.NewRequestSnapshot("POST", tt.path, nil, nil, nil, "application/json", body, false, "req-1", nil, "/")
+		req = req.WithContext(core.WithRequestSnapshot(context.Background(), snapshot))
+
+		entry := &LogEntry{}
+		PopulateRequestData(entry, req, cfg)
+		if entry.Data.RequestBodyTooBigToHandle != tt.tooBig || (entry.Data.RequestBody != nil) != tt.hasBody {
+			t.Fatalf("%s: too big = %v, body = %v; want %v, %v",
+				tt.path, entry.Data.RequestBodyTooBigToHandle, entry.Data.RequestBody != nil, tt.tooBig, tt.hasBody)
+		}
+	}
+}
+
+func TestPopulateRequestData_DecodesEncodedRequestBody(t *testing.T) {
+	body := []byte(`{"model":"gpt-4o","input":"` + strings.Repeat("x", 200) + `"}`)
+	compressed := compressZstd(body)
+	cfg := Config{
+		LogBodies: true,
+		BodyCapture: BodyCaptureConfig{Paths: map[string]BodyCaptureLimits{
+			"/v1/embeddings": {Request: 128},
+		}},
+	}
+
+	for _, tt := range []struct {
+		path    string
+		tooBig  bool
+		hasBody bool
+	}{
+		{"/v1/embeddings", true, false},
+		{"/v1/chat/completions", false, true},
+	} {
+		req := httptest.NewRequest("POST", tt.path, nil)
+		req.Header.Set("Content-Encoding", "zstd")
+		snapshot := core.NewRequestSnapshot("POST", tt.path, nil, nil, nil, "application/json", compressed, false, "req-1", nil, "/")
+		req = req.WithContext(core.WithRequestSnapshot(context.Background(), snapshot))
+
+		entry := &LogEntry{}
+		PopulateRequestData(entry, req, cfg)
+		if entry.Data.RequestBodyTooBigToHandle != tt.tooBig || (entry.Data.RequestBody != nil) != tt.hasBody {
+			t.Fatalf("%s: too big = %v, body = %v; want %v, %v",
+				tt.path, entry.Data.RequestBodyTooBigToHandle, entry.Data.RequestBody != nil, tt.tooBig, tt.hasBody)
+		}
+		if tt.hasBody {
+			if parsed, ok := entry.Data.RequestBody.(map[string]any); !ok || parsed["model"] != "gpt-4o" {
+				t.Fatalf("%s: RequestBody = %#v, want decoded JSON", tt.path, entry.Data.RequestBody)
+			}
+		}
+	}
+}
+
+func TestCaptureInternalJSONExchange_UsesPerPathLimits(t *testing.T) {
+	payload := map[string]any{"payload": strings.Repeat("x", 600)}
+	cfg := Config{
+		LogBodies: true,
+		BodyCapture: BodyCaptureConfig{
+			BodyCaptureLimits: BodyCaptureLimits{Request: 64},
+			Paths:             map[string]BodyCaptureLimits{"/v1/chat/": {Request: 1024, Response: 100}},
+		},
+	}
+
+	entry := &LogEntry{RequestID: "req-1", Data: &LogData{}}
+	CaptureInternalJSONExchange(entry, context.Background(), "POST", "/v1/chat/completions", payload, payload, nil, cfg)
+
+	if entry.Data.RequestBodyTooBigToHandle || entry.Data.RequestBody == nil {
+		t.Fatalf("request too big = %v, want body captured under the 1024-byte path limit", entry.Data.RequestBodyTooBigToHandle)
+	}
+	if !entry.Data.ResponseBodyTooBigToHandle {
+		t.Fatal("ResponseBodyTooBigToHandle = false, want true")
+	}
+	if got, ok := entry.Data.ResponseBody.(string); !ok || len(got) != 100 {
+		t.Fatalf("ResponseBody = %#v, want 100 truncated bytes", entry.Data.ResponseBody)
+	}
+}
+
+func TestStreamLogObserver_UsesResponseLimitForPath(t *testing.T) {
+	logger := &capturingLogger{cfg: Config{
+		Enabled:   true,
+		LogBodies: true,
+		BodyCapture: BodyCaptureConfig{Paths: map[string]BodyCaptureLimits{
+			"/v1/chat/completions": {Response: 10},
+		}},
+	}}
+	entry := &LogEntry{Data: &LogData{}}
+	observer := NewStreamLogObserver(logger, entry, "/v1/chat/completions")
+
+	for _, delta := range []string{"Hello, ", "world", "!"} {
+		observer.OnJSONEvent(map[string]any{
+			"choices": []any{map[string]any{"delta": map[string]any{"content": delta}}},
+		})
+	}
+	observer.OnStreamClose()
+
+	if !entry.Data.ResponseBodyTooBigToHandle {
+		t.Fatal("ResponseBodyTooBigToHandle = false, want true")
+	}
+	body := entry.Data.ResponseBody.(map[string]any)
+	message := body["choices"].([]map[string]any)[0]["message"].(map[string]any)
+	if got := message["content"]; got != "Hello, wor" {
+		t.Fatalf("content = %q, want the first 10 bytes", got)
+	}
+}
+
+func TestPopulateRequestData_ReplacesInlineImages(t *testing.T) {
+	payload := strings.Repeat("A", 4000)
+	body := []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":[` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,` + payload + `"}},` +
+		`{"type":"image_url","image_url":{"url":"data:image/jpeg;base64,` + payload + `"}}]}]}`)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	snapshot := core.NewRequestSnapshot("POST", "/v1/chat/completions", nil, nil, nil, "application/json", body, false, "req-1", nil, "/")
+	req = req.WithContext(core.WithRequestSnapshot(context.Background(), snapshot))
+
+	entry := &LogEntry{}
+	PopulateRequestData(entry, req, Config{LogBodies: true})
+
+	images := entry.Data.RequestImages
+	if len(images) != 2 || images[0].Bytes != 3000 || images[0].SHA256 != images[1].SHA256 {
+		t.Fatalf("RequestImages = %+v, want two 3000-byte images with the same hash", images)
+	}
+	content := entry.Data.RequestBody.(map[string]any)["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	for i, part := range content {
+		url := part.(map[string]any)["image_url"].(map[string]any)["url"]
+		if url != images[i].Placeholder() {
+			t.Fatalf("content[%d] url = %v, want placeholder %q", i, url, images[i].Placeholder())
+		}
+	}
+}
@@ -2,12 +2,18 @@ package auditlog
 
 // Buffer and capture limits for audit logging.
 const (
-	// MaxBodyCapture is the maximum size of request/response bodies to capture (1MB).
+	// MaxBodyCapture is the default maximum size of request/response bodies to
+	// capture (1MB) when Config.BodyCapture does not set a limit.
 	// Prevents memory exhaustion from large payloads.
 	MaxBodyCapture = 1024 * 1024
 
-	// MaxContentCapture is the maximum size of accumulated streaming content (1MB).
-	// Used by the stream observer to limit reconstructed response body size.
+	// MaxBodyCaptureCeiling is the hard upper bound for any configured body
+	// capture limit (16MB).
+	MaxBodyCaptureCeiling = 16 * 1024 * 1024
+
+	// MaxContentCapture is the default maximum size of accumulated streaming
+	// content (1MB). The stream observer uses the configured response body
+	// limit for the stream path instead when one is set.
 	MaxContentCapture = 1024 * 1024
 
 	// BatchFlushThreshold is the number of entries that triggers an immediate flush.
@@ -30,4 +36,9 @@ const (
 	// When true, the middleware skips logging because the stream observer path
 	// handles streaming audit logging.
 	LogEntryStreamingKey contextKey = "auditlog_entry_streaming"
+
+	// OriginalResponseBodyKey is the context key for a response body that
+	// was rewritten after the handler wrote it. The middleware records it
+	// instead of the rewritten body the client received.
+	OriginalResponseBodyKey contextKey = "auditlog_original_response_body"
 )
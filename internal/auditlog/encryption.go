@@ -0,0 +1,256 @@
+package auditlog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gomodel/config"
+)
+
+// encryptionKeySize is the size of master keys and per-entry data keys
+// (AES-256).
+const encryptionKeySize = 32
+
+// ErrEncryptionDisabled is returned by re-encryption when no encryption keys
+// are configured.
+var ErrEncryptionDisabled = errors.New("audit log encryption is not configured")
+
+// SealedFields holds the encrypted bodies (and optionally headers) of an
+// entry. Each entry is encrypted with its own AES-256-GCM data key, which is
+// stored wrapped by the master key named by KeyID.
+type SealedFields struct {
+	KeyID string `json:"key_id" bson:"key_id"`
+	// WrappedKey is the nonce-prefixed data key encrypted by the master key.
+	WrappedKey []byte `json:"wrapped_key" bson:"wrapped_key"`
+	Nonce      []byte `json:"nonce" bson:"nonce"`
+	Ciphertext []byte `json:"ciphertext" bson:"ciphertext"`
+}
+
+// sealedPayload is the plaintext of SealedFields.Ciphertext.
+type sealedPayload struct {
+	RequestBody     any               `json:"request_body,omitempty"`
+	ResponseBody    any               `json:"response_body,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+}
+
+// Keyring encrypts audit log entries with the newest configured master key
+// and decrypts them with the key recorded on each entry. A nil Keyring
+// leaves entries in plaintext and reports sealed entries as undecryptable.
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	active  string
+	headers bool
+}
+
+// NewKeyring validates cfg and builds a Keyring. It returns nil when no keys
+// are configured.
+func NewKeyring(cfg config.LogEncryptionConfig) (*Keyring, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(cfg.Keys)), headers: cfg.Headers}
+	for i, keyCfg := range cfg.Keys {
+		id := strings.TrimSpace(keyCfg.ID)
+		if id == "" {
+			return nil, fmt.Errorf("logging.encryption.keys[%d]: id is required", i)
+		}
+		if _, exists := k.keys[id]; exists {
+			return nil, fmt.Errorf("logging.encryption.keys[%d]: duplicate key id %q", i, id)
+		}
+		secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(keyCfg.Secret))
+		if err != nil {
+			return nil, fmt.Errorf("logging.encryption.keys[%d]: secret of key %q is not valid base64: %w", i, id, err)
+		}
+		if len(secret) != encryptionKeySize {
+			return nil, fmt.Errorf("logging.encryption.keys[%d]: secret of key %q must decode to %d bytes, got %d", i, id, encryptionKeySize, len(secret))
+		}
+		aead, err := newGCM(secret)
+		if err != nil {
+			return nil, fmt.Errorf("logging.encryption.keys[%d]: %w", i, err)
+		}
+		k.keys[id] = aead
+		k.active = id
+	}
+	return k, nil
+}
+
+// ActiveKeyID returns the ID of the key new entries are encrypted with.
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.active
+}
+
+// Seal encrypts the bodies of entry, and its headers when configured, with a
+// fresh data key and replaces them with LogData.Sealed. Entries without
+// captured fields and entries that are already sealed are left unchanged.
+func (k *Keyring) Seal(entry *LogEntry) error {
+	if k == nil || entry == nil || entry.Data == nil || entry.Data.Sealed != nil {
+		return nil
+	}
+	data := *entry.Data
+	payload := sealedPayload{RequestBody: data.RequestBody, ResponseBody: data.ResponseBody}
+	data.RequestBody, data.ResponseBody = nil, nil
+	if k.headers {
+		payload.RequestHeaders, payload.ResponseHeaders = data.RequestHeaders, data.ResponseHeaders
+		data.RequestHeaders, data.ResponseHeaders = nil, nil
+	}
+	if payload.RequestBody == nil && payload.ResponseBody == nil && payload.RequestHeaders == nil && payload.ResponseHeaders == nil {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log fields for encryption: %w", err)
+	}
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return fmt.Errorf("failed to generate audit log data key: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+	nonce, err := randomNonce(dataAEAD)
+	if err != nil {
+		return err
+	}
+	wrapped, err := k.wrap(k.active, dataKey)
+	if err != nil {
+		return err
+	}
+
+	data.Sealed = &SealedFields{
+		KeyID:      k.active,
+		WrappedKey: wrapped,
+		Nonce:      nonce,
+		Ciphertext: dataAEAD.Seal(nil, nonce, plaintext, []byte(entry.ID)),
+	}
+	entry.Data = &data
+	return nil
+}
+
+// Open restores the sealed fields of entry. When they cannot be decrypted,
+// the entry keeps its metadata, the sealed fields are dropped and
+// LogData.DecryptionError explains why; the error is also returned.
+func (k *Keyring) Open(entry *LogEntry) error {
+	if entry == nil || entry.Data == nil || entry.Data.Sealed == nil {
+		return nil
+	}
+	data := *entry.Data
+	sealed := data.Sealed
+	data.Sealed = nil
+	entry.Data = &data
+
+	payload, err := k.decrypt(entry.ID, sealed)
+	if err != nil {
+		data.DecryptionError = err.Error()
+		return err
+	}
+	data.RequestBody, data.ResponseBody = payload.RequestBody, payload.ResponseBody
+	if payload.RequestHeaders != nil || payload.ResponseHeaders != nil {
+		data.RequestHeaders, data.ResponseHeaders = payload.RequestHeaders, payload.ResponseHeaders
+	}
+	return nil
+}
+
+// Rewrap re-encrypts the data key of sealed with the active key. The entry
+// ciphertext is unchanged.
+func (k *Keyring) Rewrap(sealed *SealedFields) (*SealedFields, error) {
+	if k == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	dataKey, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := k.wrap(k.active, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SealedFields{
+		KeyID:      k.active,
+		WrappedKey: wrapped,
+		Nonce:      sealed.Nonce,
+		Ciphertext: sealed.Ciphertext,
+	}, nil
+}
+
+func (k *Keyring) decrypt(entryID string, sealed *SealedFields) (*sealedPayload, error) {
+	dataKey, err := k.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := dataAEAD.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(entryID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt audit log fields: %w", err)
+	}
+	var payload sealedPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal decrypted audit log fields: %w", err)
+	}
+	return &payload, nil
+}
+
+// wrap encrypts dataKey with the master key keyID, binding the key ID as
+// additional data.
+func (k *Keyring) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	master := k.keys[keyID]
+	nonce, err := randomNonce(master)
+	if err != nil {
+		return nil, err
+	}
+	return master.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (k *Keyring) unwrap(sealed *SealedFields) ([]byte, error) {
+	if k == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	master, ok := k.keys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("audit log encryption key %q is not configured", sealed.KeyID)
+	}
+	nonceSize := master.NonceSize()
+	if len(sealed.WrappedKey) < nonceSize {
+		return nil, fmt.Errorf("audit log data key wrapped by %q is truncated", sealed.KeyID)
+	}
+	dataKey, err := master.Open(nil, sealed.WrappedKey[:nonceSize], sealed.WrappedKey[nonceSize:], []byte(sealed.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap audit log data key with key %q: %w", sealed.KeyID, err)
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log cipher: %w", err)
+	}
+	return aead, nil
+}
+
+func randomNonce(aead cipher.AEAD) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate audit log nonce: %w", err)
+	}
+	return nonce, nil
+}
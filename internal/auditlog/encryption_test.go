@@ -0,0 +1,294 @@
+package auditlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/pipelinestats"
+)
+
+func testEncryptionKey(fill byte) config.LogEncryptionKeyConfig {
+	return config.LogEncryptionKeyConfig{
+		ID:     fmt.Sprintf("k%c", fill),
+		Secret: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, encryptionKeySize)),
+	}
+}
+
+func newTestKeyring(t *testing.T, headers bool, keys ...config.LogEncryptionKeyConfig) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(config.LogEncryptionConfig{Keys: keys, Headers: headers})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return keyring
+}
+
+func sealableEntry(id string) *LogEntry {
+	return &LogEntry{
+		ID:             id,
+		Timestamp:      time.Now(),
+		RequestedModel: "gpt-5",
+		Provider:       "openai",
+		StatusCode:     200,
+		Data: &LogData{
+			APIKeyHash:      "abc123",
+			RequestHeaders:  map[string]string{"X-Trace": "t1"},
+			ResponseHeaders: map[string]string{"X-Request-Id": "1"},
+			RequestBody:     map[string]any{"messages": []any{"my password is hunter2"}},
+			ResponseBody:    map[string]any{"id": "resp_1"},
+		},
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	if keyring, err := NewKeyring(config.LogEncryptionConfig{}); err != nil || keyring != nil {
+		t.Fatalf("NewKeyring(no keys) = %v, %v; want nil, nil", keyring, err)
+	}
+
+	keyring := newTestKeyring(t, false, testEncryptionKey('a'), testEncryptionKey('b'))
+	if got := keyring.ActiveKeyID(); got != "kb" {
+		t.Fatalf("ActiveKeyID = %q, want kb", got)
+	}
+
+	for name, keys := range map[string][]config.LogEncryptionKeyConfig{
+		"missing id":   {{Secret: testEncryptionKey('a').Secret}},
+		"duplicate id": {testEncryptionKey('a'), testEncryptionKey('a')},
+		"not base64":   {{ID: "k1", Secret: "not base64!"}},
+		"short secret": {{ID: "k1", Secret: base64.StdEncoding.EncodeToString([]byte("short"))}},
+	} {
+		if _, err := NewKeyring(config.LogEncryptionConfig{Keys: keys}); err == nil {
+			t.Errorf("%s: NewKeyring succeeded", name)
+		}
+	}
+}
+
+func TestKeyring_SealAndOpen(t *testing.T) {
+	keyring := newTestKeyring(t, false, testEncryptionKey('a'))
+	entry := sealableEntry("entry-1")
+	original := entry.Data
+
+	if err := keyring.Seal(entry); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if original.RequestBody == nil {
+		t.Fatal("Seal modified the caller's LogData")
+	}
+	data := entry.Data
+	if data.Sealed == nil || data.Sealed.KeyID != "ka" {
+		t.Fatalf("Sealed = %+v, want key ka", data.Sealed)
+	}
+	if data.RequestBody != nil || data.ResponseBody != nil {
+		t.Fatalf("bodies left in plaintext: %+v / %+v", data.RequestBody, data.ResponseBody)
+	}
+	if data.RequestHeaders == nil {
+		t.Fatal("headers were sealed although header encryption is off")
+	}
+	if bytes.Contains(data.Sealed.Ciphertext, []byte("hunter2")) {
+		t.Fatal("ciphertext contains the plaintext body")
+	}
+
+	if err := keyring.Seal(entry); err != nil || entry.Data.Sealed != data.Sealed {
+		t.Fatalf("second Seal resealed the entry: %v", err)
+	}
+
+	if err := keyring.Open(entry); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	body, _ := entry.Data.RequestBody.(map[string]any)
+	if entry.Data.Sealed != nil || body == nil || !strings.Contains(fmt.Sprint(body["messages"]), "hunter2") {
+		t.Fatalf("opened entry = %+v", entry.Data)
+	}
+}
+
+func TestKeyring_SealsHeadersWhenConfigured(t *testing.T) {
+	keyring := newTestKeyring(t, true, testEncryptionKey('a'))
+	entry := sealableEntry("entry-1")
+
+	if err := keyring.Seal(entry); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if entry.Data.RequestHeaders != nil || entry.Data.ResponseHeaders != nil {
+		t.Fatal("headers left in plaintext")
+	}
+	if err := keyring.Open(entry); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if entry.Data.RequestHeaders["X-Trace"] != "t1" || entry.Data.ResponseHeaders["X-Request-Id"] != "1" {
+		t.Fatalf("headers = %+v / %+v", entry.Data.RequestHeaders, entry.Data.ResponseHeaders)
+	}
+}
+
+func TestKeyring_OpenRejectsCiphertextMovedToAnotherEntry(t *testing.T) {
+	keyring := newTestKeyring(t, false, testEncryptionKey('a'))
+	entry := sealableEntry("entry-1")
+	if err := keyring.Seal(entry); err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	entry.ID = "entry-2"
+	if err := keyring.Open(entry); err == nil {
+		t.Fatal("Open succeeded for a ciphertext bound to another entry")
+	}
+	if entry.Data.DecryptionError == "" || entry.Data.Sealed != nil {
+		t.Fatalf("failed Open left %+v", entry.Data)
+	}
+}
+
+// setupEncryptedSQLite writes entries sealed by writer to a SQLite store and
+// returns a reader decrypting with reader.
+func setupEncryptedSQLite(t *testing.T, writer, readerKeys *Keyring, entries ...*LogEntry) *DecryptingReader {
+	t.Helper()
+	db := createTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for _, entry := range entries {
+		if err := writer.Seal(entry); err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+	}
+	if err := store.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	return NewDecryptingReader(reader, readerKeys)
+}
+
+func TestDecryptingReader_WrongKeyIsPerEntryError(t *testing.T) {
+	oldKey := newTestKeyring(t, false, testEncryptionKey('a'))
+	newKey := newTestKeyring(t, false, testEncryptionKey('b'))
+	ctx := context.Background()
+
+	plain := sealableEntry("plain")
+	plain.Data.RequestBody = nil
+	plain.Data.ResponseBody = nil
+	plain.Data.RequestHeaders = nil
+	plain.Data.ResponseHeaders = nil
+	reader := setupEncryptedSQLite(t, oldKey, newKey, sealableEntry("old"), plain)
+
+	page, err := reader.GetLogs(ctx, LogQueryParams{})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if len(page.Entries) != 2 {
+		t.Fatalf("GetLogs returned %d entries, want 2", len(page.Entries))
+	}
+	for _, entry := range page.Entries {
+		switch entry.ID {
+		case "old":
+			if !strings.Contains(entry.Data.DecryptionError, `key "ka" is not configured`) {
+				t.Fatalf("DecryptionError = %q", entry.Data.DecryptionError)
+			}
+			if entry.Data.Sealed != nil || entry.Data.RequestBody != nil || entry.Data.APIKeyHash != "abc123" {
+				t.Fatalf("undecryptable entry = %+v", entry.Data)
+			}
+		case "plain":
+			if entry.Data.DecryptionError != "" {
+				t.Fatalf("plain entry DecryptionError = %q", entry.Data.DecryptionError)
+			}
+		}
+	}
+}
+
+func TestDecryptingReader_ReEncryptMigratesToActiveKey(t *testing.T) {
+	oldKey := newTestKeyring(t, false, testEncryptionKey('a'))
+	rotated := newTestKeyring(t, false, testEncryptionKey('a'), testEncryptionKey('b'))
+	ctx := context.Background()
+
+	reader := setupEncryptedSQLite(t, oldKey, rotated, sealableEntry("e1"), sealableEntry("e2"), sealableEntry("e3"))
+
+	result, err := reader.ReEncrypt(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("ReEncrypt: %v", err)
+	}
+	if result.Rewrapped != 2 || result.Done || result.Next != "e2" || result.ActiveKeyID != "kb" {
+		t.Fatalf("first page = %+v", result)
+	}
+	result, err = reader.ReEncrypt(ctx, result.Next, 2)
+	if err != nil {
+		t.Fatalf("ReEncrypt: %v", err)
+	}
+	if result.Rewrapped != 1 || !result.Done {
+		t.Fatalf("second page = %+v", result)
+	}
+
+	// Only the new key remains: every entry must still decrypt.
+	reader.keyring = newTestKeyring(t, false, testEncryptionKey('b'))
+	for _, id := range []string{"e1", "e2", "e3"} {
+		entry, err := reader.GetLogByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetLogByID(%s): %v", id, err)
+		}
+		if entry.Data.DecryptionError != "" || entry.Data.ResponseBody == nil {
+			t.Fatalf("entry %s after re-encryption = %+v", id, entry.Data)
+		}
+	}
+
+	result, err = reader.ReEncrypt(ctx, "", 0)
+	if err != nil || result.Rewrapped != 0 || !result.Done {
+		t.Fatalf("ReEncrypt after migration = %+v, %v", result, err)
+	}
+}
+
+func TestDecryptingReader_RedactDropsSealedFields(t *testing.T) {
+	keyring := newTestKeyring(t, false, testEncryptionKey('a'))
+	reader := setupEncryptedSQLite(t, keyring, keyring, sealableEntry("e1"))
+
+	redacted, err := reader.RedactLog(context.Background(), "e1", "adminhash")
+	if err != nil {
+		t.Fatalf("RedactLog: %v", err)
+	}
+	if redacted.Data.Sealed != nil || redacted.Data.RequestBody != RedactedMarker || redacted.Data.DecryptionError != "" {
+		t.Fatalf("redacted entry = %+v", redacted.Data)
+	}
+}
+
+type discardLogStore struct{}
+
+func (discardLogStore) WriteBatch(context.Context, []*LogEntry) error { return nil }
+func (discardLogStore) Flush(context.Context) error                   { return nil }
+func (discardLogStore) Close() error                                  { return nil }
+
+// BenchmarkLoggerFlush measures the cost encryption adds to writing a batch.
+func BenchmarkLoggerFlush(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		keyring *Keyring
+	}{
+		{name: "plaintext"},
+		{name: "encrypted", keyring: func() *Keyring {
+			k, err := NewKeyring(config.LogEncryptionConfig{Keys: []config.LogEncryptionKeyConfig{testEncryptionKey('a')}})
+			if err != nil {
+				b.Fatal(err)
+			}
+			return k
+		}()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			l := &Logger{store: discardLogStore{}, config: Config{Encryption: bc.keyring}, pipeline: pipelinestats.NewTracker(0)}
+			batch := make([]*LogEntry, 100)
+			b.ReportAllocs()
+			for b.Loop() {
+				for i := range batch {
+					batch[i] = sealableEntry(fmt.Sprintf("entry-%d", i))
+				}
+				if err := l.writeBatch(batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 
+	"gomodel/internal/compression"
 	"gomodel/internal/core"
 )
 
@@ -24,6 +25,12 @@ func PopulateRequestData(entry *LogEntry, req *http.Request, cfg Config) {
 	if cfg.LogHeaders {
 		data.RequestHeaders = extractHeaders(req.Header)
 	}
+	if data.BodyLogOptOut {
+		return
+	}
+	if cfg.StreamSamples.Enabled() {
+		entry.sampleRequest = newStreamSampleRequest(req)
+	}
 
 	if !cfg.LogBodies {
 		return
@@ -34,10 +41,21 @@ func PopulateRequestData(entry *LogEntry, req *http.Request, cfg Config) {
 		return
 	}
 
+	limit := cfg.BodyCapture.LimitsFor(req.URL.Path).Request
 	switch body := snapshot.CapturedBody(); {
-	case snapshot.BodyNotCaptured:
+	case snapshot.BodyNotCaptured, int64(len(body)) > limit:
 		data.RequestBodyTooBigToHandle = true
 	case body != nil:
+		// Clients may send encoded bodies; decode them so the audit log
+		// stores readable JSON. Reading one byte past the limit detects
+		// bodies that only exceed it once decoded.
+		if decoded, ok := compression.Decompress(body, req.Header.Get("Content-Encoding"), limit+1); ok {
+			if int64(len(decoded)) > limit {
+				data.RequestBodyTooBigToHandle = true
+				return
+			}
+			body = decoded
+		}
 		captureLoggedRequestBody(entry, body)
 	}
 }
@@ -62,11 +80,10 @@ func PopulateResponseData(entry *LogEntry, headers http.Header, body []byte, bod
 	if cfg.LogHeaders {
 		PopulateResponseHeaders(entry, headers)
 	}
-	if !cfg.LogBodies {
+	data := ensureLogData(entry)
+	if !cfg.LogBodies || data.BodyLogOptOut {
 		return
 	}
-
-	data := ensureLogData(entry)
 	if bodyTruncated {
 		data.ResponseBodyTooBigToHandle = true
 	}
@@ -93,10 +110,11 @@ func CaptureInternalJSONExchange(
 		return
 	}
 
-	if req := internalJSONAuditRequest(ctx, method, path, requestIDForEntry(entry), requestBody, cfg.LogBodies); req != nil {
+	limits := cfg.BodyCapture.LimitsFor(path)
+	if req := internalJSONAuditRequest(ctx, method, path, requestIDForEntry(entry), requestBody, cfg.LogBodies, limits.Request); req != nil {
 		PopulateRequestData(entry, req, cfg)
 	}
-	headers, body, truncated := internalJSONAuditResponse(ctx, responseBody, responseErr, requestIDForEntry(entry), cfg.LogBodies)
+	headers, body, truncated := internalJSONAuditResponse(ctx, responseBody, responseErr, requestIDForEntry(entry), cfg.LogBodies, limits.Response)
 	PopulateResponseData(entry, headers, body, truncated, cfg)
 }
 
@@ -114,7 +132,7 @@ func requestIDForEntry(entry *LogEntry) string {
 	return strings.TrimSpace(entry.RequestID)
 }
 
-func internalJSONAuditRequest(ctx context.Context, method, path, requestID string, bodyValue any, logBodies bool) *http.Request {
+func internalJSONAuditRequest(ctx context.Context, method, path, requestID string, bodyValue any, logBodies bool, limit int64) *http.Request {
 	headers := internalJSONAuditHeaders(ctx, requestID)
 	req := &http.Request{
 		Method: method,
@@ -123,7 +141,7 @@ func internalJSONAuditRequest(ctx context.Context, method, path, requestID strin
 	}
 	reqCtx := ctx
 	if logBodies {
-		capturedBody, bodyTooBig := internalJSONAuditRequestBody(bodyValue)
+		capturedBody, bodyTooBig := internalJSONAuditRequestBody(bodyValue, limit)
 		snapshot := core.NewRequestSnapshot(
 			method,
 			path,
@@ -142,7 +160,7 @@ func internalJSONAuditRequest(ctx context.Context, method, path, requestID strin
 	return req.WithContext(reqCtx)
 }
 
-func internalJSONAuditRequestBody(bodyValue any) ([]byte, bool) {
+func internalJSONAuditRequestBody(bodyValue any, limit int64) ([]byte, bool) {
 	if bodyValue == nil {
 		return nil, false
 	}
@@ -152,10 +170,10 @@ func internalJSONAuditRequestBody(bodyValue any) ([]byte, bool) {
 		return nil, false
 	}
 
-	return boundedAuditBody(body, false)
+	return boundedAuditBody(body, limit, false)
 }
 
-func internalJSONAuditResponse(ctx context.Context, bodyValue any, responseErr error, requestID string, logBodies bool) (http.Header, []byte, bool) {
+func internalJSONAuditResponse(ctx context.Context, bodyValue any, responseErr error, requestID string, logBodies bool, limit int64) (http.Header, []byte, bool) {
 	headers := internalJSONAuditHeaders(ctx, requestID)
 
 	if !logBodies {
@@ -182,7 +200,7 @@ func internalJSONAuditResponse(ctx context.Context, bodyValue any, responseErr e
 	if err != nil {
 		return headers, nil, false
 	}
-	capturedBody, truncated := boundedAuditBody(body, true)
+	capturedBody, truncated := boundedAuditBody(body, limit, true)
 	return headers, capturedBody, truncated
 }
 
@@ -206,11 +224,16 @@ func internalJSONAuditHeaders(ctx context.Context, requestID string) http.Header
 	return headers
 }
 
-func boundedAuditBody(body []byte, truncate bool) ([]byte, bool) {
+// boundedAuditBody copies body if it fits within limit. Oversized bodies are
+// cut to limit when truncate is set and dropped otherwise.
+func boundedAuditBody(body []byte, limit int64, truncate bool) ([]byte, bool) {
 	if body == nil {
 		return []byte{}, false
 	}
-	if len(body) <= MaxBodyCapture {
+	if limit <= 0 {
+		limit = MaxBodyCapture
+	}
+	if int64(len(body)) <= limit {
 		cloned := make([]byte, len(body))
 		copy(cloned, body)
 		return cloned, false
@@ -218,7 +241,7 @@ func boundedAuditBody(body []byte, truncate bool) ([]byte, bool) {
 	if !truncate {
 		return nil, true
 	}
-	cloned := make([]byte, MaxBodyCapture)
-	copy(cloned, body[:MaxBodyCapture])
+	cloned := make([]byte, limit)
+	copy(cloned, body[:limit])
 	return cloned, true
 }
@@ -0,0 +1,233 @@
+package auditlog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error summary bucket intervals.
+const (
+	ErrorIntervalHourly = "hourly"
+	ErrorIntervalDaily  = "daily"
+)
+
+const (
+	// maxErrorTopMessages caps the top_messages list returned to callers.
+	maxErrorTopMessages = 50
+	// errorMessageScanLimit caps how many distinct raw messages are pulled from
+	// storage before normalization merges them.
+	errorMessageScanLimit = 1000
+)
+
+// ErrorSummaryParams specifies the query parameters for error analytics.
+type ErrorSummaryParams struct {
+	QueryParams
+	Interval    string // "hourly" or "daily" (default daily)
+	TopMessages int    // number of most frequent normalized messages; 0 disables
+}
+
+// ErrorGroup is the error count for one provider/model/error_type/status combination.
+type ErrorGroup struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	ErrorType  string `json:"error_type"`
+	StatusCode int    `json:"status_code"`
+	Count      int    `json:"count"`
+}
+
+// ErrorSeriesPoint is the error count for one time bucket.
+// Bucket is YYYY-MM-DD for daily and YYYY-MM-DDTHH:00:00Z for hourly intervals.
+type ErrorSeriesPoint struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
+// ErrorMessageCount is the frequency of one normalized error message.
+type ErrorMessageCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// ErrorSummary holds aggregated error analytics over a time period.
+type ErrorSummary struct {
+	TotalErrors int                 `json:"total_errors"`
+	Interval    string              `json:"interval"`
+	Groups      []ErrorGroup        `json:"groups"`
+	Series      []ErrorSeriesPoint  `json:"series"`
+	TopMessages []ErrorMessageCount `json:"top_messages,omitempty"`
+}
+
+// NormalizeErrorInterval returns the canonical interval name, or false when the
+// value is not supported. An empty value selects daily buckets.
+func NormalizeErrorInterval(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", ErrorIntervalDaily:
+		return ErrorIntervalDaily, true
+	case ErrorIntervalHourly:
+		return ErrorIntervalHourly, true
+	default:
+		return "", false
+	}
+}
+
+// errorEntrySQLCondition selects audit rows that represent failed requests.
+const errorEntrySQLCondition = "(status_code >= 400 OR COALESCE(error_type, '') <> '')"
+
+// Provider and model expressions prefer the resolved values and fall back to
+// what was requested when routing never completed.
+const (
+	errorProviderSQLExpr = "COALESCE(NULLIF(provider_name, ''), NULLIF(provider, ''), '')"
+	errorModelSQLExpr    = "COALESCE(NULLIF(resolved_model, ''), NULLIF(requested_model, ''), '')"
+)
+
+func clampTopMessages(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return min(n, maxErrorTopMessages)
+}
+
+var (
+	errorMessageUUIDPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	errorMessageTokenPattern  = regexp.MustCompile(`\b(?:[A-Za-z]+[_-])?[A-Za-z0-9]{8,}\b`)
+	errorMessageNumberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	errorMessageSpacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// normalizeErrorMessage collapses identifiers and numbers so messages that
+// differ only by request IDs, token counts or timings group together.
+func normalizeErrorMessage(message string) string {
+	message = errorMessageUUIDPattern.ReplaceAllString(message, "<id>")
+	message = errorMessageTokenPattern.ReplaceAllStringFunc(message, func(token string) string {
+		// Long tokens mixing letters and digits are identifiers such as
+		// "req_01HX3K9..." or "chatcmpl-9aBc..."; plain words are kept.
+		if strings.ContainsAny(token, "0123456789") && strings.IndexFunc(token, isASCIILetter) >= 0 {
+			return "<id>"
+		}
+		return token
+	})
+	message = errorMessageNumberPattern.ReplaceAllString(message, "<n>")
+	message = errorMessageSpacePattern.ReplaceAllString(message, " ")
+	return strings.TrimSpace(message)
+}
+
+func isASCIILetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// topErrorMessages normalizes raw message counts, merges duplicates and
+// returns the n most frequent, ties broken alphabetically.
+func topErrorMessages(raw []ErrorMessageCount, n int) []ErrorMessageCount {
+	if n <= 0 {
+		return nil
+	}
+
+	merged := make(map[string]int, len(raw))
+	for _, entry := range raw {
+		message := normalizeErrorMessage(entry.Message)
+		if message == "" {
+			continue
+		}
+		merged[message] += entry.Count
+	}
+
+	result := make([]ErrorMessageCount, 0, len(merged))
+	for message, count := range merged {
+		result = append(result, ErrorMessageCount{Message: message, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Message < result[j].Message
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// errorSummaryRows is the subset of database/sql and pgx row iteration used by
+// the SQL error summary scanners.
+type errorSummaryRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+type errorSummarySQL struct {
+	groups   string
+	series   string
+	messages string
+}
+
+// buildErrorSummarySQL renders the grouped, bucketed and top-message queries
+// for SQL backends. All three share the same placeholder arguments.
+func buildErrorSummarySQL(conditions []string, bucketExpr, messageExpr string) errorSummarySQL {
+	where := buildWhereClause(append(append([]string(nil), conditions...), errorEntrySQLCondition))
+	return errorSummarySQL{
+		groups: `SELECT ` + errorProviderSQLExpr + `, ` + errorModelSQLExpr + `, COALESCE(error_type, ''), COALESCE(status_code, 0), COUNT(*)
+		FROM audit_logs` + where + `
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 5 DESC, 1, 2, 3, 4`,
+		series: `SELECT ` + bucketExpr + `, COUNT(*)
+		FROM audit_logs` + where + `
+		GROUP BY 1
+		ORDER BY 1`,
+		messages: `SELECT ` + messageExpr + `, COUNT(*)
+		FROM audit_logs` + where + ` AND COALESCE(` + messageExpr + `, '') <> ''
+		GROUP BY 1
+		ORDER BY 2 DESC
+		LIMIT ` + strconv.Itoa(errorMessageScanLimit),
+	}
+}
+
+func scanErrorGroups(rows errorSummaryRows) ([]ErrorGroup, int, error) {
+	groups := make([]ErrorGroup, 0)
+	total := 0
+	for rows.Next() {
+		var g ErrorGroup
+		if err := rows.Scan(&g.Provider, &g.Model, &g.ErrorType, &g.StatusCode, &g.Count); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan error summary group: %w", err)
+		}
+		total += g.Count
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating error summary groups: %w", err)
+	}
+	return groups, total, nil
+}
+
+func scanErrorSeries(rows errorSummaryRows) ([]ErrorSeriesPoint, error) {
+	series := make([]ErrorSeriesPoint, 0)
+	for rows.Next() {
+		var p ErrorSeriesPoint
+		if err := rows.Scan(&p.Bucket, &p.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan error summary bucket: %w", err)
+		}
+		series = append(series, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error summary buckets: %w", err)
+	}
+	return series, nil
+}
+
+func scanErrorMessages(rows errorSummaryRows) ([]ErrorMessageCount, error) {
+	messages := make([]ErrorMessageCount, 0)
+	for rows.Next() {
+		var m ErrorMessageCount
+		if err := rows.Scan(&m.Message, &m.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan error summary message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error summary messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,178 @@
+package auditlog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeErrorMessage(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			input: "Rate limit reached for requests. Retry after 20s (request req_01HX3K9ZP4Q)",
+			want:  "Rate limit reached for requests. Retry after <n>s (request <id>)",
+		},
+		{
+			input: "context length 131072 exceeded by 2048 tokens",
+			want:  "context length <n> exceeded by <n> tokens",
+		},
+		{
+			input: "upstream  request 3f2b6c1e-9a4d-4e7b-8c2a-1d5e6f7a8b9c\tfailed",
+			want:  "upstream request <id> failed",
+		},
+		{
+			input: "authentication failed",
+			want:  "authentication failed",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeErrorMessage(tt.input); got != tt.want {
+			t.Errorf("normalizeErrorMessage(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTopErrorMessagesMergesNormalizedDuplicates(t *testing.T) {
+	raw := []ErrorMessageCount{
+		{Message: "timeout after 30s", Count: 2},
+		{Message: "timeout after 45s", Count: 3},
+		{Message: "invalid api key", Count: 4},
+		{Message: "model overloaded", Count: 1},
+		{Message: "   ", Count: 7},
+	}
+
+	got := topErrorMessages(raw, 2)
+	want := []ErrorMessageCount{
+		{Message: "timeout after <n>s", Count: 5},
+		{Message: "invalid api key", Count: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("topErrorMessages() = %+v, want %+v", got, want)
+	}
+	if got := topErrorMessages(raw, 0); got != nil {
+		t.Fatalf("topErrorMessages(n=0) = %+v, want nil", got)
+	}
+}
+
+func TestSQLiteReaderGetErrorSummary(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	err = store.WriteBatch(ctx, []*LogEntry{
+		{
+			ID:             "ok",
+			Timestamp:      time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC),
+			RequestedModel: "gpt-5",
+			Provider:       "openai",
+			StatusCode:     200,
+		},
+		{
+			ID:             "rate-limit-1",
+			Timestamp:      time.Date(2026, 3, 10, 9, 30, 0, 123_000_000, time.UTC),
+			RequestedModel: "smart",
+			ResolvedModel:  "gpt-5",
+			Provider:       "openai",
+			ProviderName:   "openai-primary",
+			StatusCode:     429,
+			ErrorType:      "rate_limit_error",
+			Data:           &LogData{ErrorMessage: "retry after 20s"},
+		},
+		{
+			ID:             "rate-limit-2",
+			Timestamp:      time.Date(2026, 3, 10, 10, 5, 0, 0, time.UTC),
+			RequestedModel: "smart",
+			ResolvedModel:  "gpt-5",
+			Provider:       "openai",
+			ProviderName:   "openai-primary",
+			StatusCode:     429,
+			ErrorType:      "rate_limit_error",
+			Data:           &LogData{ErrorMessage: "retry after 7s"},
+		},
+		{
+			ID:             "unresolved",
+			Timestamp:      time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC),
+			RequestedModel: "missing-model",
+			StatusCode:     400,
+			ErrorType:      "invalid_request_error",
+			Data:           &LogData{ErrorMessage: "unsupported model: missing-model"},
+		},
+		{
+			ID:             "outside-range",
+			Timestamp:      time.Date(2026, 3, 13, 8, 0, 0, 0, time.UTC),
+			RequestedModel: "gpt-5",
+			Provider:       "openai",
+			StatusCode:     500,
+			ErrorType:      "provider_error",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed audit logs: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	rangeParams := QueryParams{
+		StartDate: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC),
+	}
+
+	daily, err := reader.GetErrorSummary(ctx, ErrorSummaryParams{QueryParams: rangeParams, TopMessages: 5})
+	if err != nil {
+		t.Fatalf("GetErrorSummary returned error: %v", err)
+	}
+	if daily.TotalErrors != 3 || daily.Interval != ErrorIntervalDaily {
+		t.Fatalf("summary = %+v, want 3 daily errors", daily)
+	}
+	wantGroups := []ErrorGroup{
+		{Provider: "openai-primary", Model: "gpt-5", ErrorType: "rate_limit_error", StatusCode: 429, Count: 2},
+		{Provider: "", Model: "missing-model", ErrorType: "invalid_request_error", StatusCode: 400, Count: 1},
+	}
+	if !reflect.DeepEqual(daily.Groups, wantGroups) {
+		t.Fatalf("groups = %+v, want %+v", daily.Groups, wantGroups)
+	}
+	wantDaily := []ErrorSeriesPoint{{Bucket: "2026-03-10", Count: 2}, {Bucket: "2026-03-11", Count: 1}}
+	if !reflect.DeepEqual(daily.Series, wantDaily) {
+		t.Fatalf("daily series = %+v, want %+v", daily.Series, wantDaily)
+	}
+	wantMessages := []ErrorMessageCount{
+		{Message: "retry after <n>s", Count: 2},
+		{Message: "unsupported model: missing-model", Count: 1},
+	}
+	if !reflect.DeepEqual(daily.TopMessages, wantMessages) {
+		t.Fatalf("top messages = %+v, want %+v", daily.TopMessages, wantMessages)
+	}
+
+	hourly, err := reader.GetErrorSummary(ctx, ErrorSummaryParams{QueryParams: rangeParams, Interval: ErrorIntervalHourly})
+	if err != nil {
+		t.Fatalf("GetErrorSummary(hourly) returned error: %v", err)
+	}
+	wantHourly := []ErrorSeriesPoint{
+		{Bucket: "2026-03-10T09:00:00Z", Count: 1},
+		{Bucket: "2026-03-10T10:00:00Z", Count: 1},
+		{Bucket: "2026-03-11T08:00:00Z", Count: 1},
+	}
+	if !reflect.DeepEqual(hourly.Series, wantHourly) {
+		t.Fatalf("hourly series = %+v, want %+v", hourly.Series, wantHourly)
+	}
+	if hourly.TopMessages != nil {
+		t.Fatalf("top messages = %+v, want none when not requested", hourly.TopMessages)
+	}
+
+	if _, err := reader.GetErrorSummary(ctx, ErrorSummaryParams{Interval: "weekly"}); err == nil {
+		t.Fatal("GetErrorSummary(weekly) should fail")
+	}
+}
@@ -19,6 +19,10 @@ import (
 type Result struct {
 	Logger  LoggerInterface
 	Storage storage.Storage
+	// StreamSamples is nil unless stream sampling is enabled.
+	StreamSamples *StreamSampler
+	// Keyring is nil unless audit log encryption is enabled.
+	Keyring *Keyring
 }
 
 // Close releases all resources held by the audit logger.
@@ -55,6 +59,11 @@ func New(ctx context.Context, cfg *config.Config) (*Result, error) {
 		}, nil
 	}
 
+	keyring, err := NewKeyring(cfg.Logging.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit log encryption config: %w", err)
+	}
+
 	// Create storage configuration
 	storageCfg := cfg.Storage.BackendConfig()
 
@@ -73,13 +82,47 @@ func New(ctx context.Context, cfg *config.Config) (*Result, error) {
 
 	// Create logger configuration
 	logCfg := buildLoggerConfig(cfg.Logging)
+	logCfg.Encryption = keyring
+
+	var sampler *StreamSampler
+	if logCfg.StreamSamples.Enabled() {
+		sampler, err = newStreamSampler(ctx, store, logCfg.StreamSamples)
+		if err != nil {
+			_ = logStore.Close()
+			store.Close()
+			return nil, err
+		}
+	}
 
+	logger := NewLogger(logStore, logCfg)
+	logger.streamSamples = sampler
 	return &Result{
-		Logger:  NewLogger(logStore, logCfg),
-		Storage: store,
+		Logger:        logger,
+		Storage:       store,
+		StreamSamples: sampler,
+		Keyring:       keyring,
 	}, nil
 }
 
+// newStreamSampler creates the stream sample store for the storage backend
+// and a sampler over it.
+func newStreamSampler(ctx context.Context, store storage.Storage, cfg StreamSampleConfig) (*StreamSampler, error) {
+	sampleStore, err := storage.ResolveBackend[StreamSampleStore](
+		store,
+		func(db *sql.DB) (StreamSampleStore, error) { return NewSQLiteStreamSampleStore(db) },
+		func(pool *pgxpool.Pool) (StreamSampleStore, error) { return NewPostgreSQLStreamSampleStore(ctx, pool) },
+		func(db *mongo.Database) (StreamSampleStore, error) { return NewMongoDBStreamSampleStore(db) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream sample store: %w", err)
+	}
+	sampler, err := NewStreamSampler(ctx, sampleStore, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stream sample usage: %w", err)
+	}
+	return sampler, nil
+}
+
 // createLogStore creates the appropriate LogStore for the given storage backend.
 func createLogStore(store storage.Storage, retentionDays int) (LogStore, error) {
 	return storage.ResolveBackend[LogStore](
@@ -96,10 +139,23 @@ func buildLoggerConfig(logCfg config.LogConfig) Config {
 		Enabled:               logCfg.Enabled,
 		LogBodies:             logCfg.LogBodies,
 		LogHeaders:            logCfg.LogHeaders,
+		IgnoreNoBodyLogHeader: logCfg.IgnoreNoBodyLogHeader,
 		BufferSize:            logCfg.BufferSize,
 		FlushInterval:         time.Duration(logCfg.FlushInterval) * time.Second,
 		RetentionDays:         logCfg.RetentionDays,
 		OnlyModelInteractions: logCfg.OnlyModelInteractions,
+		FailureMode:           logCfg.FailureMode,
+		SpillDir:              logCfg.SpillDir,
+		SpillMaxBytes:         logCfg.SpillMaxBytes,
+		DrainTimeout:          time.Duration(logCfg.DrainTimeout) * time.Second,
+		WriteLagWarning:       time.Duration(logCfg.WriteLagWarning) * time.Second,
+		BodyCapture:           BodyCaptureFromConfig(logCfg),
+		StreamSamples: StreamSampleConfig{
+			Rate:      logCfg.StreamSampleRate,
+			OptIn:     logCfg.StreamSampleOptIn,
+			MaxPerDay: logCfg.StreamSampleMaxPerDay,
+			MaxBytes:  logCfg.StreamSampleMaxBytes,
+		},
 	}
 
 	// Apply defaults
@@ -112,3 +168,21 @@ func buildLoggerConfig(logCfg config.LogConfig) Config {
 
 	return cfg
 }
+
+// BodyCaptureFromConfig maps the configured body capture limits onto a
+// BodyCaptureConfig. Unset limits inherit MaxBodyCapture.
+func BodyCaptureFromConfig(logCfg config.LogConfig) BodyCaptureConfig {
+	capture := BodyCaptureConfig{
+		BodyCaptureLimits: BodyCaptureLimits{
+			Request:  logCfg.MaxRequestBodyBytes,
+			Response: logCfg.MaxResponseBodyBytes,
+		},
+	}
+	if len(logCfg.BodyCapturePaths) > 0 {
+		capture.Paths = make(map[string]BodyCaptureLimits, len(logCfg.BodyCapturePaths))
+		for prefix, limits := range logCfg.BodyCapturePaths {
+			capture.Paths[prefix] = BodyCaptureLimits{Request: limits.Request, Response: limits.Response}
+		}
+	}
+	return capture
+}
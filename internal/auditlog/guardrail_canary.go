@@ -0,0 +1,90 @@
+package auditlog
+
+import "fmt"
+
+// Guardrail canary arms.
+const (
+	GuardrailCanaryArmApplied = "applied"
+	GuardrailCanaryArmSkipped = "skipped"
+)
+
+// GuardrailCanaryParams specifies the guardrail and date range of a canary
+// comparison.
+type GuardrailCanaryParams struct {
+	QueryParams
+	Guardrail string
+}
+
+// GuardrailCanaryArm holds the request and error counts of one canary arm.
+type GuardrailCanaryArm struct {
+	Arm       string  `json:"arm"`
+	Requests  int     `json:"requests"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// GuardrailCanaryStats compares the requests a guardrail in canary rollout
+// was applied to against the requests it skipped.
+type GuardrailCanaryStats struct {
+	Guardrail string               `json:"guardrail"`
+	Arms      []GuardrailCanaryArm `json:"arms"`
+	// ErrorRateDelta is the applied error rate minus the skipped error rate.
+	ErrorRateDelta float64 `json:"error_rate_delta"`
+}
+
+// guardrailCanaryCount is the request and error count of the entries with
+// one canary decision, as aggregated by a reader backend.
+type guardrailCanaryCount struct {
+	Applied  bool
+	Requests int
+	Errors   int
+}
+
+// newGuardrailCanaryStats splits counts into the applied and skipped arms and
+// computes their error rates. Both arms are always present, applied first.
+func newGuardrailCanaryStats(guardrail string, counts []guardrailCanaryCount) *GuardrailCanaryStats {
+	applied := GuardrailCanaryArm{Arm: GuardrailCanaryArmApplied}
+	skipped := GuardrailCanaryArm{Arm: GuardrailCanaryArmSkipped}
+	for _, count := range counts {
+		arm := &skipped
+		if count.Applied {
+			arm = &applied
+		}
+		arm.Requests += count.Requests
+		arm.Errors += count.Errors
+	}
+	for _, arm := range []*GuardrailCanaryArm{&applied, &skipped} {
+		if arm.Requests > 0 {
+			arm.ErrorRate = float64(arm.Errors) / float64(arm.Requests)
+		}
+	}
+	return &GuardrailCanaryStats{
+		Guardrail:      guardrail,
+		Arms:           []GuardrailCanaryArm{applied, skipped},
+		ErrorRateDelta: applied.ErrorRate - skipped.ErrorRate,
+	}
+}
+
+// buildGuardrailCanarySQL aggregates audit rows by canary decision. from
+// joins audit_logs with one row per recorded decision and appliedExpr reads
+// the decision from that row.
+func buildGuardrailCanarySQL(from, appliedExpr string, conditions []string) string {
+	return `SELECT ` + appliedExpr + `, COUNT(*), SUM(CASE WHEN ` + errorEntrySQLCondition + ` THEN 1 ELSE 0 END)
+		FROM ` + from + buildWhereClause(conditions) + `
+		GROUP BY 1`
+}
+
+func scanGuardrailCanaryCounts(rows errorSummaryRows) ([]guardrailCanaryCount, error) {
+	var counts []guardrailCanaryCount
+	for rows.Next() {
+		var count guardrailCanaryCount
+		if err := rows.Scan(&count.Applied, &count.Requests, &count.Errors); err != nil {
+			return nil, fmt.Errorf("failed to scan guardrail canary counts: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating guardrail canary counts: %w", err)
+	}
+	return counts, nil
+}
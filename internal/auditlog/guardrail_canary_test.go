@@ -0,0 +1,83 @@
+package auditlog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewGuardrailCanaryStats(t *testing.T) {
+	appliedRate, skippedRate := 0.3, 0.1
+	got := newGuardrailCanaryStats("pii", []guardrailCanaryCount{
+		{Applied: false, Requests: 90, Errors: 9},
+		{Applied: true, Requests: 10, Errors: 3},
+	})
+	want := &GuardrailCanaryStats{
+		Guardrail: "pii",
+		Arms: []GuardrailCanaryArm{
+			{Arm: GuardrailCanaryArmApplied, Requests: 10, Errors: 3, ErrorRate: appliedRate},
+			{Arm: GuardrailCanaryArmSkipped, Requests: 90, Errors: 9, ErrorRate: skippedRate},
+		},
+		ErrorRateDelta: appliedRate - skippedRate,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("newGuardrailCanaryStats() = %+v, want %+v", got, want)
+	}
+
+	empty := newGuardrailCanaryStats("pii", nil)
+	if len(empty.Arms) != 2 || empty.Arms[0].Requests != 0 || empty.Arms[1].ErrorRate != 0 || empty.ErrorRateDelta != 0 {
+		t.Fatalf("newGuardrailCanaryStats(nil) = %+v, want two empty arms", empty)
+	}
+}
+
+func TestSQLiteReaderGetGuardrailCanaryStats(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	day := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	canary := func(applied bool) *LogData {
+		return &LogData{GuardrailCanaries: []GuardrailCanarySnapshot{
+			{Name: "other", Applied: !applied},
+			{Name: "pii", Applied: applied},
+		}}
+	}
+	ctx := context.Background()
+	err = store.WriteBatch(ctx, []*LogEntry{
+		{ID: "applied-ok", Timestamp: day, StatusCode: 200, Data: canary(true)},
+		{ID: "applied-error", Timestamp: day, StatusCode: 502, ErrorType: "provider_error", Data: canary(true)},
+		{ID: "skipped-ok-1", Timestamp: day, StatusCode: 200, Data: canary(false)},
+		{ID: "skipped-ok-2", Timestamp: day, StatusCode: 200, Data: canary(false)},
+		{ID: "skipped-ok-3", Timestamp: day, StatusCode: 200, Data: canary(false)},
+		{ID: "no-canary", Timestamp: day, StatusCode: 500, ErrorType: "provider_error"},
+		{ID: "outside-range", Timestamp: day.AddDate(0, 0, 5), StatusCode: 500, ErrorType: "provider_error", Data: canary(true)},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed audit logs: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	stats, err := reader.GetGuardrailCanaryStats(ctx, GuardrailCanaryParams{
+		QueryParams: QueryParams{StartDate: day, EndDate: day},
+		Guardrail:   "pii",
+	})
+	if err != nil {
+		t.Fatalf("GetGuardrailCanaryStats returned error: %v", err)
+	}
+	want := []GuardrailCanaryArm{
+		{Arm: GuardrailCanaryArmApplied, Requests: 2, Errors: 1, ErrorRate: 0.5},
+		{Arm: GuardrailCanaryArmSkipped, Requests: 3, Errors: 0, ErrorRate: 0},
+	}
+	if !reflect.DeepEqual(stats.Arms, want) {
+		t.Fatalf("arms = %+v, want %+v", stats.Arms, want)
+	}
+}
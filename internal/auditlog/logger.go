@@ -2,24 +2,55 @@ package auditlog
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"gomodel/internal/logging"
+	"gomodel/internal/pipelinestats"
 )
 
+// auditLogger logs audit buffering, flushing and storage events.
+var auditLogger = logging.Component(logging.ComponentAuditLog)
+
 // Logger provides async buffered logging with batch writes.
 // It collects log entries in a channel and flushes them to storage
 // either when the buffer is full or at regular intervals.
+// Config.FailureMode controls what happens to batches the store rejects.
 type Logger struct {
 	store         LogStore
 	config        Config
-	buffer        chan *LogEntry
+	buffer        chan queuedEntry
 	done          chan struct{}
+	stopping      chan struct{} // closed when Close starts; releases writers blocked by backpressure
+	flushReqs     chan flushRequest
 	wg            sync.WaitGroup
 	writes        sync.WaitGroup // tracks in-flight Write calls
 	flushInterval time.Duration
 	closed        atomic.Bool
+	pipeline      *pipelinestats.Tracker
+
+	// streamSamples stores full-text samples of streamed responses; nil
+	// unless stream sampling is enabled.
+	streamSamples *StreamSampler
+
+	// Spill state is owned by the flush loop goroutine.
+	spill        *spillQueue // nil unless FailureModeSpill
+	retryBackoff time.Duration
+	nextRetry    time.Time
+}
+
+// queuedEntry is a buffered entry and the time Write queued it (unix nanos).
+type queuedEntry struct {
+	entry      *LogEntry
+	enqueuedAt int64
+}
+
+type flushRequest struct {
+	ctx    context.Context
+	result chan error
 }
 
 // NewLogger creates a new async buffered Logger.
@@ -31,17 +62,44 @@ func NewLogger(store LogStore, cfg Config) *Logger {
 	if cfg.FlushInterval <= 0 {
 		cfg.FlushInterval = 5 * time.Second
 	}
+	applyFailureDefaults(&cfg)
 
 	l := &Logger{
 		store:         store,
 		config:        cfg,
-		buffer:        make(chan *LogEntry, cfg.BufferSize),
+		buffer:        make(chan queuedEntry, cfg.BufferSize),
 		done:          make(chan struct{}),
+		stopping:      make(chan struct{}),
+		flushReqs:     make(chan flushRequest),
 		flushInterval: cfg.FlushInterval,
+		pipeline:      pipelinestats.NewTracker(cfg.WriteLagWarning),
 	}
 
-	l.wg.Add(1)
+	if cfg.FailureMode == FailureModeSpill {
+		spill, err := openSpillQueue(cfg.SpillDir, cfg.SpillMaxBytes)
+		if err != nil {
+			auditLogger.Error("failed to open audit log spill queue, falling back to drop mode",
+				"error", err,
+				"dir", cfg.SpillDir,
+			)
+			l.config.FailureMode = FailureModeDrop
+		} else {
+			l.spill = spill
+			if pending := spill.Len(); pending > 0 {
+				auditLogger.Info("found spilled audit log entries from a previous run",
+					"pending", pending,
+					"dir", cfg.SpillDir,
+				)
+			}
+		}
+	}
+
+	l.wg.Add(2)
 	go l.flushLoop()
+	go func() {
+		defer l.wg.Done()
+		pipelinestats.MonitorLag(l.stopping, cfg.WriteLagWarning, l.PipelineStats, auditLogger, "audit log")
+	}()
 
 	return l
 }
@@ -68,16 +126,27 @@ func (l *Logger) Write(entry *LogEntry) {
 		return
 	}
 
+	queued := queuedEntry{entry: entry, enqueuedAt: time.Now().UnixNano()}
+	if l.config.FailureMode == FailureModeBlock {
+		// Backpressure: wait for room instead of dropping while the store recovers.
+		select {
+		case l.buffer <- queued:
+		case <-l.stopping:
+		}
+		return
+	}
+
 	select {
-	case l.buffer <- entry:
+	case l.buffer <- queued:
 		// Entry queued successfully
 	default:
 		// Buffer full - drop entry and log warning
+		l.pipeline.Dropped(1)
 		requestID := entry.RequestID
 		if requestID == "" {
 			requestID = "unknown"
 		}
-		slog.Warn("audit log buffer full, dropping entry",
+		auditLogger.Warn("audit log buffer full, dropping entry",
 			"request_id", requestID,
 			"requested_model", entry.RequestedModel,
 		)
@@ -89,6 +158,43 @@ func (l *Logger) Config() Config {
 	return l.config
 }
 
+// PipelineStats returns a snapshot of the write pipeline: buffer fill level,
+// spill backlog, write lag, flush outcomes and drops.
+func (l *Logger) PipelineStats() pipelinestats.Snapshot {
+	s := l.pipeline.Snapshot(time.Now(), len(l.buffer), cap(l.buffer))
+	if l.spill != nil {
+		s.Spilled = l.spill.Len()
+	}
+	return s
+}
+
+// Flush writes buffered entries and replays the spill queue, giving up when
+// ctx ends or Config.DrainTimeout elapses. Entries that could not be replayed
+// stay on disk for the next attempt.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.closed.Load() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.config.DrainTimeout)
+	defer cancel()
+
+	req := flushRequest{ctx: ctx, result: make(chan error, 1)}
+	select {
+	case l.flushReqs <- req:
+	case <-l.stopping:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Close stops the logger and flushes remaining entries.
 // This should be called during graceful shutdown.
 // Close is idempotent - calling it multiple times is safe.
@@ -98,8 +204,12 @@ func (l *Logger) Close() error {
 		return nil
 	}
 
-	// Wait for any in-flight Write calls to complete
+	// Release writers waiting on a full buffer in block mode
+	close(l.stopping)
+
+	// Wait for any in-flight Write calls and stream sample writes to complete
 	l.writes.Wait()
+	l.streamSamples.Close()
 
 	// Signal the flush loop to stop
 	close(l.done)
@@ -111,6 +221,12 @@ func (l *Logger) Close() error {
 	return l.store.Close()
 }
 
+// StreamSampler returns the stream sampler, or nil when stream sampling is
+// disabled.
+func (l *Logger) StreamSampler() *StreamSampler {
+	return l.streamSamples
+}
+
 // flushLoop runs in the background and periodically flushes the buffer.
 func (l *Logger) flushLoop() {
 	defer l.wg.Done()
@@ -122,8 +238,8 @@ func (l *Logger) flushLoop() {
 
 	for {
 		select {
-		case entry := <-l.buffer:
-			batch = append(batch, entry)
+		case queued := <-l.buffer:
+			batch = l.appendQueued(batch, queued)
 			// Flush when batch reaches threshold
 			if len(batch) >= BatchFlushThreshold {
 				l.flushBatch(batch)
@@ -136,29 +252,36 @@ func (l *Logger) flushLoop() {
 				l.flushBatch(batch)
 				batch = make([]*LogEntry, 0, BatchFlushThreshold)
 			}
+			l.replaySpilled(context.Background(), false)
+
+		case req := <-l.flushReqs:
+			batch = l.drainBuffer(batch)
+			if len(batch) > 0 {
+				l.flushBatch(batch)
+				batch = make([]*LogEntry, 0, BatchFlushThreshold)
+			}
+			req.result <- l.drainSpill(req.ctx)
 
 		case <-l.done:
 			// Shutdown: drain remaining entries from buffer using non-blocking loop.
 			// Note: l.closed is already set by Close() before sending on l.done.
 			// We do NOT close(l.buffer) — closing is unnecessary since flushLoop
 			// exits via l.done, and closing creates a race with concurrent Write() calls.
-			for {
-				select {
-				case entry := <-l.buffer:
-					batch = append(batch, entry)
-				default:
-					goto drainComplete
-				}
-			}
-		drainComplete:
+			batch = l.drainBuffer(batch)
 			// Final flush
 			if len(batch) > 0 {
 				l.flushBatch(batch)
 			}
+			// Best-effort replay; whatever remains stays on disk for the next start
+			drainCtx, drainCancel := context.WithTimeout(context.Background(), l.config.DrainTimeout)
+			if err := l.drainSpill(drainCtx); err != nil {
+				auditLogger.Warn("audit log spill queue not drained before shutdown", "error", err)
+			}
+			drainCancel()
 			// Flush the store
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			if err := l.store.Flush(ctx); err != nil {
-				slog.Error("failed to flush audit log store", "error", err)
+				auditLogger.Error("failed to flush audit log store", "error", err)
 			}
 			cancel()
 			return
@@ -166,20 +289,285 @@ func (l *Logger) flushLoop() {
 	}
 }
 
-// flushBatch writes a batch of entries to the store.
+// drainBuffer moves every entry currently queued in the buffer into batch
+// without blocking.
+func (l *Logger) drainBuffer(batch []*LogEntry) []*LogEntry {
+	for {
+		select {
+		case queued := <-l.buffer:
+			batch = l.appendQueued(batch, queued)
+		default:
+			return batch
+		}
+	}
+}
+
+// appendQueued adds a queued entry to batch, tracking when the batch's oldest
+// entry was queued.
+func (l *Logger) appendQueued(batch []*LogEntry, queued queuedEntry) []*LogEntry {
+	if len(batch) == 0 {
+		l.pipeline.Hold(queued.enqueuedAt)
+	}
+	return append(batch, queued.entry)
+}
+
+// flushBatch writes a batch of entries to the store, handling a failed write
+// according to the configured failure mode.
 func (l *Logger) flushBatch(batch []*LogEntry) {
 	if len(batch) == 0 {
 		return
 	}
+	defer l.pipeline.Release()
 
+	switch l.config.FailureMode {
+	case FailureModeSpill:
+		l.flushBatchWithSpill(batch)
+	case FailureModeBlock:
+		l.flushBatchWithRetry(batch)
+	default:
+		if err := l.writeBatch(batch); err != nil {
+			auditLogger.Error("failed to write audit log batch",
+				"error", err,
+				"count", len(batch),
+			)
+			auditLogDroppedEntries.Add(float64(len(batch)))
+			l.pipeline.Dropped(len(batch))
+		}
+	}
+}
+
+func (l *Logger) writeBatch(batch []*LogEntry) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
+	l.sealBatch(batch)
+	start := time.Now()
+	err := l.store.WriteBatch(ctx, batch)
+	l.pipeline.Flushed(start, len(batch), err)
+	return err
+}
+
+// flushBatchWithSpill writes batch behind any previously spilled batches so
+// the store receives entries in order, spilling it when that is not possible.
+func (l *Logger) flushBatchWithSpill(batch []*LogEntry) {
+	if l.replaySpilled(context.Background(), false) {
+		err := l.writeBatch(batch)
+		if err == nil {
+			return
+		}
+		auditLogger.Warn("failed to write audit log batch, spilling to disk",
+			"error", err,
+			"count", len(batch),
+		)
+		l.scheduleRetry()
+	}
+	l.spillBatch(batch)
+}
+
+// sealBatch encrypts the captured fields of batch when encryption is
+// enabled. Entries that fail to encrypt lose their captured fields rather
+// than being written in plaintext. Already sealed entries are skipped, so
+// batches replayed from the spill queue are not encrypted twice.
+func (l *Logger) sealBatch(batch []*LogEntry) {
+	if l.config.Encryption == nil {
+		return
+	}
+	for _, entry := range batch {
+		if err := l.config.Encryption.Seal(entry); err != nil {
+			auditLogger.Error("failed to encrypt audit log entry, dropping its bodies and headers",
+				"error", err,
+				"id", entry.ID,
+			)
+			data := *entry.Data
+			data.RequestBody, data.ResponseBody = nil, nil
+			data.RequestHeaders, data.ResponseHeaders = nil, nil
+			entry.Data = &data
+		}
+	}
+}
+
+func (l *Logger) spillBatch(batch []*LogEntry) {
+	l.sealBatch(batch)
+	evicted, err := l.spill.Push(batch)
+	if evicted > 0 {
+		auditLogDroppedEntries.Add(float64(evicted))
+		l.pipeline.Dropped(evicted)
+		auditLogger.Error("audit log spill queue full, dropped oldest entries",
+			"dropped", evicted,
+			"dropped_total", l.spill.Dropped(),
+		)
+	}
+	if err != nil {
+		auditLogDroppedEntries.Add(float64(len(batch)))
+		l.pipeline.Dropped(len(batch))
+		auditLogger.Error("failed to spill audit log batch, dropping entries",
+			"error", err,
+			"count", len(batch),
+		)
+		return
+	}
+	auditLogSpilledEntries.Add(float64(len(batch)))
+	auditLogger.Warn("spilled audit log batch to disk",
+		"count", len(batch),
+		"pending", l.spill.Len(),
+	)
+}
+
+// replaySpilled writes spilled batches to the store oldest first. Unless force
+// is set it waits for the retry backoff to elapse. It reports whether the
+// spill queue is empty afterwards.
+func (l *Logger) replaySpilled(ctx context.Context, force bool) bool {
+	if l.spill == nil {
+		return true
+	}
+	if !force && time.Now().Before(l.nextRetry) {
+		return l.spill.Len() == 0
+	}
+
+	replayed := 0
+	defer func() {
+		if replayed > 0 {
+			auditLogReplayedEntries.Add(float64(replayed))
+			auditLogger.Info("replayed spilled audit log entries",
+				"count", replayed,
+				"pending", l.spill.Len(),
+			)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		segment, batch, ok, corrupt := l.spill.Peek()
+		if corrupt != nil {
+			auditLogDroppedEntries.Add(float64(segment.count))
+			l.pipeline.Dropped(segment.count)
+			auditLogger.Error("dropped unreadable audit log spill segment", "error", corrupt)
+			continue
+		}
+		if !ok {
+			l.retryBackoff = 0
+			l.nextRetry = time.Time{}
+			return true
+		}
+		if err := l.writeBatch(batch); err != nil {
+			l.scheduleRetry()
+			auditLogger.Warn("failed to replay spilled audit log batch",
+				"error", err,
+				"pending", l.spill.Len(),
+				"retry_in", l.retryBackoff,
+			)
+			return false
+		}
+		if err := l.spill.Remove(segment); err != nil {
+			// The batch is already stored; retrying later would duplicate it
+			// only if removal keeps failing.
+			l.scheduleRetry()
+			auditLogger.Error("failed to remove replayed audit log spill segment", "error", err)
+			return false
+		}
+		replayed += len(batch)
+	}
+	return false
+}
+
+// drainSpill replays the spill queue until it is empty or ctx ends.
+func (l *Logger) drainSpill(ctx context.Context) error {
+	if l.spill == nil {
+		return nil
+	}
+	for {
+		if l.replaySpilled(ctx, true) {
+			return nil
+		}
+		timer := time.NewTimer(time.Until(l.nextRetry))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%d spilled audit log entries still pending: %w", l.spill.Len(), ctx.Err())
+		}
+	}
+}
+
+func (l *Logger) scheduleRetry() {
+	if l.retryBackoff <= 0 {
+		l.retryBackoff = l.config.RetryInitialBackoff
+	} else {
+		l.retryBackoff = min(l.retryBackoff*2, l.config.RetryMaxBackoff)
+	}
+	l.nextRetry = time.Now().Add(l.retryBackoff)
+}
 
-	if err := l.store.WriteBatch(ctx, batch); err != nil {
-		slog.Error("failed to write audit log batch",
+// flushBatchWithRetry retries batch with exponential backoff until the store
+// accepts it. While it waits the buffer fills up and Write blocks. Once Close
+// starts, retries continue for at most DrainTimeout before the batch is dropped.
+func (l *Logger) flushBatchWithRetry(batch []*LogEntry) {
+	backoff := l.config.RetryInitialBackoff
+	stopping := l.stopping
+	var deadline time.Time
+	for attempt := 0; ; attempt++ {
+		err := l.writeBatch(batch)
+		if err == nil {
+			if attempt > 0 {
+				auditLogger.Info("audit log store recovered", "attempts", attempt+1, "count", len(batch))
+			}
+			return
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			auditLogDroppedEntries.Add(float64(len(batch)))
+			l.pipeline.Dropped(len(batch))
+			auditLogger.Error("failed to write audit log batch before shutdown, dropping entries",
+				"error", err,
+				"count", len(batch),
+			)
+			return
+		}
+		auditLogger.Warn("failed to write audit log batch, retrying",
 			"error", err,
 			"count", len(batch),
+			"retry_in", backoff,
 		)
+
+		wait := backoff
+		if !deadline.IsZero() {
+			wait = min(wait, time.Until(deadline))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-stopping:
+			timer.Stop()
+			stopping = nil
+			deadline = time.Now().Add(l.config.DrainTimeout)
+		}
+		backoff = min(backoff*2, l.config.RetryMaxBackoff)
+	}
+}
+
+// applyFailureDefaults canonicalizes the failure mode and fills unset
+// failure-handling settings.
+func applyFailureDefaults(cfg *Config) {
+	switch mode := strings.ToLower(strings.TrimSpace(cfg.FailureMode)); mode {
+	case FailureModeSpill, FailureModeBlock:
+		cfg.FailureMode = mode
+	case "", FailureModeDrop:
+		cfg.FailureMode = FailureModeDrop
+	default:
+		auditLogger.Warn("unknown audit log failure mode, using drop", "failure_mode", cfg.FailureMode)
+		cfg.FailureMode = FailureModeDrop
+	}
+	if cfg.FailureMode == FailureModeSpill && strings.TrimSpace(cfg.SpillDir) == "" {
+		cfg.SpillDir = DefaultSpillDir
+	}
+	if cfg.SpillMaxBytes < 0 {
+		cfg.SpillMaxBytes = 0
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = DefaultDrainTimeout
+	}
+	if cfg.RetryInitialBackoff <= 0 {
+		cfg.RetryInitialBackoff = DefaultRetryInitialBackoff
+	}
+	if cfg.RetryMaxBackoff < cfg.RetryInitialBackoff {
+		cfg.RetryMaxBackoff = max(DefaultRetryMaxBackoff, cfg.RetryInitialBackoff)
 	}
 }
 
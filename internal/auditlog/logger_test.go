@@ -0,0 +1,313 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyStore rejects writes while failing is set and records accepted entries
+// in the order they were written.
+type flakyStore struct {
+	mu       sync.Mutex
+	entries  []*LogEntry
+	failing  atomic.Bool
+	attempts atomic.Int64
+}
+
+func (s *flakyStore) WriteBatch(_ context.Context, entries []*LogEntry) error {
+	s.attempts.Add(1)
+	if s.failing.Load() {
+		return errors.New("connection refused")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entries...)
+	return nil
+}
+
+func (s *flakyStore) Flush(context.Context) error { return nil }
+func (s *flakyStore) Close() error                { return nil }
+
+func (s *flakyStore) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, len(s.entries))
+	for i, entry := range s.entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+func failureTestConfig(mode, spillDir string) Config {
+	return Config{
+		Enabled:             true,
+		BufferSize:          1000,
+		FlushInterval:       10 * time.Millisecond,
+		FailureMode:         mode,
+		SpillDir:            spillDir,
+		DrainTimeout:        2 * time.Second,
+		RetryInitialBackoff: 5 * time.Millisecond,
+		RetryMaxBackoff:     20 * time.Millisecond,
+	}
+}
+
+func writeEntries(l *Logger, from, to int) {
+	for i := from; i < to; i++ {
+		l.Write(&LogEntry{ID: fmt.Sprintf("entry-%04d", i), Timestamp: time.Now()})
+	}
+}
+
+func assertOrderedIDs(t *testing.T, got []string, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("stored %d entries, want %d", len(got), n)
+	}
+	for i, id := range got {
+		if want := fmt.Sprintf("entry-%04d", i); id != want {
+			t.Fatalf("entry %d = %s, want %s", i, id, want)
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLogger_SpillModeReplaysAfterRecovery(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	logger := NewLogger(store, failureTestConfig(FailureModeSpill, t.TempDir()))
+	defer logger.Close()
+
+	writeEntries(logger, 0, 150)
+	waitFor(t, func() bool { return logger.spill.Len() == 150 })
+
+	// Entries written while earlier batches are still spilled must queue behind them.
+	writeEntries(logger, 150, 250)
+	waitFor(t, func() bool { return logger.spill.Len() == 250 })
+
+	store.failing.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	assertOrderedIDs(t, store.ids(), 250)
+	if pending := logger.spill.Len(); pending != 0 {
+		t.Fatalf("spill queue has %d pending entries after flush", pending)
+	}
+}
+
+func TestLogger_SpillModeDrainsOnClose(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	logger := NewLogger(store, failureTestConfig(FailureModeSpill, t.TempDir()))
+
+	writeEntries(logger, 0, 120)
+	waitFor(t, func() bool { return logger.spill.Len() == 120 })
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		store.failing.Store(false)
+	}()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	assertOrderedIDs(t, store.ids(), 120)
+}
+
+func TestLogger_SpillModeResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	failing := &flakyStore{}
+	failing.failing.Store(true)
+	cfg := failureTestConfig(FailureModeSpill, dir)
+	cfg.DrainTimeout = 20 * time.Millisecond
+	first := NewLogger(failing, cfg)
+	writeEntries(first, 0, 40)
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := len(failing.ids()); got != 0 {
+		t.Fatalf("failing store accepted %d entries", got)
+	}
+
+	recovered := &flakyStore{}
+	second := NewLogger(recovered, failureTestConfig(FailureModeSpill, dir))
+	defer second.Close()
+	writeEntries(second, 40, 50)
+	if err := second.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	assertOrderedIDs(t, recovered.ids(), 50)
+}
+
+func TestLogger_BlockModeAppliesBackpressure(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	cfg := failureTestConfig(FailureModeBlock, "")
+	cfg.BufferSize = 1
+	logger := NewLogger(store, cfg)
+	defer logger.Close()
+
+	// Once the flush loop is stuck retrying the first batch, the one-slot
+	// buffer fills and further writers must wait.
+	writeEntries(logger, 0, 5)
+	waitFor(t, func() bool { return store.attempts.Load() >= 2 })
+
+	written := make(chan struct{})
+	go func() {
+		writeEntries(logger, 5, 10)
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("writes completed while the store was failing; expected backpressure")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	store.failing.Store(false)
+	<-written
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	assertOrderedIDs(t, store.ids(), 10)
+}
+
+func TestLogger_BlockModeCloseReleasesBlockedWriters(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	cfg := failureTestConfig(FailureModeBlock, "")
+	cfg.BufferSize = 1
+	cfg.DrainTimeout = 20 * time.Millisecond
+	logger := NewLogger(store, cfg)
+
+	writeEntries(logger, 0, 5)
+	waitFor(t, func() bool { return store.attempts.Load() >= 2 })
+	written := make(chan struct{})
+	go func() {
+		writeEntries(logger, 5, 10)
+		close(written)
+	}()
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case <-written:
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked writers were not released by Close")
+	}
+}
+
+func TestLogger_DropModeDiscardsFailedBatches(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	logger := NewLogger(store, failureTestConfig(FailureModeDrop, ""))
+	defer logger.Close()
+
+	writeEntries(logger, 0, 5)
+	waitFor(t, func() bool { return store.attempts.Load() >= 1 })
+
+	store.failing.Store(false)
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := len(store.ids()); got != 0 {
+		t.Fatalf("stored %d entries, want failed batch dropped", got)
+	}
+}
+
+func TestLogger_SpillModeFallsBackToDropWhenDirUnavailable(t *testing.T) {
+	// A regular file where the spill directory's parent should be.
+	parent := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(parent, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(&flakyStore{}, failureTestConfig(FailureModeSpill, filepath.Join(parent, "spill")))
+	defer logger.Close()
+
+	if logger.Config().FailureMode != FailureModeDrop {
+		t.Fatalf("FailureMode = %q, want drop fallback", logger.Config().FailureMode)
+	}
+}
+
+func TestLogger_PipelineStatsReportFailuresAndSpill(t *testing.T) {
+	store := &flakyStore{}
+	store.failing.Store(true)
+	logger := NewLogger(store, failureTestConfig(FailureModeSpill, t.TempDir()))
+	defer logger.Close()
+
+	writeEntries(logger, 0, 20)
+	waitFor(t, func() bool { return logger.PipelineStats().Spilled == 20 })
+	stats := logger.PipelineStats()
+	if !stats.LastFlushErrorActive || stats.LastFlushError != "connection refused" || stats.Written != 0 || stats.Dropped != 0 {
+		t.Fatalf("stats = %+v, want an active flush error and the entries spilled", stats)
+	}
+
+	store.failing.Store(false)
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	stats = logger.PipelineStats()
+	if stats.LastFlushErrorActive || stats.Written != 20 || stats.Spilled != 0 || stats.OldestPendingSeconds != 0 {
+		t.Fatalf("stats = %+v, want the spilled entries written", stats)
+	}
+}
+
+func TestLogger_PipelineStatsUnderConcurrentWrites(t *testing.T) {
+	const (
+		writers          = 20
+		entriesPerWriter = 500
+	)
+
+	store := &flakyStore{}
+	cfg := failureTestConfig(FailureModeDrop, "")
+	cfg.BufferSize = 200
+	logger := NewLogger(store, cfg)
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range entriesPerWriter {
+				logger.Write(&LogEntry{ID: fmt.Sprintf("w%d-%d", w, i)})
+				if i%50 == 0 {
+					_ = logger.PipelineStats()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	stats := logger.PipelineStats()
+	if stats.Written+stats.Dropped != writers*entriesPerWriter {
+		t.Fatalf("written %d + dropped %d != %d", stats.Written, stats.Dropped, writers*entriesPerWriter)
+	}
+	if int64(len(store.ids())) != stats.Written {
+		t.Fatalf("stored entries = %d, want %d", len(store.ids()), stats.Written)
+	}
+}
@@ -3,28 +3,29 @@ package auditlog
 import (
 	"bufio"
 	"bytes"
-	"compress/flate"
-	"compress/gzip"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"io"
+	"maps"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	"github.com/andybalholm/brotli"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
 
+	"gomodel/internal/compression"
 	"gomodel/internal/core"
 )
 
 // Note: contextKey type and constants (LogEntryKey, LogEntryStreamingKey,
-// MaxBodyCapture, APIKeyHashPrefixLength) are defined in constants.go
+// MaxBodyCapture, APIKeyHashPrefixLength) are defined in constants.go.
+// Per-path body capture limits come from Config.BodyCapture.
 
 // Middleware creates an Echo middleware for audit logging.
 // It captures request metadata at the start and response metadata at the end,
@@ -73,23 +74,40 @@ func Middleware(logger LoggerInterface) echo.MiddlewareFunc {
 				UserPath:  userPath,
 				Data: &LogData{
 					UserAgent: req.UserAgent(),
+					Labels:    copyMap(core.GetRequestLabels(req.Context())),
+					Options:   effectiveRequestOptions(req.Context()),
 				},
 			}
 
+			// A client opting out of body capture still gets a full metadata
+			// entry; only the bodies and the stream sample are skipped.
+			if bodyLogOptOut(req, cfg) {
+				cfg.LogBodies = false
+				entry.Data.BodyLogOptOut = true
+			}
+
 			// Hash API key if present (for identification without exposing the key)
 			if authHeader := req.Header.Get("Authorization"); authHeader != "" {
-				entry.Data.APIKeyHash = hashAPIKey(authHeader)
+				entry.Data.APIKeyHash = HashAPIKey(authHeader)
 			}
 
 			// Store entry in context for potential enrichment by handlers
 			c.Set(string(LogEntryKey), entry)
 
+			// Provider clients report the upstream HTTP outcome here.
+			upstream := &core.UpstreamCall{}
+			// Guardrails in canary rollout report whether they applied here.
+			canaries := &core.GuardrailCanaries{}
+			ctx := core.WithGuardrailCanaries(core.WithUpstreamCall(req.Context(), upstream), canaries)
+			c.SetRequest(req.WithContext(ctx))
+
 			// Create response body capture if logging bodies
 			var responseCapture *responseBodyCapture
 			if cfg.LogBodies {
 				responseCapture = &responseBodyCapture{
 					ResponseWriter: c.Response(),
 					body:           &bytes.Buffer{},
+					limit:          cfg.BodyCapture.LimitsFor(req.URL.Path).Response,
 					shouldCapture: func() bool {
 						return auditEnabledForContext(c.Request().Context()) && shouldCaptureResponseBody(c)
 					},
@@ -109,6 +127,9 @@ func Middleware(logger LoggerInterface) echo.MiddlewareFunc {
 
 			// Calculate duration
 			entry.DurationNs = time.Since(start).Nanoseconds()
+			applyUpstreamCall(entry, upstream)
+			applyGuardrailCanaries(entry, canaries)
+			applySecretRedaction(entry, core.GetSecretRedaction(c.Request().Context()))
 
 			// ResolveResponseStatus applies Echo v5 precedence rules for committed responses,
 			// suggested status codes, and errors implementing HTTPStatusCoder.
@@ -123,6 +144,10 @@ func Middleware(logger LoggerInterface) echo.MiddlewareFunc {
 				PopulateResponseHeaders(entry, c.Response().Header())
 			}
 
+			if original, ok := c.Get(string(OriginalResponseBodyKey)).([]byte); ok && responseCapture != nil {
+				responseCapture.replace(original)
+			}
+
 			// Capture response body if enabled
 			if cfg.LogBodies && responseCapture != nil && shouldCaptureResponseBody(c) && responseCapture.body.Len() > 0 {
 				// Set truncation flag if response body exceeded limit
@@ -169,6 +194,42 @@ func applyWorkflow(entry *LogEntry, ctx context.Context) {
 	}
 }
 
+func applyUpstreamCall(entry *LogEntry, call *core.UpstreamCall) {
+	if delay := call.PacingDelay(); delay > 0 {
+		ensureLogData(entry).PacingDelayNs = delay.Nanoseconds()
+	}
+	statusCode, duration := call.Result()
+	if statusCode == 0 {
+		return
+	}
+	entry.UpstreamStatusCode = statusCode
+	entry.UpstreamDurationNs = duration.Nanoseconds()
+}
+
+func applyGuardrailCanaries(entry *LogEntry, canaries *core.GuardrailCanaries) {
+	decisions := canaries.Decisions()
+	if entry == nil || len(decisions) == 0 {
+		return
+	}
+	snapshots := make([]GuardrailCanarySnapshot, 0, len(decisions))
+	for _, decision := range decisions {
+		snapshots = append(snapshots, GuardrailCanarySnapshot{Name: decision.Guardrail, Applied: decision.Applied})
+	}
+	ensureLogData(entry).GuardrailCanaries = snapshots
+}
+
+// applySecretRedaction records the detectors secret redaction matched, which
+// ran before the entry existed.
+func applySecretRedaction(entry *LogEntry, result *core.SecretRedactionResult) {
+	if entry == nil || result == nil {
+		return
+	}
+	ensureLogData(entry).SecretRedaction = &SecretRedactionSnapshot{
+		Detections: slices.Clone(result.Detections),
+		Blocked:    result.Blocked,
+	}
+}
+
 func applyAuthentication(entry *LogEntry, ctx context.Context) {
 	if entry == nil || ctx == nil {
 		return
@@ -179,6 +240,24 @@ func applyAuthentication(entry *LogEntry, ctx context.Context) {
 	if userPath := strings.TrimSpace(core.UserPathFromContext(ctx)); userPath != "" {
 		entry.UserPath = userPath
 	}
+	if residency := core.GetDataResidency(ctx); residency != "" {
+		if entry.Data == nil || entry.Data.DataResidency == nil {
+			ensureLogData(entry).DataResidency = &DataResidencySnapshot{Requirement: residency}
+		}
+	}
+	if labels := core.GetRequestLabels(ctx); len(labels) > 0 {
+		ensureLogData(entry).Labels = copyMap(labels)
+	}
+}
+
+// effectiveRequestOptions returns the effective request options to record, or
+// nil when the request kept every default.
+func effectiveRequestOptions(ctx context.Context) map[string]string {
+	opts := core.GetRequestOptions(ctx)
+	if opts == nil {
+		return nil
+	}
+	return opts.Effective()
 }
 
 func enrichEntryWithWorkflow(entry *LogEntry, workflow *core.Workflow) {
@@ -210,6 +289,28 @@ func enrichEntryWithWorkflow(entry *LogEntry, workflow *core.Workflow) {
 			entry.ProviderName = providerName
 		}
 		entry.AliasUsed = workflow.Resolution.AliasApplied
+		if experiment := workflow.Resolution.Experiment; experiment != nil {
+			ensureLogData(entry).Experiment = &ExperimentSnapshot{
+				Name:    experiment.Experiment,
+				Variant: experiment.Variant,
+			}
+		}
+		if group := workflow.Resolution.ModelGroup; group != nil {
+			ensureLogData(entry).ModelGroup = &ModelGroupSnapshot{
+				Name:     group.Group,
+				Member:   group.Member,
+				Fallback: group.Fallback,
+			}
+		}
+		if workflow.Resolution.UnlistedModel {
+			ensureLogData(entry).UnlistedModel = true
+		}
+		if residency := strings.TrimSpace(workflow.Resolution.DataResidency); residency != "" {
+			ensureLogData(entry).DataResidency = &DataResidencySnapshot{
+				Requirement: residency,
+				Provider:    strings.TrimSpace(workflow.Resolution.ProviderName),
+			}
+		}
 	}
 	if versionID := strings.TrimSpace(workflow.WorkflowVersionID()); versionID != "" {
 		entry.WorkflowVersionID = versionID
@@ -242,8 +343,16 @@ func resolvedModelForAuditLog(workflow *core.Workflow) string {
 	return model
 }
 
+// captureLoggedRequestBody stores the request body with inline base64 images
+// replaced by placeholders; the images are summarized in RequestImages.
 func captureLoggedRequestBody(entry *LogEntry, bodyBytes []byte) {
-	entry.Data.RequestBody = captureLoggedBody(bodyBytes)
+	var images []core.InlineImage
+	entry.Data.RequestBody = core.StripInlineImages(captureLoggedBody(bodyBytes), func(img core.InlineImage) {
+		images = append(images, img)
+	})
+	if len(images) > 0 {
+		entry.Data.RequestImages = images
+	}
 }
 
 func captureLoggedResponseBody(entry *LogEntry, bodyBytes []byte) {
@@ -272,16 +381,18 @@ type responseBodyCapture struct {
 	http.ResponseWriter
 	body      *bytes.Buffer
 	truncated bool
+	// limit caps the captured bytes; zero means MaxBodyCapture.
+	limit int64
 	// shouldCapture allows middleware to stop buffering once the request is
 	// known to be streaming. Streaming responses are handled by the stream observer path.
 	shouldCapture func() bool
 }
 
 func (r *responseBodyCapture) Write(b []byte) (int, error) {
-	// Write to the capture buffer (limit to MaxBodyCapture to avoid memory issues).
+	// Write to the capture buffer (bounded by the configured limit to avoid memory issues).
 	// Streaming responses bypass this path once marked or identified as SSE.
 	if r.captureEnabled() && !r.truncated {
-		remaining := int(MaxBodyCapture) - r.body.Len()
+		remaining := int(r.captureLimit()) - r.body.Len()
 		if remaining > 0 {
 			if len(b) <= remaining {
 				r.body.Write(b)
@@ -297,6 +408,24 @@ func (r *responseBodyCapture) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// replace swaps the captured bytes for body, within the capture limit.
+func (r *responseBodyCapture) replace(body []byte) {
+	r.body.Reset()
+	r.truncated = false
+	if limit := int(r.captureLimit()); len(body) > limit {
+		body = body[:limit]
+		r.truncated = true
+	}
+	r.body.Write(body)
+}
+
+func (r *responseBodyCapture) captureLimit() int64 {
+	if r.limit <= 0 {
+		return MaxBodyCapture
+	}
+	return r.limit
+}
+
 func (r *responseBodyCapture) captureEnabled() bool {
 	if r == nil || r.shouldCapture == nil {
 		return true
@@ -337,6 +466,16 @@ func shouldCaptureResponseBody(c *echo.Context) bool {
 	return !isEventStreamContentType(c.Response().Header().Get("Content-Type"))
 }
 
+// bodyLogOptOut reports whether the request opted out of body capture with
+// core.NoBodyLogHeader and the configuration honors it.
+func bodyLogOptOut(req *http.Request, cfg Config) bool {
+	if !cfg.LogBodies || cfg.IgnoreNoBodyLogHeader {
+		return false
+	}
+	optOut, err := strconv.ParseBool(strings.TrimSpace(req.Header.Get(core.NoBodyLogHeader)))
+	return err == nil && optOut
+}
+
 func isEventStreamContentType(contentType string) bool {
 	if contentType == "" {
 		return false
@@ -357,9 +496,9 @@ func extractHeaders(headers map[string][]string) map[string]string {
 	return RedactHeaders(result)
 }
 
-// hashAPIKey creates a short hash of the API key for identification.
+// HashAPIKey creates a short hash of the API key for identification.
 // Returns first APIKeyHashPrefixLength hex characters of SHA256 hash.
-func hashAPIKey(authHeader string) string {
+func HashAPIKey(authHeader string) string {
 	// Extract token from "Bearer <token>"
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 	token = strings.TrimSpace(token)
@@ -434,6 +573,51 @@ func EnrichLogEntryWithResolvedRoute(entry *LogEntry, resolvedModel, providerTyp
 	enrichEntryWithResolvedRoute(entry, resolvedModel, providerType, providerName)
 }
 
+// EnrichEntryWithServedModel records the model the provider reported in its
+// response body on the live audit entry.
+func EnrichEntryWithServedModel(c *echo.Context, servedModel string) {
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+
+	enrichEntryWithServedModel(entry, servedModel)
+}
+
+// EnrichLogEntryWithServedModel attaches the provider-reported model directly
+// to an existing audit log entry.
+func EnrichLogEntryWithServedModel(entry *LogEntry, servedModel string) {
+	enrichEntryWithServedModel(entry, servedModel)
+}
+
+// EnrichEntryWithResponseID records the response ID returned to the client
+// on the live audit entry. providerResponseID is the ID the provider issued,
+// when the gateway replaced it with its own.
+func EnrichEntryWithResponseID(c *echo.Context, responseID, providerResponseID string) {
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+
+	enrichEntryWithResponseID(entry, responseID, providerResponseID)
+}
+
+// EnrichLogEntryWithResponseID attaches the response IDs directly to an
+// existing audit log entry.
+func EnrichLogEntryWithResponseID(entry *LogEntry, responseID, providerResponseID string) {
+	enrichEntryWithResponseID(entry, responseID, providerResponseID)
+}
+
 // EnrichEntryWithFailover records the configured failover selector used for the
 // live request when translated execution redirected away from the primary
 // selector.
@@ -457,6 +641,244 @@ func EnrichLogEntryWithFailover(entry *LogEntry, targetModel string) {
 	enrichEntryWithFailover(entry, targetModel)
 }
 
+// EnrichEntryWithContextOverflow records the context overflow strategy the
+// gateway applied to the live request.
+func EnrichEntryWithContextOverflow(c *echo.Context, result *core.ContextOverflowResult) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok {
+		return
+	}
+	EnrichLogEntryWithContextOverflow(entry, result)
+}
+
+// EnrichLogEntryWithContextOverflow attaches context overflow metadata directly
+// to an existing audit log entry.
+func EnrichLogEntryWithContextOverflow(entry *LogEntry, result *core.ContextOverflowResult) {
+	if entry == nil || result == nil {
+		return
+	}
+	ensureLogData(entry).ContextOverflow = &ContextOverflowSnapshot{
+		Strategy:        string(result.Strategy),
+		EstimatedTokens: result.EstimatedTokens,
+		Limit:           result.Limit,
+		DroppedMessages: result.DroppedMessages,
+	}
+}
+
+// EnrichEntryWithPromptCompression records the prompt compression the gateway
+// applied to the live request.
+func EnrichEntryWithPromptCompression(c *echo.Context, result *core.PromptCompressionResult) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok {
+		return
+	}
+	EnrichLogEntryWithPromptCompression(entry, result)
+}
+
+// EnrichLogEntryWithPromptCompression attaches prompt compression metadata
+// directly to an existing audit log entry.
+func EnrichLogEntryWithPromptCompression(entry *LogEntry, result *core.PromptCompressionResult) {
+	if entry == nil || result == nil {
+		return
+	}
+	passes := make([]string, len(result.Passes))
+	for i, pass := range result.Passes {
+		passes[i] = string(pass)
+	}
+	ensureLogData(entry).PromptCompression = &PromptCompressionSnapshot{
+		Passes:          passes,
+		EstimatedTokens: result.EstimatedTokens,
+		TokensSaved:     result.TokensSaved,
+	}
+}
+
+// EnrichEntryWithModeration records the moderation pre-check outcome of the
+// live request.
+func EnrichEntryWithModeration(c *echo.Context, result *core.ModerationResult) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok {
+		return
+	}
+	EnrichLogEntryWithModeration(entry, result)
+}
+
+// EnrichLogEntryWithModeration attaches the moderation pre-check outcome
+// directly to an existing audit log entry.
+func EnrichLogEntryWithModeration(entry *LogEntry, result *core.ModerationResult) {
+	if entry == nil || result == nil {
+		return
+	}
+	ensureLogData(entry).Moderation = &ModerationSnapshot{
+		Flagged:     append([]string(nil), result.Flagged...),
+		Scores:      maps.Clone(result.Scores),
+		Unavailable: result.Unavailable,
+		Error:       result.Error,
+	}
+}
+
+// EnrichEntryWithToolLoop records the server-side tool loop that answered the
+// live request.
+func EnrichEntryWithToolLoop(c *echo.Context, result *core.ToolLoopResult) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok {
+		return
+	}
+	EnrichLogEntryWithToolLoop(entry, result)
+}
+
+// EnrichLogEntryWithToolLoop attaches the server-side tool loop directly to
+// an existing audit log entry.
+func EnrichLogEntryWithToolLoop(entry *LogEntry, result *core.ToolLoopResult) {
+	if entry == nil || result == nil {
+		return
+	}
+	snapshot := &ToolLoopSnapshot{
+		Iterations: result.Iterations,
+		Turns:      make([]ToolLoopTurnSnapshot, len(result.Turns)),
+	}
+	for i, turn := range result.Turns {
+		calls := make([]ToolLoopCallSnapshot, len(turn.Calls))
+		for j, call := range turn.Calls {
+			calls[j] = ToolLoopCallSnapshot{
+				CallID:      call.CallID,
+				Name:        call.Name,
+				DurationMs:  call.Duration.Milliseconds(),
+				OutputBytes: call.OutputBytes,
+				Truncated:   call.Truncated,
+				Error:       call.Error,
+			}
+		}
+		snapshot.Turns[i] = ToolLoopTurnSnapshot{
+			ResponseID:   turn.ResponseID,
+			Model:        turn.Model,
+			InputTokens:  turn.InputTokens,
+			OutputTokens: turn.OutputTokens,
+			Calls:        calls,
+		}
+	}
+	ensureLogData(entry).ToolLoop = snapshot
+}
+
+// EnrichEntryWithEmbeddingCache records the embeddings cache hits and misses
+// of the live request.
+func EnrichEntryWithEmbeddingCache(c *echo.Context, hits, misses int) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).EmbeddingCache = &EmbeddingCacheSnapshot{Hits: hits, Misses: misses}
+}
+
+// EnrichEntryWithChaos marks the live request as faulted by the fault
+// injection rule named rule.
+func EnrichEntryWithChaos(c *echo.Context, rule, effect string) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).Chaos = &ChaosSnapshot{Rule: rule, Effect: effect}
+}
+
+// EnrichEntryWithSelfProtection records the self-protection match of the
+// live request.
+func EnrichEntryWithSelfProtection(c *echo.Context, snapshot SelfProtectionSnapshot) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).SelfProtection = &snapshot
+}
+
+// EnrichEntryWithResponseTransforms records the response transforms applied
+// to the live request's response. original, when set, is the response body
+// before the transforms; it is captured instead of the body the client
+// received.
+func EnrichEntryWithResponseTransforms(c *echo.Context, snapshot ResponseTransformSnapshot, original []byte) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).ResponseTransforms = &snapshot
+	if original != nil {
+		c.Set(string(OriginalResponseBodyKey), original)
+	}
+}
+
+// EnrichLogEntryWithResponseTransforms records the response transforms
+// applied to a streamed response on its entry.
+func EnrichLogEntryWithResponseTransforms(entry *LogEntry, snapshot ResponseTransformSnapshot) {
+	if entry == nil {
+		return
+	}
+	ensureLogData(entry).ResponseTransforms = &snapshot
+}
+
+// EnrichEntryWithIdempotentReplay marks the live request as answered from
+// the response of the request with id originalRequestID.
+func EnrichEntryWithIdempotentReplay(c *echo.Context, originalRequestID string) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).IdempotentReplayOf = originalRequestID
+}
+
+// EnrichEntryWithStreamFormat records the format the live request's stream
+// is re-encoded to for the client.
+func EnrichEntryWithStreamFormat(c *echo.Context, format string) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).StreamFormat = format
+}
+
+// EnrichEntryWithMaintenance records the maintenance mode state an admin
+// request set.
+func EnrichEntryWithMaintenance(c *echo.Context, snapshot MaintenanceSnapshot) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).Maintenance = &snapshot
+}
+
+// EnrichEntryWithInspection records the live request inspection an admin
+// request started or stopped.
+func EnrichEntryWithInspection(c *echo.Context, snapshot InspectionSnapshot) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).Inspection = &snapshot
+}
+
+// EnrichEntryWithCapabilityProbe records the outcome of a capability probe
+// on the audit entry of the admin request that ran it.
+func EnrichEntryWithCapabilityProbe(c *echo.Context, snapshot CapabilityProbeSnapshot) {
+	entry, ok := c.Get(string(LogEntryKey)).(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).CapabilityProbe = &snapshot
+}
+
+// EnrichLogEntryWithComparison links an audit log entry to the chat comparison
+// that fanned it out.
+func EnrichLogEntryWithComparison(entry *LogEntry, comparisonID string, index int) {
+	if entry == nil {
+		return
+	}
+	comparisonID = strings.TrimSpace(comparisonID)
+	if comparisonID == "" {
+		return
+	}
+	ensureLogData(entry).Comparison = &ComparisonSnapshot{
+		ID:    comparisonID,
+		Index: index,
+	}
+}
+
 func enrichEntryWithResolvedRoute(entry *LogEntry, resolvedModel, providerType, providerName string) {
 	if entry == nil {
 		return
@@ -472,6 +894,27 @@ func enrichEntryWithResolvedRoute(entry *LogEntry, resolvedModel, providerType,
 	}
 }
 
+func enrichEntryWithServedModel(entry *LogEntry, servedModel string) {
+	if entry == nil {
+		return
+	}
+	if servedModel = strings.TrimSpace(servedModel); servedModel != "" {
+		entry.ServedModel = servedModel
+	}
+}
+
+func enrichEntryWithResponseID(entry *LogEntry, responseID, providerResponseID string) {
+	if entry == nil {
+		return
+	}
+	if responseID = strings.TrimSpace(responseID); responseID != "" {
+		entry.ResponseID = responseID
+	}
+	if providerResponseID = strings.TrimSpace(providerResponseID); providerResponseID != "" && providerResponseID != entry.ResponseID {
+		entry.ProviderResponseID = providerResponseID
+	}
+}
+
 func enrichEntryWithFailover(entry *LogEntry, targetModel string) {
 	if entry == nil {
 		return
@@ -566,10 +1009,107 @@ func EnrichEntryWithUserPath(c *echo.Context, userPath string) {
 	entry.UserPath = userPath
 }
 
-// EnrichLogEntryWithRequestContext attaches auth and effective user-path
-// metadata from context directly to an existing log entry.
+// EnrichEntryWithLabels attaches the cost allocation labels of the request to
+// the live audit entry.
+func EnrichEntryWithLabels(c *echo.Context, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).Labels = copyMap(labels)
+}
+
+// EnrichEntryWithPromptTemplate attaches the prompt template version rendered
+// into the request to the live audit entry.
+func EnrichEntryWithPromptTemplate(c *echo.Context, use core.PromptTemplateUse) {
+	if use.Name == "" {
+		return
+	}
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).PromptTemplate = &PromptTemplateSnapshot{Name: use.Name, Version: use.Version}
+}
+
+// EnrichEntryWithUser attaches the end user named by the user request field to
+// the live audit entry.
+func EnrichEntryWithUser(c *echo.Context, user core.RequestUser) {
+	if user.Hash == "" {
+		return
+	}
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	data := ensureLogData(entry)
+	data.UserHash = user.Hash
+	data.User = user.Raw
+}
+
+// EnrichEntryWithInlineImages attaches the summaries of the inline base64
+// images of the request to the live audit entry. They are recorded even when
+// the request body is too big to capture.
+func EnrichEntryWithInlineImages(c *echo.Context, images []core.InlineImage) {
+	if len(images) == 0 {
+		return
+	}
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	ensureLogData(entry).RequestImages = images
+}
+
+// EnrichEntryWithRequestBodyDigest records the size and SHA-256 hash of a
+// request body that was piped to the provider instead of being captured, and
+// marks the body as too big to handle. An empty hash records only the size.
+func EnrichEntryWithRequestBodyDigest(c *echo.Context, size int64, sha256Hex string) {
+	entryVal := c.Get(string(LogEntryKey))
+	if entryVal == nil {
+		return
+	}
+
+	entry, ok := entryVal.(*LogEntry)
+	if !ok || entry == nil {
+		return
+	}
+	data := ensureLogData(entry)
+	data.RequestBodyTooBigToHandle = true
+	data.RequestBodyBytes = size
+	data.RequestBodySHA256 = sha256Hex
+}
+
+// EnrichLogEntryWithRequestContext attaches auth, effective user-path, data
+// residency, labels, upstream call metadata and guardrail canary decisions
+// from context directly to an existing log entry.
 func EnrichLogEntryWithRequestContext(entry *LogEntry, ctx context.Context) {
 	applyAuthentication(entry, ctx)
+	applyUpstreamCall(entry, core.GetUpstreamCall(ctx))
+	applyGuardrailCanaries(entry, core.GetGuardrailCanaries(ctx))
 }
 
 func auditEnabledForContext(ctx context.Context) bool {
@@ -610,6 +1150,20 @@ func EnrichEntryWithStream(c *echo.Context, stream bool) {
 	entry.Stream = stream
 }
 
+// EnrichEntryWithUpstreamCall copies the provider HTTP outcome and the
+// guardrail canary decisions recorded so far onto the audit entry. Streaming
+// handlers call it before CreateStreamEntry, since the stream entry is
+// written before the middleware finishes.
+func EnrichEntryWithUpstreamCall(c *echo.Context) {
+	entry := GetStreamEntryFromContext(c)
+	if entry == nil {
+		return
+	}
+	ctx := c.Request().Context()
+	applyUpstreamCall(entry, core.GetUpstreamCall(ctx))
+	applyGuardrailCanaries(entry, core.GetGuardrailCanaries(ctx))
+}
+
 // toValidUTF8String converts bytes to a valid UTF-8 string.
 // If the input is already valid UTF-8, it returns it as-is.
 // Otherwise, it replaces invalid bytes with the Unicode replacement character.
@@ -622,48 +1176,12 @@ func toValidUTF8String(b []byte) string {
 	return strings.ToValidUTF8(string(b), "\uFFFD")
 }
 
+// maxDecompressedBodySize caps decoded response bodies (compression bomb protection).
+const maxDecompressedBodySize = 2 * 1024 * 1024
+
 // decompressBody attempts to decompress the response body based on Content-Encoding.
 // Returns original body unchanged if no decompression needed or if decompression fails.
-// Supports gzip, deflate, and brotli (br) encodings.
+// Supports gzip, deflate, brotli (br) and zstd encodings.
 func decompressBody(body []byte, contentEncoding string) ([]byte, bool) {
-	if len(body) == 0 || contentEncoding == "" {
-		return body, false
-	}
-
-	// Parse encoding (handle "gzip, deflate" - take first)
-	encoding := strings.TrimSpace(strings.Split(contentEncoding, ",")[0])
-	encoding = strings.ToLower(encoding)
-
-	if encoding == "identity" || encoding == "" {
-		return body, false
-	}
-
-	const maxDecompressedSize = 2 * 1024 * 1024 // 2MB limit
-
-	var reader io.ReadCloser
-	var err error
-
-	switch encoding {
-	case "gzip":
-		reader, err = gzip.NewReader(bytes.NewReader(body))
-	case "deflate":
-		reader = flate.NewReader(bytes.NewReader(body))
-	case "br":
-		reader = io.NopCloser(brotli.NewReader(bytes.NewReader(body)))
-	default:
-		return body, false
-	}
-
-	if err != nil {
-		return body, false
-	}
-	defer reader.Close()
-
-	// Read with size limit (compression bomb protection)
-	decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize))
-	if err != nil {
-		return body, false
-	}
-
-	return decompressed, true
+	return compression.Decompress(body, contentEncoding, maxDecompressedBodySize)
 }
@@ -1,13 +1,18 @@
 package auditlog
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v5"
 
 	"gomodel/internal/core"
+	"gomodel/internal/streaming"
 )
 
 func TestEnrichEntryWithWorkflow_PrefersProviderNameForResolvedModel(t *testing.T) {
@@ -40,3 +45,246 @@ func TestEnrichEntryWithWorkflow_PrefersProviderNameForResolvedModel(t *testing.
 		t.Fatalf("ResolvedModel = %q, want %q", got, "openai_test/gpt-5-nano")
 	}
 }
+
+func TestEnrichEntryWithWorkflow_RecordsModelGroup(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	entry := &LogEntry{ID: "model-group"}
+	c.Set(string(LogEntryKey), entry)
+
+	EnrichEntryWithWorkflow(c, &core.Workflow{
+		Resolution: &core.RequestModelResolution{
+			Requested:        core.NewRequestedModelSelector("group:coding", ""),
+			ResolvedSelector: core.ModelSelector{Provider: "openai", Model: "gpt-5"},
+			ModelGroup:       &core.ModelGroupSelection{Group: "coding", Member: "openai/gpt-5"},
+		},
+	})
+
+	if entry.Data == nil || entry.Data.ModelGroup == nil {
+		t.Fatal("expected the model group to be stored in audit data")
+	}
+	if got := *entry.Data.ModelGroup; got != (ModelGroupSnapshot{Name: "coding", Member: "openai/gpt-5"}) {
+		t.Fatalf("ModelGroup = %+v, want coding served by openai/gpt-5", got)
+	}
+	if got := entry.RequestedModel; got != "group:coding" {
+		t.Fatalf("RequestedModel = %q, want the group", got)
+	}
+}
+
+func TestMiddleware_RecordsUpstreamStatusSeparatelyFromGatewayStatus(t *testing.T) {
+	e := echo.New()
+	logger := &capturingLogger{cfg: Config{Enabled: true}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := Middleware(logger)(func(c *echo.Context) error {
+		core.RecordUpstreamCall(c.Request().Context(), 529, 40*time.Millisecond)
+		return c.NoContent(http.StatusServiceUnavailable)
+	})
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want 503", entry.StatusCode)
+	}
+	if entry.UpstreamStatusCode != 529 {
+		t.Fatalf("UpstreamStatusCode = %d, want 529", entry.UpstreamStatusCode)
+	}
+	if entry.UpstreamDurationNs != (40 * time.Millisecond).Nanoseconds() {
+		t.Fatalf("UpstreamDurationNs = %d, want %d", entry.UpstreamDurationNs, (40 * time.Millisecond).Nanoseconds())
+	}
+}
+
+func TestMiddleware_RecordsPacingDelay(t *testing.T) {
+	e := echo.New()
+	logger := &capturingLogger{cfg: Config{Enabled: true}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := Middleware(logger)(func(c *echo.Context) error {
+		ctx := c.Request().Context()
+		core.RecordPacingDelay(ctx, 150*time.Millisecond)
+		core.RecordUpstreamCall(ctx, http.StatusOK, 400*time.Millisecond)
+		return c.NoContent(http.StatusOK)
+	})
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry.Data == nil || entry.Data.PacingDelayNs != (150*time.Millisecond).Nanoseconds() {
+		t.Fatalf("Data = %+v, want pacing_delay_ns of 150ms", entry.Data)
+	}
+}
+
+func TestMiddleware_RecordsGuardrailCanaryDecisions(t *testing.T) {
+	e := echo.New()
+	logger := &capturingLogger{cfg: Config{Enabled: true}}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	handler := Middleware(logger)(func(c *echo.Context) error {
+		core.RecordGuardrailCanary(c.Request().Context(), "pii", true)
+		core.RecordGuardrailCanary(c.Request().Context(), "tone", false)
+		core.RecordGuardrailCanary(c.Request().Context(), "pii", false)
+		return c.NoContent(http.StatusOK)
+	})
+	if err := handler(c); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
+	}
+	want := []GuardrailCanarySnapshot{{Name: "pii", Applied: true}, {Name: "tone", Applied: false}}
+	if got := logger.entries[0].Data.GuardrailCanaries; !reflect.DeepEqual(got, want) {
+		t.Fatalf("GuardrailCanaries = %+v, want %+v", got, want)
+	}
+}
+
+func TestEnrichEntryWithUpstreamCall_CopiesIntoStreamEntry(t *testing.T) {
+	e := echo.New()
+	call := &core.UpstreamCall{}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req = req.WithContext(core.WithUpstreamCall(req.Context(), call))
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	entry := &LogEntry{ID: "stream-upstream"}
+	c.Set(string(LogEntryKey), entry)
+
+	call.Record(http.StatusOK, 120*time.Millisecond)
+	EnrichEntryWithUpstreamCall(c)
+
+	streamEntry := CreateStreamEntry(entry)
+	if streamEntry.UpstreamStatusCode != http.StatusOK {
+		t.Fatalf("UpstreamStatusCode = %d, want 200", streamEntry.UpstreamStatusCode)
+	}
+	if streamEntry.UpstreamDurationNs != (120 * time.Millisecond).Nanoseconds() {
+		t.Fatalf("UpstreamDurationNs = %d, want %d", streamEntry.UpstreamDurationNs, (120 * time.Millisecond).Nanoseconds())
+	}
+}
+
+func TestMiddleware_NoBodyLogHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		ignore     bool
+		wantBodies bool
+	}{
+		{name: "honored", ignore: false, wantBodies: false},
+		{name: "ignored", ignore: true, wantBodies: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			logger := &capturingLogger{cfg: Config{Enabled: true, LogBodies: true, IgnoreNoBodyLogHeader: tt.ignore}}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			req.Header.Set(core.NoBodyLogHeader, "true")
+			req = req.WithContext(core.WithRequestSnapshot(req.Context(), core.NewRequestSnapshot(
+				http.MethodPost, "/v1/chat/completions", nil, nil, nil, "",
+				[]byte(`{"model":"gpt-4"}`), false, "", nil,
+			)))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := Middleware(logger)(func(c *echo.Context) error {
+				core.RecordUpstreamCall(c.Request().Context(), http.StatusOK, time.Millisecond)
+				return c.JSON(http.StatusOK, map[string]string{"id": "chatcmpl-1"})
+			})
+			if err := handler(c); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if len(logger.entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
+			}
+			entry := logger.entries[0]
+			if entry.StatusCode != http.StatusOK || entry.UpstreamStatusCode != http.StatusOK {
+				t.Fatalf("StatusCode = %d, UpstreamStatusCode = %d, want metadata recorded", entry.StatusCode, entry.UpstreamStatusCode)
+			}
+			gotBodies := entry.Data.RequestBody != nil && entry.Data.ResponseBody != nil
+			if gotBodies != tt.wantBodies {
+				t.Fatalf("RequestBody = %#v, ResponseBody = %#v, want bodies captured = %v", entry.Data.RequestBody, entry.Data.ResponseBody, tt.wantBodies)
+			}
+			if entry.Data.BodyLogOptOut == tt.wantBodies {
+				t.Fatalf("BodyLogOptOut = %v, want %v", entry.Data.BodyLogOptOut, !tt.wantBodies)
+			}
+		})
+	}
+}
+
+func TestMiddleware_NoBodyLogHeaderSkipsStreamBodies(t *testing.T) {
+	tests := []struct {
+		name       string
+		ignore     bool
+		wantBodies bool
+	}{
+		{name: "honored", ignore: false, wantBodies: false},
+		{name: "ignored", ignore: true, wantBodies: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			logger := &capturingLogger{cfg: Config{Enabled: true, LogBodies: true, IgnoreNoBodyLogHeader: tt.ignore}}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			req.Header.Set(core.NoBodyLogHeader, "true")
+			req = req.WithContext(core.WithRequestSnapshot(req.Context(), core.NewRequestSnapshot(
+				http.MethodPost, "/v1/chat/completions", nil, nil, nil, "",
+				[]byte(`{"model":"gpt-4","stream":true}`), false, "", nil,
+			)))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Mirrors the streaming handlers: populate the request data with
+			// the global config, then hand a copy of the entry to the observer.
+			handler := Middleware(logger)(func(c *echo.Context) error {
+				MarkEntryAsStreaming(c, true)
+				entry := GetStreamEntryFromContext(c)
+				PopulateRequestData(entry, c.Request(), logger.Config())
+				stream := streaming.NewObservedSSEStream(
+					io.NopCloser(strings.NewReader("data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n")),
+					NewStreamLogObserver(logger, CreateStreamEntry(entry), c.Request().URL.Path),
+				)
+				if _, err := io.Copy(io.Discard, stream); err != nil {
+					return err
+				}
+				return stream.Close()
+			})
+			if err := handler(c); err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if len(logger.entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1 stream entry", len(logger.entries))
+			}
+			entry := logger.entries[0]
+			if !entry.Stream {
+				t.Fatal("Stream = false, want the stream entry")
+			}
+			gotBodies := entry.Data.RequestBody != nil && entry.Data.ResponseBody != nil
+			if gotBodies != tt.wantBodies {
+				t.Fatalf("RequestBody = %#v, ResponseBody = %#v, want bodies captured = %v", entry.Data.RequestBody, entry.Data.ResponseBody, tt.wantBodies)
+			}
+			if entry.Data.BodyLogOptOut == tt.wantBodies {
+				t.Fatalf("BodyLogOptOut = %v, want %v", entry.Data.BodyLogOptOut, !tt.wantBodies)
+			}
+		})
+	}
+}
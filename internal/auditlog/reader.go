@@ -20,11 +20,20 @@ type LogQueryParams struct {
 	Path           string
 	UserPath       string
 	ErrorType      string
-	Search         string
-	StatusCode     *int
-	Stream         *bool
-	Limit          int
-	Offset         int
+	// ResponseID matches the client-facing or the provider-issued response ID.
+	ResponseID string
+	// RequestID matches the gateway request ID exactly.
+	RequestID  string
+	Search     string
+	StatusCode *int
+	// UpstreamStatusCode filters by the status the provider returned.
+	UpstreamStatusCode *int
+	Stream             *bool
+	Limit              int
+	Offset             int
+	// Cursor continues after the page that returned it as NextCursor and
+	// replaces Offset.
+	Cursor string
 }
 
 // LogListResult holds a paginated list of audit log entries.
@@ -33,6 +42,10 @@ type LogListResult struct {
 	Total   int        `json:"total"`
 	Limit   int        `json:"limit"`
 	Offset  int        `json:"offset"`
+	// NextCursor fetches the next page when passed as cursor, and is empty
+	// on the last page. Unlike offset it stays stable while new entries
+	// arrive, so it is the preferred way to page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ConversationResult holds a linear conversation thread centered around an anchor log.
@@ -41,7 +54,7 @@ type ConversationResult struct {
 	Entries  []LogEntry `json:"entries"`
 }
 
-// Reader provides read access to audit log data for the admin API.
+// Reader provides read-only access to audit log data for the admin API.
 type Reader interface {
 	// GetLogs returns a paginated list of audit log entries with optional filtering.
 	GetLogs(ctx context.Context, params LogQueryParams) (*LogListResult, error)
@@ -50,8 +63,38 @@ type Reader interface {
 	// Returns (nil, nil) when no entry exists for the given ID.
 	GetLogByID(ctx context.Context, id string) (*LogEntry, error)
 
+	// GetLogByResponseID returns the earliest entry whose client-facing or
+	// provider-issued response ID equals responseID, i.e. the request that
+	// produced the response. Returns (nil, nil) when no entry matches.
+	GetLogByResponseID(ctx context.Context, responseID string) (*LogEntry, error)
+
 	// GetConversation returns a linear conversation thread around a seed log entry.
 	// It follows Responses API linkage fields when available:
 	// request_body.previous_response_id and response_body.id.
 	GetConversation(ctx context.Context, logID string, limit int) (*ConversationResult, error)
+
+	// GetErrorSummary returns error counts grouped by provider, model, error
+	// type and status code, plus a time-bucketed series and, optionally, the
+	// most frequent normalized error messages.
+	GetErrorSummary(ctx context.Context, params ErrorSummaryParams) (*ErrorSummary, error)
+
+	// GetGuardrailCanaryStats returns the request and error counts of the
+	// requests a guardrail in canary rollout was applied to and skipped.
+	GetGuardrailCanaryStats(ctx context.Context, params GuardrailCanaryParams) (*GuardrailCanaryStats, error)
+}
+
+// Redactor redacts and removes individual audit log entries. Both are
+// recorded in a redaction log that is kept independently of the entries. The
+// readers of every storage backend implement it, and so do the reader
+// wrappers when the reader they wrap does.
+type Redactor interface {
+	// RedactLog replaces the captured bodies of an entry with RedactedMarker,
+	// drops its captured headers and stamps it with actor (the acting admin's
+	// API key hash) and the current time. It returns the updated entry,
+	// ErrNotFound, or ErrAlreadyRedacted.
+	RedactLog(ctx context.Context, id, actor string) (*LogEntry, error)
+
+	// DeleteLog removes an entry entirely. It returns ErrNotFound when no entry
+	// exists for the given ID.
+	DeleteLog(ctx context.Context, id, actor string) error
 }
@@ -0,0 +1,187 @@
+package auditlog
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LookupCacheStats reports the by-ID lookup cache of a CachingReader.
+type LookupCacheStats struct {
+	Entries    int    `json:"entries"`
+	MaxEntries int    `json:"max_entries"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+}
+
+// CachingReader keeps recently read entries in memory so repeated GetLogByID
+// calls for the same entry skip the store. It caches entries as the wrapped
+// Reader returns them, decrypted when that is a DecryptingReader; callers
+// must copy Data before changing it. List and search queries always go to
+// the wrapped Reader. Redacting or deleting an entry through the CachingReader
+// drops it from the cache; changes made elsewhere are seen once the entry's
+// TTL passes.
+type CachingReader struct {
+	Reader
+
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+	// generation counts invalidations, so a read that raced with a
+	// redaction or deletion does not cache the entry it read before.
+	generation uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cachedEntry struct {
+	id        string
+	entry     *LogEntry
+	expiresAt time.Time
+}
+
+// NewCachingReader wraps reader with a lookup cache of at most maxEntries
+// entries, each kept for ttl. It returns reader unchanged when reader is nil
+// or maxEntries is not positive.
+func NewCachingReader(reader Reader, maxEntries int, ttl time.Duration) Reader {
+	if reader == nil || maxEntries <= 0 {
+		return reader
+	}
+	return &CachingReader{
+		Reader:     reader,
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// GetLogByID returns the cached entry for id, reading and caching it on a
+// miss. Missing entries are not cached, since they may still be written.
+func (r *CachingReader) GetLogByID(ctx context.Context, id string) (*LogEntry, error) {
+	if entry := r.get(id); entry != nil {
+		r.hits.Add(1)
+		return entry, nil
+	}
+	r.misses.Add(1)
+
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+	entry, err := r.Reader.GetLogByID(ctx, id)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	// An entry that failed to decrypt is read again next time, in case the
+	// failure was not permanent.
+	if entry.Data == nil || entry.Data.DecryptionError == "" {
+		r.put(id, entry, generation)
+	}
+	cp := *entry
+	return &cp, nil
+}
+
+// RedactLog redacts an entry and drops it from the cache.
+func (r *CachingReader) RedactLog(ctx context.Context, id, actor string) (*LogEntry, error) {
+	redactor, ok := r.Reader.(Redactor)
+	if !ok {
+		return nil, ErrRedactionUnsupported
+	}
+	defer r.invalidate(id)
+	return redactor.RedactLog(ctx, id, actor)
+}
+
+// DeleteLog removes an entry and drops it from the cache.
+func (r *CachingReader) DeleteLog(ctx context.Context, id, actor string) error {
+	redactor, ok := r.Reader.(Redactor)
+	if !ok {
+		return ErrRedactionUnsupported
+	}
+	defer r.invalidate(id)
+	return redactor.DeleteLog(ctx, id, actor)
+}
+
+// ReEncrypt forwards to the wrapped Reader. Re-encryption does not change
+// decrypted entries, so the cache is kept.
+func (r *CachingReader) ReEncrypt(ctx context.Context, after string, limit int) (*ReEncryptResult, error) {
+	reEncrypter, ok := r.Reader.(interface {
+		ReEncrypt(ctx context.Context, after string, limit int) (*ReEncryptResult, error)
+	})
+	if !ok {
+		return nil, ErrEncryptionDisabled
+	}
+	return reEncrypter.ReEncrypt(ctx, after, limit)
+}
+
+// LookupCacheStats returns the cache size and its hits and misses since
+// startup.
+func (r *CachingReader) LookupCacheStats() LookupCacheStats {
+	r.mu.Lock()
+	entries := r.order.Len()
+	r.mu.Unlock()
+	return LookupCacheStats{
+		Entries:    entries,
+		MaxEntries: r.maxEntries,
+		Hits:       r.hits.Load(),
+		Misses:     r.misses.Load(),
+	}
+}
+
+func (r *CachingReader) get(id string) *LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elem, ok := r.items[id]
+	if !ok {
+		return nil
+	}
+	cached := elem.Value.(*cachedEntry)
+	if !cached.expiresAt.IsZero() && !r.now().Before(cached.expiresAt) {
+		r.remove(elem)
+		return nil
+	}
+	r.order.MoveToFront(elem)
+	cp := *cached.entry
+	return &cp
+}
+
+func (r *CachingReader) put(id string, entry *LogEntry, generation uint64) {
+	cp := *entry
+	cached := &cachedEntry{id: id, entry: &cp}
+	if r.ttl > 0 {
+		cached.expiresAt = r.now().Add(r.ttl)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if generation != r.generation {
+		return
+	}
+	if elem, ok := r.items[id]; ok {
+		r.remove(elem)
+	}
+	r.items[id] = r.order.PushFront(cached)
+	for r.order.Len() > r.maxEntries {
+		r.remove(r.order.Back())
+	}
+}
+
+func (r *CachingReader) invalidate(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	if elem, ok := r.items[id]; ok {
+		r.remove(elem)
+	}
+}
+
+func (r *CachingReader) remove(elem *list.Element) {
+	cached := r.order.Remove(elem).(*cachedEntry)
+	delete(r.items, cached.id)
+}
@@ -0,0 +1,183 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingReader serves GetLogByID from entries and counts the reads.
+type countingReader struct {
+	Reader
+	mu      sync.Mutex
+	entries map[string]*LogEntry
+	reads   map[string]int
+	// onRead runs during a read, before the entry is returned.
+	onRead func()
+}
+
+func newCountingReader(ids ...string) *countingReader {
+	r := &countingReader{entries: map[string]*LogEntry{}, reads: map[string]int{}}
+	for _, id := range ids {
+		r.entries[id] = &LogEntry{ID: id, Data: &LogData{RequestBody: "body " + id}}
+	}
+	return r
+}
+
+func (r *countingReader) GetLogByID(_ context.Context, id string) (*LogEntry, error) {
+	r.mu.Lock()
+	r.reads[id]++
+	entry := r.entries[id]
+	onRead := r.onRead
+	r.mu.Unlock()
+	if onRead != nil {
+		onRead()
+	}
+	return entry, nil
+}
+
+func (r *countingReader) RedactLog(_ context.Context, id, _ string) (*LogEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[id] = &LogEntry{ID: id, Redacted: true, Data: &LogData{RequestBody: RedactedMarker}}
+	return r.entries[id], nil
+}
+
+func (r *countingReader) DeleteLog(_ context.Context, id, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, id)
+	return nil
+}
+
+func (r *countingReader) readsOf(id string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads[id]
+}
+
+func TestNewCachingReader_Disabled(t *testing.T) {
+	inner := newCountingReader()
+	if got := NewCachingReader(inner, 0, time.Minute); got != Reader(inner) {
+		t.Fatalf("NewCachingReader() with no entries = %T, want the wrapped reader", got)
+	}
+	if got := NewCachingReader(nil, 10, time.Minute); got != nil {
+		t.Fatalf("NewCachingReader(nil) = %v, want nil", got)
+	}
+}
+
+func TestCachingReader_EvictsLeastRecentlyUsed(t *testing.T) {
+	inner := newCountingReader("a", "b", "c")
+	r := NewCachingReader(inner, 2, 0).(*CachingReader)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := r.GetLogByID(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// c evicted b, which was read longer ago than a; b evicted c.
+	if got := []int{inner.readsOf("a"), inner.readsOf("b"), inner.readsOf("c")}; got[0] != 1 || got[1] != 2 || got[2] != 1 {
+		t.Fatalf("store reads a, b, c = %v, want [1 2 1]", got)
+	}
+	if stats := r.LookupCacheStats(); stats != (LookupCacheStats{Entries: 2, MaxEntries: 2, Hits: 2, Misses: 4}) {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestCachingReader_ExpiresEntries(t *testing.T) {
+	inner := newCountingReader("a")
+	r := NewCachingReader(inner, 10, time.Minute).(*CachingReader)
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _ = r.GetLogByID(ctx, "a")
+	now = now.Add(59 * time.Second)
+	_, _ = r.GetLogByID(ctx, "a")
+	now = now.Add(time.Second)
+	_, _ = r.GetLogByID(ctx, "a")
+	if got := inner.readsOf("a"); got != 2 {
+		t.Fatalf("store reads = %d, want 2", got)
+	}
+}
+
+func TestCachingReader_ReturnsCopies(t *testing.T) {
+	r := NewCachingReader(newCountingReader("a"), 10, 0)
+	ctx := context.Background()
+
+	entry, _ := r.GetLogByID(ctx, "a")
+	entry.ID = "changed"
+	entry.Data = nil
+	again, _ := r.GetLogByID(ctx, "a")
+	if again.ID != "a" || again.Data == nil {
+		t.Fatalf("cached entry was changed through a returned copy: %+v", again)
+	}
+}
+
+func TestCachingReader_RedactionDuringReadIsNotCached(t *testing.T) {
+	inner := newCountingReader("a")
+	r := NewCachingReader(inner, 10, 0)
+	ctx := context.Background()
+
+	// The entry is redacted after the store returned its old version but
+	// before the read finished, so that version must not be cached.
+	inner.onRead = func() {
+		inner.mu.Lock()
+		inner.onRead = nil
+		inner.mu.Unlock()
+		if _, err := r.(Redactor).RedactLog(ctx, "a", "actor"); err != nil {
+			t.Error(err)
+		}
+	}
+	_, _ = r.GetLogByID(ctx, "a")
+	entry, _ := r.GetLogByID(ctx, "a")
+	if !entry.Redacted {
+		t.Fatalf("entry = %+v, want the redacted version", entry)
+	}
+}
+
+func TestCachingReader_RedactionUnsupported(t *testing.T) {
+	r := NewCachingReader(struct{ Reader }{newCountingReader("a")}, 10, 0).(Redactor)
+	ctx := context.Background()
+
+	if _, err := r.RedactLog(ctx, "a", "actor"); !errors.Is(err, ErrRedactionUnsupported) {
+		t.Fatalf("RedactLog error = %v, want ErrRedactionUnsupported", err)
+	}
+	if err := r.DeleteLog(ctx, "a", "actor"); !errors.Is(err, ErrRedactionUnsupported) {
+		t.Fatalf("DeleteLog error = %v, want ErrRedactionUnsupported", err)
+	}
+}
+
+func TestCachingReader_ConcurrentAccess(t *testing.T) {
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("log-%d", i)
+	}
+	r := NewCachingReader(newCountingReader(ids...), 8, time.Minute).(*CachingReader)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Go(func() {
+			for i := range 200 {
+				id := ids[(w+i)%len(ids)]
+				entry, err := r.GetLogByID(ctx, id)
+				if err != nil || entry == nil || entry.ID != id {
+					t.Errorf("GetLogByID(%q) = %+v, %v", id, entry, err)
+					return
+				}
+				if i%50 == 0 {
+					_, _ = r.RedactLog(ctx, id, "actor")
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if stats := r.LookupCacheStats(); stats.Entries > 8 || stats.Hits+stats.Misses != 8*200 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
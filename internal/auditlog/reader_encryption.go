@@ -0,0 +1,179 @@
+package auditlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultReEncryptLimit and MaxReEncryptLimit bound how many entries one
+// ReEncrypt call migrates.
+const (
+	DefaultReEncryptLimit = 500
+	MaxReEncryptLimit     = 5000
+)
+
+// sealedEntry is the sealed part of one stored entry.
+type sealedEntry struct {
+	ID     string
+	Sealed *SealedFields
+}
+
+// sealedEntryStore is implemented by the backend readers to find and update
+// entries sealed with a key other than the active one.
+type sealedEntryStore interface {
+	// listSealed returns up to limit sealed entries whose key ID differs from
+	// keyID and whose ID sorts after afterID, ordered by ID.
+	listSealed(ctx context.Context, keyID, afterID string, limit int) ([]sealedEntry, error)
+	// updateSealed replaces the sealed fields of entry id if it is still
+	// sealed with previousKeyID. It reports whether the entry was updated.
+	updateSealed(ctx context.Context, id, previousKeyID string, sealed *SealedFields) (bool, error)
+}
+
+// ReEncryptResult reports one page of a re-encryption run.
+type ReEncryptResult struct {
+	ActiveKeyID string `json:"active_key_id"`
+	Rewrapped   int    `json:"rewrapped"`
+	// Failures lists the entries whose data key could not be unwrapped,
+	// usually because their key is no longer configured.
+	Failures []ReEncryptFailure `json:"failures"`
+	// Next is the cursor to pass to the following call. It is empty when Done.
+	Next string `json:"next,omitempty"`
+	Done bool   `json:"done"`
+}
+
+// ReEncryptFailure is one entry re-encryption skipped.
+type ReEncryptFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// DecryptingReader decrypts sealed entries returned by the wrapped Reader.
+// An entry that cannot be decrypted is returned with DecryptionError set
+// instead of failing the whole request.
+type DecryptingReader struct {
+	Reader
+	keyring *Keyring
+}
+
+// NewDecryptingReader wraps reader so sealed entries are decrypted with
+// keyring. keyring may be nil, in which case sealed entries are reported as
+// undecryptable. It returns nil when reader is nil.
+func NewDecryptingReader(reader Reader, keyring *Keyring) *DecryptingReader {
+	if reader == nil {
+		return nil
+	}
+	return &DecryptingReader{Reader: reader, keyring: keyring}
+}
+
+// GetLogs returns a page of entries with their sealed fields decrypted.
+func (r *DecryptingReader) GetLogs(ctx context.Context, params LogQueryParams) (*LogListResult, error) {
+	result, err := r.Reader.GetLogs(ctx, params)
+	if err != nil || result == nil {
+		return result, err
+	}
+	r.openEntries(result.Entries)
+	return result, nil
+}
+
+// GetLogByID returns an entry with its sealed fields decrypted.
+func (r *DecryptingReader) GetLogByID(ctx context.Context, id string) (*LogEntry, error) {
+	entry, err := r.Reader.GetLogByID(ctx, id)
+	r.openEntry(entry)
+	return entry, err
+}
+
+// GetLogByResponseID returns an entry with its sealed fields decrypted.
+func (r *DecryptingReader) GetLogByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
+	entry, err := r.Reader.GetLogByResponseID(ctx, responseID)
+	r.openEntry(entry)
+	return entry, err
+}
+
+// GetConversation returns a conversation thread with its entries decrypted.
+// Threads are linked through request and response bodies, so sealed entries
+// end a thread.
+func (r *DecryptingReader) GetConversation(ctx context.Context, logID string, limit int) (*ConversationResult, error) {
+	result, err := r.Reader.GetConversation(ctx, logID, limit)
+	if err != nil || result == nil {
+		return result, err
+	}
+	r.openEntries(result.Entries)
+	return result, nil
+}
+
+// RedactLog redacts an entry and returns it decrypted.
+func (r *DecryptingReader) RedactLog(ctx context.Context, id, actor string) (*LogEntry, error) {
+	redactor, ok := r.Reader.(Redactor)
+	if !ok {
+		return nil, ErrRedactionUnsupported
+	}
+	entry, err := redactor.RedactLog(ctx, id, actor)
+	r.openEntry(entry)
+	return entry, err
+}
+
+// DeleteLog removes an entry.
+func (r *DecryptingReader) DeleteLog(ctx context.Context, id, actor string) error {
+	redactor, ok := r.Reader.(Redactor)
+	if !ok {
+		return ErrRedactionUnsupported
+	}
+	return redactor.DeleteLog(ctx, id, actor)
+}
+
+// ReEncrypt rewraps the data keys of up to limit entries sealed with an older
+// key so they use the active key, starting after the cursor after. Callers
+// repeat it with the returned Next cursor until Done.
+func (r *DecryptingReader) ReEncrypt(ctx context.Context, after string, limit int) (*ReEncryptResult, error) {
+	if r.keyring == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	store, ok := r.Reader.(sealedEntryStore)
+	if !ok {
+		return nil, fmt.Errorf("audit log storage does not support re-encryption")
+	}
+	if limit <= 0 {
+		limit = DefaultReEncryptLimit
+	}
+	limit = min(limit, MaxReEncryptLimit)
+
+	active := r.keyring.ActiveKeyID()
+	entries, err := store.listSealed(ctx, active, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReEncryptResult{ActiveKeyID: active, Failures: []ReEncryptFailure{}}
+	for _, entry := range entries {
+		rewrapped, err := r.keyring.Rewrap(entry.Sealed)
+		if err != nil {
+			result.Failures = append(result.Failures, ReEncryptFailure{ID: entry.ID, Error: err.Error()})
+			continue
+		}
+		updated, err := store.updateSealed(ctx, entry.ID, entry.Sealed.KeyID, rewrapped)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			result.Rewrapped++
+		}
+	}
+	if len(entries) < limit {
+		result.Done = true
+	} else {
+		result.Next = entries[len(entries)-1].ID
+	}
+	return result, nil
+}
+
+func (r *DecryptingReader) openEntries(entries []LogEntry) {
+	for i := range entries {
+		r.openEntry(&entries[i])
+	}
+}
+
+func (r *DecryptingReader) openEntry(entry *LogEntry) {
+	if err := r.keyring.Open(entry); err != nil {
+		auditLogger.Warn("failed to decrypt audit log entry", "id", entry.ID, "error", err)
+	}
+}
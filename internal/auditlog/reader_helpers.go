@@ -2,6 +2,9 @@ package auditlog
 
 import (
 	"strings"
+
+	"gomodel/internal/core"
+	"gomodel/internal/pagination"
 )
 
 func buildWhereClause(conditions []string) string {
@@ -30,3 +33,31 @@ func clampLimitOffset(limit, offset int) (int, int) {
 	}
 	return limit, offset
 }
+
+// logCursor returns the position params.Cursor continues from, or nil
+// without a cursor, and the fingerprint of params' filters that the next
+// cursor is issued for.
+func logCursor(params LogQueryParams) (*pagination.Cursor, string, error) {
+	encoded := params.Cursor
+	params.Limit, params.Offset, params.Cursor = 0, 0, ""
+	filter := pagination.Fingerprint(params)
+	if encoded == "" {
+		return nil, filter, nil
+	}
+	cursor, err := pagination.Decode(encoded, filter)
+	if err != nil {
+		return nil, "", core.NewInvalidRequestError(err.Error(), err)
+	}
+	return &cursor, filter, nil
+}
+
+// nextLogCursor trims entries fetched with one extra row to limit and
+// returns the cursor after the last one kept, which is empty when nothing
+// followed it. keys holds the sort key of each entry.
+func nextLogCursor(entries []LogEntry, keys []string, limit int, filter string) ([]LogEntry, string) {
+	if len(entries) <= limit {
+		return entries, ""
+	}
+	entries = entries[:limit]
+	return entries, pagination.Cursor{Key: keys[limit-1], ID: entries[limit-1].ID}.Encode(filter)
+}
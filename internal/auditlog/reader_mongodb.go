@@ -2,6 +2,7 @@ package auditlog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"time"
@@ -16,6 +17,7 @@ import (
 // MongoDBReader implements Reader for MongoDB.
 type MongoDBReader struct {
 	collection *mongo.Collection
+	redactions *mongo.Collection
 }
 
 type mongoLogRow struct {
@@ -25,6 +27,7 @@ type mongoLogRow struct {
 	RequestedModel    string    `bson:"requested_model"`
 	LegacyModel       string    `bson:"model"`
 	ResolvedModel     string    `bson:"resolved_model"`
+	ServedModel       string    `bson:"served_model"`
 	Provider          string    `bson:"provider"`
 	ProviderName      string    `bson:"provider_name"`
 	AliasUsed         bool      `bson:"alias_used"`
@@ -40,33 +43,44 @@ type mongoLogRow struct {
 	UserPath          string    `bson:"user_path"`
 	Stream            bool      `bson:"stream"`
 	ErrorType         string    `bson:"error_type"`
+	UpstreamStatus    int       `bson:"upstream_status_code"`
+	UpstreamDuration  int64     `bson:"upstream_duration_ns"`
+	ResponseID        string    `bson:"response_id"`
+	ProviderResponse  string    `bson:"provider_response_id"`
 	Data              *LogData  `bson:"data"`
 }
 
 func (r mongoLogRow) toLogEntry() *LogEntry {
-	return &LogEntry{
-		ID:                r.ID,
-		Timestamp:         r.Timestamp,
-		DurationNs:        r.DurationNs,
-		RequestedModel:    firstNonEmpty(r.RequestedModel, r.LegacyModel),
-		ResolvedModel:     r.ResolvedModel,
-		Provider:          r.Provider,
-		ProviderName:      displayAuditProviderName(r.ProviderName, r.Provider),
-		AliasUsed:         r.AliasUsed,
-		WorkflowVersionID: r.WorkflowVersionID,
-		CacheType:         normalizeCacheType(r.CacheType),
-		StatusCode:        r.StatusCode,
-		RequestID:         r.RequestID,
-		AuthKeyID:         r.AuthKeyID,
-		AuthMethod:        r.AuthMethod,
-		ClientIP:          r.ClientIP,
-		Method:            r.Method,
-		Path:              r.Path,
-		UserPath:          r.UserPath,
-		Stream:            r.Stream,
-		ErrorType:         r.ErrorType,
-		Data:              sanitizeLogData(r.Data),
+	entry := &LogEntry{
+		ID:                 r.ID,
+		Timestamp:          r.Timestamp,
+		DurationNs:         r.DurationNs,
+		RequestedModel:     firstNonEmpty(r.RequestedModel, r.LegacyModel),
+		ResolvedModel:      r.ResolvedModel,
+		ServedModel:        r.ServedModel,
+		Provider:           r.Provider,
+		ProviderName:       displayAuditProviderName(r.ProviderName, r.Provider),
+		AliasUsed:          r.AliasUsed,
+		WorkflowVersionID:  r.WorkflowVersionID,
+		CacheType:          normalizeCacheType(r.CacheType),
+		StatusCode:         r.StatusCode,
+		RequestID:          r.RequestID,
+		AuthKeyID:          r.AuthKeyID,
+		AuthMethod:         r.AuthMethod,
+		ClientIP:           r.ClientIP,
+		Method:             r.Method,
+		Path:               r.Path,
+		UserPath:           r.UserPath,
+		Stream:             r.Stream,
+		ErrorType:          r.ErrorType,
+		UpstreamStatusCode: r.UpstreamStatus,
+		UpstreamDurationNs: r.UpstreamDuration,
+		ResponseID:         r.ResponseID,
+		ProviderResponseID: r.ProviderResponse,
+		Data:               sanitizeLogData(r.Data),
 	}
+	markRedacted(entry)
+	return entry
 }
 
 func sanitizeLogData(data *LogData) *LogData {
@@ -84,7 +98,10 @@ func NewMongoDBReader(database *mongo.Database) (*MongoDBReader, error) {
 	if database == nil {
 		return nil, fmt.Errorf("database is required")
 	}
-	return &MongoDBReader{collection: database.Collection("audit_logs")}, nil
+	return &MongoDBReader{
+		collection: database.Collection("audit_logs"),
+		redactions: database.Collection(redactionLogTable),
+	}, nil
 }
 
 func mongoUserPathMatchFilter(userPath string) bson.E {
@@ -110,6 +127,10 @@ func mongoUserPathMatchFilter(userPath string) bson.E {
 // GetLogs returns a paginated list of audit log entries.
 func (r *MongoDBReader) GetLogs(ctx context.Context, params LogQueryParams) (*LogListResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	after, filter, err := logCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	matchFilters := bson.D{}
 
@@ -167,9 +188,19 @@ func (r *MongoDBReader) GetLogs(ctx context.Context, params LogQueryParams) (*Lo
 			},
 		})
 	}
+	if params.ResponseID != "" {
+		// Wrapped in $and so it does not collide with the other $or filters.
+		matchFilters = append(matchFilters, bson.E{Key: "$and", Value: bson.A{mongoResponseIDFilter(params.ResponseID)}})
+	}
+	if params.RequestID != "" {
+		matchFilters = append(matchFilters, bson.E{Key: "request_id", Value: params.RequestID})
+	}
 	if params.StatusCode != nil {
 		matchFilters = append(matchFilters, bson.E{Key: "status_code", Value: *params.StatusCode})
 	}
+	if params.UpstreamStatusCode != nil {
+		matchFilters = append(matchFilters, bson.E{Key: "upstream_status_code", Value: *params.UpstreamStatusCode})
+	}
 	if params.Stream != nil {
 		matchFilters = append(matchFilters, bson.E{Key: "stream", Value: *params.Stream})
 	}
@@ -196,12 +227,24 @@ func (r *MongoDBReader) GetLogs(ctx context.Context, params LogQueryParams) (*Lo
 		pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchFilters}})
 	}
 
+	// The cursor only narrows the page, not the total.
+	dataStages := bson.A{}
+	if after != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid cursor", err)
+		}
+		offset = 0
+		dataStages = append(dataStages, bson.D{{Key: "$match", Value: mongoKeysetFilter(cursorTime, after.ID)}})
+	}
+	dataStages = append(dataStages,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit + 1}},
+	)
+
 	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.D{
-		{Key: "data", Value: bson.A{
-			bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}}}},
-			bson.D{{Key: "$skip", Value: offset}},
-			bson.D{{Key: "$limit", Value: limit}},
-		}},
+		{Key: "data", Value: dataStages},
 		{Key: "total", Value: bson.A{
 			bson.D{{Key: "$count", Value: "count"}},
 		}},
@@ -236,21 +279,34 @@ func (r *MongoDBReader) GetLogs(ctx context.Context, params LogQueryParams) (*Lo
 	}
 
 	entries := make([]LogEntry, 0, len(facetResult.Data))
+	keys := make([]string, 0, len(facetResult.Data))
 	for _, row := range facetResult.Data {
 		entry := row.toLogEntry()
 		if entry != nil {
 			entries = append(entries, *entry)
+			keys = append(keys, row.Timestamp.UTC().Format(time.RFC3339Nano))
 		}
 	}
 
+	entries, next := nextLogCursor(entries, keys, limit, filter)
 	return &LogListResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
+// mongoKeysetFilter matches the entries sorted after the one at ts with id
+// in newest-first order.
+func mongoKeysetFilter(ts time.Time, id string) bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "timestamp", Value: bson.D{{Key: "$lt", Value: ts}}}},
+		bson.D{{Key: "timestamp", Value: ts}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: id}}}},
+	}}}
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, value := range values {
 		if value != "" {
@@ -275,11 +331,297 @@ func (r *MongoDBReader) GetLogByID(ctx context.Context, id string) (*LogEntry, e
 	return row.toLogEntry(), nil
 }
 
+// GetLogByResponseID returns the earliest audit log entry with the given
+// client-facing or provider-issued response ID.
+func (r *MongoDBReader) GetLogByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	var row mongoLogRow
+	if err := r.collection.FindOne(ctx, mongoResponseIDFilter(responseID), opts).Decode(&row); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query audit log by response id: %w", err)
+	}
+
+	return row.toLogEntry(), nil
+}
+
+func mongoResponseIDFilter(responseID string) bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "response_id", Value: responseID}},
+		bson.D{{Key: "provider_response_id", Value: responseID}},
+	}}}
+}
+
+// RedactLog blanks the bodies and headers of an entry and records the redaction.
+// The update only matches unredacted documents, so concurrent redactions of
+// the same entry record a single event. MongoDB writes to two collections are
+// not atomic without a replica set, so the event is recorded first and
+// removed again when the update fails: a redaction is never left unrecorded.
+func (r *MongoDBReader) RedactLog(ctx context.Context, id, actor string) (*LogEntry, error) {
+	var row mongoLogRow
+	if err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&row); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to query audit log by id: %w", err)
+	}
+	if row.Data != nil && row.Data.Redaction != nil {
+		return nil, ErrAlreadyRedacted
+	}
+
+	now := time.Now().UTC()
+	event := newRedactionEvent(id, RedactionActionRedact, actor, now)
+	if _, err := r.redactions.InsertOne(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to record audit log redact: %w", err)
+	}
+	redacted := redactLogData(row.Data, RedactionSnapshot{RedactedAt: now, RedactedBy: actor})
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "data.redaction", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "data", Value: redacted}}}},
+	)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to redact audit log: %w", err), r.forgetRedactionEvent(ctx, event.ID))
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.Join(ErrAlreadyRedacted, r.forgetRedactionEvent(ctx, event.ID))
+	}
+
+	return r.GetLogByID(ctx, id)
+}
+
+// DeleteLog removes an entry and records the removal. Like RedactLog, it
+// records the event first and removes it again when the delete fails.
+func (r *MongoDBReader) DeleteLog(ctx context.Context, id, actor string) error {
+	event := newRedactionEvent(id, RedactionActionDelete, actor, time.Now())
+	if _, err := r.redactions.InsertOne(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit log delete: %w", err)
+	}
+	result, err := r.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to delete audit log: %w", err), r.forgetRedactionEvent(ctx, event.ID))
+	}
+	if result.DeletedCount == 0 {
+		return errors.Join(ErrNotFound, r.forgetRedactionEvent(ctx, event.ID))
+	}
+	return nil
+}
+
+// forgetRedactionEvent rolls back a recorded event whose write did not happen.
+func (r *MongoDBReader) forgetRedactionEvent(ctx context.Context, eventID string) error {
+	if _, err := r.redactions.DeleteOne(context.WithoutCancel(ctx), bson.D{{Key: "_id", Value: eventID}}); err != nil {
+		return fmt.Errorf("failed to roll back audit log redaction event: %w", err)
+	}
+	return nil
+}
+
 // GetConversation returns a linear conversation thread around a seed log entry.
 func (r *MongoDBReader) GetConversation(ctx context.Context, logID string, limit int) (*ConversationResult, error) {
 	return buildConversationThread(ctx, logID, limit, r.GetLogByID, r.findByResponseID, r.findByPreviousResponseID)
 }
 
+// GetErrorSummary returns aggregated error analytics for the date range.
+func (r *MongoDBReader) GetErrorSummary(ctx context.Context, params ErrorSummaryParams) (*ErrorSummary, error) {
+	interval, ok := NormalizeErrorInterval(params.Interval)
+	if !ok {
+		return nil, fmt.Errorf("unsupported error summary interval %q", params.Interval)
+	}
+
+	match := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "status_code", Value: bson.D{{Key: "$gte", Value: 400}}}},
+		bson.D{{Key: "error_type", Value: bson.D{{Key: "$nin", Value: bson.A{"", nil}}}}},
+	}}}
+	if tsFilter := mongoDateRangeFilter(params.QueryParams); tsFilter != nil {
+		match = append(match, bson.E{Key: "timestamp", Value: tsFilter})
+	}
+	matchStage := bson.D{{Key: "$match", Value: match}}
+
+	summary := &ErrorSummary{Interval: interval, Groups: make([]ErrorGroup, 0), Series: make([]ErrorSeriesPoint, 0)}
+
+	groupPipeline := bson.A{
+		matchStage,
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "provider", Value: mongoFirstNonEmptyExpr("$provider_name", "$provider")},
+				{Key: "model", Value: mongoFirstNonEmptyExpr("$resolved_model", "$requested_model", "$model")},
+				{Key: "error_type", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$error_type", ""}}}},
+				{Key: "status_code", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$status_code", 0}}}},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "count", Value: -1},
+			{Key: "_id.provider", Value: 1},
+			{Key: "_id.model", Value: 1},
+			{Key: "_id.error_type", Value: 1},
+			{Key: "_id.status_code", Value: 1},
+		}}},
+	}
+	groupCursor, err := r.collection.Aggregate(ctx, groupPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate error summary groups: %w", err)
+	}
+	defer groupCursor.Close(ctx)
+	for groupCursor.Next(ctx) {
+		var row struct {
+			ID struct {
+				Provider   string `bson:"provider"`
+				Model      string `bson:"model"`
+				ErrorType  string `bson:"error_type"`
+				StatusCode int    `bson:"status_code"`
+			} `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		if err := groupCursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode error summary group: %w", err)
+		}
+		summary.TotalErrors += row.Count
+		summary.Groups = append(summary.Groups, ErrorGroup{
+			Provider:   row.ID.Provider,
+			Model:      row.ID.Model,
+			ErrorType:  row.ID.ErrorType,
+			StatusCode: row.ID.StatusCode,
+			Count:      row.Count,
+		})
+	}
+	if err := groupCursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error summary groups: %w", err)
+	}
+
+	bucketFormat := "%Y-%m-%d"
+	if interval == ErrorIntervalHourly {
+		bucketFormat = "%Y-%m-%dT%H:00:00Z"
+	}
+	seriesPipeline := bson.A{
+		matchStage,
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
+				{Key: "format", Value: bucketFormat},
+				{Key: "date", Value: "$timestamp"},
+				{Key: "timezone", Value: "UTC"},
+			}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	seriesCursor, err := r.collection.Aggregate(ctx, seriesPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate error summary series: %w", err)
+	}
+	defer seriesCursor.Close(ctx)
+	for seriesCursor.Next(ctx) {
+		var row struct {
+			Bucket string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := seriesCursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode error summary bucket: %w", err)
+		}
+		summary.Series = append(summary.Series, ErrorSeriesPoint{Bucket: row.Bucket, Count: row.Count})
+	}
+	if err := seriesCursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating error summary buckets: %w", err)
+	}
+
+	if topN := clampTopMessages(params.TopMessages); topN > 0 {
+		messageMatch := append(bson.D{}, match...)
+		messageMatch = append(messageMatch, bson.E{Key: "data.error_message", Value: bson.D{{Key: "$nin", Value: bson.A{"", nil}}}})
+		messagePipeline := bson.A{
+			bson.D{{Key: "$match", Value: messageMatch}},
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$data.error_message"},
+				{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}}}},
+			bson.D{{Key: "$limit", Value: errorMessageScanLimit}},
+		}
+		messageCursor, err := r.collection.Aggregate(ctx, messagePipeline)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate error summary messages: %w", err)
+		}
+		defer messageCursor.Close(ctx)
+		raw := make([]ErrorMessageCount, 0)
+		for messageCursor.Next(ctx) {
+			var row struct {
+				Message string `bson:"_id"`
+				Count   int    `bson:"count"`
+			}
+			if err := messageCursor.Decode(&row); err != nil {
+				return nil, fmt.Errorf("failed to decode error summary message: %w", err)
+			}
+			raw = append(raw, ErrorMessageCount{Message: row.Message, Count: row.Count})
+		}
+		if err := messageCursor.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating error summary messages: %w", err)
+		}
+		summary.TopMessages = topErrorMessages(raw, topN)
+	}
+
+	return summary, nil
+}
+
+// mongoFirstNonEmptyExpr returns the first field that is neither missing nor
+// an empty string, or "" when all are empty.
+func mongoFirstNonEmptyExpr(fields ...string) any {
+	var expr any = ""
+	for i := len(fields) - 1; i >= 0; i-- {
+		value := bson.D{{Key: "$ifNull", Value: bson.A{fields[i], ""}}}
+		expr = bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$ne", Value: bson.A{value, ""}}},
+			value,
+			expr,
+		}}}
+	}
+	return expr
+}
+
+// GetGuardrailCanaryStats returns the request and error counts of the
+// applied and skipped arms of a guardrail in canary rollout.
+func (r *MongoDBReader) GetGuardrailCanaryStats(ctx context.Context, params GuardrailCanaryParams) (*GuardrailCanaryStats, error) {
+	match := bson.D{{Key: "data.guardrail_canaries.name", Value: params.Guardrail}}
+	if tsFilter := mongoDateRangeFilter(params.QueryParams); tsFilter != nil {
+		match = append(match, bson.E{Key: "timestamp", Value: tsFilter})
+	}
+	isError := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "$gte", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$status_code", 0}}}, 400}}},
+		bson.D{{Key: "$ne", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$error_type", ""}}}, ""}}},
+	}}}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$unwind", Value: "$data.guardrail_canaries"}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "data.guardrail_canaries.name", Value: params.Guardrail}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$data.guardrail_canaries.applied"},
+			{Key: "requests", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "errors", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{isError, 1, 0}}}}}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate guardrail canary stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var counts []guardrailCanaryCount
+	for cursor.Next(ctx) {
+		var row struct {
+			Applied  bool `bson:"_id"`
+			Requests int  `bson:"requests"`
+			Errors   int  `bson:"errors"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode guardrail canary stats: %w", err)
+		}
+		counts = append(counts, guardrailCanaryCount{Applied: row.Applied, Requests: row.Requests, Errors: row.Errors})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating guardrail canary stats: %w", err)
+	}
+	return newGuardrailCanaryStats(params.Guardrail, counts), nil
+}
+
 func mongoDateRangeFilter(params QueryParams) bson.D {
 	startZero := params.StartDate.IsZero()
 	endZero := params.EndDate.IsZero()
@@ -319,3 +661,50 @@ func (r *MongoDBReader) findFirstByField(ctx context.Context, field string, valu
 
 	return row.toLogEntry(), nil
 }
+
+func (r *MongoDBReader) listSealed(ctx context.Context, keyID, afterID string, limit int) ([]sealedEntry, error) {
+	filter := bson.D{
+		{Key: "data.sealed.key_id", Value: bson.D{{Key: "$exists", Value: true}, {Key: "$ne", Value: keyID}}},
+		{Key: "_id", Value: bson.D{{Key: "$gt", Value: afterID}}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.D{{Key: "data.sealed", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sealed audit logs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []sealedEntry
+	for cursor.Next(ctx) {
+		var row struct {
+			ID   string `bson:"_id"`
+			Data struct {
+				Sealed *SealedFields `bson:"sealed"`
+			} `bson:"data"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode sealed audit log: %w", err)
+		}
+		if row.Data.Sealed != nil {
+			entries = append(entries, sealedEntry{ID: row.ID, Sealed: row.Data.Sealed})
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sealed audit logs: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *MongoDBReader) updateSealed(ctx context.Context, id, previousKeyID string, sealed *SealedFields) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "data.sealed.key_id", Value: previousKeyID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "data.sealed", Value: sealed}}}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update sealed audit log %s: %w", id, err)
+	}
+	return result.ModifiedCount > 0, nil
+}
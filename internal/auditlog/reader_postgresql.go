@@ -3,10 +3,14 @@ package auditlog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"gomodel/internal/core"
 )
 
 // PostgreSQLReader implements Reader for PostgreSQL databases.
@@ -25,6 +29,10 @@ func NewPostgreSQLReader(pool *pgxpool.Pool) (*PostgreSQLReader, error) {
 // GetLogs returns a paginated list of audit log entries.
 func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (*LogListResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	cursor, filter, err := logCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	conditions, args, argIdx := pgDateRangeConditions(params.QueryParams, 1)
 	userPath, err := normalizeAuditUserPathFilter(params.UserPath)
@@ -66,11 +74,26 @@ func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (
 		args = append(args, "%"+escapeLikeWildcards(params.ErrorType)+"%")
 		argIdx++
 	}
+	if params.ResponseID != "" {
+		conditions = append(conditions, fmt.Sprintf("(response_id = $%d OR provider_response_id = $%d)", argIdx, argIdx))
+		args = append(args, params.ResponseID)
+		argIdx++
+	}
+	if params.RequestID != "" {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", argIdx))
+		args = append(args, params.RequestID)
+		argIdx++
+	}
 	if params.StatusCode != nil {
 		conditions = append(conditions, fmt.Sprintf("status_code = $%d", argIdx))
 		args = append(args, *params.StatusCode)
 		argIdx++
 	}
+	if params.UpstreamStatusCode != nil {
+		conditions = append(conditions, fmt.Sprintf("upstream_status_code = $%d", argIdx))
+		args = append(args, *params.UpstreamStatusCode)
+		argIdx++
+	}
 	if params.Stream != nil {
 		conditions = append(conditions, fmt.Sprintf("stream = $%d", argIdx))
 		args = append(args, *params.Stream)
@@ -91,10 +114,22 @@ func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (
 		return nil, fmt.Errorf("failed to count audit log entries: %w", err)
 	}
 
-	dataQuery := fmt.Sprintf(`SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
-		FROM audit_logs%s ORDER BY timestamp DESC LIMIT $%d OFFSET $%d`, where, argIdx, argIdx+1)
-	dataArgs := append(append([]any(nil), args...), limit, offset)
+	dataWhere, dataArgs := where, append([]any(nil), args...)
+	if cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, cursor.Key)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid cursor", err)
+		}
+		offset = 0
+		dataWhere = buildWhereClause(append(append([]string(nil), conditions...), fmt.Sprintf("(timestamp, id) < ($%d, $%d)", argIdx, argIdx+1)))
+		dataArgs = append(dataArgs, cursorTime, cursor.ID)
+		argIdx += 2
+	}
+
+	dataQuery := fmt.Sprintf(`SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
+		FROM audit_logs%s ORDER BY timestamp DESC, id DESC LIMIT $%d OFFSET $%d`, dataWhere, argIdx, argIdx+1)
+	dataArgs = append(dataArgs, limit+1, offset)
 
 	rows, err := r.pool.Query(ctx, dataQuery, dataArgs...)
 	if err != nil {
@@ -103,6 +138,7 @@ func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (
 	defer rows.Close()
 
 	entries := make([]LogEntry, 0)
+	var keys []string
 	for rows.Next() {
 		var e LogEntry
 		var dataJSON *string
@@ -112,9 +148,12 @@ func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (
 		var authKeyID *string
 		var authMethod *string
 		var userPath *string
+		var servedModel *string
+		var responseID *string
+		var providerResponseID *string
 
-		if err := rows.Scan(&e.ID, &e.Timestamp, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &e.Provider, &providerName, &e.AliasUsed, &workflowVersionID, &cacheType, &e.StatusCode,
-			&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &e.Stream, &e.ErrorType, &dataJSON); err != nil {
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &servedModel, &e.Provider, &providerName, &e.AliasUsed, &workflowVersionID, &cacheType, &e.StatusCode,
+			&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &e.Stream, &e.ErrorType, &e.UpstreamStatusCode, &e.UpstreamDurationNs, &responseID, &providerResponseID, &dataJSON); err != nil {
 			return nil, fmt.Errorf("failed to scan audit log row: %w", err)
 		}
 		if workflowVersionID != nil {
@@ -137,35 +176,48 @@ func (r *PostgreSQLReader) GetLogs(ctx context.Context, params LogQueryParams) (
 		if userPath != nil {
 			e.UserPath = *userPath
 		}
+		if servedModel != nil {
+			e.ServedModel = *servedModel
+		}
+		if responseID != nil {
+			e.ResponseID = *responseID
+		}
+		if providerResponseID != nil {
+			e.ProviderResponseID = *providerResponseID
+		}
 
 		if dataJSON != nil && *dataJSON != "" {
 			var data LogData
 			if err := json.Unmarshal([]byte(*dataJSON), &data); err != nil {
-				slog.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
+				auditLogger.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
 			} else {
 				e.Data = &data
 			}
 		}
 
+		markRedacted(&e)
 		entries = append(entries, e)
+		keys = append(keys, e.Timestamp.UTC().Format(time.RFC3339Nano))
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating audit log rows: %w", err)
 	}
 
+	entries, next := nextLogCursor(entries, keys, limit, filter)
 	return &LogListResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
 // GetLogByID returns a single audit log entry by ID.
 func (r *PostgreSQLReader) GetLogByID(ctx context.Context, id string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs WHERE id::text = $1 LIMIT 1`
 
 	rows, err := r.pool.Query(ctx, query, id)
@@ -185,11 +237,188 @@ func (r *PostgreSQLReader) GetLogByID(ctx context.Context, id string) (*LogEntry
 	return entry, nil
 }
 
+// GetLogByResponseID returns the earliest audit log entry with the given
+// client-facing or provider-issued response ID.
+func (r *PostgreSQLReader) GetLogByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
+		FROM audit_logs
+		WHERE response_id = $1 OR provider_response_id = $1
+		ORDER BY timestamp ASC
+		LIMIT 1`
+
+	rows, err := r.pool.Query(ctx, query, responseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log by response id: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scanPostgreSQLLogEntry(rows)
+}
+
+// RedactLog blanks the bodies and headers of an entry and records the redaction.
+func (r *PostgreSQLReader) RedactLog(ctx context.Context, id, actor string) (*LogEntry, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin audit log redaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var dataJSON *string
+	err = tx.QueryRow(ctx, `SELECT data FROM audit_logs WHERE id::text = $1 FOR UPDATE`, id).Scan(&dataJSON)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log by id: %w", err)
+	}
+
+	var data *LogData
+	if dataJSON != nil && *dataJSON != "" {
+		var stored LogData
+		if err := json.Unmarshal([]byte(*dataJSON), &stored); err != nil {
+			// Unreadable data cannot be redacted selectively; replace it whole.
+			auditLogger.Warn("failed to unmarshal audit data JSON for redaction", "id", id, "error", err)
+		} else {
+			data = &stored
+		}
+	}
+	if data != nil && data.Redaction != nil {
+		return nil, ErrAlreadyRedacted
+	}
+
+	now := time.Now().UTC()
+	redacted := redactLogData(data, RedactionSnapshot{RedactedAt: now, RedactedBy: actor})
+	if _, err := tx.Exec(ctx, `UPDATE audit_logs SET data = $1 WHERE id::text = $2`, marshalLogData(redacted, id), id); err != nil {
+		return nil, fmt.Errorf("failed to redact audit log: %w", err)
+	}
+	if err := insertPostgreSQLRedactionEvent(ctx, tx, newRedactionEvent(id, RedactionActionRedact, actor, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit audit log redaction: %w", err)
+	}
+
+	return r.GetLogByID(ctx, id)
+}
+
+// DeleteLog removes an entry and records the removal.
+func (r *PostgreSQLReader) DeleteLog(ctx context.Context, id, actor string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin audit log deletion: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	tag, err := tx.Exec(ctx, `DELETE FROM audit_logs WHERE id::text = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete audit log: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if err := insertPostgreSQLRedactionEvent(ctx, tx, newRedactionEvent(id, RedactionActionDelete, actor, time.Now())); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit audit log deletion: %w", err)
+	}
+	return nil
+}
+
+func insertPostgreSQLRedactionEvent(ctx context.Context, tx pgx.Tx, event RedactionEvent) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO audit_log_redactions (id, log_id, action, actor, timestamp) VALUES ($1, $2, $3, $4, $5)`,
+		event.ID, event.LogID, event.Action, event.Actor, event.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to record audit log %s: %w", event.Action, err)
+	}
+	return nil
+}
+
 // GetConversation returns a linear conversation thread around a seed log entry.
 func (r *PostgreSQLReader) GetConversation(ctx context.Context, logID string, limit int) (*ConversationResult, error) {
 	return buildConversationThread(ctx, logID, limit, r.GetLogByID, r.findByResponseID, r.findByPreviousResponseID)
 }
 
+// GetErrorSummary returns aggregated error analytics for the date range.
+func (r *PostgreSQLReader) GetErrorSummary(ctx context.Context, params ErrorSummaryParams) (*ErrorSummary, error) {
+	interval, ok := NormalizeErrorInterval(params.Interval)
+	if !ok {
+		return nil, fmt.Errorf("unsupported error summary interval %q", params.Interval)
+	}
+
+	bucketExpr := `to_char(timestamp AT TIME ZONE 'UTC', 'YYYY-MM-DD')`
+	if interval == ErrorIntervalHourly {
+		bucketExpr = `to_char(date_trunc('hour', timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD"T"HH24":00:00Z"')`
+	}
+	conditions, args, _ := pgDateRangeConditions(params.QueryParams, 1)
+	queries := buildErrorSummarySQL(conditions, bucketExpr, "data->>'error_message'")
+
+	summary := &ErrorSummary{Interval: interval}
+
+	groupRows, err := r.pool.Query(ctx, queries.groups, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error summary groups: %w", err)
+	}
+	summary.Groups, summary.TotalErrors, err = scanErrorGroups(groupRows)
+	groupRows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	seriesRows, err := r.pool.Query(ctx, queries.series, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error summary series: %w", err)
+	}
+	summary.Series, err = scanErrorSeries(seriesRows)
+	seriesRows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if topN := clampTopMessages(params.TopMessages); topN > 0 {
+		messageRows, err := r.pool.Query(ctx, queries.messages, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query error summary messages: %w", err)
+		}
+		raw, err := scanErrorMessages(messageRows)
+		messageRows.Close()
+		if err != nil {
+			return nil, err
+		}
+		summary.TopMessages = topErrorMessages(raw, topN)
+	}
+
+	return summary, nil
+}
+
+// GetGuardrailCanaryStats returns the request and error counts of the
+// applied and skipped arms of a guardrail in canary rollout.
+func (r *PostgreSQLReader) GetGuardrailCanaryStats(ctx context.Context, params GuardrailCanaryParams) (*GuardrailCanaryStats, error) {
+	conditions, args, argIdx := pgDateRangeConditions(params.QueryParams, 1)
+	conditions = append(conditions, fmt.Sprintf("canary->>'name' = $%d", argIdx))
+	args = append(args, params.Guardrail)
+	query := buildGuardrailCanarySQL(
+		"audit_logs, jsonb_array_elements(data->'guardrail_canaries') AS canary",
+		"(canary->>'applied')::boolean",
+		conditions,
+	)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query guardrail canary stats: %w", err)
+	}
+	defer rows.Close()
+	counts, err := scanGuardrailCanaryCounts(rows)
+	if err != nil {
+		return nil, err
+	}
+	return newGuardrailCanaryStats(params.Guardrail, counts), nil
+}
+
 func pgDateRangeConditions(params QueryParams, argIdx int) (conditions []string, args []any, nextIdx int) {
 	nextIdx = argIdx
 	if !params.StartDate.IsZero() {
@@ -206,8 +435,8 @@ func pgDateRangeConditions(params QueryParams, argIdx int) (conditions []string,
 }
 
 func (r *PostgreSQLReader) findByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs
 		WHERE data->'response_body'->>'id' = $1
 		ORDER BY timestamp ASC
@@ -225,8 +454,8 @@ func (r *PostgreSQLReader) findByResponseID(ctx context.Context, responseID stri
 }
 
 func (r *PostgreSQLReader) findByPreviousResponseID(ctx context.Context, previousResponseID string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs
 		WHERE data->'request_body'->>'previous_response_id' = $1
 		ORDER BY timestamp ASC
@@ -254,9 +483,12 @@ func scanPostgreSQLLogEntry(rows interface {
 	var authKeyID *string
 	var authMethod *string
 	var userPath *string
+	var servedModel *string
+	var responseID *string
+	var providerResponseID *string
 
-	if err := rows.Scan(&e.ID, &e.Timestamp, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &e.Provider, &providerName, &e.AliasUsed, &workflowVersionID, &cacheType, &e.StatusCode,
-		&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &e.Stream, &e.ErrorType, &dataJSON); err != nil {
+	if err := rows.Scan(&e.ID, &e.Timestamp, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &servedModel, &e.Provider, &providerName, &e.AliasUsed, &workflowVersionID, &cacheType, &e.StatusCode,
+		&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &e.Stream, &e.ErrorType, &e.UpstreamStatusCode, &e.UpstreamDurationNs, &responseID, &providerResponseID, &dataJSON); err != nil {
 		return nil, fmt.Errorf("failed to scan audit log row: %w", err)
 	}
 	if workflowVersionID != nil {
@@ -279,15 +511,69 @@ func scanPostgreSQLLogEntry(rows interface {
 	if userPath != nil {
 		e.UserPath = *userPath
 	}
+	if servedModel != nil {
+		e.ServedModel = *servedModel
+	}
+	if responseID != nil {
+		e.ResponseID = *responseID
+	}
+	if providerResponseID != nil {
+		e.ProviderResponseID = *providerResponseID
+	}
 
 	if dataJSON != nil && *dataJSON != "" {
 		var data LogData
 		if err := json.Unmarshal([]byte(*dataJSON), &data); err != nil {
-			slog.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
+			auditLogger.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
 		} else {
 			e.Data = &data
 		}
 	}
+	markRedacted(&e)
 
 	return &e, nil
 }
+
+func (r *PostgreSQLReader) listSealed(ctx context.Context, keyID, afterID string, limit int) ([]sealedEntry, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::text, data->'sealed' FROM audit_logs
+		WHERE data->'sealed'->>'key_id' IS NOT NULL AND data->'sealed'->>'key_id' <> $1 AND id::text > $2
+		ORDER BY id::text LIMIT $3`,
+		keyID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sealed audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []sealedEntry
+	for rows.Next() {
+		var id string
+		var sealedJSON []byte
+		if err := rows.Scan(&id, &sealedJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan sealed audit log: %w", err)
+		}
+		var sealed SealedFields
+		if err := json.Unmarshal(sealedJSON, &sealed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal sealed audit log %s: %w", id, err)
+		}
+		entries = append(entries, sealedEntry{ID: id, Sealed: &sealed})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sealed audit logs: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *PostgreSQLReader) updateSealed(ctx context.Context, id, previousKeyID string, sealed *SealedFields) (bool, error) {
+	sealedJSON, err := json.Marshal(sealed)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal sealed audit log %s: %w", id, err)
+	}
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE audit_logs SET data = jsonb_set(data, '{sealed}', $1::jsonb) WHERE id::text = $2 AND data->'sealed'->>'key_id' = $3`,
+		sealedJSON, id, previousKeyID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update sealed audit log %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
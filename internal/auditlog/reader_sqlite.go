@@ -4,8 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
 	"sort"
 	"time"
 )
@@ -28,6 +28,10 @@ func NewSQLiteReader(db *sql.DB) (*SQLiteReader, error) {
 // GetLogs returns a paginated list of audit log entries.
 func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*LogListResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	cursor, filter, err := logCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	conditions, args := sqliteDateRangeConditions(params.QueryParams)
 	userPath, err := normalizeAuditUserPathFilter(params.UserPath)
@@ -59,10 +63,22 @@ func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*Log
 		conditions = append(conditions, "error_type LIKE ? ESCAPE '\\'")
 		args = append(args, "%"+escapeLikeWildcards(params.ErrorType)+"%")
 	}
+	if params.ResponseID != "" {
+		conditions = append(conditions, "(response_id = ? OR provider_response_id = ?)")
+		args = append(args, params.ResponseID, params.ResponseID)
+	}
+	if params.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, params.RequestID)
+	}
 	if params.StatusCode != nil {
 		conditions = append(conditions, "status_code = ?")
 		args = append(args, *params.StatusCode)
 	}
+	if params.UpstreamStatusCode != nil {
+		conditions = append(conditions, "upstream_status_code = ?")
+		args = append(args, *params.UpstreamStatusCode)
+	}
 	if params.Stream != nil {
 		conditions = append(conditions, "stream = ?")
 		if *params.Stream {
@@ -86,10 +102,19 @@ func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*Log
 		return nil, fmt.Errorf("failed to count audit log entries: %w", err)
 	}
 
-	dataQuery := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
-		FROM audit_logs` + where + ` ORDER BY timestamp DESC LIMIT ? OFFSET ?`
-	dataArgs := append(append([]any(nil), args...), limit, offset)
+	// Keyset pagination compares the stored timestamp text, which is also
+	// what the page is ordered by.
+	dataWhere, dataArgs := where, append([]any(nil), args...)
+	if cursor != nil {
+		offset = 0
+		dataWhere = buildWhereClause(append(append([]string(nil), conditions...), "(timestamp < ? OR (timestamp = ? AND id < ?))"))
+		dataArgs = append(dataArgs, cursor.Key, cursor.Key, cursor.ID)
+	}
+
+	dataQuery := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
+		FROM audit_logs` + dataWhere + ` ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?`
+	dataArgs = append(dataArgs, limit+1, offset)
 
 	rows, err := r.db.QueryContext(ctx, dataQuery, dataArgs...)
 	if err != nil {
@@ -98,6 +123,7 @@ func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*Log
 	defer rows.Close()
 
 	entries := make([]LogEntry, 0)
+	var keys []string
 	for rows.Next() {
 		var e LogEntry
 		var ts string
@@ -110,9 +136,12 @@ func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*Log
 		var authKeyID sql.NullString
 		var authMethod sql.NullString
 		var userPath sql.NullString
+		var servedModel sql.NullString
+		var responseID sql.NullString
+		var providerResponseID sql.NullString
 
-		if err := rows.Scan(&e.ID, &ts, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &e.Provider, &providerName, &aliasUsedInt, &workflowVersionID, &cacheType, &e.StatusCode,
-			&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &streamInt, &e.ErrorType, &dataJSON); err != nil {
+		if err := rows.Scan(&e.ID, &ts, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &servedModel, &e.Provider, &providerName, &aliasUsedInt, &workflowVersionID, &cacheType, &e.StatusCode,
+			&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &streamInt, &e.ErrorType, &e.UpstreamStatusCode, &e.UpstreamDurationNs, &responseID, &providerResponseID, &dataJSON); err != nil {
 			return nil, fmt.Errorf("failed to scan audit log row: %w", err)
 		}
 
@@ -139,35 +168,48 @@ func (r *SQLiteReader) GetLogs(ctx context.Context, params LogQueryParams) (*Log
 		if userPath.Valid {
 			e.UserPath = userPath.String
 		}
+		if servedModel.Valid {
+			e.ServedModel = servedModel.String
+		}
+		if responseID.Valid {
+			e.ResponseID = responseID.String
+		}
+		if providerResponseID.Valid {
+			e.ProviderResponseID = providerResponseID.String
+		}
 
 		if dataJSON != nil && *dataJSON != "" {
 			var data LogData
 			if err := json.Unmarshal([]byte(*dataJSON), &data); err != nil {
-				slog.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
+				auditLogger.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
 			} else {
 				e.Data = &data
 			}
 		}
 
+		markRedacted(&e)
 		entries = append(entries, e)
+		keys = append(keys, ts)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating audit log rows: %w", err)
 	}
 
+	entries, next := nextLogCursor(entries, keys, limit, filter)
 	return &LogListResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
 // GetLogByID returns a single audit log entry by ID.
 func (r *SQLiteReader) GetLogByID(ctx context.Context, id string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs WHERE id = ? LIMIT 1`
 
 	rows, err := r.db.QueryContext(ctx, query, id)
@@ -187,6 +229,117 @@ func (r *SQLiteReader) GetLogByID(ctx context.Context, id string) (*LogEntry, er
 	return entry, nil
 }
 
+// GetLogByResponseID returns the earliest audit log entry with the given
+// client-facing or provider-issued response ID.
+func (r *SQLiteReader) GetLogByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
+		FROM audit_logs
+		WHERE response_id = ? OR provider_response_id = ?
+		ORDER BY timestamp ASC
+		LIMIT 1`
+
+	rows, err := r.db.QueryContext(ctx, query, responseID, responseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log by response id: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, nil
+	}
+	return scanSQLiteLogEntry(rows)
+}
+
+// RedactLog blanks the bodies and headers of an entry and records the redaction.
+func (r *SQLiteReader) RedactLog(ctx context.Context, id, actor string) (*LogEntry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin audit log redaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var dataJSON sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT data FROM audit_logs WHERE id = ?`, id).Scan(&dataJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log by id: %w", err)
+	}
+
+	var data *LogData
+	if dataJSON.Valid && dataJSON.String != "" {
+		var stored LogData
+		if err := json.Unmarshal([]byte(dataJSON.String), &stored); err != nil {
+			// Unreadable data cannot be redacted selectively; replace it whole.
+			auditLogger.Warn("failed to unmarshal audit data JSON for redaction", "id", id, "error", err)
+		} else {
+			data = &stored
+		}
+	}
+	if data != nil && data.Redaction != nil {
+		return nil, ErrAlreadyRedacted
+	}
+
+	now := time.Now().UTC()
+	redacted := redactLogData(data, RedactionSnapshot{RedactedAt: now, RedactedBy: actor})
+	result, err := tx.ExecContext(ctx,
+		`UPDATE audit_logs SET data = ? WHERE id = ? AND (data IS NULL OR json_extract(data, '$.redaction') IS NULL)`,
+		string(marshalLogData(redacted, id)), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact audit log: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, ErrAlreadyRedacted
+	}
+	if err := insertSQLiteRedactionEvent(ctx, tx, newRedactionEvent(id, RedactionActionRedact, actor, now)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit audit log redaction: %w", err)
+	}
+
+	return r.GetLogByID(ctx, id)
+}
+
+// DeleteLog removes an entry and records the removal.
+func (r *SQLiteReader) DeleteLog(ctx context.Context, id, actor string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin audit log deletion: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM audit_logs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete audit log: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete audit log: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	if err := insertSQLiteRedactionEvent(ctx, tx, newRedactionEvent(id, RedactionActionDelete, actor, time.Now())); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log deletion: %w", err)
+	}
+	return nil
+}
+
+func insertSQLiteRedactionEvent(ctx context.Context, tx *sql.Tx, event RedactionEvent) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_log_redactions (id, log_id, action, actor, timestamp) VALUES (?, ?, ?, ?, ?)`,
+		event.ID, event.LogID, event.Action, event.Actor, event.Timestamp.Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to record audit log %s: %w", event.Action, err)
+	}
+	return nil
+}
+
 // GetConversation returns a linear conversation thread around a seed log entry.
 func (r *SQLiteReader) GetConversation(ctx context.Context, logID string, limit int) (*ConversationResult, error) {
 	limit = clampConversationLimit(limit)
@@ -266,6 +419,82 @@ func (r *SQLiteReader) GetConversation(ctx context.Context, logID string, limit
 	}, nil
 }
 
+// GetErrorSummary returns aggregated error analytics for the date range.
+func (r *SQLiteReader) GetErrorSummary(ctx context.Context, params ErrorSummaryParams) (*ErrorSummary, error) {
+	interval, ok := NormalizeErrorInterval(params.Interval)
+	if !ok {
+		return nil, fmt.Errorf("unsupported error summary interval %q", params.Interval)
+	}
+
+	bucketExpr := "strftime('%Y-%m-%d', timestamp)"
+	if interval == ErrorIntervalHourly {
+		bucketExpr = "strftime('%Y-%m-%dT%H:00:00Z', timestamp)"
+	}
+	conditions, args := sqliteDateRangeConditions(params.QueryParams)
+	queries := buildErrorSummarySQL(conditions, bucketExpr, "json_extract(data, '$.error_message')")
+
+	summary := &ErrorSummary{Interval: interval}
+
+	groupRows, err := r.db.QueryContext(ctx, queries.groups, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error summary groups: %w", err)
+	}
+	summary.Groups, summary.TotalErrors, err = scanErrorGroups(groupRows)
+	_ = groupRows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	seriesRows, err := r.db.QueryContext(ctx, queries.series, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query error summary series: %w", err)
+	}
+	summary.Series, err = scanErrorSeries(seriesRows)
+	_ = seriesRows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if topN := clampTopMessages(params.TopMessages); topN > 0 {
+		messageRows, err := r.db.QueryContext(ctx, queries.messages, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query error summary messages: %w", err)
+		}
+		raw, err := scanErrorMessages(messageRows)
+		_ = messageRows.Close()
+		if err != nil {
+			return nil, err
+		}
+		summary.TopMessages = topErrorMessages(raw, topN)
+	}
+
+	return summary, nil
+}
+
+// GetGuardrailCanaryStats returns the request and error counts of the
+// applied and skipped arms of a guardrail in canary rollout.
+func (r *SQLiteReader) GetGuardrailCanaryStats(ctx context.Context, params GuardrailCanaryParams) (*GuardrailCanaryStats, error) {
+	conditions, args := sqliteDateRangeConditions(params.QueryParams)
+	conditions = append(conditions, "json_extract(canary.value, '$.name') = ?")
+	args = append(args, params.Guardrail)
+	query := buildGuardrailCanarySQL(
+		"audit_logs, json_each(audit_logs.data, '$.guardrail_canaries') AS canary",
+		"json_extract(canary.value, '$.applied')",
+		conditions,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query guardrail canary stats: %w", err)
+	}
+	defer rows.Close()
+	counts, err := scanGuardrailCanaryCounts(rows)
+	if err != nil {
+		return nil, err
+	}
+	return newGuardrailCanaryStats(params.Guardrail, counts), nil
+}
+
 func sqliteDateRangeConditions(params QueryParams) (conditions []string, args []any) {
 	if !params.StartDate.IsZero() {
 		conditions = append(conditions, "timestamp >= ?")
@@ -293,13 +522,13 @@ func parseSQLTimestamp(ts string, entryID string) time.Time {
 		return t
 	}
 
-	slog.Warn("failed to parse audit timestamp", "id", entryID, "raw_timestamp", ts)
+	auditLogger.Warn("failed to parse audit timestamp", "id", entryID, "raw_timestamp", ts)
 	return time.Time{}
 }
 
 func (r *SQLiteReader) findByResponseID(ctx context.Context, responseID string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs
 		WHERE json_extract(data, '$.response_body.id') = ?
 		ORDER BY timestamp ASC
@@ -317,8 +546,8 @@ func (r *SQLiteReader) findByResponseID(ctx context.Context, responseID string)
 }
 
 func (r *SQLiteReader) findByPreviousResponseID(ctx context.Context, previousResponseID string) (*LogEntry, error) {
-	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
-		client_ip, method, path, user_path, stream, error_type, data
+	query := `SELECT id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code, request_id, auth_key_id, auth_method,
+		client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data
 		FROM audit_logs
 		WHERE json_extract(data, '$.request_body.previous_response_id') = ?
 		ORDER BY timestamp ASC
@@ -347,9 +576,12 @@ func scanSQLiteLogEntry(rows *sql.Rows) (*LogEntry, error) {
 	var authKeyID sql.NullString
 	var authMethod sql.NullString
 	var userPath sql.NullString
+	var servedModel sql.NullString
+	var responseID sql.NullString
+	var providerResponseID sql.NullString
 
-	if err := rows.Scan(&e.ID, &ts, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &e.Provider, &providerName, &aliasUsedInt, &workflowVersionID, &cacheType, &e.StatusCode,
-		&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &streamInt, &e.ErrorType, &dataJSON); err != nil {
+	if err := rows.Scan(&e.ID, &ts, &e.DurationNs, &e.RequestedModel, &e.ResolvedModel, &servedModel, &e.Provider, &providerName, &aliasUsedInt, &workflowVersionID, &cacheType, &e.StatusCode,
+		&e.RequestID, &authKeyID, &authMethod, &e.ClientIP, &e.Method, &e.Path, &userPath, &streamInt, &e.ErrorType, &e.UpstreamStatusCode, &e.UpstreamDurationNs, &responseID, &providerResponseID, &dataJSON); err != nil {
 		return nil, fmt.Errorf("failed to scan audit log row: %w", err)
 	}
 
@@ -376,15 +608,72 @@ func scanSQLiteLogEntry(rows *sql.Rows) (*LogEntry, error) {
 	if userPath.Valid {
 		e.UserPath = userPath.String
 	}
+	if servedModel.Valid {
+		e.ServedModel = servedModel.String
+	}
+	if responseID.Valid {
+		e.ResponseID = responseID.String
+	}
+	if providerResponseID.Valid {
+		e.ProviderResponseID = providerResponseID.String
+	}
 
 	if dataJSON != nil && *dataJSON != "" {
 		var data LogData
 		if err := json.Unmarshal([]byte(*dataJSON), &data); err != nil {
-			slog.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
+			auditLogger.Warn("failed to unmarshal audit data JSON", "id", e.ID, "error", err)
 		} else {
 			e.Data = &data
 		}
 	}
+	markRedacted(&e)
 
 	return &e, nil
 }
+
+func (r *SQLiteReader) listSealed(ctx context.Context, keyID, afterID string, limit int) ([]sealedEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, json_extract(data, '$.sealed') FROM audit_logs
+		WHERE json_extract(data, '$.sealed.key_id') IS NOT NULL AND json_extract(data, '$.sealed.key_id') != ? AND id > ?
+		ORDER BY id LIMIT ?`,
+		keyID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sealed audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []sealedEntry
+	for rows.Next() {
+		var id, sealedJSON string
+		if err := rows.Scan(&id, &sealedJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan sealed audit log: %w", err)
+		}
+		var sealed SealedFields
+		if err := json.Unmarshal([]byte(sealedJSON), &sealed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal sealed audit log %s: %w", id, err)
+		}
+		entries = append(entries, sealedEntry{ID: id, Sealed: &sealed})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sealed audit logs: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *SQLiteReader) updateSealed(ctx context.Context, id, previousKeyID string, sealed *SealedFields) (bool, error) {
+	sealedJSON, err := json.Marshal(sealed)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal sealed audit log %s: %w", id, err)
+	}
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE audit_logs SET data = json_set(data, '$.sealed', json(?)) WHERE id = ? AND json_extract(data, '$.sealed.key_id') = ?`,
+		string(sealedJSON), id, previousKeyID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update sealed audit log %s: %w", id, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update sealed audit log %s: %w", id, err)
+	}
+	return rows > 0, nil
+}
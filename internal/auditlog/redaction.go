@@ -0,0 +1,95 @@
+package auditlog
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned when an audit log entry does not exist.
+	ErrNotFound = errors.New("audit log entry not found")
+
+	// ErrAlreadyRedacted is returned when redacting an entry that was already redacted.
+	ErrAlreadyRedacted = errors.New("audit log entry already redacted")
+
+	// ErrRedactionUnsupported is returned by the reader wrappers when the
+	// reader they wrap is not a Redactor.
+	ErrRedactionUnsupported = errors.New("audit log storage does not support redaction")
+)
+
+// RedactedMarker replaces request and response bodies of redacted entries.
+const RedactedMarker = "[REDACTED]"
+
+// Redaction log actions.
+const (
+	RedactionActionRedact = "redact"
+	RedactionActionDelete = "delete"
+)
+
+// redactionLogTable is the table (or MongoDB collection) that records every
+// redaction and removal of an audit log entry.
+const redactionLogTable = "audit_log_redactions"
+
+// RedactionSnapshot records who redacted an entry and when.
+// RedactedBy is the API key hash of the acting administrator.
+type RedactionSnapshot struct {
+	RedactedAt time.Time `json:"redacted_at" bson:"redacted_at"`
+	RedactedBy string    `json:"redacted_by,omitempty" bson:"redacted_by,omitempty"`
+}
+
+// RedactionEvent is one row of the redaction log. The log outlives the
+// entries it refers to, so removals stay on record after the entry is gone.
+type RedactionEvent struct {
+	ID        string    `json:"id" bson:"_id"`
+	LogID     string    `json:"log_id" bson:"log_id"`
+	Action    string    `json:"action" bson:"action"`
+	Actor     string    `json:"actor,omitempty" bson:"actor,omitempty"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+}
+
+func newRedactionEvent(logID, action, actor string, at time.Time) RedactionEvent {
+	return RedactionEvent{
+		ID:        uuid.NewString(),
+		LogID:     logID,
+		Action:    action,
+		Actor:     actor,
+		Timestamp: at.UTC(),
+	}
+}
+
+// redactLogData returns a copy of data with captured bodies replaced by
+// RedactedMarker and captured headers and the raw user dropped. Everything
+// else, including the error message, request parameters and user hash, is
+// kept for the record.
+func redactLogData(data *LogData, snapshot RedactionSnapshot) *LogData {
+	var redacted LogData
+	if data != nil {
+		redacted = *data
+	}
+	if redacted.RequestBody != nil {
+		redacted.RequestBody = RedactedMarker
+	}
+	if redacted.ResponseBody != nil {
+		redacted.ResponseBody = RedactedMarker
+	}
+	if redacted.Sealed != nil {
+		redacted.RequestBody = RedactedMarker
+		redacted.ResponseBody = RedactedMarker
+		redacted.Sealed = nil
+	}
+	redacted.RequestHeaders = nil
+	redacted.ResponseHeaders = nil
+	redacted.User = ""
+	redacted.Redaction = &snapshot
+	return &redacted
+}
+
+// markRedacted sets LogEntry.Redacted from the stored redaction snapshot.
+func markRedacted(entry *LogEntry) {
+	if entry == nil {
+		return
+	}
+	entry.Redacted = entry.Data != nil && entry.Data.Redaction != nil
+}
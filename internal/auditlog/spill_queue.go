@@ -0,0 +1,273 @@
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics for the audit log spill queue.
+var (
+	auditLogSpilledEntries = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gomodel_audit_log_spilled_entries_total",
+			Help: "Total number of audit log entries written to the on-disk spill queue after a failed store write",
+		},
+	)
+	auditLogReplayedEntries = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gomodel_audit_log_replayed_entries_total",
+			Help: "Total number of spilled audit log entries replayed into the store",
+		},
+	)
+	auditLogDroppedEntries = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gomodel_audit_log_dropped_entries_total",
+			Help: "Total number of audit log entries dropped because the store and the spill queue could not accept them",
+		},
+	)
+)
+
+const spillSegmentSuffix = ".jsonl"
+
+// spillSegment is one spilled batch on disk. Segment files are named
+// <sequence>-<entry count>.jsonl so the queue can be rebuilt after a restart
+// without reading every file.
+type spillSegment struct {
+	seq   uint64
+	count int
+	size  int64
+}
+
+func (s spillSegment) name() string {
+	return fmt.Sprintf("%020d-%d%s", s.seq, s.count, spillSegmentSuffix)
+}
+
+// spillQueue is a size-capped FIFO of audit log batches stored as JSON lines
+// in a local directory. When the cap is exceeded the oldest batches are
+// dropped. Segments left behind by a previous process are picked up again.
+type spillQueue struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	segments []spillSegment
+	size     int64
+	nextSeq  uint64
+	dropped  int64
+}
+
+func openSpillQueue(dir string, maxBytes int64) (*spillQueue, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("spill directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spill directory: %w", err)
+	}
+
+	q := &spillQueue{dir: dir, maxBytes: maxBytes}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		segment, ok := parseSpillSegmentName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		segment.size = info.Size()
+		q.segments = append(q.segments, segment)
+		q.size += segment.size
+		q.nextSeq = max(q.nextSeq, segment.seq+1)
+	}
+	slices.SortFunc(q.segments, func(a, b spillSegment) int { return cmp.Compare(a.seq, b.seq) })
+	return q, nil
+}
+
+func parseSpillSegmentName(name string) (spillSegment, bool) {
+	base, ok := strings.CutSuffix(name, spillSegmentSuffix)
+	if !ok {
+		return spillSegment{}, false
+	}
+	seqPart, countPart, ok := strings.Cut(base, "-")
+	if !ok {
+		return spillSegment{}, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return spillSegment{}, false
+	}
+	count, err := strconv.Atoi(countPart)
+	if err != nil || count < 0 {
+		return spillSegment{}, false
+	}
+	return spillSegment{seq: seq, count: count}, true
+}
+
+// Push appends a batch to the tail of the queue, evicting the oldest batches
+// when the size cap would be exceeded. It returns the number of entries
+// evicted to make room.
+func (q *spillQueue) Push(batch []*LogEntry) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range batch {
+		if err := enc.Encode(entry); err != nil {
+			return 0, fmt.Errorf("failed to encode spilled audit log entry: %w", err)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	size := int64(buf.Len())
+	if q.maxBytes > 0 && size > q.maxBytes {
+		return 0, fmt.Errorf("batch of %d bytes exceeds spill queue limit of %d bytes", size, q.maxBytes)
+	}
+
+	evicted := 0
+	for q.maxBytes > 0 && len(q.segments) > 0 && q.size+size > q.maxBytes {
+		oldest := q.segments[0]
+		if err := q.removeLocked(oldest); err != nil {
+			return evicted, err
+		}
+		evicted += oldest.count
+	}
+	q.dropped += int64(evicted)
+
+	segment := spillSegment{seq: q.nextSeq, count: len(batch), size: size}
+	path := filepath.Join(q.dir, segment.name())
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return evicted, fmt.Errorf("failed to write spill segment: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return evicted, fmt.Errorf("failed to commit spill segment: %w", err)
+	}
+	q.nextSeq++
+	q.segments = append(q.segments, segment)
+	q.size += size
+	return evicted, nil
+}
+
+// Peek returns the oldest spilled batch without removing it. ok is false when
+// the queue is empty. A segment that cannot be decoded is discarded and
+// returned together with corrupt so replay can move past it.
+func (q *spillQueue) Peek() (segment spillSegment, batch []*LogEntry, ok bool, corrupt error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.segments) == 0 {
+		return spillSegment{}, nil, false, nil
+	}
+	segment = q.segments[0]
+	batch, err := readSpillSegment(filepath.Join(q.dir, segment.name()))
+	if err == nil {
+		return segment, batch, true, nil
+	}
+	corrupt = fmt.Errorf("discarded unreadable spill segment %s: %w", segment.name(), err)
+	if removeErr := q.removeLocked(segment); removeErr != nil {
+		// Forget the segment even though its file stays on disk, so replay
+		// moves past it instead of reading it again on every pass. It is
+		// picked up again after a restart.
+		q.forgetLocked(segment)
+		corrupt = errors.Join(corrupt, removeErr)
+	}
+	q.dropped += int64(segment.count)
+	return segment, nil, false, corrupt
+}
+
+// Remove deletes a segment previously returned by Peek.
+func (q *spillQueue) Remove(segment spillSegment) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.removeLocked(segment)
+}
+
+func (q *spillQueue) removeLocked(segment spillSegment) error {
+	idx := slices.IndexFunc(q.segments, func(s spillSegment) bool { return s.seq == segment.seq })
+	if idx < 0 {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(q.dir, segment.name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove spill segment: %w", err)
+	}
+	q.forgetLocked(segment)
+	return nil
+}
+
+// forgetLocked drops a segment from the in-memory queue without touching
+// its file.
+func (q *spillQueue) forgetLocked(segment spillSegment) {
+	idx := slices.IndexFunc(q.segments, func(s spillSegment) bool { return s.seq == segment.seq })
+	if idx < 0 {
+		return
+	}
+	q.segments = slices.Delete(q.segments, idx, idx+1)
+	q.size -= segment.size
+}
+
+// Len returns the number of spilled entries waiting for replay.
+func (q *spillQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	total := 0
+	for _, segment := range q.segments {
+		total += segment.count
+	}
+	return total
+}
+
+// Dropped returns the number of entries evicted or discarded since open.
+func (q *spillQueue) Dropped() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+func readSpillSegment(path string) ([]*LogEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var batch []*LogEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, err
+		}
+		batch = append(batch, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
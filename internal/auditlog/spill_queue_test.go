@@ -0,0 +1,169 @@
+package auditlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func spillBatch(from, to int) []*LogEntry {
+	batch := make([]*LogEntry, 0, to-from)
+	for i := from; i < to; i++ {
+		batch = append(batch, &LogEntry{ID: fmt.Sprintf("entry-%04d", i), Timestamp: time.Unix(0, 0).UTC()})
+	}
+	return batch
+}
+
+func TestSpillQueue_FIFOAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openSpillQueue(dir, 0)
+	if err != nil {
+		t.Fatalf("openSpillQueue() error = %v", err)
+	}
+	for i := range 3 {
+		if _, err := q.Push(spillBatch(i*10, i*10+10)); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	reopened, err := openSpillQueue(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	if got := reopened.Len(); got != 30 {
+		t.Fatalf("Len() = %d, want 30", got)
+	}
+	if _, err := reopened.Push(spillBatch(30, 35)); err != nil {
+		t.Fatalf("Push() after reopen error = %v", err)
+	}
+
+	next := 0
+	for {
+		segment, batch, ok, corrupt := reopened.Peek()
+		if corrupt != nil {
+			t.Fatalf("Peek() corrupt = %v", corrupt)
+		}
+		if !ok {
+			break
+		}
+		for _, entry := range batch {
+			if want := fmt.Sprintf("entry-%04d", next); entry.ID != want {
+				t.Fatalf("entry = %s, want %s", entry.ID, want)
+			}
+			next++
+		}
+		if err := reopened.Remove(segment); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+	}
+	if next != 35 {
+		t.Fatalf("replayed %d entries, want 35", next)
+	}
+}
+
+func TestSpillQueue_EvictsOldestWhenFull(t *testing.T) {
+	probe, err := openSpillQueue(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("openSpillQueue() error = %v", err)
+	}
+	if _, err := probe.Push(spillBatch(0, 5)); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	batchSize := probe.size
+
+	// Room for two batches of five entries.
+	q, err := openSpillQueue(t.TempDir(), batchSize*2)
+	if err != nil {
+		t.Fatalf("openSpillQueue() error = %v", err)
+	}
+	for i := range 4 {
+		evicted, err := q.Push(spillBatch(i*5, i*5+5))
+		if err != nil {
+			t.Fatalf("Push(%d) error = %v", i, err)
+		}
+		wantEvicted := 0
+		if i >= 2 {
+			wantEvicted = 5
+		}
+		if evicted != wantEvicted {
+			t.Fatalf("Push(%d) evicted %d, want %d", i, evicted, wantEvicted)
+		}
+	}
+
+	if got := q.Len(); got != 10 {
+		t.Fatalf("Len() = %d, want 10", got)
+	}
+	if got := q.Dropped(); got != 10 {
+		t.Fatalf("Dropped() = %d, want 10", got)
+	}
+	_, batch, ok, _ := q.Peek()
+	if !ok || batch[0].ID != "entry-0010" {
+		t.Fatalf("oldest remaining batch starts at %v, want entry-0010", batch)
+	}
+
+	if _, err := q.Push(spillBatch(0, 50)); err == nil {
+		t.Fatal("Push() of a batch larger than the cap succeeded")
+	}
+}
+
+func TestSpillQueue_DiscardsUnreadableSegment(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openSpillQueue(dir, 0)
+	if err != nil {
+		t.Fatalf("openSpillQueue() error = %v", err)
+	}
+	if _, err := q.Push(spillBatch(0, 2)); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := q.Push(spillBatch(2, 4)); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, q.segments[0].name()), []byte("{not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	segment, _, ok, corrupt := q.Peek()
+	if corrupt == nil || ok || segment.count != 2 {
+		t.Fatalf("Peek() = segment %+v ok %v corrupt %v, want corrupt first segment", segment, ok, corrupt)
+	}
+	_, batch, ok, corrupt := q.Peek()
+	if corrupt != nil || !ok || batch[0].ID != "entry-0002" {
+		t.Fatalf("Peek() after discard = %v ok %v corrupt %v", batch, ok, corrupt)
+	}
+}
+
+func TestSpillQueue_SkipsUnreadableSegmentThatCannotBeRemoved(t *testing.T) {
+	dir := t.TempDir()
+	q, err := openSpillQueue(dir, 0)
+	if err != nil {
+		t.Fatalf("openSpillQueue() error = %v", err)
+	}
+	if _, err := q.Push(spillBatch(0, 2)); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := q.Push(spillBatch(2, 4)); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	// A non-empty directory in place of the segment can be neither read nor
+	// removed.
+	path := filepath.Join(dir, q.segments[0].name())
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "child"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok, corrupt := q.Peek(); corrupt == nil || ok {
+		t.Fatalf("Peek() ok %v corrupt %v, want corrupt first segment", ok, corrupt)
+	}
+	_, batch, ok, corrupt := q.Peek()
+	if corrupt != nil || !ok || batch[0].ID != "entry-0002" {
+		t.Fatalf("Peek() after discard = %v ok %v corrupt %v", batch, ok, corrupt)
+	}
+	if got := q.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+}
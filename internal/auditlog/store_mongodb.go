@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -70,6 +69,9 @@ func NewMongoDBStore(database *mongo.Database, retentionDays int) (*MongoDBStore
 		{
 			Keys: bson.D{{Key: "status_code", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "status_code", Value: 1}, {Key: "timestamp", Value: -1}},
+		},
 		{
 			Keys: bson.D{{Key: "provider", Value: 1}},
 		},
@@ -97,12 +99,25 @@ func NewMongoDBStore(database *mongo.Database, retentionDays int) (*MongoDBStore
 		{
 			Keys: bson.D{{Key: "error_type", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "upstream_status_code", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "response_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "provider_response_id", Value: 1}},
+		},
 		{
 			Keys: bson.D{{Key: "data.response_body.id", Value: 1}},
 		},
 		{
 			Keys: bson.D{{Key: "data.request_body.previous_response_id", Value: 1}},
 		},
+		{
+			// Keyset pagination of the log, newest first.
+			Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}},
+		},
 	}
 
 	// Add timestamp index - use TTL index if retention is configured,
@@ -123,7 +138,13 @@ func NewMongoDBStore(database *mongo.Database, retentionDays int) (*MongoDBStore
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	if err != nil {
 		// Log warning but don't fail - indexes may already exist
-		slog.Warn("failed to create some MongoDB indexes", "error", err)
+		auditLogger.Warn("failed to create some MongoDB indexes", "error", err)
+	}
+
+	if _, err := database.Collection(redactionLogTable).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "log_id", Value: 1}},
+	}); err != nil {
+		auditLogger.Warn("failed to create MongoDB redaction log index", "error", err)
 	}
 
 	return &MongoDBStore{
@@ -152,7 +173,7 @@ func (s *MongoDBStore) WriteBatch(ctx context.Context, entries []*LogEntry) erro
 		if bulkErr, ok := errors.AsType[*mongo.BulkWriteException](err); ok {
 			failedCount := len(bulkErr.WriteErrors)
 			// Log for visibility
-			slog.Warn("partial audit log insert failure",
+			auditLogger.Warn("partial audit log insert failure",
 				"total", len(entries),
 				"failed", failedCount,
 				"succeeded", len(entries)-failedCount,
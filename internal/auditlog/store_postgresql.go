@@ -3,7 +3,6 @@ package auditlog
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,14 +13,14 @@ import (
 )
 
 const (
-	auditLogInsertColumnCount     = 21
+	auditLogInsertColumnCount     = 26
 	postgresMaxBindParameters     = 65535
 	auditLogInsertMaxRowsPerQuery = postgresMaxBindParameters / auditLogInsertColumnCount
 )
 
 const auditLogInsertPrefix = `
-		INSERT INTO audit_logs (id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code,
-			request_id, auth_key_id, auth_method, client_ip, method, path, user_path, stream, error_type, data)
+		INSERT INTO audit_logs (id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code,
+			request_id, auth_key_id, auth_method, client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data)
 		VALUES `
 
 const auditLogInsertSuffix = `
@@ -48,8 +47,28 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
-	ctx := context.Background()
+	if err := CreatePostgreSQLSchema(context.Background(), pool); err != nil {
+		return nil, err
+	}
+
+	store := &PostgreSQLStore{
+		pool:          pool,
+		retentionDays: retentionDays,
+		stopCleanup:   make(chan struct{}),
+	}
+
+	// Start background cleanup if retention is configured
+	if retentionDays > 0 {
+		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	}
+
+	return store, nil
+}
 
+// CreatePostgreSQLSchema creates or upgrades the audit_logs and
+// audit_log_redactions tables and their indexes. It is idempotent: store
+// constructors run it, and so does the baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	// Create table with commonly-filtered fields as columns
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS audit_logs (
@@ -58,6 +77,7 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 			duration_ns BIGINT DEFAULT 0,
 			requested_model TEXT,
 			resolved_model TEXT,
+			served_model TEXT,
 			provider TEXT,
 			provider_name TEXT,
 			alias_used BOOLEAN DEFAULT FALSE,
@@ -73,15 +93,19 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 			user_path TEXT,
 			stream BOOLEAN DEFAULT FALSE,
 			error_type TEXT,
+			upstream_status_code INTEGER DEFAULT 0,
+			upstream_duration_ns BIGINT DEFAULT 0,
+			response_id TEXT,
+			provider_response_id TEXT,
 			data JSONB
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create audit_logs table: %w", err)
+		return fmt.Errorf("failed to create audit_logs table: %w", err)
 	}
 
 	if err := renamePostgreSQLAuditColumn(ctx, pool, "audit_logs", "model", "requested_model"); err != nil {
-		return nil, fmt.Errorf("failed to rename audit_logs.model to requested_model: %w", err)
+		return fmt.Errorf("failed to rename audit_logs.model to requested_model: %w", err)
 	}
 
 	migrations := []string{
@@ -94,19 +118,26 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS auth_key_id TEXT",
 		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS auth_method TEXT",
 		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS user_path TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS upstream_status_code INTEGER DEFAULT 0",
+		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS upstream_duration_ns BIGINT DEFAULT 0",
+		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS served_model TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS response_id TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS provider_response_id TEXT",
 	}
 	for _, migration := range migrations {
 		if _, err := pool.Exec(ctx, migration); err != nil {
-			return nil, fmt.Errorf("failed to run migration %q: %w", migration, err)
+			return fmt.Errorf("failed to run migration %q: %w", migration, err)
 		}
 	}
 
 	// Create indexes for common queries
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_audit_timestamp ON audit_logs(timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_timestamp_id ON audit_logs(timestamp, id)",
 		"DROP INDEX IF EXISTS idx_audit_model",
 		"CREATE INDEX IF NOT EXISTS idx_audit_requested_model ON audit_logs(requested_model)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_status ON audit_logs(status_code)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_status_timestamp ON audit_logs(status_code, timestamp)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_provider ON audit_logs(provider)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_provider_name ON audit_logs(provider_name)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_workflow_version_id ON audit_logs(workflow_version_id)",
@@ -116,28 +147,34 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		"CREATE INDEX IF NOT EXISTS idx_audit_path ON audit_logs(path)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_user_path ON audit_logs(user_path)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_error_type ON audit_logs(error_type)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_upstream_status ON audit_logs(upstream_status_code)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_response_id_column ON audit_logs(response_id)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_provider_response_id ON audit_logs(provider_response_id)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_response_id ON audit_logs ((data->'response_body'->>'id'))",
 		"CREATE INDEX IF NOT EXISTS idx_audit_previous_response_id ON audit_logs ((data->'request_body'->>'previous_response_id'))",
 		"CREATE INDEX IF NOT EXISTS idx_audit_data_gin ON audit_logs USING GIN (data)",
 	}
 	for _, idx := range indexes {
 		if _, err := pool.Exec(ctx, idx); err != nil {
-			slog.Warn("failed to create index", "error", err)
+			auditLogger.Warn("failed to create index", "error", err)
 		}
 	}
 
-	store := &PostgreSQLStore{
-		pool:          pool,
-		retentionDays: retentionDays,
-		stopCleanup:   make(chan struct{}),
+	if _, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS audit_log_redactions (
+			id UUID PRIMARY KEY,
+			log_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			actor TEXT,
+			timestamp TIMESTAMPTZ NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create audit_log_redactions table: %w", err)
 	}
-
-	// Start background cleanup if retention is configured
-	if retentionDays > 0 {
-		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_audit_redactions_log_id ON audit_log_redactions(log_id)"); err != nil {
+		auditLogger.Warn("failed to create index", "error", err)
 	}
-
-	return store, nil
+	return nil
 }
 
 // WriteBatch writes multiple log entries to PostgreSQL using batch insert.
@@ -158,7 +195,7 @@ func (s *PostgreSQLStore) WriteBatch(ctx context.Context, entries []*LogEntry) e
 // writeBatchSmall uses INSERT for small batches
 func (s *PostgreSQLStore) writeBatchSmall(ctx context.Context, entries []*LogEntry) error {
 	if err := writeAuditLogInsertChunks(ctx, s.pool, entries); err != nil {
-		slog.Warn("failed to insert audit log batch", "error", err, "count", len(entries))
+		auditLogger.Warn("failed to insert audit log batch", "error", err, "count", len(entries))
 		return fmt.Errorf("failed to insert %d audit logs: %w", len(entries), err)
 	}
 	return nil
@@ -173,7 +210,7 @@ func (s *PostgreSQLStore) writeBatchLarge(ctx context.Context, entries []*LogEnt
 	defer tx.Rollback(ctx) //nolint:errcheck
 
 	if err := writeAuditLogInsertChunks(ctx, tx, entries); err != nil {
-		slog.Warn("failed to insert audit log batch in transaction", "error", err, "count", len(entries))
+		auditLogger.Warn("failed to insert audit log batch in transaction", "error", err, "count", len(entries))
 		return fmt.Errorf("failed to insert %d audit logs: %w", len(entries), err)
 	}
 
@@ -233,6 +270,7 @@ func buildAuditLogInsert(entries []*LogEntry) (string, []any) {
 			entry.DurationNs,
 			entry.RequestedModel,
 			entry.ResolvedModel,
+			entry.ServedModel,
 			entry.Provider,
 			entry.ProviderName,
 			entry.AliasUsed,
@@ -248,6 +286,10 @@ func buildAuditLogInsert(entries []*LogEntry) (string, []any) {
 			userPathValue,
 			entry.Stream,
 			entry.ErrorType,
+			entry.UpstreamStatusCode,
+			entry.UpstreamDurationNs,
+			entry.ResponseID,
+			entry.ProviderResponseID,
 			dataJSON,
 		)
 	}
@@ -313,11 +355,11 @@ func (s *PostgreSQLStore) cleanup() {
 
 	result, err := s.pool.Exec(ctx, "DELETE FROM audit_logs WHERE timestamp < $1", cutoff)
 	if err != nil {
-		slog.Error("failed to cleanup old audit logs", "error", err)
+		auditLogger.Error("failed to cleanup old audit logs", "error", err)
 		return
 	}
 
 	if result.RowsAffected() > 0 {
-		slog.Info("cleaned up old audit logs", "deleted", result.RowsAffected())
+		auditLogger.Info("cleaned up old audit logs", "deleted", result.RowsAffected())
 	}
 }
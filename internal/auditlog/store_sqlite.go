@@ -4,19 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log/slog"
 	"strings"
 	"sync"
 	"time"
 )
 
 // SQLite has a default limit of 999 bindable parameters per query (SQLITE_MAX_VARIABLE_NUMBER).
-// With 20 columns per log entry, we can safely insert up to 49 entries per batch (49 * 20 = 980).
+// With 23 columns per log entry, we can safely insert up to 43 entries per batch (43 * 23 = 989).
 // We chunk larger batches to avoid hitting this limit.
 const (
 	maxSQLiteParams    = 999
-	columnsPerEntry    = 21
-	maxEntriesPerBatch = maxSQLiteParams / columnsPerEntry // 49 entries
+	columnsPerEntry    = 26
+	maxEntriesPerBatch = maxSQLiteParams / columnsPerEntry // 38 entries
 )
 
 const sqliteAuditLogTable = "audit_logs"
@@ -37,14 +36,37 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	store := &SQLiteStore{
+		db:            db,
+		retentionDays: retentionDays,
+		stopCleanup:   make(chan struct{}),
+	}
+
+	// Start background cleanup if retention is configured
+	if retentionDays > 0 {
+		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	}
+
+	return store, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the audit_logs and
+// audit_log_redactions tables and their indexes. It is idempotent: store
+// constructors run it, and so does the baseline schema migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
 	// Create table with commonly-filtered fields as columns
-	_, err := db.Exec(`
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS audit_logs (
 			id TEXT PRIMARY KEY,
 			timestamp DATETIME NOT NULL,
 			duration_ns INTEGER DEFAULT 0,
 			requested_model TEXT,
 			resolved_model TEXT,
+			served_model TEXT,
 			provider TEXT,
 			provider_name TEXT,
 			alias_used INTEGER DEFAULT 0,
@@ -60,15 +82,19 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 			user_path TEXT,
 			stream INTEGER DEFAULT 0,
 			error_type TEXT,
+			upstream_status_code INTEGER DEFAULT 0,
+			upstream_duration_ns INTEGER DEFAULT 0,
+			response_id TEXT,
+			provider_response_id TEXT,
 			data JSON
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create audit_logs table: %w", err)
+		return fmt.Errorf("failed to create audit_logs table: %w", err)
 	}
 
 	if err := renameSQLiteAuditColumn(db, sqliteAuditLogTable, "model", "requested_model"); err != nil {
-		return nil, fmt.Errorf("failed to rename audit_logs.model to requested_model: %w", err)
+		return fmt.Errorf("failed to rename audit_logs.model to requested_model: %w", err)
 	}
 
 	migrations := []string{
@@ -81,11 +107,16 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		"ALTER TABLE audit_logs ADD COLUMN auth_key_id TEXT",
 		"ALTER TABLE audit_logs ADD COLUMN auth_method TEXT",
 		"ALTER TABLE audit_logs ADD COLUMN user_path TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN upstream_status_code INTEGER DEFAULT 0",
+		"ALTER TABLE audit_logs ADD COLUMN upstream_duration_ns INTEGER DEFAULT 0",
+		"ALTER TABLE audit_logs ADD COLUMN served_model TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN response_id TEXT",
+		"ALTER TABLE audit_logs ADD COLUMN provider_response_id TEXT",
 	}
 	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
+		if _, err := db.ExecContext(ctx, migration); err != nil {
 			if !strings.Contains(err.Error(), "duplicate column") {
-				return nil, fmt.Errorf("failed to run migration %q: %w", migration, err)
+				return fmt.Errorf("failed to run migration %q: %w", migration, err)
 			}
 		}
 	}
@@ -93,9 +124,11 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 	// Create indexes for common queries
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_audit_timestamp ON audit_logs(timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_timestamp_id ON audit_logs(timestamp, id)",
 		"DROP INDEX IF EXISTS idx_audit_model",
 		"CREATE INDEX IF NOT EXISTS idx_audit_requested_model ON audit_logs(requested_model)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_status ON audit_logs(status_code)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_status_timestamp ON audit_logs(status_code, timestamp)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_provider ON audit_logs(provider)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_provider_name ON audit_logs(provider_name)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_workflow_version_id ON audit_logs(workflow_version_id)",
@@ -105,27 +138,33 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		"CREATE INDEX IF NOT EXISTS idx_audit_path ON audit_logs(path)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_user_path ON audit_logs(user_path)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_error_type ON audit_logs(error_type)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_upstream_status ON audit_logs(upstream_status_code)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_response_id_column ON audit_logs(response_id)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_provider_response_id ON audit_logs(provider_response_id)",
 		"CREATE INDEX IF NOT EXISTS idx_audit_response_id ON audit_logs(json_extract(data, '$.response_body.id'))",
 		"CREATE INDEX IF NOT EXISTS idx_audit_previous_response_id ON audit_logs(json_extract(data, '$.request_body.previous_response_id'))",
 	}
 	for _, idx := range indexes {
-		if _, err := db.Exec(idx); err != nil {
-			slog.Warn("failed to create index", "error", err)
+		if _, err := db.ExecContext(ctx, idx); err != nil {
+			auditLogger.Warn("failed to create index", "error", err)
 		}
 	}
 
-	store := &SQLiteStore{
-		db:            db,
-		retentionDays: retentionDays,
-		stopCleanup:   make(chan struct{}),
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS audit_log_redactions (
+			id TEXT PRIMARY KEY,
+			log_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			actor TEXT,
+			timestamp DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create audit_log_redactions table: %w", err)
 	}
-
-	// Start background cleanup if retention is configured
-	if retentionDays > 0 {
-		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_audit_redactions_log_id ON audit_log_redactions(log_id)"); err != nil {
+		auditLogger.Warn("failed to create index", "error", err)
 	}
-
-	return store, nil
+	return nil
 }
 
 // WriteBatch writes multiple log entries to SQLite using batch insert.
@@ -145,7 +184,7 @@ func (s *SQLiteStore) WriteBatch(ctx context.Context, entries []*LogEntry) error
 		values := make([]any, 0, len(chunk)*columnsPerEntry)
 
 		for j, e := range chunk {
-			placeholders[j] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+			placeholders[j] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 			dataJSON := marshalLogData(e.Data, e.ID)
 
@@ -179,6 +218,7 @@ func (s *SQLiteStore) WriteBatch(ctx context.Context, entries []*LogEntry) error
 				e.DurationNs,
 				e.RequestedModel,
 				e.ResolvedModel,
+				e.ServedModel,
 				e.Provider,
 				e.ProviderName,
 				aliasUsedInt,
@@ -194,12 +234,16 @@ func (s *SQLiteStore) WriteBatch(ctx context.Context, entries []*LogEntry) error
 				userPathValue,
 				streamInt,
 				e.ErrorType,
+				e.UpstreamStatusCode,
+				e.UpstreamDurationNs,
+				e.ResponseID,
+				e.ProviderResponseID,
 				dataValue,
 			)
 		}
 
-		query := `INSERT OR IGNORE INTO audit_logs (id, timestamp, duration_ns, requested_model, resolved_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code,
-			request_id, auth_key_id, auth_method, client_ip, method, path, user_path, stream, error_type, data) VALUES ` +
+		query := `INSERT OR IGNORE INTO audit_logs (id, timestamp, duration_ns, requested_model, resolved_model, served_model, provider, provider_name, alias_used, workflow_version_id, cache_type, status_code,
+			request_id, auth_key_id, auth_method, client_ip, method, path, user_path, stream, error_type, upstream_status_code, upstream_duration_ns, response_id, provider_response_id, data) VALUES ` +
 			strings.Join(placeholders, ",")
 
 		_, err := s.db.ExecContext(ctx, query, values...)
@@ -238,12 +282,12 @@ func (s *SQLiteStore) cleanup() {
 
 	result, err := s.db.Exec("DELETE FROM audit_logs WHERE timestamp < ?", cutoff)
 	if err != nil {
-		slog.Error("failed to cleanup old audit logs", "error", err)
+		auditLogger.Error("failed to cleanup old audit logs", "error", err)
 		return
 	}
 
 	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
-		slog.Info("cleaned up old audit logs", "deleted", rowsAffected)
+		auditLogger.Info("cleaned up old audit logs", "deleted", rowsAffected)
 	}
 }
 
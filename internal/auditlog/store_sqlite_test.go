@@ -3,7 +3,9 @@ package auditlog
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -562,3 +564,249 @@ func TestSQLiteStoreAndReader_PreserveCacheType(t *testing.T) {
 		t.Fatalf("none entry cache_type = %#v, want empty", noneEntry)
 	}
 }
+
+func TestSQLiteStoreAndReader_UpstreamStatusCode(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := store.WriteBatch(ctx, []*LogEntry{
+		{
+			ID:                 "overloaded",
+			Timestamp:          now,
+			RequestedModel:     "claude-sonnet-4",
+			Provider:           "anthropic",
+			StatusCode:         503,
+			UpstreamStatusCode: 529,
+			UpstreamDurationNs: int64(250 * time.Millisecond),
+		},
+		{
+			ID:                 "ok",
+			Timestamp:          now.Add(time.Second),
+			RequestedModel:     "claude-sonnet-4",
+			Provider:           "anthropic",
+			StatusCode:         200,
+			UpstreamStatusCode: 200,
+		},
+	}); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	upstreamStatus := 529
+	logs, err := reader.GetLogs(ctx, LogQueryParams{UpstreamStatusCode: &upstreamStatus, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if len(logs.Entries) != 1 || logs.Entries[0].ID != "overloaded" {
+		t.Fatalf("entries = %#v, want only overloaded", logs.Entries)
+	}
+	entry := logs.Entries[0]
+	if entry.StatusCode != 503 || entry.UpstreamStatusCode != 529 {
+		t.Fatalf("status = %d, upstream status = %d, want 503 and 529", entry.StatusCode, entry.UpstreamStatusCode)
+	}
+	if entry.UpstreamDurationNs != int64(250*time.Millisecond) {
+		t.Fatalf("UpstreamDurationNs = %d, want %d", entry.UpstreamDurationNs, int64(250*time.Millisecond))
+	}
+}
+
+func TestSQLiteReader_RedactLog(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	entry := &LogEntry{
+		ID:             "secret-entry",
+		Timestamp:      time.Now(),
+		RequestedModel: "gpt-5",
+		Provider:       "openai",
+		StatusCode:     200,
+		Data: &LogData{
+			APIKeyHash:      "abc123",
+			ErrorMessage:    "kept",
+			RequestHeaders:  map[string]string{"X-Secret": "sk-live"},
+			ResponseHeaders: map[string]string{"X-Request-Id": "1"},
+			RequestBody:     map[string]any{"messages": []any{"my password is hunter2"}},
+			ResponseBody:    map[string]any{"id": "resp_1"},
+		},
+	}
+	if err := store.WriteBatch(ctx, []*LogEntry{entry}); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	redacted, err := reader.RedactLog(ctx, entry.ID, "adminhash")
+	if err != nil {
+		t.Fatalf("RedactLog failed: %v", err)
+	}
+	if !redacted.Redacted {
+		t.Fatal("Redacted = false, want true")
+	}
+	data := redacted.Data
+	if data == nil || data.RequestBody != RedactedMarker || data.ResponseBody != RedactedMarker {
+		t.Fatalf("bodies were not redacted: %+v", data)
+	}
+	if data.RequestHeaders != nil || data.ResponseHeaders != nil {
+		t.Fatalf("headers were not dropped: %+v / %+v", data.RequestHeaders, data.ResponseHeaders)
+	}
+	if data.Redaction == nil || data.Redaction.RedactedBy != "adminhash" || data.Redaction.RedactedAt.IsZero() {
+		t.Fatalf("Redaction = %+v", data.Redaction)
+	}
+	if data.APIKeyHash != "abc123" || data.ErrorMessage != "kept" || redacted.RequestedModel != "gpt-5" {
+		t.Fatalf("metadata was not kept: %+v", redacted)
+	}
+
+	if _, err := reader.RedactLog(ctx, entry.ID, "adminhash"); !errors.Is(err, ErrAlreadyRedacted) {
+		t.Fatalf("second RedactLog error = %v, want ErrAlreadyRedacted", err)
+	}
+	if _, err := reader.RedactLog(ctx, "missing", "adminhash"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("RedactLog(missing) error = %v, want ErrNotFound", err)
+	}
+
+	listed, err := reader.GetLogs(ctx, LogQueryParams{})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if len(listed.Entries) != 1 || !listed.Entries[0].Redacted {
+		t.Fatalf("GetLogs entries = %+v, want one redacted entry", listed.Entries)
+	}
+
+	assertRedactionEvents(t, db, entry.ID, RedactionActionRedact)
+}
+
+func TestSQLiteReader_DeleteLog(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.WriteBatch(ctx, []*LogEntry{{ID: "to-delete", Timestamp: time.Now(), StatusCode: 200}}); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	if err := reader.DeleteLog(ctx, "to-delete", "adminhash"); err != nil {
+		t.Fatalf("DeleteLog failed: %v", err)
+	}
+	if entry, err := reader.GetLogByID(ctx, "to-delete"); err != nil || entry != nil {
+		t.Fatalf("GetLogByID after delete = %+v, %v; want nil, nil", entry, err)
+	}
+	if err := reader.DeleteLog(ctx, "to-delete", "adminhash"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second DeleteLog error = %v, want ErrNotFound", err)
+	}
+
+	assertRedactionEvents(t, db, "to-delete", RedactionActionDelete)
+}
+
+func assertRedactionEvents(t *testing.T, db *sql.DB, logID string, wantActions ...string) {
+	t.Helper()
+	rows, err := db.Query(`SELECT action, actor FROM audit_log_redactions WHERE log_id = ? ORDER BY timestamp`, logID)
+	if err != nil {
+		t.Fatalf("failed to query redaction log: %v", err)
+	}
+	defer rows.Close()
+
+	var actions []string
+	for rows.Next() {
+		var action, actor string
+		if err := rows.Scan(&action, &actor); err != nil {
+			t.Fatalf("failed to scan redaction log: %v", err)
+		}
+		if actor != "adminhash" {
+			t.Fatalf("redaction log actor = %q, want adminhash", actor)
+		}
+		actions = append(actions, action)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("redaction log rows: %v", err)
+	}
+	if !slices.Equal(actions, wantActions) {
+		t.Fatalf("redaction log actions = %v, want %v", actions, wantActions)
+	}
+}
+
+func TestSQLiteReader_FindsEntriesByResponseID(t *testing.T) {
+	db := createTestDB(t)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now().UTC()
+	err = store.WriteBatch(context.Background(), []*LogEntry{
+		{ID: "chat", Timestamp: now, Provider: "openai", RequestID: "req-1", ResponseID: "chatcmpl-abc"},
+		{ID: "converted", Timestamp: now, Provider: "anthropic", RequestID: "req-2", ResponseID: "resp_123", ProviderResponseID: "msg_01"},
+		{ID: "other", Timestamp: now, Provider: "openai", RequestID: "req-22", ResponseID: "chatcmpl-other"},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed audit logs: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+
+	for responseID, wantID := range map[string]string{"chatcmpl-abc": "chat", "resp_123": "converted", "msg_01": "converted"} {
+		entry, err := reader.GetLogByResponseID(context.Background(), responseID)
+		if err != nil {
+			t.Fatalf("GetLogByResponseID(%q) error = %v", responseID, err)
+		}
+		if entry == nil || entry.ID != wantID {
+			t.Fatalf("GetLogByResponseID(%q) = %+v, want entry %s", responseID, entry, wantID)
+		}
+	}
+	entry, err := reader.GetLogByResponseID(context.Background(), "chatcmpl-missing")
+	if err != nil || entry != nil {
+		t.Fatalf("GetLogByResponseID(missing) = %+v, %v, want nil", entry, err)
+	}
+
+	logs, err := reader.GetLogs(context.Background(), LogQueryParams{ResponseID: "msg_01", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if len(logs.Entries) != 1 || logs.Entries[0].ResponseID != "resp_123" || logs.Entries[0].ProviderResponseID != "msg_01" {
+		t.Fatalf("entries = %+v, want the converted entry with both IDs", logs.Entries)
+	}
+
+	logs, err = reader.GetLogs(context.Background(), LogQueryParams{RequestID: "req-2", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetLogs failed: %v", err)
+	}
+	if len(logs.Entries) != 1 || logs.Entries[0].ID != "converted" {
+		t.Fatalf("entries = %+v, want only the exact request ID match", logs.Entries)
+	}
+}
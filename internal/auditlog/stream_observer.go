@@ -25,8 +25,18 @@ type StreamLogObserver struct {
 	entry     *LogEntry
 	builder   *streamResponseBuilder
 	logBodies bool
-	closed    bool
-	startTime time.Time
+	// sample captures the complete response text of a sampled stream.
+	sample         *streamSampleCapture
+	isResponsesAPI bool
+	closed         bool
+	startTime      time.Time
+	// servedModel is the model reported by the upstream stream chunks.
+	servedModel string
+	// responseID and providerResponseID are taken from the first chunk or
+	// lifecycle event that carries them. Converted streams may only report
+	// the provider-issued ID in their terminal event.
+	responseID         string
+	providerResponseID string
 }
 
 func NewStreamLogObserver(logger LoggerInterface, entry *LogEntry, path string) *StreamLogObserver {
@@ -34,24 +44,44 @@ func NewStreamLogObserver(logger LoggerInterface, entry *LogEntry, path string)
 		return nil
 	}
 
-	logBodies := logger.Config().LogBodies
+	cfg := logger.Config()
+	logBodies := cfg.LogBodies && (entry.Data == nil || !entry.Data.BodyLogOptOut)
+	isResponsesAPI := strings.HasPrefix(path, "/v1/responses")
 	var builder *streamResponseBuilder
 	if logBodies {
 		builder = &streamResponseBuilder{
-			IsResponsesAPI: strings.HasPrefix(path, "/v1/responses"),
+			IsResponsesAPI: isResponsesAPI,
+			maxContent:     int(cfg.BodyCapture.LimitsFor(path).Response),
 		}
 	}
 
 	return &StreamLogObserver{
-		logger:    logger,
-		entry:     entry,
-		builder:   builder,
-		logBodies: logBodies,
-		startTime: entry.Timestamp,
+		logger:         logger,
+		entry:          entry,
+		builder:        builder,
+		logBodies:      logBodies,
+		sample:         streamSamplerFor(logger).begin(entry),
+		isResponsesAPI: isResponsesAPI,
+		startTime:      entry.Timestamp,
 	}
 }
 
 func (o *StreamLogObserver) OnJSONEvent(event map[string]any) {
+	if model := servedModelFromStreamEvent(event); model != "" {
+		o.servedModel = model
+	}
+	if o.responseID == "" || o.providerResponseID == "" {
+		responseID, providerResponseID := responseIDsFromStreamEvent(event)
+		if o.responseID == "" {
+			o.responseID = responseID
+		}
+		if o.providerResponseID == "" {
+			o.providerResponseID = providerResponseID
+		}
+	}
+	if o.sample != nil {
+		o.sample.observe(o.isResponsesAPI, event)
+	}
 	if !o.logBodies || o.builder == nil {
 		return
 	}
@@ -67,6 +97,10 @@ func (o *StreamLogObserver) OnStreamClose() {
 	if o.entry != nil && !o.startTime.IsZero() {
 		o.entry.DurationNs = time.Since(o.startTime).Nanoseconds()
 	}
+	if o.entry != nil && o.servedModel != "" {
+		o.entry.ServedModel = o.servedModel
+	}
+	enrichEntryWithResponseID(o.entry, o.responseID, o.providerResponseID)
 
 	if o.logBodies && o.builder != nil && o.entry != nil && o.entry.Data != nil {
 		if o.builder.IsResponsesAPI {
@@ -77,6 +111,12 @@ func (o *StreamLogObserver) OnStreamClose() {
 		o.entry.Data.ResponseBodyTooBigToHandle = o.builder.truncated
 	}
 
+	if o.sample != nil && o.entry != nil {
+		if sampleID := o.sample.finish(o.entry); sampleID != "" {
+			ensureLogData(o.entry).StreamSampleID = sampleID
+		}
+	}
+
 	if o.logger != nil && o.entry != nil {
 		o.logger.Write(o.entry)
 	}
@@ -88,7 +128,8 @@ func EnrichEntryWithCachedStreamResponse(c *echo.Context, path string, body []by
 	if c == nil || len(body) == 0 {
 		return
 	}
-	if !hasResponseBodyCapture(c.Response()) {
+	capture := findResponseBodyCapture(c.Response())
+	if capture == nil {
 		return
 	}
 
@@ -99,6 +140,7 @@ func EnrichEntryWithCachedStreamResponse(c *echo.Context, path string, body []by
 
 	builder := &streamResponseBuilder{
 		IsResponsesAPI: strings.HasPrefix(path, "/v1/responses"),
+		maxContent:     int(capture.captureLimit()),
 	}
 	observer := &cachedStreamObserver{builder: builder}
 	stream := streaming.NewObservedSSEStream(io.NopCloser(bytes.NewReader(body)), observer)
@@ -127,22 +169,22 @@ func (o *cachedStreamObserver) OnJSONEvent(event map[string]any) {
 
 func (o *cachedStreamObserver) OnStreamClose() {}
 
-func hasResponseBodyCapture(w http.ResponseWriter) bool {
+func findResponseBodyCapture(w http.ResponseWriter) *responseBodyCapture {
 	for depth := 0; w != nil && depth < maxResponseWriterUnwrapDepth; depth++ {
-		if _, ok := w.(*responseBodyCapture); ok {
-			return true
+		if capture, ok := w.(*responseBodyCapture); ok {
+			return capture
 		}
 		unwrapper, ok := w.(responseWriterUnwrapper)
 		if !ok {
-			return false
+			return nil
 		}
 		next := unwrapper.Unwrap()
 		if next == w {
-			return false
+			return nil
 		}
 		w = next
 	}
-	return false
+	return nil
 }
 
 func observeStreamJSONEvent(builder *streamResponseBuilder, event map[string]any) {
@@ -156,6 +198,35 @@ func observeStreamJSONEvent(builder *streamResponseBuilder, event map[string]any
 	parseChatCompletionEvent(builder, event)
 }
 
+// servedModelFromStreamEvent returns the model reported by a chat completion
+// chunk or a Responses API lifecycle event.
+func servedModelFromStreamEvent(event map[string]any) string {
+	if model, ok := event["model"].(string); ok {
+		return model
+	}
+	if resp, ok := event["response"].(map[string]any); ok {
+		if model, ok := resp["model"].(string); ok {
+			return model
+		}
+	}
+	return ""
+}
+
+// responseIDsFromStreamEvent returns the response ID of a chat completion
+// chunk or a Responses API lifecycle event, and the provider-issued ID that
+// converted streams report next to the ID the gateway synthesized.
+func responseIDsFromStreamEvent(event map[string]any) (responseID, providerResponseID string) {
+	if resp, ok := event["response"].(map[string]any); ok {
+		responseID, _ = resp["id"].(string)
+		providerResponseID, _ = resp["provider_response_id"].(string)
+		return responseID, providerResponseID
+	}
+	if _, ok := event["choices"]; ok {
+		responseID, _ = event["id"].(string)
+	}
+	return responseID, ""
+}
+
 func parseChatCompletionEvent(builder *streamResponseBuilder, event map[string]any) {
 	if builder == nil {
 		return
@@ -186,6 +257,9 @@ func parseChatCompletionEvent(builder *streamResponseBuilder, event map[string]a
 				if content, ok := delta["content"].(string); ok && content != "" {
 					appendStreamContent(builder, content)
 				}
+				if annotations, ok := delta["annotations"].([]any); ok {
+					builder.Annotations = append(builder.Annotations, annotations...)
+				}
 			}
 		}
 	}
@@ -217,15 +291,27 @@ func parseResponsesAPIEvent(builder *streamResponseBuilder, event map[string]any
 		if delta, ok := event["delta"].(string); ok && delta != "" {
 			appendStreamContent(builder, delta)
 		}
+	case "response.output_text.annotation.added":
+		if annotation, ok := event["annotation"]; ok && annotation != nil {
+			builder.Annotations = append(builder.Annotations, annotation)
+		}
 	}
 }
 
 func appendStreamContent(builder *streamResponseBuilder, content string) {
-	if builder == nil || builder.truncated || builder.contentLen >= MaxContentCapture {
+	if builder == nil || builder.truncated {
+		return
+	}
+	limit := builder.maxContent
+	if limit <= 0 {
+		limit = MaxContentCapture
+	}
+	if builder.contentLen >= limit {
+		builder.truncated = true
 		return
 	}
 
-	remaining := MaxContentCapture - builder.contentLen
+	remaining := limit - builder.contentLen
 	if len(content) > remaining {
 		content = content[:remaining]
 		builder.truncated = true
@@ -0,0 +1,464 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gomodel/internal/compression"
+	"gomodel/internal/core"
+)
+
+// ErrStreamSampleNotFound is returned when a stream sample does not exist.
+var ErrStreamSampleNotFound = errors.New("stream sample not found")
+
+// streamSampleTable is the table (or MongoDB collection) holding stream samples.
+const streamSampleTable = "audit_stream_samples"
+
+// streamSampleWriteTimeout bounds the background write of one stream sample.
+const streamSampleWriteTimeout = 10 * time.Second
+
+// StreamSampleConfig controls full-text sampling of streamed responses.
+type StreamSampleConfig struct {
+	// Rate is the fraction (0-1) of streamed responses sampled at random.
+	Rate float64
+
+	// OptIn samples requests that send StreamSampleHeader "true".
+	OptIn bool
+
+	// MaxPerDay caps the samples taken per UTC day (0 = unlimited)
+	MaxPerDay int
+
+	// MaxBytes caps the total size of stored samples (0 = unlimited)
+	MaxBytes int64
+}
+
+// Enabled reports whether any stream can be sampled.
+func (c StreamSampleConfig) Enabled() bool {
+	return c.Rate > 0 || c.OptIn
+}
+
+// StreamSample is the complete text of a streamed response kept for quality
+// review, with the prompt that produced it. Unlike audit log bodies it is not
+// truncated to the body capture limits.
+type StreamSample struct {
+	ID             string    `json:"id" bson:"_id"`
+	LogID          string    `json:"log_id" bson:"log_id"`
+	Timestamp      time.Time `json:"timestamp" bson:"timestamp"`
+	RequestID      string    `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	RequestedModel string    `json:"requested_model,omitempty" bson:"requested_model,omitempty"`
+	ResolvedModel  string    `json:"resolved_model,omitempty" bson:"resolved_model,omitempty"`
+	Provider       string    `json:"provider,omitempty" bson:"provider,omitempty"`
+	ProviderName   string    `json:"provider_name,omitempty" bson:"provider_name,omitempty"`
+	Path           string    `json:"path,omitempty" bson:"path,omitempty"`
+	UserPath       string    `json:"user_path,omitempty" bson:"user_path,omitempty"`
+	// OptIn reports whether the client asked for the sample instead of it
+	// being picked at random.
+	OptIn bool `json:"opt_in,omitempty" bson:"opt_in,omitempty"`
+
+	// DurationNs is the time from request start until the stream closed and
+	// FirstTokenNs the time until the first response text arrived.
+	DurationNs   int64  `json:"duration_ns" bson:"duration_ns"`
+	FirstTokenNs int64  `json:"first_token_ns,omitempty" bson:"first_token_ns,omitempty"`
+	FinishReason string `json:"finish_reason,omitempty" bson:"finish_reason,omitempty"`
+
+	// Bytes is the stored size of Prompt and Response, counted against the
+	// sample byte budget.
+	Bytes    int64  `json:"bytes" bson:"bytes"`
+	Prompt   string `json:"prompt,omitempty" bson:"prompt,omitempty"`
+	Response string `json:"response,omitempty" bson:"response,omitempty"`
+}
+
+// StreamSampleQueryParams specifies filters for listing stream samples.
+type StreamSampleQueryParams struct {
+	QueryParams
+	// Model matches the requested or resolved model.
+	Model  string
+	Limit  int
+	Offset int
+}
+
+// StreamSampleListResult holds a page of stream samples, newest first.
+// Listed samples omit the prompt and response text.
+type StreamSampleListResult struct {
+	Samples []StreamSample `json:"samples"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+// StreamSampleStore persists stream samples.
+type StreamSampleStore interface {
+	CreateStreamSample(ctx context.Context, sample *StreamSample) error
+	// GetStreamSample returns ErrStreamSampleNotFound for unknown IDs.
+	GetStreamSample(ctx context.Context, id string) (*StreamSample, error)
+	ListStreamSamples(ctx context.Context, params StreamSampleQueryParams) (*StreamSampleListResult, error)
+	// DeleteStreamSample returns the bytes freed, or ErrStreamSampleNotFound.
+	DeleteStreamSample(ctx context.Context, id string) (int64, error)
+	// DeleteStreamSamplesForLog removes the samples of an audit log entry and
+	// returns the bytes freed.
+	DeleteStreamSamplesForLog(ctx context.Context, logID string) (int64, error)
+	// StreamSampleUsage returns the number of samples taken since the given
+	// time and the size of all stored samples.
+	StreamSampleUsage(ctx context.Context, since time.Time) (count int, bytes int64, err error)
+}
+
+// StreamSampler decides which streamed responses are sampled and stores them
+// within the daily count and total byte budgets. Budgets are tracked in
+// memory and reloaded from the store at every UTC day boundary.
+type StreamSampler struct {
+	store StreamSampleStore
+	cfg   StreamSampleConfig
+	roll  func() float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	day       time.Time
+	dayCount  int
+	usedBytes int64
+
+	writes sync.WaitGroup
+}
+
+// NewStreamSampler creates a sampler over store and loads the current budget
+// usage from it.
+func NewStreamSampler(ctx context.Context, store StreamSampleStore, cfg StreamSampleConfig) (*StreamSampler, error) {
+	if store == nil {
+		return nil, errors.New("stream sample store is required")
+	}
+	s := &StreamSampler{
+		store: store,
+		cfg:   cfg,
+		roll:  rand.Float64,
+		now:   time.Now,
+	}
+	if err := s.loadUsage(ctx, streamSampleDay(s.now())); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Config returns the sampling configuration.
+func (s *StreamSampler) Config() StreamSampleConfig {
+	if s == nil {
+		return StreamSampleConfig{}
+	}
+	return s.cfg
+}
+
+// Get returns a stored stream sample.
+func (s *StreamSampler) Get(ctx context.Context, id string) (*StreamSample, error) {
+	return s.store.GetStreamSample(ctx, id)
+}
+
+// List returns stored stream samples without their prompt and response text.
+func (s *StreamSampler) List(ctx context.Context, params StreamSampleQueryParams) (*StreamSampleListResult, error) {
+	result, err := s.store.ListStreamSamples(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Samples {
+		result.Samples[i].Prompt = ""
+		result.Samples[i].Response = ""
+	}
+	return result, nil
+}
+
+// Delete removes a stream sample and returns its bytes to the budget.
+func (s *StreamSampler) Delete(ctx context.Context, id string) error {
+	freed, err := s.store.DeleteStreamSample(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.release(freed)
+	return nil
+}
+
+// DeleteForLog removes the samples of an audit log entry. Audit log redaction
+// and deletion call it so sampled text never outlives its entry's bodies.
+func (s *StreamSampler) DeleteForLog(ctx context.Context, logID string) error {
+	freed, err := s.store.DeleteStreamSamplesForLog(ctx, logID)
+	if err != nil {
+		return err
+	}
+	s.release(freed)
+	return nil
+}
+
+// Close waits for pending sample writes.
+func (s *StreamSampler) Close() {
+	if s != nil {
+		s.writes.Wait()
+	}
+}
+
+// begin decides whether a stream is sampled before any of its content is
+// captured. It returns nil for streams that are not sampled.
+func (s *StreamSampler) begin(entry *LogEntry) *streamSampleCapture {
+	if s == nil || entry == nil || entry.sampleRequest == nil {
+		return nil
+	}
+	request := entry.sampleRequest
+	if request.consent == streamSampleOptOut {
+		return nil
+	}
+	optIn := s.cfg.OptIn && request.consent == streamSampleOptIn
+	if !optIn && (s.cfg.Rate <= 0 || s.roll() >= s.cfg.Rate) {
+		return nil
+	}
+	day, remaining, ok := s.reserve()
+	if !ok {
+		return nil
+	}
+	return &streamSampleCapture{
+		sampler: s,
+		request: request,
+		optIn:   optIn,
+		day:     day,
+		builder: &streamResponseBuilder{maxContent: streamSampleContentLimit(remaining)},
+	}
+}
+
+// reserve counts a sample against the daily budget of the current day and
+// returns the bytes left in the total budget, or -1 when it is unlimited.
+func (s *StreamSampler) reserve() (time.Time, int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollDayLocked()
+	if s.cfg.MaxPerDay > 0 && s.dayCount >= s.cfg.MaxPerDay {
+		return time.Time{}, 0, false
+	}
+	remaining := int64(-1)
+	if s.cfg.MaxBytes > 0 {
+		remaining = s.cfg.MaxBytes - s.usedBytes
+		if remaining <= 0 {
+			return time.Time{}, 0, false
+		}
+	}
+	s.dayCount++
+	return s.day, remaining, true
+}
+
+// commit claims size bytes of the total budget for a sample reserved on day.
+// A sample that does not fit is dropped and gives its reservation back.
+func (s *StreamSampler) commit(day time.Time, size int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxBytes > 0 && s.usedBytes+size > s.cfg.MaxBytes {
+		s.unreserveLocked(day)
+		return false
+	}
+	s.usedBytes += size
+	return true
+}
+
+// unreserve gives back the daily reservation of a dropped sample.
+func (s *StreamSampler) unreserve(day time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unreserveLocked(day)
+}
+
+func (s *StreamSampler) unreserveLocked(day time.Time) {
+	if s.day.Equal(day) && s.dayCount > 0 {
+		s.dayCount--
+	}
+}
+
+func (s *StreamSampler) release(size int64) {
+	if s == nil || size <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usedBytes = max(s.usedBytes-size, 0)
+}
+
+// rollDayLocked starts a new daily budget at the UTC day boundary and
+// reloads usage, which also picks up samples removed by retention cleanup.
+// Callers must hold s.mu.
+func (s *StreamSampler) rollDayLocked() {
+	day := streamSampleDay(s.now())
+	if s.day.Equal(day) {
+		return
+	}
+	s.day = day
+	s.dayCount = 0
+	ctx, cancel := context.WithTimeout(context.Background(), streamSampleWriteTimeout)
+	defer cancel()
+	count, bytes, err := s.store.StreamSampleUsage(ctx, day)
+	if err != nil {
+		auditLogger.Warn("failed to reload stream sample usage", "error", err)
+		return
+	}
+	s.dayCount = count
+	s.usedBytes = bytes
+}
+
+func (s *StreamSampler) loadUsage(ctx context.Context, day time.Time) error {
+	count, bytes, err := s.store.StreamSampleUsage(ctx, day)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.day = day
+	s.dayCount = count
+	s.usedBytes = bytes
+	return nil
+}
+
+// write stores sample in the background so closing a stream never waits on
+// the database. A failed write gives its bytes back to the budget.
+func (s *StreamSampler) write(sample *StreamSample) {
+	s.writes.Add(1)
+	go func() {
+		defer s.writes.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), streamSampleWriteTimeout)
+		defer cancel()
+		if err := s.store.CreateStreamSample(ctx, sample); err != nil {
+			auditLogger.Warn("failed to store stream sample", "id", sample.ID, "log_id", sample.LogID, "error", err)
+			s.release(sample.Bytes)
+		}
+	}()
+}
+
+func streamSampleDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// streamSampleContentLimit caps the captured response text one byte past
+// the remaining budget, so a response that cannot fit is detected without
+// buffering all of it.
+func streamSampleContentLimit(remaining int64) int {
+	const unlimited = int(^uint(0) >> 1)
+	if remaining < 0 || remaining >= int64(unlimited) {
+		return unlimited
+	}
+	return int(remaining) + 1
+}
+
+// Stream sample consent sent with StreamSampleHeader.
+const (
+	streamSampleNoConsent = iota
+	streamSampleOptIn
+	streamSampleOptOut
+)
+
+// streamSampleRequest is what a stream sample needs from the request. It
+// only references the request snapshot; the prompt is copied once a stream
+// is actually sampled.
+type streamSampleRequest struct {
+	consent         int
+	snapshot        *core.RequestSnapshot
+	contentEncoding string
+}
+
+func newStreamSampleRequest(req *http.Request) *streamSampleRequest {
+	request := &streamSampleRequest{
+		snapshot:        core.GetRequestSnapshot(req.Context()),
+		contentEncoding: req.Header.Get("Content-Encoding"),
+	}
+	if raw := strings.TrimSpace(req.Header.Get(core.StreamSampleHeader)); raw != "" {
+		if consent, err := strconv.ParseBool(raw); err == nil {
+			request.consent = streamSampleOptOut
+			if consent {
+				request.consent = streamSampleOptIn
+			}
+		}
+	}
+	return request
+}
+
+// prompt returns the request body as the client sent it, decoded when it
+// was compressed. It is empty when the body was too large to capture.
+func (r *streamSampleRequest) prompt(limit int) string {
+	if r.snapshot == nil || r.snapshot.BodyNotCaptured {
+		return ""
+	}
+	body := r.snapshot.CapturedBodyView()
+	if len(body) == 0 {
+		return ""
+	}
+	if decoded, ok := compression.Decompress(body, r.contentEncoding, int64(limit)); ok {
+		body = decoded
+	}
+	return string(body)
+}
+
+// streamSampleCapture assembles the full response text of a sampled stream.
+type streamSampleCapture struct {
+	sampler      *StreamSampler
+	request      *streamSampleRequest
+	optIn        bool
+	day          time.Time
+	builder      *streamResponseBuilder
+	firstTokenAt time.Time
+}
+
+func (c *streamSampleCapture) observe(isResponsesAPI bool, event map[string]any) {
+	c.builder.IsResponsesAPI = isResponsesAPI
+	observeStreamJSONEvent(c.builder, event)
+	if c.firstTokenAt.IsZero() && c.builder.contentLen > 0 {
+		c.firstTokenAt = c.sampler.now()
+	}
+}
+
+// finish stores the sample of a closed stream and returns its ID, or ""
+// when the sample did not fit the byte budget.
+func (c *streamSampleCapture) finish(entry *LogEntry) string {
+	s := c.sampler
+	if c.builder.truncated {
+		s.unreserve(c.day)
+		auditLogger.Debug("stream sample dropped: byte budget exhausted", "log_id", entry.ID)
+		return ""
+	}
+
+	response := c.builder.Content.String()
+	prompt := c.request.prompt(c.builder.maxContent)
+	sample := &StreamSample{
+		ID:             uuid.NewString(),
+		LogID:          entry.ID,
+		Timestamp:      s.now().UTC(),
+		RequestID:      entry.RequestID,
+		RequestedModel: entry.RequestedModel,
+		ResolvedModel:  entry.ResolvedModel,
+		Provider:       entry.Provider,
+		ProviderName:   entry.ProviderName,
+		Path:           entry.Path,
+		UserPath:       entry.UserPath,
+		OptIn:          c.optIn,
+		DurationNs:     entry.DurationNs,
+		FinishReason:   c.builder.FinishReason,
+		Bytes:          int64(len(prompt) + len(response)),
+		Prompt:         prompt,
+		Response:       response,
+	}
+	if !c.firstTokenAt.IsZero() && !entry.Timestamp.IsZero() {
+		sample.FirstTokenNs = c.firstTokenAt.Sub(entry.Timestamp).Nanoseconds()
+	}
+	if !s.commit(c.day, sample.Bytes) {
+		auditLogger.Debug("stream sample dropped: byte budget exhausted", "log_id", entry.ID, "bytes", sample.Bytes)
+		return ""
+	}
+	s.write(sample)
+	return sample.ID
+}
+
+// streamSamplerFor returns the stream sampler of logger, if it has one.
+func streamSamplerFor(logger LoggerInterface) *StreamSampler {
+	if source, ok := logger.(interface{ StreamSampler() *StreamSampler }); ok {
+		return source.StreamSampler()
+	}
+	return nil
+}
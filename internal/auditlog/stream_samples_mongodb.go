@@ -0,0 +1,161 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoDBStreamSampleStore stores stream samples in MongoDB.
+type MongoDBStreamSampleStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBStreamSampleStore creates the audit_stream_samples collection
+// indexes if needed.
+func NewMongoDBStreamSampleStore(database *mongo.Database) (*MongoDBStreamSampleStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+
+	collection := database.Collection(streamSampleTable)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "log_id", Value: 1}}},
+		{Keys: bson.D{{Key: "requested_model", Value: 1}}},
+		{Keys: bson.D{{Key: "resolved_model", Value: 1}}},
+	}
+	if _, err := collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		auditLogger.Warn("failed to create stream sample indexes", "error", err)
+	}
+
+	return &MongoDBStreamSampleStore{collection: collection}, nil
+}
+
+// CreateStreamSample inserts a stream sample.
+func (s *MongoDBStreamSampleStore) CreateStreamSample(ctx context.Context, sample *StreamSample) error {
+	doc := *sample
+	doc.Timestamp = doc.Timestamp.UTC()
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("insert stream sample: %w", err)
+	}
+	return nil
+}
+
+// GetStreamSample returns a stream sample by ID.
+func (s *MongoDBStreamSampleStore) GetStreamSample(ctx context.Context, id string) (*StreamSample, error) {
+	var sample StreamSample
+	err := s.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&sample)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query stream sample: %w", err)
+	}
+	return &sample, nil
+}
+
+// ListStreamSamples returns stream samples, newest first.
+func (s *MongoDBStreamSampleStore) ListStreamSamples(ctx context.Context, params StreamSampleQueryParams) (*StreamSampleListResult, error) {
+	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	filter := bson.D{}
+	if dateFilter := mongoDateRangeFilter(params.QueryParams); dateFilter != nil {
+		filter = append(filter, bson.E{Key: "timestamp", Value: dateFilter})
+	}
+	if params.Model != "" {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "requested_model", Value: params.Model}},
+			bson.D{{Key: "resolved_model", Value: params.Model}},
+		}})
+	}
+
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("count stream samples: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list stream samples: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	samples := make([]StreamSample, 0, limit)
+	if err := cursor.All(ctx, &samples); err != nil {
+		return nil, fmt.Errorf("decode stream samples: %w", err)
+	}
+	return &StreamSampleListResult{Samples: samples, Total: int(total), Limit: limit, Offset: offset}, nil
+}
+
+// DeleteStreamSample removes a stream sample and returns its size.
+func (s *MongoDBStreamSampleStore) DeleteStreamSample(ctx context.Context, id string) (int64, error) {
+	var sample StreamSample
+	err := s.collection.FindOneAndDelete(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&sample)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("delete stream sample: %w", err)
+	}
+	return sample.Bytes, nil
+}
+
+// DeleteStreamSamplesForLog removes the samples of an audit log entry and
+// returns their total size.
+func (s *MongoDBStreamSampleStore) DeleteStreamSamplesForLog(ctx context.Context, logID string) (int64, error) {
+	var freed int64
+	for {
+		var sample StreamSample
+		err := s.collection.FindOneAndDelete(ctx, bson.D{{Key: "log_id", Value: logID}}).Decode(&sample)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return freed, nil
+		}
+		if err != nil {
+			return freed, fmt.Errorf("delete stream samples for log: %w", err)
+		}
+		freed += sample.Bytes
+	}
+}
+
+// StreamSampleUsage returns the samples taken since the given time and the
+// size of all stored samples.
+func (s *MongoDBStreamSampleStore) StreamSampleUsage(ctx context.Context, since time.Time) (int, int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "bytes", Value: bson.D{{Key: "$sum", Value: "$bytes"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gte", Value: bson.A{"$timestamp", since.UTC()}}}, 1, 0,
+			}}}}}},
+		}}},
+	}
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query stream sample usage: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Bytes int64 `bson:"bytes"`
+		Count int   `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, 0, fmt.Errorf("decode stream sample usage: %w", err)
+	}
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+	return results[0].Count, results[0].Bytes, nil
+}
@@ -0,0 +1,175 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLStreamSampleStore stores stream samples in PostgreSQL.
+type PostgreSQLStreamSampleStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLStreamSampleStore creates the audit_stream_samples table and
+// indexes if needed.
+func NewPostgreSQLStreamSampleStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLStreamSampleStore, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is required")
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("connection pool is required")
+	}
+
+	if err := CreatePostgreSQLStreamSampleSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStreamSampleStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLStreamSampleSchema creates or upgrades the stream sample
+// table and its indexes. It is idempotent: store constructors run it, and so
+// does the baseline schema migration.
+func CreatePostgreSQLStreamSampleSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS `+streamSampleTable+` (
+			id TEXT PRIMARY KEY,
+			log_id TEXT NOT NULL,
+			timestamp TIMESTAMPTZ NOT NULL,
+			requested_model TEXT,
+			resolved_model TEXT,
+			bytes BIGINT NOT NULL,
+			data JSONB NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create %s table: %w", streamSampleTable, err)
+	}
+	for _, index := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_audit_stream_samples_timestamp ON " + streamSampleTable + "(timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_stream_samples_log_id ON " + streamSampleTable + "(log_id)",
+	} {
+		if _, err := pool.Exec(ctx, index); err != nil {
+			auditLogger.Warn("failed to create index", "error", err)
+		}
+	}
+	return nil
+}
+
+// CreateStreamSample inserts a stream sample.
+func (s *PostgreSQLStreamSampleStore) CreateStreamSample(ctx context.Context, sample *StreamSample) error {
+	payload, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("marshal stream sample: %w", err)
+	}
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO `+streamSampleTable+` (id, log_id, timestamp, requested_model, resolved_model, bytes, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb)
+	`, sample.ID, sample.LogID, sample.Timestamp.UTC(), sample.RequestedModel, sample.ResolvedModel, sample.Bytes, payload)
+	if err != nil {
+		return fmt.Errorf("insert stream sample: %w", err)
+	}
+	return nil
+}
+
+// GetStreamSample returns a stream sample by ID.
+func (s *PostgreSQLStreamSampleStore) GetStreamSample(ctx context.Context, id string) (*StreamSample, error) {
+	var payload []byte
+	err := s.pool.QueryRow(ctx, "SELECT data FROM "+streamSampleTable+" WHERE id = $1", id).Scan(&payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query stream sample: %w", err)
+	}
+	return decodeStreamSample(payload)
+}
+
+// ListStreamSamples returns stream samples, newest first.
+func (s *PostgreSQLStreamSampleStore) ListStreamSamples(ctx context.Context, params StreamSampleQueryParams) (*StreamSampleListResult, error) {
+	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	conditions, args, argIdx := pgDateRangeConditions(params.QueryParams, 1)
+	if params.Model != "" {
+		conditions = append(conditions, fmt.Sprintf("(requested_model = $%d OR resolved_model = $%d)", argIdx, argIdx))
+		args = append(args, params.Model)
+		argIdx++
+	}
+	where := buildWhereClause(conditions)
+
+	var total int
+	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+streamSampleTable+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count stream samples: %w", err)
+	}
+
+	query := fmt.Sprintf("SELECT data FROM %s%s ORDER BY timestamp DESC, id DESC LIMIT $%d OFFSET $%d",
+		streamSampleTable, where, argIdx, argIdx+1)
+	rows, err := s.pool.Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list stream samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := make([]StreamSample, 0, limit)
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("scan stream sample row: %w", err)
+		}
+		sample, err := decodeStreamSample(payload)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, *sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stream sample rows: %w", err)
+	}
+	return &StreamSampleListResult{Samples: samples, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// DeleteStreamSample removes a stream sample and returns its size.
+func (s *PostgreSQLStreamSampleStore) DeleteStreamSample(ctx context.Context, id string) (int64, error) {
+	var bytes int64
+	err := s.pool.QueryRow(ctx, "DELETE FROM "+streamSampleTable+" WHERE id = $1 RETURNING bytes", id).Scan(&bytes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("delete stream sample: %w", err)
+	}
+	return bytes, nil
+}
+
+// DeleteStreamSamplesForLog removes the samples of an audit log entry and
+// returns their total size.
+func (s *PostgreSQLStreamSampleStore) DeleteStreamSamplesForLog(ctx context.Context, logID string) (int64, error) {
+	var freed int64
+	err := s.pool.QueryRow(ctx, `
+		WITH deleted AS (DELETE FROM `+streamSampleTable+` WHERE log_id = $1 RETURNING bytes)
+		SELECT COALESCE(SUM(bytes), 0) FROM deleted
+	`, logID).Scan(&freed)
+	if err != nil {
+		return 0, fmt.Errorf("delete stream samples for log: %w", err)
+	}
+	return freed, nil
+}
+
+// StreamSampleUsage returns the samples taken since the given time and the
+// size of all stored samples.
+func (s *PostgreSQLStreamSampleStore) StreamSampleUsage(ctx context.Context, since time.Time) (int, int64, error) {
+	var count int
+	var bytes int64
+	err := s.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE timestamp >= $1), COALESCE(SUM(bytes), 0)::BIGINT
+		FROM `+streamSampleTable,
+		since.UTC()).Scan(&count, &bytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query stream sample usage: %w", err)
+	}
+	return count, bytes, nil
+}
@@ -0,0 +1,187 @@
+package auditlog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLiteStreamSampleStore stores stream samples in SQLite.
+type SQLiteStreamSampleStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStreamSampleStore creates the audit_stream_samples table and
+// indexes if needed.
+func NewSQLiteStreamSampleStore(db *sql.DB) (*SQLiteStreamSampleStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is required")
+	}
+
+	if err := CreateSQLiteStreamSampleSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStreamSampleStore{db: db}, nil
+}
+
+// CreateSQLiteStreamSampleSchema creates or upgrades the stream sample table
+// and its indexes. It is idempotent: store constructors run it, and so does
+// the baseline schema migration.
+func CreateSQLiteStreamSampleSchema(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+streamSampleTable+` (
+			id TEXT PRIMARY KEY,
+			log_id TEXT NOT NULL,
+			timestamp TEXT NOT NULL,
+			requested_model TEXT,
+			resolved_model TEXT,
+			bytes INTEGER NOT NULL,
+			data TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create %s table: %w", streamSampleTable, err)
+	}
+	for _, index := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_audit_stream_samples_timestamp ON " + streamSampleTable + "(timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_audit_stream_samples_log_id ON " + streamSampleTable + "(log_id)",
+	} {
+		if _, err := db.ExecContext(ctx, index); err != nil {
+			auditLogger.Warn("failed to create index", "error", err)
+		}
+	}
+	return nil
+}
+
+// CreateStreamSample inserts a stream sample.
+func (s *SQLiteStreamSampleStore) CreateStreamSample(ctx context.Context, sample *StreamSample) error {
+	payload, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("marshal stream sample: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO `+streamSampleTable+` (id, log_id, timestamp, requested_model, resolved_model, bytes, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sample.ID, sample.LogID, sample.Timestamp.UTC().Format(time.RFC3339Nano),
+		sample.RequestedModel, sample.ResolvedModel, sample.Bytes, string(payload))
+	if err != nil {
+		return fmt.Errorf("insert stream sample: %w", err)
+	}
+	return nil
+}
+
+// GetStreamSample returns a stream sample by ID.
+func (s *SQLiteStreamSampleStore) GetStreamSample(ctx context.Context, id string) (*StreamSample, error) {
+	var payload string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM "+streamSampleTable+" WHERE id = ?", id).Scan(&payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query stream sample: %w", err)
+	}
+	return decodeStreamSample([]byte(payload))
+}
+
+// ListStreamSamples returns stream samples, newest first.
+func (s *SQLiteStreamSampleStore) ListStreamSamples(ctx context.Context, params StreamSampleQueryParams) (*StreamSampleListResult, error) {
+	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	conditions, args := sqliteDateRangeConditions(params.QueryParams)
+	if params.Model != "" {
+		conditions = append(conditions, "(requested_model = ? OR resolved_model = ?)")
+		args = append(args, params.Model, params.Model)
+	}
+	where := buildWhereClause(conditions)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+streamSampleTable+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count stream samples: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT data FROM "+streamSampleTable+where+" ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("list stream samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := make([]StreamSample, 0, limit)
+	for rows.Next() {
+		var payload string
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("scan stream sample row: %w", err)
+		}
+		sample, err := decodeStreamSample([]byte(payload))
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, *sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate stream sample rows: %w", err)
+	}
+	return &StreamSampleListResult{Samples: samples, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// DeleteStreamSample removes a stream sample and returns its size.
+func (s *SQLiteStreamSampleStore) DeleteStreamSample(ctx context.Context, id string) (int64, error) {
+	var bytes int64
+	err := s.db.QueryRowContext(ctx, "DELETE FROM "+streamSampleTable+" WHERE id = ? RETURNING bytes", id).Scan(&bytes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrStreamSampleNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("delete stream sample: %w", err)
+	}
+	return bytes, nil
+}
+
+// DeleteStreamSamplesForLog removes the samples of an audit log entry and
+// returns their total size.
+func (s *SQLiteStreamSampleStore) DeleteStreamSamplesForLog(ctx context.Context, logID string) (int64, error) {
+	rows, err := s.db.QueryContext(ctx, "DELETE FROM "+streamSampleTable+" WHERE log_id = ? RETURNING bytes", logID)
+	if err != nil {
+		return 0, fmt.Errorf("delete stream samples for log: %w", err)
+	}
+	defer rows.Close()
+
+	var freed int64
+	for rows.Next() {
+		var bytes int64
+		if err := rows.Scan(&bytes); err != nil {
+			return 0, fmt.Errorf("scan deleted stream sample: %w", err)
+		}
+		freed += bytes
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("delete stream samples for log: %w", err)
+	}
+	return freed, nil
+}
+
+// StreamSampleUsage returns the samples taken since the given time and the
+// size of all stored samples.
+func (s *SQLiteStreamSampleStore) StreamSampleUsage(ctx context.Context, since time.Time) (int, int64, error) {
+	var count int
+	var bytes int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(CASE WHEN timestamp >= ? THEN 1 ELSE 0 END), 0), COALESCE(SUM(bytes), 0)
+		FROM `+streamSampleTable,
+		sqliteTimestampBoundary(since)).Scan(&count, &bytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query stream sample usage: %w", err)
+	}
+	return count, bytes, nil
+}
+
+func decodeStreamSample(payload []byte) (*StreamSample, error) {
+	var sample StreamSample
+	if err := json.Unmarshal(payload, &sample); err != nil {
+		return nil, fmt.Errorf("unmarshal stream sample: %w", err)
+	}
+	return &sample, nil
+}
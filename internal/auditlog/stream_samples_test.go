@@ -0,0 +1,274 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func newTestStreamSampler(t *testing.T, cfg StreamSampleConfig) (*StreamSampler, *SQLiteStreamSampleStore) {
+	t.Helper()
+	db := createTestDB(t)
+	// Sample writes run on their own goroutine; keep them on the one
+	// connection that holds the in-memory database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := NewSQLiteStreamSampleStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStreamSampleStore() error = %v", err)
+	}
+	sampler, err := NewStreamSampler(context.Background(), store, cfg)
+	if err != nil {
+		t.Fatalf("NewStreamSampler() error = %v", err)
+	}
+	return sampler, store
+}
+
+func newStreamSampleEntry(t *testing.T, id, header string) *LogEntry {
+	t.Helper()
+	body := []byte(`{"model":"gpt-4o","stream":true}`)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req = req.WithContext(core.WithRequestSnapshot(req.Context(), core.NewRequestSnapshot(
+		"POST", "/v1/chat/completions", nil, nil, nil, "application/json", body, false, "req-1", nil, "/",
+	)))
+	if header != "" {
+		req.Header.Set(core.StreamSampleHeader, header)
+	}
+	return &LogEntry{
+		ID:             id,
+		Timestamp:      time.Now(),
+		RequestedModel: "gpt-4o",
+		ResolvedModel:  "openai/gpt-4o",
+		sampleRequest:  newStreamSampleRequest(req),
+	}
+}
+
+func streamSampleContentEvent(content string) map[string]any {
+	return map[string]any{
+		"choices": []any{map[string]any{"delta": map[string]any{"content": content}}},
+	}
+}
+
+func TestStreamSampler_SamplesAtConfiguredRate(t *testing.T) {
+	sampler, _ := newTestStreamSampler(t, StreamSampleConfig{Rate: 0.1})
+	sampler.roll = rand.New(rand.NewPCG(1, 2)).Float64
+
+	const trials = 20000
+	sampled := 0
+	for range trials {
+		if capture := sampler.begin(newStreamSampleEntry(t, "log", "")); capture != nil {
+			sampled++
+		}
+	}
+
+	// Binomial(20000, 0.1) has a standard deviation of ~42; allow five.
+	expected := trials * 0.1
+	tolerance := 5 * math.Sqrt(trials*0.1*0.9)
+	if math.Abs(float64(sampled)-expected) > tolerance {
+		t.Fatalf("sampled %d of %d streams, want %.0f ± %.0f", sampled, trials, expected, tolerance)
+	}
+}
+
+func TestStreamSampler_HonorsOptInAndOptOut(t *testing.T) {
+	sampler, _ := newTestStreamSampler(t, StreamSampleConfig{OptIn: true})
+	sampler.roll = func() float64 { return 0 }
+
+	if sampler.begin(newStreamSampleEntry(t, "log-1", "")) != nil {
+		t.Fatal("expected no sample without consent at rate 0")
+	}
+	capture := sampler.begin(newStreamSampleEntry(t, "log-2", "true"))
+	if capture == nil || !capture.optIn {
+		t.Fatalf("expected opted-in sample, got %+v", capture)
+	}
+
+	sampler.cfg.Rate = 1
+	if sampler.begin(newStreamSampleEntry(t, "log-3", "false")) != nil {
+		t.Fatal("expected opt-out to override the sampling rate")
+	}
+
+	sampler.cfg.OptIn = false
+	sampler.cfg.Rate = 0
+	if sampler.begin(newStreamSampleEntry(t, "log-4", "true")) != nil {
+		t.Fatal("expected opt-in header to be ignored when opt-in is disabled")
+	}
+}
+
+func TestStreamSampler_StoresCompleteStream(t *testing.T) {
+	sampler, _ := newTestStreamSampler(t, StreamSampleConfig{Rate: 1})
+	entry := newStreamSampleEntry(t, "log-1", "")
+	capture := sampler.begin(entry)
+	if capture == nil {
+		t.Fatal("expected stream to be sampled")
+	}
+	long := strings.Repeat("x", 10000)
+	capture.observe(false, streamSampleContentEvent("Hello, "))
+	capture.observe(false, streamSampleContentEvent(long))
+
+	id := capture.finish(entry)
+	sampler.Close()
+	if id == "" {
+		t.Fatal("expected sample ID")
+	}
+
+	sample, err := sampler.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if sample.Response != "Hello, "+long {
+		t.Fatalf("expected complete response, got %d bytes", len(sample.Response))
+	}
+	if sample.Prompt != `{"model":"gpt-4o","stream":true}` || sample.LogID != "log-1" {
+		t.Fatalf("unexpected sample prompt %q for log %q", sample.Prompt, sample.LogID)
+	}
+	if sample.Bytes != int64(len(sample.Prompt)+len(sample.Response)) {
+		t.Fatalf("bytes = %d, want prompt plus response", sample.Bytes)
+	}
+}
+
+func TestStreamSampler_StopsAtDailyCap(t *testing.T) {
+	sampler, _ := newTestStreamSampler(t, StreamSampleConfig{Rate: 1, MaxPerDay: 2})
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	sampler.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if sampler.begin(newStreamSampleEntry(t, "log", "")) == nil {
+			t.Fatalf("expected sample %d within the daily cap", i+1)
+		}
+	}
+	if sampler.begin(newStreamSampleEntry(t, "log", "")) != nil {
+		t.Fatal("expected no sample past the daily cap")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if sampler.begin(newStreamSampleEntry(t, "log", "")) == nil {
+		t.Fatal("expected the daily cap to reset on a new day")
+	}
+}
+
+func TestStreamSampler_StopsAtByteBudget(t *testing.T) {
+	sampler, _ := newTestStreamSampler(t, StreamSampleConfig{Rate: 1, MaxBytes: 200})
+	ctx := context.Background()
+
+	first := newStreamSampleEntry(t, "log-1", "")
+	capture := sampler.begin(first)
+	capture.observe(false, streamSampleContentEvent(strings.Repeat("a", 100)))
+	if capture.finish(first) == "" {
+		t.Fatal("expected first sample to fit the budget")
+	}
+	sampler.Close()
+
+	// The prompt takes 32 bytes, so 68 of the 200 bytes are left: a longer
+	// response is cut off while streaming and dropped.
+	second := newStreamSampleEntry(t, "log-2", "")
+	capture = sampler.begin(second)
+	capture.observe(false, streamSampleContentEvent(strings.Repeat("b", 100)))
+	if !capture.builder.truncated {
+		t.Fatal("expected capture to stop at the remaining budget")
+	}
+	if capture.finish(second) != "" {
+		t.Fatal("expected sample over the byte budget to be dropped")
+	}
+
+	// A response that fits while streaming can still overflow once the
+	// prompt is added.
+	third := newStreamSampleEntry(t, "log-3", "")
+	capture = sampler.begin(third)
+	capture.observe(false, streamSampleContentEvent(strings.Repeat("c", 60)))
+	if capture.finish(third) != "" {
+		t.Fatal("expected sample whose prompt overflows the budget to be dropped")
+	}
+
+	exact := newStreamSampleEntry(t, "log-4", "")
+	capture = sampler.begin(exact)
+	capture.observe(false, streamSampleContentEvent(strings.Repeat("d", 36)))
+	if capture.finish(exact) == "" {
+		t.Fatal("expected sample that exactly fills the budget to be stored")
+	}
+	sampler.Close()
+	if sampler.begin(newStreamSampleEntry(t, "log-5", "")) != nil {
+		t.Fatal("expected no sampling once the budget is exhausted")
+	}
+
+	if err := sampler.DeleteForLog(ctx, "log-1"); err != nil {
+		t.Fatalf("DeleteForLog() error = %v", err)
+	}
+	if sampler.begin(newStreamSampleEntry(t, "log-6", "")) == nil {
+		t.Fatal("expected deleting samples to free budget")
+	}
+}
+
+func TestStreamSampler_LoadsUsageFromStore(t *testing.T) {
+	sampler, store := newTestStreamSampler(t, StreamSampleConfig{Rate: 1, MaxPerDay: 1})
+	entry := newStreamSampleEntry(t, "log-1", "")
+	capture := sampler.begin(entry)
+	capture.observe(false, streamSampleContentEvent("hi"))
+	capture.finish(entry)
+	sampler.Close()
+
+	restarted, err := NewStreamSampler(context.Background(), store, sampler.cfg)
+	if err != nil {
+		t.Fatalf("NewStreamSampler() error = %v", err)
+	}
+	if restarted.begin(newStreamSampleEntry(t, "log-2", "")) != nil {
+		t.Fatal("expected the daily cap to survive a restart")
+	}
+}
+
+func TestSQLiteStreamSampleStore_ListFiltersAndDeletes(t *testing.T) {
+	_, store := newTestStreamSampler(t, StreamSampleConfig{Rate: 1})
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	samples := []*StreamSample{
+		{ID: "s1", LogID: "log-1", Timestamp: base, RequestedModel: "gpt-4o", ResolvedModel: "openai/gpt-4o", Bytes: 10, Response: "one"},
+		{ID: "s2", LogID: "log-2", Timestamp: base.Add(24 * time.Hour), RequestedModel: "fast", ResolvedModel: "claude-haiku", Bytes: 20, Response: "two"},
+		{ID: "s3", LogID: "log-2", Timestamp: base.Add(48 * time.Hour), RequestedModel: "gpt-4o", ResolvedModel: "openai/gpt-4o", Bytes: 30, Response: "three"},
+	}
+	for _, sample := range samples {
+		if err := store.CreateStreamSample(ctx, sample); err != nil {
+			t.Fatalf("CreateStreamSample() error = %v", err)
+		}
+	}
+
+	result, err := store.ListStreamSamples(ctx, StreamSampleQueryParams{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("ListStreamSamples() error = %v", err)
+	}
+	if result.Total != 2 || result.Samples[0].ID != "s3" || result.Samples[1].ID != "s1" {
+		t.Fatalf("unexpected model filter result: %+v", result)
+	}
+
+	result, err = store.ListStreamSamples(ctx, StreamSampleQueryParams{
+		QueryParams: QueryParams{StartDate: base.Add(24 * time.Hour), EndDate: base.Add(24 * time.Hour)},
+		Model:       "claude-haiku",
+	})
+	if err != nil {
+		t.Fatalf("ListStreamSamples() error = %v", err)
+	}
+	if result.Total != 1 || result.Samples[0].ID != "s2" {
+		t.Fatalf("unexpected date filter result: %+v", result)
+	}
+
+	count, bytes, err := store.StreamSampleUsage(ctx, base.Add(24*time.Hour))
+	if err != nil || count != 2 || bytes != 60 {
+		t.Fatalf("StreamSampleUsage() = %d, %d, %v; want 2, 60", count, bytes, err)
+	}
+
+	freed, err := store.DeleteStreamSamplesForLog(ctx, "log-2")
+	if err != nil || freed != 50 {
+		t.Fatalf("DeleteStreamSamplesForLog() = %d, %v; want 50", freed, err)
+	}
+	if _, err := store.DeleteStreamSample(ctx, "s2"); !errors.Is(err, ErrStreamSampleNotFound) {
+		t.Fatalf("expected ErrStreamSampleNotFound, got %v", err)
+	}
+	if _, err := store.GetStreamSample(ctx, "s3"); !errors.Is(err, ErrStreamSampleNotFound) {
+		t.Fatalf("expected deleted sample to be gone, got %v", err)
+	}
+}
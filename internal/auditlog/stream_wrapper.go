@@ -16,6 +16,7 @@ type streamResponseBuilder struct {
 	Role         string
 	FinishReason string
 	Content      strings.Builder // accumulated delta content
+	Annotations  []any           // citations of the content, in the wire format of the stream
 
 	// Responses API fields
 	IsResponsesAPI bool
@@ -25,6 +26,7 @@ type streamResponseBuilder struct {
 
 	// Tracking
 	contentLen int // track content length to enforce limit
+	maxContent int // content limit; zero means MaxContentCapture
 	truncated  bool
 }
 
@@ -35,6 +37,13 @@ func (b *streamResponseBuilder) buildChatCompletionResponse() map[string]any {
 		role = "assistant"
 	}
 
+	message := map[string]any{
+		"role":    role,
+		"content": b.Content.String(),
+	}
+	if len(b.Annotations) > 0 {
+		message["annotations"] = b.Annotations
+	}
 	return map[string]any{
 		"id":      b.ID,
 		"object":  "chat.completion",
@@ -42,11 +51,8 @@ func (b *streamResponseBuilder) buildChatCompletionResponse() map[string]any {
 		"created": b.Created,
 		"choices": []map[string]any{
 			{
-				"index": 0,
-				"message": map[string]any{
-					"role":    role,
-					"content": b.Content.String(),
-				},
+				"index":         0,
+				"message":       message,
 				"finish_reason": b.FinishReason,
 			},
 		},
@@ -55,6 +61,13 @@ func (b *streamResponseBuilder) buildChatCompletionResponse() map[string]any {
 
 // buildResponsesAPIResponse constructs a Responses API response from accumulated data
 func (b *streamResponseBuilder) buildResponsesAPIResponse() map[string]any {
+	text := map[string]any{
+		"type": "output_text",
+		"text": b.Content.String(),
+	}
+	if len(b.Annotations) > 0 {
+		text["annotations"] = b.Annotations
+	}
 	return map[string]any{
 		"id":         b.ResponseID,
 		"object":     "response",
@@ -63,14 +76,9 @@ func (b *streamResponseBuilder) buildResponsesAPIResponse() map[string]any {
 		"status":     b.Status,
 		"output": []map[string]any{
 			{
-				"type": "message",
-				"role": "assistant",
-				"content": []map[string]any{
-					{
-						"type": "output_text",
-						"text": b.Content.String(),
-					},
-				},
+				"type":    "message",
+				"role":    "assistant",
+				"content": []map[string]any{text},
 			},
 		},
 	}
@@ -86,17 +94,22 @@ func CreateStreamEntry(baseEntry *LogEntry) *LogEntry {
 	// Create a copy of the entry for the stream.
 	// The stream observer will complete and write it when the stream closes.
 	entryCopy := &LogEntry{
-		ID:                baseEntry.ID,
-		Timestamp:         baseEntry.Timestamp,
-		DurationNs:        baseEntry.DurationNs,
-		RequestedModel:    baseEntry.RequestedModel,
-		ResolvedModel:     baseEntry.ResolvedModel,
-		Provider:          baseEntry.Provider,
-		ProviderName:      baseEntry.ProviderName,
-		AliasUsed:         baseEntry.AliasUsed,
-		WorkflowVersionID: baseEntry.WorkflowVersionID,
-		CacheType:         baseEntry.CacheType,
-		StatusCode:        baseEntry.StatusCode,
+		ID:                 baseEntry.ID,
+		Timestamp:          baseEntry.Timestamp,
+		DurationNs:         baseEntry.DurationNs,
+		RequestedModel:     baseEntry.RequestedModel,
+		ResolvedModel:      baseEntry.ResolvedModel,
+		ServedModel:        baseEntry.ServedModel,
+		ResponseID:         baseEntry.ResponseID,
+		ProviderResponseID: baseEntry.ProviderResponseID,
+		Provider:           baseEntry.Provider,
+		ProviderName:       baseEntry.ProviderName,
+		AliasUsed:          baseEntry.AliasUsed,
+		WorkflowVersionID:  baseEntry.WorkflowVersionID,
+		CacheType:          baseEntry.CacheType,
+		StatusCode:         baseEntry.StatusCode,
+		UpstreamStatusCode: baseEntry.UpstreamStatusCode,
+		UpstreamDurationNs: baseEntry.UpstreamDurationNs,
 		// Copy extracted fields
 		RequestID:  baseEntry.RequestID,
 		AuthKeyID:  baseEntry.AuthKeyID,
@@ -106,6 +119,8 @@ func CreateStreamEntry(baseEntry *LogEntry) *LogEntry {
 		Path:       baseEntry.Path,
 		UserPath:   baseEntry.UserPath,
 		Stream:     true, // Mark as streaming
+
+		sampleRequest: baseEntry.sampleRequest,
 	}
 
 	if baseEntry.Data != nil {
@@ -117,6 +132,17 @@ func CreateStreamEntry(baseEntry *LogEntry) *LogEntry {
 			RequestHeaders:  copyMap(baseEntry.Data.RequestHeaders),
 			ResponseHeaders: copyMap(baseEntry.Data.ResponseHeaders),
 			RequestBody:     baseEntry.Data.RequestBody,
+			Labels:          copyMap(baseEntry.Data.Labels),
+			Options:         copyMap(baseEntry.Data.Options),
+			UnlistedModel:   baseEntry.Data.UnlistedModel,
+			UserHash:        baseEntry.Data.UserHash,
+			User:            baseEntry.Data.User,
+			BodyLogOptOut:   baseEntry.Data.BodyLogOptOut,
+			StreamFormat:    baseEntry.Data.StreamFormat,
+
+			RequestBodyTooBigToHandle: baseEntry.Data.RequestBodyTooBigToHandle,
+			RequestBodyBytes:          baseEntry.Data.RequestBodyBytes,
+			RequestBodySHA256:         baseEntry.Data.RequestBodySHA256,
 		}
 		if baseEntry.Data.WorkflowFeatures != nil {
 			snapshot := *baseEntry.Data.WorkflowFeatures
@@ -126,6 +152,33 @@ func CreateStreamEntry(baseEntry *LogEntry) *LogEntry {
 			snapshot := *baseEntry.Data.Failover
 			entryCopy.Data.Failover = &snapshot
 		}
+		if baseEntry.Data.Comparison != nil {
+			snapshot := *baseEntry.Data.Comparison
+			entryCopy.Data.Comparison = &snapshot
+		}
+		if baseEntry.Data.Experiment != nil {
+			snapshot := *baseEntry.Data.Experiment
+			entryCopy.Data.Experiment = &snapshot
+		}
+		if baseEntry.Data.ModelGroup != nil {
+			snapshot := *baseEntry.Data.ModelGroup
+			entryCopy.Data.ModelGroup = &snapshot
+		}
+		if baseEntry.Data.PromptTemplate != nil {
+			snapshot := *baseEntry.Data.PromptTemplate
+			entryCopy.Data.PromptTemplate = &snapshot
+		}
+		if baseEntry.Data.DataResidency != nil {
+			snapshot := *baseEntry.Data.DataResidency
+			entryCopy.Data.DataResidency = &snapshot
+		}
+		if baseEntry.Data.Chaos != nil {
+			snapshot := *baseEntry.Data.Chaos
+			entryCopy.Data.Chaos = &snapshot
+		}
+		if len(baseEntry.Data.GuardrailCanaries) > 0 {
+			entryCopy.Data.GuardrailCanaries = append([]GuardrailCanarySnapshot(nil), baseEntry.Data.GuardrailCanaries...)
+		}
 	}
 
 	return entryCopy
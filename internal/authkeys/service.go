@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"gomodel/internal/core"
 )
 
 const defaultRefreshInterval = time.Minute
@@ -27,8 +29,10 @@ type snapshot struct {
 
 // AuthenticationResult describes one successful managed auth key lookup.
 type AuthenticationResult struct {
-	ID       string
-	UserPath string
+	ID            string
+	UserPath      string
+	Role          Role
+	DataResidency string
 }
 
 // Service keeps managed auth keys cached in memory for request authentication.
@@ -75,6 +79,11 @@ func (s *Service) Refresh(ctx context.Context) error {
 		if key.ID == "" {
 			return fmt.Errorf("load auth key %q: missing id", key.Name)
 		}
+		role, ok := ParseRole(string(key.Role))
+		if !ok {
+			return fmt.Errorf("load auth key %q: unknown role %q", key.ID, key.Role)
+		}
+		key.Role = role
 		next.order = append(next.order, key.ID)
 		next.byID[key.ID] = key
 		next.bySecretHash[key.SecretHash] = key
@@ -174,6 +183,8 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*IssuedKey, er
 		Name:          normalized.Name,
 		Description:   normalized.Description,
 		UserPath:      normalized.UserPath,
+		Role:          normalized.Role,
+		DataResidency: normalized.DataResidency,
 		RedactedValue: redactedValue,
 		SecretHash:    secretHash,
 		Enabled:       true,
@@ -197,6 +208,57 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*IssuedKey, er
 	}, nil
 }
 
+// Import persists a key exactly as restored from a state export, keeping its
+// ID, secret hash and timestamps, so clients holding the original token keep
+// authenticating. It does not check that expires_at lies in the future.
+func (s *Service) Import(ctx context.Context, key AuthKey) error {
+	if s == nil {
+		return fmt.Errorf("auth key service is required")
+	}
+	key.ID = normalizeID(key.ID)
+	key.Name = strings.TrimSpace(key.Name)
+	key.Description = strings.TrimSpace(key.Description)
+	key.SecretHash = strings.ToLower(strings.TrimSpace(key.SecretHash))
+	if key.ID == "" {
+		return newValidationError("auth key id is required", nil)
+	}
+	if key.Name == "" {
+		return newValidationError("name is required", nil)
+	}
+	if decoded, err := hex.DecodeString(key.SecretHash); err != nil || len(decoded) != sha256.Size {
+		return newValidationError("secret_hash must be a hex-encoded SHA-256 digest", err)
+	}
+	userPath, err := core.NormalizeUserPath(key.UserPath)
+	if err != nil {
+		return newValidationError("invalid user_path", err)
+	}
+	key.UserPath = userPath
+	role, ok := ParseRole(string(key.Role))
+	if !ok {
+		return newValidationError("invalid role: must be one of admin, read_usage, read_audit_metadata", nil)
+	}
+	key.Role = role
+	residency, err := core.NormalizeDataResidency(key.DataResidency)
+	if err != nil {
+		return newValidationError("invalid data_residency", err)
+	}
+	key.DataResidency = residency
+
+	now := time.Now().UTC()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = now
+	}
+	if key.UpdatedAt.IsZero() {
+		key.UpdatedAt = key.CreatedAt
+	}
+	if err := s.store.Create(ctx, key); err != nil {
+		return fmt.Errorf("import auth key: %w", err)
+	}
+	s.applyUpsert(key, now)
+	s.refreshBestEffort(ctx, "import")
+	return nil
+}
+
 // Deactivate marks a managed auth key inactive while preserving its record and
 // best-effort reconciles the snapshot from storage afterward.
 func (s *Service) Deactivate(ctx context.Context, id string) error {
@@ -245,6 +307,24 @@ func (s *Service) Authenticate(_ context.Context, token string) (AuthenticationR
 	return authenticateKey(key, now)
 }
 
+// AuthenticateID re-resolves a managed auth key by its ID, for work that was
+// authenticated earlier and runs later without the bearer token, such as
+// deferred request replays. It fails like Authenticate when the key has since
+// been deactivated, disabled or has expired.
+func (s *Service) AuthenticateID(_ context.Context, id string) (AuthenticationResult, error) {
+	if s == nil {
+		return AuthenticationResult{}, ErrInvalidToken
+	}
+
+	s.mu.RLock()
+	key, exists := s.snapshot.byID[strings.TrimSpace(id)]
+	s.mu.RUnlock()
+	if !exists {
+		return AuthenticationResult{}, ErrNotFound
+	}
+	return authenticateKey(key, time.Now().UTC())
+}
+
 // StartBackgroundRefresh periodically reloads auth keys from storage until stopped.
 func (s *Service) StartBackgroundRefresh(interval time.Duration) func() {
 	if interval <= 0 {
@@ -290,8 +370,10 @@ func authenticateKey(key AuthKey, now time.Time) (AuthenticationResult, error) {
 		return AuthenticationResult{}, ErrInvalidToken
 	}
 	return AuthenticationResult{
-		ID:       key.ID,
-		UserPath: strings.TrimSpace(key.UserPath),
+		ID:            key.ID,
+		UserPath:      strings.TrimSpace(key.UserPath),
+		Role:          key.Role,
+		DataResidency: strings.TrimSpace(key.DataResidency),
 	}, nil
 }
 
@@ -92,6 +92,9 @@ func TestServiceCreateAuthenticateAndDeactivate(t *testing.T) {
 	if authKeyID.ID != issued.ID {
 		t.Fatalf("Authenticate() id = %q, want %q", authKeyID.ID, issued.ID)
 	}
+	if byID, err := service.AuthenticateID(context.Background(), issued.ID); err != nil || byID.ID != issued.ID {
+		t.Fatalf("AuthenticateID() = %+v, %v, want %q", byID, err, issued.ID)
+	}
 
 	if err := service.Deactivate(context.Background(), issued.ID); err != nil {
 		t.Fatalf("Deactivate() error = %v", err)
@@ -100,6 +103,13 @@ func TestServiceCreateAuthenticateAndDeactivate(t *testing.T) {
 		t.Fatalf("Authenticate() after deactivate error = %v, want %v", err, ErrInactive)
 	}
 
+	if _, err := service.AuthenticateID(context.Background(), issued.ID); err != ErrInactive {
+		t.Fatalf("AuthenticateID() after deactivate error = %v, want %v", err, ErrInactive)
+	}
+	if _, err := service.AuthenticateID(context.Background(), "missing"); err != ErrNotFound {
+		t.Fatalf("AuthenticateID(missing) error = %v, want %v", err, ErrNotFound)
+	}
+
 	views := service.ListViews()
 	if len(views) != 1 {
 		t.Fatalf("ListViews() len = %d, want 1", len(views))
@@ -256,3 +266,84 @@ func TestServiceCreateRejectsInvalidUserPath(t *testing.T) {
 		t.Fatalf("Create() error = %T, want validation error", err)
 	}
 }
+
+func TestServiceCreateRoleDefaultsAndValidation(t *testing.T) {
+	service, err := NewService(newTestStore(AuthKey{
+		ID:         "legacy",
+		Name:       "legacy",
+		SecretHash: hashSecret("legacy-secret"),
+		Enabled:    true,
+		CreatedAt:  time.Now().UTC(),
+	}))
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	legacy, err := service.Authenticate(context.Background(), TokenPrefix+"legacy-secret")
+	if err != nil {
+		t.Fatalf("Authenticate(legacy) error = %v", err)
+	}
+	if legacy.Role != RoleAdmin {
+		t.Fatalf("legacy role = %q, want admin for keys stored without a role", legacy.Role)
+	}
+
+	issued, err := service.Create(context.Background(), CreateInput{Name: "dashboard", Role: " Read_Usage "})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if issued.Role != RoleReadUsage {
+		t.Fatalf("issued.Role = %q, want read_usage", issued.Role)
+	}
+	authenticated, err := service.Authenticate(context.Background(), issued.Value)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authenticated.Role != RoleReadUsage {
+		t.Fatalf("Authenticate().Role = %q, want read_usage", authenticated.Role)
+	}
+
+	if _, err := service.Create(context.Background(), CreateInput{Name: "bad", Role: "superuser"}); !IsValidationError(err) {
+		t.Fatalf("Create() error = %v, want validation error for unknown role", err)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleReadAuditMetadata) || !RoleReadAuditMetadata.Allows(RoleReadUsage) {
+		t.Fatal("higher roles must include lower role access")
+	}
+	if RoleReadUsage.Allows(RoleReadAuditMetadata) || RoleReadAuditMetadata.Allows(RoleAdmin) {
+		t.Fatal("lower roles must not gain higher role access")
+	}
+	if Role("superuser").Allows(RoleReadUsage) {
+		t.Fatal("unknown roles must not be allowed anything")
+	}
+}
+
+func TestServiceCreateDataResidency(t *testing.T) {
+	service, err := NewService(newTestStore())
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+
+	issued, err := service.Create(context.Background(), CreateInput{Name: "eu", DataResidency: " EU "})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if issued.DataResidency != "eu" {
+		t.Fatalf("issued.DataResidency = %q, want eu", issued.DataResidency)
+	}
+	authenticated, err := service.Authenticate(context.Background(), issued.Value)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if authenticated.DataResidency != "eu" {
+		t.Fatalf("Authenticate().DataResidency = %q, want eu", authenticated.DataResidency)
+	}
+
+	if _, err := service.Create(context.Background(), CreateInput{Name: "bad", DataResidency: "eu west"}); !IsValidationError(err) {
+		t.Fatalf("Create() error = %v, want validation error for invalid data residency", err)
+	}
+}
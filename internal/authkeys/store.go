@@ -79,6 +79,16 @@ func normalizeCreateInput(input CreateInput) (CreateInput, error) {
 		return CreateInput{}, newValidationError("invalid user_path", err)
 	}
 	input.UserPath = userPath
+	role, ok := ParseRole(string(input.Role))
+	if !ok {
+		return CreateInput{}, newValidationError("invalid role: must be one of admin, read_usage, read_audit_metadata", nil)
+	}
+	input.Role = role
+	residency, err := core.NormalizeDataResidency(input.DataResidency)
+	if err != nil {
+		return CreateInput{}, newValidationError("invalid data_residency", err)
+	}
+	input.DataResidency = residency
 	if input.ExpiresAt != nil {
 		expiresAt := input.ExpiresAt.UTC()
 		now := time.Now().UTC()
@@ -15,6 +15,8 @@ type mongoAuthKeyDocument struct {
 	Name          string     `bson:"name"`
 	Description   string     `bson:"description,omitempty"`
 	UserPath      string     `bson:"user_path,omitempty"`
+	Role          string     `bson:"role,omitempty"`
+	DataResidency string     `bson:"data_residency,omitempty"`
 	RedactedValue string     `bson:"redacted_value"`
 	SecretHash    string     `bson:"secret_hash"`
 	Enabled       bool       `bson:"enabled"`
@@ -80,6 +82,8 @@ func (s *MongoDBStore) Create(ctx context.Context, key AuthKey) error {
 		Name:          key.Name,
 		Description:   key.Description,
 		UserPath:      key.UserPath,
+		Role:          string(key.Role),
+		DataResidency: key.DataResidency,
 		RedactedValue: key.RedactedValue,
 		SecretHash:    key.SecretHash,
 		Enabled:       key.Enabled,
@@ -125,6 +129,8 @@ func authKeyFromMongo(doc mongoAuthKeyDocument) AuthKey {
 		Name:          doc.Name,
 		Description:   doc.Description,
 		UserPath:      doc.UserPath,
+		Role:          Role(doc.Role),
+		DataResidency: doc.DataResidency,
 		RedactedValue: doc.RedactedValue,
 		SecretHash:    doc.SecretHash,
 		Enabled:       doc.Enabled,
@@ -25,12 +25,25 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the auth_keys table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS auth_keys (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
 			description TEXT NOT NULL DEFAULT '',
 			user_path TEXT,
+			role TEXT,
+			data_residency TEXT,
 			redacted_value TEXT NOT NULL,
 			secret_hash TEXT NOT NULL UNIQUE,
 			enabled BOOLEAN NOT NULL DEFAULT TRUE,
@@ -41,15 +54,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create auth_keys table: %w", err)
+		return fmt.Errorf("failed to create auth_keys table: %w", err)
 	}
 
 	migrations := []string{
 		`ALTER TABLE auth_keys ADD COLUMN IF NOT EXISTS user_path TEXT`,
+		`ALTER TABLE auth_keys ADD COLUMN IF NOT EXISTS role TEXT`,
+		`ALTER TABLE auth_keys ADD COLUMN IF NOT EXISTS data_residency TEXT`,
 	}
 	for _, migration := range migrations {
 		if _, err := pool.Exec(ctx, migration); err != nil {
-			return nil, fmt.Errorf("failed to run migration %q: %w", migration, err)
+			return fmt.Errorf("failed to run migration %q: %w", migration, err)
 		}
 	}
 	for _, index := range []string{
@@ -57,15 +72,15 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		`CREATE INDEX IF NOT EXISTS idx_auth_keys_created_at ON auth_keys(created_at DESC)`,
 	} {
 		if _, err := pool.Exec(ctx, index); err != nil {
-			return nil, fmt.Errorf("failed to create auth_keys index: %w", err)
+			return fmt.Errorf("failed to create auth_keys index: %w", err)
 		}
 	}
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 func (s *PostgreSQLStore) List(ctx context.Context) ([]AuthKey, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, name, description, user_path, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at
+		SELECT id, name, description, user_path, role, data_residency, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at
 		FROM auth_keys
 		ORDER BY created_at DESC, id ASC
 	`)
@@ -82,9 +97,9 @@ func (s *PostgreSQLStore) List(ctx context.Context) ([]AuthKey, error) {
 
 func (s *PostgreSQLStore) Create(ctx context.Context, key AuthKey) error {
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO auth_keys (id, name, description, user_path, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	`, key.ID, key.Name, key.Description, pgNullableString(key.UserPath), key.RedactedValue, key.SecretHash, key.Enabled, pgUnixOrNil(key.ExpiresAt), pgUnixOrNil(key.DeactivatedAt), key.CreatedAt.Unix(), key.UpdatedAt.Unix())
+		INSERT INTO auth_keys (id, name, description, user_path, role, data_residency, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, key.ID, key.Name, key.Description, pgNullableString(key.UserPath), pgNullableString(string(key.Role)), pgNullableString(key.DataResidency), key.RedactedValue, key.SecretHash, key.Enabled, pgUnixOrNil(key.ExpiresAt), pgUnixOrNil(key.DeactivatedAt), key.CreatedAt.Unix(), key.UpdatedAt.Unix())
 	if err != nil {
 		return fmt.Errorf("create auth key: %w", err)
 	}
@@ -115,6 +130,8 @@ func (s *PostgreSQLStore) Close() error {
 func scanPostgreSQLAuthKey(scanner authKeyScanner) (AuthKey, error) {
 	var key AuthKey
 	var userPath *string
+	var role *string
+	var dataResidency *string
 	var expiresAt *int64
 	var deactivatedAt *int64
 	var createdAt int64
@@ -124,6 +141,8 @@ func scanPostgreSQLAuthKey(scanner authKeyScanner) (AuthKey, error) {
 		&key.Name,
 		&key.Description,
 		&userPath,
+		&role,
+		&dataResidency,
 		&key.RedactedValue,
 		&key.SecretHash,
 		&key.Enabled,
@@ -138,6 +157,8 @@ func scanPostgreSQLAuthKey(scanner authKeyScanner) (AuthKey, error) {
 		return AuthKey{}, err
 	}
 	key.UserPath = derefTrimmedString(userPath)
+	key.Role = Role(derefTrimmedString(role))
+	key.DataResidency = derefTrimmedString(dataResidency)
 	key.ExpiresAt = int64PtrToTime(expiresAt)
 	key.DeactivatedAt = int64PtrToTime(deactivatedAt)
 	key.CreatedAt = time.Unix(createdAt, 0).UTC()
@@ -20,12 +20,25 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
-	_, err := db.Exec(`
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the auth_keys table and its indexes.
+// It is idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS auth_keys (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
 			description TEXT NOT NULL DEFAULT '',
 			user_path TEXT,
+			role TEXT,
+			data_residency TEXT,
 			redacted_value TEXT NOT NULL,
 			secret_hash TEXT NOT NULL UNIQUE,
 			enabled INTEGER NOT NULL DEFAULT 1,
@@ -36,32 +49,33 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create auth_keys table: %w", err)
+		return fmt.Errorf("failed to create auth_keys table: %w", err)
 	}
 
 	migrations := []string{
 		`ALTER TABLE auth_keys ADD COLUMN user_path TEXT`,
+		`ALTER TABLE auth_keys ADD COLUMN role TEXT`,
+		`ALTER TABLE auth_keys ADD COLUMN data_residency TEXT`,
 	}
 	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil && !isSQLiteDuplicateColumnError(err) {
-			return nil, fmt.Errorf("failed to run migration %q: %w", migration, err)
+		if _, err := db.ExecContext(ctx, migration); err != nil && !isSQLiteDuplicateColumnError(err) {
+			return fmt.Errorf("failed to run migration %q: %w", migration, err)
 		}
 	}
 	for _, index := range []string{
 		`CREATE INDEX IF NOT EXISTS idx_auth_keys_enabled ON auth_keys(enabled)`,
 		`CREATE INDEX IF NOT EXISTS idx_auth_keys_created_at ON auth_keys(created_at DESC)`,
 	} {
-		if _, err := db.Exec(index); err != nil {
-			return nil, fmt.Errorf("failed to create auth_keys index: %w", err)
+		if _, err := db.ExecContext(ctx, index); err != nil {
+			return fmt.Errorf("failed to create auth_keys index: %w", err)
 		}
 	}
-
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 func (s *SQLiteStore) List(ctx context.Context) ([]AuthKey, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, description, user_path, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at
+		SELECT id, name, description, user_path, role, data_residency, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at
 		FROM auth_keys
 		ORDER BY created_at DESC, id ASC
 	`)
@@ -78,9 +92,9 @@ func (s *SQLiteStore) List(ctx context.Context) ([]AuthKey, error) {
 
 func (s *SQLiteStore) Create(ctx context.Context, key AuthKey) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO auth_keys (id, name, description, user_path, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, key.ID, key.Name, key.Description, nullableString(key.UserPath), key.RedactedValue, key.SecretHash, boolToSQLite(key.Enabled), unixOrNil(key.ExpiresAt), unixOrNil(key.DeactivatedAt), key.CreatedAt.Unix(), key.UpdatedAt.Unix())
+		INSERT INTO auth_keys (id, name, description, user_path, role, data_residency, redacted_value, secret_hash, enabled, expires_at, deactivated_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.ID, key.Name, key.Description, nullableString(key.UserPath), nullableString(string(key.Role)), nullableString(key.DataResidency), key.RedactedValue, key.SecretHash, boolToSQLite(key.Enabled), unixOrNil(key.ExpiresAt), unixOrNil(key.DeactivatedAt), key.CreatedAt.Unix(), key.UpdatedAt.Unix())
 	if err != nil {
 		return fmt.Errorf("create auth key: %w", err)
 	}
@@ -115,6 +129,8 @@ func (s *SQLiteStore) Close() error {
 func scanSQLiteAuthKey(scanner authKeyScanner) (AuthKey, error) {
 	var key AuthKey
 	var userPath sql.NullString
+	var role sql.NullString
+	var dataResidency sql.NullString
 	var enabled int
 	var expiresAt sql.NullInt64
 	var deactivatedAt sql.NullInt64
@@ -125,6 +141,8 @@ func scanSQLiteAuthKey(scanner authKeyScanner) (AuthKey, error) {
 		&key.Name,
 		&key.Description,
 		&userPath,
+		&role,
+		&dataResidency,
 		&key.RedactedValue,
 		&key.SecretHash,
 		&enabled,
@@ -139,6 +157,8 @@ func scanSQLiteAuthKey(scanner authKeyScanner) (AuthKey, error) {
 		return AuthKey{}, err
 	}
 	key.UserPath = nullableStringValue(userPath)
+	key.Role = Role(nullableStringValue(role))
+	key.DataResidency = nullableStringValue(dataResidency)
 	key.Enabled = enabled != 0
 	key.ExpiresAt = unixPtr(expiresAt)
 	key.DeactivatedAt = unixPtr(deactivatedAt)
@@ -1,6 +1,9 @@
 package authkeys
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 const (
 	// TokenPrefix is the managed API key prefix returned to clients.
@@ -8,12 +11,56 @@ const (
 	secretBytes = 32
 )
 
+// Role scopes what a key may do on the admin API. Roles are ordered: each
+// role can also call every route open to the roles below it.
+type Role string
+
+const (
+	// RoleReadUsage can read usage, cache and model inventory endpoints.
+	RoleReadUsage Role = "read_usage"
+	// RoleReadAuditMetadata can additionally read audit log entries without
+	// their captured headers and bodies.
+	RoleReadAuditMetadata Role = "read_audit_metadata"
+	// RoleAdmin has full admin API access, including audit bodies and
+	// mutating endpoints.
+	RoleAdmin Role = "admin"
+)
+
+// RoleContextKey is the Echo context key under which the auth middleware
+// stores the Role of the calling key.
+const RoleContextKey = "auth_key_role"
+
+var roleRanks = map[Role]int{
+	RoleReadUsage:         1,
+	RoleReadAuditMetadata: 2,
+	RoleAdmin:             3,
+}
+
+// ParseRole normalizes a role name. An empty value yields RoleAdmin so keys
+// created before roles existed keep full access.
+func ParseRole(value string) (Role, bool) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	if role == "" {
+		return RoleAdmin, true
+	}
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// Allows reports whether r grants at least the access of required.
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
 // AuthKey is the persisted auth key record.
 type AuthKey struct {
 	ID            string     `json:"id" bson:"_id"`
 	Name          string     `json:"name" bson:"name"`
 	Description   string     `json:"description,omitempty" bson:"description,omitempty"`
 	UserPath      string     `json:"user_path,omitempty" bson:"user_path,omitempty"`
+	Role          Role       `json:"role" bson:"role,omitempty"`
+	DataResidency string     `json:"data_residency,omitempty" bson:"data_residency,omitempty"`
 	RedactedValue string     `json:"redacted_value" bson:"redacted_value"`
 	SecretHash    string     `json:"-" bson:"secret_hash"`
 	Enabled       bool       `json:"enabled" bson:"enabled"`
@@ -37,10 +84,12 @@ type IssuedKey struct {
 
 // CreateInput captures the admin request for issuing a new auth key.
 type CreateInput struct {
-	Name        string
-	Description string
-	UserPath    string
-	ExpiresAt   *time.Time
+	Name          string
+	Description   string
+	UserPath      string
+	Role          Role
+	DataResidency string
+	ExpiresAt     *time.Time
 }
 
 // Active reports whether the key can currently authenticate requests.
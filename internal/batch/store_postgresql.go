@@ -24,6 +24,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the batches table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS batches (
 			id TEXT PRIMARY KEY,
@@ -34,17 +45,16 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create batches table: %w", err)
+		return fmt.Errorf("failed to create batches table: %w", err)
 	}
 
 	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_batches_created_at ON batches(created_at DESC)"); err != nil {
-		return nil, fmt.Errorf("failed to create batches created_at index: %w", err)
+		return fmt.Errorf("failed to create batches created_at index: %w", err)
 	}
 	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_batches_status ON batches(status)"); err != nil {
-		return nil, fmt.Errorf("failed to create batches status index: %w", err)
+		return fmt.Errorf("failed to create batches status index: %w", err)
 	}
-
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 // Create inserts a new batch.
@@ -19,7 +19,18 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
-	_, err := db.Exec(`
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the batches table and its indexes. It
+// is idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS batches (
 			id TEXT PRIMARY KEY,
 			created_at INTEGER NOT NULL,
@@ -29,17 +40,16 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create batches table: %w", err)
+		return fmt.Errorf("failed to create batches table: %w", err)
 	}
 
-	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_batches_created_at ON batches(created_at DESC)"); err != nil {
-		return nil, fmt.Errorf("failed to create batches created_at index: %w", err)
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_batches_created_at ON batches(created_at DESC)"); err != nil {
+		return fmt.Errorf("failed to create batches created_at index: %w", err)
 	}
-	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_batches_status ON batches(status)"); err != nil {
-		return nil, fmt.Errorf("failed to create batches status index: %w", err)
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_batches_status ON batches(status)"); err != nil {
+		return fmt.Errorf("failed to create batches status index: %w", err)
 	}
-
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 // Create inserts a new batch.
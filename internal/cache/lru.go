@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// lruEntryOverhead estimates the bytes an entry takes beyond its key and
+// value: the list element, the entry and the map slot.
+const lruEntryOverhead = 128
+
+// LRUStore is an in-memory Store that evicts the least recently used entries
+// once it holds more than maxEntries entries or maxBytes bytes of keys and
+// values. A limit of zero or less disables that limit. Entries stored with a
+// positive TTL expire after it; a zero TTL keeps them until evicted.
+type LRUStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	order      *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e *lruEntry) size() int64 {
+	return int64(len(e.key) + len(e.value))
+}
+
+// NewLRUStore creates an in-memory LRU store with the given limits.
+func NewLRUStore(maxEntries int, maxBytes int64) *LRUStore {
+	return &LRUStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get retrieves value by key and marks it as recently used. It returns nil
+// for missing and expired keys.
+func (s *LRUStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		s.remove(elem)
+		return nil, nil
+	}
+	s.order.MoveToFront(elem)
+	cp := make([]byte, len(entry.value))
+	copy(cp, entry.value)
+	return cp, nil
+}
+
+// Set stores value, replacing any previous value of key, and evicts least
+// recently used entries until the store is within its limits. A value larger
+// than maxBytes on its own is not stored.
+func (s *LRUStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := &lruEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[key]; ok {
+		s.remove(elem)
+	}
+	if s.maxBytes > 0 && entry.size() > s.maxBytes {
+		return nil
+	}
+	s.items[key] = s.order.PushFront(entry)
+	s.bytes += entry.size()
+	for (s.maxEntries > 0 && s.order.Len() > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, including expired entries that
+// have not been read or evicted yet.
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Bytes returns the stored key and value bytes.
+func (s *LRUStore) Bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bytes
+}
+
+// MemoryUsage returns the number of entries and their estimated bytes,
+// including per-entry bookkeeping.
+func (s *LRUStore) MemoryUsage() (int, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len(), s.bytes + int64(s.order.Len())*lruEntryOverhead
+}
+
+// Evict drops the least recently used fraction of the entries, rounded up.
+func (s *LRUStore) Evict(fraction float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(int(math.Ceil(fraction*float64(s.order.Len()))), s.order.Len())
+	for range n {
+		s.remove(s.order.Back())
+	}
+}
+
+// Close is a no-op.
+func (s *LRUStore) Close() error {
+	return nil
+}
+
+func (s *LRUStore) remove(elem *list.Element) {
+	entry := s.order.Remove(elem).(*lruEntry)
+	delete(s.items, entry.key)
+	s.bytes -= entry.size()
+}
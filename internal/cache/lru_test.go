@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUStore_EvictsLeastRecentlyUsedByEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(2, 0)
+
+	_ = store.Set(ctx, "a", []byte("1"), 0)
+	_ = store.Set(ctx, "b", []byte("2"), 0)
+	if got, _ := store.Get(ctx, "a"); string(got) != "1" {
+		t.Fatalf("Get(a) = %q, want 1", got)
+	}
+	_ = store.Set(ctx, "c", []byte("3"), 0)
+
+	if got, _ := store.Get(ctx, "b"); got != nil {
+		t.Fatalf("Get(b) = %q, want evicted", got)
+	}
+	for key, want := range map[string]string{"a": "1", "c": "3"} {
+		if got, _ := store.Get(ctx, key); string(got) != want {
+			t.Fatalf("Get(%s) = %q, want %s", key, got, want)
+		}
+	}
+}
+
+func TestLRUStore_EvictsByBytes(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(0, 10)
+
+	_ = store.Set(ctx, "a", []byte("1234"), 0)
+	_ = store.Set(ctx, "b", []byte("1234"), 0)
+	if store.Len() != 2 || store.Bytes() != 10 {
+		t.Fatalf("Len() = %d, Bytes() = %d, want 2 and 10", store.Len(), store.Bytes())
+	}
+	_ = store.Set(ctx, "c", []byte("12"), 0)
+	if got, _ := store.Get(ctx, "a"); got != nil {
+		t.Fatalf("Get(a) = %q, want evicted", got)
+	}
+	if store.Bytes() != 8 {
+		t.Fatalf("Bytes() = %d, want 8", store.Bytes())
+	}
+
+	_ = store.Set(ctx, "huge", []byte("12345678901"), 0)
+	if got, _ := store.Get(ctx, "huge"); got != nil || store.Len() != 2 {
+		t.Fatalf("oversized value stored: %q, Len() = %d", got, store.Len())
+	}
+
+	_ = store.Set(ctx, "b", []byte("1"), 0)
+	if store.Len() != 2 || store.Bytes() != 5 {
+		t.Fatalf("after replace Len() = %d, Bytes() = %d, want 2 and 5", store.Len(), store.Bytes())
+	}
+}
+
+func TestLRUStore_EvictFraction(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRUStore(0, 0)
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		_ = store.Set(ctx, key, []byte("1234"), 0)
+	}
+	if entries, bytes := store.MemoryUsage(); entries != 5 || bytes != 5*(5+lruEntryOverhead) {
+		t.Fatalf("MemoryUsage() = %d, %d, want 5 entries of %d bytes", entries, bytes, 5+lruEntryOverhead)
+	}
+	_, _ = store.Get(ctx, "a")
+
+	// 40% of five entries: the two least recently used go.
+	store.Evict(0.4)
+	for key, want := range map[string]bool{"a": true, "b": false, "c": false, "d": true, "e": true} {
+		if got, _ := store.Get(ctx, key); (got != nil) != want {
+			t.Fatalf("Get(%s) = %q, want kept %v", key, got, want)
+		}
+	}
+
+	store.Evict(1)
+	if entries, bytes := store.MemoryUsage(); entries != 0 || bytes != 0 {
+		t.Fatalf("MemoryUsage() after Evict(1) = %d, %d, want empty", entries, bytes)
+	}
+}
+
+func TestLRUStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewLRUStore(0, 0)
+	store.now = func() time.Time { return now }
+
+	_ = store.Set(ctx, "short", []byte("1"), time.Minute)
+	_ = store.Set(ctx, "forever", []byte("2"), 0)
+	now = now.Add(time.Minute)
+
+	if got, _ := store.Get(ctx, "short"); got != nil {
+		t.Fatalf("Get(short) = %q, want expired", got)
+	}
+	if got, _ := store.Get(ctx, "forever"); string(got) != "2" {
+		t.Fatalf("Get(forever) = %q, want 2", got)
+	}
+	if store.Len() != 1 {
+		t.Fatalf("Len() = %d, want expired entry removed", store.Len())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
 // LocalCache implements Cache using local file storage.
@@ -14,6 +15,8 @@ import (
 type LocalCache struct {
 	mu       sync.RWMutex
 	filePath string
+	// policy bounds the cache directory; see Cleanup.
+	policy CleanupPolicy
 }
 
 // NewLocalCache creates a new local file-based cache.
@@ -68,9 +71,22 @@ func (c *LocalCache) Set(ctx context.Context, cache *ModelCache) error {
 		return fmt.Errorf("failed to marshal cache: %w", err)
 	}
 
-	// Write atomically using temp file + rename
-	tmpFile := c.filePath + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0o644); err != nil {
+	// Write atomically using a temp file + rename. The temp file name is
+	// unique so gateways sharing the directory never write into each other's.
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	tmpFile := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile, 0o644)
+	}
+	if err != nil {
+		os.Remove(tmpFile)
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 	if err := os.Rename(tmpFile, c.filePath); err != nil {
@@ -78,6 +94,11 @@ func (c *LocalCache) Set(ctx context.Context, cache *ModelCache) error {
 		return fmt.Errorf("failed to rename cache file: %w", err)
 	}
 
+	if c.policy.enabled() {
+		if _, err := c.cleanupLocked(time.Now()); err != nil {
+			return fmt.Errorf("failed to clean up cache directory: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,307 @@
+package modelcache
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the cache file layout. Cache files carry it
+// in their name (models.v1.json); files of any other version are never read
+// and are removed by Cleanup, so a directory shared across gateway versions
+// does not accumulate them.
+const SchemaVersion = 1
+
+// legacyFileName is the unversioned model cache file written before the
+// schema version was part of the name. Its layout is schema version 1.
+const legacyFileName = "models.json"
+
+// tmpFileGrace is how long a temp file may exist before Cleanup treats it as
+// left behind by a failed write rather than a write in progress.
+const tmpFileGrace = time.Minute
+
+// Reasons a file is removed from the cache directory.
+const (
+	RemovalStaleSchema = "stale_schema"
+	RemovalExpired     = "expired"
+	RemovalSizeCap     = "size_cap"
+	RemovalAbandoned   = "abandoned_temp_file"
+	RemovalPurged      = "purged"
+)
+
+var (
+	// cacheFilePattern matches versioned cache files such as models.v1.json.
+	cacheFilePattern = regexp.MustCompile(`^[a-z0-9_-]+\.v(\d+)\.json$`)
+	// cacheTempFilePattern matches the temp files cache writes rename from.
+	cacheTempFilePattern = regexp.MustCompile(`^[a-z0-9_-]+(\.v\d+)?\.json\.(\d+\.)?tmp$`)
+)
+
+// LocalCacheFileName returns the model cache file name of the current schema
+// version.
+func LocalCacheFileName() string {
+	return fmt.Sprintf("models.v%d.json", SchemaVersion)
+}
+
+// CleanupPolicy bounds a local cache directory. Zero fields disable a check.
+type CleanupPolicy struct {
+	// MaxAge removes cache files not rewritten for longer.
+	MaxAge time.Duration
+	// MaxBytes caps the total size of the cache files. The oldest files are
+	// evicted first; the model cache file itself never is.
+	MaxBytes int64
+}
+
+func (p CleanupPolicy) enabled() bool {
+	return p.MaxAge > 0 || p.MaxBytes > 0
+}
+
+// NewManagedLocalCache creates a local cache that stores the current schema's
+// model cache file in dir and keeps the directory within policy. Callers run
+// Cleanup once at startup; Set enforces the policy after every write.
+func NewManagedLocalCache(dir string, policy CleanupPolicy) *LocalCache {
+	return &LocalCache{
+		filePath: filepath.Join(dir, LocalCacheFileName()),
+		policy:   policy,
+	}
+}
+
+// CacheFile describes one file in the cache directory.
+type CacheFile struct {
+	Name       string    `json:"name"`
+	SizeBytes  int64     `json:"size_bytes"`
+	ModifiedAt time.Time `json:"modified_at"`
+	AgeSeconds int64     `json:"age_seconds"`
+	// SchemaVersion is the version in the file name; 0 for temp files and the
+	// legacy unversioned models.json.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// Current marks the model cache file the gateway reads and writes.
+	Current bool `json:"current"`
+}
+
+// CacheDirReport lists the files of a local cache directory, oldest first.
+type CacheDirReport struct {
+	Dir           string      `json:"dir"`
+	SchemaVersion int         `json:"schema_version"`
+	CurrentFile   string      `json:"current_file"`
+	TotalBytes    int64       `json:"total_bytes"`
+	MaxBytes      int64       `json:"max_bytes,omitempty"`
+	MaxAgeSeconds int64       `json:"max_age_seconds,omitempty"`
+	Files         []CacheFile `json:"files"`
+}
+
+// RemovedCacheFile is a file Cleanup or Purge deleted, with the reason.
+type RemovedCacheFile struct {
+	CacheFile
+	Reason string `json:"reason"`
+}
+
+// Files reports the cache files in the directory.
+func (c *LocalCache) Files(now time.Time) (*CacheDirReport, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	files, err := c.listLocked(now)
+	if err != nil {
+		return nil, err
+	}
+	report := &CacheDirReport{
+		Dir:           filepath.Dir(c.filePath),
+		SchemaVersion: SchemaVersion,
+		CurrentFile:   filepath.Base(c.filePath),
+		MaxBytes:      c.policy.MaxBytes,
+		MaxAgeSeconds: int64(c.policy.MaxAge / time.Second),
+		Files:         files,
+	}
+	for _, file := range files {
+		report.TotalBytes += file.SizeBytes
+	}
+	return report, nil
+}
+
+// Cleanup brings the cache directory within the policy. It adopts a legacy
+// models.json as the current file when there is none yet, then removes files
+// of other schema versions, temp files abandoned by failed writes, files older
+// than MaxAge and, oldest first, files beyond MaxBytes. Only files named like
+// cache files are considered, so other data in the directory is left alone.
+func (c *LocalCache) Cleanup(now time.Time) ([]RemovedCacheFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.adoptLegacyLocked(); err != nil {
+		return nil, err
+	}
+	return c.cleanupLocked(now)
+}
+
+// Purge removes every cache file, the current one included. The registry
+// writes the model cache again on its next refresh.
+func (c *LocalCache) Purge(now time.Time) ([]RemovedCacheFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.listLocked(now)
+	if err != nil {
+		return nil, err
+	}
+	removals := make([]RemovedCacheFile, 0, len(files))
+	for _, file := range files {
+		removals = append(removals, RemovedCacheFile{CacheFile: file, Reason: RemovalPurged})
+	}
+	return c.removeLocked(removals)
+}
+
+func (c *LocalCache) cleanupLocked(now time.Time) ([]RemovedCacheFile, error) {
+	files, err := c.listLocked(now)
+	if err != nil {
+		return nil, err
+	}
+	return c.removeLocked(planCleanup(files, c.policy, now))
+}
+
+// adoptLegacyLocked renames a legacy models.json to the current file name, so
+// upgrading keeps the warm cache. It only applies while the schema is still
+// the layout the legacy file was written in.
+func (c *LocalCache) adoptLegacyLocked() error {
+	if c.filePath == "" || SchemaVersion != 1 {
+		return nil
+	}
+	legacy := filepath.Join(filepath.Dir(c.filePath), legacyFileName)
+	if legacy == c.filePath {
+		return nil
+	}
+	if _, err := os.Stat(c.filePath); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := os.Rename(legacy, c.filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to adopt legacy cache file: %w", err)
+	}
+	return nil
+}
+
+// planCleanup decides which of files, sorted oldest first, Cleanup removes.
+func planCleanup(files []CacheFile, policy CleanupPolicy, now time.Time) []RemovedCacheFile {
+	var removals []RemovedCacheFile
+	kept := make([]CacheFile, 0, len(files))
+	for _, file := range files {
+		age := now.Sub(file.ModifiedAt)
+		switch {
+		case isTempFileName(file.Name) && age < tmpFileGrace:
+			kept = append(kept, file)
+		case isTempFileName(file.Name):
+			removals = append(removals, RemovedCacheFile{CacheFile: file, Reason: RemovalAbandoned})
+		case file.SchemaVersion != SchemaVersion && !file.Current:
+			removals = append(removals, RemovedCacheFile{CacheFile: file, Reason: RemovalStaleSchema})
+		case policy.MaxAge > 0 && age > policy.MaxAge:
+			removals = append(removals, RemovedCacheFile{CacheFile: file, Reason: RemovalExpired})
+		default:
+			kept = append(kept, file)
+		}
+	}
+
+	if policy.MaxBytes <= 0 {
+		return removals
+	}
+	var total int64
+	for _, file := range kept {
+		total += file.SizeBytes
+	}
+	for _, file := range kept {
+		if total <= policy.MaxBytes {
+			break
+		}
+		// The model cache file and writes in progress are never evicted.
+		if file.Current || isTempFileName(file.Name) {
+			continue
+		}
+		removals = append(removals, RemovedCacheFile{CacheFile: file, Reason: RemovalSizeCap})
+		total -= file.SizeBytes
+	}
+	return removals
+}
+
+func (c *LocalCache) removeLocked(removals []RemovedCacheFile) ([]RemovedCacheFile, error) {
+	dir := filepath.Dir(c.filePath)
+	removed := make([]RemovedCacheFile, 0, len(removals))
+	var errs []error
+	for _, removal := range removals {
+		if err := os.Remove(filepath.Join(dir, removal.Name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove cache file %s: %w", removal.Name, err))
+			continue
+		}
+		removed = append(removed, removal)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// listLocked returns the cache files in the directory, oldest first.
+func (c *LocalCache) listLocked(now time.Time) ([]CacheFile, error) {
+	if c.filePath == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Dir(c.filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []CacheFile{}, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	current := filepath.Base(c.filePath)
+	files := make([]CacheFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		version, ok := cacheFileVersion(name)
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat cache file %s: %w", name, err)
+		}
+		modified := info.ModTime().UTC()
+		files = append(files, CacheFile{
+			Name:          name,
+			SizeBytes:     info.Size(),
+			ModifiedAt:    modified,
+			AgeSeconds:    max(int64(now.Sub(modified)/time.Second), 0),
+			SchemaVersion: version,
+			Current:       name == current,
+		})
+	}
+	slices.SortFunc(files, func(a, b CacheFile) int {
+		return cmp.Or(a.ModifiedAt.Compare(b.ModifiedAt), strings.Compare(a.Name, b.Name))
+	})
+	return files, nil
+}
+
+// cacheFileVersion reports whether name is a cache file, a temp file of one
+// or the legacy models.json, and the schema version in its name.
+func cacheFileVersion(name string) (int, bool) {
+	if name == legacyFileName || isTempFileName(name) {
+		return 0, true
+	}
+	match := cacheFilePattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+func isTempFileName(name string) bool {
+	return cacheTempFilePattern.MatchString(name)
+}
@@ -0,0 +1,238 @@
+package modelcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCacheFile creates name in dir with size bytes, last modified age ago.
+func writeCacheFile(t *testing.T, dir, name string, size int, age time.Duration, now time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	modified := now.Add(-age)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("chtimes %s: %v", name, err)
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func removalReasons(removed []RemovedCacheFile) map[string]string {
+	reasons := make(map[string]string, len(removed))
+	for _, file := range removed {
+		reasons[file.Name] = file.Reason
+	}
+	return reasons
+}
+
+func TestLocalCacheCleanup(t *testing.T) {
+	now := time.Now()
+
+	t.Run("RemovesStaleSchemaAndAbandonedTempFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, "models.v1.json", 100, time.Hour, now)
+		writeCacheFile(t, dir, "models.v0.json", 100, time.Hour, now)
+		writeCacheFile(t, dir, "models.v2.json", 100, time.Minute, now)
+		writeCacheFile(t, dir, "models.v1.json.123.tmp", 10, time.Hour, now)
+		writeCacheFile(t, dir, "models.v1.json.456.tmp", 10, time.Second, now)
+		writeCacheFile(t, dir, "notes.txt", 10, 48*time.Hour, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{}).Cleanup(now)
+		if err != nil {
+			t.Fatalf("Cleanup() error = %v", err)
+		}
+		want := map[string]string{
+			"models.v0.json":         RemovalStaleSchema,
+			"models.v2.json":         RemovalStaleSchema,
+			"models.v1.json.123.tmp": RemovalAbandoned,
+		}
+		if got := removalReasons(removed); len(got) != len(want) || got["models.v0.json"] != want["models.v0.json"] ||
+			got["models.v2.json"] != want["models.v2.json"] || got["models.v1.json.123.tmp"] != want["models.v1.json.123.tmp"] {
+			t.Fatalf("removed = %v, want %v", got, want)
+		}
+		if got := dirNames(t, dir); !slices.Equal(got, []string{"models.v1.json", "models.v1.json.456.tmp", "notes.txt"}) {
+			t.Fatalf("remaining = %v, want the current file, the write in progress and the unrelated file", got)
+		}
+	})
+
+	t.Run("AdoptsLegacyFile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, legacyFileName, 100, time.Hour, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{}).Cleanup(now)
+		if err != nil || len(removed) != 0 {
+			t.Fatalf("Cleanup() = %v, %v; want nothing removed", removed, err)
+		}
+		if got := dirNames(t, dir); !slices.Equal(got, []string{LocalCacheFileName()}) {
+			t.Fatalf("remaining = %v, want the legacy file renamed to %s", got, LocalCacheFileName())
+		}
+	})
+
+	t.Run("RemovesLegacyFileNextToCurrent", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, legacyFileName, 100, time.Hour, now)
+		writeCacheFile(t, dir, LocalCacheFileName(), 100, time.Minute, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{}).Cleanup(now)
+		if err != nil || removalReasons(removed)[legacyFileName] != RemovalStaleSchema {
+			t.Fatalf("Cleanup() = %v, %v; want the legacy file removed as stale", removed, err)
+		}
+	})
+
+	t.Run("RemovesExpiredFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, "models.v1.json", 100, 10*24*time.Hour, now)
+		writeCacheFile(t, dir, "pricing.v1.json", 100, time.Hour, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{MaxAge: 7 * 24 * time.Hour}).Cleanup(now)
+		if err != nil {
+			t.Fatalf("Cleanup() error = %v", err)
+		}
+		if got := removalReasons(removed); len(got) != 1 || got["models.v1.json"] != RemovalExpired {
+			t.Fatalf("removed = %v, want the expired models.v1.json", got)
+		}
+	})
+
+	t.Run("EvictsOldestFirstOverSizeCap", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, "models.v1.json", 400, 4*time.Hour, now)
+		writeCacheFile(t, dir, "pricing.v1.json", 300, 3*time.Hour, now)
+		writeCacheFile(t, dir, "catalog.v1.json", 300, 2*time.Hour, now)
+		writeCacheFile(t, dir, "latest.v1.json", 300, time.Hour, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{MaxBytes: 800}).Cleanup(now)
+		if err != nil {
+			t.Fatalf("Cleanup() error = %v", err)
+		}
+		got := removalReasons(removed)
+		if len(got) != 2 || got["pricing.v1.json"] != RemovalSizeCap || got["catalog.v1.json"] != RemovalSizeCap {
+			t.Fatalf("removed = %v, want the two oldest artifacts evicted, never the older model cache file", got)
+		}
+	})
+
+	t.Run("KeepsOversizedCurrentFile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeCacheFile(t, dir, "models.v1.json", 5000, time.Hour, now)
+
+		removed, err := NewManagedLocalCache(dir, CleanupPolicy{MaxBytes: 1024}).Cleanup(now)
+		if err != nil || len(removed) != 0 {
+			t.Fatalf("Cleanup() = %v, %v; want the model cache file kept", removed, err)
+		}
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		cache := NewManagedLocalCache(filepath.Join(t.TempDir(), "missing"), CleanupPolicy{MaxBytes: 1024})
+		if removed, err := cache.Cleanup(now); err != nil || len(removed) != 0 {
+			t.Fatalf("Cleanup() = %v, %v; want nothing to do", removed, err)
+		}
+		report, err := cache.Files(now)
+		if err != nil || report.TotalBytes != 0 || len(report.Files) != 0 {
+			t.Fatalf("Files() = %+v, %v; want an empty report", report, err)
+		}
+	})
+}
+
+func TestLocalCacheFilesAndPurge(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	writeCacheFile(t, dir, "models.v1.json", 100, time.Hour, now)
+	writeCacheFile(t, dir, "models.v0.json", 50, 2*time.Hour, now)
+	writeCacheFile(t, dir, "notes.txt", 10, time.Hour, now)
+	cache := NewManagedLocalCache(dir, CleanupPolicy{MaxAge: 24 * time.Hour, MaxBytes: 4096})
+
+	report, err := cache.Files(now)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if report.CurrentFile != "models.v1.json" || report.SchemaVersion != SchemaVersion || report.TotalBytes != 150 ||
+		report.MaxBytes != 4096 || report.MaxAgeSeconds != 86400 || len(report.Files) != 2 {
+		t.Fatalf("report = %+v, want both cache files and the bounds", report)
+	}
+	if f := report.Files[0]; f.Name != "models.v0.json" || f.Current || f.SchemaVersion != 0 || f.AgeSeconds != 7200 {
+		t.Fatalf("oldest file = %+v, want models.v0.json aged 2h", f)
+	}
+	if f := report.Files[1]; f.Name != "models.v1.json" || !f.Current || f.SchemaVersion != 1 || f.SizeBytes != 100 {
+		t.Fatalf("newest file = %+v, want the current models.v1.json", f)
+	}
+
+	removed, err := cache.Purge(now)
+	if err != nil || len(removed) != 2 || removed[0].Reason != RemovalPurged {
+		t.Fatalf("Purge() = %v, %v; want both cache files purged", removed, err)
+	}
+	if got := dirNames(t, dir); !slices.Equal(got, []string{"notes.txt"}) {
+		t.Fatalf("remaining = %v, want only the unrelated file", got)
+	}
+}
+
+func TestLocalCacheSetEnforcesPolicy(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	writeCacheFile(t, dir, "models.v2.json", 100, time.Hour, now)
+	writeCacheFile(t, dir, "pricing.v1.json", 2000, time.Hour, now)
+	cache := NewManagedLocalCache(dir, CleanupPolicy{MaxBytes: 1024})
+
+	if err := cache.Set(context.Background(), &ModelCache{Providers: map[string]CachedProvider{}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := dirNames(t, dir); !slices.Equal(got, []string{LocalCacheFileName()}) {
+		t.Fatalf("remaining = %v, want only the freshly written cache file", got)
+	}
+}
+
+func TestLocalCacheConcurrentSetAndCleanup(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewManagedLocalCache(dir, CleanupPolicy{MaxAge: time.Hour, MaxBytes: 1024})
+	ctx := context.Background()
+	if err := cache.Set(ctx, &ModelCache{Providers: map[string]CachedProvider{}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := cache.Set(ctx, &ModelCache{Providers: map[string]CachedProvider{}}); err != nil {
+					t.Errorf("Set() error = %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := cache.Cleanup(time.Now()); err != nil {
+					t.Errorf("Cleanup() error = %v", err)
+				}
+				if got, err := cache.Get(ctx); err != nil || got == nil {
+					t.Errorf("Get() = %v, %v; want the cache every time", got, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := dirNames(t, dir); !slices.Equal(got, []string{LocalCacheFileName()}) {
+		t.Fatalf("remaining = %v, want no temp files left behind", got)
+	}
+}
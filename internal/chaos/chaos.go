@@ -0,0 +1,257 @@
+// Package chaos injects faults into gateway traffic for resilience testing.
+//
+// Fault injection only exists when it is enabled in config: New returns a nil
+// Injector otherwise, the server does not install its middleware, and streams
+// are only wrapped when the middleware marked the request context. Rules are
+// evaluated in order; the first rule that matches a request and wins its
+// probability roll applies. A kill switch turns every rule off at once,
+// including for streams already in flight.
+package chaos
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// Header is set on every response the injector faulted. Its value is the
+// applied effect.
+const Header = "X-GoModel-Chaos"
+
+// errorCode is the error code of injected error responses.
+const errorCode = "chaos_fault_injected"
+
+// Effect is the fault a rule injects.
+type Effect string
+
+const (
+	// EffectLatency delays the request before it is handled.
+	EffectLatency Effect = "latency"
+	// EffectError answers with an OpenAI-shaped error instead of calling the
+	// provider.
+	EffectError Effect = "error"
+	// EffectTruncateStream ends a stream after AfterChunks events.
+	EffectTruncateStream Effect = "truncate_stream"
+	// EffectCorruptStream cuts the payload of a stream's final event in half.
+	EffectCorruptStream Effect = "corrupt_stream"
+)
+
+// Stream reports whether the effect applies to streamed responses only.
+func (e Effect) Stream() bool {
+	return e == EffectTruncateStream || e == EffectCorruptStream
+}
+
+// Rule is one fault injection rule. Empty Path, Model and Provider match every
+// request; a Path ending in "*" matches by prefix.
+type Rule struct {
+	Name        string  `json:"name"`
+	Path        string  `json:"path,omitempty"`
+	Model       string  `json:"model,omitempty"`
+	Provider    string  `json:"provider,omitempty"`
+	Probability float64 `json:"probability"`
+	Effect      Effect  `json:"effect"`
+	LatencyMS   int64   `json:"latency_ms,omitempty"`
+	Status      int     `json:"status,omitempty"`
+	Message     string  `json:"message,omitempty"`
+	AfterChunks int     `json:"after_chunks,omitempty"`
+}
+
+// Latency returns the delay of a latency rule.
+func (r Rule) Latency() time.Duration {
+	return time.Duration(r.LatencyMS) * time.Millisecond
+}
+
+// Error returns the error an error rule answers with. It is classified like
+// an upstream error with the rule's status.
+func (r Rule) Error() *core.GatewayError {
+	gatewayErr := core.ParseProviderError("", r.Status, nil, nil)
+	gatewayErr.Message = r.Message
+	gatewayErr.ProviderError = nil
+	gatewayErr.UpstreamStatusCode = 0
+	return gatewayErr.WithCode(errorCode)
+}
+
+// Target describes the request rules are matched against. Models and
+// Providers hold every name the request is known by, such as the requested and
+// resolved model or the provider type and configured provider name.
+type Target struct {
+	Path      string
+	Models    []string
+	Providers []string
+}
+
+// Injector holds the active rules and the kill switch.
+type Injector struct {
+	rules  atomic.Pointer[[]Rule]
+	killed atomic.Bool
+	roll   func() float64
+}
+
+// New builds an Injector from cfg. It returns nil when fault injection is not
+// enabled, so none of its code runs.
+func New(cfg config.ChaosConfig) (*Injector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	rules := make([]Rule, 0, len(cfg.Rules))
+	for _, ruleCfg := range cfg.Rules {
+		rules = append(rules, Rule{
+			Name:        ruleCfg.Name,
+			Path:        ruleCfg.Path,
+			Model:       ruleCfg.Model,
+			Provider:    ruleCfg.Provider,
+			Probability: ruleCfg.Probability,
+			Effect:      Effect(ruleCfg.Effect),
+			LatencyMS:   ruleCfg.Latency.Milliseconds(),
+			Status:      ruleCfg.Status,
+			Message:     ruleCfg.Message,
+			AfterChunks: ruleCfg.AfterChunks,
+		})
+	}
+	injector := &Injector{roll: rand.Float64}
+	if _, err := injector.SetRules(rules); err != nil {
+		return nil, err
+	}
+	return injector, nil
+}
+
+// Rules returns a copy of the active rules.
+func (i *Injector) Rules() []Rule {
+	if i == nil {
+		return nil
+	}
+	if rules := i.rules.Load(); rules != nil {
+		return append([]Rule(nil), (*rules)...)
+	}
+	return []Rule{}
+}
+
+// SetRules validates rules and replaces the active set. It returns the
+// normalized rules that are now active.
+func (i *Injector) SetRules(rules []Rule) ([]Rule, error) {
+	if i == nil {
+		return nil, fmt.Errorf("fault injection is not enabled")
+	}
+	normalized := make([]Rule, 0, len(rules))
+	names := make(map[string]struct{}, len(rules))
+	for index, rule := range rules {
+		rule, err := normalizeRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", index, err)
+		}
+		if _, exists := names[rule.Name]; exists {
+			return nil, fmt.Errorf("rules[%d]: duplicate rule name %q", index, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+		normalized = append(normalized, rule)
+	}
+	i.rules.Store(&normalized)
+	return append([]Rule(nil), normalized...), nil
+}
+
+// Active reports whether faults are injected, that is the injector exists and
+// the kill switch is off.
+func (i *Injector) Active() bool {
+	return i != nil && !i.killed.Load()
+}
+
+// SetActive flips the kill switch. Deactivating stops injection immediately,
+// and streams already being faulted pass through unchanged from then on.
+func (i *Injector) SetActive(active bool) {
+	if i == nil {
+		return
+	}
+	i.killed.Store(!active)
+}
+
+// Match returns the first rule that matches target and wins its probability
+// roll.
+func (i *Injector) Match(target Target) (Rule, bool) {
+	if !i.Active() {
+		return Rule{}, false
+	}
+	rules := i.rules.Load()
+	if rules == nil {
+		return Rule{}, false
+	}
+	for _, rule := range *rules {
+		if !rule.matches(target) {
+			continue
+		}
+		if rule.Probability >= 1 || i.roll() < rule.Probability {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (r Rule) matches(target Target) bool {
+	if r.Path != "" {
+		if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+			if !strings.HasPrefix(target.Path, prefix) {
+				return false
+			}
+		} else if target.Path != r.Path {
+			return false
+		}
+	}
+	return matchesAny(r.Model, target.Models) && matchesAny(r.Provider, target.Providers)
+}
+
+func matchesAny(want string, values []string) bool {
+	if want == "" {
+		return true
+	}
+	for _, value := range values {
+		if strings.TrimSpace(value) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeRule(rule Rule) (Rule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Path = strings.TrimSpace(rule.Path)
+	rule.Model = strings.TrimSpace(rule.Model)
+	rule.Provider = strings.TrimSpace(rule.Provider)
+	rule.Effect = Effect(strings.ToLower(strings.TrimSpace(string(rule.Effect))))
+	rule.Message = strings.TrimSpace(rule.Message)
+	if rule.Name == "" {
+		return Rule{}, fmt.Errorf("name is required")
+	}
+	if rule.Probability < 0 || rule.Probability > 1 {
+		return Rule{}, fmt.Errorf("rule %q: probability must be between 0 and 1", rule.Name)
+	}
+	if rule.Probability == 0 {
+		rule.Probability = 1
+	}
+
+	switch rule.Effect {
+	case EffectLatency:
+		if rule.LatencyMS <= 0 {
+			return Rule{}, fmt.Errorf("rule %q: latency must be positive", rule.Name)
+		}
+	case EffectError:
+		if rule.Status < http.StatusBadRequest || rule.Status > 599 {
+			return Rule{}, fmt.Errorf("rule %q: status must be a 4xx or 5xx code", rule.Name)
+		}
+		if rule.Message == "" {
+			rule.Message = "fault injected by chaos rule " + rule.Name
+		}
+	case EffectTruncateStream:
+		if rule.AfterChunks < 0 {
+			return Rule{}, fmt.Errorf("rule %q: after_chunks must not be negative", rule.Name)
+		}
+	case EffectCorruptStream:
+	default:
+		return Rule{}, fmt.Errorf("rule %q: unknown effect %q, expected latency, error, truncate_stream or corrupt_stream", rule.Name, rule.Effect)
+	}
+	return rule, nil
+}
@@ -0,0 +1,188 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+const testStream = "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\ndata: {\"id\":\"3\"}\n\ndata: [DONE]\n\n"
+
+func newTestInjector(t *testing.T, rules ...config.ChaosRuleConfig) *Injector {
+	t.Helper()
+	injector, err := New(config.ChaosConfig{Enabled: true, Rules: rules})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return injector
+}
+
+func TestNewDisabledReturnsNil(t *testing.T) {
+	injector, err := New(config.ChaosConfig{Rules: []config.ChaosRuleConfig{{Name: "slow", Effect: "latency", Latency: time.Second}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if injector != nil {
+		t.Fatal("New() returned an injector while disabled")
+	}
+	if injector.Active() {
+		t.Fatal("nil injector reports active")
+	}
+	if _, ok := injector.Match(Target{Path: "/v1/chat/completions"}); ok {
+		t.Fatal("nil injector matched a rule")
+	}
+}
+
+func TestNewValidatesRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.ChaosRuleConfig
+	}{
+		{name: "missing name", rule: config.ChaosRuleConfig{Effect: "latency", Latency: time.Second}},
+		{name: "unknown effect", rule: config.ChaosRuleConfig{Name: "r", Effect: "explode"}},
+		{name: "latency without duration", rule: config.ChaosRuleConfig{Name: "r", Effect: "latency"}},
+		{name: "error with success status", rule: config.ChaosRuleConfig{Name: "r", Effect: "error", Status: 200}},
+		{name: "probability above one", rule: config.ChaosRuleConfig{Name: "r", Effect: "corrupt_stream", Probability: 1.5}},
+		{name: "negative after_chunks", rule: config.ChaosRuleConfig{Name: "r", Effect: "truncate_stream", AfterChunks: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(config.ChaosConfig{Enabled: true, Rules: []config.ChaosRuleConfig{tt.rule}}); err == nil {
+				t.Fatal("New() error = nil, want validation error")
+			}
+		})
+	}
+
+	injector := newTestInjector(t, config.ChaosRuleConfig{Name: "dup", Effect: "corrupt_stream"})
+	if _, err := injector.SetRules([]Rule{{Name: "dup", Effect: EffectCorruptStream}, {Name: "dup", Effect: EffectCorruptStream}}); err == nil {
+		t.Fatal("SetRules() accepted duplicate names")
+	}
+	if got := injector.Rules(); len(got) != 1 {
+		t.Fatalf("rules after rejected update = %v, want the previous rules kept", got)
+	}
+}
+
+func TestInjectorMatch(t *testing.T) {
+	injector := newTestInjector(t,
+		config.ChaosRuleConfig{Name: "chat-only", Path: "/v1/chat/completions", Model: "gpt-4o", Effect: "error", Status: 503},
+		config.ChaosRuleConfig{Name: "any-v1", Path: "/v1/*", Provider: "anthropic", Effect: "latency", Latency: time.Millisecond},
+	)
+
+	rule, ok := injector.Match(Target{Path: "/v1/chat/completions", Models: []string{"gpt-4o"}, Providers: []string{"openai"}})
+	if !ok || rule.Name != "chat-only" || rule.Probability != 1 {
+		t.Fatalf("Match() = %+v, %v, want chat-only with default probability 1", rule, ok)
+	}
+	rule, ok = injector.Match(Target{Path: "/v1/responses", Models: []string{"claude"}, Providers: []string{"anthropic"}})
+	if !ok || rule.Name != "any-v1" {
+		t.Fatalf("Match() = %+v, %v, want any-v1 by path prefix and provider", rule, ok)
+	}
+	if _, ok := injector.Match(Target{Path: "/v1/responses", Providers: []string{"openai"}}); ok {
+		t.Fatal("Match() matched a request no rule targets")
+	}
+
+	injector.SetActive(false)
+	if _, ok := injector.Match(Target{Path: "/v1/chat/completions", Models: []string{"gpt-4o"}}); ok {
+		t.Fatal("Match() matched while the kill switch is on")
+	}
+	injector.SetActive(true)
+	if _, ok := injector.Match(Target{Path: "/v1/chat/completions", Models: []string{"gpt-4o"}}); !ok {
+		t.Fatal("Match() did not match after the kill switch was released")
+	}
+}
+
+func TestInjectorMatchProbability(t *testing.T) {
+	injector := newTestInjector(t, config.ChaosRuleConfig{Name: "flaky", Effect: "error", Status: 500, Probability: 0.25})
+
+	injector.roll = func() float64 { return 0.3 }
+	if _, ok := injector.Match(Target{Path: "/v1/chat/completions"}); ok {
+		t.Fatal("Match() applied a rule that lost its roll")
+	}
+	injector.roll = func() float64 { return 0.2 }
+	if _, ok := injector.Match(Target{Path: "/v1/chat/completions"}); !ok {
+		t.Fatal("Match() skipped a rule that won its roll")
+	}
+}
+
+func TestRuleError(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantType core.ErrorType
+	}{
+		{status: http.StatusTooManyRequests, wantType: core.ErrorTypeRateLimit},
+		{status: http.StatusServiceUnavailable, wantType: core.ErrorTypeProvider},
+		{status: http.StatusBadRequest, wantType: core.ErrorTypeInvalidRequest},
+	}
+	for _, tt := range tests {
+		injector := newTestInjector(t, config.ChaosRuleConfig{Name: "boom", Effect: "error", Status: tt.status})
+		err := injector.Rules()[0].Error()
+		if err.HTTPStatusCode() != tt.status || err.Type != tt.wantType {
+			t.Fatalf("Error() = (%d, %s), want (%d, %s)", err.HTTPStatusCode(), err.Type, tt.status, tt.wantType)
+		}
+		body := err.ToJSON()["error"].(map[string]any)
+		if body["code"] != errorCode || body["message"] != "fault injected by chaos rule boom" {
+			t.Fatalf("error body = %v, want chaos code and default message", body)
+		}
+	}
+}
+
+func readFaultedStream(t *testing.T, injector *Injector, rule Rule) string {
+	t.Helper()
+	ctx := WithStreamFault(context.Background(), injector, rule)
+	stream, applied, ok := WrapStream(ctx, io.NopCloser(strings.NewReader(testStream)))
+	if !ok || applied.Name != rule.Name {
+		t.Fatalf("WrapStream() applied = %+v, %v, want rule %q", applied, ok, rule.Name)
+	}
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(body)
+}
+
+func TestWrapStreamTruncate(t *testing.T) {
+	injector := newTestInjector(t)
+	got := readFaultedStream(t, injector, Rule{Name: "cut", Effect: EffectTruncateStream, AfterChunks: 2})
+	if want := "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\n"; got != want {
+		t.Fatalf("truncated stream = %q, want %q", got, want)
+	}
+}
+
+func TestWrapStreamCorruptFinalChunk(t *testing.T) {
+	injector := newTestInjector(t)
+	got := readFaultedStream(t, injector, Rule{Name: "garble", Effect: EffectCorruptStream})
+	want := "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\ndata: {\"id\":\"3\"}\n\ndata: [DO\n\n"
+	if got != want {
+		t.Fatalf("corrupted stream = %q, want %q", got, want)
+	}
+}
+
+func TestWrapStreamWithoutFaultOrWhileKilled(t *testing.T) {
+	source := io.NopCloser(strings.NewReader(testStream))
+	if stream, _, ok := WrapStream(context.Background(), source); ok || stream != source {
+		t.Fatal("WrapStream() wrapped a stream without a marked fault")
+	}
+
+	injector := newTestInjector(t)
+	ctx := WithStreamFault(context.Background(), injector, Rule{Name: "cut", Effect: EffectTruncateStream, AfterChunks: 1})
+	stream, _, ok := WrapStream(ctx, io.NopCloser(strings.NewReader(testStream)))
+	if !ok {
+		t.Fatal("WrapStream() did not apply the fault")
+	}
+	injector.SetActive(false)
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(body) != testStream {
+		t.Fatalf("stream after kill switch = %q, want it unchanged", body)
+	}
+	if _, _, ok := WrapStream(ctx, io.NopCloser(strings.NewReader(testStream))); ok {
+		t.Fatal("WrapStream() applied a fault while the kill switch is on")
+	}
+}
@@ -0,0 +1,149 @@
+package chaos
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+type streamFaultKey struct{}
+
+type streamFault struct {
+	injector *Injector
+	rule     Rule
+}
+
+// WithStreamFault marks ctx so the response stream, if the request produces
+// one, is faulted by rule.
+func WithStreamFault(ctx context.Context, injector *Injector, rule Rule) context.Context {
+	return context.WithValue(ctx, streamFaultKey{}, &streamFault{injector: injector, rule: rule})
+}
+
+// WrapStream applies the stream fault marked on ctx to an SSE stream. It
+// returns stream unchanged and false when ctx carries no fault or the kill
+// switch is on.
+func WrapStream(ctx context.Context, stream io.ReadCloser) (io.ReadCloser, Rule, bool) {
+	if ctx == nil || stream == nil {
+		return stream, Rule{}, false
+	}
+	fault, _ := ctx.Value(streamFaultKey{}).(*streamFault)
+	if fault == nil || !fault.injector.Active() {
+		return stream, Rule{}, false
+	}
+	return &faultStream{
+		src:      stream,
+		reader:   bufio.NewReader(stream),
+		injector: fault.injector,
+		rule:     fault.rule,
+	}, fault.rule, true
+}
+
+// faultStream rewrites an SSE stream event by event. Events are delimited by
+// a blank line.
+type faultStream struct {
+	src      io.ReadCloser
+	reader   *bufio.Reader
+	injector *Injector
+	rule     Rule
+
+	pending []byte
+	held    []byte
+	events  int
+	done    bool
+}
+
+func (s *faultStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *faultStream) Close() error {
+	return s.src.Close()
+}
+
+// fill queues the next event, or finishes the stream.
+func (s *faultStream) fill() error {
+	if !s.injector.Active() {
+		// The kill switch was flipped mid-stream: release the held event and
+		// pass the rest through unchanged.
+		if s.held != nil {
+			s.pending, s.held = s.held, nil
+			return nil
+		}
+		buf := make([]byte, 32*1024)
+		n, err := s.reader.Read(buf)
+		s.pending = buf[:n]
+		if err == io.EOF {
+			s.done = true
+			return nil
+		}
+		return err
+	}
+
+	if s.rule.Effect == EffectTruncateStream && s.events >= s.rule.AfterChunks {
+		s.done = true
+		return nil
+	}
+
+	event, err := readEvent(s.reader)
+	if len(event) > 0 {
+		s.events++
+		switch s.rule.Effect {
+		case EffectCorruptStream:
+			s.pending = append(s.pending, s.held...)
+			s.held = event
+		default:
+			s.pending = append(s.pending, event...)
+		}
+	}
+	if err == io.EOF {
+		if s.held != nil {
+			s.pending = append(s.pending, corruptEvent(s.held)...)
+			s.held = nil
+		}
+		s.done = true
+		return nil
+	}
+	return err
+}
+
+// readEvent reads one SSE event including its terminating blank line. At the
+// end of the stream it returns the remaining bytes with io.EOF.
+func readEvent(reader *bufio.Reader) ([]byte, error) {
+	var event []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		event = append(event, line...)
+		if err != nil {
+			return event, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 && len(bytes.TrimSpace(event)) > 0 {
+			return event, nil
+		}
+	}
+}
+
+// corruptEvent cuts the payload of the event's last data line in half, so it
+// no longer parses.
+func corruptEvent(event []byte) []byte {
+	trimmed := bytes.TrimRight(event, "\r\n")
+	start := bytes.LastIndex(trimmed, []byte("data:"))
+	if start < 0 {
+		start = 0
+	} else {
+		start += len("data:")
+	}
+	cut := start + (len(trimmed)-start+1)/2
+	corrupted := append([]byte(nil), trimmed[:cut]...)
+	return append(corrupted, '\n', '\n')
+}
@@ -0,0 +1,189 @@
+// Package compression decodes HTTP content encodings for the audit logger and
+// the upstream provider clients.
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings understood by NewReader and Decompress.
+const (
+	Identity = "identity"
+	Gzip     = "gzip"
+	Deflate  = "deflate"
+	Brotli   = "br"
+	Zstd     = "zstd"
+)
+
+// ErrUnsupportedEncoding is returned by NewReader for encodings it cannot decode.
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// ParseEncoding returns the first coding of a Content-Encoding header value,
+// lowercased and trimmed. It returns "" for an empty value or identity.
+func ParseEncoding(contentEncoding string) string {
+	encoding, _, _ := strings.Cut(contentEncoding, ",")
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	if encoding == Identity {
+		return ""
+	}
+	return encoding
+}
+
+// Supported reports whether encoding can be decoded.
+func Supported(encoding string) bool {
+	switch encoding {
+	case Gzip, Deflate, Brotli, Zstd:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewReader returns a reader that decodes r according to encoding, which must
+// already be parsed with ParseEncoding. Closing the returned reader releases
+// decoder state but does not close r.
+func NewReader(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Deflate:
+		return flate.NewReader(r), nil
+	case Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case Zstd:
+		return newZstdReader(r)
+	default:
+		return nil, ErrUnsupportedEncoding
+	}
+}
+
+// Decompress decodes body according to contentEncoding, reading at most
+// maxSize decoded bytes. The compressed body is decoded in place without being
+// copied; only the decoded output is allocated. It returns body unchanged and
+// false when no decoding applies or decoding fails.
+func Decompress(body []byte, contentEncoding string, maxSize int64) ([]byte, bool) {
+	if len(body) == 0 || maxSize <= 0 {
+		return body, false
+	}
+	encoding := ParseEncoding(contentEncoding)
+	if !Supported(encoding) {
+		return body, false
+	}
+
+	reader, err := NewReader(bytes.NewReader(body), encoding)
+	if err != nil {
+		return body, false
+	}
+	defer reader.Close()
+
+	decoded, err := readAll(io.LimitReader(reader, maxSize))
+	if err != nil {
+		return body, false
+	}
+	return decoded, true
+}
+
+const chunkSize = 64 * 1024
+
+var chunks = sync.Pool{
+	New: func() any {
+		chunk := make([]byte, chunkSize)
+		return &chunk
+	},
+}
+
+// readAll drains r through pooled fixed-size chunks and copies them into a
+// single exactly sized slice, so decoded bytes are copied once instead of on
+// every growth step of an expanding buffer.
+func readAll(r io.Reader) ([]byte, error) {
+	var filled []*[]byte
+	defer func() {
+		for _, chunk := range filled {
+			chunks.Put(chunk)
+		}
+	}()
+
+	total := 0
+	last := 0
+	for {
+		chunk := chunks.Get().(*[]byte)
+		n, err := io.ReadFull(r, *chunk)
+		if n > 0 {
+			filled = append(filled, chunk)
+			total += n
+			last = n
+		} else {
+			chunks.Put(chunk)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]byte, 0, total)
+	for i, chunk := range filled {
+		if i == len(filled)-1 {
+			out = append(out, (*chunk)[:last]...)
+			break
+		}
+		out = append(out, *chunk...)
+	}
+	return out, nil
+}
+
+// zstd decoders are expensive to build, so they are pooled and reset per use.
+var zstdDecoders = sync.Pool{
+	New: func() any {
+		decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil
+		}
+		return decoder
+	},
+}
+
+type zstdReader struct {
+	decoder *zstd.Decoder
+}
+
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, _ := zstdDecoders.Get().(*zstd.Decoder)
+	if decoder == nil {
+		return nil, errors.New("zstd decoder unavailable")
+	}
+	if err := decoder.Reset(r); err != nil {
+		zstdDecoders.Put(decoder)
+		return nil, err
+	}
+	return &zstdReader{decoder: decoder}, nil
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	if z.decoder == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return z.decoder.Read(p)
+}
+
+func (z *zstdReader) Close() error {
+	if z.decoder == nil {
+		return nil
+	}
+	// Detach the source so the pooled decoder does not keep it alive.
+	_ = z.decoder.Reset(nil)
+	zstdDecoders.Put(z.decoder)
+	z.decoder = nil
+	return nil
+}
@@ -0,0 +1,240 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compressGzip(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func compressDeflate(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatalf("flate writer: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("flate write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("flate close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func compressBrotli(t testing.TB, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	// A bounded window keeps the decoder's ring buffer small, so allocation
+	// checks measure the decoded body rather than decoder state.
+	w := brotli.NewWriterOptions(&buf, brotli.WriterOptions{Quality: brotli.DefaultCompression, LGWin: 18})
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("brotli write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("brotli close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func compressZstd(t testing.TB, data []byte) []byte {
+	t.Helper()
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("zstd writer: %v", err)
+	}
+	defer w.Close()
+	return w.EncodeAll(data, nil)
+}
+
+var compressors = map[string]func(testing.TB, []byte) []byte{
+	Gzip:    compressGzip,
+	Deflate: compressDeflate,
+	Brotli:  compressBrotli,
+	Zstd:    compressZstd,
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"identity":      "",
+		" GZIP ":        Gzip,
+		"zstd, gzip":    Zstd,
+		"br":            Brotli,
+		"x-custom":      "x-custom",
+		"Identity, br ": "",
+	}
+	for input, want := range tests {
+		if got := ParseEncoding(input); got != want {
+			t.Errorf("ParseEncoding(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDecompress_EachEncoding(t *testing.T) {
+	original := []byte(`{"id":"chatcmpl-1","choices":[{"message":{"content":"` + strings.Repeat("hello ", 200) + `"}}]}`)
+
+	for encoding, compress := range compressors {
+		t.Run(encoding, func(t *testing.T) {
+			decoded, ok := Decompress(compress(t, original), encoding, 1<<20)
+			if !ok {
+				t.Fatal("Decompress() = false, want true")
+			}
+			if !bytes.Equal(decoded, original) {
+				t.Fatalf("decoded body mismatch: got %d bytes, want %d", len(decoded), len(original))
+			}
+		})
+	}
+}
+
+func TestDecompress_NoDecoding(t *testing.T) {
+	body := []byte("plain body")
+	for _, encoding := range []string{"", "identity", "compress", "x-unknown"} {
+		decoded, ok := Decompress(body, encoding, 1<<20)
+		if ok || !bytes.Equal(decoded, body) {
+			t.Errorf("Decompress(%q) = %q, %v; want body unchanged", encoding, decoded, ok)
+		}
+	}
+}
+
+func TestDecompress_InvalidData(t *testing.T) {
+	body := []byte("not compressed")
+	for encoding := range compressors {
+		decoded, ok := Decompress(body, encoding, 1<<20)
+		if ok || !bytes.Equal(decoded, body) {
+			t.Errorf("Decompress(%q) on invalid data = %q, %v; want body unchanged", encoding, decoded, ok)
+		}
+	}
+}
+
+func TestDecompress_LimitsDecodedSize(t *testing.T) {
+	original := bytes.Repeat([]byte("a"), 1<<20)
+	for encoding, compress := range compressors {
+		t.Run(encoding, func(t *testing.T) {
+			decoded, ok := Decompress(compress(t, original), encoding, 1024)
+			if !ok {
+				t.Fatal("Decompress() = false, want true")
+			}
+			if len(decoded) != 1024 {
+				t.Fatalf("len(decoded) = %d, want 1024", len(decoded))
+			}
+		})
+	}
+}
+
+func TestNewReader_StreamsEachEncoding(t *testing.T) {
+	original := []byte(strings.Repeat("data: {\"delta\":\"chunk\"}\n\n", 100))
+	for encoding, compress := range compressors {
+		t.Run(encoding, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(compress(t, original)), encoding)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			decoded, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if err := reader.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if !bytes.Equal(decoded, original) {
+				t.Fatal("decoded stream mismatch")
+			}
+		})
+	}
+}
+
+func TestNewReader_UnsupportedEncoding(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("x"), "compress"); err != ErrUnsupportedEncoding {
+		t.Fatalf("NewReader() error = %v, want ErrUnsupportedEncoding", err)
+	}
+}
+
+func TestZstdReader_ReusesPooledDecoder(t *testing.T) {
+	original := []byte("pooled zstd payload")
+	compressed := compressZstd(t, original)
+	for i := range 3 {
+		decoded, ok := Decompress(compressed, Zstd, 1<<20)
+		if !ok || !bytes.Equal(decoded, original) {
+			t.Fatalf("iteration %d: Decompress() = %q, %v", i, decoded, ok)
+		}
+	}
+}
+
+// benchmarkBody is a JSON-like payload of at least size bytes with realistic
+// redundancy.
+func benchmarkBody(size int) []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, `{"index":%d,"text":"token %d of a long completion"},`, i, i*7919)
+	}
+	return buf.Bytes()
+}
+
+func allocatedPerDecode(compressed []byte, encoding string) int64 {
+	const runs = 8
+	Decompress(compressed, encoding, 8<<20) // warm decoder and chunk pools
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for range runs {
+		Decompress(compressed, encoding, 8<<20)
+	}
+	runtime.ReadMemStats(&after)
+	return int64(after.TotalAlloc-before.TotalAlloc) / runs
+}
+
+// TestDecompress_CopiesDecodedBodyOnce checks that each extra decoded byte
+// costs well under two allocated bytes: the compressed input is never
+// buffered and the output is not re-copied as it grows. Comparing two body
+// sizes cancels out fixed decoder state.
+func TestDecompress_CopiesDecodedBodyOnce(t *testing.T) {
+	small := benchmarkBody(1 << 20)
+	large := benchmarkBody(2 << 20)
+	for encoding, compress := range compressors {
+		t.Run(encoding, func(t *testing.T) {
+			marginal := allocatedPerDecode(compress(t, large), encoding) - allocatedPerDecode(compress(t, small), encoding)
+			extra := int64(len(large) - len(small))
+			if marginal > extra*3/2 {
+				t.Fatalf("decoding %d extra bytes allocated %d extra bytes, want about one copy", extra, marginal)
+			}
+		})
+	}
+}
+
+func BenchmarkDecompress(b *testing.B) {
+	original := benchmarkBody(1 << 20)
+	for _, encoding := range []string{Gzip, Deflate, Brotli, Zstd} {
+		compressed := compressors[encoding](b, original)
+		b.Run(encoding, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(original)))
+			for b.Loop() {
+				if _, ok := Decompress(compressed, encoding, 4<<20); !ok {
+					b.Fatal("Decompress() failed")
+				}
+			}
+		})
+	}
+}
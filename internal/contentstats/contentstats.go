@@ -0,0 +1,97 @@
+// Package contentstats describes assistant output for usage analytics: its
+// language, character count and word count. Only a short leading sample of
+// the text is held, in memory, while it is analyzed; callers record the
+// Stats and never the text.
+package contentstats
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Undetermined is the language of text too short or too mixed to identify,
+// following the ISO 639-2 code for an undetermined language.
+const Undetermined = "und"
+
+// DefaultSampleChars is the number of leading characters the language is
+// identified from when no sample size is configured.
+const DefaultSampleChars = 500
+
+// Stats describes one piece of assistant output.
+type Stats struct {
+	// Language is the ISO 639-1 code of the detected language, or
+	// Undetermined.
+	Language string
+	// Chars counts the Unicode code points of the text.
+	Chars int
+	// Words counts the whitespace-separated words of the text. Scripts
+	// written without spaces, such as Chinese, count a run as one word.
+	Words int
+}
+
+// Analyzer accumulates text written in chunks, such as the deltas of a
+// stream, and describes it once the text is complete. The zero value is not
+// usable; create one with NewAnalyzer. An Analyzer is not safe for
+// concurrent use.
+type Analyzer struct {
+	sampleChars int
+	sample      []byte
+	sampled     int
+	chars       int
+	words       int
+	inWord      bool
+}
+
+// NewAnalyzer returns an analyzer that identifies the language from the
+// first sampleChars characters, or DefaultSampleChars when sampleChars is
+// not positive.
+func NewAnalyzer(sampleChars int) *Analyzer {
+	if sampleChars <= 0 {
+		sampleChars = DefaultSampleChars
+	}
+	return &Analyzer{sampleChars: sampleChars}
+}
+
+// Write adds the next chunk of text.
+func (a *Analyzer) Write(s string) {
+	for i, r := range s {
+		if a.sampled == a.sampleChars {
+			a.count(s[i:])
+			return
+		}
+		a.sample = utf8.AppendRune(a.sample, r)
+		a.sampled++
+		a.countRune(r)
+	}
+}
+
+func (a *Analyzer) count(s string) {
+	for _, r := range s {
+		a.countRune(r)
+	}
+}
+
+func (a *Analyzer) countRune(r rune) {
+	a.chars++
+	if unicode.IsSpace(r) {
+		a.inWord = false
+		return
+	}
+	if !a.inWord {
+		a.inWord = true
+		a.words++
+	}
+}
+
+// Stats describes the text written so far.
+func (a *Analyzer) Stats() Stats {
+	return Stats{Language: Detect(string(a.sample)), Chars: a.chars, Words: a.words}
+}
+
+// Analyze describes text, identifying its language from the first
+// sampleChars characters.
+func Analyze(text string, sampleChars int) Stats {
+	analyzer := NewAnalyzer(sampleChars)
+	analyzer.Write(text)
+	return analyzer.Stats()
+}
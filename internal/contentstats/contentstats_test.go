@@ -0,0 +1,87 @@
+package contentstats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "english", text: "Sure! Here is a short explanation of how the cache works and why the first request is slower than the others.", want: "en"},
+		{name: "spanish", text: "¡Claro! Aquí tienes una explicación breve de cómo funciona la caché y por qué la primera petición es más lenta que las demás.", want: "es"},
+		{name: "french", text: "Bien sûr ! Voici une courte explication du fonctionnement du cache et de la raison pour laquelle la première requête est plus lente.", want: "fr"},
+		{name: "german", text: "Natürlich! Hier ist eine kurze Erklärung, wie der Cache funktioniert und warum die erste Anfrage langsamer ist als die anderen.", want: "de"},
+		{name: "italian", text: "Certo! Ecco una breve spiegazione di come funziona la cache e del perché la prima richiesta è più lenta delle altre.", want: "it"},
+		{name: "portuguese", text: "Claro! Aqui está uma explicação curta de como o cache funciona e por que a primeira requisição é mais lenta que as outras.", want: "pt"},
+		{name: "dutch", text: "Natuurlijk! Hier is een korte uitleg van hoe de cache werkt en waarom het eerste verzoek trager is dan de andere.", want: "nl"},
+		{name: "polish", text: "Oczywiście! Oto krótkie wyjaśnienie, jak działa pamięć podręczna i dlaczego pierwsze żądanie jest wolniejsze od pozostałych.", want: "pl"},
+		{name: "russian", text: "Конечно! Вот краткое объяснение того, как работает кэш.", want: "ru"},
+		{name: "ukrainian", text: "Звісно! Ось коротке пояснення, як працює кеш і чому перший запит повільніший.", want: "uk"},
+		{name: "japanese", text: "もちろんです。キャッシュの仕組みを簡単に説明します。", want: "ja"},
+		{name: "chinese", text: "当然。这里简要说明缓存的工作原理。", want: "zh"},
+		{name: "korean", text: "물론입니다. 캐시가 작동하는 방식을 간단히 설명하겠습니다.", want: "ko"},
+		{name: "greek", text: "Φυσικά! Ακολουθεί μια σύντομη εξήγηση.", want: "el"},
+		{name: "arabic", text: "بالتأكيد! إليك شرح موجز لطريقة عمل ذاكرة التخزين المؤقت.", want: "ar"},
+		{name: "empty", text: "", want: Undetermined},
+		{name: "too short", text: "OK", want: Undetermined},
+		{name: "numbers only", text: "42 + 17 = 59", want: Undetermined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Fatalf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzerCountsAcrossChunks(t *testing.T) {
+	text := "Hello there, this is a streamed answer. It arrives in small pieces."
+	want := Analyze(text, 0)
+	if want.Language != "en" || want.Chars != 67 || want.Words != 12 {
+		t.Fatalf("Analyze() = %+v, want en with 67 chars and 12 words", want)
+	}
+
+	// Five-byte chunks cut words apart; the stats must not change.
+	analyzer := NewAnalyzer(0)
+	for i := 0; i < len(text); i += 5 {
+		analyzer.Write(text[i:min(i+5, len(text))])
+	}
+	if got := analyzer.Stats(); got != want {
+		t.Fatalf("chunked Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAnalyzerSamplesLeadingChars(t *testing.T) {
+	analyzer := NewAnalyzer(40)
+	analyzer.Write("The first part of this answer is written in English, ")
+	analyzer.Write(strings.Repeat("y la segunda parte está escrita en español. ", 20))
+
+	stats := analyzer.Stats()
+	if stats.Language != "en" {
+		t.Fatalf("Language = %q, want the language of the leading sample", stats.Language)
+	}
+	if len([]rune(string(analyzer.sample))) != 40 {
+		t.Fatalf("sample holds %d chars, want 40", len([]rune(string(analyzer.sample))))
+	}
+	if stats.Words != 10+20*8 {
+		t.Fatalf("Words = %d, want %d", stats.Words, 10+20*8)
+	}
+}
+
+// BenchmarkAnalyze measures a typical response: language identification on
+// the default sample plus counting about 4 KB of text. It must stay well
+// under a millisecond per response.
+func BenchmarkAnalyze(b *testing.B) {
+	text := strings.Repeat("Here is a short explanation of how the cache works and why the first request is slower. ", 45)
+	Analyze(text, 0) // build the trigram model outside the timed loop
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		Analyze(text, DefaultSampleChars)
+	}
+}
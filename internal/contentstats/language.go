@@ -0,0 +1,253 @@
+package contentstats
+
+import (
+	"embed"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// corpus holds a short sample text per Latin-script language. The trigram
+// model is built from it on first use.
+//
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+const (
+	// minLatinLetters is the fewest letters trigram scoring is trusted on.
+	minLatinLetters = 12
+	// minScriptLetters is the fewest letters a script-only decision is
+	// trusted on.
+	minScriptLetters = 2
+	// dominantShare is the share of letters a script needs to decide the
+	// language on its own.
+	dominantShare = 0.6
+	// smoothing is the count assumed for a trigram a language never used.
+	smoothing = 0.5
+)
+
+type script int
+
+const (
+	scriptLatin script = iota
+	scriptCyrillic
+	scriptGreek
+	scriptArabic
+	scriptHebrew
+	scriptDevanagari
+	scriptThai
+	scriptHangul
+	scriptKana
+	scriptHan
+	scriptCount
+)
+
+// scriptLanguages is the language each non-Latin script is taken for.
+// Cyrillic text with Ukrainian letters is Ukrainian, and Han text with kana
+// is Japanese.
+var scriptLanguages = [scriptCount]string{
+	scriptCyrillic:   "ru",
+	scriptGreek:      "el",
+	scriptArabic:     "ar",
+	scriptHebrew:     "he",
+	scriptDevanagari: "hi",
+	scriptThai:       "th",
+	scriptHangul:     "ko",
+	scriptKana:       "ja",
+	scriptHan:        "zh",
+}
+
+func scriptOf(r rune) (script, bool) {
+	switch {
+	case r < 0x80:
+		return scriptLatin, (r|0x20) >= 'a' && (r|0x20) <= 'z'
+	case unicode.Is(unicode.Latin, r):
+		return scriptLatin, true
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic, true
+	case unicode.Is(unicode.Greek, r):
+		return scriptGreek, true
+	case unicode.Is(unicode.Arabic, r):
+		return scriptArabic, true
+	case unicode.Is(unicode.Hebrew, r):
+		return scriptHebrew, true
+	case unicode.Is(unicode.Devanagari, r):
+		return scriptDevanagari, true
+	case unicode.Is(unicode.Thai, r):
+		return scriptThai, true
+	case unicode.Is(unicode.Hangul, r):
+		return scriptHangul, true
+	case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+		return scriptKana, true
+	case unicode.Is(unicode.Han, r):
+		return scriptHan, true
+	default:
+		return 0, false
+	}
+}
+
+// Detect returns the ISO 639-1 code of the language of text, or
+// Undetermined. Non-Latin scripts are identified by their script alone;
+// Latin text is scored against the embedded trigram model of each
+// supported language.
+func Detect(text string) string {
+	var counts [scriptCount]int
+	letters := 0
+	ukrainian := false
+	for _, r := range text {
+		s, ok := scriptOf(r)
+		if !ok {
+			continue
+		}
+		counts[s]++
+		letters++
+		if s == scriptCyrillic {
+			switch unicode.ToLower(r) {
+			case 'і', 'ї', 'є', 'ґ':
+				ukrainian = true
+			}
+		}
+	}
+	if letters < minScriptLetters {
+		return Undetermined
+	}
+
+	// Japanese mixes kana with Han, so any kana in a mostly Han and kana
+	// text makes it Japanese.
+	if counts[scriptKana] > 0 && float64(counts[scriptKana]+counts[scriptHan]) >= dominantShare*float64(letters) {
+		return "ja"
+	}
+	best := scriptLatin
+	for s := range scriptCount {
+		if counts[s] > counts[best] {
+			best = s
+		}
+	}
+	if float64(counts[best]) < dominantShare*float64(letters) {
+		return Undetermined
+	}
+	switch {
+	case best == scriptLatin:
+		if counts[scriptLatin] < minLatinLetters {
+			return Undetermined
+		}
+		return latinModel().classify(text)
+	case best == scriptCyrillic && ukrainian:
+		return "uk"
+	default:
+		return scriptLanguages[best]
+	}
+}
+
+// trigramModel scores text against the trigram frequencies of each
+// language. A trigram is three lower-case letters, or word boundaries,
+// packed into one key so scoring costs one map lookup per trigram.
+type trigramModel struct {
+	languages []string
+	index     map[uint64]int32
+	// logProbs holds len(languages) smoothed log probabilities per indexed
+	// trigram, so a trigram one language never used still scores it.
+	logProbs []float32
+}
+
+var (
+	latinOnce  sync.Once
+	latinCache *trigramModel
+)
+
+func latinModel() *trigramModel {
+	latinOnce.Do(func() {
+		latinCache = buildModel()
+	})
+	return latinCache
+}
+
+func buildModel() *trigramModel {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic("contentstats: read embedded corpus: " + err.Error())
+	}
+	model := &trigramModel{index: make(map[uint64]int32)}
+	var counts []map[uint64]int
+	var totals []int
+	for _, entry := range entries {
+		data, err := corpus.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			panic("contentstats: read embedded corpus: " + err.Error())
+		}
+		langCounts := make(map[uint64]int)
+		total := 0
+		eachTrigram(string(data), func(key uint64) {
+			langCounts[key]++
+			total++
+			if _, ok := model.index[key]; !ok {
+				model.index[key] = int32(len(model.index))
+			}
+		})
+		model.languages = append(model.languages, strings.TrimSuffix(entry.Name(), ".txt"))
+		counts = append(counts, langCounts)
+		totals = append(totals, total)
+	}
+
+	n := len(model.languages)
+	vocabulary := float64(len(model.index))
+	model.logProbs = make([]float32, len(model.index)*n)
+	for lang := range n {
+		denominator := float64(totals[lang]) + smoothing*vocabulary
+		for key, row := range model.index {
+			model.logProbs[int(row)*n+lang] = float32(math.Log((float64(counts[lang][key]) + smoothing) / denominator))
+		}
+	}
+	return model
+}
+
+// classify returns the language whose trigrams best explain text, or
+// Undetermined when none of its trigrams are known.
+func (m *trigramModel) classify(text string) string {
+	n := len(m.languages)
+	scores := make([]float32, n)
+	known := 0
+	eachTrigram(text, func(key uint64) {
+		row, ok := m.index[key]
+		if !ok {
+			// A trigram no language used does not tell them apart.
+			return
+		}
+		known++
+		probs := m.logProbs[int(row)*n : int(row)*n+n]
+		for i, p := range probs {
+			scores[i] += p
+		}
+	})
+	if known == 0 {
+		return Undetermined
+	}
+	return m.languages[slices.Index(scores, slices.Max(scores))]
+}
+
+// eachTrigram calls fn with every trigram of the lower-cased letters of
+// text. Runs of anything other than letters become one word boundary, so
+// " th", "the" and "he " are the trigrams of "the".
+func eachTrigram(text string, fn func(uint64)) {
+	var prev2, prev1 rune = 0, ' '
+	push := func(r rune) {
+		if r == ' ' && prev1 == ' ' {
+			return
+		}
+		if prev2 != 0 {
+			fn(uint64(prev2)<<42 | uint64(prev1)<<21 | uint64(r))
+		}
+		prev2, prev1 = prev1, r
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			push(unicode.ToLower(r))
+		} else {
+			push(' ')
+		}
+	}
+	push(' ')
+}
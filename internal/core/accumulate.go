@@ -0,0 +1,37 @@
+package core
+
+import "encoding/json"
+
+const (
+	// AccumulateHeader asks the gateway to consume a streaming chat or
+	// responses request itself and answer with one non-streamed response.
+	AccumulateHeader = "X-GoModel-Accumulate"
+	// AccumulateJSON accumulates the streamed text and parses it as JSON.
+	AccumulateJSON = "json"
+)
+
+// AccumulatedJSONResponse is returned for streaming requests sent with
+// AccumulateHeader set to AccumulateJSON. Output holds the parsed object when
+// the assembled text is valid JSON; otherwise ParseFailed is set and Text
+// carries the raw text.
+type AccumulatedJSONResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Object   string          `json:"object"`
+	Model    string          `json:"model"`
+	Provider string          `json:"provider"`
+	Output   json.RawMessage `json:"output,omitempty" swaggertype:"object"`
+	Text     string          `json:"text,omitempty"`
+	// ParseFailed reports that the assembled text is not valid JSON.
+	ParseFailed  bool               `json:"parse_failed"`
+	ParseError   string             `json:"parse_error,omitempty"`
+	FinishReason string             `json:"finish_reason,omitempty"`
+	Usage        *Usage             `json:"usage,omitempty"`
+	Timing       AccumulationTiming `json:"timing"`
+}
+
+// AccumulationTiming reports how long the upstream stream took.
+type AccumulationTiming struct {
+	// TTFBMs is the time until the first upstream stream byte arrived.
+	TTFBMs     int64 `json:"ttfb_ms"`
+	DurationMs int64 `json:"duration_ms"`
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Annotation types of Responses API output text.
+const (
+	AnnotationTypeURLCitation           = "url_citation"
+	AnnotationTypeFileCitation          = "file_citation"
+	AnnotationTypeContainerFileCitation = "container_file_citation"
+	AnnotationTypeFilePath              = "file_path"
+)
+
+// ResponsesAnnotation is a citation or file reference attached to Responses
+// API output text. StartIndex and EndIndex are character offsets into the
+// text. Fields of annotation types the gateway does not model survive in
+// ExtraFields. Text holds a legacy string-only annotation, which marshals back
+// to a plain JSON string.
+type ResponsesAnnotation struct {
+	Type        string            `json:"type"`
+	URL         string            `json:"url,omitempty"`
+	Title       string            `json:"title,omitempty"`
+	StartIndex  *int              `json:"start_index,omitempty"`
+	EndIndex    *int              `json:"end_index,omitempty"`
+	FileID      string            `json:"file_id,omitempty"`
+	Filename    string            `json:"filename,omitempty"`
+	ContainerID string            `json:"container_id,omitempty"`
+	Index       *int              `json:"index,omitempty"`
+	Text        string            `json:"-"`
+	ExtraFields UnknownJSONFields `json:"-" swaggerignore:"true"`
+}
+
+type responsesAnnotationJSON struct {
+	Type        string `json:"type"`
+	URL         string `json:"url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	StartIndex  *int   `json:"start_index,omitempty"`
+	EndIndex    *int   `json:"end_index,omitempty"`
+	FileID      string `json:"file_id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+	Index       *int   `json:"index,omitempty"`
+}
+
+var responsesAnnotationFields = []string{
+	"type", "url", "title", "start_index", "end_index", "file_id", "filename", "container_id", "index",
+}
+
+// NewURLCitation returns a url_citation annotation for text[start:end].
+func NewURLCitation(url, title string, start, end int) ResponsesAnnotation {
+	return ResponsesAnnotation{
+		Type:       AnnotationTypeURLCitation,
+		URL:        url,
+		Title:      title,
+		StartIndex: &start,
+		EndIndex:   &end,
+	}
+}
+
+func (a *ResponsesAnnotation) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		var text string
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return err
+		}
+		*a = ResponsesAnnotation{Text: text}
+		return nil
+	}
+
+	var raw responsesAnnotationJSON
+	if err := json.Unmarshal(trimmed, &raw); err != nil {
+		return err
+	}
+	extraFields, err := extractUnknownJSONFields(trimmed, responsesAnnotationFields...)
+	if err != nil {
+		return err
+	}
+	*a = ResponsesAnnotation{
+		Type:        raw.Type,
+		URL:         raw.URL,
+		Title:       raw.Title,
+		StartIndex:  raw.StartIndex,
+		EndIndex:    raw.EndIndex,
+		FileID:      raw.FileID,
+		Filename:    raw.Filename,
+		ContainerID: raw.ContainerID,
+		Index:       raw.Index,
+		ExtraFields: extraFields,
+	}
+	return nil
+}
+
+func (a ResponsesAnnotation) MarshalJSON() ([]byte, error) {
+	if a.Type == "" && a.Text != "" {
+		return json.Marshal(a.Text)
+	}
+	return marshalWithUnknownJSONFields(responsesAnnotationJSON{
+		Type:        a.Type,
+		URL:         a.URL,
+		Title:       a.Title,
+		StartIndex:  a.StartIndex,
+		EndIndex:    a.EndIndex,
+		FileID:      a.FileID,
+		Filename:    a.Filename,
+		ContainerID: a.ContainerID,
+		Index:       a.Index,
+	}, a.ExtraFields)
+}
+
+// ParseChatAnnotations decodes the annotations of a Chat Completions message.
+// Chat Completions nests each annotation's fields under its type, as in
+// {"type":"url_citation","url_citation":{"url":...}}; flat Responses-style
+// annotations are accepted too.
+func ParseChatAnnotations(raw json.RawMessage) ([]ResponsesAnnotation, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(trimmed, &entries); err != nil {
+		return nil, fmt.Errorf("annotations must be an array: %w", err)
+	}
+	annotations := make([]ResponsesAnnotation, 0, len(entries))
+	for _, entry := range entries {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(entry, &envelope); err != nil {
+			var annotation ResponsesAnnotation
+			if err := json.Unmarshal(entry, &annotation); err != nil {
+				return nil, err
+			}
+			annotations = append(annotations, annotation)
+			continue
+		}
+		var annotationType string
+		_ = json.Unmarshal(envelope["type"], &annotationType)
+		if nested, ok := envelope[annotationType]; ok && annotationType != "" && isJSONObject(nested) {
+			flattened := make(map[string]json.RawMessage, len(envelope))
+			if err := json.Unmarshal(nested, &flattened); err != nil {
+				return nil, err
+			}
+			flattened["type"] = envelope["type"]
+			entry, _ = json.Marshal(flattened)
+		}
+		var annotation ResponsesAnnotation
+		if err := json.Unmarshal(entry, &annotation); err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// MarshalChatAnnotations encodes annotations in the nested Chat Completions
+// form that ParseChatAnnotations reads.
+func MarshalChatAnnotations(annotations []ResponsesAnnotation) (json.RawMessage, error) {
+	entries := make([]json.RawMessage, 0, len(annotations))
+	for _, annotation := range annotations {
+		if annotation.Type == "" {
+			encoded, err := json.Marshal(annotation)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, encoded)
+			continue
+		}
+		fields, err := json.Marshal(annotation)
+		if err != nil {
+			return nil, err
+		}
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(fields, &nested); err != nil {
+			return nil, err
+		}
+		delete(nested, "type")
+		encoded, err := json.Marshal(map[string]any{
+			"type":          annotation.Type,
+			annotation.Type: nested,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, encoded)
+	}
+	return json.Marshal(entries)
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResponsesAnnotationJSON_PreservesUnknownFieldsAndStrings(t *testing.T) {
+	var annotations []ResponsesAnnotation
+	if err := json.Unmarshal([]byte(`[
+		{"type":"file_citation","file_id":"file_1","filename":"notes.md","index":12,"quote":"x"},
+		"legacy note"
+	]`), &annotations); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(annotations) != 2 {
+		t.Fatalf("len(annotations) = %d, want 2", len(annotations))
+	}
+	if annotations[0].Type != AnnotationTypeFileCitation || annotations[0].FileID != "file_1" || annotations[0].Index == nil || *annotations[0].Index != 12 {
+		t.Fatalf("annotations[0] = %+v, want file_citation for file_1 at 12", annotations[0])
+	}
+	if annotations[1].Text != "legacy note" {
+		t.Fatalf("annotations[1].Text = %q, want legacy note", annotations[1].Text)
+	}
+
+	body, err := json.Marshal(annotations)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `[{"type":"file_citation","file_id":"file_1","filename":"notes.md","index":12,"quote":"x"},"legacy note"]`
+	if string(body) != want {
+		t.Fatalf("json.Marshal() = %s, want %s", body, want)
+	}
+}
+
+func TestParseChatAnnotations_FlattensNestedForm(t *testing.T) {
+	annotations, err := ParseChatAnnotations(json.RawMessage(`[
+		{"type":"url_citation","url_citation":{"url":"https://example.com","title":"Example","start_index":0,"end_index":5}},
+		{"type":"url_citation","url":"https://example.org","start_index":6,"end_index":9}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseChatAnnotations() error = %v", err)
+	}
+
+	if len(annotations) != 2 {
+		t.Fatalf("len(annotations) = %d, want 2", len(annotations))
+	}
+	first := annotations[0]
+	if first.Type != AnnotationTypeURLCitation || first.URL != "https://example.com" || first.Title != "Example" || *first.StartIndex != 0 || *first.EndIndex != 5 {
+		t.Fatalf("annotations[0] = %+v, want flattened url_citation", first)
+	}
+	if annotations[1].URL != "https://example.org" || *annotations[1].EndIndex != 9 {
+		t.Fatalf("annotations[1] = %+v, want flat url_citation", annotations[1])
+	}
+
+	raw, err := MarshalChatAnnotations(annotations[:1])
+	if err != nil {
+		t.Fatalf("MarshalChatAnnotations() error = %v", err)
+	}
+	want := `[{"type":"url_citation","url_citation":{"end_index":5,"start_index":0,"title":"Example","url":"https://example.com"}}]`
+	if string(raw) != want {
+		t.Fatalf("MarshalChatAnnotations() = %s, want %s", raw, want)
+	}
+}
+
+func TestParseChatAnnotations_RejectsNonArray(t *testing.T) {
+	if _, err := ParseChatAnnotations(json.RawMessage(`{"type":"url_citation"}`)); err == nil {
+		t.Fatal("ParseChatAnnotations() error = nil, want error for an object")
+	}
+	annotations, err := ParseChatAnnotations(json.RawMessage(`null`))
+	if err != nil || annotations != nil {
+		t.Fatalf("ParseChatAnnotations(null) = %v, %v, want nil, nil", annotations, err)
+	}
+}
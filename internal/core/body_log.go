@@ -0,0 +1,6 @@
+package core
+
+// NoBodyLogHeader lets a client keep its request and response bodies out of
+// the audit log. "true" skips body capture for the request; the rest of the
+// entry is still recorded.
+const NoBodyLogHeader = "X-GoModel-No-Body-Log"
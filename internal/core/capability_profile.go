@@ -0,0 +1,74 @@
+package core
+
+import "time"
+
+// Capabilities a provider capability probe checks.
+const (
+	CapabilityChat       = "chat"
+	CapabilityChatStream = "chat_stream"
+	CapabilityTools      = "tools"
+	CapabilityJSONMode   = "json_mode"
+	CapabilityEmbeddings = "embeddings"
+	CapabilityResponses  = "responses"
+)
+
+// CapabilityCheckStatus is the outcome of one capability probe request.
+type CapabilityCheckStatus string
+
+const (
+	// CapabilitySupported means the provider accepted the request.
+	CapabilitySupported CapabilityCheckStatus = "supported"
+	// CapabilityUnsupported means the provider rejected the request as
+	// invalid or unknown, which is how missing features surface.
+	CapabilityUnsupported CapabilityCheckStatus = "unsupported"
+	// CapabilityInconclusive means the request failed for another reason,
+	// such as a timeout, rate limit or server error.
+	CapabilityInconclusive CapabilityCheckStatus = "inconclusive"
+	// CapabilitySkipped means the request was not sent because the probe's
+	// token budget was exhausted.
+	CapabilitySkipped CapabilityCheckStatus = "skipped"
+)
+
+// CapabilityCheck is the result of one capability probe request.
+type CapabilityCheck struct {
+	Capability string                `json:"capability"`
+	Status     CapabilityCheckStatus `json:"status"`
+	Error      string                `json:"error,omitempty"`
+	LatencyMs  int64                 `json:"latency_ms"`
+	Tokens     int                   `json:"tokens"`
+}
+
+// CapabilityProfile is what a capability probe learned about one provider.
+type CapabilityProfile struct {
+	Provider       string            `json:"provider"`
+	Model          string            `json:"model"`
+	EmbeddingModel string            `json:"embedding_model,omitempty"`
+	ProbedAt       time.Time         `json:"probed_at"`
+	Checks         []CapabilityCheck `json:"checks"`
+	TokenBudget    int               `json:"token_budget"`
+	TokensUsed     int               `json:"tokens_used"`
+	// Applied profiles make routing reject requests that need a capability
+	// the probe found unsupported.
+	Applied bool `json:"applied"`
+}
+
+// Supports reports whether the probe found capability supported. known is
+// false when the capability was not checked or the check was inconclusive.
+func (p *CapabilityProfile) Supports(capability string) (supported, known bool) {
+	if p == nil {
+		return false, false
+	}
+	for _, check := range p.Checks {
+		if check.Capability != capability {
+			continue
+		}
+		switch check.Status {
+		case CapabilitySupported:
+			return true, true
+		case CapabilityUnsupported:
+			return false, true
+		}
+		return false, false
+	}
+	return false, false
+}
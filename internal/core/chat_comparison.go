@@ -0,0 +1,80 @@
+package core
+
+import (
+	"encoding/json"
+)
+
+// ChatComparisonRequest is a chat completion request fanned out to several
+// models by POST /v1/chat/completions/compare. The embedded request is sent to
+// every model; its model and provider fields are ignored.
+type ChatComparisonRequest struct {
+	ChatRequest
+	Models []string `json:"models"`
+}
+
+// UnmarshalJSON decodes the shared chat request and the models list without
+// leaking "models" into the chat request's unknown fields.
+func (r *ChatComparisonRequest) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Models []string `json:"models"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var chat ChatRequest
+	if err := chat.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if chat.ExtraFields.Lookup("models") != nil {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(chat.ExtraFields.raw, &fields); err != nil {
+			return err
+		}
+		delete(fields, "models")
+		chat.ExtraFields = unknownJSONFieldsFromMap(fields, false)
+	}
+
+	r.ChatRequest = chat
+	r.Models = raw.Models
+	return nil
+}
+
+// ChatComparisonResult is the outcome of one model in a chat comparison.
+// Exactly one of Response and Error is set.
+type ChatComparisonResult struct {
+	Index      int                `json:"index"`
+	Model      string             `json:"model"`
+	Provider   string             `json:"provider,omitempty"`
+	StatusCode int                `json:"status_code"`
+	LatencyMs  int64              `json:"latency_ms"`
+	Usage      *Usage             `json:"usage,omitempty"`
+	Response   *ChatResponse      `json:"response,omitempty"`
+	Error      *OpenAIErrorObject `json:"error,omitempty"`
+}
+
+// ChatComparisonResponse is the non-streaming response of a chat comparison.
+// Results follow the order of the requested models. ID is shared by the usage
+// and audit records of every sub-request.
+type ChatComparisonResponse struct {
+	ID            string                 `json:"id"`
+	Object        string                 `json:"object"`
+	Created       int64                  `json:"created"`
+	EstimatedCost *float64               `json:"estimated_cost,omitempty"`
+	Results       []ChatComparisonResult `json:"results"`
+}
+
+// ChatComparisonStreamEvent is one SSE event of a streaming chat comparison.
+// Chunk events carry an upstream chat.completion.chunk; each model ends with
+// either a Done event or an Error event.
+type ChatComparisonStreamEvent struct {
+	ComparisonID string             `json:"comparison_id"`
+	Index        int                `json:"index"`
+	Model        string             `json:"model"`
+	Chunk        json.RawMessage    `json:"chunk,omitempty" swaggertype:"object"`
+	Done         bool               `json:"done,omitempty"`
+	LatencyMs    int64              `json:"latency_ms,omitempty"`
+	StatusCode   int                `json:"status_code,omitempty"`
+	Usage        json.RawMessage    `json:"usage,omitempty" swaggertype:"object"`
+	Error        *OpenAIErrorObject `json:"error,omitempty"`
+}
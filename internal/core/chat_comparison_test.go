@@ -0,0 +1,34 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatComparisonRequestJSON_SeparatesModelsFromUnknownFields(t *testing.T) {
+	body := []byte(`{
+		"models":["gpt-4o-mini","anthropic/claude-sonnet-4"],
+		"messages":[{"role":"user","content":"hello"}],
+		"max_tokens":64,
+		"stream":true,
+		"x_trace":{"id":"trace-1"}
+	}`)
+
+	var req ChatComparisonRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(req.Models) != 2 || req.Models[0] != "gpt-4o-mini" || req.Models[1] != "anthropic/claude-sonnet-4" {
+		t.Fatalf("Models = %v", req.Models)
+	}
+	if len(req.Messages) != 1 || req.MaxTokens == nil || *req.MaxTokens != 64 || !req.Stream {
+		t.Fatalf("chat request not decoded: %+v", req.ChatRequest)
+	}
+	if raw := req.ExtraFields.Lookup("models"); raw != nil {
+		t.Fatalf("models leaked into unknown fields: %s", raw)
+	}
+	if got := string(lookupUnknownField(t, req.ExtraFields, "x_trace")); got != `{"id":"trace-1"}` {
+		t.Fatalf("x_trace = %s", got)
+	}
+}
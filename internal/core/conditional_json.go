@@ -0,0 +1,60 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ModelListCacheControl is the Cache-Control value of model listings. Clients
+// and intermediaries may keep serving a listing for a minute while they
+// revalidate it with If-None-Match.
+const ModelListCacheControl = "private, max-age=0, stale-while-revalidate=60"
+
+// ETagForBody returns a strong entity tag derived from a response body, so
+// identical listings share a tag across refreshes and gateway instances.
+func ETagForBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches reports whether an If-None-Match header value matches etag.
+// Weak comparison is used, as RFC 9110 requires for If-None-Match.
+func ETagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCacheableJSON writes v as a 200 JSON response with ETag and
+// Cache-Control headers, or an empty 304 when the request's If-None-Match
+// already names the current representation.
+func WriteCacheableJSON(w http.ResponseWriter, r *http.Request, cacheControl string, v any) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	body := buf.Bytes()
+	etag := ETagForBody(body)
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", cacheControl)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && ETagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	header.Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(body)
+	return err
+}
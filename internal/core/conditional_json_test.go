@@ -0,0 +1,64 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteCacheableJSON(t *testing.T) {
+	payload := ModelsResponse{Object: "list", Data: []Model{{ID: "gpt-4o", Object: "model"}}}
+
+	write := func(ifNoneMatch string, v any) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		if err := WriteCacheableJSON(rec, req, ModelListCacheControl, v); err != nil {
+			t.Fatalf("WriteCacheableJSON() error = %v", err)
+		}
+		return rec
+	}
+
+	first := write("", payload)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag header")
+	}
+	if got := first.Header().Get("Cache-Control"); got != ModelListCacheControl {
+		t.Fatalf("Cache-Control = %q, want %q", got, ModelListCacheControl)
+	}
+	if got := first.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+	if got := write("", payload).Header().Get("ETag"); got != etag {
+		t.Fatalf("ETag for identical payload = %q, want %q", got, etag)
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec := write(ifNoneMatch, payload)
+		if rec.Code != http.StatusNotModified {
+			t.Fatalf("If-None-Match %q: status = %d, want 304", ifNoneMatch, rec.Code)
+		}
+		if rec.Body.Len() != 0 {
+			t.Fatalf("If-None-Match %q: body = %q, want empty", ifNoneMatch, rec.Body.String())
+		}
+		if got := rec.Header().Get("ETag"); got != etag {
+			t.Fatalf("If-None-Match %q: ETag = %q, want %q", ifNoneMatch, got, etag)
+		}
+	}
+
+	changed := ModelsResponse{Object: "list", Data: []Model{{ID: "gpt-4o-mini", Object: "model"}}}
+	rec := write(etag, changed)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match: status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Fatal("ETag did not change with the payload")
+	}
+}
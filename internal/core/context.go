@@ -40,6 +40,70 @@ const (
 	// requestOriginKey stores the logical request origin for internal execution
 	// flows that still reuse the translated request pipeline.
 	requestOriginKey contextKey = "request-origin"
+
+	// contextOverflowKey stores how the translated chat pipeline adjusted a
+	// request that exceeded the model's context window.
+	contextOverflowKey contextKey = "context-overflow"
+
+	// promptCompressionKey stores how the translated chat pipeline compressed
+	// the prompt of a request.
+	promptCompressionKey contextKey = "prompt-compression"
+
+	// maxTokensDefaultKey stores the max_tokens default the translated
+	// pipeline applied to a request that did not set a limit.
+	maxTokensDefaultKey contextKey = "max-tokens-default"
+
+	// moderationKey stores the moderation pre-check outcome of a request
+	// that was allowed through.
+	moderationKey contextKey = "moderation"
+
+	// upstreamCallKey stores the recorder that captures the provider HTTP
+	// status and duration of the request.
+	upstreamCallKey contextKey = "upstream-call"
+
+	// upstreamResponseHeadersKey stores the collector of provider response
+	// headers passed through to the client.
+	upstreamResponseHeadersKey contextKey = "upstream-response-headers"
+
+	// guardrailCanariesKey stores the recorder that captures which guardrails
+	// in canary rollout were applied to the request.
+	guardrailCanariesKey contextKey = "guardrail-canaries"
+
+	// dataResidencyKey stores the data residency requirement providers must
+	// satisfy to serve the request.
+	dataResidencyKey contextKey = "data-residency"
+
+	// requestLabelsKey stores the validated cost allocation labels of the
+	// request.
+	requestLabelsKey contextKey = "request-labels"
+
+	// inlineImageStatsKey stores the count and decoded size of the base64
+	// images carried in the request content.
+	inlineImageStatsKey contextKey = "inline-image-stats"
+
+	// promptTemplateKey stores the name and version of the prompt template
+	// rendered into the request.
+	promptTemplateKey contextKey = "prompt-template"
+
+	// requestUserKey stores the end-user identifier taken from the OpenAI
+	// user request field.
+	requestUserKey contextKey = "request-user"
+
+	// promptCacheKey stores the cacheable system prompt prefix of the
+	// request.
+	promptCacheKey contextKey = "prompt-cache"
+
+	// requestOptionsKey stores the per-request gateway options parsed from
+	// the X-GoModel-Options header.
+	requestOptionsKey contextKey = "request-options"
+
+	// secretRedactionKey stores the secrets secret redaction found in the
+	// request.
+	secretRedactionKey contextKey = "secret-redaction"
+
+	// toolLoopKey stores the server-side tool loop that produced the
+	// response of the request.
+	toolLoopKey contextKey = "tool-loop"
 )
 
 // RequestOrigin identifies whether a request came from an external caller or an
@@ -49,6 +113,9 @@ type RequestOrigin string
 const (
 	RequestOriginExternal  RequestOrigin = "external"
 	RequestOriginGuardrail RequestOrigin = "guardrail"
+	// RequestOriginDeferred marks the replay of a deferred request. The
+	// caller was authenticated when the request was enqueued.
+	RequestOriginDeferred RequestOrigin = "deferred"
 )
 
 // WithRequestID returns a new context with the request ID attached.
@@ -112,6 +179,70 @@ func GetWorkflow(ctx context.Context) *Workflow {
 	return nil
 }
 
+// WithContextOverflow returns a new context with the applied context overflow handling attached.
+func WithContextOverflow(ctx context.Context, result *ContextOverflowResult) context.Context {
+	return context.WithValue(ctx, contextOverflowKey, result)
+}
+
+// GetContextOverflow retrieves the applied context overflow handling from the context.
+// Returns nil when the request fit the context window or was not checked.
+func GetContextOverflow(ctx context.Context) *ContextOverflowResult {
+	if v := ctx.Value(contextOverflowKey); v != nil {
+		if result, ok := v.(*ContextOverflowResult); ok {
+			return result
+		}
+	}
+	return nil
+}
+
+// WithMaxTokensDefault returns a new context with the applied max_tokens default attached.
+func WithMaxTokensDefault(ctx context.Context, applied *MaxTokensDefault) context.Context {
+	return context.WithValue(ctx, maxTokensDefaultKey, applied)
+}
+
+// GetMaxTokensDefault retrieves the applied max_tokens default from the context.
+// Returns nil when the request set its own limit or no default applied.
+func GetMaxTokensDefault(ctx context.Context) *MaxTokensDefault {
+	if v := ctx.Value(maxTokensDefaultKey); v != nil {
+		if applied, ok := v.(*MaxTokensDefault); ok {
+			return applied
+		}
+	}
+	return nil
+}
+
+// WithPromptCompression returns a new context with the applied prompt compression attached.
+func WithPromptCompression(ctx context.Context, result *PromptCompressionResult) context.Context {
+	return context.WithValue(ctx, promptCompressionKey, result)
+}
+
+// GetPromptCompression retrieves the applied prompt compression from the context.
+// Returns nil when no pass changed the prompt.
+func GetPromptCompression(ctx context.Context) *PromptCompressionResult {
+	if v := ctx.Value(promptCompressionKey); v != nil {
+		if result, ok := v.(*PromptCompressionResult); ok {
+			return result
+		}
+	}
+	return nil
+}
+
+// WithModeration returns a new context with the moderation pre-check outcome attached.
+func WithModeration(ctx context.Context, result *ModerationResult) context.Context {
+	return context.WithValue(ctx, moderationKey, result)
+}
+
+// GetModeration retrieves the moderation pre-check outcome from the context.
+// Returns nil when the request was not checked or nothing was flagged.
+func GetModeration(ctx context.Context) *ModerationResult {
+	if v := ctx.Value(moderationKey); v != nil {
+		if result, ok := v.(*ModerationResult); ok {
+			return result
+		}
+	}
+	return nil
+}
+
 // WithAuthKeyID returns a new context with the authenticated managed auth key id attached.
 func WithAuthKeyID(ctx context.Context, id string) context.Context {
 	return context.WithValue(ctx, authKeyIDKey, id)
@@ -221,3 +352,117 @@ func GetRequestOrigin(ctx context.Context) RequestOrigin {
 	}
 	return RequestOriginExternal
 }
+
+// WithUpstreamCall returns a new context carrying the recorder that provider
+// clients report their HTTP outcome to.
+func WithUpstreamCall(ctx context.Context, call *UpstreamCall) context.Context {
+	return context.WithValue(ctx, upstreamCallKey, call)
+}
+
+// GetUpstreamCall retrieves the upstream call recorder from context.
+func GetUpstreamCall(ctx context.Context) *UpstreamCall {
+	if v := ctx.Value(upstreamCallKey); v != nil {
+		if call, ok := v.(*UpstreamCall); ok {
+			return call
+		}
+	}
+	return nil
+}
+
+// WithUpstreamResponseHeaders returns a new context carrying the collector
+// that provider clients report their response headers to.
+func WithUpstreamResponseHeaders(ctx context.Context, headers *UpstreamResponseHeaders) context.Context {
+	return context.WithValue(ctx, upstreamResponseHeadersKey, headers)
+}
+
+// GetUpstreamResponseHeaders retrieves the upstream response header collector
+// from context.
+func GetUpstreamResponseHeaders(ctx context.Context) *UpstreamResponseHeaders {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(upstreamResponseHeadersKey); v != nil {
+		if headers, ok := v.(*UpstreamResponseHeaders); ok {
+			return headers
+		}
+	}
+	return nil
+}
+
+// WithGuardrailCanaries returns a new context carrying the recorder that
+// guardrails in canary rollout report their decisions to.
+func WithGuardrailCanaries(ctx context.Context, canaries *GuardrailCanaries) context.Context {
+	return context.WithValue(ctx, guardrailCanariesKey, canaries)
+}
+
+// GetGuardrailCanaries retrieves the guardrail canary recorder from context.
+func GetGuardrailCanaries(ctx context.Context) *GuardrailCanaries {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(guardrailCanariesKey); v != nil {
+		if canaries, ok := v.(*GuardrailCanaries); ok {
+			return canaries
+		}
+	}
+	return nil
+}
+
+// WithDataResidency returns a new context carrying a data residency requirement.
+func WithDataResidency(ctx context.Context, residency string) context.Context {
+	return context.WithValue(ctx, dataResidencyKey, residency)
+}
+
+// GetDataResidency retrieves the data residency requirement from context.
+// It returns "" when the request has no requirement.
+func GetDataResidency(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v := ctx.Value(dataResidencyKey); v != nil {
+		if residency, ok := v.(string); ok {
+			return residency
+		}
+	}
+	return ""
+}
+
+// WithSecretRedaction returns a new context carrying the secrets found in the
+// request.
+func WithSecretRedaction(ctx context.Context, result *SecretRedactionResult) context.Context {
+	return context.WithValue(ctx, secretRedactionKey, result)
+}
+
+// GetSecretRedaction retrieves the secrets found in the request from
+// context. Returns nil when the request was not scanned or had none.
+func GetSecretRedaction(ctx context.Context) *SecretRedactionResult {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(secretRedactionKey); v != nil {
+		if result, ok := v.(*SecretRedactionResult); ok {
+			return result
+		}
+	}
+	return nil
+}
+
+// WithToolLoop returns a new context with the server-side tool loop of the
+// request attached.
+func WithToolLoop(ctx context.Context, result *ToolLoopResult) context.Context {
+	return context.WithValue(ctx, toolLoopKey, result)
+}
+
+// GetToolLoop retrieves the server-side tool loop of the request from
+// context. Returns nil when the request ran no loop.
+func GetToolLoop(ctx context.Context) *ToolLoopResult {
+	if ctx == nil {
+		return nil
+	}
+	if v := ctx.Value(toolLoopKey); v != nil {
+		if result, ok := v.(*ToolLoopResult); ok {
+			return result
+		}
+	}
+	return nil
+}
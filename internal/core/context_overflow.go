@@ -0,0 +1,49 @@
+package core
+
+import "strings"
+
+const (
+	// ContextOverflowHeader selects the context overflow strategy for one
+	// request. On responses it reports the strategy the gateway applied.
+	ContextOverflowHeader = "X-GoModel-Context-Overflow"
+	// ContextOverflowDroppedHeader reports how many messages were removed to
+	// fit the model's context window.
+	ContextOverflowDroppedHeader = "X-GoModel-Context-Overflow-Dropped"
+)
+
+// ContextOverflowStrategy selects what the gateway does with a chat request
+// whose estimated prompt exceeds the model's context window.
+type ContextOverflowStrategy string
+
+const (
+	// ContextOverflowOff forwards the request unchanged.
+	ContextOverflowOff ContextOverflowStrategy = "off"
+	// ContextOverflowReject fails the request with a 400 before it reaches the provider.
+	ContextOverflowReject ContextOverflowStrategy = "reject"
+	// ContextOverflowTruncateOldest drops the earliest non-system messages.
+	ContextOverflowTruncateOldest ContextOverflowStrategy = "truncate_oldest"
+	// ContextOverflowMiddleOut keeps the first and latest turns and replaces
+	// the middle of the conversation with a marker message.
+	ContextOverflowMiddleOut ContextOverflowStrategy = "middle_out"
+)
+
+// ParseContextOverflowStrategy parses a strategy name. An empty value returns
+// ("", true) so callers can fall back to a configured default.
+func ParseContextOverflowStrategy(value string) (ContextOverflowStrategy, bool) {
+	switch s := ContextOverflowStrategy(strings.ToLower(strings.TrimSpace(value))); s {
+	case "":
+		return "", true
+	case ContextOverflowOff, ContextOverflowReject, ContextOverflowTruncateOldest, ContextOverflowMiddleOut:
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+// ContextOverflowResult describes how an oversized request was adjusted.
+type ContextOverflowResult struct {
+	Strategy        ContextOverflowStrategy
+	EstimatedTokens int // estimated prompt tokens before adjustment
+	Limit           int // context window minus the requested output tokens
+	DroppedMessages int
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DataResidencyHeader carries the data residency a request requires, for
+	// example "eu". Only providers labeled with that residency may serve it.
+	DataResidencyHeader = "X-GoModel-Data-Residency"
+	// DataResidencyAny labels providers without a residency guarantee. As a
+	// requirement it places no restriction on the request.
+	DataResidencyAny = "any"
+
+	// dataResidencyErrorCode is the error code of data residency policy errors.
+	dataResidencyErrorCode = "data_residency_unsatisfied"
+)
+
+// NormalizeDataResidency canonicalizes a residency label or requirement.
+// Labels are lowercase letters, digits, '-' and '_'; an empty value is allowed.
+func NormalizeDataResidency(raw string) (string, error) {
+	residency := strings.ToLower(strings.TrimSpace(raw))
+	if len(residency) > 32 {
+		return "", fmt.Errorf("data residency must be at most 32 characters")
+	}
+	for _, r := range residency {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("data residency %q may only contain letters, digits, '-' and '_'", raw)
+		}
+	}
+	return residency, nil
+}
+
+// DataResidencySatisfied reports whether a provider labeled label may serve a
+// request that requires requirement. Unlabeled providers count as "any" and
+// only serve requests without a requirement.
+func DataResidencySatisfied(label, requirement string) bool {
+	requirement = strings.ToLower(strings.TrimSpace(requirement))
+	if requirement == "" || requirement == DataResidencyAny {
+		return true
+	}
+	return strings.ToLower(strings.TrimSpace(label)) == requirement
+}
+
+// NewDataResidencyError reports that no configured provider satisfies the
+// request's data residency requirement for model.
+func NewDataResidencyError(requirement, model string) *GatewayError {
+	message := fmt.Sprintf("no provider satisfies data residency requirement %q", requirement)
+	if model = strings.TrimSpace(model); model != "" {
+		message += " for model " + model
+	}
+	return NewInvalidRequestErrorWithStatus(http.StatusBadRequest, message, nil).WithCode(dataResidencyErrorCode)
+}
+
+// IsDataResidencyError reports whether err is a data residency policy error.
+func IsDataResidencyError(err *GatewayError) bool {
+	return err != nil && err.Code != nil && *err.Code == dataResidencyErrorCode
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeDataResidency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty stays unset", raw: "", want: ""},
+		{name: "trim and lowercase", raw: "  EU ", want: "eu"},
+		{name: "allows dashes and underscores", raw: "us-east_1", want: "us-east_1"},
+		{name: "any is a valid requirement", raw: "Any", want: DataResidencyAny},
+		{name: "reject spaces", raw: "eu west", wantErr: true},
+		{name: "reject slashes", raw: "eu/west", wantErr: true},
+		{name: "reject long labels", raw: "abcdefghijklmnopqrstuvwxyz0123456", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeDataResidency(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NormalizeDataResidency() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeDataResidency() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("NormalizeDataResidency() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataResidencySatisfied(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		label       string
+		requirement string
+		want        bool
+	}{
+		{label: "", requirement: "", want: true},
+		{label: "", requirement: DataResidencyAny, want: true},
+		{label: "us", requirement: DataResidencyAny, want: true},
+		{label: "eu", requirement: "eu", want: true},
+		{label: "us", requirement: "eu", want: false},
+		{label: "", requirement: "eu", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := DataResidencySatisfied(tt.label, tt.requirement); got != tt.want {
+			t.Errorf("DataResidencySatisfied(%q, %q) = %v, want %v", tt.label, tt.requirement, got, tt.want)
+		}
+	}
+}
+
+func TestNewDataResidencyError(t *testing.T) {
+	t.Parallel()
+
+	err := NewDataResidencyError("eu", "openai/gpt-4o")
+	if err.HTTPStatusCode() != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", err.HTTPStatusCode())
+	}
+	if !IsDataResidencyError(err) {
+		t.Fatal("IsDataResidencyError() = false, want true")
+	}
+	if want := `no provider satisfies data residency requirement "eu" for model openai/gpt-4o`; err.Message != want {
+		t.Fatalf("message = %q, want %q", err.Message, want)
+	}
+	if IsDataResidencyError(NewInvalidRequestError("other", nil)) {
+		t.Fatal("IsDataResidencyError() = true for unrelated error")
+	}
+}
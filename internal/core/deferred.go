@@ -0,0 +1,39 @@
+package core
+
+import "encoding/json"
+
+const (
+	// DeferredHeader opts a non-streaming request into deferred execution:
+	// when it fails with a retriable provider error the gateway queues it for
+	// replay and answers 202 instead of returning the error.
+	DeferredHeader = "X-GoModel-Deferred"
+	// DeferredCallbackHeader carries an optional http(s) URL that receives the
+	// final DeferredRequestResponse as a JSON POST.
+	DeferredCallbackHeader = "X-GoModel-Deferred-Callback"
+	// DeferredIDHeader reports the id of a queued deferred request.
+	DeferredIDHeader = "X-GoModel-Deferred-ID"
+)
+
+// DeferredRequestResponse describes a queued deferred request. It is returned
+// with 202 when a request is deferred, by GET /v1/deferred/{id}, and as the
+// callback payload once the request reaches a final status.
+type DeferredRequestResponse struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	// Status is one of queued, running, succeeded, failed or expired.
+	Status      string `json:"status"`
+	Endpoint    string `json:"endpoint"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	CreatedAt   int64  `json:"created_at"`
+	ExpiresAt   int64  `json:"expires_at"`
+	// NextAttemptAt is set while the request waits for its next replay.
+	NextAttemptAt *int64 `json:"next_attempt_at,omitempty"`
+	CompletedAt   *int64 `json:"completed_at,omitempty"`
+	// LastError is the error message of the most recent failed attempt.
+	LastError string `json:"last_error,omitempty"`
+	// StatusCode and Response hold the final upstream-facing response once the
+	// request succeeded or failed permanently.
+	StatusCode int             `json:"status_code,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty" swaggertype:"object"`
+}
@@ -0,0 +1,10 @@
+package core
+
+const (
+	// EmbeddingCacheHitsHeader reports how many embeddings inputs were served
+	// without an upstream call.
+	EmbeddingCacheHitsHeader = "X-GoModel-Embedding-Cache-Hits"
+	// EmbeddingCacheMissesHeader reports how many embeddings inputs were sent
+	// upstream.
+	EmbeddingCacheMissesHeader = "X-GoModel-Embedding-Cache-Misses"
+)
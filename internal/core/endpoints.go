@@ -20,10 +20,12 @@ type Operation string
 
 const (
 	OperationChatCompletions     Operation = "chat_completions"
+	OperationChatComparison      Operation = "chat_comparison"
 	OperationResponses           Operation = "responses"
 	OperationEmbeddings          Operation = "embeddings"
 	OperationBatches             Operation = "batches"
 	OperationFiles               Operation = "files"
+	OperationAssistants          Operation = "assistants"
 	OperationProviderPassthrough Operation = "provider_passthrough"
 )
 
@@ -61,6 +63,13 @@ func describeEndpointPath(path string) EndpointDescriptor {
 			Dialect:          "openai_compat",
 			Operation:        OperationChatCompletions,
 		}
+	case path == "/v1/chat/completions/compare":
+		return EndpointDescriptor{
+			ModelInteraction: true,
+			IngressManaged:   true,
+			Dialect:          "openai_compat",
+			Operation:        OperationChatComparison,
+		}
 	case matchesEndpointPath(path, "/v1/responses"):
 		return EndpointDescriptor{
 			ModelInteraction: true,
@@ -89,6 +98,13 @@ func describeEndpointPath(path string) EndpointDescriptor {
 			Dialect:          "openai_compat",
 			Operation:        OperationFiles,
 		}
+	case matchesEndpointPath(path, "/v1/assistants") || matchesEndpointPath(path, "/v1/threads"):
+		return EndpointDescriptor{
+			ModelInteraction: true,
+			IngressManaged:   true,
+			Dialect:          "openai_compat",
+			Operation:        OperationAssistants,
+		}
 	case strings.HasPrefix(path, "/p/"):
 		return EndpointDescriptor{
 			ModelInteraction: true,
@@ -106,7 +122,7 @@ func bodyModeForEndpoint(method, path string, operation Operation) BodyMode {
 	path = normalizeEndpointPath(path)
 
 	switch operation {
-	case OperationChatCompletions, OperationEmbeddings:
+	case OperationChatCompletions, OperationChatComparison, OperationEmbeddings:
 		return BodyModeJSON
 	case OperationResponses:
 		if method == http.MethodPost && (path == "/v1/responses" || path == "/v1/responses/input_tokens" || path == "/v1/responses/compact") {
@@ -128,7 +144,7 @@ func bodyModeForEndpoint(method, path string, operation Operation) BodyMode {
 			return BodyModeMultipart
 		}
 		return BodyModeNone
-	case OperationProviderPassthrough:
+	case OperationAssistants, OperationProviderPassthrough:
 		return BodyModeOpaque
 	default:
 		return BodyModeNone
@@ -15,12 +15,16 @@ func TestDescribeEndpointPath(t *testing.T) {
 		interaction bool
 	}{
 		{path: "/v1/chat/completions", managed: true, dialect: "openai_compat", operation: OperationChatCompletions, bodyMode: BodyModeJSON, interaction: true},
+		{path: "/v1/chat/completions/compare", managed: true, dialect: "openai_compat", operation: OperationChatComparison, bodyMode: BodyModeJSON, interaction: true},
 		{path: "/v1/chat/completions/", managed: true, dialect: "openai_compat", operation: OperationChatCompletions, bodyMode: BodyModeJSON, interaction: true},
 		{path: "/v1/responses/resp_1", managed: true, dialect: "openai_compat", operation: OperationResponses, bodyMode: BodyModeNone, interaction: true},
 		{path: "/v1/responses/resp_1/input_items", managed: true, dialect: "openai_compat", operation: OperationResponses, bodyMode: BodyModeNone, interaction: true},
 		{path: "/v1/batches", managed: true, dialect: "openai_compat", operation: OperationBatches, bodyMode: BodyModeNone, interaction: true},
 		{path: "/v1/embeddings/", managed: true, dialect: "openai_compat", operation: OperationEmbeddings, bodyMode: BodyModeJSON, interaction: true},
 		{path: "/v1/files/file_1", managed: true, dialect: "openai_compat", operation: OperationFiles, bodyMode: BodyModeNone, interaction: true},
+		{path: "/v1/threads/thread_1/runs", managed: true, dialect: "openai_compat", operation: OperationAssistants, bodyMode: BodyModeOpaque, interaction: true},
+		{path: "/v1/assistants", managed: true, dialect: "openai_compat", operation: OperationAssistants, bodyMode: BodyModeOpaque, interaction: true},
+		{path: "/v1/threadsx", managed: false, dialect: "", operation: "", bodyMode: BodyModeNone, interaction: false},
 		{path: "/p/openai/responses", managed: true, dialect: "provider_passthrough", operation: OperationProviderPassthrough, bodyMode: BodyModeOpaque, interaction: true},
 		{path: "/v1/models", managed: false, dialect: "", operation: "", bodyMode: BodyModeNone, interaction: false},
 	}
@@ -2,7 +2,6 @@
 package core
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 )
@@ -21,6 +20,10 @@ const (
 	ErrorTypeAuthentication ErrorType = "authentication_error"
 	// ErrorTypeNotFound indicates a not found error (404)
 	ErrorTypeNotFound ErrorType = "not_found_error"
+	// ErrorTypeOverloaded indicates the provider is temporarily over capacity (503)
+	ErrorTypeOverloaded ErrorType = "overloaded_error"
+	// ErrorTypeContentFilter indicates the provider refused the request under its content policy (400)
+	ErrorTypeContentFilter ErrorType = "content_filter_error"
 )
 
 // GatewayError is the base error type for all gateway errors
@@ -31,6 +34,11 @@ type GatewayError struct {
 	Provider   string    `json:"provider,omitempty"`
 	Param      *string   `json:"param" extensions:"x-nullable"`
 	Code       *string   `json:"code" extensions:"x-nullable"`
+	// ProviderError is the raw error object returned by the upstream provider.
+	ProviderError any `json:"provider_error,omitempty"`
+	// UpstreamStatusCode is the HTTP status the provider answered with, before
+	// any mapping to StatusCode. Zero when no upstream response was received.
+	UpstreamStatusCode int `json:"-"`
 	// Original error for debugging (not exposed to clients)
 	Err error `json:"-"`
 }
@@ -46,6 +54,8 @@ type OpenAIErrorObject struct {
 	Message string    `json:"message" binding:"required"`
 	Param   *string   `json:"param" binding:"required" extensions:"x-nullable"`
 	Code    *string   `json:"code" binding:"required" extensions:"x-nullable"`
+	// ProviderError is the raw upstream error object, present when the error came from a provider.
+	ProviderError any `json:"provider_error,omitempty"`
 }
 
 // Error implements the error interface
@@ -76,6 +86,10 @@ func (e *GatewayError) HTTPStatusCode() int {
 		return http.StatusUnauthorized
 	case ErrorTypeNotFound:
 		return http.StatusNotFound
+	case ErrorTypeContentFilter:
+		return http.StatusBadRequest
+	case ErrorTypeOverloaded:
+		return http.StatusServiceUnavailable
 	case ErrorTypeProvider:
 		return http.StatusBadGateway
 	default:
@@ -95,14 +109,16 @@ func (e *GatewayError) ToJSON() map[string]any {
 		code = *e.Code
 	}
 
-	return map[string]any{
-		"error": map[string]any{
-			"type":    e.Type,
-			"message": e.Message,
-			"param":   param,
-			"code":    code,
-		},
+	body := map[string]any{
+		"type":    e.Type,
+		"message": e.Message,
+		"param":   param,
+		"code":    code,
+	}
+	if e.ProviderError != nil {
+		body["provider_error"] = e.ProviderError
 	}
+	return map[string]any{"error": body}
 }
 
 // WithParam annotates the error with the offending parameter name.
@@ -163,6 +179,16 @@ func NewAuthenticationError(provider string, message string) *GatewayError {
 	}
 }
 
+// NewForbiddenError creates a new error for authenticated callers that lack
+// permission for the requested operation (403)
+func NewForbiddenError(message string) *GatewayError {
+	return &GatewayError{
+		Type:       ErrorTypeAuthentication,
+		Message:    message,
+		StatusCode: http.StatusForbidden,
+	}
+}
+
 // NewNotFoundError creates a new not found error (404)
 func NewNotFoundError(message string) *GatewayError {
 	return &GatewayError{
@@ -172,28 +198,55 @@ func NewNotFoundError(message string) *GatewayError {
 	}
 }
 
-// ParseProviderError parses an error response from a provider and returns an appropriate GatewayError
+// ParseProviderError parses an error response from a provider and returns an appropriate GatewayError.
+// The upstream error is classified into a canonical ErrorType using the provider's
+// entry in providerErrorRules, falling back to the HTTP status code, and the raw
+// upstream error object is kept in ProviderError. Gateway-internal headers
+// the provider echoes back are redacted from both.
 func ParseProviderError(provider string, statusCode int, body []byte, originalErr error) *GatewayError {
-	// Try to parse the error response as JSON
-	var errorResponse struct {
-		Error struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    string `json:"code"`
-			Param   string `json:"param"`
-		} `json:"error"`
+	upstream := parseUpstreamError(body)
+	message := upstream.Message
+	if message == "" {
+		message = string(body)
+	}
+	message = StripInternalHeaders(message)
+	upstream.Raw = stripInternalHeaderValues(upstream.Raw)
+
+	var gatewayErr *GatewayError
+	if rule, ok := matchProviderErrorRule(provider, statusCode, upstream); ok {
+		status := statusCode
+		if rule.Status != 0 {
+			status = rule.Status
+		}
+		gatewayErr = &GatewayError{
+			Type:       rule.Canonical,
+			Message:    message,
+			StatusCode: status,
+			Provider:   provider,
+			Err:        originalErr,
+		}
+	} else {
+		gatewayErr = providerErrorFromStatus(provider, statusCode, message, originalErr)
 	}
 
-	message := string(body)
-	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Message != "" {
-		message = errorResponse.Error.Message
+	if upstream.Param != "" {
+		gatewayErr = gatewayErr.WithParam(upstream.Param)
 	}
+	if upstream.Code != "" {
+		gatewayErr = gatewayErr.WithCode(upstream.Code)
+	}
+	gatewayErr.ProviderError = upstream.Raw
+	gatewayErr.UpstreamStatusCode = statusCode
 
-	// Determine error type based on status code
-	var gatewayErr *GatewayError
+	return gatewayErr
+}
+
+// providerErrorFromStatus classifies upstream errors that no rule matched by
+// their HTTP status code alone.
+func providerErrorFromStatus(provider string, statusCode int, message string, originalErr error) *GatewayError {
 	switch {
 	case statusCode == http.StatusUnauthorized:
-		gatewayErr = &GatewayError{
+		return &GatewayError{
 			Type:       ErrorTypeAuthentication,
 			Message:    message,
 			StatusCode: http.StatusUnauthorized,
@@ -201,7 +254,7 @@ func ParseProviderError(provider string, statusCode int, body []byte, originalEr
 			Err:        originalErr,
 		}
 	case statusCode == http.StatusForbidden:
-		gatewayErr = &GatewayError{
+		return &GatewayError{
 			Type:       ErrorTypeAuthentication,
 			Message:    message,
 			StatusCode: http.StatusForbidden,
@@ -209,7 +262,7 @@ func ParseProviderError(provider string, statusCode int, body []byte, originalEr
 			Err:        originalErr,
 		}
 	case statusCode == http.StatusTooManyRequests:
-		gatewayErr = &GatewayError{
+		return &GatewayError{
 			Type:       ErrorTypeRateLimit,
 			Message:    message,
 			StatusCode: http.StatusTooManyRequests,
@@ -218,27 +271,20 @@ func ParseProviderError(provider string, statusCode int, body []byte, originalEr
 		}
 	case statusCode == http.StatusNotFound:
 		// 404 - model or resource not found
-		gatewayErr = NewNotFoundError(message)
+		gatewayErr := NewNotFoundError(message)
 		gatewayErr.Provider = provider
 		gatewayErr.Err = originalErr
+		return gatewayErr
 	case statusCode >= 400 && statusCode < 500:
 		// Client errors from provider - mark as invalid request and preserve both provider info and original status code
-		gatewayErr = NewInvalidRequestErrorWithStatus(statusCode, message, originalErr)
+		gatewayErr := NewInvalidRequestErrorWithStatus(statusCode, message, originalErr)
 		gatewayErr.Provider = provider
+		return gatewayErr
 	case statusCode >= 500:
 		// Server errors from provider - preserve the original status code (500, 503, 504, etc.)
-		gatewayErr = NewProviderError(provider, statusCode, message, originalErr)
+		return NewProviderError(provider, statusCode, message, originalErr)
 	default:
 		// For any other status codes (2xx, 3xx, etc.), treat as provider error with Bad Gateway
-		gatewayErr = NewProviderError(provider, http.StatusBadGateway, message, originalErr)
-	}
-
-	if errorResponse.Error.Param != "" {
-		gatewayErr = gatewayErr.WithParam(errorResponse.Error.Param)
+		return NewProviderError(provider, http.StatusBadGateway, message, originalErr)
 	}
-	if errorResponse.Error.Code != "" {
-		gatewayErr = gatewayErr.WithCode(errorResponse.Error.Code)
-	}
-
-	return gatewayErr
 }
@@ -719,3 +719,14 @@ func TestParseProviderError_Preserves5xxStatusCodes(t *testing.T) {
 		})
 	}
 }
+
+func TestParseProviderError_KeepsUpstreamStatusCode(t *testing.T) {
+	err := ParseProviderError("anthropic", 529, []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`), nil)
+
+	if err.UpstreamStatusCode != 529 {
+		t.Errorf("UpstreamStatusCode = %d, want 529", err.UpstreamStatusCode)
+	}
+	if err.HTTPStatusCode() == err.UpstreamStatusCode {
+		t.Errorf("HTTPStatusCode() = %d, want a mapped status different from the upstream 529", err.HTTPStatusCode())
+	}
+}
@@ -0,0 +1,17 @@
+package core
+
+const (
+	// ExperimentHeader reports the A/B experiment a request was assigned to.
+	ExperimentHeader = "X-GoModel-Experiment"
+	// ExperimentVariantHeader reports the experiment variant that served the request.
+	ExperimentVariantHeader = "X-GoModel-Experiment-Variant"
+	// ConversationIDHeader carries a client-chosen conversation id used as the
+	// assignment unit by experiments configured with the "conversation" unit.
+	ConversationIDHeader = "X-GoModel-Conversation-ID"
+)
+
+// ExperimentAssignment records the experiment variant chosen for one request.
+type ExperimentAssignment struct {
+	Experiment string
+	Variant    string
+}
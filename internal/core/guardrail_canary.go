@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// GuardrailCanaryDecision records whether a guardrail in canary rollout was
+// applied to a request.
+type GuardrailCanaryDecision struct {
+	Guardrail string
+	Applied   bool
+}
+
+// GuardrailCanaries records the canary decisions made for a request. A
+// guardrail that runs more than once for the same request (batch items,
+// retries) keeps its first decision, which is the same for every run.
+type GuardrailCanaries struct {
+	mu        sync.Mutex
+	decisions []GuardrailCanaryDecision
+}
+
+// Record stores the decision for guardrail unless one was already recorded.
+func (g *GuardrailCanaries) Record(guardrail string, applied bool) {
+	if g == nil || guardrail == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, decision := range g.decisions {
+		if decision.Guardrail == guardrail {
+			return
+		}
+	}
+	g.decisions = append(g.decisions, GuardrailCanaryDecision{Guardrail: guardrail, Applied: applied})
+}
+
+// Decisions returns the recorded decisions in the order they were made.
+func (g *GuardrailCanaries) Decisions() []GuardrailCanaryDecision {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.decisions) == 0 {
+		return nil
+	}
+	return append([]GuardrailCanaryDecision(nil), g.decisions...)
+}
+
+// RecordGuardrailCanary reports a canary decision to the recorder in ctx, if
+// any.
+func RecordGuardrailCanary(ctx context.Context, guardrail string, applied bool) {
+	GetGuardrailCanaries(ctx).Record(guardrail, applied)
+}
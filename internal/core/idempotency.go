@@ -0,0 +1,10 @@
+package core
+
+const (
+	// IdempotencyKeyHeader carries a client-chosen key. A request repeated
+	// with the same key and body is answered with the first response.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is "true" on responses served from an earlier
+	// request with the same Idempotency-Key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
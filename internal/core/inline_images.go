@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InlineImage describes a base64 data URI image carried in request content.
+// It stands in for the payload wherever the bytes themselves are not needed,
+// such as audit logs and usage accounting.
+type InlineImage struct {
+	MediaType string `json:"media_type" bson:"media_type"`
+	// Bytes is the decoded size of the image.
+	Bytes int64 `json:"bytes" bson:"bytes"`
+	// SHA256 is the hex digest of the base64 payload, so identical images
+	// share a hash without decoding them.
+	SHA256 string `json:"sha256" bson:"sha256"`
+}
+
+// InlineImageStats summarizes the inline images of one request.
+type InlineImageStats struct {
+	Count int
+	Bytes int64
+}
+
+// InlineImageLimits bounds the inline images of one request. Zero values
+// disable the corresponding check.
+type InlineImageLimits struct {
+	MaxImages     int   // Max inline images per request
+	MaxImageBytes int64 // Max decoded size of a single inline image
+}
+
+// ParseInlineImage parses a data:image/...;base64, URI. It reports false for
+// anything else, including remote image URLs.
+func ParseInlineImage(uri string) (InlineImage, bool) {
+	const scheme = "data:"
+	if len(uri) < len(scheme) || !strings.EqualFold(uri[:len(scheme)], scheme) {
+		return InlineImage{}, false
+	}
+	header, payload, ok := strings.Cut(uri[len(scheme):], ",")
+	if !ok {
+		return InlineImage{}, false
+	}
+	mediaType, params, _ := strings.Cut(header, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if !strings.HasPrefix(mediaType, "image/") || !hasBase64Param(params) {
+		return InlineImage{}, false
+	}
+	sum := sha256.Sum256([]byte(payload))
+	return InlineImage{
+		MediaType: mediaType,
+		Bytes:     base64DecodedLen(payload),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}, true
+}
+
+func hasBase64Param(params string) bool {
+	for param := range strings.SplitSeq(params, ";") {
+		if strings.EqualFold(strings.TrimSpace(param), "base64") {
+			return true
+		}
+	}
+	return false
+}
+
+// base64DecodedLen returns the decoded size of a base64 payload without
+// decoding it. Padding and line breaks are not counted.
+func base64DecodedLen(payload string) int64 {
+	var chars int64
+	for i := 0; i < len(payload); i++ {
+		switch payload[i] {
+		case '=', '\r', '\n', ' ', '\t':
+		default:
+			chars++
+		}
+	}
+	return chars * 3 / 4
+}
+
+// Placeholder returns the text that replaces the image payload in audit logs.
+func (img InlineImage) Placeholder() string {
+	return fmt.Sprintf("[inline image omitted: %s, %d bytes, sha256:%s]", img.MediaType, img.Bytes, img.SHA256)
+}
+
+// InlineImageStatsOf sums the count and decoded size of images.
+func InlineImageStatsOf(images []InlineImage) InlineImageStats {
+	stats := InlineImageStats{Count: len(images)}
+	for _, img := range images {
+		stats.Bytes += img.Bytes
+	}
+	return stats
+}
+
+// Check returns an invalid request error when images exceed the limits.
+func (l InlineImageLimits) Check(images []InlineImage) error {
+	if l.MaxImages > 0 && len(images) > l.MaxImages {
+		return NewInvalidRequestError(
+			fmt.Sprintf("request contains %d inline images, at most %d are allowed", len(images), l.MaxImages), nil,
+		).WithCode("too_many_images")
+	}
+	if l.MaxImageBytes <= 0 {
+		return nil
+	}
+	for i, img := range images {
+		if img.Bytes > l.MaxImageBytes {
+			return NewInvalidRequestError(
+				fmt.Sprintf("inline image %d is %d bytes, at most %d bytes are allowed per image", i+1, img.Bytes, l.MaxImageBytes), nil,
+			).WithCode("image_too_large")
+		}
+	}
+	return nil
+}
+
+// RequestInlineImages returns the inline images in the message content of a
+// translated chat or responses request, in message order. The request is not
+// modified: providers still receive the original payload.
+func RequestInlineImages(req any) []InlineImage {
+	var images []InlineImage
+	switch typed := req.(type) {
+	case *ChatRequest:
+		if typed == nil {
+			return nil
+		}
+		for i := range typed.Messages {
+			images = appendContentInlineImages(images, typed.Messages[i].Content)
+		}
+	case *ResponsesRequest:
+		if typed == nil {
+			return nil
+		}
+		switch input := typed.Input.(type) {
+		case []ResponsesInputElement:
+			for i := range input {
+				images = appendContentInlineImages(images, input[i].Content)
+			}
+		case []any:
+			images = appendInlineImagesInValue(images, input)
+		}
+	}
+	return images
+}
+
+func appendContentInlineImages(images []InlineImage, content any) []InlineImage {
+	switch parts := content.(type) {
+	case []ContentPart:
+		for _, part := range parts {
+			if part.ImageURL == nil {
+				continue
+			}
+			if img, ok := ParseInlineImage(part.ImageURL.URL); ok {
+				images = append(images, img)
+			}
+		}
+		return images
+	case []any:
+		return appendInlineImagesInValue(images, parts)
+	default:
+		return images
+	}
+}
+
+// appendInlineImagesInValue collects the inline images of a JSON value
+// decoded into any without modifying it.
+func appendInlineImagesInValue(images []InlineImage, value any) []InlineImage {
+	switch typed := value.(type) {
+	case string:
+		if img, ok := ParseInlineImage(typed); ok {
+			images = append(images, img)
+		}
+	case map[string]any:
+		for _, child := range typed {
+			images = appendInlineImagesInValue(images, child)
+		}
+	case []any:
+		for _, child := range typed {
+			images = appendInlineImagesInValue(images, child)
+		}
+	}
+	return images
+}
+
+// StripInlineImages walks a JSON value decoded into any and replaces every
+// inline image data URI string in place with its placeholder, calling found
+// (when non-nil) for each image. It returns the possibly replaced value;
+// callers must pass a value they own.
+func StripInlineImages(value any, found func(InlineImage)) any {
+	switch typed := value.(type) {
+	case string:
+		if img, ok := ParseInlineImage(typed); ok {
+			if found != nil {
+				found(img)
+			}
+			return img.Placeholder()
+		}
+	case map[string]any:
+		for key, child := range typed {
+			typed[key] = StripInlineImages(child, found)
+		}
+	case []any:
+		for i, child := range typed {
+			typed[i] = StripInlineImages(child, found)
+		}
+	}
+	return value
+}
+
+// WithInlineImageStats returns a new context carrying the inline image stats
+// of the request.
+func WithInlineImageStats(ctx context.Context, stats InlineImageStats) context.Context {
+	return context.WithValue(ctx, inlineImageStatsKey, stats)
+}
+
+// GetInlineImageStats retrieves the inline image stats of the request from
+// context. It returns zero stats when none were recorded.
+func GetInlineImageStats(ctx context.Context) InlineImageStats {
+	if ctx == nil {
+		return InlineImageStats{}
+	}
+	stats, _ := ctx.Value(inlineImageStatsKey).(InlineImageStats)
+	return stats
+}
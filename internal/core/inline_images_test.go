@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testInlineImageURI(mediaType string, size int) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(make([]byte, size))
+}
+
+func TestParseInlineImage(t *testing.T) {
+	tests := []struct {
+		name      string
+		uri       string
+		wantOK    bool
+		wantType  string
+		wantBytes int64
+	}{
+		{name: "png", uri: testInlineImageURI("image/png", 3000), wantOK: true, wantType: "image/png", wantBytes: 3000},
+		{name: "one padding byte", uri: testInlineImageURI("image/jpeg", 1024), wantOK: true, wantType: "image/jpeg", wantBytes: 1024},
+		{name: "two padding bytes", uri: testInlineImageURI("image/webp", 1025), wantOK: true, wantType: "image/webp", wantBytes: 1025},
+		{name: "parameters and case", uri: "DATA:Image/PNG;name=a.png;Base64,AAAA", wantOK: true, wantType: "image/png", wantBytes: 3},
+		{name: "remote url", uri: "https://example.com/cat.png"},
+		{name: "not base64", uri: "data:image/svg+xml,<svg/>"},
+		{name: "not an image", uri: "data:application/pdf;base64,AAAA"},
+		{name: "no payload separator", uri: "data:image/png;base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, ok := ParseInlineImage(tt.uri)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseInlineImage() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if img.MediaType != tt.wantType || img.Bytes != tt.wantBytes || len(img.SHA256) != 64 {
+				t.Fatalf("ParseInlineImage() = %+v, want %s with %d bytes and a sha256", img, tt.wantType, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestRequestInlineImages_MultiImageMessages(t *testing.T) {
+	first, second, third := testInlineImageURI("image/png", 10), testInlineImageURI("image/jpeg", 20), testInlineImageURI("image/gif", 30)
+	chat := &ChatRequest{Messages: []Message{
+		{Role: "system", Content: "describe images"},
+		{Role: "user", Content: []ContentPart{
+			{Type: "text", Text: "two images"},
+			{Type: "image_url", ImageURL: &ImageURLContent{URL: first}},
+			{Type: "image_url", ImageURL: &ImageURLContent{URL: "https://example.com/remote.png"}},
+			{Type: "image_url", ImageURL: &ImageURLContent{URL: second}},
+		}},
+		{Role: "user", Content: []any{
+			map[string]any{"type": "image_url", "image_url": map[string]any{"url": third}},
+		}},
+	}}
+
+	images := RequestInlineImages(chat)
+	if len(images) != 3 || images[0].Bytes != 10 || images[1].Bytes != 20 || images[2].MediaType != "image/gif" {
+		t.Fatalf("RequestInlineImages() = %+v, want the three inline images in order", images)
+	}
+	if stats := InlineImageStatsOf(images); stats.Count != 3 || stats.Bytes != 60 {
+		t.Fatalf("InlineImageStatsOf() = %+v, want 3 images and 60 bytes", stats)
+	}
+	if url := chat.Messages[2].Content.([]any)[0].(map[string]any)["image_url"].(map[string]any)["url"]; url != third {
+		t.Fatal("RequestInlineImages() modified the request content")
+	}
+
+	responses := &ResponsesRequest{Input: []ResponsesInputElement{{
+		Role: "user",
+		Content: []ContentPart{
+			{Type: "input_image", ImageURL: &ImageURLContent{URL: first}},
+			{Type: "input_image", ImageURL: &ImageURLContent{URL: second}},
+		},
+	}}}
+	if images := RequestInlineImages(responses); len(images) != 2 {
+		t.Fatalf("RequestInlineImages(responses) = %+v, want 2 images", images)
+	}
+}
+
+func TestStripInlineImages(t *testing.T) {
+	png, jpeg := testInlineImageURI("image/png", 4096), testInlineImageURI("image/jpeg", 512)
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":[` +
+		`{"type":"image_url","image_url":{"url":"` + png + `"}},` +
+		`{"type":"image_url","image_url":{"url":"` + jpeg + `"}},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]}]}`
+	var parsed any
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	var found []InlineImage
+	stripped, err := json.Marshal(StripInlineImages(parsed, func(img InlineImage) { found = append(found, img) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d images, want 2", len(found))
+	}
+	if strings.Contains(string(stripped), "base64,") {
+		t.Fatalf("stripped body keeps image payloads: %s", stripped)
+	}
+	for _, want := range []string{found[0].Placeholder(), found[1].Placeholder(), "https://example.com/cat.png"} {
+		if !strings.Contains(string(stripped), want) {
+			t.Fatalf("stripped body = %s, want %q", stripped, want)
+		}
+	}
+}
+
+func TestInlineImageLimits_Check(t *testing.T) {
+	images := []InlineImage{{MediaType: "image/png", Bytes: 1024}, {MediaType: "image/png", Bytes: 2048}}
+	tests := []struct {
+		name     string
+		limits   InlineImageLimits
+		wantCode string
+	}{
+		{name: "disabled", limits: InlineImageLimits{}},
+		{name: "exactly at the limits", limits: InlineImageLimits{MaxImages: 2, MaxImageBytes: 2048}},
+		{name: "one image too many", limits: InlineImageLimits{MaxImages: 1}, wantCode: "too_many_images"},
+		{name: "one byte too large", limits: InlineImageLimits{MaxImageBytes: 2047}, wantCode: "image_too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Check(images)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			gatewayErr, ok := err.(*GatewayError)
+			if !ok || gatewayErr.HTTPStatusCode() != 400 || gatewayErr.Code == nil || *gatewayErr.Code != tt.wantCode {
+				t.Fatalf("Check() error = %#v, want a 400 with code %q", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -147,6 +147,21 @@ type ProviderNameResolver interface {
 	GetProviderName(model string) string
 }
 
+// UnlistedModelResolver is an optional interface for components that can tell
+// whether a routed model selector is missing from the model registry and only
+// reaches a provider because it allows unlisted models.
+type UnlistedModelResolver interface {
+	IsUnlistedModel(model string) bool
+}
+
+// LazyProviderInitializer is an optional interface for components that hold
+// providers configured to be constructed on first use. InitializeLazyProviders
+// constructs those that may serve model and reports whether any became
+// available, so the caller can retry resolving it.
+type LazyProviderInitializer interface {
+	InitializeLazyProviders(ctx context.Context, model string) bool
+}
+
 // ProviderTypeNameResolver is an optional interface for components that can map
 // a provider type such as "openai" to the concrete configured provider
 // instance name used for routing, such as "openai_primary".
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// InternalHeaderRedaction replaces the values of gateway-internal headers
+// echoed in provider error messages.
+const InternalHeaderRedaction = "[REDACTED]"
+
+// internalHeaderNames are the credential headers the gateway sends upstream.
+// Every X-GoModel-* header is internal as well.
+var internalHeaderNames = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"api-key":             {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"cookie":              {},
+}
+
+// internalHeaderPattern matches "Name: value" and "\"Name\": \"value\""
+// echoes of internal headers in free text.
+var internalHeaderPattern = regexp.MustCompile(`(?i)\b(authorization|proxy-authorization|api-key|x-api-key|x-goog-api-key|cookie|x-gomodel-[a-z0-9-]+)("?\s*[:=]\s*)("[^"]*"|[^\r\n,;}]*)`)
+
+// IsInternalHeader reports whether name is a gateway-internal header: a
+// credential the gateway sends upstream or an X-GoModel-* header.
+func IsInternalHeader(name string) bool {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if strings.HasPrefix(lower, "x-gomodel-") {
+		return true
+	}
+	_, ok := internalHeaderNames[lower]
+	return ok
+}
+
+// StripInternalHeaders replaces the values of gateway-internal headers that
+// text echoes, such as a proxy error page listing the request headers.
+func StripInternalHeaders(text string) string {
+	if !mayContainInternalHeader(text) {
+		return text
+	}
+	return internalHeaderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := internalHeaderPattern.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[3], `"`) {
+			return parts[1] + parts[2] + `"` + InternalHeaderRedaction + `"`
+		}
+		return parts[1] + parts[2] + InternalHeaderRedaction
+	})
+}
+
+// stripInternalHeaderValues redacts internal headers in a decoded upstream
+// error object in place: values under internal header keys and header
+// echoes inside strings.
+func stripInternalHeaderValues(value any) any {
+	switch v := value.(type) {
+	case string:
+		return StripInternalHeaders(v)
+	case map[string]any:
+		for key, item := range v {
+			if IsInternalHeader(key) {
+				v[key] = InternalHeaderRedaction
+				continue
+			}
+			v[key] = stripInternalHeaderValues(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = stripInternalHeaderValues(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// mayContainInternalHeader is a cheap pre-check that skips the regular
+// expression for the common message without any header echo.
+func mayContainInternalHeader(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "authorization") ||
+		strings.Contains(lower, "api-key") ||
+		strings.Contains(lower, "cookie") ||
+		strings.Contains(lower, "x-gomodel-")
+}
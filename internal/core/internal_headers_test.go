@@ -0,0 +1,81 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStripInternalHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain text echo",
+			in:   "bad gateway; request headers: Authorization: Bearer sk-secret\nX-GoModel-User-Path: /team/a\nAccept: */*",
+			want: "bad gateway; request headers: Authorization: [REDACTED]\nX-GoModel-User-Path: [REDACTED]\nAccept: */*",
+		},
+		{
+			name: "json echo",
+			in:   `upstream rejected {"x-api-key": "sk-ant-secret", "content-type": "application/json"}`,
+			want: `upstream rejected {"x-api-key": "[REDACTED]", "content-type": "application/json"}`,
+		},
+		{
+			name: "no header mention",
+			in:   "The model does not exist or you do not have access to it.",
+			want: "The model does not exist or you do not have access to it.",
+		},
+		{
+			name: "header named without value",
+			in:   "Missing Authorization header",
+			want: "Missing Authorization header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripInternalHeaders(tt.in); got != tt.want {
+				t.Fatalf("StripInternalHeaders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProviderError_StripsEchoedInternalHeaders(t *testing.T) {
+	body := `{"error":{"message":"proxy error: Authorization: Bearer sk-live-123","type":"server_error","request":{"headers":{"Authorization":"Bearer sk-live-123","X-GoModel-Label-Team":"blue","Accept":"application/json"}}}}`
+
+	gatewayErr := ParseProviderError("openai", http.StatusBadGateway, []byte(body), nil)
+
+	if strings.Contains(gatewayErr.Message, "sk-live-123") {
+		t.Fatalf("Message leaks the credential: %q", gatewayErr.Message)
+	}
+	raw, ok := gatewayErr.ProviderError.(map[string]any)
+	if !ok {
+		t.Fatalf("ProviderError = %T", gatewayErr.ProviderError)
+	}
+	headers := raw["request"].(map[string]any)["headers"].(map[string]any)
+	if headers["Authorization"] != InternalHeaderRedaction || headers["X-GoModel-Label-Team"] != InternalHeaderRedaction {
+		t.Fatalf("internal headers not redacted: %v", headers)
+	}
+	if headers["Accept"] != "application/json" {
+		t.Fatalf("Accept header = %v, want it kept", headers["Accept"])
+	}
+	if strings.Contains(raw["message"].(string), "sk-live-123") {
+		t.Fatalf("raw message leaks the credential: %q", raw["message"])
+	}
+}
+
+func TestIsInternalHeader(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Authorization":       true,
+		"x-goog-api-key":      true,
+		"X-GoModel-User-Path": true,
+		"Content-Type":        false,
+		"X-Request-ID":        false,
+	} {
+		if got := IsInternalHeader(name); got != want {
+			t.Errorf("IsInternalHeader(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -118,6 +118,30 @@ func (fields UnknownJSONFields) IsEmpty() bool {
 	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("{}"))
 }
 
+// Without returns a copy of the container with the given keys removed.
+func (fields UnknownJSONFields) Without(keys ...string) UnknownJSONFields {
+	if fields.IsEmpty() || len(keys) == 0 {
+		return CloneUnknownJSONFields(fields)
+	}
+	filtered, err := extractUnknownJSONFields(fields.raw, keys...)
+	if err != nil {
+		return CloneUnknownJSONFields(fields)
+	}
+	return filtered
+}
+
+// With returns a copy of the container with key set to value.
+func (fields UnknownJSONFields) With(key string, value json.RawMessage) UnknownJSONFields {
+	members := make(map[string]json.RawMessage)
+	if !fields.IsEmpty() {
+		if err := json.Unmarshal(fields.raw, &members); err != nil {
+			return CloneUnknownJSONFields(fields)
+		}
+	}
+	members[key] = value
+	return unknownJSONFieldsFromMap(members, true)
+}
+
 func extractUnknownJSONFields(data []byte, knownFields ...string) (UnknownJSONFields, error) {
 	data = bytes.TrimSpace(data)
 	if len(data) == 0 || data[0] != '{' {
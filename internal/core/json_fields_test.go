@@ -85,6 +85,27 @@ func TestUnknownJSONFieldsFromMap_EmptyRawValueEncodesAsNull(t *testing.T) {
 	}
 }
 
+func TestUnknownJSONFields_Without(t *testing.T) {
+	fields := UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+		"id":     json.RawMessage(`"msg_1"`),
+		"x_keep": json.RawMessage(`{"nested":[1,2]}`),
+	})
+
+	filtered := fields.Without("id", "missing")
+	if got := filtered.Lookup("id"); got != nil {
+		t.Fatalf("id = %q, want removed", got)
+	}
+	if got := filtered.Lookup("x_keep"); !bytes.Equal(got, []byte(`{"nested":[1,2]}`)) {
+		t.Fatalf("x_keep = %q, want preserved", got)
+	}
+	if got := fields.Lookup("id"); got == nil {
+		t.Fatal("Without modified the original fields")
+	}
+	if !fields.Without("id", "x_keep").IsEmpty() {
+		t.Fatal("removing every key should leave an empty container")
+	}
+}
+
 func TestExtractUnknownJSONFields_RejectsInvalidJSONSyntax(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// KeyHealthStatus is what the periodic key health checks concluded about a
+// provider's API key.
+type KeyHealthStatus string
+
+const (
+	// KeyHealthUnknown means no check has authenticated or rejected the key
+	// yet, for example because every check failed for another reason.
+	KeyHealthUnknown KeyHealthStatus = "unknown"
+	// KeyHealthValid means the last conclusive check authenticated.
+	KeyHealthValid KeyHealthStatus = "valid"
+	// KeyHealthInvalid means the provider rejected the key on the configured
+	// number of consecutive checks.
+	KeyHealthInvalid KeyHealthStatus = "key_invalid"
+)
+
+// Key health warnings are hints about a key that still authenticates.
+const (
+	KeyWarningExpiring       = "key_expiring"
+	KeyWarningQuotaExhausted = "quota_exhausted"
+)
+
+// KeyMetadata is what a provider reports about the API key it authenticates
+// with. Nil fields were not reported.
+type KeyMetadata struct {
+	Label     string     `json:"label,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// CreditLimit and CreditRemaining are the key's spending limit and what
+	// is left of it, in the provider's billing unit. Nil for keys without a
+	// limit.
+	CreditLimit     *float64 `json:"credit_limit,omitempty"`
+	CreditRemaining *float64 `json:"credit_remaining,omitempty"`
+}
+
+// KeyMetadataFetcher is an optional provider interface for providers whose
+// API reports metadata about the key in use. The request authenticates the
+// key, so key health checks use it instead of a model listing.
+type KeyMetadataFetcher interface {
+	FetchKeyMetadata(ctx context.Context) (KeyMetadata, error)
+}
+
+// KeyHealth is the state of a provider's API key as seen by the periodic key
+// health checks. Authentication failures and other failures are counted
+// separately: only the former say anything about the key.
+type KeyHealth struct {
+	Status      KeyHealthStatus `json:"status"`
+	LastCheckAt time.Time       `json:"last_check_at"`
+	LastValidAt *time.Time      `json:"last_valid_at,omitempty"`
+	// ConsecutiveAuthFailures counts checks in a row the provider rejected
+	// the key on (401 or 403).
+	ConsecutiveAuthFailures int `json:"consecutive_auth_failures"`
+	// ConsecutiveAvailabilityFailures counts checks in a row that failed for
+	// another reason, such as a timeout or server error. They leave Status
+	// unchanged.
+	ConsecutiveAvailabilityFailures int    `json:"consecutive_availability_failures"`
+	LastError                       string `json:"last_error,omitempty"`
+	// Metadata is the key metadata from the last successful check of a
+	// provider that reports it.
+	Metadata *KeyMetadata `json:"metadata,omitempty"`
+	// Warnings lists KeyWarningExpiring and KeyWarningQuotaExhausted when
+	// the last check found them.
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+)
+
+const (
+	// LabelHeaderPrefix prefixes request label headers: X-GoModel-Label-team
+	// labels the request with team. Label headers are never sent upstream.
+	LabelHeaderPrefix = "X-GoModel-Label-"
+
+	// MaxRequestLabels is the number of labels one request may carry.
+	MaxRequestLabels = 5
+	// MaxRequestLabelKeyLength caps the length of a label key.
+	MaxRequestLabelKeyLength = 32
+	// MaxRequestLabelValueLength caps the length of a label value.
+	MaxRequestLabelValueLength = 64
+)
+
+// NormalizeRequestLabelKey canonicalizes a label key. Keys are lowercased and
+// may only contain letters, digits, '-' and '_'.
+func NormalizeRequestLabelKey(raw string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	if key == "" {
+		return "", fmt.Errorf("label key must not be empty")
+	}
+	if len(key) > MaxRequestLabelKeyLength {
+		return "", fmt.Errorf("label key %q must be at most %d characters", raw, MaxRequestLabelKeyLength)
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("label key %q may only contain letters, digits, '-' and '_'", raw)
+		}
+	}
+	return key, nil
+}
+
+func validateRequestLabelValue(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("label %q must have a value", key)
+	}
+	if len(value) > MaxRequestLabelValueLength {
+		return fmt.Errorf("label %q value must be at most %d characters", key, MaxRequestLabelValueLength)
+	}
+	for _, r := range value {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune("-_.:/", r) {
+			return fmt.Errorf("label %q value may only contain letters, digits, '-', '_', '.', ':' and '/'", key)
+		}
+	}
+	return nil
+}
+
+// NormalizeRequestLabels validates labels and returns them with canonical
+// keys. It returns nil for an empty set.
+func NormalizeRequestLabels(labels map[string]string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(labels))
+	for rawKey, rawValue := range labels {
+		key, err := NormalizeRequestLabelKey(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSpace(rawValue)
+		if err := validateRequestLabelValue(key, value); err != nil {
+			return nil, err
+		}
+		if _, duplicate := normalized[key]; duplicate {
+			return nil, fmt.Errorf("label %q is set more than once", key)
+		}
+		normalized[key] = value
+	}
+	if len(normalized) > MaxRequestLabels {
+		return nil, fmt.Errorf("at most %d labels are allowed per request", MaxRequestLabels)
+	}
+	return normalized, nil
+}
+
+// RequestLabelsFromHeaders validates the X-GoModel-Label-* headers of a
+// request.
+func RequestLabelsFromHeaders(header http.Header) (map[string]string, error) {
+	var raw map[string]string
+	for name, values := range header {
+		if len(name) <= len(LabelHeaderPrefix) || !strings.EqualFold(name[:len(LabelHeaderPrefix)], LabelHeaderPrefix) {
+			continue
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf("label header %s must be set once", name)
+		}
+		if raw == nil {
+			raw = make(map[string]string)
+		}
+		raw[name[len(LabelHeaderPrefix):]] = values[0]
+	}
+	return NormalizeRequestLabels(raw)
+}
+
+// IsRequestLabelHeader reports whether name is an X-GoModel-Label-* header.
+func IsRequestLabelHeader(name string) bool {
+	return len(name) > len(LabelHeaderPrefix) && strings.EqualFold(name[:len(LabelHeaderPrefix)], LabelHeaderPrefix)
+}
+
+// RequestLabelsFromMetadata validates an OpenAI-style metadata object used as
+// request labels. Values must be strings.
+func RequestLabelsFromMetadata(raw json.RawMessage) (map[string]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("metadata must be an object with string values")
+	}
+	return NormalizeRequestLabels(metadata)
+}
+
+// MergeRequestLabels combines body metadata labels with header labels. Header
+// labels win for keys set in both places.
+func MergeRequestLabels(headerLabels, metadataLabels map[string]string) (map[string]string, error) {
+	if len(metadataLabels) == 0 {
+		return headerLabels, nil
+	}
+	merged := maps.Clone(metadataLabels)
+	maps.Copy(merged, headerLabels)
+	if len(merged) > MaxRequestLabels {
+		return nil, fmt.Errorf("at most %d labels are allowed per request", MaxRequestLabels)
+	}
+	return merged, nil
+}
+
+// ProviderSupportsRequestMetadata reports whether a provider type accepts the
+// OpenAI metadata request field. The field is removed from requests sent to
+// every other provider.
+func ProviderSupportsRequestMetadata(providerType string) bool {
+	return strings.EqualFold(strings.TrimSpace(providerType), "openai")
+}
+
+// WithRequestLabels returns a new context carrying the request labels.
+func WithRequestLabels(ctx context.Context, labels map[string]string) context.Context {
+	return context.WithValue(ctx, requestLabelsKey, labels)
+}
+
+// GetRequestLabels retrieves the request labels from context. Callers must
+// treat the map as read-only.
+func GetRequestLabels(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	if labels, ok := ctx.Value(requestLabelsKey).(map[string]string); ok {
+		return labels
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRequestLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty stays unset", labels: nil, want: nil},
+		{name: "lowercases keys and trims values", labels: map[string]string{" Team ": " growth "}, want: map[string]string{"team": "growth"}},
+		{name: "allows value punctuation", labels: map[string]string{"cost_center": "eu:acme/ml-1.2"}, want: map[string]string{"cost_center": "eu:acme/ml-1.2"}},
+		{name: "reject empty key", labels: map[string]string{" ": "x"}, wantErr: true},
+		{name: "reject key charset", labels: map[string]string{"team.name": "x"}, wantErr: true},
+		{name: "reject long key", labels: map[string]string{strings.Repeat("k", MaxRequestLabelKeyLength+1): "x"}, wantErr: true},
+		{name: "reject empty value", labels: map[string]string{"team": ""}, wantErr: true},
+		{name: "reject value charset", labels: map[string]string{"team": "growth team"}, wantErr: true},
+		{name: "reject long value", labels: map[string]string{"team": strings.Repeat("v", MaxRequestLabelValueLength+1)}, wantErr: true},
+		{name: "reject keys that collide after lowercasing", labels: map[string]string{"team": "a", "TEAM": "b"}, wantErr: true},
+		{name: "reject too many labels", labels: map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NormalizeRequestLabels(tt.labels)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeRequestLabels() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRequestLabels() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("NormalizeRequestLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLabelsFromHeaders(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("X-Gomodel-Label-team", "growth")
+	header.Set("X-GoModel-Label-Env", "prod")
+	header.Set("X-GoModel-User-Path", "/team")
+
+	got, err := RequestLabelsFromHeaders(header)
+	if err != nil {
+		t.Fatalf("RequestLabelsFromHeaders() error = %v", err)
+	}
+	if want := map[string]string{"team": "growth", "env": "prod"}; !maps.Equal(got, want) {
+		t.Fatalf("RequestLabelsFromHeaders() = %v, want %v", got, want)
+	}
+
+	header.Add("X-GoModel-Label-Team", "search")
+	if _, err := RequestLabelsFromHeaders(header); err == nil {
+		t.Fatal("RequestLabelsFromHeaders() accepted a repeated label header")
+	}
+}
+
+func TestRequestLabelsFromMetadata(t *testing.T) {
+	t.Parallel()
+
+	got, err := RequestLabelsFromMetadata(json.RawMessage(`{"Team":"growth"}`))
+	if err != nil {
+		t.Fatalf("RequestLabelsFromMetadata() error = %v", err)
+	}
+	if want := map[string]string{"team": "growth"}; !maps.Equal(got, want) {
+		t.Fatalf("RequestLabelsFromMetadata() = %v, want %v", got, want)
+	}
+	if got, err := RequestLabelsFromMetadata(json.RawMessage(`null`)); err != nil || got != nil {
+		t.Fatalf("RequestLabelsFromMetadata(null) = %v, %v, want nil", got, err)
+	}
+	if _, err := RequestLabelsFromMetadata(json.RawMessage(`{"team":1}`)); err == nil {
+		t.Fatal("RequestLabelsFromMetadata() accepted a non-string value")
+	}
+}
+
+func TestMergeRequestLabels(t *testing.T) {
+	t.Parallel()
+
+	got, err := MergeRequestLabels(map[string]string{"team": "growth"}, map[string]string{"team": "search", "env": "prod"})
+	if err != nil {
+		t.Fatalf("MergeRequestLabels() error = %v", err)
+	}
+	if want := map[string]string{"team": "growth", "env": "prod"}; !maps.Equal(got, want) {
+		t.Fatalf("MergeRequestLabels() = %v, want header labels to win: %v", got, want)
+	}
+
+	headers := map[string]string{"a": "1", "b": "2", "c": "3"}
+	metadata := map[string]string{"d": "4", "e": "5", "f": "6"}
+	if _, err := MergeRequestLabels(headers, metadata); err == nil {
+		t.Fatal("MergeRequestLabels() accepted more than MaxRequestLabels labels")
+	}
+}
+
+func TestProviderSupportsRequestMetadata(t *testing.T) {
+	t.Parallel()
+
+	if !ProviderSupportsRequestMetadata("openai") {
+		t.Fatal("openai should accept request metadata")
+	}
+	for _, providerType := range []string{"anthropic", "gemini", "azure", ""} {
+		if ProviderSupportsRequestMetadata(providerType) {
+			t.Fatalf("%q should not accept request metadata", providerType)
+		}
+	}
+}
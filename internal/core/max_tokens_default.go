@@ -0,0 +1,29 @@
+package core
+
+const (
+	// DefaultMaxTokensHeader reports the output token limit the gateway set
+	// on a request that did not set one.
+	DefaultMaxTokensHeader = "X-GoModel-Default-Max-Tokens"
+	// DefaultMaxTokensSourceHeader reports which policy the default came from.
+	DefaultMaxTokensSourceHeader = "X-GoModel-Default-Max-Tokens-Source"
+)
+
+// MaxTokensDefaultSource names the policy a max_tokens default came from.
+type MaxTokensDefaultSource string
+
+const (
+	// MaxTokensDefaultModel is a default configured for the model.
+	MaxTokensDefaultModel MaxTokensDefaultSource = "model"
+	// MaxTokensDefaultDynamic is derived from the model's recent completion
+	// lengths.
+	MaxTokensDefaultDynamic MaxTokensDefaultSource = "dynamic"
+	// MaxTokensDefaultGlobal is the configured default for all models.
+	MaxTokensDefaultGlobal MaxTokensDefaultSource = "global"
+)
+
+// MaxTokensDefault is the output token limit set on a request that did not
+// set one.
+type MaxTokensDefault struct {
+	Value  int
+	Source MaxTokensDefaultSource
+}
@@ -0,0 +1,19 @@
+package core
+
+const (
+	// ModelGroupPrefix marks a requested model that targets a model group.
+	ModelGroupPrefix = "group:"
+	// ModelGroupHeader reports the model group a request targeted.
+	ModelGroupHeader = "X-GoModel-Model-Group"
+	// ModelGroupMemberHeader reports the group member that served the request.
+	ModelGroupMemberHeader = "X-GoModel-Model-Group-Member"
+)
+
+// ModelGroupSelection records the model group member chosen for one request.
+type ModelGroupSelection struct {
+	Group  string
+	Member string
+	// Fallback is set when no member was eligible and the first member was
+	// used anyway.
+	Fallback bool
+}
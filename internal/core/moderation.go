@@ -0,0 +1,45 @@
+package core
+
+const (
+	// ModerationHeader reports the moderation pre-check outcome of a request
+	// that was allowed through: "flagged" when a category with the flag
+	// policy fired, or "unavailable" when the check failed open.
+	ModerationHeader = "X-GoModel-Moderation"
+	// ModerationCategoriesHeader lists the flagged categories, comma-separated.
+	ModerationCategoriesHeader = "X-GoModel-Moderation-Categories"
+	// ContentPolicyViolationCode is the error code of requests blocked by the
+	// moderation pre-check.
+	ContentPolicyViolationCode = "content_policy_violation"
+)
+
+// ModerationAction is the policy applied to a flagged moderation category.
+type ModerationAction string
+
+const (
+	// ModerationBlock rejects the request with a content_policy_violation error.
+	ModerationBlock ModerationAction = "block"
+	// ModerationFlag lets the request through and reports the category.
+	ModerationFlag ModerationAction = "flag"
+	// ModerationIgnore lets the request through without reporting the category.
+	ModerationIgnore ModerationAction = "ignore"
+)
+
+// ModerationCategories lists the categories the moderation pre-check scores.
+var ModerationCategories = []string{
+	"harassment",
+	"hate",
+	"illicit",
+	"self_harm",
+	"sexual",
+	"sexual_minors",
+	"violence",
+}
+
+// ModerationResult describes the moderation pre-check of a request that was
+// allowed through with something to report.
+type ModerationResult struct {
+	Flagged     []string           // categories at or above the threshold with the flag policy, sorted
+	Scores      map[string]float64 // scores returned by the moderation backend
+	Unavailable bool               // the backend failed and the check failed open
+	Error       string             // backend error when Unavailable
+}
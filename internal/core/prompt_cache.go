@@ -0,0 +1,28 @@
+package core
+
+import "context"
+
+// PromptCacheHint marks the leading system prompt text of a request as
+// cacheable. Providers whose prompt caching needs an explicit opt-in mark
+// Prefix as a cache breakpoint; the others cache it automatically.
+type PromptCacheHint struct {
+	// Prefix is the leading text of the system prompt to cache.
+	Prefix string
+	// Key identifies the prefix in prompt cache statistics.
+	Key string
+}
+
+// WithPromptCacheHint returns a new context carrying the prompt cache hint.
+func WithPromptCacheHint(ctx context.Context, hint PromptCacheHint) context.Context {
+	return context.WithValue(ctx, promptCacheKey, hint)
+}
+
+// GetPromptCacheHint retrieves the prompt cache hint from context. Prefix is
+// empty when the request has no cacheable prefix.
+func GetPromptCacheHint(ctx context.Context) PromptCacheHint {
+	if ctx == nil {
+		return PromptCacheHint{}
+	}
+	hint, _ := ctx.Value(promptCacheKey).(PromptCacheHint)
+	return hint
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PromptCompressionHeader selects the prompt compression passes for one
+	// request as a comma-separated list, or "off" to skip the stage. On
+	// responses it reports the passes that changed the prompt.
+	PromptCompressionHeader = "X-GoModel-Prompt-Compression"
+	// PromptTokensSavedHeader reports the estimated prompt tokens removed by
+	// prompt compression.
+	PromptTokensSavedHeader = "X-GoModel-Prompt-Tokens-Saved"
+)
+
+// PromptCompressionPass names one prompt compression pass or scope option.
+type PromptCompressionPass string
+
+const (
+	// PromptCompressionWhitespace collapses runs of spaces and blank lines
+	// outside fenced code blocks.
+	PromptCompressionWhitespace PromptCompressionPass = "whitespace"
+	// PromptCompressionDedupe removes paragraphs that repeat an earlier
+	// paragraph of the context messages verbatim.
+	PromptCompressionDedupe PromptCompressionPass = "dedupe"
+	// PromptCompressionDataURI replaces inline base64 data URIs above the
+	// configured size with a short placeholder.
+	PromptCompressionDataURI PromptCompressionPass = "data_uri"
+	// PromptCompressionIncludeSystem lets the passes rewrite system and
+	// developer messages, which are left untouched by default.
+	PromptCompressionIncludeSystem PromptCompressionPass = "include_system"
+	// PromptCompressionIncludeLatestUser lets the passes rewrite the latest
+	// user turn, which is left untouched by default.
+	PromptCompressionIncludeLatestUser PromptCompressionPass = "include_latest_user"
+	// PromptCompressionOff disables the stage for a request.
+	PromptCompressionOff PromptCompressionPass = "off"
+)
+
+// PromptCompressionPasses is a parsed set of passes and scope options.
+type PromptCompressionPasses map[PromptCompressionPass]bool
+
+// ParsePromptCompressionPasses parses a comma-separated pass list. "off" (or
+// "none") yields an empty, non-nil set; an empty value yields nil so callers
+// can fall back to a configured default.
+func ParsePromptCompressionPasses(value string) (PromptCompressionPasses, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	passes := PromptCompressionPasses{}
+	for _, item := range strings.Split(value, ",") {
+		pass := PromptCompressionPass(strings.ToLower(strings.TrimSpace(item)))
+		switch pass {
+		case "":
+		case PromptCompressionOff, "none":
+			return PromptCompressionPasses{}, nil
+		case PromptCompressionWhitespace, PromptCompressionDedupe, PromptCompressionDataURI,
+			PromptCompressionIncludeSystem, PromptCompressionIncludeLatestUser:
+			passes[pass] = true
+		default:
+			return nil, fmt.Errorf("unknown prompt compression pass %q", item)
+		}
+	}
+	return passes, nil
+}
+
+// PromptCompressionResult describes how prompt compression changed a request.
+type PromptCompressionResult struct {
+	Passes          []PromptCompressionPass // passes that changed the prompt, in execution order
+	EstimatedTokens int                     // estimated prompt tokens before compression
+	TokensSaved     int
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// PromptTemplateField is the chat and responses request field that selects a
+// stored prompt template. The gateway renders it and strips it before routing.
+const PromptTemplateField = "template"
+
+// PromptTemplateRef selects a stored prompt template and supplies its
+// variables.
+type PromptTemplateRef struct {
+	Name      string         `json:"name"`
+	Version   int            `json:"version,omitempty"` // zero selects the latest version
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// PromptTemplateUse identifies the template version that rendered a request.
+type PromptTemplateUse struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// RequestPromptTemplate decodes the template reference carried by a chat or
+// responses request. It returns nil when the request has none.
+func RequestPromptTemplate(req any) (*PromptTemplateRef, error) {
+	var raw json.RawMessage
+	switch typed := req.(type) {
+	case *ChatRequest:
+		if typed != nil {
+			raw = typed.ExtraFields.Lookup(PromptTemplateField)
+		}
+	case *ResponsesRequest:
+		if typed != nil {
+			raw = typed.ExtraFields.Lookup(PromptTemplateField)
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var ref PromptTemplateRef
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return nil, NewInvalidRequestError("invalid template: "+err.Error(), err).WithParam(PromptTemplateField)
+	}
+	ref.Name = strings.TrimSpace(ref.Name)
+	if ref.Name == "" {
+		return nil, NewInvalidRequestError("template name is required", nil).WithParam(PromptTemplateField)
+	}
+	if ref.Version < 0 {
+		return nil, NewInvalidRequestError("template version must be positive", nil).WithParam(PromptTemplateField)
+	}
+	return &ref, nil
+}
+
+// ApplyPromptTemplateMessages strips the template field from a chat or
+// responses request and prepends the rendered messages to its conversation.
+func ApplyPromptTemplateMessages(req any, messages []Message) {
+	switch typed := req.(type) {
+	case *ChatRequest:
+		if typed == nil {
+			return
+		}
+		typed.ExtraFields = typed.ExtraFields.Without(PromptTemplateField)
+		typed.Messages = append(append(make([]Message, 0, len(messages)+len(typed.Messages)), messages...), typed.Messages...)
+	case *ResponsesRequest:
+		if typed == nil {
+			return
+		}
+		typed.ExtraFields = typed.ExtraFields.Without(PromptTemplateField)
+		typed.Input = prependResponsesInput(typed.Input, messages)
+	}
+}
+
+func prependResponsesInput(input any, messages []Message) any {
+	elements := make([]ResponsesInputElement, 0, len(messages)+1)
+	for _, msg := range messages {
+		elements = append(elements, ResponsesInputElement{Type: "message", Role: msg.Role, Content: msg.Content})
+	}
+	switch typed := input.(type) {
+	case nil:
+		return elements
+	case string:
+		if typed == "" {
+			return elements
+		}
+		return append(elements, ResponsesInputElement{Type: "message", Role: "user", Content: typed})
+	case []ResponsesInputElement:
+		return append(elements, typed...)
+	case []any:
+		merged := make([]any, 0, len(elements)+len(typed))
+		for _, element := range elements {
+			merged = append(merged, map[string]any{"type": element.Type, "role": element.Role, "content": element.Content})
+		}
+		return append(merged, typed...)
+	default:
+		return input
+	}
+}
+
+// WithPromptTemplate records the template version that rendered the request.
+func WithPromptTemplate(ctx context.Context, use PromptTemplateUse) context.Context {
+	return context.WithValue(ctx, promptTemplateKey, use)
+}
+
+// GetPromptTemplate returns the template version that rendered the request.
+// The name is empty when the request used no template.
+func GetPromptTemplate(ctx context.Context) PromptTemplateUse {
+	if ctx == nil {
+		return PromptTemplateUse{}
+	}
+	use, _ := ctx.Value(promptTemplateKey).(PromptTemplateUse)
+	return use
+}
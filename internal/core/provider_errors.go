@@ -0,0 +1,158 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ProviderErrorRule maps one upstream error signature to a canonical ErrorType.
+// Every non-empty field must match; string fields compare case-insensitively
+// and Message matches a substring of the upstream message.
+type ProviderErrorRule struct {
+	StatusCode int    // upstream HTTP status
+	Type       string // upstream error type, or the status name for Google-style errors
+	Code       string // upstream error code
+	Message    string
+
+	Canonical ErrorType
+	// Status is the HTTP status returned to clients. Zero keeps the upstream status.
+	Status int
+}
+
+// providerErrorRules holds the rules for each provider type. They are checked
+// in order before commonProviderErrorRules; an error that matches no rule is
+// classified by its HTTP status. Add an entry here to teach the gateway a new
+// provider's error vocabulary.
+var providerErrorRules = map[string][]ProviderErrorRule{
+	"anthropic": {
+		{Type: "overloaded_error", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{StatusCode: 529, Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{Type: "invalid_request_error", Canonical: ErrorTypeInvalidRequest},
+		{Type: "request_too_large", Canonical: ErrorTypeInvalidRequest},
+		{Type: "authentication_error", Canonical: ErrorTypeAuthentication},
+		{Type: "permission_error", Canonical: ErrorTypeAuthentication},
+		{Type: "not_found_error", Canonical: ErrorTypeNotFound},
+		{Type: "rate_limit_error", Canonical: ErrorTypeRateLimit},
+		{Type: "api_error", Canonical: ErrorTypeProvider},
+	},
+	"gemini": {
+		// Gemini reports invalid API keys as 400 INVALID_ARGUMENT.
+		{Message: "api key not valid", Canonical: ErrorTypeAuthentication, Status: http.StatusUnauthorized},
+		{Type: "INVALID_ARGUMENT", Canonical: ErrorTypeInvalidRequest},
+		{Type: "FAILED_PRECONDITION", Canonical: ErrorTypeInvalidRequest},
+		{Type: "OUT_OF_RANGE", Canonical: ErrorTypeInvalidRequest},
+		{Type: "UNAUTHENTICATED", Canonical: ErrorTypeAuthentication},
+		{Type: "PERMISSION_DENIED", Canonical: ErrorTypeAuthentication},
+		{Type: "NOT_FOUND", Canonical: ErrorTypeNotFound},
+		{Type: "RESOURCE_EXHAUSTED", Canonical: ErrorTypeRateLimit},
+		{Type: "UNAVAILABLE", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{Type: "INTERNAL", Canonical: ErrorTypeProvider},
+		{Type: "DEADLINE_EXCEEDED", Canonical: ErrorTypeProvider},
+	},
+	"groq": {
+		// 498 is Groq's flex tier "capacity exceeded" status.
+		{StatusCode: 498, Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{Code: "capacity_exceeded", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{Message: "queue is full", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+		{Message: "over capacity", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+	},
+	"xai": {
+		// xAI reports invalid API keys as 400 with a plain-string error.
+		{Message: "incorrect api key", Canonical: ErrorTypeAuthentication, Status: http.StatusUnauthorized},
+	},
+}
+
+// commonProviderErrorRules covers the OpenAI-compatible codes most providers share.
+var commonProviderErrorRules = []ProviderErrorRule{
+	{Code: "content_filter", Canonical: ErrorTypeContentFilter, Status: http.StatusBadRequest},
+	{Code: "content_policy_violation", Canonical: ErrorTypeContentFilter, Status: http.StatusBadRequest},
+	{Code: "invalid_api_key", Canonical: ErrorTypeAuthentication, Status: http.StatusUnauthorized},
+	{Code: "rate_limit_exceeded", Canonical: ErrorTypeRateLimit, Status: http.StatusTooManyRequests},
+	{Code: "insufficient_quota", Canonical: ErrorTypeRateLimit, Status: http.StatusTooManyRequests},
+	// "overloaded" only counts on capacity statuses, so a client error whose
+	// message happens to contain the word keeps its own classification.
+	{StatusCode: http.StatusTooManyRequests, Message: "overloaded", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+	{StatusCode: http.StatusServiceUnavailable, Message: "overloaded", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+	{StatusCode: 529, Message: "overloaded", Canonical: ErrorTypeOverloaded, Status: http.StatusServiceUnavailable},
+}
+
+// upstreamError is the normalized view of a provider error body.
+type upstreamError struct {
+	Raw     any // the upstream error object, or the body text when it is not JSON
+	Message string
+	Type    string
+	Code    string
+	Param   string
+}
+
+// parseUpstreamError extracts the error object from the shapes providers use:
+// {"error":{...}} (OpenAI, Anthropic, Gemini), a one-element array of those
+// (Gemini's OpenAI-compatible endpoint), and {"error":"...","code":"..."} (xAI).
+func parseUpstreamError(body []byte) upstreamError {
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		if text := strings.TrimSpace(string(body)); text != "" {
+			return upstreamError{Raw: text}
+		}
+		return upstreamError{}
+	}
+	if items, ok := decoded.([]any); ok && len(items) > 0 {
+		decoded = items[0]
+	}
+	envelope, ok := decoded.(map[string]any)
+	if !ok {
+		return upstreamError{Raw: decoded}
+	}
+
+	switch inner := envelope["error"].(type) {
+	case map[string]any:
+		result := upstreamError{
+			Raw:     inner,
+			Message: stringField(inner, "message"),
+			Type:    stringField(inner, "type"),
+			Code:    stringField(inner, "code"),
+			Param:   stringField(inner, "param"),
+		}
+		if status := stringField(inner, "status"); status != "" && result.Type == "" {
+			result.Type = status
+		}
+		return result
+	case string:
+		return upstreamError{Raw: envelope, Message: inner}
+	default:
+		return upstreamError{Raw: envelope, Message: stringField(envelope, "message")}
+	}
+}
+
+func stringField(object map[string]any, key string) string {
+	value, _ := object[key].(string)
+	return strings.TrimSpace(value)
+}
+
+func matchProviderErrorRule(provider string, statusCode int, upstream upstreamError) (ProviderErrorRule, bool) {
+	for _, rules := range [][]ProviderErrorRule{providerErrorRules[strings.ToLower(provider)], commonProviderErrorRules} {
+		for _, rule := range rules {
+			if rule.matches(statusCode, upstream) {
+				return rule, true
+			}
+		}
+	}
+	return ProviderErrorRule{}, false
+}
+
+func (r ProviderErrorRule) matches(statusCode int, upstream upstreamError) bool {
+	if r.StatusCode != 0 && r.StatusCode != statusCode {
+		return false
+	}
+	if r.Type != "" && !strings.EqualFold(r.Type, upstream.Type) {
+		return false
+	}
+	if r.Code != "" && !strings.EqualFold(r.Code, upstream.Code) {
+		return false
+	}
+	if r.Message != "" && !strings.Contains(strings.ToLower(upstream.Message), r.Message) {
+		return false
+	}
+	return true
+}
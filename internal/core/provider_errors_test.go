@@ -0,0 +1,217 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseProviderError_CanonicalTypes(t *testing.T) {
+	tests := []struct {
+		name           string
+		provider       string
+		statusCode     int
+		body           string
+		expectedType   ErrorType
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "anthropic overloaded",
+			provider:       "anthropic",
+			statusCode:     529,
+			body:           `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedType:   ErrorTypeOverloaded,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "anthropic permission error",
+			provider:       "anthropic",
+			statusCode:     http.StatusForbidden,
+			body:           `{"type":"error","error":{"type":"permission_error","message":"Your API key does not have permission"}}`,
+			expectedType:   ErrorTypeAuthentication,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "anthropic request too large",
+			provider:       "anthropic",
+			statusCode:     http.StatusRequestEntityTooLarge,
+			body:           `{"type":"error","error":{"type":"request_too_large","message":"Request exceeds the maximum allowed number of bytes."}}`,
+			expectedType:   ErrorTypeInvalidRequest,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "gemini invalid api key",
+			provider:       "gemini",
+			statusCode:     http.StatusBadRequest,
+			body:           `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`,
+			expectedType:   ErrorTypeAuthentication,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "gemini resource exhausted in array envelope",
+			provider:       "gemini",
+			statusCode:     http.StatusTooManyRequests,
+			body:           `[{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}]`,
+			expectedType:   ErrorTypeRateLimit,
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "gemini unavailable",
+			provider:       "gemini",
+			statusCode:     http.StatusServiceUnavailable,
+			body:           `{"error":{"code":503,"message":"The model is overloaded. Please try again later.","status":"UNAVAILABLE"}}`,
+			expectedType:   ErrorTypeOverloaded,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "groq flex capacity",
+			provider:       "groq",
+			statusCode:     498,
+			body:           `{"error":{"message":"Flex tier capacity exceeded.","type":"service_unavailable","code":"capacity_exceeded"}}`,
+			expectedType:   ErrorTypeOverloaded,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "capacity_exceeded",
+		},
+		{
+			name:           "groq rate limit code",
+			provider:       "groq",
+			statusCode:     http.StatusTooManyRequests,
+			body:           `{"error":{"message":"Rate limit reached for model","type":"tokens","code":"rate_limit_exceeded"}}`,
+			expectedType:   ErrorTypeRateLimit,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   "rate_limit_exceeded",
+		},
+		{
+			name:           "xai plain string error",
+			provider:       "xai",
+			statusCode:     http.StatusBadRequest,
+			body:           `{"code":"Client specified an invalid argument","error":"Incorrect API key provided: xa***. You can obtain an API key from https://console.x.ai."}`,
+			expectedType:   ErrorTypeAuthentication,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "openai content policy",
+			provider:       "openai",
+			statusCode:     http.StatusBadRequest,
+			body:           `{"error":{"message":"Your request was rejected as a result of our safety system.","type":"invalid_request_error","param":null,"code":"content_policy_violation"}}`,
+			expectedType:   ErrorTypeContentFilter,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "content_policy_violation",
+		},
+		{
+			name:           "openai insufficient quota",
+			provider:       "openai",
+			statusCode:     http.StatusTooManyRequests,
+			body:           `{"error":{"message":"You exceeded your current quota.","type":"insufficient_quota","param":null,"code":"insufficient_quota"}}`,
+			expectedType:   ErrorTypeRateLimit,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedCode:   "insufficient_quota",
+		},
+		{
+			name:           "overloaded in a client error message keeps the status",
+			provider:       "openai",
+			statusCode:     http.StatusBadRequest,
+			body:           `{"error":{"message":"Function 'sum' is overloaded; use distinct tool names.","type":"invalid_request_error"}}`,
+			expectedType:   ErrorTypeInvalidRequest,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown provider falls back to status",
+			provider:       "custom",
+			statusCode:     http.StatusServiceUnavailable,
+			body:           `{"error":{"message":"try again"}}`,
+			expectedType:   ErrorTypeProvider,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "provider name matches case-insensitively",
+			provider:       "Anthropic",
+			statusCode:     529,
+			body:           `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedType:   ErrorTypeOverloaded,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseProviderError(tt.provider, tt.statusCode, []byte(tt.body), nil)
+			if err.Type != tt.expectedType {
+				t.Errorf("Type = %q, want %q", err.Type, tt.expectedType)
+			}
+			if err.StatusCode != tt.expectedStatus {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode, tt.expectedStatus)
+			}
+			if tt.expectedCode != "" && (err.Code == nil || *err.Code != tt.expectedCode) {
+				t.Errorf("Code = %v, want %q", err.Code, tt.expectedCode)
+			}
+			if err.ProviderError == nil {
+				t.Error("ProviderError is nil, want the raw upstream error")
+			}
+		})
+	}
+}
+
+func TestParseProviderError_PreservesRawUpstreamError(t *testing.T) {
+	body := []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	err := ParseProviderError("anthropic", 529, body, nil)
+
+	raw, ok := err.ProviderError.(map[string]any)
+	if !ok {
+		t.Fatalf("ProviderError = %#v, want upstream error object", err.ProviderError)
+	}
+	if raw["type"] != "overloaded_error" || raw["message"] != "Overloaded" {
+		t.Fatalf("ProviderError = %v, want upstream fields kept", raw)
+	}
+
+	errorBody := err.ToJSON()["error"].(map[string]any)
+	if errorBody["type"] != ErrorTypeOverloaded {
+		t.Fatalf("type = %v, want %q", errorBody["type"], ErrorTypeOverloaded)
+	}
+	if _, ok := errorBody["provider_error"]; !ok {
+		t.Fatal("ToJSON() is missing provider_error")
+	}
+}
+
+func TestParseProviderError_NonJSONBody(t *testing.T) {
+	err := ParseProviderError("openai", http.StatusBadGateway, []byte("  upstream connect error  "), nil)
+	if err.Type != ErrorTypeProvider {
+		t.Fatalf("Type = %q, want %q", err.Type, ErrorTypeProvider)
+	}
+	if err.ProviderError != "upstream connect error" {
+		t.Fatalf("ProviderError = %#v, want trimmed body text", err.ProviderError)
+	}
+}
+
+func TestGatewayError_ToJSON_OmitsEmptyProviderError(t *testing.T) {
+	body := NewInvalidRequestError("bad", nil).ToJSON()["error"].(map[string]any)
+	if _, ok := body["provider_error"]; ok {
+		t.Fatal("ToJSON() included provider_error for a gateway-originated error")
+	}
+}
+
+func TestProviderErrorRules_HaveCanonicalTypes(t *testing.T) {
+	canonical := map[ErrorType]bool{
+		ErrorTypeInvalidRequest: true,
+		ErrorTypeAuthentication: true,
+		ErrorTypeRateLimit:      true,
+		ErrorTypeNotFound:       true,
+		ErrorTypeProvider:       true,
+		ErrorTypeOverloaded:     true,
+		ErrorTypeContentFilter:  true,
+	}
+	check := func(provider string, rules []ProviderErrorRule) {
+		for i, rule := range rules {
+			if !canonical[rule.Canonical] {
+				t.Errorf("%s rule %d maps to unknown type %q", provider, i, rule.Canonical)
+			}
+			if rule.StatusCode == 0 && rule.Type == "" && rule.Code == "" && rule.Message == "" {
+				t.Errorf("%s rule %d matches every error", provider, i)
+			}
+		}
+	}
+	for provider, rules := range providerErrorRules {
+		check(provider, rules)
+	}
+	check("common", commonProviderErrorRules)
+}
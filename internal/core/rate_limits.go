@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitWindow is one provider account limit as reported by upstream
+// rate-limit response headers. Nil fields were not reported.
+type RateLimitWindow struct {
+	Limit     *int64     `json:"limit,omitempty"`
+	Remaining *int64     `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// RateLimits holds the provider account limits observed on an upstream
+// response. Nil windows were not reported by the provider.
+type RateLimits struct {
+	Requests     *RateLimitWindow `json:"requests,omitempty"`
+	Tokens       *RateLimitWindow `json:"tokens,omitempty"`
+	InputTokens  *RateLimitWindow `json:"input_tokens,omitempty"`
+	OutputTokens *RateLimitWindow `json:"output_tokens,omitempty"`
+	ObservedAt   time.Time        `json:"observed_at"`
+}
+
+// IsEmpty reports whether no limit window was observed.
+func (r RateLimits) IsEmpty() bool {
+	return r.Requests == nil && r.Tokens == nil && r.InputTokens == nil && r.OutputTokens == nil
+}
+
+// Merge returns r updated with the windows observed in next. Windows next
+// does not report keep their previous values, since providers may report
+// request and token limits on different responses.
+func (r RateLimits) Merge(next RateLimits) RateLimits {
+	if next.Requests != nil {
+		r.Requests = next.Requests
+	}
+	if next.Tokens != nil {
+		r.Tokens = next.Tokens
+	}
+	if next.InputTokens != nil {
+		r.InputTokens = next.InputTokens
+	}
+	if next.OutputTokens != nil {
+		r.OutputTokens = next.OutputTokens
+	}
+	if next.ObservedAt.After(r.ObservedAt) {
+		r.ObservedAt = next.ObservedAt
+	}
+	return r
+}
+
+// ExhaustedUntil returns the latest reset time among windows that report no
+// remaining capacity and reset after now. ok is false when every window still
+// has capacity or has already reset.
+func (r RateLimits) ExhaustedUntil(now time.Time) (time.Time, bool) {
+	var until time.Time
+	for _, w := range []*RateLimitWindow{r.Requests, r.Tokens, r.InputTokens, r.OutputTokens} {
+		if w == nil || w.Remaining == nil || *w.Remaining > 0 || w.ResetAt == nil || !w.ResetAt.After(now) {
+			continue
+		}
+		if w.ResetAt.After(until) {
+			until = *w.ResetAt
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// RateLimitFetcher is an optional provider interface for providers that can
+// query their account limits on demand instead of waiting for live traffic.
+type RateLimitFetcher interface {
+	FetchRateLimits(ctx context.Context) (RateLimits, error)
+}
@@ -8,6 +8,16 @@ type RequestModelResolution struct {
 	ProviderType     string
 	ProviderName     string
 	AliasApplied     bool
+	// Experiment is set when an A/B experiment picked the resolved selector.
+	Experiment *ExperimentAssignment
+	// ModelGroup is set when the request targeted a model group.
+	ModelGroup *ModelGroupSelection
+	// DataResidency is the residency requirement the resolved provider was
+	// chosen to satisfy, or "" when the request had none.
+	DataResidency string
+	// UnlistedModel is set when the resolved model is missing from the model
+	// registry and is served by a provider that allows unlisted models.
+	UnlistedModel bool
 }
 
 // RequestedQualifiedModel returns the canonical requested selector.
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RequestOptionsHeader selects gateway behaviors for one request as
+	// comma-separated key=value pairs, for example
+	// "strict_compat=true, prompt_compression=\"whitespace,dedupe\"". Values
+	// containing commas, spaces or quotes are double-quoted, with backslash
+	// escapes.
+	RequestOptionsHeader = "X-GoModel-Options"
+
+	// StrictCompatHeader is the standalone header of the strict_compat
+	// option: "true" or "false".
+	StrictCompatHeader = "X-GoModel-Strict-Compat"
+
+	// FirstTokenTimeoutHeader is the standalone header of the
+	// first_token_timeout option: a duration such as "3s", "off" or
+	// "default".
+	FirstTokenTimeoutHeader = "X-GoModel-First-Token-Timeout"
+
+	// MaxRequestOptionsHeaderBytes caps the total size of the
+	// RequestOptionsHeader values of one request.
+	MaxRequestOptionsHeaderBytes = 1024
+	// MaxRequestOptions caps the pairs one request may send.
+	MaxRequestOptions = 16
+)
+
+// Request option keys.
+const (
+	OptionStrictCompat      = "strict_compat"
+	OptionAccumulate        = "accumulate"
+	OptionPromptCompression = "prompt_compression"
+	OptionFirstTokenTimeout = "first_token_timeout"
+	OptionServerTools       = "server_tools"
+)
+
+// RequestOptions are the gateway behaviors one request selected, through
+// RequestOptionsHeader or the standalone header of an option. The zero value
+// keeps every default.
+type RequestOptions struct {
+	// StrictCompat overrides the configured strict OpenAI compatibility mode;
+	// nil keeps it.
+	StrictCompat *bool
+	// Accumulate is AccumulateJSON to answer a streaming request with one
+	// accumulated JSON response; empty streams as usual.
+	Accumulate string
+	// PromptCompression selects the prompt compression passes in
+	// PromptCompressionHeader syntax; empty uses the configured passes.
+	PromptCompression string
+	// FirstTokenTimeout overrides the configured first-token deadline; nil
+	// keeps it and zero turns it off.
+	FirstTokenTimeout *time.Duration
+	// ServerTools selects the server-side tools the gateway may run for
+	// the request; nil runs none.
+	ServerTools *ServerToolSelection
+
+	// values holds the canonical value of every option the request set.
+	values map[string]string
+}
+
+// Values returns the canonical value of every option the request set, keyed
+// by option key. The map is nil when none was set.
+func (o *RequestOptions) Values() map[string]string {
+	if o == nil || len(o.values) == 0 {
+		return nil
+	}
+	return maps.Clone(o.values)
+}
+
+// Effective returns the value every known option takes for the request, its
+// default when the request did not set it.
+func (o *RequestOptions) Effective() map[string]string {
+	effective := make(map[string]string, len(requestOptionRegistry))
+	for key, option := range requestOptionRegistry {
+		effective[key] = option.defaultValue
+	}
+	if o != nil {
+		maps.Copy(effective, o.values)
+	}
+	return effective
+}
+
+// requestOption registers one option key. A new per-request behavior adds an
+// entry here and a field to RequestOptions.
+type requestOption struct {
+	// defaultValue is the value the option takes when a request does not set
+	// it. Sending it explicitly keeps the default.
+	defaultValue string
+	// header is the standalone header that sets the option on its own. It is
+	// read when the option is not in RequestOptionsHeader.
+	header string
+	// headerKey is the canonical form of header, so looking it up does not
+	// allocate.
+	headerKey string
+	// apply validates value and stores it on opts, returning its canonical
+	// form.
+	apply func(opts *RequestOptions, value string) (string, error)
+}
+
+// requestOptionsHeaderKey is the canonical form of RequestOptionsHeader, so
+// looking it up does not allocate.
+var requestOptionsHeaderKey = http.CanonicalHeaderKey(RequestOptionsHeader)
+
+var requestOptionRegistry = map[string]requestOption{
+	OptionStrictCompat: {
+		defaultValue: "default",
+		header:       StrictCompatHeader,
+		headerKey:    http.CanonicalHeaderKey(StrictCompatHeader),
+		apply: func(opts *RequestOptions, value string) (string, error) {
+			if strings.EqualFold(value, "default") {
+				opts.StrictCompat = nil
+				return "default", nil
+			}
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", fmt.Errorf("must be true, false or default")
+			}
+			opts.StrictCompat = &enabled
+			return strconv.FormatBool(enabled), nil
+		},
+	},
+	OptionAccumulate: {
+		defaultValue: "off",
+		header:       AccumulateHeader,
+		headerKey:    http.CanonicalHeaderKey(AccumulateHeader),
+		apply: func(opts *RequestOptions, value string) (string, error) {
+			switch strings.ToLower(value) {
+			case "off":
+				opts.Accumulate = ""
+				return "off", nil
+			case AccumulateJSON:
+				opts.Accumulate = AccumulateJSON
+				return AccumulateJSON, nil
+			default:
+				return "", fmt.Errorf("supported values: %s, off", AccumulateJSON)
+			}
+		},
+	},
+	OptionPromptCompression: {
+		defaultValue: "default",
+		header:       PromptCompressionHeader,
+		headerKey:    http.CanonicalHeaderKey(PromptCompressionHeader),
+		apply: func(opts *RequestOptions, value string) (string, error) {
+			if strings.EqualFold(value, "default") {
+				opts.PromptCompression = ""
+				return "default", nil
+			}
+			if _, err := ParsePromptCompressionPasses(value); err != nil {
+				return "", err
+			}
+			opts.PromptCompression = value
+			return strings.ToLower(value), nil
+		},
+	},
+	OptionFirstTokenTimeout: {
+		defaultValue: "default",
+		header:       FirstTokenTimeoutHeader,
+		headerKey:    http.CanonicalHeaderKey(FirstTokenTimeoutHeader),
+		apply: func(opts *RequestOptions, value string) (string, error) {
+			switch strings.ToLower(value) {
+			case "default":
+				opts.FirstTokenTimeout = nil
+				return "default", nil
+			case "off":
+				off := time.Duration(0)
+				opts.FirstTokenTimeout = &off
+				return "off", nil
+			}
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return "", fmt.Errorf("must be a positive duration such as 3s, off or default")
+			}
+			opts.FirstTokenTimeout = &timeout
+			return timeout.String(), nil
+		},
+	},
+	OptionServerTools: {
+		defaultValue: "off",
+		header:       ServerToolsHeader,
+		headerKey:    http.CanonicalHeaderKey(ServerToolsHeader),
+		apply: func(opts *RequestOptions, value string) (string, error) {
+			selection, err := ParseServerToolSelection(value)
+			if err != nil {
+				return "", err
+			}
+			opts.ServerTools = selection
+			return selection.String(), nil
+		},
+	},
+}
+
+// RequestOptionKeys returns the known option keys, sorted.
+func RequestOptionKeys() []string {
+	return slices.Sorted(maps.Keys(requestOptionRegistry))
+}
+
+// ParseRequestOptions reads the options of a request from RequestOptionsHeader
+// and the standalone option headers; RequestOptionsHeader wins for an option
+// set in both. Malformed pairs, duplicate keys, oversized headers and invalid
+// values are errors. Unknown keys are errors when strict and skipped
+// otherwise. It returns nil when the request sets no option.
+func ParseRequestOptions(header http.Header, strict bool) (*RequestOptions, error) {
+	var opts *RequestOptions
+	if values := header[requestOptionsHeaderKey]; len(values) > 0 {
+		raw := strings.Join(values, ",")
+		if len(raw) > MaxRequestOptionsHeaderBytes {
+			return nil, fmt.Errorf("%s must be at most %d bytes", RequestOptionsHeader, MaxRequestOptionsHeaderBytes)
+		}
+		pairs, err := parseOptionPairs(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header: %w", RequestOptionsHeader, err)
+		}
+		if len(pairs) > MaxRequestOptions {
+			return nil, fmt.Errorf("%s allows at most %d options", RequestOptionsHeader, MaxRequestOptions)
+		}
+		for _, pair := range pairs {
+			option, known := requestOptionRegistry[pair.key]
+			if !known {
+				if strict {
+					return nil, fmt.Errorf("unknown option %q in %s: known options: %s", pair.key, RequestOptionsHeader, strings.Join(RequestOptionKeys(), ", "))
+				}
+				continue
+			}
+			if opts == nil {
+				opts = &RequestOptions{}
+			}
+			if err := opts.set(pair.key, option, pair.value); err != nil {
+				return nil, fmt.Errorf("invalid %s option %s=%q: %w", RequestOptionsHeader, pair.key, pair.value, err)
+			}
+		}
+	}
+
+	for key, option := range requestOptionRegistry {
+		values := header[option.headerKey]
+		if len(values) == 0 {
+			continue
+		}
+		value := strings.TrimSpace(values[0])
+		if value == "" {
+			continue
+		}
+		if opts == nil {
+			opts = &RequestOptions{}
+		}
+		if _, set := opts.values[key]; set {
+			continue
+		}
+		if err := opts.set(key, option, value); err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %w", option.header, value, err)
+		}
+	}
+	return opts, nil
+}
+
+func (o *RequestOptions) set(key string, option requestOption, value string) error {
+	canonical, err := option.apply(o, value)
+	if err != nil {
+		return err
+	}
+	if o.values == nil {
+		o.values = make(map[string]string, len(requestOptionRegistry))
+	}
+	o.values[key] = canonical
+	return nil
+}
+
+type optionPair struct {
+	key   string
+	value string
+}
+
+// parseOptionPairs splits a RequestOptionsHeader value into key=value pairs.
+// Keys are lowercased; empty pairs between commas are skipped.
+func parseOptionPairs(raw string) ([]optionPair, error) {
+	var pairs []optionPair
+	seen := map[string]bool{}
+	i := 0
+	for i < len(raw) {
+		i = skipOptionSpace(raw, i)
+		if i < len(raw) && raw[i] == ',' {
+			i++
+			continue
+		}
+		if i == len(raw) {
+			break
+		}
+
+		start := i
+		for i < len(raw) && raw[i] != '=' && raw[i] != ',' {
+			i++
+		}
+		key := strings.ToLower(strings.TrimSpace(raw[start:i]))
+		if i == len(raw) || raw[i] != '=' {
+			return nil, fmt.Errorf("expected key=value, got %q", key)
+		}
+		if !validOptionKey(key) {
+			return nil, fmt.Errorf("invalid option key %q", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("option %q is set more than once", key)
+		}
+		seen[key] = true
+		i = skipOptionSpace(raw, i+1)
+
+		var value string
+		if i < len(raw) && raw[i] == '"' {
+			var err error
+			value, i, err = readQuotedOptionValue(raw, i)
+			if err != nil {
+				return nil, fmt.Errorf("option %q: %w", key, err)
+			}
+			i = skipOptionSpace(raw, i)
+			if i < len(raw) && raw[i] != ',' {
+				return nil, fmt.Errorf("option %q: unexpected text after quoted value", key)
+			}
+		} else {
+			start = i
+			for i < len(raw) && raw[i] != ',' {
+				if raw[i] == '"' {
+					return nil, fmt.Errorf("option %q: quotes must enclose the whole value", key)
+				}
+				i++
+			}
+			value = strings.TrimSpace(raw[start:i])
+		}
+		if value == "" {
+			return nil, fmt.Errorf("option %q must have a value", key)
+		}
+		pairs = append(pairs, optionPair{key: key, value: value})
+	}
+	return pairs, nil
+}
+
+// readQuotedOptionValue reads the double-quoted value starting at raw[start]
+// and returns it unescaped with the index after the closing quote.
+func readQuotedOptionValue(raw string, start int) (string, int, error) {
+	var value strings.Builder
+	for i := start + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			if i+1 == len(raw) {
+				return "", 0, fmt.Errorf("unterminated quoted value")
+			}
+			i++
+			value.WriteByte(raw[i])
+		case '"':
+			return value.String(), i + 1, nil
+		default:
+			value.WriteByte(raw[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted value")
+}
+
+func skipOptionSpace(raw string, i int) int {
+	for i < len(raw) && (raw[i] == ' ' || raw[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func validOptionKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestOptions returns a new context carrying the request options.
+func WithRequestOptions(ctx context.Context, opts *RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey, opts)
+}
+
+// GetRequestOptions retrieves the request options from context. It returns
+// nil when the request set none; the methods of a nil *RequestOptions report
+// the defaults.
+func GetRequestOptions(ctx context.Context) *RequestOptions {
+	if ctx == nil {
+		return nil
+	}
+	if opts, ok := ctx.Value(requestOptionsKey).(*RequestOptions); ok {
+		return opts
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRequestOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		header  string
+		strict  bool
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "unset", want: nil},
+		{name: "single option", header: "strict_compat=true", want: map[string]string{"strict_compat": "true"}},
+		{
+			name:   "several options with whitespace",
+			header: " strict_compat = false ,accumulate=JSON,, ",
+			want:   map[string]string{"strict_compat": "false", "accumulate": "json"},
+		},
+		{
+			name:   "quoted value with commas",
+			header: `prompt_compression="whitespace, dedupe", accumulate=json`,
+			want:   map[string]string{"prompt_compression": "whitespace, dedupe", "accumulate": "json"},
+		},
+		{name: "escaped quote reaches validation", header: `prompt_compression="white\"space"`, wantErr: `"white\"space"`},
+		{name: "keys are case-insensitive", header: "Strict_Compat=false", want: map[string]string{"strict_compat": "false"}},
+		{name: "explicit default", header: "prompt_compression=default", want: map[string]string{"prompt_compression": "default"}},
+		{name: "first token timeout", header: "first_token_timeout=1500ms", want: map[string]string{"first_token_timeout": "1.5s"}},
+		{name: "first token timeout off", header: "first_token_timeout=OFF", want: map[string]string{"first_token_timeout": "off"}},
+		{name: "first token timeout not positive", header: "first_token_timeout=0s", wantErr: "positive duration"},
+		{name: "server tools list", header: `server_tools="calculator, current_time"`, want: map[string]string{"server_tools": "calculator,current_time"}},
+		{name: "server tools all", header: "server_tools=ALL", want: map[string]string{"server_tools": "all"}},
+		{name: "server tools invalid name", header: "server_tools=calc.v2", wantErr: "invalid tool name"},
+		{name: "duplicate key", header: "accumulate=json,ACCUMULATE=off", wantErr: "more than once"},
+		{name: "missing value", header: "accumulate=", wantErr: "must have a value"},
+		{name: "missing equals", header: "accumulate", wantErr: "key=value"},
+		{name: "unterminated quote", header: `prompt_compression="whitespace`, wantErr: "unterminated"},
+		{name: "text after quoted value", header: `prompt_compression="whitespace"x`, wantErr: "after quoted value"},
+		{name: "stray quote", header: `prompt_compression=white"space`, wantErr: "quotes"},
+		{name: "invalid key", header: "strict.compat=true", wantErr: "invalid option key"},
+		{name: "invalid value", header: "strict_compat=maybe", wantErr: "strict_compat"},
+		{name: "unknown key lenient", header: "future_flag=1,accumulate=json", want: map[string]string{"accumulate": "json"}},
+		{name: "only unknown keys lenient", header: "future_flag=1", want: nil},
+		{name: "unknown key strict", header: "future_flag=1", strict: true, wantErr: "unknown option"},
+		{name: "too many options", header: manyOptions(MaxRequestOptions + 1), wantErr: "at most"},
+		{name: "too large", header: "prompt_compression=\"" + strings.Repeat(" ", MaxRequestOptionsHeaderBytes) + "\"", wantErr: "bytes"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set(RequestOptionsHeader, tt.header)
+			}
+			got, err := ParseRequestOptions(header, tt.strict)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseRequestOptions() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRequestOptions() error = %v", err)
+			}
+			if !maps.Equal(got.Values(), tt.want) {
+				t.Fatalf("ParseRequestOptions() = %v, want %v", got.Values(), tt.want)
+			}
+		})
+	}
+}
+
+func manyOptions(n int) string {
+	pairs := make([]string, n)
+	for i := range pairs {
+		pairs[i] = fmt.Sprintf("option_%d=1", i)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func TestParseRequestOptions_TypedFields(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set(RequestOptionsHeader, `strict_compat=false, accumulate=json, prompt_compression="dedupe,include_system", first_token_timeout=3s`)
+	opts, err := ParseRequestOptions(header, true)
+	if err != nil {
+		t.Fatalf("ParseRequestOptions() error = %v", err)
+	}
+	if opts.StrictCompat == nil || *opts.StrictCompat || opts.Accumulate != AccumulateJSON || opts.PromptCompression != "dedupe,include_system" ||
+		opts.FirstTokenTimeout == nil || *opts.FirstTokenTimeout != 3*time.Second {
+		t.Fatalf("options = %+v, want every field set", opts)
+	}
+}
+
+func TestParseRequestOptions_StandaloneHeaders(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set(StrictCompatHeader, "true")
+	header.Set(AccumulateHeader, "json")
+	header.Set(RequestOptionsHeader, "strict_compat=false")
+	opts, err := ParseRequestOptions(header, true)
+	if err != nil {
+		t.Fatalf("ParseRequestOptions() error = %v", err)
+	}
+	if opts.StrictCompat == nil || *opts.StrictCompat || opts.Accumulate != AccumulateJSON {
+		t.Fatalf("options = %+v, want strict_compat from the options header and accumulate from its own", opts)
+	}
+
+	header = http.Header{}
+	header.Set(AccumulateHeader, "xml")
+	if _, err := ParseRequestOptions(header, false); err == nil || !strings.Contains(err.Error(), AccumulateHeader) {
+		t.Fatalf("ParseRequestOptions() error = %v, want one naming %s", err, AccumulateHeader)
+	}
+}
+
+func TestRequestOptionsEffective(t *testing.T) {
+	t.Parallel()
+
+	var unset *RequestOptions
+	want := map[string]string{"strict_compat": "default", "accumulate": "off", "prompt_compression": "default", "first_token_timeout": "default", "server_tools": "off"}
+	if got := unset.Effective(); !maps.Equal(got, want) {
+		t.Fatalf("Effective() = %v, want the defaults %v", got, want)
+	}
+
+	header := http.Header{}
+	header.Set(RequestOptionsHeader, "accumulate=json")
+	opts, err := ParseRequestOptions(header, true)
+	if err != nil {
+		t.Fatalf("ParseRequestOptions() error = %v", err)
+	}
+	want["accumulate"] = "json"
+	if got := opts.Effective(); !maps.Equal(got, want) {
+		t.Fatalf("Effective() = %v, want %v", got, want)
+	}
+
+	ctx := WithRequestOptions(context.Background(), opts)
+	if GetRequestOptions(ctx) != opts || GetRequestOptions(context.Background()) != nil {
+		t.Fatal("GetRequestOptions() did not round-trip the options")
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// RequestUserField is the OpenAI chat and responses request field that names
+// the end user for abuse attribution.
+const RequestUserField = "user"
+
+// RequestUserHashLength is the number of hex characters kept from the SHA-256
+// hash of a request user.
+const RequestUserHashLength = 16
+
+// RequestUser identifies the end user a request was made for. Raw is only set
+// when the gateway is configured to record raw user values.
+type RequestUser struct {
+	Hash string
+	Raw  string
+}
+
+// RequestUserValue returns the user field of a chat or responses request. It
+// returns "" when the request has none.
+func RequestUserValue(req any) (string, error) {
+	var raw json.RawMessage
+	switch typed := req.(type) {
+	case *ChatRequest:
+		if typed != nil {
+			raw = typed.ExtraFields.Lookup(RequestUserField)
+		}
+	case *ResponsesRequest:
+		if typed != nil {
+			raw = typed.ExtraFields.Lookup(RequestUserField)
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var user string
+	if err := json.Unmarshal(raw, &user); err != nil {
+		return "", NewInvalidRequestError("user must be a string", err).WithParam(RequestUserField)
+	}
+	return strings.TrimSpace(user), nil
+}
+
+// HashRequestUser returns the first RequestUserHashLength hex characters of
+// the SHA-256 hash of user, or "" for an empty user.
+func HashRequestUser(user string) string {
+	if user == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(user))
+	return hex.EncodeToString(hash[:])[:RequestUserHashLength]
+}
+
+// WithRequestUser returns a new context carrying the request user.
+func WithRequestUser(ctx context.Context, user RequestUser) context.Context {
+	return context.WithValue(ctx, requestUserKey, user)
+}
+
+// GetRequestUser retrieves the request user from context.
+func GetRequestUser(ctx context.Context) RequestUser {
+	if ctx == nil {
+		return RequestUser{}
+	}
+	user, _ := ctx.Value(requestUserKey).(RequestUser)
+	return user
+}
@@ -123,9 +123,8 @@ type ResponsesContentItem struct {
 	Text       string             `json:"text,omitempty"`
 	ImageURL   *ImageURLContent   `json:"image_url,omitempty"`
 	InputAudio *InputAudioContent `json:"input_audio,omitempty"`
-	// Providers can return structured annotation objects here (for example
-	// citations from native tools), so keep the payload shape liberal.
-	Annotations []json.RawMessage `json:"annotations,omitempty" swaggertype:"array,object"`
+	// Annotations are the citations and file references of output_text.
+	Annotations []ResponsesAnnotation `json:"annotations,omitempty"`
 }
 
 // ResponsesUsage represents token usage for the Responses API.
@@ -492,12 +492,8 @@ func TestResponsesResponseJSON_AcceptsStructuredAnnotations(t *testing.T) {
 		t.Fatalf("len(Annotations) = %d, want 1", len(annotations))
 	}
 
-	var annotation map[string]any
-	if err := json.Unmarshal(annotations[0], &annotation); err != nil {
-		t.Fatalf("json.Unmarshal(annotation) error = %v", err)
-	}
-	if annotation["type"] != "url_citation" {
-		t.Fatalf("annotation.type = %#v, want url_citation", annotation["type"])
+	if annotations[0].Type != AnnotationTypeURLCitation || annotations[0].URL != "https://example.com" {
+		t.Fatalf("annotation = %+v, want url_citation for https://example.com", annotations[0])
 	}
 
 	body, err := json.Marshal(resp)
@@ -0,0 +1,19 @@
+package core
+
+// SecretDetectedCode is the error code of requests blocked by secret
+// redaction.
+const SecretDetectedCode = "secret_detected"
+
+// SecretDetection counts the matches of one secret detector in a request.
+// It never holds the matched text.
+type SecretDetection struct {
+	Detector string `json:"detector" bson:"detector"`
+	Action   string `json:"action" bson:"action"`
+	Count    int    `json:"count" bson:"count"`
+}
+
+// SecretRedactionResult describes the secrets found in a request.
+type SecretRedactionResult struct {
+	Detections []SecretDetection // sorted by detector
+	Blocked    bool              // a detector with the block action matched
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ServerToolsHeader opts a request into the server-side tools granted
+	// to it: "all", "off" or a comma-separated list of tool names.
+	ServerToolsHeader = "X-GoModel-Server-Tools"
+	// ToolIterationsHeader reports how many model calls the server-side tool
+	// loop of a request made.
+	ToolIterationsHeader = "X-GoModel-Tool-Iterations"
+)
+
+// ServerToolSelection is the server-side tools a request opted into.
+type ServerToolSelection struct {
+	// All selects every tool granted to the request.
+	All bool
+	// Names lists the selected tools when All is false.
+	Names []string
+}
+
+// Selects reports whether the selection includes the named tool.
+func (s *ServerToolSelection) Selects(name string) bool {
+	return s != nil && (s.All || slices.Contains(s.Names, name))
+}
+
+// ParseServerToolSelection parses a ServerToolsHeader value. "off" returns
+// nil. Tool names follow the function name rules of the OpenAI API.
+func ParseServerToolSelection(value string) (*ServerToolSelection, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "off":
+		return nil, nil
+	case "all":
+		return &ServerToolSelection{All: true}, nil
+	}
+	selection := &ServerToolSelection{}
+	for name := range strings.SplitSeq(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !validToolName(name) {
+			return nil, fmt.Errorf("invalid tool name %q: use letters, digits, _ and -, at most 64 characters", name)
+		}
+		if !slices.Contains(selection.Names, name) {
+			selection.Names = append(selection.Names, name)
+		}
+	}
+	if len(selection.Names) == 0 {
+		return nil, fmt.Errorf("expected all, off or a comma-separated list of tool names")
+	}
+	return selection, nil
+}
+
+// String returns the canonical header form of the selection.
+func (s *ServerToolSelection) String() string {
+	switch {
+	case s == nil:
+		return "off"
+	case s.All:
+		return "all"
+	default:
+		return strings.Join(s.Names, ",")
+	}
+}
+
+func validToolName(name string) bool {
+	if len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolLoopResult describes the server-side tool loop that produced the
+// response of a request: every model call and the tools each one ran.
+type ToolLoopResult struct {
+	// Iterations counts the model calls, including the final one.
+	Iterations int
+	Turns      []ToolLoopTurn
+}
+
+// ToolLoopTurn is one model call of a tool loop.
+type ToolLoopTurn struct {
+	ResponseID   string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	// Calls lists the server-side tools the gateway ran for the call's
+	// tool calls. It is empty for the final call.
+	Calls []ToolLoopCall
+}
+
+// ToolLoopCall is one server-side tool run of a tool loop.
+type ToolLoopCall struct {
+	CallID      string
+	Name        string
+	Duration    time.Duration
+	OutputBytes int
+	Truncated   bool
+	Error       string
+}
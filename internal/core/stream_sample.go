@@ -0,0 +1,6 @@
+package core
+
+// StreamSampleHeader carries a client's consent for stream sampling. "true"
+// requests a full-text sample of the streamed response when opt-in is
+// enabled; "false" excludes the request from sampling.
+const StreamSampleHeader = "X-GoModel-Stream-Sample"
@@ -1,6 +1,9 @@
 package core
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"slices"
+)
 
 // StreamOptions controls streaming behavior options.
 // This is used to request usage data in streaming responses.
@@ -234,6 +237,12 @@ func AllCategories() []ModelCategory {
 	}
 }
 
+// IsBuiltinCategory reports whether cat is one of the categories returned by
+// AllCategories.
+func IsBuiltinCategory(cat ModelCategory) bool {
+	return slices.Contains(AllCategories(), cat)
+}
+
 // ModelPricing holds pricing information for cost calculation.
 type ModelPricing struct {
 	Currency               string             `json:"currency"`
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// UpstreamCall records the HTTP outcome of the provider call made for a
+// request. When a request reaches a provider more than once (retries,
+// fallbacks), the most recent call wins. Pacing delays add up across calls.
+type UpstreamCall struct {
+	mu          sync.Mutex
+	statusCode  int
+	duration    time.Duration
+	pacingDelay time.Duration
+}
+
+// Record stores the upstream status code and duration. A zero status code
+// means no upstream response was received and is ignored.
+func (u *UpstreamCall) Record(statusCode int, duration time.Duration) {
+	if u == nil || statusCode == 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.statusCode = statusCode
+	u.duration = duration
+}
+
+// Result returns the recorded status code and duration; the status code is
+// zero when no provider answered.
+func (u *UpstreamCall) Result() (int, time.Duration) {
+	if u == nil {
+		return 0, 0
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.statusCode, u.duration
+}
+
+// AddPacingDelay adds time a provider call spent queued by request pacing.
+func (u *UpstreamCall) AddPacingDelay(delay time.Duration) {
+	if u == nil || delay <= 0 {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pacingDelay += delay
+}
+
+// PacingDelay returns the total time provider calls spent queued by request
+// pacing.
+func (u *UpstreamCall) PacingDelay() time.Duration {
+	if u == nil {
+		return 0
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.pacingDelay
+}
+
+// RecordUpstreamCall reports a provider HTTP outcome to the recorder in ctx,
+// if any.
+func RecordUpstreamCall(ctx context.Context, statusCode int, duration time.Duration) {
+	GetUpstreamCall(ctx).Record(statusCode, duration)
+}
+
+// RecordPacingDelay reports time spent queued by request pacing to the
+// recorder in ctx, if any.
+func RecordPacingDelay(ctx context.Context, delay time.Duration) {
+	GetUpstreamCall(ctx).AddPacingDelay(delay)
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordUpstreamCall_LastAttemptWins(t *testing.T) {
+	call := &UpstreamCall{}
+	ctx := WithUpstreamCall(context.Background(), call)
+
+	RecordUpstreamCall(ctx, 503, time.Second)
+	RecordUpstreamCall(ctx, 0, time.Minute)
+	RecordUpstreamCall(ctx, 200, 2*time.Second)
+
+	statusCode, duration := call.Result()
+	if statusCode != 200 || duration != 2*time.Second {
+		t.Fatalf("Result() = %d, %v, want 200, 2s", statusCode, duration)
+	}
+}
+
+func TestRecordUpstreamCall_WithoutRecorder(t *testing.T) {
+	RecordUpstreamCall(context.Background(), 200, time.Second)
+
+	var call *UpstreamCall
+	if statusCode, duration := call.Result(); statusCode != 0 || duration != 0 {
+		t.Fatalf("nil Result() = %d, %v, want zero values", statusCode, duration)
+	}
+}
+
+func TestRecordPacingDelay_Accumulates(t *testing.T) {
+	call := &UpstreamCall{}
+	ctx := WithUpstreamCall(context.Background(), call)
+
+	RecordPacingDelay(ctx, 300*time.Millisecond)
+	RecordPacingDelay(ctx, 0)
+	RecordPacingDelay(ctx, 200*time.Millisecond)
+
+	if delay := call.PacingDelay(); delay != 500*time.Millisecond {
+		t.Fatalf("PacingDelay() = %v, want 500ms", delay)
+	}
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// deniedUpstreamHeaders can never be passed through to clients, whatever the
+// allow-list says: credentials, cookies and headers that describe the
+// framing of the provider's response rather than the gateway's.
+var deniedUpstreamHeaders = map[string]struct{}{
+	"Authorization":             {},
+	"Proxy-Authorization":       {},
+	"Www-Authenticate":          {},
+	"Proxy-Authenticate":        {},
+	"Cookie":                    {},
+	"Set-Cookie":                {},
+	"Set-Cookie2":               {},
+	"Api-Key":                   {},
+	"X-Api-Key":                 {},
+	"X-Goog-Api-Key":            {},
+	"Connection":                {},
+	"Keep-Alive":                {},
+	"Upgrade":                   {},
+	"Te":                        {},
+	"Trailer":                   {},
+	"Transfer-Encoding":         {},
+	"Content-Length":            {},
+	"Content-Encoding":          {},
+	"Content-Type":              {},
+	"Host":                      {},
+	"Location":                  {},
+	"Strict-Transport-Security": {},
+	"Alt-Svc":                   {},
+}
+
+// IsDeniedUpstreamHeader reports whether name is on the hard deny-list of
+// upstream response headers that are never passed through.
+func IsDeniedUpstreamHeader(name string) bool {
+	_, denied := deniedUpstreamHeaders[http.CanonicalHeaderKey(name)]
+	return denied
+}
+
+// MatchUpstreamHeader reports whether name matches one of patterns. A pattern
+// is a header name, or a prefix ending in "*" such as "anthropic-ratelimit-*".
+// Matching is case-insensitive.
+func MatchUpstreamHeader(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpstreamHeaderPolicy selects the provider response headers passed through to
+// clients. Allow applies to every provider; providers may allow more names of
+// their own. Prefix, when set, is prepended to each passed header name.
+type UpstreamHeaderPolicy struct {
+	Allow  []string
+	Prefix string
+}
+
+// UpstreamResponseHeaders collects the provider response headers a request may
+// pass through to its client. When a request reaches a provider more than
+// once (retries, fallbacks), the most recent response wins.
+type UpstreamResponseHeaders struct {
+	policy UpstreamHeaderPolicy
+	mu     sync.Mutex
+	header http.Header
+}
+
+// NewUpstreamResponseHeaders returns an empty collector for policy.
+func NewUpstreamResponseHeaders(policy UpstreamHeaderPolicy) *UpstreamResponseHeaders {
+	return &UpstreamResponseHeaders{policy: policy}
+}
+
+// Record replaces the collected headers with the headers of a provider
+// response that match the policy or providerAllow. Denied headers are dropped
+// and the remaining names are canonicalized and prefixed.
+func (u *UpstreamResponseHeaders) Record(header http.Header, providerAllow []string) {
+	if u == nil {
+		return
+	}
+	selected := make(http.Header)
+	for name, values := range header {
+		if len(values) == 0 || IsDeniedUpstreamHeader(name) {
+			continue
+		}
+		if !MatchUpstreamHeader(u.policy.Allow, name) && !MatchUpstreamHeader(providerAllow, name) {
+			continue
+		}
+		selected[http.CanonicalHeaderKey(u.policy.Prefix+name)] = append([]string(nil), values...)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.header = selected
+}
+
+// Header returns a copy of the collected headers, ready to be set on the
+// client response. It is nil until a provider response is recorded.
+func (u *UpstreamResponseHeaders) Header() http.Header {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.header.Clone()
+}
+
+// RecordUpstreamResponseHeaders reports a provider response's headers to the
+// collector in ctx, if any.
+func RecordUpstreamResponseHeaders(ctx context.Context, header http.Header, providerAllow []string) {
+	GetUpstreamResponseHeaders(ctx).Record(header, providerAllow)
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMatchUpstreamHeader(t *testing.T) {
+	patterns := []string{"openai-processing-ms", "anthropic-ratelimit-*"}
+	for name, want := range map[string]bool{
+		"Openai-Processing-Ms":            true,
+		"Anthropic-Ratelimit-Tokens-Left": true,
+		"Anthropic-Ratelimit":             false,
+		"Openai-Version":                  false,
+	} {
+		if got := MatchUpstreamHeader(patterns, name); got != want {
+			t.Errorf("MatchUpstreamHeader(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestUpstreamResponseHeaders_RecordKeepsLastResponse(t *testing.T) {
+	headers := NewUpstreamResponseHeaders(UpstreamHeaderPolicy{Allow: []string{"x-*"}, Prefix: "X-Upstream-"})
+	headers.Record(http.Header{"X-First": {"1"}}, nil)
+	headers.Record(http.Header{"X-Second": {"2"}, "Set-Cookie": {"s"}}, []string{"set-cookie"})
+
+	got := headers.Header()
+	if len(got) != 1 || got.Get("X-Upstream-X-Second") != "2" {
+		t.Fatalf("Header() = %v, want only the last response's allowed headers", got)
+	}
+}
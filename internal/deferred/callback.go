@@ -0,0 +1,92 @@
+package deferred
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrCallbacksDisabled is returned by CallbackURL when callbacks are not
+// enabled in the deferred config.
+var ErrCallbacksDisabled = errors.New("deferred callbacks are not enabled")
+
+// CallbackURL validates a client-supplied callback URL: callbacks must be
+// enabled, and the URL must be absolute http(s) with an allowed host. It
+// returns the URL in normalized form; an empty raw value returns "".
+func (s *Service) CallbackURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if !s.cfg.Callbacks.Enabled {
+		return "", ErrCallbacksDisabled
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("callback URL must be an absolute http or https URL")
+	}
+	if parsed.User != nil {
+		return "", fmt.Errorf("callback URL must not carry credentials")
+	}
+	if !s.cfg.Callbacks.AllowsHost(parsed.Hostname()) {
+		return "", fmt.Errorf("callback host %q is not allowed", parsed.Hostname())
+	}
+	return parsed.String(), nil
+}
+
+// callbackAllowed re-checks a stored callback URL at delivery time, so
+// requests queued before the allow-list changed are held to the current one.
+func (s *Service) callbackAllowed(raw string) bool {
+	_, err := s.CallbackURL(raw)
+	return err == nil
+}
+
+// newCallbackClient returns the client that delivers callbacks. It does not
+// use a proxy or follow redirects, and its dialer refuses non-public
+// addresses after DNS resolution, so an allowed host that resolves to an
+// internal address is still refused.
+func newCallbackClient() *http.Client {
+	dialer := &net.Dialer{Timeout: callbackTimeout, Control: refuseNonPublicAddress}
+	return &http.Client{
+		Timeout: callbackTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: callbackTimeout,
+			MaxIdleConns:        10,
+			ForceAttemptHTTP2:   true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refuseNonPublicAddress is a net.Dialer Control hook; address is the
+// resolved IP and port about to be dialed.
+func refuseNonPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ip) {
+		return fmt.Errorf("callback address %s is not public", ip)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598).
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
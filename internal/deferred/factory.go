@@ -0,0 +1,115 @@
+package deferred
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"gomodel/config"
+	"gomodel/internal/storage"
+)
+
+// Result holds the initialized deferred request service and owned resources.
+type Result struct {
+	Service *Service
+	Store   Store
+	Storage storage.Storage
+
+	stopWorker func()
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+// StartWorker starts replaying due requests through executor. The worker
+// stops when the result is closed.
+func (r *Result) StartWorker(executor Executor) {
+	if r == nil || r.Service == nil || executor == nil || r.stopWorker != nil {
+		return
+	}
+	r.stopWorker = r.Service.Start(executor)
+}
+
+// Close stops the worker and releases resources held by the deferred store.
+func (r *Result) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.closeOnce.Do(func() {
+		if r.stopWorker != nil {
+			r.stopWorker()
+			r.stopWorker = nil
+		}
+
+		var errs []error
+		if r.Store != nil {
+			if err := r.Store.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("store close: %w", err))
+			}
+		}
+		if r.Storage != nil {
+			if err := r.Storage.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("storage close: %w", err))
+			}
+		}
+		if len(errs) > 0 {
+			r.closeErr = fmt.Errorf("close errors: %w", errors.Join(errs...))
+		}
+	})
+	return r.closeErr
+}
+
+// New creates a deferred request service with its own storage connection.
+func New(ctx context.Context, cfg *config.Config) (*Result, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	storeConn, err := storage.New(ctx, cfg.Storage.BackendConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	result, err := newResult(ctx, storeConn, cfg.Deferred)
+	if err != nil {
+		_ = storeConn.Close()
+		return nil, err
+	}
+	result.Storage = storeConn
+	return result, nil
+}
+
+// NewWithSharedStorage creates a deferred request service using an existing
+// storage connection.
+func NewWithSharedStorage(ctx context.Context, shared storage.Storage, cfg config.DeferredConfig) (*Result, error) {
+	if shared == nil {
+		return nil, fmt.Errorf("shared storage is required")
+	}
+	return newResult(ctx, shared, cfg)
+}
+
+func newResult(ctx context.Context, storeConn storage.Storage, cfg config.DeferredConfig) (*Result, error) {
+	store, err := createStore(ctx, storeConn)
+	if err != nil {
+		return nil, err
+	}
+	service, err := NewService(store, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Service: service,
+		Store:   store,
+	}, nil
+}
+
+func createStore(ctx context.Context, store storage.Storage) (Store, error) {
+	return storage.ResolveBackend[Store](
+		store,
+		func(db *sql.DB) (Store, error) { return NewSQLiteStore(db) },
+		func(pool *pgxpool.Pool) (Store, error) { return NewPostgreSQLStore(ctx, pool) },
+		func(db *mongo.Database) (Store, error) { return NewMongoDBStore(db) },
+	)
+}
@@ -0,0 +1,326 @@
+package deferred
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/observability"
+)
+
+const (
+	// dueBatchSize bounds how many due requests one poll replays.
+	dueBatchSize = 50
+	// leaseDuration is how long a claimed request stays reserved for the
+	// worker replaying it. A worker that dies mid-replay releases it when the
+	// lease lapses.
+	leaseDuration = 15 * time.Minute
+	// callbackTimeout bounds one callback delivery.
+	callbackTimeout = 10 * time.Second
+)
+
+// Executor replays one deferred request through the gateway.
+type Executor interface {
+	Execute(ctx context.Context, req *Request) (*Replay, error)
+}
+
+// Replay is the HTTP outcome of one replay.
+type Replay struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Outcome classifies a gateway response for deferred execution.
+type Outcome int
+
+const (
+	// OutcomeSucceeded is a 2xx response.
+	OutcomeSucceeded Outcome = iota
+	// OutcomeRetry is a retriable provider error: 429 or 5xx.
+	OutcomeRetry
+	// OutcomeCircuitOpen is a provider whose circuit breaker is still open.
+	// It is retried without consuming an attempt.
+	OutcomeCircuitOpen
+	// OutcomeFailed is any other error; replaying it would fail again.
+	OutcomeFailed
+)
+
+// ClassifyResponse classifies a gateway response by its status code and
+// OpenAI-compatible error body.
+func ClassifyResponse(statusCode int, body []byte) Outcome {
+	if statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices {
+		return OutcomeSucceeded
+	}
+	if statusCode != http.StatusTooManyRequests && statusCode < http.StatusInternalServerError {
+		return OutcomeFailed
+	}
+	errObj := gjson.GetBytes(body, "error")
+	switch core.ErrorType(errObj.Get("type").String()) {
+	case core.ErrorTypeProvider, core.ErrorTypeRateLimit, core.ErrorTypeOverloaded:
+	default:
+		return OutcomeFailed
+	}
+	if errObj.Get("code").String() == "circuit_open" {
+		return OutcomeCircuitOpen
+	}
+	return OutcomeRetry
+}
+
+// Service persists deferred requests and replays them in the background.
+type Service struct {
+	store  Store
+	cfg    config.DeferredConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewService creates a deferred request service.
+func NewService(store Store, cfg config.DeferredConfig) (*Service, error) {
+	if store == nil {
+		return nil, fmt.Errorf("deferred store is required")
+	}
+	if err := config.ValidateDeferredConfig(&cfg); err != nil {
+		return nil, err
+	}
+	return &Service{
+		store:  store,
+		cfg:    cfg,
+		client: newCallbackClient(),
+		now:    time.Now,
+	}, nil
+}
+
+// Enqueue persists req for replay after its original attempt failed with
+// failure. It assigns the id, status and schedule.
+func (s *Service) Enqueue(ctx context.Context, req *Request, failure string) (*Request, error) {
+	now := s.now().UTC()
+	req.ID = "deferred_" + uuid.NewString()
+	req.Status = StatusQueued
+	req.MaxAttempts = s.cfg.MaxAttempts
+	req.LastError = failure
+	req.CreatedAt = now
+	req.UpdatedAt = now
+	req.ExpiresAt = now.Add(s.cfg.TTL)
+	req.NextAttemptAt = now.Add(s.backoff(0))
+	if err := s.store.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	observability.DeferredQueueDepth.WithLabelValues(string(StatusQueued)).Inc()
+	return req, nil
+}
+
+// Get returns a deferred request by id.
+func (s *Service) Get(ctx context.Context, id string) (*Request, error) {
+	return s.store.Get(ctx, id)
+}
+
+// Depth returns the number of stored requests per status and refreshes the
+// queue depth metric.
+func (s *Service) Depth(ctx context.Context) (map[Status]int, error) {
+	counts, err := s.store.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range Statuses {
+		observability.DeferredQueueDepth.WithLabelValues(string(status)).Set(float64(counts[status]))
+	}
+	return counts, nil
+}
+
+// Start runs the replay worker until the returned stop function is called.
+func (s *Service) Start(executor Executor) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(s.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.poll(ctx, executor)
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// poll replays due requests, deletes requests past their pickup window, and
+// refreshes the queue depth metric.
+func (s *Service) poll(ctx context.Context, executor Executor) {
+	due, err := s.store.ListDue(ctx, s.now(), dueBatchSize)
+	if err != nil {
+		slog.Warn("deferred: list due requests failed", "error", err)
+	}
+	for _, req := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.process(ctx, executor, req)
+	}
+	if _, err := s.store.DeleteExpired(ctx, s.now()); err != nil {
+		slog.Warn("deferred: delete expired requests failed", "error", err)
+	}
+	if _, err := s.Depth(ctx); err != nil {
+		slog.Warn("deferred: count requests failed", "error", err)
+	}
+}
+
+func (s *Service) process(ctx context.Context, executor Executor, req *Request) {
+	now := s.now().UTC()
+	claimed, err := s.store.Claim(ctx, req.ID, now, now.Add(leaseDuration))
+	if err != nil {
+		slog.Warn("deferred: claim failed", "id", req.ID, "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if !now.Before(req.ExpiresAt) {
+		s.finish(ctx, req, StatusExpired, now)
+		return
+	}
+
+	result, err := executor.Execute(ctx, req)
+	now = s.now().UTC()
+	if ctx.Err() != nil {
+		// Shutting down: hand the request back without consuming an attempt.
+		req.Status = StatusQueued
+		req.NextAttemptAt = now
+		s.update(context.WithoutCancel(ctx), req)
+		return
+	}
+
+	outcome := OutcomeRetry
+	if err != nil {
+		req.LastError = err.Error()
+	} else {
+		outcome = ClassifyResponse(result.StatusCode, result.Body)
+		req.StatusCode = result.StatusCode
+		req.Response = result.Body
+		if outcome != OutcomeSucceeded {
+			req.LastError = errorMessage(result)
+		}
+	}
+
+	switch outcome {
+	case OutcomeSucceeded:
+		req.LastError = ""
+		req.Attempts++
+		s.finish(ctx, req, StatusSucceeded, now)
+		return
+	case OutcomeFailed:
+		req.Attempts++
+		s.finish(ctx, req, StatusFailed, now)
+		return
+	case OutcomeRetry:
+		req.Attempts++
+		if req.Attempts >= req.MaxAttempts {
+			s.finish(ctx, req, StatusFailed, now)
+			return
+		}
+	}
+
+	// Retriable: keep the response out of the record until it is final.
+	req.StatusCode = 0
+	req.Response = nil
+	req.Status = StatusQueued
+	req.NextAttemptAt = now.Add(s.backoff(req.Attempts))
+	if req.NextAttemptAt.After(req.ExpiresAt) {
+		req.NextAttemptAt = req.ExpiresAt
+	}
+	s.update(ctx, req)
+}
+
+// finish records a final status, opens the result pickup window, and
+// delivers the callback when one was requested.
+func (s *Service) finish(ctx context.Context, req *Request, status Status, now time.Time) {
+	req.Status = status
+	req.CompletedAt = &now
+	req.NextAttemptAt = now
+	req.ExpiresAt = now.Add(s.cfg.TTL)
+	if !s.update(ctx, req) {
+		return
+	}
+	if req.CallbackURL != "" {
+		s.deliverCallback(ctx, req)
+	}
+}
+
+func (s *Service) update(ctx context.Context, req *Request) bool {
+	req.UpdatedAt = s.now().UTC()
+	if err := s.store.Update(ctx, req); err != nil {
+		slog.Warn("deferred: update failed", "id", req.ID, "status", req.Status, "error", err)
+		return false
+	}
+	return true
+}
+
+func (s *Service) deliverCallback(ctx context.Context, req *Request) {
+	if !s.callbackAllowed(req.CallbackURL) {
+		slog.Warn("deferred: callback URL no longer allowed; skipping delivery", "id", req.ID)
+		return
+	}
+	payload, err := json.Marshal(req.Public())
+	if err != nil {
+		slog.Warn("deferred: encode callback failed", "id", req.ID, "error", err)
+		return
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.CallbackURL, bytes.NewReader(payload))
+	if err != nil {
+		slog.Warn("deferred: build callback failed", "id", req.ID, "error", err)
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(core.DeferredIDHeader, req.ID)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		slog.Warn("deferred: callback failed", "id", req.ID, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		slog.Warn("deferred: callback rejected", "id", req.ID, "status", resp.StatusCode)
+	}
+}
+
+// backoff returns the delay before the next replay after failed attempts.
+func (s *Service) backoff(failed int) time.Duration {
+	delay := s.cfg.InitialBackoff
+	for range failed {
+		delay *= 2
+		if delay >= s.cfg.MaxBackoff {
+			return s.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+func errorMessage(result *Replay) string {
+	if message := gjson.GetBytes(result.Body, "error.message").String(); message != "" {
+		return message
+	}
+	return fmt.Sprintf("gateway returned status %d", result.StatusCode)
+}
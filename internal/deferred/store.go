@@ -0,0 +1,160 @@
+// Package deferred queues requests that failed with retriable provider errors
+// and replays them in the background once the provider recovers.
+package deferred
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// ErrNotFound indicates a requested deferred request was not found.
+var ErrNotFound = errors.New("deferred request not found")
+
+// Status is the lifecycle state of a deferred request.
+type Status string
+
+const (
+	// StatusQueued waits for its next replay.
+	StatusQueued Status = "queued"
+	// StatusRunning is being replayed by a worker that holds its lease.
+	StatusRunning Status = "running"
+	// StatusSucceeded holds the successful response.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed ran out of attempts or hit a non-retriable error.
+	StatusFailed Status = "failed"
+	// StatusExpired outlived its TTL before succeeding.
+	StatusExpired Status = "expired"
+)
+
+// Statuses lists every status in lifecycle order.
+var Statuses = []Status{StatusQueued, StatusRunning, StatusSucceeded, StatusFailed, StatusExpired}
+
+// Final reports whether no further replay happens in status s.
+func (s Status) Final() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusExpired
+}
+
+// Request is one persisted deferred request together with its outcome.
+type Request struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Headers keeps the request headers needed for replay. Credentials are
+	// never stored; the replay runs as AuthKeyID instead.
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body"`
+	RequestID   string            `json:"request_id,omitempty"`
+	AuthKeyID   string            `json:"auth_key_id,omitempty"`
+	UserPath    string            `json:"user_path,omitempty"`
+	CallbackURL string            `json:"callback_url,omitempty"`
+
+	Attempts      int       `json:"attempts"`
+	MaxAttempts   int       `json:"max_attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Response      []byte    `json:"response,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// ExpiresAt is the retry deadline while the request is pending and the
+	// end of the result pickup window once it is final.
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Public returns the client-facing view of r.
+func (r *Request) Public() *core.DeferredRequestResponse {
+	resp := &core.DeferredRequestResponse{
+		ID:          r.ID,
+		Object:      "deferred_request",
+		Status:      string(r.Status),
+		Endpoint:    r.Path,
+		Attempts:    r.Attempts,
+		MaxAttempts: r.MaxAttempts,
+		CreatedAt:   r.CreatedAt.Unix(),
+		ExpiresAt:   r.ExpiresAt.Unix(),
+		LastError:   r.LastError,
+		StatusCode:  r.StatusCode,
+	}
+	if r.Status == StatusQueued {
+		next := r.NextAttemptAt.Unix()
+		resp.NextAttemptAt = &next
+	}
+	if r.CompletedAt != nil {
+		completed := r.CompletedAt.Unix()
+		resp.CompletedAt = &completed
+	}
+	if json.Valid(r.Response) {
+		resp.Response = json.RawMessage(r.Response)
+	}
+	return resp
+}
+
+// Store defines persistence operations for deferred requests.
+type Store interface {
+	Create(ctx context.Context, req *Request) error
+	Get(ctx context.Context, id string) (*Request, error)
+	Update(ctx context.Context, req *Request) error
+	// ListDue returns up to limit queued requests, and running requests whose
+	// lease lapsed, with NextAttemptAt at or before now, oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*Request, error)
+	// Claim marks a due request as running until leaseUntil. It returns false
+	// when another worker claimed it first or it is no longer due.
+	Claim(ctx context.Context, id string, now, leaseUntil time.Time) (bool, error)
+	// CountByStatus returns the number of stored requests per status.
+	CountByStatus(ctx context.Context) (map[Status]int, error)
+	// DeleteExpired removes final requests whose pickup window closed before now.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+	Close() error
+}
+
+func serializeRequest(req *Request) ([]byte, error) {
+	if req == nil {
+		return nil, fmt.Errorf("deferred request is nil")
+	}
+	if req.ID == "" {
+		return nil, fmt.Errorf("deferred request ID is empty")
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal deferred request: %w", err)
+	}
+	return b, nil
+}
+
+// deserializeRequest decodes a stored payload. The status and next attempt
+// columns win over the payload because Claim only updates the columns.
+func deserializeRequest(raw []byte, status string, nextAttemptAt int64) (*Request, error) {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("unmarshal deferred request: %w", err)
+	}
+	if status != "" {
+		req.Status = Status(status)
+	}
+	if nextAttemptAt != 0 {
+		req.NextAttemptAt = time.UnixMilli(nextAttemptAt).UTC()
+	}
+	return &req, nil
+}
+
+func cloneRequest(src *Request) (*Request, error) {
+	b, err := serializeRequest(src)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeRequest(b, "", 0)
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	return limit
+}
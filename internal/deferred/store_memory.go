@@ -0,0 +1,142 @@
+package deferred
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps deferred requests in process memory.
+// Data survives across requests but not process restarts.
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]*Request
+}
+
+// NewMemoryStore creates an empty in-memory deferred request store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*Request),
+	}
+}
+
+// Create stores a new deferred request.
+func (s *MemoryStore) Create(_ context.Context, req *Request) error {
+	c, err := cloneRequest(req)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.items[c.ID]; exists {
+		return fmt.Errorf("deferred request already exists: %s", c.ID)
+	}
+	s.items[c.ID] = c
+	return nil
+}
+
+// Get retrieves one deferred request by id.
+func (s *MemoryStore) Get(_ context.Context, id string) (*Request, error) {
+	s.mu.Lock()
+	req, ok := s.items[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneRequest(req)
+}
+
+// Update replaces an existing deferred request.
+func (s *MemoryStore) Update(_ context.Context, req *Request) error {
+	c, err := cloneRequest(req)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.items[c.ID]; !exists {
+		return ErrNotFound
+	}
+	s.items[c.ID] = c
+	return nil
+}
+
+// ListDue returns due requests ordered by next attempt time.
+func (s *MemoryStore) ListDue(_ context.Context, now time.Time, limit int) ([]*Request, error) {
+	limit = normalizeLimit(limit)
+
+	s.mu.Lock()
+	due := make([]*Request, 0)
+	for _, req := range s.items {
+		if (req.Status == StatusQueued || req.Status == StatusRunning) && !req.NextAttemptAt.After(now) {
+			c, err := cloneRequest(req)
+			if err != nil {
+				s.mu.Unlock()
+				return nil, err
+			}
+			due = append(due, c)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Claim marks a due request as running until leaseUntil.
+func (s *MemoryStore) Claim(_ context.Context, id string, now, leaseUntil time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, ok := s.items[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if (req.Status != StatusQueued && req.Status != StatusRunning) || req.NextAttemptAt.After(now) {
+		return false, nil
+	}
+	req.Status = StatusRunning
+	req.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// CountByStatus returns the number of stored requests per status.
+func (s *MemoryStore) CountByStatus(_ context.Context) (map[Status]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[Status]int, len(Statuses))
+	for _, req := range s.items {
+		counts[req.Status]++
+	}
+	return counts, nil
+}
+
+// DeleteExpired removes final requests whose ExpiresAt is before now.
+func (s *MemoryStore) DeleteExpired(_ context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for id, req := range s.items {
+		if req.Status.Final() && req.ExpiresAt.Before(now) {
+			delete(s.items, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close releases resources (no-op for memory store).
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,209 @@
+package deferred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoDeferredDocument struct {
+	ID            string `bson:"_id"`
+	CreatedAt     int64  `bson:"created_at"`
+	UpdatedAt     int64  `bson:"updated_at"`
+	Status        string `bson:"status"`
+	NextAttemptAt int64  `bson:"next_attempt_at"`
+	ExpiresAt     int64  `bson:"expires_at"`
+	Data          []byte `bson:"data"`
+}
+
+// MongoDBStore stores deferred requests in MongoDB.
+type MongoDBStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBStore creates collection indexes if needed.
+func NewMongoDBStore(database *mongo.Database) (*MongoDBStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+
+	coll := database.Collection("deferred_requests")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("create deferred_requests indexes: %w", err)
+	}
+
+	return &MongoDBStore{collection: coll}, nil
+}
+
+// Create inserts a new deferred request.
+func (s *MongoDBStore) Create(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	doc := mongoDeferredDocument{
+		ID:            req.ID,
+		CreatedAt:     req.CreatedAt.UnixMilli(),
+		UpdatedAt:     time.Now().UnixMilli(),
+		Status:        string(req.Status),
+		NextAttemptAt: req.NextAttemptAt.UnixMilli(),
+		ExpiresAt:     req.ExpiresAt.UnixMilli(),
+		Data:          payload,
+	}
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("insert deferred request: %w", err)
+	}
+	return nil
+}
+
+// Get returns a deferred request by id.
+func (s *MongoDBStore) Get(ctx context.Context, id string) (*Request, error) {
+	var doc mongoDeferredDocument
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query deferred request: %w", err)
+	}
+
+	req, err := deserializeRequest(doc.Data, doc.Status, doc.NextAttemptAt)
+	if err != nil {
+		return nil, fmt.Errorf("decode deferred request: %w", err)
+	}
+	return req, nil
+}
+
+// Update replaces a stored deferred request.
+func (s *MongoDBStore) Update(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": req.ID},
+		bson.M{"$set": bson.M{
+			"updated_at":      time.Now().UnixMilli(),
+			"status":          string(req.Status),
+			"next_attempt_at": req.NextAttemptAt.UnixMilli(),
+			"expires_at":      req.ExpiresAt.UnixMilli(),
+			"data":            payload,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("update deferred request: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func dueFilter(now time.Time) bson.M {
+	return bson.M{
+		"status":          bson.M{"$in": bson.A{string(StatusQueued), string(StatusRunning)}},
+		"next_attempt_at": bson.M{"$lte": now.UnixMilli()},
+	}
+}
+
+// ListDue returns due requests ordered by next attempt time.
+func (s *MongoDBStore) ListDue(ctx context.Context, now time.Time, limit int) ([]*Request, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(normalizeLimit(limit)))
+	cursor, err := s.collection.Find(ctx, dueFilter(now), opts)
+	if err != nil {
+		return nil, fmt.Errorf("list due deferred requests: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var items []*Request
+	for cursor.Next(ctx) {
+		var doc mongoDeferredDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode deferred request document: %w", err)
+		}
+		req, err := deserializeRequest(doc.Data, doc.Status, doc.NextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("decode deferred request payload: %w", err)
+		}
+		items = append(items, req)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred requests cursor: %w", err)
+	}
+	return items, nil
+}
+
+// Claim marks a due request as running until leaseUntil.
+func (s *MongoDBStore) Claim(ctx context.Context, id string, now, leaseUntil time.Time) (bool, error) {
+	filter := dueFilter(now)
+	filter["_id"] = id
+	result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"status":          string(StatusRunning),
+		"next_attempt_at": leaseUntil.UnixMilli(),
+		"updated_at":      now.UnixMilli(),
+	}})
+	if err != nil {
+		return false, fmt.Errorf("claim deferred request: %w", err)
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// CountByStatus returns the number of stored requests per status.
+func (s *MongoDBStore) CountByStatus(ctx context.Context) (map[Status]int, error) {
+	cursor, err := s.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$status"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("count deferred requests: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[Status]int, len(Statuses))
+	for cursor.Next(ctx) {
+		var row struct {
+			Status string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("decode deferred request count: %w", err)
+		}
+		counts[Status(row.Status)] = row.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred request counts: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteExpired removes final requests whose pickup window closed before now.
+func (s *MongoDBStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{
+		"status":     bson.M{"$in": bson.A{string(StatusSucceeded), string(StatusFailed), string(StatusExpired)}},
+		"expires_at": bson.M{"$lt": now.UnixMilli()},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete expired deferred requests: %w", err)
+	}
+	return int(result.DeletedCount), nil
+}
+
+// Close is a no-op; Mongo client lifecycle is managed by storage layer.
+func (s *MongoDBStore) Close() error {
+	return nil
+}
@@ -0,0 +1,208 @@
+package deferred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLStore stores deferred requests in PostgreSQL.
+type PostgreSQLStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLStore creates the deferred_requests table and indexes if needed.
+func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLStore, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is required")
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("connection pool is required")
+	}
+
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the deferred_requests table and
+// its indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS deferred_requests (
+			id TEXT PRIMARY KEY,
+			created_at BIGINT NOT NULL,
+			updated_at BIGINT NOT NULL,
+			status TEXT NOT NULL,
+			next_attempt_at BIGINT NOT NULL,
+			expires_at BIGINT NOT NULL,
+			data JSONB NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create deferred_requests table: %w", err)
+	}
+
+	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_deferred_requests_due ON deferred_requests(status, next_attempt_at)"); err != nil {
+		return fmt.Errorf("failed to create deferred_requests due index: %w", err)
+	}
+	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_deferred_requests_expires_at ON deferred_requests(expires_at)"); err != nil {
+		return fmt.Errorf("failed to create deferred_requests expires_at index: %w", err)
+	}
+	return nil
+}
+
+// Create inserts a new deferred request.
+func (s *PostgreSQLStore) Create(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO deferred_requests (id, created_at, updated_at, status, next_attempt_at, expires_at, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb)
+	`, req.ID, req.CreatedAt.UnixMilli(), time.Now().UnixMilli(), string(req.Status),
+		req.NextAttemptAt.UnixMilli(), req.ExpiresAt.UnixMilli(), payload)
+	if err != nil {
+		return fmt.Errorf("insert deferred request: %w", err)
+	}
+	return nil
+}
+
+// Get returns a deferred request by id.
+func (s *PostgreSQLStore) Get(ctx context.Context, id string) (*Request, error) {
+	var payload []byte
+	var status string
+	var nextAttemptAt int64
+	err := s.pool.QueryRow(ctx,
+		"SELECT data, status, next_attempt_at FROM deferred_requests WHERE id = $1", id,
+	).Scan(&payload, &status, &nextAttemptAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query deferred request: %w", err)
+	}
+
+	req, err := deserializeRequest(payload, status, nextAttemptAt)
+	if err != nil {
+		return nil, fmt.Errorf("decode deferred request: %w", err)
+	}
+	return req, nil
+}
+
+// Update replaces a stored deferred request.
+func (s *PostgreSQLStore) Update(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE deferred_requests
+		SET updated_at = $1, status = $2, next_attempt_at = $3, expires_at = $4, data = $5::jsonb
+		WHERE id = $6
+	`, time.Now().UnixMilli(), string(req.Status), req.NextAttemptAt.UnixMilli(), req.ExpiresAt.UnixMilli(), payload, req.ID)
+	if err != nil {
+		return fmt.Errorf("update deferred request: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDue returns due requests ordered by next attempt time.
+func (s *PostgreSQLStore) ListDue(ctx context.Context, now time.Time, limit int) ([]*Request, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT data, status, next_attempt_at
+		FROM deferred_requests
+		WHERE status IN ($1, $2) AND next_attempt_at <= $3
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT $4
+	`, string(StatusQueued), string(StatusRunning), now.UnixMilli(), normalizeLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list due deferred requests: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Request
+	for rows.Next() {
+		var payload []byte
+		var status string
+		var nextAttemptAt int64
+		if err := rows.Scan(&payload, &status, &nextAttemptAt); err != nil {
+			return nil, fmt.Errorf("scan deferred request row: %w", err)
+		}
+		req, err := deserializeRequest(payload, status, nextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("decode deferred request row: %w", err)
+		}
+		items = append(items, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred request rows: %w", err)
+	}
+	return items, nil
+}
+
+// Claim marks a due request as running until leaseUntil.
+func (s *PostgreSQLStore) Claim(ctx context.Context, id string, now, leaseUntil time.Time) (bool, error) {
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE deferred_requests
+		SET status = $1, next_attempt_at = $2, updated_at = $3
+		WHERE id = $4 AND status IN ($5, $6) AND next_attempt_at <= $3
+	`, string(StatusRunning), leaseUntil.UnixMilli(), now.UnixMilli(), id, string(StatusQueued), string(StatusRunning))
+	if err != nil {
+		return false, fmt.Errorf("claim deferred request: %w", err)
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// CountByStatus returns the number of stored requests per status.
+func (s *PostgreSQLStore) CountByStatus(ctx context.Context) (map[Status]int, error) {
+	rows, err := s.pool.Query(ctx, "SELECT status, COUNT(*) FROM deferred_requests GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("count deferred requests: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[Status]int, len(Statuses))
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("scan deferred request count: %w", err)
+		}
+		counts[Status(status)] = int(count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred request counts: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteExpired removes final requests whose pickup window closed before now.
+func (s *PostgreSQLStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	cmd, err := s.pool.Exec(ctx, `
+		DELETE FROM deferred_requests
+		WHERE status IN ($1, $2, $3) AND expires_at < $4
+	`, string(StatusSucceeded), string(StatusFailed), string(StatusExpired), now.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired deferred requests: %w", err)
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+// Close is a no-op; pool lifecycle is managed by storage layer.
+func (s *PostgreSQLStore) Close() error {
+	return nil
+}
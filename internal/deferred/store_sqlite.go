@@ -0,0 +1,214 @@
+package deferred
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLiteStore stores deferred requests in SQLite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the deferred_requests table and indexes if needed.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is required")
+	}
+
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the deferred_requests table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS deferred_requests (
+			id TEXT PRIMARY KEY,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			next_attempt_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL,
+			data TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create deferred_requests table: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_deferred_requests_due ON deferred_requests(status, next_attempt_at)"); err != nil {
+		return fmt.Errorf("failed to create deferred_requests due index: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_deferred_requests_expires_at ON deferred_requests(expires_at)"); err != nil {
+		return fmt.Errorf("failed to create deferred_requests expires_at index: %w", err)
+	}
+	return nil
+}
+
+// Create inserts a new deferred request.
+func (s *SQLiteStore) Create(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO deferred_requests (id, created_at, updated_at, status, next_attempt_at, expires_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, req.ID, req.CreatedAt.UnixMilli(), time.Now().UnixMilli(), string(req.Status),
+		req.NextAttemptAt.UnixMilli(), req.ExpiresAt.UnixMilli(), string(payload))
+	if err != nil {
+		return fmt.Errorf("insert deferred request: %w", err)
+	}
+	return nil
+}
+
+// Get returns a deferred request by id.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Request, error) {
+	var payload, status string
+	var nextAttemptAt int64
+	err := s.db.QueryRowContext(ctx,
+		"SELECT data, status, next_attempt_at FROM deferred_requests WHERE id = ?", id,
+	).Scan(&payload, &status, &nextAttemptAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query deferred request: %w", err)
+	}
+
+	req, err := deserializeRequest([]byte(payload), status, nextAttemptAt)
+	if err != nil {
+		return nil, fmt.Errorf("decode deferred request: %w", err)
+	}
+	return req, nil
+}
+
+// Update replaces a stored deferred request.
+func (s *SQLiteStore) Update(ctx context.Context, req *Request) error {
+	payload, err := serializeRequest(req)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE deferred_requests
+		SET updated_at = ?, status = ?, next_attempt_at = ?, expires_at = ?, data = ?
+		WHERE id = ?
+	`, time.Now().UnixMilli(), string(req.Status), req.NextAttemptAt.UnixMilli(), req.ExpiresAt.UnixMilli(), string(payload), req.ID)
+	if err != nil {
+		return fmt.Errorf("update deferred request: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read update rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDue returns due requests ordered by next attempt time.
+func (s *SQLiteStore) ListDue(ctx context.Context, now time.Time, limit int) ([]*Request, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT data, status, next_attempt_at
+		FROM deferred_requests
+		WHERE status IN (?, ?) AND next_attempt_at <= ?
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT ?
+	`, string(StatusQueued), string(StatusRunning), now.UnixMilli(), normalizeLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("list due deferred requests: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Request
+	for rows.Next() {
+		var payload, status string
+		var nextAttemptAt int64
+		if err := rows.Scan(&payload, &status, &nextAttemptAt); err != nil {
+			return nil, fmt.Errorf("scan deferred request row: %w", err)
+		}
+		req, err := deserializeRequest([]byte(payload), status, nextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("decode deferred request row: %w", err)
+		}
+		items = append(items, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred request rows: %w", err)
+	}
+	return items, nil
+}
+
+// Claim marks a due request as running until leaseUntil.
+func (s *SQLiteStore) Claim(ctx context.Context, id string, now, leaseUntil time.Time) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE deferred_requests
+		SET status = ?, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status IN (?, ?) AND next_attempt_at <= ?
+	`, string(StatusRunning), leaseUntil.UnixMilli(), now.UnixMilli(), id,
+		string(StatusQueued), string(StatusRunning), now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("claim deferred request: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("read claim rows affected: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// CountByStatus returns the number of stored requests per status.
+func (s *SQLiteStore) CountByStatus(ctx context.Context) (map[Status]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM deferred_requests GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("count deferred requests: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[Status]int, len(Statuses))
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("scan deferred request count: %w", err)
+		}
+		counts[Status(status)] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deferred request counts: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteExpired removes final requests whose pickup window closed before now.
+func (s *SQLiteStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM deferred_requests
+		WHERE status IN (?, ?, ?) AND expires_at < ?
+	`, string(StatusSucceeded), string(StatusFailed), string(StatusExpired), now.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired deferred requests: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("read delete rows affected: %w", err)
+	}
+	return int(affected), nil
+}
+
+// Close is a no-op; DB lifecycle is managed by storage layer.
+func (s *SQLiteStore) Close() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package deferred
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gomodel/internal/storage"
+)
+
+func TestSQLiteStoreLifecycle(t *testing.T) {
+	st, err := storage.NewSQLite(storage.SQLiteConfig{Path: filepath.Join(t.TempDir(), "deferred.db")})
+	if err != nil {
+		t.Fatalf("new sqlite storage: %v", err)
+	}
+	defer st.Close()
+
+	store, err := NewSQLiteStore(st.DB())
+	if err != nil {
+		t.Fatalf("new sqlite deferred store: %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := &Request{
+		ID:            "deferred_1",
+		Status:        StatusQueued,
+		Method:        "POST",
+		Path:          "/v1/chat/completions",
+		Headers:       map[string]string{"Content-Type": "application/json"},
+		Body:          []byte(`{"model":"gpt-4o-mini"}`),
+		AuthKeyID:     "key-1",
+		MaxAttempts:   3,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		NextAttemptAt: now.Add(time.Second),
+		ExpiresAt:     now.Add(time.Hour),
+	}
+	if err := store.Create(ctx, req); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	due, err := store.ListDue(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("list due before next attempt: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("len(due) = %d before next attempt, want 0", len(due))
+	}
+
+	later := now.Add(2 * time.Second)
+	due, err = store.ListDue(ctx, later, 10)
+	if err != nil {
+		t.Fatalf("list due: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != req.ID || string(due[0].Body) != string(req.Body) || due[0].AuthKeyID != "key-1" {
+		t.Fatalf("due = %+v", due)
+	}
+
+	claimed, err := store.Claim(ctx, req.ID, later, later.Add(time.Minute))
+	if err != nil || !claimed {
+		t.Fatalf("claim = %v, %v; want true", claimed, err)
+	}
+	claimed, err = store.Claim(ctx, req.ID, later, later.Add(time.Minute))
+	if err != nil || claimed {
+		t.Fatalf("second claim = %v, %v; want false while leased", claimed, err)
+	}
+
+	got, err := store.Get(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != StatusRunning {
+		t.Fatalf("status = %s, want running", got.Status)
+	}
+
+	completed := later
+	got.Status = StatusSucceeded
+	got.CompletedAt = &completed
+	got.Response = []byte(`{"id":"chatcmpl-1"}`)
+	got.ExpiresAt = later.Add(time.Hour)
+	if err := store.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	counts, err := store.CountByStatus(ctx)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if counts[StatusSucceeded] != 1 || counts[StatusQueued] != 0 {
+		t.Fatalf("counts = %v", counts)
+	}
+
+	deleted, err := store.DeleteExpired(ctx, later.Add(2*time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("delete expired = %d, %v; want 1", deleted, err)
+	}
+	if _, err := store.Get(ctx, req.ID); err != ErrNotFound {
+		t.Fatalf("get after delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Update(ctx, got); err != ErrNotFound {
+		t.Fatalf("update missing error = %v, want ErrNotFound", err)
+	}
+}
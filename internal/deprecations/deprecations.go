@@ -0,0 +1,571 @@
+// Package deprecations tracks models their providers are shutting down and
+// warns the clients still using them.
+//
+// Deprecations come from a YAML data file, maintained per provider type, and
+// from config, whose entries override the file's for the same provider and
+// model. A request for a deprecated model gets the Deprecation (RFC 9745) and
+// Sunset (RFC 8594) response headers; once the sunset date has passed it is
+// either still served or rejected with a 410 naming the replacement. Usage of
+// deprecated models is counted for the admin view, and each model in use is
+// logged at most once per warn interval.
+//
+// Deprecations only exist when they are enabled in config: New returns a nil
+// Registry otherwise and the server does not install its middleware.
+package deprecations
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// After-sunset actions.
+const (
+	ActionWarn   = "warn"
+	ActionReject = "reject"
+)
+
+// ErrorCode is the error code of requests rejected after a model's sunset.
+const ErrorCode = "model_sunset"
+
+// Field is the top-level JSON field the warning is added under in
+// non-streaming JSON responses.
+const Field = "gomodel_deprecation"
+
+// Response headers set on requests for a deprecated model.
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// dateLayout is the layout of deprecation and sunset dates.
+const dateLayout = "2006-01-02"
+
+// Upstream states of a deprecated model, as found by the last CrossCheck.
+const (
+	UpstreamUnknown = "unknown"
+	UpstreamListed  = "listed"
+	UpstreamMissing = "missing"
+)
+
+// recentWindow is the window of the recent request count in the admin view.
+const recentWindow = 24 * time.Hour
+
+// topKeys is the number of consuming keys listed per deprecation.
+const topKeys = 5
+
+// maxKeysPerEntry bounds the keys counted per deprecation.
+const maxKeysPerEntry = 1000
+
+// Deprecation describes the deprecation of one model or model pattern.
+type Deprecation struct {
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model"`
+	Deprecated  time.Time `json:"deprecated"`
+	Sunset      time.Time `json:"sunset"`
+	Replacement string    `json:"replacement,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+}
+
+// Warning is the gomodel_deprecation object added to responses.
+type Warning struct {
+	Model       string `json:"model"`
+	Sunset      string `json:"sunset"`
+	Replacement string `json:"replacement,omitempty"`
+	Message     string `json:"message"`
+}
+
+// Match is the deprecation a request matched.
+type Match struct {
+	Deprecation
+	// ServedModel is the model the request targets.
+	ServedModel string
+	// SunsetPassed is true once the sunset date has passed.
+	SunsetPassed bool
+
+	entry *entry
+}
+
+// Report is one deprecation with the usage recorded for it.
+type Report struct {
+	Deprecation
+	SunsetPassed bool `json:"sunset_passed"`
+	// Upstream is whether the provider still lists the model, as of the last
+	// registry refresh. Patterns are always "unknown".
+	Upstream       string      `json:"upstream"`
+	RequestsRecent int64       `json:"requests_24h"`
+	RequestsTotal  int64       `json:"requests_total"`
+	LastRequestAt  *time.Time  `json:"last_request_at,omitempty"`
+	TopKeys        []KeyUsage  `json:"top_keys"`
+	Models         []ModelSeen `json:"models,omitempty"`
+}
+
+// KeyUsage is the number of requests one auth key sent for a deprecation.
+type KeyUsage struct {
+	KeyID    string `json:"key_id"`
+	Requests int64  `json:"requests"`
+}
+
+// ModelSeen is the number of requests for one model matching a pattern.
+type ModelSeen struct {
+	Model    string `json:"model"`
+	Requests int64  `json:"requests"`
+}
+
+// ModelLister reports the models providers currently list.
+type ModelLister interface {
+	// Listed reports whether a provider of the given type or name lists
+	// model. An empty provider matches any provider.
+	Listed(provider, model string) bool
+}
+
+// Registry matches requests against deprecations and counts their usage.
+// A nil Registry matches nothing.
+type Registry struct {
+	afterSunset  string
+	warnInterval time.Duration
+	exact        []*entry
+	patterns     []*entry
+	now          func() time.Time
+
+	mu sync.Mutex
+}
+
+type entry struct {
+	Deprecation
+	pattern *regexp.Regexp
+
+	// Guarded by Registry.mu.
+	upstream   string
+	total      int64
+	hourly     map[int64]int64 // hour start (unix) -> requests
+	lastSeen   time.Time
+	lastWarned map[string]time.Time // served model -> last log time
+	keys       map[string]int64
+	models     map[string]int64
+}
+
+// New returns the registry for cfg, or nil when deprecations are disabled.
+func New(cfg config.DeprecationsConfig) (*Registry, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	action := strings.ToLower(strings.TrimSpace(cfg.AfterSunset))
+	switch action {
+	case "":
+		action = ActionWarn
+	case ActionWarn, ActionReject:
+	default:
+		return nil, fmt.Errorf("after_sunset must be %q or %q, got %q", ActionWarn, ActionReject, cfg.AfterSunset)
+	}
+	warnInterval := cfg.WarnInterval
+	if warnInterval <= 0 {
+		warnInterval = time.Hour
+	}
+
+	var configs []config.ModelDeprecationConfig
+	if path := strings.TrimSpace(cfg.File); path != "" {
+		fromFile, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		configs = fromFile
+	}
+	// Config entries replace file entries with the same key and keep their
+	// own order otherwise.
+	index := make(map[string]int, len(configs)+len(cfg.Models))
+	for i, c := range configs {
+		index[entryKey(c)] = i
+	}
+	for i, c := range cfg.Models {
+		if c.Model == "" {
+			return nil, fmt.Errorf("models[%d]: model is required", i)
+		}
+		if at, ok := index[entryKey(c)]; ok {
+			configs[at] = c
+			continue
+		}
+		index[entryKey(c)] = len(configs)
+		configs = append(configs, c)
+	}
+
+	r := &Registry{afterSunset: action, warnInterval: warnInterval, now: time.Now}
+	for _, c := range configs {
+		e, err := newEntry(c)
+		if err != nil {
+			return nil, err
+		}
+		if e.pattern != nil {
+			r.patterns = append(r.patterns, e)
+		} else {
+			r.exact = append(r.exact, e)
+		}
+	}
+	return r, nil
+}
+
+// loadFile reads a deprecations data file: a map of provider types to lists
+// of deprecated models.
+func loadFile(path string) ([]config.ModelDeprecationConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("file: failed to read %q: %w", path, err)
+	}
+	var byProvider map[string][]config.ModelDeprecationConfig
+	if err := yaml.Unmarshal(raw, &byProvider); err != nil {
+		return nil, fmt.Errorf("file: failed to parse %q: %w", path, err)
+	}
+	providers := make([]string, 0, len(byProvider))
+	for provider := range byProvider {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+
+	var configs []config.ModelDeprecationConfig
+	for _, provider := range providers {
+		for i, c := range byProvider[provider] {
+			if c.Model == "" {
+				return nil, fmt.Errorf("file %q: %s[%d]: model is required", path, provider, i)
+			}
+			c.Provider = provider
+			configs = append(configs, c)
+		}
+	}
+	return configs, nil
+}
+
+func entryKey(c config.ModelDeprecationConfig) string {
+	return strings.ToLower(strings.TrimSpace(c.Provider)) + "\x00" + strings.TrimSpace(c.Model)
+}
+
+func newEntry(c config.ModelDeprecationConfig) (*entry, error) {
+	model := strings.TrimSpace(c.Model)
+	name := model
+	if provider := strings.TrimSpace(c.Provider); provider != "" {
+		name = provider + "/" + model
+	}
+	sunset, err := time.Parse(dateLayout, strings.TrimSpace(c.Sunset))
+	if err != nil {
+		return nil, fmt.Errorf("%s: sunset must be a YYYY-MM-DD date, got %q", name, c.Sunset)
+	}
+	deprecated := sunset
+	if raw := strings.TrimSpace(c.Deprecated); raw != "" {
+		deprecated, err = time.Parse(dateLayout, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: deprecated must be a YYYY-MM-DD date, got %q", name, c.Deprecated)
+		}
+	}
+	e := &entry{
+		Deprecation: Deprecation{
+			Provider:    strings.TrimSpace(c.Provider),
+			Model:       model,
+			Deprecated:  deprecated,
+			Sunset:      sunset,
+			Replacement: strings.TrimSpace(c.Replacement),
+			Notes:       strings.TrimSpace(c.Notes),
+		},
+		upstream:   UpstreamUnknown,
+		hourly:     make(map[int64]int64),
+		lastWarned: make(map[string]time.Time),
+		keys:       make(map[string]int64),
+		models:     make(map[string]int64),
+	}
+	if strings.Contains(model, "*") {
+		e.pattern = compilePattern(model)
+	}
+	return e, nil
+}
+
+// compilePattern turns a "*" wildcard pattern into an anchored regexp. "*"
+// also matches "/", so patterns can span vendor prefixes.
+func compilePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// AfterSunset returns ActionWarn or ActionReject.
+func (r *Registry) AfterSunset() string {
+	return r.afterSunset
+}
+
+// Len returns the number of deprecations.
+func (r *Registry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.exact) + len(r.patterns)
+}
+
+// Target is what a request resolved to.
+type Target struct {
+	// Providers holds the provider types and names of the request.
+	Providers []string
+	// Models holds the requested and resolved models, bare and
+	// provider-qualified.
+	Models []string
+	// Model is the model the request is served by.
+	Model string
+}
+
+// Lookup returns the deprecation matching target. Exact entries win over
+// patterns.
+func (r *Registry) Lookup(target Target) (*Match, bool) {
+	if r == nil || len(target.Models) == 0 {
+		return nil, false
+	}
+	for _, candidates := range [][]*entry{r.exact, r.patterns} {
+		for _, e := range candidates {
+			if !e.matchesProvider(target.Providers) {
+				continue
+			}
+			for _, model := range target.Models {
+				if model != "" && e.matchesModel(model) {
+					served := target.Model
+					if served == "" {
+						served = model
+					}
+					return &Match{
+						Deprecation:  e.Deprecation,
+						ServedModel:  served,
+						SunsetPassed: !r.now().Before(e.Sunset),
+						entry:        e,
+					}, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+func (e *entry) matchesProvider(providers []string) bool {
+	if e.Provider == "" {
+		return true
+	}
+	for _, provider := range providers {
+		if strings.EqualFold(provider, e.Provider) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *entry) matchesModel(model string) bool {
+	if e.pattern != nil {
+		return e.pattern.MatchString(model)
+	}
+	return model == e.Model
+}
+
+// Rejects reports whether m must be rejected rather than served.
+func (r *Registry) Rejects(m *Match) bool {
+	return m.SunsetPassed && r.afterSunset == ActionReject
+}
+
+// RejectionError returns the error a request rejected after m's sunset gets.
+func RejectionError(m *Match) *core.GatewayError {
+	message := fmt.Sprintf("model %q was shut down on %s", m.ServedModel, m.Sunset.Format(dateLayout))
+	if m.Replacement != "" {
+		message += "; use " + m.Replacement + " instead"
+	}
+	return core.NewInvalidRequestErrorWithStatus(http.StatusGone, message, nil).
+		WithParam("model").
+		WithCode(ErrorCode)
+}
+
+// SetHeaders sets the Deprecation and Sunset headers for m.
+func SetHeaders(header http.Header, m *Match) {
+	header.Set(DeprecationHeader, "@"+strconv.FormatInt(m.Deprecated.Unix(), 10))
+	header.Set(SunsetHeader, m.Sunset.Format(http.TimeFormat))
+}
+
+// WarningFor returns the warning added to responses for m.
+func WarningFor(m *Match) Warning {
+	verb := "will be shut down on"
+	if m.SunsetPassed {
+		verb = "was shut down on"
+	}
+	message := fmt.Sprintf("model %s is deprecated and %s %s", m.ServedModel, verb, m.Sunset.Format(dateLayout))
+	if m.Replacement != "" {
+		message += "; migrate to " + m.Replacement
+	}
+	if m.Notes != "" {
+		message += ". " + m.Notes
+	}
+	return Warning{
+		Model:       m.ServedModel,
+		Sunset:      m.Sunset.Format(dateLayout),
+		Replacement: m.Replacement,
+		Message:     message,
+	}
+}
+
+// Embed returns body with w added under Field. Bodies that are not JSON
+// objects are returned unchanged with ok false.
+func Embed(body []byte, w Warning) ([]byte, bool) {
+	trimmed := bytes.TrimRight(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[len(trimmed)-1] != '}' || !json.Valid(trimmed) {
+		return body, false
+	}
+	encoded, err := json.Marshal(w)
+	if err != nil {
+		return body, false
+	}
+	head := bytes.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+	out := make([]byte, 0, len(body)+len(Field)+len(encoded)+4)
+	out = append(out, head...)
+	if head[len(head)-1] != '{' {
+		out = append(out, ',')
+	}
+	out = append(out, '"')
+	out = append(out, Field...)
+	out = append(out, '"', ':')
+	out = append(out, encoded...)
+	out = append(out, '}')
+	return out, true
+}
+
+// Record counts a request for m sent with the given auth key ID (empty when
+// the request used no managed key) and logs the model in use at most once
+// per warn interval.
+func (r *Registry) Record(m *Match, keyID string) {
+	if r == nil || m == nil || m.entry == nil {
+		return
+	}
+	now := r.now()
+	e := m.entry
+
+	r.mu.Lock()
+	e.total++
+	e.lastSeen = now
+	hour := now.Truncate(time.Hour).Unix()
+	e.hourly[hour]++
+	for start := range e.hourly {
+		if now.Sub(time.Unix(start, 0)) > recentWindow {
+			delete(e.hourly, start)
+		}
+	}
+	if keyID != "" {
+		if _, ok := e.keys[keyID]; ok || len(e.keys) < maxKeysPerEntry {
+			e.keys[keyID]++
+		}
+	}
+	if e.pattern != nil && m.ServedModel != "" {
+		if _, ok := e.models[m.ServedModel]; ok || len(e.models) < maxKeysPerEntry {
+			e.models[m.ServedModel]++
+		}
+	}
+	warn := now.Sub(e.lastWarned[m.ServedModel]) >= r.warnInterval
+	if warn {
+		e.lastWarned[m.ServedModel] = now
+	}
+	r.mu.Unlock()
+
+	if warn {
+		slog.Warn("request for deprecated model",
+			"model", m.ServedModel,
+			"sunset", m.Sunset.Format(dateLayout),
+			"sunset_passed", m.SunsetPassed,
+			"replacement", m.Replacement,
+		)
+	}
+}
+
+// CrossCheck records whether the providers still list each deprecated model
+// and logs the ones that disappeared, which usually means the provider has
+// already shut them down. It runs after every model registry refresh.
+func (r *Registry) CrossCheck(lister ModelLister) {
+	if r == nil || lister == nil {
+		return
+	}
+	var missing []string
+	r.mu.Lock()
+	for _, e := range r.exact {
+		upstream := UpstreamMissing
+		if lister.Listed(e.Provider, e.Model) {
+			upstream = UpstreamListed
+		}
+		if upstream == UpstreamMissing && e.upstream != UpstreamMissing {
+			missing = append(missing, e.Model)
+		}
+		e.upstream = upstream
+	}
+	r.mu.Unlock()
+
+	if len(missing) > 0 {
+		slog.Warn("deprecated models no longer listed by their providers", "models", missing)
+	}
+}
+
+// Reports returns every deprecation with its usage, sunset soonest first.
+func (r *Registry) Reports() []Report {
+	if r == nil {
+		return []Report{}
+	}
+	now := r.now()
+	entries := append(slices.Clone(r.exact), r.patterns...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reports := make([]Report, 0, len(entries))
+	for _, e := range entries {
+		report := Report{
+			Deprecation:   e.Deprecation,
+			SunsetPassed:  !now.Before(e.Sunset),
+			Upstream:      e.upstream,
+			RequestsTotal: e.total,
+			TopKeys:       topKeyUsage(e.keys),
+		}
+		for start, count := range e.hourly {
+			if now.Sub(time.Unix(start, 0)) <= recentWindow {
+				report.RequestsRecent += count
+			}
+		}
+		if !e.lastSeen.IsZero() {
+			lastSeen := e.lastSeen
+			report.LastRequestAt = &lastSeen
+		}
+		for model, count := range e.models {
+			report.Models = append(report.Models, ModelSeen{Model: model, Requests: count})
+		}
+		slices.SortFunc(report.Models, func(a, b ModelSeen) int {
+			return cmp.Or(cmp.Compare(b.Requests, a.Requests), strings.Compare(a.Model, b.Model))
+		})
+		reports = append(reports, report)
+	}
+	slices.SortStableFunc(reports, func(a, b Report) int {
+		return a.Sunset.Compare(b.Sunset)
+	})
+	return reports
+}
+
+func topKeyUsage(keys map[string]int64) []KeyUsage {
+	usage := make([]KeyUsage, 0, len(keys))
+	for keyID, count := range keys {
+		usage = append(usage, KeyUsage{KeyID: keyID, Requests: count})
+	}
+	slices.SortFunc(usage, func(a, b KeyUsage) int {
+		return cmp.Or(cmp.Compare(b.Requests, a.Requests), strings.Compare(a.KeyID, b.KeyID))
+	})
+	if len(usage) > topKeys {
+		usage = usage[:topKeys]
+	}
+	return usage
+}
@@ -0,0 +1,236 @@
+package deprecations
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+type fakeLister map[string]bool
+
+func (l fakeLister) Listed(provider, model string) bool {
+	return l[provider+"/"+model]
+}
+
+func newTestRegistry(t *testing.T, cfg config.DeprecationsConfig, now time.Time) *Registry {
+	t.Helper()
+	cfg.Enabled = true
+	r, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestNew_Disabled(t *testing.T) {
+	r, err := New(config.DeprecationsConfig{Models: []config.ModelDeprecationConfig{{Model: "gpt-4", Sunset: "2025-01-01"}}})
+	if err != nil || r != nil {
+		t.Fatalf("New() = %v, %v; want nil, nil", r, err)
+	}
+	if _, ok := r.Lookup(Target{Models: []string{"gpt-4"}}); ok {
+		t.Fatal("nil registry matched a request")
+	}
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]config.DeprecationsConfig{
+		"action":     {AfterSunset: "block"},
+		"no model":   {Models: []config.ModelDeprecationConfig{{Sunset: "2025-01-01"}}},
+		"no sunset":  {Models: []config.ModelDeprecationConfig{{Model: "gpt-4"}}},
+		"bad sunset": {Models: []config.ModelDeprecationConfig{{Model: "gpt-4", Sunset: "01/01/2025"}}},
+		"missing":    {File: filepath.Join(t.TempDir(), "missing.yaml")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg.Enabled = true
+			if _, err := New(cfg); err == nil {
+				t.Fatal("New() succeeded")
+			}
+		})
+	}
+}
+
+func TestNew_ConfigOverridesFileEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deprecations.yaml")
+	data := `
+openai:
+  - model: gpt-4-0613
+    sunset: "2025-06-06"
+    replacement: gpt-4o
+  - model: gpt-3.5-turbo-*
+    deprecated: "2024-07-01"
+    sunset: "2025-09-13"
+anthropic:
+  - model: claude-2.1
+    sunset: "2025-07-21"
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRegistry(t, config.DeprecationsConfig{
+		File: path,
+		Models: []config.ModelDeprecationConfig{
+			{Provider: "openai", Model: "gpt-4-0613", Sunset: "2025-12-31", Replacement: "gpt-4.1"},
+			{Model: "legacy-model", Sunset: "2026-01-01"},
+		},
+	}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if r.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", r.Len())
+	}
+	m, ok := r.Lookup(Target{Providers: []string{"openai"}, Models: []string{"gpt-4-0613"}})
+	if !ok || m.Replacement != "gpt-4.1" || m.Sunset.Format(dateLayout) != "2025-12-31" {
+		t.Fatalf("Lookup() = %+v, %v; want the config entry", m, ok)
+	}
+	// File entries only apply to their provider type.
+	if _, ok := r.Lookup(Target{Providers: []string{"azure"}, Models: []string{"gpt-4-0613"}}); ok {
+		t.Fatal("openai entry matched an azure request")
+	}
+	m, ok = r.Lookup(Target{Providers: []string{"openai", "openai-eu"}, Models: []string{"my-alias", "gpt-3.5-turbo-0125"}, Model: "gpt-3.5-turbo-0125"})
+	if !ok || m.Model != "gpt-3.5-turbo-*" || m.ServedModel != "gpt-3.5-turbo-0125" || m.Deprecated.Format(dateLayout) != "2024-07-01" {
+		t.Fatalf("Lookup() = %+v, %v; want the pattern entry", m, ok)
+	}
+	if m, ok := r.Lookup(Target{Providers: []string{"anthropic"}, Models: []string{"claude-2.1"}}); !ok || !m.Deprecated.Equal(m.Sunset) {
+		t.Fatalf("Lookup() = %+v, %v; want deprecated to default to sunset", m, ok)
+	}
+}
+
+func TestLookup_SunsetAndRejection(t *testing.T) {
+	cfg := config.DeprecationsConfig{
+		AfterSunset: "reject",
+		Models:      []config.ModelDeprecationConfig{{Model: "gpt-4-0613", Sunset: "2025-06-06", Replacement: "gpt-4o"}},
+	}
+	before := newTestRegistry(t, cfg, time.Date(2025, 6, 5, 23, 0, 0, 0, time.UTC))
+	m, _ := before.Lookup(Target{Models: []string{"gpt-4-0613"}})
+	if m.SunsetPassed || before.Rejects(m) {
+		t.Fatalf("match before sunset = %+v, rejected %v", m, before.Rejects(m))
+	}
+
+	after := newTestRegistry(t, cfg, time.Date(2025, 6, 6, 0, 0, 0, 0, time.UTC))
+	m, _ = after.Lookup(Target{Models: []string{"gpt-4-0613"}})
+	if !m.SunsetPassed || !after.Rejects(m) {
+		t.Fatalf("match after sunset = %+v, rejected %v", m, after.Rejects(m))
+	}
+	err := RejectionError(m)
+	if err.HTTPStatusCode() != http.StatusGone || !strings.Contains(err.Message, "gpt-4o") {
+		t.Fatalf("RejectionError() = %d %q", err.HTTPStatusCode(), err.Message)
+	}
+
+	cfg.AfterSunset = "warn"
+	warnOnly := newTestRegistry(t, cfg, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if m, _ := warnOnly.Lookup(Target{Models: []string{"gpt-4-0613"}}); warnOnly.Rejects(m) {
+		t.Fatal("warn-only registry rejected a request")
+	}
+}
+
+func TestSetHeadersAndWarning(t *testing.T) {
+	r := newTestRegistry(t, config.DeprecationsConfig{Models: []config.ModelDeprecationConfig{{
+		Model: "gpt-4-0613", Deprecated: "2024-06-06", Sunset: "2025-06-06", Replacement: "gpt-4o", Notes: "See the provider notice.",
+	}}}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	m, _ := r.Lookup(Target{Models: []string{"gpt-4-0613"}})
+
+	header := http.Header{}
+	SetHeaders(header, m)
+	if got := header.Get(DeprecationHeader); got != "@1717632000" {
+		t.Fatalf("Deprecation = %q", got)
+	}
+	if got := header.Get(SunsetHeader); got != "Fri, 06 Jun 2025 00:00:00 GMT" {
+		t.Fatalf("Sunset = %q", got)
+	}
+
+	body, ok := Embed([]byte(`{"id":"chatcmpl-1"}`+"\n"), WarningFor(m))
+	if !ok {
+		t.Fatal("Embed() ok = false")
+	}
+	var decoded struct {
+		ID      string  `json:"id"`
+		Warning Warning `json:"gomodel_deprecation"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("embedded body is not JSON: %v\n%s", err, body)
+	}
+	want := "model gpt-4-0613 is deprecated and will be shut down on 2025-06-06; migrate to gpt-4o. See the provider notice."
+	if decoded.ID != "chatcmpl-1" || decoded.Warning.Message != want || decoded.Warning.Replacement != "gpt-4o" {
+		t.Fatalf("decoded = %+v", decoded)
+	}
+	if _, ok := Embed([]byte(`[1,2]`), WarningFor(m)); ok {
+		t.Fatal("Embed() added the warning to an array")
+	}
+}
+
+func TestRecord_RollsUpUsage(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	r := newTestRegistry(t, config.DeprecationsConfig{Models: []config.ModelDeprecationConfig{
+		{Model: "gpt-4-0613", Sunset: "2025-06-06"},
+		{Model: "gpt-3.5-*", Sunset: "2025-03-01"},
+		{Model: "unused", Sunset: "2025-12-01"},
+	}}, now)
+	record := func(at time.Time, model, keyID string, times int) {
+		r.now = func() time.Time { return at }
+		m, ok := r.Lookup(Target{Models: []string{model}})
+		if !ok {
+			t.Fatalf("Lookup(%q) did not match", model)
+		}
+		for range times {
+			r.Record(m, keyID)
+		}
+	}
+	record(now.Add(-48*time.Hour), "gpt-4-0613", "key-old", 5)
+	record(now.Add(-time.Hour), "gpt-4-0613", "key-a", 3)
+	record(now, "gpt-4-0613", "key-b", 4)
+	record(now, "gpt-4-0613", "", 2)
+	record(now, "gpt-3.5-turbo", "key-a", 1)
+	record(now, "gpt-3.5-turbo-16k", "key-a", 2)
+
+	reports := r.Reports()
+	if len(reports) != 3 || reports[0].Model != "gpt-3.5-*" || reports[2].Model != "unused" {
+		t.Fatalf("reports = %+v, want sunset order", reports)
+	}
+	gpt4 := reports[1]
+	if gpt4.RequestsTotal != 14 || gpt4.RequestsRecent != 9 || gpt4.LastRequestAt == nil || !gpt4.LastRequestAt.Equal(now) {
+		t.Fatalf("gpt-4 report = %+v", gpt4)
+	}
+	wantKeys := []KeyUsage{{"key-old", 5}, {"key-b", 4}, {"key-a", 3}}
+	if len(gpt4.TopKeys) != len(wantKeys) {
+		t.Fatalf("top keys = %+v, want %+v", gpt4.TopKeys, wantKeys)
+	}
+	for i, want := range wantKeys {
+		if gpt4.TopKeys[i] != want {
+			t.Fatalf("top keys = %+v, want %+v", gpt4.TopKeys, wantKeys)
+		}
+	}
+	pattern := reports[0]
+	if len(pattern.Models) != 2 || pattern.Models[0] != (ModelSeen{"gpt-3.5-turbo-16k", 2}) {
+		t.Fatalf("pattern models = %+v", pattern.Models)
+	}
+	if unused := reports[2]; unused.RequestsTotal != 0 || unused.LastRequestAt != nil || unused.TopKeys == nil {
+		t.Fatalf("unused report = %+v", unused)
+	}
+}
+
+func TestCrossCheck_MarksModelsMissingUpstream(t *testing.T) {
+	r := newTestRegistry(t, config.DeprecationsConfig{Models: []config.ModelDeprecationConfig{
+		{Provider: "openai", Model: "gpt-4-0613", Sunset: "2025-06-06"},
+		{Provider: "openai", Model: "gpt-4-0314", Sunset: "2024-06-13"},
+		{Model: "gpt-3.5-*", Sunset: "2025-03-01"},
+	}}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	r.CrossCheck(fakeLister{"openai/gpt-4-0613": true})
+
+	upstream := map[string]string{}
+	for _, report := range r.Reports() {
+		upstream[report.Model] = report.Upstream
+	}
+	want := map[string]string{"gpt-4-0613": UpstreamListed, "gpt-4-0314": UpstreamMissing, "gpt-3.5-*": UpstreamUnknown}
+	for model, state := range want {
+		if upstream[model] != state {
+			t.Fatalf("upstream = %v, want %v", upstream, want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"gomodel/config"
+	"gomodel/internal/httpclient"
 )
 
 const defaultTimeout = 120 * time.Second
@@ -54,11 +55,20 @@ func NewEmbedder(cfg config.EmbedderConfig, resolvedProviders map[string]config.
 	} else if typ == "gemini" {
 		model = normalizeGeminiEmbeddingModel(model)
 	}
+	httpClient := &http.Client{Timeout: defaultTimeout}
+	if transport := raw.TransportOptions(); !transport.IsZero() {
+		clientCfg := httpclient.DefaultConfig()
+		clientCfg.Timeout = defaultTimeout
+		if err := transport.Apply(&clientCfg); err != nil {
+			return nil, fmt.Errorf("embedding: provider %q: %w", p, err)
+		}
+		httpClient = httpclient.NewHTTPClient(&clientCfg)
+	}
 	return &apiEmbedder{
 		endpointURL: endpointURL,
 		apiKey:      raw.APIKey,
 		model:       model,
-		httpClient:  &http.Client{Timeout: defaultTimeout},
+		httpClient:  httpClient,
 	}, nil
 }
 
@@ -0,0 +1,313 @@
+// Package embeddingcache caches /v1/embeddings results per input string, so
+// a batch only sends the inputs missing from the cache upstream.
+package embeddingcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"gomodel/config"
+	"gomodel/internal/cache"
+	"gomodel/internal/core"
+	"gomodel/internal/memgovernor"
+)
+
+// Cache serves embeddings inputs from a cache.Store. Each input string is
+// stored under a key derived from the model, the dimensions, the encoding
+// format and a hash of the input.
+type Cache struct {
+	store   cache.Store
+	enabled bool
+	ttl     time.Duration
+	models  map[string]modelPolicy
+	group   singleflight.Group
+}
+
+type modelPolicy struct {
+	enabled *bool
+	ttl     *time.Duration
+}
+
+// Execute sends req upstream to the model the cache key names.
+type Execute func(ctx context.Context, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error)
+
+// Result is an embeddings response assembled from cached and upstream
+// embeddings.
+type Result struct {
+	Response *core.EmbeddingResponse
+	// Hits counts the inputs served without an upstream call of this
+	// request: cached inputs and inputs shared with an identical concurrent
+	// request.
+	Hits int
+	// Misses counts the inputs this request sent upstream.
+	Misses int
+}
+
+// Upstream reports whether this request sent inputs upstream, which means
+// the execute function ran and its route metadata applies.
+func (r *Result) Upstream() bool {
+	return r != nil && r.Misses > 0
+}
+
+// New creates a cache backed by an in-memory LRU store sized by cfg. It
+// returns nil when the cache is off for every model.
+func New(cfg config.EmbeddingCacheConfig) *Cache {
+	return NewWithStore(cache.NewLRUStore(cfg.MaxEntries, cfg.MaxBytes), cfg)
+}
+
+// NewWithStore creates a cache backed by store. It returns nil when the cache
+// is off for every model.
+func NewWithStore(store cache.Store, cfg config.EmbeddingCacheConfig) *Cache {
+	c := &Cache{
+		store:   store,
+		enabled: cfg.Enabled,
+		ttl:     time.Duration(cfg.TTL) * time.Second,
+		models:  make(map[string]modelPolicy, len(cfg.Models)),
+	}
+	active := cfg.Enabled
+	for model, override := range cfg.Models {
+		policy := modelPolicy{enabled: override.Enabled}
+		if override.TTL != nil {
+			ttl := time.Duration(*override.TTL) * time.Second
+			policy.ttl = &ttl
+		}
+		c.models[strings.TrimSpace(model)] = policy
+		active = active || (override.Enabled != nil && *override.Enabled)
+	}
+	if store == nil || !active {
+		return nil
+	}
+	return c
+}
+
+// policy returns whether caching is on and the TTL for a request. selectors
+// are matched against the per-model settings in order; the first match wins.
+func (c *Cache) policy(selectors []string) (bool, time.Duration) {
+	enabled, ttl := c.enabled, c.ttl
+	for _, selector := range selectors {
+		policy, ok := c.models[strings.TrimSpace(selector)]
+		if !ok {
+			continue
+		}
+		if policy.enabled != nil {
+			enabled = *policy.enabled
+		}
+		if policy.ttl != nil {
+			ttl = *policy.ttl
+		}
+		break
+	}
+	return enabled, ttl
+}
+
+// Embed serves req from the cache where it can and sends the remaining inputs
+// upstream through execute, merging both in input order. model is the
+// qualified model serving the request and is part of the cache key;
+// selectors are matched against the per-model settings. ok is false when the
+// request is not cacheable and the caller should execute it normally.
+func (c *Cache) Embed(ctx context.Context, model string, selectors []string, req *core.EmbeddingRequest, execute Execute) (result *Result, ok bool, err error) {
+	if c == nil || req == nil || strings.TrimSpace(model) == "" {
+		return nil, false, nil
+	}
+	enabled, ttl := c.policy(selectors)
+	if !enabled {
+		return nil, false, nil
+	}
+	inputs, single, ok := stringInputs(req)
+	if !ok {
+		return nil, false, nil
+	}
+
+	keys := make([]string, len(inputs))
+	data := make([]core.EmbeddingData, len(inputs))
+	var first *entry
+	var missKeys []string
+	var missIndexes []int
+	for i, input := range inputs {
+		keys[i] = cacheKey(model, req, input)
+		cached := c.lookup(ctx, keys[i])
+		if cached == nil {
+			missKeys = append(missKeys, keys[i])
+			missIndexes = append(missIndexes, i)
+			continue
+		}
+		if first == nil {
+			first = cached
+		}
+		data[i] = core.EmbeddingData{Object: "embedding", Embedding: cached.Embedding, Index: i}
+	}
+
+	resp := &core.EmbeddingResponse{Object: "list", Data: data}
+	if first != nil {
+		resp.Model, resp.Provider = first.Model, first.Provider
+	}
+	if len(missIndexes) == 0 {
+		return &Result{Response: resp, Hits: len(inputs)}, true, nil
+	}
+
+	missReq := *req
+	missInputs := make([]string, len(missIndexes))
+	for i, index := range missIndexes {
+		missInputs[i] = inputs[index]
+	}
+	missReq.Input = missInputs
+	if single {
+		missReq.Input = missInputs[0]
+	}
+
+	ran := false
+	shared, err, _ := c.group.Do(strings.Join(missKeys, ","), func() (any, error) {
+		ran = true
+		upstream, err := execute(ctx, &missReq)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkUpstream(upstream, len(missIndexes)); err != nil {
+			return nil, err
+		}
+		c.storeAll(ctx, missKeys, upstream, ttl)
+		return upstream, nil
+	})
+	if err != nil {
+		return nil, true, err
+	}
+	upstream := shared.(*core.EmbeddingResponse)
+	for _, item := range upstream.Data {
+		index := missIndexes[item.Index]
+		data[index] = core.EmbeddingData{Object: "embedding", Embedding: item.Embedding, Index: index}
+	}
+	resp.Model, resp.Provider = upstream.Model, upstream.Provider
+	if upstream.Object != "" {
+		resp.Object = upstream.Object
+	}
+
+	if !ran {
+		// Another request sent these inputs upstream and records their usage.
+		return &Result{Response: resp, Hits: len(inputs)}, true, nil
+	}
+	resp.Usage = upstream.Usage
+	return &Result{Response: resp, Hits: len(inputs) - len(missIndexes), Misses: len(missIndexes)}, true, nil
+}
+
+// entry is the stored form of one cached embedding.
+type entry struct {
+	Model     string          `json:"model"`
+	Provider  string          `json:"provider,omitempty"`
+	Embedding json.RawMessage `json:"embedding"`
+}
+
+func (c *Cache) lookup(ctx context.Context, key string) *entry {
+	raw, err := c.store.Get(ctx, key)
+	if err != nil {
+		slog.Warn("embedding cache read failed", "error", err)
+		return nil
+	}
+	if raw == nil {
+		return nil
+	}
+	var cached entry
+	if err := json.Unmarshal(raw, &cached); err != nil || len(cached.Embedding) == 0 {
+		return nil
+	}
+	return &cached
+}
+
+func (c *Cache) storeAll(ctx context.Context, keys []string, resp *core.EmbeddingResponse, ttl time.Duration) {
+	for _, item := range resp.Data {
+		raw, err := json.Marshal(entry{Model: resp.Model, Provider: resp.Provider, Embedding: item.Embedding})
+		if err != nil {
+			continue
+		}
+		if err := c.store.Set(ctx, keys[item.Index], raw, ttl); err != nil {
+			slog.Warn("embedding cache write failed", "error", err)
+			return
+		}
+	}
+}
+
+// MemoryUsage returns the size of the cached embeddings when they are kept
+// in process memory, and zero for shared stores.
+func (c *Cache) MemoryUsage() (int, int64) {
+	if store, ok := c.store.(memgovernor.Store); ok {
+		return store.MemoryUsage()
+	}
+	return 0, 0
+}
+
+// Evict drops the least recently used fraction of the cached embeddings kept
+// in process memory.
+func (c *Cache) Evict(fraction float64) {
+	if store, ok := c.store.(memgovernor.Store); ok {
+		store.Evict(fraction)
+	}
+}
+
+// checkUpstream verifies that resp holds exactly one embedding for each of
+// the want inputs sent upstream.
+func checkUpstream(resp *core.EmbeddingResponse, want int) error {
+	if resp == nil {
+		return core.NewProviderError("", http.StatusBadGateway, "embeddings provider returned an empty response", nil)
+	}
+	seen := make([]bool, want)
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= want || seen[item.Index] {
+			return core.NewProviderError(resp.Provider, http.StatusBadGateway, "embeddings provider returned an unexpected embedding index "+strconv.Itoa(item.Index), nil)
+		}
+		seen[item.Index] = true
+	}
+	if len(resp.Data) != want {
+		return core.NewProviderError(resp.Provider, http.StatusBadGateway, fmt.Sprintf("embeddings provider returned %d embeddings for %d inputs", len(resp.Data), want), nil)
+	}
+	return nil
+}
+
+// stringInputs returns the input strings of req. single reports a plain
+// string input. Token inputs and requests with provider-specific fields,
+// which may change the embedding, are not cacheable.
+func stringInputs(req *core.EmbeddingRequest) (inputs []string, single, ok bool) {
+	if !req.ExtraFields.IsEmpty() {
+		return nil, false, false
+	}
+	switch input := req.Input.(type) {
+	case string:
+		return []string{input}, true, true
+	case []string:
+		return input, false, len(input) > 0
+	case []any:
+		inputs = make([]string, len(input))
+		for i, item := range input {
+			text, isString := item.(string)
+			if !isString {
+				return nil, false, false
+			}
+			inputs[i] = text
+		}
+		return inputs, false, len(inputs) > 0
+	default:
+		return nil, false, false
+	}
+}
+
+func cacheKey(model string, req *core.EmbeddingRequest, input string) string {
+	dimensions := ""
+	if req.Dimensions != nil {
+		dimensions = strconv.Itoa(*req.Dimensions)
+	}
+	encoding := strings.ToLower(strings.TrimSpace(req.EncodingFormat))
+	if encoding == "" {
+		encoding = "float"
+	}
+	inputHash := sha256.Sum256([]byte(input))
+	sum := sha256.Sum256([]byte(model + "\x00" + dimensions + "\x00" + encoding + "\x00" + hex.EncodeToString(inputHash[:])))
+	return "embedding:" + hex.EncodeToString(sum[:])
+}
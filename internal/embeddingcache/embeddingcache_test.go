@@ -0,0 +1,242 @@
+package embeddingcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+const testModel = "openai/text-embedding-3-small"
+
+// fakeUpstream embeds each input as [len(input)] and counts the inputs it
+// was sent.
+type fakeUpstream struct {
+	mu     sync.Mutex
+	calls  int
+	inputs [][]string
+	delay  time.Duration
+}
+
+func (f *fakeUpstream) execute(_ context.Context, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	inputs, _, _ := stringInputs(req)
+	f.mu.Lock()
+	f.calls++
+	f.inputs = append(f.inputs, inputs)
+	f.mu.Unlock()
+	time.Sleep(f.delay)
+
+	resp := &core.EmbeddingResponse{Object: "list", Model: "text-embedding-3-small", Provider: "openai"}
+	// Return the embeddings out of order; indexes identify the inputs.
+	for i := len(inputs) - 1; i >= 0; i-- {
+		resp.Data = append(resp.Data, core.EmbeddingData{
+			Object:    "embedding",
+			Embedding: json.RawMessage(fmt.Sprintf("[%d]", len(inputs[i]))),
+			Index:     i,
+		})
+	}
+	resp.Usage = core.EmbeddingUsage{PromptTokens: len(inputs), TotalTokens: len(inputs)}
+	return resp, nil
+}
+
+func newTestCache(t *testing.T) *Cache {
+	t.Helper()
+	c := New(config.EmbeddingCacheConfig{Enabled: true, MaxEntries: 100, MaxBytes: 1 << 20})
+	if c == nil {
+		t.Fatal("New() returned nil for an enabled cache")
+	}
+	return c
+}
+
+func embeddings(resp *core.EmbeddingResponse) []string {
+	out := make([]string, len(resp.Data))
+	for i, item := range resp.Data {
+		out[i] = fmt.Sprintf("%d:%s", item.Index, item.Embedding)
+	}
+	return out
+}
+
+func TestEmbed_PartialHitMergesInInputOrder(t *testing.T) {
+	c := newTestCache(t)
+	upstream := &fakeUpstream{}
+	ctx := context.Background()
+
+	first := &core.EmbeddingRequest{Model: "text-embedding-3-small", Input: []any{"a", "ccc"}}
+	if _, ok, err := c.Embed(ctx, testModel, nil, first, upstream.execute); !ok || err != nil {
+		t.Fatalf("Embed() ok = %v, err = %v", ok, err)
+	}
+
+	req := &core.EmbeddingRequest{Model: "text-embedding-3-small", Input: []any{"bb", "a", "dddd", "ccc"}}
+	result, ok, err := c.Embed(ctx, testModel, nil, req, upstream.execute)
+	if !ok || err != nil {
+		t.Fatalf("Embed() ok = %v, err = %v", ok, err)
+	}
+	if result.Hits != 2 || result.Misses != 2 || !result.Upstream() {
+		t.Fatalf("hits = %d, misses = %d, want 2 and 2", result.Hits, result.Misses)
+	}
+	if want := []string{"bb", "dddd"}; !reflect.DeepEqual(upstream.inputs[1], want) {
+		t.Fatalf("upstream inputs = %v, want only the misses %v", upstream.inputs[1], want)
+	}
+	if want := []string{"0:[2]", "1:[1]", "2:[4]", "3:[3]"}; !reflect.DeepEqual(embeddings(result.Response), want) {
+		t.Fatalf("embeddings = %v, want %v", embeddings(result.Response), want)
+	}
+	if result.Response.Usage.PromptTokens != 2 {
+		t.Fatalf("usage = %+v, want the upstream portion only", result.Response.Usage)
+	}
+	if result.Response.Model != "text-embedding-3-small" || result.Response.Provider != "openai" {
+		t.Fatalf("model = %q, provider = %q", result.Response.Model, result.Response.Provider)
+	}
+
+	full, _, err := c.Embed(ctx, testModel, nil, req, upstream.execute)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if full.Hits != 4 || full.Misses != 0 || full.Upstream() || upstream.calls != 2 {
+		t.Fatalf("full hit: hits = %d, misses = %d, upstream calls = %d", full.Hits, full.Misses, upstream.calls)
+	}
+	if full.Response.Usage != (core.EmbeddingUsage{}) || full.Response.Model != "text-embedding-3-small" {
+		t.Fatalf("full hit response = %+v", full.Response)
+	}
+	if !reflect.DeepEqual(embeddings(full.Response), embeddings(result.Response)) {
+		t.Fatalf("full hit embeddings = %v, want %v", embeddings(full.Response), embeddings(result.Response))
+	}
+}
+
+func TestEmbed_ConcurrentIdenticalBatchesShareOneUpstreamCall(t *testing.T) {
+	c := newTestCache(t)
+	upstream := &fakeUpstream{delay: 50 * time.Millisecond}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	var misses atomic.Int64
+	results := make([]*Result, callers)
+	for i := range callers {
+		wg.Go(func() {
+			req := &core.EmbeddingRequest{Model: "text-embedding-3-small", Input: []any{"x", "yy"}}
+			result, _, err := c.Embed(context.Background(), testModel, nil, req, upstream.execute)
+			if err != nil {
+				t.Errorf("Embed() error = %v", err)
+				return
+			}
+			misses.Add(int64(result.Misses))
+			results[i] = result
+		})
+	}
+	wg.Wait()
+
+	if upstream.calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1", upstream.calls)
+	}
+	if misses.Load() != 2 {
+		t.Fatalf("total misses = %d, want the 2 inputs of one upstream call", misses.Load())
+	}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if want := []string{"0:[1]", "1:[2]"}; !reflect.DeepEqual(embeddings(result.Response), want) {
+			t.Fatalf("embeddings = %v, want %v", embeddings(result.Response), want)
+		}
+		if !result.Upstream() && result.Response.Usage != (core.EmbeddingUsage{}) {
+			t.Fatalf("shared result carries usage %+v", result.Response.Usage)
+		}
+	}
+}
+
+func TestEmbed_KeyIncludesModelDimensionsAndEncoding(t *testing.T) {
+	c := newTestCache(t)
+	upstream := &fakeUpstream{}
+	ctx := context.Background()
+	dims := 256
+
+	for _, tt := range []struct {
+		model string
+		req   *core.EmbeddingRequest
+	}{
+		{testModel, &core.EmbeddingRequest{Input: "hello"}},
+		{"openai/text-embedding-3-large", &core.EmbeddingRequest{Input: "hello"}},
+		{testModel, &core.EmbeddingRequest{Input: "hello", Dimensions: &dims}},
+		{testModel, &core.EmbeddingRequest{Input: "hello", EncodingFormat: "base64"}},
+	} {
+		result, _, err := c.Embed(ctx, tt.model, nil, tt.req, upstream.execute)
+		if err != nil || result.Misses != 1 {
+			t.Fatalf("Embed(%s, %+v) misses = %d, err = %v, want a miss", tt.model, tt.req, result.Misses, err)
+		}
+	}
+	result, _, _ := c.Embed(ctx, testModel, nil, &core.EmbeddingRequest{Input: "hello", EncodingFormat: "float"}, upstream.execute)
+	if result.Hits != 1 {
+		t.Fatal("explicit float encoding missed the default encoding entry")
+	}
+	if len(result.Response.Data) != 1 || result.Response.Data[0].Index != 0 {
+		t.Fatalf("string input response = %+v", result.Response.Data)
+	}
+}
+
+func TestEmbed_SkipsUncacheableRequests(t *testing.T) {
+	c := newTestCache(t)
+	upstream := &fakeUpstream{}
+
+	for _, req := range []*core.EmbeddingRequest{
+		{Input: []any{float64(1), float64(2)}},
+		{Input: []any{}},
+	} {
+		if _, ok, _ := c.Embed(context.Background(), testModel, nil, req, upstream.execute); ok {
+			t.Fatalf("Embed(%v) applied the cache to an uncacheable request", req.Input)
+		}
+	}
+	if upstream.calls != 0 {
+		t.Fatalf("upstream calls = %d, want 0", upstream.calls)
+	}
+}
+
+func TestEmbed_PerModelSettings(t *testing.T) {
+	off, on := false, true
+	c := New(config.EmbeddingCacheConfig{
+		MaxEntries: 10,
+		Models: map[string]config.EmbeddingCacheModelConfig{
+			"small":   {Enabled: &on},
+			testModel: {Enabled: &off},
+		},
+	})
+	if c == nil {
+		t.Fatal("New() returned nil with a model enabled")
+	}
+	upstream := &fakeUpstream{}
+	req := &core.EmbeddingRequest{Input: "hello"}
+
+	if _, ok, _ := c.Embed(context.Background(), testModel, []string{"small"}, req, upstream.execute); !ok {
+		t.Fatal("cache skipped a model enabled by its requested name")
+	}
+	if _, ok, _ := c.Embed(context.Background(), testModel, []string{"other", testModel}, req, upstream.execute); ok {
+		t.Fatal("cache applied to a model switched off")
+	}
+	if _, ok, _ := c.Embed(context.Background(), "openai/large", nil, req, upstream.execute); ok {
+		t.Fatal("cache applied to an unlisted model while globally off")
+	}
+
+	if New(config.EmbeddingCacheConfig{Models: map[string]config.EmbeddingCacheModelConfig{"small": {Enabled: &off}}}) != nil {
+		t.Fatal("New() returned a cache with every model off")
+	}
+}
+
+func TestEmbed_RejectsMismatchedUpstreamResponse(t *testing.T) {
+	c := newTestCache(t)
+	execute := func(context.Context, *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+		return &core.EmbeddingResponse{Data: []core.EmbeddingData{{Embedding: json.RawMessage(`[1]`), Index: 5}}}, nil
+	}
+
+	_, ok, err := c.Embed(context.Background(), testModel, nil, &core.EmbeddingRequest{Input: []any{"a", "b"}}, execute)
+	if !ok || err == nil {
+		t.Fatalf("Embed() ok = %v, err = %v, want an error", ok, err)
+	}
+	if result, _, _ := c.Embed(context.Background(), testModel, nil, &core.EmbeddingRequest{Input: "a"}, (&fakeUpstream{}).execute); result.Hits != 0 {
+		t.Fatal("mismatched response was cached")
+	}
+}
@@ -0,0 +1,208 @@
+// Package experiments assigns requests for a model or alias to weighted A/B
+// variants.
+//
+// Assignment is deterministic: the experiment name, its salt and a stable
+// unit taken from the request (API key, conversation id or user field) are
+// hashed into a bucket, so the same unit always lands on the same variant
+// while the weights stay fixed. Requests without a unit, and every request
+// once an experiment is removed from config, get the requested model.
+package experiments
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// Unit selects the request value hashed for assignment.
+type Unit string
+
+const (
+	UnitAPIKey       Unit = "api_key"
+	UnitConversation Unit = "conversation"
+	UnitUser         Unit = "user"
+)
+
+// ParseUnit parses a unit name. An empty value selects UnitAPIKey.
+func ParseUnit(value string) (Unit, bool) {
+	switch u := Unit(strings.ToLower(strings.TrimSpace(value))); u {
+	case "":
+		return UnitAPIKey, true
+	case UnitAPIKey, UnitConversation, UnitUser:
+		return u, true
+	default:
+		return "", false
+	}
+}
+
+// Experiment is one validated experiment definition.
+type Experiment struct {
+	Name     string
+	Model    string
+	Unit     Unit
+	Salt     string
+	Variants []*Variant
+
+	totalWeight uint64
+}
+
+// Variant is one arm of an experiment. A variant without a model is the
+// control and keeps the requested selector.
+type Variant struct {
+	Name     string
+	Model    string
+	Provider string
+	Weight   int
+
+	assignments atomic.Int64
+}
+
+// Control reports whether the variant keeps the requested model.
+func (v *Variant) Control() bool {
+	return v.Model == ""
+}
+
+// Service holds the configured experiments keyed by their target model.
+type Service struct {
+	experiments []*Experiment
+	byModel     map[string]*Experiment
+}
+
+// New validates cfgs and builds a Service. It returns nil when no
+// experiments are configured.
+func New(cfgs []config.ExperimentConfig) (*Service, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+
+	s := &Service{byModel: make(map[string]*Experiment, len(cfgs))}
+	names := make(map[string]struct{}, len(cfgs))
+	for i, cfg := range cfgs {
+		experiment, err := newExperiment(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("experiments[%d]: %w", i, err)
+		}
+		if _, exists := names[experiment.Name]; exists {
+			return nil, fmt.Errorf("experiments[%d]: duplicate experiment name %q", i, experiment.Name)
+		}
+		if other, exists := s.byModel[experiment.Model]; exists {
+			return nil, fmt.Errorf("experiments[%d]: model %q is already targeted by experiment %q", i, experiment.Model, other.Name)
+		}
+		names[experiment.Name] = struct{}{}
+		s.byModel[experiment.Model] = experiment
+		s.experiments = append(s.experiments, experiment)
+	}
+	return s, nil
+}
+
+func newExperiment(cfg config.ExperimentConfig) (*Experiment, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	model := strings.TrimSpace(cfg.Model)
+	if model == "" {
+		return nil, fmt.Errorf("experiment %q: model is required", name)
+	}
+	unit, ok := ParseUnit(cfg.Unit)
+	if !ok {
+		return nil, fmt.Errorf("experiment %q: unknown unit %q, expected api_key, conversation or user", name, cfg.Unit)
+	}
+	if len(cfg.Variants) < 2 {
+		return nil, fmt.Errorf("experiment %q: at least two variants are required", name)
+	}
+
+	experiment := &Experiment{
+		Name:  name,
+		Model: model,
+		Unit:  unit,
+		Salt:  cfg.Salt,
+	}
+	variantNames := make(map[string]struct{}, len(cfg.Variants))
+	for _, variantCfg := range cfg.Variants {
+		variant := &Variant{
+			Name:     strings.TrimSpace(variantCfg.Name),
+			Model:    strings.TrimSpace(variantCfg.Model),
+			Provider: strings.TrimSpace(variantCfg.Provider),
+			Weight:   variantCfg.Weight,
+		}
+		if variant.Name == "" {
+			return nil, fmt.Errorf("experiment %q: variant name is required", name)
+		}
+		if _, exists := variantNames[variant.Name]; exists {
+			return nil, fmt.Errorf("experiment %q: duplicate variant name %q", name, variant.Name)
+		}
+		if variant.Weight < 0 {
+			return nil, fmt.Errorf("experiment %q: variant %q has a negative weight", name, variant.Name)
+		}
+		if variant.Control() && variant.Provider != "" {
+			return nil, fmt.Errorf("experiment %q: variant %q sets a provider without a model", name, variant.Name)
+		}
+		variantNames[variant.Name] = struct{}{}
+		experiment.Variants = append(experiment.Variants, variant)
+		experiment.totalWeight += uint64(variant.Weight)
+	}
+	if experiment.totalWeight == 0 {
+		return nil, fmt.Errorf("experiment %q: variant weights must add up to more than zero", name)
+	}
+	return experiment, nil
+}
+
+// Experiments returns the configured experiments in config order.
+func (s *Service) Experiments() []*Experiment {
+	if s == nil {
+		return nil
+	}
+	return s.experiments
+}
+
+// Lookup returns the experiment targeting model, if any.
+func (s *Service) Lookup(model string) (*Experiment, bool) {
+	if s == nil {
+		return nil, false
+	}
+	experiment, ok := s.byModel[strings.TrimSpace(model)]
+	return experiment, ok
+}
+
+// Pick returns the variant unit is assigned to. The same unit always gets
+// the same variant for a given name, salt and set of weights.
+func (e *Experiment) Pick(unit string) *Variant {
+	sum := sha256.Sum256([]byte(e.Name + "\x00" + e.Salt + "\x00" + unit))
+	bucket := binary.BigEndian.Uint64(sum[:8]) % e.totalWeight
+	for _, variant := range e.Variants {
+		weight := uint64(variant.Weight)
+		if bucket < weight {
+			return variant
+		}
+		bucket -= weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// Assign picks the variant for unit and records the assignment.
+func (e *Experiment) Assign(unit string) *Variant {
+	variant := e.Pick(unit)
+	variant.assignments.Add(1)
+	return variant
+}
+
+// Assignments returns how many requests this gateway process assigned to the
+// variant since startup.
+func (v *Variant) Assignments() int64 {
+	return v.assignments.Load()
+}
+
+// Selector returns the selector a request assigned to v resolves, given the
+// selector the client requested.
+func (v *Variant) Selector(requested core.RequestedModelSelector) core.RequestedModelSelector {
+	if v.Control() {
+		return requested
+	}
+	return core.NewRequestedModelSelector(v.Model, v.Provider)
+}
@@ -0,0 +1,214 @@
+package experiments
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+)
+
+func newTestService(t *testing.T, cfgs ...config.ExperimentConfig) *Service {
+	t.Helper()
+	service, err := New(cfgs)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return service
+}
+
+func splitExperiment(controlWeight, candidateWeight int) config.ExperimentConfig {
+	return config.ExperimentConfig{
+		Name:  "smart-model",
+		Model: "smart",
+		Salt:  "2026-10",
+		Variants: []config.ExperimentVariantConfig{
+			{Name: "control", Weight: controlWeight},
+			{Name: "candidate", Model: "claude-sonnet-4", Provider: "anthropic", Weight: candidateWeight},
+		},
+	}
+}
+
+func TestNew_NoExperiments(t *testing.T) {
+	service, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if service != nil {
+		t.Fatalf("New(nil) = %+v, want nil", service)
+	}
+	if _, ok := service.Lookup("smart"); ok {
+		t.Fatal("nil service should not match any model")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	control := config.ExperimentVariantConfig{Name: "control", Weight: 1}
+	candidate := config.ExperimentVariantConfig{Name: "candidate", Model: "gpt-5", Weight: 1}
+
+	tests := []struct {
+		name    string
+		cfgs    []config.ExperimentConfig
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			cfgs:    []config.ExperimentConfig{{Model: "smart", Variants: []config.ExperimentVariantConfig{control, candidate}}},
+			wantErr: "name is required",
+		},
+		{
+			name:    "missing model",
+			cfgs:    []config.ExperimentConfig{{Name: "e", Variants: []config.ExperimentVariantConfig{control, candidate}}},
+			wantErr: "model is required",
+		},
+		{
+			name:    "unknown unit",
+			cfgs:    []config.ExperimentConfig{{Name: "e", Model: "smart", Unit: "ip", Variants: []config.ExperimentVariantConfig{control, candidate}}},
+			wantErr: "unknown unit",
+		},
+		{
+			name:    "single variant",
+			cfgs:    []config.ExperimentConfig{{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{control}}},
+			wantErr: "at least two variants",
+		},
+		{
+			name:    "duplicate variant",
+			cfgs:    []config.ExperimentConfig{{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{control, control}}},
+			wantErr: "duplicate variant name",
+		},
+		{
+			name: "negative weight",
+			cfgs: []config.ExperimentConfig{{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{
+				control, {Name: "candidate", Model: "gpt-5", Weight: -1},
+			}}},
+			wantErr: "negative weight",
+		},
+		{
+			name: "zero total weight",
+			cfgs: []config.ExperimentConfig{{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{
+				{Name: "control"}, {Name: "candidate", Model: "gpt-5"},
+			}}},
+			wantErr: "more than zero",
+		},
+		{
+			name: "provider without model",
+			cfgs: []config.ExperimentConfig{{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{
+				{Name: "control", Provider: "openai", Weight: 1}, candidate,
+			}}},
+			wantErr: "provider without a model",
+		},
+		{
+			name: "duplicate experiment",
+			cfgs: []config.ExperimentConfig{
+				{Name: "e", Model: "smart", Variants: []config.ExperimentVariantConfig{control, candidate}},
+				{Name: "e", Model: "fast", Variants: []config.ExperimentVariantConfig{control, candidate}},
+			},
+			wantErr: "duplicate experiment name",
+		},
+		{
+			name: "model targeted twice",
+			cfgs: []config.ExperimentConfig{
+				{Name: "a", Model: "smart", Variants: []config.ExperimentVariantConfig{control, candidate}},
+				{Name: "b", Model: "smart", Variants: []config.ExperimentVariantConfig{control, candidate}},
+			},
+			wantErr: "already targeted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfgs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPick_IsDeterministic(t *testing.T) {
+	experiment, _ := newTestService(t, splitExperiment(1, 1)).Lookup("smart")
+	again, _ := newTestService(t, splitExperiment(1, 1)).Lookup("smart")
+
+	for i := range 1000 {
+		unit := fmt.Sprintf("unit-%d", i)
+		first := experiment.Pick(unit)
+		if second := experiment.Pick(unit); second != first {
+			t.Fatalf("Pick(%q) changed from %q to %q", unit, first.Name, second.Name)
+		}
+		if rebuilt := again.Pick(unit); rebuilt.Name != first.Name {
+			t.Fatalf("Pick(%q) = %q after rebuilding the service, want %q", unit, rebuilt.Name, first.Name)
+		}
+	}
+}
+
+func TestPick_SaltReshufflesUnits(t *testing.T) {
+	experiment, _ := newTestService(t, splitExperiment(1, 1)).Lookup("smart")
+	resalted := splitExperiment(1, 1)
+	resalted.Salt = "2026-11"
+	other, _ := newTestService(t, resalted).Lookup("smart")
+
+	moved := 0
+	for i := range 1000 {
+		unit := fmt.Sprintf("unit-%d", i)
+		if experiment.Pick(unit).Name != other.Pick(unit).Name {
+			moved++
+		}
+	}
+	if moved < 400 || moved > 600 {
+		t.Fatalf("a new salt moved %d of 1000 units, want roughly half", moved)
+	}
+}
+
+func TestPick_FollowsWeights(t *testing.T) {
+	tests := []struct {
+		name      string
+		control   int
+		candidate int
+	}{
+		{name: "even split", control: 50, candidate: 50},
+		{name: "90/10 split", control: 90, candidate: 10},
+		{name: "1/3 split", control: 1, candidate: 3},
+	}
+
+	const units = 100000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			experiment, _ := newTestService(t, splitExperiment(tt.control, tt.candidate)).Lookup("smart")
+			counts := map[string]int{}
+			for i := range units {
+				counts[experiment.Pick(fmt.Sprintf("sk-synthetic-%d", i)).Name]++
+			}
+
+			want := float64(tt.candidate) / float64(tt.control+tt.candidate)
+			got := float64(counts["candidate"]) / units
+			if math.Abs(got-want) > 0.01 {
+				t.Fatalf("candidate share = %.4f, want %.4f ± 0.01 (counts %v)", got, want, counts)
+			}
+		})
+	}
+}
+
+func TestPick_ZeroWeightVariantNeverSelected(t *testing.T) {
+	experiment, _ := newTestService(t, splitExperiment(1, 0)).Lookup("smart")
+	for i := range 10000 {
+		if variant := experiment.Pick(fmt.Sprintf("unit-%d", i)); variant.Name != "control" {
+			t.Fatalf("Pick() = %q, want control for every unit", variant.Name)
+		}
+	}
+}
+
+func TestAssign_CountsAssignments(t *testing.T) {
+	experiment, _ := newTestService(t, splitExperiment(1, 1)).Lookup("smart")
+	for i := range 100 {
+		experiment.Assign(fmt.Sprintf("unit-%d", i))
+	}
+
+	var total int64
+	for _, variant := range experiment.Variants {
+		total += variant.Assignments()
+	}
+	if total != 100 {
+		t.Fatalf("total assignments = %d, want 100", total)
+	}
+}
@@ -0,0 +1,88 @@
+package experiments
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+)
+
+// ModelResolver is the selector resolver the experiment resolver wraps,
+// usually the alias service.
+type ModelResolver interface {
+	ResolveModel(requested core.RequestedModelSelector) (core.ModelSelector, bool, error)
+}
+
+// Resolver runs experiments ahead of the wrapped model resolver. It
+// implements gateway.ExperimentAssigner, so workflow resolution resolves the
+// assigned variant's model through the wrapped resolver.
+type Resolver struct {
+	service *Service
+	next    ModelResolver
+}
+
+// NewResolver wraps next with the experiments in service.
+func NewResolver(service *Service, next ModelResolver) *Resolver {
+	return &Resolver{service: service, next: next}
+}
+
+// ResolveModel delegates to the wrapped resolver.
+func (r *Resolver) ResolveModel(requested core.RequestedModelSelector) (core.ModelSelector, bool, error) {
+	if r == nil || r.next == nil {
+		selector, err := requested.Normalize()
+		return selector, false, err
+	}
+	return r.next.ResolveModel(requested)
+}
+
+// AssignExperiment replaces requested with the selector of its assigned
+// variant when requested targets an experiment and the request carries the
+// experiment's unit.
+func (r *Resolver) AssignExperiment(ctx context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ExperimentAssignment) {
+	if r == nil {
+		return requested, nil
+	}
+	experiment, ok := r.service.Lookup(requested.RequestedQualifiedModel())
+	if !ok {
+		return requested, nil
+	}
+	unit := unitValue(ctx, experiment.Unit)
+	if unit == "" {
+		return requested, nil
+	}
+
+	variant := experiment.Assign(unit)
+	return variant.Selector(requested), &core.ExperimentAssignment{
+		Experiment: experiment.Name,
+		Variant:    variant.Name,
+	}
+}
+
+// unitValue extracts the assignment unit from the inbound request snapshot.
+func unitValue(ctx context.Context, unit Unit) string {
+	snapshot := core.GetRequestSnapshot(ctx)
+	if snapshot == nil {
+		return ""
+	}
+	switch unit {
+	case UnitAPIKey:
+		token := strings.TrimSpace(strings.TrimPrefix(http.Header(snapshot.GetHeaders()).Get("Authorization"), "Bearer "))
+		if token != "" {
+			return token
+		}
+		return core.GetAuthKeyID(ctx)
+	case UnitConversation:
+		return strings.TrimSpace(http.Header(snapshot.GetHeaders()).Get(core.ConversationIDHeader))
+	case UnitUser:
+		body := snapshot.CapturedBodyView()
+		if len(body) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(gjson.GetBytes(body, "user").String())
+	default:
+		return ""
+	}
+}
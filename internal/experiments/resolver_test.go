@@ -0,0 +1,132 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func requestContext(headers map[string][]string, body string) context.Context {
+	snapshot := core.NewRequestSnapshot(http.MethodPost, "/v1/chat/completions", nil, nil, headers, "application/json", []byte(body), false, "req-1", nil)
+	return core.WithRequestSnapshot(context.Background(), snapshot)
+}
+
+func TestResolver_AssignsByAPIKey(t *testing.T) {
+	service := newTestService(t, splitExperiment(1, 1))
+	resolver := NewResolver(service, nil)
+	experiment, _ := service.Lookup("smart")
+	requested := core.NewRequestedModelSelector("smart", "")
+
+	seen := map[string]bool{}
+	for i := range 64 {
+		key := fmt.Sprintf("sk-test-%d", i)
+		ctx := requestContext(map[string][]string{"Authorization": {"Bearer " + key}}, `{}`)
+
+		selector, assignment := resolver.AssignExperiment(ctx, requested)
+		if assignment == nil || assignment.Experiment != "smart-model" {
+			t.Fatalf("AssignExperiment() assignment = %+v, want smart-model", assignment)
+		}
+		want := experiment.Pick(key)
+		if assignment.Variant != want.Name {
+			t.Fatalf("variant = %q, want %q", assignment.Variant, want.Name)
+		}
+		if want.Control() && selector != requested {
+			t.Fatalf("control selector = %+v, want requested %+v", selector, requested)
+		}
+		if !want.Control() && selector != core.NewRequestedModelSelector("claude-sonnet-4", "anthropic") {
+			t.Fatalf("candidate selector = %+v", selector)
+		}
+		seen[assignment.Variant] = true
+	}
+	if !seen["control"] || !seen["candidate"] {
+		t.Fatalf("expected both variants across 64 keys, got %v", seen)
+	}
+}
+
+func TestResolver_UnitSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		unit     string
+		ctx      context.Context
+		wantUnit string
+	}{
+		{
+			name:     "conversation header",
+			unit:     "conversation",
+			ctx:      requestContext(map[string][]string{http.CanonicalHeaderKey(core.ConversationIDHeader): {"conv-42"}}, `{}`),
+			wantUnit: "conv-42",
+		},
+		{
+			name:     "user field",
+			unit:     "user",
+			ctx:      requestContext(nil, `{"model":"smart","user":"alice"}`),
+			wantUnit: "alice",
+		},
+		{
+			name:     "auth key id without bearer token",
+			unit:     "api_key",
+			ctx:      core.WithAuthKeyID(requestContext(nil, `{}`), "key-7"),
+			wantUnit: "key-7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := splitExperiment(1, 1)
+			cfg.Unit = tt.unit
+			service := newTestService(t, cfg)
+			experiment, _ := service.Lookup("smart")
+
+			_, assignment := NewResolver(service, nil).AssignExperiment(tt.ctx, core.NewRequestedModelSelector("smart", ""))
+			if assignment == nil {
+				t.Fatal("AssignExperiment() returned no assignment")
+			}
+			if want := experiment.Pick(tt.wantUnit).Name; assignment.Variant != want {
+				t.Fatalf("variant = %q, want %q", assignment.Variant, want)
+			}
+		})
+	}
+}
+
+func TestResolver_MissingUnitKeepsRequestedModel(t *testing.T) {
+	cfg := splitExperiment(0, 1)
+	cfg.Unit = "user"
+	resolver := NewResolver(newTestService(t, cfg), nil)
+	requested := core.NewRequestedModelSelector("smart", "")
+
+	selector, assignment := resolver.AssignExperiment(requestContext(nil, `{"model":"smart"}`), requested)
+	if assignment != nil {
+		t.Fatalf("assignment = %+v, want nil without a unit", assignment)
+	}
+	if selector != requested {
+		t.Fatalf("selector = %+v, want requested %+v", selector, requested)
+	}
+}
+
+func TestResolver_UntargetedModelPassesThrough(t *testing.T) {
+	resolver := NewResolver(newTestService(t, splitExperiment(0, 1)), nil)
+	requested := core.NewRequestedModelSelector("gpt-4o", "openai")
+	ctx := requestContext(map[string][]string{"Authorization": {"Bearer sk-test"}}, `{}`)
+
+	selector, assignment := resolver.AssignExperiment(ctx, requested)
+	if assignment != nil || selector != requested {
+		t.Fatalf("AssignExperiment() = %+v, %+v; want requested selector and no assignment", selector, assignment)
+	}
+}
+
+func TestResolver_RemovedExperimentRevertsToRequestedModel(t *testing.T) {
+	service, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	requested := core.NewRequestedModelSelector("smart", "")
+	ctx := requestContext(map[string][]string{"Authorization": {"Bearer sk-test"}}, `{}`)
+
+	selector, assignment := NewResolver(service, nil).AssignExperiment(ctx, requested)
+	if assignment != nil || selector != requested {
+		t.Fatalf("AssignExperiment() = %+v, %+v; want requested selector and no assignment", selector, assignment)
+	}
+}
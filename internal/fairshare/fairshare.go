@@ -0,0 +1,261 @@
+// Package fairshare caps the requests in flight to a provider and divides
+// its slots across models with weighted fair queuing. While the provider has
+// free slots requests go straight through; once it is saturated, each freed
+// slot goes to the queued request with the smallest virtual finish tag, so
+// backlogged models receive slots in proportion to their weights and one
+// busy model cannot starve the others.
+package fairshare
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gomodel/config"
+)
+
+// ErrTimeout is returned by Acquire when a request queued for the max wait
+// without receiving a slot.
+var ErrTimeout = errors.New("concurrency queue wait exceeded max_wait")
+
+// Snapshot is the current state of a Scheduler.
+type Snapshot struct {
+	MaxInFlight int   `json:"max_in_flight"`
+	InFlight    int   `json:"in_flight"`
+	Waiting     int   `json:"waiting"`
+	MaxWaitMs   int64 `json:"max_wait_ms"`
+	// Queued and Rejected count requests that waited for a slot, or gave up
+	// after the max wait, since startup.
+	Queued   int64 `json:"queued"`
+	Rejected int64 `json:"rejected"`
+	// Models lists the models holding or waiting for slots right now.
+	Models []ModelSnapshot `json:"models,omitempty"`
+}
+
+// ModelSnapshot is one model's current allocation.
+type ModelSnapshot struct {
+	Model    string  `json:"model"`
+	Weight   float64 `json:"weight"`
+	InFlight int     `json:"in_flight"`
+	Waiting  int     `json:"waiting"`
+}
+
+// Scheduler holds the concurrency slots of one provider. A nil *Scheduler
+// never queues.
+type Scheduler struct {
+	mu          sync.Mutex
+	maxInFlight int
+	maxWait     time.Duration
+	weights     map[string]float64
+	inFlight    int
+	waiting     int
+	queued      int64
+	rejected    int64
+	// virtual is the finish tag of the last request granted from the queue.
+	virtual float64
+	// models holds the state of models with requests in flight or queued.
+	models map[string]*modelState
+}
+
+type modelState struct {
+	weight   float64
+	inFlight int
+	waiters  []*waiter
+	// lastTag is the finish tag of the model's most recently queued request.
+	lastTag float64
+}
+
+type waiter struct {
+	tag     float64
+	granted bool
+	ready   chan struct{}
+}
+
+// New returns a Scheduler for cfg, or nil when cfg is nil or sets no limit.
+func New(cfg *config.ConcurrencyConfig) *Scheduler {
+	if cfg == nil || cfg.MaxInFlight <= 0 {
+		return nil
+	}
+	s := &Scheduler{
+		maxInFlight: cfg.MaxInFlight,
+		maxWait:     cfg.MaxWait,
+		weights:     make(map[string]float64, len(cfg.ModelWeights)),
+		models:      make(map[string]*modelState),
+	}
+	if s.maxWait <= 0 {
+		s.maxWait = config.DefaultConcurrencyMaxWait
+	}
+	for model, weight := range cfg.ModelWeights {
+		if weight > 0 {
+			s.weights[model] = weight
+		}
+	}
+	return s
+}
+
+// Acquire takes a slot for a request to model, queueing while the provider
+// is saturated, and returns the function that gives the slot back. Calling
+// it more than once has no further effect. A request still queued after the
+// max wait fails with ErrTimeout, and a canceled ctx ends the wait early
+// with ctx's error.
+func (s *Scheduler) Acquire(ctx context.Context, model string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	s.mu.Lock()
+	m := s.model(model)
+	if s.inFlight < s.maxInFlight {
+		s.inFlight++
+		m.inFlight++
+		s.mu.Unlock()
+		return s.releaser(model), nil
+	}
+	w := &waiter{
+		tag:   max(s.virtual, m.lastTag) + 1/m.weight,
+		ready: make(chan struct{}),
+	}
+	m.lastTag = w.tag
+	m.waiters = append(m.waiters, w)
+	s.waiting++
+	s.queued++
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.maxWait)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return s.releaser(model), nil
+	case <-ctx.Done():
+		return nil, s.abandon(model, w, ctx.Err())
+	case <-timer.C:
+		return nil, s.abandon(model, w, ErrTimeout)
+	}
+}
+
+// abandon takes w out of model's queue and returns err. A slot granted in
+// the meantime is passed on instead.
+func (s *Scheduler) abandon(model string, w *waiter, err error) error {
+	s.mu.Lock()
+	if w.granted {
+		s.mu.Unlock()
+		s.release(model)
+		return err
+	}
+	defer s.mu.Unlock()
+
+	m := s.models[model]
+	for i, queued := range m.waiters {
+		if queued == w {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			break
+		}
+	}
+	s.waiting--
+	if errors.Is(err, ErrTimeout) {
+		s.rejected++
+	}
+	// Give back the share of an abandoned tail so the model's next request
+	// is not pushed back by it.
+	if n := len(m.waiters); n > 0 {
+		m.lastTag = m.waiters[n-1].tag
+	} else {
+		m.lastTag = s.virtual
+	}
+	s.forget(model, m)
+	return err
+}
+
+func (s *Scheduler) releaser(model string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.release(model) })
+	}
+}
+
+// release frees one of model's slots and hands it to the queued request with
+// the smallest finish tag.
+func (s *Scheduler) release(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.models[model]
+	m.inFlight--
+	s.inFlight--
+	s.forget(model, m)
+
+	var next *modelState
+	for _, candidate := range s.models {
+		if len(candidate.waiters) == 0 {
+			continue
+		}
+		if next == nil || candidate.waiters[0].tag < next.waiters[0].tag {
+			next = candidate
+		}
+	}
+	if next == nil {
+		return
+	}
+	w := next.waiters[0]
+	next.waiters[0] = nil
+	next.waiters = next.waiters[1:]
+	s.virtual = w.tag
+	s.waiting--
+	s.inFlight++
+	next.inFlight++
+	w.granted = true
+	close(w.ready)
+}
+
+// model returns the state of model, creating it on first use.
+func (s *Scheduler) model(model string) *modelState {
+	m, ok := s.models[model]
+	if !ok {
+		weight, ok := s.weights[model]
+		if !ok {
+			weight = 1
+		}
+		m = &modelState{weight: weight, lastTag: s.virtual}
+		s.models[model] = m
+	}
+	return m
+}
+
+// forget drops the state of a model with nothing in flight or queued.
+func (s *Scheduler) forget(model string, m *modelState) {
+	if m.inFlight == 0 && len(m.waiters) == 0 {
+		delete(s.models, model)
+	}
+}
+
+// Snapshot returns the current allocations and counters.
+func (s *Scheduler) Snapshot() *Snapshot {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := &Snapshot{
+		MaxInFlight: s.maxInFlight,
+		InFlight:    s.inFlight,
+		Waiting:     s.waiting,
+		MaxWaitMs:   s.maxWait.Milliseconds(),
+		Queued:      s.queued,
+		Rejected:    s.rejected,
+	}
+	for name, m := range s.models {
+		snapshot.Models = append(snapshot.Models, ModelSnapshot{
+			Model:    name,
+			Weight:   m.weight,
+			InFlight: m.inFlight,
+			Waiting:  len(m.waiters),
+		})
+	}
+	sort.Slice(snapshot.Models, func(i, j int) bool {
+		return snapshot.Models[i].Model < snapshot.Models[j].Model
+	})
+	return snapshot
+}
@@ -0,0 +1,205 @@
+package fairshare
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+func TestNew_NilWithoutLimit(t *testing.T) {
+	if New(nil) != nil || New(&config.ConcurrencyConfig{}) != nil {
+		t.Fatal("New returned a scheduler without max_in_flight")
+	}
+	var s *Scheduler
+	release, err := s.Acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("nil Acquire: %v", err)
+	}
+	release()
+	if s.Snapshot() != nil {
+		t.Fatal("nil scheduler returned a snapshot")
+	}
+}
+
+// queue acquires a slot for model in the background and sends model on
+// granted once it has one, holding the slot until it receives from release.
+func queue(t *testing.T, s *Scheduler, model string, granted chan<- string, release <-chan struct{}) {
+	t.Helper()
+	before := s.Snapshot().Waiting
+	go func() {
+		done, err := s.Acquire(context.Background(), model)
+		if err != nil {
+			t.Errorf("Acquire(%s): %v", model, err)
+			return
+		}
+		granted <- model
+		<-release
+		done()
+	}()
+	waitFor(t, func() bool { return s.Snapshot().Waiting == before+1 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAcquire_GrantsQueuedRequestsByWeight(t *testing.T) {
+	s := New(&config.ConcurrencyConfig{MaxInFlight: 1, ModelWeights: map[string]float64{"big": 2.5}})
+	hold, err := s.Acquire(context.Background(), "other")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	granted := make(chan string, 6)
+	release := make(chan struct{})
+	// small queues first, yet big gets 2.5 slots for each of small's.
+	for range 2 {
+		queue(t, s, "small", granted, release)
+	}
+	for range 4 {
+		queue(t, s, "big", granted, release)
+	}
+
+	snapshot := s.Snapshot()
+	if snapshot.InFlight != 1 || snapshot.Waiting != 6 || snapshot.Queued != 6 || len(snapshot.Models) != 3 {
+		t.Fatalf("snapshot = %+v, want 1 in flight and 6 waiting across 3 models", snapshot)
+	}
+
+	hold()
+	var order []string
+	for range 6 {
+		order = append(order, <-granted)
+		// Each grant holds the only slot until its request finishes.
+		release <- struct{}{}
+	}
+	want := []string{"big", "big", "small", "big", "big", "small"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("grant order = %v, want %v", order, want)
+		}
+	}
+	waitFor(t, func() bool { return s.Snapshot().InFlight == 0 })
+	if models := s.Snapshot().Models; len(models) != 0 {
+		t.Fatalf("idle models kept: %+v", models)
+	}
+}
+
+func TestAcquire_TimeoutAndCancel(t *testing.T) {
+	s := New(&config.ConcurrencyConfig{MaxInFlight: 1, MaxWait: 20 * time.Millisecond})
+	hold, err := s.Acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	if _, err := s.Acquire(context.Background(), "gpt-4o"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("saturated Acquire error = %v, want ErrTimeout", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Acquire(ctx, "gpt-4o"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled Acquire error = %v, want context.Canceled", err)
+	}
+
+	snapshot := s.Snapshot()
+	if snapshot.InFlight != 1 || snapshot.Waiting != 0 || snapshot.Queued != 2 || snapshot.Rejected != 1 {
+		t.Fatalf("snapshot = %+v, want 1 in flight, 2 queued and 1 rejected", snapshot)
+	}
+
+	// Releasing twice frees a single slot.
+	hold()
+	hold()
+	if got := s.Snapshot().InFlight; got != 0 {
+		t.Fatalf("in flight = %d, want 0", got)
+	}
+	release, err := s.Acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	release()
+}
+
+func TestAcquire_ConcurrentUseKeepsLimit(t *testing.T) {
+	s := New(&config.ConcurrencyConfig{MaxInFlight: 3, ModelWeights: map[string]float64{"a": 3}})
+	var (
+		mu      sync.Mutex
+		current int
+		peak    int
+		wg      sync.WaitGroup
+	)
+	for i := range 40 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model := "a"
+			if i%2 == 1 {
+				model = "b"
+			}
+			release, err := s.Acquire(context.Background(), model)
+			if err != nil {
+				t.Errorf("Acquire: %v", err)
+				return
+			}
+			mu.Lock()
+			current++
+			peak = max(peak, current)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			current--
+			mu.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if peak > 3 {
+		t.Fatalf("peak in flight = %d, want at most 3", peak)
+	}
+	if snapshot := s.Snapshot(); snapshot.InFlight != 0 || snapshot.Waiting != 0 {
+		t.Fatalf("snapshot = %+v, want idle", snapshot)
+	}
+}
+
+// BenchmarkAcquireRelease measures a scheduling decision with free slots.
+func BenchmarkAcquireRelease(b *testing.B) {
+	s := New(&config.ConcurrencyConfig{MaxInFlight: 64})
+	ctx := context.Background()
+	for b.Loop() {
+		release, err := s.Acquire(ctx, "gpt-4o")
+		if err != nil {
+			b.Fatal(err)
+		}
+		release()
+	}
+}
+
+// BenchmarkAcquireRelease_Saturated measures handing slots over through the
+// queue while eight models compete for four slots.
+func BenchmarkAcquireRelease_Saturated(b *testing.B) {
+	s := New(&config.ConcurrencyConfig{MaxInFlight: 4})
+	models := []string{"m0", "m1", "m2", "m3", "m4", "m5", "m6", "m7"}
+	ctx := context.Background()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			release, err := s.Acquire(ctx, models[i%len(models)])
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			release()
+			i++
+		}
+	})
+}
@@ -102,6 +102,8 @@ func LogBatchUsageFromBatchResults(
 			continue
 		}
 		entry.ID = deterministicBatchUsageID(stored.Batch, item, providerID)
+		entry.RequestedModel = strings.TrimSpace(item.Model)
+		entry.ServedModel = stringFromAny(payload["model"])
 		entry.UserPath = stored.UserPath
 
 		usageLogger.Write(entry)
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"testing"
 
 	batchstore "gomodel/internal/batch"
@@ -17,8 +18,9 @@ func (l *batchUsageCaptureLogger) Write(entry *usage.UsageEntry) {
 	l.entries = append(l.entries, entry)
 }
 
-func (l *batchUsageCaptureLogger) Config() usage.Config { return l.config }
-func (l *batchUsageCaptureLogger) Close() error         { return nil }
+func (l *batchUsageCaptureLogger) Config() usage.Config        { return l.config }
+func (l *batchUsageCaptureLogger) Flush(context.Context) error { return nil }
+func (l *batchUsageCaptureLogger) Close() error                { return nil }
 
 type staticBatchPricingResolver struct {
 	pricing *core.ModelPricing
@@ -0,0 +1,330 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// ContextWindowResolver reports the context window of a concrete model.
+type ContextWindowResolver interface {
+	// ResolveContextWindow returns the context window in tokens, or 0 when
+	// the window is unknown.
+	ResolveContextWindow(model, providerType string) int
+}
+
+// ContextOverflowConfig configures how translated chat requests whose
+// estimated prompt exceeds the model's context window are handled.
+// Per-request overrides arrive through RequestMeta.ContextOverflow.
+type ContextOverflowConfig struct {
+	// Strategy applies to models without an entry in ModelStrategies.
+	// An empty value behaves like core.ContextOverflowOff.
+	Strategy core.ContextOverflowStrategy
+	// ModelStrategies is keyed by "provider/model" or bare model ID.
+	ModelStrategies map[string]core.ContextOverflowStrategy
+	// Resolver supplies context windows. Without it no request is checked.
+	Resolver ContextWindowResolver
+}
+
+const (
+	// Rough prompt token estimate: four characters per token plus a fixed
+	// per-message overhead for role and framing tokens.
+	contextCharsPerToken     = 4
+	contextTokensPerMessage  = 4
+	contextTokensReplyPrimer = 3
+	// Image parts are counted at a flat rate, a high-detail 1024x1024 image
+	// on OpenAI, rather than by the length of their base64 payload.
+	contextTokensPerImage       = 765
+	contextOverflowMarkerFormat = "[%d earlier messages omitted to fit the context window]"
+)
+
+func (c ContextOverflowConfig) strategyFor(workflow *core.Workflow, model string, override core.ContextOverflowStrategy) core.ContextOverflowStrategy {
+	if override != "" {
+		return override
+	}
+	if providerName := ProviderNameFromWorkflow(workflow); providerName != "" {
+		if strategy, ok := c.ModelStrategies[providerName+"/"+model]; ok {
+			return strategy
+		}
+	}
+	if strategy, ok := c.ModelStrategies[model]; ok {
+		return strategy
+	}
+	if c.Strategy == "" {
+		return core.ContextOverflowOff
+	}
+	return c.Strategy
+}
+
+// applyContextOverflow checks a prepared chat request against the resolved
+// model's context window and applies the configured strategy when the
+// estimated prompt does not fit.
+func (o *InferenceOrchestrator) applyContextOverflow(prepared *PreparedChatRequest, meta RequestMeta) (*PreparedChatRequest, error) {
+	override, ok := core.ParseContextOverflowStrategy(string(meta.ContextOverflow))
+	if !ok {
+		return nil, core.NewInvalidRequestError(
+			fmt.Sprintf("invalid %s header %q: expected off, reject, truncate_oldest or middle_out", core.ContextOverflowHeader, meta.ContextOverflow), nil,
+		)
+	}
+
+	cfg := o.contextOverflow
+	if prepared == nil || prepared.Request == nil || cfg.Resolver == nil {
+		return prepared, nil
+	}
+	model := ResolvedModelFromWorkflow(prepared.Workflow, prepared.Request.Model)
+	strategy := cfg.strategyFor(prepared.Workflow, model, override)
+	if strategy == core.ContextOverflowOff {
+		return prepared, nil
+	}
+	providerType := ""
+	if prepared.Workflow != nil {
+		providerType = prepared.Workflow.ProviderType
+	}
+	window := cfg.Resolver.ResolveContextWindow(model, providerType)
+	if window <= 0 {
+		return prepared, nil
+	}
+
+	req, result, err := FitChatRequestToContextWindow(prepared.Request, window, strategy)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return prepared, nil
+	}
+	prepared.Request = req
+	prepared.Context = core.WithContextOverflow(prepared.Context, result)
+	return prepared, nil
+}
+
+// FitChatRequestToContextWindow estimates the prompt size of req and, when it
+// exceeds window minus the requested max_tokens, applies strategy. It returns
+// the request unchanged and a nil result when the prompt fits. Truncation
+// never removes system messages or the latest turn; when those alone do not
+// fit, the request is rejected.
+func FitChatRequestToContextWindow(req *core.ChatRequest, window int, strategy core.ContextOverflowStrategy) (*core.ChatRequest, *core.ContextOverflowResult, error) {
+	if req == nil || window <= 0 || strategy == core.ContextOverflowOff {
+		return req, nil, nil
+	}
+	limit := window
+	if req.MaxTokens != nil && *req.MaxTokens > 0 {
+		limit -= *req.MaxTokens
+	}
+	estimated := EstimateChatPromptTokens(req)
+	if estimated <= limit {
+		return req, nil, nil
+	}
+
+	result := &core.ContextOverflowResult{Strategy: strategy, EstimatedTokens: estimated, Limit: limit}
+	var messages []core.Message
+	switch strategy {
+	case core.ContextOverflowTruncateOldest:
+		messages, result.DroppedMessages = truncateOldestMessages(req, limit)
+	case core.ContextOverflowMiddleOut:
+		messages, result.DroppedMessages = truncateMiddleMessages(req, limit)
+	}
+	if messages == nil {
+		return nil, nil, contextOverflowError(estimated, limit, window, strategy)
+	}
+
+	fitted := *req
+	fitted.Messages = messages
+	return &fitted, result, nil
+}
+
+func contextOverflowError(estimated, limit, window int, strategy core.ContextOverflowStrategy) error {
+	message := fmt.Sprintf("estimated prompt of %d tokens exceeds the model's context window limit of %d tokens", estimated, limit)
+	if limit != window {
+		message = fmt.Sprintf("%s (%d-token window minus max_tokens)", message, window)
+	}
+	if strategy != core.ContextOverflowReject {
+		message += "; system messages and the latest turn do not fit even after truncation"
+	}
+	return core.NewInvalidRequestError(message, nil).
+		WithParam("messages").
+		WithCode("context_length_exceeded")
+}
+
+// EstimateChatPromptTokens returns a heuristic token count for the prompt of
+// req: message text and tool calls at four characters per token, a flat rate
+// per image part, plus framing overhead per message and the serialized tool
+// definitions.
+func EstimateChatPromptTokens(req *core.ChatRequest) int {
+	if req == nil {
+		return 0
+	}
+	total := contextTokensReplyPrimer + estimateToolDefinitionTokens(req.Tools)
+	for i := range req.Messages {
+		total += estimateMessageTokens(&req.Messages[i])
+	}
+	return total
+}
+
+func estimateMessageTokens(msg *core.Message) int {
+	chars := len(msg.Role) + len(core.ExtractTextContent(msg.Content))
+	for _, call := range msg.ToolCalls {
+		chars += len(call.Function.Name) + len(call.Function.Arguments)
+	}
+	return contextTokensPerMessage + ceilDiv(chars, contextCharsPerToken) + countImageParts(msg.Content)*contextTokensPerImage
+}
+
+func countImageParts(content any) int {
+	parts, ok := core.NormalizeContentParts(content)
+	if !ok {
+		return 0
+	}
+	images := 0
+	for _, part := range parts {
+		if part.ImageURL != nil {
+			images++
+		}
+	}
+	return images
+}
+
+func estimateToolDefinitionTokens(tools []map[string]any) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	encoded, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return ceilDiv(len(encoded), contextCharsPerToken)
+}
+
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
+
+// messageUnit is a run of messages that must be kept or dropped together: an
+// assistant message with tool calls and the tool results that answer it.
+type messageUnit struct {
+	start, end int // [start, end) into the original messages
+	system     bool
+	tokens     int
+}
+
+func groupMessageUnits(messages []core.Message) []messageUnit {
+	units := make([]messageUnit, 0, len(messages))
+	for i := 0; i < len(messages); {
+		unit := messageUnit{start: i, end: i + 1, system: isSystemRole(messages[i].Role)}
+		if len(messages[i].ToolCalls) > 0 {
+			for unit.end < len(messages) && messages[unit.end].Role == "tool" {
+				unit.end++
+			}
+		}
+		for j := unit.start; j < unit.end; j++ {
+			unit.tokens += estimateMessageTokens(&messages[j])
+		}
+		units = append(units, unit)
+		i = unit.end
+	}
+	return units
+}
+
+func isSystemRole(role string) bool {
+	role = strings.ToLower(strings.TrimSpace(role))
+	return role == "system" || role == "developer"
+}
+
+// droppableUnits returns the indexes of non-system units other than the last
+// one, oldest first.
+func droppableUnits(units []messageUnit) []int {
+	last := -1
+	for i := len(units) - 1; i >= 0; i-- {
+		if !units[i].system {
+			last = i
+			break
+		}
+	}
+	var droppable []int
+	for i, unit := range units {
+		if !unit.system && i != last {
+			droppable = append(droppable, i)
+		}
+	}
+	return droppable
+}
+
+// truncateOldestMessages drops the earliest droppable units until the prompt
+// fits. It returns nil messages when even dropping all of them is not enough.
+func truncateOldestMessages(req *core.ChatRequest, limit int) ([]core.Message, int) {
+	units := groupMessageUnits(req.Messages)
+	estimated := EstimateChatPromptTokens(req)
+	dropped := make(map[int]bool)
+	for _, idx := range droppableUnits(units) {
+		if estimated <= limit {
+			break
+		}
+		dropped[idx] = true
+		estimated -= units[idx].tokens
+	}
+	if estimated > limit {
+		return nil, 0
+	}
+	return keepUnits(req.Messages, units, dropped, -1, nil)
+}
+
+// truncateMiddleMessages keeps the first and the latest turns and drops
+// droppable units from the middle outwards. The dropped span is replaced by a
+// single marker message so the model knows the conversation was shortened.
+func truncateMiddleMessages(req *core.ChatRequest, limit int) ([]core.Message, int) {
+	units := groupMessageUnits(req.Messages)
+	candidates := droppableUnits(units)
+	if len(candidates) > 0 {
+		candidates = candidates[1:] // keep the first turn, which usually states the task
+	}
+
+	estimated := EstimateChatPromptTokens(req)
+	dropped := make(map[int]bool)
+	droppedMessages := 0
+	markerTokens := 0
+	for estimated+markerTokens > limit && len(candidates) > 0 {
+		mid := len(candidates) / 2
+		idx := candidates[mid]
+		candidates = append(candidates[:mid], candidates[mid+1:]...)
+		dropped[idx] = true
+		estimated -= units[idx].tokens
+		droppedMessages += units[idx].end - units[idx].start
+		marker := contextOverflowMarker(droppedMessages)
+		markerTokens = estimateMessageTokens(&marker)
+	}
+	if estimated+markerTokens > limit {
+		return nil, 0
+	}
+
+	markerAt := -1
+	for i := range units {
+		if dropped[i] {
+			markerAt = i
+			break
+		}
+	}
+	marker := contextOverflowMarker(droppedMessages)
+	return keepUnits(req.Messages, units, dropped, markerAt, &marker)
+}
+
+func contextOverflowMarker(dropped int) core.Message {
+	return core.Message{Role: "user", Content: fmt.Sprintf(contextOverflowMarkerFormat, dropped)}
+}
+
+// keepUnits rebuilds the message list without dropped units. When marker is
+// set it is inserted in place of the unit at markerAt.
+func keepUnits(messages []core.Message, units []messageUnit, dropped map[int]bool, markerAt int, marker *core.Message) ([]core.Message, int) {
+	kept := make([]core.Message, 0, len(messages))
+	removed := 0
+	for i, unit := range units {
+		if i == markerAt && marker != nil {
+			kept = append(kept, *marker)
+		}
+		if dropped[i] {
+			removed += unit.end - unit.start
+			continue
+		}
+		kept = append(kept, messages[unit.start:unit.end]...)
+	}
+	return kept, removed
+}
@@ -0,0 +1,307 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+type staticContextWindowResolver map[string]int
+
+func (r staticContextWindowResolver) ResolveContextWindow(model, _ string) int {
+	return r[model]
+}
+
+// turn returns a message whose role and text together are size*4 characters,
+// so it estimates to exactly size+4 tokens.
+func turn(role string, size int) core.Message {
+	return core.Message{Role: role, Content: strings.Repeat("x", size*4-len(role))}
+}
+
+func messageRoles(messages []core.Message) string {
+	roles := make([]string, len(messages))
+	for i, msg := range messages {
+		roles[i] = msg.Role
+	}
+	return strings.Join(roles, ",")
+}
+
+func requireContextLengthError(t *testing.T, err error) *core.GatewayError {
+	t.Helper()
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) {
+		t.Fatalf("err = %v, want *core.GatewayError", err)
+	}
+	if gatewayErr.HTTPStatusCode() != 400 || gatewayErr.Code == nil || *gatewayErr.Code != "context_length_exceeded" {
+		t.Fatalf("err = %+v, want 400 context_length_exceeded", gatewayErr)
+	}
+	return gatewayErr
+}
+
+func TestEstimateChatPromptTokens(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{turn("system", 10), turn("user", 20)}}
+	if got, want := EstimateChatPromptTokens(req), contextTokensReplyPrimer+14+24; got != want {
+		t.Fatalf("EstimateChatPromptTokens() = %d, want %d", got, want)
+	}
+
+	req.Messages = append(req.Messages, core.Message{
+		Role:      "assistant",
+		ToolCalls: []core.ToolCall{{Function: core.FunctionCall{Name: "lookup", Arguments: `{"q":"a"}`}}},
+	})
+	// "assistant" + "lookup" + {"q":"a"} is 24 characters.
+	if got, want := EstimateChatPromptTokens(req), contextTokensReplyPrimer+14+24+10; got != want {
+		t.Fatalf("EstimateChatPromptTokens() with tool call = %d, want %d", got, want)
+	}
+}
+
+func TestFitChatRequestToContextWindow_Boundary(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{turn("system", 10), turn("user", 100)}}
+	estimated := EstimateChatPromptTokens(req)
+
+	got, result, err := FitChatRequestToContextWindow(req, estimated, core.ContextOverflowReject)
+	if err != nil || result != nil || got != req {
+		t.Fatalf("at the limit: got %p result %+v err %v, want request unchanged", got, result, err)
+	}
+
+	_, _, err = FitChatRequestToContextWindow(req, estimated-1, core.ContextOverflowReject)
+	gatewayErr := requireContextLengthError(t, err)
+	if !strings.Contains(gatewayErr.Message, "121 tokens") || !strings.Contains(gatewayErr.Message, "limit of 120 tokens") {
+		t.Fatalf("message = %q, want estimated tokens and limit", gatewayErr.Message)
+	}
+}
+
+func TestFitChatRequestToContextWindow_ReservesMaxTokens(t *testing.T) {
+	maxTokens := 50
+	req := &core.ChatRequest{MaxTokens: &maxTokens, Messages: []core.Message{turn("user", 100)}}
+	estimated := EstimateChatPromptTokens(req)
+
+	if _, result, err := FitChatRequestToContextWindow(req, estimated+maxTokens, core.ContextOverflowReject); err != nil || result != nil {
+		t.Fatalf("prompt plus max_tokens at the window: result %+v err %v, want no action", result, err)
+	}
+	_, _, err := FitChatRequestToContextWindow(req, estimated+maxTokens-1, core.ContextOverflowReject)
+	gatewayErr := requireContextLengthError(t, err)
+	if !strings.Contains(gatewayErr.Message, "minus max_tokens") {
+		t.Fatalf("message = %q, want max_tokens reservation explained", gatewayErr.Message)
+	}
+}
+
+func TestFitChatRequestToContextWindow_TruncateOldestPreservesSystemMessages(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		turn("system", 20),
+		turn("user", 100),
+		turn("assistant", 100),
+		turn("developer", 20),
+		turn("user", 100),
+		turn("assistant", 100),
+		turn("user", 100),
+	}}
+	// Room for both system messages and the last two turns only.
+	window := contextTokensReplyPrimer + 2*24 + 2*104
+
+	got, result, err := FitChatRequestToContextWindow(req, window, core.ContextOverflowTruncateOldest)
+	if err != nil {
+		t.Fatalf("FitChatRequestToContextWindow() error = %v", err)
+	}
+	if roles := messageRoles(got.Messages); roles != "system,developer,assistant,user" {
+		t.Fatalf("roles = %s, want system,developer,assistant,user", roles)
+	}
+	if result.Strategy != core.ContextOverflowTruncateOldest || result.DroppedMessages != 3 || result.Limit != window {
+		t.Fatalf("result = %+v, want 3 dropped messages", result)
+	}
+	if result.EstimatedTokens != EstimateChatPromptTokens(req) {
+		t.Fatalf("EstimatedTokens = %d, want the pre-truncation estimate", result.EstimatedTokens)
+	}
+	if EstimateChatPromptTokens(got) > window {
+		t.Fatalf("truncated request still estimates %d tokens", EstimateChatPromptTokens(got))
+	}
+	if len(req.Messages) != 7 {
+		t.Fatalf("original request was modified: %d messages", len(req.Messages))
+	}
+}
+
+func TestFitChatRequestToContextWindow_TruncateOldestKeepsToolExchangesTogether(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		turn("system", 10),
+		turn("user", 10),
+		{Role: "assistant", ToolCalls: []core.ToolCall{{ID: "call_1", Function: core.FunctionCall{Name: "lookup", Arguments: "{}"}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: strings.Repeat("r", 400)},
+		turn("user", 10),
+	}}
+	// Dropping the first user turn alone is not enough; the tool exchange has
+	// to go, and the tool result must not be left without its call.
+	window := EstimateChatPromptTokens(req) - 20
+
+	got, result, err := FitChatRequestToContextWindow(req, window, core.ContextOverflowTruncateOldest)
+	if err != nil {
+		t.Fatalf("FitChatRequestToContextWindow() error = %v", err)
+	}
+	if roles := messageRoles(got.Messages); roles != "system,user" {
+		t.Fatalf("roles = %s, want system,user", roles)
+	}
+	if result.DroppedMessages != 3 {
+		t.Fatalf("DroppedMessages = %d, want 3", result.DroppedMessages)
+	}
+}
+
+func TestFitChatRequestToContextWindow_MiddleOutInsertsMarker(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		turn("system", 10),
+		{Role: "user", Content: "first task"},
+		turn("assistant", 100),
+		turn("user", 100),
+		turn("assistant", 100),
+		turn("user", 100),
+		turn("assistant", 100),
+		{Role: "user", Content: "latest question"},
+	}}
+	window := EstimateChatPromptTokens(req) - 250
+
+	got, result, err := FitChatRequestToContextWindow(req, window, core.ContextOverflowMiddleOut)
+	if err != nil {
+		t.Fatalf("FitChatRequestToContextWindow() error = %v", err)
+	}
+	if result.Strategy != core.ContextOverflowMiddleOut || result.DroppedMessages != 3 {
+		t.Fatalf("result = %+v, want 3 dropped messages", result)
+	}
+	if len(got.Messages) != 6 {
+		t.Fatalf("len(messages) = %d, want 6 (5 kept plus marker)", len(got.Messages))
+	}
+	if got.Messages[0].Role != "system" || got.Messages[1].Content != "first task" || got.Messages[5].Content != "latest question" {
+		t.Fatalf("messages = %s, want system, first and latest turns kept", messageRoles(got.Messages))
+	}
+	if marker := core.ExtractTextContent(got.Messages[3].Content); marker != "[3 earlier messages omitted to fit the context window]" {
+		t.Fatalf("marker = %q, want it in place of the dropped span", marker)
+	}
+	if EstimateChatPromptTokens(got) > window {
+		t.Fatalf("truncated request still estimates %d tokens", EstimateChatPromptTokens(got))
+	}
+}
+
+func TestFitChatRequestToContextWindow_RejectsWhenSystemAndLatestTurnDoNotFit(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{turn("system", 100), turn("user", 10), turn("user", 100)}}
+	window := contextTokensReplyPrimer + 104 + 104 - 1
+
+	for _, strategy := range []core.ContextOverflowStrategy{core.ContextOverflowTruncateOldest, core.ContextOverflowMiddleOut} {
+		_, _, err := FitChatRequestToContextWindow(req, window, strategy)
+		gatewayErr := requireContextLengthError(t, err)
+		if !strings.Contains(gatewayErr.Message, "even after truncation") {
+			t.Fatalf("%s: message = %q", strategy, gatewayErr.Message)
+		}
+	}
+}
+
+func TestApplyContextOverflow_StrategySelection(t *testing.T) {
+	workflow := &core.Workflow{
+		ProviderType: "openai",
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: "gpt-5", Provider: "openai"},
+			ProviderType:     "openai",
+			ProviderName:     "openai-eu",
+		},
+	}
+	newPrepared := func() *PreparedChatRequest {
+		return &PreparedChatRequest{
+			Context:  context.Background(),
+			Workflow: workflow,
+			Request: &core.ChatRequest{Model: "smart", Messages: []core.Message{
+				turn("user", 100), turn("assistant", 100), turn("user", 100),
+			}},
+		}
+	}
+	resolver := staticContextWindowResolver{"gpt-5": 250}
+
+	tests := []struct {
+		name      string
+		cfg       ContextOverflowConfig
+		header    core.ContextOverflowStrategy
+		want      core.ContextOverflowStrategy
+		wantError bool
+	}{
+		{name: "off by default", cfg: ContextOverflowConfig{}},
+		{name: "global strategy", cfg: ContextOverflowConfig{Strategy: core.ContextOverflowTruncateOldest}, want: core.ContextOverflowTruncateOldest},
+		{
+			name: "provider-qualified model strategy",
+			cfg: ContextOverflowConfig{
+				Strategy: core.ContextOverflowReject,
+				ModelStrategies: map[string]core.ContextOverflowStrategy{
+					"gpt-5":           core.ContextOverflowReject,
+					"openai-eu/gpt-5": core.ContextOverflowMiddleOut,
+				},
+			},
+			want: core.ContextOverflowMiddleOut,
+		},
+		{
+			name: "bare model strategy",
+			cfg: ContextOverflowConfig{
+				ModelStrategies: map[string]core.ContextOverflowStrategy{"gpt-5": core.ContextOverflowTruncateOldest},
+			},
+			want: core.ContextOverflowTruncateOldest,
+		},
+		{name: "header overrides config", cfg: ContextOverflowConfig{Strategy: core.ContextOverflowTruncateOldest}, header: "REJECT", wantError: true},
+		{name: "header disables handling", cfg: ContextOverflowConfig{Strategy: core.ContextOverflowReject}, header: "off"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Resolver = resolver
+			orchestrator := NewInferenceOrchestrator(InferenceConfig{ContextOverflow: tt.cfg})
+
+			prepared, err := orchestrator.applyContextOverflow(newPrepared(), RequestMeta{ContextOverflow: tt.header})
+			if tt.wantError {
+				requireContextLengthError(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyContextOverflow() error = %v", err)
+			}
+			result := core.GetContextOverflow(prepared.Context)
+			if tt.want == "" {
+				if result != nil || len(prepared.Request.Messages) != 3 {
+					t.Fatalf("result = %+v with %d messages, want request untouched", result, len(prepared.Request.Messages))
+				}
+				return
+			}
+			if result == nil || result.Strategy != tt.want {
+				t.Fatalf("result = %+v, want strategy %s", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyContextOverflow_RejectsInvalidHeader(t *testing.T) {
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{})
+	_, err := orchestrator.applyContextOverflow(&PreparedChatRequest{}, RequestMeta{ContextOverflow: "summarize"})
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != 400 || !strings.Contains(gatewayErr.Message, core.ContextOverflowHeader) {
+		t.Fatalf("err = %v, want 400 naming the header", err)
+	}
+}
+
+func TestApplyContextOverflow_SkipsModelsWithUnknownWindow(t *testing.T) {
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{ContextOverflow: ContextOverflowConfig{
+		Strategy: core.ContextOverflowReject,
+		Resolver: staticContextWindowResolver{},
+	}})
+	prepared := &PreparedChatRequest{
+		Context: context.Background(),
+		Request: &core.ChatRequest{Model: "unknown", Messages: []core.Message{turn("user", 1000)}},
+	}
+	got, err := orchestrator.applyContextOverflow(prepared, RequestMeta{})
+	if err != nil || got != prepared || core.GetContextOverflow(got.Context) != nil {
+		t.Fatalf("got %+v err %v, want request passed through", got, err)
+	}
+}
+
+func TestEstimateChatPromptTokens_CountsImagesAtFlatRate(t *testing.T) {
+	image := core.ContentPart{Type: "image_url", ImageURL: &core.ImageURLContent{URL: "data:image/png;base64," + strings.Repeat("A", 400_000)}}
+	req := &core.ChatRequest{Messages: []core.Message{{
+		Role:    "user",
+		Content: []core.ContentPart{{Type: "text", Text: strings.Repeat("x", 12)}, image, image},
+	}}}
+	// "user" + 12 characters of text is 4 tokens; the base64 payload is not text.
+	if got, want := EstimateChatPromptTokens(req), contextTokensReplyPrimer+contextTokensPerMessage+4+2*contextTokensPerImage; got != want {
+		t.Fatalf("EstimateChatPromptTokens() = %d, want %d", got, want)
+	}
+}
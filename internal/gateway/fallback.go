@@ -19,6 +19,20 @@ func (o *InferenceOrchestrator) FallbackSelectors(workflow *core.Workflow) []cor
 	return o.fallbackResolver.ResolveFallbacks(workflow.Resolution, workflow.Endpoint.Operation)
 }
 
+// allowsDataResidency reports whether the provider serving selector satisfies
+// the data residency requirement in ctx.
+func (o *InferenceOrchestrator) allowsDataResidency(ctx context.Context, selector core.ModelSelector) bool {
+	residency := core.GetDataResidency(ctx)
+	if residency == "" {
+		return true
+	}
+	checker, ok := o.provider.(ResidencyModelResolver)
+	if !ok {
+		return true
+	}
+	return checker.SatisfiesDataResidency(selector, residency)
+}
+
 // ProviderTypeForSelector returns the provider type for a selector.
 func (o *InferenceOrchestrator) ProviderTypeForSelector(selector core.ModelSelector, fallback string) string {
 	fallback = strings.TrimSpace(fallback)
@@ -59,6 +73,9 @@ func tryFallbackResponse[T any](
 		if o.modelAuthorizer != nil && !o.modelAuthorizer.AllowsModel(ctx, selector) {
 			continue
 		}
+		if !o.allowsDataResidency(ctx, selector) {
+			continue
+		}
 		qualified := selector.QualifiedModel()
 		providerType := o.ProviderTypeForSelector(selector, ProviderTypeFromWorkflow(workflow))
 		providerName := ResolvedProviderName(o.provider, selector, ProviderNameFromWorkflow(workflow))
@@ -150,6 +167,9 @@ func tryFallbackStream(
 		if o.modelAuthorizer != nil && !o.modelAuthorizer.AllowsModel(ctx, selector) {
 			continue
 		}
+		if !o.allowsDataResidency(ctx, selector) {
+			continue
+		}
 		qualified := selector.QualifiedModel()
 		providerType := o.ProviderTypeForSelector(selector, ProviderTypeFromWorkflow(workflow))
 		providerName := ResolvedProviderName(o.provider, selector, ProviderNameFromWorkflow(workflow))
@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// FirstTokenTimeoutCode is the error code of requests whose provider did not
+// start answering within the first-token deadline.
+const FirstTokenTimeoutCode = "first_token_timeout"
+
+// FirstTokenConfig bounds how long translated chat and Responses requests
+// wait for the provider to start answering, independently of the overall
+// HTTP timeout. Per-request overrides arrive through
+// core.RequestOptions.FirstTokenTimeout.
+type FirstTokenConfig struct {
+	// Timeout applies to requests without a provider or model entry. Zero
+	// turns the deadline off.
+	Timeout time.Duration
+	// Providers maps configured provider names to a deadline.
+	Providers map[string]time.Duration
+	// Models maps bare models ("gpt-4o") or provider-qualified selectors
+	// ("azure/gpt-4o") to a deadline; they win over Providers.
+	Models map[string]time.Duration
+}
+
+// timeoutFor returns the first-token deadline of a request for model served
+// by providerName. Zero means no deadline.
+func (c FirstTokenConfig) timeoutFor(ctx context.Context, model, providerName string) time.Duration {
+	if opts := core.GetRequestOptions(ctx); opts != nil && opts.FirstTokenTimeout != nil {
+		return *opts.FirstTokenTimeout
+	}
+	if providerName != "" {
+		if timeout, ok := c.Models[providerName+"/"+model]; ok {
+			return timeout
+		}
+	}
+	if timeout, ok := c.Models[model]; ok {
+		return timeout
+	}
+	if timeout, ok := c.Providers[providerName]; ok {
+		return timeout
+	}
+	return c.Timeout
+}
+
+// firstTokenDeadline returns the first-token deadline of a request for the
+// selector model and provider, with the name of the provider serving it.
+func (o *InferenceOrchestrator) firstTokenDeadline(ctx context.Context, model, provider string) (time.Duration, string) {
+	providerName := ResolvedProviderName(o.provider, core.ModelSelector{Model: model, Provider: provider}, provider)
+	return o.firstToken.timeoutFor(ctx, model, providerName), providerName
+}
+
+// errFirstTokenDeadline is the cancellation cause of a provider call whose
+// deadline fired.
+var errFirstTokenDeadline = errors.New("first-token deadline exceeded")
+
+// firstTokenTimeoutError is returned instead of the provider call's own
+// error when the deadline fired. Its 504 status makes it eligible for
+// fallback.
+func firstTokenTimeoutError(provider string, timeout time.Duration) *core.GatewayError {
+	message := fmt.Sprintf("provider did not start responding within %s", timeout)
+	return core.NewProviderError(provider, http.StatusGatewayTimeout, message, errFirstTokenDeadline).
+		WithCode(FirstTokenTimeoutCode)
+}
+
+// withFirstByteDeadline runs a non-streaming provider call with a deadline on
+// the first response byte from the provider. The deadline is disarmed once
+// the response starts arriving, so long responses are not cut off. Most
+// providers send nothing before the whole non-streaming response is ready,
+// so there the deadline bounds the full generation.
+func withFirstByteDeadline[Resp any](ctx context.Context, timeout time.Duration, providerName string, call func(context.Context) (Resp, error)) (Resp, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(timeout, func() { cancel(errFirstTokenDeadline) })
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { timer.Stop() },
+	})
+
+	resp, err := call(ctx)
+	timer.Stop()
+	if err != nil && errors.Is(context.Cause(ctx), errFirstTokenDeadline) {
+		var zero Resp
+		return zero, firstTokenTimeoutError(providerName, timeout)
+	}
+	return resp, err
+}
+
+// withFirstChunkDeadline opens a provider stream with a deadline on its first
+// chunk. The stream is returned only after the first bytes arrived, so a
+// stream that stays silent fails before anything reaches the client and can
+// still fall back to another model. The deadline is disarmed once data
+// flows; the returned stream replays the first chunk and ends the call's
+// context when closed.
+func withFirstChunkDeadline(ctx context.Context, timeout time.Duration, providerName string, call func(context.Context) (io.ReadCloser, error)) (io.ReadCloser, error) {
+	if timeout <= 0 {
+		return call(ctx)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(timeout, func() { cancel(errFirstTokenDeadline) })
+
+	stream, err := call(ctx)
+	if err != nil || stream == nil {
+		timer.Stop()
+		cancel(nil)
+		if err != nil && errors.Is(context.Cause(ctx), errFirstTokenDeadline) {
+			return nil, firstTokenTimeoutError(providerName, timeout)
+		}
+		return stream, err
+	}
+
+	first := make([]byte, 4096)
+	n, readErr := readFirstChunk(stream, first)
+	timer.Stop()
+	// A deadline that fired as the first bytes arrived has still ended the
+	// stream's context.
+	if errors.Is(context.Cause(ctx), errFirstTokenDeadline) {
+		_ = stream.Close()
+		cancel(nil)
+		return nil, firstTokenTimeoutError(providerName, timeout)
+	}
+	return &firstChunkStream{ReadCloser: stream, pending: first[:n], pendingErr: readErr, cancel: cancel}, nil
+}
+
+// readFirstChunk reads until stream returns data or an error.
+func readFirstChunk(stream io.Reader, buf []byte) (int, error) {
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// firstChunkStream replays the chunk read while waiting for the first token
+// before reading on from the provider stream.
+type firstChunkStream struct {
+	io.ReadCloser
+	pending    []byte
+	pendingErr error
+	cancel     context.CancelCauseFunc
+}
+
+func (s *firstChunkStream) Read(p []byte) (int, error) {
+	if len(s.pending) > 0 {
+		n := copy(p, s.pending)
+		s.pending = s.pending[n:]
+		return n, nil
+	}
+	if s.pendingErr != nil {
+		err := s.pendingErr
+		s.pendingErr = nil
+		return 0, err
+	}
+	return s.ReadCloser.Read(p)
+}
+
+func (s *firstChunkStream) Close() error {
+	err := s.ReadCloser.Close()
+	s.cancel(nil)
+	return err
+}
@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func TestFirstTokenConfigTimeoutFor(t *testing.T) {
+	cfg := FirstTokenConfig{
+		Timeout:   time.Second,
+		Providers: map[string]time.Duration{"openai": 2 * time.Second},
+		Models: map[string]time.Duration{
+			"gpt-4o":       3 * time.Second,
+			"azure/gpt-4o": 4 * time.Second,
+		},
+	}
+	override := 5 * time.Second
+	off := time.Duration(0)
+	tests := []struct {
+		name     string
+		opts     *core.RequestOptions
+		model    string
+		provider string
+		want     time.Duration
+	}{
+		{name: "global", model: "o3", provider: "anthropic", want: time.Second},
+		{name: "provider", model: "o3", provider: "openai", want: 2 * time.Second},
+		{name: "bare model wins over provider", model: "gpt-4o", provider: "openai", want: 3 * time.Second},
+		{name: "qualified model", model: "gpt-4o", provider: "azure", want: 4 * time.Second},
+		{name: "request option", opts: &core.RequestOptions{FirstTokenTimeout: &override}, model: "gpt-4o", provider: "azure", want: override},
+		{name: "request option off", opts: &core.RequestOptions{FirstTokenTimeout: &off}, model: "gpt-4o", provider: "azure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.opts != nil {
+				ctx = core.WithRequestOptions(ctx, tt.opts)
+			}
+			if got := cfg.timeoutFor(ctx, tt.model, tt.provider); got != tt.want {
+				t.Fatalf("timeoutFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
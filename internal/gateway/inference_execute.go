@@ -15,6 +15,13 @@ func (o *InferenceOrchestrator) ExecuteChatCompletion(ctx context.Context, workf
 	if err := o.validateProviderAndRequest(req != nil, "chat request is required"); err != nil {
 		return nil, err
 	}
+	names, err := serverToolsFor(o, ctx, workflow, req, req.Model, chatToolLoopSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		return executeToolLoopResult(o, ctx, workflow, req, names, requestID, endpoint, chatToolLoopSpec, chatExecutionSpec)
+	}
 	return executeTranslatedResult(o, ctx, workflow, req, requestID, endpoint, chatExecutionSpec)
 }
 
@@ -35,6 +42,19 @@ func (o *InferenceOrchestrator) StreamChatCompletion(ctx context.Context, workfl
 	if err := o.validateProviderAndRequest(req != nil, "chat request is required"); err != nil {
 		return nil, err
 	}
+	names, err := serverToolsFor(o, ctx, workflow, req, req.Model, chatToolLoopSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		loopReq := CloneChatRequestForStreamUsage(req)
+		loopReq.Stream, loopReq.StreamOptions = false, nil
+		resp, meta, err := runToolLoop(o, ctx, workflow, loopReq, names, chatToolLoopSpec)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResult{Stream: chatToolLoopStream(resp), Meta: meta}, nil
+	}
 	streamReq, providerType, providerName, usageModel := o.ResolveChatRoute(workflow, req)
 	stream, resolvedProviderType, resolvedProviderName, resolvedUsageModel, failoverModel, usedFallback, err := o.streamChatCompletion(ctx, workflow, streamReq, providerType, providerName, usageModel)
 	if err != nil {
@@ -57,6 +77,13 @@ func (o *InferenceOrchestrator) ExecuteResponses(ctx context.Context, workflow *
 	if err := o.validateProviderAndRequest(req != nil, "responses request is required"); err != nil {
 		return nil, err
 	}
+	names, err := serverToolsFor(o, ctx, workflow, req, req.Model, responsesToolLoopSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		return executeToolLoopResult(o, ctx, workflow, req, names, requestID, endpoint, responsesToolLoopSpec, responsesExecutionSpec)
+	}
 	return executeTranslatedResult(o, ctx, workflow, req, requestID, endpoint, responsesExecutionSpec)
 }
 
@@ -77,6 +104,19 @@ func (o *InferenceOrchestrator) StreamResponses(ctx context.Context, workflow *c
 	if err := o.validateProviderAndRequest(req != nil, "responses request is required"); err != nil {
 		return nil, err
 	}
+	names, err := serverToolsFor(o, ctx, workflow, req, req.Model, responsesToolLoopSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		loopReq := CloneResponsesRequestForSelector(req, core.ModelSelector{Model: req.Model, Provider: req.Provider})
+		loopReq.Stream, loopReq.StreamOptions = false, nil
+		resp, meta, err := runToolLoop(o, ctx, workflow, loopReq, names, responsesToolLoopSpec)
+		if err != nil {
+			return nil, err
+		}
+		return &StreamResult{Stream: responsesToolLoopStream(resp), Meta: meta}, nil
+	}
 	providerType, providerName, usageModel := o.routeMetadata(workflow, req.Model)
 	if (workflow == nil || workflow.UsageEnabled()) && o.ShouldEnforceReturningUsageData() {
 		ctx = core.WithEnforceReturningUsageData(ctx, true)
@@ -108,7 +148,7 @@ func (o *InferenceOrchestrator) ExecuteEmbeddings(ctx context.Context, workflow
 	}
 	o.logUsage(ctx, workflow, resp.Model, providerType, providerName, func(pricing *core.ModelPricing) *usage.UsageEntry {
 		return usage.ExtractFromEmbeddingResponse(resp, requestID, providerType, endpoint, pricing)
-	})
+	}, nil)
 	return &EmbeddingResult{
 		Response: resp,
 		Meta: ExecutionMeta{
@@ -157,11 +197,11 @@ func (o *InferenceOrchestrator) routeMetadata(workflow *core.Workflow, fallbackM
 }
 
 // CanFastPathStreamingChatPassthrough reports whether a streaming chat request can bypass translation.
-func (o *InferenceOrchestrator) CanFastPathStreamingChatPassthrough(workflow *core.Workflow, req *core.ChatRequest) bool {
+func (o *InferenceOrchestrator) CanFastPathStreamingChatPassthrough(ctx context.Context, workflow *core.Workflow, req *core.ChatRequest) bool {
 	if req == nil || !req.Stream {
 		return false
 	}
-	if o.translatedRequestPatcher != nil || o.ShouldEnforceReturningUsageData() {
+	if o.translatedRequestPatcher != nil || o.ShouldEnforceReturningUsageData() || o.serverTools.requested(ctx) {
 		return false
 	}
 	if workflow == nil || workflow.Resolution == nil {
@@ -181,7 +221,99 @@ func (o *InferenceOrchestrator) CanFastPathStreamingChatPassthrough(workflow *co
 	if translatedStreamingChatBodyRewriteRequired(req) {
 		return false
 	}
+	if !core.ProviderSupportsRequestMetadata(providerType) && req.ExtraFields.Lookup("metadata") != nil {
+		// The raw body would carry metadata the provider does not accept.
+		return false
+	}
+	// The first-token deadline is enforced on the translated path.
+	if timeout, _ := o.firstTokenDeadline(ctx, req.Model, req.Provider); timeout > 0 {
+		return false
+	}
+	// The raw body lacks the max_tokens default set on req.
+	if core.GetMaxTokensDefault(ctx) != nil {
+		return false
+	}
+
+	return true
+}
+
+// StreamableRequestWorkflow resolves the workflow of a chat or Responses
+// request from the model and provider selectors read ahead of its body, and
+// reports whether the body can be sent to the provider exactly as received.
+// That holds when no stage of the translated path would change the request:
+// the selector must not be rewritten, the provider must accept OpenAI bodies
+// unchanged, and guardrails, moderation, fallbacks, max_tokens defaults and,
+// for chat, usage enforcement, prompt compression and context overflow must
+// not apply, and no first-token deadline may be set.
+// Resolution failures report false so the buffered path reports them.
+func (o *InferenceOrchestrator) StreamableRequestWorkflow(ctx context.Context, meta RequestMeta, model, provider string) (*core.Workflow, bool) {
+	if o.provider == nil || o.translatedRequestPatcher != nil || strings.TrimSpace(provider) != "" {
+		return nil, false
+	}
+	var moderationPath string
+	switch meta.Endpoint.Operation {
+	case core.OperationChatCompletions:
+		moderationPath = moderationChatPath
+	case core.OperationResponses:
+		moderationPath = moderationResponsesPath
+	default:
+		return nil, false
+	}
+
+	ctx = contextWithRequestID(ctx, meta.RequestID)
+	workflow, err := o.ensureTranslatedRequestWorkflow(ctx, meta.Workflow, meta.RequestID, meta.Endpoint, &model, &provider)
+	if err != nil || workflow == nil || translatedStreamingSelectorRewriteRequired(workflow.Resolution) {
+		return nil, false
+	}
+	// Other providers need fields such as metadata removed from the body.
+	if !core.ProviderSupportsRequestMetadata(workflow.ProviderType) || len(o.FallbackSelectors(workflow)) > 0 {
+		return nil, false
+	}
+	if timeout, _ := o.firstTokenDeadline(ctx, model, provider); timeout > 0 {
+		return nil, false
+	}
+	// The body may lack a limit the max_tokens default would set.
+	if _, ok := o.maxTokensDefaultFor(workflow, model); ok {
+		return nil, false
+	}
+	if o.moderation.enabledFor(ctx, moderationPath) && o.moderation.coversModel(ProviderNameFromWorkflow(workflow), model) {
+		return nil, false
+	}
+	if o.serverTools.requested(ctx) {
+		return nil, false
+	}
+	if meta.Endpoint.Operation == core.OperationChatCompletions && !o.chatBodyUnchanged(workflow, model, meta) {
+		return nil, false
+	}
+	return workflow, true
+}
 
+// chatBodyUnchanged reports whether the translated path would send a chat
+// request body for model as received, without knowing its fields.
+func (o *InferenceOrchestrator) chatBodyUnchanged(workflow *core.Workflow, model string, meta RequestMeta) bool {
+	if o.ShouldEnforceReturningUsageData() {
+		return false
+	}
+	// O-series requests may carry max_tokens or temperature, which are rewritten.
+	if oSeriesModel(model) {
+		return false
+	}
+	override, ok := core.ParseContextOverflowStrategy(string(meta.ContextOverflow))
+	if !ok {
+		return false
+	}
+	if o.contextOverflow.Resolver != nil && o.contextOverflow.strategyFor(workflow, model, override) != core.ContextOverflowOff {
+		return false
+	}
+	passes, err := core.ParsePromptCompressionPasses(meta.PromptCompression)
+	if err != nil {
+		return false
+	}
+	for _, enabled := range o.promptCompression.passesFor(workflow, model, passes) {
+		if enabled {
+			return false
+		}
+	}
 	return true
 }
 
@@ -206,9 +338,12 @@ func translatedStreamingChatBodyRewriteRequired(req *core.ChatRequest) bool {
 		return true
 	}
 
-	model := strings.ToLower(strings.TrimSpace(req.Model))
-	oSeries := len(model) >= 2 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9'
-	return oSeries && (req.MaxTokens != nil || req.Temperature != nil)
+	return oSeriesModel(req.Model) && (req.MaxTokens != nil || req.Temperature != nil)
+}
+
+func oSeriesModel(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	return len(model) >= 2 && model[0] == 'o' && model[1] >= '0' && model[1] <= '9'
 }
 
 func (o *InferenceOrchestrator) executeChatCompletion(
@@ -261,6 +396,7 @@ type translatedExecutionSpec[Req any, Resp any, Result any] struct {
 	execute func(*InferenceOrchestrator, context.Context, *core.Workflow, Req) (Resp, string, string, string, bool, error)
 	model   func(Resp) string
 	usage   func(Resp, string, string, string, *core.ModelPricing) *usage.UsageEntry
+	content func(Resp) usage.ContentFunc
 	build   func(Resp, ExecutionMeta) Result
 }
 
@@ -270,6 +406,7 @@ var chatExecutionSpec = translatedExecutionSpec[*core.ChatRequest, *core.ChatRes
 	usage: func(resp *core.ChatResponse, requestID, providerType, endpoint string, pricing *core.ModelPricing) *usage.UsageEntry {
 		return usage.ExtractFromChatResponse(resp, requestID, providerType, endpoint, pricing)
 	},
+	content: usage.ChatResponseContent,
 	build: func(resp *core.ChatResponse, meta ExecutionMeta) *ChatCompletionResult {
 		return &ChatCompletionResult{Response: resp, Meta: meta}
 	},
@@ -281,6 +418,7 @@ var responsesExecutionSpec = translatedExecutionSpec[*core.ResponsesRequest, *co
 	usage: func(resp *core.ResponsesResponse, requestID, providerType, endpoint string, pricing *core.ModelPricing) *usage.UsageEntry {
 		return usage.ExtractFromResponsesResponse(resp, requestID, providerType, endpoint, pricing)
 	},
+	content: usage.ResponsesContent,
 	build: func(resp *core.ResponsesResponse, meta ExecutionMeta) *ResponsesResult {
 		return &ResponsesResult{Response: resp, Meta: meta}
 	},
@@ -302,6 +440,7 @@ func executeTranslatedResult[Req any, Resp any, Result any](
 		func(resp Resp, providerType string, pricing *core.ModelPricing) *usage.UsageEntry {
 			return spec.usage(resp, requestID, providerType, endpoint, pricing)
 		},
+		spec.content,
 	)
 	if err != nil {
 		var zero Result
@@ -310,6 +449,29 @@ func executeTranslatedResult[Req any, Resp any, Result any](
 	return spec.build(resp, meta), nil
 }
 
+// executeToolLoopResult runs the server tool loop and records the summed
+// usage of its model calls as one entry of the request.
+func executeToolLoopResult[Req any, Resp any, Result any](
+	o *InferenceOrchestrator,
+	ctx context.Context,
+	workflow *core.Workflow,
+	req Req,
+	names []string,
+	requestID, endpoint string,
+	loop toolLoopSpec[Req, Resp],
+	spec translatedExecutionSpec[Req, Resp, Result],
+) (Result, error) {
+	resp, meta, err := runToolLoop(o, ctx, workflow, req, names, loop)
+	if err != nil {
+		var zero Result
+		return zero, err
+	}
+	o.logUsage(core.WithToolLoop(ctx, meta.ToolLoop), workflow, meta.Model, meta.ProviderType, meta.ProviderName, func(pricing *core.ModelPricing) *usage.UsageEntry {
+		return spec.usage(resp, requestID, meta.ProviderType, endpoint, pricing)
+	}, spec.content(resp))
+	return spec.build(resp, meta), nil
+}
+
 func executeWithUsage[Resp any](
 	o *InferenceOrchestrator,
 	ctx context.Context,
@@ -317,6 +479,7 @@ func executeWithUsage[Resp any](
 	execute func() (Resp, string, string, string, bool, error),
 	modelFromResponse func(Resp) string,
 	entry func(Resp, string, *core.ModelPricing) *usage.UsageEntry,
+	content func(Resp) usage.ContentFunc,
 ) (Resp, ExecutionMeta, error) {
 	resp, providerType, providerName, failoverModel, usedFallback, err := execute()
 	if err != nil {
@@ -326,7 +489,7 @@ func executeWithUsage[Resp any](
 	model := modelFromResponse(resp)
 	o.logUsage(ctx, workflow, model, providerType, providerName, func(pricing *core.ModelPricing) *usage.UsageEntry {
 		return entry(resp, providerType, pricing)
-	})
+	}, content(resp))
 	return resp, ExecutionMeta{
 		ProviderType:  providerType,
 		ProviderName:  providerName,
@@ -405,7 +568,10 @@ func (o *InferenceOrchestrator) validateProviderAndRequest(requestPresent bool,
 }
 
 func (o *InferenceOrchestrator) chatCompletionProviderCall(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
-	resp, err := o.provider.ChatCompletion(ctx, req)
+	timeout, providerName := o.firstTokenDeadline(ctx, req.Model, req.Provider)
+	resp, err := withFirstByteDeadline(ctx, timeout, providerName, func(ctx context.Context) (*core.ChatResponse, error) {
+		return o.provider.ChatCompletion(ctx, req)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -416,7 +582,10 @@ func (o *InferenceOrchestrator) chatCompletionProviderCall(ctx context.Context,
 }
 
 func (o *InferenceOrchestrator) responsesProviderCall(ctx context.Context, req *core.ResponsesRequest) (*core.ResponsesResponse, error) {
-	resp, err := o.provider.Responses(ctx, req)
+	timeout, providerName := o.firstTokenDeadline(ctx, req.Model, req.Provider)
+	resp, err := withFirstByteDeadline(ctx, timeout, providerName, func(ctx context.Context) (*core.ResponsesResponse, error) {
+		return o.provider.Responses(ctx, req)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -427,11 +596,17 @@ func (o *InferenceOrchestrator) responsesProviderCall(ctx context.Context, req *
 }
 
 func (o *InferenceOrchestrator) streamChatCompletionProviderCall(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
-	return o.provider.StreamChatCompletion(ctx, req)
+	timeout, providerName := o.firstTokenDeadline(ctx, req.Model, req.Provider)
+	return withFirstChunkDeadline(ctx, timeout, providerName, func(ctx context.Context) (io.ReadCloser, error) {
+		return o.provider.StreamChatCompletion(ctx, req)
+	})
 }
 
 func (o *InferenceOrchestrator) streamResponsesProviderCall(ctx context.Context, req *core.ResponsesRequest) (io.ReadCloser, error) {
-	return o.provider.StreamResponses(ctx, req)
+	timeout, providerName := o.firstTokenDeadline(ctx, req.Model, req.Provider)
+	return withFirstChunkDeadline(ctx, timeout, providerName, func(ctx context.Context) (io.ReadCloser, error) {
+		return o.provider.StreamResponses(ctx, req)
+	})
 }
 
 func emptyProviderResponseError(providerType string) *core.GatewayError {
@@ -19,6 +19,13 @@ type InferenceConfig struct {
 	UsageLogger              usage.LoggerInterface
 	PricingResolver          usage.PricingResolver
 	GuardrailsHash           string
+	ContextOverflow          ContextOverflowConfig
+	PromptCompression        PromptCompressionConfig
+	Moderation               ModerationConfig
+	PromptCache              PromptCacheMatcher
+	MaxTokensDefaults        MaxTokensDefaulter
+	FirstToken               FirstTokenConfig
+	ServerTools              ServerToolsConfig
 }
 
 // InferenceOrchestrator owns translated inference workflow resolution, request
@@ -33,6 +40,13 @@ type InferenceOrchestrator struct {
 	usageLogger              usage.LoggerInterface
 	pricingResolver          usage.PricingResolver
 	guardrailsHash           string
+	contextOverflow          ContextOverflowConfig
+	promptCompression        PromptCompressionConfig
+	moderation               ModerationConfig
+	promptCache              PromptCacheMatcher
+	maxTokensDefaults        MaxTokensDefaulter
+	firstToken               FirstTokenConfig
+	serverTools              ServerToolsConfig
 }
 
 // NewInferenceOrchestrator creates a translated inference orchestrator.
@@ -47,6 +61,13 @@ func NewInferenceOrchestrator(cfg InferenceConfig) *InferenceOrchestrator {
 		usageLogger:              cfg.UsageLogger,
 		pricingResolver:          cfg.PricingResolver,
 		guardrailsHash:           cfg.GuardrailsHash,
+		contextOverflow:          cfg.ContextOverflow,
+		promptCompression:        cfg.PromptCompression,
+		moderation:               cfg.Moderation,
+		promptCache:              cfg.PromptCache,
+		maxTokensDefaults:        cfg.MaxTokensDefaults,
+		firstToken:               cfg.FirstToken,
+		serverTools:              cfg.ServerTools,
 	}
 }
 
@@ -55,6 +76,12 @@ type RequestMeta struct {
 	RequestID string
 	Endpoint  core.EndpointDescriptor
 	Workflow  *core.Workflow
+	// ContextOverflow is the per-request strategy override taken from
+	// core.ContextOverflowHeader. Empty means use the configured strategy.
+	ContextOverflow core.ContextOverflowStrategy
+	// PromptCompression is the raw per-request pass list taken from
+	// core.PromptCompressionHeader. Empty means use the configured passes.
+	PromptCompression string
 }
 
 // PreparedChatRequest is a translated chat request ready for cache lookup or execution.
@@ -85,6 +112,9 @@ type ExecutionMeta struct {
 	Model         string
 	FailoverModel string
 	UsedFallback  bool
+	// ToolLoop describes the server-side tool loop that produced the
+	// response, nil when none ran.
+	ToolLoop *core.ToolLoopResult
 }
 
 // ChatCompletionResult is the non-streaming chat completion result.
@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"io"
+	"net/http"
 	"testing"
 
 	"gomodel/internal/core"
@@ -18,8 +19,9 @@ func (l *usageCaptureLogger) Write(entry *usage.UsageEntry) {
 	l.entries = append(l.entries, entry)
 }
 
-func (l *usageCaptureLogger) Config() usage.Config { return l.config }
-func (l *usageCaptureLogger) Close() error         { return nil }
+func (l *usageCaptureLogger) Config() usage.Config        { return l.config }
+func (l *usageCaptureLogger) Flush(context.Context) error { return nil }
+func (l *usageCaptureLogger) Close() error                { return nil }
 
 func TestInferenceOrchestratorLogUsageAssignsUserPathAndProviderName(t *testing.T) {
 	logger := &usageCaptureLogger{config: usage.Config{Enabled: true}}
@@ -28,7 +30,7 @@ func TestInferenceOrchestratorLogUsageAssignsUserPathAndProviderName(t *testing.
 
 	orchestrator.LogUsage(ctx, nil, "gpt-5-nano", "openai", "primary-openai", func(*core.ModelPricing) *usage.UsageEntry {
 		return &usage.UsageEntry{ID: "usage-1"}
-	})
+	}, nil)
 
 	if len(logger.entries) != 1 {
 		t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
@@ -57,13 +59,47 @@ func TestInferenceOrchestratorLogUsageSkipsWhenWorkflowDisablesUsage(t *testing.
 		},
 	}, "gpt-5-nano", "openai", "primary-openai", func(*core.ModelPricing) *usage.UsageEntry {
 		return &usage.UsageEntry{ID: "usage-1"}
-	})
+	}, nil)
 
 	if len(logger.entries) != 0 {
 		t.Fatalf("len(entries) = %d, want 0", len(logger.entries))
 	}
 }
 
+func TestInferenceOrchestratorLogUsageRecordsContentStats(t *testing.T) {
+	resp := &core.ChatResponse{Choices: []core.Choice{{
+		Index:   0,
+		Message: core.ResponseMessage{Role: "assistant", Content: "The capital of France is Paris, a city on the Seine."},
+	}}}
+
+	for _, tt := range []struct {
+		name         string
+		analysis     bool
+		wantLanguage string
+		wantChars    int
+	}{
+		{name: "enabled", analysis: true, wantLanguage: "en", wantChars: 52},
+		{name: "disabled", analysis: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &usageCaptureLogger{config: usage.Config{Enabled: true, ContentAnalysis: tt.analysis}}
+			orchestrator := NewInferenceOrchestrator(InferenceConfig{UsageLogger: logger})
+
+			orchestrator.LogUsage(context.Background(), nil, "gpt-5-nano", "openai", "primary-openai", func(*core.ModelPricing) *usage.UsageEntry {
+				return &usage.UsageEntry{ID: "usage-1"}
+			}, usage.ChatResponseContent(resp))
+
+			if len(logger.entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1", len(logger.entries))
+			}
+			entry := logger.entries[0]
+			if entry.ContentLanguage != tt.wantLanguage || entry.ContentChars != tt.wantChars {
+				t.Fatalf("content = %q with %d chars, want %q with %d", entry.ContentLanguage, entry.ContentChars, tt.wantLanguage, tt.wantChars)
+			}
+		})
+	}
+}
+
 func TestInferenceOrchestratorWithCacheRequestContextClearsInheritedGuardrailsHash(t *testing.T) {
 	orchestrator := NewInferenceOrchestrator(InferenceConfig{GuardrailsHash: "service-default"})
 	ctx := core.WithGuardrailsHash(context.Background(), "caller-hash")
@@ -158,3 +194,85 @@ func (p *providerTypeResolverStub) Supports(string) bool { return true }
 func (p *providerTypeResolverStub) GetProviderType(model string) string {
 	return p.providerTypes[model]
 }
+
+func TestStreamableRequestWorkflow(t *testing.T) {
+	provider := &providerTypeResolverStub{providerTypes: map[string]string{"gpt-5": "openai", "o3": "openai", "claude": "anthropic"}}
+	chat := core.DescribeEndpoint(http.MethodPost, "/v1/chat/completions")
+	responses := core.DescribeEndpoint(http.MethodPost, "/v1/responses")
+	tests := []struct {
+		name     string
+		cfg      InferenceConfig
+		meta     RequestMeta
+		model    string
+		provider string
+		want     bool
+	}{
+		{name: "chat", meta: RequestMeta{Endpoint: chat}, model: "gpt-5", want: true},
+		{name: "responses", meta: RequestMeta{Endpoint: responses}, model: "gpt-5", want: true},
+		{name: "provider needs conversion", meta: RequestMeta{Endpoint: chat}, model: "claude"},
+		{name: "provider field in body", meta: RequestMeta{Endpoint: chat}, model: "gpt-5", provider: "openai"},
+		{name: "o-series chat", meta: RequestMeta{Endpoint: chat}, model: "o3"},
+		{name: "o-series responses", meta: RequestMeta{Endpoint: responses}, model: "o3", want: true},
+		{name: "unsupported operation", meta: RequestMeta{Endpoint: core.DescribeEndpoint(http.MethodPost, "/v1/embeddings")}, model: "gpt-5"},
+		{
+			name:  "usage enforcement on chat",
+			cfg:   InferenceConfig{UsageLogger: &usageCaptureLogger{config: usage.Config{Enabled: true, EnforceReturningUsageData: true}}},
+			meta:  RequestMeta{Endpoint: chat},
+			model: "gpt-5",
+		},
+		{
+			name:  "usage enforcement on responses",
+			cfg:   InferenceConfig{UsageLogger: &usageCaptureLogger{config: usage.Config{Enabled: true, EnforceReturningUsageData: true}}},
+			meta:  RequestMeta{Endpoint: responses},
+			model: "gpt-5",
+			want:  true,
+		},
+		{
+			name:  "moderation in scope",
+			cfg:   InferenceConfig{Moderation: ModerationConfig{Moderator: &moderatorStub{}}},
+			meta:  RequestMeta{Endpoint: responses},
+			model: "gpt-5",
+		},
+		{
+			name:  "moderation out of scope",
+			cfg:   InferenceConfig{Moderation: ModerationConfig{Moderator: &moderatorStub{}, Models: []string{"claude"}}},
+			meta:  RequestMeta{Endpoint: responses},
+			model: "gpt-5",
+			want:  true,
+		},
+		{
+			name:  "context overflow",
+			cfg:   InferenceConfig{ContextOverflow: ContextOverflowConfig{Strategy: core.ContextOverflowReject, Resolver: staticContextWindowResolver{}}},
+			meta:  RequestMeta{Endpoint: chat},
+			model: "gpt-5",
+		},
+		{
+			name:  "context overflow turned off by header",
+			cfg:   InferenceConfig{ContextOverflow: ContextOverflowConfig{Strategy: core.ContextOverflowReject, Resolver: staticContextWindowResolver{}}},
+			meta:  RequestMeta{Endpoint: chat, ContextOverflow: core.ContextOverflowOff},
+			model: "gpt-5",
+			want:  true,
+		},
+		{name: "prompt compression header", meta: RequestMeta{Endpoint: chat, PromptCompression: "whitespace"}, model: "gpt-5"},
+		{name: "invalid prompt compression header", meta: RequestMeta{Endpoint: chat, PromptCompression: "zip"}, model: "gpt-5"},
+		{
+			name:  "prompt compression turned off by header",
+			cfg:   InferenceConfig{PromptCompression: PromptCompressionConfig{Passes: core.PromptCompressionPasses{core.PromptCompressionWhitespace: true}}},
+			meta:  RequestMeta{Endpoint: chat, PromptCompression: "off"},
+			model: "gpt-5",
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Provider = provider
+			workflow, ok := NewInferenceOrchestrator(tt.cfg).StreamableRequestWorkflow(context.Background(), tt.meta, tt.model, tt.provider)
+			if ok != tt.want {
+				t.Fatalf("StreamableRequestWorkflow() = %v, want %v", ok, tt.want)
+			}
+			if ok && (workflow == nil || workflow.ProviderType != "openai" || workflow.Resolution.ResolvedSelector.Model != tt.model) {
+				t.Fatalf("workflow = %+v, want the resolved openai workflow", workflow)
+			}
+		})
+	}
+}
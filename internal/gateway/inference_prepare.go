@@ -9,12 +9,54 @@ import (
 
 // PrepareChatRequest resolves workflow/model policy and applies translated request patching.
 func (o *InferenceOrchestrator) PrepareChatRequest(ctx context.Context, req *core.ChatRequest, meta RequestMeta) (*PreparedChatRequest, error) {
-	return prepareTranslated(o, ctx, req, meta, chatPrepareSpec)
+	var moderationText string
+	var check *moderationCheck
+	if req != nil {
+		moderationText = chatModerationText(req)
+		check = o.beginModeration(ctx, meta, moderationChatPath, moderationText, req.Provider, req.Model)
+	}
+	prepared, err := prepareTranslated(o, ctx, req, meta, chatPrepareSpec)
+	if err != nil {
+		check.stop()
+		return nil, err
+	}
+	prepared.Context, err = o.finishModeration(prepared.Context, check, moderationChatPath, moderationText, prepared.Workflow, prepared.Request.Model)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err = o.applyPromptCompression(prepared, meta)
+	if err != nil {
+		return nil, err
+	}
+	prepared = o.applyChatMaxTokensDefault(prepared)
+	prepared, err = o.applyContextOverflow(prepared, meta)
+	if err != nil {
+		return nil, err
+	}
+	prepared.Context = o.applyPromptCache(prepared.Context, prepared.Workflow, prepared.Request.Model, chatSystemPrompt(prepared.Request))
+	return prepared, nil
 }
 
 // PrepareResponsesRequest resolves workflow/model policy and applies translated request patching.
 func (o *InferenceOrchestrator) PrepareResponsesRequest(ctx context.Context, req *core.ResponsesRequest, meta RequestMeta) (*PreparedResponsesRequest, error) {
-	return prepareTranslated(o, ctx, req, meta, responsesPrepareSpec)
+	var moderationText string
+	var check *moderationCheck
+	if req != nil {
+		moderationText = responsesModerationText(req)
+		check = o.beginModeration(ctx, meta, moderationResponsesPath, moderationText, req.Provider, req.Model)
+	}
+	prepared, err := prepareTranslated(o, ctx, req, meta, responsesPrepareSpec)
+	if err != nil {
+		check.stop()
+		return nil, err
+	}
+	prepared.Context, err = o.finishModeration(prepared.Context, check, moderationResponsesPath, moderationText, prepared.Workflow, prepared.Request.Model)
+	if err != nil {
+		return nil, err
+	}
+	prepared = o.applyResponsesMaxTokensDefault(prepared)
+	prepared.Context = o.applyPromptCache(prepared.Context, prepared.Workflow, prepared.Request.Model, prepared.Request.Instructions)
+	return prepared, nil
 }
 
 // PrepareEmbeddingRequest resolves workflow/model policy for an embeddings request.
@@ -13,6 +13,29 @@ type ModelResolver interface {
 	ResolveModel(requested core.RequestedModelSelector) (core.ModelSelector, bool, error)
 }
 
+// ExperimentAssigner is implemented by model resolvers that run A/B
+// experiments. AssignExperiment returns the selector to resolve in place of
+// requested and the assignment, or requested unchanged and nil when the
+// request is not part of an experiment.
+type ExperimentAssigner interface {
+	AssignExperiment(ctx context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ExperimentAssignment)
+}
+
+// ModelGroupResolver is implemented by model resolvers that route model
+// groups. ResolveModelGroup returns the selector of the group member to
+// resolve in place of requested and the selection, or requested unchanged and
+// nil when requested does not target a group.
+type ModelGroupResolver interface {
+	ResolveModelGroup(ctx context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ModelGroupSelection, error)
+}
+
+// ResidencyModelResolver is implemented by providers that can restrict model
+// resolution to providers satisfying a data residency requirement.
+type ResidencyModelResolver interface {
+	ResolveModelForResidency(requested core.RequestedModelSelector, residency string) (core.ModelSelector, bool, error)
+	SatisfiesDataResidency(selector core.ModelSelector, residency string) bool
+}
+
 // FallbackResolver resolves alternate concrete model selectors for a translated
 // request after the primary selector has already been resolved.
 type FallbackResolver interface {
@@ -31,6 +54,26 @@ type WorkflowPolicyResolver interface {
 	Match(selector core.WorkflowSelector) (*core.ResolvedWorkflowPolicy, error)
 }
 
+// Moderator scores prompt text against core.ModerationCategories for the
+// moderation pre-check. Scores range from 0 to 1.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (map[string]float64, error)
+}
+
+// PromptCacheMatcher decides whether the system prompt of a request starts
+// with a cacheable prefix and remembers the request by ID for hit accounting.
+type PromptCacheMatcher interface {
+	Match(requestID, providerName, model, system string) (core.PromptCacheHint, bool)
+}
+
+// MaxTokensDefaulter supplies the output token limit set on translated
+// requests that do not set one.
+type MaxTokensDefaulter interface {
+	// DefaultMaxTokens returns the default for model served by the provider
+	// named providerName, or false when the provider's own default applies.
+	DefaultMaxTokens(providerName, model string) (core.MaxTokensDefault, bool)
+}
+
 // TranslatedRequestPatcher applies request-level transforms for translated
 // routes after workflow resolution has resolved the concrete execution selector.
 type TranslatedRequestPatcher interface {
@@ -0,0 +1,47 @@
+package gateway
+
+import "gomodel/internal/core"
+
+// maxTokensDefaultFor returns the max_tokens default for model on the
+// workflow's provider, or false when the provider's own default applies.
+func (o *InferenceOrchestrator) maxTokensDefaultFor(workflow *core.Workflow, model string) (core.MaxTokensDefault, bool) {
+	if o.maxTokensDefaults == nil {
+		return core.MaxTokensDefault{}, false
+	}
+	return o.maxTokensDefaults.DefaultMaxTokens(ProviderNameFromWorkflow(workflow), ResolvedModelFromWorkflow(workflow, model))
+}
+
+// applyChatMaxTokensDefault sets the configured default on a chat request
+// that sets neither max_tokens nor max_completion_tokens.
+func (o *InferenceOrchestrator) applyChatMaxTokensDefault(prepared *PreparedChatRequest) *PreparedChatRequest {
+	if prepared == nil || prepared.Request == nil || prepared.Request.MaxTokens != nil ||
+		prepared.Request.ExtraFields.Lookup("max_completion_tokens") != nil {
+		return prepared
+	}
+	applied, ok := o.maxTokensDefaultFor(prepared.Workflow, prepared.Request.Model)
+	if !ok {
+		return prepared
+	}
+	req := *prepared.Request
+	req.MaxTokens = &applied.Value
+	prepared.Request = &req
+	prepared.Context = core.WithMaxTokensDefault(prepared.Context, &applied)
+	return prepared
+}
+
+// applyResponsesMaxTokensDefault sets the configured default on a Responses
+// request without max_output_tokens.
+func (o *InferenceOrchestrator) applyResponsesMaxTokensDefault(prepared *PreparedResponsesRequest) *PreparedResponsesRequest {
+	if prepared == nil || prepared.Request == nil || prepared.Request.MaxOutputTokens != nil {
+		return prepared
+	}
+	applied, ok := o.maxTokensDefaultFor(prepared.Workflow, prepared.Request.Model)
+	if !ok {
+		return prepared
+	}
+	req := *prepared.Request
+	req.MaxOutputTokens = &applied.Value
+	prepared.Request = &req
+	prepared.Context = core.WithMaxTokensDefault(prepared.Context, &applied)
+	return prepared
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+type staticMaxTokensDefaulter map[string]int
+
+func (d staticMaxTokensDefaulter) DefaultMaxTokens(providerName, model string) (core.MaxTokensDefault, bool) {
+	if value, ok := d[providerName+"/"+model]; ok {
+		return core.MaxTokensDefault{Value: value, Source: core.MaxTokensDefaultModel}, true
+	}
+	if value, ok := d[model]; ok {
+		return core.MaxTokensDefault{Value: value, Source: core.MaxTokensDefaultModel}, true
+	}
+	return core.MaxTokensDefault{}, false
+}
+
+func TestApplyChatMaxTokensDefault(t *testing.T) {
+	workflow := &core.Workflow{
+		ProviderType: "anthropic",
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: "claude-sonnet-4", Provider: "anthropic"},
+			ProviderType:     "anthropic",
+			ProviderName:     "anthropic-eu",
+		},
+	}
+	explicit := 64
+	tests := []struct {
+		name        string
+		defaults    MaxTokensDefaulter
+		req         core.ChatRequest
+		wantTokens  *int
+		wantApplied bool
+	}{
+		{name: "no policy", req: core.ChatRequest{Model: "smart"}},
+		{
+			name:     "model without default",
+			defaults: staticMaxTokensDefaulter{"gpt-4o": 1000},
+			req:      core.ChatRequest{Model: "smart"},
+		},
+		{
+			name:        "resolved provider-qualified model",
+			defaults:    staticMaxTokensDefaulter{"anthropic-eu/claude-sonnet-4": 2000},
+			req:         core.ChatRequest{Model: "smart"},
+			wantTokens:  new(2000),
+			wantApplied: true,
+		},
+		{
+			name:       "explicit max_tokens wins",
+			defaults:   staticMaxTokensDefaulter{"claude-sonnet-4": 2000},
+			req:        core.ChatRequest{Model: "smart", MaxTokens: &explicit},
+			wantTokens: &explicit,
+		},
+		{
+			name:     "explicit max_completion_tokens wins",
+			defaults: staticMaxTokensDefaulter{"claude-sonnet-4": 2000},
+			req: core.ChatRequest{Model: "smart", ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+				"max_completion_tokens": json.RawMessage(`64`),
+			})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewInferenceOrchestrator(InferenceConfig{MaxTokensDefaults: tt.defaults})
+			req := tt.req
+			got := orchestrator.applyChatMaxTokensDefault(&PreparedChatRequest{
+				Context:  context.Background(),
+				Workflow: workflow,
+				Request:  &req,
+			})
+			switch {
+			case tt.wantTokens == nil && got.Request.MaxTokens != nil:
+				t.Fatalf("MaxTokens = %d, want unset", *got.Request.MaxTokens)
+			case tt.wantTokens != nil && (got.Request.MaxTokens == nil || *got.Request.MaxTokens != *tt.wantTokens):
+				t.Fatalf("MaxTokens = %v, want %d", got.Request.MaxTokens, *tt.wantTokens)
+			}
+			applied := core.GetMaxTokensDefault(got.Context)
+			if (applied != nil) != tt.wantApplied {
+				t.Fatalf("GetMaxTokensDefault() = %+v, want applied %v", applied, tt.wantApplied)
+			}
+			if req.MaxTokens != tt.req.MaxTokens {
+				t.Fatal("applyChatMaxTokensDefault() mutated the caller's request")
+			}
+		})
+	}
+}
+
+func TestApplyResponsesMaxTokensDefault(t *testing.T) {
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{MaxTokensDefaults: staticMaxTokensDefaulter{"gpt-4o": 1500}})
+
+	got := orchestrator.applyResponsesMaxTokensDefault(&PreparedResponsesRequest{
+		Context: context.Background(),
+		Request: &core.ResponsesRequest{Model: "gpt-4o"},
+	})
+	if got.Request.MaxOutputTokens == nil || *got.Request.MaxOutputTokens != 1500 {
+		t.Fatalf("MaxOutputTokens = %v, want 1500", got.Request.MaxOutputTokens)
+	}
+	if applied := core.GetMaxTokensDefault(got.Context); applied == nil || applied.Value != 1500 {
+		t.Fatalf("GetMaxTokensDefault() = %+v, want 1500", applied)
+	}
+
+	explicit := 32
+	got = orchestrator.applyResponsesMaxTokensDefault(&PreparedResponsesRequest{
+		Context: context.Background(),
+		Request: &core.ResponsesRequest{Model: "gpt-4o", MaxOutputTokens: &explicit},
+	})
+	if *got.Request.MaxOutputTokens != 32 || core.GetMaxTokensDefault(got.Context) != nil {
+		t.Fatalf("MaxOutputTokens = %d, want the client's 32 kept", *got.Request.MaxOutputTokens)
+	}
+}
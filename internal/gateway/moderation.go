@@ -0,0 +1,266 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+const (
+	// DefaultModerationThreshold is the score at which a category is flagged
+	// when ModerationConfig.Threshold is unset.
+	DefaultModerationThreshold = 0.5
+	// DefaultModerationTimeout bounds the moderation call when
+	// ModerationConfig.Timeout is unset.
+	DefaultModerationTimeout = 10 * time.Second
+
+	moderationChatPath      = "/v1/chat/completions"
+	moderationResponsesPath = "/v1/responses"
+)
+
+// ModerationConfig configures the optional moderation pre-check that scores
+// translated chat and Responses prompts before they reach a provider.
+type ModerationConfig struct {
+	// Moderator scores prompts. Nil disables the stage.
+	Moderator Moderator
+	// Threshold is the score at which a category is flagged.
+	Threshold float64
+	// DefaultAction applies to flagged categories without an entry in Actions.
+	// An empty value behaves like core.ModerationBlock.
+	DefaultAction core.ModerationAction
+	// Actions is keyed by category.
+	Actions map[string]core.ModerationAction
+	// Models limits the check to these bare or "provider/model" selectors.
+	// Empty checks every model.
+	Models []string
+	// Paths limits the check to these endpoint paths. Empty checks
+	// /v1/chat/completions and /v1/responses.
+	Paths []string
+	// SkipKeys lists managed API key IDs whose requests are never checked.
+	SkipKeys []string
+	// FailOpen lets requests through when the moderator fails; otherwise they
+	// are rejected with a 503.
+	FailOpen bool
+	// ExposeScores adds the scores of the triggering categories to block
+	// errors and to the flagged categories response header.
+	ExposeScores bool
+	// Timeout bounds one moderation call.
+	Timeout time.Duration
+}
+
+// enabledFor reports whether requests to path from the caller in ctx are
+// checked, before their model is known.
+func (c ModerationConfig) enabledFor(ctx context.Context, path string) bool {
+	if c.Moderator == nil {
+		return false
+	}
+	if len(c.Paths) > 0 && !slices.Contains(c.Paths, path) {
+		return false
+	}
+	keyID := core.GetAuthKeyID(ctx)
+	return keyID == "" || !slices.Contains(c.SkipKeys, keyID)
+}
+
+func (c ModerationConfig) coversModel(providerName, model string) bool {
+	if len(c.Models) == 0 {
+		return true
+	}
+	if slices.Contains(c.Models, model) {
+		return true
+	}
+	return providerName != "" && slices.Contains(c.Models, providerName+"/"+model)
+}
+
+func (c ModerationConfig) actionFor(category string) core.ModerationAction {
+	if action, ok := c.Actions[category]; ok {
+		return action
+	}
+	if c.DefaultAction == "" {
+		return core.ModerationBlock
+	}
+	return c.DefaultAction
+}
+
+// classify returns the sorted categories at or above the threshold whose
+// policy blocks the request, and those whose policy flags it.
+func (c ModerationConfig) classify(scores map[string]float64) (blocked, flagged []string) {
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = DefaultModerationThreshold
+	}
+	for category, score := range scores {
+		if score < threshold {
+			continue
+		}
+		switch c.actionFor(category) {
+		case core.ModerationBlock:
+			blocked = append(blocked, category)
+		case core.ModerationFlag:
+			flagged = append(flagged, category)
+		}
+	}
+	sort.Strings(blocked)
+	sort.Strings(flagged)
+	return blocked, flagged
+}
+
+// describe lists categories for clients, with their scores when configured.
+func (c ModerationConfig) describe(categories []string, scores map[string]float64) string {
+	if !c.ExposeScores {
+		return strings.Join(categories, ",")
+	}
+	described := make([]string, len(categories))
+	for i, category := range categories {
+		described[i] = fmt.Sprintf("%s=%.2f", category, scores[category])
+	}
+	return strings.Join(described, ",")
+}
+
+// DescribeModerationCategories formats the flagged categories of result for
+// the core.ModerationCategoriesHeader response header.
+func (o *InferenceOrchestrator) DescribeModerationCategories(result *core.ModerationResult) string {
+	if result == nil {
+		return ""
+	}
+	return o.moderation.describe(result.Flagged, result.Scores)
+}
+
+// moderationCheck is a moderation call running alongside request preparation.
+type moderationCheck struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	scores map[string]float64
+	err    error
+}
+
+func (o *InferenceOrchestrator) startModeration(ctx context.Context, text string) *moderationCheck {
+	timeout := o.moderation.Timeout
+	if timeout <= 0 {
+		timeout = DefaultModerationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	check := &moderationCheck{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(check.done)
+		check.scores, check.err = o.moderation.Moderator.Moderate(ctx, text)
+	}()
+	return check
+}
+
+func (m *moderationCheck) stop() {
+	if m != nil {
+		m.cancel()
+	}
+}
+
+// beginModeration starts the moderation call for a request before routing
+// resolves its model, so the two overlap. The call only starts early when
+// the requested selector is already in scope; otherwise finishModeration
+// runs it once the resolved model is known.
+func (o *InferenceOrchestrator) beginModeration(ctx context.Context, meta RequestMeta, path, text, providerName, model string) *moderationCheck {
+	if !o.moderation.enabledFor(ctx, path) || strings.TrimSpace(text) == "" || !o.moderation.coversModel(providerName, model) {
+		return nil
+	}
+	return o.startModeration(contextWithRequestID(ctx, meta.RequestID), text)
+}
+
+// finishModeration waits for the moderation call of a prepared request and
+// applies the category policies. Blocked requests fail with a
+// content_policy_violation error; flagged ones and checks that failed open
+// are recorded on the returned context.
+func (o *InferenceOrchestrator) finishModeration(ctx context.Context, check *moderationCheck, path, text string, workflow *core.Workflow, model string) (context.Context, error) {
+	defer check.stop()
+	cfg := o.moderation
+	if !cfg.enabledFor(ctx, path) || strings.TrimSpace(text) == "" {
+		return ctx, nil
+	}
+	if !cfg.coversModel(ProviderNameFromWorkflow(workflow), ResolvedModelFromWorkflow(workflow, model)) {
+		return ctx, nil
+	}
+	if check == nil {
+		check = o.startModeration(ctx, text)
+		defer check.stop()
+	}
+
+	select {
+	case <-check.done:
+	case <-ctx.Done():
+		return ctx, ctx.Err()
+	}
+	if check.err != nil {
+		if !cfg.FailOpen {
+			return ctx, core.NewProviderError("", http.StatusServiceUnavailable, "content moderation is unavailable", check.err)
+		}
+		return core.WithModeration(ctx, &core.ModerationResult{Unavailable: true, Error: check.err.Error()}), nil
+	}
+
+	blocked, flagged := cfg.classify(check.scores)
+	if len(blocked) > 0 {
+		return ctx, core.NewInvalidRequestError(
+			"request blocked by content moderation: "+cfg.describe(blocked, check.scores), nil,
+		).WithCode(core.ContentPolicyViolationCode)
+	}
+	if len(flagged) == 0 {
+		return ctx, nil
+	}
+	return core.WithModeration(ctx, &core.ModerationResult{Flagged: flagged, Scores: check.scores}), nil
+}
+
+// chatModerationText returns the prompt text of req that is moderated: every
+// message except the assistant's own turns.
+func chatModerationText(req *core.ChatRequest) string {
+	if req == nil {
+		return ""
+	}
+	var parts []string
+	for _, msg := range req.Messages {
+		if msg.Role == "assistant" {
+			continue
+		}
+		if text := core.ExtractTextContent(msg.Content); strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// responsesModerationText returns the instructions and input text of req.
+// Input shapes without a typed form are moderated as their JSON encoding.
+func responsesModerationText(req *core.ResponsesRequest) string {
+	if req == nil {
+		return ""
+	}
+	var parts []string
+	if strings.TrimSpace(req.Instructions) != "" {
+		parts = append(parts, req.Instructions)
+	}
+	switch input := req.Input.(type) {
+	case nil:
+	case string:
+		parts = append(parts, input)
+	case []core.ResponsesInputElement:
+		for _, element := range input {
+			if element.Role == "assistant" {
+				continue
+			}
+			if text := core.ExtractTextContent(element.Content); strings.TrimSpace(text) != "" {
+				parts = append(parts, text)
+			}
+			if strings.TrimSpace(element.Output) != "" {
+				parts = append(parts, element.Output)
+			}
+		}
+	default:
+		if encoded, err := json.Marshal(input); err == nil {
+			parts = append(parts, string(encoded))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+type moderatorStub struct {
+	scores map[string]float64
+	err    error
+	calls  atomic.Int32
+}
+
+func (m *moderatorStub) Moderate(context.Context, string) (map[string]float64, error) {
+	m.calls.Add(1)
+	return m.scores, m.err
+}
+
+var moderationWorkflow = &core.Workflow{
+	ProviderType: "openai",
+	Resolution: &core.RequestModelResolution{
+		ResolvedSelector: core.ModelSelector{Model: "gpt-5", Provider: "openai"},
+		ProviderType:     "openai",
+		ProviderName:     "openai-eu",
+	},
+}
+
+// moderate runs the pre-check of a chat request for the "smart" alias,
+// which routing resolves to openai-eu/gpt-5.
+func moderate(ctx context.Context, cfg ModerationConfig) (*core.ModerationResult, error) {
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{Moderation: cfg})
+	const text = "some prompt"
+	check := orchestrator.beginModeration(ctx, RequestMeta{}, moderationChatPath, text, "", "smart")
+	ctx, err := orchestrator.finishModeration(ctx, check, moderationChatPath, text, moderationWorkflow, "smart")
+	return core.GetModeration(ctx), err
+}
+
+func TestModeration_PolicyActions(t *testing.T) {
+	scores := map[string]float64{"violence": 0.9, "hate": 0.7, "sexual": 0.2}
+	tests := []struct {
+		name        string
+		cfg         ModerationConfig
+		wantBlocked string
+		wantFlagged []string
+	}{
+		{name: "block by default", wantBlocked: "request blocked by content moderation: hate,violence"},
+		{
+			name:        "block with scores",
+			cfg:         ModerationConfig{ExposeScores: true},
+			wantBlocked: "request blocked by content moderation: hate=0.70,violence=0.90",
+		},
+		{
+			name:        "flag and continue",
+			cfg:         ModerationConfig{DefaultAction: core.ModerationFlag},
+			wantFlagged: []string{"hate", "violence"},
+		},
+		{
+			name: "per-category policies",
+			cfg: ModerationConfig{
+				DefaultAction: core.ModerationBlock,
+				Actions:       map[string]core.ModerationAction{"violence": core.ModerationFlag, "hate": core.ModerationIgnore},
+			},
+			wantFlagged: []string{"violence"},
+		},
+		{name: "ignore", cfg: ModerationConfig{DefaultAction: core.ModerationIgnore}},
+		{name: "below threshold", cfg: ModerationConfig{Threshold: 0.95}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Moderator = &moderatorStub{scores: scores}
+			result, err := moderate(context.Background(), tt.cfg)
+			if tt.wantBlocked != "" {
+				var gatewayErr *core.GatewayError
+				if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusBadRequest ||
+					gatewayErr.Code == nil || *gatewayErr.Code != core.ContentPolicyViolationCode {
+					t.Fatalf("err = %v, want a 400 content_policy_violation", err)
+				}
+				if gatewayErr.Message != tt.wantBlocked {
+					t.Fatalf("message = %q, want %q", gatewayErr.Message, tt.wantBlocked)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("finishModeration() error = %v", err)
+			}
+			if tt.wantFlagged == nil {
+				if result != nil {
+					t.Fatalf("result = %+v, want nothing recorded", result)
+				}
+				return
+			}
+			if result == nil || strings.Join(result.Flagged, ",") != strings.Join(tt.wantFlagged, ",") {
+				t.Fatalf("result = %+v, want flagged %v", result, tt.wantFlagged)
+			}
+			if result.Scores["violence"] != 0.9 {
+				t.Fatalf("scores = %v, want the backend scores recorded", result.Scores)
+			}
+		})
+	}
+}
+
+func TestModeration_BackendFailure(t *testing.T) {
+	failing := func() *moderatorStub { return &moderatorStub{err: errors.New("guard model down")} }
+
+	_, err := moderate(context.Background(), ModerationConfig{Moderator: failing()})
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("fail closed err = %v, want 503", err)
+	}
+
+	result, err := moderate(context.Background(), ModerationConfig{Moderator: failing(), FailOpen: true})
+	if err != nil {
+		t.Fatalf("fail open err = %v", err)
+	}
+	if result == nil || !result.Unavailable || result.Error != "guard model down" {
+		t.Fatalf("result = %+v, want the failure recorded", result)
+	}
+}
+
+func TestModeration_Scope(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ModerationConfig
+		keyID       string
+		early       bool // started before routing resolved the model
+		wantBlocked bool
+	}{
+		{name: "every model", early: true, wantBlocked: true},
+		{name: "skipped key", cfg: ModerationConfig{SkipKeys: []string{"trusted"}}, keyID: "trusted"},
+		{name: "other key", cfg: ModerationConfig{SkipKeys: []string{"trusted"}}, keyID: "someone", early: true, wantBlocked: true},
+		{name: "path out of scope", cfg: ModerationConfig{Paths: []string{moderationResponsesPath}}},
+		{name: "requested model in scope", cfg: ModerationConfig{Models: []string{"smart", "gpt-5"}}, early: true, wantBlocked: true},
+		{name: "resolved model in scope", cfg: ModerationConfig{Models: []string{"openai-eu/gpt-5"}}, wantBlocked: true},
+		{name: "resolved model out of scope", cfg: ModerationConfig{Models: []string{"smart"}}, early: true},
+		{name: "model out of scope", cfg: ModerationConfig{Models: []string{"gpt-4o"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.keyID != "" {
+				ctx = core.WithAuthKeyID(ctx, tt.keyID)
+			}
+			tt.cfg.Moderator = &moderatorStub{scores: map[string]float64{"violence": 1}}
+			orchestrator := NewInferenceOrchestrator(InferenceConfig{Moderation: tt.cfg})
+			check := orchestrator.beginModeration(ctx, RequestMeta{}, moderationChatPath, "text", "", "smart")
+			check.stop()
+			if (check != nil) != tt.early {
+				t.Fatalf("started before routing = %v, want %v", check != nil, tt.early)
+			}
+
+			stub := &moderatorStub{scores: map[string]float64{"violence": 1}}
+			tt.cfg.Moderator = stub
+			_, err := moderate(ctx, tt.cfg)
+			if blocked := err != nil; blocked != tt.wantBlocked {
+				t.Fatalf("blocked = %v (err %v), want %v", blocked, err, tt.wantBlocked)
+			}
+			if !tt.early && !tt.wantBlocked && stub.calls.Load() != 0 {
+				t.Fatalf("moderator calls = %d, want none", stub.calls.Load())
+			}
+		})
+	}
+}
+
+func TestModerationText(t *testing.T) {
+	chat := chatModerationText(&core.ChatRequest{Messages: []core.Message{
+		{Role: "system", Content: "be nice"},
+		{Role: "user", Content: "hello"},
+		{Role: "assistant", Content: "model output"},
+		{Role: "user", Content: []core.ContentPart{{Type: "text", Text: "again"}}},
+	}})
+	if chat != "be nice\n\nhello\n\nagain" {
+		t.Fatalf("chat text = %q", chat)
+	}
+
+	responses := responsesModerationText(&core.ResponsesRequest{
+		Instructions: "be nice",
+		Input: []core.ResponsesInputElement{
+			{Role: "user", Content: "hello"},
+			{Role: "assistant", Content: "model output"},
+			{Type: "function_call_output", Output: "tool result"},
+		},
+	})
+	if responses != "be nice\n\nhello\n\ntool result" {
+		t.Fatalf("responses text = %q", responses)
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// promptCacheProviderTypes are the provider types whose prompt caching the
+// gateway negotiates. Anthropic caches a system prompt only when it is marked
+// with a cache breakpoint; OpenAI caches long prompts on its own and only
+// needs the cached tokens of its usage tracked.
+var promptCacheProviderTypes = map[string]bool{
+	"anthropic": true,
+	"openai":    true,
+}
+
+// applyPromptCache records the prompt cache hint of a request whose system
+// prompt starts with a cacheable prefix on its context.
+func (o *InferenceOrchestrator) applyPromptCache(ctx context.Context, workflow *core.Workflow, model, system string) context.Context {
+	if o.promptCache == nil || system == "" || workflow == nil {
+		return ctx
+	}
+	if !promptCacheProviderTypes[strings.TrimSpace(workflow.ProviderType)] {
+		return ctx
+	}
+	hint, ok := o.promptCache.Match(core.GetRequestID(ctx), ProviderNameFromWorkflow(workflow), ResolvedModelFromWorkflow(workflow, model), system)
+	if !ok {
+		return ctx
+	}
+	return core.WithPromptCacheHint(ctx, hint)
+}
+
+// chatSystemPrompt joins the text of the system and developer messages of
+// req the way providers without those roles send them as one system prompt.
+func chatSystemPrompt(req *core.ChatRequest) string {
+	if req == nil {
+		return ""
+	}
+	var parts []string
+	for _, msg := range req.Messages {
+		if !isSystemRole(msg.Role) {
+			continue
+		}
+		if text := core.ExtractTextContent(msg.Content); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+type promptCacheStub struct {
+	prefix    string
+	requestID string
+	model     string
+	system    string
+}
+
+func (s *promptCacheStub) Match(requestID, providerName, model, system string) (core.PromptCacheHint, bool) {
+	s.requestID, s.model, s.system = requestID, providerName+"/"+model, system
+	if s.prefix == "" {
+		return core.PromptCacheHint{}, false
+	}
+	return core.PromptCacheHint{Prefix: s.prefix, Key: "k1"}, true
+}
+
+func TestApplyPromptCache(t *testing.T) {
+	anthropicWorkflow := &core.Workflow{
+		ProviderType: "anthropic",
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: "claude-sonnet-4-5", Provider: "anthropic"},
+			ProviderType:     "anthropic",
+			ProviderName:     "claude-prod",
+		},
+	}
+	geminiWorkflow := &core.Workflow{ProviderType: "gemini"}
+	ctx := core.WithRequestID(context.Background(), "req-1")
+
+	stub := &promptCacheStub{prefix: "You are"}
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{PromptCache: stub})
+	got := core.GetPromptCacheHint(orchestrator.applyPromptCache(ctx, anthropicWorkflow, "smart", "You are helpful."))
+	if got.Prefix != "You are" || got.Key != "k1" {
+		t.Fatalf("hint = %+v, want the matched prefix", got)
+	}
+	if stub.requestID != "req-1" || stub.model != "claude-prod/claude-sonnet-4-5" || stub.system != "You are helpful." {
+		t.Fatalf("Match() got request %q, model %q, system %q", stub.requestID, stub.model, stub.system)
+	}
+
+	for name, tt := range map[string]struct {
+		orchestrator *InferenceOrchestrator
+		workflow     *core.Workflow
+		system       string
+	}{
+		"unsupported provider": {orchestrator, geminiWorkflow, "You are helpful."},
+		"no system prompt":     {orchestrator, anthropicWorkflow, ""},
+		"no match":             {NewInferenceOrchestrator(InferenceConfig{PromptCache: &promptCacheStub{}}), anthropicWorkflow, "You are helpful."},
+		"disabled":             {NewInferenceOrchestrator(InferenceConfig{}), anthropicWorkflow, "You are helpful."},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if hint := core.GetPromptCacheHint(tt.orchestrator.applyPromptCache(ctx, tt.workflow, "smart", tt.system)); hint.Prefix != "" {
+				t.Fatalf("hint = %+v, want none", hint)
+			}
+		})
+	}
+}
+
+func TestChatSystemPrompt(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		{Role: "system", Content: "You are a support agent."},
+		{Role: "user", Content: "Hello"},
+		{Role: "system", Content: ""},
+		{Role: "developer", Content: []core.ContentPart{{Type: "text", Text: "Answer in French."}}},
+	}}
+	if got, want := chatSystemPrompt(req), "You are a support agent.\n\nAnswer in French."; got != want {
+		t.Fatalf("chatSystemPrompt() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,300 @@
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// DefaultPromptDataURIMinBytes is the smallest data URI the data_uri pass
+// replaces when PromptCompressionConfig.DataURIMinBytes is unset.
+const DefaultPromptDataURIMinBytes = 1024
+
+// PromptCompressionConfig configures the optional prompt compression stage
+// that runs on translated chat requests before context overflow handling.
+// Per-request overrides arrive through RequestMeta.PromptCompression.
+type PromptCompressionConfig struct {
+	// Passes applies to models without an entry in ModelPasses.
+	Passes core.PromptCompressionPasses
+	// ModelPasses is keyed by "provider/model" or bare model ID.
+	ModelPasses map[string]core.PromptCompressionPasses
+	// DataURIMinBytes is the smallest data URI the data_uri pass replaces.
+	DataURIMinBytes int
+}
+
+const (
+	// Paragraphs shorter than this are never deduplicated, so short replies
+	// such as "Thanks!" survive in every turn.
+	promptDedupeMinParagraphChars = 32
+	promptDataURIPlaceholder      = "[data URI omitted: %s, %d bytes]"
+	promptCompressionHeaderHint   = "expected off or a comma-separated list of whitespace, dedupe, data_uri, include_system, include_latest_user"
+)
+
+var (
+	promptDataURIPattern     = regexp.MustCompile(`data:([a-zA-Z0-9.+-]+/[a-zA-Z0-9.+-]+)?(?:;[a-zA-Z0-9=.+-]+)*;base64,[A-Za-z0-9+/]+=*`)
+	promptParagraphSeparator = regexp.MustCompile(`\n[ \t\r]*\n`)
+	// Data URIs go first so whitespace collapsing never touches base64 runs,
+	// and dedupe goes last so it compares whitespace-normalized paragraphs.
+	promptCompressionPassOrder = []core.PromptCompressionPass{core.PromptCompressionDataURI, core.PromptCompressionWhitespace, core.PromptCompressionDedupe}
+)
+
+func (c PromptCompressionConfig) passesFor(workflow *core.Workflow, model string, override core.PromptCompressionPasses) core.PromptCompressionPasses {
+	if override != nil {
+		return override
+	}
+	if providerName := ProviderNameFromWorkflow(workflow); providerName != "" {
+		if passes, ok := c.ModelPasses[providerName+"/"+model]; ok {
+			return passes
+		}
+	}
+	if passes, ok := c.ModelPasses[model]; ok {
+		return passes
+	}
+	return c.Passes
+}
+
+// applyPromptCompression runs the configured compression passes over a
+// prepared chat request and records the estimated savings on its context.
+func (o *InferenceOrchestrator) applyPromptCompression(prepared *PreparedChatRequest, meta RequestMeta) (*PreparedChatRequest, error) {
+	override, err := core.ParsePromptCompressionPasses(meta.PromptCompression)
+	if err != nil {
+		return nil, core.NewInvalidRequestError(
+			fmt.Sprintf("invalid %s header %q: %s", core.PromptCompressionHeader, meta.PromptCompression, promptCompressionHeaderHint), err,
+		)
+	}
+
+	if prepared == nil || prepared.Request == nil {
+		return prepared, nil
+	}
+	cfg := o.promptCompression
+	model := ResolvedModelFromWorkflow(prepared.Workflow, prepared.Request.Model)
+	passes := cfg.passesFor(prepared.Workflow, model, override)
+
+	req, result := CompressChatPrompt(prepared.Request, passes, cfg.DataURIMinBytes)
+	if result == nil {
+		return prepared, nil
+	}
+	prepared.Request = req
+	prepared.Context = core.WithPromptCompression(prepared.Context, result)
+	return prepared, nil
+}
+
+// CompressChatPrompt applies the enabled passes to the text content of req
+// and returns a rewritten copy with a result describing the savings. It
+// returns req unchanged and a nil result when no pass changed the prompt.
+// System and developer messages and the latest user turn are left untouched
+// unless the include_system or include_latest_user options are set, and
+// streaming requests that continue after tool results are never rewritten.
+func CompressChatPrompt(req *core.ChatRequest, passes core.PromptCompressionPasses, dataURIMinBytes int) (*core.ChatRequest, *core.PromptCompressionResult) {
+	if req == nil || len(req.Messages) == 0 || isStreamingToolContinuation(req) {
+		return req, nil
+	}
+	if dataURIMinBytes <= 0 {
+		dataURIMinBytes = DefaultPromptDataURIMinBytes
+	}
+
+	eligible := promptCompressionEligible(req.Messages, passes)
+	messages := append([]core.Message(nil), req.Messages...)
+	var applied []core.PromptCompressionPass
+	for _, pass := range promptCompressionPassOrder {
+		if !passes[pass] {
+			continue
+		}
+		changed := false
+		switch pass {
+		case core.PromptCompressionDataURI:
+			changed = rewriteEligibleText(messages, eligible, func(text string) string {
+				return stripDataURIs(text, dataURIMinBytes)
+			})
+		case core.PromptCompressionWhitespace:
+			changed = rewriteEligibleText(messages, eligible, collapsePromptWhitespace)
+		case core.PromptCompressionDedupe:
+			changed = dedupePromptParagraphs(messages, eligible)
+		}
+		if changed {
+			applied = append(applied, pass)
+		}
+	}
+	if len(applied) == 0 {
+		return req, nil
+	}
+
+	compressed := *req
+	compressed.Messages = messages
+	before := EstimateChatPromptTokens(req)
+	return &compressed, &core.PromptCompressionResult{
+		Passes:          applied,
+		EstimatedTokens: before,
+		TokensSaved:     max(before-EstimateChatPromptTokens(&compressed), 0),
+	}
+}
+
+// isStreamingToolContinuation reports whether req streams the model's reply
+// to tool results. Rewriting those turns could corrupt the tool exchange the
+// client is in the middle of.
+func isStreamingToolContinuation(req *core.ChatRequest) bool {
+	if !req.Stream {
+		return false
+	}
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if isSystemRole(req.Messages[i].Role) {
+			continue
+		}
+		return req.Messages[i].Role == "tool"
+	}
+	return false
+}
+
+func promptCompressionEligible(messages []core.Message, passes core.PromptCompressionPasses) []bool {
+	latestUser := -1
+	if !passes[core.PromptCompressionIncludeLatestUser] {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == "user" {
+				latestUser = i
+				break
+			}
+		}
+	}
+	eligible := make([]bool, len(messages))
+	for i := range messages {
+		eligible[i] = i != latestUser &&
+			(passes[core.PromptCompressionIncludeSystem] || !isSystemRole(messages[i].Role))
+	}
+	return eligible
+}
+
+// rewriteEligibleText applies fn to the text of every eligible message.
+// Non-text content parts are preserved as-is.
+func rewriteEligibleText(messages []core.Message, eligible []bool, fn func(string) string) bool {
+	changed := false
+	for i := range messages {
+		if eligible[i] && rewriteMessageText(&messages[i], fn) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+func rewriteMessageText(msg *core.Message, fn func(string) string) bool {
+	switch content := msg.Content.(type) {
+	case string:
+		rewritten := fn(content)
+		if rewritten == content {
+			return false
+		}
+		msg.Content = rewritten
+		return true
+	case []core.ContentPart:
+		var parts []core.ContentPart
+		for i, part := range content {
+			if part.Type != "text" {
+				continue
+			}
+			rewritten := fn(part.Text)
+			if rewritten == part.Text {
+				continue
+			}
+			if parts == nil {
+				parts = append([]core.ContentPart(nil), content...)
+			}
+			parts[i].Text = rewritten
+		}
+		if parts == nil {
+			return false
+		}
+		msg.Content = parts
+		return true
+	default:
+		return false
+	}
+}
+
+func stripDataURIs(text string, minBytes int) string {
+	if !strings.Contains(text, "data:") {
+		return text
+	}
+	return promptDataURIPattern.ReplaceAllStringFunc(text, func(uri string) string {
+		if len(uri) < minBytes {
+			return uri
+		}
+		mediaType := "unknown type"
+		if match := promptDataURIPattern.FindStringSubmatch(uri); len(match) > 1 && match[1] != "" {
+			mediaType = match[1]
+		}
+		return fmt.Sprintf(promptDataURIPlaceholder, mediaType, len(uri))
+	})
+}
+
+// collapsePromptWhitespace collapses runs of spaces and tabs to one space,
+// trims every line, and keeps at most one blank line between paragraphs.
+// Fenced code blocks are copied verbatim because indentation matters there.
+func collapsePromptWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	inFence := false
+	blank := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inFence = !inFence
+			out = append(out, trimmed)
+			blank = false
+		case inFence:
+			out = append(out, line)
+		case trimmed == "":
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+		default:
+			out = append(out, strings.Join(strings.Fields(trimmed), " "))
+			blank = false
+		}
+	}
+	if blank && len(out) > 0 {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// dedupePromptParagraphs removes paragraphs of eligible messages that repeat
+// an earlier paragraph of any message verbatim. A message whose paragraphs
+// are all duplicates is left as-is so no turn ends up empty.
+func dedupePromptParagraphs(messages []core.Message, eligible []bool) bool {
+	seen := make(map[string]bool)
+	changed := false
+	for i := range messages {
+		if !eligible[i] {
+			for _, paragraph := range promptParagraphSeparator.Split(core.ExtractTextContent(messages[i].Content), -1) {
+				if key := strings.TrimSpace(paragraph); len(key) >= promptDedupeMinParagraphChars {
+					seen[key] = true
+				}
+			}
+			continue
+		}
+		if rewriteMessageText(&messages[i], func(text string) string {
+			paragraphs := promptParagraphSeparator.Split(text, -1)
+			kept := paragraphs[:0:0]
+			for _, paragraph := range paragraphs {
+				key := strings.TrimSpace(paragraph)
+				if len(key) >= promptDedupeMinParagraphChars {
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				kept = append(kept, paragraph)
+			}
+			if len(kept) == len(paragraphs) || len(kept) == 0 {
+				return text
+			}
+			return strings.Join(kept, "\n\n")
+		}) {
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,211 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func passSet(passes ...core.PromptCompressionPass) core.PromptCompressionPasses {
+	set := core.PromptCompressionPasses{}
+	for _, pass := range passes {
+		set[pass] = true
+	}
+	return set
+}
+
+func messageText(msg core.Message) string {
+	return core.ExtractTextContent(msg.Content)
+}
+
+const (
+	ragHeader    = "Source: handbook.pdf, section 4 (internal use only)"
+	ragParagraph = "Refunds are processed within five business days of approval."
+)
+
+func TestCompressChatPrompt_Whitespace(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		{Role: "user", Content: "Context:\n\n\n\n  lots   of\t\tspace   here  \n\n\n```go\nfunc main() {\n    fmt.Println(\"x\")\n}\n```\n\n"},
+		{Role: "user", Content: "question?"},
+	}}
+
+	got, result := CompressChatPrompt(req, passSet(core.PromptCompressionWhitespace), 0)
+	if result == nil {
+		t.Fatal("result = nil, want whitespace pass applied")
+	}
+	want := "Context:\n\nlots of space here\n\n```go\nfunc main() {\n    fmt.Println(\"x\")\n}\n```"
+	if text := messageText(got.Messages[0]); text != want {
+		t.Fatalf("text = %q, want %q", text, want)
+	}
+	if len(result.Passes) != 1 || result.Passes[0] != core.PromptCompressionWhitespace || result.TokensSaved <= 0 {
+		t.Fatalf("result = %+v, want whitespace with tokens saved", result)
+	}
+	if messageText(req.Messages[0]) == want {
+		t.Fatal("original request was mutated")
+	}
+}
+
+func TestCompressChatPrompt_Dedupe(t *testing.T) {
+	req := &core.ChatRequest{Messages: []core.Message{
+		{Role: "user", Content: ragHeader + "\n\n" + ragParagraph},
+		{Role: "assistant", Content: "Thanks!\n\nNoted."},
+		{Role: "tool", Content: ragHeader + "\n\nShipping takes two weeks for international orders.\n\nThanks!"},
+		{Role: "user", Content: ragHeader + "\n\nWhat is the refund window?"},
+	}}
+
+	got, result := CompressChatPrompt(req, passSet(core.PromptCompressionDedupe), 0)
+	if result == nil || result.Passes[0] != core.PromptCompressionDedupe {
+		t.Fatalf("result = %+v, want dedupe applied", result)
+	}
+	if text := messageText(got.Messages[2]); text != "Shipping takes two weeks for international orders.\n\nThanks!" {
+		t.Fatalf("tool text = %q, want the repeated header removed and short paragraphs kept", text)
+	}
+	if text := messageText(got.Messages[3]); text != messageText(req.Messages[3]) {
+		t.Fatalf("latest user turn = %q, want it untouched", text)
+	}
+}
+
+func TestCompressChatPrompt_DataURI(t *testing.T) {
+	blob := "data:image/png;base64," + strings.Repeat("QUJD", 400)
+	small := "data:text/plain;base64,aGk="
+	req := &core.ChatRequest{Messages: []core.Message{
+		{Role: "user", Content: []core.ContentPart{
+			{Type: "text", Text: "see " + blob + " and " + small},
+			{Type: "image_url", ImageURL: &core.ImageURLContent{URL: blob}},
+		}},
+		{Role: "user", Content: "describe it"},
+	}}
+
+	got, result := CompressChatPrompt(req, passSet(core.PromptCompressionDataURI), 1024)
+	if result == nil || result.TokensSaved < 300 {
+		t.Fatalf("result = %+v, want data_uri applied with large savings", result)
+	}
+	parts := got.Messages[0].Content.([]core.ContentPart)
+	if want := "see [data URI omitted: image/png, 1622 bytes] and " + small; parts[0].Text != want {
+		t.Fatalf("text part = %q, want %q", parts[0].Text, want)
+	}
+	if parts[1].ImageURL.URL != blob {
+		t.Fatal("image_url part was rewritten, want non-text parts preserved")
+	}
+	if req.Messages[0].Content.([]core.ContentPart)[0].Text == parts[0].Text {
+		t.Fatal("original content parts were mutated")
+	}
+}
+
+func TestCompressChatPrompt_LeavesSystemAndLatestUserUntouchedByDefault(t *testing.T) {
+	noisy := "  padded   text  \n\n\n" + ragHeader + "\n\n" + ragHeader + " data:image/png;base64," + strings.Repeat("A", 2048)
+	req := &core.ChatRequest{Messages: []core.Message{
+		{Role: "system", Content: noisy},
+		{Role: "developer", Content: noisy},
+		{Role: "user", Content: noisy},
+	}}
+	all := passSet(core.PromptCompressionWhitespace, core.PromptCompressionDedupe, core.PromptCompressionDataURI)
+
+	got, result := CompressChatPrompt(req, all, 0)
+	if result != nil || got != req {
+		t.Fatalf("result = %+v, want system, developer and latest user messages untouched", result)
+	}
+
+	all[core.PromptCompressionIncludeSystem] = true
+	got, result = CompressChatPrompt(req, all, 0)
+	if result == nil || messageText(got.Messages[0]) == noisy || messageText(got.Messages[1]) == noisy {
+		t.Fatal("include_system did not compress system and developer messages")
+	}
+	if messageText(got.Messages[2]) != noisy {
+		t.Fatal("latest user turn was rewritten without include_latest_user")
+	}
+
+	all[core.PromptCompressionIncludeLatestUser] = true
+	got, _ = CompressChatPrompt(req, all, 0)
+	if messageText(got.Messages[2]) == noisy {
+		t.Fatal("include_latest_user did not compress the latest user turn")
+	}
+}
+
+func TestCompressChatPrompt_SkipsStreamingToolContinuations(t *testing.T) {
+	req := &core.ChatRequest{Stream: true, Messages: []core.Message{
+		{Role: "user", Content: "  lots   of   space  "},
+		{Role: "assistant", ToolCalls: []core.ToolCall{{ID: "call_1", Type: "function", Function: core.FunctionCall{Name: "lookup"}}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "  result   with   space  "},
+	}}
+	passes := passSet(core.PromptCompressionWhitespace)
+
+	if got, result := CompressChatPrompt(req, passes, 0); result != nil || got != req {
+		t.Fatalf("result = %+v, want streaming tool continuation bypassed", result)
+	}
+
+	req.Stream = false
+	if _, result := CompressChatPrompt(req, passes, 0); result == nil {
+		t.Fatal("result = nil, want non-streaming tool continuation compressed")
+	}
+}
+
+func TestApplyPromptCompression_PassSelection(t *testing.T) {
+	workflow := &core.Workflow{
+		ProviderType: "openai",
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: "gpt-5", Provider: "openai"},
+			ProviderType:     "openai",
+			ProviderName:     "openai-eu",
+		},
+	}
+	newPrepared := func() *PreparedChatRequest {
+		return &PreparedChatRequest{
+			Context:  context.Background(),
+			Workflow: workflow,
+			Request: &core.ChatRequest{Model: "smart", Messages: []core.Message{
+				{Role: "user", Content: "  spaced   out  "},
+				{Role: "user", Content: "question"},
+			}},
+		}
+	}
+	whitespace := passSet(core.PromptCompressionWhitespace)
+
+	tests := []struct {
+		name    string
+		cfg     PromptCompressionConfig
+		header  string
+		applied bool
+	}{
+		{name: "off by default"},
+		{name: "global passes", cfg: PromptCompressionConfig{Passes: whitespace}, applied: true},
+		{
+			name: "provider-qualified model passes win",
+			cfg: PromptCompressionConfig{
+				Passes:      whitespace,
+				ModelPasses: map[string]core.PromptCompressionPasses{"gpt-5": whitespace, "openai-eu/gpt-5": {}},
+			},
+		},
+		{name: "bare model passes", cfg: PromptCompressionConfig{ModelPasses: map[string]core.PromptCompressionPasses{"gpt-5": whitespace}}, applied: true},
+		{name: "header enables passes", header: "Whitespace", applied: true},
+		{name: "header disables passes", cfg: PromptCompressionConfig{Passes: whitespace}, header: "off"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := NewInferenceOrchestrator(InferenceConfig{PromptCompression: tt.cfg})
+			prepared, err := orchestrator.applyPromptCompression(newPrepared(), RequestMeta{PromptCompression: tt.header})
+			if err != nil {
+				t.Fatalf("applyPromptCompression() error = %v", err)
+			}
+			result := core.GetPromptCompression(prepared.Context)
+			if (result != nil) != tt.applied {
+				t.Fatalf("result = %+v, want applied = %v", result, tt.applied)
+			}
+			if tt.applied && messageText(prepared.Request.Messages[0]) != "spaced out" {
+				t.Fatalf("text = %q, want collapsed whitespace", messageText(prepared.Request.Messages[0]))
+			}
+		})
+	}
+}
+
+func TestApplyPromptCompression_RejectsInvalidHeader(t *testing.T) {
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{})
+	_, err := orchestrator.applyPromptCompression(&PreparedChatRequest{}, RequestMeta{PromptCompression: "whitespace,summarize"})
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != 400 || !strings.Contains(gatewayErr.Message, core.PromptCompressionHeader) {
+		t.Fatalf("err = %v, want 400 naming the header", err)
+	}
+}
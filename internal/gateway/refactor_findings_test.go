@@ -260,6 +260,52 @@ func TestStreamResponsesFallsBackAfterEmptyPrimaryStream(t *testing.T) {
 	}
 }
 
+func TestStreamResponsesFallbackSkipsProvidersOutsideDataResidency(t *testing.T) {
+	provider := &residencyFallbackProvider{
+		streamFallbackProvider: &streamFallbackProvider{
+			streamsByModel: map[string]io.ReadCloser{
+				"us-fallback": io.NopCloser(strings.NewReader("data: {}\n\n")),
+				"eu-fallback": io.NopCloser(strings.NewReader("data: {}\n\n")),
+			},
+		},
+		residencyByProvider: map[string]string{"openai_us": "us", "openai_eu": "eu"},
+	}
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{
+		Provider: provider,
+		FallbackResolver: fallbackResolverFunc(func(*core.RequestModelResolution, core.Operation) []core.ModelSelector {
+			return []core.ModelSelector{
+				{Provider: "openai_us", Model: "us-fallback"},
+				{Provider: "openai_eu", Model: "eu-fallback"},
+			}
+		}),
+	})
+	workflow := &core.Workflow{
+		Endpoint: core.DescribeEndpoint(http.MethodPost, "/v1/responses"),
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Provider: "openai_eu", Model: "primary"},
+			ProviderType:     "openai",
+			DataResidency:    "eu",
+		},
+		Policy: &core.ResolvedWorkflowPolicy{
+			Features: core.WorkflowFeatures{Fallback: true},
+		},
+	}
+
+	ctx := core.WithDataResidency(context.Background(), "eu")
+	result, err := orchestrator.StreamResponses(ctx, workflow, &core.ResponsesRequest{Model: "primary"})
+	if err != nil {
+		t.Fatalf("StreamResponses() error = %v", err)
+	}
+	defer result.Stream.Close()
+
+	if result.Meta.FailoverModel != "openai_eu/eu-fallback" {
+		t.Fatalf("FailoverModel = %q, want openai_eu/eu-fallback", result.Meta.FailoverModel)
+	}
+	if got := strings.Join(provider.responseStreamCalls, ","); got != "primary,eu-fallback" {
+		t.Fatalf("response stream calls = %q, want primary,eu-fallback", got)
+	}
+}
+
 type fallbackResolverFunc func(*core.RequestModelResolution, core.Operation) []core.ModelSelector
 
 func (f fallbackResolverFunc) ResolveFallbacks(resolution *core.RequestModelResolution, op core.Operation) []core.ModelSelector {
@@ -305,3 +351,17 @@ func (p *streamFallbackProvider) GetProviderType(model string) string {
 	}
 	return ""
 }
+
+type residencyFallbackProvider struct {
+	*streamFallbackProvider
+	residencyByProvider map[string]string
+}
+
+func (p *residencyFallbackProvider) ResolveModelForResidency(requested core.RequestedModelSelector, _ string) (core.ModelSelector, bool, error) {
+	selector, err := requested.Normalize()
+	return selector, false, err
+}
+
+func (p *residencyFallbackProvider) SatisfiesDataResidency(selector core.ModelSelector, residency string) bool {
+	return core.DataResidencySatisfied(p.residencyByProvider[selector.Provider], residency)
+}
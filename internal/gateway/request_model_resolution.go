@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"gomodel/internal/core"
@@ -48,6 +49,40 @@ func WorkflowProviderNameForType(provider core.RoutableProvider, providerType st
 	return ""
 }
 
+// resolveSupportedSelector resolves selector to the concrete selector that
+// executes the request and checks that the provider serves it.
+func resolveSupportedSelector(
+	provider core.RoutableProvider,
+	resolver ModelResolver,
+	selector core.RequestedModelSelector,
+	residency string,
+) (core.ModelSelector, bool, error) {
+	resolvedSelector, aliasApplied, err := resolveExecutionSelector(provider, resolver, selector, residency)
+	if err != nil {
+		var gatewayErr *core.GatewayError
+		if errors.As(err, &gatewayErr) && core.IsDataResidencyError(gatewayErr) {
+			return core.ModelSelector{}, false, gatewayErr
+		}
+		return core.ModelSelector{}, false, core.NewInvalidRequestError(err.Error(), err)
+	}
+	if resolvedSelector == (core.ModelSelector{}) {
+		resolvedSelector, err = selector.Normalize()
+		if err != nil {
+			return core.ModelSelector{}, false, core.NewInvalidRequestError(err.Error(), err)
+		}
+	}
+
+	resolvedModel := resolvedSelector.QualifiedModel()
+	supported := provider.Supports(resolvedModel)
+	if counted, ok := provider.(modelCountProvider); ok && counted.ModelCount() == 0 && !supported {
+		return core.ModelSelector{}, false, core.NewProviderError("", 0, "model registry not initialized", nil)
+	}
+	if !supported {
+		return core.ModelSelector{}, false, core.NewInvalidRequestError("unsupported model: "+resolvedModel, nil)
+	}
+	return resolvedSelector, aliasApplied, nil
+}
+
 // ResolveRequestModel resolves a requested selector into a concrete provider/model selector.
 func ResolveRequestModel(provider core.RoutableProvider, resolver ModelResolver, requested core.RequestedModelSelector) (*core.RequestModelResolution, error) {
 	return ResolveRequestModelWithAuthorizer(context.Background(), provider, resolver, nil, requested)
@@ -63,24 +98,34 @@ func ResolveRequestModelWithAuthorizer(
 ) (*core.RequestModelResolution, error) {
 	requested = core.NewRequestedModelSelector(requested.Model, requested.ProviderHint)
 
-	resolvedSelector, aliasApplied, err := ResolveExecutionSelector(provider, resolver, requested)
-	if err != nil {
-		return nil, core.NewInvalidRequestError(err.Error(), err)
-	}
-	if resolvedSelector == (core.ModelSelector{}) {
-		resolvedSelector, err = requested.Normalize()
+	selector := requested
+	var group *core.ModelGroupSelection
+	if groups, ok := resolver.(ModelGroupResolver); ok {
+		var err error
+		selector, group, err = groups.ResolveModelGroup(ctx, requested)
 		if err != nil {
-			return nil, core.NewInvalidRequestError(err.Error(), err)
+			return nil, err
 		}
 	}
+	var experiment *core.ExperimentAssignment
+	if assigner, ok := resolver.(ExperimentAssigner); ok {
+		selector, experiment = assigner.AssignExperiment(ctx, selector)
+	}
 
-	resolvedModel := resolvedSelector.QualifiedModel()
-	if counted, ok := provider.(modelCountProvider); ok && counted.ModelCount() == 0 {
-		return nil, core.NewProviderError("", 0, "model registry not initialized", nil)
+	residency := core.GetDataResidency(ctx)
+	resolvedSelector, aliasApplied, err := resolveSupportedSelector(provider, resolver, selector, residency)
+	var gatewayErr *core.GatewayError
+	if err != nil && !(errors.As(err, &gatewayErr) && core.IsDataResidencyError(gatewayErr)) {
+		// A provider configured with lazy: true may serve the model once
+		// constructed.
+		if lazy, ok := provider.(core.LazyProviderInitializer); ok && lazy.InitializeLazyProviders(ctx, selector.RequestedQualifiedModel()) {
+			resolvedSelector, aliasApplied, err = resolveSupportedSelector(provider, resolver, selector, residency)
+		}
 	}
-	if !provider.Supports(resolvedModel) {
-		return nil, core.NewInvalidRequestError("unsupported model: "+resolvedModel, nil)
+	if err != nil {
+		return nil, err
 	}
+	resolvedModel := resolvedSelector.QualifiedModel()
 	if authorizer != nil {
 		if err := authorizer.ValidateModelAccess(ctx, resolvedSelector); err != nil {
 			return nil, err
@@ -93,14 +138,34 @@ func ResolveRequestModelWithAuthorizer(
 		ProviderType:     strings.TrimSpace(provider.GetProviderType(resolvedModel)),
 		ProviderName:     ResolvedProviderName(provider, resolvedSelector, ""),
 		AliasApplied:     aliasApplied,
+		Experiment:       experiment,
+		ModelGroup:       group,
+		DataResidency:    residency,
+		UnlistedModel:    isUnlistedModel(provider, resolvedModel),
 	}, nil
 }
 
+func isUnlistedModel(provider core.RoutableProvider, model string) bool {
+	unlisted, ok := provider.(core.UnlistedModelResolver)
+	return ok && unlisted.IsUnlistedModel(model)
+}
+
 // ResolveExecutionSelector applies explicit and provider-owned selector resolution.
 func ResolveExecutionSelector(
 	provider core.RoutableProvider,
 	resolver ModelResolver,
 	requested core.RequestedModelSelector,
+) (core.ModelSelector, bool, error) {
+	return resolveExecutionSelector(provider, resolver, requested, "")
+}
+
+// resolveExecutionSelector is ResolveExecutionSelector restricted to providers
+// satisfying residency when the provider supports data residency routing.
+func resolveExecutionSelector(
+	provider core.RoutableProvider,
+	resolver ModelResolver,
+	requested core.RequestedModelSelector,
+	residency string,
 ) (core.ModelSelector, bool, error) {
 	requested = core.NewRequestedModelSelector(requested.Model, requested.ProviderHint)
 
@@ -118,6 +183,15 @@ func ResolveExecutionSelector(
 		requested = core.NewRequestedModelSelector(resolvedSelector.QualifiedModel(), "")
 	}
 
+	if residencyResolver, ok := provider.(ResidencyModelResolver); ok && residency != "" {
+		var providerChanged bool
+		resolvedSelector, providerChanged, err = residencyResolver.ResolveModelForResidency(requested, residency)
+		if err != nil {
+			return core.ModelSelector{}, false, err
+		}
+		return resolvedSelector, aliasApplied || providerChanged, nil
+	}
+
 	if providerResolver, ok := provider.(ModelResolver); ok {
 		var providerChanged bool
 		resolvedSelector, providerChanged, err = providerResolver.ResolveModel(requested)
@@ -0,0 +1,596 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"gomodel/internal/core"
+	"gomodel/internal/tools"
+)
+
+// Defaults for ServerToolsConfig fields left unset.
+const (
+	DefaultServerToolMaxIterations  = 5
+	DefaultServerToolTimeout        = 10 * time.Second
+	DefaultServerToolMaxOutputBytes = 16 << 10
+)
+
+// ServerToolsConfig configures the server-side tool loop on translated chat
+// and Responses requests. Requests opt in through
+// core.RequestOptions.ServerTools.
+type ServerToolsConfig struct {
+	// Grants declares which registered tools are offered to which models
+	// and callers. No grants disables the loop.
+	Grants []ServerToolGrant
+	// MaxIterations caps the model calls of one request, including the
+	// final answer.
+	MaxIterations int
+	// Timeout bounds one tool call.
+	Timeout time.Duration
+	// MaxOutputBytes cuts the output of one tool call.
+	MaxOutputBytes int
+}
+
+// ServerToolGrant makes tools available to the requests it covers.
+type ServerToolGrant struct {
+	Tools []string
+	// Models limits the grant to these bare or "provider/model" selectors.
+	// Empty covers every model.
+	Models []string
+	// Keys limits the grant to these managed API key IDs. Empty covers
+	// every caller.
+	Keys []string
+}
+
+// requested reports whether the request in ctx opted into server tools,
+// before its model is known.
+func (c ServerToolsConfig) requested(ctx context.Context) bool {
+	if len(c.Grants) == 0 {
+		return false
+	}
+	opts := core.GetRequestOptions(ctx)
+	return opts != nil && opts.ServerTools != nil
+}
+
+// toolsFor returns the tools the request in ctx selected and may use with
+// model, skipping the names the request declares itself. Selecting a tool
+// by name that no grant covers is an error.
+func (c ServerToolsConfig) toolsFor(ctx context.Context, providerName, model string, declared []string) ([]string, error) {
+	if !c.requested(ctx) {
+		return nil, nil
+	}
+	selection := core.GetRequestOptions(ctx).ServerTools
+	keyID := core.GetAuthKeyID(ctx)
+	var granted []string
+	for _, grant := range c.Grants {
+		if len(grant.Keys) > 0 && !slices.Contains(grant.Keys, keyID) {
+			continue
+		}
+		if len(grant.Models) > 0 && !slices.Contains(grant.Models, model) &&
+			(providerName == "" || !slices.Contains(grant.Models, providerName+"/"+model)) {
+			continue
+		}
+		for _, name := range grant.Tools {
+			if selection.Selects(name) && !slices.Contains(granted, name) {
+				granted = append(granted, name)
+			}
+		}
+	}
+	for _, name := range selection.Names {
+		if !slices.Contains(granted, name) {
+			return nil, core.NewInvalidRequestError(fmt.Sprintf("server tool %q is not available for model %q", name, model), nil)
+		}
+	}
+
+	names := granted[:0]
+	for _, name := range granted {
+		if _, ok := tools.Lookup(name); ok && !slices.Contains(declared, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (c ServerToolsConfig) maxIterations() int {
+	if c.MaxIterations < 2 {
+		return DefaultServerToolMaxIterations
+	}
+	return c.MaxIterations
+}
+
+func (c ServerToolsConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultServerToolTimeout
+	}
+	return c.Timeout
+}
+
+func (c ServerToolsConfig) maxOutputBytes() int {
+	if c.MaxOutputBytes <= 0 {
+		return DefaultServerToolMaxOutputBytes
+	}
+	return c.MaxOutputBytes
+}
+
+// toolOutput is the result of one server tool call, sent back to the model.
+type toolOutput struct {
+	callID string
+	output string
+}
+
+// toolLoopSpec adapts the tool loop to one request type.
+type toolLoopSpec[Req any, Resp any] struct {
+	// declared lists the function tools the request declares itself.
+	declared func(Req) []string
+	// declare returns a copy of the request offering the named tools.
+	declare func(Req, []string) Req
+	execute func(*InferenceOrchestrator, context.Context, *core.Workflow, Req) (Resp, string, string, string, bool, error)
+	// calls returns the function calls of a response.
+	calls func(Resp) []core.ToolCall
+	// next returns the request of the following turn: the previous one plus
+	// the calls and their outputs. last asks the model for a final answer.
+	next func(req Req, resp Resp, calls []core.ToolCall, outputs []toolOutput, last bool) Req
+	turn func(Resp) core.ToolLoopTurn
+	// sum returns the final response with the usage of every turn added up.
+	sum func([]Resp) Resp
+	// model returns the model a response reports.
+	model func(Resp) string
+}
+
+var chatToolLoopSpec = toolLoopSpec[*core.ChatRequest, *core.ChatResponse]{
+	declared: chatDeclaredTools,
+	declare:  declareChatServerTools,
+	execute:  executeChatCompletionRequest,
+	calls:    chatToolCalls,
+	next:     nextChatToolTurn,
+	turn: func(resp *core.ChatResponse) core.ToolLoopTurn {
+		return core.ToolLoopTurn{ResponseID: resp.ID, Model: resp.Model, InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	},
+	sum:   sumChatUsage,
+	model: chatResponseModel,
+}
+
+var responsesToolLoopSpec = toolLoopSpec[*core.ResponsesRequest, *core.ResponsesResponse]{
+	declared: responsesDeclaredTools,
+	declare:  declareResponsesServerTools,
+	execute:  executeResponsesRequest,
+	calls:    responsesToolCalls,
+	next:     nextResponsesToolTurn,
+	turn: func(resp *core.ResponsesResponse) core.ToolLoopTurn {
+		turn := core.ToolLoopTurn{ResponseID: resp.ID, Model: resp.Model}
+		if resp.Usage != nil {
+			turn.InputTokens, turn.OutputTokens = resp.Usage.InputTokens, resp.Usage.OutputTokens
+		}
+		return turn
+	},
+	sum:   sumResponsesUsage,
+	model: responsesResponseModel,
+}
+
+// serverToolsFor returns the server tools the loop offers to req, if any.
+func serverToolsFor[Req any, Resp any](o *InferenceOrchestrator, ctx context.Context, workflow *core.Workflow, req Req, model string, spec toolLoopSpec[Req, Resp]) ([]string, error) {
+	if !o.serverTools.requested(ctx) {
+		return nil, nil
+	}
+	return o.serverTools.toolsFor(ctx, ProviderNameFromWorkflow(workflow), ResolvedModelFromWorkflow(workflow, model), spec.declared(req))
+}
+
+// runToolLoop calls the model, runs the server tools it calls and calls it
+// again with their outputs, until it answers without calling one or the
+// iteration cap is reached. A response that also calls a tool the gateway
+// does not run ends the loop and goes to the client as it is. The final
+// response carries the usage of every call.
+func runToolLoop[Req any, Resp any](
+	o *InferenceOrchestrator,
+	ctx context.Context,
+	workflow *core.Workflow,
+	req Req,
+	names []string,
+	spec toolLoopSpec[Req, Resp],
+) (Resp, ExecutionMeta, error) {
+	cfg := o.serverTools
+	loop := &core.ToolLoopResult{}
+	var responses []Resp
+	var meta ExecutionMeta
+	current := spec.declare(req, names)
+	for {
+		resp, providerType, providerName, failoverModel, usedFallback, err := spec.execute(o, ctx, workflow, current)
+		if err != nil {
+			var zero Resp
+			return zero, ExecutionMeta{}, err
+		}
+		loop.Iterations++
+		responses = append(responses, resp)
+		meta.ProviderType, meta.ProviderName, meta.Model = providerType, providerName, spec.model(resp)
+		if usedFallback {
+			meta.UsedFallback, meta.FailoverModel = true, failoverModel
+		}
+
+		turn := spec.turn(resp)
+		calls := spec.calls(resp)
+		if len(calls) == 0 || !allServerTools(calls, names) || loop.Iterations >= cfg.maxIterations() {
+			loop.Turns = append(loop.Turns, turn)
+			meta.ToolLoop = loop
+			return spec.sum(responses), meta, nil
+		}
+
+		outputs := make([]toolOutput, len(calls))
+		turn.Calls = make([]core.ToolLoopCall, len(calls))
+		for i, call := range calls {
+			handler, _ := tools.Lookup(call.Function.Name)
+			result := tools.Run(ctx, handler, call.Function.Arguments, cfg.timeout(), cfg.maxOutputBytes())
+			outputs[i] = toolOutput{callID: call.ID, output: result.Output}
+			turn.Calls[i] = core.ToolLoopCall{
+				CallID:      call.ID,
+				Name:        call.Function.Name,
+				Duration:    result.Duration,
+				OutputBytes: len(result.Output),
+				Truncated:   result.Truncated,
+			}
+			if result.Err != nil {
+				turn.Calls[i].Error = result.Err.Error()
+			}
+		}
+		loop.Turns = append(loop.Turns, turn)
+		if err := ctx.Err(); err != nil {
+			var zero Resp
+			return zero, ExecutionMeta{}, err
+		}
+		current = spec.next(current, resp, calls, outputs, loop.Iterations+1 >= cfg.maxIterations())
+	}
+}
+
+func allServerTools(calls []core.ToolCall, names []string) bool {
+	for _, call := range calls {
+		if !slices.Contains(names, call.Function.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextToolChoice releases a tool_choice that forced a tool call, so the
+// model can answer after seeing the outputs, and forbids tool calls on the
+// last allowed turn.
+func nextToolChoice(last bool) any {
+	if last {
+		return "none"
+	}
+	return "auto"
+}
+
+func serverToolDefinition(name string) (tools.Definition, bool) {
+	handler, ok := tools.Lookup(name)
+	if !ok {
+		return tools.Definition{}, false
+	}
+	definition := handler.Definition()
+	if definition.Parameters == nil {
+		definition.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return definition, true
+}
+
+func chatDeclaredTools(req *core.ChatRequest) []string {
+	var names []string
+	for _, tool := range req.Tools {
+		if function, ok := tool["function"].(map[string]any); ok {
+			if name, _ := function["name"].(string); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func declareChatServerTools(req *core.ChatRequest, names []string) *core.ChatRequest {
+	cloned := CloneChatRequestForSelector(req, core.ModelSelector{Model: req.Model, Provider: req.Provider})
+	cloned.Tools = slices.Clone(req.Tools)
+	for _, name := range names {
+		definition, ok := serverToolDefinition(name)
+		if !ok {
+			continue
+		}
+		cloned.Tools = append(cloned.Tools, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        name,
+				"description": definition.Description,
+				"parameters":  definition.Parameters,
+			},
+		})
+	}
+	return cloned
+}
+
+func chatToolCalls(resp *core.ChatResponse) []core.ToolCall {
+	if resp == nil || len(resp.Choices) == 0 {
+		return nil
+	}
+	return resp.Choices[0].Message.ToolCalls
+}
+
+func nextChatToolTurn(req *core.ChatRequest, resp *core.ChatResponse, calls []core.ToolCall, outputs []toolOutput, last bool) *core.ChatRequest {
+	next := CloneChatRequestForSelector(req, core.ModelSelector{Model: req.Model, Provider: req.Provider})
+	message := resp.Choices[0].Message
+	next.Messages = append(next.Messages, core.Message{
+		Role:        "assistant",
+		Content:     message.Content,
+		ContentNull: message.Content == nil,
+		ToolCalls:   slices.Clone(calls),
+	})
+	for _, output := range outputs {
+		next.Messages = append(next.Messages, core.Message{Role: "tool", ToolCallID: output.callID, Content: output.output})
+	}
+	next.ToolChoice = nextToolChoice(last)
+	return next
+}
+
+func sumChatUsage(responses []*core.ChatResponse) *core.ChatResponse {
+	final := responses[len(responses)-1]
+	if len(responses) == 1 {
+		return final
+	}
+	summed := *final
+	var usage core.Usage
+	for _, resp := range responses {
+		usage.PromptTokens += resp.Usage.PromptTokens
+		usage.CompletionTokens += resp.Usage.CompletionTokens
+		usage.TotalTokens += resp.Usage.TotalTokens
+		usage.PromptTokensDetails = addPromptTokensDetails(usage.PromptTokensDetails, resp.Usage.PromptTokensDetails)
+		usage.CompletionTokensDetails = addCompletionTokensDetails(usage.CompletionTokensDetails, resp.Usage.CompletionTokensDetails)
+		usage.RawUsage = addRawUsage(usage.RawUsage, resp.Usage.RawUsage)
+	}
+	summed.Usage = usage
+	return &summed
+}
+
+func responsesDeclaredTools(req *core.ResponsesRequest) []string {
+	var names []string
+	for _, tool := range req.Tools {
+		if name, _ := tool["name"].(string); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func declareResponsesServerTools(req *core.ResponsesRequest, names []string) *core.ResponsesRequest {
+	cloned := CloneResponsesRequestForSelector(req, core.ModelSelector{Model: req.Model, Provider: req.Provider})
+	cloned.Tools = slices.Clone(req.Tools)
+	for _, name := range names {
+		definition, ok := serverToolDefinition(name)
+		if !ok {
+			continue
+		}
+		cloned.Tools = append(cloned.Tools, map[string]any{
+			"type":        "function",
+			"name":        name,
+			"description": definition.Description,
+			"parameters":  definition.Parameters,
+		})
+	}
+	return cloned
+}
+
+func responsesToolCalls(resp *core.ResponsesResponse) []core.ToolCall {
+	if resp == nil {
+		return nil
+	}
+	var calls []core.ToolCall
+	for _, item := range resp.Output {
+		if item.Type == "function_call" {
+			calls = append(calls, core.ToolCall{ID: item.CallID, Type: "function", Function: core.FunctionCall{Name: item.Name, Arguments: item.Arguments}})
+		}
+	}
+	return calls
+}
+
+func nextResponsesToolTurn(req *core.ResponsesRequest, resp *core.ResponsesResponse, _ []core.ToolCall, outputs []toolOutput, last bool) *core.ResponsesRequest {
+	next := CloneResponsesRequestForSelector(req, core.ModelSelector{Model: req.Model, Provider: req.Provider})
+	input := responsesInputElements(req.Input)
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "function_call":
+			input = append(input, core.ResponsesInputElement{Type: "function_call", CallID: item.CallID, Name: item.Name, Arguments: item.Arguments})
+		case "message":
+			var text strings.Builder
+			for _, content := range item.Content {
+				text.WriteString(content.Text)
+			}
+			if text.Len() > 0 {
+				input = append(input, core.ResponsesInputElement{Type: "message", Role: "assistant", Content: text.String()})
+			}
+		}
+	}
+	for _, output := range outputs {
+		input = append(input, core.ResponsesInputElement{Type: "function_call_output", CallID: output.callID, Output: output.output})
+	}
+	next.Input = input
+	next.ToolChoice = nextToolChoice(last)
+	return next
+}
+
+// responsesInputElements returns a copy of a Responses input as a list of
+// elements, so the loop can append the turns it adds.
+func responsesInputElements(input any) []core.ResponsesInputElement {
+	switch typed := input.(type) {
+	case nil:
+		return nil
+	case string:
+		return []core.ResponsesInputElement{{Type: "message", Role: "user", Content: typed}}
+	case []core.ResponsesInputElement:
+		return slices.Clone(typed)
+	}
+	// Other shapes come from internal callers; they round-trip through
+	// JSON like a client request would.
+	var elements []core.ResponsesInputElement
+	if encoded, err := json.Marshal(input); err == nil {
+		_ = json.Unmarshal(encoded, &elements) //nolint:errcheck
+	}
+	return elements
+}
+
+func sumResponsesUsage(responses []*core.ResponsesResponse) *core.ResponsesResponse {
+	final := responses[len(responses)-1]
+	if len(responses) == 1 {
+		return final
+	}
+	summed := *final
+	usage := &core.ResponsesUsage{}
+	for _, resp := range responses {
+		if resp.Usage == nil {
+			continue
+		}
+		usage.InputTokens += resp.Usage.InputTokens
+		usage.OutputTokens += resp.Usage.OutputTokens
+		usage.TotalTokens += resp.Usage.TotalTokens
+		usage.PromptTokensDetails = addPromptTokensDetails(usage.PromptTokensDetails, resp.Usage.PromptTokensDetails)
+		usage.CompletionTokensDetails = addCompletionTokensDetails(usage.CompletionTokensDetails, resp.Usage.CompletionTokensDetails)
+		usage.RawUsage = addRawUsage(usage.RawUsage, resp.Usage.RawUsage)
+	}
+	summed.Usage = usage
+	return &summed
+}
+
+func addPromptTokensDetails(total, details *core.PromptTokensDetails) *core.PromptTokensDetails {
+	if details == nil {
+		return total
+	}
+	if total == nil {
+		total = &core.PromptTokensDetails{}
+	}
+	total.CachedTokens += details.CachedTokens
+	total.AudioTokens += details.AudioTokens
+	total.TextTokens += details.TextTokens
+	total.ImageTokens += details.ImageTokens
+	return total
+}
+
+func addCompletionTokensDetails(total, details *core.CompletionTokensDetails) *core.CompletionTokensDetails {
+	if details == nil {
+		return total
+	}
+	if total == nil {
+		total = &core.CompletionTokensDetails{}
+	}
+	total.ReasoningTokens += details.ReasoningTokens
+	total.AudioTokens += details.AudioTokens
+	total.AcceptedPredictionTokens += details.AcceptedPredictionTokens
+	total.RejectedPredictionTokens += details.RejectedPredictionTokens
+	return total
+}
+
+// addRawUsage adds the numeric provider usage fields of raw to total. Other
+// fields keep the value of the latest turn.
+func addRawUsage(total, raw map[string]any) map[string]any {
+	if len(raw) == 0 {
+		return total
+	}
+	if total == nil {
+		total = make(map[string]any, len(raw))
+	}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case float64:
+			sum, _ := total[key].(float64)
+			total[key] = sum + v
+		case int:
+			sum, _ := total[key].(int)
+			total[key] = sum + v
+		default:
+			total[key] = value
+		}
+	}
+	return total
+}
+
+// chatToolLoopStream replays the final chat response of a tool loop as a
+// chat completion SSE stream: one content delta, the finish reason and the
+// summed usage. Intermediate turns are never streamed.
+func chatToolLoopStream(resp *core.ChatResponse) io.ReadCloser {
+	var buf bytes.Buffer
+	writeEvent := func(payload any) {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		buf.WriteString("data: ")
+		buf.Write(encoded)
+		buf.WriteString("\n\n")
+	}
+	chunk := func(choices []map[string]any) map[string]any {
+		return map[string]any{
+			"id":      resp.ID,
+			"object":  "chat.completion.chunk",
+			"created": resp.Created,
+			"model":   resp.Model,
+			"choices": choices,
+		}
+	}
+	for _, choice := range resp.Choices {
+		delta := map[string]any{"role": "assistant"}
+		if choice.Message.Content != nil {
+			delta["content"] = choice.Message.Content
+		}
+		if len(choice.Message.ToolCalls) > 0 {
+			calls := make([]map[string]any, len(choice.Message.ToolCalls))
+			for i, call := range choice.Message.ToolCalls {
+				calls[i] = map[string]any{"index": i, "id": call.ID, "type": call.Type, "function": call.Function}
+			}
+			delta["tool_calls"] = calls
+		}
+		writeEvent(chunk([]map[string]any{{"index": choice.Index, "delta": delta}}))
+		writeEvent(chunk([]map[string]any{{"index": choice.Index, "delta": map[string]any{}, "finish_reason": choice.FinishReason}}))
+	}
+	usage := chunk([]map[string]any{})
+	usage["usage"] = resp.Usage
+	writeEvent(usage)
+	buf.WriteString("data: [DONE]\n\n")
+	return io.NopCloser(&buf)
+}
+
+// responsesToolLoopStream replays the final Responses API response of a
+// tool loop as a Responses SSE stream: created, the output text delta and
+// completed with the summed usage.
+func responsesToolLoopStream(resp *core.ResponsesResponse) io.ReadCloser {
+	var buf bytes.Buffer
+	writeEvent := func(eventType string, payload map[string]any) {
+		payload["type"] = eventType
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		buf.WriteString("event: ")
+		buf.WriteString(eventType)
+		buf.WriteString("\ndata: ")
+		buf.Write(encoded)
+		buf.WriteString("\n\n")
+	}
+	created := *resp
+	created.Status = "in_progress"
+	created.Output = []core.ResponsesOutputItem{}
+	created.Usage = nil
+	writeEvent("response.created", map[string]any{"response": created})
+	for index, item := range resp.Output {
+		writeEvent("response.output_item.added", map[string]any{"output_index": index, "item": item})
+		for contentIndex, content := range item.Content {
+			if content.Type == "output_text" && content.Text != "" {
+				writeEvent("response.output_text.delta", map[string]any{"item_id": item.ID, "output_index": index, "content_index": contentIndex, "delta": content.Text})
+			}
+			for annotationIndex, annotation := range content.Annotations {
+				writeEvent("response.output_text.annotation.added", map[string]any{"item_id": item.ID, "output_index": index, "content_index": contentIndex, "annotation_index": annotationIndex, "annotation": annotation})
+			}
+		}
+		writeEvent("response.output_item.done", map[string]any{"output_index": index, "item": item})
+	}
+	writeEvent("response.completed", map[string]any{"response": resp})
+	buf.WriteString("data: [DONE]\n\n")
+	return io.NopCloser(&buf)
+}
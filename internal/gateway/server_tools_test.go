@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+	"gomodel/internal/tools"
+)
+
+func serverToolsContext(selection string, keyID string) context.Context {
+	parsed, err := core.ParseServerToolSelection(selection)
+	if err != nil {
+		panic(err)
+	}
+	ctx := core.WithRequestOptions(context.Background(), &core.RequestOptions{ServerTools: parsed})
+	if keyID != "" {
+		ctx = core.WithAuthKeyID(ctx, keyID)
+	}
+	return ctx
+}
+
+func TestServerToolsConfigToolsFor(t *testing.T) {
+	cfg := ServerToolsConfig{Grants: []ServerToolGrant{
+		{Tools: []string{tools.CalculatorTool}},
+		{Tools: []string{tools.CurrentTimeTool}, Models: []string{"openai/gpt-4o"}, Keys: []string{"key-ops"}},
+	}}
+	tests := []struct {
+		name      string
+		selection string
+		keyID     string
+		model     string
+		declared  []string
+		want      string
+		wantErr   string
+	}{
+		{name: "all for anyone", selection: "all", model: "gpt-4o", want: "calculator"},
+		{name: "all for granted key and model", selection: "all", keyID: "key-ops", model: "gpt-4o", want: "calculator,current_time"},
+		{name: "other model", selection: "all", keyID: "key-ops", model: "gpt-5", want: "calculator"},
+		{name: "declared by client", selection: "all", model: "gpt-4o", declared: []string{"calculator"}},
+		{name: "named and granted", selection: "current_time", keyID: "key-ops", model: "gpt-4o", want: "current_time"},
+		{name: "named but not granted", selection: "calculator,current_time", model: "gpt-4o", wantErr: `server tool "current_time" is not available`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := cfg.toolsFor(serverToolsContext(tt.selection, tt.keyID), "openai", tt.model, tt.declared)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("toolsFor() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("toolsFor() error = %v", err)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Fatalf("toolsFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if cfg.requested(context.Background()) {
+		t.Fatal("requests without the server_tools option must not use the loop")
+	}
+}
+
+// scriptedChatLoop runs the chat tool loop against canned responses and
+// returns the requests the model received.
+func scriptedChatLoop(t *testing.T, cfg ServerToolsConfig, responses ...*core.ChatResponse) (*core.ChatResponse, ExecutionMeta, []*core.ChatRequest) {
+	t.Helper()
+	var requests []*core.ChatRequest
+	spec := chatToolLoopSpec
+	spec.execute = func(_ *InferenceOrchestrator, _ context.Context, _ *core.Workflow, req *core.ChatRequest) (*core.ChatResponse, string, string, string, bool, error) {
+		requests = append(requests, req)
+		return responses[min(len(requests), len(responses))-1], "openai", "openai", "", false, nil
+	}
+	orchestrator := NewInferenceOrchestrator(InferenceConfig{ServerTools: cfg})
+	req := &core.ChatRequest{
+		Model:      "gpt-4o",
+		Messages:   []core.Message{{Role: "user", Content: "What is 6*7?"}},
+		ToolChoice: map[string]any{"type": "function", "function": map[string]any{"name": tools.CalculatorTool}},
+	}
+	resp, meta, err := runToolLoop(orchestrator, context.Background(), nil, req, []string{tools.CalculatorTool}, spec)
+	if err != nil {
+		t.Fatalf("runToolLoop() error = %v", err)
+	}
+	return resp, meta, requests
+}
+
+func calculatorCallResponse(id string) *core.ChatResponse {
+	return &core.ChatResponse{
+		ID:    id,
+		Model: "gpt-4o",
+		Choices: []core.Choice{{
+			FinishReason: "tool_calls",
+			Message: core.ResponseMessage{Role: "assistant", ToolCalls: []core.ToolCall{{
+				ID:       "call_" + id,
+				Type:     "function",
+				Function: core.FunctionCall{Name: tools.CalculatorTool, Arguments: `{"expression":"6*7"}`},
+			}}},
+		}},
+		Usage: core.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+}
+
+func TestRunToolLoop_ChatTwoIterations(t *testing.T) {
+	final := &core.ChatResponse{
+		ID:      "resp-2",
+		Model:   "gpt-4o",
+		Choices: []core.Choice{{FinishReason: "stop", Message: core.ResponseMessage{Role: "assistant", Content: "42"}}},
+		Usage:   core.Usage{PromptTokens: 30, CompletionTokens: 2, TotalTokens: 32},
+	}
+	resp, meta, requests := scriptedChatLoop(t, ServerToolsConfig{}, calculatorCallResponse("resp-1"), final)
+
+	if len(requests) != 2 {
+		t.Fatalf("model calls = %d, want 2", len(requests))
+	}
+	if got := chatDeclaredTools(requests[0]); len(got) != 1 || got[0] != tools.CalculatorTool {
+		t.Fatalf("first call declared tools %v, want the calculator", got)
+	}
+	second := requests[1]
+	if second.ToolChoice != "auto" {
+		t.Fatalf("second call tool_choice = %v, want auto", second.ToolChoice)
+	}
+	if len(second.Messages) != 3 || second.Messages[1].Role != "assistant" || len(second.Messages[1].ToolCalls) != 1 {
+		t.Fatalf("second call messages = %+v, want user, assistant tool call and tool output", second.Messages)
+	}
+	toolMessage := second.Messages[2]
+	if toolMessage.Role != "tool" || toolMessage.ToolCallID != "call_resp-1" || !strings.Contains(core.ExtractTextContent(toolMessage.Content), `"result":"42"`) {
+		t.Fatalf("tool message = %+v", toolMessage)
+	}
+
+	if resp.ID != "resp-2" || resp.Usage.PromptTokens != 40 || resp.Usage.CompletionTokens != 7 || resp.Usage.TotalTokens != 47 {
+		t.Fatalf("final response = %s with usage %+v, want resp-2 with summed usage", resp.ID, resp.Usage)
+	}
+	loop := meta.ToolLoop
+	if loop == nil || loop.Iterations != 2 || len(loop.Turns) != 2 {
+		t.Fatalf("tool loop = %+v, want two iterations", loop)
+	}
+	if calls := loop.Turns[0].Calls; len(calls) != 1 || calls[0].Name != tools.CalculatorTool || calls[0].Error != "" {
+		t.Fatalf("first turn calls = %+v", calls)
+	}
+	if len(loop.Turns[1].Calls) != 0 || loop.Turns[1].InputTokens != 30 {
+		t.Fatalf("final turn = %+v", loop.Turns[1])
+	}
+}
+
+func TestRunToolLoop_StopsAtMaxIterations(t *testing.T) {
+	_, meta, requests := scriptedChatLoop(t, ServerToolsConfig{MaxIterations: 2}, calculatorCallResponse("resp-1"))
+
+	if len(requests) != 2 || meta.ToolLoop.Iterations != 2 {
+		t.Fatalf("model calls = %d, iterations = %d, want 2", len(requests), meta.ToolLoop.Iterations)
+	}
+	if requests[1].ToolChoice != "none" {
+		t.Fatalf("last call tool_choice = %v, want none", requests[1].ToolChoice)
+	}
+}
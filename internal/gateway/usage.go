@@ -9,13 +9,15 @@ import (
 )
 
 // LogUsage writes one non-streaming usage entry when usage is enabled.
+// content, when not nil, writes the assistant output for content analysis.
 func (o *InferenceOrchestrator) LogUsage(
 	ctx context.Context,
 	workflow *core.Workflow,
 	model, providerType, providerName string,
 	extractFn func(*core.ModelPricing) *usage.UsageEntry,
+	content usage.ContentFunc,
 ) {
-	o.logUsage(ctx, workflow, model, providerType, providerName, extractFn)
+	o.logUsage(ctx, workflow, model, providerType, providerName, extractFn, content)
 }
 
 func (o *InferenceOrchestrator) logUsage(
@@ -23,6 +25,7 @@ func (o *InferenceOrchestrator) logUsage(
 	workflow *core.Workflow,
 	model, providerType, providerName string,
 	extractFn func(*core.ModelPricing) *usage.UsageEntry,
+	content usage.ContentFunc,
 ) {
 	if o.usageLogger == nil || !o.usageLogger.Config().Enabled || (workflow != nil && !workflow.UsageEnabled()) {
 		return
@@ -32,8 +35,25 @@ func (o *InferenceOrchestrator) logUsage(
 		pricing = o.pricingResolver.ResolvePricing(model, providerType)
 	}
 	if entry := extractFn(pricing); entry != nil {
+		entry.RequestedModel = workflow.RequestedQualifiedModel()
 		entry.ProviderName = strings.TrimSpace(providerName)
 		entry.UserPath = core.UserPathFromContext(ctx)
+		entry.Labels = core.GetRequestLabels(ctx)
+		images := core.GetInlineImageStats(ctx)
+		entry.ImageCount, entry.ImageBytes = images.Count, images.Bytes
+		template := core.GetPromptTemplate(ctx)
+		entry.TemplateName, entry.TemplateVersion = template.Name, template.Version
+		user := core.GetRequestUser(ctx)
+		entry.UserHash, entry.User = user.Hash, user.Raw
+		entry.AuthKeyID = strings.TrimSpace(core.GetAuthKeyID(ctx))
+		if workflow != nil && workflow.Resolution != nil && workflow.Resolution.Experiment != nil {
+			entry.Experiment = workflow.Resolution.Experiment.Experiment
+			entry.ExperimentVariant = workflow.Resolution.Experiment.Variant
+		}
+		if loop := core.GetToolLoop(ctx); loop != nil {
+			entry.RawData = usage.WithToolLoopIterations(entry.RawData, loop.Iterations)
+		}
+		usage.AnalyzeContent(entry, o.usageLogger.Config(), content)
 		o.usageLogger.Write(entry)
 	}
 }
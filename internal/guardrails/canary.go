@@ -0,0 +1,110 @@
+package guardrails
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// Canary units select the request value hashed for assignment.
+const (
+	CanaryUnitRequestID = "request_id"
+	CanaryUnitAPIKey    = "api_key"
+)
+
+// canaryBuckets is the number of assignment buckets; it gives Percent a
+// resolution of 0.01.
+const canaryBuckets = 10000
+
+// Canary limits a guardrail to a deterministic slice of requests while a
+// change is rolled out. The guardrail name, the salt and the request's unit
+// are hashed into a bucket, so a unit always gets the same decision for the
+// same settings. Requests without a unit are never in the slice.
+type Canary struct {
+	// Percent is the share of requests the guardrail applies to, 0 to 100.
+	Percent float64 `json:"percent"`
+	Salt    string  `json:"salt,omitempty"`
+	Unit    string  `json:"unit,omitempty"`
+}
+
+// Applies reports whether the request identified by unit falls into the
+// canary slice of the named guardrail.
+func (c Canary) Applies(guardrail, unit string) bool {
+	if unit == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(guardrail + "\x00" + c.Salt + "\x00" + unit))
+	bucket := binary.BigEndian.Uint64(sum[:8]) % canaryBuckets
+	return bucket < uint64(math.Round(c.Percent*canaryBuckets/100))
+}
+
+func normalizeCanary(canary *Canary) (*Canary, error) {
+	if canary == nil {
+		return nil, nil
+	}
+	normalized := *canary
+	if math.IsNaN(normalized.Percent) || normalized.Percent < 0 || normalized.Percent > 100 {
+		return nil, newValidationError("canary percent must be between 0 and 100", nil)
+	}
+	normalized.Unit = strings.ToLower(strings.TrimSpace(normalized.Unit))
+	switch normalized.Unit {
+	case "", CanaryUnitRequestID:
+		normalized.Unit = CanaryUnitRequestID
+	case CanaryUnitAPIKey:
+	default:
+		return nil, newValidationError(`canary unit must be "request_id" or "api_key"`, nil)
+	}
+	return &normalized, nil
+}
+
+// withCanary wraps g so it only runs for requests in the canary slice. It
+// returns g unchanged when canary is nil.
+func withCanary(g Guardrail, canary *Canary) Guardrail {
+	if canary == nil {
+		return g
+	}
+	return &canaryGuardrail{Guardrail: g, canary: *canary}
+}
+
+// canaryGuardrail runs the wrapped guardrail for requests in its canary
+// slice, passes the others through unchanged and reports each decision to
+// the request's audit entry.
+type canaryGuardrail struct {
+	Guardrail
+	canary Canary
+}
+
+func (g *canaryGuardrail) Process(ctx context.Context, msgs []Message) ([]Message, error) {
+	applied := g.canary.Applies(g.Name(), canaryUnitValue(ctx, g.canary.Unit))
+	core.RecordGuardrailCanary(ctx, g.Name(), applied)
+	if !applied {
+		return msgs, nil
+	}
+	return g.Guardrail.Process(ctx, msgs)
+}
+
+// canaryUnitValue extracts the assignment unit from the request context.
+func canaryUnitValue(ctx context.Context, unit string) string {
+	snapshot := core.GetRequestSnapshot(ctx)
+	if unit == CanaryUnitAPIKey {
+		if snapshot != nil {
+			token := strings.TrimSpace(strings.TrimPrefix(http.Header(snapshot.GetHeaders()).Get("Authorization"), "Bearer "))
+			if token != "" {
+				return token
+			}
+		}
+		return core.GetAuthKeyID(ctx)
+	}
+	if requestID := core.GetRequestID(ctx); requestID != "" {
+		return requestID
+	}
+	if snapshot != nil {
+		return snapshot.RequestID
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package guardrails
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func TestCanaryApplies_Deterministic(t *testing.T) {
+	canary := Canary{Percent: 5, Salt: "v2"}
+
+	applied := 0
+	for i := range 20000 {
+		unit := fmt.Sprintf("req-%d", i)
+		first := canary.Applies("pii", unit)
+		if first != canary.Applies("pii", unit) {
+			t.Fatalf("Applies(%q) changed between calls", unit)
+		}
+		if first {
+			applied++
+		}
+	}
+	if applied < 800 || applied > 1200 {
+		t.Fatalf("applied to %d of 20000 requests, want about 5%%", applied)
+	}
+
+	if (Canary{Percent: 0}).Applies("pii", "req-1") {
+		t.Fatal("0% canary applied")
+	}
+	if !(Canary{Percent: 100}).Applies("pii", "req-1") {
+		t.Fatal("100% canary skipped")
+	}
+	if (Canary{Percent: 100}).Applies("pii", "") {
+		t.Fatal("canary applied to a request without a unit")
+	}
+}
+
+func TestCanaryApplies_SaltAndNameReshuffle(t *testing.T) {
+	base := Canary{Percent: 50, Salt: "a"}
+	resalted := Canary{Percent: 50, Salt: "b"}
+
+	var saltDiffers, nameDiffers bool
+	for i := range 100 {
+		unit := fmt.Sprintf("req-%d", i)
+		saltDiffers = saltDiffers || base.Applies("pii", unit) != resalted.Applies("pii", unit)
+		nameDiffers = nameDiffers || base.Applies("pii", unit) != base.Applies("tone", unit)
+	}
+	if !saltDiffers || !nameDiffers {
+		t.Fatalf("salt reshuffles = %v, name reshuffles = %v, want both", saltDiffers, nameDiffers)
+	}
+}
+
+func TestCanaryApplies_GrowingPercentKeepsSlice(t *testing.T) {
+	small := Canary{Percent: 5, Salt: "s"}
+	large := Canary{Percent: 25, Salt: "s"}
+	for i := range 2000 {
+		unit := fmt.Sprintf("req-%d", i)
+		if small.Applies("pii", unit) && !large.Applies("pii", unit) {
+			t.Fatalf("unit %q left the slice when the percent grew", unit)
+		}
+	}
+}
+
+func TestNormalizeCanary(t *testing.T) {
+	got, err := normalizeCanary(&Canary{Percent: 5, Unit: " API_KEY "})
+	if err != nil || got.Unit != CanaryUnitAPIKey {
+		t.Fatalf("normalizeCanary() = %+v, %v, want api_key unit", got, err)
+	}
+	got, err = normalizeCanary(&Canary{Percent: 5})
+	if err != nil || got.Unit != CanaryUnitRequestID {
+		t.Fatalf("normalizeCanary() = %+v, %v, want request_id unit", got, err)
+	}
+	for _, invalid := range []Canary{{Percent: -1}, {Percent: 101}, {Percent: 5, Unit: "user"}} {
+		if _, err := normalizeCanary(&invalid); !IsValidationError(err) {
+			t.Errorf("normalizeCanary(%+v) error = %v, want validation error", invalid, err)
+		}
+	}
+}
+
+func TestCanaryGuardrail_RecordsDecisions(t *testing.T) {
+	var calls int
+	inner := &mockGuardrail{
+		name: "pii",
+		processFn: func(_ context.Context, msgs []Message) ([]Message, error) {
+			calls++
+			return append(msgs, Message{Role: "system", Content: "applied"}), nil
+		},
+	}
+	canary := Canary{Percent: 50, Unit: CanaryUnitRequestID}
+	g := withCanary(inner, &canary)
+
+	var appliedID, skippedID string
+	for i := 0; appliedID == "" || skippedID == ""; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		if canary.Applies("pii", id) {
+			appliedID = id
+		} else {
+			skippedID = id
+		}
+	}
+
+	for _, tt := range []struct {
+		requestID string
+		applied   bool
+	}{{appliedID, true}, {skippedID, false}} {
+		canaries := &core.GuardrailCanaries{}
+		ctx := core.WithGuardrailCanaries(core.WithRequestID(context.Background(), tt.requestID), canaries)
+		calls = 0
+
+		msgs, err := g.Process(ctx, []Message{{Role: "user", Content: "hi"}})
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if gotApplied := calls == 1 && len(msgs) == 2; gotApplied != tt.applied {
+			t.Fatalf("request %s applied = %v, want %v", tt.requestID, gotApplied, tt.applied)
+		}
+		want := []core.GuardrailCanaryDecision{{Guardrail: "pii", Applied: tt.applied}}
+		if got := canaries.Decisions(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("decisions = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestCanaryUnitValue_APIKey(t *testing.T) {
+	snapshot := core.NewRequestSnapshot(http.MethodPost, "/v1/chat/completions", nil, nil,
+		map[string][]string{"Authorization": {"Bearer sk-test"}}, "application/json", nil, false, "req-1", nil)
+	ctx := core.WithRequestSnapshot(context.Background(), snapshot)
+
+	if got := canaryUnitValue(ctx, CanaryUnitAPIKey); got != "sk-test" {
+		t.Fatalf("canaryUnitValue(api_key) = %q, want sk-test", got)
+	}
+	if got := canaryUnitValue(ctx, CanaryUnitRequestID); got != "req-1" {
+		t.Fatalf("canaryUnitValue(request_id) = %q, want req-1", got)
+	}
+}
+
+func TestNormalizeDefinition_Canary(t *testing.T) {
+	def, err := normalizeDefinition(Definition{
+		Name:   "policy",
+		Type:   "system_prompt",
+		Config: json.RawMessage(`{"mode":"inject","content":"be nice","canary":{"percent":5,"salt":"v2"}}`),
+	})
+	if err != nil {
+		t.Fatalf("normalizeDefinition() error = %v", err)
+	}
+	if want := `{"mode":"inject","content":"be nice","canary":{"percent":5,"salt":"v2","unit":"request_id"}}`; string(def.Config) != want {
+		t.Fatalf("config = %s, want %s", def.Config, want)
+	}
+	if got := summarizeDefinition(def); got != "inject • be nice • 5% canary" {
+		t.Fatalf("summary = %q", got)
+	}
+
+	instance, _, err := buildDefinition(def, nil)
+	if err != nil {
+		t.Fatalf("buildDefinition() error = %v", err)
+	}
+	if _, ok := instance.(*canaryGuardrail); !ok {
+		t.Fatalf("buildDefinition() = %T, want a canary guardrail", instance)
+	}
+
+	_, err = normalizeDefinition(Definition{
+		Name:   "policy",
+		Type:   "system_prompt",
+		Config: json.RawMessage(`{"content":"x","canary":{"percent":150}}`),
+	})
+	if !IsValidationError(err) {
+		t.Fatalf("normalizeDefinition(percent 150) error = %v, want validation error", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -65,8 +66,9 @@ type TypeDefinition struct {
 }
 
 type systemPromptDefinitionConfig struct {
-	Mode    string `json:"mode"`
-	Content string `json:"content"`
+	Mode    string  `json:"mode"`
+	Content string  `json:"content"`
+	Canary  *Canary `json:"canary,omitempty"`
 }
 
 type llmBasedAlteringDefinitionConfig struct {
@@ -76,6 +78,7 @@ type llmBasedAlteringDefinitionConfig struct {
 	Roles             []string `json:"roles,omitempty"`
 	SkipContentPrefix string   `json:"skip_content_prefix,omitempty"`
 	MaxTokens         int      `json:"max_tokens,omitempty"`
+	Canary            *Canary  `json:"canary,omitempty"`
 }
 
 func normalizeDefinition(def Definition) (Definition, error) {
@@ -191,6 +194,11 @@ func decodeSystemPromptDefinitionConfig(raw json.RawMessage) (systemPromptDefini
 	if cfg.Content == "" {
 		return systemPromptDefinitionConfig{}, newValidationError("system_prompt content is required", nil)
 	}
+	canary, err := normalizeCanary(cfg.Canary)
+	if err != nil {
+		return systemPromptDefinitionConfig{}, err
+	}
+	cfg.Canary = canary
 	return cfg, nil
 }
 
@@ -229,6 +237,11 @@ func decodeLLMBasedAlteringDefinitionConfig(raw json.RawMessage) (llmBasedAlteri
 		return llmBasedAlteringDefinitionConfig{}, newValidationError(err.Error(), err)
 	}
 	cfg.Roles = roles
+	canary, err := normalizeCanary(cfg.Canary)
+	if err != nil {
+		return llmBasedAlteringDefinitionConfig{}, err
+	}
+	cfg.Canary = canary
 	return cfg, nil
 }
 
@@ -260,7 +273,7 @@ func buildDefinition(def Definition, executor ChatCompletionExecutor) (Guardrail
 		if err != nil {
 			return nil, responsecache.GuardrailRuleDescriptor{}, newValidationError("build system_prompt guardrail: "+err.Error(), err)
 		}
-		return instance, responsecache.GuardrailRuleDescriptor{
+		return withCanary(instance, cfg.Canary), responsecache.GuardrailRuleDescriptor{
 			Name:    def.Name,
 			Type:    def.Type,
 			Mode:    string(mode),
@@ -276,13 +289,13 @@ func buildDefinition(def Definition, executor ChatCompletionExecutor) (Guardrail
 			return nil, responsecache.GuardrailRuleDescriptor{}, newValidationError("build llm_based_altering guardrail: "+err.Error(), err)
 		}
 		if executor == nil {
-			return &unavailableGuardrail{
+			return withCanary(&unavailableGuardrail{
 					name: def.Name,
 					message: fmt.Sprintf(
 						`guardrail %q of type "llm_based_altering" cannot execute because the auxiliary executor is not configured`,
 						def.Name,
 					),
-				},
+				}, cfg.Canary),
 				llmBasedAlteringDescriptor(def.Name, runtimeCfg),
 				nil
 		}
@@ -290,13 +303,32 @@ func buildDefinition(def Definition, executor ChatCompletionExecutor) (Guardrail
 		if err != nil {
 			return nil, responsecache.GuardrailRuleDescriptor{}, newValidationError("build llm_based_altering guardrail: "+err.Error(), err)
 		}
-		return instance, llmBasedAlteringDescriptor(def.Name, runtimeCfg), nil
+		return withCanary(instance, cfg.Canary), llmBasedAlteringDescriptor(def.Name, runtimeCfg), nil
 	default:
 		return nil, responsecache.GuardrailRuleDescriptor{}, newValidationError(`unknown guardrail type: "`+def.Type+`"`, nil)
 	}
 }
 
 func summarizeDefinition(def Definition) string {
+	summary := summarizeDefinitionConfig(def)
+	if canary := definitionCanary(def); canary != nil && summary != "" {
+		summary += " • " + strconv.FormatFloat(canary.Percent, 'f', -1, 64) + "% canary"
+	}
+	return summary
+}
+
+// definitionCanary returns the canary settings of a definition, if any.
+func definitionCanary(def Definition) *Canary {
+	var cfg struct {
+		Canary *Canary `json:"canary"`
+	}
+	if err := json.Unmarshal(def.Config, &cfg); err != nil {
+		return nil
+	}
+	return cfg.Canary
+}
+
+func summarizeDefinitionConfig(def Definition) string {
 	switch def.Type {
 	case "system_prompt":
 		cfg, err := decodeSystemPromptDefinitionConfig(def.Config)
@@ -25,6 +25,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the guardrail_definitions table
+// and its indexes. It is idempotent: store constructors run it, and so does
+// the baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	statements := []string{
 		`CREATE TABLE IF NOT EXISTS guardrail_definitions (
 			name TEXT PRIMARY KEY,
@@ -41,11 +52,10 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 	}
 	for _, statement := range statements {
 		if _, err := pool.Exec(ctx, statement); err != nil {
-			return nil, fmt.Errorf("initialize guardrail definitions table: %w", err)
+			return fmt.Errorf("initialize guardrail definitions table: %w", err)
 		}
 	}
-
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 func (s *PostgreSQLStore) List(ctx context.Context) ([]Definition, error) {
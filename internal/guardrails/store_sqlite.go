@@ -23,6 +23,17 @@ func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
+	if err := CreateSQLiteSchema(ctx, db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the guardrail_definitions table and
+// its indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
 	statements := []string{
 		`CREATE TABLE IF NOT EXISTS guardrail_definitions (
 			name TEXT PRIMARY KEY,
@@ -42,11 +53,10 @@ func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
 			if statement == `ALTER TABLE guardrail_definitions ADD COLUMN user_path TEXT` && isSQLiteDuplicateColumnError(err) {
 				continue
 			}
-			return nil, fmt.Errorf("initialize guardrail definitions table: %w", err)
+			return fmt.Errorf("initialize guardrail definitions table: %w", err)
 		}
 	}
-
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 func (s *SQLiteStore) List(ctx context.Context) ([]Definition, error) {
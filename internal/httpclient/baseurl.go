@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateBaseURL checks an upstream base URL such as
+// "https://proxy.internal/openai/v1". It must be an absolute http or https
+// URL with a host and may carry a path prefix, but not a query string or a
+// fragment: endpoints are appended to it, so either would end up in the
+// middle of every request URL.
+func ValidateBaseURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL, got %q", raw)
+	}
+	if u.RawQuery != "" || u.ForceQuery {
+		return fmt.Errorf("must not have a query string, got %q; use extra_query for query parameters", u.Redacted())
+	}
+	if u.Fragment != "" || strings.Contains(raw, "#") {
+		return fmt.Errorf("must not have a fragment, got %q", u.Redacted())
+	}
+	return nil
+}
+
+// JoinURL appends endpoint, a path with an optional query string, to
+// baseURL. Trailing slashes of baseURL and leading slashes of endpoint
+// collapse into one, so a path prefix of baseURL is kept whether or not it
+// ends in a slash.
+func JoinURL(baseURL, endpoint string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if endpoint == "" || strings.HasPrefix(endpoint, "?") {
+		return baseURL + endpoint
+	}
+	return baseURL + "/" + strings.TrimLeft(endpoint, "/")
+}
@@ -0,0 +1,51 @@
+package httpclient
+
+import "testing"
+
+func TestValidateBaseURL(t *testing.T) {
+	for _, raw := range []string{
+		"https://api.openai.com/v1",
+		"https://proxy.internal/openai/v1/",
+		"http://localhost:11434",
+		" https://proxy.internal ",
+	} {
+		if err := ValidateBaseURL(raw); err != nil {
+			t.Errorf("ValidateBaseURL(%q) error = %v", raw, err)
+		}
+	}
+	for _, raw := range []string{
+		"api.openai.com/v1",
+		"ftp://proxy.internal",
+		"https://",
+		"://bad",
+		"https://proxy.internal/v1?api-version=2024-10-21",
+		"https://proxy.internal/v1?",
+		"https://proxy.internal/v1#chat",
+		"https://proxy.internal/v1#",
+	} {
+		if err := ValidateBaseURL(raw); err == nil {
+			t.Errorf("ValidateBaseURL(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		endpoint string
+		want     string
+	}{
+		{"https://api.openai.com/v1", "/chat/completions", "https://api.openai.com/v1/chat/completions"},
+		{"https://proxy.internal/openai/v1/", "/chat/completions", "https://proxy.internal/openai/v1/chat/completions"},
+		{"https://proxy.internal/openai/v1//", "chat/completions", "https://proxy.internal/openai/v1/chat/completions"},
+		{"https://proxy.internal", "/models?limit=1000", "https://proxy.internal/models?limit=1000"},
+		{"https://proxy.internal/", "/models", "https://proxy.internal/models"},
+		{"https://proxy.internal/v1", "?api-version=1", "https://proxy.internal/v1?api-version=1"},
+		{"https://proxy.internal/v1/", "", "https://proxy.internal/v1"},
+	}
+	for _, tt := range tests {
+		if got := JoinURL(tt.baseURL, tt.endpoint); got != tt.want {
+			t.Errorf("JoinURL(%q, %q) = %q, want %q", tt.baseURL, tt.endpoint, got, tt.want)
+		}
+	}
+}
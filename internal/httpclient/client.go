@@ -2,13 +2,26 @@
 package httpclient
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Connection pool defaults of DefaultConfig.
+const (
+	DefaultMaxIdleConns        = 1024
+	DefaultMaxIdleConnsPerHost = 256
+	DefaultIdleConnTimeout     = 120 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
 // ClientConfig holds configuration options for creating HTTP clients
 type ClientConfig struct {
 	// MaxIdleConns controls the maximum number of idle (keep-alive) connections across all hosts
@@ -17,6 +30,9 @@ type ClientConfig struct {
 	// MaxIdleConnsPerHost controls the maximum idle (keep-alive) connections to keep per-host
 	MaxIdleConnsPerHost int
 
+	// MaxConnsPerHost limits the total connections per host, including those in use; 0 means no limit
+	MaxConnsPerHost int
+
 	// IdleConnTimeout is the maximum amount of time an idle (keep-alive) connection will remain idle before closing itself
 	IdleConnTimeout time.Duration
 
@@ -32,8 +48,18 @@ type ClientConfig struct {
 	// TLSHandshakeTimeout specifies the maximum amount of time to wait for a TLS handshake
 	TLSHandshakeTimeout time.Duration
 
+	// ForceAttemptHTTP2 negotiates HTTP/2 even with a custom dialer or TLS config
+	ForceAttemptHTTP2 bool
+
 	// ResponseHeaderTimeout specifies the amount of time to wait for a server's response headers
 	ResponseHeaderTimeout time.Duration
+
+	// Proxy selects the proxy for each request. Nil uses the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// TLSClientConfig customizes server certificate verification. Nil uses Go's defaults.
+	TLSClientConfig *tls.Config
 }
 
 // getEnvDuration reads a duration from an environment variable, returning the default if not set or invalid.
@@ -55,7 +81,10 @@ func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 }
 
 // DefaultConfig returns a ClientConfig with sensible defaults for API clients.
-// Timeout values match OpenAI/Anthropic SDK defaults (10 minutes).
+// Timeout values match OpenAI/Anthropic SDK defaults (10 minutes). The idle
+// pool is sized for a gateway holding hundreds of concurrent requests to a
+// few provider hosts, so sustained load reuses connections instead of paying
+// a TLS handshake for each one.
 // Can be overridden via environment variables (values in seconds, or Go duration format):
 //   - HTTP_TIMEOUT: overall request timeout (default: 600)
 //   - HTTP_RESPONSE_HEADER_TIMEOUT: time to wait for response headers (default: 600)
@@ -66,13 +95,14 @@ func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
 func DefaultConfig() ClientConfig {
 	defaultLongTimeout := 600 * time.Second
 	return ClientConfig{
-		MaxIdleConns:          100,
-		MaxIdleConnsPerHost:   100,
-		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          DefaultMaxIdleConns,
+		MaxIdleConnsPerHost:   DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       DefaultIdleConnTimeout,
 		Timeout:               getEnvDuration("HTTP_TIMEOUT", defaultLongTimeout),
 		DialTimeout:           30 * time.Second,
 		KeepAlive:             30 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
+		TLSHandshakeTimeout:   DefaultTLSHandshakeTimeout,
+		ForceAttemptHTTP2:     true,
 		ResponseHeaderTimeout: getEnvDuration("HTTP_RESPONSE_HEADER_TIMEOUT", defaultLongTimeout),
 	}
 }
@@ -85,25 +115,54 @@ func NewHTTPClient(config *ClientConfig) *http.Client {
 		config = &cfg
 	}
 
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
 	transport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: proxy,
 		DialContext: (&net.Dialer{
 			Timeout:   config.DialTimeout,
 			KeepAlive: config.KeepAlive,
 		}).DialContext,
 		MaxIdleConns:          config.MaxIdleConns,
 		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
 		IdleConnTimeout:       config.IdleConnTimeout,
 		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
 		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
-		ForceAttemptHTTP2:     true,
+		ForceAttemptHTTP2:     config.ForceAttemptHTTP2,
 		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       config.TLSClientConfig,
 	}
 
 	return &http.Client{
-		Transport: transport,
-		Timeout:   config.Timeout,
+		Transport:     transport,
+		Timeout:       config.Timeout,
+		CheckRedirect: refuseCrossHostRedirect,
+	}
+}
+
+// maxRedirects matches the redirect limit of Go's default client.
+const maxRedirects = 10
+
+// ErrCrossHostRedirect is returned when an upstream redirects a request to a
+// different host or scheme. Following it would send the request, with the
+// provider credentials, somewhere other than the configured provider.
+var ErrCrossHostRedirect = errors.New("refusing redirect to a different host")
+
+// refuseCrossHostRedirect follows redirects only within the scheme and host
+// of the original request.
+func refuseCrossHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	original := via[0].URL
+	if !strings.EqualFold(req.URL.Host, original.Host) || !strings.EqualFold(req.URL.Scheme, original.Scheme) {
+		return fmt.Errorf("%w: %s://%s redirected to %s://%s", ErrCrossHostRedirect, original.Scheme, original.Host, req.URL.Scheme, req.URL.Host)
 	}
+	return nil
 }
 
 // NewDefaultHTTPClient creates a new HTTP client with default configuration.
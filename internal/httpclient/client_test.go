@@ -1,7 +1,9 @@
 package httpclient
 
 import (
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -9,16 +11,24 @@ import (
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
-	if config.MaxIdleConns != 100 {
-		t.Errorf("Expected MaxIdleConns to be 100, got %d", config.MaxIdleConns)
+	if config.MaxIdleConns != 1024 {
+		t.Errorf("Expected MaxIdleConns to be 1024, got %d", config.MaxIdleConns)
 	}
 
-	if config.MaxIdleConnsPerHost != 100 {
-		t.Errorf("Expected MaxIdleConnsPerHost to be 100, got %d", config.MaxIdleConnsPerHost)
+	if config.MaxIdleConnsPerHost != 256 {
+		t.Errorf("Expected MaxIdleConnsPerHost to be 256, got %d", config.MaxIdleConnsPerHost)
 	}
 
-	if config.IdleConnTimeout != 90*time.Second {
-		t.Errorf("Expected IdleConnTimeout to be 90s, got %v", config.IdleConnTimeout)
+	if config.MaxConnsPerHost != 0 {
+		t.Errorf("Expected MaxConnsPerHost to be 0 (no limit), got %d", config.MaxConnsPerHost)
+	}
+
+	if config.IdleConnTimeout != 120*time.Second {
+		t.Errorf("Expected IdleConnTimeout to be 120s, got %v", config.IdleConnTimeout)
+	}
+
+	if !config.ForceAttemptHTTP2 {
+		t.Error("Expected ForceAttemptHTTP2 to be true")
 	}
 
 	// Default timeout is 600s (10 minutes) to match OpenAI/Anthropic SDKs
@@ -102,12 +112,14 @@ func TestNewHTTPClient(t *testing.T) {
 			config: &ClientConfig{
 				MaxIdleConns:          50,
 				MaxIdleConnsPerHost:   25,
+				MaxConnsPerHost:       40,
 				IdleConnTimeout:       60 * time.Second,
 				Timeout:               15 * time.Second,
 				DialTimeout:           10 * time.Second,
 				KeepAlive:             15 * time.Second,
 				TLSHandshakeTimeout:   5 * time.Second,
 				ResponseHeaderTimeout: 5 * time.Second,
+				ForceAttemptHTTP2:     true,
 			},
 		},
 	}
@@ -146,6 +158,10 @@ func TestNewHTTPClient(t *testing.T) {
 				t.Errorf("Expected MaxIdleConnsPerHost to be %d, got %d", expectedConfig.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
 			}
 
+			if transport.MaxConnsPerHost != expectedConfig.MaxConnsPerHost {
+				t.Errorf("Expected MaxConnsPerHost to be %d, got %d", expectedConfig.MaxConnsPerHost, transport.MaxConnsPerHost)
+			}
+
 			if transport.IdleConnTimeout != expectedConfig.IdleConnTimeout {
 				t.Errorf("Expected IdleConnTimeout to be %v, got %v", expectedConfig.IdleConnTimeout, transport.IdleConnTimeout)
 			}
@@ -257,3 +273,52 @@ func TestClientConfigZeroValues(t *testing.T) {
 		t.Errorf("Expected Timeout to be 0, got %v", client.Timeout)
 	}
 }
+
+func TestNewHTTPClient_RefusesCrossHostRedirect(t *testing.T) {
+	var otherHits int
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		otherHits++
+	}))
+	defer other.Close()
+
+	var sawAuthorization string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/elsewhere":
+			http.Redirect(w, r, other.URL+"/steal", http.StatusFound)
+		case "/moved":
+			http.Redirect(w, r, "/v1/models", http.StatusMovedPermanently)
+		default:
+			sawAuthorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer provider.Close()
+
+	client := NewHTTPClient(nil)
+
+	req, _ := http.NewRequest(http.MethodGet, provider.URL+"/elsewhere", nil)
+	req.Header.Set("Authorization", "Bearer sk-provider")
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the cross-host redirect to be refused")
+	}
+	if !errors.Is(err, ErrCrossHostRedirect) {
+		t.Fatalf("error = %v, want ErrCrossHostRedirect", err)
+	}
+	if otherHits != 0 {
+		t.Fatalf("redirect target received %d requests", otherHits)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, provider.URL+"/moved", nil)
+	req.Header.Set("Authorization", "Bearer sk-provider")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("same-host redirect failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || sawAuthorization != "Bearer sk-provider" {
+		t.Fatalf("same-host redirect = %d, authorization %q", resp.StatusCode, sawAuthorization)
+	}
+}
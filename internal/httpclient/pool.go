@@ -0,0 +1,48 @@
+package httpclient
+
+import "time"
+
+// PoolOptions tunes the connection pool of one upstream. Zero fields keep
+// the DefaultConfig values.
+type PoolOptions struct {
+	// MaxIdleConnsPerHost caps the keep-alive connections kept per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps all connections per host, in use or idle.
+	// Requests over the cap wait for a connection to free up.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections idle for longer.
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake of new connections.
+	TLSHandshakeTimeout time.Duration
+	// ForceAttemptHTTP2 overrides whether HTTP/2 is negotiated; nil keeps the
+	// default, which attempts it.
+	ForceAttemptHTTP2 *bool
+}
+
+// IsZero reports whether no option is set.
+func (o PoolOptions) IsZero() bool {
+	return o.MaxIdleConnsPerHost == 0 && o.MaxConnsPerHost == 0 && o.IdleConnTimeout == 0 &&
+		o.TLSHandshakeTimeout == 0 && o.ForceAttemptHTTP2 == nil
+}
+
+// Apply sets the pool options on cfg. The total idle connection cap is
+// raised to MaxIdleConnsPerHost when it is lower, so the per-host cap takes
+// effect.
+func (o PoolOptions) Apply(cfg *ClientConfig) {
+	if o.MaxIdleConnsPerHost > 0 {
+		cfg.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+		cfg.MaxIdleConns = max(cfg.MaxIdleConns, o.MaxIdleConnsPerHost)
+	}
+	if o.MaxConnsPerHost > 0 {
+		cfg.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		cfg.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.TLSHandshakeTimeout > 0 {
+		cfg.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+	if o.ForceAttemptHTTP2 != nil {
+		cfg.ForceAttemptHTTP2 = *o.ForceAttemptHTTP2
+	}
+}
@@ -0,0 +1,28 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolOptions_Apply(t *testing.T) {
+	cfg := DefaultConfig()
+	PoolOptions{}.Apply(&cfg)
+	if cfg.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || cfg.MaxConnsPerHost != 0 ||
+		cfg.IdleConnTimeout != DefaultIdleConnTimeout || !cfg.ForceAttemptHTTP2 {
+		t.Fatalf("zero options changed the config: %+v", cfg)
+	}
+
+	disabled := false
+	PoolOptions{
+		MaxIdleConnsPerHost: 2048,
+		MaxConnsPerHost:     512,
+		IdleConnTimeout:     5 * time.Minute,
+		TLSHandshakeTimeout: 3 * time.Second,
+		ForceAttemptHTTP2:   &disabled,
+	}.Apply(&cfg)
+	if cfg.MaxIdleConnsPerHost != 2048 || cfg.MaxIdleConns != 2048 || cfg.MaxConnsPerHost != 512 ||
+		cfg.IdleConnTimeout != 5*time.Minute || cfg.TLSHandshakeTimeout != 3*time.Second || cfg.ForceAttemptHTTP2 {
+		t.Fatalf("config = %+v, want every pool option applied and the idle cap raised", cfg)
+	}
+}
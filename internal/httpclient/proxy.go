@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TransportOptions holds the proxy, TLS and connection pool settings of one
+// upstream.
+type TransportOptions struct {
+	ProxyURL           string
+	NoProxy            []string
+	CAFile             string
+	InsecureSkipVerify bool
+	Pool               PoolOptions
+}
+
+// IsZero reports whether no option is set, so the default client applies.
+func (o TransportOptions) IsZero() bool {
+	return o.ProxyURL == "" && o.CAFile == "" && !o.InsecureSkipVerify && o.Pool.IsZero()
+}
+
+// Apply sets the proxy, TLS and pool settings on cfg, failing on an invalid
+// proxy URL or an unreadable CA bundle.
+func (o TransportOptions) Apply(cfg *ClientConfig) error {
+	o.Pool.Apply(cfg)
+	if o.ProxyURL != "" {
+		proxyURL, err := ParseProxyURL(o.ProxyURL)
+		if err != nil {
+			return err
+		}
+		cfg.Proxy = ProxyFunc(proxyURL, o.NoProxy)
+	}
+	tlsCfg, err := LoadTLSConfig(o.CAFile, o.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+	cfg.TLSClientConfig = tlsCfg
+	return nil
+}
+
+// ParseProxyURL parses an upstream proxy URL. The http, https, socks5 and
+// socks5h schemes are supported; socks5h resolves host names on the proxy.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q: must be http, https, socks5 or socks5h", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", u.Redacted())
+	}
+	return u, nil
+}
+
+// ProxyFunc returns a Transport.Proxy function that sends every request
+// through proxyURL except those whose host matches a noProxy entry.
+//
+// Entries follow the NO_PROXY conventions: "*" matches every host, an IP
+// address or CIDR range matches IP hosts, "example.com" matches the domain
+// and its subdomains, ".example.com" matches only subdomains, and an optional
+// ":port" suffix restricts a host entry to one port. Unlike NO_PROXY,
+// loopback hosts are proxied unless listed.
+func ProxyFunc(proxyURL *url.URL, noProxy []string) func(*http.Request) (*url.URL, error) {
+	matcher := newNoProxyMatcher(noProxy)
+	return func(req *http.Request) (*url.URL, error) {
+		if matcher.matches(req.URL) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// LoadTLSConfig builds the TLS settings for a provider behind internal TLS.
+// caFile adds a PEM CA bundle to the system roots. It returns nil when
+// neither option is set so the transport keeps Go's defaults.
+func LoadTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	caFile = strings.TrimSpace(caFile)
+	if caFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify, //nolint:gosec // explicitly enabled per provider
+	}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", caFile)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+type noProxyMatcher struct {
+	all      bool
+	networks []*net.IPNet
+	ips      []net.IP
+	hosts    []noProxyHost
+}
+
+type noProxyHost struct {
+	name string
+	// subdomainsOnly is set for entries written with a leading dot.
+	subdomainsOnly bool
+	port           string
+}
+
+func newNoProxyMatcher(entries []string) *noProxyMatcher {
+	m := &noProxyMatcher{}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			m.all = true
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			m.networks = append(m.networks, network)
+			continue
+		}
+		if ip := net.ParseIP(strings.Trim(entry, "[]")); ip != nil {
+			m.ips = append(m.ips, ip)
+			continue
+		}
+
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		if ip := net.ParseIP(host); ip != nil && port == "" {
+			m.ips = append(m.ips, ip)
+			continue
+		}
+		m.hosts = append(m.hosts, noProxyHost{
+			name:           strings.TrimPrefix(strings.TrimPrefix(host, "*"), "."),
+			subdomainsOnly: strings.HasPrefix(host, ".") || strings.HasPrefix(host, "*."),
+			port:           port,
+		})
+	}
+	return m
+}
+
+func (m *noProxyMatcher) matches(u *url.URL) bool {
+	if m.all {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range m.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		for _, candidate := range m.ips {
+			if candidate.Equal(ip) {
+				return true
+			}
+		}
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(u.Scheme)
+	}
+	for _, entry := range m.hosts {
+		if entry.port != "" && entry.port != port {
+			continue
+		}
+		if host == entry.name && !entry.subdomainsOnly {
+			return true
+		}
+		if strings.HasSuffix(host, "."+entry.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultPort(scheme string) string {
+	if strings.EqualFold(scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
@@ -0,0 +1,366 @@
+// Package idempotency deduplicates model requests sent with an
+// Idempotency-Key header, so a double-submitted request is answered with the
+// response of the first one instead of a second provider call.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/cache"
+	"gomodel/internal/memgovernor"
+)
+
+const keyPrefix = "idempotency:"
+
+// Record is the stored response of an idempotent request.
+type Record struct {
+	// Fingerprint identifies the request body the key was first used with.
+	Fingerprint string `json:"fingerprint"`
+	// RequestID is the request id of the execution that produced the response.
+	RequestID string      `json:"request_id"`
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+}
+
+// Decision tells the caller of Begin how to answer a request.
+type Decision int
+
+const (
+	// DecisionExecute runs the request; the caller must end the execution
+	// with Finish or Abort.
+	DecisionExecute Decision = iota
+	// DecisionReplay answers with Result.Record without running the request.
+	DecisionReplay
+	// DecisionAttach streams the response of the running execution.
+	DecisionAttach
+	// DecisionConflict rejects a key reused with a different request body.
+	DecisionConflict
+	// DecisionInProgress rejects a duplicate of a running streaming request.
+	DecisionInProgress
+)
+
+// Result is the outcome of Begin.
+type Result struct {
+	Decision Decision
+	// Record is set for DecisionReplay.
+	Record *Record
+	// Execution is the caller's execution for DecisionExecute and the
+	// execution to follow for DecisionAttach.
+	Execution *Execution
+	// OriginalRequestID is the request id of the first request with the key.
+	OriginalRequestID string
+}
+
+// Service coordinates executions per key. Finished responses are kept in a
+// cache.Store; running executions are tracked in process, so duplicates that
+// arrive while the first request runs wait for it or attach to its stream.
+type Service struct {
+	store        cache.Store
+	ttl          time.Duration
+	maxBodyBytes int
+	attach       bool
+
+	mu       sync.Mutex
+	inflight map[string]*Execution
+}
+
+// New creates a service backed by an in-memory LRU store sized by cfg. It
+// returns nil when idempotency is disabled.
+func New(cfg config.IdempotencyConfig) *Service {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewWithStore(cache.NewLRUStore(cfg.MaxEntries, 0), cfg)
+}
+
+// NewWithStore creates a service that keeps finished responses in store.
+func NewWithStore(store cache.Store, cfg config.IdempotencyConfig) *Service {
+	return &Service{
+		store:        store,
+		ttl:          cfg.TTL,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		attach:       cfg.StreamDuplicates != config.IdempotencyStreamReject,
+		inflight:     make(map[string]*Execution),
+	}
+}
+
+// Key derives the storage key of an idempotency key used by one caller.
+// scope separates callers, such as a hash of their API key.
+func Key(scope, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + idempotencyKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint identifies a request by method, path and body.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin decides how to answer a request with key and fingerprint. A
+// duplicate of a running non-streaming request waits for it to finish.
+func (s *Service) Begin(ctx context.Context, key, fingerprint, requestID string, stream bool) (Result, error) {
+	for {
+		s.mu.Lock()
+		running, ok := s.inflight[key]
+		if !ok {
+			exec := newExecution(s, key, fingerprint, requestID, stream)
+			s.inflight[key] = exec
+			s.mu.Unlock()
+			return s.beginOwned(ctx, exec)
+		}
+		s.mu.Unlock()
+
+		switch {
+		case running.fingerprint != fingerprint:
+			return Result{Decision: DecisionConflict, OriginalRequestID: running.requestID}, nil
+		case running.stream && s.attach:
+			return Result{Decision: DecisionAttach, Execution: running, OriginalRequestID: running.requestID}, nil
+		case running.stream:
+			return Result{Decision: DecisionInProgress, OriginalRequestID: running.requestID}, nil
+		}
+
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+			return Result{}, ctx.Err()
+		}
+		if record := running.finished(); record != nil {
+			return Result{Decision: DecisionReplay, Record: record, OriginalRequestID: record.RequestID}, nil
+		}
+		// The running execution was aborted; try to run the request again.
+	}
+}
+
+// beginOwned checks the store for a finished response once exec is
+// registered, so concurrent duplicates wait on exec instead of racing it.
+func (s *Service) beginOwned(ctx context.Context, exec *Execution) (Result, error) {
+	data, err := s.store.Get(ctx, keyPrefix+exec.key)
+	if err != nil {
+		exec.Abort()
+		return Result{}, err
+	}
+	if data == nil {
+		return Result{Decision: DecisionExecute, Execution: exec, OriginalRequestID: exec.requestID}, nil
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		slog.Warn("idempotency: dropping unreadable record", "error", err)
+		return Result{Decision: DecisionExecute, Execution: exec, OriginalRequestID: exec.requestID}, nil
+	}
+	s.release(exec, &record)
+	if record.Fingerprint != exec.fingerprint {
+		return Result{Decision: DecisionConflict, OriginalRequestID: record.RequestID}, nil
+	}
+	return Result{Decision: DecisionReplay, Record: &record, OriginalRequestID: record.RequestID}, nil
+}
+
+// release ends exec with record, or aborts it when record is nil.
+func (s *Service) release(exec *Execution, record *Record) {
+	s.mu.Lock()
+	if s.inflight[exec.key] == exec {
+		delete(s.inflight, exec.key)
+	}
+	s.mu.Unlock()
+	exec.finish(record)
+}
+
+func (s *Service) save(ctx context.Context, key string, record *Record) {
+	if len(record.Body) > s.maxBodyBytes || !Storable(record.Status) {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		slog.Warn("idempotency: failed to encode record", "error", err)
+		return
+	}
+	if err := s.store.Set(ctx, keyPrefix+key, data, s.ttl); err != nil {
+		slog.Warn("idempotency: failed to store record", "error", err)
+	}
+}
+
+// MemoryUsage returns the size of the stored responses when they are kept in
+// process memory, and zero for shared stores such as Redis.
+func (s *Service) MemoryUsage() (int, int64) {
+	if store, ok := s.store.(memgovernor.Store); ok {
+		return store.MemoryUsage()
+	}
+	return 0, 0
+}
+
+// Evict drops the least recently used fraction of the stored responses kept
+// in process memory. A dropped key runs its request again; running
+// executions are not affected.
+func (s *Service) Evict(fraction float64) {
+	if store, ok := s.store.(memgovernor.Store); ok {
+		store.Evict(fraction)
+	}
+}
+
+// Storable reports whether a response with status is kept for replay.
+// Server errors and rate limits are not, so a retry with the same key runs
+// the request again.
+func Storable(status int) bool {
+	return status >= 200 && status < 500 && status != http.StatusTooManyRequests
+}
+
+// Execution is a running request. Its owner records the response as it is
+// written; followers of a streaming execution read it as it grows.
+type Execution struct {
+	service     *Service
+	key         string
+	fingerprint string
+	requestID   string
+	stream      bool
+	done        chan struct{}
+
+	mu      sync.Mutex
+	status  int
+	header  http.Header
+	body    []byte
+	record  *Record
+	changed chan struct{}
+}
+
+func newExecution(s *Service, key, fingerprint, requestID string, stream bool) *Execution {
+	return &Execution{
+		service:     s,
+		key:         key,
+		fingerprint: fingerprint,
+		requestID:   requestID,
+		stream:      stream,
+		done:        make(chan struct{}),
+		changed:     make(chan struct{}),
+	}
+}
+
+// WriteHeader records the response status and header.
+func (e *Execution) WriteHeader(status int, header http.Header) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.status != 0 {
+		return
+	}
+	e.status = status
+	e.header = header.Clone()
+	e.notifyLocked()
+}
+
+// Write appends to the recorded response body.
+func (e *Execution) Write(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	e.body = append(e.body, b...)
+	e.notifyLocked()
+}
+
+func (e *Execution) notifyLocked() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// Finish ends the execution with the recorded response, hands it to waiting
+// duplicates and stores it for later ones.
+func (e *Execution) Finish(ctx context.Context) {
+	e.mu.Lock()
+	if e.status == 0 {
+		e.status = http.StatusOK
+	}
+	record := &Record{
+		Fingerprint: e.fingerprint,
+		RequestID:   e.requestID,
+		Status:      e.status,
+		Header:      e.header,
+		Body:        append([]byte(nil), e.body...),
+	}
+	e.mu.Unlock()
+
+	e.service.save(ctx, e.key, record)
+	e.service.release(e, record)
+}
+
+// Abort ends the execution without a response. Waiting duplicates run the
+// request again.
+func (e *Execution) Abort() {
+	e.service.release(e, nil)
+}
+
+func (e *Execution) finish(record *Record) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+	e.record = record
+	close(e.done)
+	e.notifyLocked()
+}
+
+func (e *Execution) finished() *Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.record
+}
+
+// Follow sends the response of a streaming execution to a follower: start
+// once the status is known, then every body chunk from offset zero as it is
+// written. It returns when the execution ends or ctx is done; aborted is
+// true when the execution ended without a response.
+func (e *Execution) Follow(ctx context.Context, start func(status int, header http.Header), write func([]byte) error) (aborted bool, err error) {
+	started := false
+	offset := 0
+	for {
+		e.mu.Lock()
+		status, header := e.status, e.header
+		chunk := e.body[offset:]
+		changed := e.changed
+		var finished, hasRecord bool
+		select {
+		case <-e.done:
+			finished, hasRecord = true, e.record != nil
+		default:
+		}
+		e.mu.Unlock()
+
+		if finished && !hasRecord && !started {
+			return true, nil
+		}
+		if status != 0 && !started {
+			start(status, header)
+			started = true
+		}
+		if len(chunk) > 0 {
+			if err := write(chunk); err != nil {
+				return false, err
+			}
+			offset += len(chunk)
+		}
+		if finished {
+			return false, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+func newTestService(streamDuplicates string) *Service {
+	return New(config.IdempotencyConfig{
+		Enabled:          true,
+		TTL:              time.Hour,
+		MaxEntries:       10,
+		MaxBodyBytes:     1024,
+		StreamDuplicates: streamDuplicates,
+	})
+}
+
+func mustBegin(t *testing.T, s *Service, key, fingerprint, requestID string, stream bool) Result {
+	t.Helper()
+	result, err := s.Begin(context.Background(), key, fingerprint, requestID, stream)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	return result
+}
+
+func finishWith(exec *Execution, status int, body string) {
+	exec.WriteHeader(status, http.Header{"Content-Type": {"application/json"}})
+	exec.Write([]byte(body))
+	exec.Finish(context.Background())
+}
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	if s := New(config.IdempotencyConfig{}); s != nil {
+		t.Fatalf("New() = %v, want nil when disabled", s)
+	}
+}
+
+func TestService_ReplaysFinishedResponseAndRejectsOtherBodies(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+
+	first := mustBegin(t, s, "k", "body-a", "req-1", false)
+	if first.Decision != DecisionExecute {
+		t.Fatalf("first Decision = %v, want execute", first.Decision)
+	}
+	finishWith(first.Execution, http.StatusOK, `{"id":"1"}`)
+
+	replay := mustBegin(t, s, "k", "body-a", "req-2", false)
+	if replay.Decision != DecisionReplay || string(replay.Record.Body) != `{"id":"1"}` || replay.OriginalRequestID != "req-1" {
+		t.Fatalf("replay = %+v, want the stored response of req-1", replay)
+	}
+	if replay.Record.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("replayed header = %v", replay.Record.Header)
+	}
+
+	if conflict := mustBegin(t, s, "k", "body-b", "req-3", false); conflict.Decision != DecisionConflict {
+		t.Fatalf("Decision = %v, want conflict for a different body", conflict.Decision)
+	}
+}
+
+func TestService_DoesNotStoreRetriableOrOversizedResponses(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+
+	for _, tt := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "server error", status: http.StatusBadGateway, body: `{}`},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{}`},
+		{name: "oversized", status: http.StatusOK, body: strings.Repeat("x", 2048)},
+	} {
+		finishWith(mustBegin(t, s, tt.name, "body", "req-1", false).Execution, tt.status, tt.body)
+		if again := mustBegin(t, s, tt.name, "body", "req-2", false); again.Decision != DecisionExecute {
+			t.Errorf("%s: Decision = %v, want the request to run again", tt.name, again.Decision)
+		}
+	}
+}
+
+func TestService_ConcurrentDuplicatesWaitForFirstExecution(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+	const duplicates = 20
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		decisions = map[Decision]int{}
+		bodies    []string
+		owner     = make(chan *Execution, duplicates)
+	)
+	start := make(chan struct{})
+	for range duplicates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			result, err := s.Begin(context.Background(), "k", "body", "req", false)
+			if err != nil {
+				t.Errorf("Begin() error = %v", err)
+				return
+			}
+			mu.Lock()
+			decisions[result.Decision]++
+			mu.Unlock()
+			if result.Decision == DecisionExecute {
+				owner <- result.Execution
+				return
+			}
+			mu.Lock()
+			bodies = append(bodies, string(result.Record.Body))
+			mu.Unlock()
+		}()
+	}
+	close(start)
+
+	exec := <-owner
+	// Give the duplicates time to find the running execution.
+	time.Sleep(20 * time.Millisecond)
+	finishWith(exec, http.StatusOK, `{"id":"only"}`)
+	wg.Wait()
+
+	if decisions[DecisionExecute] != 1 || decisions[DecisionReplay] != duplicates-1 {
+		t.Fatalf("decisions = %v, want one execution and %d replays", decisions, duplicates-1)
+	}
+	for _, body := range bodies {
+		if body != `{"id":"only"}` {
+			t.Fatalf("replayed body = %q, want the first response", body)
+		}
+	}
+}
+
+func TestService_AbortLetsWaitingDuplicateRun(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+	first := mustBegin(t, s, "k", "body", "req-1", false)
+
+	done := make(chan Result, 1)
+	go func() {
+		done <- mustBegin(t, s, "k", "body", "req-2", false)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	first.Execution.Abort()
+
+	select {
+	case result := <-done:
+		if result.Decision != DecisionExecute {
+			t.Fatalf("Decision = %v, want the duplicate to run after the abort", result.Decision)
+		}
+		result.Execution.Abort()
+	case <-time.After(time.Second):
+		t.Fatal("duplicate still waiting after abort")
+	}
+}
+
+func TestService_WaitingDuplicateHonorsContext(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+	first := mustBegin(t, s, "k", "body", "req-1", false)
+	defer first.Execution.Abort()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Begin(ctx, "k", "body", "req-2", false); err == nil {
+		t.Fatal("Begin() returned without error after the context ended")
+	}
+}
+
+func TestService_StreamDuplicateAttachesFromStart(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamAttach)
+	first := mustBegin(t, s, "k", "body", "req-1", true)
+	exec := first.Execution
+	exec.WriteHeader(http.StatusOK, http.Header{"Content-Type": {"text/event-stream"}})
+	exec.Write([]byte("data: a\n\n"))
+
+	follower := mustBegin(t, s, "k", "body", "req-2", true)
+	if follower.Decision != DecisionAttach || follower.OriginalRequestID != "req-1" {
+		t.Fatalf("follower = %+v, want attach to req-1", follower)
+	}
+
+	var (
+		got    strings.Builder
+		status int
+	)
+	done := make(chan error, 1)
+	go func() {
+		aborted, err := follower.Execution.Follow(context.Background(),
+			func(code int, header http.Header) { status = code },
+			func(chunk []byte) error { got.Write(chunk); return nil },
+		)
+		if aborted {
+			t.Error("Follow() reported an aborted execution")
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	exec.Write([]byte("data: b\n\n"))
+	exec.Finish(context.Background())
+
+	if err := <-done; err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+	if status != http.StatusOK || got.String() != "data: a\n\ndata: b\n\n" {
+		t.Fatalf("follower got status %d body %q, want the whole stream", status, got.String())
+	}
+}
+
+func TestService_StreamDuplicateRejectedWhenConfigured(t *testing.T) {
+	s := newTestService(config.IdempotencyStreamReject)
+	first := mustBegin(t, s, "k", "body", "req-1", true)
+	defer first.Execution.Abort()
+
+	if result := mustBegin(t, s, "k", "body", "req-2", true); result.Decision != DecisionInProgress {
+		t.Fatalf("Decision = %v, want in progress", result.Decision)
+	}
+}
+
+func TestKey_ScopesByCaller(t *testing.T) {
+	if Key("Bearer a", "k") == Key("Bearer b", "k") {
+		t.Fatal("Key() is equal for different callers")
+	}
+}
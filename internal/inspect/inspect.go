@@ -0,0 +1,310 @@
+// Package inspect is the live request inspector: an ephemeral capture of
+// redacted previews of the requests and responses flowing through the
+// gateway, for on-call debugging without enabling audit body logging.
+//
+// An operator starts an inspection through the admin API with a filter, a
+// duration and a sample count. Until either runs out, matching model requests
+// leave a sample holding the first characters of their prompt and response
+// content, with secrets masked, in a bounded in-memory buffer. Nothing is ever
+// written to storage, and the buffer never holds more than MaxBytes of
+// previews; the oldest samples are evicted first. Samples stay readable after
+// the inspection ends until the next one starts or it is stopped.
+package inspect
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/secretscan"
+)
+
+// Limits of an inspection.
+const (
+	DefaultDuration = 5 * time.Minute
+	MaxDuration     = time.Hour
+
+	DefaultMaxSamples = 50
+	MaxSamples        = 1000
+
+	DefaultPreviewChars = 500
+	MaxPreviewChars     = 4000
+
+	// MaxBytes caps the memory held by the previews of all samples.
+	MaxBytes = 4 << 20
+)
+
+// Filter selects the requests an inspection captures. Empty fields match
+// everything. Model matches the requested or the resolved model, Provider
+// the provider's configured name or its type, and Path is a prefix of the
+// request path.
+type Filter struct {
+	Model    string `json:"model,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Path     string `json:"path,omitempty"`
+}
+
+// Options configure an inspection. Zero values take the defaults.
+type Options struct {
+	Filter       Filter
+	Duration     time.Duration
+	MaxSamples   int
+	PreviewChars int
+	// StartedBy identifies the operator, such as their auth key ID.
+	StartedBy string
+}
+
+// State describes the current or last inspection.
+type State struct {
+	Active       bool       `json:"active"`
+	Filter       Filter     `json:"filter"`
+	MaxSamples   int        `json:"max_samples,omitempty"`
+	PreviewChars int        `json:"preview_chars,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	StartedBy    string     `json:"started_by,omitempty"`
+	// ExpiresAt is when capture stops unless the sample count runs out first.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Captured counts the samples taken, including evicted ones.
+	Captured int `json:"captured"`
+	// Evicted counts the samples dropped to stay under MaxBytes.
+	Evicted int `json:"evicted"`
+	// Bytes is the size of the previews held.
+	Bytes int `json:"bytes"`
+}
+
+// Sample is the redacted preview of one request.
+type Sample struct {
+	Timestamp     time.Time `json:"timestamp"`
+	RequestID     string    `json:"request_id,omitempty"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Model         string    `json:"model,omitempty"`
+	ResolvedModel string    `json:"resolved_model,omitempty"`
+	Provider      string    `json:"provider,omitempty"`
+	ProviderType  string    `json:"provider_type,omitempty"`
+	StatusCode    int       `json:"status_code"`
+	DurationNs    int64     `json:"duration_ns"`
+	Stream        bool      `json:"stream,omitempty"`
+	// RequestPreview and ResponsePreview hold the first PreviewChars
+	// characters of the content, with secrets masked. Truncated reports
+	// that either was cut.
+	RequestPreview  string `json:"request_preview,omitempty"`
+	ResponsePreview string `json:"response_preview,omitempty"`
+	Truncated       bool   `json:"truncated,omitempty"`
+}
+
+func (s Sample) size() int {
+	return len(s.RequestPreview) + len(s.ResponsePreview)
+}
+
+// Inspector holds the inspection state and its samples. A nil Inspector
+// never captures.
+type Inspector struct {
+	// active is read on every request without taking mu.
+	active atomic.Bool
+
+	mu      sync.Mutex
+	session uint64 // counts Start calls, so late samples skip new sessions
+	state   State
+	until   time.Time
+	samples []Sample // oldest first
+	bytes   int
+
+	scanner *secretscan.Scanner
+	now     func() time.Time
+}
+
+// New returns an idle Inspector. Previews are masked with scanner, the
+// configured secret redaction scanner, or the built-in detectors when nil.
+func New(scanner *secretscan.Scanner) *Inspector {
+	if scanner == nil {
+		scanner, _ = secretscan.New(config.SecretRedactionConfig{Enabled: true})
+	}
+	return &Inspector{scanner: scanner, now: time.Now}
+}
+
+// Start begins an inspection, replacing the running one and discarding
+// every sample held.
+func (in *Inspector) Start(opts Options) (State, error) {
+	if opts.Duration < 0 || opts.Duration > MaxDuration {
+		return State{}, fmt.Errorf("duration must not exceed %s", MaxDuration)
+	}
+	if opts.MaxSamples < 0 || opts.MaxSamples > MaxSamples {
+		return State{}, fmt.Errorf("max_samples must not exceed %d", MaxSamples)
+	}
+	if opts.PreviewChars < 0 || opts.PreviewChars > MaxPreviewChars {
+		return State{}, fmt.Errorf("preview_chars must not exceed %d", MaxPreviewChars)
+	}
+	if opts.Duration == 0 {
+		opts.Duration = DefaultDuration
+	}
+	if opts.MaxSamples == 0 {
+		opts.MaxSamples = DefaultMaxSamples
+	}
+	if opts.PreviewChars == 0 {
+		opts.PreviewChars = DefaultPreviewChars
+	}
+	opts.Filter.Model = strings.TrimSpace(opts.Filter.Model)
+	opts.Filter.Provider = strings.TrimSpace(opts.Filter.Provider)
+	opts.Filter.Path = strings.TrimSpace(opts.Filter.Path)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	now := in.now().UTC()
+	expires := now.Add(opts.Duration)
+	in.state = State{
+		Active:       true,
+		Filter:       opts.Filter,
+		MaxSamples:   opts.MaxSamples,
+		PreviewChars: opts.PreviewChars,
+		StartedAt:    &now,
+		StartedBy:    strings.TrimSpace(opts.StartedBy),
+		ExpiresAt:    &expires,
+	}
+	in.until = expires
+	in.session++
+	in.samples = nil
+	in.bytes = 0
+	in.active.Store(true)
+	return in.stateLocked(), nil
+}
+
+// Stop ends the inspection and discards its samples.
+func (in *Inspector) Stop() State {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.active.Store(false)
+	in.state.Active = false
+	in.samples = nil
+	in.bytes = 0
+	return in.stateLocked()
+}
+
+// State returns the state of the current or last inspection.
+func (in *Inspector) State() State {
+	if in == nil {
+		return State{}
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.expireLocked(in.now())
+	return in.stateLocked()
+}
+
+// Samples returns the samples held, newest first.
+func (in *Inspector) Samples() []Sample {
+	if in == nil {
+		return nil
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.expireLocked(in.now())
+	out := make([]Sample, 0, len(in.samples))
+	for i := len(in.samples) - 1; i >= 0; i-- {
+		out = append(out, in.samples[i])
+	}
+	return out
+}
+
+// Active reports whether an inspection may capture requests to path. It is
+// cheap enough to call on every request.
+func (in *Inspector) Active(path string) bool {
+	if in == nil || !in.active.Load() {
+		return false
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.expireLocked(in.now()) && strings.HasPrefix(path, in.state.Filter.Path)
+}
+
+// record adds a sample when the inspection is still running and the sample
+// matches its filter. The previews arrive whole and are masked before they
+// are cut to PreviewChars, so a secret is never cut into a fragment the
+// detectors miss. Masking runs outside the lock.
+func (in *Inspector) record(sample Sample) {
+	in.mu.Lock()
+	ok := in.expireLocked(in.now()) && in.state.Filter.matches(sample)
+	session, limit := in.session, in.state.PreviewChars
+	in.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var cutRequest, cutResponse bool
+	sample.RequestPreview, cutRequest = truncate(in.scanner.Mask(sample.RequestPreview), limit)
+	sample.ResponsePreview, cutResponse = truncate(in.scanner.Mask(sample.ResponsePreview), limit)
+	sample.Truncated = sample.Truncated || cutRequest || cutResponse
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.session != session || !in.expireLocked(in.now()) {
+		return
+	}
+	in.state.Captured++
+	if in.state.Captured >= in.state.MaxSamples {
+		in.stopLocked()
+	}
+	in.push(sample)
+}
+
+// truncate cuts text to at most limit characters.
+func truncate(text string, limit int) (string, bool) {
+	if len(text) <= limit {
+		return text, false
+	}
+	n := 0
+	for i := range text {
+		if n == limit {
+			return text[:i], true
+		}
+		n++
+	}
+	return text, false
+}
+
+// push appends sample, evicting the oldest samples while the previews held
+// exceed MaxBytes. The sample count needs no eviction: capture stops once
+// it reaches MaxSamples.
+func (in *Inspector) push(sample Sample) {
+	in.samples = append(in.samples, sample)
+	in.bytes += sample.size()
+	for in.bytes > MaxBytes {
+		in.bytes -= in.samples[0].size()
+		in.samples[0] = Sample{}
+		in.samples = in.samples[1:]
+		in.state.Evicted++
+	}
+}
+
+// expireLocked reports whether the inspection is running at now, ending it
+// when its duration has passed.
+func (in *Inspector) expireLocked(now time.Time) bool {
+	if in.state.Active && !now.Before(in.until) {
+		in.stopLocked()
+	}
+	return in.state.Active
+}
+
+func (in *Inspector) stopLocked() {
+	in.state.Active = false
+	in.active.Store(false)
+}
+
+func (in *Inspector) stateLocked() State {
+	state := in.state
+	state.Bytes = in.bytes
+	return state
+}
+
+func (f Filter) matches(s Sample) bool {
+	if f.Model != "" && !strings.EqualFold(f.Model, s.Model) && !strings.EqualFold(f.Model, s.ResolvedModel) {
+		return false
+	}
+	if f.Provider != "" && f.Provider != s.Provider && f.Provider != s.ProviderType {
+		return false
+	}
+	return strings.HasPrefix(s.Path, f.Path)
+}
@@ -0,0 +1,156 @@
+package inspect
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testOpenAIKey = "sk-proj-Abcdefghijklmnopqrstuvwx0123"
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestInspector(clock *fakeClock) *Inspector {
+	in := New(nil)
+	in.now = clock.Now
+	return in
+}
+
+func TestInspector_ExpiresAfterDuration(t *testing.T) {
+	clock := newFakeClock()
+	in := newTestInspector(clock)
+	if in.Active("/v1/chat/completions") {
+		t.Fatal("idle inspector is active")
+	}
+
+	state, err := in.Start(Options{Duration: time.Minute, StartedBy: "key-1"})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !state.Active || state.StartedBy != "key-1" || !state.ExpiresAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Fatalf("state = %+v", state)
+	}
+	in.record(Sample{Path: "/v1/chat/completions", RequestPreview: "hello"})
+
+	clock.Advance(time.Minute)
+	if in.Active("/v1/chat/completions") {
+		t.Fatal("inspector still active after its duration")
+	}
+	in.record(Sample{Path: "/v1/chat/completions", RequestPreview: "late"})
+	if state := in.State(); state.Active || state.Captured != 1 {
+		t.Fatalf("state after expiry = %+v, want inactive with 1 capture", state)
+	}
+	samples := in.Samples()
+	if len(samples) != 1 || samples[0].RequestPreview != "hello" {
+		t.Fatalf("samples = %+v, want the sample taken before expiry", samples)
+	}
+
+	in.Stop()
+	if samples := in.Samples(); len(samples) != 0 {
+		t.Fatalf("samples after Stop = %d, want 0", len(samples))
+	}
+}
+
+func TestInspector_StopsAtSampleCount(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	if _, err := in.Start(Options{MaxSamples: 2}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for i := range 3 {
+		in.record(Sample{Path: "/v1/chat/completions", RequestPreview: strings.Repeat("x", i+1)})
+	}
+	samples := in.Samples()
+	if len(samples) != 2 || samples[0].RequestPreview != "xx" || samples[1].RequestPreview != "x" {
+		t.Fatalf("samples = %+v, want the first two, newest first", samples)
+	}
+	if in.State().Active {
+		t.Fatal("inspector still active after its sample count")
+	}
+}
+
+func TestInspector_FilterAndValidation(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	if _, err := in.Start(Options{Filter: Filter{Model: "GPT-4o", Provider: "openai", Path: "/v1/chat"}}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if in.Active("/v1/embeddings") {
+		t.Fatal("inspector active for a path outside its filter")
+	}
+	in.record(Sample{Path: "/v1/chat/completions", Model: "gpt-4o", Provider: "openai-eu", ProviderType: "openai", RequestPreview: "match"})
+	in.record(Sample{Path: "/v1/chat/completions", Model: "gpt-4o-mini", ProviderType: "openai", RequestPreview: "other model"})
+	in.record(Sample{Path: "/v1/chat/completions", Model: "gpt-4o", ProviderType: "azure", RequestPreview: "other provider"})
+	if samples := in.Samples(); len(samples) != 1 || samples[0].RequestPreview != "match" {
+		t.Fatalf("samples = %+v, want only the matching one", samples)
+	}
+
+	for name, opts := range map[string]Options{
+		"duration":      {Duration: 2 * time.Hour},
+		"samples":       {MaxSamples: MaxSamples + 1},
+		"preview chars": {PreviewChars: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := in.Start(opts); err == nil {
+				t.Fatal("Start() succeeded with invalid options")
+			}
+		})
+	}
+}
+
+func TestInspector_RedactsBeforeTruncating(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	if _, err := in.Start(Options{PreviewChars: 20}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	// The key starts inside the preview and ends past it: cutting first
+	// would leave a prefix too short for the detector to recognize.
+	in.record(Sample{
+		Path:            "/v1/chat/completions",
+		RequestPreview:  "my key: " + testOpenAIKey,
+		ResponsePreview: "héllo wörld, and the rest",
+	})
+	sample := in.Samples()[0]
+	if strings.Contains(sample.RequestPreview, "sk-proj") || sample.RequestPreview != "my key: [REDACTED:op" {
+		t.Fatalf("RequestPreview = %q, want the masked key cut to 20 characters", sample.RequestPreview)
+	}
+	if sample.ResponsePreview != "héllo wörld, and the" || !sample.Truncated {
+		t.Fatalf("ResponsePreview = %q, Truncated = %v", sample.ResponsePreview, sample.Truncated)
+	}
+}
+
+func TestInspector_EvictsOldestOverMemoryCap(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	if _, err := in.Start(Options{MaxSamples: MaxSamples, PreviewChars: MaxPreviewChars}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	preview := strings.Repeat("€", MaxPreviewChars) // 3 bytes per character
+	for range MaxSamples {
+		in.record(Sample{Path: "/v1/chat/completions", RequestPreview: preview, ResponsePreview: preview})
+	}
+	state := in.State()
+	if state.Bytes > MaxBytes || state.Evicted == 0 || state.Captured != MaxSamples {
+		t.Fatalf("state = %+v, want at most %d bytes held after evictions", state, MaxBytes)
+	}
+	if held := len(in.Samples()); held+state.Evicted != MaxSamples {
+		t.Fatalf("held %d + evicted %d != captured %d", held, state.Evicted, MaxSamples)
+	}
+}
@@ -0,0 +1,140 @@
+package inspect
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+)
+
+// responseCaptureLimit bounds the response bytes kept per request for its
+// preview. Captures past it are cut and marked truncated.
+const responseCaptureLimit = 64 << 10
+
+// Middleware captures a sample of each model request while an inspection
+// matching its path runs. The request content is read from the request
+// snapshot, and the response is copied, up to responseCaptureLimit, as it is
+// written to the client. Model and provider are matched once the request
+// completes, from the resolved workflow.
+func Middleware(in *Inspector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !core.IsModelInteractionPath(req.URL.Path) || !in.Active(req.URL.Path) {
+				return next(c)
+			}
+
+			start := in.now()
+			recorder := &captureRecorder{ResponseWriter: c.Response()}
+			c.SetResponse(recorder)
+			err := next(c)
+
+			ctx := c.Request().Context()
+			var body []byte
+			if snapshot := core.GetRequestSnapshot(ctx); snapshot != nil {
+				body = snapshot.CapturedBodyView()
+			}
+			_, status := echo.ResolveResponseStatus(c.Response(), err)
+			header := c.Response().Header()
+			stream := strings.HasPrefix(strings.ToLower(header.Get("Content-Type")), "text/event-stream")
+			captured, cut := recorder.captured()
+			sample := Sample{
+				Timestamp:  start.UTC(),
+				RequestID:  strings.TrimSpace(core.GetRequestID(ctx)),
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Model:      gjson.GetBytes(body, "model").String(),
+				StatusCode: status,
+				DurationNs: in.now().Sub(start).Nanoseconds(),
+				Stream:     stream,
+				Truncated:  cut,
+			}
+			if sample.RequestID == "" {
+				sample.RequestID = req.Header.Get("X-Request-ID")
+			}
+			sample.ResolvedModel, sample.Provider, sample.ProviderType = route(core.GetWorkflow(ctx))
+			sample.RequestPreview = requestText(body)
+			// Encoded responses are not decoded for a preview.
+			if header.Get("Content-Encoding") == "" {
+				sample.ResponsePreview = responseText(captured, stream)
+			}
+			if err != nil && sample.ResponsePreview == "" {
+				sample.ResponsePreview = err.Error()
+			}
+			in.record(sample)
+			return err
+		}
+	}
+}
+
+func route(workflow *core.Workflow) (model, provider, providerType string) {
+	if workflow == nil || workflow.Resolution == nil {
+		return "", "", ""
+	}
+	resolution := workflow.Resolution
+	model = strings.TrimSpace(resolution.ResolvedSelector.Model)
+	providerType = strings.TrimSpace(workflow.ProviderType)
+	if providerType == "" {
+		providerType = strings.TrimSpace(resolution.ProviderType)
+	}
+	provider = strings.TrimSpace(resolution.ProviderName)
+	if provider == "" {
+		provider = strings.TrimSpace(resolution.ResolvedSelector.Provider)
+	}
+	if provider == "" {
+		provider = providerType
+	}
+	return model, provider, providerType
+}
+
+// captureRecorder copies the first responseCaptureLimit bytes written to the
+// client.
+type captureRecorder struct {
+	http.ResponseWriter
+	mu  sync.Mutex
+	buf []byte
+	cut bool
+}
+
+func (r *captureRecorder) Write(b []byte) (int, error) {
+	r.mu.Lock()
+	if room := responseCaptureLimit - len(r.buf); room > 0 {
+		r.buf = append(r.buf, b[:min(room, len(b))]...)
+		r.cut = r.cut || len(b) > room
+	} else if len(b) > 0 {
+		r.cut = true
+	}
+	r.mu.Unlock()
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *captureRecorder) captured() ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf, r.cut
+}
+
+// Flush implements http.Flusher so SSE streaming keeps working.
+func (r *captureRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it.
+func (r *captureRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (r *captureRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,163 @@
+package inspect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+)
+
+func routedWorkflow(providerName, providerType, model string) *core.Workflow {
+	return &core.Workflow{
+		ProviderType: providerType,
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: model, Provider: providerType},
+			ProviderType:     providerType,
+			ProviderName:     providerName,
+		},
+	}
+}
+
+// newTestServer serves handler behind the inspector middleware, with the
+// request snapshot and workflow set up as the gateway's middleware would.
+func newTestServer(in *Inspector, handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			body := []byte(req.Header.Get("X-Test-Body"))
+			snapshot := core.NewRequestSnapshot(req.Method, req.URL.Path, nil, nil, nil, "application/json", body, false, req.Header.Get("X-Request-ID"), nil)
+			ctx := core.WithRequestSnapshot(req.Context(), snapshot)
+			ctx = core.WithRequestID(ctx, req.Header.Get("X-Request-ID"))
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.Use(Middleware(in))
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			ctx := core.WithWorkflow(c.Request().Context(), routedWorkflow("openai-eu", "openai", "gpt-4o-2024-08-06"))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.POST("/v1/chat/completions", handler)
+	return e
+}
+
+func serve(e *echo.Echo, requestID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("X-Request-ID", requestID)
+	req.Header.Set("X-Test-Body", body)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_CapturesRedactedPreviews(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	e := newTestServer(in, func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": "Use " + testOpenAIKey + " carefully."}}},
+		})
+	})
+	body := `{"model":"gpt-4o","temperature":0.2,"messages":[{"role":"system","content":"Be brief."},{"role":"user","content":[{"type":"text","text":"my key is ` + testOpenAIKey + `"}]}]}`
+
+	serve(e, "req-0", body)
+	if samples := in.Samples(); len(samples) != 0 {
+		t.Fatalf("captured %d samples while idle", len(samples))
+	}
+
+	if _, err := in.Start(Options{Filter: Filter{Model: "gpt-4o", Provider: "openai-eu"}}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	rec := serve(e, "req-1", body)
+	if !strings.Contains(rec.Body.String(), testOpenAIKey) {
+		t.Fatalf("client response was altered: %s", rec.Body.String())
+	}
+
+	samples := in.Samples()
+	if len(samples) != 1 {
+		t.Fatalf("captured %d samples, want 1", len(samples))
+	}
+	got := samples[0]
+	if got.RequestID != "req-1" || got.Model != "gpt-4o" || got.ResolvedModel != "gpt-4o-2024-08-06" ||
+		got.Provider != "openai-eu" || got.ProviderType != "openai" || got.StatusCode != http.StatusOK {
+		t.Fatalf("sample = %+v", got)
+	}
+	if want := "system: Be brief.\nuser: my key is [REDACTED:openai_api_key]"; got.RequestPreview != want {
+		t.Fatalf("RequestPreview = %q, want %q", got.RequestPreview, want)
+	}
+	if want := "Use [REDACTED:openai_api_key] carefully."; got.ResponsePreview != want {
+		t.Fatalf("ResponsePreview = %q, want %q", got.ResponsePreview, want)
+	}
+}
+
+func TestMiddleware_CapturesStreamText(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	if _, err := in.Start(Options{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	e := newTestServer(in, func(c *echo.Context) error {
+		c.Response().Header().Set("Content-Type", "text/event-stream")
+		c.Response().WriteHeader(http.StatusOK)
+		for _, chunk := range []string{"Hel", "lo"} {
+			_, _ = c.Response().Write([]byte(`data: {"choices":[{"delta":{"content":"` + chunk + `"}}]}` + "\n\n"))
+		}
+		_, _ = c.Response().Write([]byte("data: [DONE]\n\n"))
+		return nil
+	})
+
+	serve(e, "req-1", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"Hi"}]}`)
+	samples := in.Samples()
+	if len(samples) != 1 || !samples[0].Stream || samples[0].ResponsePreview != "Hello" {
+		t.Fatalf("samples = %+v, want one stream sample with the joined deltas", samples)
+	}
+}
+
+func TestMiddleware_ConcurrentCaptureUnderLoad(t *testing.T) {
+	in := newTestInspector(newFakeClock())
+	const maxSamples = 25
+	if _, err := in.Start(Options{MaxSamples: maxSamples}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	e := newTestServer(in, func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{"choices": []any{map[string]any{"message": map[string]any{"content": "ok"}}}})
+	})
+
+	var wg sync.WaitGroup
+	for i := range 200 {
+		wg.Go(func() {
+			id := "req-" + strconv.Itoa(i)
+			if rec := serve(e, id, `{"model":"gpt-4o","messages":[{"role":"user","content":"`+id+`"}]}`); rec.Code != http.StatusOK {
+				t.Errorf("%s: status = %d", id, rec.Code)
+			}
+		})
+		if i%20 == 0 {
+			wg.Go(func() {
+				in.State()
+				in.Samples()
+			})
+		}
+	}
+	wg.Wait()
+
+	samples := in.Samples()
+	state := in.State()
+	if len(samples) != maxSamples || state.Captured != maxSamples || state.Active {
+		t.Fatalf("held %d samples, state = %+v; want exactly %d and capture stopped", len(samples), state, maxSamples)
+	}
+	seen := map[string]bool{}
+	for _, s := range samples {
+		if seen[s.RequestID] || s.RequestPreview != "user: "+s.RequestID {
+			t.Fatalf("sample %+v is duplicated or mixed up with another request", s)
+		}
+		seen[s.RequestID] = true
+	}
+}
@@ -0,0 +1,138 @@
+package inspect
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// requestText returns the content of a model request body: chat messages,
+// Responses API instructions and input, or embedding input, one entry per
+// line prefixed with its role when it has one. Other fields are left out.
+func requestText(body []byte) string {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return ""
+	}
+	var b strings.Builder
+	if instructions := gjson.GetBytes(body, "instructions"); instructions.Type == gjson.String {
+		appendLine(&b, "instructions", instructions.Str)
+	}
+	gjson.GetBytes(body, "messages").ForEach(func(_, message gjson.Result) bool {
+		appendLine(&b, message.Get("role").String(), contentText(message.Get("content")))
+		return true
+	})
+	switch input := gjson.GetBytes(body, "input"); {
+	case input.Type == gjson.String:
+		appendLine(&b, "", input.Str)
+	case input.IsArray():
+		input.ForEach(func(_, item gjson.Result) bool {
+			if item.Type == gjson.String {
+				appendLine(&b, "", item.Str)
+			} else {
+				appendLine(&b, item.Get("role").String(), contentText(item.Get("content")))
+			}
+			return true
+		})
+	}
+	if prompt := gjson.GetBytes(body, "prompt"); prompt.Type == gjson.String {
+		appendLine(&b, "", prompt.Str)
+	}
+	return b.String()
+}
+
+// responseText returns the generated text of a response body, JSON or an
+// event stream, or the error message of an error response.
+func responseText(body []byte, stream bool) string {
+	if stream {
+		return streamText(body)
+	}
+	if !gjson.ValidBytes(body) {
+		return ""
+	}
+	var b strings.Builder
+	gjson.GetBytes(body, "choices").ForEach(func(_, choice gjson.Result) bool {
+		b.WriteString(contentText(choice.Get("message.content")))
+		b.WriteString(choice.Get("text").String())
+		return true
+	})
+	gjson.GetBytes(body, "output").ForEach(func(_, item gjson.Result) bool {
+		b.WriteString(contentText(item.Get("content")))
+		return true
+	})
+	gjson.GetBytes(body, "content").ForEach(func(_, part gjson.Result) bool {
+		b.WriteString(part.Get("text").String())
+		return true
+	})
+	if b.Len() == 0 {
+		b.WriteString(gjson.GetBytes(body, "error.message").String())
+	}
+	return b.String()
+}
+
+// streamText joins the text deltas of an event stream: chat completion
+// chunks, Responses API output_text deltas and Anthropic text deltas. A
+// partial event at the end of a cut capture is skipped.
+func streamText(body []byte) string {
+	var b strings.Builder
+	for line := range bytes.Lines(body) {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if !gjson.ValidBytes(data) {
+			continue
+		}
+		event := gjson.ParseBytes(data)
+		switch event.Get("type").String() {
+		case "response.output_text.delta":
+			b.WriteString(event.Get("delta").String())
+			continue
+		case "content_block_delta":
+			b.WriteString(event.Get("delta.text").String())
+			continue
+		}
+		event.Get("choices").ForEach(func(_, choice gjson.Result) bool {
+			b.WriteString(choice.Get("delta.content").String())
+			return true
+		})
+		if message := event.Get("error.message"); message.Exists() {
+			b.WriteString(message.String())
+		}
+	}
+	return b.String()
+}
+
+// contentText returns content when it is a string, or the text of its parts
+// when it is an array.
+func contentText(content gjson.Result) string {
+	if content.Type == gjson.String {
+		return content.Str
+	}
+	var b strings.Builder
+	content.ForEach(func(_, part gjson.Result) bool {
+		if text := part.Get("text"); text.Type == gjson.String {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(text.Str)
+		}
+		return true
+	})
+	return b.String()
+}
+
+func appendLine(b *strings.Builder, role, text string) {
+	if text == "" {
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteByte('\n')
+	}
+	if role != "" {
+		b.WriteString(role)
+		b.WriteString(": ")
+	}
+	b.WriteString(text)
+}
@@ -0,0 +1,344 @@
+// Package keyhealth periodically checks that provider API keys still
+// authenticate. Each check is one lightweight authenticated request per
+// provider: a key metadata request for providers that report key metadata,
+// otherwise a model listing. Authentication failures (401 and 403) are
+// counted apart from other failures, and a key the provider rejects on
+// enough consecutive checks is reported key_invalid on the provider status.
+//
+// Checks run one provider at a time, never more often than
+// config.MinKeyHealthInterval, and skip providers that are not constructed
+// yet, have an open circuit breaker or have exhausted a reported rate-limit
+// window, so they never use a meaningful share of a provider's quota. Each
+// check writes a zero-token usage entry labeled as internal.
+package keyhealth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/providers"
+	"gomodel/internal/usage"
+)
+
+// UsageLabel and UsageLabelValue are the cost allocation label set on the
+// usage entry of every check.
+const (
+	UsageLabel      = "gomodel_internal"
+	UsageLabelValue = "key_health_check"
+)
+
+// Usage entry endpoints of the two kinds of check.
+const (
+	endpointModels = "/v1/models"
+	endpointKey    = "key_metadata"
+)
+
+// Registry is the part of the model registry the checker reads providers
+// from and reports key health to.
+type Registry interface {
+	ProviderRuntimeSnapshots() []providers.ProviderRuntimeSnapshot
+	ProviderByName(providerName string) core.Provider
+	RecordKeyHealth(providerName string, health *core.KeyHealth)
+}
+
+// Checker runs the periodic key health checks. A nil Checker is disabled.
+type Checker struct {
+	cfg      config.KeyHealthConfig
+	registry Registry
+	usage    usage.LoggerInterface
+	notifier *notifier
+	now      func() time.Time
+
+	mu     sync.Mutex
+	health map[string]core.KeyHealth
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New returns the checker for cfg, or nil when key health checks are
+// disabled. Call Start to begin the periodic checks.
+func New(cfg config.KeyHealthConfig, registry Registry, usageLogger usage.LoggerInterface) (*Checker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if registry == nil {
+		return nil, errors.New("provider registry is required")
+	}
+	if err := config.ValidateKeyHealthConfig(&cfg); err != nil {
+		return nil, err
+	}
+
+	checker := &Checker{
+		cfg:      cfg,
+		registry: registry,
+		usage:    usageLogger,
+		now:      time.Now,
+		health:   make(map[string]core.KeyHealth),
+	}
+	if webhookURL := strings.TrimSpace(cfg.WebhookURL); webhookURL != "" {
+		checker.notifier = newNotifier(webhookURL)
+	}
+	return checker, nil
+}
+
+// Start checks every provider once per interval in a background goroutine
+// until Close.
+func (c *Checker) Start() {
+	if c == nil || c.done != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.CheckAll(ctx)
+			}
+		}
+	}()
+}
+
+// Close stops the periodic checks, waits for a running check to finish and
+// delivers the queued webhook events.
+func (c *Checker) Close() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() {
+		if c.cancel != nil {
+			c.cancel()
+			<-c.done
+		}
+		if c.notifier != nil {
+			c.notifier.close()
+		}
+	})
+}
+
+// CheckAll checks the key of every eligible provider, one at a time, in
+// name order.
+func (c *Checker) CheckAll(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	snapshots := c.registry.ProviderRuntimeSnapshots()
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	for _, snapshot := range snapshots {
+		if ctx.Err() != nil {
+			return
+		}
+		if reason := skipReason(snapshot, c.now()); reason != "" {
+			slog.Debug("key health check skipped", "provider", snapshot.Name, "reason", reason)
+			continue
+		}
+		c.check(ctx, snapshot.Name, snapshot.Type)
+	}
+}
+
+// skipReason explains why provider must not be checked at now, or returns "".
+func skipReason(snapshot providers.ProviderRuntimeSnapshot, now time.Time) string {
+	switch {
+	case snapshot.Initialization == providers.ProviderInitUninitialized,
+		snapshot.Initialization == providers.ProviderInitFailed:
+		return "lazy provider not constructed"
+	case snapshot.CircuitOpenUntil != nil && now.Before(*snapshot.CircuitOpenUntil):
+		return "circuit breaker open"
+	case snapshot.RateLimits != nil:
+		if _, exhausted := snapshot.RateLimits.ExhaustedUntil(now); exhausted {
+			return "rate limited"
+		}
+	}
+	return ""
+}
+
+// check sends one authenticated request to the provider and records what it
+// says about the key.
+func (c *Checker) check(ctx context.Context, name, providerType string) {
+	provider := c.registry.ProviderByName(name)
+	if provider == nil {
+		return
+	}
+
+	requestID := uuid.NewString()
+	checkCtx, cancel := context.WithTimeout(core.WithRequestID(ctx, requestID), c.cfg.Timeout)
+	defer cancel()
+
+	var metadata *core.KeyMetadata
+	var err error
+	endpoint := endpointModels
+	if fetcher, ok := provider.(core.KeyMetadataFetcher); ok {
+		endpoint = endpointKey
+		var fetched core.KeyMetadata
+		if fetched, err = fetcher.FetchKeyMetadata(checkCtx); err == nil {
+			metadata = &fetched
+		}
+	} else {
+		_, err = provider.ListModels(checkCtx)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the aborted request says nothing about the key.
+		return
+	}
+
+	c.recordUsage(requestID, name, providerType, endpoint)
+	c.record(name, providerType, err, metadata)
+}
+
+// record applies the outcome of one check to the provider's key health,
+// publishes it to the registry and alerts on transitions.
+func (c *Checker) record(name, providerType string, checkErr error, metadata *core.KeyMetadata) core.KeyHealth {
+	now := c.now().UTC()
+
+	c.mu.Lock()
+	previous, ok := c.health[name]
+	if !ok {
+		previous = core.KeyHealth{Status: core.KeyHealthUnknown}
+	}
+	next := previous
+	next.LastCheckAt = now
+	authFailure, quotaExhausted := classify(checkErr)
+	switch {
+	case checkErr == nil:
+		next.Status = core.KeyHealthValid
+		next.LastValidAt = &now
+		next.ConsecutiveAuthFailures = 0
+		next.ConsecutiveAvailabilityFailures = 0
+		next.LastError = ""
+		if metadata != nil {
+			next.Metadata = metadata
+		}
+	case authFailure:
+		next.ConsecutiveAuthFailures++
+		next.ConsecutiveAvailabilityFailures = 0
+		next.LastError = checkErr.Error()
+		if next.ConsecutiveAuthFailures >= c.cfg.FailureThreshold {
+			next.Status = core.KeyHealthInvalid
+		}
+	default:
+		// Inconclusive about the key: the status and the auth failure streak
+		// stay as they were.
+		next.ConsecutiveAvailabilityFailures++
+		next.LastError = checkErr.Error()
+	}
+	next.Warnings = c.warnings(next.Metadata, quotaExhausted && !authFailure, now)
+	c.health[name] = next
+	c.mu.Unlock()
+
+	c.registry.RecordKeyHealth(name, &next)
+	c.alert(name, providerType, previous, next)
+	return next
+}
+
+// warnings returns the hints about a key that still authenticates: an expiry
+// within the warning window and an exhausted credit limit or quota.
+func (c *Checker) warnings(metadata *core.KeyMetadata, quotaExhausted bool, now time.Time) []string {
+	var warnings []string
+	if metadata != nil && metadata.ExpiresAt != nil && metadata.ExpiresAt.Sub(now) <= c.cfg.ExpiryWarning {
+		warnings = append(warnings, core.KeyWarningExpiring)
+	}
+	if quotaExhausted || (metadata != nil && metadata.CreditRemaining != nil && *metadata.CreditRemaining <= 0) {
+		warnings = append(warnings, core.KeyWarningQuotaExhausted)
+	}
+	return warnings
+}
+
+// classify reports whether err is the provider rejecting the key, and
+// whether it says the key's quota or credits ran out.
+func classify(err error) (authFailure, quotaExhausted bool) {
+	gatewayErr, ok := errors.AsType[*core.GatewayError](err)
+	if !ok {
+		return false, false
+	}
+	status := gatewayErr.UpstreamStatusCode
+	if status == 0 {
+		status = gatewayErr.StatusCode
+	}
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return true, false
+	case status == http.StatusPaymentRequired:
+		return false, true
+	case status == http.StatusTooManyRequests && gatewayErr.Code != nil && *gatewayErr.Code == "insufficient_quota":
+		return false, true
+	}
+	return false, false
+}
+
+// alert logs and sends webhook events for the changes between previous and
+// next: the key becoming invalid, recovering, and newly found warnings.
+func (c *Checker) alert(name, providerType string, previous, next core.KeyHealth) {
+	var events []Event
+	switch {
+	case next.Status == core.KeyHealthInvalid && previous.Status != core.KeyHealthInvalid:
+		slog.Warn("provider key invalid",
+			"provider", name,
+			"type", providerType,
+			"consecutive_auth_failures", next.ConsecutiveAuthFailures,
+			"error", next.LastError,
+		)
+		events = append(events, Event{Event: EventKeyInvalid, Error: next.LastError})
+	case next.Status == core.KeyHealthValid && previous.Status == core.KeyHealthInvalid:
+		slog.Info("provider key recovered", "provider", name, "type", providerType)
+		events = append(events, Event{Event: EventKeyRecovered})
+	}
+	for _, warning := range next.Warnings {
+		if slices.Contains(previous.Warnings, warning) {
+			continue
+		}
+		event := Event{Event: warning}
+		if warning == core.KeyWarningExpiring && next.Metadata != nil {
+			event.ExpiresAt = next.Metadata.ExpiresAt
+		}
+		slog.Warn("provider key warning", "provider", name, "type", providerType, "warning", warning, "expires_at", event.ExpiresAt)
+		events = append(events, event)
+	}
+
+	if c.notifier == nil {
+		return
+	}
+	for _, event := range events {
+		event.Type = eventType
+		event.Provider = name
+		event.ProviderType = providerType
+		event.Timestamp = next.LastCheckAt
+		c.notifier.enqueue(event)
+	}
+}
+
+// recordUsage writes the zero-token usage entry of one check, labeled as
+// internal traffic.
+func (c *Checker) recordUsage(requestID, name, providerType, endpoint string) {
+	if c.usage == nil || !c.usage.Config().Enabled {
+		return
+	}
+	c.usage.Write(&usage.UsageEntry{
+		ID:           uuid.NewString(),
+		RequestID:    requestID,
+		Timestamp:    c.now().UTC(),
+		Provider:     providerType,
+		ProviderName: name,
+		Endpoint:     endpoint,
+		Labels:       map[string]string{UsageLabel: UsageLabelValue},
+	})
+}
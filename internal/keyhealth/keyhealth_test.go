@@ -0,0 +1,277 @@
+package keyhealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/providers"
+	"gomodel/internal/usage"
+)
+
+// scriptedProvider answers each model listing with the next scripted error;
+// nil authenticates.
+type scriptedProvider struct {
+	results []error
+	calls   int
+}
+
+func (p *scriptedProvider) ListModels(_ context.Context) (*core.ModelsResponse, error) {
+	err := p.results[p.calls]
+	p.calls++
+	if err != nil {
+		return nil, err
+	}
+	return &core.ModelsResponse{Object: "list"}, nil
+}
+
+func (p *scriptedProvider) ChatCompletion(_ context.Context, _ *core.ChatRequest) (*core.ChatResponse, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *scriptedProvider) StreamChatCompletion(_ context.Context, _ *core.ChatRequest) (io.ReadCloser, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *scriptedProvider) Responses(_ context.Context, _ *core.ResponsesRequest) (*core.ResponsesResponse, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *scriptedProvider) StreamResponses(_ context.Context, _ *core.ResponsesRequest) (io.ReadCloser, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *scriptedProvider) Embeddings(_ context.Context, _ *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	return nil, errors.New("not used")
+}
+
+// metadataProvider reports key metadata like OpenRouter's /key endpoint.
+type metadataProvider struct {
+	scriptedProvider
+	metadata core.KeyMetadata
+}
+
+func (p *metadataProvider) FetchKeyMetadata(_ context.Context) (core.KeyMetadata, error) {
+	return p.metadata, nil
+}
+
+type fakeRegistry struct {
+	snapshots []providers.ProviderRuntimeSnapshot
+	providers map[string]core.Provider
+	recorded  map[string]*core.KeyHealth
+}
+
+func (r *fakeRegistry) ProviderRuntimeSnapshots() []providers.ProviderRuntimeSnapshot {
+	return append([]providers.ProviderRuntimeSnapshot(nil), r.snapshots...)
+}
+
+func (r *fakeRegistry) ProviderByName(name string) core.Provider { return r.providers[name] }
+
+func (r *fakeRegistry) RecordKeyHealth(name string, health *core.KeyHealth) {
+	if r.recorded == nil {
+		r.recorded = make(map[string]*core.KeyHealth)
+	}
+	r.recorded[name] = health
+}
+
+type recordingUsageLogger struct {
+	entries []*usage.UsageEntry
+}
+
+func (l *recordingUsageLogger) Write(entry *usage.UsageEntry) { l.entries = append(l.entries, entry) }
+func (l *recordingUsageLogger) Config() usage.Config          { return usage.Config{Enabled: true} }
+func (l *recordingUsageLogger) Flush(_ context.Context) error { return nil }
+func (l *recordingUsageLogger) Close() error                  { return nil }
+
+type webhookRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (w *webhookRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var event Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err == nil {
+		w.mu.Lock()
+		w.events = append(w.events, event)
+		w.mu.Unlock()
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func testConfig(webhookURL string) config.KeyHealthConfig {
+	return config.KeyHealthConfig{
+		Enabled:          true,
+		Interval:         time.Hour,
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		ExpiryWarning:    7 * 24 * time.Hour,
+		WebhookURL:       webhookURL,
+	}
+}
+
+func TestCheckAll_KeyBecomesInvalidAndRecovers(t *testing.T) {
+	unauthorized := core.ParseProviderError("openai", http.StatusUnauthorized, []byte(`{"error":{"message":"Incorrect API key provided"}}`), nil)
+	provider := &scriptedProvider{results: []error{nil, unauthorized, unauthorized, unauthorized, nil}}
+	registry := &fakeRegistry{
+		snapshots: []providers.ProviderRuntimeSnapshot{{Name: "openai-main", Type: "openai"}},
+		providers: map[string]core.Provider{"openai-main": provider},
+	}
+	webhook := &webhookRecorder{}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+	logger := &recordingUsageLogger{}
+
+	checker, err := New(testConfig(server.URL), registry, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	wantStatuses := []struct {
+		status       core.KeyHealthStatus
+		authFailures int
+	}{
+		{core.KeyHealthValid, 0},
+		{core.KeyHealthValid, 1},
+		{core.KeyHealthInvalid, 2},
+		{core.KeyHealthInvalid, 3},
+		{core.KeyHealthValid, 0},
+	}
+	for i, want := range wantStatuses {
+		checker.CheckAll(context.Background())
+		got := registry.recorded["openai-main"]
+		if got == nil || got.Status != want.status || got.ConsecutiveAuthFailures != want.authFailures {
+			t.Fatalf("check %d: key health = %+v, want %s with %d auth failures", i+1, got, want.status, want.authFailures)
+		}
+	}
+	if registry.recorded["openai-main"].LastValidAt == nil || registry.recorded["openai-main"].LastError != "" {
+		t.Fatalf("recovered key health = %+v, want last_valid_at and no error", registry.recorded["openai-main"])
+	}
+
+	checker.Close()
+	if len(webhook.events) != 2 {
+		t.Fatalf("webhook events = %+v, want key_invalid then key_recovered", webhook.events)
+	}
+	invalid, recovered := webhook.events[0], webhook.events[1]
+	if invalid.Type != eventType || invalid.Event != EventKeyInvalid || invalid.Provider != "openai-main" || invalid.ProviderType != "openai" || invalid.Error == "" {
+		t.Errorf("first event = %+v, want key_invalid for openai-main with the error", invalid)
+	}
+	if recovered.Event != EventKeyRecovered || recovered.Provider != "openai-main" {
+		t.Errorf("second event = %+v, want key_recovered for openai-main", recovered)
+	}
+
+	if len(logger.entries) != len(wantStatuses) {
+		t.Fatalf("usage entries = %d, want one per check", len(logger.entries))
+	}
+	for _, entry := range logger.entries {
+		if entry.Labels[UsageLabel] != UsageLabelValue || entry.ProviderName != "openai-main" || entry.Endpoint != endpointModels || entry.TotalTokens != 0 {
+			t.Fatalf("usage entry = %+v, want an internal zero-token key health entry", entry)
+		}
+	}
+}
+
+func TestCheckAll_AvailabilityFailuresLeaveStatus(t *testing.T) {
+	unavailable := core.ParseProviderError("openai", http.StatusServiceUnavailable, []byte(`{"error":{"message":"overloaded"}}`), nil)
+	provider := &scriptedProvider{results: []error{nil, unavailable, unavailable, unavailable}}
+	registry := &fakeRegistry{
+		snapshots: []providers.ProviderRuntimeSnapshot{{Name: "openai-main", Type: "openai"}},
+		providers: map[string]core.Provider{"openai-main": provider},
+	}
+	checker, err := New(testConfig(""), registry, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for range provider.results {
+		checker.CheckAll(context.Background())
+	}
+	got := registry.recorded["openai-main"]
+	if got.Status != core.KeyHealthValid || got.ConsecutiveAuthFailures != 0 || got.ConsecutiveAvailabilityFailures != 3 {
+		t.Fatalf("key health = %+v, want valid with 3 availability failures", got)
+	}
+}
+
+func TestCheckAll_SkipsProvidersItMustNotCall(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	openUntil := now.Add(time.Minute)
+	exhaustedReset := now.Add(time.Minute)
+	remaining := int64(0)
+	registry := &fakeRegistry{
+		snapshots: []providers.ProviderRuntimeSnapshot{
+			{Name: "lazy", Type: "openai", Initialization: providers.ProviderInitUninitialized},
+			{Name: "tripped", Type: "openai", CircuitOpenUntil: &openUntil},
+			{Name: "limited", Type: "openai", RateLimits: &core.RateLimits{
+				Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &exhaustedReset},
+			}},
+		},
+		providers: map[string]core.Provider{
+			"lazy":    &scriptedProvider{},
+			"tripped": &scriptedProvider{},
+			"limited": &scriptedProvider{},
+		},
+	}
+	checker, err := New(testConfig(""), registry, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	checker.now = func() time.Time { return now }
+
+	checker.CheckAll(context.Background())
+	if len(registry.recorded) != 0 {
+		t.Fatalf("recorded = %v, want no checks", registry.recorded)
+	}
+}
+
+func TestCheckAll_WarnsAboutExpiringKeyAndExhaustedCredits(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(48 * time.Hour)
+	limit, remaining := 10.0, 0.0
+	provider := &metadataProvider{metadata: core.KeyMetadata{
+		Label:           "sk-or-v1-abc...xyz",
+		ExpiresAt:       &expiresAt,
+		CreditLimit:     &limit,
+		CreditRemaining: &remaining,
+	}}
+	registry := &fakeRegistry{
+		snapshots: []providers.ProviderRuntimeSnapshot{{Name: "openrouter", Type: "openrouter"}},
+		providers: map[string]core.Provider{"openrouter": provider},
+	}
+	logger := &recordingUsageLogger{}
+	checker, err := New(testConfig(""), registry, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	checker.now = func() time.Time { return now }
+
+	checker.CheckAll(context.Background())
+	got := registry.recorded["openrouter"]
+	if got.Status != core.KeyHealthValid || got.Metadata == nil || got.Metadata.Label != "sk-or-v1-abc...xyz" {
+		t.Fatalf("key health = %+v, want valid with metadata", got)
+	}
+	if len(got.Warnings) != 2 || got.Warnings[0] != core.KeyWarningExpiring || got.Warnings[1] != core.KeyWarningQuotaExhausted {
+		t.Fatalf("warnings = %v, want key_expiring and quota_exhausted", got.Warnings)
+	}
+	if provider.calls != 0 {
+		t.Fatalf("ListModels calls = %d, want the key metadata request only", provider.calls)
+	}
+	if len(logger.entries) != 1 || logger.entries[0].Endpoint != endpointKey {
+		t.Fatalf("usage entries = %+v, want one key metadata entry", logger.entries)
+	}
+}
+
+func TestNew_DisabledAndInvalidWebhook(t *testing.T) {
+	checker, err := New(config.KeyHealthConfig{}, &fakeRegistry{}, nil)
+	if err != nil || checker != nil {
+		t.Fatalf("New(disabled) = %v, %v; want nil, nil", checker, err)
+	}
+	if _, err := New(testConfig("ftp://hooks.example.com"), &fakeRegistry{}, nil); err == nil {
+		t.Fatal("New() error = nil, want error for a non-http webhook URL")
+	}
+}
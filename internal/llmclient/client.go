@@ -22,7 +22,9 @@ import (
 
 	"gomodel/config"
 	"gomodel/internal/core"
+	"gomodel/internal/fairshare"
 	"gomodel/internal/httpclient"
+	"gomodel/internal/pacing"
 )
 
 // RequestInfo contains metadata about a request for observability hooks
@@ -55,6 +57,26 @@ type Hooks struct {
 	// OnRequestEnd is called after a request completes (success or failure).
 	// For streaming requests, this is called when the stream starts, not when it closes.
 	OnRequestEnd func(ctx context.Context, info ResponseInfo)
+
+	// OnRateLimits is called for every upstream response, including retried
+	// attempts, that reports account limits in its rate-limit headers.
+	OnRateLimits func(ctx context.Context, limits core.RateLimits)
+
+	// OnConnection is called when a request attempt gets an upstream
+	// connection, new or reused from the idle pool.
+	OnConnection func(ctx context.Context, info ConnectionInfo)
+
+	// OnCircuitChange is called when the provider's circuit breaker opens or
+	// closes again after recovering.
+	OnCircuitChange func(info CircuitInfo)
+}
+
+// CircuitInfo describes a circuit breaker state change.
+type CircuitInfo struct {
+	Provider string
+	Open     bool
+	// RetryAt is when an open circuit lets the next probe request through.
+	RetryAt time.Time
 }
 
 // Config holds configuration for the LLM client
@@ -70,6 +92,26 @@ type Config struct {
 	CircuitBreaker config.CircuitBreakerConfig
 	// Hooks provides optional observability callbacks invoked on request start and end.
 	Hooks Hooks
+	// Signing, when set, adds an HMAC signature header computed over each
+	// request body. Retries are re-signed.
+	Signing *config.RequestSigningConfig
+	// AcceptEncoding lists the response encodings requested for non-streaming
+	// calls, in preference order. The client decodes them itself. Empty keeps
+	// Go's transparent gzip handling. Streaming calls always request identity.
+	AcceptEncoding []string
+	// HTTPClient, when set, is used by New instead of the default client, for
+	// providers with their own proxy or TLS settings.
+	HTTPClient *http.Client
+	// Pacer, when set, queues each attempt until the provider's pacing
+	// buckets allow it. Clients of one provider share its Pacer.
+	Pacer *pacing.Pacer
+	// Scheduler, when set, holds each logical request, including its
+	// retries and, for streams, until the body is closed, to one of the
+	// provider's concurrency slots. Clients of one provider share it.
+	Scheduler *fairshare.Scheduler
+	// Quirks are the provider's serialization rules, applied to each
+	// request Body after it is marshaled.
+	Quirks Quirks
 }
 
 // DefaultConfig returns default client configuration
@@ -92,33 +134,27 @@ type Client struct {
 	config         Config
 	headerSetter   HeaderSetter
 	circuitBreaker *circuitBreaker
+	acceptEncoding string
 }
 
 // New creates a new LLM client with the given configuration
 func New(cfg Config, headerSetter HeaderSetter) *Client {
-	c := &Client{
-		httpClient:   httpclient.NewDefaultHTTPClient(),
-		config:       cfg,
-		headerSetter: headerSetter,
-	}
-
-	if cfg.CircuitBreaker.FailureThreshold > 0 {
-		c.circuitBreaker = newCircuitBreaker(
-			cfg.CircuitBreaker.FailureThreshold,
-			cfg.CircuitBreaker.SuccessThreshold,
-			cfg.CircuitBreaker.Timeout,
-		)
+	if cfg.HTTPClient != nil {
+		return NewWithHTTPClient(cfg.HTTPClient, cfg, headerSetter)
 	}
-
-	return c
+	return NewWithHTTPClient(httpclient.NewDefaultHTTPClient(), cfg, headerSetter)
 }
 
 // NewWithHTTPClient creates a new LLM client with a custom HTTP client
 func NewWithHTTPClient(httpClient *http.Client, cfg Config, headerSetter HeaderSetter) *Client {
+	if cfg.Signing != nil {
+		httpClient = withRequestSigning(httpClient, *cfg.Signing)
+	}
 	c := &Client{
-		httpClient:   httpClient,
-		config:       cfg,
-		headerSetter: headerSetter,
+		httpClient:     httpClient,
+		config:         cfg,
+		headerSetter:   headerSetter,
+		acceptEncoding: strings.Join(cfg.AcceptEncoding, ", "),
 	}
 
 	if cfg.CircuitBreaker.FailureThreshold > 0 {
@@ -176,6 +212,8 @@ type requestScope struct {
 	startedAt     time.Time
 	requestInfo   RequestInfo
 	halfOpenProbe bool
+	// release gives back the request's concurrency slot.
+	release func()
 }
 
 func (c *Client) beginRequest(ctx context.Context, req Request, stream bool) (requestScope, error) {
@@ -195,11 +233,26 @@ func (c *Client) beginRequest(ctx context.Context, req Request, stream bool) (re
 		scope.ctx = c.config.Hooks.OnRequestStart(scope.ctx, scope.requestInfo)
 	}
 
+	// Pacing runs before the circuit breaker so a request that gives up in
+	// the queue never holds the half-open probe slot.
+	if err := c.pace(scope.ctx, req); err != nil {
+		c.finishRequest(scope, extractStatusCode(err), err)
+		return requestScope{}, err
+	}
+
+	release, err := c.acquireSlot(scope.ctx, scope.requestInfo.Model)
+	if err != nil {
+		c.finishRequest(scope, extractStatusCode(err), err)
+		return requestScope{}, err
+	}
+	scope.release = release
+
 	if c.circuitBreaker != nil {
 		allowed, probe := c.circuitBreaker.acquire()
 		if !allowed {
+			release()
 			err := core.NewProviderError(c.config.ProviderName, http.StatusServiceUnavailable,
-				"circuit breaker is open - provider temporarily unavailable", nil)
+				"circuit breaker is open - provider temporarily unavailable", nil).WithCode("circuit_open")
 			c.finishRequest(scope, http.StatusServiceUnavailable, err)
 			return requestScope{}, err
 		}
@@ -210,6 +263,9 @@ func (c *Client) beginRequest(ctx context.Context, req Request, stream bool) (re
 }
 
 func (c *Client) finishRequest(scope requestScope, statusCode int, err error) {
+	duration := time.Since(scope.startedAt)
+	core.RecordUpstreamCall(scope.ctx, upstreamStatusCode(statusCode, err), duration)
+
 	if c.config.Hooks.OnRequestEnd == nil {
 		return
 	}
@@ -218,31 +274,57 @@ func (c *Client) finishRequest(scope requestScope, statusCode int, err error) {
 		Model:      scope.requestInfo.Model,
 		Endpoint:   scope.requestInfo.Endpoint,
 		StatusCode: statusCode,
-		Duration:   time.Since(scope.startedAt),
+		Duration:   duration,
 		Stream:     scope.requestInfo.Stream,
 		Error:      err,
 	})
 }
 
+// upstreamStatusCode returns the status the provider actually answered with.
+// Errors carry it on the parsed provider error; transport failures and open
+// circuits have none.
+func upstreamStatusCode(statusCode int, err error) int {
+	if err == nil {
+		return statusCode
+	}
+	var gatewayErr *core.GatewayError
+	if errors.As(err, &gatewayErr) {
+		return gatewayErr.UpstreamStatusCode
+	}
+	return 0
+}
+
 func (c *Client) recordCircuitBreakerCompletion(statusCode int, err error) {
 	if c.circuitBreaker == nil {
 		return
 	}
 	if err != nil {
-		c.circuitBreaker.RecordFailure()
+		c.circuitFailure()
 		return
 	}
 	if statusCode == http.StatusTooManyRequests {
 		if c.circuitBreaker.IsHalfOpen() {
-			c.circuitBreaker.RecordFailure()
+			c.circuitFailure()
 		}
 		return
 	}
 	if c.shouldTripCircuitBreaker(statusCode) {
-		c.circuitBreaker.RecordFailure()
+		c.circuitFailure()
 		return
 	}
-	c.circuitBreaker.RecordSuccess()
+	if c.circuitBreaker.RecordSuccess() && c.config.Hooks.OnCircuitChange != nil {
+		c.config.Hooks.OnCircuitChange(CircuitInfo{Provider: c.config.ProviderName})
+	}
+}
+
+func (c *Client) circuitFailure() {
+	if c.circuitBreaker.RecordFailure() && c.config.Hooks.OnCircuitChange != nil {
+		c.config.Hooks.OnCircuitChange(CircuitInfo{
+			Provider: c.config.ProviderName,
+			Open:     true,
+			RetryAt:  time.Now().Add(c.circuitBreaker.timeout),
+		})
+	}
 }
 
 func (c *Client) shouldTripCircuitBreaker(statusCode int) bool {
@@ -260,7 +342,9 @@ func (c *Client) maxAttempts() int {
 	return maxAttempts
 }
 
-func (c *Client) waitForRetry(ctx context.Context, attempt int) error {
+// waitForRetry sleeps through the backoff before a retry attempt, then
+// queues the attempt for pacing like the first one.
+func (c *Client) waitForRetry(ctx context.Context, attempt int, req Request) error {
 	if attempt <= 0 {
 		return nil
 	}
@@ -269,8 +353,78 @@ func (c *Client) waitForRetry(ctx context.Context, attempt int) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(backoff):
+	}
+	return c.pace(ctx, req)
+}
+
+// pace queues req until the provider's pacer allows it and records the
+// delay for the audit log.
+func (c *Client) pace(ctx context.Context, req Request) error {
+	pacer := c.config.Pacer
+	if pacer == nil {
+		return nil
+	}
+	tokens := 0
+	if pacer.PacesTokens() {
+		tokens = estimatePromptTokens(req)
+	}
+	delay, err := pacer.Wait(ctx, tokens)
+	core.RecordPacingDelay(ctx, delay)
+	if err == nil {
 		return nil
 	}
+	if errors.Is(err, pacing.ErrTimeout) {
+		return core.NewRateLimitError(c.config.ProviderName, "request pacing queue is full, try again later").WithCode("pacing_timeout")
+	}
+	return core.NewProviderError(c.config.ProviderName, providerErrorStatusCode(err), "request pacing interrupted: "+err.Error(), err)
+}
+
+// acquireSlot queues for one of the provider's concurrency slots for model
+// and returns the function that gives it back.
+func (c *Client) acquireSlot(ctx context.Context, model string) (func(), error) {
+	release, err := c.config.Scheduler.Acquire(ctx, model)
+	if err == nil {
+		return release, nil
+	}
+	if errors.Is(err, fairshare.ErrTimeout) {
+		return nil, core.NewRateLimitError(c.config.ProviderName, "provider concurrency queue is full, try again later").WithCode("concurrency_timeout")
+	}
+	return nil, core.NewProviderError(c.config.ProviderName, providerErrorStatusCode(err), "concurrency queue wait interrupted: "+err.Error(), err)
+}
+
+// slotBody gives back a streaming request's concurrency slot when the
+// response body is closed.
+type slotBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// holdSlot ties the concurrency slot of scope to body.
+func holdSlot(body io.ReadCloser, scope requestScope) io.ReadCloser {
+	if scope.release == nil {
+		return body
+	}
+	return &slotBody{ReadCloser: body, release: scope.release}
+}
+
+// estimatePromptTokens approximates the prompt size of req at four bytes per
+// token of its body. Streamed bodies count as zero.
+func estimatePromptTokens(req Request) int {
+	size := len(req.RawBody)
+	if req.Body != nil {
+		body, err := json.Marshal(req.Body)
+		if err != nil {
+			return 0
+		}
+		size = len(body)
+	}
+	return (size + 3) / 4
 }
 
 // Do executes a request with retries and circuit breaking, then unmarshals the response
@@ -316,6 +470,7 @@ func (c *Client) DoRaw(ctx context.Context, req Request) (*Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer scope.release()
 	ctx = scope.ctx
 
 	var lastErr error
@@ -327,8 +482,8 @@ func (c *Client) DoRaw(ctx context.Context, req Request) (*Response, error) {
 	}
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		if err := c.waitForRetry(ctx, attempt); err != nil {
-			c.finishRequest(scope, 0, err)
+		if err := c.waitForRetry(ctx, attempt, req); err != nil {
+			c.finishRequest(scope, extractStatusCode(err), err)
 			return nil, err
 		}
 
@@ -393,14 +548,16 @@ func (c *Client) DoRaw(ctx context.Context, req Request) (*Response, error) {
 // DoStream executes a streaming request, returning a ReadCloser
 // Note: Streaming requests do NOT retry (as partial data may have been sent)
 // Metrics note: Duration is measured from start to stream establishment, not stream close
+// The stream holds its concurrency slot until it is closed.
 func (c *Client) DoStream(ctx context.Context, req Request) (io.ReadCloser, error) {
 	scope, err := c.beginRequest(ctx, req, true)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.doHTTPRequest(scope.ctx, req)
+	resp, err := c.doHTTPRequest(scope.ctx, withStreamingAcceptEncoding(req))
 	if err != nil {
+		scope.release()
 		c.recordCircuitBreakerCompletion(extractStatusCode(err), err)
 		c.finishRequest(scope, extractStatusCode(err), err)
 		return nil, err
@@ -412,6 +569,7 @@ func (c *Client) DoStream(ctx context.Context, req Request) (io.ReadCloser, erro
 			respBody = []byte("failed to read error response")
 		}
 		_ = resp.Body.Close()
+		scope.release()
 
 		c.recordCircuitBreakerCompletion(resp.StatusCode, nil)
 		providerErr := core.ParseProviderError(c.config.ProviderName, resp.StatusCode, respBody, nil)
@@ -421,7 +579,7 @@ func (c *Client) DoStream(ctx context.Context, req Request) (io.ReadCloser, erro
 
 	c.recordCircuitBreakerCompletion(resp.StatusCode, nil)
 	c.finishRequest(scope, resp.StatusCode, nil)
-	return resp.Body, nil
+	return holdSlot(decodedBody(resp), scope), nil
 }
 
 func canRetryPassthrough(req Request) bool {
@@ -455,6 +613,7 @@ func hasIdempotencyKey(headers http.Header) bool {
 
 // DoPassthrough executes a request and returns the raw upstream HTTP response.
 // Unlike DoRaw, it preserves non-200 responses for the caller to proxy unchanged.
+// The response holds its concurrency slot until its body is closed.
 func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response, error) {
 	stream := strings.Contains(strings.ToLower(strings.Join(req.Headers.Values("Accept"), ",")), "text/event-stream")
 	scope, err := c.beginRequest(ctx, req, stream)
@@ -462,6 +621,9 @@ func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response
 		return nil, err
 	}
 	ctx = scope.ctx
+	if stream {
+		req = withStreamingAcceptEncoding(req)
+	}
 
 	maxAttempts := 1
 	if canRetryPassthrough(req) {
@@ -469,8 +631,9 @@ func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response
 	}
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		if err := c.waitForRetry(ctx, attempt); err != nil {
-			c.finishRequest(scope, 0, err)
+		if err := c.waitForRetry(ctx, attempt, req); err != nil {
+			scope.release()
+			c.finishRequest(scope, extractStatusCode(err), err)
 			return nil, err
 		}
 
@@ -478,6 +641,7 @@ func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response
 		if err != nil {
 			statusCode := extractStatusCode(err)
 			if scope.halfOpenProbe || isClientTimeoutGatewayError(err) || attempt == maxAttempts-1 {
+				scope.release()
 				c.recordCircuitBreakerCompletion(statusCode, err)
 				c.finishRequest(scope, statusCode, err)
 				return nil, err
@@ -490,6 +654,7 @@ func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response
 			if scope.halfOpenProbe || attempt == maxAttempts-1 {
 				c.recordCircuitBreakerCompletion(resp.StatusCode, nil)
 				c.finishRequest(scope, resp.StatusCode, nil)
+				resp.Body = holdSlot(resp.Body, scope)
 				return resp, nil
 			}
 			_ = resp.Body.Close()
@@ -498,9 +663,11 @@ func (c *Client) DoPassthrough(ctx context.Context, req Request) (*http.Response
 
 		c.recordCircuitBreakerCompletion(resp.StatusCode, nil)
 		c.finishRequest(scope, resp.StatusCode, nil)
+		resp.Body = holdSlot(resp.Body, scope)
 		return resp, nil
 	}
 
+	scope.release()
 	err = core.NewProviderError(c.config.ProviderName, http.StatusBadGateway, "request failed after retries", nil)
 	c.recordCircuitBreakerCompletion(http.StatusBadGateway, err)
 	c.finishRequest(scope, http.StatusBadGateway, err)
@@ -555,6 +722,7 @@ func (c *Client) doHTTPRequest(ctx context.Context, req Request) (*http.Response
 	if err != nil {
 		return nil, core.NewProviderError(c.config.ProviderName, providerErrorStatusCode(err), "failed to send request: "+err.Error(), err)
 	}
+	c.observeRateLimits(ctx, resp.Header)
 	return resp, nil
 }
 
@@ -562,15 +730,16 @@ func (c *Client) doHTTPRequest(ctx context.Context, req Request) (*http.Response
 // Note: Metrics hooks are called at the DoRaw level, not here, to avoid
 // counting each retry attempt as a separate request.
 func (c *Client) doRequest(ctx context.Context, req Request) (*Response, error) {
-	resp, err := c.doHTTPRequest(ctx, req)
+	resp, err := c.doHTTPRequest(ctx, c.withBufferedAcceptEncoding(req))
 	if err != nil {
 		return nil, err
 	}
+	respBody := decodedBody(resp)
 	defer func() {
-		_ = resp.Body.Close()
+		_ = respBody.Close()
 	}()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(respBody)
 	if err != nil {
 		return nil, core.NewProviderError(c.config.ProviderName, providerErrorStatusCode(err), "failed to read response: "+err.Error(), err)
 	}
@@ -599,7 +768,7 @@ func (c *Client) buildRequest(ctx context.Context, req Request) (*http.Request,
 		return nil, core.NewInvalidRequestError(fmt.Sprintf("invalid HTTP method: %s", req.Method), nil)
 	}
 
-	url := c.getBaseURL() + req.Endpoint
+	url := httpclient.JoinURL(c.getBaseURL(), req.Endpoint)
 
 	var bodyReader io.Reader
 	bodySources := 0
@@ -624,10 +793,13 @@ func (c *Client) buildRequest(ctx context.Context, req Request) (*http.Request,
 		if err != nil {
 			return nil, core.NewInvalidRequestError("failed to marshal request", err)
 		}
+		if bodyBytes, err = c.config.Quirks.Apply(req.Endpoint, bodyBytes); err != nil {
+			return nil, core.NewInvalidRequestError("failed to apply provider serialization quirks", err)
+		}
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, req.Method, url, bodyReader)
+	httpReq, err := http.NewRequestWithContext(c.traceConnection(ctx), req.Method, url, bodyReader)
 	if err != nil {
 		return nil, core.NewInvalidRequestError("failed to create request", err)
 	}
@@ -787,8 +959,9 @@ func (cb *circuitBreaker) Allow() bool {
 	return allowed
 }
 
-// RecordSuccess records a successful request
-func (cb *circuitBreaker) RecordSuccess() {
+// RecordSuccess records a successful request and reports whether it closed
+// the circuit.
+func (cb *circuitBreaker) RecordSuccess() bool {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -799,14 +972,17 @@ func (cb *circuitBreaker) RecordSuccess() {
 		if cb.successes >= cb.successThreshold {
 			cb.state = circuitClosed
 			cb.failures = 0
+			return true
 		}
 	case circuitClosed:
 		cb.failures = 0
 	}
+	return false
 }
 
-// RecordFailure records a failed request
-func (cb *circuitBreaker) RecordFailure() {
+// RecordFailure records a failed request and reports whether it opened the
+// circuit.
+func (cb *circuitBreaker) RecordFailure() bool {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -817,12 +993,15 @@ func (cb *circuitBreaker) RecordFailure() {
 	case circuitClosed:
 		if cb.failures >= cb.failureThreshold {
 			cb.state = circuitOpen
+			return true
 		}
 	case circuitHalfOpen:
 		cb.state = circuitOpen
 		cb.successes = 0
 		cb.halfOpenAllowed = true // Reset for next timeout period
+		return true
 	}
+	return false
 }
 
 // State returns the current circuit state (for testing/monitoring)
@@ -16,6 +16,8 @@ import (
 
 	goconfig "gomodel/config"
 	"gomodel/internal/core"
+	"gomodel/internal/fairshare"
+	"gomodel/internal/pacing"
 )
 
 func TestClient_Do_Success(t *testing.T) {
@@ -893,6 +895,42 @@ func TestCircuitBreaker_ClosesAfterTimeout(t *testing.T) {
 	}
 }
 
+func TestCircuitBreaker_ReportsStateChanges(t *testing.T) {
+	var shouldSucceed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if shouldSucceed.Load() {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var changes []CircuitInfo
+	config := DefaultConfig("test", server.URL)
+	config.Retry.MaxRetries = 0
+	config.CircuitBreaker = goconfig.CircuitBreakerConfig{FailureThreshold: 2, SuccessThreshold: 1, Timeout: 20 * time.Millisecond}
+	config.Hooks.OnCircuitChange = func(info CircuitInfo) { changes = append(changes, info) }
+	client := New(config, nil)
+
+	for range 3 {
+		_ = client.Do(context.Background(), Request{Method: http.MethodGet, Endpoint: "/test"}, nil)
+	}
+	if len(changes) != 1 || !changes[0].Open || changes[0].Provider != "test" || changes[0].RetryAt.IsZero() {
+		t.Fatalf("changes = %+v, want a single open notification", changes)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	shouldSucceed.Store(true)
+	if err := client.Do(context.Background(), Request{Method: http.MethodGet, Endpoint: "/test"}, nil); err != nil {
+		t.Fatalf("probe request error = %v", err)
+	}
+	if len(changes) != 2 || changes[1].Open {
+		t.Fatalf("changes = %+v, want the circuit reported closed", changes)
+	}
+}
+
 // TestCircuitBreaker_HalfOpenPreventsThunderingHerd tests that only one request
 // is allowed through in half-open state to prevent thundering herd
 func TestCircuitBreaker_HalfOpenPreventsThunderingHerd(t *testing.T) {
@@ -1576,3 +1614,219 @@ func TestBackoffCalculation_WithJitter(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_RecordsUpstreamStatusBeforeMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig("anthropic", server.URL)
+	config.Retry.MaxRetries = 0
+	client := New(config, nil)
+
+	call := &core.UpstreamCall{}
+	ctx := core.WithUpstreamCall(context.Background(), call)
+	_, err := client.DoRaw(ctx, Request{Method: http.MethodPost, Endpoint: "/messages"})
+
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) {
+		t.Fatalf("expected GatewayError, got %T (%v)", err, err)
+	}
+	statusCode, duration := call.Result()
+	if statusCode != 529 {
+		t.Errorf("upstream status = %d, want 529", statusCode)
+	}
+	if gatewayErr.HTTPStatusCode() == statusCode {
+		t.Errorf("gateway status %d should differ from upstream status %d", gatewayErr.HTTPStatusCode(), statusCode)
+	}
+	if duration <= 0 {
+		t.Errorf("upstream duration = %v, want > 0", duration)
+	}
+}
+
+func TestClient_DoStream_RecordsUpstreamStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := New(DefaultConfig("test", server.URL), nil)
+	call := &core.UpstreamCall{}
+	stream, err := client.DoStream(core.WithUpstreamCall(context.Background(), call), Request{
+		Method:   http.MethodPost,
+		Endpoint: "/stream",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	if statusCode, _ := call.Result(); statusCode != http.StatusOK {
+		t.Errorf("upstream status = %d, want 200", statusCode)
+	}
+}
+
+func TestClient_PacingQueuesBurstAndRecordsDelay(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig("test", server.URL)
+	config.Pacer = pacing.New(&goconfig.PacingConfig{RequestsPerSecond: 20, Burst: 1, MaxWait: time.Second})
+	client := New(config, nil)
+
+	first := &core.UpstreamCall{}
+	if _, err := client.DoRaw(core.WithUpstreamCall(context.Background(), first), Request{Method: http.MethodGet, Endpoint: "/models"}); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	second := &core.UpstreamCall{}
+	if _, err := client.DoRaw(core.WithUpstreamCall(context.Background(), second), Request{Method: http.MethodGet, Endpoint: "/models"}); err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+
+	if delay := first.PacingDelay(); delay != 0 {
+		t.Errorf("first pacing delay = %v, want 0 within the burst", delay)
+	}
+	if delay := second.PacingDelay(); delay <= 0 || delay > 50*time.Millisecond {
+		t.Errorf("second pacing delay = %v, want up to 50ms", delay)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("upstream hits = %d, want 2", hits.Load())
+	}
+}
+
+func TestClient_PacingTimeoutFailsWithoutTrippingCircuit(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig("test", server.URL)
+	config.CircuitBreaker.FailureThreshold = 1
+	config.Pacer = pacing.New(&goconfig.PacingConfig{
+		RequestsPerSecond: 0.1,
+		Burst:             1,
+		MaxWait:           time.Millisecond,
+		OnTimeout:         goconfig.PacingOnTimeoutFail,
+	})
+	client := New(config, nil)
+
+	stream, err := client.DoStream(context.Background(), Request{Method: http.MethodPost, Endpoint: "/stream"})
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	_ = stream.Close()
+
+	for range 2 {
+		_, err = client.DoStream(context.Background(), Request{Method: http.MethodPost, Endpoint: "/stream"})
+		var gatewayErr *core.GatewayError
+		if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusTooManyRequests || gatewayErr.Code == nil || *gatewayErr.Code != "pacing_timeout" {
+			t.Fatalf("paced request error = %v, want a 429 pacing_timeout", err)
+		}
+	}
+	if hits.Load() != 1 {
+		t.Errorf("upstream hits = %d, want 1", hits.Load())
+	}
+	if state := client.circuitBreaker.State(); state != "closed" {
+		t.Errorf("circuit state = %s, want closed", state)
+	}
+}
+
+func TestClient_ConcurrencySharesSaturatedProviderByWeight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig("test", server.URL)
+	config.Scheduler = fairshare.New(&goconfig.ConcurrencyConfig{
+		MaxInFlight:  2,
+		ModelWeights: map[string]float64{"heavy": 3},
+	})
+	client := New(config, nil)
+
+	// Both models keep more requests waiting than the provider has slots,
+	// so each freed slot is a scheduling decision between them.
+	var heavy, light atomic.Int64
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, model := range []string{"heavy", "light"} {
+		completed := &heavy
+		if model == "light" {
+			completed = &light
+		}
+		for range 6 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := Request{Method: http.MethodPost, Endpoint: "/chat", Body: &core.ChatRequest{Model: model}}
+				for ctx.Err() == nil {
+					if _, err := client.DoRaw(ctx, req); err == nil {
+						completed.Add(1)
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if light.Load() < 10 {
+		t.Fatalf("light completed %d requests, want enough to compare", light.Load())
+	}
+	ratio := float64(heavy.Load()) / float64(light.Load())
+	if ratio < 2.4 || ratio > 3.6 {
+		t.Fatalf("heavy:light = %d:%d (%.2f), want about 3", heavy.Load(), light.Load(), ratio)
+	}
+	if snapshot := config.Scheduler.Snapshot(); snapshot.InFlight != 0 || snapshot.Waiting != 0 {
+		t.Fatalf("scheduler = %+v, want every slot released", snapshot)
+	}
+}
+
+func TestClient_StreamHoldsConcurrencySlotUntilClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig("test", server.URL)
+	config.Scheduler = fairshare.New(&goconfig.ConcurrencyConfig{MaxInFlight: 1, MaxWait: 20 * time.Millisecond})
+	client := New(config, nil)
+	req := Request{Method: http.MethodPost, Endpoint: "/stream", Body: &core.ChatRequest{Model: "gpt-4o"}}
+
+	stream, err := client.DoStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("first stream: %v", err)
+	}
+	if _, err := io.ReadAll(stream); err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+
+	// The drained but open stream still holds the only slot.
+	_, err = client.DoStream(context.Background(), req)
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusTooManyRequests || gatewayErr.Code == nil || *gatewayErr.Code != "concurrency_timeout" {
+		t.Fatalf("second stream error = %v, want a 429 concurrency_timeout", err)
+	}
+
+	_ = stream.Close()
+	stream, err = client.DoStream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("stream after close: %v", err)
+	}
+	_ = stream.Close()
+	if snapshot := config.Scheduler.Snapshot(); snapshot.InFlight != 0 || snapshot.Rejected != 1 {
+		t.Fatalf("scheduler = %+v, want no slot held and one rejection", snapshot)
+	}
+}
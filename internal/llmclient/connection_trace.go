@@ -0,0 +1,50 @@
+package llmclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// ConnectionInfo describes the upstream connection one request attempt got
+// from the pool, for connection pool metrics.
+type ConnectionInfo struct {
+	Provider string // Provider name
+	Reused   bool   // Whether the connection was reused from the idle pool
+	// TLSHandshake is how long the TLS handshake of a new connection took;
+	// zero for reused and plain-text connections.
+	TLSHandshake time.Duration
+}
+
+// traceConnection returns ctx with an httptrace hook reporting the
+// connection of the request to Hooks.OnConnection, or ctx unchanged when the
+// hook is not set.
+func (c *Client) traceConnection(ctx context.Context) context.Context {
+	onConnection := c.config.Hooks.OnConnection
+	if onConnection == nil {
+		return ctx
+	}
+	// The transport may dial on its own goroutine and hand the request an
+	// idle connection while that dial is still running, so the handshake
+	// timing is shared atomically.
+	var handshakeStart, handshake atomic.Int64
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeStart: func() {
+			handshakeStart.Store(time.Now().UnixNano())
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if start := handshakeStart.Load(); start != 0 {
+				handshake.Store(time.Now().UnixNano() - start)
+			}
+		},
+		GotConn: func(conn httptrace.GotConnInfo) {
+			info := ConnectionInfo{Provider: c.config.ProviderName, Reused: conn.Reused}
+			if !conn.Reused {
+				info.TLSHandshake = time.Duration(handshake.Load())
+			}
+			onConnection(ctx, info)
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package llmclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gomodel/internal/httpclient"
+)
+
+// TestClient_ReusesConnectionsUnderLoad sends concurrent requests to a TLS
+// server and checks that the pool serves nearly all of them from reused
+// connections.
+func TestClient_ReusesConnectionsUnderLoad(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	clientCfg := httpclient.DefaultConfig()
+	clientCfg.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	var reused, opened, handshakes atomic.Int64
+	cfg := DefaultConfig("test", server.URL)
+	cfg.Retry.MaxRetries = 0
+	cfg.HTTPClient = httpclient.NewHTTPClient(&clientCfg)
+	cfg.Hooks.OnConnection = func(_ context.Context, info ConnectionInfo) {
+		if info.Provider != "test" {
+			t.Errorf("provider = %q, want test", info.Provider)
+		}
+		if info.Reused {
+			reused.Add(1)
+			return
+		}
+		opened.Add(1)
+		if info.TLSHandshake > 0 {
+			handshakes.Add(1)
+		}
+	}
+	client := New(cfg, nil)
+
+	const workers, perWorker = 8, 25
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				if _, err := client.DoRaw(context.Background(), Request{Method: http.MethodGet, Endpoint: "/models"}); err != nil {
+					t.Errorf("DoRaw() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := reused.Load() + opened.Load()
+	if total != workers*perWorker {
+		t.Fatalf("connections reported = %d, want one per request (%d)", total, workers*perWorker)
+	}
+	// A dial started for one request can finish after another connection
+	// served it, so allow a few more connections than workers.
+	if opened.Load() > 2*workers {
+		t.Fatalf("opened %d connections for %d concurrent workers", opened.Load(), workers)
+	}
+	if reused.Load() < 10*opened.Load() {
+		t.Fatalf("reused = %d, opened = %d; want reused connections to dominate", reused.Load(), opened.Load())
+	}
+	if handshakes.Load() == 0 {
+		t.Fatal("no TLS handshake duration reported for new connections")
+	}
+}
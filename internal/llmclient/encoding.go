@@ -0,0 +1,73 @@
+package llmclient
+
+import (
+	"io"
+	"net/http"
+
+	"gomodel/internal/compression"
+)
+
+const acceptEncodingHeader = "Accept-Encoding"
+
+// withAcceptEncoding returns req with its Accept-Encoding header set to value.
+// The header map is cloned so the caller's request is left untouched.
+func withAcceptEncoding(req Request, value string) Request {
+	headers := req.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header, 1)
+	}
+	headers.Set(acceptEncodingHeader, value)
+	req.Headers = headers
+	return req
+}
+
+// withStreamingAcceptEncoding asks the provider for an uncompressed stream.
+// Compressed SSE is decoded in compressor-sized blocks, which would hold back
+// events that the upstream already flushed.
+func withStreamingAcceptEncoding(req Request) Request {
+	return withAcceptEncoding(req, compression.Identity)
+}
+
+// withBufferedAcceptEncoding applies the configured Accept-Encoding to a
+// buffered request unless the caller set one explicitly. With none configured,
+// Go's transport keeps negotiating and decoding gzip transparently.
+func (c *Client) withBufferedAcceptEncoding(req Request) Request {
+	if c.acceptEncoding == "" || req.Headers.Get(acceptEncodingHeader) != "" {
+		return req
+	}
+	return withAcceptEncoding(req, c.acceptEncoding)
+}
+
+// decodedBody returns resp.Body decoded according to its Content-Encoding.
+// Bodies the transport already decoded, and encodings the gateway cannot
+// decode, are returned unchanged.
+func decodedBody(resp *http.Response) io.ReadCloser {
+	if resp.Uncompressed {
+		return resp.Body
+	}
+	encoding := compression.ParseEncoding(resp.Header.Get("Content-Encoding"))
+	if !compression.Supported(encoding) {
+		return resp.Body
+	}
+	decoder, err := compression.NewReader(resp.Body, encoding)
+	if err != nil {
+		return resp.Body
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return &decodingBody{Reader: decoder, decoder: decoder, body: resp.Body}
+}
+
+// decodingBody closes both the decoder and the underlying response body.
+type decodingBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.Closer
+}
+
+func (d *decodingBody) Close() error {
+	_ = d.decoder.Close()
+	return d.body.Close()
+}
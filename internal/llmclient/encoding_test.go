@@ -0,0 +1,177 @@
+package llmclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func encodeBody(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch encoding {
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(body)
+		_ = w.Close()
+	case "br":
+		w := brotli.NewWriter(&buf)
+		_, _ = w.Write(body)
+		_ = w.Close()
+	case "zstd":
+		w, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatalf("zstd writer: %v", err)
+		}
+		defer w.Close()
+		return w.EncodeAll(body, nil)
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+	return buf.Bytes()
+}
+
+// newEncodingServer replies with body encoded as the first requested
+// encoding, or as forceEncoding when set, and records Accept-Encoding.
+func newEncodingServer(t *testing.T, contentType, forceEncoding string, body []byte) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var accepted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding := r.Header.Get("Accept-Encoding")
+		mu.Lock()
+		accepted = append(accepted, acceptEncoding)
+		mu.Unlock()
+
+		encoding := forceEncoding
+		if encoding == "" {
+			for _, candidate := range []string{"zstd", "br", "gzip"} {
+				if strings.HasPrefix(acceptEncoding, candidate) {
+					encoding = candidate
+					break
+				}
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		if encoding == "" {
+			_, _ = w.Write(body)
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(encodeBody(t, encoding, body))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), accepted...)
+	}
+}
+
+func TestClient_AcceptEncoding_DecodesEachEncoding(t *testing.T) {
+	for _, encoding := range []string{"zstd", "br", "gzip"} {
+		t.Run(encoding, func(t *testing.T) {
+			server, accepted := newEncodingServer(t, "application/json", "", []byte(`{"id":"chatcmpl-1"}`))
+			cfg := DefaultConfig("test", server.URL)
+			cfg.AcceptEncoding = []string{encoding, "gzip"}
+			client := New(cfg, nil)
+
+			var result struct {
+				ID string `json:"id"`
+			}
+			if err := client.Do(context.Background(), Request{Method: http.MethodPost, Endpoint: "/chat", Body: map[string]string{"model": "m"}}, &result); err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if result.ID != "chatcmpl-1" {
+				t.Fatalf("ID = %q, want decoded response", result.ID)
+			}
+			if got := accepted(); len(got) != 1 || got[0] != encoding+", gzip" {
+				t.Fatalf("Accept-Encoding = %v, want %q", got, encoding+", gzip")
+			}
+		})
+	}
+}
+
+func TestClient_AcceptEncoding_DefaultKeepsTransparentGzip(t *testing.T) {
+	server, accepted := newEncodingServer(t, "application/json", "", []byte(`{"ok":true}`))
+	client := New(DefaultConfig("test", server.URL), nil)
+
+	resp, err := client.DoRaw(context.Background(), Request{Method: http.MethodGet, Endpoint: "/models"})
+	if err != nil {
+		t.Fatalf("DoRaw() error = %v", err)
+	}
+	if string(resp.Body) != `{"ok":true}` {
+		t.Fatalf("body = %q, want decoded JSON", resp.Body)
+	}
+	if got := accepted(); len(got) != 1 || got[0] != "gzip" {
+		t.Fatalf("Accept-Encoding = %v, want Go's default gzip", got)
+	}
+}
+
+func TestClient_AcceptEncoding_StreamsRequestIdentity(t *testing.T) {
+	stream := []byte("data: {\"choices\":[]}\n\ndata: [DONE]\n\n")
+	server, accepted := newEncodingServer(t, "text/event-stream", "", stream)
+	cfg := DefaultConfig("test", server.URL)
+	cfg.AcceptEncoding = []string{"zstd", "br", "gzip"}
+	client := New(cfg, nil)
+
+	body, err := client.DoStream(context.Background(), Request{Method: http.MethodPost, Endpoint: "/chat", Body: map[string]string{"model": "m"}})
+	if err != nil {
+		t.Fatalf("DoStream() error = %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	_ = body.Close()
+
+	if !bytes.Equal(got, stream) {
+		t.Fatalf("stream = %q, want %q", got, stream)
+	}
+	if headers := accepted(); len(headers) != 1 || headers[0] != "identity" {
+		t.Fatalf("Accept-Encoding = %v, want identity for streams", headers)
+	}
+}
+
+func TestClient_AcceptEncoding_DecodesStreamCompressedAnyway(t *testing.T) {
+	stream := []byte("data: {\"choices\":[]}\n\ndata: [DONE]\n\n")
+	server, _ := newEncodingServer(t, "text/event-stream", "zstd", stream)
+	client := New(DefaultConfig("test", server.URL), nil)
+
+	body, err := client.DoStream(context.Background(), Request{Method: http.MethodPost, Endpoint: "/chat", Body: map[string]string{"model": "m"}})
+	if err != nil {
+		t.Fatalf("DoStream() error = %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	if err := body.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !bytes.Equal(got, stream) {
+		t.Fatalf("stream = %q, want decoded SSE", got)
+	}
+}
+
+func TestClient_AcceptEncoding_PassthroughStreamRequestsIdentity(t *testing.T) {
+	server, accepted := newEncodingServer(t, "text/event-stream", "", []byte("data: [DONE]\n\n"))
+	client := New(DefaultConfig("test", server.URL), nil)
+
+	resp, err := client.DoPassthrough(context.Background(), Request{
+		Method:   http.MethodPost,
+		Endpoint: "/chat",
+		RawBody:  []byte(`{"stream":true}`),
+		Headers:  http.Header{"Accept": {"text/event-stream"}, "Accept-Encoding": {"gzip, br"}},
+	})
+	if err != nil {
+		t.Fatalf("DoPassthrough() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if headers := accepted(); len(headers) != 1 || headers[0] != "identity" {
+		t.Fatalf("Accept-Encoding = %v, want identity for passthrough streams", headers)
+	}
+}
@@ -0,0 +1,57 @@
+package llmclient
+
+import "net/http"
+
+// ExtraRequestParams are static headers and query parameters added to every
+// request a provider sends, such as gateway routing headers or access tokens.
+type ExtraRequestParams struct {
+	Headers map[string]string
+	Query   map[string]string
+	// Override replaces headers and query parameters the provider set itself.
+	// Without it the provider's own values win.
+	Override bool
+}
+
+// IsZero reports whether there is nothing to add.
+func (p ExtraRequestParams) IsZero() bool {
+	return len(p.Headers) == 0 && len(p.Query) == 0
+}
+
+// extraParamsTransport adds ExtraRequestParams below the retry loop, so every
+// attempt of every call, streaming or not, carries them. It modifies a clone,
+// so errors returned by the client still show the URL without the extra query.
+type extraParamsTransport struct {
+	base   http.RoundTripper
+	params ExtraRequestParams
+}
+
+func (t *extraParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	for name, value := range t.params.Headers {
+		if t.params.Override || out.Header.Get(name) == "" {
+			out.Header.Set(name, value)
+		}
+	}
+	if len(t.params.Query) > 0 {
+		query := out.URL.Query()
+		for name, value := range t.params.Query {
+			if t.params.Override || !query.Has(name) {
+				query.Set(name, value)
+			}
+		}
+		out.URL.RawQuery = query.Encode()
+	}
+	return t.base.RoundTrip(out)
+}
+
+// WithExtraRequestParams returns a shallow copy of httpClient whose transport
+// adds params to each request. The original client is left untouched.
+func WithExtraRequestParams(httpClient *http.Client, params ExtraRequestParams) *http.Client {
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client := *httpClient
+	client.Transport = &extraParamsTransport{base: base, params: params}
+	return &client
+}
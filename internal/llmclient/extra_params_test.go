@@ -0,0 +1,63 @@
+package llmclient
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gomodel/internal/testfixtures"
+)
+
+func TestWithExtraRequestParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		override   bool
+		wantAuth   string
+		wantRegion string
+	}{
+		{name: "provider values win", override: false, wantAuth: "Bearer provider", wantRegion: "us"},
+		{name: "override", override: true, wantAuth: "Bearer extra", wantRegion: "eu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+			httpClient := WithExtraRequestParams(&http.Client{}, ExtraRequestParams{
+				Headers:  map[string]string{"Authorization": "Bearer extra", "X-Route": "blue"},
+				Query:    map[string]string{"region": "eu", "tenant": "acme"},
+				Override: tt.override,
+			})
+			client := NewWithHTTPClient(httpClient, DefaultConfig("test", server.URL), func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer provider")
+			})
+
+			if err := client.Do(context.Background(), Request{Method: http.MethodGet, Endpoint: "/models?region=us"}, nil); err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+
+			got := server.Requests()[0]
+			if got.Header.Get("Authorization") != tt.wantAuth || got.Header.Get("X-Route") != "blue" {
+				t.Fatalf("headers = %v, want Authorization %q and X-Route blue", got.Header, tt.wantAuth)
+			}
+			if got.Query.Get("region") != tt.wantRegion || got.Query.Get("tenant") != "acme" {
+				t.Fatalf("query = %v, want region %q and tenant acme", got.Query, tt.wantRegion)
+			}
+		})
+	}
+}
+
+func TestWithExtraRequestParams_ErrorsOmitExtraQuery(t *testing.T) {
+	httpClient := WithExtraRequestParams(&http.Client{}, ExtraRequestParams{Query: map[string]string{"token": "secret"}})
+	cfg := DefaultConfig("test", "http://127.0.0.1:1")
+	cfg.Retry.MaxRetries = 0
+	client := NewWithHTTPClient(httpClient, cfg, nil)
+
+	err := client.Do(context.Background(), Request{Method: http.MethodGet, Endpoint: "/models"}, nil)
+	if err == nil {
+		t.Fatal("Do() succeeded against a closed port")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Fatalf("error %q leaks the extra query parameter", err)
+	}
+}
@@ -0,0 +1,287 @@
+package llmclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+)
+
+// Quirks are a provider's serialization rules: field renames, null and empty
+// value handling, value casing and the removal of fields it does not accept.
+// The client applies them to the JSON body of each request after marshaling
+// it. Raw bodies, such as passthrough requests, are sent as given.
+//
+// Each provider type declares its quirks in a Go table on its Registration,
+// and a provider's config can override them with config.QuirksConfig.
+type Quirks struct {
+	// Rules run in order on every request whose endpoint and model they match.
+	Rules []config.QuirkRule
+	// KnownFields lists the top-level fields the provider accepts.
+	KnownFields []string
+	// StripUnknown removes top-level fields outside KnownFields after the
+	// rules ran.
+	StripUnknown bool
+}
+
+// IsZero reports whether there are no rules and nothing to strip.
+func (q Quirks) IsZero() bool {
+	return len(q.Rules) == 0 && !q.StripUnknown
+}
+
+// WithOverrides returns the quirks with a provider's config overrides applied:
+// disabled rules are removed, the config's rules run after the remaining
+// ones, and its known fields and strip toggle extend or replace the table's.
+func (q Quirks) WithOverrides(o *config.QuirksConfig) (Quirks, error) {
+	if o == nil {
+		return q, nil
+	}
+	hasRule := func(rules []config.QuirkRule, name string) bool {
+		return slices.ContainsFunc(rules, func(rule config.QuirkRule) bool { return rule.Name == name })
+	}
+	for _, name := range o.Disable {
+		if !hasRule(q.Rules, name) {
+			return Quirks{}, fmt.Errorf("quirks: cannot disable unknown rule %q", name)
+		}
+	}
+
+	out := Quirks{
+		KnownFields:  append(slices.Clone(q.KnownFields), o.KnownFields...),
+		StripUnknown: q.StripUnknown,
+	}
+	for _, rule := range q.Rules {
+		if !slices.Contains(o.Disable, rule.Name) {
+			out.Rules = append(out.Rules, rule)
+		}
+	}
+	for _, rule := range o.Rules {
+		if hasRule(out.Rules, rule.Name) {
+			return Quirks{}, fmt.Errorf("quirks: rule %q is built in; disable it to replace it", rule.Name)
+		}
+		out.Rules = append(out.Rules, rule)
+	}
+	if o.StripUnknown != nil {
+		out.StripUnknown = *o.StripUnknown
+	}
+	if out.StripUnknown && len(out.KnownFields) == 0 {
+		return Quirks{}, fmt.Errorf("quirks: strip_unknown requires known_fields")
+	}
+	return out, nil
+}
+
+// Apply returns body, a request for endpoint, with the rules matching the
+// endpoint and the body's model applied and unknown fields stripped. Fields
+// nothing touches keep their exact bytes, and body itself is returned when
+// nothing changes. Changed objects are re-encoded with sorted keys.
+func (q Quirks) Apply(endpoint string, body []byte) ([]byte, error) {
+	if q.IsZero() || len(body) == 0 {
+		return body, nil
+	}
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+	model := strings.ToLower(gjson.GetBytes(body, "model").String())
+
+	value := json.RawMessage(body)
+	changed := false
+	apply := func(p string, edit func(json.RawMessage) (json.RawMessage, bool)) error {
+		next, c, err := update(value, strings.Split(p, "."), edit)
+		if err != nil {
+			return err
+		}
+		value, changed = next, changed || c
+		return nil
+	}
+	for _, rule := range q.Rules {
+		if !ruleMatches(rule, endpoint, model) {
+			continue
+		}
+		for _, from := range slices.Sorted(maps.Keys(rule.Rename)) {
+			var moved json.RawMessage
+			if err := apply(from, func(current json.RawMessage) (json.RawMessage, bool) {
+				moved = current
+				return nil, current != nil
+			}); err != nil {
+				return nil, err
+			}
+			if moved == nil {
+				continue
+			}
+			if err := apply(rule.Rename[from], func(json.RawMessage) (json.RawMessage, bool) {
+				return moved, true
+			}); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range rule.Drop {
+			if err := apply(p, func(current json.RawMessage) (json.RawMessage, bool) {
+				return nil, current != nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range rule.OmitEmpty {
+			if err := apply(p, func(current json.RawMessage) (json.RawMessage, bool) {
+				if current != nil && isEmptyJSON(current) {
+					return nil, true
+				}
+				return current, false
+			}); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range rule.Lowercase {
+			if err := apply(p, lowercaseJSON); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if q.StripUnknown {
+		stripped, c, err := stripUnknown(value, q.KnownFields)
+		if err != nil {
+			return nil, err
+		}
+		value, changed = stripped, changed || c
+	}
+	if !changed {
+		return body, nil
+	}
+	return value, nil
+}
+
+func ruleMatches(rule config.QuirkRule, endpoint, model string) bool {
+	if len(rule.Endpoints) > 0 && !slices.ContainsFunc(rule.Endpoints, func(suffix string) bool {
+		return strings.HasSuffix(endpoint, suffix)
+	}) {
+		return false
+	}
+	if len(rule.Models) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(rule.Models, func(pattern string) bool {
+		ok, _ := path.Match(pattern, model)
+		return ok
+	})
+}
+
+// update rewrites the value at path inside raw with edit, which receives the
+// current value, nil when it is missing, and returns the new value, nil to
+// remove it, and whether anything changed. Objects and arrays along the path
+// are decoded only as deep as the path goes and keep their bytes when nothing
+// changed. Missing objects are created for new values, and objects a removal
+// leaves empty are removed. Values of another kind along the path are left
+// alone.
+func update(raw json.RawMessage, path []string, edit func(json.RawMessage) (json.RawMessage, bool)) (json.RawMessage, bool, error) {
+	if path[0] == "#" {
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return raw, false, nil
+		}
+		changed := false
+		out := make([]json.RawMessage, 0, len(items))
+		for _, item := range items {
+			var next json.RawMessage
+			var c bool
+			if len(path) == 1 {
+				next, c = edit(item)
+			} else {
+				var err error
+				if next, c, err = update(item, path[1:], edit); err != nil {
+					return nil, false, err
+				}
+			}
+			changed = changed || c
+			if next != nil {
+				out = append(out, next)
+			}
+		}
+		if !changed {
+			return raw, false, nil
+		}
+		encoded, err := json.Marshal(out)
+		return encoded, true, err
+	}
+
+	obj := map[string]json.RawMessage{}
+	if raw != nil {
+		if json.Unmarshal(raw, &obj) != nil || obj == nil {
+			return raw, false, nil
+		}
+	}
+	var next json.RawMessage
+	var changed bool
+	if len(path) == 1 {
+		next, changed = edit(obj[path[0]])
+	} else {
+		var err error
+		if next, changed, err = update(obj[path[0]], path[1:], edit); err != nil {
+			return nil, false, err
+		}
+		if changed && bytes.Equal(next, []byte("{}")) {
+			next = nil
+		}
+	}
+	if !changed {
+		return raw, false, nil
+	}
+	if next == nil {
+		delete(obj, path[0])
+	} else {
+		obj[path[0]] = next
+	}
+	encoded, err := json.Marshal(obj)
+	return encoded, true, err
+}
+
+// isEmptyJSON reports whether v is null, "", [] or {}.
+func isEmptyJSON(v json.RawMessage) bool {
+	switch bytes.TrimSpace(v)[0] {
+	case 'n':
+		return true
+	case '"':
+		return len(bytes.TrimSpace(v)) == 2
+	case '[':
+		var items []json.RawMessage
+		return json.Unmarshal(v, &items) == nil && len(items) == 0
+	case '{':
+		var fields map[string]json.RawMessage
+		return json.Unmarshal(v, &fields) == nil && len(fields) == 0
+	}
+	return false
+}
+
+func lowercaseJSON(current json.RawMessage) (json.RawMessage, bool) {
+	var s string
+	if current == nil || json.Unmarshal(current, &s) != nil || strings.ToLower(s) == s {
+		return current, false
+	}
+	encoded, err := json.Marshal(strings.ToLower(s))
+	if err != nil {
+		return current, false
+	}
+	return encoded, true
+}
+
+func stripUnknown(raw json.RawMessage, known []string) (json.RawMessage, bool, error) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil || obj == nil {
+		return raw, false, nil
+	}
+	changed := false
+	for field := range obj {
+		if !slices.Contains(known, field) {
+			delete(obj, field)
+			changed = true
+		}
+	}
+	if !changed {
+		return raw, false, nil
+	}
+	encoded, err := json.Marshal(obj)
+	return encoded, true, err
+}
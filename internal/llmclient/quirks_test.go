@@ -0,0 +1,206 @@
+package llmclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/testfixtures"
+)
+
+func TestQuirksApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		quirks   Quirks
+		endpoint string
+		body     string
+		want     string
+	}{
+		{
+			name:     "rename nested field to top level",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "effort", Rename: map[string]string{"reasoning.effort": "reasoning_effort"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"gemini-2.5-flash","reasoning":{"effort":"low"},"messages":[]}`,
+			want:     `{"messages":[],"model":"gemini-2.5-flash","reasoning_effort":"low"}`,
+		},
+		{
+			name:     "rename keeps sibling fields",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "effort", Rename: map[string]string{"reasoning.effort": "reasoning_effort"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m","reasoning":{"effort":"high","summary":"auto"}}`,
+			want:     `{"model":"m","reasoning":{"summary":"auto"},"reasoning_effort":"high"}`,
+		},
+		{
+			name:     "omit null",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "stream_options", OmitEmpty: []string{"stream_options"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m","stream":true,"stream_options":null}`,
+			want:     `{"model":"m","stream":true}`,
+		},
+		{
+			name:     "omit empty keeps values",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "stream_options", OmitEmpty: []string{"stream_options"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m", "stream_options":{"include_usage":true}}`,
+			want:     `{"model":"m", "stream_options":{"include_usage":true}}`,
+		},
+		{
+			name:     "lowercase roles",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "roles", Lowercase: []string{"messages.#.role"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m","messages":[{"role":"System","content":"Be brief"},{"role":"user","content":"Hi"}]}`,
+			want:     `{"messages":[{"content":"Be brief","role":"system"},{"role":"user","content":"Hi"}],"model":"m"}`,
+		},
+		{
+			name:     "drop array element fields",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "names", Drop: []string{"messages.#.name"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m","messages":[{"role":"user","name":"ada","content":"Hi"}]}`,
+			want:     `{"messages":[{"content":"Hi","role":"user"}],"model":"m"}`,
+		},
+		{
+			name:     "strip unknown fields",
+			quirks:   Quirks{KnownFields: []string{"model", "messages"}, StripUnknown: true},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m","messages":[],"service_tier":"flex","user":"u"}`,
+			want:     `{"messages":[],"model":"m"}`,
+		},
+		{
+			name: "model pattern matches",
+			quirks: Quirks{Rules: []config.QuirkRule{{
+				Name:   "reasoning",
+				Models: []string{"o[0-9]*"},
+				Rename: map[string]string{"max_tokens": "max_completion_tokens"},
+				Drop:   []string{"temperature"},
+			}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"O3-mini","max_tokens":64,"temperature":0.2}`,
+			want:     `{"max_completion_tokens":64,"model":"O3-mini"}`,
+		},
+		{
+			name: "model pattern does not match",
+			quirks: Quirks{Rules: []config.QuirkRule{{
+				Name:   "reasoning",
+				Models: []string{"o[0-9]*"},
+				Drop:   []string{"temperature"},
+			}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"gpt-4o", "temperature":0.2}`,
+			want:     `{"model":"gpt-4o", "temperature":0.2}`,
+		},
+		{
+			name: "endpoint does not match",
+			quirks: Quirks{Rules: []config.QuirkRule{{
+				Name:      "chat only",
+				Endpoints: []string{"/chat/completions"},
+				Drop:      []string{"temperature"},
+			}}},
+			endpoint: "/responses?stream=true",
+			body:     `{"model":"m","temperature":0.2}`,
+			want:     `{"model":"m","temperature":0.2}`,
+		},
+		{
+			name: "endpoint matches with query",
+			quirks: Quirks{Rules: []config.QuirkRule{{
+				Name:      "chat only",
+				Endpoints: []string{"/chat/completions"},
+				Drop:      []string{"temperature"},
+			}}},
+			endpoint: "/openai/deployments/d/chat/completions?api-version=2024-10-21",
+			body:     `{"model":"m","temperature":0.2}`,
+			want:     `{"model":"m"}`,
+		},
+		{
+			name:     "missing paths",
+			quirks:   Quirks{Rules: []config.QuirkRule{{Name: "absent", Drop: []string{"a.b"}, OmitEmpty: []string{"c"}, Lowercase: []string{"messages.#.role"}}}},
+			endpoint: "/chat/completions",
+			body:     `{"model":"m", "messages":"not an array"}`,
+			want:     `{"model":"m", "messages":"not an array"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.quirks.Apply(tt.endpoint, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuirksWithOverrides(t *testing.T) {
+	builtIn := Quirks{
+		Rules: []config.QuirkRule{
+			{Name: "effort", Rename: map[string]string{"reasoning.effort": "reasoning_effort"}},
+			{Name: "roles", Lowercase: []string{"messages.#.role"}},
+		},
+		KnownFields: []string{"model"},
+	}
+	strip := true
+
+	got, err := builtIn.WithOverrides(&config.QuirksConfig{
+		Disable:      []string{"effort"},
+		Rules:        []config.QuirkRule{{Name: "stream_options", OmitEmpty: []string{"stream_options"}}},
+		StripUnknown: &strip,
+		KnownFields:  []string{"messages", "stream_options"},
+	})
+	if err != nil {
+		t.Fatalf("WithOverrides() error = %v", err)
+	}
+	body, err := got.Apply("/chat/completions", []byte(`{"model":"m","messages":[{"role":"USER"}],"reasoning":{"effort":"low"},"stream_options":null}`))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if want := `{"messages":[{"role":"user"}],"model":"m"}`; string(body) != want {
+		t.Fatalf("Apply() = %s, want %s", body, want)
+	}
+	if len(builtIn.Rules) != 2 || len(builtIn.KnownFields) != 1 {
+		t.Fatalf("WithOverrides() modified the built-in quirks: %+v", builtIn)
+	}
+
+	for name, overrides := range map[string]*config.QuirksConfig{
+		"unknown disabled rule":    {Disable: []string{"missing"}},
+		"rule shadows built in":    {Rules: []config.QuirkRule{{Name: "roles", Drop: []string{"user"}}}},
+		"strip without known list": {StripUnknown: &strip},
+	} {
+		t.Run(name, func(t *testing.T) {
+			q := Quirks{Rules: builtIn.Rules}
+			if _, err := q.WithOverrides(overrides); err == nil {
+				t.Fatal("WithOverrides() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestClientAppliesQuirks(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	cfg := DefaultConfig("test", server.URL)
+	cfg.Quirks = Quirks{Rules: []config.QuirkRule{{Name: "stream_options", OmitEmpty: []string{"stream_options"}}}}
+	client := NewWithHTTPClient(&http.Client{}, cfg, nil)
+
+	body := struct {
+		Model         string `json:"model"`
+		StreamOptions *struct {
+			IncludeUsage bool `json:"include_usage"`
+		} `json:"stream_options"`
+	}{Model: "m"}
+	if err := client.Do(context.Background(), Request{Method: http.MethodPost, Endpoint: "/chat/completions", Body: body}, nil); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got, want := string(server.Requests()[0].Body), `{"model":"m"}`; got != want {
+		t.Fatalf("sent body = %s, want %s", got, want)
+	}
+
+	raw := []byte(`{"model":"m","stream_options":null}`)
+	if err := client.Do(context.Background(), Request{Method: http.MethodPost, Endpoint: "/chat/completions", RawBody: raw}, nil); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := string(server.Requests()[1].Body); got != string(raw) {
+		t.Fatalf("sent raw body = %s, want it unchanged", got)
+	}
+}
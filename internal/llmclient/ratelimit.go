@@ -0,0 +1,137 @@
+package llmclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// rateLimitWindows maps each limit window to its header names. OpenAI and
+// Groq report "x-ratelimit-<field>-<window>"; Anthropic reports
+// "anthropic-ratelimit-<window>-<field>".
+var rateLimitWindows = []struct {
+	openAI    string
+	anthropic string
+	window    func(*core.RateLimits) **core.RateLimitWindow
+}{
+	{openAI: "requests", anthropic: "requests", window: func(r *core.RateLimits) **core.RateLimitWindow { return &r.Requests }},
+	{openAI: "tokens", anthropic: "tokens", window: func(r *core.RateLimits) **core.RateLimitWindow { return &r.Tokens }},
+	{anthropic: "input-tokens", window: func(r *core.RateLimits) **core.RateLimitWindow { return &r.InputTokens }},
+	{anthropic: "output-tokens", window: func(r *core.RateLimits) **core.RateLimitWindow { return &r.OutputTokens }},
+}
+
+// ParseRateLimitHeaders extracts the account limits a provider reported in
+// its response headers. now stamps the observation and anchors relative
+// reset values such as "6m0s". The result is empty when the response carried
+// no rate-limit headers.
+func ParseRateLimitHeaders(header http.Header, now time.Time) core.RateLimits {
+	limits := core.RateLimits{ObservedAt: now.UTC()}
+	if len(header) == 0 {
+		return limits
+	}
+	for _, w := range rateLimitWindows {
+		var window *core.RateLimitWindow
+		if w.openAI != "" {
+			window = parseRateLimitWindow(
+				header.Get("x-ratelimit-limit-"+w.openAI),
+				header.Get("x-ratelimit-remaining-"+w.openAI),
+				header.Get("x-ratelimit-reset-"+w.openAI),
+				now,
+			)
+		}
+		if window == nil {
+			window = parseRateLimitWindow(
+				header.Get("anthropic-ratelimit-"+w.anthropic+"-limit"),
+				header.Get("anthropic-ratelimit-"+w.anthropic+"-remaining"),
+				header.Get("anthropic-ratelimit-"+w.anthropic+"-reset"),
+				now,
+			)
+		}
+		*w.window(&limits) = window
+	}
+	return limits
+}
+
+func parseRateLimitWindow(limit, remaining, reset string, now time.Time) *core.RateLimitWindow {
+	window := &core.RateLimitWindow{
+		Limit:     parseRateLimitCount(limit),
+		Remaining: parseRateLimitCount(remaining),
+		ResetAt:   parseRateLimitReset(reset, now),
+	}
+	if window.Limit == nil && window.Remaining == nil && window.ResetAt == nil {
+		return nil
+	}
+	return window
+}
+
+func parseRateLimitCount(value string) *int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || count < 0 {
+		return nil
+	}
+	return &count
+}
+
+// parseRateLimitReset accepts an RFC 3339 timestamp (Anthropic), a Go-style
+// duration such as "1m30.5s" or "20ms" (OpenAI, Groq), or plain seconds.
+func parseRateLimitReset(value string, now time.Time) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		at = at.UTC()
+		return &at
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		at := now.Add(d).UTC()
+		return &at
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		at := now.Add(time.Duration(seconds * float64(time.Second))).UTC()
+		return &at
+	}
+	return nil
+}
+
+// observeRateLimits reports the limits carried by an upstream response to
+// the OnRateLimits hook.
+func (c *Client) observeRateLimits(ctx context.Context, header http.Header) {
+	if c.config.Hooks.OnRateLimits == nil {
+		return
+	}
+	limits := ParseRateLimitHeaders(header, time.Now())
+	if limits.IsEmpty() {
+		return
+	}
+	c.config.Hooks.OnRateLimits(ctx, limits)
+}
+
+// FetchRateLimits queries endpoint once, without retries, and returns the
+// account limits reported in the response headers. The observation also
+// reaches the OnRateLimits hook. Providers use it to refresh limits on
+// demand with a cheap request such as listing models.
+func (c *Client) FetchRateLimits(ctx context.Context, endpoint string) (core.RateLimits, error) {
+	resp, err := c.doHTTPRequest(ctx, Request{Method: http.MethodGet, Endpoint: endpoint})
+	if err != nil {
+		return core.RateLimits{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return core.RateLimits{}, core.ParseProviderError(c.config.ProviderName, resp.StatusCode, body, nil)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return ParseRateLimitHeaders(resp.Header, time.Now()), nil
+}
@@ -0,0 +1,116 @@
+package llmclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func TestParseRateLimitHeaders_OpenAI(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "500")
+	header.Set("x-ratelimit-remaining-requests", "499")
+	header.Set("x-ratelimit-reset-requests", "1m30s")
+	header.Set("x-ratelimit-limit-tokens", "30000")
+	header.Set("x-ratelimit-remaining-tokens", "29000")
+	header.Set("x-ratelimit-reset-tokens", "2.5")
+
+	limits := ParseRateLimitHeaders(header, now)
+
+	if limits.Requests == nil || *limits.Requests.Limit != 500 || *limits.Requests.Remaining != 499 {
+		t.Fatalf("unexpected request window: %+v", limits.Requests)
+	}
+	if want := now.Add(90 * time.Second); !limits.Requests.ResetAt.Equal(want) {
+		t.Fatalf("requests reset = %v, want %v", limits.Requests.ResetAt, want)
+	}
+	if limits.Tokens == nil || *limits.Tokens.Remaining != 29000 {
+		t.Fatalf("unexpected token window: %+v", limits.Tokens)
+	}
+	if want := now.Add(2500 * time.Millisecond); !limits.Tokens.ResetAt.Equal(want) {
+		t.Fatalf("tokens reset = %v, want %v", limits.Tokens.ResetAt, want)
+	}
+	if limits.InputTokens != nil || limits.OutputTokens != nil {
+		t.Fatalf("expected no input/output windows, got %+v", limits)
+	}
+}
+
+func TestParseRateLimitHeaders_Anthropic(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	header := http.Header{}
+	header.Set("anthropic-ratelimit-requests-limit", "50")
+	header.Set("anthropic-ratelimit-requests-remaining", "49")
+	header.Set("anthropic-ratelimit-requests-reset", "2026-01-02T03:05:00Z")
+	header.Set("anthropic-ratelimit-input-tokens-remaining", "40000")
+	header.Set("anthropic-ratelimit-output-tokens-limit", "8000")
+
+	limits := ParseRateLimitHeaders(header, now)
+
+	if limits.Requests == nil || *limits.Requests.Remaining != 49 {
+		t.Fatalf("unexpected request window: %+v", limits.Requests)
+	}
+	if want := time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC); !limits.Requests.ResetAt.Equal(want) {
+		t.Fatalf("requests reset = %v, want %v", limits.Requests.ResetAt, want)
+	}
+	if limits.InputTokens == nil || *limits.InputTokens.Remaining != 40000 || limits.InputTokens.Limit != nil {
+		t.Fatalf("unexpected input token window: %+v", limits.InputTokens)
+	}
+	if limits.OutputTokens == nil || *limits.OutputTokens.Limit != 8000 {
+		t.Fatalf("unexpected output token window: %+v", limits.OutputTokens)
+	}
+	if limits.Tokens != nil {
+		t.Fatalf("expected no combined token window, got %+v", limits.Tokens)
+	}
+}
+
+func TestParseRateLimitHeaders_IgnoresMissingAndMalformedValues(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "lots")
+	header.Set("x-ratelimit-reset-requests", "soon")
+
+	if limits := ParseRateLimitHeaders(header, time.Now()); !limits.IsEmpty() {
+		t.Fatalf("expected empty limits, got %+v", limits)
+	}
+	if limits := ParseRateLimitHeaders(nil, time.Now()); !limits.IsEmpty() {
+		t.Fatalf("expected empty limits for nil header, got %+v", limits)
+	}
+}
+
+func TestClient_ReportsRateLimitsToHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-remaining-requests", "9")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad key"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var observed []core.RateLimits
+	cfg := DefaultConfig("test", server.URL)
+	cfg.Retry.MaxRetries = 0
+	cfg.Hooks.OnRateLimits = func(_ context.Context, limits core.RateLimits) {
+		observed = append(observed, limits)
+	}
+	client := New(cfg, nil)
+
+	limits, err := client.FetchRateLimits(context.Background(), "/models")
+	if err != nil {
+		t.Fatalf("FetchRateLimits() error = %v", err)
+	}
+	if limits.Requests == nil || *limits.Requests.Remaining != 9 {
+		t.Fatalf("unexpected fetched limits: %+v", limits)
+	}
+	if _, err := client.FetchRateLimits(context.Background(), "/fail"); err == nil {
+		t.Fatal("expected error for non-200 response")
+	}
+	if len(observed) != 2 {
+		t.Fatalf("expected hook to observe both responses, got %d", len(observed))
+	}
+}
@@ -0,0 +1,84 @@
+package llmclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gomodel/config"
+)
+
+// SignRequestBody returns the hex-encoded HMAC-SHA256 of body keyed by secret.
+// When timestamp is non-empty the signed payload is "<timestamp>.<body>", so
+// receivers can reject replayed requests.
+func SignRequestBody(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	if timestamp != "" {
+		mac.Write([]byte(timestamp))
+		mac.Write([]byte{'.'})
+	}
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signingTransport signs the final serialized body of every outgoing request.
+// It sits below the retry loop, so each attempt is signed with a fresh
+// timestamp, and it works for both buffered and streaming responses. Request
+// bodies supplied as a one-shot reader are buffered to compute the signature.
+type signingTransport struct {
+	base    http.RoundTripper
+	signing config.RequestSigningConfig
+	now     func() time.Time
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+		signed.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		signed.ContentLength = int64(len(body))
+	}
+
+	timestamp := ""
+	if t.signing.IncludeTimestamp {
+		timestamp = strconv.FormatInt(t.now().Unix(), 10)
+		signed.Header.Set(t.signing.TimestampHeader, timestamp)
+	}
+	signed.Header.Set(t.signing.Header, SignRequestBody(t.signing.Secret, timestamp, body))
+	return t.base.RoundTrip(signed)
+}
+
+// withRequestSigning returns a shallow copy of httpClient whose transport
+// signs each request. The original client is left untouched.
+func withRequestSigning(httpClient *http.Client, signing config.RequestSigningConfig) *http.Client {
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if signing.Header == "" {
+		signing.Header = config.DefaultRequestSigningHeader
+	}
+	if signing.TimestampHeader == "" {
+		signing.TimestampHeader = config.DefaultRequestSigningTimestampHeader
+	}
+	signedClient := *httpClient
+	signedClient.Transport = &signingTransport{base: base, signing: signing, now: time.Now}
+	return &signedClient
+}
@@ -0,0 +1,171 @@
+package llmclient
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	goconfig "gomodel/config"
+)
+
+// referenceSignature mirrors how a receiving platform verifies signatures.
+func referenceSignature(secret, timestamp string, body []byte) string {
+	payload := body
+	if timestamp != "" {
+		payload = append([]byte(timestamp+"."), body...)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type signedRequest struct {
+	body      string
+	signature string
+	timestamp string
+}
+
+func newSigningServer(t *testing.T, handler func(attempt int, w http.ResponseWriter)) (*httptest.Server, func() []signedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []signedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, signedRequest{
+			body:      string(body),
+			signature: r.Header.Get("X-Signature"),
+			timestamp: r.Header.Get("X-Signature-Timestamp"),
+		})
+		attempt := len(requests)
+		mu.Unlock()
+		handler(attempt, w)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []signedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]signedRequest(nil), requests...)
+	}
+}
+
+func TestSignRequestBody_MatchesReference(t *testing.T) {
+	body := []byte(`{"model":"m","messages":[]}`)
+	if got, want := SignRequestBody("s3cret", "", body), referenceSignature("s3cret", "", body); got != want {
+		t.Fatalf("SignRequestBody() = %s, want %s", got, want)
+	}
+	if got, want := SignRequestBody("s3cret", "1700000000", body), referenceSignature("s3cret", "1700000000", body); got != want {
+		t.Fatalf("SignRequestBody() with timestamp = %s, want %s", got, want)
+	}
+}
+
+func TestClient_Signing_RetriesAreResigned(t *testing.T) {
+	server, requests := newSigningServer(t, func(attempt int, w http.ResponseWriter) {
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+
+	cfg := DefaultConfig("test", server.URL)
+	cfg.Retry.MaxRetries = 2
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.JitterFactor = 0
+	cfg.Signing = &goconfig.RequestSigningConfig{
+		Type:             goconfig.RequestSigningHMACSHA256,
+		Secret:           "s3cret",
+		IncludeTimestamp: true,
+	}
+	client := New(cfg, nil)
+
+	clock := time.Unix(1700000000, 0)
+	client.httpClient.Transport.(*signingTransport).now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	if _, err := client.DoRaw(context.Background(), Request{
+		Method:   http.MethodPost,
+		Endpoint: "/chat",
+		Body:     map[string]string{"model": "m"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(got))
+	}
+	for i, req := range got {
+		if want := strconv.Itoa(1700000001 + i); req.timestamp != want {
+			t.Fatalf("attempt %d timestamp = %q, want %q", i+1, req.timestamp, want)
+		}
+		if want := referenceSignature("s3cret", req.timestamp, []byte(req.body)); req.signature != want {
+			t.Fatalf("attempt %d signature = %q, want %q", i+1, req.signature, want)
+		}
+	}
+	if got[0].signature == got[1].signature {
+		t.Fatal("retry reused the first attempt's signature")
+	}
+}
+
+func TestClient_Signing_StreamAndRawBodyReader(t *testing.T) {
+	server, requests := newSigningServer(t, func(_ int, w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	cfg := DefaultConfig("test", server.URL)
+	cfg.Signing = &goconfig.RequestSigningConfig{
+		Type:   goconfig.RequestSigningHMACSHA256,
+		Secret: "s3cret",
+	}
+	client := New(cfg, nil)
+
+	stream, err := client.DoStream(context.Background(), Request{
+		Method:   http.MethodPost,
+		Endpoint: "/chat",
+		Body:     map[string]bool{"stream": true},
+	})
+	if err != nil {
+		t.Fatalf("DoStream() error: %v", err)
+	}
+	_ = stream.Close()
+
+	resp, err := client.DoPassthrough(context.Background(), Request{
+		Method:        http.MethodPost,
+		Endpoint:      "/passthrough",
+		RawBodyReader: strings.NewReader(`{"raw":true}`),
+	})
+	if err != nil {
+		t.Fatalf("DoPassthrough() error: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("requests = %d, want 2", len(got))
+	}
+	if got[1].body != `{"raw":true}` {
+		t.Fatalf("passthrough body = %q, want the raw reader contents", got[1].body)
+	}
+	for i, req := range got {
+		if req.timestamp != "" {
+			t.Fatalf("request %d timestamp = %q, want none", i+1, req.timestamp)
+		}
+		if want := referenceSignature("s3cret", "", []byte(req.body)); req.signature != want {
+			t.Fatalf("request %d signature = %q, want %q", i+1, req.signature, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package llmclient
+
+import (
+	"net/http"
+
+	"gomodel/internal/core"
+)
+
+// upstreamHeadersTransport reports every provider response's headers to the
+// collector in the request context, so the server can pass the allowed ones
+// through to the client. It sits below the retry loop, so the last attempt
+// wins, and sees streaming responses before their body is read.
+type upstreamHeadersTransport struct {
+	base  http.RoundTripper
+	allow []string
+}
+
+func (t *upstreamHeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp != nil {
+		core.RecordUpstreamResponseHeaders(req.Context(), resp.Header, t.allow)
+	}
+	return resp, err
+}
+
+// WithUpstreamResponseHeaders returns a shallow copy of httpClient whose
+// transport reports response headers for passthrough. allow lists the names
+// this provider passes through on top of the global allow-list. The original
+// client is left untouched.
+func WithUpstreamResponseHeaders(httpClient *http.Client, allow []string) *http.Client {
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client := *httpClient
+	client.Transport = &upstreamHeadersTransport{base: base, allow: allow}
+	return &client
+}
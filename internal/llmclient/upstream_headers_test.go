@@ -0,0 +1,34 @@
+package llmclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gomodel/internal/core"
+	"gomodel/internal/testfixtures"
+)
+
+func TestWithUpstreamResponseHeaders_RecordsProviderResponseHeaders(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`, ResponseHeaders: map[string]string{
+		"X-Ratelimit-Remaining-Tokens": "9000",
+		"Anthropic-Ratelimit-Requests": "10",
+		"Set-Cookie":                   "session=secret",
+	}})
+	httpClient := WithUpstreamResponseHeaders(&http.Client{}, []string{"anthropic-ratelimit-*", "set-cookie"})
+	client := NewWithHTTPClient(httpClient, DefaultConfig("test", server.URL), nil)
+
+	headers := core.NewUpstreamResponseHeaders(core.UpstreamHeaderPolicy{Allow: []string{"x-ratelimit-remaining-tokens"}})
+	ctx := core.WithUpstreamResponseHeaders(context.Background(), headers)
+	if err := client.Do(ctx, Request{Method: http.MethodGet, Endpoint: "/models"}, nil); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	got := headers.Header()
+	if got.Get("X-Ratelimit-Remaining-Tokens") != "9000" || got.Get("Anthropic-Ratelimit-Requests") != "10" {
+		t.Fatalf("recorded headers = %v, want the global and provider allowed headers", got)
+	}
+	if got.Get("Set-Cookie") != "" {
+		t.Fatalf("recorded Set-Cookie = %q, want it denied", got.Get("Set-Cookie"))
+	}
+}
@@ -0,0 +1,297 @@
+// Package logging configures the process-wide slog logger and hands out named
+// component loggers whose levels can be tuned independently at runtime.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lmittmann/tint"
+)
+
+// Known component names. Each maps to a named child logger that carries a
+// "component" attribute and can have its own level override.
+const (
+	ComponentServer    = "server"
+	ComponentProviders = "providers"
+	ComponentRegistry  = "registry"
+	ComponentAuditLog  = "auditlog"
+	ComponentUsage     = "usage"
+)
+
+// Components lists the component names accepted for level overrides.
+var Components = []string{
+	ComponentServer,
+	ComponentProviders,
+	ComponentRegistry,
+	ComponentAuditLog,
+	ComponentUsage,
+}
+
+// Options configures the process logger.
+type Options struct {
+	// Level is the global minimum level.
+	Level slog.Level
+	// Format selects "json" or "text". Empty picks text on a TTY and JSON otherwise.
+	Format string
+	// ComponentLevels overrides the global level for named components.
+	ComponentLevels map[string]slog.Level
+	// DebugSampleRate keeps one in N debug records when greater than 1.
+	DebugSampleRate int
+}
+
+// LevelsSnapshot describes the currently effective levels.
+type LevelsSnapshot struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+type manager struct {
+	// base is nil until Configure runs; until then component loggers forward
+	// to whatever slog.Default() currently is.
+	base       atomic.Pointer[slog.Handler]
+	level      slog.LevelVar
+	mu         sync.RWMutex
+	components map[string]slog.Level
+	sampleRate atomic.Int64
+	sampleSeq  atomic.Uint64
+}
+
+var global = &manager{components: make(map[string]slog.Level)}
+
+// Configure installs the process-wide default logger writing to w.
+func Configure(w io.Writer, isTTY bool, opts Options) {
+	for name := range opts.ComponentLevels {
+		if !slices.Contains(Components, name) {
+			slog.Warn("unknown logging component override ignored", "component", name)
+		}
+	}
+
+	// The base handler accepts everything; level filtering happens in
+	// componentHandler so levels stay adjustable at runtime.
+	base := newBaseHandler(w, isTTY, opts.Format)
+	global.level.Set(opts.Level)
+	global.mu.Lock()
+	global.components = make(map[string]slog.Level, len(opts.ComponentLevels))
+	for name, level := range opts.ComponentLevels {
+		if slices.Contains(Components, name) {
+			global.components[name] = level
+		}
+	}
+	global.mu.Unlock()
+	global.sampleRate.Store(int64(max(opts.DebugSampleRate, 0)))
+	global.sampleSeq.Store(0)
+	global.base.Store(&base)
+
+	slog.SetDefault(slog.New(&componentHandler{}))
+}
+
+func newBaseHandler(w io.Writer, isTTY bool, format string) slog.Handler {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if (isTTY && format != "json") || format == "text" {
+		return tint.NewHandler(w, &tint.Options{
+			Level:      slog.LevelDebug,
+			TimeFormat: time.Kitchen,
+			NoColor:    !isTTY,
+		})
+	}
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+}
+
+// Component returns a logger for the named component. The logger resolves the
+// configured output and levels on every record, so it is safe to create at
+// package initialization before Configure runs.
+func Component(name string) *slog.Logger {
+	return slog.New(&componentHandler{
+		component: name,
+		ops:       []handlerOp{{attrs: []slog.Attr{slog.String("component", name)}}},
+	})
+}
+
+// ParseLevel parses a case-insensitive level name.
+func ParseLevel(raw string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "info", "inf":
+		return slog.LevelInfo, nil
+	case "debug", "dbg":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error", "err":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q: supported values are debug, info, warn, error", raw)
+	}
+}
+
+// ParseComponentLevels parses "component=level" pairs separated by commas,
+// e.g. "registry=warn,auditlog=error".
+func ParseComponentLevels(raw string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for pair := range strings.SplitSeq(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, rawLevel, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid component level %q: expected component=level", pair)
+		}
+		level, err := ParseLevel(rawLevel)
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %w", strings.TrimSpace(name), err)
+		}
+		levels[strings.ToLower(strings.TrimSpace(name))] = level
+	}
+	return levels, nil
+}
+
+// SetLevel changes the global level, or a component's level when component is
+// non-empty.
+func SetLevel(component string, level slog.Level) error {
+	component = strings.ToLower(strings.TrimSpace(component))
+	if component == "" {
+		global.level.Set(level)
+		return nil
+	}
+	if !slices.Contains(Components, component) {
+		return fmt.Errorf("unknown logging component %q", component)
+	}
+	global.mu.Lock()
+	global.components[component] = level
+	global.mu.Unlock()
+	return nil
+}
+
+// Levels reports the global level and every component override.
+func Levels() LevelsSnapshot {
+	global.mu.RLock()
+	defer global.mu.RUnlock()
+
+	names := make([]string, 0, len(global.components))
+	for name := range global.components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	components := make(map[string]string, len(names))
+	for _, name := range names {
+		components[name] = levelName(global.components[name])
+	}
+	return LevelsSnapshot{
+		Level:      levelName(global.level.Level()),
+		Components: components,
+	}
+}
+
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+func (m *manager) minLevel(component string) slog.Level {
+	if component != "" {
+		m.mu.RLock()
+		level, ok := m.components[component]
+		m.mu.RUnlock()
+		if ok {
+			return level
+		}
+	}
+	return m.level.Level()
+}
+
+// sampled reports whether a debug record should be dropped by sampling.
+func (m *manager) sampled(level slog.Level) bool {
+	if level >= slog.LevelInfo {
+		return false
+	}
+	rate := m.sampleRate.Load()
+	if rate <= 1 {
+		return false
+	}
+	return (m.sampleSeq.Add(1)-1)%uint64(rate) != 0
+}
+
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+type derivedHandler struct {
+	base    *slog.Handler
+	handler slog.Handler
+}
+
+// componentHandler filters by the effective component level and forwards to
+// the current base handler with any accumulated attrs and groups applied.
+type componentHandler struct {
+	component string
+	ops       []handlerOp
+	derived   atomic.Pointer[derivedHandler]
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if global.base.Load() == nil {
+		return slog.Default().Handler().Enabled(ctx, level)
+	}
+	return level >= global.minLevel(h.component)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	if global.sampled(record.Level) {
+		return nil
+	}
+	return h.resolve().Handle(ctx, record)
+}
+
+func (h *componentHandler) resolve() slog.Handler {
+	base := global.base.Load()
+	if base == nil {
+		return h.apply(slog.Default().Handler())
+	}
+	if cached := h.derived.Load(); cached != nil && cached.base == base {
+		return cached.handler
+	}
+	handler := h.apply(*base)
+	h.derived.Store(&derivedHandler{base: base, handler: handler})
+	return handler
+}
+
+func (h *componentHandler) apply(handler slog.Handler) slog.Handler {
+	for _, op := range h.ops {
+		if op.group != "" {
+			handler = handler.WithGroup(op.group)
+			continue
+		}
+		handler = handler.WithAttrs(op.attrs)
+	}
+	return handler
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(handlerOp{attrs: slices.Clone(attrs)})
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(handlerOp{group: name})
+}
+
+func (h *componentHandler) with(op handlerOp) *componentHandler {
+	ops := make([]handlerOp, 0, len(h.ops)+1)
+	ops = append(ops, h.ops...)
+	ops = append(ops, op)
+	return &componentHandler{component: h.component, ops: ops}
+}
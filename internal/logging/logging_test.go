@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func configureForTest(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+
+	previous := slog.Default()
+	t.Cleanup(func() {
+		global.base.Store(nil)
+		slog.SetDefault(previous)
+	})
+
+	var buf bytes.Buffer
+	Configure(&buf, false, opts)
+	return &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  slog.Level
+	}{
+		{input: "", want: slog.LevelInfo},
+		{input: "info", want: slog.LevelInfo},
+		{input: "inf", want: slog.LevelInfo},
+		{input: "debug", want: slog.LevelDebug},
+		{input: "dbg", want: slog.LevelDebug},
+		{input: "warn", want: slog.LevelWarn},
+		{input: "warning", want: slog.LevelWarn},
+		{input: "error", want: slog.LevelError},
+		{input: "err", want: slog.LevelError},
+		{input: "  WARN  ", want: slog.LevelWarn},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.input)
+		if err != nil {
+			t.Fatalf("ParseLevel(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Fatal("ParseLevel(trace) should fail")
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	levels, err := ParseComponentLevels(" Registry=warn, auditlog=error ,")
+	if err != nil {
+		t.Fatalf("ParseComponentLevels() error = %v", err)
+	}
+	if len(levels) != 2 || levels[ComponentRegistry] != slog.LevelWarn || levels[ComponentAuditLog] != slog.LevelError {
+		t.Fatalf("ParseComponentLevels() = %v", levels)
+	}
+}
+
+func TestConfigureFiltersByGlobalLevel(t *testing.T) {
+	buf := configureForTest(t, Options{Level: slog.LevelWarn, Format: "json"})
+
+	slog.Info("dropped")
+	slog.Warn("kept")
+
+	records := decodeLines(t, buf)
+	if len(records) != 1 || records[0]["msg"] != "kept" {
+		t.Fatalf("records = %v, want only the warn line", records)
+	}
+}
+
+func TestComponentLoggerAddsAttributeAndHonorsOverride(t *testing.T) {
+	buf := configureForTest(t, Options{
+		Level:           slog.LevelInfo,
+		Format:          "json",
+		ComponentLevels: map[string]slog.Level{ComponentRegistry: slog.LevelError},
+	})
+
+	registry := Component(ComponentRegistry)
+	server := Component(ComponentServer).With("request_id", "req-1")
+
+	registry.Warn("registry noise")
+	registry.Error("registry failure")
+	server.Info("request handled", "provider", "openai", "model", "gpt-4o")
+
+	records := decodeLines(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("records = %v, want 2 lines", records)
+	}
+	if records[0]["msg"] != "registry failure" || records[0]["component"] != ComponentRegistry {
+		t.Fatalf("registry record = %v", records[0])
+	}
+	for _, key := range []string{"request_id", "provider", "model"} {
+		if _, ok := records[1][key]; !ok {
+			t.Fatalf("server record missing %q: %v", key, records[1])
+		}
+	}
+	if records[1]["component"] != ComponentServer {
+		t.Fatalf("server record component = %v", records[1]["component"])
+	}
+}
+
+func TestSetLevelAppliesAtRuntime(t *testing.T) {
+	buf := configureForTest(t, Options{Level: slog.LevelInfo, Format: "json"})
+	logger := Component(ComponentUsage)
+
+	logger.Debug("before")
+	if err := SetLevel("", slog.LevelDebug); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	logger.Debug("after")
+	if err := SetLevel(ComponentUsage, slog.LevelError); err != nil {
+		t.Fatalf("SetLevel(usage) error = %v", err)
+	}
+	logger.Info("suppressed by override")
+
+	records := decodeLines(t, buf)
+	if len(records) != 1 || records[0]["msg"] != "after" {
+		t.Fatalf("records = %v, want only the post-change debug line", records)
+	}
+
+	snapshot := Levels()
+	if snapshot.Level != "debug" || snapshot.Components[ComponentUsage] != "error" {
+		t.Fatalf("Levels() = %+v", snapshot)
+	}
+	if err := SetLevel("unknown", slog.LevelDebug); err == nil {
+		t.Fatal("SetLevel(unknown) should fail")
+	}
+}
+
+func TestDebugSampling(t *testing.T) {
+	buf := configureForTest(t, Options{Level: slog.LevelDebug, Format: "json", DebugSampleRate: 3})
+
+	for range 9 {
+		slog.Debug("sampled")
+	}
+	slog.Info("never sampled")
+
+	records := decodeLines(t, buf)
+	if len(records) != 4 {
+		t.Fatalf("record count = %d, want 3 sampled debug lines plus 1 info line", len(records))
+	}
+}
@@ -0,0 +1,196 @@
+// Package maintenance holds the gateway's maintenance mode: while it is
+// active, model endpoints answer 503 with a Retry-After header instead of
+// calling providers, so operators can rotate provider keys without stopping
+// the process.
+//
+// Maintenance mode is always available. Its initial state comes from config
+// and it is switched at runtime through the admin API. An activation with a
+// duration ends by itself once the duration has passed. Requests admitted
+// before activation keep running; Drain waits for them to finish.
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// ErrorCode is the error code of responses rejected during maintenance.
+const ErrorCode = "maintenance_mode"
+
+// DefaultMessage is the error message when the operator did not give one.
+const DefaultMessage = "The gateway is in maintenance; retry shortly."
+
+// DefaultRetryAfter is the Retry-After sent for activations without a
+// duration when the config does not set one.
+const DefaultRetryAfter = 60 * time.Second
+
+// State describes maintenance mode at one point in time.
+type State struct {
+	Active  bool       `json:"active"`
+	Message string     `json:"message,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+	// Until is when an activation with a duration ends by itself.
+	Until *time.Time `json:"until,omitempty"`
+	// InFlight is the number of model requests still running.
+	InFlight int `json:"in_flight"`
+}
+
+// Mode is the maintenance switch and the in-flight model request count.
+type Mode struct {
+	mu         sync.Mutex
+	active     bool
+	message    string
+	since      time.Time
+	until      time.Time
+	retryAfter time.Duration
+	inFlight   int
+	idle       chan struct{} // closed whenever inFlight drops to zero
+
+	now func() time.Time
+}
+
+// New returns a Mode starting in the state cfg describes.
+func New(cfg config.MaintenanceConfig) *Mode {
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	m := &Mode{retryAfter: retryAfter, idle: closedChannel(), now: time.Now}
+	if cfg.Enabled {
+		m.Enable(cfg.Message, 0)
+	}
+	return m
+}
+
+// Enable turns maintenance mode on. A positive duration ends it by itself
+// once the duration has passed; zero keeps it on until Disable.
+func (m *Mode) Enable(message string, duration time.Duration) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.active = true
+	m.message = strings.TrimSpace(message)
+	m.since = now
+	m.until = time.Time{}
+	if duration > 0 {
+		m.until = now.Add(duration)
+	}
+	return m.stateLocked(now)
+}
+
+// Disable turns maintenance mode off.
+func (m *Mode) Disable() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active = false
+	m.message = ""
+	m.since = time.Time{}
+	m.until = time.Time{}
+	return m.stateLocked(m.now())
+}
+
+// State returns the current state.
+func (m *Mode) State() State {
+	if m == nil {
+		return State{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stateLocked(m.now())
+}
+
+func (m *Mode) stateLocked(now time.Time) State {
+	state := State{InFlight: m.inFlight}
+	if !m.activeLocked(now) {
+		return state
+	}
+	state.Active = true
+	state.Message = m.message
+	since := m.since.UTC()
+	state.Since = &since
+	if !m.until.IsZero() {
+		until := m.until.UTC()
+		state.Until = &until
+	}
+	return state
+}
+
+// activeLocked reports whether maintenance is on at now, switching it off
+// when its duration has passed.
+func (m *Mode) activeLocked(now time.Time) bool {
+	if m.active && !m.until.IsZero() && !now.Before(m.until) {
+		m.active = false
+		m.message = ""
+		m.since = time.Time{}
+		m.until = time.Time{}
+	}
+	return m.active
+}
+
+// Admit lets a model request in. While maintenance is on it returns the error
+// to answer with and how long the client should wait before retrying.
+// Otherwise the request counts as in flight until done is called.
+func (m *Mode) Admit() (done func(), rejection *core.GatewayError, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if m.activeLocked(now) {
+		retryAfter = m.retryAfter
+		if !m.until.IsZero() {
+			retryAfter = m.until.Sub(now)
+		}
+		message := m.message
+		if message == "" {
+			message = DefaultMessage
+		}
+		rejection = (&core.GatewayError{
+			Type:       core.ErrorTypeOverloaded,
+			Message:    message,
+			StatusCode: http.StatusServiceUnavailable,
+		}).WithCode(ErrorCode)
+		return nil, rejection, retryAfter
+	}
+
+	if m.inFlight == 0 {
+		m.idle = make(chan struct{})
+	}
+	m.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(m.release)
+	}, nil, 0
+}
+
+func (m *Mode) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if m.inFlight == 0 {
+		close(m.idle)
+	}
+}
+
+// Drain waits until no admitted model request is left running, or ctx ends.
+func (m *Mode) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	idle := m.idle
+	m.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func closedChannel() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
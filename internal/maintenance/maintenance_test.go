@@ -0,0 +1,76 @@
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+func newTestMode(cfg config.MaintenanceConfig) (*Mode, *time.Time) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	m := New(cfg)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestNew_StartsFromConfig(t *testing.T) {
+	m, _ := newTestMode(config.MaintenanceConfig{Enabled: true, Message: "key rotation"})
+	_, rejection, retryAfter := m.Admit()
+	if rejection == nil || rejection.Message != "key rotation" || rejection.HTTPStatusCode() != http.StatusServiceUnavailable {
+		t.Fatalf("Admit() rejection = %+v, want a 503 with the configured message", rejection)
+	}
+	if retryAfter != DefaultRetryAfter {
+		t.Fatalf("retryAfter = %s, want %s", retryAfter, DefaultRetryAfter)
+	}
+}
+
+func TestEnable_ExpiresAfterDuration(t *testing.T) {
+	m, now := newTestMode(config.MaintenanceConfig{RetryAfter: time.Minute})
+	m.Enable("", 90*time.Second)
+
+	*now = now.Add(30 * time.Second)
+	_, rejection, retryAfter := m.Admit()
+	if rejection == nil || rejection.Message != DefaultMessage {
+		t.Fatalf("Admit() rejection = %+v, want the default message", rejection)
+	}
+	if retryAfter != time.Minute {
+		t.Fatalf("retryAfter = %s, want the 1m left", retryAfter)
+	}
+
+	*now = now.Add(time.Minute)
+	done, rejection, _ := m.Admit()
+	if rejection != nil {
+		t.Fatalf("Admit() rejection = %+v after expiry, want the request admitted", rejection)
+	}
+	done()
+	if state := m.State(); state.Active || state.Until != nil {
+		t.Fatalf("State() = %+v, want inactive", state)
+	}
+}
+
+func TestDrain_WaitsForAdmittedRequests(t *testing.T) {
+	m, _ := newTestMode(config.MaintenanceConfig{})
+	done, _, _ := m.Admit()
+	m.Enable("", 0)
+
+	if got := m.State().InFlight; got != 1 {
+		t.Fatalf("InFlight = %d, want 1", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Drain(ctx); err == nil {
+		t.Fatal("Drain() = nil while a request was in flight")
+	}
+
+	done()
+	done()
+	if err := m.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() = %v after the request finished", err)
+	}
+	if got := m.State().InFlight; got != 0 {
+		t.Fatalf("InFlight = %d, want 0 even when done is called twice", got)
+	}
+}
@@ -0,0 +1,287 @@
+// Package maxtokens supplies the max_tokens default the gateway sets on
+// translated chat and Responses requests that do not set an output token
+// limit, so the limit no longer depends on each provider's own default.
+//
+// Defaults come from configuration: one per model and one for all models.
+// With dynamic defaults on, a model without a configured default gets a
+// percentile of the output tokens of its recent usage records, times a
+// headroom factor. Records are kept in memory per model as the usage logger
+// writes them, and the percentile is recomputed at most once per
+// percentileTTL, so the request path never queries storage. After a restart,
+// Warm refills the records from the usage store. Until a model has enough
+// records, the static defaults apply.
+package maxtokens
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+const (
+	// maxSamplesPerModel bounds the usage records kept for one model; the
+	// oldest are dropped first.
+	maxSamplesPerModel = 2000
+	// maxModels bounds how many models dynamic defaults are tracked for.
+	maxModels = 1000
+	// percentileTTL is how long a computed dynamic default is reused.
+	percentileTTL = time.Minute
+	// warmPageSize is the usage log page size read by Warm.
+	warmPageSize = 200
+	// maxWarmEntries bounds how many usage records Warm reads.
+	maxWarmEntries = 100000
+	// sampleBytes is the size of one kept usage record.
+	sampleBytes = 32
+	// modelOverheadBytes estimates a tracked model beyond its records.
+	modelOverheadBytes = 128
+	// internalUsageLabel marks usage records of requests the gateway sent
+	// itself, such as capability probes and key health checks.
+	internalUsageLabel = "gomodel_internal"
+)
+
+type sample struct {
+	at     time.Time
+	tokens int
+}
+
+// modelSamples holds one model's recent completion lengths, oldest first,
+// and the dynamic default last computed from them.
+type modelSamples struct {
+	samples    []sample
+	value      int
+	computedAt time.Time
+}
+
+// Policy resolves max_tokens defaults. A nil Policy applies no default.
+type Policy struct {
+	global  int
+	models  map[string]int
+	dynamic config.DynamicMaxTokensConfig
+	now     func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*modelSamples
+}
+
+// New returns the policy for cfg, or nil when it sets no default.
+func New(cfg config.DefaultMaxTokensConfig) *Policy {
+	if cfg.Default <= 0 && len(cfg.Models) == 0 && !cfg.Dynamic.Enabled {
+		return nil
+	}
+	return &Policy{
+		global:  cfg.Default,
+		models:  cfg.Models,
+		dynamic: cfg.Dynamic,
+		now:     time.Now,
+		series:  make(map[string]*modelSamples),
+	}
+}
+
+// DynamicEnabled reports whether p derives defaults from usage records.
+func (p *Policy) DynamicEnabled() bool {
+	return p != nil && p.dynamic.Enabled
+}
+
+// DefaultMaxTokens returns the default for model served by the provider
+// named providerName: the model's configured default, then its dynamic
+// default, then the global default. It returns false when none applies and
+// the provider's own default is left in place.
+func (p *Policy) DefaultMaxTokens(providerName, model string) (core.MaxTokensDefault, bool) {
+	if p == nil || model == "" {
+		return core.MaxTokensDefault{}, false
+	}
+	if providerName != "" {
+		if value, ok := p.models[providerName+"/"+model]; ok {
+			return core.MaxTokensDefault{Value: value, Source: core.MaxTokensDefaultModel}, true
+		}
+	}
+	if value, ok := p.models[model]; ok {
+		return core.MaxTokensDefault{Value: value, Source: core.MaxTokensDefaultModel}, true
+	}
+	if value := p.dynamicDefault(model); value > 0 {
+		return core.MaxTokensDefault{Value: value, Source: core.MaxTokensDefaultDynamic}, true
+	}
+	if p.global > 0 {
+		return core.MaxTokensDefault{Value: p.global, Source: core.MaxTokensDefaultGlobal}, true
+	}
+	return core.MaxTokensDefault{}, false
+}
+
+// dynamicDefault returns the dynamic default of model, or 0 when dynamic
+// defaults are off or the model has too few recent records.
+func (p *Policy) dynamicDefault(model string) int {
+	if !p.dynamic.Enabled {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series := p.series[model]
+	if series == nil {
+		return 0
+	}
+	now := p.now()
+	if !series.computedAt.IsZero() && now.Sub(series.computedAt) < percentileTTL {
+		return series.value
+	}
+	cutoff := now.Add(-p.dynamic.Window)
+	first, _ := slices.BinarySearchFunc(series.samples, cutoff, func(s sample, t time.Time) int {
+		return s.at.Compare(t)
+	})
+	series.samples = slices.Delete(series.samples, 0, first)
+	series.value = percentileDefault(series.samples, p.dynamic)
+	series.computedAt = now
+	return series.value
+}
+
+// percentileDefault returns the configured percentile of the samples'
+// output tokens times the headroom, or 0 for fewer than MinSamples samples.
+func percentileDefault(samples []sample, cfg config.DynamicMaxTokensConfig) int {
+	if len(samples) == 0 || len(samples) < cfg.MinSamples {
+		return 0
+	}
+	tokens := make([]int, len(samples))
+	for i, s := range samples {
+		tokens[i] = s.tokens
+	}
+	slices.Sort(tokens)
+	// Nearest-rank percentile.
+	rank := int(math.Ceil(cfg.Percentile / 100 * float64(len(tokens))))
+	rank = min(max(rank, 1), len(tokens))
+	return int(math.Ceil(float64(tokens[rank-1]) * cfg.Headroom))
+}
+
+// Observe records the completion length of a usage record. Records without
+// output tokens, cache hits and the gateway's own requests are ignored.
+func (p *Policy) Observe(entry *usage.UsageEntry) {
+	if p == nil || entry == nil || entry.CacheType != "" || entry.Labels[internalUsageLabel] != "" {
+		return
+	}
+	p.observe(entry.Timestamp, entry.Model, entry.OutputTokens)
+}
+
+func (p *Policy) observe(at time.Time, model string, tokens int) {
+	if !p.dynamic.Enabled || model == "" || tokens <= 0 {
+		return
+	}
+	if at.IsZero() {
+		at = p.now()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series := p.series[model]
+	if series == nil {
+		if len(p.series) >= maxModels {
+			return
+		}
+		series = &modelSamples{}
+		p.series[model] = series
+	}
+	// Keep samples ordered by time; records arrive nearly in order.
+	i := len(series.samples)
+	for i > 0 && series.samples[i-1].at.After(at) {
+		i--
+	}
+	series.samples = slices.Insert(series.samples, i, sample{at: at, tokens: tokens})
+	if len(series.samples) > maxSamplesPerModel {
+		series.samples = slices.Delete(series.samples, 0, len(series.samples)-maxSamplesPerModel)
+	}
+}
+
+// MemoryUsage returns the number of kept usage records and their estimated
+// bytes.
+func (p *Policy) MemoryUsage() (int, int64) {
+	if p == nil {
+		return 0, 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := 0
+	for _, series := range p.series {
+		entries += len(series.samples)
+	}
+	return entries, int64(entries)*sampleBytes + int64(len(p.series))*modelOverheadBytes
+}
+
+// Evict drops the oldest fraction of every model's usage records, rounded
+// up, and forgets models left without records. Dynamic defaults are
+// recomputed from what is left, so a model may fall back to the static
+// defaults until it has enough records again.
+func (p *Policy) Evict(fraction float64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for model, series := range p.series {
+		n := min(int(math.Ceil(fraction*float64(len(series.samples)))), len(series.samples))
+		if n == len(series.samples) {
+			delete(p.series, model)
+			continue
+		}
+		// Clone so the dropped records' backing array is released.
+		series.samples = slices.Clone(series.samples[n:])
+		series.computedAt = time.Time{}
+	}
+}
+
+// Warm reads the uncached usage records of the dynamic window from reader
+// and returns how many it read. It does nothing without dynamic defaults.
+func (p *Policy) Warm(ctx context.Context, reader usage.UsageReader) (int, error) {
+	if !p.DynamicEnabled() || reader == nil {
+		return 0, nil
+	}
+	end := p.now().UTC()
+	start := end.Add(-p.dynamic.Window)
+	params := usage.UsageLogParams{
+		UsageQueryParams: usage.UsageQueryParams{StartDate: start, EndDate: end, CacheMode: usage.CacheModeUncached},
+		Limit:            warmPageSize,
+	}
+	read := 0
+	for read < maxWarmEntries {
+		page, err := reader.GetUsageLog(ctx, params)
+		if err != nil {
+			return read, err
+		}
+		for _, entry := range page.Entries {
+			// Pages are newest first; the range is day-precise.
+			if entry.Timestamp.Before(start) {
+				return read, nil
+			}
+			if entry.Labels[internalUsageLabel] == "" {
+				p.observe(entry.Timestamp, entry.Model, entry.OutputTokens)
+			}
+			read++
+		}
+		if len(page.Entries) < warmPageSize {
+			break
+		}
+		params.Offset += len(page.Entries)
+	}
+	return read, nil
+}
+
+// observedLogger passes every usage record it writes to the policy.
+type observedLogger struct {
+	usage.LoggerInterface
+	policy *Policy
+}
+
+func (l *observedLogger) Write(entry *usage.UsageEntry) {
+	l.LoggerInterface.Write(entry)
+	l.policy.Observe(entry)
+}
+
+// WrapLogger returns a usage logger that also feeds p. Without dynamic
+// defaults it returns logger unchanged.
+func (p *Policy) WrapLogger(logger usage.LoggerInterface) usage.LoggerInterface {
+	if !p.DynamicEnabled() || logger == nil {
+		return logger
+	}
+	return &observedLogger{LoggerInterface: logger, policy: p}
+}
@@ -0,0 +1,223 @@
+package maxtokens
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestPolicy(cfg config.DefaultMaxTokensConfig) *Policy {
+	if cfg.Dynamic.Percentile == 0 {
+		cfg.Dynamic.Percentile = 95
+	}
+	if cfg.Dynamic.Headroom == 0 {
+		cfg.Dynamic.Headroom = 1
+	}
+	if cfg.Dynamic.Window == 0 {
+		cfg.Dynamic.Window = time.Hour
+	}
+	if cfg.Dynamic.MinSamples == 0 {
+		cfg.Dynamic.MinSamples = 10
+	}
+	p := New(cfg)
+	if p != nil {
+		p.now = func() time.Time { return testNow }
+	}
+	return p
+}
+
+// seed writes one usage record per token count, a second apart, ending a
+// minute before testNow.
+func seed(p *Policy, model string, tokens ...int) {
+	for i, n := range tokens {
+		p.Observe(&usage.UsageEntry{
+			Timestamp:    testNow.Add(-time.Minute - time.Duration(len(tokens)-i)*time.Second),
+			Model:        model,
+			Provider:     "openai",
+			OutputTokens: n,
+		})
+	}
+}
+
+// ramp returns the token counts step, 2*step, ..., n*step.
+func ramp(n, step int) []int {
+	tokens := make([]int, n)
+	for i := range tokens {
+		tokens[i] = (i + 1) * step
+	}
+	return tokens
+}
+
+func TestNew_NilWithoutDefaults(t *testing.T) {
+	if p := New(config.DefaultMaxTokensConfig{}); p != nil {
+		t.Fatalf("New() = %+v, want nil without defaults", p)
+	}
+	var p *Policy
+	if got, ok := p.DefaultMaxTokens("openai", "gpt-4o"); ok {
+		t.Fatalf("nil Policy DefaultMaxTokens() = %+v, want none", got)
+	}
+}
+
+func TestPolicy_DefaultMaxTokensPrecedence(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Default: 1000,
+		Models: map[string]int{
+			"gpt-4o":       2000,
+			"azure/gpt-4o": 3000,
+			"o3":           4000,
+		},
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true},
+	})
+	seed(p, "o3", ramp(20, 10)...)
+	seed(p, "gpt-4o-mini", ramp(20, 10)...)
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		want     core.MaxTokensDefault
+	}{
+		{name: "qualified model", provider: "azure", model: "gpt-4o", want: core.MaxTokensDefault{Value: 3000, Source: core.MaxTokensDefaultModel}},
+		{name: "bare model", provider: "openai", model: "gpt-4o", want: core.MaxTokensDefault{Value: 2000, Source: core.MaxTokensDefaultModel}},
+		{name: "model wins over dynamic", provider: "openai", model: "o3", want: core.MaxTokensDefault{Value: 4000, Source: core.MaxTokensDefaultModel}},
+		{name: "dynamic", provider: "openai", model: "gpt-4o-mini", want: core.MaxTokensDefault{Value: 190, Source: core.MaxTokensDefaultDynamic}},
+		{name: "global", provider: "anthropic", model: "claude-sonnet-4", want: core.MaxTokensDefault{Value: 1000, Source: core.MaxTokensDefaultGlobal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.DefaultMaxTokens(tt.provider, tt.model)
+			if !ok || got != tt.want {
+				t.Fatalf("DefaultMaxTokens(%q, %q) = %+v, %v, want %+v", tt.provider, tt.model, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_NoGlobalLeavesProviderDefault(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{Models: map[string]int{"gpt-4o": 2000}})
+	if got, ok := p.DefaultMaxTokens("anthropic", "claude-sonnet-4"); ok {
+		t.Fatalf("DefaultMaxTokens() = %+v, want the provider's own default", got)
+	}
+}
+
+func TestPolicy_DynamicPercentileWithHeadroom(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true, Percentile: 90, Headroom: 1.5, MinSamples: 10},
+	})
+	// Out of order on purpose: the percentile does not depend on arrival order.
+	seed(p, "gpt-4o", 500, 100, 900, 300, 700, 200, 1000, 400, 800, 600)
+
+	got, ok := p.DefaultMaxTokens("openai", "gpt-4o")
+	// p90 of 100..1000 by nearest rank is 900; with 1.5 headroom, 1350.
+	if !ok || got.Value != 1350 || got.Source != core.MaxTokensDefaultDynamic {
+		t.Fatalf("DefaultMaxTokens() = %+v, %v, want 1350 from dynamic", got, ok)
+	}
+}
+
+func TestPolicy_DynamicDegradesToStaticDefault(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Default: 1000,
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true, MinSamples: 10},
+	})
+
+	// No usage data at all.
+	if got, ok := p.DefaultMaxTokens("openai", "gpt-4o"); !ok || got.Source != core.MaxTokensDefaultGlobal {
+		t.Fatalf("DefaultMaxTokens() without usage = %+v, %v, want the global default", got, ok)
+	}
+
+	// Too few records, ignored records and records outside the window.
+	seed(p, "gpt-4o", ramp(9, 10)...)
+	p.Observe(&usage.UsageEntry{Timestamp: testNow, Model: "gpt-4o", OutputTokens: 50, CacheType: "exact"})
+	p.Observe(&usage.UsageEntry{Timestamp: testNow, Model: "gpt-4o", OutputTokens: 50, Labels: map[string]string{internalUsageLabel: "probe"}})
+	p.Observe(&usage.UsageEntry{Timestamp: testNow, Model: "gpt-4o"})
+	p.Observe(&usage.UsageEntry{Timestamp: testNow.Add(-2 * time.Hour), Model: "gpt-4o", OutputTokens: 50})
+	if got, ok := p.DefaultMaxTokens("openai", "gpt-4o"); !ok || got.Source != core.MaxTokensDefaultGlobal {
+		t.Fatalf("DefaultMaxTokens() with too few samples = %+v, %v, want the global default", got, ok)
+	}
+}
+
+func TestPolicy_DynamicRecomputedAfterTTL(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true, Percentile: 100, MinSamples: 1},
+	})
+	seed(p, "gpt-4o", 100)
+	if got, _ := p.DefaultMaxTokens("openai", "gpt-4o"); got.Value != 100 {
+		t.Fatalf("DefaultMaxTokens() = %+v, want 100", got)
+	}
+
+	p.Observe(&usage.UsageEntry{Timestamp: testNow, Model: "gpt-4o", OutputTokens: 500})
+	if got, _ := p.DefaultMaxTokens("openai", "gpt-4o"); got.Value != 100 {
+		t.Fatalf("DefaultMaxTokens() within the TTL = %+v, want the cached 100", got)
+	}
+
+	p.now = func() time.Time { return testNow.Add(percentileTTL) }
+	if got, _ := p.DefaultMaxTokens("openai", "gpt-4o"); got.Value != 500 {
+		t.Fatalf("DefaultMaxTokens() after the TTL = %+v, want 500", got)
+	}
+}
+
+type fakeUsageReader struct {
+	usage.UsageReader
+	entries []usage.UsageLogEntry // newest first
+}
+
+func (r *fakeUsageReader) GetUsageLog(_ context.Context, params usage.UsageLogParams) (*usage.UsageLogResult, error) {
+	end := min(params.Offset+params.Limit, len(r.entries))
+	return &usage.UsageLogResult{Entries: r.entries[params.Offset:end], Total: len(r.entries), Limit: params.Limit, Offset: params.Offset}, nil
+}
+
+func TestPolicy_WarmFromUsageFixtures(t *testing.T) {
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true, Percentile: 50, Headroom: 2, MinSamples: 100},
+	})
+
+	reader := &fakeUsageReader{}
+	for i := range 300 {
+		reader.entries = append(reader.entries, usage.UsageLogEntry{
+			Timestamp:    testNow.Add(-time.Duration(i+1) * 10 * time.Second),
+			Model:        "gpt-4o",
+			ProviderName: "openai",
+			OutputTokens: 100 + i%3*100,
+		})
+	}
+	reader.entries = append(reader.entries, usage.UsageLogEntry{
+		Timestamp:    testNow.Add(-55 * time.Minute),
+		Model:        "gpt-4o",
+		OutputTokens: 100000,
+		Labels:       map[string]string{internalUsageLabel: "key_health"},
+	})
+	// Older than the window: Warm stops here.
+	reader.entries = append(reader.entries, usage.UsageLogEntry{Timestamp: testNow.Add(-2 * time.Hour), Model: "gpt-4o", OutputTokens: 100000})
+
+	read, err := p.Warm(context.Background(), reader)
+	if err != nil || read != 301 {
+		t.Fatalf("Warm() = %d, %v, want 301 records", read, err)
+	}
+	got, ok := p.DefaultMaxTokens("openai", "gpt-4o")
+	// Equal thirds of 100, 200 and 300: p50 is 200, doubled by headroom.
+	if !ok || got.Value != 400 || got.Source != core.MaxTokensDefaultDynamic {
+		t.Fatalf("DefaultMaxTokens() after Warm = %+v, %v, want 400 from dynamic", got, ok)
+	}
+}
+
+func TestPolicy_WrapLoggerFeedsPolicy(t *testing.T) {
+	static := newTestPolicy(config.DefaultMaxTokensConfig{Default: 1000})
+	logger := &usage.NoopLogger{}
+	if got := static.WrapLogger(logger); got != usage.LoggerInterface(logger) {
+		t.Fatalf("WrapLogger() without dynamic defaults = %T, want the logger unchanged", got)
+	}
+
+	p := newTestPolicy(config.DefaultMaxTokensConfig{
+		Dynamic: config.DynamicMaxTokensConfig{Enabled: true, Percentile: 100, MinSamples: 1},
+	})
+	p.WrapLogger(logger).Write(&usage.UsageEntry{Timestamp: testNow, Model: "gpt-4o", OutputTokens: 321})
+	if got, ok := p.DefaultMaxTokens("openai", "gpt-4o"); !ok || got.Value != 321 {
+		t.Fatalf("DefaultMaxTokens() after Write = %+v, %v, want 321", got, ok)
+	}
+}
@@ -0,0 +1,339 @@
+// Package memgovernor bounds the in-memory state the gateway accumulates,
+// such as caches, scoreboards and usage baselines. Each structure registers
+// as a Store that reports its estimated size and can drop part of its
+// entries; the governor keeps their total under a global budget and warns
+// when the process nears its memory ceiling. The model registry snapshot is
+// not a store: it is rebuilt from providers and cannot be trimmed.
+//
+// Lock discipline: the governor never holds its own lock while it calls a
+// store, and a store must not call the governor from MemoryUsage or Evict.
+// Inside those calls a store only takes its own lock, exactly like its other
+// operations, so eviction cannot deadlock with requests using the store.
+package memgovernor
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gomodel/config"
+)
+
+// lowWaterRatio is the share of the budget an eviction pass trims down to,
+// so the stores are not trimmed again on the next check.
+const lowWaterRatio = 0.9
+
+// Priority orders stores for eviction: every store of a lower priority is
+// trimmed before any store of a higher one.
+type Priority int
+
+const (
+	// PriorityLow is for caches whose entries are cheap to rebuild.
+	PriorityLow Priority = iota
+	// PriorityNormal is for statistics and baselines that degrade gracefully
+	// when trimmed.
+	PriorityNormal
+	// PriorityHigh is for state whose loss changes how requests are answered.
+	PriorityHigh
+)
+
+// String returns the name of p used in diagnostics.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	default:
+		return "high"
+	}
+}
+
+// Store is an in-memory structure the governor bounds. Both methods are
+// called without any governor lock held and must not call the governor.
+type Store interface {
+	// MemoryUsage returns the number of entries and their estimated bytes.
+	MemoryUsage() (entries int, bytes int64)
+	// Evict drops about fraction of the entries, least valuable first.
+	// fraction is in (0, 1].
+	Evict(fraction float64)
+}
+
+// StoreUsage is the size of one registered store.
+type StoreUsage struct {
+	Name     string `json:"name"`
+	Priority string `json:"priority"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	// Evictions counts the eviction passes that trimmed the store and
+	// EvictedBytes the estimated bytes they freed.
+	Evictions    int64 `json:"evictions"`
+	EvictedBytes int64 `json:"evicted_bytes"`
+}
+
+// Snapshot is a point-in-time view of the registered stores and the process
+// memory.
+type Snapshot struct {
+	// BudgetBytes is the configured budget, 0 when it is disabled.
+	BudgetBytes int64 `json:"budget_bytes"`
+	// UsedBytes is the estimated size of all registered stores.
+	UsedBytes int64 `json:"used_bytes"`
+	// RSSCeilingBytes is the configured process memory ceiling, 0 when it
+	// is disabled.
+	RSSCeilingBytes int64 `json:"rss_ceiling_bytes"`
+	// ProcessBytes is the memory the Go runtime obtained from the OS, the
+	// closest figure to RSS the runtime reports.
+	ProcessBytes uint64 `json:"process_bytes"`
+	// NearRSSCeiling reports whether ProcessBytes is at or above the warning
+	// share of the ceiling.
+	NearRSSCeiling bool         `json:"near_rss_ceiling"`
+	LastEvictionAt *time.Time   `json:"last_eviction_at,omitempty"`
+	Stores         []StoreUsage `json:"stores"`
+}
+
+type registration struct {
+	name         string
+	priority     Priority
+	store        Store
+	evictions    atomic.Int64
+	evictedBytes atomic.Int64
+}
+
+type measured struct {
+	reg     *registration
+	entries int
+	bytes   int64
+}
+
+// Governor enforces the memory budget over the registered stores. A nil
+// Governor registers nothing and never evicts.
+type Governor struct {
+	budget    int64
+	ceiling   int64
+	warnRatio float64
+	interval  time.Duration
+	readSys   func() uint64
+	now       func() time.Time
+
+	mu     sync.Mutex
+	stores []*registration
+
+	// evictMu serializes eviction passes; it is never taken while mu is held.
+	evictMu        sync.Mutex
+	lastEvictionAt atomic.Int64 // unix nanos, 0 before the first pass
+	nearCeiling    atomic.Bool
+
+	stopOnce sync.Once
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New returns a governor for cfg. Stores can register with it even when the
+// budget and the ceiling are disabled, so their sizes are still reported.
+func New(cfg config.MemoryConfig) *Governor {
+	return &Governor{
+		budget:    cfg.BudgetBytes,
+		ceiling:   cfg.RSSCeilingBytes,
+		warnRatio: cfg.RSSWarnRatio,
+		interval:  cfg.CheckInterval,
+		readSys:   readSys,
+		now:       time.Now,
+	}
+}
+
+func readSys() uint64 {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return mem.Sys
+}
+
+// Register adds store under name. Stores of a lower priority are trimmed
+// first. A nil store is ignored.
+func (g *Governor) Register(name string, priority Priority, store Store) {
+	if g == nil || store == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stores = append(g.stores, &registration{name: name, priority: priority, store: store})
+}
+
+// registrations copies the registered stores, so callers can use them
+// without holding g.mu.
+func (g *Governor) registrations() []*registration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.stores)
+}
+
+func measure(regs []*registration) ([]measured, int64) {
+	sizes := make([]measured, len(regs))
+	var total int64
+	for i, reg := range regs {
+		entries, bytes := reg.store.MemoryUsage()
+		sizes[i] = measured{reg: reg, entries: entries, bytes: bytes}
+		total += bytes
+	}
+	return sizes, total
+}
+
+// Enforce trims the stores when their total exceeds the budget and returns
+// the estimated bytes freed. The lowest priority is trimmed first, each of
+// its stores by the same fraction so they shrink in proportion to their
+// size; what a priority cannot free moves on to the next one. The pass
+// stops once the total is back under lowWaterRatio of the budget.
+func (g *Governor) Enforce() int64 {
+	if g == nil || g.budget <= 0 {
+		return 0
+	}
+	g.evictMu.Lock()
+	defer g.evictMu.Unlock()
+
+	sizes, used := measure(g.registrations())
+	if used <= g.budget {
+		return 0
+	}
+	excess := used - int64(float64(g.budget)*lowWaterRatio)
+	var freed int64
+	for _, priority := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		var class []measured
+		var classBytes int64
+		for _, size := range sizes {
+			if size.reg.priority == priority && size.bytes > 0 {
+				class = append(class, size)
+				classBytes += size.bytes
+			}
+		}
+		if classBytes == 0 {
+			continue
+		}
+		fraction := math.Min(1, float64(excess)/float64(classBytes))
+		for _, size := range class {
+			size.reg.store.Evict(fraction)
+			_, after := size.reg.store.MemoryUsage()
+			// Requests may have grown the store in the meantime.
+			storeFreed := max(size.bytes-after, 0)
+			size.reg.evictions.Add(1)
+			size.reg.evictedBytes.Add(storeFreed)
+			freed += storeFreed
+			excess -= storeFreed
+		}
+		if excess <= 0 {
+			break
+		}
+	}
+	g.lastEvictionAt.Store(g.now().UnixNano())
+	slog.Warn("in-memory state exceeded the memory budget; evicted entries",
+		"budget_bytes", g.budget,
+		"used_bytes", used,
+		"freed_bytes", freed)
+	return freed
+}
+
+// checkCeiling logs when process memory crosses the warning share of the
+// ceiling in either direction.
+func (g *Governor) checkCeiling() {
+	if g.ceiling <= 0 {
+		return
+	}
+	sys := g.readSys()
+	near := float64(sys) >= float64(g.ceiling)*g.warnRatio
+	if near == g.nearCeiling.Swap(near) {
+		return
+	}
+	if near {
+		slog.Warn("process memory is approaching the configured ceiling",
+			"process_bytes", sys,
+			"rss_ceiling_bytes", g.ceiling,
+			"warn_ratio", g.warnRatio)
+		return
+	}
+	slog.Info("process memory is back below the ceiling warning level",
+		"process_bytes", sys,
+		"rss_ceiling_bytes", g.ceiling)
+}
+
+// Check enforces the budget and checks the process memory once.
+func (g *Governor) Check() {
+	if g == nil {
+		return
+	}
+	g.Enforce()
+	g.checkCeiling()
+}
+
+// Start runs Check once per interval in a background goroutine until Close.
+// It does nothing when neither the budget nor the ceiling is set.
+func (g *Governor) Start() {
+	if g == nil || g.done != nil || (g.budget <= 0 && g.ceiling <= 0) || g.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	g.done = make(chan struct{})
+
+	go func() {
+		defer close(g.done)
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.Check()
+			}
+		}
+	}()
+}
+
+// Close stops the periodic checks and waits for a running check to finish.
+func (g *Governor) Close() {
+	if g == nil {
+		return
+	}
+	g.stopOnce.Do(func() {
+		if g.cancel != nil {
+			g.cancel()
+			<-g.done
+		}
+	})
+}
+
+// Snapshot reports the size of every registered store, in registration
+// order, and the process memory.
+func (g *Governor) Snapshot() Snapshot {
+	if g == nil {
+		return Snapshot{Stores: []StoreUsage{}}
+	}
+	sizes, used := measure(g.registrations())
+	sys := g.readSys()
+	snapshot := Snapshot{
+		BudgetBytes:     g.budget,
+		UsedBytes:       used,
+		RSSCeilingBytes: g.ceiling,
+		ProcessBytes:    sys,
+		NearRSSCeiling:  g.ceiling > 0 && float64(sys) >= float64(g.ceiling)*g.warnRatio,
+		Stores:          make([]StoreUsage, 0, len(sizes)),
+	}
+	if at := g.lastEvictionAt.Load(); at > 0 {
+		t := time.Unix(0, at).UTC()
+		snapshot.LastEvictionAt = &t
+	}
+	for _, size := range sizes {
+		snapshot.Stores = append(snapshot.Stores, StoreUsage{
+			Name:         size.reg.name,
+			Priority:     size.reg.priority.String(),
+			Entries:      size.entries,
+			Bytes:        size.bytes,
+			Evictions:    size.reg.evictions.Load(),
+			EvictedBytes: size.reg.evictedBytes.Load(),
+		})
+	}
+	return snapshot
+}
@@ -0,0 +1,231 @@
+package memgovernor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/cache"
+)
+
+// fakeStore holds entries of entryBytes each and evicts the rounded fraction
+// it is asked to.
+type fakeStore struct {
+	mu         sync.Mutex
+	entries    int
+	entryBytes int64
+	evictCalls int
+}
+
+func (s *fakeStore) MemoryUsage() (int, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries, int64(s.entries) * s.entryBytes
+}
+
+func (s *fakeStore) Evict(fraction float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries -= int(math.Round(fraction * float64(s.entries)))
+	s.evictCalls++
+}
+
+func (s *fakeStore) add(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries += n
+}
+
+func newTestGovernor(budget int64) *Governor {
+	g := New(config.MemoryConfig{BudgetBytes: budget, RSSWarnRatio: 0.9, CheckInterval: time.Second})
+	g.readSys = func() uint64 { return 0 }
+	return g
+}
+
+func TestGovernor_UnderBudgetDoesNotEvict(t *testing.T) {
+	g := newTestGovernor(1000)
+	store := &fakeStore{entries: 100, entryBytes: 10}
+	g.Register("cache", PriorityLow, store)
+
+	if freed := g.Enforce(); freed != 0 || store.evictCalls != 0 {
+		t.Fatalf("Enforce() = %d with %d evictions, want nothing evicted at the budget", freed, store.evictCalls)
+	}
+	if snapshot := g.Snapshot(); snapshot.LastEvictionAt != nil {
+		t.Fatalf("LastEvictionAt = %v, want nil", snapshot.LastEvictionAt)
+	}
+}
+
+func TestGovernor_EvictsLowPriorityFirst(t *testing.T) {
+	g := newTestGovernor(1000)
+	low := &fakeStore{entries: 20, entryBytes: 10}
+	normal := &fakeStore{entries: 100, entryBytes: 10}
+	high := &fakeStore{entries: 10, entryBytes: 10}
+	g.Register("normal", PriorityNormal, normal)
+	g.Register("high", PriorityHigh, high)
+	g.Register("low", PriorityLow, low)
+
+	// 1300 bytes trimmed to 900: the low store frees all of its 200, the
+	// normal store the remaining 200, and the high store is not touched.
+	if freed := g.Enforce(); freed != 400 {
+		t.Fatalf("Enforce() = %d, want 400", freed)
+	}
+	if low.entries != 0 || normal.entries != 80 || high.entries != 10 {
+		t.Fatalf("entries low=%d normal=%d high=%d, want 0, 80 and 10", low.entries, normal.entries, high.entries)
+	}
+	if high.evictCalls != 0 {
+		t.Fatalf("high priority store evicted %d times, want 0", high.evictCalls)
+	}
+}
+
+func TestGovernor_ProportionalWithinPriority(t *testing.T) {
+	g := newTestGovernor(500)
+	big := &fakeStore{entries: 60, entryBytes: 10}
+	small := &fakeStore{entries: 40, entryBytes: 10}
+	g.Register("big", PriorityNormal, big)
+	g.Register("small", PriorityNormal, small)
+
+	// 1000 bytes trimmed to 450: both stores lose 55% of their entries.
+	if freed := g.Enforce(); freed != 550 {
+		t.Fatalf("Enforce() = %d, want 550", freed)
+	}
+	if big.entries != 27 || small.entries != 18 {
+		t.Fatalf("entries big=%d small=%d, want 27 and 18", big.entries, small.entries)
+	}
+
+	snapshot := g.Snapshot()
+	if snapshot.UsedBytes != 450 || snapshot.BudgetBytes != 500 || snapshot.LastEvictionAt == nil {
+		t.Fatalf("Snapshot() = %+v, want 450 of 500 bytes after an eviction", snapshot)
+	}
+	want := []StoreUsage{
+		{Name: "big", Priority: "normal", Entries: 27, Bytes: 270, Evictions: 1, EvictedBytes: 330},
+		{Name: "small", Priority: "normal", Entries: 18, Bytes: 180, Evictions: 1, EvictedBytes: 220},
+	}
+	if fmt.Sprint(snapshot.Stores) != fmt.Sprint(want) {
+		t.Fatalf("Stores = %+v, want %+v", snapshot.Stores, want)
+	}
+}
+
+func TestGovernor_NoBudgetOnlyReports(t *testing.T) {
+	g := newTestGovernor(0)
+	store := &fakeStore{entries: 1000, entryBytes: 1000}
+	g.Register("cache", PriorityLow, store)
+
+	if freed := g.Enforce(); freed != 0 || store.entries != 1000 {
+		t.Fatalf("Enforce() = %d, want no eviction without a budget", freed)
+	}
+	if snapshot := g.Snapshot(); snapshot.UsedBytes != 1000000 || len(snapshot.Stores) != 1 {
+		t.Fatalf("Snapshot() = %+v, want the store reported", snapshot)
+	}
+}
+
+func TestGovernor_RSSCeiling(t *testing.T) {
+	g := New(config.MemoryConfig{RSSCeilingBytes: 1000, RSSWarnRatio: 0.9, CheckInterval: time.Second})
+	var sys atomic.Uint64
+	g.readSys = sys.Load
+
+	sys.Store(800)
+	g.Check()
+	if g.nearCeiling.Load() || g.Snapshot().NearRSSCeiling {
+		t.Fatal("near ceiling at 800 of 1000 bytes, want below the 90% warning level")
+	}
+
+	sys.Store(950)
+	g.Check()
+	if snapshot := g.Snapshot(); !g.nearCeiling.Load() || !snapshot.NearRSSCeiling || snapshot.ProcessBytes != 950 {
+		t.Fatalf("Snapshot() = %+v, want near the ceiling at 950 bytes", snapshot)
+	}
+
+	sys.Store(500)
+	g.Check()
+	if g.nearCeiling.Load() {
+		t.Fatal("still near ceiling after memory dropped to 500 bytes")
+	}
+}
+
+func TestGovernor_Nil(t *testing.T) {
+	var g *Governor
+	g.Register("cache", PriorityLow, &fakeStore{})
+	g.Check()
+	g.Start()
+	g.Close()
+	if freed := g.Enforce(); freed != 0 {
+		t.Fatalf("nil Enforce() = %d, want 0", freed)
+	}
+	if snapshot := g.Snapshot(); snapshot.Stores == nil || len(snapshot.Stores) != 0 {
+		t.Fatalf("nil Snapshot() = %+v, want no stores", snapshot)
+	}
+}
+
+// TestGovernor_ConcurrentEviction hammers real stores while the governor
+// evicts, registers stores and takes snapshots. Run with -race: it catches
+// data races and, through the timeout, deadlocks between eviction and store
+// operations.
+func TestGovernor_ConcurrentEviction(t *testing.T) {
+	const budget = 64 * 1024
+	g := newTestGovernor(budget)
+	stores := []*cache.LRUStore{
+		cache.NewLRUStore(0, 0),
+		cache.NewLRUStore(0, 0),
+		cache.NewLRUStore(0, 0),
+	}
+	for i, store := range stores {
+		g.Register(fmt.Sprintf("lru-%d", i), Priority(i), store)
+	}
+	fake := &fakeStore{entryBytes: 64}
+	g.Register("fake", PriorityNormal, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Go(func() {
+			value := make([]byte, 256)
+			for i := 0; ctx.Err() == nil; i++ {
+				store := stores[(w+i)%len(stores)]
+				key := fmt.Sprintf("w%d-%d", w, i%512)
+				_ = store.Set(ctx, key, value, 0)
+				_, _ = store.Get(ctx, fmt.Sprintf("w%d-%d", w, (i+7)%512))
+				fake.add(1)
+			}
+		})
+	}
+	for range 2 {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				g.Enforce()
+			}
+		})
+	}
+	wg.Go(func() {
+		for ctx.Err() == nil {
+			_ = g.Snapshot()
+		}
+	})
+	wg.Go(func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			g.Register(fmt.Sprintf("late-%d", i), PriorityLow, &fakeStore{entries: 1, entryBytes: 1})
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("stores and governor deadlocked")
+	}
+
+	g.Enforce()
+	if snapshot := g.Snapshot(); snapshot.UsedBytes > budget {
+		t.Fatalf("UsedBytes = %d after the writers stopped, want at most %d", snapshot.UsedBytes, budget)
+	}
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"gomodel/internal/aliases"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/batch"
+	"gomodel/internal/deferred"
+	"gomodel/internal/guardrails"
+	"gomodel/internal/modeloverrides"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/usage"
+	"gomodel/internal/workflows"
+)
+
+// baselineSchema is the table setup of one store package.
+type baselineSchema struct {
+	name       string
+	sqlite     func(context.Context, *sql.DB) error
+	postgresql func(context.Context, *pgxpool.Pool) error
+}
+
+// baselineSchemas lists every store whose tables existed before versioned
+// migrations. The semantic cache's pgvector table is left out: its layout
+// depends on the configured embedding dimensions.
+var baselineSchemas = []baselineSchema{
+	{"audit logs", auditlog.CreateSQLiteSchema, auditlog.CreatePostgreSQLSchema},
+	{"audit stream samples", auditlog.CreateSQLiteStreamSampleSchema, auditlog.CreatePostgreSQLStreamSampleSchema},
+	{"usage", usage.CreateSQLiteSchema, usage.CreatePostgreSQLSchema},
+	{"batches", batch.CreateSQLiteSchema, batch.CreatePostgreSQLSchema},
+	{"deferred requests", deferred.CreateSQLiteSchema, deferred.CreatePostgreSQLSchema},
+	{"aliases", aliases.CreateSQLiteSchema, aliases.CreatePostgreSQLSchema},
+	{"model overrides", modeloverrides.CreateSQLiteSchema, modeloverrides.CreatePostgreSQLSchema},
+	{"auth keys", authkeys.CreateSQLiteSchema, authkeys.CreatePostgreSQLSchema},
+	{"guardrails", guardrails.CreateSQLiteSchema, guardrails.CreatePostgreSQLSchema},
+	{"workflows", workflows.CreateSQLiteSchema, workflows.CreatePostgreSQLSchema},
+	{"prompt templates", prompttemplates.CreateSQLiteSchema, prompttemplates.CreatePostgreSQLSchema},
+}
+
+// baseline is migration 0001. It runs the idempotent table setup the stores
+// used before versioned migrations, so a fresh database and one created by an
+// older release converge on the same schema.
+var baseline = Migration{
+	Version: 1,
+	Name:    "baseline",
+	Up: Script{Func: func(ctx context.Context, db DB) error {
+		for _, schema := range baselineSchemas {
+			var err error
+			if db.PostgreSQL != nil {
+				err = schema.postgresql(ctx, db.PostgreSQL)
+			} else {
+				err = schema.sqlite(ctx, db.SQLite)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", schema.name, err)
+			}
+		}
+		return nil
+	}},
+}
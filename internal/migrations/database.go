@@ -0,0 +1,238 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"gomodel/internal/storage"
+)
+
+// Dialect names the SQL dialect of a database.
+type Dialect string
+
+// Supported dialects.
+const (
+	DialectSQLite     Dialect = storage.TypeSQLite
+	DialectPostgreSQL Dialect = storage.TypePostgreSQL
+)
+
+// advisoryLockID is the PostgreSQL advisory lock serializing migrations
+// across gateway instances starting at the same time.
+const advisoryLockID int64 = 0x676f6d6f64656c // "gomodel"
+
+// DB is the database a migration runs against. Exactly one of SQLite and
+// PostgreSQL is set.
+type DB struct {
+	SQLite     *sql.DB
+	PostgreSQL *pgxpool.Pool
+}
+
+type execFunc func(ctx context.Context, query string, args ...any) error
+
+// NewSQLite returns a Migrator for a SQLite database.
+func NewSQLite(db *sql.DB, migrations []Migration) *Migrator {
+	return newMigrator(DB{SQLite: db}, migrations)
+}
+
+// NewPostgreSQL returns a Migrator for a PostgreSQL database.
+func NewPostgreSQL(pool *pgxpool.Pool, migrations []Migration) *Migrator {
+	return newMigrator(DB{PostgreSQL: pool}, migrations)
+}
+
+// ForStorage returns a Migrator running All against store. It returns
+// ErrUnsupportedBackend for MongoDB.
+func ForStorage(store storage.Storage) (*Migrator, error) {
+	return storage.ResolveBackend[*Migrator](
+		store,
+		func(db *sql.DB) (*Migrator, error) {
+			return NewSQLite(db, All()), nil
+		},
+		func(pool *pgxpool.Pool) (*Migrator, error) {
+			return NewPostgreSQL(pool, All()), nil
+		},
+		func(*mongo.Database) (*Migrator, error) {
+			return nil, ErrUnsupportedBackend
+		},
+	)
+}
+
+// Prepare brings store to the schema this binary expects at startup. With
+// autoMigrate it applies pending migrations and returns them; otherwise it
+// fails while any are pending. Either way it refuses a database migrated by
+// a newer binary. MongoDB storage is left alone.
+func Prepare(ctx context.Context, store storage.Storage, autoMigrate bool) ([]Migration, error) {
+	migrator, err := ForStorage(store)
+	if errors.Is(err, ErrUnsupportedBackend) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if autoMigrate {
+		return migrator.Up(ctx)
+	}
+	if _, err := migrator.Check(ctx); err != nil {
+		if errors.Is(err, ErrPending) {
+			return nil, fmt.Errorf("%w; run `gomodel migrate up` or set STORAGE_AUTO_MIGRATE=true", err)
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// Dialect returns the dialect of db.
+func (db DB) Dialect() Dialect {
+	if db.PostgreSQL != nil {
+		return DialectPostgreSQL
+	}
+	return DialectSQLite
+}
+
+// Exec runs a statement written with ? placeholders, which are rewritten to
+// $n for PostgreSQL.
+func (db DB) Exec(ctx context.Context, query string, args ...any) error {
+	if db.PostgreSQL != nil {
+		_, err := db.PostgreSQL.Exec(ctx, rebind(query), args...)
+		return err
+	}
+	_, err := db.SQLite.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (db DB) ensureTable(ctx context.Context) error {
+	err := db.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS `+Table+` (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", Table, err)
+	}
+	return nil
+}
+
+func (db DB) applied(ctx context.Context) ([]AppliedMigration, error) {
+	const query = "SELECT version, name, applied_at FROM " + Table + " ORDER BY version"
+	var applied []AppliedMigration
+	scan := func(version int, name string, appliedAt int64) {
+		applied = append(applied, AppliedMigration{Version: version, Name: name, AppliedAt: time.Unix(appliedAt, 0).UTC()})
+	}
+
+	if db.PostgreSQL != nil {
+		rows, err := db.PostgreSQL.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", Table, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				version   int
+				name      string
+				appliedAt int64
+			)
+			if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", Table, err)
+			}
+			scan(version, name, appliedAt)
+		}
+		return applied, rows.Err()
+	}
+
+	rows, err := db.SQLite.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", Table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			version   int
+			name      string
+			appliedAt int64
+		)
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", Table, err)
+		}
+		scan(version, name, appliedAt)
+	}
+	return applied, rows.Err()
+}
+
+// inTx runs fn in a transaction.
+func (db DB) inTx(ctx context.Context, fn func(exec execFunc) error) error {
+	if db.PostgreSQL != nil {
+		tx, err := db.PostgreSQL.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		if err := fn(func(ctx context.Context, query string, args ...any) error {
+			_, err := tx.Exec(ctx, rebind(query), args...)
+			return err
+		}); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	tx, err := db.SQLite.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(func(ctx context.Context, query string, args ...any) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock runs fn while holding the PostgreSQL migration lock. SQLite
+// serializes writers itself.
+func (db DB) withLock(ctx context.Context, fn func() error) error {
+	if db.PostgreSQL == nil {
+		return fn()
+	}
+	conn, err := db.PostgreSQL.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration connection: %w", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", advisoryLockID)
+	}()
+	return fn()
+}
+
+// rebind rewrites ? placeholders to PostgreSQL's $n form.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
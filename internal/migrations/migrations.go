@@ -0,0 +1,291 @@
+// Package migrations versions the relational storage schema.
+//
+// Migrations are ordered, numbered steps registered explicitly in All. The
+// version of every applied step is recorded in the schema_migrations table,
+// so a database can be brought up to date, inspected, or rolled back, and a
+// binary refuses to touch a database migrated by a newer release.
+// MongoDB storage has no schema and is not migrated.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Table is the name of the table recording applied migrations.
+const Table = "schema_migrations"
+
+var (
+	// ErrDatabaseNewer is returned when the database has migrations applied
+	// that this binary does not know, usually because a newer release ran
+	// against it.
+	ErrDatabaseNewer = errors.New("database schema is newer than this binary")
+	// ErrPending is returned by Check when migrations are waiting to be applied.
+	ErrPending = errors.New("database schema has pending migrations")
+	// ErrIrreversible is returned by Down for a migration without a Down script.
+	ErrIrreversible = errors.New("migration cannot be reverted")
+	// ErrUnsupportedBackend is returned for storage backends without a schema.
+	ErrUnsupportedBackend = errors.New("schema migrations apply to sqlite and postgresql storage only")
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	// Version orders migrations; it must be positive and unique.
+	Version int
+	// Name describes the change in a few words.
+	Name string
+	Up   Script
+	// Down reverts Up. A zero Script marks the migration as irreversible.
+	Down Script
+}
+
+// Script is the body of a migration step. SQL runs on every dialect unless
+// the dialect has its own override; Func runs Go code instead and takes
+// precedence over SQL.
+//
+// SQL scripts run in a transaction together with the version bookkeeping.
+// Func scripts manage their own statements and should be idempotent, since a
+// failure after they ran leaves the step unrecorded.
+type Script struct {
+	SQL        string
+	SQLite     string
+	PostgreSQL string
+	Func       func(ctx context.Context, db DB) error
+}
+
+func (s Script) empty() bool {
+	return s.Func == nil && s.SQL == "" && s.SQLite == "" && s.PostgreSQL == ""
+}
+
+func (s Script) statement(dialect Dialect) string {
+	switch {
+	case dialect == DialectSQLite && s.SQLite != "":
+		return s.SQLite
+	case dialect == DialectPostgreSQL && s.PostgreSQL != "":
+		return s.PostgreSQL
+	default:
+		return s.SQL
+	}
+}
+
+// All returns the migrations shipped with this binary, in version order.
+func All() []Migration {
+	return []Migration{
+		baseline,
+		usageJobs,
+	}
+}
+
+// AppliedMigration is a row of the schema_migrations table.
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Status describes the schema version of a database.
+type Status struct {
+	Dialect Dialect `json:"dialect"`
+	// Current is the highest applied version; 0 for an unmigrated database.
+	Current int `json:"current"`
+	// Latest is the highest version known to this binary.
+	Latest  int                `json:"latest"`
+	Applied []AppliedMigration `json:"applied"`
+	Pending []Migration        `json:"-"`
+	// Unknown lists applied versions this binary has no migration for.
+	Unknown []int `json:"unknown,omitempty"`
+}
+
+// Migrator applies and reverts migrations against one database.
+type Migrator struct {
+	db         DB
+	migrations []Migration
+}
+
+func newMigrator(db DB, migrations []Migration) *Migrator {
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int { return a.Version - b.Version })
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// Status reports the applied and pending migrations.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	if err := m.db.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	return m.status(ctx)
+}
+
+// Check returns ErrDatabaseNewer when the database is ahead of this binary
+// and ErrPending when migrations are waiting to be applied.
+func (m *Migrator) Check(ctx context.Context) (*Status, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := refuseNewer(status); err != nil {
+		return status, err
+	}
+	if len(status.Pending) > 0 {
+		return status, fmt.Errorf("%w: version %d, latest %d", ErrPending, status.Current, status.Latest)
+	}
+	return status, nil
+}
+
+// Up applies every pending migration in version order and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	err := m.db.withLock(ctx, func() error {
+		if err := m.db.ensureTable(ctx); err != nil {
+			return err
+		}
+		status, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		if err := refuseNewer(status); err != nil {
+			return err
+		}
+		for _, migration := range status.Pending {
+			if err := m.run(ctx, migration, migration.Up, true); err != nil {
+				return fmt.Errorf("migration %04d %s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the steps most recently applied migrations, newest first, and
+// returns them. It stops at the first irreversible migration.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	err := m.db.withLock(ctx, func() error {
+		if err := m.db.ensureTable(ctx); err != nil {
+			return err
+		}
+		status, err := m.status(ctx)
+		if err != nil {
+			return err
+		}
+		if err := refuseNewer(status); err != nil {
+			return err
+		}
+		for i := len(status.Applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration, _ := m.find(status.Applied[i].Version)
+			if migration.Down.empty() {
+				return fmt.Errorf("migration %04d %s: %w", migration.Version, migration.Name, ErrIrreversible)
+			}
+			if err := m.run(ctx, migration, migration.Down, false); err != nil {
+				return fmt.Errorf("revert migration %04d %s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) validate() error {
+	for i, migration := range m.migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("migration %q: version must be positive", migration.Name)
+		}
+		if i > 0 && migration.Version == m.migrations[i-1].Version {
+			return fmt.Errorf("duplicate migration version %d", migration.Version)
+		}
+		if migration.Name == "" {
+			return fmt.Errorf("migration %d: name is required", migration.Version)
+		}
+		if migration.Up.empty() {
+			return fmt.Errorf("migration %d: up script is required", migration.Version)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) status(ctx context.Context) (*Status, error) {
+	applied, err := m.db.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Dialect: m.db.Dialect(), Applied: applied}
+	if n := len(m.migrations); n > 0 {
+		status.Latest = m.migrations[n-1].Version
+	}
+	done := make(map[int]bool, len(applied))
+	for _, row := range applied {
+		done[row.Version] = true
+		status.Current = max(status.Current, row.Version)
+		if _, ok := m.find(row.Version); !ok {
+			status.Unknown = append(status.Unknown, row.Version)
+		}
+	}
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	i, ok := slices.BinarySearchFunc(m.migrations, version, func(migration Migration, version int) int {
+		return migration.Version - version
+	})
+	if !ok {
+		return Migration{}, false
+	}
+	return m.migrations[i], true
+}
+
+// run executes script and records (up) or removes (down) the version row.
+func (m *Migrator) run(ctx context.Context, migration Migration, script Script, up bool) error {
+	record := func(exec execFunc) error {
+		if up {
+			return exec(ctx, "INSERT INTO "+Table+" (version, name, applied_at) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, time.Now().Unix())
+		}
+		return exec(ctx, "DELETE FROM "+Table+" WHERE version = ?", migration.Version)
+	}
+
+	if script.Func != nil {
+		if err := script.Func(ctx, m.db); err != nil {
+			return err
+		}
+		return m.db.inTx(ctx, record)
+	}
+	statement := script.statement(m.db.Dialect())
+	return m.db.inTx(ctx, func(exec execFunc) error {
+		if statement != "" {
+			if err := exec(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return record(exec)
+	})
+}
+
+func refuseNewer(status *Status) error {
+	if len(status.Unknown) == 0 && status.Current <= status.Latest {
+		return nil
+	}
+	return fmt.Errorf("%w: database is at version %d but the latest migration known to this binary is %d; upgrade gomodel before using this database",
+		ErrDatabaseNewer, status.Current, status.Latest)
+}
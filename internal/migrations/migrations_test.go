@@ -0,0 +1,199 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// testMigrations extends the baseline migration with reversible steps.
+func testMigrations() []Migration {
+	return append([]Migration{baseline},
+		Migration{
+			Version: 1000,
+			Name:    "notes",
+			Up: Script{
+				SQL: `CREATE TABLE notes (id TEXT PRIMARY KEY);
+					CREATE INDEX idx_notes_id ON notes(id)`,
+			},
+			Down: Script{SQL: `DROP TABLE notes`},
+		},
+		Migration{
+			Version: 1001,
+			Name:    "notes body",
+			Up:      Script{SQL: `ALTER TABLE notes ADD COLUMN body TEXT`},
+			Down:    Script{SQLite: `ALTER TABLE notes DROP COLUMN body`, PostgreSQL: `ALTER TABLE notes DROP COLUMN IF EXISTS body`},
+		},
+	)
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count); err != nil {
+		t.Fatalf("failed to inspect schema: %v", err)
+	}
+	return count > 0
+}
+
+func TestUp_AppliesAllAndIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := NewSQLite(db, All())
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(All()) {
+		t.Fatalf("Up applied %d migrations, want %d", len(applied), len(All()))
+	}
+	for _, table := range []string{Table, "audit_logs", "usage", "batches", "aliases", "auth_keys", "workflow_versions", "prompt_templates", "usage_jobs"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("second Up = %v, %v; want nothing applied", applied, err)
+	}
+	status, err := migrator.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if status.Current != status.Latest || len(status.Pending) != 0 {
+		t.Fatalf("status after Up = %+v", status)
+	}
+}
+
+func TestUp_AdoptsDatabaseCreatedByStores(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	for _, schema := range baselineSchemas {
+		if err := schema.sqlite(ctx, db); err != nil {
+			t.Fatalf("%s: %v", schema.name, err)
+		}
+	}
+
+	if _, err := NewSQLite(db, All()).Up(ctx); err != nil {
+		t.Fatalf("Up on existing tables: %v", err)
+	}
+}
+
+func TestDown_RevertsNewestFirstAndStopsAtBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := NewSQLite(db, testMigrations())
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != 1001 {
+		t.Fatalf("Down(1) = %v, %v", reverted, err)
+	}
+	if _, err := db.Exec(`INSERT INTO notes (id, body) VALUES ('a', 'b')`); err == nil {
+		t.Fatal("notes.body still exists after Down")
+	}
+
+	reverted, err = migrator.Down(ctx, 5)
+	if !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("Down past baseline error = %v, want ErrIrreversible", err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != 1000 || tableExists(t, db, "notes") {
+		t.Fatalf("Down(5) reverted %v", reverted)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Current != 1 || len(status.Pending) != 2 {
+		t.Fatalf("status after Down = current %d, pending %d", status.Current, len(status.Pending))
+	}
+}
+
+func TestRefusesDatabaseNewerThanBinary(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	if _, err := NewSQLite(db, testMigrations()).Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	older := NewSQLite(db, All())
+	if _, err := older.Up(ctx); !errors.Is(err, ErrDatabaseNewer) {
+		t.Fatalf("Up error = %v, want ErrDatabaseNewer", err)
+	}
+	if _, err := older.Down(ctx, 1); !errors.Is(err, ErrDatabaseNewer) {
+		t.Fatalf("Down error = %v, want ErrDatabaseNewer", err)
+	}
+	if _, err := older.Check(ctx); !errors.Is(err, ErrDatabaseNewer) {
+		t.Fatalf("Check error = %v, want ErrDatabaseNewer", err)
+	}
+	status, err := older.Status(ctx)
+	if err != nil || len(status.Unknown) != 2 {
+		t.Fatalf("Status = %+v, %v; want two unknown versions", status, err)
+	}
+}
+
+func TestCheck_ReportsPending(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := NewSQLite(db, All()).Check(context.Background()); !errors.Is(err, ErrPending) {
+		t.Fatalf("Check error = %v, want ErrPending", err)
+	}
+}
+
+func TestUp_FailedStepIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := NewSQLite(db, []Migration{
+		{Version: 1, Name: "ok", Up: Script{SQL: `CREATE TABLE ok (id TEXT)`}},
+		{Version: 2, Name: "broken", Up: Script{SQL: `CREATE TABLE broken (id TEXT); SELECT * FROM missing`}},
+	})
+
+	if _, err := migrator.Up(ctx); err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	if tableExists(t, db, "broken") {
+		t.Fatal("broken migration was not rolled back")
+	}
+	status, err := migrator.Status(ctx)
+	if err != nil || status.Current != 1 {
+		t.Fatalf("Status = %+v, %v; want version 1", status, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	db := openTestDB(t)
+	for name, migrations := range map[string][]Migration{
+		"zero version": {{Name: "a", Up: Script{SQL: "SELECT 1"}}},
+		"duplicate":    {{Version: 1, Name: "a", Up: Script{SQL: "SELECT 1"}}, {Version: 1, Name: "b", Up: Script{SQL: "SELECT 1"}}},
+		"no name":      {{Version: 1, Up: Script{SQL: "SELECT 1"}}},
+		"no up":        {{Version: 1, Name: "a"}},
+	} {
+		if _, err := NewSQLite(db, migrations).Up(context.Background()); err == nil {
+			t.Errorf("%s: Up succeeded", name)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	if got := rebind("INSERT INTO t VALUES (?, ?)"); got != "INSERT INTO t VALUES ($1, $2)" {
+		t.Fatalf("rebind = %q", got)
+	}
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+
+	"gomodel/internal/usagejobs"
+)
+
+// usageJobs is migration 0002. It adds the table that tracks the admin bulk
+// usage correction jobs.
+var usageJobs = Migration{
+	Version: 2,
+	Name:    "usage jobs",
+	Up: Script{Func: func(ctx context.Context, db DB) error {
+		if db.PostgreSQL != nil {
+			return usagejobs.CreatePostgreSQLSchema(ctx, db.PostgreSQL)
+		}
+		return usagejobs.CreateSQLiteSchema(ctx, db.SQLite)
+	}},
+	Down: Script{SQL: "DROP TABLE IF EXISTS usage_jobs"},
+}
@@ -0,0 +1,167 @@
+// Package modelgroups routes requests for "group:<name>" to the best
+// available member of an ordered model group.
+//
+// Members are tried in config order. A member is eligible while its provider
+// is healthy (circuit breaker closed, rate limits not exhausted, last
+// availability check passed) and it meets its optional latency and cost
+// conditions. When no member is eligible the first member serves the
+// request, so a group never fails harder than its preferred model would.
+package modelgroups
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// Group is one validated model group.
+type Group struct {
+	Name    string
+	Members []*Member
+}
+
+// Member is one candidate model of a group.
+type Member struct {
+	Model        string
+	Provider     string
+	MaxLatency   time.Duration
+	MaxCostPer1K float64
+
+	selections atomic.Int64
+}
+
+// Selector returns the selector a request routed to m resolves.
+func (m *Member) Selector() core.RequestedModelSelector {
+	return core.NewRequestedModelSelector(m.Model, m.Provider)
+}
+
+// Selections returns how many requests this gateway process routed to the
+// member since startup.
+func (m *Member) Selections() int64 {
+	return m.selections.Load()
+}
+
+// Service holds the configured model groups keyed by name.
+type Service struct {
+	groups []*Group
+	byName map[string]*Group
+}
+
+// New validates cfgs and builds a Service. It returns nil when no groups are
+// configured.
+func New(cfgs []config.ModelGroupConfig) (*Service, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+
+	s := &Service{byName: make(map[string]*Group, len(cfgs))}
+	for i, cfg := range cfgs {
+		group, err := newGroup(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("model_groups[%d]: %w", i, err)
+		}
+		if _, exists := s.byName[group.Name]; exists {
+			return nil, fmt.Errorf("model_groups[%d]: duplicate group name %q", i, group.Name)
+		}
+		s.byName[group.Name] = group
+		s.groups = append(s.groups, group)
+	}
+	return s, nil
+}
+
+func newGroup(cfg config.ModelGroupConfig) (*Group, error) {
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if strings.ContainsAny(name, " /:") {
+		return nil, fmt.Errorf("group %q: name must not contain spaces, '/' or ':'", name)
+	}
+	if len(cfg.Members) == 0 {
+		return nil, fmt.Errorf("group %q: at least one member is required", name)
+	}
+
+	group := &Group{Name: name}
+	seen := make(map[string]struct{}, len(cfg.Members))
+	for _, memberCfg := range cfg.Members {
+		member := &Member{
+			Model:        strings.TrimSpace(memberCfg.Model),
+			Provider:     strings.TrimSpace(memberCfg.Provider),
+			MaxLatency:   memberCfg.MaxLatency,
+			MaxCostPer1K: memberCfg.MaxCostPer1K,
+		}
+		if member.Model == "" {
+			return nil, fmt.Errorf("group %q: member model is required", name)
+		}
+		if IsGroupModel(member.Model) {
+			return nil, fmt.Errorf("group %q: member %q must not be another group", name, member.Model)
+		}
+		if member.MaxLatency < 0 || member.MaxCostPer1K < 0 {
+			return nil, fmt.Errorf("group %q: member %q has a negative condition", name, member.Model)
+		}
+		key := member.Selector().RequestedQualifiedModel()
+		if _, exists := seen[key]; exists {
+			return nil, fmt.Errorf("group %q: duplicate member %q", name, key)
+		}
+		seen[key] = struct{}{}
+		group.Members = append(group.Members, member)
+	}
+	return group, nil
+}
+
+// Groups returns the configured groups in config order.
+func (s *Service) Groups() []*Group {
+	if s == nil {
+		return nil
+	}
+	return s.groups
+}
+
+// Lookup returns the group called name, if any.
+func (s *Service) Lookup(name string) (*Group, bool) {
+	if s == nil {
+		return nil, false
+	}
+	group, ok := s.byName[strings.TrimSpace(name)]
+	return group, ok
+}
+
+// IsGroupModel reports whether model targets a model group.
+func IsGroupModel(model string) bool {
+	return strings.HasPrefix(strings.TrimSpace(model), core.ModelGroupPrefix)
+}
+
+// GroupName returns the group a requested model targets. ok is false when
+// model is not a group reference.
+func GroupName(model string) (string, bool) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(model), core.ModelGroupPrefix)
+	return strings.TrimSpace(name), ok
+}
+
+// ExposedModels lists the groups as public models for GET /v1/models.
+func (s *Service) ExposedModels() []core.Model {
+	if s == nil {
+		return nil
+	}
+	models := make([]core.Model, 0, len(s.groups))
+	for _, group := range s.groups {
+		members := make([]string, 0, len(group.Members))
+		for _, member := range group.Members {
+			members = append(members, member.Selector().RequestedQualifiedModel())
+		}
+		models = append(models, core.Model{
+			ID:      core.ModelGroupPrefix + group.Name,
+			Object:  "model",
+			OwnedBy: "gomodel",
+			Metadata: &core.ModelMetadata{
+				DisplayName: group.Name,
+				Description: "Model group routed to the first available of: " + strings.Join(members, ", "),
+			},
+		})
+	}
+	return models
+}
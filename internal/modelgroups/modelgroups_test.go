@@ -0,0 +1,122 @@
+package modelgroups
+
+import (
+	"strings"
+	"testing"
+
+	"gomodel/config"
+)
+
+func newTestService(t *testing.T, cfgs ...config.ModelGroupConfig) *Service {
+	t.Helper()
+	service, err := New(cfgs)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return service
+}
+
+func TestNew_NoGroups(t *testing.T) {
+	service, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if service != nil {
+		t.Fatalf("New(nil) = %+v, want nil", service)
+	}
+	if _, ok := service.Lookup("coding"); ok {
+		t.Fatal("nil service should not match any group")
+	}
+	if models := service.ExposedModels(); models != nil {
+		t.Fatalf("ExposedModels() = %v, want nil", models)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	member := config.ModelGroupMemberConfig{Model: "gpt-5"}
+
+	tests := []struct {
+		name    string
+		cfgs    []config.ModelGroupConfig
+		wantErr string
+	}{
+		{
+			name:    "missing name",
+			cfgs:    []config.ModelGroupConfig{{Members: []config.ModelGroupMemberConfig{member}}},
+			wantErr: "name is required",
+		},
+		{
+			name:    "name with separator",
+			cfgs:    []config.ModelGroupConfig{{Name: "a:b", Members: []config.ModelGroupMemberConfig{member}}},
+			wantErr: "must not contain",
+		},
+		{
+			name:    "no members",
+			cfgs:    []config.ModelGroupConfig{{Name: "coding"}},
+			wantErr: "at least one member",
+		},
+		{
+			name:    "member without model",
+			cfgs:    []config.ModelGroupConfig{{Name: "coding", Members: []config.ModelGroupMemberConfig{{Provider: "openai"}}}},
+			wantErr: "member model is required",
+		},
+		{
+			name:    "nested group",
+			cfgs:    []config.ModelGroupConfig{{Name: "coding", Members: []config.ModelGroupMemberConfig{{Model: "group:fast"}}}},
+			wantErr: "must not be another group",
+		},
+		{
+			name:    "negative condition",
+			cfgs:    []config.ModelGroupConfig{{Name: "coding", Members: []config.ModelGroupMemberConfig{{Model: "gpt-5", MaxCostPer1K: -1}}}},
+			wantErr: "negative condition",
+		},
+		{
+			name:    "duplicate member",
+			cfgs:    []config.ModelGroupConfig{{Name: "coding", Members: []config.ModelGroupMemberConfig{member, member}}},
+			wantErr: `duplicate member "gpt-5"`,
+		},
+		{
+			name: "duplicate group",
+			cfgs: []config.ModelGroupConfig{
+				{Name: "coding", Members: []config.ModelGroupMemberConfig{member}},
+				{Name: "coding", Members: []config.ModelGroupMemberConfig{member}},
+			},
+			wantErr: `model_groups[1]: duplicate group name "coding"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfgs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_ExposedModels(t *testing.T) {
+	service := newTestService(t, config.ModelGroupConfig{
+		Name: "coding",
+		Members: []config.ModelGroupMemberConfig{
+			{Model: "claude-sonnet-4", Provider: "anthropic"},
+			{Model: "gpt-5"},
+		},
+	})
+
+	models := service.ExposedModels()
+	if len(models) != 1 || models[0].ID != "group:coding" || models[0].Object != "model" {
+		t.Fatalf("ExposedModels() = %+v, want the coding group", models)
+	}
+	if desc := models[0].Metadata.Description; !strings.Contains(desc, "anthropic/claude-sonnet-4, gpt-5") {
+		t.Fatalf("description = %q, want the members in order", desc)
+	}
+}
+
+func TestGroupName(t *testing.T) {
+	if name, ok := GroupName(" group:coding "); !ok || name != "coding" {
+		t.Fatalf("GroupName() = %q, %v, want coding", name, ok)
+	}
+	if _, ok := GroupName("gpt-5"); ok {
+		t.Fatal("GroupName() matched a plain model")
+	}
+}
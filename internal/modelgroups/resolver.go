@@ -0,0 +1,217 @@
+package modelgroups
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gomodel/internal/core"
+	"gomodel/internal/scoreboard"
+)
+
+// latencyWindow is the scoreboard window a member's max_latency is checked
+// against.
+const latencyWindow = scoreboard.Window5m
+
+// ModelResolver is the selector resolver the group resolver wraps, usually
+// the experiment resolver or the alias service.
+type ModelResolver interface {
+	ResolveModel(requested core.RequestedModelSelector) (core.ModelSelector, bool, error)
+}
+
+type experimentAssigner interface {
+	AssignExperiment(ctx context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ExperimentAssignment)
+}
+
+// Registry reports which provider serves a model, whether it can take
+// traffic and what the model costs.
+type Registry interface {
+	Supports(model string) bool
+	GetProviderName(model string) string
+	GetProviderType(model string) string
+	ProviderHealth(providerName string, now time.Time) (bool, string)
+	ResolvePricing(model, providerType string) *core.ModelPricing
+}
+
+// LatencySource reports recent latency for a provider+model pair.
+type LatencySource interface {
+	Stats(provider, model string, window scoreboard.Window) (scoreboard.ModelStats, bool)
+}
+
+// Resolver routes model groups ahead of the wrapped model resolver. It
+// implements gateway.ModelGroupResolver and forwards experiment assignment,
+// so a group member may itself be an experiment or an alias.
+type Resolver struct {
+	service  *Service
+	next     ModelResolver
+	registry Registry
+	latency  LatencySource
+	now      func() time.Time
+}
+
+// NewResolver wraps next with the groups in service. latency may be nil, in
+// which case max_latency conditions always pass.
+func NewResolver(service *Service, next ModelResolver, registry Registry, latency LatencySource) *Resolver {
+	return &Resolver{service: service, next: next, registry: registry, latency: latency, now: time.Now}
+}
+
+// ResolveModel delegates to the wrapped resolver.
+func (r *Resolver) ResolveModel(requested core.RequestedModelSelector) (core.ModelSelector, bool, error) {
+	if r == nil || r.next == nil {
+		selector, err := requested.Normalize()
+		return selector, false, err
+	}
+	return r.next.ResolveModel(requested)
+}
+
+// AssignExperiment delegates to the wrapped resolver when it runs
+// experiments.
+func (r *Resolver) AssignExperiment(ctx context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ExperimentAssignment) {
+	if r == nil {
+		return requested, nil
+	}
+	if assigner, ok := r.next.(experimentAssigner); ok {
+		return assigner.AssignExperiment(ctx, requested)
+	}
+	return requested, nil
+}
+
+// ResolveModelGroup replaces a "group:<name>" selector with the selector of
+// the group's best available member. Other selectors are returned unchanged.
+func (r *Resolver) ResolveModelGroup(_ context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ModelGroupSelection, error) {
+	if r == nil {
+		return requested, nil, nil
+	}
+	name, ok := GroupName(requested.Model)
+	if !ok {
+		return requested, nil, nil
+	}
+	group, ok := r.service.Lookup(name)
+	if !ok {
+		return requested, nil, core.NewInvalidRequestError("unknown model group: "+name, nil)
+	}
+
+	resolution := r.Resolve(group)
+	member := resolution.selected
+	member.selections.Add(1)
+	return member.Selector(), &core.ModelGroupSelection{
+		Group:    group.Name,
+		Member:   resolution.Selected,
+		Fallback: resolution.Fallback,
+	}, nil
+}
+
+// Resolution is the current routing decision for a group.
+type Resolution struct {
+	Group string `json:"group"`
+	// Selected is the member requests for the group go to right now.
+	Selected string `json:"selected"`
+	// Fallback is set when no member is eligible and Selected is the first
+	// member regardless.
+	Fallback bool           `json:"fallback"`
+	Members  []MemberStatus `json:"members"`
+
+	selected *Member
+}
+
+// MemberStatus explains whether one member is eligible.
+type MemberStatus struct {
+	Model        string  `json:"model"`
+	ProviderName string  `json:"provider_name,omitempty"`
+	Eligible     bool    `json:"eligible"`
+	Reason       string  `json:"reason,omitempty"`
+	Selections   int64   `json:"selections"`
+	MaxLatencyMs int64   `json:"max_latency_ms,omitempty"`
+	MaxCostPer1K float64 `json:"max_cost_per_1k,omitempty"`
+}
+
+// Resolve evaluates every member of group in order and picks the first
+// eligible one.
+func (r *Resolver) Resolve(group *Group) Resolution {
+	now := r.now()
+	resolution := Resolution{Group: group.Name, Members: make([]MemberStatus, 0, len(group.Members))}
+	for _, member := range group.Members {
+		status := r.memberStatus(member, now)
+		if status.Eligible && resolution.selected == nil {
+			resolution.selected = member
+			resolution.Selected = status.Model
+		}
+		resolution.Members = append(resolution.Members, status)
+	}
+	if resolution.selected == nil {
+		resolution.selected = group.Members[0]
+		resolution.Selected = resolution.Members[0].Model
+		resolution.Fallback = true
+	}
+	return resolution
+}
+
+// Resolutions returns the current resolution of every configured group.
+func (r *Resolver) Resolutions() []Resolution {
+	if r == nil {
+		return nil
+	}
+	groups := r.service.Groups()
+	result := make([]Resolution, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, r.Resolve(group))
+	}
+	return result
+}
+
+func (r *Resolver) memberStatus(member *Member, now time.Time) MemberStatus {
+	status := MemberStatus{
+		Model:        member.Selector().RequestedQualifiedModel(),
+		Selections:   member.Selections(),
+		MaxLatencyMs: member.MaxLatency.Milliseconds(),
+		MaxCostPer1K: member.MaxCostPer1K,
+	}
+	selector, _, err := r.ResolveModel(member.Selector())
+	if err != nil {
+		status.Reason = err.Error()
+		return status
+	}
+	model := selector.QualifiedModel()
+	if r.registry == nil {
+		status.Eligible = true
+		return status
+	}
+	if !r.registry.Supports(model) {
+		status.Reason = "model not available"
+		return status
+	}
+	status.ProviderName = r.registry.GetProviderName(model)
+	if healthy, reason := r.registry.ProviderHealth(status.ProviderName, now); !healthy {
+		status.Reason = reason
+		return status
+	}
+	if member.MaxLatency > 0 && r.latency != nil {
+		if stats, ok := r.latency.Stats(status.ProviderName, selector.Model, latencyWindow); ok && stats.LatencyP50Ms > float64(member.MaxLatency.Milliseconds()) {
+			status.Reason = fmt.Sprintf("median latency %.0fms exceeds %s", stats.LatencyP50Ms, member.MaxLatency)
+			return status
+		}
+	}
+	if member.MaxCostPer1K > 0 {
+		if cost, ok := costPer1K(r.registry.ResolvePricing(selector.Model, r.registry.GetProviderType(model))); ok && cost > member.MaxCostPer1K {
+			status.Reason = fmt.Sprintf("cost %g per 1K tokens exceeds %g", cost, member.MaxCostPer1K)
+			return status
+		}
+	}
+	status.Eligible = true
+	return status
+}
+
+// costPer1K returns the higher of the input and output prices per 1K tokens.
+func costPer1K(pricing *core.ModelPricing) (float64, bool) {
+	if pricing == nil || (pricing.InputPerMtok == nil && pricing.OutputPerMtok == nil) {
+		return 0, false
+	}
+	var perMtok float64
+	if pricing.InputPerMtok != nil {
+		perMtok = *pricing.InputPerMtok
+	}
+	if pricing.OutputPerMtok != nil {
+		perMtok = max(perMtok, *pricing.OutputPerMtok)
+	}
+	return perMtok / 1000, true
+}
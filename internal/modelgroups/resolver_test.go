@@ -0,0 +1,151 @@
+package modelgroups
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/scoreboard"
+)
+
+// fakeRegistry serves every model and maps it to the provider named in its
+// qualified selector.
+type fakeRegistry struct {
+	unhealthy map[string]string
+	pricing   map[string]*core.ModelPricing
+}
+
+func (r *fakeRegistry) Supports(model string) bool {
+	return !strings.Contains(model, "missing")
+}
+
+func (r *fakeRegistry) GetProviderName(model string) string {
+	provider, _, _ := strings.Cut(model, "/")
+	return provider
+}
+
+func (r *fakeRegistry) GetProviderType(model string) string {
+	return r.GetProviderName(model)
+}
+
+func (r *fakeRegistry) ProviderHealth(providerName string, _ time.Time) (bool, string) {
+	if reason, ok := r.unhealthy[providerName]; ok {
+		return false, reason
+	}
+	return true, ""
+}
+
+func (r *fakeRegistry) ResolvePricing(model, _ string) *core.ModelPricing {
+	return r.pricing[model]
+}
+
+type fakeLatency map[string]float64
+
+func (l fakeLatency) Stats(provider, model string, _ scoreboard.Window) (scoreboard.ModelStats, bool) {
+	p50, ok := l[provider+"/"+model]
+	return scoreboard.ModelStats{LatencyP50Ms: p50}, ok
+}
+
+func codingGroup(members ...config.ModelGroupMemberConfig) config.ModelGroupConfig {
+	if len(members) == 0 {
+		members = []config.ModelGroupMemberConfig{
+			{Model: "claude-sonnet-4", Provider: "anthropic"},
+			{Model: "gpt-5", Provider: "openai"},
+			{Model: "gemini-2.5-pro", Provider: "gemini"},
+		}
+	}
+	return config.ModelGroupConfig{Name: "coding", Members: members}
+}
+
+func resolveGroup(t *testing.T, resolver *Resolver) (core.RequestedModelSelector, *core.ModelGroupSelection) {
+	t.Helper()
+	selector, selection, err := resolver.ResolveModelGroup(context.Background(), core.NewRequestedModelSelector("group:coding", ""))
+	if err != nil {
+		t.Fatalf("ResolveModelGroup() error = %v", err)
+	}
+	return selector, selection
+}
+
+func TestResolver_FollowsProviderHealth(t *testing.T) {
+	registry := &fakeRegistry{unhealthy: map[string]string{}}
+	resolver := NewResolver(newTestService(t, codingGroup()), nil, registry, nil)
+
+	steps := []struct {
+		unhealthy    map[string]string
+		wantSelector core.RequestedModelSelector
+		wantFallback bool
+	}{
+		{nil, core.NewRequestedModelSelector("claude-sonnet-4", "anthropic"), false},
+		{map[string]string{"anthropic": "circuit breaker open"}, core.NewRequestedModelSelector("gpt-5", "openai"), false},
+		{map[string]string{"anthropic": "circuit breaker open", "openai": "rate limited"}, core.NewRequestedModelSelector("gemini-2.5-pro", "gemini"), false},
+		{map[string]string{"openai": "rate limited"}, core.NewRequestedModelSelector("claude-sonnet-4", "anthropic"), false},
+		{map[string]string{"anthropic": "x", "openai": "x", "gemini": "x"}, core.NewRequestedModelSelector("claude-sonnet-4", "anthropic"), true},
+	}
+	for i, step := range steps {
+		registry.unhealthy = step.unhealthy
+		selector, selection := resolveGroup(t, resolver)
+		if selector != step.wantSelector {
+			t.Fatalf("step %d: selector = %+v, want %+v", i, selector, step.wantSelector)
+		}
+		if selection.Group != "coding" || selection.Member != step.wantSelector.RequestedQualifiedModel() || selection.Fallback != step.wantFallback {
+			t.Fatalf("step %d: selection = %+v", i, selection)
+		}
+	}
+
+	group, _ := resolver.service.Lookup("coding")
+	if got := group.Members[0].Selections(); got != 3 {
+		t.Fatalf("first member selections = %d, want 3", got)
+	}
+}
+
+func TestResolver_MemberConditions(t *testing.T) {
+	expensive := 60.0
+	cheap := 0.5
+	registry := &fakeRegistry{pricing: map[string]*core.ModelPricing{
+		"claude-opus-4": {InputPerMtok: &cheap, OutputPerMtok: &expensive},
+		"gpt-5-mini":    {InputPerMtok: &cheap, OutputPerMtok: &cheap},
+	}}
+	latency := fakeLatency{"openai/gpt-5": 9000}
+	service := newTestService(t, codingGroup(
+		config.ModelGroupMemberConfig{Model: "missing-model", Provider: "openai"},
+		config.ModelGroupMemberConfig{Model: "claude-opus-4", Provider: "anthropic", MaxCostPer1K: 0.01},
+		config.ModelGroupMemberConfig{Model: "gpt-5", Provider: "openai", MaxLatency: 5 * time.Second},
+		config.ModelGroupMemberConfig{Model: "gpt-5-mini", Provider: "openai", MaxLatency: 5 * time.Second, MaxCostPer1K: 0.01},
+	))
+	resolver := NewResolver(service, nil, registry, latency)
+
+	group, _ := service.Lookup("coding")
+	resolution := resolver.Resolve(group)
+	if resolution.Selected != "openai/gpt-5-mini" || resolution.Fallback {
+		t.Fatalf("resolution = %+v, want gpt-5-mini", resolution)
+	}
+	wantReasons := []string{"model not available", "cost 0.06 per 1K tokens exceeds 0.01", "median latency 9000ms exceeds 5s", ""}
+	for i, want := range wantReasons {
+		if got := resolution.Members[i]; got.Reason != want || got.Eligible != (want == "") {
+			t.Fatalf("member %d = %+v, want reason %q", i, got, want)
+		}
+	}
+
+	delete(latency, "openai/gpt-5")
+	if resolution := resolver.Resolve(group); resolution.Selected != "openai/gpt-5" {
+		t.Fatalf("selected = %q, want gpt-5 once its latency has no data", resolution.Selected)
+	}
+}
+
+func TestResolver_PassesThroughOtherModels(t *testing.T) {
+	resolver := NewResolver(newTestService(t, codingGroup()), nil, &fakeRegistry{}, nil)
+
+	requested := core.NewRequestedModelSelector("gpt-5", "openai")
+	selector, selection, err := resolver.ResolveModelGroup(context.Background(), requested)
+	if err != nil || selection != nil || selector != requested {
+		t.Fatalf("ResolveModelGroup() = %+v, %+v, %v; want the request unchanged", selector, selection, err)
+	}
+
+	_, _, err = resolver.ResolveModelGroup(context.Background(), core.NewRequestedModelSelector("group:unknown", ""))
+	if err == nil || !strings.Contains(err.Error(), "unknown model group: unknown") {
+		t.Fatalf("ResolveModelGroup() error = %v, want unknown model group", err)
+	}
+}
@@ -25,6 +25,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the model_overrides table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS model_overrides (
 			selector TEXT PRIMARY KEY,
@@ -36,21 +47,21 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides table: %w", err)
+		return fmt.Errorf("failed to create model_overrides table: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `ALTER TABLE model_overrides ADD COLUMN IF NOT EXISTS user_paths JSONB NOT NULL DEFAULT '[]'::jsonb`); err != nil {
-		return nil, fmt.Errorf("failed to migrate model_overrides user_paths column: %w", err)
+		return fmt.Errorf("failed to migrate model_overrides user_paths column: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_provider_name ON model_overrides(provider_name)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides provider_name index: %w", err)
+		return fmt.Errorf("failed to create model_overrides provider_name index: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_model ON model_overrides(model)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides model index: %w", err)
+		return fmt.Errorf("failed to create model_overrides model index: %w", err)
 	}
 	if _, err := pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_updated_at ON model_overrides(updated_at DESC)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides updated_at index: %w", err)
+		return fmt.Errorf("failed to create model_overrides updated_at index: %w", err)
 	}
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 func (s *PostgreSQLStore) List(ctx context.Context) ([]Override, error) {
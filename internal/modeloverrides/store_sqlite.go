@@ -20,7 +20,18 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
-	_, err := db.Exec(`
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the model_overrides table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS model_overrides (
 			selector TEXT PRIMARY KEY,
 			provider_name TEXT NOT NULL DEFAULT '',
@@ -31,21 +42,21 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides table: %w", err)
+		return fmt.Errorf("failed to create model_overrides table: %w", err)
 	}
-	if _, err := db.Exec(`ALTER TABLE model_overrides ADD COLUMN user_paths TEXT NOT NULL DEFAULT '[]'`); err != nil && !isSQLiteDuplicateColumnError(err) {
-		return nil, fmt.Errorf("failed to migrate model_overrides user_paths column: %w", err)
+	if _, err := db.ExecContext(ctx, `ALTER TABLE model_overrides ADD COLUMN user_paths TEXT NOT NULL DEFAULT '[]'`); err != nil && !isSQLiteDuplicateColumnError(err) {
+		return fmt.Errorf("failed to migrate model_overrides user_paths column: %w", err)
 	}
-	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_model_overrides_provider_name ON model_overrides(provider_name)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides provider_name index: %w", err)
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_provider_name ON model_overrides(provider_name)`); err != nil {
+		return fmt.Errorf("failed to create model_overrides provider_name index: %w", err)
 	}
-	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_model_overrides_model ON model_overrides(model)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides model index: %w", err)
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_model ON model_overrides(model)`); err != nil {
+		return fmt.Errorf("failed to create model_overrides model index: %w", err)
 	}
-	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_model_overrides_updated_at ON model_overrides(updated_at DESC)`); err != nil {
-		return nil, fmt.Errorf("failed to create model_overrides updated_at index: %w", err)
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_model_overrides_updated_at ON model_overrides(updated_at DESC)`); err != nil {
+		return fmt.Errorf("failed to create model_overrides updated_at index: %w", err)
 	}
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 func (s *SQLiteStore) List(ctx context.Context) ([]Override, error) {
@@ -51,6 +51,98 @@ var (
 		},
 		[]string{"provider", "provider_name", "operation"},
 	)
+
+	// DeferredQueueDepth tracks stored deferred requests by status
+	DeferredQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gomodel_deferred_requests",
+			Help: "Number of stored deferred requests by status",
+		},
+		[]string{"status"},
+	)
+
+	// StreamBufferHighWaterBytes records the most bytes a streamed response
+	// held in its per-connection send buffer
+	StreamBufferHighWaterBytes = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gomodel_stream_buffer_high_water_bytes",
+			Help:    "Peak bytes buffered per streamed response while waiting for the client",
+			Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+		},
+		[]string{"endpoint"},
+	)
+
+	// StreamBackpressureEvents counts streams whose send buffer stayed full past
+	// the stall timeout, by the resulting event (stall or terminated)
+	StreamBackpressureEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_stream_backpressure_events_total",
+			Help: "Total number of streamed responses stalled or terminated by a slow client",
+		},
+		[]string{"endpoint", "event"},
+	)
+
+	// StreamChunkGapSeconds records the time between consecutive upstream
+	// chunks of streamed responses
+	StreamChunkGapSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gomodel_stream_chunk_gap_seconds",
+			Help:    "Time between consecutive chunks of provider streams in seconds",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+		},
+		[]string{"provider", "model"},
+	)
+
+	// StreamUpstreamStalls counts inter-chunk gaps of provider streams at or
+	// above the chunk stall threshold
+	StreamUpstreamStalls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_stream_upstream_stalls_total",
+			Help: "Total number of provider stream chunk gaps at or above the stall threshold",
+		},
+		[]string{"provider", "model"},
+	)
+
+	// StreamLimitExceeded counts streamed responses ended by the stream size
+	// or duration limit, by the limit that fired (bytes or duration)
+	StreamLimitExceeded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_stream_limit_exceeded_total",
+			Help: "Total number of streamed responses ended by the stream size or duration limit",
+		},
+		[]string{"endpoint", "limit"},
+	)
+
+	// UpstreamConnections counts the upstream connections request attempts
+	// got, by whether they were reused from the idle pool
+	UpstreamConnections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_upstream_connections_total",
+			Help: "Total number of upstream connections used by provider requests, new or reused",
+		},
+		[]string{"provider", "reused"},
+	)
+
+	// SecretDetections counts the secrets secret redaction found in request
+	// content, by detector and the action it took
+	SecretDetections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_secret_detections_total",
+			Help: "Total number of secrets found in request content by secret redaction",
+		},
+		[]string{"detector", "action"},
+	)
+
+	// UpstreamTLSHandshakeSeconds records the TLS handshake duration of new
+	// upstream connections
+	UpstreamTLSHandshakeSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "gomodel_upstream_tls_handshake_seconds",
+			Help:    "TLS handshake duration of new upstream connections in seconds",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		},
+		[]string{"provider"},
+	)
 )
 
 // NewPrometheusHooks returns hooks that instrument LLM requests with Prometheus metrics.
@@ -111,6 +203,12 @@ func NewPrometheusHooks() llmclient.Hooks {
 				streamLabel,
 			).Observe(info.Duration.Seconds())
 		},
+		OnConnection: func(_ context.Context, info llmclient.ConnectionInfo) {
+			UpstreamConnections.WithLabelValues(info.Provider, strconv.FormatBool(info.Reused)).Inc()
+			if info.TLSHandshake > 0 {
+				UpstreamTLSHandshakeSeconds.WithLabelValues(info.Provider).Observe(info.TLSHandshake.Seconds())
+			}
+		},
 	}
 }
 
@@ -127,6 +225,13 @@ func NewPrometheusHooks() llmclient.Hooks {
 //
 // Concurrent requests:
 //   gomodel_requests_in_flight
+//
+// Deferred requests waiting for replay:
+//   gomodel_deferred_requests{status="queued"}
+//
+// Share of provider requests that opened a new connection:
+//   sum(rate(gomodel_upstream_connections_total{reused="false"}[5m])) by (provider)
+//     / sum(rate(gomodel_upstream_connections_total[5m])) by (provider)
 
 // Example Grafana dashboard queries:
 //
@@ -151,6 +256,15 @@ type PrometheusMetrics struct {
 	RequestDuration               *prometheus.HistogramVec
 	InFlightRequests              *prometheus.GaugeVec
 	ResponseSnapshotStoreFailures *prometheus.CounterVec
+	DeferredQueueDepth            *prometheus.GaugeVec
+	StreamBufferHighWaterBytes    *prometheus.HistogramVec
+	StreamBackpressureEvents      *prometheus.CounterVec
+	StreamChunkGapSeconds         *prometheus.HistogramVec
+	StreamUpstreamStalls          *prometheus.CounterVec
+	StreamLimitExceeded           *prometheus.CounterVec
+	UpstreamConnections           *prometheus.CounterVec
+	UpstreamTLSHandshakeSeconds   *prometheus.HistogramVec
+	SecretDetections              *prometheus.CounterVec
 }
 
 // GetMetrics returns the prometheus metrics for testing and introspection
@@ -160,6 +274,15 @@ func GetMetrics() *PrometheusMetrics {
 		RequestDuration:               RequestDuration,
 		InFlightRequests:              InFlightRequests,
 		ResponseSnapshotStoreFailures: ResponseSnapshotStoreFailures,
+		DeferredQueueDepth:            DeferredQueueDepth,
+		StreamBufferHighWaterBytes:    StreamBufferHighWaterBytes,
+		StreamBackpressureEvents:      StreamBackpressureEvents,
+		StreamChunkGapSeconds:         StreamChunkGapSeconds,
+		StreamUpstreamStalls:          StreamUpstreamStalls,
+		StreamLimitExceeded:           StreamLimitExceeded,
+		UpstreamConnections:           UpstreamConnections,
+		UpstreamTLSHandshakeSeconds:   UpstreamTLSHandshakeSeconds,
+		SecretDetections:              SecretDetections,
 	}
 }
 
@@ -169,6 +292,15 @@ func ResetMetrics() {
 	RequestDuration.Reset()
 	InFlightRequests.Reset()
 	ResponseSnapshotStoreFailures.Reset()
+	DeferredQueueDepth.Reset()
+	StreamBufferHighWaterBytes.Reset()
+	StreamBackpressureEvents.Reset()
+	StreamChunkGapSeconds.Reset()
+	StreamUpstreamStalls.Reset()
+	StreamLimitExceeded.Reset()
+	UpstreamConnections.Reset()
+	UpstreamTLSHandshakeSeconds.Reset()
+	SecretDetections.Reset()
 }
 
 // HealthCheck verifies that metrics are being collected
@@ -344,6 +344,26 @@ func TestRequestDuration(t *testing.T) {
 	}
 }
 
+func TestConnectionMetrics(t *testing.T) {
+	ResetMetrics()
+
+	hooks := NewPrometheusHooks()
+	ctx := context.Background()
+	hooks.OnConnection(ctx, llmclient.ConnectionInfo{Provider: "openai", TLSHandshake: 40 * time.Millisecond})
+	hooks.OnConnection(ctx, llmclient.ConnectionInfo{Provider: "openai", Reused: true})
+	hooks.OnConnection(ctx, llmclient.ConnectionInfo{Provider: "openai", Reused: true})
+
+	if got := testutil.ToFloat64(UpstreamConnections.WithLabelValues("openai", "true")); got != 2 {
+		t.Errorf("reused connections = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(UpstreamConnections.WithLabelValues("openai", "false")); got != 1 {
+		t.Errorf("new connections = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(UpstreamTLSHandshakeSeconds); got != 1 {
+		t.Errorf("handshake series = %d, want one for the new connection", got)
+	}
+}
+
 func TestHealthCheck(t *testing.T) {
 	// Reset metrics before test
 	ResetMetrics()
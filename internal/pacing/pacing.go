@@ -0,0 +1,325 @@
+// Package pacing spaces out upstream requests to a provider with token
+// buckets. A request that finds its bucket empty queues until enough has
+// refilled, up to a bounded wait, so a burst of client traffic reaches the
+// provider at a steady rate instead of tripping its rate limits.
+package pacing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// ErrTimeout is returned by Wait when a request would queue longer than the
+// configured max wait and the timeout policy is to fail.
+var ErrTimeout = errors.New("pacing queue wait would exceed max_wait")
+
+// Snapshot is the current state of a Pacer.
+type Snapshot struct {
+	Requests *BucketSnapshot `json:"requests,omitempty"`
+	Tokens   *BucketSnapshot `json:"tokens,omitempty"`
+	// MaxWaitMs and OnTimeout echo the configured queueing bounds.
+	MaxWaitMs int64  `json:"max_wait_ms"`
+	OnTimeout string `json:"on_timeout"`
+	// Waiting is the number of requests queued right now.
+	Waiting int `json:"waiting"`
+	// Delayed and Rejected count requests that queued, or were refused
+	// because they would have queued too long, since startup.
+	Delayed  int64 `json:"delayed"`
+	Rejected int64 `json:"rejected"`
+}
+
+// BucketSnapshot is the fill level of one token bucket.
+type BucketSnapshot struct {
+	// RatePerSecond is the refill rate in effect, which is below
+	// ConfiguredRatePerSecond while adapted to provider rate-limit headers.
+	RatePerSecond           float64 `json:"rate_per_second"`
+	ConfiguredRatePerSecond float64 `json:"configured_rate_per_second"`
+	Burst                   float64 `json:"burst"`
+	// Available is negative while queued requests have reserved more than
+	// the bucket holds.
+	Available float64 `json:"available"`
+	// Fill is Available over Burst, clamped to [0, 1].
+	Fill         float64    `json:"fill"`
+	AdaptedUntil *time.Time `json:"adapted_until,omitempty"`
+}
+
+// Pacer holds the token buckets of one provider. A nil *Pacer never delays.
+type Pacer struct {
+	mu        sync.Mutex
+	requests  *bucket
+	tokens    *bucket
+	maxWait   time.Duration
+	onTimeout string
+	adapt     bool
+	waiting   int
+	delayed   int64
+	rejected  int64
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New returns a Pacer for cfg, or nil when cfg is nil or sets no rate.
+func New(cfg *config.PacingConfig) *Pacer {
+	return newWithClock(cfg, time.Now, sleepContext)
+}
+
+func newWithClock(cfg *config.PacingConfig, now func() time.Time, sleep func(context.Context, time.Duration) error) *Pacer {
+	if cfg == nil || (cfg.RequestsPerSecond <= 0 && cfg.TokensPerSecond <= 0) {
+		return nil
+	}
+	p := &Pacer{
+		maxWait:   cfg.MaxWait,
+		onTimeout: cfg.OnTimeout,
+		adapt:     cfg.AdaptToRateLimits,
+		now:       now,
+		sleep:     sleep,
+	}
+	if p.maxWait <= 0 {
+		p.maxWait = config.DefaultPacingMaxWait
+	}
+	if p.onTimeout == "" {
+		p.onTimeout = config.PacingOnTimeoutProceed
+	}
+	started := now()
+	if cfg.RequestsPerSecond > 0 {
+		burst := float64(cfg.Burst)
+		if burst <= 0 {
+			burst = math.Max(1, math.Ceil(cfg.RequestsPerSecond))
+		}
+		p.requests = newBucket(cfg.RequestsPerSecond, burst, started)
+	}
+	if cfg.TokensPerSecond > 0 {
+		burst := float64(cfg.TokenBurst)
+		if burst <= 0 {
+			burst = math.Max(1, math.Ceil(cfg.TokensPerSecond))
+		}
+		p.tokens = newBucket(cfg.TokensPerSecond, burst, started)
+	}
+	return p
+}
+
+// PacesTokens reports whether Wait uses its token estimate, so callers can
+// skip estimating when it does not.
+func (p *Pacer) PacesTokens() bool {
+	return p != nil && p.tokens != nil
+}
+
+// Wait queues one request carrying an estimated tokens prompt tokens until
+// the buckets allow it, and returns how long it waited. A request that would
+// wait longer than the max wait either proceeds after it or fails with
+// ErrTimeout without consuming anything. A canceled ctx ends the wait early
+// with ctx's error.
+func (p *Pacer) Wait(ctx context.Context, tokens int) (time.Duration, error) {
+	if p == nil {
+		return 0, nil
+	}
+
+	p.mu.Lock()
+	now := p.now()
+	p.requests.advance(now)
+	p.tokens.advance(now)
+	delay := max(p.requests.delayFor(1), p.tokens.delayFor(float64(tokens)))
+	if delay > p.maxWait {
+		if p.onTimeout == config.PacingOnTimeoutFail {
+			p.rejected++
+			p.mu.Unlock()
+			return 0, ErrTimeout
+		}
+		delay = p.maxWait
+	}
+	// Debt is capped at what refills within the max wait, so sustained
+	// overload under the proceed policy cannot push later requests past it.
+	p.requests.take(1, p.maxWait)
+	p.tokens.take(float64(tokens), p.maxWait)
+	if delay <= 0 {
+		p.mu.Unlock()
+		return 0, nil
+	}
+	p.delayed++
+	p.waiting++
+	p.mu.Unlock()
+
+	err := p.sleep(ctx, delay)
+
+	p.mu.Lock()
+	p.waiting--
+	p.mu.Unlock()
+	return delay, err
+}
+
+// ObserveRateLimits lowers the bucket rates to spread what the provider
+// reports as remaining evenly until its window resets. It has no effect
+// unless the pacer adapts to rate limits, and never raises a rate above its
+// configured value.
+func (p *Pacer) ObserveRateLimits(limits core.RateLimits) {
+	if p == nil || !p.adapt {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.requests.adaptTo(limits.Requests, now)
+	tokenWindow := limits.InputTokens
+	if tokenWindow == nil {
+		tokenWindow = limits.Tokens
+	}
+	p.tokens.adaptTo(tokenWindow, now)
+}
+
+// Snapshot returns the current fill levels and counters.
+func (p *Pacer) Snapshot() *Snapshot {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.requests.advance(now)
+	p.tokens.advance(now)
+	return &Snapshot{
+		Requests:  p.requests.snapshot(),
+		Tokens:    p.tokens.snapshot(),
+		MaxWaitMs: p.maxWait.Milliseconds(),
+		OnTimeout: p.onTimeout,
+		Waiting:   p.waiting,
+		Delayed:   p.delayed,
+		Rejected:  p.rejected,
+	}
+}
+
+// bucket is a token bucket whose level may go negative while queued
+// requests hold reservations. A nil *bucket allows everything.
+type bucket struct {
+	rate      float64
+	burst     float64
+	available float64
+	last      time.Time
+
+	// adaptedRate replaces rate until adaptedUntil when it is positive.
+	adaptedRate  float64
+	adaptedUntil time.Time
+}
+
+func newBucket(rate, burst float64, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: burst, available: burst, last: now}
+}
+
+// currentRate is the refill rate in effect.
+func (b *bucket) currentRate() float64 {
+	if b.adaptedRate > 0 {
+		return b.adaptedRate
+	}
+	return b.rate
+}
+
+// advance refills the bucket up to now, switching back to the configured
+// rate where an adaptation expires.
+func (b *bucket) advance(now time.Time) {
+	if b == nil || !now.After(b.last) {
+		return
+	}
+	from := b.last
+	if b.adaptedRate > 0 {
+		until := now
+		if b.adaptedUntil.Before(now) {
+			until = b.adaptedUntil
+		}
+		if until.After(from) {
+			b.available += until.Sub(from).Seconds() * b.adaptedRate
+			from = until
+		}
+		if !now.Before(b.adaptedUntil) {
+			b.adaptedRate = 0
+		}
+	}
+	if now.After(from) {
+		b.available += now.Sub(from).Seconds() * b.rate
+	}
+	b.available = math.Min(b.available, b.burst)
+	b.last = now
+}
+
+// delayFor returns how long n must wait for the bucket to hold it.
+func (b *bucket) delayFor(n float64) time.Duration {
+	if b == nil || n <= 0 {
+		return 0
+	}
+	missing := n - b.available
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.currentRate() * float64(time.Second))
+}
+
+// take reserves n, keeping the debt within what refills in maxWait.
+func (b *bucket) take(n float64, maxWait time.Duration) {
+	if b == nil || n <= 0 {
+		return
+	}
+	b.available -= n
+	floor := -b.currentRate() * maxWait.Seconds()
+	if b.available < floor {
+		b.available = floor
+	}
+}
+
+// adaptTo derives a rate from a reported rate-limit window. A window with
+// nothing reported, or one that already reset, ends any adaptation.
+func (b *bucket) adaptTo(window *core.RateLimitWindow, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.advance(now)
+	if window == nil || window.Remaining == nil || window.ResetAt == nil || !window.ResetAt.After(now) {
+		b.adaptedRate = 0
+		return
+	}
+	// Leave at least one unit for the reset instant so the rate never
+	// reaches zero.
+	remaining := math.Max(float64(*window.Remaining), 1)
+	rate := remaining / window.ResetAt.Sub(now).Seconds()
+	if rate >= b.rate {
+		b.adaptedRate = 0
+		return
+	}
+	b.adaptedRate = rate
+	b.adaptedUntil = *window.ResetAt
+}
+
+func (b *bucket) snapshot() *BucketSnapshot {
+	if b == nil {
+		return nil
+	}
+	s := &BucketSnapshot{
+		RatePerSecond:           b.currentRate(),
+		ConfiguredRatePerSecond: b.rate,
+		Burst:                   b.burst,
+		Available:               b.available,
+		Fill:                    math.Max(0, math.Min(1, b.available/b.burst)),
+	}
+	if b.adaptedRate > 0 {
+		until := b.adaptedUntil.UTC()
+		s.AdaptedUntil = &until
+	}
+	return s
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+package pacing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// fakeClock drives a pacer deterministically: sleeping advances the clock
+// instead of blocking.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return ctx.Err()
+}
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestPacer(t *testing.T, cfg config.PacingConfig, clock *fakeClock) *Pacer {
+	t.Helper()
+	if err := config.ValidatePacingConfig(&cfg); err != nil {
+		t.Fatalf("ValidatePacingConfig() error = %v", err)
+	}
+	return newWithClock(&cfg, clock.Now, clock.Sleep)
+}
+
+func TestNew_DisabledWithoutRates(t *testing.T) {
+	if New(nil) != nil || New(&config.PacingConfig{}) != nil {
+		t.Fatal("New() without a rate returned a pacer")
+	}
+	var p *Pacer
+	if delay, err := p.Wait(context.Background(), 100); delay != 0 || err != nil {
+		t.Fatalf("nil Wait() = %s, %v; want no delay", delay, err)
+	}
+	if p.Snapshot() != nil || p.PacesTokens() {
+		t.Fatal("nil pacer reported state")
+	}
+}
+
+func TestWait_SmoothsBurstToConfiguredRate(t *testing.T) {
+	clock := newFakeClock()
+	p := newTestPacer(t, config.PacingConfig{RequestsPerSecond: 10, Burst: 2}, clock)
+
+	var delays []time.Duration
+	for range 5 {
+		delay, err := p.Wait(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+		delays = append(delays, delay)
+	}
+	// The burst goes through, then each request waits for its own refill.
+	want := []time.Duration{0, 0, 100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("delays = %v, want %v", delays, want)
+		}
+	}
+
+	snapshot := p.Snapshot()
+	if snapshot.Delayed != 3 || snapshot.Waiting != 0 || snapshot.Requests.Fill != 0 {
+		t.Fatalf("snapshot = %+v, want 3 delayed and an empty bucket", snapshot)
+	}
+	clock.Advance(time.Second)
+	if fill := p.Snapshot().Requests.Fill; fill != 1 {
+		t.Fatalf("fill after a second = %v, want 1", fill)
+	}
+}
+
+func TestWait_ConcurrentReservationsQueueBehindEachOther(t *testing.T) {
+	clock := newFakeClock()
+	// Sleeps do not advance the clock, as if all requests arrived at once.
+	p := newWithClock(&config.PacingConfig{RequestsPerSecond: 4, Burst: 1, MaxWait: time.Second}, clock.Now,
+		func(context.Context, time.Duration) error { return nil })
+
+	var delays []time.Duration
+	for range 4 {
+		delay, _ := p.Wait(context.Background(), 0)
+		delays = append(delays, delay)
+	}
+	want := []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, 750 * time.Millisecond}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("delays = %v, want %v", delays, want)
+		}
+	}
+	if available := p.Snapshot().Requests.Available; available != -3 {
+		t.Fatalf("available = %v, want -3 for three queued reservations", available)
+	}
+}
+
+func TestWait_TimeoutPolicies(t *testing.T) {
+	noSleep := func(context.Context, time.Duration) error { return nil }
+
+	t.Run("fail", func(t *testing.T) {
+		clock := newFakeClock()
+		p := newWithClock(&config.PacingConfig{RequestsPerSecond: 1, Burst: 1, MaxWait: 1500 * time.Millisecond, OnTimeout: config.PacingOnTimeoutFail}, clock.Now, noSleep)
+		for _, want := range []time.Duration{0, time.Second} {
+			if delay, err := p.Wait(context.Background(), 0); err != nil || delay != want {
+				t.Fatalf("Wait() = %s, %v; want %s", delay, err, want)
+			}
+		}
+		if _, err := p.Wait(context.Background(), 0); !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Wait() error = %v, want ErrTimeout", err)
+		}
+		snapshot := p.Snapshot()
+		if snapshot.Rejected != 1 || snapshot.Requests.Available != -1 {
+			t.Fatalf("snapshot = %+v, want one rejection that reserved nothing", snapshot.Requests)
+		}
+	})
+
+	t.Run("proceed", func(t *testing.T) {
+		clock := newFakeClock()
+		p := newWithClock(&config.PacingConfig{RequestsPerSecond: 1, Burst: 1, MaxWait: 1500 * time.Millisecond, OnTimeout: config.PacingOnTimeoutProceed}, clock.Now, noSleep)
+		var delays []time.Duration
+		for range 5 {
+			delay, err := p.Wait(context.Background(), 0)
+			if err != nil {
+				t.Fatalf("Wait() error = %v", err)
+			}
+			delays = append(delays, delay)
+		}
+		want := []time.Duration{0, time.Second, 1500 * time.Millisecond, 1500 * time.Millisecond, 1500 * time.Millisecond}
+		for i := range want {
+			if delays[i] != want[i] {
+				t.Fatalf("delays = %v, want %v", delays, want)
+			}
+		}
+	})
+}
+
+func TestWait_TokenBucketUsesPromptEstimate(t *testing.T) {
+	clock := newFakeClock()
+	p := newTestPacer(t, config.PacingConfig{TokensPerSecond: 1000, TokenBurst: 2000, MaxWait: 5 * time.Second}, clock)
+	if !p.PacesTokens() {
+		t.Fatal("PacesTokens() = false with a token rate")
+	}
+
+	if delay, _ := p.Wait(context.Background(), 1500); delay != 0 {
+		t.Fatalf("first delay = %s, want 0 within the burst", delay)
+	}
+	if delay, _ := p.Wait(context.Background(), 1500); delay != time.Second {
+		t.Fatalf("second delay = %s, want 1s for the 1000 missing tokens", delay)
+	}
+	if p.Snapshot().Requests != nil {
+		t.Fatal("snapshot has a request bucket without a request rate")
+	}
+}
+
+func TestWait_CanceledContext(t *testing.T) {
+	clock := newFakeClock()
+	p := newTestPacer(t, config.PacingConfig{RequestsPerSecond: 1, Burst: 1}, clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := p.Wait(ctx, 0); err != nil {
+		t.Fatalf("Wait() within the burst error = %v, want nil", err)
+	}
+	if _, err := p.Wait(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestObserveRateLimits_AdaptsUntilReset(t *testing.T) {
+	clock := newFakeClock()
+	p := newTestPacer(t, config.PacingConfig{RequestsPerSecond: 10, Burst: 1, MaxWait: 5 * time.Second, AdaptToRateLimits: true}, clock)
+
+	remaining := int64(4)
+	reset := clock.now.Add(2 * time.Second)
+	p.ObserveRateLimits(core.RateLimits{Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &reset}})
+
+	snapshot := p.Snapshot()
+	if snapshot.Requests.RatePerSecond != 2 || snapshot.Requests.AdaptedUntil == nil || !snapshot.Requests.AdaptedUntil.Equal(reset) {
+		t.Fatalf("requests = %+v, want rate 2/s until the reset", snapshot.Requests)
+	}
+	if _, err := p.Wait(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if delay, _ := p.Wait(context.Background(), 0); delay != 500*time.Millisecond {
+		t.Fatalf("adapted delay = %s, want 500ms", delay)
+	}
+
+	clock.Advance(2 * time.Second)
+	snapshot = p.Snapshot()
+	if snapshot.Requests.RatePerSecond != 10 || snapshot.Requests.AdaptedUntil != nil {
+		t.Fatalf("requests = %+v, want the configured rate after the reset", snapshot.Requests)
+	}
+
+	// Plenty remaining never raises the rate above its configured value.
+	remaining = 1000
+	reset = clock.now.Add(time.Second)
+	p.ObserveRateLimits(core.RateLimits{Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &reset}})
+	if rate := p.Snapshot().Requests.RatePerSecond; rate != 10 {
+		t.Fatalf("rate = %v, want 10", rate)
+	}
+}
+
+func TestObserveRateLimits_IgnoredWithoutAdapt(t *testing.T) {
+	clock := newFakeClock()
+	p := newTestPacer(t, config.PacingConfig{RequestsPerSecond: 10}, clock)
+	remaining := int64(0)
+	reset := clock.now.Add(time.Minute)
+	p.ObserveRateLimits(core.RateLimits{Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &reset}})
+	if rate := p.Snapshot().Requests.RatePerSecond; rate != 10 {
+		t.Fatalf("rate = %v, want 10", rate)
+	}
+}
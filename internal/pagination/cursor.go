@@ -0,0 +1,68 @@
+// Package pagination encodes the opaque keyset cursors of the admin log
+// endpoints. A cursor holds the sort key and ID of the last entry of a page,
+// and a fingerprint of the filters it was issued for, so it cannot be
+// replayed against a different query.
+package pagination
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned by Decode for a cursor that is malformed or
+// was issued for different filters.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position after the last entry of a page. Key is the entry's
+// sort key in the form the store compares it, which need not be a
+// timestamp format of its own.
+type Cursor struct {
+	Key string `json:"k"`
+	ID  string `json:"id"`
+}
+
+type token struct {
+	Cursor
+	Filter string `json:"f"`
+}
+
+// Encode returns the opaque form of c for the query fingerprinted by filter.
+func (c Cursor) Encode(filter string) string {
+	raw, err := json.Marshal(token{Cursor: c, Filter: filter})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode parses an encoded cursor and checks that it was issued for the
+// query fingerprinted by filter.
+func Decode(encoded, filter string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var t token
+	if err := json.Unmarshal(raw, &t); err != nil || t.Key == "" || t.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if t.Filter != filter {
+		return Cursor{}, fmt.Errorf("%w: it was issued for different filters", ErrInvalidCursor)
+	}
+	return t.Cursor, nil
+}
+
+// Fingerprint returns a short digest of the JSON form of filters. Callers
+// pass their query parameters with the paging fields cleared.
+func Fingerprint(filters any) string {
+	raw, err := json.Marshal(filters)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,29 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCursor_RoundTripsForItsFilters(t *testing.T) {
+	filter := Fingerprint(map[string]string{"model": "gpt-4o"})
+	want := Cursor{Key: "2026-03-01T12:00:00Z", ID: "log-1"}
+
+	got, err := Decode(want.Encode(filter), filter)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Decode = %+v, want %+v", got, want)
+	}
+
+	other := Fingerprint(map[string]string{"model": "gpt-4o-mini"})
+	if _, err := Decode(want.Encode(filter), other); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("Decode with other filters error = %v, want ErrInvalidCursor", err)
+	}
+	for _, encoded := range []string{"not a cursor", "e30", Cursor{ID: "log-1"}.Encode(filter)} {
+		if _, err := Decode(encoded, filter); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("Decode(%q) error = %v, want ErrInvalidCursor", encoded, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package pipelinestats tracks the health of buffered write pipelines such as
+// the audit log and usage loggers. Every counter is atomic so recording stays
+// cheap enough for the request path.
+package pipelinestats
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Tracker records the state of one write pipeline. Writers report drops; the
+// flush loop reports the entries it holds and the outcome of every batch.
+type Tracker struct {
+	lagThreshold time.Duration
+
+	oldestPending     atomic.Int64 // enqueue time (unix nanos) of the oldest held entry, 0 when none
+	written           atomic.Int64
+	dropped           atomic.Int64
+	flushes           atomic.Int64
+	lastFlushAt       atomic.Int64
+	lastFlushDuration atomic.Int64
+	lastError         atomic.Pointer[flushFailure]
+}
+
+type flushFailure struct {
+	message string
+	at      int64
+}
+
+// Snapshot is a point-in-time view of a write pipeline.
+type Snapshot struct {
+	// Buffered is the number of entries waiting in the in-memory buffer and
+	// BufferSize its capacity.
+	Buffered   int `json:"buffered"`
+	BufferSize int `json:"buffer_size"`
+	// Spilled is the number of entries waiting in an on-disk spill queue.
+	Spilled int `json:"spilled,omitempty"`
+	// OldestPendingSeconds is how long the oldest entry not yet written to
+	// the store has been waiting, 0 when nothing is pending.
+	OldestPendingSeconds float64 `json:"oldest_pending_seconds"`
+	// LagThresholdSeconds is the configured write lag warning bound, 0 when
+	// lag warnings are disabled.
+	LagThresholdSeconds float64 `json:"lag_threshold_seconds,omitempty"`
+	// Lagging reports whether OldestPendingSeconds exceeds the threshold.
+	Lagging bool `json:"lagging"`
+
+	Written int64 `json:"written"`
+	Dropped int64 `json:"dropped"`
+	Flushes int64 `json:"flushes"`
+
+	LastFlushAt          *time.Time `json:"last_flush_at,omitempty"`
+	LastFlushSeconds     float64    `json:"last_flush_seconds"`
+	LastFlushError       string     `json:"last_flush_error,omitempty"`
+	LastFlushErrorAt     *time.Time `json:"last_flush_error_at,omitempty"`
+	LastFlushErrorActive bool       `json:"last_flush_error_active"`
+}
+
+// OldestPending returns OldestPendingSeconds as a duration.
+func (s Snapshot) OldestPending() time.Duration {
+	return time.Duration(s.OldestPendingSeconds * float64(time.Second))
+}
+
+// NewTracker returns a Tracker that reports the pipeline lagging once its
+// oldest pending entry is older than lagThreshold. Zero disables the check.
+func NewTracker(lagThreshold time.Duration) *Tracker {
+	return &Tracker{lagThreshold: max(lagThreshold, 0)}
+}
+
+// LagThreshold returns the configured write lag warning bound.
+func (t *Tracker) LagThreshold() time.Duration {
+	return t.lagThreshold
+}
+
+// Hold records that the flush loop started holding a batch whose first entry
+// was enqueued at enqueuedAt (unix nanos). Calls while a batch is already held
+// are ignored, so the oldest entry wins.
+func (t *Tracker) Hold(enqueuedAt int64) {
+	if enqueuedAt <= 0 {
+		return
+	}
+	t.oldestPending.CompareAndSwap(0, enqueuedAt)
+}
+
+// Release records that the held batch left memory, written or not.
+func (t *Tracker) Release() {
+	t.oldestPending.Store(0)
+}
+
+// Flushed records a batch write of count entries that started at start.
+// A nil err counts the entries as written.
+func (t *Tracker) Flushed(start time.Time, count int, err error) {
+	now := time.Now()
+	t.flushes.Add(1)
+	t.lastFlushAt.Store(now.UnixNano())
+	t.lastFlushDuration.Store(int64(now.Sub(start)))
+	if err != nil {
+		t.lastError.Store(&flushFailure{message: err.Error(), at: now.UnixNano()})
+		return
+	}
+	t.written.Add(int64(count))
+}
+
+// Written returns the number of entries the store accepted.
+func (t *Tracker) Written() int64 {
+	return t.written.Load()
+}
+
+// Dropped records count entries lost without reaching the store.
+func (t *Tracker) Dropped(count int) {
+	if count > 0 {
+		t.dropped.Add(int64(count))
+	}
+}
+
+// Snapshot returns the tracker state at now together with the buffer fill
+// level the caller reports.
+func (t *Tracker) Snapshot(now time.Time, buffered, bufferSize int) Snapshot {
+	s := Snapshot{
+		Buffered:            buffered,
+		BufferSize:          bufferSize,
+		LagThresholdSeconds: t.lagThreshold.Seconds(),
+		Written:             t.written.Load(),
+		Dropped:             t.dropped.Load(),
+		Flushes:             t.flushes.Load(),
+		LastFlushSeconds:    time.Duration(t.lastFlushDuration.Load()).Seconds(),
+	}
+	if oldest := t.oldestPending.Load(); oldest > 0 {
+		s.OldestPendingSeconds = max(now.Sub(time.Unix(0, oldest)), 0).Seconds()
+	}
+	s.Lagging = t.lagThreshold > 0 && s.OldestPending() > t.lagThreshold
+
+	lastFlush := t.lastFlushAt.Load()
+	if lastFlush > 0 {
+		at := time.Unix(0, lastFlush).UTC()
+		s.LastFlushAt = &at
+	}
+	if failure := t.lastError.Load(); failure != nil {
+		at := time.Unix(0, failure.at).UTC()
+		s.LastFlushError = failure.message
+		s.LastFlushErrorAt = &at
+		s.LastFlushErrorActive = failure.at >= lastFlush
+	}
+	return s
+}
+
+// MonitorLag checks snapshot until stop is closed and logs a warning whenever
+// the pipeline is lagging, repeated at most once per threshold, and a notice
+// once it catches up. It returns immediately when threshold is not positive.
+func MonitorLag(stop <-chan struct{}, threshold time.Duration, snapshot func() Snapshot, logger *slog.Logger, pipeline string) {
+	if threshold <= 0 {
+		return
+	}
+	ticker := time.NewTicker(min(max(threshold/4, 10*time.Millisecond), 15*time.Second))
+	defer ticker.Stop()
+
+	var lagging bool
+	var lastWarn time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s := snapshot()
+			switch {
+			case s.Lagging && (!lagging || now.Sub(lastWarn) >= threshold):
+				lagging, lastWarn = true, now
+				logger.Warn(pipeline+" write lag exceeded threshold",
+					"lag", s.OldestPending().Round(time.Millisecond),
+					"threshold", threshold,
+					"buffered", s.Buffered,
+					"buffer_size", s.BufferSize,
+					"dropped_total", s.Dropped,
+					"last_flush_error", s.LastFlushError,
+				)
+			case !s.Lagging && lagging:
+				lagging = false
+				logger.Info(pipeline+" write lag recovered", "buffered", s.Buffered)
+			}
+		}
+	}
+}
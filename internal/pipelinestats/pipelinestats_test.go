@@ -0,0 +1,135 @@
+package pipelinestats
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTrackerCountsConcurrentUpdates(t *testing.T) {
+	const (
+		workers = 16
+		rounds  = 500
+	)
+	tracker := NewTracker(time.Minute)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				tracker.Dropped(1)
+				tracker.Hold(time.Now().UnixNano())
+				var err error
+				if (w+i)%5 == 0 {
+					err = errors.New("store unavailable")
+				}
+				tracker.Flushed(time.Now(), 3, err)
+				tracker.Release()
+				_ = tracker.Snapshot(time.Now(), 0, 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := tracker.Snapshot(time.Now(), 0, 10)
+	failed := int64(workers * rounds / 5)
+	if s.Dropped != workers*rounds || s.Flushes != workers*rounds {
+		t.Fatalf("dropped = %d, flushes = %d, want %d each", s.Dropped, s.Flushes, workers*rounds)
+	}
+	if want := (workers*rounds - failed) * 3; s.Written != want || tracker.Written() != want {
+		t.Fatalf("written = %d, want %d", s.Written, want)
+	}
+	if s.OldestPendingSeconds != 0 || s.LastFlushAt == nil || s.LastFlushErrorAt == nil {
+		t.Fatalf("snapshot = %+v, want nothing pending and both flush times set", s)
+	}
+}
+
+func TestTrackerSnapshotReportsLag(t *testing.T) {
+	tracker := NewTracker(time.Second)
+	now := time.Now()
+
+	tracker.Hold(now.Add(-3 * time.Second).UnixNano())
+	tracker.Hold(now.UnixNano()) // a later entry must not reset the lag
+	tracker.Flushed(now.Add(-time.Second), 5, errors.New("timeout"))
+
+	s := tracker.Snapshot(now, 7, 100)
+	if s.OldestPending() != 3*time.Second || !s.Lagging || s.Buffered != 7 || s.BufferSize != 100 {
+		t.Fatalf("snapshot = %+v, want 3s of lag over a 1s threshold", s)
+	}
+	if s.LastFlushError != "timeout" || !s.LastFlushErrorActive || s.Written != 0 {
+		t.Fatalf("snapshot = %+v, want the failed flush to be the active error", s)
+	}
+
+	tracker.Release()
+	time.Sleep(time.Millisecond) // order the successful flush after the failure
+	tracker.Flushed(time.Now(), 5, nil)
+	s = tracker.Snapshot(time.Now(), 0, 100)
+	if s.Lagging || s.OldestPendingSeconds != 0 || s.LastFlushErrorActive || s.Written != 5 {
+		t.Fatalf("snapshot = %+v, want a recovered pipeline", s)
+	}
+}
+
+func TestTrackerWithoutThresholdNeverLags(t *testing.T) {
+	tracker := NewTracker(0)
+	tracker.Hold(time.Now().Add(-time.Hour).UnixNano())
+	if s := tracker.Snapshot(time.Now(), 0, 0); s.Lagging || s.OldestPending() < time.Hour {
+		t.Fatalf("snapshot = %+v, want an hour pending but no lag flag", s)
+	}
+}
+
+func TestMonitorLagWarnsAndRecovers(t *testing.T) {
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &mu, w: &buf}, nil))
+
+	tracker := NewTracker(20 * time.Millisecond)
+	snapshot := func() Snapshot { return tracker.Snapshot(time.Now(), 1, 10) }
+	logged := func(msg string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Contains(buf.String(), msg)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		MonitorLag(stop, 20*time.Millisecond, snapshot, logger, "usage")
+		close(done)
+	}()
+
+	tracker.Hold(time.Now().UnixNano())
+	waitFor(t, func() bool { return logged("usage write lag exceeded threshold") })
+	tracker.Release()
+	waitFor(t, func() bool { return logged("usage write lag recovered") })
+
+	close(stop)
+	<-done
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,64 @@
+// Package playground provides the optional embedded developer playground UI.
+//
+// The playground is a static page that calls the gateway's own /v1 endpoints
+// from the browser with an API key the user pastes. The server only serves
+// the assets; it holds no playground state and never sees the key outside
+// the normal authenticated requests.
+package playground
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+//go:embed static/*.html static/*.css static/*.js
+var content embed.FS
+
+// ContentSecurityPolicy restricts the playground to its own assets and to
+// requests against the gateway's origin.
+const ContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// Handler serves the playground UI.
+type Handler struct {
+	index    []byte
+	staticFS http.Handler
+}
+
+// New creates a playground handler over the embedded assets.
+func New() (*Handler, error) {
+	index, err := content.ReadFile("static/index.html")
+	if err != nil {
+		return nil, err
+	}
+	staticSub, err := fs.Sub(content, "static")
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		index:    index,
+		staticFS: http.StripPrefix("/playground/static/", http.FileServer(http.FS(staticSub))),
+	}, nil
+}
+
+// Index serves GET /playground — the playground page.
+func (h *Handler) Index(c *echo.Context) error {
+	setSecurityHeaders(c.Response().Header())
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.HTMLBlob(http.StatusOK, h.index)
+}
+
+// Static serves GET /playground/static/* — the embedded CSS and JS.
+func (h *Handler) Static(c *echo.Context) error {
+	setSecurityHeaders(c.Response().Header())
+	h.staticFS.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func setSecurityHeaders(header http.Header) {
+	header.Set("Content-Security-Policy", ContentSecurityPolicy)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+}
@@ -0,0 +1,93 @@
+package playground
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newTestContext(path string) (*echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestIndex_ServesPageWithSecurityHeaders(t *testing.T) {
+	h, err := New()
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	c, rec := newTestContext("/playground")
+
+	if err := h.Index(c); err != nil {
+		t.Fatalf("Index() returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); csp != ContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`id="api-key"`, `id="model"`, `id="stream"`, "/playground/static/playground.js"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page does not contain %s", want)
+		}
+	}
+}
+
+func TestStatic_ServesEmbeddedAssets(t *testing.T) {
+	h, err := New()
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	for path, wantType := range map[string]string{
+		"/playground/static/playground.js":  "javascript",
+		"/playground/static/playground.css": "text/css",
+	} {
+		c, rec := newTestContext(path)
+		if err := h.Static(c); err != nil {
+			t.Fatalf("Static(%s) returned error: %v", path, err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, wantType) {
+			t.Errorf("%s: Content-Type = %q, want %s", path, ct, wantType)
+		}
+		if rec.Header().Get("Content-Security-Policy") == "" {
+			t.Errorf("%s: missing Content-Security-Policy", path)
+		}
+	}
+
+	c, rec := newTestContext("/playground/static/missing.js")
+	if err := h.Static(c); err != nil {
+		t.Fatalf("Static() returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing asset: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestScript_NeverPersistsTheAPIKey(t *testing.T) {
+	script, err := content.ReadFile("static/playground.js")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, forbidden := range []string{"localStorage", "sessionStorage", "document.cookie", "?key=", "api_key="} {
+		if strings.Contains(string(script), forbidden) {
+			t.Errorf("playground.js uses %s", forbidden)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// GoModel Playground: sends chat completions through the gateway's own /v1
+// endpoints. The API key is only kept in the input field; it is never written
+// to storage or put in a URL.
+(function () {
+  "use strict";
+
+  const $ = (id) => document.getElementById(id);
+
+  function headers(json) {
+    const h = { Authorization: "Bearer " + $("api-key").value.trim() };
+    if (json) {
+      h["Content-Type"] = "application/json";
+    }
+    return h;
+  }
+
+  function setOutput(text, isError) {
+    const output = $("output");
+    output.textContent = text;
+    output.classList.toggle("error", Boolean(isError));
+  }
+
+  function setMeta(status, requestID, firstByte, total) {
+    $("status").textContent = status;
+    $("request-id").textContent = requestID || "-";
+    $("first-byte").textContent = firstByte == null ? "-" : firstByte.toFixed(0) + " ms";
+    $("total").textContent = total == null ? "-" : total.toFixed(0) + " ms";
+  }
+
+  async function errorText(response) {
+    const body = await response.text();
+    try {
+      const parsed = JSON.parse(body);
+      if (parsed.error && parsed.error.message) {
+        return parsed.error.message;
+      }
+    } catch (_) {
+      // Not JSON; show the raw body.
+    }
+    return body || response.statusText;
+  }
+
+  async function loadModels() {
+    const button = $("load-models");
+    button.disabled = true;
+    try {
+      const response = await fetch("/v1/models", { headers: headers(false) });
+      if (!response.ok) {
+        setOutput("Loading models failed: " + (await errorText(response)), true);
+        return;
+      }
+      const body = await response.json();
+      const select = $("model");
+      select.replaceChildren();
+      const ids = (body.data || []).map((m) => m.id).sort();
+      for (const id of ids) {
+        const option = document.createElement("option");
+        option.value = id;
+        option.textContent = id;
+        select.append(option);
+      }
+      setOutput(ids.length + " models loaded.", false);
+    } catch (err) {
+      setOutput("Loading models failed: " + err.message, true);
+    } finally {
+      button.disabled = false;
+    }
+  }
+
+  function buildRequest() {
+    const request = {
+      model: $("model").value,
+      messages: [{ role: "user", content: $("prompt").value }],
+      stream: $("stream").checked,
+    };
+    const temperature = $("temperature").value;
+    if (temperature !== "") {
+      request.temperature = Number(temperature);
+    }
+    const maxTokens = $("max-tokens").value;
+    if (maxTokens !== "") {
+      request.max_tokens = Number(maxTokens);
+    }
+    return request;
+  }
+
+  // readStream appends the content deltas of an SSE chat completion stream
+  // to the output and returns the time the first byte arrived.
+  async function readStream(response, started) {
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let firstByte = null;
+    let buffered = "";
+    let text = "";
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) {
+        break;
+      }
+      if (firstByte === null) {
+        firstByte = performance.now() - started;
+      }
+      buffered += decoder.decode(value, { stream: true });
+      const lines = buffered.split("\n");
+      buffered = lines.pop();
+      for (const line of lines) {
+        if (!line.startsWith("data:")) {
+          continue;
+        }
+        const data = line.slice(5).trim();
+        if (data === "" || data === "[DONE]") {
+          continue;
+        }
+        try {
+          const chunk = JSON.parse(data);
+          const delta = chunk.choices && chunk.choices[0] && chunk.choices[0].delta;
+          if (delta && delta.content) {
+            text += delta.content;
+            setOutput(text, false);
+          }
+          if (chunk.error && chunk.error.message) {
+            setOutput(text + "\n\n" + chunk.error.message, true);
+          }
+        } catch (_) {
+          // Ignore partial or non-JSON events.
+        }
+      }
+    }
+    return firstByte;
+  }
+
+  async function send(event) {
+    event.preventDefault();
+    const button = $("send");
+    button.disabled = true;
+    setOutput("", false);
+    setMeta("pending", null, null, null);
+
+    const request = buildRequest();
+    const started = performance.now();
+    try {
+      const response = await fetch("/v1/chat/completions", {
+        method: "POST",
+        headers: headers(true),
+        body: JSON.stringify(request),
+      });
+      const headersAt = performance.now() - started;
+      const requestID = response.headers.get("X-Request-ID");
+      if (!response.ok) {
+        const message = await errorText(response);
+        setMeta(String(response.status), requestID, null, performance.now() - started);
+        setOutput(message, true);
+        return;
+      }
+      let firstByte;
+      if (request.stream) {
+        firstByte = await readStream(response, started);
+      } else {
+        const body = await response.json();
+        firstByte = headersAt;
+        const message = body.choices && body.choices[0] && body.choices[0].message;
+        setOutput(message && message.content != null ? message.content : JSON.stringify(body, null, 2), false);
+      }
+      setMeta(String(response.status), requestID, firstByte, performance.now() - started);
+    } catch (err) {
+      setMeta("failed", null, null, performance.now() - started);
+      setOutput(err.message, true);
+    } finally {
+      button.disabled = false;
+    }
+  }
+
+  document.addEventListener("DOMContentLoaded", () => {
+    $("load-models").addEventListener("click", loadModels);
+    $("request-form").addEventListener("submit", send);
+  });
+})();
@@ -0,0 +1,316 @@
+// Package probe detects what a provider supports by sending it a battery of
+// tiny requests: a one-token chat, a chat with a trivial tool, a JSON-mode
+// chat, a streaming chat, a Responses API request and a one-string
+// embedding. Probes only run when an operator triggers them; each run is
+// capped by a token budget, and its usage entries are labeled as internal
+// so they can be told apart from client traffic.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// UsageLabel and UsageLabelValue are the cost allocation label set on every
+// usage entry a probe records.
+const (
+	UsageLabel      = "gomodel_internal"
+	UsageLabelValue = "capability_probe"
+)
+
+// DefaultTokenBudget caps a probe run when the config does not.
+const DefaultTokenBudget = 1000
+
+// DefaultChecks is the battery run when a probe does not name its checks, in
+// execution order.
+var DefaultChecks = []string{
+	core.CapabilityChat,
+	core.CapabilityChatStream,
+	core.CapabilityTools,
+	core.CapabilityJSONMode,
+	core.CapabilityResponses,
+	core.CapabilityEmbeddings,
+}
+
+const (
+	// prompt is the user message of every chat and Responses check.
+	prompt = "Reply with the single word: pong"
+	// embeddingInput is the one short string the embeddings check sends.
+	embeddingInput = "pong"
+	// chatMaxTokens caps the output of the chat checks; tool and JSON output
+	// need a few tokens to be well-formed, and Responses requires at least 16.
+	chatMaxTokens       = 1
+	structuredMaxTokens = 16
+	// maxStreamBytes bounds how much of a probe stream is read.
+	maxStreamBytes = 1 << 20
+)
+
+// Options describes one probe run.
+type Options struct {
+	// Model is the chat model the checks use.
+	Model string
+	// EmbeddingModel is the model of the embeddings check; empty uses Model.
+	EmbeddingModel string
+	// Checks limits the battery; empty runs DefaultChecks.
+	Checks []string
+	// TokenBudget lowers the configured budget for this run; 0 keeps it.
+	TokenBudget int
+	// ProviderName and ProviderType identify the probed provider in the
+	// profile and in usage entries.
+	ProviderName string
+	ProviderType string
+	// RequestID links the usage entries to the request that ran the probe.
+	RequestID string
+}
+
+// Prober runs capability probes.
+type Prober struct {
+	tokenBudget int
+	usage       usage.LoggerInterface
+	pricing     usage.PricingResolver
+	now         func() time.Time
+}
+
+// New returns a Prober capped by cfg. usageLogger and pricing may be nil.
+func New(cfg config.CapabilityProbeConfig, usageLogger usage.LoggerInterface, pricing usage.PricingResolver) *Prober {
+	budget := cfg.TokenBudget
+	if budget <= 0 {
+		budget = DefaultTokenBudget
+	}
+	return &Prober{tokenBudget: budget, usage: usageLogger, pricing: pricing, now: time.Now}
+}
+
+// TokenBudget returns the most tokens one probe run may spend.
+func (p *Prober) TokenBudget() int {
+	return p.tokenBudget
+}
+
+// ValidateChecks rejects check names the battery does not know.
+func ValidateChecks(checks []string) error {
+	for _, check := range checks {
+		if !slices.Contains(DefaultChecks, check) {
+			return fmt.Errorf("unknown check %q, expected one of %s", check, strings.Join(DefaultChecks, ", "))
+		}
+	}
+	return nil
+}
+
+// Run probes provider directly, bypassing routing, and returns the profile.
+// Checks run in order and stop being sent once the next one could exceed the
+// token budget.
+func (p *Prober) Run(ctx context.Context, provider core.Provider, opts Options) (*core.CapabilityProfile, error) {
+	opts.Model = strings.TrimSpace(opts.Model)
+	if opts.Model == "" {
+		return nil, errors.New("model is required")
+	}
+	opts.EmbeddingModel = strings.TrimSpace(opts.EmbeddingModel)
+	if opts.EmbeddingModel == "" {
+		opts.EmbeddingModel = opts.Model
+	}
+	checks := opts.Checks
+	if len(checks) == 0 {
+		checks = DefaultChecks
+	}
+	if err := ValidateChecks(checks); err != nil {
+		return nil, err
+	}
+	budget := p.tokenBudget
+	if opts.TokenBudget > 0 && opts.TokenBudget < budget {
+		budget = opts.TokenBudget
+	}
+	if opts.RequestID != "" {
+		ctx = core.WithRequestID(ctx, opts.RequestID)
+	}
+
+	profile := &core.CapabilityProfile{
+		Provider:    opts.ProviderName,
+		Model:       opts.Model,
+		ProbedAt:    p.now().UTC(),
+		Checks:      make([]core.CapabilityCheck, 0, len(checks)),
+		TokenBudget: budget,
+	}
+	for _, capability := range checks {
+		if capability == core.CapabilityEmbeddings {
+			profile.EmbeddingModel = opts.EmbeddingModel
+		}
+		check := core.CapabilityCheck{Capability: capability}
+		if estimate := estimateTokens(capability); profile.TokensUsed+estimate > budget {
+			check.Status = core.CapabilitySkipped
+			check.Error = fmt.Sprintf("token budget exhausted (%d of %d used)", profile.TokensUsed, budget)
+			profile.Checks = append(profile.Checks, check)
+			continue
+		}
+
+		started := p.now()
+		entry, err := p.runCheck(ctx, provider, capability, opts)
+		check.LatencyMs = p.now().Sub(started).Milliseconds()
+		check.Status, check.Error = classify(err)
+		check.Tokens = estimateTokens(capability)
+		if entry != nil {
+			if entry.TotalTokens > 0 {
+				check.Tokens = entry.TotalTokens
+			}
+			p.recordUsage(entry, opts)
+		}
+		profile.TokensUsed += check.Tokens
+		profile.Checks = append(profile.Checks, check)
+	}
+	return profile, nil
+}
+
+// runCheck sends one probe request and returns its usage when the response
+// reported any.
+func (p *Prober) runCheck(ctx context.Context, provider core.Provider, capability string, opts Options) (*usage.UsageEntry, error) {
+	switch capability {
+	case core.CapabilityChat:
+		resp, err := provider.ChatCompletion(ctx, chatRequest(opts.Model, chatMaxTokens))
+		if err != nil {
+			return nil, err
+		}
+		return usage.ExtractFromChatResponse(resp, opts.RequestID, opts.ProviderType, "/v1/chat/completions", p.resolvePricing(opts.Model, opts.ProviderType)), nil
+
+	case core.CapabilityTools:
+		req := chatRequest(opts.Model, structuredMaxTokens)
+		req.Tools = []map[string]any{{
+			"type": "function",
+			"function": map[string]any{
+				"name":        "pong",
+				"description": "Answers a ping.",
+				"parameters":  map[string]any{"type": "object", "properties": map[string]any{}},
+			},
+		}}
+		resp, err := provider.ChatCompletion(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return usage.ExtractFromChatResponse(resp, opts.RequestID, opts.ProviderType, "/v1/chat/completions", p.resolvePricing(opts.Model, opts.ProviderType)), nil
+
+	case core.CapabilityJSONMode:
+		req := chatRequest(opts.Model, structuredMaxTokens)
+		req.Messages[0].Content = `Reply with the JSON object {"reply":"pong"}`
+		req.ExtraFields = core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+			"response_format": json.RawMessage(`{"type":"json_object"}`),
+		})
+		resp, err := provider.ChatCompletion(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return usage.ExtractFromChatResponse(resp, opts.RequestID, opts.ProviderType, "/v1/chat/completions", p.resolvePricing(opts.Model, opts.ProviderType)), nil
+
+	case core.CapabilityChatStream:
+		req := chatRequest(opts.Model, chatMaxTokens).WithStreaming()
+		stream, err := provider.StreamChatCompletion(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		defer stream.Close()
+		body, err := io.ReadAll(io.LimitReader(stream, maxStreamBytes))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Contains(body, []byte("data:")) {
+			return nil, core.NewInvalidRequestError("response was not an event stream", nil)
+		}
+		return nil, nil
+
+	case core.CapabilityResponses:
+		maxOutput := structuredMaxTokens
+		resp, err := provider.Responses(ctx, &core.ResponsesRequest{
+			Model:           opts.Model,
+			Input:           prompt,
+			MaxOutputTokens: &maxOutput,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return usage.ExtractFromResponsesResponse(resp, opts.RequestID, opts.ProviderType, "/v1/responses", p.resolvePricing(opts.Model, opts.ProviderType)), nil
+
+	case core.CapabilityEmbeddings:
+		resp, err := provider.Embeddings(ctx, &core.EmbeddingRequest{
+			Model: opts.EmbeddingModel,
+			Input: embeddingInput,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return usage.ExtractFromEmbeddingResponse(resp, opts.RequestID, opts.ProviderType, "/v1/embeddings", p.resolvePricing(opts.EmbeddingModel, opts.ProviderType)), nil
+	}
+	return nil, fmt.Errorf("unknown check %q", capability)
+}
+
+func (p *Prober) resolvePricing(model, providerType string) *core.ModelPricing {
+	if p.pricing == nil {
+		return nil
+	}
+	return p.pricing.ResolvePricing(model, providerType)
+}
+
+// recordUsage writes entry labeled as internal probe traffic.
+func (p *Prober) recordUsage(entry *usage.UsageEntry, opts Options) {
+	if p.usage == nil || !p.usage.Config().Enabled {
+		return
+	}
+	entry.ProviderName = opts.ProviderName
+	entry.Labels = map[string]string{UsageLabel: UsageLabelValue}
+	p.usage.Write(entry)
+}
+
+func chatRequest(model string, maxTokens int) *core.ChatRequest {
+	return &core.ChatRequest{
+		Model:     model,
+		Messages:  []core.Message{{Role: "user", Content: prompt}},
+		MaxTokens: &maxTokens,
+	}
+}
+
+// estimateTokens is the cost charged to the budget for a check whose
+// response reports no usage, and the reservation made before sending it.
+func estimateTokens(capability string) int {
+	input := (len(prompt) + 3) / 4
+	switch capability {
+	case core.CapabilityChat, core.CapabilityChatStream:
+		return input + chatMaxTokens
+	case core.CapabilityEmbeddings:
+		return (len(embeddingInput) + 3) / 4
+	case core.CapabilityTools:
+		// The tool definition is part of the prompt.
+		return input + 40 + structuredMaxTokens
+	default:
+		return input + structuredMaxTokens
+	}
+}
+
+// classify maps a probe error to a check status. Providers reject features
+// they lack as invalid or unknown requests; other failures say nothing about
+// the feature.
+func classify(err error) (core.CapabilityCheckStatus, string) {
+	if err == nil {
+		return core.CapabilitySupported, ""
+	}
+	message := err.Error()
+	if gatewayErr, ok := errors.AsType[*core.GatewayError](err); ok {
+		message = gatewayErr.Message
+		status := gatewayErr.UpstreamStatusCode
+		if status == 0 {
+			status = gatewayErr.StatusCode
+		}
+		switch status {
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed,
+			http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusNotImplemented:
+			return core.CapabilityUnsupported, message
+		}
+	}
+	return core.CapabilityInconclusive, message
+}
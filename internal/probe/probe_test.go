@@ -0,0 +1,159 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// limitedProvider answers plain and streaming chat, rejects tools and JSON
+// mode like an upstream without them, has no Responses API, no embeddings,
+// and fails the model listing.
+type limitedProvider struct {
+	chatCalls int
+}
+
+func (p *limitedProvider) ChatCompletion(_ context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	p.chatCalls++
+	if len(req.Tools) > 0 {
+		return nil, core.ParseProviderError("limited", http.StatusBadRequest, []byte(`{"error":{"message":"tools are not supported"}}`), nil)
+	}
+	if req.ExtraFields.Lookup("response_format") != nil {
+		return nil, core.ParseProviderError("limited", http.StatusUnprocessableEntity, []byte(`{"error":{"message":"unknown field response_format"}}`), nil)
+	}
+	return &core.ChatResponse{
+		ID:    "chatcmpl-probe",
+		Model: req.Model,
+		Usage: core.Usage{PromptTokens: 9, CompletionTokens: 1, TotalTokens: 10},
+	}, nil
+}
+
+func (p *limitedProvider) StreamChatCompletion(_ context.Context, _ *core.ChatRequest) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("data: {\"choices\":[{\"delta\":{\"content\":\"p\"}}]}\n\ndata: [DONE]\n\n")), nil
+}
+
+func (p *limitedProvider) ListModels(_ context.Context) (*core.ModelsResponse, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *limitedProvider) Responses(_ context.Context, _ *core.ResponsesRequest) (*core.ResponsesResponse, error) {
+	return nil, core.ParseProviderError("limited", http.StatusNotFound, []byte(`{"error":{"message":"no route /v1/responses"}}`), nil)
+}
+
+func (p *limitedProvider) StreamResponses(_ context.Context, _ *core.ResponsesRequest) (io.ReadCloser, error) {
+	return nil, core.NewInvalidRequestError("not supported", nil)
+}
+
+func (p *limitedProvider) Embeddings(_ context.Context, _ *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	return nil, core.ParseProviderError("limited", http.StatusServiceUnavailable, []byte(`{"error":{"message":"overloaded"}}`), nil)
+}
+
+type recordingUsageLogger struct {
+	entries []*usage.UsageEntry
+}
+
+func (l *recordingUsageLogger) Write(entry *usage.UsageEntry) { l.entries = append(l.entries, entry) }
+func (l *recordingUsageLogger) Config() usage.Config          { return usage.Config{Enabled: true} }
+func (l *recordingUsageLogger) Flush(_ context.Context) error { return nil }
+func (l *recordingUsageLogger) Close() error                  { return nil }
+
+func statuses(profile *core.CapabilityProfile) map[string]core.CapabilityCheckStatus {
+	got := make(map[string]core.CapabilityCheckStatus, len(profile.Checks))
+	for _, check := range profile.Checks {
+		got[check.Capability] = check.Status
+	}
+	return got
+}
+
+func TestRun_ProfilesLimitedProvider(t *testing.T) {
+	logger := &recordingUsageLogger{}
+	prober := New(config.CapabilityProbeConfig{TokenBudget: 1000}, logger, nil)
+
+	profile, err := prober.Run(context.Background(), &limitedProvider{}, Options{
+		Model:        "tiny-chat",
+		ProviderName: "limited-main",
+		ProviderType: "openai",
+		RequestID:    "req-probe",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]core.CapabilityCheckStatus{
+		core.CapabilityChat:       core.CapabilitySupported,
+		core.CapabilityChatStream: core.CapabilitySupported,
+		core.CapabilityTools:      core.CapabilityUnsupported,
+		core.CapabilityJSONMode:   core.CapabilityUnsupported,
+		core.CapabilityResponses:  core.CapabilityUnsupported,
+		core.CapabilityEmbeddings: core.CapabilityInconclusive,
+	}
+	got := statuses(profile)
+	for capability, status := range want {
+		if got[capability] != status {
+			t.Errorf("%s = %q, want %q", capability, got[capability], status)
+		}
+	}
+	if len(profile.Checks) != len(DefaultChecks) {
+		t.Fatalf("checks = %d, want %d", len(profile.Checks), len(DefaultChecks))
+	}
+	for _, check := range profile.Checks {
+		if check.Capability == core.CapabilityTools && check.Error != "tools are not supported" {
+			t.Errorf("tools error = %q, want the upstream message", check.Error)
+		}
+	}
+	if supported, known := profile.Supports(core.CapabilityEmbeddings); supported || known {
+		t.Errorf("Supports(embeddings) = %v, %v; want unknown after an inconclusive check", supported, known)
+	}
+	if profile.Provider != "limited-main" || profile.EmbeddingModel != "tiny-chat" || profile.TokensUsed == 0 {
+		t.Errorf("profile = %+v, want provider, embedding model and tokens used", profile)
+	}
+
+	if len(logger.entries) != 1 {
+		t.Fatalf("usage entries = %d, want 1 for the chat check", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry.Labels[UsageLabel] != UsageLabelValue || entry.RequestID != "req-probe" || entry.ProviderName != "limited-main" || entry.TotalTokens != 10 {
+		t.Fatalf("usage entry = %+v, want an internal probe entry for req-probe", entry)
+	}
+}
+
+func TestRun_SkipsChecksBeyondTokenBudget(t *testing.T) {
+	provider := &limitedProvider{}
+	prober := New(config.CapabilityProbeConfig{TokenBudget: 1000}, nil, nil)
+
+	profile, err := prober.Run(context.Background(), provider, Options{
+		Model:       "tiny-chat",
+		Checks:      []string{core.CapabilityChat, core.CapabilityTools},
+		TokenBudget: 20,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	got := statuses(profile)
+	if got[core.CapabilityChat] != core.CapabilitySupported || got[core.CapabilityTools] != core.CapabilitySkipped {
+		t.Fatalf("statuses = %v, want chat supported and tools skipped", got)
+	}
+	if provider.chatCalls != 1 || profile.TokenBudget != 20 || profile.TokensUsed > 20 {
+		t.Fatalf("chat calls = %d, budget = %d, used = %d; want one call within 20 tokens", provider.chatCalls, profile.TokenBudget, profile.TokensUsed)
+	}
+}
+
+func TestRun_RejectsUnknownCheckAndMissingModel(t *testing.T) {
+	prober := New(config.CapabilityProbeConfig{}, nil, nil)
+	if _, err := prober.Run(context.Background(), &limitedProvider{}, Options{Model: "m", Checks: []string{"vision"}}); err == nil {
+		t.Fatal("Run() with an unknown check succeeded")
+	}
+	if _, err := prober.Run(context.Background(), &limitedProvider{}, Options{}); err == nil {
+		t.Fatal("Run() without a model succeeded")
+	}
+	if prober.TokenBudget() != DefaultTokenBudget {
+		t.Fatalf("TokenBudget() = %d, want %d", prober.TokenBudget(), DefaultTokenBudget)
+	}
+}
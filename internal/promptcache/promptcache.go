@@ -0,0 +1,456 @@
+// Package promptcache negotiates provider prompt caching for system prompts
+// shared by many requests and tracks how often the provider cache hits.
+//
+// A system prompt is cacheable when it starts with a configured prefix or,
+// with auto-detection, once enough requests sent the same prompt. Detection
+// state and hit statistics live in memory only and restart empty with the
+// gateway. Hits are read from the cached token counts of the usage entries,
+// so they are only tracked while usage tracking is enabled.
+package promptcache
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// Prefix sources reported in PrefixStats.Source.
+const (
+	SourceConfigured = "configured"
+	SourceDetected   = "detected"
+)
+
+const (
+	// keyLength is the number of hex characters kept from the SHA-256 hash
+	// of a prefix.
+	keyLength = 16
+	// previewLength is the number of characters of a prefix shown in stats.
+	previewLength = 80
+	// maxPending caps the requests waiting for their usage entry.
+	maxPending = 10000
+	// pendingTTL drops requests whose usage entry never arrived, such as
+	// failed requests.
+	pendingTTL = 15 * time.Minute
+	// prefixOverheadBytes estimates a tracked prefix beyond its preview:
+	// the key, the counters and the map slot.
+	prefixOverheadBytes = 256
+	// pendingBytes estimates one request waiting for its usage entry.
+	pendingBytes = 96
+)
+
+// Counts are the cache hit rate and savings of cacheable requests.
+type Counts struct {
+	// Requests counts requests sent with the prefix marked cacheable.
+	Requests int64 `json:"requests"`
+	// Observed counts those requests whose usage was recorded.
+	Observed int64 `json:"observed"`
+	// Hits counts observed requests that read the prefix from the cache.
+	Hits             int64   `json:"hits"`
+	HitRate          float64 `json:"hit_rate"`
+	InputTokens      int64   `json:"input_tokens"`
+	CachedTokens     int64   `json:"cached_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	// EstimatedSavingsUSD is the input cost saved by cache reads minus the
+	// cache write surcharge, for models with known pricing.
+	EstimatedSavingsUSD float64 `json:"estimated_savings_usd"`
+}
+
+func (c *Counts) add(other Counts) {
+	c.Requests += other.Requests
+	c.Observed += other.Observed
+	c.Hits += other.Hits
+	c.InputTokens += other.InputTokens
+	c.CachedTokens += other.CachedTokens
+	c.CacheWriteTokens += other.CacheWriteTokens
+	c.EstimatedSavingsUSD += other.EstimatedSavingsUSD
+	c.HitRate = hitRate(c.Hits, c.Observed)
+}
+
+// PrefixStats reports the counts of one cacheable prefix.
+type PrefixStats struct {
+	Key     string `json:"key"`
+	Source  string `json:"source"`
+	Chars   int    `json:"chars"`
+	Preview string `json:"preview"`
+	Counts
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// Report is the prompt cache statistics served by the admin API.
+type Report struct {
+	Enabled  bool          `json:"enabled"`
+	Totals   Counts        `json:"totals"`
+	Prefixes []PrefixStats `json:"prefixes"`
+}
+
+type prefixState struct {
+	stats PrefixStats
+	// seen counts requests with a detected system prompt, including those
+	// before it became cacheable.
+	seen     int
+	lastSeen time.Time
+}
+
+type pendingRequest struct {
+	key string
+	at  time.Time
+}
+
+// Tracker matches system prompts against cacheable prefixes and accounts
+// cache hits per prefix. A nil Tracker matches nothing.
+type Tracker struct {
+	models      []string
+	configured  []string
+	autoDetect  bool
+	minRepeats  int
+	minChars    int
+	maxPrefixes int
+	pricing     usage.PricingResolver
+	now         func() time.Time
+
+	mu        sync.Mutex
+	prefixes  map[string]*prefixState
+	detected  int
+	pending   map[string]pendingRequest
+	lastSweep time.Time
+}
+
+// New returns a Tracker for cfg, or nil when prompt caching is disabled.
+// pricing, when set, prices the savings of cache reads.
+func New(cfg config.PromptCachingConfig, pricing usage.PricingResolver) *Tracker {
+	if !cfg.Enabled {
+		return nil
+	}
+	t := &Tracker{
+		models:      cfg.Models,
+		autoDetect:  cfg.AutoDetect,
+		minRepeats:  max(cfg.MinRepeats, 1),
+		minChars:    max(cfg.MinChars, 1),
+		maxPrefixes: max(cfg.MaxPrefixes, 1),
+		pricing:     pricing,
+		now:         time.Now,
+		prefixes:    make(map[string]*prefixState),
+		pending:     make(map[string]pendingRequest),
+	}
+	for _, prefix := range cfg.Prefixes {
+		key := hashKey(prefix)
+		if _, ok := t.prefixes[key]; ok {
+			continue
+		}
+		t.configured = append(t.configured, prefix)
+		t.prefixes[key] = &prefixState{stats: newStats(key, SourceConfigured, prefix)}
+	}
+	// Longest first, so the most specific configured prefix wins.
+	slices.SortStableFunc(t.configured, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return t
+}
+
+// Match reports whether system, the system prompt of a request for model,
+// starts with a cacheable prefix and returns the hint providers use to mark
+// it. A matched request is remembered by requestID until its usage arrives.
+func (t *Tracker) Match(requestID, providerName, model, system string) (core.PromptCacheHint, bool) {
+	if t == nil || system == "" || !t.coversModel(providerName, model) {
+		return core.PromptCacheHint{}, false
+	}
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prefix := t.configuredPrefix(system)
+	if prefix == "" && t.detect(system, now) {
+		prefix = system
+	}
+	if prefix == "" {
+		return core.PromptCacheHint{}, false
+	}
+	key := hashKey(prefix)
+	state := t.prefixes[key]
+	state.stats.Requests++
+	state.lastSeen = now
+	t.track(requestID, key, now)
+	return core.PromptCacheHint{Prefix: prefix, Key: key}, true
+}
+
+func (t *Tracker) coversModel(providerName, model string) bool {
+	if len(t.models) == 0 {
+		return true
+	}
+	if slices.Contains(t.models, model) {
+		return true
+	}
+	return providerName != "" && slices.Contains(t.models, providerName+"/"+model)
+}
+
+func (t *Tracker) configuredPrefix(system string) string {
+	for _, prefix := range t.configured {
+		if strings.HasPrefix(system, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// detect counts a request with system and reports whether the prompt has
+// been seen often enough to be cacheable. Caller must hold t.mu.
+func (t *Tracker) detect(system string, now time.Time) bool {
+	if !t.autoDetect || len(system) < t.minChars {
+		return false
+	}
+	key := hashKey(system)
+	state, ok := t.prefixes[key]
+	if !ok {
+		if t.detected >= t.maxPrefixes {
+			t.evictDetected()
+		}
+		state = &prefixState{stats: newStats(key, SourceDetected, system)}
+		t.prefixes[key] = state
+		t.detected++
+	}
+	state.seen++
+	state.lastSeen = now
+	return state.seen >= t.minRepeats
+}
+
+// evictDetected drops the least recently seen detected prompt. Caller must
+// hold t.mu.
+func (t *Tracker) evictDetected() {
+	var oldest string
+	var oldestAt time.Time
+	for key, state := range t.prefixes {
+		if state.stats.Source != SourceDetected {
+			continue
+		}
+		if oldest == "" || state.lastSeen.Before(oldestAt) {
+			oldest, oldestAt = key, state.lastSeen
+		}
+	}
+	if oldest != "" {
+		delete(t.prefixes, oldest)
+		t.detected--
+	}
+}
+
+// MemoryUsage returns the number of tracked prefixes and the estimated bytes
+// of the prefixes and the requests waiting for their usage.
+func (t *Tracker) MemoryUsage() (int, int64) {
+	if t == nil {
+		return 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	bytes := int64(len(t.pending)) * pendingBytes
+	for _, state := range t.prefixes {
+		bytes += prefixOverheadBytes + int64(len(state.stats.Preview))
+	}
+	return len(t.prefixes), bytes
+}
+
+// Evict drops the least recently seen fraction of the detected prompts,
+// rounded up. Configured prefixes are kept.
+func (t *Tracker) Evict(fraction float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := min(int(math.Ceil(fraction*float64(t.detected))), t.detected)
+	if n == 0 {
+		return
+	}
+	keys := make([]string, 0, t.detected)
+	for key, state := range t.prefixes {
+		if state.stats.Source == SourceDetected {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int { return t.prefixes[a].lastSeen.Compare(t.prefixes[b].lastSeen) })
+	for _, key := range keys[:n] {
+		delete(t.prefixes, key)
+		t.detected--
+	}
+}
+
+// track remembers a matched request until its usage arrives. Requests are
+// not tracked while maxPending requests are still waiting. Caller must hold
+// t.mu.
+func (t *Tracker) track(requestID, key string, now time.Time) {
+	if requestID == "" {
+		return
+	}
+	if len(t.pending) >= maxPending && now.Sub(t.lastSweep) >= time.Minute {
+		t.lastSweep = now
+		for id, pending := range t.pending {
+			if now.Sub(pending.at) > pendingTTL {
+				delete(t.pending, id)
+			}
+		}
+	}
+	if len(t.pending) < maxPending {
+		t.pending[requestID] = pendingRequest{key: key, at: now}
+	}
+}
+
+// Observe accounts the cached and cache write tokens of a usage entry to the
+// prefix its request matched. Entries of unmatched requests are ignored.
+func (t *Tracker) Observe(entry *usage.UsageEntry) {
+	if t == nil || entry == nil || entry.RequestID == "" {
+		return
+	}
+	t.mu.Lock()
+	pending, ok := t.pending[entry.RequestID]
+	delete(t.pending, entry.RequestID)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	cached := cachedTokens(entry.RawData)
+	written := rawInt(entry.RawData, "cache_creation_input_tokens")
+	var saved float64
+	if t.pricing != nil {
+		saved = estimateSavings(t.pricing.ResolvePricing(entry.Model, entry.Provider), cached, written)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.prefixes[pending.key]
+	if !ok {
+		return
+	}
+	stats := &state.stats
+	stats.Observed++
+	if cached > 0 {
+		stats.Hits++
+	}
+	stats.InputTokens += int64(entry.InputTokens)
+	stats.CachedTokens += int64(cached)
+	stats.CacheWriteTokens += int64(written)
+	stats.EstimatedSavingsUSD += saved
+}
+
+// Report returns the statistics of every configured prefix and of every
+// detected prompt that was sent cacheable, ordered by cached tokens.
+func (t *Tracker) Report() Report {
+	if t == nil {
+		return Report{Prefixes: []PrefixStats{}}
+	}
+	t.mu.Lock()
+	prefixes := make([]PrefixStats, 0, len(t.prefixes))
+	for _, state := range t.prefixes {
+		if state.stats.Source == SourceDetected && state.stats.Requests == 0 {
+			continue
+		}
+		stats := state.stats
+		if !state.lastSeen.IsZero() {
+			lastSeen := state.lastSeen.UTC()
+			stats.LastSeen = &lastSeen
+		}
+		prefixes = append(prefixes, stats)
+	}
+	t.mu.Unlock()
+
+	report := Report{Enabled: true, Prefixes: prefixes}
+	for i := range prefixes {
+		prefixes[i].HitRate = hitRate(prefixes[i].Hits, prefixes[i].Observed)
+		report.Totals.add(prefixes[i].Counts)
+	}
+	slices.SortFunc(prefixes, func(a, b PrefixStats) int {
+		return cmp.Or(
+			cmp.Compare(b.CachedTokens, a.CachedTokens),
+			cmp.Compare(b.Requests, a.Requests),
+			cmp.Compare(a.Key, b.Key),
+		)
+	})
+	return report
+}
+
+func newStats(key, source, prefix string) PrefixStats {
+	preview := prefix
+	if runes := []rune(preview); len(runes) > previewLength {
+		preview = string(runes[:previewLength]) + "…"
+	}
+	return PrefixStats{Key: key, Source: source, Chars: len(prefix), Preview: preview}
+}
+
+func hashKey(prefix string) string {
+	hash := sha256.Sum256([]byte(prefix))
+	return hex.EncodeToString(hash[:])[:keyLength]
+}
+
+func hitRate(hits, observed int64) float64 {
+	if observed == 0 {
+		return 0
+	}
+	return float64(hits) / float64(observed)
+}
+
+// cachedTokens returns the prompt tokens read from the provider cache:
+// cache_read_input_tokens for Anthropic, cached_tokens or
+// prompt_cached_tokens for OpenAI.
+func cachedTokens(raw map[string]any) int {
+	for _, key := range []string{"cache_read_input_tokens", "prompt_cached_tokens", "cached_tokens"} {
+		if n := rawInt(raw, key); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func rawInt(raw map[string]any, key string) int {
+	switch n := raw[key].(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+// estimateSavings prices cache reads at the difference between the input and
+// cached input rates, less the surcharge of cache writes over the input rate.
+func estimateSavings(pricing *core.ModelPricing, cached, written int) float64 {
+	if pricing == nil || pricing.InputPerMtok == nil {
+		return 0
+	}
+	var saved float64
+	if cached > 0 && pricing.CachedInputPerMtok != nil {
+		saved += float64(cached) * (*pricing.InputPerMtok - *pricing.CachedInputPerMtok) / 1e6
+	}
+	if written > 0 && pricing.CacheWritePerMtok != nil {
+		saved -= float64(written) * (*pricing.CacheWritePerMtok - *pricing.InputPerMtok) / 1e6
+	}
+	return saved
+}
+
+// usageTap forwards usage entries to the wrapped logger and accounts their
+// cached tokens to the tracker.
+type usageTap struct {
+	usage.LoggerInterface
+	tracker *Tracker
+}
+
+func (t *usageTap) Write(entry *usage.UsageEntry) {
+	t.tracker.Observe(entry)
+	t.LoggerInterface.Write(entry)
+}
+
+// WrapUsageLogger returns a usage logger that also feeds cached token counts
+// into tracker. It returns logger unchanged when either argument is nil.
+func WrapUsageLogger(logger usage.LoggerInterface, tracker *Tracker) usage.LoggerInterface {
+	if logger == nil || tracker == nil {
+		return logger
+	}
+	return &usageTap{LoggerInterface: logger, tracker: tracker}
+}
@@ -0,0 +1,196 @@
+package promptcache
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+type recordingUsageLogger struct {
+	entries []*usage.UsageEntry
+}
+
+func (l *recordingUsageLogger) Write(entry *usage.UsageEntry) { l.entries = append(l.entries, entry) }
+func (l *recordingUsageLogger) Config() usage.Config          { return usage.Config{Enabled: true} }
+func (l *recordingUsageLogger) Flush(context.Context) error   { return nil }
+func (l *recordingUsageLogger) Close() error                  { return nil }
+
+type staticPricing struct {
+	pricing *core.ModelPricing
+}
+
+func (p staticPricing) ResolvePricing(string, string) *core.ModelPricing { return p.pricing }
+
+func float(v float64) *float64 { return &v }
+
+func testConfig() config.PromptCachingConfig {
+	return config.PromptCachingConfig{Enabled: true, AutoDetect: true, MinRepeats: 3, MinChars: 10, MaxPrefixes: 100}
+}
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	tracker := New(config.PromptCachingConfig{Prefixes: []string{"You are"}}, nil)
+	if tracker != nil {
+		t.Fatal("New() with prompt caching disabled returned a tracker")
+	}
+	if _, ok := tracker.Match("req-1", "", "gpt-4o", "You are a support agent."); ok {
+		t.Fatal("nil tracker matched a prompt")
+	}
+	if report := tracker.Report(); report.Enabled || len(report.Prefixes) != 0 {
+		t.Fatalf("nil tracker report = %+v", report)
+	}
+}
+
+func TestMatch_ConfiguredPrefixes(t *testing.T) {
+	cfg := testConfig()
+	cfg.AutoDetect = false
+	cfg.Prefixes = []string{"You are", "You are a support agent."}
+	tracker := New(cfg, nil)
+
+	hint, ok := tracker.Match("req-1", "", "gpt-4o", "You are a support agent. Be brief.")
+	if !ok || hint.Prefix != "You are a support agent." || hint.Key != hashKey("You are a support agent.") {
+		t.Fatalf("Match() = %+v, %v; want the longest configured prefix", hint, ok)
+	}
+	if _, ok := tracker.Match("req-2", "", "gpt-4o", "Translate to French."); ok {
+		t.Fatal("Match() matched a prompt without a configured prefix")
+	}
+}
+
+func TestMatch_DetectsRepeatedPrompts(t *testing.T) {
+	tracker := New(testConfig(), nil)
+	system := strings.Repeat("policy ", 4)
+
+	for i := range 2 {
+		if _, ok := tracker.Match("req", "", "claude-sonnet-4-5", system); ok {
+			t.Fatalf("request %d matched before min_repeats", i+1)
+		}
+	}
+	hint, ok := tracker.Match("req-3", "", "claude-sonnet-4-5", system)
+	if !ok || hint.Prefix != system {
+		t.Fatalf("third request Match() = %+v, %v; want the whole system prompt", hint, ok)
+	}
+	if _, ok := tracker.Match("req-4", "", "claude-sonnet-4-5", "too short"); ok {
+		t.Fatal("Match() detected a prompt below min_chars")
+	}
+
+	report := tracker.Report()
+	if len(report.Prefixes) != 1 || report.Prefixes[0].Source != SourceDetected || report.Prefixes[0].Requests != 1 {
+		t.Fatalf("report = %+v, want one detected prefix sent cacheable once", report.Prefixes)
+	}
+}
+
+func TestMatch_ModelScope(t *testing.T) {
+	cfg := testConfig()
+	cfg.Prefixes = []string{"You are"}
+	cfg.Models = []string{"claude-sonnet-4-5", "azure/gpt-4o"}
+	tracker := New(cfg, nil)
+
+	for _, tt := range []struct {
+		provider, model string
+		want            bool
+	}{
+		{"anthropic", "claude-sonnet-4-5", true},
+		{"azure", "gpt-4o", true},
+		{"openai", "gpt-4o", false},
+	} {
+		if _, ok := tracker.Match("req", tt.provider, tt.model, "You are helpful."); ok != tt.want {
+			t.Errorf("Match(%s/%s) = %v, want %v", tt.provider, tt.model, ok, tt.want)
+		}
+	}
+}
+
+func TestMatch_EvictsLeastRecentlySeenDetectedPrompt(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxPrefixes = 2
+	cfg.MinRepeats = 1
+	cfg.Prefixes = []string{"configured prefix"}
+	tracker := New(cfg, nil)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	for _, system := range []string{"first system prompt", "second system prompt", "third system prompt"} {
+		tracker.Match("req", "", "gpt-4o", system)
+		now = now.Add(time.Second)
+	}
+
+	if _, ok := tracker.prefixes[hashKey("first system prompt")]; ok {
+		t.Fatal("least recently seen detected prompt was kept")
+	}
+	if _, ok := tracker.prefixes[hashKey("configured prefix")]; !ok || tracker.detected != 2 {
+		t.Fatalf("detected = %d, configured kept = %v; want 2 detected and the configured prefix", tracker.detected, ok)
+	}
+}
+
+func TestWrapUsageLogger_AccountsHitsFromUsage(t *testing.T) {
+	cfg := testConfig()
+	cfg.Prefixes = []string{"You are a support agent."}
+	pricing := staticPricing{&core.ModelPricing{
+		InputPerMtok:       float(3),
+		CachedInputPerMtok: float(0.3),
+		CacheWritePerMtok:  float(3.75),
+	}}
+	tracker := New(cfg, pricing)
+	inner := &recordingUsageLogger{}
+	logger := WrapUsageLogger(inner, tracker)
+	system := "You are a support agent. Be brief."
+
+	for _, id := range []string{"req-1", "req-2", "req-3", "req-4"} {
+		if _, ok := tracker.Match(id, "", "claude-sonnet-4-5", system); !ok {
+			t.Fatalf("%s did not match", id)
+		}
+	}
+	// The first request writes the cache, the next two read it from the
+	// Anthropic and OpenAI usage fields, and the last misses.
+	logger.Write(&usage.UsageEntry{RequestID: "req-1", Model: "claude-sonnet-4-5", Provider: "anthropic", InputTokens: 50,
+		RawData: map[string]any{"cache_creation_input_tokens": 1000}})
+	logger.Write(&usage.UsageEntry{RequestID: "req-2", Model: "claude-sonnet-4-5", Provider: "anthropic", InputTokens: 50,
+		RawData: map[string]any{"cache_read_input_tokens": float64(1000)}})
+	logger.Write(&usage.UsageEntry{RequestID: "req-3", Model: "gpt-4o", Provider: "openai", InputTokens: 1050,
+		RawData: map[string]any{"prompt_cached_tokens": 1000}})
+	logger.Write(&usage.UsageEntry{RequestID: "req-4", Model: "gpt-4o", Provider: "openai", InputTokens: 1050})
+	logger.Write(&usage.UsageEntry{RequestID: "req-unmatched", Model: "gpt-4o", Provider: "openai", InputTokens: 10,
+		RawData: map[string]any{"prompt_cached_tokens": 500}})
+
+	if len(inner.entries) != 5 {
+		t.Fatalf("inner logger got %d entries, want all 5", len(inner.entries))
+	}
+	report := tracker.Report()
+	if len(report.Prefixes) != 1 {
+		t.Fatalf("prefixes = %+v, want the configured prefix", report.Prefixes)
+	}
+	got := report.Prefixes[0]
+	if got.Source != SourceConfigured || got.Requests != 4 || got.Observed != 4 || got.Hits != 2 || got.HitRate != 0.5 {
+		t.Fatalf("counts = %+v, want 2 hits of 4 observed", got.Counts)
+	}
+	if got.CachedTokens != 2000 || got.CacheWriteTokens != 1000 || got.InputTokens != 2200 {
+		t.Fatalf("tokens = %+v", got.Counts)
+	}
+	// 2000 reads save 2.7/Mtok, 1000 writes cost 0.75/Mtok extra.
+	if want := 0.0054 - 0.00075; math.Abs(got.EstimatedSavingsUSD-want) > 1e-9 {
+		t.Fatalf("EstimatedSavingsUSD = %g, want %g", got.EstimatedSavingsUSD, want)
+	}
+	if report.Totals != got.Counts || got.LastSeen == nil {
+		t.Fatalf("totals = %+v, last seen = %v; want the prefix counts", report.Totals, got.LastSeen)
+	}
+
+	// A usage entry is accounted once.
+	logger.Write(&usage.UsageEntry{RequestID: "req-2", RawData: map[string]any{"cache_read_input_tokens": 1000}})
+	if hits := tracker.Report().Totals.Hits; hits != 2 {
+		t.Fatalf("hits after a repeated entry = %d, want 2", hits)
+	}
+}
+
+func TestWrapUsageLogger_NilArguments(t *testing.T) {
+	inner := &recordingUsageLogger{}
+	if got := WrapUsageLogger(inner, nil); got != inner {
+		t.Fatal("WrapUsageLogger() wrapped the logger without a tracker")
+	}
+	if got := WrapUsageLogger(nil, New(testConfig(), nil)); got != nil {
+		t.Fatal("WrapUsageLogger() wrapped a nil logger")
+	}
+}
@@ -0,0 +1,145 @@
+package prompttemplates
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"gomodel/internal/core"
+)
+
+// maxRenderedBytes bounds the text rendered from one template so a loop in a
+// template cannot grow a request without limit.
+const maxRenderedBytes = 1 << 20
+
+var errRenderedTooLarge = fmt.Errorf("rendered template exceeds %d bytes", maxRenderedBytes)
+
+// templateFuncs are the only functions available to templates besides the
+// text/template builtins. Neither can reach the file system, the
+// environment or the network.
+var templateFuncs = template.FuncMap{
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"join":    joinValues,
+	"default": defaultValue,
+}
+
+// MissingVariablesError lists the required variables a request did not supply.
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return "missing required template variables: " + strings.Join(e.Names, ", ")
+}
+
+// compiledTemplate is a template version with its parsed message contents.
+type compiledTemplate struct {
+	Template
+	contents []*template.Template
+}
+
+func compile(tpl Template) (compiledTemplate, error) {
+	compiled := compiledTemplate{Template: tpl, contents: make([]*template.Template, 0, len(tpl.Messages))}
+	for i, msg := range tpl.Messages {
+		parsed, err := parseContent(tpl.Name, i, msg.Content)
+		if err != nil {
+			return compiledTemplate{}, err
+		}
+		compiled.contents = append(compiled.contents, parsed)
+	}
+	return compiled, nil
+}
+
+func parseContent(name string, index int, content string) (*template.Template, error) {
+	return template.New(fmt.Sprintf("%s/messages[%d]", name, index)).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(content)
+}
+
+// render checks the supplied variables against the declared ones and renders
+// every message of the template.
+func (t compiledTemplate) render(variables map[string]any) ([]core.Message, error) {
+	data := make(map[string]any, len(t.Variables))
+	var missing []string
+	for _, variable := range t.Variables {
+		value, ok := variables[variable.Name]
+		switch {
+		case ok:
+			data[variable.Name] = value
+		case variable.Required:
+			missing = append(missing, variable.Name)
+		case variable.Default != nil:
+			data[variable.Name] = variable.Default
+		default:
+			data[variable.Name] = ""
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: missing}
+	}
+	var unknown []string
+	for name := range variables {
+		if _, ok := data[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, newValidationError("unknown template variables: "+strings.Join(unknown, ", "), nil)
+	}
+
+	out := &limitedWriter{remaining: maxRenderedBytes}
+	messages := make([]core.Message, 0, len(t.contents))
+	for i, content := range t.contents {
+		out.buf.Reset()
+		if err := content.Execute(out, data); err != nil {
+			if errors.Is(err, errRenderedTooLarge) {
+				return nil, newValidationError(errRenderedTooLarge.Error(), err)
+			}
+			return nil, newValidationError(fmt.Sprintf("render template %q version %d: %v", t.Name, t.Version, err), err)
+		}
+		messages = append(messages, core.Message{Role: t.Messages[i].Role, Content: out.buf.String()})
+	}
+	return messages, nil
+}
+
+// limitedWriter collects rendered text and fails once the shared byte budget
+// is spent.
+type limitedWriter struct {
+	buf       strings.Builder
+	remaining int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		return 0, errRenderedTooLarge
+	}
+	w.remaining -= len(p)
+	return w.buf.Write(p)
+}
+
+func joinValues(values []any, sep string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, fmt.Sprint(value))
+	}
+	return strings.Join(parts, sep)
+}
+
+func defaultValue(fallback, value any) any {
+	if value == nil {
+		return fallback
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return fallback
+	}
+	return value
+}
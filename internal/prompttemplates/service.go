@@ -0,0 +1,292 @@
+package prompttemplates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// maxCreateAttempts bounds the retries of Create when another gateway
+// instance stores the same version first.
+const maxCreateAttempts = 3
+
+type snapshot struct {
+	// versions holds the compiled versions of each template, oldest first.
+	versions map[string][]compiledTemplate
+	order    []string
+}
+
+// Service keeps template versions cached in memory, compiled and ready to
+// render, and refreshes them from storage.
+type Service struct {
+	store Store
+
+	writeMu  sync.Mutex
+	mu       sync.RWMutex
+	snapshot snapshot
+}
+
+// NewService creates a template service backed by the provided store.
+func NewService(store Store) (*Service, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+	return &Service{store: store}, nil
+}
+
+// Refresh reloads templates from storage and atomically swaps the in-memory snapshot.
+func (s *Service) Refresh(ctx context.Context) error {
+	templates, err := s.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list templates: %w", err)
+	}
+
+	next := snapshot{versions: make(map[string][]compiledTemplate)}
+	for _, tpl := range templates {
+		compiled, err := compile(tpl)
+		if err != nil {
+			return fmt.Errorf("load template %q version %d: %w", tpl.Name, tpl.Version, err)
+		}
+		if _, ok := next.versions[tpl.Name]; !ok {
+			next.order = append(next.order, tpl.Name)
+		}
+		next.versions[tpl.Name] = append(next.versions[tpl.Name], compiled)
+	}
+	for _, versions := range next.versions {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+	sort.Strings(next.order)
+
+	s.mu.Lock()
+	s.snapshot = next
+	s.mu.Unlock()
+	return nil
+}
+
+// List returns the latest version of every template sorted by name.
+func (s *Service) List() []View {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]View, 0, len(s.snapshot.order))
+	for _, name := range s.snapshot.order {
+		versions := s.snapshot.versions[name]
+		view := View{Template: versions[len(versions)-1].Template, Versions: make([]int, 0, len(versions))}
+		for _, version := range versions {
+			view.Versions = append(view.Versions, version.Version)
+		}
+		result = append(result, view)
+	}
+	return result
+}
+
+// Versions returns every cached version of a template, oldest first.
+func (s *Service) Versions(name string) ([]Template, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions, ok := s.snapshot.versions[normalizeName(name)]
+	if !ok {
+		return nil, false
+	}
+	result := make([]Template, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, version.Template)
+	}
+	return result, true
+}
+
+// Get returns one cached template version. Version zero selects the latest.
+func (s *Service) Get(name string, version int) (*Template, bool) {
+	compiled, ok := s.lookup(name, version)
+	if !ok {
+		return nil, false
+	}
+	tpl := compiled.Template
+	return &tpl, true
+}
+
+func (s *Service) lookup(name string, version int) (compiledTemplate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.snapshot.versions[normalizeName(name)]
+	if len(versions) == 0 {
+		return compiledTemplate{}, false
+	}
+	if version == 0 {
+		return versions[len(versions)-1], true
+	}
+	for _, compiled := range versions {
+		if compiled.Version == version {
+			return compiled, true
+		}
+	}
+	return compiledTemplate{}, false
+}
+
+// Create validates a template and stores it as the next version of its name,
+// then refreshes the in-memory snapshot.
+func (s *Service) Create(ctx context.Context, tpl Template) (*Template, error) {
+	normalized, err := normalizeTemplate(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	for attempt := 1; ; attempt++ {
+		normalized.Version = s.latestVersion(normalized.Name) + 1
+		normalized.CreatedAt = time.Now().UTC()
+		err := s.store.Insert(ctx, normalized)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrVersionExists) || attempt == maxCreateAttempts {
+			return nil, fmt.Errorf("insert template: %w", err)
+		}
+		if err := s.Refresh(ctx); err != nil {
+			return nil, fmt.Errorf("refresh templates: %w", err)
+		}
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("refresh templates: %w", err)
+	}
+	created, ok := s.Get(normalized.Name, normalized.Version)
+	if !ok {
+		return &normalized, nil
+	}
+	return created, nil
+}
+
+// Import stores a template under its own version number and creation time,
+// as restored from a state export, then refreshes the in-memory snapshot. It
+// returns ErrVersionExists when the version is already stored.
+func (s *Service) Import(ctx context.Context, tpl Template) error {
+	version, createdAt := tpl.Version, tpl.CreatedAt
+	normalized, err := normalizeTemplate(tpl)
+	if err != nil {
+		return err
+	}
+	if version <= 0 {
+		return newValidationError("template version must be positive", nil)
+	}
+	normalized.Version = version
+	normalized.CreatedAt = createdAt.UTC()
+	if normalized.CreatedAt.IsZero() {
+		normalized.CreatedAt = time.Now().UTC()
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.store.Insert(ctx, normalized); err != nil {
+		return fmt.Errorf("insert template: %w", err)
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return fmt.Errorf("refresh templates: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) latestVersion(name string) int {
+	if latest, ok := s.lookup(name, 0); ok {
+		return latest.Version
+	}
+	return 0
+}
+
+// Delete removes one template version, or every version when version is
+// zero, and refreshes the in-memory snapshot.
+func (s *Service) Delete(ctx context.Context, name string, version int) error {
+	name = normalizeName(name)
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if version < 0 {
+		return newValidationError("template version must be positive", nil)
+	}
+	if err := s.store.Delete(ctx, name, version); err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return fmt.Errorf("refresh templates: %w", err)
+	}
+	return nil
+}
+
+// Render renders a template version with the supplied variables. Version zero
+// selects the latest version. It returns ErrNotFound for unknown templates,
+// a *MissingVariablesError when required variables are absent and a
+// *ValidationError for other variable or rendering failures.
+func (s *Service) Render(name string, version int, variables map[string]any) (core.PromptTemplateUse, []core.Message, error) {
+	compiled, ok := s.lookup(name, version)
+	if !ok {
+		return core.PromptTemplateUse{}, nil, ErrNotFound
+	}
+	messages, err := compiled.render(variables)
+	if err != nil {
+		return core.PromptTemplateUse{}, nil, err
+	}
+	return core.PromptTemplateUse{Name: compiled.Name, Version: compiled.Version}, messages, nil
+}
+
+// RenderPromptTemplate renders the template a data-plane request refers to,
+// reporting failures as client errors.
+func (s *Service) RenderPromptTemplate(ref core.PromptTemplateRef) (core.PromptTemplateUse, []core.Message, error) {
+	use, messages, err := s.Render(ref.Name, ref.Version, ref.Variables)
+	if err == nil {
+		return use, messages, nil
+	}
+	if errors.Is(err, ErrNotFound) {
+		message := "template not found: " + ref.Name
+		if ref.Version > 0 {
+			message = fmt.Sprintf("template %q has no version %d", ref.Name, ref.Version)
+		}
+		return core.PromptTemplateUse{}, nil, core.NewNotFoundError(message).WithParam(core.PromptTemplateField)
+	}
+	if missing, ok := errors.AsType[*MissingVariablesError](err); ok {
+		return core.PromptTemplateUse{}, nil, core.NewInvalidRequestError(missing.Error(), err).
+			WithParam(core.PromptTemplateField + ".variables").
+			WithCode("missing_template_variables")
+	}
+	return core.PromptTemplateUse{}, nil, core.NewInvalidRequestError(err.Error(), err).WithParam(core.PromptTemplateField)
+}
+
+// StartBackgroundRefresh periodically reloads templates from storage until stopped.
+func (s *Service) StartBackgroundRefresh(interval time.Duration) func() {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refreshCtx, refreshCancel := context.WithTimeout(ctx, 30*time.Second)
+				_ = s.Refresh(refreshCtx)
+				refreshCancel()
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
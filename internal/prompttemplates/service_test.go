@@ -0,0 +1,231 @@
+package prompttemplates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"gomodel/internal/core"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	service, err := NewService(store)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	return service
+}
+
+func supportTemplate(system string) Template {
+	return Template{
+		Name: "support",
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: "{{.question}}"},
+		},
+		Variables: []Variable{
+			{Name: "product", Required: true},
+			{Name: "question", Required: true},
+			{Name: "tone", Default: "friendly"},
+		},
+	}
+}
+
+func TestServiceRender(t *testing.T) {
+	service := newTestService(t)
+	if _, err := service.Create(context.Background(), supportTemplate("You support {{.product}}. Be {{.tone}}.")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	use, messages, err := service.Render("support", 0, map[string]any{
+		"product":  "GoModel",
+		"question": "How do I add a provider?",
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if use != (core.PromptTemplateUse{Name: "support", Version: 1}) {
+		t.Fatalf("use = %+v, want support v1", use)
+	}
+	want := []core.Message{
+		{Role: "system", Content: "You support GoModel. Be friendly."},
+		{Role: "user", Content: "How do I add a provider?"},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("messages = %#v, want %#v", messages, want)
+	}
+
+	_, _, err = service.Render("support", 0, map[string]any{"product": "GoModel", "question": "hi", "topic": "setup"})
+	if !IsValidationError(err) || err.Error() != "unknown template variables: topic" {
+		t.Fatalf("Render() error = %v, want unknown variable error", err)
+	}
+}
+
+func TestServiceRender_ReportsMissingRequiredVariables(t *testing.T) {
+	service := newTestService(t)
+	if _, err := service.Create(context.Background(), supportTemplate("You support {{.product}}.")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, _, err := service.Render("support", 0, map[string]any{"tone": "terse"})
+	missing, ok := errors.AsType[*MissingVariablesError](err)
+	if !ok {
+		t.Fatalf("Render() error = %v, want *MissingVariablesError", err)
+	}
+	if !reflect.DeepEqual(missing.Names, []string{"product", "question"}) {
+		t.Fatalf("missing = %v, want [product question]", missing.Names)
+	}
+
+	_, _, err = service.RenderPromptTemplate(core.PromptTemplateRef{Name: "support", Variables: map[string]any{"product": "GoModel"}})
+	gatewayErr, ok := errors.AsType[*core.GatewayError](err)
+	if !ok || gatewayErr.HTTPStatusCode() != http.StatusBadRequest {
+		t.Fatalf("RenderPromptTemplate() error = %v, want 400 gateway error", err)
+	}
+	if gatewayErr.Message != "missing required template variables: question" {
+		t.Fatalf("message = %q", gatewayErr.Message)
+	}
+}
+
+func TestServiceRender_PinsVersions(t *testing.T) {
+	service := newTestService(t)
+	ctx := context.Background()
+	first, err := service.Create(ctx, supportTemplate("v1 for {{.product}}"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := service.Create(ctx, supportTemplate("v2 for {{.product}}"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if first.Version != 1 || second.Version != 2 {
+		t.Fatalf("versions = %d, %d, want 1, 2", first.Version, second.Version)
+	}
+
+	vars := map[string]any{"product": "GoModel", "question": "hi"}
+	for _, tc := range []struct {
+		version int
+		want    string
+	}{
+		{version: 0, want: "v2 for GoModel"},
+		{version: 1, want: "v1 for GoModel"},
+		{version: 2, want: "v2 for GoModel"},
+	} {
+		use, messages, err := service.Render("support", tc.version, vars)
+		if err != nil {
+			t.Fatalf("Render(version %d) error = %v", tc.version, err)
+		}
+		if messages[0].Content != tc.want {
+			t.Fatalf("Render(version %d) system = %q, want %q", tc.version, messages[0].Content, tc.want)
+		}
+		if tc.version != 0 && use.Version != tc.version {
+			t.Fatalf("Render(version %d) used version %d", tc.version, use.Version)
+		}
+	}
+
+	if _, _, err := service.Render("support", 3, vars); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Render(version 3) error = %v, want ErrNotFound", err)
+	}
+
+	if err := service.Delete(ctx, "support", 2); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	use, _, err := service.Render("support", 0, vars)
+	if err != nil || use.Version != 1 {
+		t.Fatalf("Render(latest) after delete = %+v, %v, want version 1", use, err)
+	}
+	views := service.List()
+	if len(views) != 1 || !reflect.DeepEqual(views[0].Versions, []int{1}) {
+		t.Fatalf("List() = %+v, want support with version 1", views)
+	}
+}
+
+func TestServiceCreate_RejectsInvalidTemplates(t *testing.T) {
+	service := newTestService(t)
+	tests := []struct {
+		name     string
+		template Template
+		want     string
+	}{
+		{
+			name:     "bad name",
+			template: Template{Name: "bad name", Messages: []Message{{Role: "user", Content: "hi"}}},
+			want:     "invalid template name",
+		},
+		{
+			name:     "no messages",
+			template: Template{Name: "empty"},
+			want:     "at least one message",
+		},
+		{
+			name:     "bad role",
+			template: Template{Name: "role", Messages: []Message{{Role: "tool", Content: "hi"}}},
+			want:     `invalid role "tool"`,
+		},
+		{
+			name:     "parse error",
+			template: Template{Name: "parse", Messages: []Message{{Role: "user", Content: "{{.name"}}},
+			want:     "messages[0]",
+		},
+		{
+			name:     "undefined function",
+			template: Template{Name: "env", Messages: []Message{{Role: "user", Content: `{{env "HOME"}}`}}},
+			want:     `function "env" not defined`,
+		},
+		{
+			name: "duplicate variable",
+			template: Template{Name: "dup", Messages: []Message{{Role: "user", Content: "hi"}}, Variables: []Variable{
+				{Name: "a"}, {Name: "a"},
+			}},
+			want: `variable "a" is declared twice`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(context.Background(), tt.template)
+			if !IsValidationError(err) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Create() error = %v, want validation error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceRender_BoundsRenderedSize(t *testing.T) {
+	service := newTestService(t)
+	_, err := service.Create(context.Background(), Template{
+		Name:     "loop",
+		Messages: []Message{{Role: "user", Content: `{{range 2000000}}{{$.text}}{{end}}`}},
+		Variables: []Variable{
+			{Name: "text", Required: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, _, err = service.Render("loop", 0, map[string]any{"text": "xxxx"})
+	if !IsValidationError(err) || !errors.Is(err, errRenderedTooLarge) {
+		t.Fatalf("Render() error = %v, want rendered size error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package prompttemplates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound indicates a requested template or template version was not found.
+var ErrNotFound = errors.New("template not found")
+
+// ErrVersionExists indicates a template version was already stored, usually by
+// a concurrent writer.
+var ErrVersionExists = errors.New("template version already exists")
+
+// ValidationError indicates invalid template input.
+type ValidationError struct {
+	Message string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+func newValidationError(message string, err error) error {
+	return &ValidationError{Message: message, Err: err}
+}
+
+// IsValidationError reports whether err is a validation error.
+func IsValidationError(err error) bool {
+	_, ok := errors.AsType[*ValidationError](err)
+	return ok
+}
+
+// Store defines persistence operations for template versions. Versions are
+// immutable: a change to a template is stored as a new version.
+type Store interface {
+	// List returns every stored version ordered by name and version.
+	List(ctx context.Context) ([]Template, error)
+	// Insert stores a new version and returns ErrVersionExists when the
+	// name and version pair is taken.
+	Insert(ctx context.Context, template Template) error
+	// Delete removes one version, or every version of name when version is
+	// zero, and returns ErrNotFound when nothing was removed.
+	Delete(ctx context.Context, name string, version int) error
+	Close() error
+}
+
+type templateScanner interface {
+	Scan(dest ...any) error
+}
+
+type templateRows interface {
+	templateScanner
+	Next() bool
+	Err() error
+}
+
+const maxNameLength = 128
+
+var (
+	templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+var messageRoles = map[string]struct{}{
+	"system":    {},
+	"developer": {},
+	"user":      {},
+	"assistant": {},
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSpace(name)
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return newValidationError("template name is required", nil)
+	}
+	if len(name) > maxNameLength || !templateNamePattern.MatchString(name) {
+		return newValidationError(fmt.Sprintf("invalid template name %q: use letters, digits, '.', '_' and '-'", name), nil)
+	}
+	return nil
+}
+
+// normalizeTemplate trims the template input and checks that its messages
+// parse and its variables are well formed.
+func normalizeTemplate(template Template) (Template, error) {
+	template.Name = normalizeName(template.Name)
+	template.Description = strings.TrimSpace(template.Description)
+	if err := validateName(template.Name); err != nil {
+		return Template{}, err
+	}
+
+	if len(template.Messages) == 0 {
+		return Template{}, newValidationError("template must have at least one message", nil)
+	}
+	messages := make([]Message, 0, len(template.Messages))
+	for i, msg := range template.Messages {
+		msg.Role = strings.ToLower(strings.TrimSpace(msg.Role))
+		if _, ok := messageRoles[msg.Role]; !ok {
+			return Template{}, newValidationError(fmt.Sprintf("messages[%d]: invalid role %q", i, msg.Role), nil)
+		}
+		if strings.TrimSpace(msg.Content) == "" {
+			return Template{}, newValidationError(fmt.Sprintf("messages[%d]: content is required", i), nil)
+		}
+		if _, err := parseContent(template.Name, i, msg.Content); err != nil {
+			return Template{}, newValidationError(fmt.Sprintf("messages[%d]: %v", i, err), err)
+		}
+		messages = append(messages, msg)
+	}
+	template.Messages = messages
+
+	variables := make([]Variable, 0, len(template.Variables))
+	seen := make(map[string]struct{}, len(template.Variables))
+	for _, variable := range template.Variables {
+		variable.Name = strings.TrimSpace(variable.Name)
+		variable.Description = strings.TrimSpace(variable.Description)
+		if !variableNamePattern.MatchString(variable.Name) {
+			return Template{}, newValidationError(fmt.Sprintf("invalid variable name %q", variable.Name), nil)
+		}
+		if _, ok := seen[variable.Name]; ok {
+			return Template{}, newValidationError(fmt.Sprintf("variable %q is declared twice", variable.Name), nil)
+		}
+		if variable.Required && variable.Default != nil {
+			return Template{}, newValidationError(fmt.Sprintf("required variable %q cannot have a default", variable.Name), nil)
+		}
+		seen[variable.Name] = struct{}{}
+		variables = append(variables, variable)
+	}
+	template.Variables = variables
+	return template, nil
+}
+
+func collectTemplates(rows templateRows, scan func(templateScanner) (Template, error)) ([]Template, error) {
+	result := make([]Template, 0)
+	for rows.Next() {
+		template, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
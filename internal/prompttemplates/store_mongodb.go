@@ -0,0 +1,116 @@
+package prompttemplates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoTemplateDocument struct {
+	Name        string     `bson:"name"`
+	Version     int        `bson:"version"`
+	Description string     `bson:"description,omitempty"`
+	Messages    []Message  `bson:"messages"`
+	Variables   []Variable `bson:"variables,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at"`
+}
+
+// MongoDBStore stores template versions in MongoDB.
+type MongoDBStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBStore creates collection indexes if needed.
+func NewMongoDBStore(database *mongo.Database) (*MongoDBStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	coll := database.Collection("prompt_templates")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := coll.Indexes().CreateMany(ctx, indexes); err != nil {
+		return nil, fmt.Errorf("create prompt_templates indexes: %w", err)
+	}
+	return &MongoDBStore{collection: coll}, nil
+}
+
+func (s *MongoDBStore) List(ctx context.Context) ([]Template, error) {
+	sort := bson.D{{Key: "name", Value: 1}, {Key: "version", Value: 1}}
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(sort))
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]Template, 0)
+	for cursor.Next(ctx) {
+		var doc mongoTemplateDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode template: %w", err)
+		}
+		result = append(result, templateFromMongo(doc))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("iterate templates: %w", err)
+	}
+	return result, nil
+}
+
+func (s *MongoDBStore) Insert(ctx context.Context, template Template) error {
+	_, err := s.collection.InsertOne(ctx, mongoTemplateDocument{
+		Name:        template.Name,
+		Version:     template.Version,
+		Description: template.Description,
+		Messages:    template.Messages,
+		Variables:   template.Variables,
+		CreatedAt:   template.CreatedAt,
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrVersionExists
+		}
+		return fmt.Errorf("insert template: %w", err)
+	}
+	return nil
+}
+
+func (s *MongoDBStore) Delete(ctx context.Context, name string, version int) error {
+	filter := bson.M{"name": normalizeName(name)}
+	if version != 0 {
+		filter["version"] = version
+	}
+	result, err := s.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoDBStore) Close() error {
+	return nil
+}
+
+func templateFromMongo(doc mongoTemplateDocument) Template {
+	return Template{
+		Name:        doc.Name,
+		Version:     doc.Version,
+		Description: doc.Description,
+		Messages:    doc.Messages,
+		Variables:   doc.Variables,
+		CreatedAt:   doc.CreatedAt.UTC(),
+	}
+}
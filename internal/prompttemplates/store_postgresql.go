@@ -0,0 +1,110 @@
+package prompttemplates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLStore stores template versions in PostgreSQL.
+type PostgreSQLStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLStore creates the prompt_templates table if needed.
+func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLStore, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is required")
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("connection pool is required")
+	}
+
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the prompt_templates table. It is
+// idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS prompt_templates (
+			name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			messages TEXT NOT NULL,
+			variables TEXT NOT NULL DEFAULT '[]',
+			created_at BIGINT NOT NULL,
+			PRIMARY KEY (name, version)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create prompt_templates table: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgreSQLStore) List(ctx context.Context) ([]Template, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT name, version, description, messages, variables, created_at
+		FROM prompt_templates
+		ORDER BY name ASC, version ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	defer rows.Close()
+	result, err := collectTemplates(rows, scanSQLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("iterate templates: %w", err)
+	}
+	return result, nil
+}
+
+func (s *PostgreSQLStore) Insert(ctx context.Context, template Template) error {
+	messages, variables, err := marshalTemplateContent(template)
+	if err != nil {
+		return err
+	}
+	cmd, err := s.pool.Exec(ctx, `
+		INSERT INTO prompt_templates (name, version, description, messages, variables, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name, version) DO NOTHING
+	`, template.Name, template.Version, template.Description, messages, variables, template.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("insert template: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrVersionExists
+	}
+	return nil
+}
+
+func (s *PostgreSQLStore) Delete(ctx context.Context, name string, version int) error {
+	var (
+		cmd pgconn.CommandTag
+		err error
+	)
+	if version == 0 {
+		cmd, err = s.pool.Exec(ctx, `DELETE FROM prompt_templates WHERE name = $1`, normalizeName(name))
+	} else {
+		cmd, err = s.pool.Exec(ctx, `DELETE FROM prompt_templates WHERE name = $1 AND version = $2`, normalizeName(name), version)
+	}
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgreSQLStore) Close() error {
+	return nil
+}
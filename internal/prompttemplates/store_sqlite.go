@@ -0,0 +1,158 @@
+package prompttemplates
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SQLiteStore stores template versions in SQLite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the prompt_templates table if needed.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is required")
+	}
+
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the prompt_templates table. It is
+// idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS prompt_templates (
+			name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			messages TEXT NOT NULL,
+			variables TEXT NOT NULL DEFAULT '[]',
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (name, version)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create prompt_templates table: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]Template, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, version, description, messages, variables, created_at
+		FROM prompt_templates
+		ORDER BY name ASC, version ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	defer rows.Close()
+	result, err := collectTemplates(rows, scanSQLTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("iterate templates: %w", err)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) Insert(ctx context.Context, template Template) error {
+	messages, variables, err := marshalTemplateContent(template)
+	if err != nil {
+		return err
+	}
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO prompt_templates (name, version, description, messages, variables, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name, version) DO NOTHING
+	`, template.Name, template.Version, template.Description, messages, variables, template.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("insert template: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read insert rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrVersionExists
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, name string, version int) error {
+	var (
+		result sql.Result
+		err    error
+	)
+	if version == 0 {
+		result, err = s.db.ExecContext(ctx, `DELETE FROM prompt_templates WHERE name = ?`, normalizeName(name))
+	} else {
+		result, err = s.db.ExecContext(ctx, `DELETE FROM prompt_templates WHERE name = ? AND version = ?`, normalizeName(name), version)
+	}
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read delete rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return nil
+}
+
+func marshalTemplateContent(template Template) (string, string, error) {
+	messages, err := json.Marshal(template.Messages)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal template messages: %w", err)
+	}
+	variables := template.Variables
+	if variables == nil {
+		variables = []Variable{}
+	}
+	encodedVariables, err := json.Marshal(variables)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal template variables: %w", err)
+	}
+	return string(messages), string(encodedVariables), nil
+}
+
+// scanSQLTemplate scans a template row of either SQL backend; both store the
+// messages and variables as JSON text and created_at as unix seconds.
+func scanSQLTemplate(scanner templateScanner) (Template, error) {
+	var template Template
+	var messages string
+	var variables string
+	var createdAt int64
+	if err := scanner.Scan(
+		&template.Name,
+		&template.Version,
+		&template.Description,
+		&messages,
+		&variables,
+		&createdAt,
+	); err != nil {
+		return Template{}, err
+	}
+	if err := json.Unmarshal([]byte(messages), &template.Messages); err != nil {
+		return Template{}, fmt.Errorf("decode messages of template %q: %w", template.Name, err)
+	}
+	if err := json.Unmarshal([]byte(variables), &template.Variables); err != nil {
+		return Template{}, fmt.Errorf("decode variables of template %q: %w", template.Name, err)
+	}
+	template.CreatedAt = time.Unix(createdAt, 0).UTC()
+	return template, nil
+}
@@ -0,0 +1,37 @@
+package prompttemplates
+
+import "time"
+
+// Template is one immutable version of a named prompt template. Message
+// contents are Go text/template sources rendered against the request
+// variables.
+type Template struct {
+	Name        string     `json:"name" bson:"name"`
+	Version     int        `json:"version" bson:"version"`
+	Description string     `json:"description,omitempty" bson:"description,omitempty"`
+	Messages    []Message  `json:"messages" bson:"messages"`
+	Variables   []Variable `json:"variables,omitempty" bson:"variables,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
+}
+
+// Message is one templated message of a prompt template.
+type Message struct {
+	Role    string `json:"role" bson:"role"`
+	Content string `json:"content" bson:"content"`
+}
+
+// Variable declares an input of a prompt template. Optional variables fall
+// back to Default when the request does not supply them.
+type Variable struct {
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	Required    bool   `json:"required,omitempty" bson:"required,omitempty"`
+	Default     any    `json:"default,omitempty" bson:"default,omitempty"`
+}
+
+// View is the admin-facing summary of a template: its latest version and the
+// versions still stored.
+type View struct {
+	Template
+	Versions []int `json:"versions"`
+}
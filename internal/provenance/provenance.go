@@ -0,0 +1,216 @@
+// Package provenance marks model responses with where and when the gateway
+// served them, and signs that record so it can be verified later.
+//
+// Provenance only exists when it is enabled in config: New returns a nil
+// Signer otherwise and the server does not install its middleware. Headers
+// carry the request ID, provider, model and timestamp. With embedding enabled,
+// non-streaming JSON responses also carry a gomodel_provenance object holding
+// the same fields, a hash of the response content and an HMAC-SHA256 over the
+// request ID, model and content hash.
+package provenance
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gomodel/config"
+)
+
+// Field is the top-level JSON field embedded provenance is stored under.
+const Field = "gomodel_provenance"
+
+// Response headers set on every model response while provenance is enabled.
+const (
+	RequestIDHeader = "X-GoModel-Provenance-Request-ID"
+	ProviderHeader  = "X-GoModel-Provenance-Provider"
+	ModelHeader     = "X-GoModel-Provenance-Model"
+	TimestampHeader = "X-GoModel-Provenance-Timestamp"
+)
+
+// OptOutHeader is the request header a client sets to "off" to skip embedded
+// provenance. Headers are still set.
+const OptOutHeader = "X-GoModel-Provenance"
+
+// Provenance records which gateway request served a response.
+type Provenance struct {
+	RequestID     string `json:"request_id"`
+	Provider      string `json:"provider,omitempty"`
+	Model         string `json:"model"`
+	Timestamp     string `json:"timestamp"`
+	ContentSHA256 string `json:"content_sha256,omitempty"`
+	Signature     string `json:"signature,omitempty"`
+}
+
+// Result is the outcome of verifying a response against its provenance.
+type Result struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Signer sets provenance headers and signs embedded provenance.
+type Signer struct {
+	embed  bool
+	secret []byte
+	now    func() time.Time
+}
+
+// New returns a Signer for cfg, or nil when provenance is disabled.
+func New(cfg config.ProvenanceConfig) *Signer {
+	if !cfg.Enabled {
+		return nil
+	}
+	secret := strings.TrimSpace(cfg.Secret)
+	return &Signer{
+		embed:  cfg.Embed && secret != "",
+		secret: []byte(secret),
+		now:    time.Now,
+	}
+}
+
+// Embeds reports whether non-streaming JSON responses get a signed
+// gomodel_provenance object.
+func (s *Signer) Embeds() bool {
+	return s != nil && s.embed
+}
+
+// CanVerify reports whether the signer holds a secret to verify signatures.
+func (s *Signer) CanVerify() bool {
+	return s != nil && len(s.secret) > 0
+}
+
+// OptedOut reports whether a request header value asks to skip embedding.
+func OptedOut(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "off", "false", "0", "none":
+		return true
+	default:
+		return false
+	}
+}
+
+// Stamp returns the unsigned provenance of a response served now.
+func (s *Signer) Stamp(requestID, provider, model string) Provenance {
+	return Provenance{
+		RequestID: requestID,
+		Provider:  provider,
+		Model:     model,
+		Timestamp: s.now().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// Embed hashes and signs body and returns it with p added under Field. The
+// original bytes are kept as they are; only the field is inserted before the
+// closing brace. Bodies that are not JSON objects are returned unchanged with
+// ok false.
+func (s *Signer) Embed(body []byte, p Provenance) ([]byte, bool) {
+	if !s.Embeds() {
+		return body, false
+	}
+	trimmed := bytes.TrimRight(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[len(trimmed)-1] != '}' {
+		return body, false
+	}
+	hash, err := ContentHash(body)
+	if err != nil {
+		return body, false
+	}
+	p.ContentSHA256 = hash
+	p.Signature = s.sign(p.RequestID, p.Model, hash)
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		return body, false
+	}
+
+	head := bytes.TrimRight(trimmed[:len(trimmed)-1], " \t\r\n")
+	out := make([]byte, 0, len(body)+len(Field)+len(encoded)+4)
+	out = append(out, head...)
+	if head[len(head)-1] != '{' {
+		out = append(out, ',')
+	}
+	out = append(out, '"')
+	out = append(out, Field...)
+	out = append(out, '"', ':')
+	out = append(out, encoded...)
+	out = append(out, '}')
+	return out, true
+}
+
+// Verify checks body against claimed provenance. When claimed is nil the
+// provenance embedded in body is used. Content is compared by hash first, so a
+// tampered body is reported as such even when the signature itself is intact.
+func (s *Signer) Verify(body []byte, claimed *Provenance) Result {
+	if claimed == nil {
+		embedded, err := Extract(body)
+		if err != nil {
+			return Result{Reason: err.Error()}
+		}
+		claimed = embedded
+	}
+	if claimed.Signature == "" {
+		return Result{Reason: "provenance has no signature"}
+	}
+	hash, err := ContentHash(body)
+	if err != nil {
+		return Result{Reason: err.Error()}
+	}
+	if claimed.ContentSHA256 != "" && claimed.ContentSHA256 != hash {
+		return Result{Reason: "response content does not match the provenance content hash"}
+	}
+	expected := s.sign(claimed.RequestID, claimed.Model, hash)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(claimed.Signature))) {
+		return Result{Reason: "signature does not match the response"}
+	}
+	return Result{Valid: true}
+}
+
+// Extract returns the provenance embedded in body.
+func Extract(body []byte) (*Provenance, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, errors.New("response is not a JSON object")
+	}
+	raw, ok := fields[Field]
+	if !ok {
+		return nil, errors.New("response has no " + Field + " object")
+	}
+	var p Provenance
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, errors.New("invalid " + Field + " object")
+	}
+	return &p, nil
+}
+
+// ContentHash returns the hex SHA-256 of body without its provenance field.
+// The body is re-encoded first, so whitespace and key order do not change the
+// hash while any change to a value does.
+func ContentHash(body []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err := decoder.Decode(&fields); err != nil {
+		return "", errors.New("response is not a JSON object")
+	}
+	delete(fields, Field)
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *Signer) sign(requestID, model, contentHash string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(requestID))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(model))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(contentHash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,131 @@
+package provenance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+)
+
+const testBody = `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"The answer is 42."}}],"usage":{"prompt_tokens":5,"completion_tokens":6}}`
+
+func newTestSigner(t *testing.T, secret string) *Signer {
+	t.Helper()
+	signer := New(config.ProvenanceConfig{Enabled: true, Embed: true, Secret: secret})
+	signer.now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+	return signer
+}
+
+func embedTestBody(t *testing.T, signer *Signer) []byte {
+	t.Helper()
+	body, ok := signer.Embed([]byte(testBody), signer.Stamp("req-1", "openai", "gpt-4o-mini"))
+	if !ok {
+		t.Fatal("Embed() ok = false")
+	}
+	return body
+}
+
+func TestNewDisabledReturnsNil(t *testing.T) {
+	signer := New(config.ProvenanceConfig{Embed: true, Secret: "s"})
+	if signer != nil {
+		t.Fatal("New() returned a signer while disabled")
+	}
+	if signer.Embeds() || signer.CanVerify() {
+		t.Fatal("nil signer reports embedding or verification")
+	}
+}
+
+func TestEmbedKeepsOriginalBytes(t *testing.T) {
+	body := embedTestBody(t, newTestSigner(t, "secret"))
+	if !strings.HasPrefix(string(body), testBody[:len(testBody)-1]+`,"gomodel_provenance":{"request_id":"req-1","provider":"openai","model":"gpt-4o-mini","timestamp":"2026-10-15T12:00:00Z"`) {
+		t.Fatalf("Embed() = %s, want the original body followed by the provenance field", body)
+	}
+
+	p, err := Extract(body)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(p.ContentSHA256) != 64 || len(p.Signature) != 64 {
+		t.Fatalf("Extract() = %+v, want a content hash and a signature", p)
+	}
+
+	if _, ok := newTestSigner(t, "secret").Embed([]byte(`[1,2]`), Provenance{}); ok {
+		t.Fatal("Embed() embedded into a JSON array")
+	}
+	if empty, ok := newTestSigner(t, "secret").Embed([]byte("{}\n"), Provenance{RequestID: "r"}); !ok || !strings.HasPrefix(string(empty), `{"gomodel_provenance":`) {
+		t.Fatalf("Embed(empty object) = %s, %v", empty, ok)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signer := newTestSigner(t, "secret")
+	body := embedTestBody(t, signer)
+	claimed, err := Extract(body)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	stripped := []byte(testBody)
+
+	reformatted := `{
+  "usage": {"completion_tokens": 6, "prompt_tokens": 5},
+  "model": "gpt-4o-mini",
+  "object": "chat.completion",
+  "id": "chatcmpl-1",
+  "choices": [{"message": {"content": "The answer is 42.", "role": "assistant"}, "index": 0}]
+}`
+	withModel := *claimed
+	withModel.Model = "gpt-4o"
+	withRequest := *claimed
+	withRequest.RequestID = "req-2"
+
+	tests := []struct {
+		name      string
+		signer    *Signer
+		body      []byte
+		claimed   *Provenance
+		wantValid bool
+		wantIn    string
+	}{
+		{name: "embedded", signer: signer, body: body, wantValid: true},
+		{name: "claimed separately", signer: signer, body: stripped, claimed: claimed, wantValid: true},
+		{name: "reformatted and reordered", signer: signer, body: []byte(reformatted), claimed: claimed, wantValid: true},
+		{
+			name:   "tampered content",
+			signer: signer,
+			body:   []byte(strings.Replace(string(body), "The answer is 42.", "The answer is 41.", 1)),
+			wantIn: "content does not match",
+		},
+		{
+			name:    "tampered content with hash omitted",
+			signer:  signer,
+			body:    []byte(strings.Replace(testBody, "42", "41", 1)),
+			claimed: &Provenance{RequestID: claimed.RequestID, Model: claimed.Model, Signature: claimed.Signature},
+			wantIn:  "signature does not match",
+		},
+		{name: "changed model", signer: signer, body: stripped, claimed: &withModel, wantIn: "signature does not match"},
+		{name: "changed request id", signer: signer, body: stripped, claimed: &withRequest, wantIn: "signature does not match"},
+		{name: "other secret", signer: newTestSigner(t, "other"), body: body, wantIn: "signature does not match"},
+		{name: "no provenance", signer: signer, body: stripped, wantIn: "no gomodel_provenance"},
+		{name: "not json", signer: signer, body: []byte("hello"), wantIn: "not a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.signer.Verify(tt.body, tt.claimed)
+			if result.Valid != tt.wantValid {
+				t.Fatalf("Verify() = %+v, want valid %v", result, tt.wantValid)
+			}
+			if !strings.Contains(result.Reason, tt.wantIn) {
+				t.Fatalf("Verify() reason = %q, want %q", result.Reason, tt.wantIn)
+			}
+		})
+	}
+}
+
+func TestOptedOut(t *testing.T) {
+	for value, want := range map[string]bool{"off": true, " OFF ": true, "false": true, "": false, "on": false} {
+		if got := OptedOut(value); got != want {
+			t.Errorf("OptedOut(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
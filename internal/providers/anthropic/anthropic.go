@@ -22,6 +22,7 @@ import (
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/sse"
 	"gomodel/internal/streaming"
 )
 
@@ -64,12 +65,20 @@ func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) c
 		apiKey:               providerCfg.APIKey,
 		batchResultEndpoints: make(map[string]map[string]string),
 	}
+	// ANTHROPIC_BASE_URL is the bare host for the Anthropic SDKs, which add
+	// /v1 themselves.
+	baseURL := providers.WithDefaultPath(providers.ResolveBaseURL(providerCfg.BaseURL, defaultBaseURL), "/v1")
 	clientCfg := llmclient.Config{
 		ProviderName:   "anthropic",
-		BaseURL:        providers.ResolveBaseURL(providerCfg.BaseURL, defaultBaseURL),
+		BaseURL:        baseURL,
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.client = llmclient.New(clientCfg, p.setHeaders)
 	return p
@@ -219,6 +228,17 @@ type anthropicRequest struct {
 	Stream       bool                   `json:"stream,omitempty"`
 	Thinking     *anthropicThinking     `json:"thinking,omitempty"`
 	OutputConfig *anthropicOutputConfig `json:"output_config,omitempty"`
+	Metadata     *anthropicMetadata     `json:"metadata,omitempty"`
+
+	// cachePrefix is the leading part of System sent with a prompt cache
+	// breakpoint. See MarshalJSON.
+	cachePrefix string
+}
+
+// anthropicMetadata carries the end-user identifier Anthropic uses for abuse
+// detection. It is mapped from the OpenAI user field.
+type anthropicMetadata struct {
+	UserID string `json:"user_id"`
 }
 
 type anthropicTool struct {
@@ -292,6 +312,9 @@ type anthropicContent struct {
 	ID        string          `json:"id,omitempty"`
 	Name      string          `json:"name,omitempty"`
 	Input     json.RawMessage `json:"input,omitempty"`
+	// Citations of a text block, present when web search or document
+	// citations are enabled.
+	Citations []json.RawMessage `json:"citations,omitempty"`
 }
 
 // anthropicUsage represents token usage in Anthropic response
@@ -320,6 +343,8 @@ type anthropicDelta struct {
 	Signature   string `json:"signature,omitempty"`
 	PartialJSON string `json:"partial_json,omitempty"`
 	StopReason  string `json:"stop_reason,omitempty"`
+	// Citation is the payload of a citations_delta.
+	Citation json.RawMessage `json:"citation,omitempty"`
 }
 
 // anthropicModelInfo represents a model in Anthropic's models API response
@@ -427,15 +452,24 @@ func convertFromAnthropicResponse(resp *anthropicResponse) *core.ChatResponse {
 		ToolCalls: toolCalls,
 	}
 
-	// Surface thinking content as reasoning_content (OpenAI-compatible format).
+	// Surface thinking content as reasoning_content and citations as
+	// annotations (OpenAI-compatible format).
+	extraFields := make(map[string]json.RawMessage, 2)
 	if thinking != "" {
 		raw, err := json.Marshal(thinking)
 		if err == nil {
-			msg.ExtraFields = core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
-				"reasoning_content": raw,
-			})
+			extraFields["reasoning_content"] = raw
 		}
 	}
+	if annotations := extractTextAnnotations(resp.Content); len(annotations) > 0 {
+		raw, err := core.MarshalChatAnnotations(annotations)
+		if err == nil {
+			extraFields["annotations"] = raw
+		}
+	}
+	if len(extraFields) > 0 {
+		msg.ExtraFields = core.UnknownJSONFieldsFromMap(extraFields)
+	}
 
 	return &core.ChatResponse{
 		ID:      resp.ID,
@@ -465,6 +499,7 @@ func (p *Provider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*
 		Method:   http.MethodPost,
 		Endpoint: "/messages",
 		Body:     anthropicReq,
+		Headers:  withPromptCache(ctx, anthropicReq),
 	}, &anthropicResp)
 	if err != nil {
 		return nil, err
@@ -485,6 +520,7 @@ func (p *Provider) StreamChatCompletion(ctx context.Context, req *core.ChatReque
 		Method:   http.MethodPost,
 		Endpoint: "/messages",
 		Body:     anthropicReq,
+		Headers:  withPromptCache(ctx, anthropicReq),
 	})
 	if err != nil {
 		return nil, err
@@ -496,7 +532,7 @@ func (p *Provider) StreamChatCompletion(ctx context.Context, req *core.ChatReque
 
 // streamConverter wraps an Anthropic stream and converts it to OpenAI format
 type streamConverter struct {
-	reader            *bufio.Reader
+	events            *sse.Reader
 	body              io.ReadCloser
 	model             string
 	msgID             string
@@ -521,7 +557,7 @@ type streamToolCallState struct {
 
 func newStreamConverter(body io.ReadCloser, model string) *streamConverter {
 	return &streamConverter{
-		reader:         bufio.NewReader(body),
+		events:         sse.NewReader(body),
 		body:           body,
 		model:          model,
 		toolCalls:      make(map[int]*streamToolCallState),
@@ -534,19 +570,9 @@ func malformedAnthropicStreamError(err error) error {
 	return core.NewProviderError("anthropic", http.StatusBadGateway, "failed to decode anthropic stream event: "+err.Error(), err)
 }
 
-func consumeAnthropicSSELine(p []byte, line []byte, body io.ReadCloser, buffer *streaming.StreamBuffer, convert func(*anthropicStreamEvent) string) (n int, handled bool, err error) {
-	line = bytes.TrimSpace(line)
-	if len(line) == 0 || bytes.HasPrefix(line, []byte("event:")) {
-		return 0, false, nil
-	}
-	if !bytes.HasPrefix(line, []byte("data:")) {
-		return 0, false, nil
-	}
-
-	data := bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
-
+func consumeAnthropicSSEEvent(p []byte, sseEvent sse.Event, body io.ReadCloser, buffer *streaming.StreamBuffer, convert func(*anthropicStreamEvent) string) (n int, handled bool, err error) {
 	var event anthropicStreamEvent
-	if err := json.Unmarshal(data, &event); err != nil {
+	if err := json.Unmarshal(sseEvent.Data, &event); err != nil {
 		_ = body.Close() //nolint:errcheck
 		return 0, false, malformedAnthropicStreamError(err)
 	}
@@ -623,11 +649,11 @@ func (sc *streamConverter) Read(p []byte) (n int, err error) {
 
 	// Read the next SSE event from Anthropic
 	for {
-		line, err := sc.reader.ReadBytes('\n')
+		event, err := sc.events.Next()
 		if err != nil {
 			if err == io.EOF {
 				// Send final [DONE] message
-				sc.buffer.AppendString("data: [DONE]\n\n")
+				sc.buffer.AppendString(sse.DoneMessage)
 				n = sc.buffer.Read(p)
 				sc.closed = true
 				_ = sc.body.Close() //nolint:errcheck
@@ -636,7 +662,7 @@ func (sc *streamConverter) Read(p []byte) (n int, err error) {
 			return 0, err
 		}
 
-		n, handled, err := consumeAnthropicSSELine(p, line, sc.body, &sc.buffer, sc.convertEvent)
+		n, handled, err := consumeAnthropicSSEEvent(p, event, sc.body, &sc.buffer, sc.convertEvent)
 		if err != nil {
 			sc.closed = true
 			sc.releaseBuffer()
@@ -736,7 +762,7 @@ func (sc *streamConverter) formatChatChunk(delta map[string]any, finishReason an
 		return ""
 	}
 
-	return fmt.Sprintf("data: %s\n\n", jsonData)
+	return sse.FormatData(jsonData)
 }
 
 func (sc *streamConverter) convertEvent(event *anthropicStreamEvent) string {
@@ -944,6 +970,12 @@ func (p *Provider) ListModels(ctx context.Context) (*core.ModelsResponse, error)
 	}, nil
 }
 
+// FetchRateLimits lists models and returns the account limits reported in the
+// response's anthropic-ratelimit-* headers.
+func (p *Provider) FetchRateLimits(ctx context.Context) (core.RateLimits, error) {
+	return p.client.FetchRateLimits(ctx, "/models?limit=1")
+}
+
 // parseCreatedAt parses an RFC3339 timestamp string to Unix timestamp
 func parseCreatedAt(createdAt string) int64 {
 	t, err := time.Parse(time.RFC3339, createdAt)
@@ -1041,11 +1073,17 @@ func convertAnthropicResponseToResponses(resp *anthropicResponse, model string)
 		Content:   content,
 		ToolCalls: toolCalls,
 	}
-	output := providers.BuildResponsesOutputItems(core.ResponseMessage{
+	message := core.ResponseMessage{
 		Role:      "assistant",
 		Content:   msg.Content,
 		ToolCalls: msg.ToolCalls,
-	})
+	}
+	if annotations := extractTextAnnotations(resp.Content); len(annotations) > 0 {
+		if raw, err := core.MarshalChatAnnotations(annotations); err == nil {
+			message.ExtraFields = core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{"annotations": raw})
+		}
+	}
+	output := providers.BuildResponsesOutputItems(message)
 
 	return &core.ResponsesResponse{
 		ID:        resp.ID,
@@ -1099,6 +1137,7 @@ func (p *Provider) Responses(ctx context.Context, req *core.ResponsesRequest) (*
 		Method:   http.MethodPost,
 		Endpoint: "/messages",
 		Body:     anthropicReq,
+		Headers:  withPromptCache(ctx, anthropicReq),
 	}, &anthropicResp)
 	if err != nil {
 		return nil, err
@@ -1472,6 +1511,7 @@ func (p *Provider) StreamResponses(ctx context.Context, req *core.ResponsesReque
 		Method:   http.MethodPost,
 		Endpoint: "/messages",
 		Body:     anthropicReq,
+		Headers:  withPromptCache(ctx, anthropicReq),
 	})
 	if err != nil {
 		return nil, err
@@ -1483,14 +1523,16 @@ func (p *Provider) StreamResponses(ctx context.Context, req *core.ResponsesReque
 
 // responsesStreamConverter wraps an Anthropic stream and converts it to Responses API format
 type responsesStreamConverter struct {
-	reader          *bufio.Reader
+	events          *sse.Reader
 	body            io.ReadCloser
 	model           string
 	responseID      string
+	messageID       string // Anthropic message ID behind the synthesized responseID
 	output          *providers.ResponsesOutputEventState
 	nextOutputIndex int
 	toolCalls       map[int]*providers.ResponsesOutputToolCallState
 	thinkingBlocks  map[int]bool // tracks which content block indices are thinking blocks
+	textBlocks      map[int]*streamTextBlock
 	buffer          streaming.StreamBuffer
 	closed          bool
 	sentDone        bool
@@ -1501,13 +1543,14 @@ type responsesStreamConverter struct {
 func newResponsesStreamConverter(body io.ReadCloser, model string) *responsesStreamConverter {
 	responseID := "resp_" + uuid.New().String()
 	return &responsesStreamConverter{
-		reader:         bufio.NewReader(body),
+		events:         sse.NewReader(body),
 		body:           body,
 		model:          model,
 		responseID:     responseID,
 		output:         providers.NewResponsesOutputEventState(responseID),
 		toolCalls:      make(map[int]*providers.ResponsesOutputToolCallState),
 		thinkingBlocks: make(map[int]bool),
+		textBlocks:     make(map[int]*streamTextBlock),
 		buffer:         streaming.NewStreamBuffer(1024),
 	}
 }
@@ -1525,13 +1568,12 @@ func (sc *responsesStreamConverter) Read(p []byte) (n int, err error) {
 
 	// Read the next SSE event from Anthropic
 	for {
-		line, err := sc.reader.ReadBytes('\n')
+		event, err := sc.events.Next()
 		if err != nil {
 			if err == io.EOF {
 				// Send final done event and [DONE] message
 				if !sc.sentDone {
 					sc.sentDone = true
-					prefix := sc.output.CompleteAssistantOutput(0)
 					responseData := map[string]any{
 						"id":         sc.responseID,
 						"object":     "response",
@@ -1540,26 +1582,16 @@ func (sc *responsesStreamConverter) Read(p []byte) (n int, err error) {
 						"provider":   "anthropic",
 						"created_at": time.Now().Unix(),
 					}
+					if sc.messageID != "" {
+						responseData["provider_response_id"] = sc.messageID
+					}
 					// Include merged usage data captured across message_start/message_delta.
 					if sc.hasUsage {
 						responseData["usage"] = anthropicResponsesUsagePayload(&sc.usage)
 					}
-					doneEvent := map[string]any{
-						"type":     "response.completed",
-						"response": responseData,
-					}
-					jsonData, marshalErr := json.Marshal(doneEvent)
-					if marshalErr != nil {
-						slog.Error("failed to marshal response.completed event", "error", marshalErr, "response_id", sc.responseID)
-						sc.closed = true
-						sc.releaseBuffer()
-						_ = sc.body.Close() //nolint:errcheck
-						return 0, io.EOF
-					}
-					sc.buffer.AppendString(prefix)
-					sc.buffer.AppendString("event: response.completed\ndata: ")
-					sc.buffer.AppendBytes(jsonData)
-					sc.buffer.AppendString("\n\ndata: [DONE]\n\n")
+					sc.buffer.AppendString(sc.output.CompleteAssistantOutput(0))
+					sc.buffer.AppendString(sc.output.WriteResponseCompleted(responseData))
+					sc.buffer.AppendString(sse.DoneMessage)
 					return sc.buffer.Read(p), nil
 				}
 				sc.closed = true
@@ -1570,7 +1602,7 @@ func (sc *responsesStreamConverter) Read(p []byte) (n int, err error) {
 			return 0, err
 		}
 
-		n, handled, err := consumeAnthropicSSELine(p, line, sc.body, &sc.buffer, sc.convertEvent)
+		n, handled, err := consumeAnthropicSSEEvent(p, event, sc.body, &sc.buffer, sc.convertEvent)
 		if err != nil {
 			sc.closed = true
 			sc.releaseBuffer()
@@ -1599,6 +1631,30 @@ func (sc *responsesStreamConverter) releaseBuffer() {
 	sc.buffer.Release()
 }
 
+// streamTextBlock tracks a streamed text block whose citations are emitted
+// when it ends.
+type streamTextBlock struct {
+	start     int
+	citations []json.RawMessage
+}
+
+func (sc *responsesStreamConverter) textBlockAnnotations(block *streamTextBlock) string {
+	if len(block.citations) == 0 {
+		return ""
+	}
+	end := sc.output.AssistantTextLength()
+	var out strings.Builder
+	for _, citation := range block.citations {
+		annotation, ok := citationAnnotation(citation, block.start, end)
+		if !ok {
+			continue
+		}
+		sc.reserveAssistantMessageOutput()
+		out.WriteString(sc.output.AssistantAnnotation(0, annotation))
+	}
+	return out.String()
+}
+
 func (sc *responsesStreamConverter) reserveAssistantMessageOutput() {
 	if sc.output.AssistantReserved() {
 		return
@@ -1629,6 +1685,7 @@ func (sc *responsesStreamConverter) convertEvent(event *anthropicStreamEvent) st
 	switch event.Type {
 	case "message_start":
 		if event.Message != nil {
+			sc.messageID = event.Message.ID
 			if mergeAnthropicUsage(&sc.usage, &event.Message.Usage) {
 				sc.hasUsage = true
 			}
@@ -1636,30 +1693,28 @@ func (sc *responsesStreamConverter) convertEvent(event *anthropicStreamEvent) st
 		if mergeAnthropicUsage(&sc.usage, event.Usage) {
 			sc.hasUsage = true
 		}
-		// Send response.created event
-		createdEvent := map[string]any{
-			"type": "response.created",
-			"response": map[string]any{
-				"id":         sc.responseID,
-				"object":     "response",
-				"status":     "in_progress",
-				"model":      sc.model,
-				"provider":   "anthropic",
-				"created_at": time.Now().Unix(),
-			},
+		created := map[string]any{
+			"id":         sc.responseID,
+			"object":     "response",
+			"status":     "in_progress",
+			"model":      sc.model,
+			"provider":   "anthropic",
+			"created_at": time.Now().Unix(),
 		}
-		jsonData, err := json.Marshal(createdEvent)
-		if err != nil {
-			slog.Error("failed to marshal response.created event", "error", err, "response_id", sc.responseID)
-			return ""
+		if sc.messageID != "" {
+			created["provider_response_id"] = sc.messageID
 		}
-		return fmt.Sprintf("event: response.created\ndata: %s\n\n", jsonData)
+		return sc.output.WriteResponseCreated(created)
 
 	case "content_block_start":
 		if event.ContentBlock != nil && event.ContentBlock.Type == "thinking" {
 			sc.thinkingBlocks[event.Index] = true
 			return ""
 		}
+		if event.ContentBlock != nil && event.ContentBlock.Type == "text" {
+			sc.textBlocks[event.Index] = &streamTextBlock{start: sc.output.AssistantTextLength()}
+			return ""
+		}
 		if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
 			if sc.output.AssistantStarted() && !sc.output.AssistantDone() {
 				prefix := sc.output.CompleteAssistantOutput(0)
@@ -1686,19 +1741,15 @@ func (sc *responsesStreamConverter) convertEvent(event *anthropicStreamEvent) st
 		case "text_delta":
 			if event.Delta.Text != "" {
 				sc.reserveAssistantMessageOutput()
-				prefix := sc.output.StartAssistantOutput(0)
-				sc.output.AppendAssistantText(event.Delta.Text)
-				deltaEvent := map[string]any{
-					"type":  "response.output_text.delta",
-					"delta": event.Delta.Text,
-				}
-				jsonData, err := json.Marshal(deltaEvent)
-				if err != nil {
-					slog.Error("failed to marshal content delta event", "error", err, "response_id", sc.responseID)
-					return ""
-				}
-				return prefix + fmt.Sprintf("event: response.output_text.delta\ndata: %s\n\n", jsonData)
+				return sc.output.AssistantTextDelta(0, event.Delta.Text)
+			}
+		case "citations_delta":
+			// Citations cover the whole text block; they are emitted once
+			// the block ends and its extent is known.
+			if block := sc.textBlocks[event.Index]; block != nil && len(event.Delta.Citation) > 0 {
+				block.citations = append(block.citations, event.Delta.Citation)
 			}
+			return ""
 		case "input_json_delta":
 			if event.Delta.PartialJSON == "" {
 				return ""
@@ -1722,6 +1773,10 @@ func (sc *responsesStreamConverter) convertEvent(event *anthropicStreamEvent) st
 		return ""
 
 	case "content_block_stop":
+		if block := sc.textBlocks[event.Index]; block != nil {
+			delete(sc.textBlocks, event.Index)
+			return sc.textBlockAnnotations(block)
+		}
 		state := sc.toolCalls[event.Index]
 		return sc.output.CompleteToolCall(state, true)
 
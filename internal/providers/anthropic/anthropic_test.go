@@ -14,6 +14,7 @@ import (
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestNew(t *testing.T) {
@@ -1342,6 +1343,62 @@ func TestConvertToAnthropicRequest_InvalidToolArguments(t *testing.T) {
 	}
 }
 
+func TestConvertToAnthropicRequest_MapsUserToMetadata(t *testing.T) {
+	chatReq := &core.ChatRequest{
+		Model:       "claude-sonnet-4-5-20250929",
+		Messages:    []core.Message{{Role: "user", Content: "hello"}},
+		ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{"user": json.RawMessage(`"user-1234"`)}),
+	}
+	result, err := convertToAnthropicRequest(chatReq)
+	if err != nil {
+		t.Fatalf("convertToAnthropicRequest() error = %v", err)
+	}
+	if result.Metadata == nil || result.Metadata.UserID != "user-1234" {
+		t.Fatalf("Metadata = %+v, want user_id user-1234", result.Metadata)
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"metadata":{"user_id":"user-1234"}`) {
+		t.Fatalf("body = %s, want metadata.user_id", body)
+	}
+
+	responsesReq := &core.ResponsesRequest{
+		Model:       "claude-sonnet-4-5-20250929",
+		Input:       "hello",
+		ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{"user": json.RawMessage(`"user-1234"`)}),
+	}
+	result, err = convertResponsesRequestToAnthropic(responsesReq)
+	if err != nil {
+		t.Fatalf("convertResponsesRequestToAnthropic() error = %v", err)
+	}
+	if result.Metadata == nil || result.Metadata.UserID != "user-1234" {
+		t.Fatalf("responses Metadata = %+v, want user_id user-1234", result.Metadata)
+	}
+
+	result, err = convertToAnthropicRequest(&core.ChatRequest{
+		Model:    "claude-sonnet-4-5-20250929",
+		Messages: []core.Message{{Role: "user", Content: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("convertToAnthropicRequest() error = %v", err)
+	}
+	if result.Metadata != nil {
+		t.Fatalf("Metadata = %+v, want nil without user", result.Metadata)
+	}
+
+	_, err = convertToAnthropicRequest(&core.ChatRequest{
+		Model:       "claude-sonnet-4-5-20250929",
+		Messages:    []core.Message{{Role: "user", Content: "hello"}},
+		ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{"user": json.RawMessage(`42`)}),
+	})
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusBadRequest {
+		t.Fatalf("error = %v, want 400 for a non-string user", err)
+	}
+}
+
 func TestConvertToAnthropicRequest_RejectsTrailingToolArgumentContent(t *testing.T) {
 	_, err := convertToAnthropicRequest(&core.ChatRequest{
 		Model: "claude-sonnet-4-5-20250929",
@@ -1928,6 +1985,52 @@ func TestConvertFromAnthropicResponse_NoCacheFields(t *testing.T) {
 	}
 }
 
+func TestConvertAnthropicResponses_MapsCitationsToAnnotations(t *testing.T) {
+	var resp anthropicResponse
+	if err := json.Unmarshal([]byte(`{
+		"id":"msg_cited",
+		"type":"message",
+		"role":"assistant",
+		"model":"claude-sonnet-4-5-20250929",
+		"content":[
+			{"type":"text","text":"Sure."},
+			{"type":"text","text":"Kraków is old.","citations":[
+				{"type":"web_search_result_location","url":"https://example.com/krakow","title":"Kraków","cited_text":"Kraków","encrypted_index":"abc"},
+				{"type":"char_location","cited_text":"old","document_index":0,"start_char_index":4,"end_char_index":7}
+			]}
+		],
+		"stop_reason":"end_turn",
+		"usage":{"input_tokens":10,"output_tokens":5}
+	}`), &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	chat := convertFromAnthropicResponse(&resp)
+	annotations, err := core.ParseChatAnnotations(chat.Choices[0].Message.ExtraFields.Lookup("annotations"))
+	if err != nil {
+		t.Fatalf("ParseChatAnnotations() error = %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("len(annotations) = %d, want 2", len(annotations))
+	}
+	web := annotations[0]
+	if web.Type != core.AnnotationTypeURLCitation || web.URL != "https://example.com/krakow" || *web.StartIndex != 7 || *web.EndIndex != 21 {
+		t.Fatalf("annotations[0] = %+v, want url_citation over [7,21)", web)
+	}
+	if annotations[1].Type != "char_location" || annotations[1].ExtraFields.Lookup("cited_text") == nil {
+		t.Fatalf("annotations[1] = %+v, want char_location with its fields", annotations[1])
+	}
+
+	responses := convertAnthropicResponseToResponses(&resp, "claude-sonnet-4-5-20250929")
+	content := responses.Output[0].Content
+	if len(content) != 1 || len(content[0].Annotations) != 2 {
+		t.Fatalf("content = %+v, want one output_text with two annotations", content)
+	}
+	if got := content[0].Annotations[0]; got.URL != "https://example.com/krakow" || *got.StartIndex != 7 {
+		t.Fatalf("responses annotation = %+v, want url_citation at 7", got)
+	}
+}
+
 func TestConvertAnthropicResponseToResponses_WithCacheFields(t *testing.T) {
 	resp := &anthropicResponse{
 		ID:    "msg_cache_resp",
@@ -2547,6 +2650,79 @@ data: {"type":"message_stop"}
 	}
 }
 
+func TestStreamResponses_EmitsEventEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"id":"msg_123","type":"message","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[],"stop_reason":null,"usage":{"input_tokens":10,"output_tokens":0}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}
+
+event: message_stop
+data: {"type":"message_stop"}
+`))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+
+	body, err := provider.StreamResponses(context.Background(), &core.ResponsesRequest{
+		Model: "claude-sonnet-4-5-20250929",
+		Input: "Hello",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = body.Close() }()
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+
+	events := parseTestSSEEvents(t, string(raw))
+	wantNames := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if len(events) != len(wantNames)+1 || !events[len(events)-1].Done {
+		t.Fatalf("event count = %d, want %d events followed by [DONE]", len(events), len(wantNames))
+	}
+	for i, want := range wantNames {
+		if events[i].Name != want {
+			t.Fatalf("event[%d] = %q, want %q", i, events[i].Name, want)
+		}
+		if events[i].Payload["sequence_number"] != float64(i) {
+			t.Fatalf("event[%d] %s sequence_number = %v, want %d", i, want, events[i].Payload["sequence_number"], i)
+		}
+	}
+	delta := events[4].Payload
+	if delta["item_id"] == nil || delta["item_id"] == "" {
+		t.Fatal("expected response.output_text.delta item_id")
+	}
+	if delta["output_index"] != float64(0) || delta["content_index"] != float64(0) {
+		t.Fatalf("delta indices = (%v, %v), want (0, 0)", delta["output_index"], delta["content_index"])
+	}
+}
+
 func TestStreamResponses_WithToolCalls(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -3005,6 +3181,50 @@ func TestConvertResponsesRequestToAnthropic(t *testing.T) {
 	}
 }
 
+func TestConvertResponsesRequestToAnthropic_MultiTurnToolExchange(t *testing.T) {
+	var req core.ResponsesRequest
+	if err := json.Unmarshal([]byte(`{
+		"model": "claude-sonnet-4-5-20250929",
+		"input": [
+			{"role": "user", "content": [{"type": "input_text", "text": "What's the weather in Paris?"}]},
+			{"type": "reasoning", "id": "rs_1", "summary": []},
+			{"type": "function_call", "id": "fc_1", "call_id": "call_weather", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}", "status": "completed"},
+			{"type": "function_call_output", "id": "fco_1", "call_id": "call_weather", "output": "{\"temp_c\":18}"},
+			{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed", "content": [{"type": "output_text", "text": "It is 18C.", "annotations": []}]},
+			{"role": "user", "content": "And tomorrow?"}
+		]
+	}`), &req); err != nil {
+		t.Fatalf("failed to decode responses request: %v", err)
+	}
+
+	anthropicReq, err := convertResponsesRequestToAnthropic(&req)
+	if err != nil {
+		t.Fatalf("convertResponsesRequestToAnthropic() error = %v", err)
+	}
+
+	msgs := anthropicReq.Messages
+	if len(msgs) != 5 {
+		t.Fatalf("len(Messages) = %d, want 5: %+v", len(msgs), msgs)
+	}
+	for i, role := range []string{"user", "assistant", "user", "assistant", "user"} {
+		if msgs[i].Role != role {
+			t.Fatalf("Messages[%d].Role = %q, want %q", i, msgs[i].Role, role)
+		}
+	}
+
+	toolUse, ok := msgs[1].Content.([]anthropicContentBlock)
+	if !ok || len(toolUse) != 1 || toolUse[0].Type != "tool_use" || toolUse[0].ID != "call_weather" || toolUse[0].Name != "get_weather" {
+		t.Fatalf("assistant content = %#v, want get_weather tool_use", msgs[1].Content)
+	}
+	toolResult, ok := msgs[2].Content.([]anthropicContentBlock)
+	if !ok || len(toolResult) != 1 || toolResult[0].Type != "tool_result" || toolResult[0].ToolUseID != "call_weather" || toolResult[0].Content != `{"temp_c":18}` {
+		t.Fatalf("tool result content = %#v, want call_weather result", msgs[2].Content)
+	}
+	if msgs[3].Content != "It is 18C." {
+		t.Fatalf("assistant reply = %#v, want flattened text", msgs[3].Content)
+	}
+}
+
 func TestConvertResponsesRequestToAnthropic_InvalidToolArguments(t *testing.T) {
 	_, err := convertResponsesRequestToAnthropic(&core.ResponsesRequest{
 		Model: "claude-sonnet-4-5-20250929",
@@ -4500,3 +4720,88 @@ func TestPassthrough(t *testing.T) {
 		t.Fatalf("response body = %q", string(body))
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "anthropic",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "claude-sonnet-4")
+	testfixtures.RequireOnEveryRequest(t, server, 5, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+// A bare host gets the /v1 the Anthropic SDKs add themselves.
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "anthropic",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "claude-sonnet-4",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/anthropic/v1", ChatPath: "/proxy/anthropic/v1/messages", Prefix: "/proxy/anthropic/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/anthropic/v1/", ChatPath: "/proxy/anthropic/v1/messages", Prefix: "/proxy/anthropic/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/v1/messages", Prefix: "/v1/"},
+	)
+}
+
+func TestConversionProperties(t *testing.T) {
+	testfixtures.CheckConversionProperties(t, testfixtures.ConversionSuite{
+		ChatRequest: func(req *core.ChatRequest) (testfixtures.ConvertedRequest, error) {
+			return anthropicRequestView(convertToAnthropicRequest(req))
+		},
+		ResponsesRequest: func(req *core.ResponsesRequest) (testfixtures.ConvertedRequest, error) {
+			return anthropicRequestView(convertResponsesRequestToAnthropic(req))
+		},
+		Response: func(gen testfixtures.GeneratedResponse) (*core.ChatResponse, *core.ResponsesResponse) {
+			resp := &anthropicResponse{
+				ID:         gen.ID,
+				Type:       "message",
+				Role:       "assistant",
+				Content:    []anthropicContent{{Type: "text", Text: gen.Text}},
+				Model:      gen.Model,
+				StopReason: "end_turn",
+				Usage:      anthropicUsage{InputTokens: gen.InputTokens, OutputTokens: gen.OutputTokens},
+			}
+			return convertFromAnthropicResponse(resp), convertAnthropicResponseToResponses(resp, gen.Model)
+		},
+	})
+}
+
+func anthropicRequestView(req *anthropicRequest, err error) (testfixtures.ConvertedRequest, error) {
+	if err != nil {
+		return testfixtures.ConvertedRequest{}, err
+	}
+	view := testfixtures.ConvertedRequest{Temperature: req.Temperature, MaxTokens: &req.MaxTokens, Stream: req.Stream}
+	if req.System != "" {
+		view.System = []string{req.System}
+	}
+	for _, msg := range req.Messages {
+		text, _ := msg.Content.(string)
+		if blocks, ok := msg.Content.([]anthropicContentBlock); ok {
+			texts := make([]string, 0, len(blocks))
+			for _, block := range blocks {
+				texts = append(texts, block.Text)
+			}
+			text = strings.Join(texts, " ")
+		}
+		view.Messages = append(view.Messages, testfixtures.ConvertedMessage{Role: msg.Role, Text: text})
+	}
+	return view, nil
+}
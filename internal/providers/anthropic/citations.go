@@ -0,0 +1,57 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"gomodel/internal/core"
+)
+
+// citationAnnotation maps an Anthropic text block citation to an annotation
+// of the text between start and end. Web search results become url_citation
+// annotations; document citations keep their Anthropic type and fields.
+func citationAnnotation(raw json.RawMessage, start, end int) (core.ResponsesAnnotation, bool) {
+	var annotation core.ResponsesAnnotation
+	if err := json.Unmarshal(raw, &annotation); err != nil || annotation.Type == "" {
+		return core.ResponsesAnnotation{}, false
+	}
+	if annotation.Type == "web_search_result_location" {
+		if annotation.URL == "" {
+			return core.ResponsesAnnotation{}, false
+		}
+		return core.NewURLCitation(annotation.URL, annotation.Title, start, end), true
+	}
+	annotation.StartIndex = &start
+	annotation.EndIndex = &end
+	return annotation, true
+}
+
+// extractTextAnnotations returns the annotations of the citations in the text
+// blocks that extractTextContent joins, with indexes into the joined text.
+func extractTextAnnotations(blocks []anthropicContent) []core.ResponsesAnnotation {
+	lastThinkingIdx := -1
+	for i, b := range blocks {
+		if b.Type == "thinking" {
+			lastThinkingIdx = i
+		}
+	}
+
+	var annotations []core.ResponsesAnnotation
+	offset := 0
+	for i, b := range blocks {
+		if b.Type != "text" || b.Text == "" || (lastThinkingIdx >= 0 && i < lastThinkingIdx) {
+			continue
+		}
+		if offset > 0 {
+			offset += utf8.RuneCountInString("\n\n")
+		}
+		end := offset + utf8.RuneCountInString(b.Text)
+		for _, citation := range b.Citations {
+			if annotation, ok := citationAnnotation(citation, offset, end); ok {
+				annotations = append(annotations, annotation)
+			}
+		}
+		offset = end
+	}
+	return annotations
+}
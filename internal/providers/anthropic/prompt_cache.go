@@ -0,0 +1,57 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// anthropicPromptCachingBeta opts a Messages request into prompt caching.
+const anthropicPromptCachingBeta = "prompt-caching-2024-07-31"
+
+// anthropicSystemBlock is one text block of a system prompt sent as blocks.
+type anthropicSystemBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+// MarshalJSON sends the system prompt as text blocks with a cache breakpoint
+// after the cacheable prefix when one is set, and as a plain string otherwise.
+func (r anthropicRequest) MarshalJSON() ([]byte, error) {
+	type plain anthropicRequest
+	if r.cachePrefix == "" || !strings.HasPrefix(r.System, r.cachePrefix) {
+		return json.Marshal(plain(r))
+	}
+	blocks := []anthropicSystemBlock{{
+		Type:         "text",
+		Text:         r.cachePrefix,
+		CacheControl: &anthropicCacheControl{Type: "ephemeral"},
+	}}
+	if rest := r.System[len(r.cachePrefix):]; rest != "" {
+		blocks = append(blocks, anthropicSystemBlock{Type: "text", Text: rest})
+	}
+	return json.Marshal(struct {
+		plain
+		System []anthropicSystemBlock `json:"system"`
+	}{plain: plain(r), System: blocks})
+}
+
+// withPromptCache marks the cacheable system prompt prefix of the request
+// context on req. It returns the headers the Messages call needs, or nil when
+// the system prompt does not start with the prefix.
+func withPromptCache(ctx context.Context, req *anthropicRequest) http.Header {
+	hint := core.GetPromptCacheHint(ctx)
+	if req == nil || hint.Prefix == "" || !strings.HasPrefix(req.System, hint.Prefix) {
+		return nil
+	}
+	req.cachePrefix = hint.Prefix
+	return http.Header{"Anthropic-Beta": {anthropicPromptCachingBeta}}
+}
@@ -0,0 +1,114 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gomodel/internal/core"
+	"gomodel/internal/llmclient"
+)
+
+const promptCacheTestResponse = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":40,"output_tokens":2,"cache_read_input_tokens":3000}}`
+
+// capturePromptCacheRequest serves one Messages call and returns its body and
+// anthropic-beta header.
+func capturePromptCacheRequest(t *testing.T, ctx context.Context, req *core.ChatRequest) (map[string]any, string, *core.ChatResponse) {
+	t.Helper()
+	var body map[string]any
+	var beta string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		beta = r.Header.Get("anthropic-beta")
+		_, _ = w.Write([]byte(promptCacheTestResponse))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+	resp, err := provider.ChatCompletion(ctx, req)
+	if err != nil {
+		t.Fatalf("ChatCompletion() error = %v", err)
+	}
+	return body, beta, resp
+}
+
+func TestChatCompletion_PromptCacheBreakpoint(t *testing.T) {
+	req := &core.ChatRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []core.Message{
+			{Role: "system", Content: "You are a support agent."},
+			{Role: "developer", Content: "Answer in French."},
+			{Role: "user", Content: "Hello"},
+		},
+	}
+	ctx := core.WithPromptCacheHint(context.Background(), core.PromptCacheHint{Prefix: "You are a support agent.", Key: "k1"})
+
+	body, beta, resp := capturePromptCacheRequest(t, ctx, req)
+
+	want := []any{
+		map[string]any{"type": "text", "text": "You are a support agent.", "cache_control": map[string]any{"type": "ephemeral"}},
+		map[string]any{"type": "text", "text": "\n\nAnswer in French."},
+	}
+	if !reflect.DeepEqual(body["system"], want) {
+		t.Fatalf("system = %#v, want %#v", body["system"], want)
+	}
+	if body["model"] != "claude-sonnet-4-5" || body["messages"] == nil {
+		t.Fatalf("body = %v, want the other fields unchanged", body)
+	}
+	if beta != anthropicPromptCachingBeta {
+		t.Fatalf("anthropic-beta = %q, want %q", beta, anthropicPromptCachingBeta)
+	}
+	if resp.Usage.RawUsage["cache_read_input_tokens"] != 3000 {
+		t.Fatalf("RawUsage = %v, want cache_read_input_tokens", resp.Usage.RawUsage)
+	}
+}
+
+func TestChatCompletion_PromptCacheWholeSystemPrompt(t *testing.T) {
+	req := &core.ChatRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []core.Message{
+			{Role: "system", Content: "You are a support agent."},
+			{Role: "user", Content: "Hello"},
+		},
+	}
+	ctx := core.WithPromptCacheHint(context.Background(), core.PromptCacheHint{Prefix: "You are a support agent."})
+
+	body, _, _ := capturePromptCacheRequest(t, ctx, req)
+
+	want := []any{
+		map[string]any{"type": "text", "text": "You are a support agent.", "cache_control": map[string]any{"type": "ephemeral"}},
+	}
+	if !reflect.DeepEqual(body["system"], want) {
+		t.Fatalf("system = %#v, want one cached block", body["system"])
+	}
+}
+
+func TestChatCompletion_PromptCacheHintNotMatchingSystem(t *testing.T) {
+	req := &core.ChatRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []core.Message{
+			{Role: "system", Content: "You are a sales agent."},
+			{Role: "user", Content: "Hello"},
+		},
+	}
+	for name, ctx := range map[string]context.Context{
+		"no hint":            context.Background(),
+		"other prefix":       core.WithPromptCacheHint(context.Background(), core.PromptCacheHint{Prefix: "You are a support agent."}),
+		"longer than system": core.WithPromptCacheHint(context.Background(), core.PromptCacheHint{Prefix: "You are a sales agent. Be brief."}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			body, beta, _ := capturePromptCacheRequest(t, ctx, req)
+			if body["system"] != "You are a sales agent." || beta != "" {
+				t.Fatalf("system = %#v, anthropic-beta = %q; want a plain string and no header", body["system"], beta)
+			}
+		})
+	}
+}
@@ -247,6 +247,11 @@ func buildAnthropicMessageContent(msg core.Message) (any, error) {
 	return blocks, nil
 }
 
+// fallbackMaxTokens is sent when a request reaches the provider without a
+// limit, which Anthropic requires. The gateway's default_max_tokens policy
+// sets the limit before that when it is configured.
+const fallbackMaxTokens = 4096
+
 // convertToAnthropicRequest converts core.ChatRequest to Anthropic format.
 func convertToAnthropicRequest(req *core.ChatRequest) (*anthropicRequest, error) {
 	if req == nil {
@@ -256,7 +261,7 @@ func convertToAnthropicRequest(req *core.ChatRequest) (*anthropicRequest, error)
 	anthropicReq := &anthropicRequest{
 		Model:       req.Model,
 		Messages:    make([]anthropicMessage, 0, len(req.Messages)),
-		MaxTokens:   4096,
+		MaxTokens:   fallbackMaxTokens,
 		Temperature: req.Temperature,
 		Stream:      req.Stream,
 	}
@@ -265,6 +270,14 @@ func convertToAnthropicRequest(req *core.ChatRequest) (*anthropicRequest, error)
 		anthropicReq.MaxTokens = *req.MaxTokens
 	}
 
+	user, err := core.RequestUserValue(req)
+	if err != nil {
+		return nil, err
+	}
+	if user != "" {
+		anthropicReq.Metadata = &anthropicMetadata{UserID: user}
+	}
+
 	if req.Reasoning != nil && req.Reasoning.Effort != "" {
 		applyReasoning(anthropicReq, req.Model, req.Reasoning.Effort)
 	}
@@ -289,7 +302,9 @@ func convertToAnthropicRequest(req *core.ChatRequest) (*anthropicRequest, error)
 	}
 
 	for _, msg := range req.Messages {
-		if msg.Role == "system" {
+		// Anthropic has no developer role; developer messages are system
+		// instructions like the system role.
+		if msg.Role == "system" || msg.Role == "developer" {
 			systemText, err := textOnlyAnthropicContent(msg.Content)
 			if err != nil {
 				return nil, err
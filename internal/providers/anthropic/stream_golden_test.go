@@ -0,0 +1,99 @@
+package anthropic
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	goldenTimestampPattern = regexp.MustCompile(`"(created|created_at)":\d+`)
+	goldenUUIDPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+// normalizeStreamGolden replaces the wall-clock timestamps and generated IDs
+// that differ between runs. Everything else must match byte for byte.
+func normalizeStreamGolden(out string) string {
+	out = goldenTimestampPattern.ReplaceAllString(out, `"$1":0`)
+	return goldenUUIDPattern.ReplaceAllString(out, "00000000-0000-0000-0000-000000000000")
+}
+
+func compareStreamGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	got = normalizeStreamGolden(got)
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s: %v (run with UPDATE_GOLDEN=1 to create it)", path, err)
+	}
+	if got != string(want) {
+		t.Fatalf("stream output differs from %s:\n got %q\nwant %q", path, got, want)
+	}
+}
+
+// readStreamInSmallChunks drains stream through a tiny buffer so partial reads
+// of buffered output are covered too.
+func readStreamInSmallChunks(t *testing.T, stream io.Reader) string {
+	t.Helper()
+
+	var out strings.Builder
+	buf := make([]byte, 7)
+	for {
+		n, err := stream.Read(buf)
+		out.Write(buf[:n])
+		if err == io.EOF {
+			return out.String()
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+	}
+}
+
+func TestStreamConverters_Golden(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "stream_mixed_blocks.sse"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	for _, crlf := range []bool{false, true} {
+		input := string(fixture)
+		suffix := ""
+		if crlf {
+			input = strings.ReplaceAll(input, "\n", "\r\n")
+			suffix = " crlf"
+		}
+
+		t.Run("chat"+suffix, func(t *testing.T) {
+			stream := newStreamConverter(io.NopCloser(strings.NewReader(input)), "claude-sonnet-4-5-20250929")
+			defer func() { _ = stream.Close() }()
+			compareStreamGolden(t, "stream_mixed_blocks.chat.golden", readStreamInSmallChunks(t, stream))
+		})
+
+		t.Run("responses"+suffix, func(t *testing.T) {
+			stream := newResponsesStreamConverter(io.NopCloser(strings.NewReader(input)), "claude-sonnet-4-5-20250929")
+			defer func() { _ = stream.Close() }()
+			compareStreamGolden(t, "stream_mixed_blocks.responses.golden", readStreamInSmallChunks(t, stream))
+		})
+	}
+}
+
+func TestResponsesStreamConverter_WebSearchCitationsGolden(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "stream_web_search_citations.sse"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	stream := newResponsesStreamConverter(io.NopCloser(strings.NewReader(string(fixture))), "claude-sonnet-4-5-20250929")
+	defer func() { _ = stream.Close() }()
+	compareStreamGolden(t, "stream_web_search_citations.responses.golden", readStreamInSmallChunks(t, stream))
+}
@@ -23,6 +23,7 @@ var Registration = providers.Registration{
 		RequireBaseURL:     true,
 		SupportsAPIVersion: true,
 	},
+	Quirks: openai.Quirks,
 }
 
 type Provider struct {
@@ -57,6 +58,7 @@ func NewWithHTTPClient(apiKey string, httpClient *http.Client, hooks llmclient.H
 		ProviderName: "azure",
 		BaseURL:      "https://example.invalid",
 		SetHeaders:   setHeaders,
+		Quirks:       openai.Quirks,
 	}
 	p.CompatibleProvider = openai.NewCompatibleProviderWithHTTPClient(apiKey, httpClient, hooks, cfg)
 	p.resourceProvider = openai.NewCompatibleProviderWithHTTPClient(apiKey, httpClient, hooks, cfg)
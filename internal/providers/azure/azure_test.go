@@ -8,6 +8,8 @@ import (
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
+	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestChatCompletion_UsesAzureAuthAndDefaultAPIVersion(t *testing.T) {
@@ -416,3 +418,45 @@ func TestGetBatchResults_UsesAzureResourceRootForDeploymentScopedBaseURL(t *test
 		}
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:       "azure",
+		APIKey:     "test-api-key",
+		BaseURL:    server.URL,
+		APIVersion: "2024-10-21",
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "gpt-4o")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+// Models and batches use the resource root in front of /openai/deployments.
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:       "azure",
+			APIKey:     "test-api-key",
+			BaseURL:    baseURL,
+			APIVersion: "2024-10-21",
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "gpt-4o",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/openai/deployments/gpt-4o", ChatPath: "/proxy/openai/deployments/gpt-4o/chat/completions", Prefix: "/proxy/openai/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/openai/deployments/gpt-4o/", ChatPath: "/proxy/openai/deployments/gpt-4o/chat/completions", Prefix: "/proxy/openai/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
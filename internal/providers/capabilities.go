@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+type probedCapabilityLookup interface {
+	ProbedCapability(providerName, capability string) (supported, known bool)
+}
+
+// chatCapabilities lists the probed capabilities a chat request needs.
+func chatCapabilities(req *core.ChatRequest, stream bool) []string {
+	capabilities := []string{core.CapabilityChat}
+	if stream {
+		capabilities = []string{core.CapabilityChatStream}
+	}
+	if len(req.Tools) > 0 {
+		capabilities = append(capabilities, core.CapabilityTools)
+	}
+	if format := req.ExtraFields.Lookup("response_format"); format != nil && strings.Contains(string(format), `"json_object"`) {
+		capabilities = append(capabilities, core.CapabilityJSONMode)
+	}
+	return capabilities
+}
+
+// checkProbedCapabilities rejects a request that needs a capability the
+// applied capability profile of the serving provider found unsupported.
+// Providers without an applied profile accept everything.
+func (r *Router) checkProbedCapabilities(selector core.ModelSelector, capabilities []string) error {
+	lookup, ok := r.lookup.(probedCapabilityLookup)
+	if !ok || len(capabilities) == 0 {
+		return nil
+	}
+	providerName := strings.TrimSpace(r.GetProviderName(selector.QualifiedModel()))
+	if providerName == "" {
+		providerName = strings.TrimSpace(selector.Provider)
+	}
+	if providerName == "" {
+		return nil
+	}
+	for _, capability := range capabilities {
+		if supported, known := lookup.ProbedCapability(providerName, capability); known && !supported {
+			return core.NewInvalidRequestError(
+				fmt.Sprintf("provider %s does not support %s according to its capability probe", providerName, capability), nil,
+			).WithCode("unsupported_capability")
+		}
+	}
+	return nil
+}
@@ -1,13 +1,17 @@
 package providers
 
 import (
+	"fmt"
 	"maps"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"unicode"
 
 	"gomodel/config"
+	"gomodel/internal/httpclient"
+	"gomodel/internal/llmclient"
 )
 
 // ProviderConfig holds the fully resolved provider configuration after merging
@@ -19,6 +23,32 @@ type ProviderConfig struct {
 	APIVersion string
 	Models     []string
 	Resilience config.ResilienceConfig
+	Signing    *config.RequestSigningConfig
+	// AcceptEncoding lists the response encodings requested for
+	// non-streaming calls. Empty keeps Go's transparent gzip handling.
+	AcceptEncoding []string
+	// DataResidency is the residency label requests are matched against.
+	DataResidency string
+	// AllowUnlistedModels routes models missing from the registry to this
+	// provider.
+	AllowUnlistedModels bool
+	// Lazy defers constructing the provider until its first use.
+	Lazy bool
+	// Transport holds the provider's proxy, TLS and connection pool settings.
+	Transport httpclient.TransportOptions
+	// Pacing configures the provider's request pacing. Nil disables it.
+	Pacing *config.PacingConfig
+	// Concurrency configures the provider's in-flight limit and per-model
+	// fair scheduling. Nil disables it.
+	Concurrency *config.ConcurrencyConfig
+	// Extra holds the headers and query parameters added to every request.
+	Extra llmclient.ExtraRequestParams
+	// UpstreamResponseHeaders lists the response headers passed through to
+	// clients on top of the global allow-list.
+	UpstreamResponseHeaders []string
+	// Quirks overrides the serialization quirks of the provider type. Nil
+	// keeps them as built in.
+	Quirks *config.QuirksConfig
 }
 
 // resolveProviders applies env var overrides to the raw YAML provider map, filters
@@ -32,6 +62,15 @@ func resolveProviders(raw map[string]config.RawProviderConfig, global config.Res
 	return buildProviderConfigs(filtered, global), filtered
 }
 
+// applyConnectionPools sets the connection pool of every resolved provider
+// from the global HTTP config and the provider's http overrides in raw.
+func applyConnectionPools(providers map[string]ProviderConfig, raw map[string]config.RawProviderConfig, global config.HTTPConfig) {
+	for name, cfg := range providers {
+		cfg.Transport.Pool = global.PoolOptions(raw[name].HTTP)
+		providers[name] = cfg
+	}
+}
+
 // applyProviderEnvVars overlays well-known provider env vars onto the raw YAML map.
 // Env var values always win over YAML values for the same provider name.
 func applyProviderEnvVars(raw map[string]config.RawProviderConfig, discovery map[string]DiscoveryConfig) map[string]config.RawProviderConfig {
@@ -157,6 +196,22 @@ func envPrefix(providerType string) string {
 	return strings.Trim(b.String(), "_")
 }
 
+// validateBaseURLs rejects malformed base URLs of the resolved providers.
+// YAML base URLs are checked when the config loads; this catches those set
+// through provider env vars such as OPENAI_BASE_URL.
+func validateBaseURLs(resolved map[string]ProviderConfig) error {
+	for _, name := range slices.Sorted(maps.Keys(resolved)) {
+		baseURL := normalizeResolvedBaseURL(resolved[name].BaseURL)
+		if baseURL == "" {
+			continue
+		}
+		if err := httpclient.ValidateBaseURL(baseURL); err != nil {
+			return fmt.Errorf("invalid base_url for provider %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func sortedDiscoveryTypes(discovery map[string]DiscoveryConfig) []string {
 	types := make([]string, 0, len(discovery))
 	for providerType := range discovery {
@@ -235,12 +290,27 @@ func buildProviderConfigs(raw map[string]config.RawProviderConfig, global config
 // Non-nil fields in the raw config override the global defaults.
 func buildProviderConfig(raw config.RawProviderConfig, global config.ResilienceConfig) ProviderConfig {
 	resolved := ProviderConfig{
-		Type:       raw.Type,
-		APIKey:     raw.APIKey,
-		BaseURL:    raw.BaseURL,
-		APIVersion: raw.APIVersion,
-		Models:     raw.Models,
-		Resilience: global,
+		Type:                raw.Type,
+		APIKey:              raw.APIKey,
+		BaseURL:             raw.BaseURL,
+		APIVersion:          raw.APIVersion,
+		Models:              raw.Models,
+		Resilience:          global,
+		Signing:             raw.Signing,
+		AcceptEncoding:      raw.AcceptEncoding,
+		DataResidency:       raw.DataResidency,
+		AllowUnlistedModels: raw.AllowUnlistedModels,
+		Lazy:                raw.Lazy,
+		Transport:           raw.TransportOptions(),
+		Pacing:              raw.Pacing,
+		Concurrency:         raw.Concurrency,
+		Extra: llmclient.ExtraRequestParams{
+			Headers:  raw.ExtraHeaders,
+			Query:    raw.ExtraQuery,
+			Override: raw.ExtraHeadersOverride,
+		},
+		UpstreamResponseHeaders: raw.UpstreamResponseHeaders,
+		Quirks:                  raw.Quirks,
 	}
 
 	if raw.Resilience == nil {
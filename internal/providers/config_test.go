@@ -1,6 +1,11 @@
 package providers
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,6 +71,23 @@ func TestBuildProviderConfig_InheritsGlobal(t *testing.T) {
 	}
 }
 
+func TestApplyConnectionPools(t *testing.T) {
+	maxConns := 4
+	raw := map[string]config.RawProviderConfig{
+		"openai":   {Type: "openai", APIKey: "sk"},
+		"internal": {Type: "openai", APIKey: "sk", HTTP: &config.RawHTTPPoolConfig{MaxConnsPerHost: &maxConns}},
+	}
+	providers := buildProviderConfigs(raw, globalResilience)
+	applyConnectionPools(providers, raw, config.HTTPConfig{MaxIdleConnsPerHost: 300, IdleConnTimeout: time.Minute, ForceAttemptHTTP2: true})
+
+	if pool := providers["openai"].Transport.Pool; pool.MaxIdleConnsPerHost != 300 || pool.MaxConnsPerHost != 0 || pool.IdleConnTimeout != time.Minute {
+		t.Errorf("openai pool = %+v, want the global settings", pool)
+	}
+	if pool := providers["internal"].Transport.Pool; pool.MaxIdleConnsPerHost != 300 || pool.MaxConnsPerHost != 4 {
+		t.Errorf("internal pool = %+v, want its max_conns_per_host override", pool)
+	}
+}
+
 func TestBuildProviderConfig_NilResilience(t *testing.T) {
 	raw := config.RawProviderConfig{Type: "openai", APIKey: "sk", Resilience: nil}
 	got := buildProviderConfig(raw, globalResilience)
@@ -182,6 +204,71 @@ func TestBuildProviderConfig_PreservesFields(t *testing.T) {
 	}
 }
 
+func TestBuildProviderConfig_SigningIsSanitized(t *testing.T) {
+	raw := config.RawProviderConfig{
+		Type:   "openai",
+		APIKey: "sk-key",
+		Signing: &config.RequestSigningConfig{
+			Type:            config.RequestSigningHMACSHA256,
+			Header:          "X-Signature",
+			Secret:          "s3cret",
+			TimestampHeader: "X-Signature-Timestamp",
+		},
+	}
+	got := buildProviderConfig(raw, globalResilience)
+	if got.Signing == nil || got.Signing.Secret != "s3cret" {
+		t.Fatalf("Signing = %+v, want the raw signing config", got.Signing)
+	}
+
+	sanitized := SanitizeProviderConfigs(map[string]ProviderConfig{"internal": got})
+	want := SanitizedSigningConfig{Type: config.RequestSigningHMACSHA256, Header: "X-Signature"}
+	if len(sanitized) != 1 || sanitized[0].Signing == nil || *sanitized[0].Signing != want {
+		t.Fatalf("sanitized Signing = %+v, want %+v", sanitized[0].Signing, want)
+	}
+}
+
+func TestBuildProviderConfig_AcceptEncoding(t *testing.T) {
+	raw := config.RawProviderConfig{
+		Type:           "openai",
+		APIKey:         "sk-key",
+		AcceptEncoding: []string{"zstd", "gzip"},
+	}
+	got := buildProviderConfig(raw, globalResilience)
+	if !slices.Equal(got.AcceptEncoding, raw.AcceptEncoding) {
+		t.Fatalf("AcceptEncoding = %v, want %v", got.AcceptEncoding, raw.AcceptEncoding)
+	}
+
+	sanitized := SanitizeProviderConfigs(map[string]ProviderConfig{"openai": got})
+	if len(sanitized) != 1 || !slices.Equal(sanitized[0].AcceptEncoding, raw.AcceptEncoding) {
+		t.Fatalf("sanitized AcceptEncoding = %+v, want %v", sanitized, raw.AcceptEncoding)
+	}
+}
+
+func TestBuildProviderConfig_ExtraRequestParamsAreSanitized(t *testing.T) {
+	raw := config.RawProviderConfig{
+		Type:         "openai",
+		APIKey:       "sk-key",
+		ExtraHeaders: map[string]string{"Helicone-Auth": "Bearer sk-helicone", "Cf-Access-Client-Id": "id"},
+		ExtraQuery:   map[string]string{"token": "q-secret"},
+	}
+	got := buildProviderConfig(raw, globalResilience)
+	if got.Extra.Headers["Helicone-Auth"] != "Bearer sk-helicone" || got.Extra.Query["token"] != "q-secret" {
+		t.Fatalf("Extra = %+v, want the raw headers and query", got.Extra)
+	}
+
+	sanitized := SanitizeProviderConfigs(map[string]ProviderConfig{"openai": got})
+	if !slices.Equal(sanitized[0].ExtraHeaders, []string{"Cf-Access-Client-Id", "Helicone-Auth"}) || !slices.Equal(sanitized[0].ExtraQuery, []string{"token"}) {
+		t.Fatalf("sanitized = %+v, want only the names", sanitized[0])
+	}
+	body, err := json.Marshal(sanitized)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(body), "sk-helicone") || strings.Contains(string(body), "q-secret") {
+		t.Fatalf("sanitized config leaks values: %s", body)
+	}
+}
+
 // --- buildProviderConfigs ---
 
 func TestBuildProviderConfigs_MultipleProviders(t *testing.T) {
@@ -846,6 +933,57 @@ func TestResolveProviders_EndToEnd(t *testing.T) {
 	}
 }
 
+func TestResolveProviders_ProfileMergedProviders(t *testing.T) {
+	for _, name := range []string{"OPENAI_API_KEY", "OPENAI_BASE_URL", "ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL", "GROQ_API_KEY", "GROQ_BASE_URL"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("STAGING_ANTHROPIC_KEY", "")
+	t.Setenv(config.ProfileEnvVar, "staging")
+	dir := t.TempDir()
+	yaml := `
+providers:
+  openai:
+    type: openai
+    api_key: "sk-openai-base"
+  anthropic:
+    type: anthropic
+    api_key: "sk-ant-base"
+  groq:
+    type: groq
+    api_key: "sk-groq-base"
+profiles:
+  staging:
+    providers:
+      openai:
+        base_url: "https://staging.example.com/v1"
+      anthropic:
+        api_key: "${STAGING_ANTHROPIC_KEY}"
+      groq: ~
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatalf("Failed to write config.yaml: %v", err)
+	}
+	t.Chdir(dir)
+
+	result, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() failed: %v", err)
+	}
+	got, _ := resolveProviders(result.RawProviders, globalResilience, testDiscoveryConfigs)
+
+	if got["openai"].APIKey != "sk-openai-base" || got["openai"].BaseURL != "https://staging.example.com/v1" {
+		t.Errorf("openai = %q at %q, want the base key at the staging URL", got["openai"].APIKey, got["openai"].BaseURL)
+	}
+	// The staging key is unset, so its placeholder replaces the base key and
+	// the provider is dropped rather than falling back to base credentials.
+	if _, ok := got["anthropic"]; ok {
+		t.Error("expected anthropic with an unresolved profile key to be filtered out")
+	}
+	if _, ok := got["groq"]; ok {
+		t.Error("expected groq deleted by the profile to be absent")
+	}
+}
+
 func TestResolveProviders_EmptyRaw_OnlyEnvVars(t *testing.T) {
 	t.Setenv("GROQ_API_KEY", "sk-groq")
 
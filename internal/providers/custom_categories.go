@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+// customCategory is a configured model category with its compiled ID patterns.
+type customCategory struct {
+	id          core.ModelCategory
+	displayName string
+	patterns    []*regexp.Regexp
+}
+
+// compileModelPattern turns a "*" wildcard pattern into an anchored regexp.
+// Unlike path.Match, "*" also matches "/" so patterns can span vendor prefixes.
+func compileModelPattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func (c customCategory) matches(modelID, selector string) bool {
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(modelID) || pattern.MatchString(selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCustomCategories configures user-defined categories, assigned to every
+// model whose ID or provider-qualified selector matches one of the category's
+// patterns. The rules are applied to the current models immediately and
+// re-applied whenever models are refreshed, loaded from cache or re-enriched.
+// Categories are expected to be validated by config.ValidateModelCategories.
+func (r *ModelRegistry) SetCustomCategories(categories []config.ModelCategoryConfig) {
+	compiled := make([]customCategory, 0, len(categories))
+	for _, c := range categories {
+		category := customCategory{
+			id:          core.ModelCategory(c.ID),
+			displayName: c.DisplayName,
+			patterns:    make([]*regexp.Regexp, 0, len(c.Models)),
+		}
+		for _, pattern := range c.Models {
+			category.patterns = append(category.patterns, compileModelPattern(pattern))
+		}
+		compiled = append(compiled, category)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.customCategories = compiled
+
+	replacements := make(map[*ModelInfo]*ModelInfo)
+	applyCustomCategories(compiled, r.modelsByProvider, replacements)
+	r.applyReplacementsLocked(replacements)
+	r.publishSnapshotLocked()
+}
+
+// customCategoryLocked returns the configured custom category with the given ID.
+// Must be called while holding r.mu.
+func (r *ModelRegistry) customCategoryLocked(category core.ModelCategory) (customCategory, bool) {
+	for _, c := range r.customCategories {
+		if c.id == category {
+			return c, true
+		}
+	}
+	return customCategory{}, false
+}
+
+// IsKnownCategory reports whether category is built in or configured through
+// SetCustomCategories.
+func (r *ModelRegistry) IsKnownCategory(category core.ModelCategory) bool {
+	if core.IsBuiltinCategory(category) {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.customCategoryLocked(category)
+	return ok
+}
+
+// applyCustomCategories appends every matching custom category to each model's
+// metadata. Metadata is cloned rather than mutated because it may be shared
+// with the provider response or the model list. As with registryAccessor, a
+// non-nil replacements map means modelsByProvider is live and published
+// ModelInfo values are swapped out instead of modified.
+func applyCustomCategories(
+	categories []customCategory,
+	modelsByProvider map[string]map[string]*ModelInfo,
+	replacements map[*ModelInfo]*ModelInfo,
+) {
+	if len(categories) == 0 {
+		return
+	}
+	for providerName, providerModels := range modelsByProvider {
+		for modelID, info := range providerModels {
+			selector := qualifyPublicModelID(providerName, modelID)
+			var matched []core.ModelCategory
+			for _, c := range categories {
+				if c.matches(modelID, selector) {
+					matched = append(matched, c.id)
+				}
+			}
+			if len(matched) == 0 {
+				continue
+			}
+
+			var meta core.ModelMetadata
+			if info.Model.Metadata != nil {
+				meta = *info.Model.Metadata
+			}
+			meta.Categories = slices.Clone(meta.Categories)
+			for _, category := range matched {
+				if !hasCategory(meta.Categories, category) {
+					meta.Categories = append(meta.Categories, category)
+				}
+			}
+
+			if replacements != nil {
+				cloned := *info
+				cloned.Model.Metadata = &meta
+				providerModels[modelID] = &cloned
+				replacements[info] = &cloned
+				continue
+			}
+			info.Model.Metadata = &meta
+		}
+	}
+}
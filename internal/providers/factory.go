@@ -2,13 +2,18 @@
 package providers
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"sort"
 	"sync"
 
 	"gomodel/config"
 	"gomodel/internal/core"
+	"gomodel/internal/fairshare"
+	"gomodel/internal/httpclient"
 	"gomodel/internal/llmclient"
+	"gomodel/internal/pacing"
 )
 
 // ProviderOptions bundles runtime settings passed from the factory to provider constructors.
@@ -16,6 +21,26 @@ type ProviderOptions struct {
 	Hooks      llmclient.Hooks
 	Models     []string
 	Resilience config.ResilienceConfig
+	// Signing is the optional request signing config. Providers built on
+	// llmclient pass it through so outgoing requests carry a signature header.
+	Signing *config.RequestSigningConfig
+	// AcceptEncoding lists the response encodings providers built on
+	// llmclient request for non-streaming calls.
+	AcceptEncoding []string
+	// HTTPClient carries the provider's proxy and TLS settings and its
+	// extra headers and query parameters. Nil means the default client.
+	HTTPClient *http.Client
+	// Pacer spaces out the provider's upstream requests. Nil means no
+	// pacing. Providers with several clients share it between them.
+	Pacer *pacing.Pacer
+	// Scheduler caps the provider's in-flight upstream requests and shares
+	// them across models. Nil means no limit. Providers with several clients
+	// share it between them.
+	Scheduler *fairshare.Scheduler
+	// Quirks are the provider type's serialization quirks with the
+	// provider's config overrides applied. Providers built on llmclient pass
+	// them to every client that sends JSON bodies.
+	Quirks llmclient.Quirks
 }
 
 // ProviderConstructor is the constructor signature for providers.
@@ -36,6 +61,9 @@ type Registration struct {
 	New                         ProviderConstructor
 	PassthroughSemanticEnricher core.PassthroughSemanticEnricher
 	Discovery                   DiscoveryConfig
+	// Quirks are the serialization rules the provider type's API needs on
+	// outgoing JSON bodies.
+	Quirks llmclient.Quirks
 }
 
 // ProviderFactory manages provider registration and creation.
@@ -44,6 +72,7 @@ type ProviderFactory struct {
 	builders             map[string]ProviderConstructor
 	discoveryConfigs     map[string]DiscoveryConfig
 	passthroughEnrichers map[string]core.PassthroughSemanticEnricher
+	quirks               map[string]llmclient.Quirks
 	hooks                llmclient.Hooks
 }
 
@@ -53,6 +82,7 @@ func NewProviderFactory() *ProviderFactory {
 		builders:             make(map[string]ProviderConstructor),
 		discoveryConfigs:     make(map[string]DiscoveryConfig),
 		passthroughEnrichers: make(map[string]core.PassthroughSemanticEnricher),
+		quirks:               make(map[string]llmclient.Quirks),
 	}
 }
 
@@ -77,6 +107,7 @@ func (f *ProviderFactory) Add(reg Registration) {
 	defer f.mu.Unlock()
 	f.builders[reg.Type] = reg.New
 	f.discoveryConfigs[reg.Type] = reg.Discovery
+	f.quirks[reg.Type] = reg.Quirks
 	if reg.PassthroughSemanticEnricher != nil {
 		f.passthroughEnrichers[reg.Type] = reg.PassthroughSemanticEnricher
 	} else {
@@ -86,22 +117,88 @@ func (f *ProviderFactory) Add(reg Registration) {
 
 // Create instantiates a provider based on its resolved configuration.
 func (f *ProviderFactory) Create(cfg ProviderConfig) (core.Provider, error) {
+	p, _, err := f.create(cfg, llmclient.Hooks{})
+	return p, err
+}
+
+// create instantiates a provider whose upstream rate-limit observations and
+// circuit breaker changes are reported to the non-nil hooks of observe. It
+// also returns the options the provider was built with, whose pacer and
+// scheduler are nil when the provider has none.
+func (f *ProviderFactory) create(cfg ProviderConfig, observe llmclient.Hooks) (core.Provider, ProviderOptions, error) {
 	f.mu.RLock()
 	builder, ok := f.builders[cfg.Type]
+	quirks := f.quirks[cfg.Type]
 	hooks := f.hooks
 	f.mu.RUnlock()
+	if observe.OnRateLimits != nil {
+		hooks.OnRateLimits = observe.OnRateLimits
+	}
+	if observe.OnCircuitChange != nil {
+		hooks.OnCircuitChange = observe.OnCircuitChange
+	}
 
 	if !ok {
-		return nil, fmt.Errorf("unknown provider type: %s", cfg.Type)
+		return nil, ProviderOptions{}, fmt.Errorf("unknown provider type: %s", cfg.Type)
+	}
+
+	quirks, err := quirks.WithOverrides(cfg.Quirks)
+	if err != nil {
+		return nil, ProviderOptions{}, err
+	}
+
+	httpClient, err := newProviderHTTPClient(cfg)
+	if err != nil {
+		return nil, ProviderOptions{}, err
+	}
+	if !cfg.Extra.IsZero() {
+		if httpClient == nil {
+			httpClient = httpclient.NewDefaultHTTPClient()
+		}
+		httpClient = llmclient.WithExtraRequestParams(httpClient, cfg.Extra)
+	}
+	if httpClient == nil {
+		httpClient = httpclient.NewDefaultHTTPClient()
+	}
+	httpClient = llmclient.WithUpstreamResponseHeaders(httpClient, cfg.UpstreamResponseHeaders)
+
+	pacer := pacing.New(cfg.Pacing)
+	if pacer != nil {
+		observe := hooks.OnRateLimits
+		hooks.OnRateLimits = func(ctx context.Context, limits core.RateLimits) {
+			pacer.ObserveRateLimits(limits)
+			if observe != nil {
+				observe(ctx, limits)
+			}
+		}
 	}
 
 	opts := ProviderOptions{
-		Hooks:      hooks,
-		Models:     cfg.Models,
-		Resilience: cfg.Resilience,
+		Hooks:          hooks,
+		Models:         cfg.Models,
+		Resilience:     cfg.Resilience,
+		Signing:        cfg.Signing,
+		AcceptEncoding: cfg.AcceptEncoding,
+		HTTPClient:     httpClient,
+		Pacer:          pacer,
+		Scheduler:      fairshare.New(cfg.Concurrency),
+		Quirks:         quirks,
 	}
 
-	return builder(cfg, opts), nil
+	return builder(cfg, opts), opts, nil
+}
+
+// newProviderHTTPClient builds an HTTP client for a provider with its own
+// proxy or TLS settings. It returns nil when the provider has neither.
+func newProviderHTTPClient(cfg ProviderConfig) (*http.Client, error) {
+	if cfg.Transport.IsZero() {
+		return nil, nil
+	}
+	clientCfg := httpclient.DefaultConfig()
+	if err := cfg.Transport.Apply(&clientCfg); err != nil {
+		return nil, err
+	}
+	return httpclient.NewHTTPClient(&clientCfg), nil
 }
 
 // discoveryConfigsSnapshot returns provider discovery metadata keyed by provider type.
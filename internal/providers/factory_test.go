@@ -3,11 +3,15 @@ package providers
 import (
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"gomodel/config"
 	"gomodel/internal/core"
+	"gomodel/internal/httpclient"
 	"gomodel/internal/llmclient"
 )
 
@@ -413,3 +417,115 @@ func TestProviderFactory_Create_PassesConfiguredModels(t *testing.T) {
 		t.Fatalf("receivedOpts.Models = %v, want [model-a model-b]", receivedOpts.Models)
 	}
 }
+
+func TestProviderFactory_Create_RoutesOnlyConfiguredProviderThroughProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		proxied.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	clients := make(map[string]*llmclient.Client)
+	factory := NewProviderFactory()
+	factory.Add(Registration{
+		Type: "test",
+		New: func(cfg ProviderConfig, opts ProviderOptions) core.Provider {
+			clients[cfg.APIKey] = llmclient.New(llmclient.Config{ProviderName: "test", BaseURL: upstream.URL, HTTPClient: opts.HTTPClient}, nil)
+			return &factoryMockProvider{}
+		},
+	})
+
+	if _, err := factory.Create(ProviderConfig{Type: "test", APIKey: "proxied", Transport: httpclient.TransportOptions{ProxyURL: proxy.URL}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := factory.Create(ProviderConfig{Type: "test", APIKey: "direct"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, name := range []string{"proxied", "direct"} {
+		if err := clients[name].Do(context.Background(), llmclient.Request{Method: http.MethodGet, Endpoint: "/models"}, nil); err != nil {
+			t.Fatalf("%s request failed: %v", name, err)
+		}
+	}
+	if proxied.Load() != 1 {
+		t.Fatalf("proxy saw %d requests, want only the proxied provider's", proxied.Load())
+	}
+}
+
+func TestProviderFactory_Create_RejectsInvalidTransport(t *testing.T) {
+	factory := NewProviderFactory()
+	factory.Add(Registration{
+		Type: "test",
+		New: func(_ ProviderConfig, _ ProviderOptions) core.Provider {
+			return &factoryMockProvider{}
+		},
+	})
+
+	for name, transport := range map[string]httpclient.TransportOptions{
+		"unsupported proxy scheme": {ProxyURL: "ftp://proxy:21"},
+		"missing CA bundle":        {CAFile: "/nonexistent/ca.pem"},
+	} {
+		if _, err := factory.Create(ProviderConfig{Type: "test", Transport: transport}); err == nil {
+			t.Errorf("%s: expected Create() to fail", name)
+		}
+	}
+}
+
+func TestProviderFactory_Create_GivesEachProviderItsOwnPacer(t *testing.T) {
+	var received []ProviderOptions
+	factory := NewProviderFactory()
+	factory.Add(Registration{
+		Type: "test",
+		New: func(_ ProviderConfig, opts ProviderOptions) core.Provider {
+			received = append(received, opts)
+			return &factoryMockProvider{}
+		},
+	})
+
+	var observed int
+	pacingCfg := &config.PacingConfig{RequestsPerSecond: 10, MaxWait: time.Second, AdaptToRateLimits: true}
+	_, primary, err := factory.create(ProviderConfig{Type: "test", Pacing: pacingCfg, Concurrency: &config.ConcurrencyConfig{MaxInFlight: 4}}, llmclient.Hooks{
+		OnRateLimits: func(context.Context, core.RateLimits) { observed++ },
+	})
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	_, fallback, err := factory.create(ProviderConfig{Type: "test", Pacing: pacingCfg}, llmclient.Hooks{})
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	_, unpaced, err := factory.create(ProviderConfig{Type: "test"}, llmclient.Hooks{})
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+
+	if primary.Pacer == nil || fallback.Pacer == nil || primary.Pacer == fallback.Pacer || unpaced.Pacer != nil {
+		t.Fatalf("pacers = %p, %p, %p; want two distinct pacers and none without config", primary.Pacer, fallback.Pacer, unpaced.Pacer)
+	}
+	if received[0].Pacer != primary.Pacer || received[2].Pacer != nil {
+		t.Fatal("builder did not receive the provider's pacer")
+	}
+	if primary.Scheduler == nil || received[0].Scheduler != primary.Scheduler || fallback.Scheduler != nil {
+		t.Fatal("builder did not receive the provider's concurrency scheduler")
+	}
+
+	// Rate-limit observations reach both the pacer and the registry hook.
+	remaining := int64(1)
+	reset := time.Now().Add(time.Minute)
+	received[0].Hooks.OnRateLimits(context.Background(), core.RateLimits{Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &reset}})
+	if observed != 1 {
+		t.Fatalf("registry hook observed %d times, want 1", observed)
+	}
+	if snapshot := primary.Pacer.Snapshot(); snapshot.Requests.RatePerSecond >= 10 {
+		t.Fatalf("rate = %v, want it adapted below 10", snapshot.Requests.RatePerSecond)
+	}
+	if snapshot := fallback.Pacer.Snapshot(); snapshot.Requests.RatePerSecond != 10 {
+		t.Fatalf("fallback rate = %v, want it untouched", snapshot.Requests.RatePerSecond)
+	}
+}
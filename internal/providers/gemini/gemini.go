@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"gomodel/config"
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
@@ -25,6 +26,7 @@ var Registration = providers.Registration{
 	Discovery: providers.DiscoveryConfig{
 		DefaultBaseURL: defaultOpenAICompatibleBaseURL,
 	},
+	Quirks: Quirks,
 }
 
 const (
@@ -46,18 +48,23 @@ type Provider struct {
 
 // New creates a new Gemini provider.
 func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) core.Provider {
-	baseURL := providers.ResolveBaseURL(providerCfg.BaseURL, defaultOpenAICompatibleBaseURL)
+	baseURL := providers.WithDefaultPath(providers.ResolveBaseURL(providerCfg.BaseURL, defaultOpenAICompatibleBaseURL), "/v1beta/openai")
+	modelsURL := modelsBaseURL(baseURL)
 	p := &Provider{
 		httpClient: nil,
 		apiKey:     providerCfg.APIKey,
 		hooks:      opts.Hooks,
-		modelsURL:  defaultModelsBaseURL,
+		modelsURL:  modelsURL,
 		modelsClientConf: llmclient.Config{
 			ProviderName:   "gemini",
-			BaseURL:        defaultModelsBaseURL,
+			BaseURL:        modelsURL,
 			Retry:          opts.Resilience.Retry,
 			Hooks:          opts.Hooks,
 			CircuitBreaker: opts.Resilience.CircuitBreaker,
+			AcceptEncoding: opts.AcceptEncoding,
+			HTTPClient:     opts.HTTPClient,
+			Pacer:          opts.Pacer,
+			Scheduler:      opts.Scheduler,
 		},
 	}
 	clientCfg := llmclient.Config{
@@ -66,6 +73,11 @@ func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) c
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.client = llmclient.New(clientCfg, p.setHeaders)
 	return p
@@ -88,10 +100,18 @@ func NewWithHTTPClient(apiKey string, httpClient *http.Client, hooks llmclient.H
 	p.modelsClientConf = modelsCfg
 	cfg := llmclient.DefaultConfig("gemini", defaultOpenAICompatibleBaseURL)
 	cfg.Hooks = hooks
+	cfg.Quirks = Quirks
 	p.client = llmclient.NewWithHTTPClient(httpClient, cfg, p.setHeaders)
 	return p
 }
 
+// modelsBaseURL returns the native API base URL that sits next to the
+// OpenAI-compatible baseURL, so a proxy prefix applies to models listing too:
+// ".../v1beta/openai" lists models under ".../v1beta".
+func modelsBaseURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(baseURL), "/"), "/openai")
+}
+
 // SetBaseURL allows configuring a custom base URL for the provider
 func (p *Provider) SetBaseURL(url string) {
 	p.client.SetBaseURL(url)
@@ -114,41 +134,25 @@ func (p *Provider) setHeaders(req *http.Request) {
 	}
 }
 
-// adaptChatRequest rewrites a ChatRequest for Gemini's OpenAI-compatible endpoint.
-// Gemini uses "reasoning_effort" as a top-level string (e.g. "low", "medium", "high"),
-// not the nested "reasoning": {"effort": "..."} format.
-func adaptChatRequest(req *core.ChatRequest) (any, error) {
-	if req.Reasoning == nil || req.Reasoning.Effort == "" {
-		return req, nil
-	}
-
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, core.NewInvalidRequestError("failed to marshal gemini request: "+err.Error(), err)
-	}
-
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return nil, core.NewInvalidRequestError("failed to decode gemini request payload: "+err.Error(), err)
-	}
-
-	effort, _ := json.Marshal(req.Reasoning.Effort)
-	raw["reasoning_effort"] = effort
-	delete(raw, "reasoning")
-	return raw, nil
-}
+// Quirks are the serialization rules of Gemini's OpenAI-compatible endpoint,
+// which takes "reasoning_effort" as a top-level string (e.g. "low", "medium",
+// "high") instead of the nested "reasoning": {"effort": "..."} format.
+var Quirks = llmclient.Quirks{Rules: []config.QuirkRule{{
+	Name:      "reasoning_effort",
+	Endpoints: []string{"/chat/completions"},
+	Rename:    map[string]string{"reasoning.effort": "reasoning_effort"},
+}}}
 
 // ChatCompletion sends a chat completion request to Gemini
 func (p *Provider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
-	body, err := adaptChatRequest(req)
-	if err != nil {
-		return nil, err
+	if req == nil {
+		return nil, core.NewInvalidRequestError("gemini chat request is required", nil)
 	}
 	var resp core.ChatResponse
-	err = p.client.Do(ctx, llmclient.Request{
+	err := p.client.Do(ctx, llmclient.Request{
 		Method:   http.MethodPost,
 		Endpoint: "/chat/completions",
-		Body:     body,
+		Body:     req,
 	}, &resp)
 	if err != nil {
 		return nil, err
@@ -156,20 +160,19 @@ func (p *Provider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*
 	if resp.Model == "" {
 		resp.Model = req.Model
 	}
+	addGroundingAnnotations(&resp)
 	return &resp, nil
 }
 
 // StreamChatCompletion returns a raw response body for streaming (caller must close)
 func (p *Provider) StreamChatCompletion(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
-	streamReq := req.WithStreaming()
-	body, err := adaptChatRequest(streamReq)
-	if err != nil {
-		return nil, err
+	if req == nil {
+		return nil, core.NewInvalidRequestError("gemini chat request is required", nil)
 	}
 	stream, err := p.client.DoStream(ctx, llmclient.Request{
 		Method:   http.MethodPost,
 		Endpoint: "/chat/completions",
-		Body:     body,
+		Body:     req.WithStreaming(),
 	})
 	if err != nil {
 		return nil, err
@@ -12,6 +12,7 @@ import (
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestNew(t *testing.T) {
@@ -439,6 +440,120 @@ func TestResponses(t *testing.T) {
 	}
 }
 
+func TestResponses_MapsGroundingMetadataToAnnotations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"id": "gemini-grounded",
+			"object": "chat.completion",
+			"created": 1677652288,
+			"model": "gemini-2.5-flash",
+			"choices": [{
+				"index": 0,
+				"message": {
+					"role": "assistant",
+					"content": "Kraków is in Poland. It is old.",
+					"extra_content": {"google": {"grounding_metadata": {
+						"grounding_chunks": [
+							{"web": {"uri": "https://example.com/krakow", "title": "example.com"}},
+							{"retrieved_context": {"uri": "gs://bucket/doc"}}
+						],
+						"grounding_supports": [{
+							"segment": {"start_index": 22, "end_index": 32, "text": "It is old."},
+							"grounding_chunk_indices": [0, 1, 7]
+						}]
+					}}}
+				},
+				"finish_reason": "stop"
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+
+	resp, err := provider.Responses(context.Background(), &core.ResponsesRequest{Model: "gemini-2.5-flash", Input: "Tell me about Kraków"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	annotations := resp.Output[0].Content[0].Annotations
+	if len(annotations) != 1 {
+		t.Fatalf("len(Annotations) = %d, want 1: %+v", len(annotations), annotations)
+	}
+	got := annotations[0]
+	if got.Type != core.AnnotationTypeURLCitation || got.URL != "https://example.com/krakow" || *got.StartIndex != 21 || *got.EndIndex != 31 {
+		t.Fatalf("annotation = %+v, want url_citation over characters [21,31)", got)
+	}
+}
+
+func TestResponses_MultiTurnToolExchange(t *testing.T) {
+	var upstream struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&upstream); err != nil {
+			t.Fatalf("failed to decode upstream request: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-456",
+			"object": "chat.completion",
+			"created": 1677652288,
+			"model": "gemini-2.5-flash",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Rain is likely."}, "finish_reason": "stop"}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+
+	var req core.ResponsesRequest
+	if err := json.Unmarshal([]byte(`{"model": "gemini-2.5-flash", "input": [
+				{"role": "user", "content": [{"type": "input_text", "text": "What's the weather in Paris?"}]},
+				{"type": "reasoning", "id": "rs_1", "summary": []},
+				{"type": "function_call", "id": "fc_1", "call_id": "call_weather", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}", "status": "completed"},
+				{"type": "function_call_output", "id": "fco_1", "call_id": "call_weather", "output": "{\"temp_c\":18}"},
+				{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed", "content": [{"type": "output_text", "text": "It is 18C.", "annotations": []}]},
+				{"role": "user", "content": "And tomorrow?"}
+			]}`), &req); err != nil {
+		t.Fatalf("failed to decode responses request: %v", err)
+	}
+	if _, err := provider.Responses(context.Background(), &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := upstream.Messages
+	if len(msgs) != 5 {
+		t.Fatalf("len(messages) = %d, want 5: %v", len(msgs), msgs)
+	}
+	for i, role := range []string{"user", "assistant", "tool", "assistant", "user"} {
+		if msgs[i]["role"] != role {
+			t.Fatalf("messages[%d].role = %v, want %s", i, msgs[i]["role"], role)
+		}
+	}
+	toolCalls, _ := msgs[1]["tool_calls"].([]any)
+	if len(toolCalls) != 1 {
+		t.Fatalf("assistant tool_calls = %v, want one call", msgs[1]["tool_calls"])
+	}
+	call, _ := toolCalls[0].(map[string]any)
+	function, _ := call["function"].(map[string]any)
+	if call["id"] != "call_weather" || function["name"] != "get_weather" || function["arguments"] != `{"city":"Paris"}` {
+		t.Errorf("tool call = %v", call)
+	}
+	if msgs[2]["tool_call_id"] != "call_weather" || msgs[2]["content"] != `{"temp_c":18}` {
+		t.Errorf("tool result = %v", msgs[2])
+	}
+	if msgs[3]["content"] != "It is 18C." {
+		t.Errorf("assistant reply = %v", msgs[3])
+	}
+	if _, ok := msgs[3]["id"]; ok {
+		t.Errorf("assistant message leaks Responses item id: %v", msgs[3])
+	}
+}
+
 func TestStreamResponses(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -479,3 +594,64 @@ data: [DONE]
 		t.Error("response should end with [DONE]")
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "gemini",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	provider.(*Provider).SetModelsURL(server.URL)
+
+	testfixtures.CallEveryEndpoint(t, provider, "gemini-2.0-flash")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+// Models are listed from the native API next to the OpenAI-compatible one,
+// under the same prefix.
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "gemini",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "gemini-2.0-flash",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/gemini/v1beta/openai", ChatPath: "/proxy/gemini/v1beta/openai/chat/completions", Prefix: "/proxy/gemini/v1beta/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/gemini/v1beta/openai/", ChatPath: "/proxy/gemini/v1beta/openai/chat/completions", Prefix: "/proxy/gemini/v1beta/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/v1beta/openai/chat/completions", Prefix: "/v1beta/"},
+	)
+}
+
+func TestConversionProperties(t *testing.T) {
+	suite := testfixtures.ChatAdapterSuite(providers.ConvertResponsesRequestToChat, providers.ConvertChatResponseToResponses)
+	suite.ChatRequest = func(req *core.ChatRequest) (testfixtures.ConvertedRequest, error) {
+		encoded, err := json.Marshal(req)
+		if err != nil {
+			return testfixtures.ConvertedRequest{}, err
+		}
+		if encoded, err = Quirks.Apply("/chat/completions", encoded); err != nil {
+			return testfixtures.ConvertedRequest{}, err
+		}
+		var sent core.ChatRequest
+		if err := json.Unmarshal(encoded, &sent); err != nil {
+			return testfixtures.ConvertedRequest{}, err
+		}
+		return testfixtures.ChatRequestView(&sent), nil
+	}
+	testfixtures.CheckConversionProperties(t, suite)
+}
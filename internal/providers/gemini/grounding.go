@@ -0,0 +1,145 @@
+package gemini
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"gomodel/internal/core"
+)
+
+// groundingMetadata is the part of Gemini's grounding metadata that locates
+// web sources in the answer. Gemini's native API spells keys in camelCase;
+// the OpenAI-compatible endpoint may spell them in snake_case, so decoding
+// goes through camelizeKeys.
+type groundingMetadata struct {
+	GroundingChunks []struct {
+		Web *struct {
+			URI   string `json:"uri"`
+			Title string `json:"title"`
+		} `json:"web"`
+	} `json:"groundingChunks"`
+	GroundingSupports []struct {
+		Segment struct {
+			StartIndex int `json:"startIndex"`
+			EndIndex   int `json:"endIndex"`
+		} `json:"segment"`
+		GroundingChunkIndices []int `json:"groundingChunkIndices"`
+	} `json:"groundingSupports"`
+}
+
+// addGroundingAnnotations maps the grounding metadata of each choice to
+// url_citation annotations on its message, the form the Chat Completions and
+// Responses conversions carry citations in. Messages that already have
+// annotations are left alone.
+func addGroundingAnnotations(resp *core.ChatResponse) {
+	for i := range resp.Choices {
+		msg := &resp.Choices[i].Message
+		if msg.ExtraFields.Lookup("annotations") != nil {
+			continue
+		}
+		metadata, ok := messageGroundingMetadata(msg.ExtraFields)
+		if !ok {
+			continue
+		}
+		annotations := groundingAnnotations(core.ExtractTextContent(msg.Content), metadata)
+		if len(annotations) == 0 {
+			continue
+		}
+		raw, err := core.MarshalChatAnnotations(annotations)
+		if err != nil {
+			continue
+		}
+		msg.ExtraFields = msg.ExtraFields.With("annotations", raw)
+	}
+}
+
+func messageGroundingMetadata(fields core.UnknownJSONFields) (groundingMetadata, bool) {
+	raw := fields.Lookup("grounding_metadata")
+	if raw == nil {
+		var extra struct {
+			Google struct {
+				GroundingMetadata json.RawMessage `json:"grounding_metadata"`
+			} `json:"google"`
+		}
+		if err := json.Unmarshal(fields.Lookup("extra_content"), &extra); err == nil {
+			raw = extra.Google.GroundingMetadata
+		}
+	}
+	if raw == nil {
+		return groundingMetadata{}, false
+	}
+	var metadata groundingMetadata
+	if err := json.Unmarshal(camelizeKeys(raw), &metadata); err != nil {
+		return groundingMetadata{}, false
+	}
+	return metadata, true
+}
+
+// groundingAnnotations returns one url_citation per supported segment and
+// web source. Gemini segment indexes count UTF-8 bytes; annotations count
+// characters.
+func groundingAnnotations(text string, metadata groundingMetadata) []core.ResponsesAnnotation {
+	var annotations []core.ResponsesAnnotation
+	for _, support := range metadata.GroundingSupports {
+		start, end := support.Segment.StartIndex, support.Segment.EndIndex
+		if start < 0 || end < start || end > len(text) {
+			continue
+		}
+		startChar := utf8.RuneCountInString(text[:start])
+		endChar := startChar + utf8.RuneCountInString(text[start:end])
+		for _, index := range support.GroundingChunkIndices {
+			if index < 0 || index >= len(metadata.GroundingChunks) {
+				continue
+			}
+			web := metadata.GroundingChunks[index].Web
+			if web == nil || web.URI == "" {
+				continue
+			}
+			annotations = append(annotations, core.NewURLCitation(web.URI, web.Title, startChar, endChar))
+		}
+	}
+	return annotations
+}
+
+// camelizeKeys rewrites the snake_case object keys of a JSON value in
+// camelCase.
+func camelizeKeys(raw json.RawMessage) json.RawMessage {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+	encoded, err := json.Marshal(camelizeValue(value))
+	if err != nil {
+		return raw
+	}
+	return encoded
+}
+
+func camelizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[camelize(key)] = camelizeValue(item)
+		}
+		return result
+	case []any:
+		for i, item := range v {
+			v[i] = camelizeValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func camelize(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
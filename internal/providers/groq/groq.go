@@ -41,6 +41,11 @@ func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) c
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.client = llmclient.New(clientCfg, p.setHeaders)
 	return p
@@ -113,6 +118,12 @@ func (p *Provider) ListModels(ctx context.Context) (*core.ModelsResponse, error)
 	return &resp, nil
 }
 
+// FetchRateLimits lists models and returns the account limits reported in the
+// response's x-ratelimit-* headers.
+func (p *Provider) FetchRateLimits(ctx context.Context) (core.RateLimits, error) {
+	return p.client.FetchRateLimits(ctx, "/models")
+}
+
 // Responses sends a Responses API request to Groq (converted to chat format)
 func (p *Provider) Responses(ctx context.Context, req *core.ResponsesRequest) (*core.ResponsesResponse, error) {
 	return providers.ResponsesViaChat(ctx, p, req)
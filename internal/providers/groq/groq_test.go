@@ -5,15 +5,30 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
+const testModel = "llama-3.3-70b-versatile"
+
+// bearerJSON holds the headers every Groq call must carry.
+var bearerJSON = testfixtures.Route{
+	Headers:        map[string]string{"Content-Type": "application/json"},
+	HeaderPrefixes: map[string]string{"Authorization": "Bearer "},
+}
+
+// requireChatStream verifies stream is set in the chat request body.
+func requireChatStream(t testing.TB, _ *http.Request, body []byte) {
+	if req, ok := testfixtures.DecodeJSON[core.ChatRequest](t, body); ok && !req.Stream {
+		t.Error("Stream should be true in request")
+	}
+}
+
 func TestNew(t *testing.T) {
 	apiKey := "test-api-key"
 	// Use NewWithHTTPClient to get concrete type for internal testing
@@ -44,27 +59,9 @@ func TestChatCompletion(t *testing.T) {
 		checkResponse func(*testing.T, *core.ChatResponse)
 	}{
 		{
-			name:       "successful request",
-			statusCode: http.StatusOK,
-			responseBody: `{
-				"id": "chatcmpl-123",
-				"object": "chat.completion",
-				"created": 1677652288,
-				"model": "llama-3.3-70b-versatile",
-				"choices": [{
-					"index": 0,
-					"message": {
-						"role": "assistant",
-						"content": "Hello! How can I help you today?"
-					},
-					"finish_reason": "stop"
-				}],
-				"usage": {
-					"prompt_tokens": 10,
-					"completion_tokens": 20,
-					"total_tokens": 30
-				}
-			}`,
+			name:          "successful request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ChatCompletion{Model: testModel}.JSON(),
 			expectedError: false,
 			checkResponse: func(t *testing.T, resp *core.ChatResponse) {
 				if resp.ID != "chatcmpl-123" {
@@ -93,61 +90,37 @@ func TestChatCompletion(t *testing.T) {
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 		{
 			name:          "rate limit error",
 			statusCode:    http.StatusTooManyRequests,
-			responseBody:  `{"error": {"message": "Rate limit exceeded"}}`,
+			responseBody:  testfixtures.OpenAIError("Rate limit exceeded"),
 			expectedError: true,
 		},
 		{
 			name:          "server error",
 			statusCode:    http.StatusInternalServerError,
-			responseBody:  `{"error": {"message": "Internal server error"}}`,
+			responseBody:  testfixtures.OpenAIError("Internal server error"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ChatRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			route := bearerJSON
+			route.Check = func(t testing.TB, _ *http.Request, body []byte) {
+				testfixtures.DecodeJSON[core.ChatRequest](t, body)
+			}
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ChatRequest{
-				Model: "llama-3.3-70b-versatile",
-				Messages: []core.Message{
-					{Role: "user", Content: "Hello"},
-				},
-			}
-
-			resp, err := provider.ChatCompletion(context.Background(), req)
+			resp, err := provider.ChatCompletion(context.Background(), testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -173,65 +146,31 @@ func TestStreamChatCompletion(t *testing.T) {
 		expectedError bool
 	}{
 		{
-			name:       "successful streaming request",
-			statusCode: http.StatusOK,
-			responseBody: `data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"llama-3.3-70b-versatile","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
-
-data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"llama-3.3-70b-versatile","choices":[{"index":0,"delta":{"content":"!"},"finish_reason":null}]}
-
-data: [DONE]
-`,
+			name:          "successful streaming request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ChatCompletionChunks(testModel, "Hello", "!"),
 			expectedError: false,
 		},
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify stream is set in request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ChatRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-				if !req.Stream {
-					t.Error("Stream should be true in request")
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			route := bearerJSON
+			route.Check = requireChatStream
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ChatRequest{
-				Model: "llama-3.3-70b-versatile",
-				Messages: []core.Message{
-					{Role: "user", Content: "Hello"},
-				},
-			}
-
-			body, err := provider.StreamChatCompletion(context.Background(), req)
+			body, err := provider.StreamChatCompletion(context.Background(), testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -268,25 +207,9 @@ func TestListModels(t *testing.T) {
 		checkResponse func(*testing.T, *core.ModelsResponse)
 	}{
 		{
-			name:       "successful request",
-			statusCode: http.StatusOK,
-			responseBody: `{
-				"object": "list",
-				"data": [
-					{
-						"id": "llama-3.3-70b-versatile",
-						"object": "model",
-						"created": 1687882411,
-						"owned_by": "groq"
-					},
-					{
-						"id": "mixtral-8x7b-32768",
-						"object": "model",
-						"created": 1687882410,
-						"owned_by": "groq"
-					}
-				]
-			}`,
+			name:          "successful request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ModelsList("groq", "llama-3.3-70b-versatile", "mixtral-8x7b-32768"),
 			expectedError: false,
 			checkResponse: func(t *testing.T, resp *core.ModelsResponse) {
 				if resp.Object != "list" {
@@ -306,32 +229,20 @@ func TestListModels(t *testing.T) {
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request method and path
-				if r.Method != http.MethodGet {
-					t.Errorf("Method = %q, want %q", r.Method, http.MethodGet)
-				}
-				if r.URL.Path != "/models" {
-					t.Errorf("Path = %q, want %q", r.URL.Path, "/models")
-				}
-
-				// Verify authorization header
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			server := testfixtures.NewServer(t, testfixtures.Route{
+				Method:         http.MethodGet,
+				Path:           "/models",
+				HeaderPrefixes: map[string]string{"Authorization": "Bearer "},
+				Status:         tt.statusCode,
+				Body:           tt.responseBody,
+			})
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
@@ -355,12 +266,7 @@ func TestListModels(t *testing.T) {
 }
 
 func TestChatCompletionWithContext(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simulate a slow response
-		<-r.Context().Done()
-		w.WriteHeader(http.StatusRequestTimeout)
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{Hang: true})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
@@ -368,58 +274,23 @@ func TestChatCompletionWithContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	req := &core.ChatRequest{
-		Model: "llama-3.3-70b-versatile",
-		Messages: []core.Message{
-			{Role: "user", Content: "Hello"},
-		},
-	}
-
-	_, err := provider.ChatCompletion(ctx, req)
+	_, err := provider.ChatCompletion(ctx, testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 	if err == nil {
 		t.Error("expected error when context is cancelled, got nil")
 	}
 }
 
 func TestResponses(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Verify request path for chat completions (Groq converts Responses to chat)
-		if r.URL.Path != "/chat/completions" {
-			t.Errorf("Path = %q, want %q", r.URL.Path, "/chat/completions")
-		}
-
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{
-			"id": "chatcmpl-123",
-			"object": "chat.completion",
-			"created": 1677652288,
-			"model": "llama-3.3-70b-versatile",
-			"choices": [{
-				"index": 0,
-				"message": {
-					"role": "assistant",
-					"content": "Hello! How can I help you today?"
-				},
-				"finish_reason": "stop"
-			}],
-			"usage": {
-				"prompt_tokens": 10,
-				"completion_tokens": 20,
-				"total_tokens": 30
-			}
-		}`))
-	}))
-	defer server.Close()
+	// Groq converts Responses to chat completions
+	server := testfixtures.NewServer(t, testfixtures.Route{
+		Path: "/chat/completions",
+		Body: testfixtures.ChatCompletion{Model: testModel}.JSON(),
+	})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
 
-	req := &core.ResponsesRequest{
-		Model: "llama-3.3-70b-versatile",
-		Input: "Hello",
-	}
-
-	resp, err := provider.Responses(context.Background(), req)
+	resp, err := provider.Responses(context.Background(), testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -457,57 +328,34 @@ func TestResponses(t *testing.T) {
 }
 
 func TestResponsesWithArrayInput(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	server := testfixtures.NewServer(t, testfixtures.Route{
 		// Verify request body is converted to chat format
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			t.Fatalf("failed to read request body: %v", err)
-		}
-
-		var req map[string]any
-		if err := json.Unmarshal(body, &req); err != nil {
-			t.Fatalf("failed to unmarshal request: %v", err)
-		}
-
-		// Verify messages array exists (converted from input)
-		messages, ok := req["messages"].([]any)
-		if !ok {
-			t.Fatal("messages should be an array")
-		}
-		// Should have system message + 2 input messages
-		if len(messages) != 3 {
-			t.Errorf("len(messages) = %d, want 3", len(messages))
-		}
-
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{
-			"id": "chatcmpl-123",
-			"object": "chat.completion",
-			"created": 1677652288,
-			"model": "llama-3.3-70b-versatile",
-			"choices": [{
-				"index": 0,
-				"message": {
-					"role": "assistant",
-					"content": "Hello!"
-				},
-				"finish_reason": "stop"
-			}],
-			"usage": {
-				"prompt_tokens": 10,
-				"completion_tokens": 5,
-				"total_tokens": 15
+		Check: func(t testing.TB, _ *http.Request, body []byte) {
+			req, ok := testfixtures.DecodeJSON[map[string]any](t, body)
+			if !ok {
+				return
 			}
-		}`))
-	}))
-	defer server.Close()
+
+			// Verify messages array exists (converted from input)
+			messages, ok := req["messages"].([]any)
+			if !ok {
+				t.Error("messages should be an array")
+				return
+			}
+			// Should have system message + 2 input messages
+			if len(messages) != 3 {
+				t.Errorf("len(messages) = %d, want 3", len(messages))
+			}
+		},
+		Body: testfixtures.ChatCompletion{Model: testModel, Content: "Hello!", PromptTokens: 10, CompletionTokens: 5}.JSON(),
+	})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
 
-	req := &core.ResponsesRequest{
-		Model: "llama-3.3-70b-versatile",
-		Input: []any{
+	req := testfixtures.ResponsesRequest(
+		testfixtures.ResponsesModel(testModel),
+		testfixtures.ResponsesInput([]any{
 			map[string]any{
 				"role":    "user",
 				"content": "Hello",
@@ -516,9 +364,9 @@ func TestResponsesWithArrayInput(t *testing.T) {
 				"role":    "assistant",
 				"content": "Hi there!",
 			},
-		},
-		Instructions: "Be helpful",
-	}
+		}),
+		testfixtures.ResponsesInstructions("Be helpful"),
+	)
 
 	resp, err := provider.Responses(context.Background(), req)
 	if err != nil {
@@ -531,86 +379,58 @@ func TestResponsesWithArrayInput(t *testing.T) {
 }
 
 func TestResponses_PreservesOpaqueFieldsThroughChatAdapter(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/chat/completions" {
-			t.Errorf("Path = %q, want %q", r.URL.Path, "/chat/completions")
-		}
-
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			t.Fatalf("failed to read request body: %v", err)
-		}
-
-		var req core.ChatRequest
-		if err := json.Unmarshal(body, &req); err != nil {
-			t.Fatalf("failed to unmarshal chat request: %v", err)
-		}
-		if req.ExtraFields.Lookup("response_format") == nil {
-			t.Fatal("response_format missing after responses-to-chat conversion")
-		}
-		if len(req.Messages) != 1 {
-			t.Fatalf("len(Messages) = %d, want 1", len(req.Messages))
-		}
-		if req.Messages[0].ExtraFields.Lookup("x_message_hint") == nil {
-			t.Fatal("message extras missing after conversion")
-		}
-		parts, ok := req.Messages[0].Content.([]core.ContentPart)
-		if !ok {
-			t.Fatalf("Messages[0].Content type = %T, want []core.ContentPart", req.Messages[0].Content)
-		}
-		if parts[0].ExtraFields.Lookup("cache_control") == nil {
-			t.Fatal("content part extras missing after conversion")
-		}
-
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{
-			"id": "chatcmpl-opaque",
-			"object": "chat.completion",
-			"created": 1677652288,
-			"model": "llama-3.3-70b-versatile",
-			"choices": [{
-				"index": 0,
-				"message": {
-					"role": "assistant",
-					"content": "ok"
-				},
-				"finish_reason": "stop"
-			}],
-			"usage": {
-				"prompt_tokens": 1,
-				"completion_tokens": 1,
-				"total_tokens": 2
+	server := testfixtures.NewServer(t, testfixtures.Route{
+		Path: "/chat/completions",
+		Check: func(t testing.TB, _ *http.Request, body []byte) {
+			req, ok := testfixtures.DecodeJSON[core.ChatRequest](t, body)
+			if !ok {
+				return
 			}
-		}`))
-	}))
-	defer server.Close()
+			if req.ExtraFields.Lookup("response_format") == nil {
+				t.Error("response_format missing after responses-to-chat conversion")
+			}
+			if len(req.Messages) != 1 {
+				t.Errorf("len(Messages) = %d, want 1", len(req.Messages))
+				return
+			}
+			if req.Messages[0].ExtraFields.Lookup("x_message_hint") == nil {
+				t.Error("message extras missing after conversion")
+			}
+			parts, ok := req.Messages[0].Content.([]core.ContentPart)
+			if !ok {
+				t.Errorf("Messages[0].Content type = %T, want []core.ContentPart", req.Messages[0].Content)
+				return
+			}
+			if parts[0].ExtraFields.Lookup("cache_control") == nil {
+				t.Error("content part extras missing after conversion")
+			}
+		},
+		Body: testfixtures.ChatCompletion{ID: "chatcmpl-opaque", Model: testModel, Content: "ok", PromptTokens: 1, CompletionTokens: 1}.JSON(),
+	})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
 
-	req := &core.ResponsesRequest{
-		Model: "llama-3.3-70b-versatile",
-		Input: []core.ResponsesInputElement{
-			{
-				Role: "user",
-				Content: []core.ContentPart{
-					{
-						Type: "input_text",
-						Text: "Hello",
-						ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
-							"cache_control": json.RawMessage(`{"type":"ephemeral"}`),
-						}),
-					},
+	req := testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel), testfixtures.ResponsesInput([]core.ResponsesInputElement{
+		{
+			Role: "user",
+			Content: []core.ContentPart{
+				{
+					Type: "input_text",
+					Text: "Hello",
+					ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+						"cache_control": json.RawMessage(`{"type":"ephemeral"}`),
+					}),
 				},
-				ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
-					"x_message_hint": json.RawMessage(`true`),
-				}),
 			},
+			ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+				"x_message_hint": json.RawMessage(`true`),
+			}),
 		},
-		ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
-			"response_format": json.RawMessage(`{"type":"json_schema"}`),
-		}),
-	}
+	}))
+	req.ExtraFields = core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+		"response_format": json.RawMessage(`{"type":"json_schema"}`),
+	})
 
 	if _, err := provider.Responses(context.Background(), req); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -618,39 +438,15 @@ func TestResponses_PreservesOpaqueFieldsThroughChatAdapter(t *testing.T) {
 }
 
 func TestStreamResponses(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Verify stream is set in request body
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			t.Fatalf("failed to read request body: %v", err)
-		}
-		var req core.ChatRequest
-		if err := json.Unmarshal(body, &req); err != nil {
-			t.Fatalf("failed to unmarshal request: %v", err)
-		}
-		if !req.Stream {
-			t.Error("Stream should be true in request")
-		}
-
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"llama-3.3-70b-versatile","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
-
-data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"llama-3.3-70b-versatile","choices":[{"index":0,"delta":{"content":"!"},"finish_reason":null}]}
-
-data: [DONE]
-`))
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{
+		Check: requireChatStream,
+		Body:  testfixtures.ChatCompletionChunks(testModel, "Hello", "!"),
+	})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
 
-	req := &core.ResponsesRequest{
-		Model: "llama-3.3-70b-versatile",
-		Input: "Hello",
-	}
-
-	body, err := provider.StreamResponses(context.Background(), req)
+	body, err := provider.StreamResponses(context.Background(), testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -677,12 +473,7 @@ data: [DONE]
 }
 
 func TestResponsesWithContext(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simulate a slow response
-		<-r.Context().Done()
-		w.WriteHeader(http.StatusRequestTimeout)
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{Hang: true})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
@@ -690,12 +481,7 @@ func TestResponsesWithContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	req := &core.ResponsesRequest{
-		Model: "llama-3.3-70b-versatile",
-		Input: "Hello",
-	}
-
-	_, err := provider.Responses(ctx, req)
+	_, err := provider.Responses(ctx, testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 	if err == nil {
 		t.Error("expected error when context is cancelled, got nil")
 	}
@@ -815,11 +601,7 @@ func TestSetBaseURL(t *testing.T) {
 
 	// We can't directly check the baseURL as it's encapsulated in llmclient
 	// but we can verify the provider still works by making a test request
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: testfixtures.ModelsList("groq")})
 
 	provider.SetBaseURL(server.URL)
 	_, err := provider.ListModels(context.Background())
@@ -827,3 +609,42 @@ func TestSetBaseURL(t *testing.T) {
 		t.Errorf("SetBaseURL should allow using custom URL: %v", err)
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "groq",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "llama-3.3-70b-versatile")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "groq",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "llama-3.3-70b-versatile",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/groq/openai/v1", ChatPath: "/proxy/groq/openai/v1/chat/completions", Prefix: "/proxy/groq/openai/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/groq/openai/v1/", ChatPath: "/proxy/groq/openai/v1/chat/completions", Prefix: "/proxy/groq/openai/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
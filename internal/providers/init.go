@@ -3,7 +3,6 @@ package providers
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"path/filepath"
 	"sort"
 	"sync"
@@ -13,9 +12,14 @@ import (
 	"gomodel/internal/cache"
 	"gomodel/internal/cache/modelcache"
 	"gomodel/internal/core"
+	"gomodel/internal/llmclient"
+	"gomodel/internal/logging"
 	"gomodel/internal/modeldata"
 )
 
+// providersLogger logs provider initialization and stream conversion events.
+var providersLogger = logging.Component(logging.ComponentProviders)
+
 // InitResult holds the initialized provider infrastructure and cleanup functions.
 type InitResult struct {
 	Registry *ModelRegistry
@@ -81,6 +85,10 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 	}
 
 	providerMap, credentialResolved := resolveProviders(result.RawProviders, result.Config.Resilience, factory.discoveryConfigsSnapshot())
+	if err := validateBaseURLs(providerMap); err != nil {
+		return nil, err
+	}
+	applyConnectionPools(providerMap, credentialResolved, result.Config.HTTP)
 
 	modelCache, err := initCache(result.Config)
 	if err != nil {
@@ -89,6 +97,7 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 
 	registry := NewModelRegistry()
 	registry.SetCache(modelCache)
+	registry.SetCustomCategories(result.Config.Models.Categories)
 
 	count, err := initializeProviders(ctx, providerMap, factory, registry)
 	if err != nil {
@@ -100,10 +109,10 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 		return nil, fmt.Errorf("no providers were successfully registered")
 	}
 
-	slog.Info("starting non-blocking model registry initialization...")
+	providersLogger.Info("starting non-blocking model registry initialization...")
 	registry.InitializeAsync(ctx)
 
-	slog.Info("model registry configured",
+	providersLogger.Info("model registry configured",
 		"cached_models", registry.ModelCount(),
 		"providers", registry.ProviderCount(),
 	)
@@ -117,7 +126,7 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 
 			list, raw, err := modeldata.Fetch(fetchCtx, modelListURL)
 			if err != nil {
-				slog.Warn("failed to fetch model list", "url", modelListURL, "error", err)
+				providersLogger.Warn("failed to fetch model list", "url", modelListURL, "error", err)
 				return
 			}
 			if list == nil {
@@ -128,7 +137,7 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 			metadataStats := registry.enrichModels()
 
 			if err := registry.SaveToCache(fetchCtx); err != nil {
-				slog.Warn("failed to save cache after model list fetch", "error", err)
+				providersLogger.Warn("failed to save cache after model list fetch", "error", err)
 			}
 			attrs := []any{
 				"models", len(list.Models),
@@ -136,7 +145,7 @@ func Init(ctx context.Context, result *config.LoadResult, factory *ProviderFacto
 				"provider_models", len(list.ProviderModels),
 			}
 			attrs = append(attrs, metadataStats.slogAttrs()...)
-			slog.Info("model list loaded", attrs...)
+			providersLogger.Info("model list loaded", attrs...)
 		}()
 	}
 
@@ -185,7 +194,7 @@ func initCache(cfg *config.Config) (modelcache.Cache, error) {
 		if key == "" {
 			key = modelcache.DefaultRedisKey
 		}
-		slog.Info("using redis cache", "key", key)
+		providersLogger.Info("using redis cache", "key", key)
 		return mc, nil
 	}
 	if m.Local != nil {
@@ -193,9 +202,23 @@ func initCache(cfg *config.Config) (modelcache.Cache, error) {
 		if cacheDir == "" {
 			cacheDir = ".cache"
 		}
-		cacheFile := filepath.Join(cacheDir, "models.json")
-		slog.Info("using local file cache", "path", cacheFile)
-		return modelcache.NewLocalCache(cacheFile), nil
+		maxBytes, err := config.ParseBodySizeLimitBytes(m.Local.MaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("cache.model.local.max_size: %w", err)
+		}
+		local := modelcache.NewManagedLocalCache(cacheDir, modelcache.CleanupPolicy{
+			MaxAge:   m.Local.MaxAge,
+			MaxBytes: maxBytes,
+		})
+		removed, err := local.Cleanup(time.Now())
+		if err != nil {
+			providersLogger.Warn("local cache cleanup failed", "dir", cacheDir, "error", err)
+		}
+		for _, file := range removed {
+			providersLogger.Info("removed local cache file", "file", file.Name, "reason", file.Reason, "size_bytes", file.SizeBytes)
+		}
+		providersLogger.Info("using local file cache", "path", filepath.Join(cacheDir, modelcache.LocalCacheFileName()))
+		return local, nil
 	}
 	return nil, fmt.Errorf("cache.model: must have either local or redis configured")
 }
@@ -211,11 +234,38 @@ func initializeProviders(ctx context.Context, providerMap map[string]ProviderCon
 	sort.Strings(names)
 
 	var count int
+	var eager, lazy []string
 	for _, name := range names {
 		pCfg := providerMap[name]
-		p, err := factory.Create(pCfg)
+		hooks := llmclient.Hooks{
+			OnRateLimits: func(_ context.Context, limits core.RateLimits) {
+				registry.RecordRateLimits(name, limits)
+			},
+			OnCircuitChange: func(info llmclient.CircuitInfo) {
+				registry.RecordCircuitState(name, info.Open, info.RetryAt)
+			},
+		}
+		if pCfg.Lazy {
+			registry.RegisterLazyProvider(name, pCfg.Type, func() (core.Provider, error) {
+				p, opts, err := factory.create(pCfg, hooks)
+				if err != nil {
+					return nil, err
+				}
+				registry.SetProviderPacer(name, opts.Pacer)
+				registry.SetProviderScheduler(name, opts.Scheduler)
+				return p, nil
+			})
+			registry.SetProviderDataResidency(name, pCfg.DataResidency)
+			registry.SetProviderAllowUnlistedModels(name, pCfg.AllowUnlistedModels)
+			count++
+			lazy = append(lazy, name)
+			providersLogger.Info("provider registered for lazy initialization", "name", name, "type", pCfg.Type)
+			continue
+		}
+
+		p, opts, err := factory.create(pCfg, hooks)
 		if err != nil {
-			slog.Error("failed to initialize provider",
+			providersLogger.Error("failed to initialize provider",
 				"name", name,
 				"type", pCfg.Type,
 				"error", err)
@@ -228,7 +278,7 @@ func initializeProviders(ctx context.Context, providerMap map[string]ProviderCon
 			probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			if err := checker.CheckAvailability(probeCtx); err != nil {
 				registry.RecordAvailabilityCheck(name, err)
-				slog.Warn("provider unavailable at startup; keeping registered for refresh",
+				providersLogger.Warn("provider unavailable at startup; keeping registered for refresh",
 					"name", name,
 					"type", pCfg.Type,
 					"reason", err.Error())
@@ -239,9 +289,17 @@ func initializeProviders(ctx context.Context, providerMap map[string]ProviderCon
 		}
 
 		registry.RegisterProviderWithNameAndType(p, name, pCfg.Type)
+		registry.SetProviderDataResidency(name, pCfg.DataResidency)
+		registry.SetProviderAllowUnlistedModels(name, pCfg.AllowUnlistedModels)
+		registry.SetProviderPacer(name, opts.Pacer)
+		registry.SetProviderScheduler(name, opts.Scheduler)
 		count++
-		slog.Info("provider registered", "name", name, "type", pCfg.Type)
+		eager = append(eager, name)
+		providersLogger.Info("provider registered", "name", name, "type", pCfg.Type)
 	}
 
+	if len(lazy) > 0 {
+		providersLogger.Info("providers registered", "eager", eager, "lazy", lazy)
+	}
 	return count, nil
 }
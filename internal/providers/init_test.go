@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -174,6 +175,30 @@ func TestInit_AllowsStartupWhenProviderIsUnavailable(t *testing.T) {
 	}
 }
 
+func TestInit_RejectsMalformedBaseURL(t *testing.T) {
+	factory := NewProviderFactory()
+	factory.Add(Registration{
+		Type: "test",
+		New: func(ProviderConfig, ProviderOptions) core.Provider {
+			return &initTestProvider{}
+		},
+	})
+
+	_, err := Init(t.Context(), &config.LoadResult{
+		Config: &config.Config{},
+		RawProviders: map[string]config.RawProviderConfig{
+			"test": {
+				Type:    "test",
+				APIKey:  "sk-test",
+				BaseURL: "https://proxy.internal/v1?tenant=acme",
+			},
+		},
+	}, factory)
+	if err == nil || !strings.Contains(err.Error(), `invalid base_url for provider "test": must not have a query string`) {
+		t.Fatalf("Init() error = %v, want a base_url query string error", err)
+	}
+}
+
 func TestInit_NormalizesNilContext(t *testing.T) {
 	nilInitContext := func() context.Context {
 		return nil
@@ -249,12 +274,16 @@ func TestInit_NormalizesNilContext(t *testing.T) {
 		t.Fatal("expected Init(nil, ...) to fetch the model list without a nil-context panic")
 	}
 
-	cacheFile := filepath.Join(cacheDir, "models.json")
+	cacheFile := filepath.Join(cacheDir, modelcache.LocalCacheFileName())
+	inProgress := func() bool {
+		tmpFiles, _ := filepath.Glob(cacheFile + ".*.tmp")
+		return len(tmpFiles) > 0
+	}
 	deadline := time.Now().Add(2 * time.Second)
 	for time.Now().Before(deadline) {
 		if result.Registry.IsInitialized() {
 			if _, err := os.Stat(cacheFile); err == nil {
-				if _, err := os.Stat(cacheFile + ".tmp"); os.IsNotExist(err) {
+				if !inProgress() {
 					return
 				}
 			}
@@ -268,8 +297,8 @@ func TestInit_NormalizesNilContext(t *testing.T) {
 	if _, err := os.Stat(cacheFile); err != nil {
 		t.Fatalf("expected Init(nil, ...) to persist the cache file, stat error = %v", err)
 	}
-	if _, err := os.Stat(cacheFile + ".tmp"); !os.IsNotExist(err) {
-		t.Fatalf("expected no in-progress cache temp file, stat error = %v", err)
+	if inProgress() {
+		t.Fatal("expected no in-progress cache temp file")
 	}
 }
 
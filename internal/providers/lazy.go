@@ -0,0 +1,377 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// Provider initialization states reported for providers configured with
+// lazy: true.
+const (
+	ProviderInitUninitialized = "uninitialized"
+	ProviderInitInitialized   = "initialized"
+	ProviderInitFailed        = "init_failed"
+)
+
+const (
+	// lazyInitRetryInterval is how long a failed construction is reported
+	// to routed requests before the next request tries again.
+	lazyInitRetryInterval = 30 * time.Second
+	// lazyModelFetchTimeout bounds the model fetch that follows a
+	// construction, independent of the request that triggered it.
+	lazyModelFetchTimeout = 30 * time.Second
+)
+
+// lazyProvider stands in the registry for a provider configured with
+// lazy: true until its first routed request or admin refresh constructs the
+// real provider. Construction runs once; concurrent callers wait for it and
+// for the models of the constructed provider. Once constructed, the registry
+// swaps the real provider in, so the placeholder only serves requests routed
+// to it before the swap, such as models loaded from the cache.
+type lazyProvider struct {
+	name         string
+	providerType string
+	construct    func() (core.Provider, error)
+	registry     *ModelRegistry
+
+	mu       sync.Mutex
+	provider core.Provider
+	// ready is closed once the constructed provider's models are fetched.
+	ready    chan struct{}
+	err      error
+	failedAt time.Time
+}
+
+// ensure returns the real provider, constructing and activating it on the
+// first call. A failed construction is returned to callers without a retry
+// for lazyInitRetryInterval.
+//
+// The model fetch runs after l.mu is released: it waits for the registry's
+// refresh slot, and a refresh holding that slot lists the placeholder's
+// models, which takes l.mu.
+func (l *lazyProvider) ensure(ctx context.Context) (core.Provider, error) {
+	l.mu.Lock()
+	if l.provider != nil {
+		provider, ready := l.provider, l.ready
+		l.mu.Unlock()
+		select {
+		case <-ready:
+		case <-ctx.Done():
+		}
+		return provider, nil
+	}
+	if l.err != nil && time.Since(l.failedAt) < lazyInitRetryInterval {
+		err := l.err
+		l.mu.Unlock()
+		return nil, err
+	}
+
+	started := time.Now()
+	provider, err := l.construct()
+	if err != nil {
+		l.failedAt = time.Now()
+		l.err = core.NewProviderError(l.providerType, http.StatusServiceUnavailable,
+			fmt.Sprintf("provider %s failed to initialize", l.name), err)
+		wrapped := l.err
+		l.mu.Unlock()
+		l.registry.recordLazyInit(l.name, err)
+		providersLogger.Error("lazy provider initialization failed",
+			"name", l.name,
+			"type", l.providerType,
+			"error", err,
+		)
+		return nil, wrapped
+	}
+	ready := make(chan struct{})
+	l.provider, l.ready, l.err = provider, ready, nil
+	l.registry.activateLazyProvider(l, provider)
+	l.mu.Unlock()
+
+	l.registry.fetchLazyProviderModels(ctx, l.name, provider)
+	close(ready)
+	providersLogger.Info("lazy provider initialized",
+		"name", l.name,
+		"type", l.providerType,
+		"duration", time.Since(started).Round(time.Millisecond),
+	)
+	return provider, nil
+}
+
+func (l *lazyProvider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	provider, err := l.ensure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.ChatCompletion(ctx, req)
+}
+
+func (l *lazyProvider) StreamChatCompletion(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
+	provider, err := l.ensure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.StreamChatCompletion(ctx, req)
+}
+
+// ListModels reports no models before construction, so registry refreshes
+// never construct a lazy provider.
+func (l *lazyProvider) ListModels(ctx context.Context) (*core.ModelsResponse, error) {
+	l.mu.Lock()
+	provider := l.provider
+	l.mu.Unlock()
+	if provider == nil {
+		return &core.ModelsResponse{Object: "list", Data: []core.Model{}}, nil
+	}
+	return provider.ListModels(ctx)
+}
+
+func (l *lazyProvider) Responses(ctx context.Context, req *core.ResponsesRequest) (*core.ResponsesResponse, error) {
+	provider, err := l.ensure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Responses(ctx, req)
+}
+
+func (l *lazyProvider) StreamResponses(ctx context.Context, req *core.ResponsesRequest) (io.ReadCloser, error) {
+	provider, err := l.ensure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.StreamResponses(ctx, req)
+}
+
+func (l *lazyProvider) Embeddings(ctx context.Context, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	provider, err := l.ensure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Embeddings(ctx, req)
+}
+
+// RegisterLazyProvider registers a placeholder for a provider that construct
+// builds on first use: the first request routed to it, a request for a
+// "provider/model" selector naming it or its type, or
+// InitializeAllLazyProviders. Until then the provider contributes no models
+// beyond those loaded from the cache and registry refreshes skip it.
+func (r *ModelRegistry) RegisterLazyProvider(providerName, providerType string, construct func() (core.Provider, error)) {
+	providerName = strings.TrimSpace(providerName)
+	placeholder := &lazyProvider{
+		name:         providerName,
+		providerType: providerType,
+		construct:    construct,
+		registry:     r,
+	}
+	r.RegisterProviderWithNameAndType(placeholder, providerName, providerType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lazyProviders = append(r.lazyProviders, placeholder)
+	state := r.providerRuntime[providerName]
+	state.lazy = true
+	state.initState = ProviderInitUninitialized
+	r.providerRuntime[providerName] = state
+}
+
+// InitializeLazyProviders constructs the lazy providers named by the
+// provider segment of a "provider/model" selector, by name or by type. Bare
+// models construct none, so requests for unknown models cannot construct
+// every lazy provider. It reports whether any of them is available, so
+// callers can retry routing model; a provider constructed by a concurrent
+// call counts once its models are registered.
+func (r *ModelRegistry) InitializeLazyProviders(ctx context.Context, model string) bool {
+	return r.initializeLazyProviders(ctx, r.lazyProvidersFor(model))
+}
+
+// InitializeAllLazyProviders constructs every lazy provider, as an admin
+// runtime refresh does. It reports whether any of them is available.
+func (r *ModelRegistry) InitializeAllLazyProviders(ctx context.Context) bool {
+	r.mu.RLock()
+	placeholders := slices.Clone(r.lazyProviders)
+	r.mu.RUnlock()
+	return r.initializeLazyProviders(ctx, placeholders)
+}
+
+func (r *ModelRegistry) initializeLazyProviders(ctx context.Context, placeholders []*lazyProvider) bool {
+	available := false
+	for _, placeholder := range placeholders {
+		if _, err := placeholder.ensure(ctx); err == nil {
+			available = true
+		}
+	}
+	return available
+}
+
+// lazyProvidersFor returns the placeholders InitializeLazyProviders
+// constructs for model.
+func (r *ModelRegistry) lazyProvidersFor(model string) []*lazyProvider {
+	providerSegment, _ := splitModelSelector(strings.TrimSpace(model))
+	if providerSegment == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matching []*lazyProvider
+	for _, placeholder := range r.lazyProviders {
+		if placeholder.name == providerSegment || strings.TrimSpace(placeholder.providerType) == providerSegment {
+			matching = append(matching, placeholder)
+		}
+	}
+	return matching
+}
+
+// activateLazyProvider swaps a constructed provider in for its placeholder.
+func (r *ModelRegistry) activateLazyProvider(placeholder *lazyProvider, provider core.Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, registered := range r.providers {
+		if registered == placeholder {
+			r.providers[i] = provider
+		}
+	}
+	r.providerTypes[provider] = r.providerTypes[placeholder]
+	r.providerNames[provider] = r.providerNames[placeholder]
+	delete(r.providerTypes, placeholder)
+	delete(r.providerNames, placeholder)
+	for modelID, info := range r.modelsByProvider[placeholder.name] {
+		swapped := *info
+		swapped.Provider = provider
+		r.modelsByProvider[placeholder.name][modelID] = &swapped
+		if r.models[modelID] == info {
+			r.models[modelID] = &swapped
+		}
+	}
+	state := r.providerRuntime[placeholder.name]
+	state.initState = ProviderInitInitialized
+	state.initError = ""
+	state.lastInitAt = time.Now().UTC()
+	r.providerRuntime[placeholder.name] = state
+	r.publishSnapshotLocked()
+}
+
+// fetchLazyProviderModels registers the models of a constructed lazy
+// provider. Registry refreshes replace the model maps wholesale; the fetch
+// serializes with them so its models are not overwritten by an older
+// listing.
+func (r *ModelRegistry) fetchLazyProviderModels(ctx context.Context, providerName string, provider core.Provider) {
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lazyModelFetchTimeout)
+	defer cancel()
+	release, err := r.acquireRefresh(fetchCtx)
+	if err != nil {
+		providersLogger.Warn("lazy provider models not fetched", "name", providerName, "error", err)
+		return
+	}
+	defer release()
+	r.fetchProviderModels(fetchCtx, providerName, provider)
+}
+
+// fetchProviderModels replaces the models of one provider with its current
+// listing, keeping unqualified model IDs claimed by other providers.
+func (r *ModelRegistry) fetchProviderModels(ctx context.Context, providerName string, provider core.Provider) {
+	resp, err := provider.ListModels(ctx)
+	fetchAt := time.Now().UTC()
+	update := providerRuntimeState{registered: true, lastModelFetchAt: fetchAt}
+	switch {
+	case err != nil:
+		update.lastModelFetchError = err.Error()
+	case resp == nil || len(resp.Data) == 0:
+		update.lastModelFetchError = "provider returned empty model list"
+	default:
+		update.lastModelFetchSuccessAt = fetchAt
+	}
+	if update.lastModelFetchError != "" {
+		registryLogger.Warn("failed to fetch models from provider", "provider", providerName, "error", update.lastModelFetchError)
+		r.applyProviderRuntimeUpdates(map[string]providerRuntimeState{providerName: update})
+		return
+	}
+
+	r.mu.RLock()
+	providerTypes := maps.Clone(r.providerTypes)
+	learned := r.learnedModelsLocked()
+	list := r.modelList
+	customCategories := r.customCategories
+	r.mu.RUnlock()
+
+	fetched := make(map[string]map[string]*ModelInfo, 1)
+	fetched[providerName] = make(map[string]*ModelInfo, len(resp.Data))
+	for _, model := range resp.Data {
+		fetched[providerName][model.ID] = &ModelInfo{
+			Model:        model,
+			Provider:     provider,
+			ProviderName: providerName,
+			ProviderType: providerTypes[provider],
+		}
+	}
+	for modelID, model := range learned[providerName] {
+		if _, exists := fetched[providerName][modelID]; !exists {
+			fetched[providerName][modelID] = &ModelInfo{Model: model, Provider: provider, ProviderName: providerName, ProviderType: providerTypes[provider]}
+		}
+	}
+	if list != nil {
+		enrichProviderModelMaps(list, providerTypes, fetched, nil)
+	}
+	applyCustomCategories(customCategories, fetched, nil)
+
+	r.mu.Lock()
+	for modelID, info := range r.models {
+		if info.ProviderName == providerName {
+			delete(r.models, modelID)
+		}
+	}
+	delete(r.modelsByProvider, providerName)
+	for _, info := range fetched[providerName] {
+		addModelInfo(r.models, r.modelsByProvider, info)
+	}
+	if r.modelsByProvider[providerName] == nil {
+		r.modelsByProvider[providerName] = make(map[string]*ModelInfo)
+	}
+	r.applyProviderRuntimeUpdatesLocked(map[string]providerRuntimeState{providerName: update})
+	r.publishSnapshotLocked()
+	hooks := slices.Clone(r.refreshHooks)
+	r.mu.Unlock()
+
+	registryLogger.Info("provider models registered", "provider", providerName, "models", len(fetched[providerName]))
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// recordLazyInit stores a failed lazy provider construction for the
+// provider status.
+func (r *ModelRegistry) recordLazyInit(providerName string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.providerRuntime[providerName]
+	state.initState = ProviderInitFailed
+	state.initError = err.Error()
+	state.lastInitAt = time.Now().UTC()
+	r.providerRuntime[providerName] = state
+}
+
+// InitializeLazyProviders constructs the lazy providers that may serve
+// model, when the lookup has any. See ModelRegistry.InitializeLazyProviders.
+func (r *Router) InitializeLazyProviders(ctx context.Context, model string) bool {
+	lazy, ok := r.lookup.(core.LazyProviderInitializer)
+	return ok && lazy.InitializeLazyProviders(ctx, model)
+}
+
+// activateLazy returns the constructed provider for a lazy placeholder that
+// provider-typed routes resolved to, so optional interfaces such as native
+// batches see the real provider.
+func activateLazy(ctx context.Context, provider core.Provider) (core.Provider, error) {
+	placeholder, ok := provider.(*lazyProvider)
+	if !ok {
+		return provider, nil
+	}
+	return placeholder.ensure(ctx)
+}
@@ -0,0 +1,224 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func newLazyTestRegistry(t *testing.T, construct func() (core.Provider, error)) *ModelRegistry {
+	t.Helper()
+	registry := NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(&registryMockProvider{
+		name: "openai",
+		modelsResponse: &core.ModelsResponse{Object: "list", Data: []core.Model{
+			{ID: "gpt-4o", Object: "model", OwnedBy: "openai"},
+		}},
+	}, "openai", "openai")
+	registry.RegisterLazyProvider("anthropic", "anthropic", construct)
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return registry
+}
+
+// lazyChatProvider returns a fresh response per call, as the router stamps
+// the provider onto the response concurrent requests receive.
+type lazyChatProvider struct {
+	*registryMockProvider
+}
+
+func (p lazyChatProvider) ChatCompletion(context.Context, *core.ChatRequest) (*core.ChatResponse, error) {
+	return &core.ChatResponse{ID: "resp-lazy", Model: "claude-sonnet-4"}, nil
+}
+
+func lazyAnthropicProvider() core.Provider {
+	return lazyChatProvider{&registryMockProvider{
+		name: "anthropic",
+		modelsResponse: &core.ModelsResponse{Object: "list", Data: []core.Model{
+			{ID: "claude-sonnet-4", Object: "model", OwnedBy: "anthropic"},
+		}},
+	}}
+}
+
+func lazyInitState(t *testing.T, registry *ModelRegistry, name string) ProviderRuntimeSnapshot {
+	t.Helper()
+	for _, snapshot := range registry.ProviderRuntimeSnapshots() {
+		if snapshot.Name == name {
+			return snapshot
+		}
+	}
+	t.Fatalf("no runtime snapshot for provider %q", name)
+	return ProviderRuntimeSnapshot{}
+}
+
+func TestLazyProvider_ConcurrentFirstRequestsConstructOnce(t *testing.T) {
+	var constructions atomic.Int32
+	registry := newLazyTestRegistry(t, func() (core.Provider, error) {
+		constructions.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return lazyAnthropicProvider(), nil
+	})
+	if registry.Supports("anthropic/claude-sonnet-4") {
+		t.Fatal("lazy provider models are registered before construction")
+	}
+	if got := lazyInitState(t, registry, "anthropic").Initialization; got != ProviderInitUninitialized {
+		t.Fatalf("initialization = %q, want %q", got, ProviderInitUninitialized)
+	}
+	router, err := NewRouter(registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "anthropic/claude-sonnet-4"})
+			if err == nil && resp.ID != "resp-lazy" {
+				err = errors.New("unexpected response " + resp.ID)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ChatCompletion() error = %v", err)
+		}
+	}
+
+	if got := constructions.Load(); got != 1 {
+		t.Fatalf("constructions = %d, want 1", got)
+	}
+	if !registry.Supports("anthropic/claude-sonnet-4") {
+		t.Fatal("lazy provider models are not registered after construction")
+	}
+	if _, ok := registry.GetProvider("anthropic/claude-sonnet-4").(*lazyProvider); ok {
+		t.Fatal("placeholder was not swapped for the constructed provider")
+	}
+	if got := lazyInitState(t, registry, "anthropic").Initialization; got != ProviderInitInitialized {
+		t.Fatalf("initialization = %q, want %q", got, ProviderInitInitialized)
+	}
+}
+
+func TestLazyProvider_ModelFetchDoesNotBlockRefreshListing(t *testing.T) {
+	constructed := make(chan struct{})
+	registry := newLazyTestRegistry(t, func() (core.Provider, error) {
+		close(constructed)
+		return lazyAnthropicProvider(), nil
+	})
+	placeholder := registry.lazyProviders[0]
+
+	// A refresh holds the refresh slot while it lists the placeholder.
+	release, err := registry.acquireRefresh(context.Background())
+	if err != nil {
+		t.Fatalf("acquireRefresh() error = %v", err)
+	}
+	ensured := make(chan error, 1)
+	go func() {
+		_, err := placeholder.ensure(context.Background())
+		ensured <- err
+	}()
+	<-constructed
+
+	listed := make(chan struct{})
+	go func() {
+		_, _ = placeholder.ListModels(context.Background())
+		close(listed)
+	}()
+	select {
+	case <-listed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListModels() blocked on a construction waiting for the refresh slot")
+	}
+	release()
+
+	if err := <-ensured; err != nil {
+		t.Fatalf("ensure() error = %v", err)
+	}
+	if !registry.Supports("anthropic/claude-sonnet-4") {
+		t.Fatal("lazy provider models are not registered after the refresh slot was released")
+	}
+}
+
+func TestLazyProvider_CachesConstructionError(t *testing.T) {
+	var constructions atomic.Int32
+	registry := newLazyTestRegistry(t, func() (core.Provider, error) {
+		constructions.Add(1)
+		return nil, errors.New("missing api key")
+	})
+	router, err := NewRouter(registry)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	for range 3 {
+		if _, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "anthropic/claude-sonnet-4"}); err == nil {
+			t.Fatal("ChatCompletion() succeeded through a provider that failed to initialize")
+		}
+	}
+	if got := constructions.Load(); got != 1 {
+		t.Fatalf("constructions = %d, want 1 within the retry interval", got)
+	}
+	state := lazyInitState(t, registry, "anthropic")
+	if state.Initialization != ProviderInitFailed || state.InitError != "missing api key" || state.LastInitAt == nil {
+		t.Fatalf("runtime = %+v, want a recorded init failure", state)
+	}
+}
+
+func TestLazyProvider_RefreshSkipsPlaceholders(t *testing.T) {
+	var constructions atomic.Int32
+	registry := newLazyTestRegistry(t, func() (core.Provider, error) {
+		constructions.Add(1)
+		return lazyAnthropicProvider(), nil
+	})
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := constructions.Load(); got != 0 {
+		t.Fatalf("constructions = %d, want 0 after a background refresh", got)
+	}
+	if !registry.InitializeAllLazyProviders(context.Background()) {
+		t.Fatal("InitializeAllLazyProviders() = false, want true")
+	}
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := constructions.Load(); got != 1 {
+		t.Fatalf("constructions = %d, want 1", got)
+	}
+	if !registry.Supports("anthropic/claude-sonnet-4") {
+		t.Fatal("constructed provider models were dropped by the refresh")
+	}
+}
+
+func TestModelRegistry_LazyProvidersFor(t *testing.T) {
+	registry := newLazyTestRegistry(t, func() (core.Provider, error) {
+		return lazyAnthropicProvider(), nil
+	})
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{model: "anthropic/claude-sonnet-4", want: 1},
+		{model: "openai/gpt-5", want: 0},
+		{model: "claude-sonnet-4", want: 0},
+		{model: "unknown-model", want: 0},
+		{model: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := len(registry.lazyProvidersFor(tt.model)); got != tt.want {
+			t.Errorf("lazyProvidersFor(%q) = %d providers, want %d", tt.model, got, tt.want)
+		}
+	}
+}
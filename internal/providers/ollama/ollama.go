@@ -47,6 +47,11 @@ func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) c
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.client = llmclient.New(clientCfg, p.setHeaders)
 
@@ -56,9 +61,16 @@ func New(providerCfg providers.ProviderConfig, opts providers.ProviderOptions) c
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.nativeClient = llmclient.New(nativeCfg, p.setHeaders)
-	p.SetBaseURL(providers.ResolveBaseURL(providerCfg.BaseURL, defaultBaseURL))
+	// Ollama is usually addressed by its host alone, as in OLLAMA_HOST; its
+	// OpenAI-compatible API lives under /v1.
+	p.SetBaseURL(providers.WithDefaultPath(providers.ResolveBaseURL(providerCfg.BaseURL, defaultBaseURL), "/v1"))
 	return p
 }
 
@@ -12,6 +12,7 @@ import (
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestNew(t *testing.T) {
@@ -590,6 +591,72 @@ func TestResponsesWithArrayInput(t *testing.T) {
 	}
 }
 
+func TestResponses_MultiTurnToolExchange(t *testing.T) {
+	var upstream struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&upstream); err != nil {
+			t.Fatalf("failed to decode upstream request: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-456",
+			"object": "chat.completion",
+			"created": 1677652288,
+			"model": "llama3.2",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Rain is likely."}, "finish_reason": "stop"}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("", nil, llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+
+	var req core.ResponsesRequest
+	if err := json.Unmarshal([]byte(`{"model": "llama3.2", "input": [
+				{"role": "user", "content": [{"type": "input_text", "text": "What's the weather in Paris?"}]},
+				{"type": "reasoning", "id": "rs_1", "summary": []},
+				{"type": "function_call", "id": "fc_1", "call_id": "call_weather", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}", "status": "completed"},
+				{"type": "function_call_output", "id": "fco_1", "call_id": "call_weather", "output": "{\"temp_c\":18}"},
+				{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed", "content": [{"type": "output_text", "text": "It is 18C.", "annotations": []}]},
+				{"role": "user", "content": "And tomorrow?"}
+			]}`), &req); err != nil {
+		t.Fatalf("failed to decode responses request: %v", err)
+	}
+	if _, err := provider.Responses(context.Background(), &req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := upstream.Messages
+	if len(msgs) != 5 {
+		t.Fatalf("len(messages) = %d, want 5: %v", len(msgs), msgs)
+	}
+	for i, role := range []string{"user", "assistant", "tool", "assistant", "user"} {
+		if msgs[i]["role"] != role {
+			t.Fatalf("messages[%d].role = %v, want %s", i, msgs[i]["role"], role)
+		}
+	}
+	toolCalls, _ := msgs[1]["tool_calls"].([]any)
+	if len(toolCalls) != 1 {
+		t.Fatalf("assistant tool_calls = %v, want one call", msgs[1]["tool_calls"])
+	}
+	call, _ := toolCalls[0].(map[string]any)
+	function, _ := call["function"].(map[string]any)
+	if call["id"] != "call_weather" || function["name"] != "get_weather" || function["arguments"] != `{"city":"Paris"}` {
+		t.Errorf("tool call = %v", call)
+	}
+	if msgs[2]["tool_call_id"] != "call_weather" || msgs[2]["content"] != `{"temp_c":18}` {
+		t.Errorf("tool result = %v", msgs[2])
+	}
+	if msgs[3]["content"] != "It is 18C." {
+		t.Errorf("assistant reply = %v", msgs[3])
+	}
+	if _, ok := msgs[3]["id"]; ok {
+		t.Errorf("assistant message leaks Responses item id: %v", msgs[3])
+	}
+}
+
 func TestStreamResponses(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify stream is set in request body
@@ -873,3 +940,48 @@ func TestEmbeddings_ModelFallback(t *testing.T) {
 		t.Errorf("Model = %q, want %q (should fall back to request model)", resp.Model, "nomic-embed-text")
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "ollama",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "llama3.2")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+// A bare host gets the /v1 of the OpenAI-compatible API; native requests go
+// to the root.
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "ollama",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "llama3.2",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/ollama/v1", ChatPath: "/proxy/ollama/v1/chat/completions", Prefix: "/proxy/ollama/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/ollama/v1/", ChatPath: "/proxy/ollama/v1/chat/completions", Prefix: "/proxy/ollama/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/v1/chat/completions", Prefix: "/"},
+	)
+}
+
+func TestConversionProperties(t *testing.T) {
+	testfixtures.CheckConversionProperties(t, testfixtures.ChatAdapterSuite(providers.ConvertResponsesRequestToChat, providers.ConvertChatResponseToResponses))
+}
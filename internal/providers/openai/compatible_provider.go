@@ -19,6 +19,10 @@ type CompatibleProviderConfig struct {
 	BaseURL        string
 	SetHeaders     func(*http.Request, string)
 	RequestMutator RequestMutator
+	// Quirks are the serialization quirks of providers built with
+	// NewCompatibleProviderWithHTTPClient. NewCompatibleProvider takes them
+	// from ProviderOptions, where config overrides are applied.
+	Quirks llmclient.Quirks
 }
 
 type CompatibleProvider struct {
@@ -40,6 +44,12 @@ func NewCompatibleProvider(apiKey string, opts providers.ProviderOptions, cfg Co
 		Retry:          opts.Resilience.Retry,
 		Hooks:          opts.Hooks,
 		CircuitBreaker: opts.Resilience.CircuitBreaker,
+		Signing:        opts.Signing,
+		AcceptEncoding: opts.AcceptEncoding,
+		HTTPClient:     opts.HTTPClient,
+		Pacer:          opts.Pacer,
+		Scheduler:      opts.Scheduler,
+		Quirks:         opts.Quirks,
 	}
 	p.client = llmclient.New(clientCfg, func(req *http.Request) {
 		if cfg.SetHeaders != nil {
@@ -60,6 +70,7 @@ func NewCompatibleProviderWithHTTPClient(apiKey string, httpClient *http.Client,
 	}
 	clientCfg := llmclient.DefaultConfig(cfg.ProviderName, cfg.BaseURL)
 	clientCfg.Hooks = hooks
+	clientCfg.Quirks = cfg.Quirks
 	p.client = llmclient.NewWithHTTPClient(httpClient, clientCfg, func(req *http.Request) {
 		if cfg.SetHeaders != nil {
 			cfg.SetHeaders(req, apiKey)
@@ -92,14 +103,10 @@ func (p *CompatibleProvider) ChatCompletion(ctx context.Context, req *core.ChatR
 		return nil, core.NewInvalidRequestError("chat request is required", nil)
 	}
 	var resp core.ChatResponse
-	body, err := chatRequestBody(req)
-	if err != nil {
-		return nil, err
-	}
-	err = p.Do(ctx, llmclient.Request{
+	err := p.Do(ctx, llmclient.Request{
 		Method:   http.MethodPost,
 		Endpoint: "/chat/completions",
-		Body:     body,
+		Body:     req,
 	}, &resp)
 	if err != nil {
 		return nil, err
@@ -114,15 +121,10 @@ func (p *CompatibleProvider) StreamChatCompletion(ctx context.Context, req *core
 	if req == nil {
 		return nil, core.NewInvalidRequestError("chat request is required", nil)
 	}
-	streamReq := req.WithStreaming()
-	body, err := chatRequestBody(streamReq)
-	if err != nil {
-		return nil, err
-	}
 	return p.client.DoStream(ctx, p.prepareRequest(llmclient.Request{
 		Method:   http.MethodPost,
 		Endpoint: "/chat/completions",
-		Body:     body,
+		Body:     req.WithStreaming(),
 	}))
 }
 
@@ -2,10 +2,10 @@
 package openai
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
-	"strings"
 
+	"gomodel/config"
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
@@ -19,6 +19,7 @@ var Registration = providers.Registration{
 	Discovery: providers.DiscoveryConfig{
 		DefaultBaseURL: defaultBaseURL,
 	},
+	Quirks: Quirks,
 }
 
 const (
@@ -50,10 +51,17 @@ func NewWithHTTPClient(apiKey string, httpClient *http.Client, hooks llmclient.H
 			ProviderName: "openai",
 			BaseURL:      defaultBaseURL,
 			SetHeaders:   setHeaders,
+			Quirks:       Quirks,
 		}),
 	}
 }
 
+// FetchRateLimits lists models and returns the account limits reported in the
+// response's x-ratelimit-* headers.
+func (p *Provider) FetchRateLimits(ctx context.Context) (core.RateLimits, error) {
+	return p.client.FetchRateLimits(ctx, "/models")
+}
+
 // setHeaders sets the required headers for OpenAI API requests
 func setHeaders(req *http.Request, apiKey string) {
 	req.Header.Set("Authorization", "Bearer "+apiKey)
@@ -79,53 +87,14 @@ func isValidClientRequestID(id string) bool {
 	return true
 }
 
-// isOSeriesModel reports whether the model is an OpenAI o-series model
-// (o1, o3, o4) that requires max_completion_tokens instead of max_tokens
-// and does not support the temperature parameter.
-func isOSeriesModel(model string) bool {
-	m := strings.ToLower(model)
-	// Match o1, o3, o4 families (e.g. o3-mini, o4-mini, o3, o1-preview).
-	// Non-reasoning models like gpt-4o start with "gpt-", not "o".
-	return len(m) >= 2 && m[0] == 'o' && m[1] >= '0' && m[1] <= '9'
-}
-
-func isGPT5Model(model string) bool {
-	m := strings.ToLower(strings.TrimSpace(model))
-	return m == "gpt-5" || strings.HasPrefix(m, "gpt-5-")
-}
-
-// isReasoningChatModel reports whether the model follows OpenAI's reasoning
-// chat parameter rules for max_completion_tokens and temperature handling.
-func isReasoningChatModel(model string) bool {
-	return isOSeriesModel(model) || isGPT5Model(model)
-}
-
-// adaptForReasoningChat rewrites a ChatRequest body for OpenAI reasoning chat
-// models, mapping max_tokens -> max_completion_tokens and dropping temperature
-// while preserving all unknown top-level JSON fields.
-func adaptForReasoningChat(req *core.ChatRequest) (any, error) {
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, core.NewInvalidRequestError("failed to marshal reasoning request: "+err.Error(), err)
-	}
-
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(body, &raw); err != nil {
-		return nil, core.NewInvalidRequestError("failed to decode reasoning request payload: "+err.Error(), err)
-	}
-	if maxTokens, ok := raw["max_tokens"]; ok {
-		raw["max_completion_tokens"] = maxTokens
-		delete(raw, "max_tokens")
-	}
-	delete(raw, "temperature")
-	return raw, nil
-}
-
-// chatRequestBody returns the appropriate request body for the model.
-// Reasoning models get parameter adaptation; others pass through as-is.
-func chatRequestBody(req *core.ChatRequest) (any, error) {
-	if isReasoningChatModel(req.Model) {
-		return adaptForReasoningChat(req)
-	}
-	return req, nil
-}
+// Quirks are the serialization rules of OpenAI's chat API. OpenAI's reasoning
+// models (o1, o3, o4 and gpt-5) take max_completion_tokens instead of
+// max_tokens and reject temperature. The OpenAI-compatible providers that
+// serve OpenAI models share these rules.
+var Quirks = llmclient.Quirks{Rules: []config.QuirkRule{{
+	Name:      "reasoning_chat_params",
+	Endpoints: []string{"/chat/completions"},
+	Models:    []string{"o[0-9]*", "gpt-5", "gpt-5-*"},
+	Rename:    map[string]string{"max_tokens": "max_completion_tokens"},
+	Drop:      []string{"temperature"},
+}}}
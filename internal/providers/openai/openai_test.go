@@ -10,9 +10,11 @@ import (
 	"strings"
 	"testing"
 
+	"gomodel/config"
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestNew(t *testing.T) {
@@ -37,6 +39,36 @@ func TestNew_ReturnsProvider(t *testing.T) {
 	}
 }
 
+func TestNew_SignsRequestsWhenConfigured(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Internal-Signature")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"m","choices":[]}`))
+	}))
+	defer server.Close()
+
+	provider := New(providers.ProviderConfig{APIKey: "sk", BaseURL: server.URL}, providers.ProviderOptions{
+		Signing: &config.RequestSigningConfig{
+			Type:   config.RequestSigningHMACSHA256,
+			Header: "X-Internal-Signature",
+			Secret: "s3cret",
+		},
+	})
+	if _, err := provider.ChatCompletion(context.Background(), &core.ChatRequest{
+		Model:    "m",
+		Messages: []core.Message{{Role: "user", Content: "hi"}},
+	}); err != nil {
+		t.Fatalf("ChatCompletion() error: %v", err)
+	}
+
+	if want := llmclient.SignRequestBody("s3cret", "", body); signature == "" || signature != want {
+		t.Fatalf("signature = %q, want %q", signature, want)
+	}
+}
+
 func TestNilRequests_ReturnInvalidRequestError(t *testing.T) {
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 
@@ -1068,12 +1100,8 @@ func TestResponses(t *testing.T) {
 					t.Fatalf("len(Annotations) = %d, want 1", len(annotations))
 				}
 
-				var annotation map[string]any
-				if err := json.Unmarshal(annotations[0], &annotation); err != nil {
-					t.Fatalf("json.Unmarshal(annotation) error = %v", err)
-				}
-				if annotation["type"] != "url_citation" {
-					t.Fatalf("annotation.type = %#v, want url_citation", annotation["type"])
+				if annotations[0].Type != core.AnnotationTypeURLCitation || annotations[0].Title != "Example Domain" {
+					t.Fatalf("annotation = %+v, want url_citation titled Example Domain", annotations[0])
 				}
 			},
 		},
@@ -1516,7 +1544,7 @@ func TestResponsesWithContext(t *testing.T) {
 	}
 }
 
-func TestIsOSeriesModel(t *testing.T) {
+func TestQuirks_ReasoningChatModels(t *testing.T) {
 	tests := []struct {
 		model    string
 		expected bool
@@ -1528,44 +1556,34 @@ func TestIsOSeriesModel(t *testing.T) {
 		{"o1-preview", true},
 		{"o1-mini", true},
 		{"o3-mini-2025-01-31", true},
-		{"gpt-4o", false},
-		{"gpt-4o-mini", false},
-		{"gpt-4", false},
-		{"gpt-3.5-turbo", false},
-		{"claude-3-opus", false},
-		{"", false},
-		{"o", false},
-		{"openai", false},
-	}
-	for _, tt := range tests {
-		t.Run(tt.model, func(t *testing.T) {
-			if got := isOSeriesModel(tt.model); got != tt.expected {
-				t.Errorf("isOSeriesModel(%q) = %v, want %v", tt.model, got, tt.expected)
-			}
-		})
-	}
-}
-
-func TestIsReasoningChatModel(t *testing.T) {
-	tests := []struct {
-		model    string
-		expected bool
-	}{
-		{"o3-mini", true},
-		{"o4-mini", true},
+		{"O3-Mini", true},
 		{"gpt-5", true},
 		{"gpt-5-mini", true},
 		{"gpt-5-nano", true},
 		{"gpt-5-chat-latest", true},
 		{"gpt-4o", false},
+		{"gpt-4o-mini", false},
 		{"gpt-4.1", false},
+		{"gpt-4", false},
+		{"gpt-3.5-turbo", false},
+		{"gpt-50", false},
 		{"claude-sonnet-4-6", false},
 		{"", false},
+		{"o", false},
+		{"openai", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.model, func(t *testing.T) {
-			if got := isReasoningChatModel(tt.model); got != tt.expected {
-				t.Errorf("isReasoningChatModel(%q) = %v, want %v", tt.model, got, tt.expected)
+			body := []byte(`{"model":"` + tt.model + `","max_tokens":10,"temperature":0.5}`)
+			got, err := Quirks.Apply("/chat/completions", body)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if adapted := string(got) != string(body); adapted != tt.expected {
+				t.Errorf("Apply(%q) = %s, want adapted = %v", tt.model, got, tt.expected)
+			}
+			if got, _ := Quirks.Apply("/responses", body); string(got) != string(body) {
+				t.Errorf("Apply(%q) rewrote a Responses body: %s", tt.model, got)
 			}
 		})
 	}
@@ -2168,3 +2186,42 @@ func TestPassthrough(t *testing.T) {
 		t.Fatalf("response body = %q", string(body))
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "openai",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "gpt-4o")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "openai",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "gpt-4o",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/openai/v1", ChatPath: "/proxy/openai/v1/chat/completions", Prefix: "/proxy/openai/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/openai/v1/", ChatPath: "/proxy/openai/v1/chat/completions", Prefix: "/proxy/openai/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
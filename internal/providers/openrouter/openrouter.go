@@ -1,9 +1,11 @@
 package openrouter
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
@@ -24,6 +26,7 @@ var Registration = providers.Registration{
 	Discovery: providers.DiscoveryConfig{
 		DefaultBaseURL: defaultBaseURL,
 	},
+	Quirks: openai.Quirks,
 }
 
 type Provider struct {
@@ -56,6 +59,7 @@ func NewWithHTTPClient(apiKey string, httpClient *http.Client, hooks llmclient.H
 		ProviderName: "openrouter",
 		BaseURL:      defaultBaseURL,
 		SetHeaders:   setHeaders,
+		Quirks:       openai.Quirks,
 	})
 	p.SetRequestMutator(p.mutateRequest)
 	return p
@@ -75,6 +79,32 @@ func (p *Provider) mutateRequest(req *llmclient.Request) {
 	}
 }
 
+// keyResponse is the body of GET /key, which describes the API key the
+// request authenticates with.
+type keyResponse struct {
+	Data struct {
+		Label          string     `json:"label"`
+		Limit          *float64   `json:"limit"`
+		LimitRemaining *float64   `json:"limit_remaining"`
+		ExpiresAt      *time.Time `json:"expires_at"`
+	} `json:"data"`
+}
+
+// FetchKeyMetadata returns the label, credit limit and expiry of the API key.
+// Unlike the public model listing, the request fails for an invalid key.
+func (p *Provider) FetchKeyMetadata(ctx context.Context) (core.KeyMetadata, error) {
+	var resp keyResponse
+	if err := p.Do(ctx, llmclient.Request{Method: http.MethodGet, Endpoint: "/key"}, &resp); err != nil {
+		return core.KeyMetadata{}, err
+	}
+	return core.KeyMetadata{
+		Label:           resp.Data.Label,
+		ExpiresAt:       resp.Data.ExpiresAt,
+		CreditLimit:     resp.Data.Limit,
+		CreditRemaining: resp.Data.LimitRemaining,
+	}, nil
+}
+
 func setHeaders(req *http.Request, apiKey string) {
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	if requestID := core.GetRequestID(req.Context()); requestID != "" && isValidClientRequestID(requestID) {
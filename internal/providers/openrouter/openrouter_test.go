@@ -2,14 +2,18 @@ package openrouter
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
+	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestChatCompletion_AddsDefaultAttributionHeaders(t *testing.T) {
@@ -55,6 +59,47 @@ func TestChatCompletion_AddsDefaultAttributionHeaders(t *testing.T) {
 	}
 }
 
+func TestFetchKeyMetadata(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		if r.Header.Get("Authorization") != "Bearer test-api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"No auth credentials found","code":401}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"label":"sk-or-v1-abc...xyz","limit":10,"usage":9.5,"limit_remaining":0.5,"is_free_tier":false,"expires_at":"2026-12-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	provider := NewWithHTTPClient("test-api-key", server.Client(), llmclient.Hooks{})
+	provider.SetBaseURL(server.URL)
+
+	metadata, err := provider.FetchKeyMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("FetchKeyMetadata() error = %v", err)
+	}
+	if gotPath != "/key" || gotAuth != "Bearer test-api-key" {
+		t.Fatalf("request = %s with %q, want /key with the API key", gotPath, gotAuth)
+	}
+	if metadata.ExpiresAt == nil || !metadata.ExpiresAt.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ExpiresAt = %v, want 2026-12-01", metadata.ExpiresAt)
+	}
+	if metadata.CreditRemaining == nil || *metadata.CreditRemaining != 0.5 || metadata.CreditLimit == nil || *metadata.CreditLimit != 10 {
+		t.Fatalf("credits = %v/%v, want 0.5/10", metadata.CreditRemaining, metadata.CreditLimit)
+	}
+
+	invalid := NewWithHTTPClient("revoked-key", server.Client(), llmclient.Hooks{})
+	invalid.SetBaseURL(server.URL)
+	_, err = invalid.FetchKeyMetadata(context.Background())
+	gatewayErr, ok := errors.AsType[*core.GatewayError](err)
+	if !ok || gatewayErr.Type != core.ErrorTypeAuthentication {
+		t.Fatalf("FetchKeyMetadata() error = %v, want authentication error", err)
+	}
+}
+
 func TestChatCompletion_UsesEnvOverridesForAttributionHeaders(t *testing.T) {
 	t.Setenv("OPENROUTER_SITE_URL", "https://example.com")
 	t.Setenv("OPENROUTER_APP_NAME", "Example App")
@@ -139,3 +184,42 @@ func TestPassthrough_PreservesUserProvidedAttributionHeaders(t *testing.T) {
 		t.Fatalf("X-OpenRouter-Title = %q, want empty when caller provided X-Title", gotTitle)
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "openrouter",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "openai/gpt-4o")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "openrouter",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "openai/gpt-4o",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/openrouter/api/v1", ChatPath: "/proxy/openrouter/api/v1/chat/completions", Prefix: "/proxy/openrouter/api/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/openrouter/api/v1/", ChatPath: "/proxy/openrouter/api/v1/chat/completions", Prefix: "/proxy/openrouter/api/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
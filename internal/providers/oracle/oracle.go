@@ -21,6 +21,7 @@ var Registration = providers.Registration{
 	Discovery: providers.DiscoveryConfig{
 		RequireBaseURL: true,
 	},
+	Quirks: openai.Quirks,
 }
 
 type Provider struct {
@@ -46,6 +47,7 @@ func NewWithHTTPClient(apiKey string, httpClient *http.Client, hooks llmclient.H
 			ProviderName: "oracle",
 			BaseURL:      defaultBaseURL,
 			SetHeaders:   setHeaders,
+			Quirks:       openai.Quirks,
 		}),
 		configuredModels: normalizeConfiguredModels(models),
 	}
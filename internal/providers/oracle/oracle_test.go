@@ -10,6 +10,8 @@ import (
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
+	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestListModels_FallsBackToConfiguredModelsWhenUpstreamFails(t *testing.T) {
@@ -194,3 +196,42 @@ func TestNormalizeConfiguredModels_AllEmpty(t *testing.T) {
 		t.Fatalf("got = %v, want nil", got)
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "oracle",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "cohere.command-r")
+	testfixtures.RequireOnEveryRequest(t, server, 5, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "oracle",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "cohere.command-r",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/oracle/v1", ChatPath: "/proxy/oracle/v1/chat/completions", Prefix: "/proxy/oracle/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/oracle/v1/", ChatPath: "/proxy/oracle/v1/chat/completions", Prefix: "/proxy/oracle/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
@@ -1,9 +1,14 @@
 package providers
 
 import (
+	"net/url"
 	"sort"
 	"strings"
 	"time"
+
+	"gomodel/internal/core"
+	"gomodel/internal/fairshare"
+	"gomodel/internal/pacing"
 )
 
 // SanitizedRetryConfig exposes effective retry settings without secrets.
@@ -28,14 +33,35 @@ type SanitizedResilienceConfig struct {
 	CircuitBreaker SanitizedCircuitBreakerConfig `json:"circuit_breaker"`
 }
 
+// SanitizedSigningConfig exposes request signing settings without the secret.
+type SanitizedSigningConfig struct {
+	Type             string `json:"type"`
+	Header           string `json:"header"`
+	IncludeTimestamp bool   `json:"include_timestamp"`
+	TimestampHeader  string `json:"timestamp_header,omitempty"`
+}
+
 // SanitizedProviderConfig is the admin-safe provider configuration view.
 type SanitizedProviderConfig struct {
-	Name       string                    `json:"name"`
-	Type       string                    `json:"type"`
-	BaseURL    string                    `json:"base_url,omitempty"`
-	APIVersion string                    `json:"api_version,omitempty"`
-	Models     []string                  `json:"models,omitempty"`
-	Resilience SanitizedResilienceConfig `json:"resilience"`
+	Name                string                    `json:"name"`
+	Type                string                    `json:"type"`
+	BaseURL             string                    `json:"base_url,omitempty"`
+	APIVersion          string                    `json:"api_version,omitempty"`
+	Models              []string                  `json:"models,omitempty"`
+	Resilience          SanitizedResilienceConfig `json:"resilience"`
+	Signing             *SanitizedSigningConfig   `json:"signing,omitempty"`
+	AcceptEncoding      []string                  `json:"accept_encoding,omitempty"`
+	DataResidency       string                    `json:"data_residency,omitempty"`
+	AllowUnlistedModels bool                      `json:"allow_unlisted_models,omitempty"`
+	Lazy                bool                      `json:"lazy,omitempty"`
+	// ProxyURL is the provider's proxy with any password masked.
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// ExtraHeaders and ExtraQuery list the names of the extra headers and
+	// query parameters sent upstream. Their values may be secrets and are
+	// never exposed.
+	ExtraHeaders         []string `json:"extra_headers,omitempty"`
+	ExtraQuery           []string `json:"extra_query,omitempty"`
+	ExtraHeadersOverride bool     `json:"extra_headers_override,omitempty"`
 }
 
 // ProviderRuntimeSnapshot describes runtime diagnostics for a configured provider.
@@ -52,6 +78,34 @@ type ProviderRuntimeSnapshot struct {
 	LastAvailabilityCheckAt *time.Time `json:"last_availability_check_at,omitempty"`
 	LastAvailabilityOKAt    *time.Time `json:"last_availability_ok_at,omitempty"`
 	LastAvailabilityError   string     `json:"last_availability_error,omitempty"`
+	// RateLimits holds the most recent account limits the provider reported
+	// in rate-limit response headers. Nil until one was observed.
+	RateLimits *core.RateLimits `json:"rate_limits,omitempty"`
+	// Capabilities is the profile of the provider's last capability probe.
+	// Nil until one ran.
+	Capabilities *core.CapabilityProfile `json:"capabilities,omitempty"`
+	// KeyHealth is the state of the provider's API key from the periodic key
+	// health checks. Nil while they are disabled or have not run.
+	KeyHealth *core.KeyHealth `json:"key_health,omitempty"`
+	// Pacing holds the fill levels of the provider's request pacing buckets.
+	// Nil when the provider is not paced.
+	Pacing *pacing.Snapshot `json:"pacing,omitempty"`
+	// Concurrency holds the provider's in-flight slots and their current
+	// allocation and queue depth per model. Nil when concurrency is not
+	// limited.
+	Concurrency *fairshare.Snapshot `json:"concurrency,omitempty"`
+	// CircuitOpenUntil is when the provider's open circuit breaker lets the
+	// next probe through. Nil while the circuit is closed.
+	CircuitOpenUntil *time.Time `json:"circuit_open_until,omitempty"`
+	// Initialization is the construction state of a provider configured
+	// with lazy: true: uninitialized, initialized or init_failed. Empty for
+	// providers constructed at startup.
+	Initialization string `json:"initialization,omitempty"`
+	// InitError is the error of the last failed lazy construction.
+	InitError string `json:"init_error,omitempty"`
+	// LastInitAt is when the provider was last constructed lazily or
+	// failed to be.
+	LastInitAt *time.Time `json:"last_init_at,omitempty"`
 }
 
 type providerRuntimeState struct {
@@ -62,6 +116,16 @@ type providerRuntimeState struct {
 	lastAvailabilityCheckAt time.Time
 	lastAvailabilityOKAt    time.Time
 	lastAvailabilityError   string
+	rateLimits              *core.RateLimits
+	capabilities            *core.CapabilityProfile
+	keyHealth               *core.KeyHealth
+	pacer                   *pacing.Pacer
+	scheduler               *fairshare.Scheduler
+	circuitOpenUntil        time.Time
+	lazy                    bool
+	initState               string
+	initError               string
+	lastInitAt              time.Time
 }
 
 // SanitizeProviderConfigs converts effective provider configs into a stable,
@@ -89,6 +153,18 @@ func SanitizeProviderConfigs(configs map[string]ProviderConfig) []SanitizedProvi
 			models = append(models, model)
 		}
 
+		var signing *SanitizedSigningConfig
+		if cfg.Signing != nil {
+			signing = &SanitizedSigningConfig{
+				Type:             cfg.Signing.Type,
+				Header:           cfg.Signing.Header,
+				IncludeTimestamp: cfg.Signing.IncludeTimestamp,
+			}
+			if cfg.Signing.IncludeTimestamp {
+				signing.TimestampHeader = cfg.Signing.TimestampHeader
+			}
+		}
+
 		result = append(result, SanitizedProviderConfig{
 			Name:       strings.TrimSpace(name),
 			Type:       strings.TrimSpace(cfg.Type),
@@ -109,12 +185,34 @@ func SanitizeProviderConfigs(configs map[string]ProviderConfig) []SanitizedProvi
 					Timeout:          cfg.Resilience.CircuitBreaker.Timeout.String(),
 				},
 			},
+			Signing:              signing,
+			AcceptEncoding:       append([]string(nil), cfg.AcceptEncoding...),
+			DataResidency:        cfg.DataResidency,
+			AllowUnlistedModels:  cfg.AllowUnlistedModels,
+			Lazy:                 cfg.Lazy,
+			ProxyURL:             redactedProxyURL(cfg.Transport.ProxyURL),
+			ExtraHeaders:         sortedKeys(cfg.Extra.Headers),
+			ExtraQuery:           sortedKeys(cfg.Extra.Query),
+			ExtraHeadersOverride: cfg.Extra.Override && len(cfg.Extra.Headers) > 0,
 		})
 	}
 
 	return result
 }
 
+// sortedKeys returns the keys of m in order, or nil when m is empty.
+func sortedKeys(m map[string]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func timePtrUTC(t time.Time) *time.Time {
 	if t.IsZero() {
 		return nil
@@ -122,3 +220,53 @@ func timePtrUTC(t time.Time) *time.Time {
 	value := t.UTC()
 	return &value
 }
+
+// cloneRateLimits copies limits so snapshots do not share the stored value.
+// Windows are replaced, never mutated, once recorded.
+func cloneRateLimits(limits *core.RateLimits) *core.RateLimits {
+	if limits == nil {
+		return nil
+	}
+	cloned := *limits
+	return &cloned
+}
+
+// cloneCapabilityProfile copies profile so snapshots do not share its checks.
+func cloneCapabilityProfile(profile *core.CapabilityProfile) *core.CapabilityProfile {
+	if profile == nil {
+		return nil
+	}
+	cloned := *profile
+	cloned.Checks = append([]core.CapabilityCheck(nil), profile.Checks...)
+	return &cloned
+}
+
+// cloneKeyHealth copies health so snapshots do not share its metadata and
+// warnings.
+func cloneKeyHealth(health *core.KeyHealth) *core.KeyHealth {
+	if health == nil {
+		return nil
+	}
+	cloned := *health
+	if health.LastValidAt != nil {
+		lastValidAt := *health.LastValidAt
+		cloned.LastValidAt = &lastValidAt
+	}
+	if health.Metadata != nil {
+		metadata := *health.Metadata
+		cloned.Metadata = &metadata
+	}
+	cloned.Warnings = append([]string(nil), health.Warnings...)
+	return &cloned
+}
+
+func redactedProxyURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
@@ -6,20 +6,26 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"maps"
 	"net/http"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gomodel/internal/cache/modelcache"
 	"gomodel/internal/core"
+	"gomodel/internal/fairshare"
+	"gomodel/internal/logging"
 	"gomodel/internal/modeldata"
+	"gomodel/internal/pacing"
 )
 
+// registryLogger logs model registry refresh and cache events.
+var registryLogger = logging.Component(logging.ComponentRegistry)
+
 // ModelInfo holds information about a model and its provider
 type ModelInfo struct {
 	Model        core.Model
@@ -32,26 +38,31 @@ type ModelInfo struct {
 // It fetches models from providers on startup and caches them in memory.
 // Supports loading from a cache (local file or Redis) for instant startup.
 type ModelRegistry struct {
-	mu               sync.RWMutex
-	models           map[string]*ModelInfo            // model ID -> model info (first provider wins)
-	modelsByProvider map[string]map[string]*ModelInfo // provider instance name -> model ID -> model info
-	providers        []core.Provider
-	providerTypes    map[core.Provider]string // provider -> type string
-	providerNames    map[core.Provider]string // provider -> configured provider instance name
-	providerRuntime  map[string]providerRuntimeState
-	cache            modelcache.Cache     // cache backend (local or redis)
-	initialized      bool                 // true when at least one successful network fetch completed
-	initMu           sync.Mutex           // protects initialized flag
-	refreshCh        chan struct{}        // serializes provider/model-list refresh cycles
-	refreshOnce      sync.Once            // initializes refreshCh for zero-value safety
-	modelList        *modeldata.ModelList // parsed model list (nil = not loaded)
-	modelListRaw     json.RawMessage      // raw bytes for cache persistence
-
-	// Cached sorted slices, rebuilt lazily after models change.
-	// nil means cache needs rebuilding. Protected by mu.
-	sortedModels             []core.Model
-	sortedModelsWithProvider []ModelWithProvider
-	categoryCache            map[core.ModelCategory][]ModelWithProvider
+	mu                sync.RWMutex
+	models            map[string]*ModelInfo            // model ID -> model info (first provider wins)
+	modelsByProvider  map[string]map[string]*ModelInfo // provider instance name -> model ID -> model info
+	providers         []core.Provider
+	providerTypes     map[core.Provider]string // provider -> type string
+	providerNames     map[core.Provider]string // provider -> configured provider instance name
+	providerRuntime   map[string]providerRuntimeState
+	providerResidency map[string]string                // provider instance name -> data residency label
+	unlistedAllowed   map[string]bool                  // provider instance name -> accepts models missing from its listing
+	unlistedLearned   map[string]map[string]core.Model // provider instance name -> model ID -> model served while unlisted
+	unlistedConflicts sync.Map                         // unlisted model selector -> conflict already logged
+	lazyProviders     []*lazyProvider                  // placeholders of providers configured with lazy: true
+	cache             modelcache.Cache                 // cache backend (local or redis)
+	initialized       bool                             // true when at least one successful network fetch completed
+	initMu            sync.Mutex                       // protects initialized flag
+	refreshCh         chan struct{}                    // serializes provider/model-list refresh cycles
+	refreshOnce       sync.Once                        // initializes refreshCh for zero-value safety
+	modelList         *modeldata.ModelList             // parsed model list (nil = not loaded)
+	modelListRaw      json.RawMessage                  // raw bytes for cache persistence
+	customCategories  []customCategory                 // configured categories assigned by model ID pattern
+	refreshHooks      []func()                         // run after every successful provider refresh
+
+	// snapshot holds the sorted model listings, rebuilt and swapped whenever
+	// models change so that listing reads never take mu.
+	snapshot atomic.Pointer[modelSnapshot]
 }
 
 type metadataEnrichmentStats struct {
@@ -71,12 +82,15 @@ func (s metadataEnrichmentStats) slogAttrs() []any {
 // NewModelRegistry creates a new model registry
 func NewModelRegistry() *ModelRegistry {
 	return &ModelRegistry{
-		models:           make(map[string]*ModelInfo),
-		modelsByProvider: make(map[string]map[string]*ModelInfo),
-		providerTypes:    make(map[core.Provider]string),
-		providerNames:    make(map[core.Provider]string),
-		providerRuntime:  make(map[string]providerRuntimeState),
-		refreshCh:        make(chan struct{}, 1),
+		models:            make(map[string]*ModelInfo),
+		modelsByProvider:  make(map[string]map[string]*ModelInfo),
+		providerTypes:     make(map[core.Provider]string),
+		providerNames:     make(map[core.Provider]string),
+		providerRuntime:   make(map[string]providerRuntimeState),
+		providerResidency: make(map[string]string),
+		unlistedAllowed:   make(map[string]bool),
+		unlistedLearned:   make(map[string]map[string]core.Model),
+		refreshCh:         make(chan struct{}, 1),
 	}
 }
 
@@ -88,14 +102,6 @@ func (r *ModelRegistry) SetCache(c modelcache.Cache) {
 	r.cache = c
 }
 
-// invalidateSortedCaches clears cached sorted slices so they are rebuilt lazily.
-// Must be called while holding the write lock (r.mu.Lock).
-func (r *ModelRegistry) invalidateSortedCaches() {
-	r.sortedModels = nil
-	r.sortedModelsWithProvider = nil
-	r.categoryCache = nil
-}
-
 // RegisterProvider adds a provider to the registry
 func (r *ModelRegistry) RegisterProvider(provider core.Provider) {
 	r.RegisterProviderWithNameAndType(provider, "", "")
@@ -156,6 +162,7 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 	newModelsByProvider := make(map[string]map[string]*ModelInfo)
 	var totalModels int
 	var failedProviders int
+	var pendingLazy int
 	runtimeUpdates := make(map[string]providerRuntimeState)
 
 	r.mu.RLock()
@@ -163,6 +170,13 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 	providerNames := make(map[core.Provider]string, len(r.providerNames))
 	maps.Copy(providerTypes, r.providerTypes)
 	maps.Copy(providerNames, r.providerNames)
+	learned := r.learnedModelsLocked()
+	cachedLazy := make(map[string]map[string]*ModelInfo)
+	for _, provider := range providers {
+		if placeholder, ok := provider.(*lazyProvider); ok {
+			cachedLazy[placeholder.name] = maps.Clone(r.modelsByProvider[placeholder.name])
+		}
+	}
 	r.mu.RUnlock()
 
 	for _, provider := range providers {
@@ -174,10 +188,22 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 			providerName = fmt.Sprintf("%p", provider)
 		}
 
+		// Refreshes never construct lazy providers; they keep the models
+		// loaded from the cache until their first use.
+		if _, ok := provider.(*lazyProvider); ok {
+			pendingLazy++
+			for _, info := range cachedLazy[providerName] {
+				if addModelInfo(newModels, newModelsByProvider, info) {
+					totalModels++
+				}
+			}
+			continue
+		}
+
 		resp, err := provider.ListModels(ctx)
 		fetchAt := time.Now().UTC()
 		if err != nil {
-			slog.Warn("failed to fetch models from provider",
+			registryLogger.Warn("failed to fetch models from provider",
 				"provider", providerName,
 				"error", err,
 			)
@@ -192,7 +218,7 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 
 		if resp == nil {
 			err := errors.New("provider returned nil model list")
-			slog.Warn("failed to fetch models from provider",
+			registryLogger.Warn("failed to fetch models from provider",
 				"provider", providerName,
 				"error", err,
 			)
@@ -207,7 +233,7 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 
 		if len(resp.Data) == 0 {
 			err := errors.New("provider returned empty model list")
-			slog.Warn("provider returned empty model list",
+			registryLogger.Warn("provider returned empty model list",
 				"provider", providerName,
 			)
 			runtimeUpdates[providerName] = providerRuntimeState{
@@ -243,7 +269,7 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 			if _, exists := newModels[model.ID]; exists {
 				// Model already registered by another provider, skip
 				// First provider wins for unqualified lookups.
-				slog.Debug("model already registered, skipping",
+				registryLogger.Debug("model already registered, skipping",
 					"model", model.ID,
 					"provider", providerName,
 					"owner", model.OwnedBy,
@@ -256,29 +282,32 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 		}
 	}
 
-	if totalModels == 0 {
+	if totalModels == 0 && pendingLazy < len(providers) {
 		r.applyProviderRuntimeUpdates(runtimeUpdates)
-		if failedProviders == len(providers) {
+		if failedProviders+pendingLazy == len(providers) {
 			return fmt.Errorf("failed to fetch models from any provider")
 		}
 		return fmt.Errorf("no models available: providers returned empty model lists")
 	}
+	totalModels += mergeLearnedModels(learned, providers, providerNames, providerTypes, newModels, newModelsByProvider)
 
 	// Enrich models with metadata from the model list (if loaded)
 	r.mu.RLock()
 	list := r.modelList
+	customCategories := r.customCategories
 	r.mu.RUnlock()
 	metadataStats := metadataEnrichmentStats{}
 	if list != nil {
 		metadataStats = enrichProviderModelMaps(list, providerTypes, newModelsByProvider, nil)
 	}
+	applyCustomCategories(customCategories, newModelsByProvider, nil)
 
-	// Atomically swap the models map and invalidate sorted caches
+	// Atomically swap the models map and publish a new snapshot
 	r.mu.Lock()
 	r.models = newModels
 	r.modelsByProvider = newModelsByProvider
 	r.applyProviderRuntimeUpdatesLocked(runtimeUpdates)
-	r.invalidateSortedCaches()
+	r.publishSnapshotLocked()
 	r.mu.Unlock()
 
 	// Mark as initialized
@@ -292,11 +321,26 @@ func (r *ModelRegistry) initialize(ctx context.Context) error {
 		"failed_providers", failedProviders,
 	}
 	attrs = append(attrs, metadataStats.slogAttrs()...)
-	slog.Info("model registry initialized", attrs...)
+	registryLogger.Info("model registry initialized", attrs...)
+
+	r.mu.RLock()
+	hooks := slices.Clone(r.refreshHooks)
+	r.mu.RUnlock()
+	for _, hook := range hooks {
+		hook()
+	}
 
 	return nil
 }
 
+// OnRefresh registers fn to run after every successful refresh of the
+// provider model listings, without registry locks held.
+func (r *ModelRegistry) OnRefresh(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshHooks = append(r.refreshHooks, fn)
+}
+
 func (r *ModelRegistry) applyProviderRuntimeUpdates(updates map[string]providerRuntimeState) {
 	if len(updates) == 0 {
 		return
@@ -432,7 +476,7 @@ func (r *ModelRegistry) LoadFromCache(ctx context.Context) (int, error) {
 	if len(modelCache.ModelListData) > 0 {
 		parsed, parseErr := modeldata.Parse(modelCache.ModelListData)
 		if parseErr != nil {
-			slog.Warn("failed to parse cached model list data", "error", parseErr)
+			registryLogger.Warn("failed to parse cached model list data", "error", parseErr)
 		} else {
 			list = parsed
 		}
@@ -443,11 +487,15 @@ func (r *ModelRegistry) LoadFromCache(ctx context.Context) (int, error) {
 	if list != nil {
 		metadataStats = enrichProviderModelMaps(list, r.snapshotProviderTypes(), newModelsByProvider, nil)
 	}
+	r.mu.RLock()
+	customCategories := r.customCategories
+	r.mu.RUnlock()
+	applyCustomCategories(customCategories, newModelsByProvider, nil)
 
 	r.mu.Lock()
 	r.models = newModels
 	r.modelsByProvider = newModelsByProvider
-	r.invalidateSortedCaches()
+	r.publishSnapshotLocked()
 	if list != nil {
 		r.modelList = list
 		r.modelListRaw = modelCache.ModelListData
@@ -459,7 +507,7 @@ func (r *ModelRegistry) LoadFromCache(ctx context.Context) (int, error) {
 		"cache_updated_at", modelCache.UpdatedAt,
 	}
 	attrs = append(attrs, metadataStats.slogAttrs()...)
-	slog.Info("loaded models from cache", attrs...)
+	registryLogger.Info("loaded models from cache", attrs...)
 
 	return len(newModels), nil
 }
@@ -537,7 +585,7 @@ func (r *ModelRegistry) SaveToCache(ctx context.Context) error {
 		return fmt.Errorf("failed to save cache: %w", err)
 	}
 
-	slog.Debug("saved models to cache", "models", totalModels)
+	registryLogger.Debug("saved models to cache", "models", totalModels)
 	return nil
 }
 
@@ -549,9 +597,9 @@ func (r *ModelRegistry) InitializeAsync(ctx context.Context) {
 	// First, try to load from cache for instant startup
 	cached, err := r.LoadFromCache(ctx)
 	if err != nil {
-		slog.Warn("failed to load models from cache", "error", err)
+		registryLogger.Warn("failed to load models from cache", "error", err)
 	} else if cached > 0 {
-		slog.Info("serving traffic with cached models while refreshing", "cached_models", cached)
+		registryLogger.Info("serving traffic with cached models while refreshing", "cached_models", cached)
 	}
 
 	// Start background initialization
@@ -560,13 +608,13 @@ func (r *ModelRegistry) InitializeAsync(ctx context.Context) {
 		defer cancel()
 
 		if err := r.Initialize(initCtx); err != nil {
-			slog.Warn("background model initialization failed", "error", err)
+			registryLogger.Warn("background model initialization failed", "error", err)
 			return
 		}
 
 		// Save to cache for next startup
 		if err := r.SaveToCache(initCtx); err != nil {
-			slog.Warn("failed to save models to cache", "error", err)
+			registryLogger.Warn("failed to save models to cache", "error", err)
 		}
 	}()
 }
@@ -579,25 +627,14 @@ func (r *ModelRegistry) IsInitialized() bool {
 	return r.initialized
 }
 
-// GetProvider returns the provider for the given model, or nil if not found
+// GetProvider returns the provider for the given model, or nil if not found.
+// Models missing from the listings resolve to a provider that allows unlisted
+// models.
 func (r *ModelRegistry) GetProvider(model string) core.Provider {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	providerName, modelID := splitModelSelector(model)
-	if providerName != "" {
-		if providerModels, ok := r.modelsByProvider[providerName]; ok {
-			if info, exists := providerModels[modelID]; exists {
-				return info.Provider
-			}
-		}
-		if r.hasConfiguredProviderNameLocked(providerName) {
-			return nil
-		}
-		// Fall through: the slash may be part of the model ID (e.g. "meta-llama/Meta-Llama-3-70B")
-	}
-
-	if info, ok := r.models[model]; ok {
+	if info := r.resolveModelLocked(model); info != nil {
 		return info.Provider
 	}
 	return nil
@@ -609,6 +646,13 @@ func (r *ModelRegistry) GetModel(model string) *ModelInfo {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	return r.findModelLocked(model)
+}
+
+// findModelLocked returns the listed model info for a selector. Qualified
+// selectors use the configured provider name prefix when present.
+// Callers must hold r.mu.
+func (r *ModelRegistry) findModelLocked(model string) *ModelInfo {
 	providerName, modelID := splitModelSelector(model)
 	if providerName != "" {
 		if providerModels, ok := r.modelsByProvider[providerName]; ok {
@@ -619,13 +663,19 @@ func (r *ModelRegistry) GetModel(model string) *ModelInfo {
 		if r.hasConfiguredProviderNameLocked(providerName) {
 			return nil
 		}
-		// Fall through: the slash may be part of the model ID
+		// Fall through: the slash may be part of the model ID (e.g. "meta-llama/Meta-Llama-3-70B")
 	}
 
-	if info, ok := r.models[model]; ok {
+	return r.models[model]
+}
+
+// resolveModelLocked returns the listed model info for a selector, falling
+// back to a provider that allows unlisted models. Callers must hold r.mu.
+func (r *ModelRegistry) resolveModelLocked(model string) *ModelInfo {
+	if info := r.findModelLocked(model); info != nil {
 		return info
 	}
-	return nil
+	return r.unlistedModelLocked(model)
 }
 
 // LookupModel returns a shallow copy of the concrete model for the given selector.
@@ -634,106 +684,55 @@ func (r *ModelRegistry) LookupModel(model string) (*core.Model, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	providerName, modelID := splitModelSelector(model)
-	if providerName != "" {
-		if providerModels, ok := r.modelsByProvider[providerName]; ok {
-			if info, exists := providerModels[modelID]; exists {
-				cloned := info.Model
-				return &cloned, true
-			}
-		}
-		if r.hasConfiguredProviderNameLocked(providerName) {
-			return nil, false
-		}
-		// Fall through: the slash may be part of the model ID
+	info := r.findModelLocked(model)
+	if info == nil {
+		return nil, false
 	}
+	cloned := info.Model
+	return &cloned, true
+}
 
-	if info, ok := r.models[model]; ok {
-		cloned := info.Model
-		return &cloned, true
+// Listed reports whether a provider of the given type or configured name
+// lists model. An empty provider matches any provider. Unlike Supports, models
+// only served because their provider allows unlisted models do not count.
+func (r *ModelRegistry) Listed(provider, model string) bool {
+	for _, listed := range r.ListModelsWithProvider() {
+		if listed.Model.ID != model {
+			continue
+		}
+		if provider == "" || strings.EqualFold(listed.ProviderType, provider) || strings.EqualFold(listed.ProviderName, provider) {
+			return true
+		}
 	}
-	return nil, false
+	return false
 }
 
-// Supports returns true if the registry has a provider for the given model
+// Supports returns true if the registry has a provider for the given model,
+// including providers that allow unlisted models.
 func (r *ModelRegistry) Supports(model string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	providerName, modelID := splitModelSelector(model)
-	if providerName != "" {
-		if providerModels, ok := r.modelsByProvider[providerName]; ok {
-			if _, exists := providerModels[modelID]; exists {
-				return true
-			}
-		}
-		if r.hasConfiguredProviderNameLocked(providerName) {
-			return false
-		}
-		// Fall through: the slash may be part of the model ID
-	}
-
-	_, ok := r.models[model]
-	return ok
+	return r.resolveModelLocked(model) != nil
 }
 
 // ListModels returns all models in the registry, sorted by model ID for consistent ordering.
-// The sorted slice is cached and rebuilt only when the underlying models change.
-// Returns a defensive copy so callers cannot mutate the internal cache.
+// It reads the current snapshot without locking.
+// Returns a defensive copy so callers cannot mutate the snapshot.
 func (r *ModelRegistry) ListModels() []core.Model {
-	r.mu.RLock()
-	if cached := r.sortedModels; cached != nil {
-		r.mu.RUnlock()
-		return append([]core.Model(nil), cached...)
-	}
-	r.mu.RUnlock()
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	// Double-check: another goroutine may have built it while we waited for the lock.
-	if r.sortedModels != nil {
-		return append([]core.Model(nil), r.sortedModels...)
-	}
-
-	models := make([]core.Model, 0, len(r.models))
-	for _, info := range r.models {
-		models = append(models, info.Model)
-	}
-	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
-
-	r.sortedModels = models
-	return append([]core.Model(nil), models...)
+	return append([]core.Model(nil), r.loadSnapshot().models...)
 }
 
 // ListPublicModels returns all provider-backed models as public selectors in
 // providerName/modelID form, sorted by public model ID.
+// It reads the current snapshot without locking.
 func (r *ModelRegistry) ListPublicModels() []core.Model {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	total := 0
-	for _, models := range r.modelsByProvider {
-		total += len(models)
-	}
-
-	result := make([]core.Model, 0, total)
-	for providerName, models := range r.modelsByProvider {
-		for modelID, info := range models {
-			model := info.Model
-			model.ID = qualifyPublicModelID(providerName, modelID)
-			model.OwnedBy = providerName
-			result = append(result, model)
-		}
-	}
-	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
-	return result
+	return append([]core.Model{}, r.loadSnapshot().publicModels...)
 }
 
 // ModelCount returns the number of registered models
 func (r *ModelRegistry) ModelCount() int {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return len(r.models)
+	return len(r.loadSnapshot().models)
 }
 
 // GetProviderType returns the provider type string for the given model.
@@ -742,20 +741,7 @@ func (r *ModelRegistry) GetProviderType(model string) string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	providerName, modelID := splitModelSelector(model)
-	if providerName != "" {
-		if providerModels, ok := r.modelsByProvider[providerName]; ok {
-			if info, exists := providerModels[modelID]; exists {
-				return info.ProviderType
-			}
-		}
-		if r.hasConfiguredProviderNameLocked(providerName) {
-			return ""
-		}
-		// Fall through: the slash may be part of the model ID
-	}
-
-	if info, ok := r.models[model]; ok {
+	if info := r.resolveModelLocked(model); info != nil {
 		return info.ProviderType
 	}
 	return ""
@@ -767,19 +753,7 @@ func (r *ModelRegistry) GetProviderName(model string) string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	providerName, modelID := splitModelSelector(model)
-	if providerName != "" {
-		if providerModels, ok := r.modelsByProvider[providerName]; ok {
-			if info, exists := providerModels[modelID]; exists {
-				return strings.TrimSpace(info.ProviderName)
-			}
-		}
-		if r.hasConfiguredProviderNameLocked(providerName) {
-			return ""
-		}
-	}
-
-	if info, ok := r.models[model]; ok {
+	if info := r.resolveModelLocked(model); info != nil {
 		return strings.TrimSpace(info.ProviderName)
 	}
 	return ""
@@ -824,6 +798,31 @@ func (r *ModelRegistry) GetProviderTypeForName(providerName string) string {
 	return ""
 }
 
+// SetProviderDataResidency labels a configured provider instance with the data
+// residency it satisfies. An empty label means "any".
+func (r *ModelRegistry) SetProviderDataResidency(providerName, residency string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" {
+		return
+	}
+	if r.providerResidency == nil {
+		r.providerResidency = make(map[string]string)
+	}
+	r.providerResidency[providerName] = strings.TrimSpace(residency)
+}
+
+// ProviderDataResidency returns the data residency label of a configured
+// provider instance, or "" when it has none.
+func (r *ModelRegistry) ProviderDataResidency(providerName string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.providerResidency[strings.TrimSpace(providerName)]
+}
+
 // ProviderByType returns the first registered provider for the given provider type.
 // This lookup is independent of discovered models so provider-typed routes keep
 // working even when a provider currently exposes zero models.
@@ -951,114 +950,22 @@ type ModelWithProvider struct {
 }
 
 // ListModelsWithProvider returns all provider-backed models with provider metadata,
-// sorted by public selector.
-// The sorted slice is cached and rebuilt only when the underlying models change.
-// Returns a defensive copy so callers cannot mutate the internal cache.
+// sorted by public selector. It reads the current snapshot without locking.
+// Returns a defensive copy so callers cannot mutate the snapshot.
 func (r *ModelRegistry) ListModelsWithProvider() []ModelWithProvider {
-	r.mu.RLock()
-	if cached := r.sortedModelsWithProvider; cached != nil {
-		r.mu.RUnlock()
-		return append([]ModelWithProvider(nil), cached...)
-	}
-	r.mu.RUnlock()
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if r.sortedModelsWithProvider != nil {
-		return append([]ModelWithProvider(nil), r.sortedModelsWithProvider...)
-	}
-
-	total := 0
-	for _, providerModels := range r.modelsByProvider {
-		total += len(providerModels)
-	}
-
-	result := make([]ModelWithProvider, 0, total)
-	for providerName, providerModels := range r.modelsByProvider {
-		for modelID, info := range providerModels {
-			publicProviderName := providerName
-			if info.ProviderName != "" {
-				publicProviderName = info.ProviderName
-			}
-			result = append(result, ModelWithProvider{
-				Model:        info.Model,
-				ProviderType: info.ProviderType,
-				ProviderName: publicProviderName,
-				Selector:     qualifyPublicModelID(publicProviderName, modelID),
-			})
-		}
-	}
-	sort.Slice(result, func(i, j int) bool { return result[i].Selector < result[j].Selector })
-
-	r.sortedModelsWithProvider = result
-	return append([]ModelWithProvider(nil), result...)
-}
-
-// cacheableCategory reports whether category is a known value that should be cached.
-// CategoryAll is handled separately (delegates to ListModelsWithProvider).
-var cacheableCategories = map[core.ModelCategory]struct{}{
-	core.CategoryTextGeneration: {},
-	core.CategoryEmbedding:      {},
-	core.CategoryImage:          {},
-	core.CategoryAudio:          {},
-	core.CategoryVideo:          {},
-	core.CategoryUtility:        {},
+	return append([]ModelWithProvider(nil), r.loadSnapshot().modelsWithProvider...)
 }
 
 // ListModelsWithProviderByCategory returns provider-backed models filtered by
 // category, sorted by public selector.
 // If category is CategoryAll, returns all models (same as ListModelsWithProvider).
-// Results for known categories are cached and rebuilt only when the underlying models change.
-// Returns a defensive copy so callers cannot mutate the internal cache.
+// It reads the current snapshot without locking.
+// Returns a defensive copy so callers cannot mutate the snapshot.
 func (r *ModelRegistry) ListModelsWithProviderByCategory(category core.ModelCategory) []ModelWithProvider {
 	if category == core.CategoryAll {
 		return r.ListModelsWithProvider()
 	}
-
-	_, cacheable := cacheableCategories[category]
-
-	if cacheable {
-		r.mu.RLock()
-		if r.categoryCache != nil {
-			if cached, ok := r.categoryCache[category]; ok {
-				r.mu.RUnlock()
-				return append([]ModelWithProvider(nil), cached...)
-			}
-		}
-		r.mu.RUnlock()
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if cacheable && r.categoryCache != nil {
-		if cached, ok := r.categoryCache[category]; ok {
-			return append([]ModelWithProvider(nil), cached...)
-		}
-	}
-
-	result := make([]ModelWithProvider, 0)
-	for _, providerModels := range r.modelsByProvider {
-		for modelID, info := range providerModels {
-			if info.Model.Metadata == nil || !hasCategory(info.Model.Metadata.Categories, category) {
-				continue
-			}
-			result = append(result, ModelWithProvider{
-				Model:        info.Model,
-				ProviderType: info.ProviderType,
-				ProviderName: info.ProviderName,
-				Selector:     qualifyPublicModelID(info.ProviderName, modelID),
-			})
-		}
-	}
-	sort.Slice(result, func(i, j int) bool { return result[i].Selector < result[j].Selector })
-
-	if cacheable {
-		if r.categoryCache == nil {
-			r.categoryCache = make(map[core.ModelCategory][]ModelWithProvider)
-		}
-		r.categoryCache[category] = result
-	}
-	return result
+	return append([]ModelWithProvider{}, r.loadSnapshot().byCategory[category]...)
 }
 
 // hasCategory returns true if the category slice contains the target category.
@@ -1084,31 +991,18 @@ var categoryDisplayNames = map[core.ModelCategory]string{
 	core.CategoryUtility:        "Utility",
 }
 
-// GetCategoryCounts returns model counts per category, in display order.
+// GetCategoryCounts returns model counts per category, in display order:
+// built-in categories first, then custom categories in configured order.
 // A model with multiple categories is counted in each.
 func (r *ModelRegistry) GetCategoryCounts() []CategoryCount {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	counts := make(map[core.ModelCategory]int)
-	total := 0
-	for _, providerModels := range r.modelsByProvider {
-		for _, info := range providerModels {
-			total++
-			if info.Model.Metadata != nil {
-				for _, cat := range info.Model.Metadata.Categories {
-					counts[cat]++
-				}
-			}
-		}
-	}
+	snapshot := r.loadSnapshot()
 
 	allCategories := core.AllCategories()
-	result := make([]CategoryCount, 0, len(allCategories))
+	result := make([]CategoryCount, 0, len(allCategories)+len(snapshot.customCategories))
 	for _, cat := range allCategories {
-		count := counts[cat]
+		count := len(snapshot.byCategory[cat])
 		if cat == core.CategoryAll {
-			count = total
+			count = len(snapshot.modelsWithProvider)
 		}
 		displayName := categoryDisplayNames[cat]
 		if displayName == "" {
@@ -1120,6 +1014,17 @@ func (r *ModelRegistry) GetCategoryCounts() []CategoryCount {
 			Count:       count,
 		})
 	}
+	for _, custom := range snapshot.customCategories {
+		displayName := custom.displayName
+		if displayName == "" {
+			displayName = string(custom.id)
+		}
+		result = append(result, CategoryCount{
+			Category:    custom.id,
+			DisplayName: displayName,
+			Count:       len(snapshot.byCategory[custom.id]),
+		})
+	}
 	return result
 }
 
@@ -1153,6 +1058,147 @@ func (r *ModelRegistry) RecordAvailabilityCheck(providerName string, err error)
 	r.providerRuntime[providerName] = state
 }
 
+// RecordRateLimits stores the account limits a provider reported in its
+// rate-limit response headers. Windows missing from limits keep their last
+// observed values.
+func (r *ModelRegistry) RecordRateLimits(providerName string, limits core.RateLimits) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" || limits.IsEmpty() {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	merged := limits
+	if state.rateLimits != nil {
+		merged = state.rateLimits.Merge(limits)
+	}
+	state.rateLimits = &merged
+	r.providerRuntime[providerName] = state
+}
+
+// RecordCircuitState stores whether the circuit breaker of a configured
+// provider is open and when it lets the next probe through.
+func (r *ModelRegistry) RecordCircuitState(providerName string, open bool, retryAt time.Time) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	state.circuitOpenUntil = time.Time{}
+	if open {
+		state.circuitOpenUntil = retryAt
+	}
+	r.providerRuntime[providerName] = state
+}
+
+// ProviderHealth reports whether a configured provider can take traffic at
+// now. A provider is unhealthy while its circuit breaker is open, while a
+// reported rate-limit window is exhausted, or when its last availability
+// check failed. reason explains an unhealthy result.
+func (r *ModelRegistry) ProviderHealth(providerName string, now time.Time) (healthy bool, reason string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state := r.providerRuntime[strings.TrimSpace(providerName)]
+	if now.Before(state.circuitOpenUntil) {
+		return false, "circuit breaker open"
+	}
+	if state.rateLimits != nil {
+		if _, exhausted := state.rateLimits.ExhaustedUntil(now); exhausted {
+			return false, "rate limited"
+		}
+	}
+	if state.lastAvailabilityError != "" {
+		return false, "availability check failed: " + state.lastAvailabilityError
+	}
+	return true, ""
+}
+
+// SetProviderPacer attaches the pacer of a configured provider so its fill
+// levels appear in the runtime snapshots. A nil pacer detaches it.
+func (r *ModelRegistry) SetProviderPacer(providerName string, pacer *pacing.Pacer) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	state.pacer = pacer
+	r.providerRuntime[providerName] = state
+}
+
+// SetProviderScheduler attaches the concurrency scheduler of a configured
+// provider so its per-model allocations appear in the runtime snapshots. A
+// nil scheduler detaches it.
+func (r *ModelRegistry) SetProviderScheduler(providerName string, scheduler *fairshare.Scheduler) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	state.scheduler = scheduler
+	r.providerRuntime[providerName] = state
+}
+
+// RecordCapabilityProfile stores the result of a capability probe, replacing
+// the provider's previous profile.
+func (r *ModelRegistry) RecordCapabilityProfile(providerName string, profile *core.CapabilityProfile) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" || profile == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	state.capabilities = cloneCapabilityProfile(profile)
+	r.providerRuntime[providerName] = state
+}
+
+// RecordKeyHealth stores the latest key health check result of a provider,
+// replacing the previous one.
+func (r *ModelRegistry) RecordKeyHealth(providerName string, health *core.KeyHealth) {
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" || health == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.providerRuntime[providerName]
+	state.keyHealth = cloneKeyHealth(health)
+	r.providerRuntime[providerName] = state
+}
+
+// ProbedCapability reports whether the applied capability profile of a
+// provider found capability supported. known is false when no applied
+// profile checked it conclusively.
+func (r *ModelRegistry) ProbedCapability(providerName, capability string) (supported, known bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	profile := r.providerRuntime[strings.TrimSpace(providerName)].capabilities
+	if profile == nil || !profile.Applied {
+		return false, false
+	}
+	return profile.Supports(capability)
+}
+
 // ProviderRuntimeSnapshots returns runtime diagnostics for configured providers
 // keyed by configured provider name.
 func (r *ModelRegistry) ProviderRuntimeSnapshots() []ProviderRuntimeSnapshot {
@@ -1175,6 +1221,15 @@ func (r *ModelRegistry) ProviderRuntimeSnapshots() []ProviderRuntimeSnapshot {
 			LastAvailabilityCheckAt: timePtrUTC(state.lastAvailabilityCheckAt),
 			LastAvailabilityOKAt:    timePtrUTC(state.lastAvailabilityOKAt),
 			LastAvailabilityError:   strings.TrimSpace(state.lastAvailabilityError),
+			RateLimits:              cloneRateLimits(state.rateLimits),
+			Capabilities:            cloneCapabilityProfile(state.capabilities),
+			KeyHealth:               cloneKeyHealth(state.keyHealth),
+			Pacing:                  state.pacer.Snapshot(),
+			Concurrency:             state.scheduler.Snapshot(),
+			CircuitOpenUntil:        timePtrUTC(state.circuitOpenUntil),
+			Initialization:          state.initState,
+			InitError:               state.initError,
+			LastInitAt:              timePtrUTC(state.lastInitAt),
 		})
 	}
 	r.mu.RUnlock()
@@ -1223,13 +1278,31 @@ func (r *ModelRegistry) enrichModelsLocked() metadataEnrichmentStats {
 
 	replacements := make(map[*ModelInfo]*ModelInfo, len(r.models))
 	stats := enrichProviderModelMaps(r.modelList, providerTypes, r.modelsByProvider, replacements)
+	// Enrichment replaces metadata wholesale, dropping custom categories.
+	applyCustomCategories(r.customCategories, r.modelsByProvider, replacements)
+	r.applyReplacementsLocked(replacements)
+	r.publishSnapshotLocked()
+	return stats
+}
+
+// applyReplacementsLocked points r.models at the replacements recorded while
+// rewriting r.modelsByProvider. A model may be replaced more than once, so
+// chains are followed to the newest value. Must be called while holding r.mu.
+func (r *ModelRegistry) applyReplacementsLocked(replacements map[*ModelInfo]*ModelInfo) {
 	for modelID, info := range r.models {
-		if replacement, ok := replacements[info]; ok {
-			r.models[modelID] = replacement
+		replaced := false
+		for {
+			replacement, ok := replacements[info]
+			if !ok {
+				break
+			}
+			info = replacement
+			replaced = true
+		}
+		if replaced {
+			r.models[modelID] = info
 		}
 	}
-	r.invalidateSortedCaches()
-	return stats
 }
 
 func (r *ModelRegistry) setModelListAndEnrich(list *modeldata.ModelList, raw json.RawMessage) metadataEnrichmentStats {
@@ -1280,6 +1353,23 @@ func (r *ModelRegistry) ResolvePricing(model, providerType string) *core.ModelPr
 	return nil
 }
 
+// ResolveContextWindow returns the context window of a model in tokens, using
+// the same registry-then-model-list lookup as ResolvePricing.
+// Returns 0 if the window is unknown.
+func (r *ModelRegistry) ResolveContextWindow(model, providerType string) int {
+	meta := r.GetModelMetadata(model)
+	if meta != nil && meta.ContextWindow != nil {
+		return *meta.ContextWindow
+	}
+	if providerType != "" {
+		meta = r.ResolveMetadata(providerType, model)
+		if meta != nil && meta.ContextWindow != nil {
+			return *meta.ContextWindow
+		}
+	}
+	return 0
+}
+
 // snapshotProviderTypes returns a copy of the providerTypes map for use outside the lock.
 func (r *ModelRegistry) snapshotProviderTypes() map[core.Provider]string {
 	r.mu.RLock()
@@ -1384,7 +1474,7 @@ func (r *ModelRegistry) StartBackgroundRefresh(interval time.Duration, modelList
 				refreshCancel()
 				if err != nil {
 					if !isBenignBackgroundRefreshError(ctx, err) {
-						slog.Warn("background model refresh failed", "error", err)
+						registryLogger.Warn("background model refresh failed", "error", err)
 					}
 				} else {
 					func() {
@@ -1392,7 +1482,7 @@ func (r *ModelRegistry) StartBackgroundRefresh(interval time.Duration, modelList
 						defer cacheCancel()
 						if err := r.SaveToCache(cacheCtx); err != nil {
 							if !isBenignBackgroundRefreshError(ctx, err) {
-								slog.Warn("failed to save models to cache after refresh", "error", err)
+								registryLogger.Warn("failed to save models to cache after refresh", "error", err)
 							}
 						}
 					}()
@@ -1453,7 +1543,7 @@ func (r *ModelRegistry) refreshModelList(ctx context.Context, url string) {
 	release, err := r.acquireRefresh(fetchCtx)
 	if err != nil {
 		if !isBenignBackgroundRefreshError(ctx, err) {
-			slog.Warn("failed to acquire model list refresh", "url", url, "error", err)
+			registryLogger.Warn("failed to acquire model list refresh", "url", url, "error", err)
 		}
 		return
 	}
@@ -1467,7 +1557,7 @@ func (r *ModelRegistry) refreshModelList(ctx context.Context, url string) {
 	}()
 	if err != nil {
 		if !isBenignBackgroundRefreshError(ctx, err) {
-			slog.Warn("failed to refresh model list", "url", url, "error", err)
+			registryLogger.Warn("failed to refresh model list", "url", url, "error", err)
 		}
 		return
 	}
@@ -1477,12 +1567,12 @@ func (r *ModelRegistry) refreshModelList(ctx context.Context, url string) {
 
 	if err := r.SaveToCache(fetchCtx); err != nil {
 		if !isBenignBackgroundRefreshError(ctx, err) {
-			slog.Warn("failed to save cache after model list refresh", "error", err)
+			registryLogger.Warn("failed to save cache after model list refresh", "error", err)
 		}
 	}
 	attrs := []any{"models", models}
 	attrs = append(attrs, metadataStats.slogAttrs()...)
-	slog.Debug("model list refreshed", attrs...)
+	registryLogger.Debug("model list refreshed", attrs...)
 }
 
 func isBenignBackgroundRefreshError(parent context.Context, err error) bool {
@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"gomodel/internal/core"
+)
+
+// modelSnapshot is an immutable view of the registry's model listings.
+// Readers load it without locking; every change to the models builds a new
+// snapshot and swaps it in, so listings never wait on a refresh in progress.
+type modelSnapshot struct {
+	// version is a content hash: identical model sets share a version.
+	version            string
+	models             []core.Model // sorted by model ID, first provider wins
+	publicModels       []core.Model // providerName/modelID selectors, sorted by ID
+	modelsWithProvider []ModelWithProvider
+	byCategory         map[core.ModelCategory][]ModelWithProvider
+	customCategories   []customCategory
+}
+
+var emptyModelSnapshot = &modelSnapshot{version: snapshotVersion(nil, nil)}
+
+// loadSnapshot returns the current model snapshot without locking.
+func (r *ModelRegistry) loadSnapshot() *modelSnapshot {
+	if snapshot := r.snapshot.Load(); snapshot != nil {
+		return snapshot
+	}
+	return emptyModelSnapshot
+}
+
+// publishSnapshotLocked builds a snapshot from the current models and swaps it
+// in. Must be called while holding the write lock (r.mu.Lock).
+func (r *ModelRegistry) publishSnapshotLocked() {
+	models := make([]core.Model, 0, len(r.models))
+	for _, info := range r.models {
+		models = append(models, info.Model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	total := 0
+	for _, providerModels := range r.modelsByProvider {
+		total += len(providerModels)
+	}
+	publicModels := make([]core.Model, 0, total)
+	withProvider := make([]ModelWithProvider, 0, total)
+	for providerName, providerModels := range r.modelsByProvider {
+		for modelID, info := range providerModels {
+			public := info.Model
+			public.ID = qualifyPublicModelID(providerName, modelID)
+			public.OwnedBy = providerName
+			publicModels = append(publicModels, public)
+
+			publicProviderName := providerName
+			if info.ProviderName != "" {
+				publicProviderName = info.ProviderName
+			}
+			withProvider = append(withProvider, ModelWithProvider{
+				Model:        info.Model,
+				ProviderType: info.ProviderType,
+				ProviderName: publicProviderName,
+				Selector:     qualifyPublicModelID(publicProviderName, modelID),
+			})
+		}
+	}
+	sort.Slice(publicModels, func(i, j int) bool { return publicModels[i].ID < publicModels[j].ID })
+	sort.Slice(withProvider, func(i, j int) bool { return withProvider[i].Selector < withProvider[j].Selector })
+
+	// withProvider is sorted, so each category list is sorted too.
+	byCategory := make(map[core.ModelCategory][]ModelWithProvider)
+	for _, model := range withProvider {
+		if model.Model.Metadata == nil {
+			continue
+		}
+		for _, category := range model.Model.Metadata.Categories {
+			byCategory[category] = append(byCategory[category], model)
+		}
+	}
+
+	r.snapshot.Store(&modelSnapshot{
+		version:            snapshotVersion(models, withProvider),
+		models:             models,
+		publicModels:       publicModels,
+		modelsWithProvider: withProvider,
+		byCategory:         byCategory,
+		customCategories:   r.customCategories,
+	})
+}
+
+// snapshotVersion hashes the sorted listings so that rebuilding the same
+// models yields the same version, across refreshes and gateway instances.
+func snapshotVersion(models []core.Model, withProvider []ModelWithProvider) string {
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	// Encoding plain model structs cannot fail.
+	_ = encoder.Encode(models)       //nolint:errcheck
+	_ = encoder.Encode(withProvider) //nolint:errcheck
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// ModelsVersion returns the version of the current model snapshot. It changes
+// whenever the listed models or their metadata change and stays the same when
+// a refresh returns identical models.
+func (r *ModelRegistry) ModelsVersion() string {
+	return r.loadSnapshot().version
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func newSnapshotTestRegistry(t testing.TB, provider *registryMockProvider) *ModelRegistry {
+	t.Helper()
+	registry := NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(provider, "primary", "openai")
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return registry
+}
+
+func TestModelsVersion_StableAcrossIdenticalSnapshots(t *testing.T) {
+	provider := &registryMockProvider{
+		name: "primary",
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data: []core.Model{
+				{ID: "gpt-4o", Object: "model", OwnedBy: "openai"},
+				{ID: "gpt-4o-mini", Object: "model", OwnedBy: "openai"},
+			},
+		},
+	}
+	registry := newSnapshotTestRegistry(t, provider)
+
+	first := registry.ModelsVersion()
+	if first == "" || first == NewModelRegistry().ModelsVersion() {
+		t.Fatalf("ModelsVersion() = %q, want a version distinct from the empty registry", first)
+	}
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := registry.ModelsVersion(); got != first {
+		t.Fatalf("ModelsVersion() after identical refresh = %q, want %q", got, first)
+	}
+	if other := newSnapshotTestRegistry(t, provider).ModelsVersion(); other != first {
+		t.Fatalf("ModelsVersion() of an identical registry = %q, want %q", other, first)
+	}
+
+	provider.modelsResponse = &core.ModelsResponse{
+		Object: "list",
+		Data:   []core.Model{{ID: "gpt-4o", Object: "model", OwnedBy: "openai"}},
+	}
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := registry.ModelsVersion(); got == first {
+		t.Fatalf("ModelsVersion() = %q after the model set changed, want a new version", got)
+	}
+	if got := registry.ModelCount(); got != 1 {
+		t.Fatalf("ModelCount() = %d, want 1", got)
+	}
+}
+
+func TestListModels_DoesNotWaitForRegistryLock(t *testing.T) {
+	registry := newSnapshotTestRegistry(t, &registryMockProvider{
+		name: "primary",
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "gpt-4o", Object: "model", OwnedBy: "openai"}},
+		},
+	})
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	done := make(chan int, 1)
+	go func() {
+		count := len(registry.ListModels()) + len(registry.ListPublicModels()) +
+			len(registry.ListModelsWithProvider()) + len(registry.ListModelsWithProviderByCategory(core.CategoryTextGeneration))
+		_ = registry.GetCategoryCounts()
+		done <- count
+	}()
+
+	select {
+	case count := <-done:
+		if count != 3 {
+			t.Fatalf("listed %d models across listings, want 3", count)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("model listings blocked while the registry lock was held")
+	}
+}
+
+// BenchmarkListModelsWithProviderDuringRefresh measures listing reads while
+// another goroutine keeps rebuilding and swapping snapshots under the write
+// lock, as a refresh does.
+func BenchmarkListModelsWithProviderDuringRefresh(b *testing.B) {
+	data := make([]core.Model, 0, 500)
+	for i := range 500 {
+		data = append(data, core.Model{ID: fmt.Sprintf("model-%03d", i), Object: "model", OwnedBy: "openai"})
+	}
+	registry := newSnapshotTestRegistry(b, &registryMockProvider{
+		name:           "primary",
+		modelsResponse: &core.ModelsResponse{Object: "list", Data: data},
+	})
+
+	var stop atomic.Bool
+	refreshed := make(chan int)
+	go func() {
+		refreshes := 0
+		for !stop.Load() {
+			registry.mu.Lock()
+			registry.publishSnapshotLocked()
+			registry.mu.Unlock()
+			refreshes++
+		}
+		refreshed <- refreshes
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if len(registry.ListModelsWithProvider()) != len(data) {
+				b.Error("listing returned a partial snapshot")
+				return
+			}
+		}
+	})
+	b.StopTimer()
+
+	stop.Store(true)
+	b.ReportMetric(float64(<-refreshed), "refreshes")
+}
@@ -8,11 +8,13 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"gomodel/config"
 	"gomodel/internal/core"
 	"gomodel/internal/modeldata"
 )
@@ -889,6 +891,39 @@ func TestInitialize_LogsSingleMetadataSummaryPerCycle(t *testing.T) {
 	}
 }
 
+func TestProviderHealth(t *testing.T) {
+	registry := NewModelRegistry()
+	now := time.Now()
+
+	if healthy, reason := registry.ProviderHealth("openai", now); !healthy {
+		t.Fatalf("ProviderHealth() = false (%s), want a provider without signals healthy", reason)
+	}
+
+	registry.RecordCircuitState("openai", true, now.Add(time.Minute))
+	if healthy, reason := registry.ProviderHealth("openai", now); healthy || reason != "circuit breaker open" {
+		t.Fatalf("ProviderHealth() = %v %q, want an open circuit", healthy, reason)
+	}
+	if healthy, _ := registry.ProviderHealth("openai", now.Add(2*time.Minute)); !healthy {
+		t.Fatal("ProviderHealth() = false, want the provider eligible again once the circuit allows probes")
+	}
+	registry.RecordCircuitState("openai", false, time.Time{})
+
+	remaining := int64(0)
+	reset := now.Add(30 * time.Second)
+	registry.RecordRateLimits("openai", core.RateLimits{Requests: &core.RateLimitWindow{Remaining: &remaining, ResetAt: &reset}})
+	if healthy, reason := registry.ProviderHealth("openai", now); healthy || reason != "rate limited" {
+		t.Fatalf("ProviderHealth() = %v %q, want rate limited", healthy, reason)
+	}
+	if healthy, _ := registry.ProviderHealth("openai", reset.Add(time.Second)); !healthy {
+		t.Fatal("ProviderHealth() = false, want the provider healthy after the window reset")
+	}
+
+	registry.RecordAvailabilityCheck("openai", errors.New("connection refused"))
+	if healthy, reason := registry.ProviderHealth("openai", reset.Add(time.Second)); healthy || !strings.Contains(reason, "connection refused") {
+		t.Fatalf("ProviderHealth() = %v %q, want the availability failure", healthy, reason)
+	}
+}
+
 func TestListModelsWithProvider_Empty(t *testing.T) {
 	registry := NewModelRegistry()
 	models := registry.ListModelsWithProvider()
@@ -1493,6 +1528,7 @@ func TestListModelsWithProviderByCategory_UsesStoredProviderMetadata(t *testing.
 			},
 		},
 	}
+	registry.publishSnapshotLocked()
 
 	allModels := registry.ListModelsWithProvider()
 	if len(allModels) != 1 {
@@ -1652,5 +1688,109 @@ func TestGetCategoryCounts(t *testing.T) {
 	}
 }
 
+func TestSetCustomCategories_AssignsMatchingModelsAcrossEnrichment(t *testing.T) {
+	registry := NewModelRegistry()
+	providerMeta := &core.ModelMetadata{Categories: []core.ModelCategory{core.CategoryTextGeneration}}
+	mock := &registryMockProvider{
+		name: "test",
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data: []core.Model{
+				{ID: "gpt-4o", Object: "model", Metadata: providerMeta},
+				{ID: "meta/llama-3-ft", Object: "model"},
+				{ID: "dall-e-3", Object: "model"},
+			},
+		},
+	}
+	registry.RegisterProviderWithNameAndType(mock, "openai", "openai")
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	// Applied to already-published models.
+	registry.SetCustomCategories([]config.ModelCategoryConfig{
+		{ID: "restricted", Models: []string{"gpt-*", "openai/meta/*"}},
+		{ID: "internal-finetune", Models: []string{"*-ft"}},
+	})
+
+	assertCategories := func(t *testing.T, selector string, want ...core.ModelCategory) {
+		t.Helper()
+		info := registry.GetModel(selector)
+		if info == nil || info.Model.Metadata == nil {
+			t.Fatalf("%s: metadata missing", selector)
+		}
+		if got := info.Model.Metadata.Categories; !slices.Equal(got, want) {
+			t.Fatalf("%s categories = %v, want %v", selector, got, want)
+		}
+	}
+	assertCategories(t, "openai/gpt-4o", core.CategoryTextGeneration, "restricted")
+	assertCategories(t, "openai/meta/llama-3-ft", "restricted", "internal-finetune")
+	if info := registry.GetModel("openai/dall-e-3"); info.Model.Metadata != nil {
+		t.Fatalf("dall-e-3 metadata = %+v, want none", info.Model.Metadata)
+	}
+	if len(providerMeta.Categories) != 1 {
+		t.Fatalf("provider metadata mutated: %v", providerMeta.Categories)
+	}
+
+	// Re-enrichment replaces metadata, so rules must be re-applied.
+	raw := []byte(`{
+		"version": 1,
+		"updated_at": "2025-01-01T00:00:00Z",
+		"providers": {"openai": {"display_name": "OpenAI", "api_type": "openai", "supported_modes": ["chat"]}},
+		"models": {"gpt-4o": {"display_name": "GPT-4o", "modes": ["chat"]}},
+		"provider_models": {}
+	}`)
+	list, err := modeldata.Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	registry.SetModelList(list, raw)
+	registry.EnrichModels()
+	assertCategories(t, "openai/gpt-4o", core.CategoryTextGeneration, "restricted")
+
+	// Refreshes apply the rules to freshly fetched models.
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	assertCategories(t, "openai/meta/llama-3-ft", "restricted", "internal-finetune")
+
+	if got := registry.ListModelsWithProviderByCategory("internal-finetune"); len(got) != 1 || got[0].Selector != "openai/meta/llama-3-ft" {
+		t.Fatalf("internal-finetune models = %+v", got)
+	}
+	if !registry.IsKnownCategory("restricted") || registry.IsKnownCategory("bogus") {
+		t.Fatal("IsKnownCategory() did not recognise configured categories only")
+	}
+}
+
 // Verify ModelRegistry implements core.ModelLookup interface
 var _ core.ModelLookup = (*ModelRegistry)(nil)
+
+func TestInitialize_KeepsLearnedUnlistedModels(t *testing.T) {
+	provider := &registryMockProvider{
+		name: "local",
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "llama3", Object: "model"}},
+		},
+	}
+	registry := NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(provider, "local", "ollama")
+	registry.SetProviderAllowUnlistedModels("local", true)
+
+	if !registry.RegisterUnlistedModel("qwen2") {
+		t.Fatal("RegisterUnlistedModel() = false, want the model added")
+	}
+	if registry.RegisterUnlistedModel("local/qwen2") {
+		t.Fatal("RegisterUnlistedModel() added an already registered model")
+	}
+	if err := registry.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize(): unexpected error: %v", err)
+	}
+
+	if registry.ModelCount() != 2 {
+		t.Fatalf("ModelCount() = %d, want the listed and the learned model", registry.ModelCount())
+	}
+	if _, unlisted := registry.UnlistedModelSelector("qwen2"); unlisted || !registry.Supports("local/qwen2") {
+		t.Fatal("learned model dropped by refresh")
+	}
+}
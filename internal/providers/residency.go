@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+type providerResidencyLookup interface {
+	ProviderDataResidency(providerName string) string
+}
+
+// ProviderDataResidency returns the data residency label of a configured
+// provider instance, or "" when the provider has none.
+func (r *Router) ProviderDataResidency(providerName string) string {
+	if lookup, ok := r.lookup.(providerResidencyLookup); ok {
+		return lookup.ProviderDataResidency(providerName)
+	}
+	return ""
+}
+
+// ResolveModelForResidency resolves requested like ResolveModel, restricted to
+// providers whose data residency label satisfies residency. When the provider
+// ResolveModel picks does not qualify, the first other provider serving the
+// same model that does is used instead. Selectors naming a provider instance
+// are never redirected; selectors naming a provider type stay within it.
+// It returns a data residency policy error when no provider qualifies.
+func (r *Router) ResolveModelForResidency(requested core.RequestedModelSelector, residency string) (core.ModelSelector, bool, error) {
+	resolved, changed, err := r.ResolveModel(requested)
+	if err != nil || core.DataResidencySatisfied("", residency) {
+		return resolved, changed, err
+	}
+	if r.selectorSatisfiesResidency(resolved, residency) {
+		return resolved, changed, nil
+	}
+	if alternative, ok := r.residencyAlternative(requested, resolved, residency); ok {
+		return alternative, true, nil
+	}
+	return core.ModelSelector{}, false, core.NewDataResidencyError(residency, resolved.QualifiedModel())
+}
+
+// SatisfiesDataResidency reports whether the provider serving selector may
+// handle a request that requires residency.
+func (r *Router) SatisfiesDataResidency(selector core.ModelSelector, residency string) bool {
+	if core.DataResidencySatisfied("", residency) {
+		return true
+	}
+	return r.selectorSatisfiesResidency(selector, residency)
+}
+
+func (r *Router) selectorSatisfiesResidency(selector core.ModelSelector, residency string) bool {
+	providerName := strings.TrimSpace(r.GetProviderName(selector.QualifiedModel()))
+	if providerName == "" {
+		providerName = strings.TrimSpace(selector.Provider)
+	}
+	if providerName == "" {
+		return false
+	}
+	return core.DataResidencySatisfied(r.ProviderDataResidency(providerName), residency)
+}
+
+func (r *Router) residencyAlternative(requested core.RequestedModelSelector, resolved core.ModelSelector, residency string) (core.ModelSelector, bool) {
+	models, ok := r.lookup.(modelWithProviderLister)
+	if !ok {
+		return core.ModelSelector{}, false
+	}
+	selector, err := core.NewRequestedModelSelector(requested.Model, requested.ProviderHint).Normalize()
+	if err != nil {
+		return core.ModelSelector{}, false
+	}
+
+	providerType := ""
+	if provider := strings.TrimSpace(selector.Provider); provider != "" {
+		if r.providerByTypeRegistry(provider) == nil {
+			// The selector names one provider instance; do not move it.
+			return core.ModelSelector{}, false
+		}
+		providerType = provider
+	}
+
+	modelID := strings.TrimSpace(resolved.Model)
+	for _, entry := range models.ListModelsWithProvider() {
+		if strings.TrimSpace(entry.Model.ID) != modelID {
+			continue
+		}
+		if providerType != "" && strings.TrimSpace(entry.ProviderType) != providerType {
+			continue
+		}
+		if !core.DataResidencySatisfied(r.ProviderDataResidency(entry.ProviderName), residency) {
+			continue
+		}
+		return core.ModelSelector{Provider: entry.ProviderName, Model: entry.Model.ID}, true
+	}
+	return core.ModelSelector{}, false
+}
+
+// requireProviderResidency returns a data residency policy error when the
+// provider a provider-typed route resolves to does not satisfy the requirement
+// in ctx. providerSelector is a provider type or configured provider name.
+func (r *Router) requireProviderResidency(ctx context.Context, providerSelector string) error {
+	residency := core.GetDataResidency(ctx)
+	if core.DataResidencySatisfied("", residency) {
+		return nil
+	}
+	providerName := strings.TrimSpace(providerSelector)
+	if r.providerByTypeRegistry(providerName) != nil {
+		if name := r.GetProviderNameForType(providerName); name != "" {
+			providerName = name
+		}
+	}
+	if core.DataResidencySatisfied(r.ProviderDataResidency(providerName), residency) {
+		return nil
+	}
+	return core.NewDataResidencyError(residency, "")
+}
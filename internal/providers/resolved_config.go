@@ -1,6 +1,9 @@
 package providers
 
-import "strings"
+import (
+	"net/url"
+	"strings"
+)
 
 // ResolveBaseURL returns the configured base URL when present, otherwise the provider default.
 func ResolveBaseURL(baseURL, fallback string) string {
@@ -10,6 +13,19 @@ func ResolveBaseURL(baseURL, fallback string) string {
 	return baseURL
 }
 
+// WithDefaultPath appends path to a bare-host base URL such as
+// "https://proxy.internal/", for providers whose SDKs take the host alone and
+// add the API version themselves. Base URLs with a path prefix are returned
+// unchanged.
+func WithDefaultPath(baseURL, path string) string {
+	trimmed := strings.TrimSpace(baseURL)
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.Host == "" || strings.Trim(parsed.Path, "/") != "" {
+		return baseURL
+	}
+	return strings.TrimRight(trimmed, "/") + path
+}
+
 // ResolveAPIVersion returns the configured API version when present, otherwise the provider default.
 func ResolveAPIVersion(apiVersion, fallback string) string {
 	if strings.TrimSpace(apiVersion) == "" {
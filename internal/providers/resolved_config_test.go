@@ -0,0 +1,22 @@
+package providers
+
+import "testing"
+
+func TestWithDefaultPath(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://proxy.internal", "https://proxy.internal/v1"},
+		{"https://proxy.internal/", "https://proxy.internal/v1"},
+		{"http://localhost:11434", "http://localhost:11434/v1"},
+		{"https://proxy.internal/anthropic", "https://proxy.internal/anthropic"},
+		{"https://api.anthropic.com/v1/", "https://api.anthropic.com/v1/"},
+		{"not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := WithDefaultPath(tt.baseURL, "/v1"); got != tt.want {
+			t.Errorf("WithDefaultPath(%q) = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
@@ -185,6 +185,9 @@ func convertResponsesInputItems(items []any) ([]core.Message, error) {
 	}
 
 	for i, item := range items {
+		if isResponsesReasoningItem(item) {
+			continue
+		}
 		msg, itemType, err := convertResponsesInputItem(item, i)
 		if err != nil {
 			return nil, err
@@ -215,6 +218,22 @@ func convertResponsesInputItems(items []any) ([]core.Message, error) {
 	return messages, nil
 }
 
+// isResponsesReasoningItem reports whether item is a reasoning item replayed
+// from an earlier Responses turn. Reasoning state is private to the provider
+// that produced it, so chat conversion drops it and keeps the surrounding
+// messages and tool exchange.
+func isResponsesReasoningItem(item any) bool {
+	switch typed := item.(type) {
+	case core.ResponsesInputElement:
+		return typed.Type == "reasoning"
+	case map[string]any:
+		itemType, _ := typed["type"].(string)
+		return itemType == "reasoning"
+	default:
+		return false
+	}
+}
+
 func convertResponsesInputItem(item any, index int) (core.Message, string, error) {
 	switch typed := item.(type) {
 	case core.ResponsesInputElement:
@@ -266,7 +285,7 @@ func convertResponsesInputElement(item core.ResponsesInputElement, index int) (c
 			Role:        "tool",
 			ToolCallID:  callID,
 			Content:     content,
-			ExtraFields: core.CloneUnknownJSONFields(item.ExtraFields),
+			ExtraFields: item.ExtraFields.Without("id"),
 		}, "function_call_output", nil
 	default: // message (type="" or "message")
 		role := strings.TrimSpace(item.Role)
@@ -280,7 +299,7 @@ func convertResponsesInputElement(item core.ResponsesInputElement, index int) (c
 		return core.Message{
 			Role:        role,
 			Content:     content,
-			ExtraFields: core.CloneUnknownJSONFields(item.ExtraFields),
+			ExtraFields: item.ExtraFields.Without("id"),
 		}, "message", nil
 	}
 }
@@ -327,7 +346,7 @@ func convertResponsesInputMap(item map[string]any, index int) (core.Message, str
 			Role:        "tool",
 			ToolCallID:  callID,
 			Content:     content,
-			ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(item, "type", "call_id", "status", "output")),
+			ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(item, "type", "id", "call_id", "status", "output")),
 		}, "function_call_output", nil
 	}
 
@@ -344,7 +363,7 @@ func convertResponsesInputMap(item map[string]any, index int) (core.Message, str
 	return core.Message{
 		Role:        role,
 		Content:     content,
-		ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(item, "type", "role", "status", "content")),
+		ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(item, "type", "id", "role", "status", "content")),
 	}, "message", nil
 }
 
@@ -453,10 +472,15 @@ func convertResponsesContentParts(parts []any) (any, bool) {
 			if !ok || text == "" {
 				return nil, false
 			}
+			knownKeys := []string{"type", "text"}
+			if partType == "output_text" {
+				// Replayed assistant output carries Responses-only metadata.
+				knownKeys = append(knownKeys, "annotations", "logprobs")
+			}
 			typedParts = append(typedParts, core.ContentPart{
 				Type:        "text",
 				Text:        text,
-				ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(partMap, "type", "text")),
+				ExtraFields: core.UnknownJSONFieldsFromMap(rawJSONMapFromUnknownKeys(partMap, knownKeys...)),
 			})
 		case "image_url", "input_image":
 			imageURL, ok := normalizeResponsesImageURLForChat(partMap["image_url"])
@@ -503,10 +527,14 @@ func normalizeTypedResponsesContentPart(part core.ContentPart) (core.ContentPart
 		if part.Text == "" {
 			return core.ContentPart{}, false
 		}
+		extraFields := core.CloneUnknownJSONFields(part.ExtraFields)
+		if part.Type == "output_text" {
+			extraFields = part.ExtraFields.Without("annotations", "logprobs")
+		}
 		return core.ContentPart{
 			Type:        "text",
 			Text:        part.Text,
-			ExtraFields: core.CloneUnknownJSONFields(part.ExtraFields),
+			ExtraFields: extraFields,
 		}, true
 	case "image_url", "input_image":
 		if part.ImageURL == nil {
@@ -817,7 +845,7 @@ func buildResponsesMessageContent(content any) []core.ResponsesContentItem {
 			{
 				Type:        "output_text",
 				Text:        c,
-				Annotations: []json.RawMessage{},
+				Annotations: []core.ResponsesAnnotation{},
 			},
 		}
 	case []core.ContentPart:
@@ -837,7 +865,7 @@ func buildResponsesMessageContent(content any) []core.ResponsesContentItem {
 			{
 				Type:        "output_text",
 				Text:        text,
-				Annotations: []json.RawMessage{},
+				Annotations: []core.ResponsesAnnotation{},
 			},
 		}
 	}
@@ -851,7 +879,7 @@ func buildResponsesContentItemsFromParts(parts []core.ContentPart) []core.Respon
 			items = append(items, core.ResponsesContentItem{
 				Type:        "output_text",
 				Text:        part.Text,
-				Annotations: []json.RawMessage{},
+				Annotations: []core.ResponsesAnnotation{},
 			})
 		case "image_url":
 			if part.ImageURL == nil {
@@ -892,17 +920,38 @@ func buildResponsesContentItemsFromParts(parts []core.ContentPart) []core.Respon
 	return items
 }
 
+// attachChatAnnotations moves the annotations of a Chat Completions message
+// onto its first output_text item. Chat annotations index into the whole
+// message text, which that item carries.
+func attachChatAnnotations(items []core.ResponsesContentItem, msg core.ResponseMessage) {
+	raw := msg.ExtraFields.Lookup("annotations")
+	if len(raw) == 0 {
+		return
+	}
+	annotations, err := core.ParseChatAnnotations(raw)
+	if err != nil || len(annotations) == 0 {
+		return
+	}
+	for i := range items {
+		if items[i].Type == "output_text" {
+			items[i].Annotations = annotations
+			return
+		}
+	}
+}
+
 // BuildResponsesOutputItems converts a response message into Responses API output items.
 func BuildResponsesOutputItems(msg core.ResponseMessage) []core.ResponsesOutputItem {
 	output := make([]core.ResponsesOutputItem, 0, len(msg.ToolCalls)+1)
 	contentItems := buildResponsesMessageContent(msg.Content)
+	attachChatAnnotations(contentItems, msg)
 	if len(contentItems) > 0 || len(msg.ToolCalls) == 0 {
 		if len(contentItems) == 0 {
 			contentItems = []core.ResponsesContentItem{
 				{
 					Type:        "output_text",
 					Text:        "",
-					Annotations: []json.RawMessage{},
+					Annotations: []core.ResponsesAnnotation{},
 				},
 			}
 		}
@@ -940,7 +989,7 @@ func ConvertChatResponseToResponses(resp *core.ChatResponse) *core.ResponsesResp
 				{
 					Type:        "output_text",
 					Text:        "",
-					Annotations: []json.RawMessage{},
+					Annotations: []core.ResponsesAnnotation{},
 				},
 			},
 		},
@@ -948,6 +997,11 @@ func ConvertChatResponseToResponses(resp *core.ChatResponse) *core.ResponsesResp
 	if len(resp.Choices) > 0 {
 		output = BuildResponsesOutputItems(resp.Choices[0].Message)
 	}
+	// Some upstreams omit total_tokens; Responses clients expect it filled.
+	totalTokens := resp.Usage.TotalTokens
+	if totalTokens == 0 {
+		totalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	}
 
 	return &core.ResponsesResponse{
 		ID:        resp.ID,
@@ -960,7 +1014,7 @@ func ConvertChatResponseToResponses(resp *core.ChatResponse) *core.ResponsesResp
 		Usage: &core.ResponsesUsage{
 			InputTokens:             resp.Usage.PromptTokens,
 			OutputTokens:            resp.Usage.CompletionTokens,
-			TotalTokens:             resp.Usage.TotalTokens,
+			TotalTokens:             totalTokens,
 			PromptTokensDetails:     resp.Usage.PromptTokensDetails,
 			CompletionTokensDetails: resp.Usage.CompletionTokensDetails,
 			RawUsage:                resp.Usage.RawUsage,
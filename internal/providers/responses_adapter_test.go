@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"gomodel/internal/core"
+	"gomodel/internal/testfixtures"
 )
 
 type capturingChatProvider struct {
@@ -422,6 +423,90 @@ func TestConvertResponsesRequestToChat_DoesNotMergeAssistantMessagesWithExtraFie
 	}
 }
 
+// multiTurnToolExchangeInput is a Responses conversation replayed by a client
+// after a tool round trip, including the reasoning and message items the
+// previous response produced.
+const multiTurnToolExchangeInput = `[
+	{"role": "user", "content": [{"type": "input_text", "text": "What's the weather in Paris?"}]},
+	{"type": "reasoning", "id": "rs_1", "summary": []},
+	{"type": "function_call", "id": "fc_1", "call_id": "call_weather", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}", "status": "completed"},
+	{"type": "function_call", "id": "fc_2", "call_id": "call_time", "name": "get_time", "arguments": "{\"tz\":\"Europe/Paris\"}", "status": "completed"},
+	{"type": "function_call_output", "id": "fco_1", "call_id": "call_weather", "output": "{\"temp_c\":18}"},
+	{"type": "function_call_output", "call_id": "call_time", "output": "14:05"},
+	{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed", "content": [{"type": "output_text", "text": "It is 18C in Paris at 14:05.", "annotations": []}]},
+	{"role": "user", "content": "And tomorrow?"}
+]`
+
+func TestConvertResponsesRequestToChat_MultiTurnToolExchange(t *testing.T) {
+	var typed core.ResponsesRequest
+	if err := json.Unmarshal([]byte(`{"model":"gemini-2.5-flash","input":`+multiTurnToolExchangeInput+`}`), &typed); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	var untyped []any
+	if err := json.Unmarshal([]byte(multiTurnToolExchangeInput), &untyped); err != nil {
+		t.Fatalf("failed to decode input: %v", err)
+	}
+
+	for name, req := range map[string]*core.ResponsesRequest{
+		"typed elements": &typed,
+		"generic maps":   {Model: "gemini-2.5-flash", Input: untyped},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chatReq, err := ConvertResponsesRequestToChat(req)
+			if err != nil {
+				t.Fatalf("ConvertResponsesRequestToChat() error = %v", err)
+			}
+
+			msgs := chatReq.Messages
+			if len(msgs) != 6 {
+				t.Fatalf("len(Messages) = %d, want 6: %+v", len(msgs), msgs)
+			}
+			wantRoles := []string{"user", "assistant", "tool", "tool", "assistant", "user"}
+			for i, role := range wantRoles {
+				if msgs[i].Role != role {
+					t.Fatalf("Messages[%d].Role = %q, want %q", i, msgs[i].Role, role)
+				}
+			}
+
+			if msgs[0].Content != "What's the weather in Paris?" {
+				t.Errorf("user content = %#v", msgs[0].Content)
+			}
+			calls := msgs[1].ToolCalls
+			if len(calls) != 2 || !msgs[1].ContentNull {
+				t.Fatalf("assistant tool calls = %+v, content null = %v; want 2 calls with null content", calls, msgs[1].ContentNull)
+			}
+			if calls[0].ID != "call_weather" || calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Paris"}` {
+				t.Errorf("first tool call = %+v", calls[0])
+			}
+			if calls[1].ID != "call_time" || calls[1].Function.Name != "get_time" {
+				t.Errorf("second tool call = %+v", calls[1])
+			}
+			if msgs[2].ToolCallID != "call_weather" || msgs[2].Content != `{"temp_c":18}` {
+				t.Errorf("first tool result = %+v", msgs[2])
+			}
+			if msgs[3].ToolCallID != "call_time" || msgs[3].Content != "14:05" {
+				t.Errorf("second tool result = %+v", msgs[3])
+			}
+			if msgs[4].Content != "It is 18C in Paris at 14:05." {
+				t.Errorf("assistant reply = %#v, want flattened text", msgs[4].Content)
+			}
+			if msgs[5].Content != "And tomorrow?" {
+				t.Errorf("follow-up content = %#v", msgs[5].Content)
+			}
+
+			body, err := json.Marshal(msgs)
+			if err != nil {
+				t.Fatalf("failed to marshal messages: %v", err)
+			}
+			for _, leaked := range []string{`"msg_1"`, `"fco_1"`, `"annotations"`, `"rs_1"`} {
+				if strings.Contains(string(body), leaked) {
+					t.Errorf("chat messages leak Responses-only field %s: %s", leaked, body)
+				}
+			}
+		})
+	}
+}
+
 func TestConvertResponsesRequestToChat_RejectsWhitespaceOnlyMediaFields(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -732,6 +817,35 @@ func TestConvertChatResponseToResponses_PreservesStructuredAssistantContent(t *t
 	}
 }
 
+func TestConvertChatResponseToResponses_MapsMessageAnnotations(t *testing.T) {
+	resp := &core.ChatResponse{
+		ID:    "chatcmpl-cited",
+		Model: "test-model",
+		Choices: []core.Choice{
+			{
+				Message: core.ResponseMessage{
+					Role:    "assistant",
+					Content: "See example.",
+					ExtraFields: core.UnknownJSONFieldsFromMap(map[string]json.RawMessage{
+						"annotations": json.RawMessage(`[{"type":"url_citation","url_citation":{"url":"https://example.com","title":"Example","start_index":4,"end_index":11}}]`),
+					}),
+				},
+				FinishReason: "stop",
+			},
+		},
+	}
+
+	result := ConvertChatResponseToResponses(resp)
+
+	annotations := result.Output[0].Content[0].Annotations
+	if len(annotations) != 1 {
+		t.Fatalf("len(Annotations) = %d, want 1", len(annotations))
+	}
+	if got := annotations[0]; got.Type != core.AnnotationTypeURLCitation || got.URL != "https://example.com" || *got.StartIndex != 4 || *got.EndIndex != 11 {
+		t.Fatalf("annotation = %+v, want url_citation over [4,11)", got)
+	}
+}
+
 func TestConvertResponsesRequestToChat_RejectsNonSerializableFunctionCallOutputMap(t *testing.T) {
 	_, err := ConvertResponsesRequestToChat(&core.ResponsesRequest{
 		Model: "test-model",
@@ -868,3 +982,7 @@ func TestStreamResponsesViaChat_DoesNotInjectUsageWhenPolicyDisabled(t *testing.
 		t.Fatalf("captured StreamOptions = %+v, want nil", provider.capturedReq.StreamOptions)
 	}
 }
+
+func TestChatAdapterConversionProperties(t *testing.T) {
+	testfixtures.CheckConversionProperties(t, testfixtures.ChatAdapterSuite(ConvertResponsesRequestToChat, ConvertChatResponseToResponses))
+}
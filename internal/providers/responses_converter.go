@@ -2,15 +2,15 @@ package providers
 
 import (
 	"bytes"
-	"encoding/json"
 	"io"
-	"log/slog"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"gomodel/internal/core"
+	"gomodel/internal/sse"
 	"gomodel/internal/streaming"
 )
 
@@ -19,13 +19,14 @@ import (
 // Used by providers that have OpenAI-compatible streaming (Groq, Gemini, etc.)
 type OpenAIResponsesStreamConverter struct {
 	reader      io.ReadCloser
+	events      *sse.Reader
 	model       string
 	provider    string
 	responseID  string
+	upstreamID  string // ID of the upstream chat completion chunks
 	output      *ResponsesOutputEventState
 	toolCalls   map[int]*ResponsesOutputToolCallState
 	buffer      streaming.StreamBuffer
-	lineBuffer  streaming.StreamBuffer
 	closed      bool
 	sentCreate  bool
 	sentDone    bool
@@ -38,24 +39,13 @@ func NewOpenAIResponsesStreamConverter(reader io.ReadCloser, model, provider str
 	responseID := "resp_" + uuid.New().String()
 	return &OpenAIResponsesStreamConverter{
 		reader:     reader,
+		events:     sse.NewReader(reader),
 		model:      model,
 		provider:   provider,
 		responseID: responseID,
 		output:     NewResponsesOutputEventState(responseID),
 		toolCalls:  make(map[int]*ResponsesOutputToolCallState),
 		buffer:     streaming.NewStreamBuffer(4096),
-		lineBuffer: streaming.NewStreamBuffer(1024),
-	}
-}
-
-func normalizeToolCallIndex(value any) (int, bool) {
-	switch v := value.(type) {
-	case int:
-		return v, true
-	case float64:
-		return int(v), true
-	default:
-		return 0, false
 	}
 }
 
@@ -117,34 +107,27 @@ func (sc *OpenAIResponsesStreamConverter) completePendingToolCalls() string {
 	return out.String()
 }
 
-func (sc *OpenAIResponsesStreamConverter) handleToolCallDeltas(toolCalls []any) string {
+func (sc *OpenAIResponsesStreamConverter) handleToolCallDeltas(toolCalls []sse.ChatCompletionChunkToolCall) string {
 	var out bytes.Buffer
 
 	if sc.output.AssistantStarted() && !sc.output.AssistantDone() {
 		out.WriteString(sc.output.CompleteAssistantOutput(0))
 	}
 
-	for _, item := range toolCalls {
-		toolCall, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-		index, ok := normalizeToolCallIndex(toolCall["index"])
-		if !ok {
+	for _, toolCall := range toolCalls {
+		if toolCall.Index == nil {
 			continue
 		}
 
-		state := sc.ensureToolCallState(index)
-		if callID, _ := toolCall["id"].(string); callID != "" {
-			state.CallID = callID
+		state := sc.ensureToolCallState(*toolCall.Index)
+		if toolCall.ID != "" {
+			state.CallID = toolCall.ID
 		}
-
-		function, _ := toolCall["function"].(map[string]any)
-		if name, _ := function["name"].(string); name != "" {
-			state.Name = name
+		if toolCall.Function.Name != "" {
+			state.Name = toolCall.Function.Name
 		}
 
-		arguments, _ := function["arguments"].(string)
+		arguments := toolCall.Function.Arguments
 		hadStarted := state.Started
 		if arguments != "" {
 			_, _ = state.Arguments.WriteString(arguments)
@@ -172,6 +155,32 @@ func (sc *OpenAIResponsesStreamConverter) handleToolCallDeltas(toolCalls []any)
 	return out.String()
 }
 
+// completeResponse closes any open output items and renders the terminal
+// response.completed event followed by the [DONE] marker.
+func (sc *OpenAIResponsesStreamConverter) completeResponse() string {
+	var out bytes.Buffer
+	out.WriteString(sc.output.CompleteAssistantOutput(0))
+	out.WriteString(sc.completePendingToolCalls())
+	responseData := map[string]any{
+		"id":         sc.responseID,
+		"object":     "response",
+		"status":     "completed",
+		"model":      sc.model,
+		"provider":   sc.provider,
+		"created_at": time.Now().Unix(),
+	}
+	if sc.upstreamID != "" {
+		responseData["provider_response_id"] = sc.upstreamID
+	}
+	// Include usage data if captured from OpenAI stream
+	if sc.cachedUsage != nil {
+		responseData["usage"] = sc.cachedUsage
+	}
+	out.WriteString(sc.output.WriteResponseCompleted(responseData))
+	out.WriteString(sse.DoneMessage)
+	return out.String()
+}
+
 func (sc *OpenAIResponsesStreamConverter) Read(p []byte) (n int, err error) {
 	if sc.closed {
 		return 0, io.EOF
@@ -185,162 +194,28 @@ func (sc *OpenAIResponsesStreamConverter) Read(p []byte) (n int, err error) {
 	// Send response.created event first
 	if !sc.sentCreate {
 		sc.sentCreate = true
-		createdEvent := map[string]any{
-			"type": "response.created",
-			"response": map[string]any{
-				"id":         sc.responseID,
-				"object":     "response",
-				"status":     "in_progress",
-				"model":      sc.model,
-				"provider":   sc.provider,
-				"created_at": time.Now().Unix(),
-			},
-		}
-		jsonData, err := json.Marshal(createdEvent)
-		if err != nil {
-			slog.Error("failed to marshal response.created event", "error", err, "response_id", sc.responseID)
-			return 0, nil
-		}
-		sc.buffer.AppendString("event: response.created\ndata: ")
-		sc.buffer.AppendBytes(jsonData)
-		sc.buffer.AppendString("\n\n")
+		sc.buffer.AppendString(sc.output.WriteResponseCreated(map[string]any{
+			"id":         sc.responseID,
+			"object":     "response",
+			"status":     "in_progress",
+			"model":      sc.model,
+			"provider":   sc.provider,
+			"created_at": time.Now().Unix(),
+		}))
 		return sc.buffer.Read(p), nil
 	}
 
-	// Read from the underlying stream
-	tempBuf := make([]byte, 1024)
-	nr, readErr := sc.reader.Read(tempBuf)
-	if nr > 0 {
-		sc.lineBuffer.AppendBytes(tempBuf[:nr])
-
-		// Process complete lines
-		for {
-			unread := sc.lineBuffer.Unread()
-			idx := bytes.IndexByte(unread, '\n')
-			if idx == -1 {
-				break
-			}
-
-			line := unread[:idx]
-			sc.lineBuffer.Consume(idx + 1)
-
-			line = bytes.TrimSpace(line)
-			if len(line) == 0 {
-				continue
-			}
-
-			if after, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
-				data := after
-				if bytes.Equal(data, []byte("[DONE]")) {
-					// Send done event
-					if !sc.sentDone {
-						sc.sentDone = true
-						sc.buffer.AppendString(sc.output.CompleteAssistantOutput(0))
-						sc.buffer.AppendString(sc.completePendingToolCalls())
-						responseData := map[string]any{
-							"id":         sc.responseID,
-							"object":     "response",
-							"status":     "completed",
-							"model":      sc.model,
-							"provider":   sc.provider,
-							"created_at": time.Now().Unix(),
-						}
-						// Include usage data if captured from OpenAI stream
-						if sc.cachedUsage != nil {
-							responseData["usage"] = sc.cachedUsage
-						}
-						doneEvent := map[string]any{
-							"type":     "response.completed",
-							"response": responseData,
-						}
-						jsonData, err := json.Marshal(doneEvent)
-						if err != nil {
-							slog.Error("failed to marshal response.completed event", "error", err, "response_id", sc.responseID)
-							continue
-						}
-						sc.buffer.AppendString("event: response.completed\ndata: ")
-						sc.buffer.AppendBytes(jsonData)
-						sc.buffer.AppendString("\n\ndata: [DONE]\n\n")
-					}
-					continue
-				}
-
-				// Parse the chat completion chunk
-				var chunk map[string]any
-				if err := json.Unmarshal(data, &chunk); err != nil {
-					continue
-				}
-
-				// Capture usage data if present (OpenAI sends this in the final chunk)
-				if usage, ok := chunk["usage"].(map[string]any); ok {
-					sc.cachedUsage = usage
-				}
-
-				// Extract content delta
-				if choices, ok := chunk["choices"].([]any); ok && len(choices) > 0 {
-					if choice, ok := choices[0].(map[string]any); ok {
-						if delta, ok := choice["delta"].(map[string]any); ok {
-							if content, ok := delta["content"].(string); ok && content != "" {
-								sc.reserveAssistantOutput()
-								sc.buffer.AppendString(sc.output.StartAssistantOutput(0))
-								sc.output.AppendAssistantText(content)
-								deltaEvent := map[string]any{
-									"type":  "response.output_text.delta",
-									"delta": content,
-								}
-								jsonData, err := json.Marshal(deltaEvent)
-								if err != nil {
-									slog.Error("failed to marshal content delta event", "error", err, "response_id", sc.responseID)
-									continue
-								}
-								sc.buffer.AppendString("event: response.output_text.delta\ndata: ")
-								sc.buffer.AppendBytes(jsonData)
-								sc.buffer.AppendString("\n\n")
-							}
-							if toolCalls, ok := delta["tool_calls"].([]any); ok && len(toolCalls) > 0 {
-								sc.buffer.AppendString(sc.handleToolCallDeltas(toolCalls))
-							}
-						}
-						if finishReason, _ := choice["finish_reason"].(string); finishReason == "tool_calls" {
-							sc.buffer.AppendString(sc.completePendingToolCalls())
-						}
-					}
-				}
+	// Read from the underlying stream until an event produces output
+	for sc.buffer.Len() == 0 {
+		event, err := sc.events.Next()
+		if err != nil {
+			if err != io.EOF {
+				return 0, err
 			}
-		}
-	}
-
-	if readErr != nil {
-		if readErr == io.EOF {
 			// Send final done event if we haven't already
 			if !sc.sentDone {
 				sc.sentDone = true
-				sc.buffer.AppendString(sc.output.CompleteAssistantOutput(0))
-				sc.buffer.AppendString(sc.completePendingToolCalls())
-				responseData := map[string]any{
-					"id":         sc.responseID,
-					"object":     "response",
-					"status":     "completed",
-					"model":      sc.model,
-					"provider":   sc.provider,
-					"created_at": time.Now().Unix(),
-				}
-				// Include usage data if captured from OpenAI stream
-				if sc.cachedUsage != nil {
-					responseData["usage"] = sc.cachedUsage
-				}
-				doneEvent := map[string]any{
-					"type":     "response.completed",
-					"response": responseData,
-				}
-				jsonData, err := json.Marshal(doneEvent)
-				if err != nil {
-					slog.Error("failed to marshal final response.completed event", "error", err, "response_id", sc.responseID)
-				} else {
-					sc.buffer.AppendString("event: response.completed\ndata: ")
-					sc.buffer.AppendBytes(jsonData)
-					sc.buffer.AppendString("\n\ndata: [DONE]\n\n")
-				}
+				sc.buffer.AppendString(sc.completeResponse())
 			}
 
 			if sc.buffer.Len() > 0 {
@@ -352,15 +227,62 @@ func (sc *OpenAIResponsesStreamConverter) Read(p []byte) (n int, err error) {
 			_ = sc.reader.Close()
 			return 0, io.EOF
 		}
-		return 0, readErr
+		sc.handleEvent(event)
 	}
 
-	if sc.buffer.Len() > 0 {
-		return sc.buffer.Read(p), nil
+	return sc.buffer.Read(p), nil
+}
+
+func (sc *OpenAIResponsesStreamConverter) handleEvent(event sse.Event) {
+	if sse.IsDone(event.Data) {
+		// Send done event
+		if !sc.sentDone {
+			sc.sentDone = true
+			sc.buffer.AppendString(sc.completeResponse())
+		}
+		return
+	}
+
+	// Parse the chat completion chunk
+	chunk, err := sse.DecodeChatCompletionChunk(event.Data)
+	if err != nil {
+		return
 	}
 
-	// No data yet, try again
-	return 0, nil
+	if sc.upstreamID == "" {
+		sc.upstreamID = chunk.ID
+	}
+
+	// Capture usage data if present (OpenAI sends this in the final chunk)
+	if chunk.Usage != nil {
+		sc.cachedUsage = chunk.Usage
+	}
+
+	// Extract content delta
+	if len(chunk.Choices) == 0 {
+		return
+	}
+	choice := chunk.Choices[0]
+	if choice.Delta.Content != "" {
+		sc.reserveAssistantOutput()
+		sc.buffer.AppendString(sc.output.AssistantTextDelta(0, choice.Delta.Content))
+	}
+	if len(choice.Delta.Annotations) > 0 {
+		// Search-enabled chat models send the citations of the message text
+		// in a delta of their own, usually after the text.
+		if annotations, err := core.ParseChatAnnotations(choice.Delta.Annotations); err == nil && len(annotations) > 0 {
+			sc.reserveAssistantOutput()
+			for _, annotation := range annotations {
+				sc.buffer.AppendString(sc.output.AssistantAnnotation(0, annotation))
+			}
+		}
+	}
+	if len(choice.Delta.ToolCalls) > 0 {
+		sc.buffer.AppendString(sc.handleToolCallDeltas(choice.Delta.ToolCalls))
+	}
+	if choice.FinishReason == "tool_calls" {
+		sc.buffer.AppendString(sc.completePendingToolCalls())
+	}
 }
 
 func (sc *OpenAIResponsesStreamConverter) Close() error {
@@ -375,5 +297,4 @@ func (sc *OpenAIResponsesStreamConverter) Close() error {
 
 func (sc *OpenAIResponsesStreamConverter) releaseBuffers() {
 	sc.buffer.Release()
-	sc.lineBuffer.Release()
 }
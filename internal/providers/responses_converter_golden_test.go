@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	goldenTimestampPattern = regexp.MustCompile(`"(created|created_at)":\d+`)
+	goldenUUIDPattern      = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+)
+
+// normalizeStreamGolden replaces the wall-clock timestamps and generated IDs
+// that differ between runs. Everything else must match byte for byte.
+func normalizeStreamGolden(out string) string {
+	out = goldenTimestampPattern.ReplaceAllString(out, `"$1":0`)
+	return goldenUUIDPattern.ReplaceAllString(out, "00000000-0000-0000-0000-000000000000")
+}
+
+func TestOpenAIResponsesStreamConverter_Golden(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "chat_tool_calls.sse"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	goldenPath := filepath.Join("testdata", "chat_tool_calls.responses.golden")
+
+	for _, crlf := range []bool{false, true} {
+		input := string(fixture)
+		name := "lf"
+		if crlf {
+			input = strings.ReplaceAll(input, "\n", "\r\n")
+			name = "crlf"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			converter := NewOpenAIResponsesStreamConverter(io.NopCloser(strings.NewReader(input)), "gpt-4o-mini", "openai")
+			defer func() { _ = converter.Close() }()
+
+			raw, err := io.ReadAll(converter)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			got := normalizeStreamGolden(string(raw))
+			if os.Getenv("UPDATE_GOLDEN") == "1" {
+				if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("read golden: %v (run with UPDATE_GOLDEN=1 to create it)", err)
+			}
+			if got != string(want) {
+				t.Fatalf("stream output differs from %s:\n got %q\nwant %q", goldenPath, got, want)
+			}
+		})
+	}
+}
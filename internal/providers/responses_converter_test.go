@@ -3,6 +3,8 @@ package providers
 import (
 	"encoding/json"
 	"io"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -195,6 +197,237 @@ data: [DONE]
 	}
 }
 
+func TestOpenAIResponsesStreamConverter_EmitsAnnotations(t *testing.T) {
+	mockStream := `data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"test-model","choices":[{"index":0,"delta":{"content":"See example."},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"test-model","choices":[{"index":0,"delta":{"annotations":[{"type":"url_citation","url_citation":{"url":"https://example.com","title":"Example","start_index":4,"end_index":11}}]},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+
+	reader := io.NopCloser(strings.NewReader(mockStream))
+	converter := NewOpenAIResponsesStreamConverter(reader, "test-model", "openrouter")
+
+	raw, err := io.ReadAll(converter)
+	if err != nil {
+		t.Fatalf("failed to read from converter: %v", err)
+	}
+
+	var added, partDone map[string]any
+	for _, event := range parseTestSSEEvents(t, string(raw)) {
+		switch event.Name {
+		case "response.output_text.annotation.added":
+			added = event.Payload
+		case "response.content_part.done":
+			partDone = event.Payload
+		}
+	}
+	if added == nil {
+		t.Fatal("expected response.output_text.annotation.added event")
+	}
+	annotation, _ := added["annotation"].(map[string]any)
+	if annotation["type"] != "url_citation" || annotation["url"] != "https://example.com" || annotation["start_index"] != float64(4) {
+		t.Fatalf("annotation = %+v, want flattened url_citation", annotation)
+	}
+	if added["annotation_index"] != float64(0) || added["content_index"] != float64(0) {
+		t.Fatalf("annotation indices = (%v, %v), want (0, 0)", added["annotation_index"], added["content_index"])
+	}
+	part, _ := partDone["part"].(map[string]any)
+	if annotations, _ := part["annotations"].([]any); len(annotations) != 1 {
+		t.Fatalf("content_part.done annotations = %v, want one annotation", part["annotations"])
+	}
+}
+
+func TestOpenAIResponsesStreamConverter_EmitsEventEnvelope(t *testing.T) {
+	mockStream := `data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"test-model","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"test-model","choices":[{"index":0,"delta":{"content":" World"},"finish_reason":"stop"}]}
+
+data: [DONE]
+`
+
+	reader := io.NopCloser(strings.NewReader(mockStream))
+	converter := NewOpenAIResponsesStreamConverter(reader, "test-model", "groq")
+
+	raw, err := io.ReadAll(converter)
+	if err != nil {
+		t.Fatalf("failed to read from converter: %v", err)
+	}
+
+	events := parseTestSSEEvents(t, string(raw))
+	wantNames := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if len(events) != len(wantNames)+1 || !events[len(events)-1].Done {
+		t.Fatalf("event count = %d, want %d events followed by [DONE]", len(events), len(wantNames))
+	}
+
+	var messageID string
+	for i, want := range wantNames {
+		event := events[i]
+		if event.Name != want {
+			t.Fatalf("event[%d] = %q, want %q", i, event.Name, want)
+		}
+		if event.Payload["sequence_number"] != float64(i) {
+			t.Fatalf("event[%d] %s sequence_number = %v, want %d", i, event.Name, event.Payload["sequence_number"], i)
+		}
+		switch event.Name {
+		case "response.output_item.added":
+			item, _ := event.Payload["item"].(map[string]any)
+			messageID, _ = item["id"].(string)
+			if messageID == "" {
+				t.Fatal("expected assistant message item id")
+			}
+		case "response.content_part.added", "response.output_text.delta", "response.output_text.done", "response.content_part.done":
+			if event.Payload["item_id"] != messageID {
+				t.Fatalf("%s item_id = %v, want %q", event.Name, event.Payload["item_id"], messageID)
+			}
+			if event.Payload["output_index"] != float64(0) || event.Payload["content_index"] != float64(0) {
+				t.Fatalf("%s indices = (%v, %v), want (0, 0)", event.Name, event.Payload["output_index"], event.Payload["content_index"])
+			}
+		}
+	}
+	if text := events[6].Payload["text"]; text != "Hello World" {
+		t.Fatalf("response.output_text.done text = %v, want %q", text, "Hello World")
+	}
+}
+
+// parallelToolCallStream is a recorded OpenAI chat stream with two parallel
+// tool calls whose argument fragments split JSON strings and multi-byte runes.
+const parallelToolCallStream = `data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":null,"tool_calls":[{"index":0,"id":"call_weather","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Krak"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_time","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ów\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"Europe/Warsaw\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+
+// randomChunkReadCloser returns the underlying bytes in reads of random sizes
+// between 1 and maxChunk.
+type randomChunkReadCloser struct {
+	data     []byte
+	rng      *rand.Rand
+	maxChunk int
+}
+
+func (r *randomChunkReadCloser) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	size := min(1+r.rng.Intn(r.maxChunk), len(p), len(r.data))
+	n := copy(p, r.data[:size])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func (r *randomChunkReadCloser) Close() error { return nil }
+
+// semanticResponsesEvents drops per-run identifiers and timestamps so streams
+// converted from the same input can be compared.
+func semanticResponsesEvents(events []testSSEEvent) []testSSEEvent {
+	normalized := make([]testSSEEvent, 0, len(events))
+	for _, event := range events {
+		normalized = append(normalized, testSSEEvent{
+			Name:    event.Name,
+			Payload: stripVolatileFields(event.Payload),
+			Done:    event.Done,
+		})
+	}
+	return normalized
+}
+
+func stripVolatileFields(payload map[string]any) map[string]any {
+	if payload == nil {
+		return nil
+	}
+	stripped := make(map[string]any, len(payload))
+	for key, value := range payload {
+		switch key {
+		case "id", "item_id", "created_at":
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			value = stripVolatileFields(nested)
+		}
+		stripped[key] = value
+	}
+	return stripped
+}
+
+func FuzzOpenAIResponsesStreamConverter_ToolCallReadBoundaries(f *testing.F) {
+	for _, seed := range []int64{1, 2, 3, 42, 1337} {
+		for _, maxChunk := range []uint8{1, 2, 5, 17, 255} {
+			f.Add(seed, maxChunk, false, false)
+			f.Add(seed, maxChunk, true, false)
+			f.Add(seed, maxChunk, false, true)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64, maxChunk uint8, crlf, compactData bool) {
+		if maxChunk == 0 {
+			maxChunk = 1
+		}
+		fixture := parallelToolCallStream
+		if crlf {
+			fixture = strings.ReplaceAll(fixture, "\n", "\r\n")
+		}
+		if compactData {
+			fixture = strings.ReplaceAll(fixture, "data: ", "data:")
+		}
+
+		wholeRaw, err := io.ReadAll(NewOpenAIResponsesStreamConverter(io.NopCloser(strings.NewReader(fixture)), "gpt-4o-mini", "openai"))
+		if err != nil {
+			t.Fatalf("ReadAll(whole) error: %v", err)
+		}
+		want := semanticResponsesEvents(parseTestSSEEvents(t, string(wholeRaw)))
+
+		reader := &randomChunkReadCloser{data: []byte(fixture), rng: rand.New(rand.NewSource(seed)), maxChunk: int(maxChunk)}
+		chunkedRaw, err := io.ReadAll(NewOpenAIResponsesStreamConverter(reader, "gpt-4o-mini", "openai"))
+		if err != nil {
+			t.Fatalf("ReadAll(chunked) error: %v", err)
+		}
+		events := parseTestSSEEvents(t, string(chunkedRaw))
+		if got := semanticResponsesEvents(events); !reflect.DeepEqual(got, want) {
+			t.Fatalf("converted events differ across read boundaries:\n got %v\nwant %v", got, want)
+		}
+
+		arguments := map[string]string{}
+		outputIndexes := map[string]any{}
+		for _, event := range events {
+			if event.Name != "response.output_item.done" {
+				continue
+			}
+			item, _ := event.Payload["item"].(map[string]any)
+			callID, _ := item["call_id"].(string)
+			arguments[callID], _ = item["arguments"].(string)
+			outputIndexes[callID] = event.Payload["output_index"]
+		}
+		if arguments["call_weather"] != `{"city":"Kraków"}` || arguments["call_time"] != `{"tz":"Europe/Warsaw"}` {
+			t.Fatalf("reconstructed arguments = %#v", arguments)
+		}
+		if outputIndexes["call_weather"] != float64(0) || outputIndexes["call_time"] != float64(1) {
+			t.Fatalf("tool call output indexes = %#v, want call_weather=0 call_time=1", outputIndexes)
+		}
+	})
+}
+
 func parseTestSSEEvents(t *testing.T, raw string) []testSSEEvent {
 	t.Helper()
 
@@ -3,9 +3,11 @@ package providers
 import (
 	"bytes"
 	"io"
+
+	"gomodel/internal/sse"
 )
 
-var responsesDoneMarker = []byte("data: [DONE]\n\n")
+var responsesDoneMarker = []byte(sse.DoneMessage)
 
 var responsesDoneLine = []byte("data: [DONE]")
 
@@ -2,11 +2,13 @@ package providers
 
 import (
 	"encoding/json"
-	"fmt"
-	"log/slog"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+
+	"gomodel/internal/core"
+	"gomodel/internal/sse"
 )
 
 // ResponsesOutputToolCallState tracks one function_call item in a Responses stream.
@@ -22,13 +24,17 @@ type ResponsesOutputToolCallState struct {
 }
 
 // ResponsesOutputEventState manages assistant/tool output items for Responses streams.
+// It also owns the stream's sequence_number counter, so every event rendered
+// through WriteEvent carries a monotonically increasing sequence number.
 type ResponsesOutputEventState struct {
 	responseID         string
+	sequenceNumber     int
 	assistantReserved  bool
 	assistantStarted   bool
 	assistantDone      bool
 	assistantMessageID string
 	assistantText      strings.Builder
+	annotations        []core.ResponsesAnnotation
 }
 
 // NewResponsesOutputEventState creates a new Responses output-item state manager.
@@ -36,14 +42,58 @@ func NewResponsesOutputEventState(responseID string) *ResponsesOutputEventState
 	return &ResponsesOutputEventState{responseID: responseID}
 }
 
-// WriteEvent renders one SSE event in Responses API format.
+// WriteEvent renders one SSE event in Responses API format and stamps it with
+// the next sequence_number.
 func (s *ResponsesOutputEventState) WriteEvent(eventName string, payload map[string]any) string {
+	payload["sequence_number"] = s.nextSequenceNumber()
+	return s.renderEvent(eventName, payload)
+}
+
+func (s *ResponsesOutputEventState) nextSequenceNumber() int {
+	sequenceNumber := s.sequenceNumber
+	s.sequenceNumber++
+	return sequenceNumber
+}
+
+func (s *ResponsesOutputEventState) renderEvent(eventName string, payload any) string {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		slog.Error("failed to marshal responses stream event", "error", err, "event", eventName, "response_id", s.responseID)
+		providersLogger.Error("failed to marshal responses stream event", "error", err, "event", eventName, "response_id", s.responseID)
 		return ""
 	}
-	return fmt.Sprintf("event: %s\ndata: %s\n\n", eventName, jsonData)
+	return sse.FormatEvent(sse.Event{Type: eventName, Data: jsonData})
+}
+
+// responsesTextDeltaEvent is the typed response.output_text.delta payload.
+// Text deltas are the highest-volume event in a stream, so they skip the
+// generic map rendering used for lifecycle events.
+type responsesTextDeltaEvent struct {
+	Type           string `json:"type"`
+	ItemID         string `json:"item_id"`
+	OutputIndex    int    `json:"output_index"`
+	ContentIndex   int    `json:"content_index"`
+	Delta          string `json:"delta"`
+	SequenceNumber int    `json:"sequence_number"`
+}
+
+// WriteResponseCreated emits the response.created and response.in_progress
+// lifecycle events that open every Responses stream.
+func (s *ResponsesOutputEventState) WriteResponseCreated(response map[string]any) string {
+	return s.WriteEvent("response.created", map[string]any{
+		"type":     "response.created",
+		"response": response,
+	}) + s.WriteEvent("response.in_progress", map[string]any{
+		"type":     "response.in_progress",
+		"response": response,
+	})
+}
+
+// WriteResponseCompleted emits the terminal response.completed event.
+func (s *ResponsesOutputEventState) WriteResponseCompleted(response map[string]any) string {
+	return s.WriteEvent("response.completed", map[string]any{
+		"type":     "response.completed",
+		"response": response,
+	})
 }
 
 // ReserveAssistant marks that the assistant message output item occupies index 0.
@@ -71,6 +121,12 @@ func (s *ResponsesOutputEventState) AppendAssistantText(text string) {
 	_, _ = s.assistantText.WriteString(text)
 }
 
+// AssistantTextLength returns the length in characters of the assistant text
+// so far, the unit of annotation indexes.
+func (s *ResponsesOutputEventState) AssistantTextLength() int {
+	return utf8.RuneCountInString(s.assistantText.String())
+}
+
 // AssistantMessageItem renders the assistant message output item payload.
 func (s *ResponsesOutputEventState) AssistantMessageItem(status string, includeContent bool) map[string]any {
 	item := map[string]any{
@@ -81,18 +137,28 @@ func (s *ResponsesOutputEventState) AssistantMessageItem(status string, includeC
 		"content": []map[string]any{},
 	}
 	if includeContent {
-		item["content"] = []map[string]any{
-			{
-				"type":        "output_text",
-				"text":        s.assistantText.String(),
-				"annotations": []json.RawMessage{},
-			},
-		}
+		item["content"] = []map[string]any{s.assistantTextPart()}
 	}
 	return item
 }
 
-// StartAssistantOutput emits the assistant message output_item.added event once.
+func (s *ResponsesOutputEventState) assistantTextPart() map[string]any {
+	return map[string]any{
+		"type":        "output_text",
+		"text":        s.assistantText.String(),
+		"annotations": s.assistantAnnotations(),
+	}
+}
+
+func (s *ResponsesOutputEventState) assistantAnnotations() []core.ResponsesAnnotation {
+	if s.annotations == nil {
+		return []core.ResponsesAnnotation{}
+	}
+	return s.annotations
+}
+
+// StartAssistantOutput emits the assistant message output_item.added and
+// content_part.added events once.
 func (s *ResponsesOutputEventState) StartAssistantOutput(outputIndex int) string {
 	if s.assistantStarted {
 		return ""
@@ -105,16 +171,69 @@ func (s *ResponsesOutputEventState) StartAssistantOutput(outputIndex int) string
 		"type":         "response.output_item.added",
 		"item":         s.AssistantMessageItem("in_progress", false),
 		"output_index": outputIndex,
+	}) + s.WriteEvent("response.content_part.added", map[string]any{
+		"type":          "response.content_part.added",
+		"item_id":       s.assistantMessageID,
+		"output_index":  outputIndex,
+		"content_index": 0,
+		"part": map[string]any{
+			"type":        "output_text",
+			"text":        "",
+			"annotations": []core.ResponsesAnnotation{},
+		},
 	})
 }
 
-// CompleteAssistantOutput emits the assistant message output_item.done event once.
+// AssistantTextDelta starts the assistant output item if needed, records the
+// text, and emits a response.output_text.delta event for it.
+func (s *ResponsesOutputEventState) AssistantTextDelta(outputIndex int, text string) string {
+	prefix := s.StartAssistantOutput(outputIndex)
+	s.AppendAssistantText(text)
+	return prefix + s.renderEvent("response.output_text.delta", responsesTextDeltaEvent{
+		Type:           "response.output_text.delta",
+		ItemID:         s.assistantMessageID,
+		OutputIndex:    outputIndex,
+		Delta:          text,
+		SequenceNumber: s.nextSequenceNumber(),
+	})
+}
+
+// AssistantAnnotation starts the assistant output item if needed, records the
+// annotation, and emits a response.output_text.annotation.added event for it.
+func (s *ResponsesOutputEventState) AssistantAnnotation(outputIndex int, annotation core.ResponsesAnnotation) string {
+	prefix := s.StartAssistantOutput(outputIndex)
+	annotationIndex := len(s.annotations)
+	s.annotations = append(s.annotations, annotation)
+	return prefix + s.WriteEvent("response.output_text.annotation.added", map[string]any{
+		"type":             "response.output_text.annotation.added",
+		"item_id":          s.assistantMessageID,
+		"output_index":     outputIndex,
+		"content_index":    0,
+		"annotation_index": annotationIndex,
+		"annotation":       annotation,
+	})
+}
+
+// CompleteAssistantOutput emits the assistant text, content part, and
+// output_item.done events once.
 func (s *ResponsesOutputEventState) CompleteAssistantOutput(outputIndex int) string {
 	if !s.assistantReserved || s.assistantDone {
 		return ""
 	}
 	s.assistantDone = true
-	return s.StartAssistantOutput(outputIndex) + s.WriteEvent("response.output_item.done", map[string]any{
+	return s.StartAssistantOutput(outputIndex) + s.WriteEvent("response.output_text.done", map[string]any{
+		"type":          "response.output_text.done",
+		"item_id":       s.assistantMessageID,
+		"output_index":  outputIndex,
+		"content_index": 0,
+		"text":          s.assistantText.String(),
+	}) + s.WriteEvent("response.content_part.done", map[string]any{
+		"type":          "response.content_part.done",
+		"item_id":       s.assistantMessageID,
+		"output_index":  outputIndex,
+		"content_index": 0,
+		"part":          s.assistantTextPart(),
+	}) + s.WriteEvent("response.output_item.done", map[string]any{
 		"type":         "response.output_item.done",
 		"item":         s.AssistantMessageItem("completed", true),
 		"output_index": outputIndex,
@@ -68,9 +68,10 @@ func NewRouter(lookup core.ModelLookup) (*Router, error) {
 }
 
 // checkReady verifies the lookup has models available.
-// Returns ErrRegistryNotInitialized if no models are loaded.
+// Returns ErrRegistryNotInitialized if no models are loaded, unless a provider
+// serves unlisted models.
 func (r *Router) checkReady() error {
-	if r.lookup.ModelCount() == 0 {
+	if r.lookup.ModelCount() == 0 && !r.allowsUnlistedModels() {
 		return ErrRegistryNotInitialized
 	}
 	return nil
@@ -157,13 +158,13 @@ func (r *Router) resolveQualifiedSelector(requested core.RequestedModelSelector,
 		return core.ModelSelector{Provider: entry.ProviderName, Model: entry.Model.ID}, true
 	}
 
-	if requested.ExplicitProvider {
-		return core.ModelSelector{}, false
-	}
 	if r.hasConfiguredProviderName(providerSegment) {
 		return core.ModelSelector{}, false
 	}
 	if r.providerByTypeRegistry(providerSegment) != nil {
+		return r.resolveUnlistedSelector(selector.QualifiedModel())
+	}
+	if requested.ExplicitProvider {
 		return core.ModelSelector{}, false
 	}
 
@@ -178,7 +179,7 @@ func (r *Router) resolveQualifiedSelector(requested core.RequestedModelSelector,
 		return core.ModelSelector{Provider: entry.ProviderName, Model: entry.Model.ID}, true
 	}
 
-	return core.ModelSelector{}, false
+	return r.resolveUnlistedSelector(rawModelID)
 }
 
 func (r *Router) hasConfiguredProviderName(providerName string) bool {
@@ -229,7 +230,7 @@ func (r *Router) resolveProviderType(providerType string) (core.Provider, error)
 	if provider == nil {
 		return nil, core.NewInvalidRequestError(fmt.Sprintf("no provider found for provider type: %s", providerType), nil)
 	}
-	return provider, nil
+	return activateLazy(context.Background(), provider)
 }
 
 func (r *Router) resolveProviderSelector(providerSelector string) (core.Provider, string, error) {
@@ -241,14 +242,16 @@ func (r *Router) resolveProviderSelector(providerSelector string) (core.Provider
 		return nil, "", core.NewInvalidRequestError("provider is required", nil)
 	}
 	if provider := r.providerByTypeRegistry(providerSelector); provider != nil {
-		return provider, providerSelector, nil
+		provider, err := activateLazy(context.Background(), provider)
+		return provider, providerSelector, err
 	}
 	if provider := r.providerByNameRegistry(providerSelector); provider != nil {
 		providerType := strings.TrimSpace(r.GetProviderTypeForName(providerSelector))
 		if providerType == "" {
 			providerType = providerSelector
 		}
-		return provider, providerType, nil
+		provider, err := activateLazy(context.Background(), provider)
+		return provider, providerType, err
 	}
 	return nil, "", core.NewInvalidRequestError(fmt.Sprintf("no provider found for provider: %s", providerSelector), nil)
 }
@@ -341,16 +344,31 @@ func routeResolvedModelCall[Req any, Resp any](
 	ctx context.Context,
 	model string,
 	providerHint string,
+	capabilities []string,
 	buildForward func(core.ModelSelector) Req,
 	call func(context.Context, core.Provider, Req) (Resp, error),
 ) (Resp, string, error) {
 	p, selector, err := r.resolveProvider(model, providerHint)
+	if err != nil && r.InitializeLazyProviders(ctx, core.NewRequestedModelSelector(model, providerHint).RequestedQualifiedModel()) {
+		p, selector, err = r.resolveProvider(model, providerHint)
+	}
 	if err != nil {
 		var zero Resp
 		return zero, "", err
 	}
+	if residency := core.GetDataResidency(ctx); !r.SatisfiesDataResidency(selector, residency) {
+		var zero Resp
+		return zero, "", core.NewDataResidencyError(residency, selector.QualifiedModel())
+	}
+	if err := r.checkProbedCapabilities(selector, capabilities); err != nil {
+		var zero Resp
+		return zero, "", err
+	}
 
 	resp, err := call(ctx, p, buildForward(selector))
+	if err == nil {
+		r.registerUnlistedModel(selector)
+	}
 	return resp, r.GetProviderType(selector.QualifiedModel()), err
 }
 
@@ -359,10 +377,11 @@ func routeStampedModelResponse[Req any, Resp any](
 	ctx context.Context,
 	model string,
 	providerHint string,
+	capabilities []string,
 	buildForward func(core.ModelSelector) Req,
 	call func(context.Context, core.Provider, Req) (Resp, error),
 ) (Resp, error) {
-	resp, providerType, err := routeResolvedModelCall(r, ctx, model, providerHint, buildForward, call)
+	resp, providerType, err := routeResolvedModelCall(r, ctx, model, providerHint, capabilities, buildForward, call)
 	if err != nil {
 		var zero Resp
 		return zero, err
@@ -371,6 +390,10 @@ func routeStampedModelResponse[Req any, Resp any](
 }
 
 func routeNativeBatchCall[T any](r *Router, ctx context.Context, providerType string, call func(context.Context, core.NativeBatchProvider) (T, error)) (T, error) {
+	if err := r.requireProviderResidency(ctx, providerType); err != nil {
+		var zero T
+		return zero, err
+	}
 	bp, err := r.resolveNativeBatchProvider(providerType)
 	if err != nil {
 		var zero T
@@ -380,6 +403,10 @@ func routeNativeBatchCall[T any](r *Router, ctx context.Context, providerType st
 }
 
 func routeNativeFileCall[T any](r *Router, ctx context.Context, providerType string, call func(context.Context, core.NativeFileProvider) (T, error)) (T, error) {
+	if err := r.requireProviderResidency(ctx, providerType); err != nil {
+		var zero T
+		return zero, err
+	}
 	fp, err := r.resolveNativeFileProvider(providerType)
 	if err != nil {
 		var zero T
@@ -389,6 +416,10 @@ func routeNativeFileCall[T any](r *Router, ctx context.Context, providerType str
 }
 
 func routeNativeResponseLifecycleCall[T any](r *Router, ctx context.Context, providerType string, call func(context.Context, core.NativeResponseLifecycleProvider) (T, error)) (T, string, error) {
+	if err := r.requireProviderResidency(ctx, providerType); err != nil {
+		var zero T
+		return zero, "", err
+	}
 	rp, resolvedProviderType, err := r.resolveNativeResponseLifecycleProvider(providerType)
 	if err != nil {
 		var zero T
@@ -399,6 +430,10 @@ func routeNativeResponseLifecycleCall[T any](r *Router, ctx context.Context, pro
 }
 
 func routeNativeResponseUtilityCall[T any](r *Router, ctx context.Context, providerType string, call func(context.Context, core.NativeResponseUtilityProvider) (T, error)) (T, string, error) {
+	if err := r.requireProviderResidency(ctx, providerType); err != nil {
+		var zero T
+		return zero, "", err
+	}
 	rp, resolvedProviderType, err := r.resolveNativeResponseUtilityProvider(providerType)
 	if err != nil {
 		var zero T
@@ -440,17 +475,27 @@ func stampProvider[T any](resp T, providerType string) T {
 
 // Provider is gateway routing metadata on OpenAI-compatible request structs and
 // must be removed before dispatching to an upstream provider implementation.
-func forwardChatRequest(req *core.ChatRequest, selector core.ModelSelector) *core.ChatRequest {
+// forwardChatRequest rewrites the request for the selected provider. The
+// metadata field only reaches providers that accept it.
+func (r *Router) forwardChatRequest(req *core.ChatRequest, selector core.ModelSelector) *core.ChatRequest {
 	forwardReq := *req
 	forwardReq.Model = selector.Model
 	forwardReq.Provider = ""
+	if forwardReq.ExtraFields.Lookup("metadata") != nil && !core.ProviderSupportsRequestMetadata(r.GetProviderType(selector.QualifiedModel())) {
+		forwardReq.ExtraFields = forwardReq.ExtraFields.Without("metadata")
+	}
 	return &forwardReq
 }
 
-func forwardResponsesRequest(req *core.ResponsesRequest, selector core.ModelSelector) *core.ResponsesRequest {
+// forwardResponsesRequest rewrites the request for the selected provider. The
+// metadata field only reaches providers that accept it.
+func (r *Router) forwardResponsesRequest(req *core.ResponsesRequest, selector core.ModelSelector) *core.ResponsesRequest {
 	forwardReq := *req
 	forwardReq.Model = selector.Model
 	forwardReq.Provider = ""
+	if forwardReq.Metadata != nil && !core.ProviderSupportsRequestMetadata(r.GetProviderType(selector.QualifiedModel())) {
+		forwardReq.Metadata = nil
+	}
 	return &forwardReq
 }
 
@@ -499,8 +544,9 @@ func (r *Router) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*co
 		ctx,
 		req.Model,
 		req.Provider,
+		chatCapabilities(req, false),
 		func(selector core.ModelSelector) *core.ChatRequest {
-			return forwardChatRequest(req, selector)
+			return r.forwardChatRequest(req, selector)
 		},
 		callChatCompletion,
 	)
@@ -514,8 +560,9 @@ func (r *Router) StreamChatCompletion(ctx context.Context, req *core.ChatRequest
 		ctx,
 		req.Model,
 		req.Provider,
+		chatCapabilities(req, true),
 		func(selector core.ModelSelector) *core.ChatRequest {
-			return forwardChatRequest(req, selector)
+			return r.forwardChatRequest(req, selector)
 		},
 		func(ctx context.Context, provider core.Provider, forwardReq *core.ChatRequest) (io.ReadCloser, error) {
 			return provider.StreamChatCompletion(ctx, forwardReq)
@@ -550,8 +597,9 @@ func (r *Router) Responses(ctx context.Context, req *core.ResponsesRequest) (*co
 		ctx,
 		req.Model,
 		req.Provider,
+		[]string{core.CapabilityResponses},
 		func(selector core.ModelSelector) *core.ResponsesRequest {
-			return forwardResponsesRequest(req, selector)
+			return r.forwardResponsesRequest(req, selector)
 		},
 		callResponses,
 	)
@@ -565,8 +613,9 @@ func (r *Router) StreamResponses(ctx context.Context, req *core.ResponsesRequest
 		ctx,
 		req.Model,
 		req.Provider,
+		[]string{core.CapabilityResponses},
 		func(selector core.ModelSelector) *core.ResponsesRequest {
-			return forwardResponsesRequest(req, selector)
+			return r.forwardResponsesRequest(req, selector)
 		},
 		func(ctx context.Context, provider core.Provider, forwardReq *core.ResponsesRequest) (io.ReadCloser, error) {
 			return provider.StreamResponses(ctx, forwardReq)
@@ -582,6 +631,7 @@ func (r *Router) Embeddings(ctx context.Context, req *core.EmbeddingRequest) (*c
 		ctx,
 		req.Model,
 		req.Provider,
+		[]string{core.CapabilityEmbeddings},
 		func(selector core.ModelSelector) *core.EmbeddingRequest {
 			return forwardEmbeddingRequest(req, selector)
 		},
@@ -767,6 +817,9 @@ func (r *Router) NativeResponseProviderTypes() []string {
 
 // Passthrough routes an opaque provider-native request by provider type.
 func (r *Router) Passthrough(ctx context.Context, providerType string, req *core.PassthroughRequest) (*core.PassthroughResponse, error) {
+	if err := r.requireProviderResidency(ctx, providerType); err != nil {
+		return nil, err
+	}
 	pp, err := r.resolvePassthroughProvider(providerType)
 	if err != nil {
 		return nil, err
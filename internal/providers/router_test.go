@@ -64,6 +64,7 @@ func newTestRegistryWithModels(entries ...registryModelEntry) *ModelRegistry {
 			registry.models[entry.modelID] = info
 		}
 	}
+	registry.publishSnapshotLocked()
 	return registry
 }
 
@@ -1250,3 +1251,251 @@ func TestRouterPassthrough_UsesProviderRegistryWithoutModels(t *testing.T) {
 		t.Fatal("provider did not receive passthrough request")
 	}
 }
+
+func TestRouterResolveModelForResidency(t *testing.T) {
+	us := &mockProvider{name: "openai_us", chatResponse: &core.ChatResponse{ID: "us"}}
+	eu := &mockProvider{name: "openai_eu", chatResponse: &core.ChatResponse{ID: "eu"}}
+	registry := newTestRegistryWithModels(
+		registryModelEntry{provider: us, providerName: "openai_us", providerType: "openai", modelID: "gpt-4o"},
+		registryModelEntry{provider: eu, providerName: "openai_eu", providerType: "openai", modelID: "gpt-4o"},
+	)
+	registry.SetProviderDataResidency("openai_us", "us")
+	registry.SetProviderDataResidency("openai_eu", "eu")
+	router, _ := NewRouter(registry)
+
+	selector, changed, err := router.ResolveModelForResidency(core.NewRequestedModelSelector("gpt-4o", ""), "eu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed || selector.Provider != "openai_eu" || selector.Model != "gpt-4o" {
+		t.Fatalf("selector = %+v changed=%v, want openai_eu/gpt-4o changed", selector, changed)
+	}
+
+	selector, _, err = router.ResolveModelForResidency(core.NewRequestedModelSelector("gpt-4o", ""), "any")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := router.GetProviderName(selector.QualifiedModel()); got != "openai_us" {
+		t.Fatalf("provider for residency any = %q, want openai_us", got)
+	}
+
+	_, _, err = router.ResolveModelForResidency(core.NewRequestedModelSelector("openai_us/gpt-4o", ""), "eu")
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || !core.IsDataResidencyError(gatewayErr) {
+		t.Fatalf("pinned provider error = %v, want data residency error", err)
+	}
+	if gatewayErr.HTTPStatusCode() != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", gatewayErr.HTTPStatusCode())
+	}
+
+	_, _, err = router.ResolveModelForResidency(core.NewRequestedModelSelector("gpt-4o", ""), "apac")
+	if !errors.As(err, &gatewayErr) || !core.IsDataResidencyError(gatewayErr) {
+		t.Fatalf("unsatisfiable error = %v, want data residency error", err)
+	}
+}
+
+func TestRouterChatCompletion_RejectsProviderOutsideDataResidency(t *testing.T) {
+	us := &mockProvider{name: "openai_us", chatResponse: &core.ChatResponse{ID: "us"}}
+	registry := newTestRegistryWithModels(
+		registryModelEntry{provider: us, providerName: "openai_us", providerType: "openai", modelID: "gpt-4o"},
+	)
+	registry.SetProviderDataResidency("openai_us", "us")
+	router, _ := NewRouter(registry)
+
+	ctx := core.WithDataResidency(context.Background(), "eu")
+	_, err := router.ChatCompletion(ctx, &core.ChatRequest{Model: "gpt-4o"})
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || !core.IsDataResidencyError(gatewayErr) {
+		t.Fatalf("error = %v, want data residency error", err)
+	}
+	if us.lastChatReq != nil {
+		t.Fatal("provider outside the data residency must not be called")
+	}
+
+	if _, err := router.Passthrough(ctx, "openai", &core.PassthroughRequest{Method: http.MethodGet, Endpoint: "models"}); !errors.As(err, &gatewayErr) || !core.IsDataResidencyError(gatewayErr) {
+		t.Fatalf("passthrough error = %v, want data residency error", err)
+	}
+
+	resp, err := router.ChatCompletion(core.WithDataResidency(context.Background(), "us"), &core.ChatRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ID != "us" {
+		t.Fatalf("response ID = %q, want us", resp.ID)
+	}
+}
+
+func TestRouterRejectsCapabilitiesAppliedProbeFoundUnsupported(t *testing.T) {
+	limited := &mockProvider{name: "limited", chatResponse: &core.ChatResponse{ID: "limited"}}
+	registry := newTestRegistryWithModels(
+		registryModelEntry{provider: limited, providerName: "limited", providerType: "openai", modelID: "tiny-chat"},
+	)
+	router, _ := NewRouter(registry)
+	toolsRequest := &core.ChatRequest{Model: "tiny-chat", Tools: []map[string]any{{"type": "function"}}}
+	profile := &core.CapabilityProfile{
+		Provider: "limited",
+		Model:    "tiny-chat",
+		Checks: []core.CapabilityCheck{
+			{Capability: core.CapabilityChat, Status: core.CapabilitySupported},
+			{Capability: core.CapabilityTools, Status: core.CapabilityUnsupported},
+		},
+	}
+
+	// A profile that was not applied is informational only.
+	registry.RecordCapabilityProfile("limited", profile)
+	if _, err := router.ChatCompletion(context.Background(), toolsRequest); err != nil {
+		t.Fatalf("unapplied profile: unexpected error: %v", err)
+	}
+
+	limited.lastChatReq = nil
+	applied := *profile
+	applied.Applied = true
+	registry.RecordCapabilityProfile("limited", &applied)
+	_, err := router.ChatCompletion(context.Background(), toolsRequest)
+	var gatewayErr *core.GatewayError
+	if !errors.As(err, &gatewayErr) || gatewayErr.HTTPStatusCode() != http.StatusBadRequest || gatewayErr.Code == nil || *gatewayErr.Code != "unsupported_capability" {
+		t.Fatalf("error = %v, want a 400 unsupported_capability error", err)
+	}
+	if limited.lastChatReq != nil {
+		t.Fatal("provider must not be called for an unsupported capability")
+	}
+
+	if resp, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "tiny-chat"}); err != nil || resp.ID != "limited" {
+		t.Fatalf("plain chat = %v, %v; want it served", resp, err)
+	}
+}
+
+func TestRouterStripsMetadataForProvidersWithoutSupport(t *testing.T) {
+	openai := &mockProvider{name: "openai", chatResponse: &core.ChatResponse{ID: "openai"}, responsesResponse: &core.ResponsesResponse{ID: "openai"}}
+	anthropic := &mockProvider{name: "anthropic", chatResponse: &core.ChatResponse{ID: "anthropic"}, responsesResponse: &core.ResponsesResponse{ID: "anthropic"}}
+	registry := newTestRegistryWithModels(
+		registryModelEntry{provider: openai, providerName: "openai", providerType: "openai", modelID: "gpt-4o"},
+		registryModelEntry{provider: anthropic, providerName: "anthropic", providerType: "anthropic", modelID: "claude-sonnet-4"},
+	)
+	router, _ := NewRouter(registry)
+
+	for _, tt := range []struct {
+		model        string
+		provider     *mockProvider
+		wantMetadata bool
+	}{
+		{model: "gpt-4o", provider: openai, wantMetadata: true},
+		{model: "claude-sonnet-4", provider: anthropic, wantMetadata: false},
+	} {
+		var chatReq core.ChatRequest
+		if err := json.Unmarshal([]byte(`{"model":"`+tt.model+`","messages":[{"role":"user","content":"hi"}],"metadata":{"team":"growth"}}`), &chatReq); err != nil {
+			t.Fatalf("decode chat request: %v", err)
+		}
+		if _, err := router.ChatCompletion(context.Background(), &chatReq); err != nil {
+			t.Fatalf("%s chat: unexpected error: %v", tt.model, err)
+		}
+		if got := tt.provider.lastChatReq.ExtraFields.Lookup("metadata") != nil; got != tt.wantMetadata {
+			t.Fatalf("%s chat metadata forwarded = %v, want %v", tt.model, got, tt.wantMetadata)
+		}
+		if chatReq.ExtraFields.Lookup("metadata") == nil {
+			t.Fatalf("%s chat: caller request metadata was modified", tt.model)
+		}
+
+		responsesReq := &core.ResponsesRequest{Model: tt.model, Input: "hi", Metadata: map[string]string{"team": "growth"}}
+		if _, err := router.Responses(context.Background(), responsesReq); err != nil {
+			t.Fatalf("%s responses: unexpected error: %v", tt.model, err)
+		}
+		if got := tt.provider.lastResponsesReq.Metadata != nil; got != tt.wantMetadata {
+			t.Fatalf("%s responses metadata forwarded = %v, want %v", tt.model, got, tt.wantMetadata)
+		}
+	}
+}
+
+func TestRouterUnlistedModels_ColdStartWithEmptyRegistry(t *testing.T) {
+	local := &mockProvider{name: "local", chatResponse: &core.ChatResponse{ID: "local"}}
+	openai := &mockProvider{name: "openai", chatResponse: &core.ChatResponse{ID: "openai"}}
+	registry := NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(local, "local", "ollama")
+	registry.RegisterProviderWithNameAndType(openai, "openai", "openai")
+	registry.SetProviderAllowUnlistedModels("local", true)
+	router, _ := NewRouter(registry)
+
+	if !router.Supports("llama3") || !router.IsUnlistedModel("llama3") {
+		t.Fatal("unlisted model not routed to the provider that allows it")
+	}
+	if got := router.GetProviderName("llama3"); got != "local" {
+		t.Fatalf("GetProviderName() = %q, want local", got)
+	}
+	if router.Supports("openai/gpt-4o") {
+		t.Fatal("unlisted model routed to a provider that does not allow it")
+	}
+
+	for _, model := range []string{"llama3", "ollama/qwen2", "local/mistral"} {
+		resp, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: model})
+		if err != nil {
+			t.Fatalf("ChatCompletion(%s): unexpected error: %v", model, err)
+		}
+		if resp.ID != "local" || resp.Provider != "ollama" {
+			t.Fatalf("ChatCompletion(%s) = %+v, want the local provider", model, resp)
+		}
+	}
+	if got := local.lastChatReq.Model; got != "mistral" {
+		t.Fatalf("forwarded model = %q, want mistral", got)
+	}
+
+	_, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "openai/gpt-4o"})
+	var gwErr *core.GatewayError
+	if !errors.As(err, &gwErr) || gwErr.HTTPStatusCode() != http.StatusNotFound {
+		t.Fatalf("expected 404 for a provider without allow_unlisted_models, got %v", err)
+	}
+}
+
+func TestRouterUnlistedModels_RegistersModelAfterSuccess(t *testing.T) {
+	local := &mockProvider{name: "local", err: core.NewProviderError("ollama", http.StatusNotFound, "model not found", nil)}
+	registry := NewModelRegistry()
+	registry.RegisterProviderWithNameAndType(local, "local", "ollama")
+	registry.SetProviderAllowUnlistedModels("local", true)
+	router, _ := NewRouter(registry)
+
+	if _, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "missing"}); err == nil {
+		t.Fatal("expected the provider error")
+	}
+	if registry.ModelCount() != 0 {
+		t.Fatalf("failed request registered a model: count = %d", registry.ModelCount())
+	}
+
+	local.err = nil
+	local.chatResponse = &core.ChatResponse{ID: "local"}
+	if _, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: "llama3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if router.IsUnlistedModel("llama3") {
+		t.Fatal("model still unlisted after a successful request")
+	}
+	resp, err := router.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels(): unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != "local/llama3" {
+		t.Fatalf("ListModels() = %+v, want local/llama3", resp.Data)
+	}
+}
+
+func TestRouterUnlistedModels_ConflictUsesFirstConfiguredProvider(t *testing.T) {
+	first := &mockProvider{name: "first", chatResponse: &core.ChatResponse{ID: "first"}}
+	second := &mockProvider{name: "second", chatResponse: &core.ChatResponse{ID: "second"}}
+	listed := &mockProvider{name: "listed", chatResponse: &core.ChatResponse{ID: "listed"}}
+	registry := newTestRegistryWithModels(
+		registryModelEntry{provider: first, providerName: "a-local", providerType: "ollama", modelID: "llama3"},
+		registryModelEntry{provider: second, providerName: "b-local", providerType: "ollama", modelID: "qwen2"},
+		registryModelEntry{provider: listed, providerName: "openai", providerType: "openai", modelID: "gpt-4o"},
+	)
+	registry.SetProviderAllowUnlistedModels("a-local", true)
+	registry.SetProviderAllowUnlistedModels("b-local", true)
+	router, _ := NewRouter(registry)
+
+	for model, want := range map[string]string{"mistral": "first", "qwen2": "second", "gpt-4o": "listed", "b-local/phi3": "second"} {
+		resp, err := router.ChatCompletion(context.Background(), &core.ChatRequest{Model: model})
+		if err != nil {
+			t.Fatalf("ChatCompletion(%s): unexpected error: %v", model, err)
+		}
+		if resp.ID != want {
+			t.Fatalf("ChatCompletion(%s) served by %q, want %q", model, resp.ID, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package providers
+
+import (
+	"maps"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// unlistedModelLookup is implemented by lookups that route models missing
+// from the provider listings to providers configured with
+// allow_unlisted_models.
+type unlistedModelLookup interface {
+	AllowsUnlistedModels() bool
+	UnlistedModelSelector(model string) (core.ModelSelector, bool)
+	RegisterUnlistedModel(model string) bool
+}
+
+// SetProviderAllowUnlistedModels lets a configured provider instance serve
+// models missing from its model listing, for providers whose listing is
+// incomplete or unavailable.
+func (r *ModelRegistry) SetProviderAllowUnlistedModels(providerName string, allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	providerName = strings.TrimSpace(providerName)
+	if providerName == "" {
+		return
+	}
+	if r.unlistedAllowed == nil {
+		r.unlistedAllowed = make(map[string]bool)
+	}
+	if allow {
+		r.unlistedAllowed[providerName] = true
+	} else {
+		delete(r.unlistedAllowed, providerName)
+	}
+}
+
+// AllowsUnlistedModels reports whether any registered provider serves models
+// missing from its listing.
+func (r *ModelRegistry) AllowsUnlistedModels() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.unlistedAllowed) > 0
+}
+
+// UnlistedModelSelector returns the provider instance and model ID a model
+// missing from every listing is routed to. ok is false for listed models and
+// for models no provider accepts.
+func (r *ModelRegistry) UnlistedModelSelector(model string) (core.ModelSelector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.findModelLocked(model) != nil {
+		return core.ModelSelector{}, false
+	}
+	info := r.unlistedModelLocked(model)
+	if info == nil {
+		return core.ModelSelector{}, false
+	}
+	return core.ModelSelector{Provider: info.ProviderName, Model: info.Model.ID}, true
+}
+
+// RegisterUnlistedModel adds an unlisted model that a provider has served
+// successfully to the registry, so model listings include it. Learned models
+// survive refreshes whose listing still omits them. It reports whether the
+// model was added.
+func (r *ModelRegistry) RegisterUnlistedModel(model string) bool {
+	r.mu.RLock()
+	listed := r.findModelLocked(model) != nil
+	r.mu.RUnlock()
+	if listed {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findModelLocked(model) != nil {
+		return false
+	}
+	info := r.unlistedModelLocked(model)
+	if info == nil {
+		return false
+	}
+	if r.unlistedLearned == nil {
+		r.unlistedLearned = make(map[string]map[string]core.Model)
+	}
+	if r.unlistedLearned[info.ProviderName] == nil {
+		r.unlistedLearned[info.ProviderName] = make(map[string]core.Model)
+	}
+	r.unlistedLearned[info.ProviderName][info.Model.ID] = info.Model
+	addModelInfo(r.models, r.modelsByProvider, info)
+	r.publishSnapshotLocked()
+
+	registryLogger.Info("registered unlisted model after successful request",
+		"model", info.Model.ID,
+		"provider", info.ProviderName,
+	)
+	return true
+}
+
+// unlistedModelLocked picks the provider serving a model missing from every
+// listing. A configured provider name prefix selects that provider and a
+// provider type prefix the first provider of that type allowing unlisted
+// models; anything else goes to the first provider allowing unlisted models
+// in registration order, logging the conflict once when several do.
+// Callers must hold r.mu.
+func (r *ModelRegistry) unlistedModelLocked(model string) *ModelInfo {
+	if len(r.unlistedAllowed) == 0 {
+		return nil
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil
+	}
+
+	providerSegment, modelID := splitModelSelector(model)
+	if providerSegment != "" {
+		if r.hasConfiguredProviderNameLocked(providerSegment) {
+			if !r.unlistedAllowed[providerSegment] {
+				return nil
+			}
+			for _, provider := range r.providers {
+				if r.providerNames[provider] == providerSegment {
+					return r.unlistedModelInfoLocked(provider, modelID)
+				}
+			}
+			return nil
+		}
+		isType := false
+		for _, provider := range r.providers {
+			if strings.TrimSpace(r.providerTypes[provider]) != providerSegment {
+				continue
+			}
+			isType = true
+			if r.unlistedAllowed[r.providerNames[provider]] {
+				return r.unlistedModelInfoLocked(provider, modelID)
+			}
+		}
+		if isType {
+			return nil
+		}
+	}
+
+	var claimed core.Provider
+	var claimants []string
+	for _, provider := range r.providers {
+		providerName := r.providerNames[provider]
+		if !r.unlistedAllowed[providerName] {
+			continue
+		}
+		if claimed == nil {
+			claimed = provider
+		}
+		claimants = append(claimants, providerName)
+	}
+	if claimed == nil {
+		return nil
+	}
+	if len(claimants) > 1 {
+		if _, logged := r.unlistedConflicts.LoadOrStore(model, struct{}{}); !logged {
+			registryLogger.Warn("several providers allow unlisted model, using the first configured",
+				"model", model,
+				"provider", claimants[0],
+				"candidates", claimants,
+			)
+		}
+	}
+	return r.unlistedModelInfoLocked(claimed, model)
+}
+
+func (r *ModelRegistry) unlistedModelInfoLocked(provider core.Provider, modelID string) *ModelInfo {
+	providerName := r.providerNames[provider]
+	return &ModelInfo{
+		Model: core.Model{
+			ID:      modelID,
+			Object:  "model",
+			OwnedBy: providerName,
+		},
+		Provider:     provider,
+		ProviderName: providerName,
+		ProviderType: r.providerTypes[provider],
+	}
+}
+
+// learnedModelsLocked copies the unlisted models learned from successful
+// requests. Callers must hold r.mu.
+func (r *ModelRegistry) learnedModelsLocked() map[string]map[string]core.Model {
+	learned := make(map[string]map[string]core.Model, len(r.unlistedLearned))
+	for providerName, models := range r.unlistedLearned {
+		learned[providerName] = maps.Clone(models)
+	}
+	return learned
+}
+
+// mergeLearnedModels adds the unlisted models learned from successful
+// requests to freshly fetched model maps, returning how many were added.
+func mergeLearnedModels(
+	learned map[string]map[string]core.Model,
+	providers []core.Provider,
+	providerNames, providerTypes map[core.Provider]string,
+	models map[string]*ModelInfo,
+	modelsByProvider map[string]map[string]*ModelInfo,
+) int {
+	added := 0
+	for _, provider := range providers {
+		providerName := providerNames[provider]
+		for modelID, model := range learned[providerName] {
+			if _, exists := modelsByProvider[providerName][modelID]; exists {
+				continue
+			}
+			if addModelInfo(models, modelsByProvider, &ModelInfo{
+				Model:        model,
+				Provider:     provider,
+				ProviderName: providerName,
+				ProviderType: providerTypes[provider],
+			}) {
+				added++
+			}
+		}
+	}
+	return added
+}
+
+// addModelInfo adds info to the model maps. The first provider keeps
+// unqualified lookups; it reports whether info claimed the unqualified ID.
+func addModelInfo(models map[string]*ModelInfo, modelsByProvider map[string]map[string]*ModelInfo, info *ModelInfo) bool {
+	if modelsByProvider[info.ProviderName] == nil {
+		modelsByProvider[info.ProviderName] = make(map[string]*ModelInfo)
+	}
+	modelsByProvider[info.ProviderName][info.Model.ID] = info
+	if _, exists := models[info.Model.ID]; exists {
+		return false
+	}
+	models[info.Model.ID] = info
+	return true
+}
+
+// IsUnlistedModel reports whether model resolves to a provider only because
+// that provider allows models missing from its listing.
+func (r *Router) IsUnlistedModel(model string) bool {
+	lookup, ok := r.lookup.(unlistedModelLookup)
+	if !ok {
+		return false
+	}
+	selector, _, err := r.ResolveModel(core.NewRequestedModelSelector(model, ""))
+	if err != nil {
+		return false
+	}
+	_, unlisted := lookup.UnlistedModelSelector(selector.QualifiedModel())
+	return unlisted
+}
+
+// allowsUnlistedModels reports whether the lookup can route models before
+// any provider listed them.
+func (r *Router) allowsUnlistedModels() bool {
+	lookup, ok := r.lookup.(unlistedModelLookup)
+	return ok && lookup.AllowsUnlistedModels()
+}
+
+// resolveUnlistedSelector maps model to the configured provider instance that
+// serves it while unlisted.
+func (r *Router) resolveUnlistedSelector(model string) (core.ModelSelector, bool) {
+	lookup, ok := r.lookup.(unlistedModelLookup)
+	if !ok {
+		return core.ModelSelector{}, false
+	}
+	return lookup.UnlistedModelSelector(model)
+}
+
+// registerUnlistedModel records an unlisted model after a provider served it.
+func (r *Router) registerUnlistedModel(selector core.ModelSelector) {
+	if lookup, ok := r.lookup.(unlistedModelLookup); ok {
+		lookup.RegisterUnlistedModel(selector.QualifiedModel())
+	}
+}
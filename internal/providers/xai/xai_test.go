@@ -2,18 +2,25 @@ package xai
 
 import (
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
 	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
+const testModel = "grok-2"
+
+// bearerJSON holds the headers every xAI call must carry.
+var bearerJSON = testfixtures.Route{
+	Headers:        map[string]string{"Content-Type": "application/json"},
+	HeaderPrefixes: map[string]string{"Authorization": "Bearer "},
+}
+
 func TestNew(t *testing.T) {
 	apiKey := "test-api-key"
 	// Use NewWithHTTPClient to get concrete type for internal testing
@@ -51,27 +58,9 @@ func TestNewWithHTTPClient(t *testing.T) {
 	const customHeaderKey = "X-Custom-Test-Header"
 	const customHeaderVal = "custom-test-value"
 
-	var receivedHeader string
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Capture the custom header to verify custom client was used
-		receivedHeader = r.Header.Get(customHeaderKey)
-
-		// Return a valid chat completion response
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{
-			"id": "chatcmpl-123",
-			"object": "chat.completion",
-			"created": 1677652288,
-			"model": "grok-2",
-			"choices": [{
-				"index": 0,
-				"message": {"role": "assistant", "content": "Hello!"},
-				"finish_reason": "stop"
-			}],
-			"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}
-		}`))
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{
+		Body: testfixtures.ChatCompletion{Model: testModel, Content: "Hello!", PromptTokens: 10, CompletionTokens: 5}.JSON(),
+	})
 
 	// Create a custom HTTP client with a RoundTripper that injects a header
 	customClient := &http.Client{
@@ -101,14 +90,7 @@ func TestNewWithHTTPClient(t *testing.T) {
 	provider.SetBaseURL(server.URL)
 
 	// Make a request to verify custom client is wired correctly
-	req := &core.ChatRequest{
-		Model: "grok-2",
-		Messages: []core.Message{
-			{Role: "user", Content: "Hello"},
-		},
-	}
-
-	resp, err := provider.ChatCompletion(context.Background(), req)
+	resp, err := provider.ChatCompletion(context.Background(), testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -122,7 +104,8 @@ func TestNewWithHTTPClient(t *testing.T) {
 	}
 
 	// Verify the custom header was injected by our custom RoundTripper
-	if receivedHeader != customHeaderVal {
+	requests := server.Requests()
+	if receivedHeader := requests[0].Header.Get(customHeaderKey); receivedHeader != customHeaderVal {
 		t.Errorf("custom header = %q, want %q (custom HTTP client not wired correctly)", receivedHeader, customHeaderVal)
 	}
 }
@@ -136,27 +119,9 @@ func TestChatCompletion(t *testing.T) {
 		checkResponse func(*testing.T, *core.ChatResponse)
 	}{
 		{
-			name:       "successful request",
-			statusCode: http.StatusOK,
-			responseBody: `{
-				"id": "chatcmpl-123",
-				"object": "chat.completion",
-				"created": 1677652288,
-				"model": "grok-2",
-				"choices": [{
-					"index": 0,
-					"message": {
-						"role": "assistant",
-						"content": "Hello! How can I help you today?"
-					},
-					"finish_reason": "stop"
-				}],
-				"usage": {
-					"prompt_tokens": 10,
-					"completion_tokens": 20,
-					"total_tokens": 30
-				}
-			}`,
+			name:          "successful request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ChatCompletion{Model: testModel}.JSON(),
 			expectedError: false,
 			checkResponse: func(t *testing.T, resp *core.ChatResponse) {
 				if resp.ID != "chatcmpl-123" {
@@ -185,61 +150,37 @@ func TestChatCompletion(t *testing.T) {
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 		{
 			name:          "rate limit error",
 			statusCode:    http.StatusTooManyRequests,
-			responseBody:  `{"error": {"message": "Rate limit exceeded"}}`,
+			responseBody:  testfixtures.OpenAIError("Rate limit exceeded"),
 			expectedError: true,
 		},
 		{
 			name:          "server error",
 			statusCode:    http.StatusInternalServerError,
-			responseBody:  `{"error": {"message": "Internal server error"}}`,
+			responseBody:  testfixtures.OpenAIError("Internal server error"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ChatRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			route := bearerJSON
+			route.Check = func(t testing.TB, _ *http.Request, body []byte) {
+				testfixtures.DecodeJSON[core.ChatRequest](t, body)
+			}
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ChatRequest{
-				Model: "grok-2",
-				Messages: []core.Message{
-					{Role: "user", Content: "Hello"},
-				},
-			}
-
-			resp, err := provider.ChatCompletion(context.Background(), req)
+			resp, err := provider.ChatCompletion(context.Background(), testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -265,65 +206,36 @@ func TestStreamChatCompletion(t *testing.T) {
 		expectedError bool
 	}{
 		{
-			name:       "successful streaming request",
-			statusCode: http.StatusOK,
-			responseBody: `data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"grok-2","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}
-
-data: {"id":"chatcmpl-123","object":"chat.completion.chunk","created":1677652288,"model":"grok-2","choices":[{"index":0,"delta":{"content":"!"},"finish_reason":null}]}
-
-data: [DONE]
-`,
+			name:          "successful streaming request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ChatCompletionChunks(testModel, "Hello", "!"),
 			expectedError: false,
 		},
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify stream is set in request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ChatRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-				if !req.Stream {
+			route := bearerJSON
+			// Verify stream is set in request body
+			route.Check = func(t testing.TB, _ *http.Request, body []byte) {
+				if req, ok := testfixtures.DecodeJSON[core.ChatRequest](t, body); ok && !req.Stream {
 					t.Error("Stream should be true in request")
 				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			}
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ChatRequest{
-				Model: "grok-2",
-				Messages: []core.Message{
-					{Role: "user", Content: "Hello"},
-				},
-			}
-
-			body, err := provider.StreamChatCompletion(context.Background(), req)
+			body, err := provider.StreamChatCompletion(context.Background(), testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -360,25 +272,9 @@ func TestListModels(t *testing.T) {
 		checkResponse func(*testing.T, *core.ModelsResponse)
 	}{
 		{
-			name:       "successful request",
-			statusCode: http.StatusOK,
-			responseBody: `{
-				"object": "list",
-				"data": [
-					{
-						"id": "grok-2",
-						"object": "model",
-						"created": 1687882411,
-						"owned_by": "xai"
-					},
-					{
-						"id": "grok-2-mini",
-						"object": "model",
-						"created": 1687882410,
-						"owned_by": "xai"
-					}
-				]
-			}`,
+			name:          "successful request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ModelsList("xai", "grok-2", "grok-2-mini"),
 			expectedError: false,
 			checkResponse: func(t *testing.T, resp *core.ModelsResponse) {
 				if resp.Object != "list" {
@@ -398,32 +294,20 @@ func TestListModels(t *testing.T) {
 		{
 			name:          "API error",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request method and path
-				if r.Method != http.MethodGet {
-					t.Errorf("Method = %q, want %q", r.Method, http.MethodGet)
-				}
-				if r.URL.Path != "/models" {
-					t.Errorf("Path = %q, want %q", r.URL.Path, "/models")
-				}
-
-				// Verify authorization header
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			server := testfixtures.NewServer(t, testfixtures.Route{
+				Method:         http.MethodGet,
+				Path:           "/models",
+				HeaderPrefixes: map[string]string{"Authorization": "Bearer "},
+				Status:         tt.statusCode,
+				Body:           tt.responseBody,
+			})
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
@@ -447,12 +331,7 @@ func TestListModels(t *testing.T) {
 }
 
 func TestChatCompletionWithContext(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simulate a slow response
-		<-r.Context().Done()
-		w.WriteHeader(http.StatusRequestTimeout)
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{Hang: true})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
@@ -460,14 +339,7 @@ func TestChatCompletionWithContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	req := &core.ChatRequest{
-		Model: "grok-2",
-		Messages: []core.Message{
-			{Role: "user", Content: "Hello"},
-		},
-	}
-
-	_, err := provider.ChatCompletion(ctx, req)
+	_, err := provider.ChatCompletion(ctx, testfixtures.ChatRequest(testfixtures.ChatModel(testModel)))
 	if err == nil {
 		t.Error("expected error when context is cancelled, got nil")
 	}
@@ -482,30 +354,9 @@ func TestResponses(t *testing.T) {
 		checkResponse func(*testing.T, *core.ResponsesResponse)
 	}{
 		{
-			name:       "successful request with string input",
-			statusCode: http.StatusOK,
-			responseBody: `{
-				"id": "resp_123",
-				"object": "response",
-				"created_at": 1677652288,
-				"model": "grok-2",
-				"status": "completed",
-				"output": [{
-					"id": "msg_123",
-					"type": "message",
-					"role": "assistant",
-					"status": "completed",
-					"content": [{
-						"type": "output_text",
-						"text": "Hello! How can I help you today?"
-					}]
-				}],
-				"usage": {
-					"input_tokens": 10,
-					"output_tokens": 20,
-					"total_tokens": 30
-				}
-			}`,
+			name:          "successful request with string input",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ResponsesResponse{Model: testModel}.JSON(),
 			expectedError: false,
 			checkResponse: func(t *testing.T, resp *core.ResponsesResponse) {
 				if resp.ID != "resp_123" {
@@ -546,64 +397,38 @@ func TestResponses(t *testing.T) {
 		{
 			name:          "API error - unauthorized",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 		{
 			name:          "rate limit error",
 			statusCode:    http.StatusTooManyRequests,
-			responseBody:  `{"error": {"message": "Rate limit exceeded"}}`,
+			responseBody:  testfixtures.OpenAIError("Rate limit exceeded"),
 			expectedError: true,
 		},
 		{
 			name:          "server error",
 			statusCode:    http.StatusInternalServerError,
-			responseBody:  `{"error": {"message": "Internal server error"}}`,
+			responseBody:  testfixtures.OpenAIError("Internal server error"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify request path
-				if r.URL.Path != "/responses" {
-					t.Errorf("Path = %q, want %q", r.URL.Path, "/responses")
-				}
-
-				// Verify request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ResponsesRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			route := bearerJSON
+			route.Path = "/responses"
+			route.Check = func(t testing.TB, _ *http.Request, body []byte) {
+				testfixtures.DecodeJSON[core.ResponsesRequest](t, body)
+			}
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ResponsesRequest{
-				Model: "grok-2",
-				Input: "Hello",
-			}
-
-			resp, err := provider.Responses(context.Background(), req)
+			resp, err := provider.Responses(context.Background(), testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -630,20 +455,9 @@ func TestStreamResponses(t *testing.T) {
 		checkStream   func(*testing.T, io.ReadCloser)
 	}{
 		{
-			name:       "successful streaming request",
-			statusCode: http.StatusOK,
-			responseBody: `event: response.created
-data: {"type":"response.created","response":{"id":"resp_123","object":"response","status":"in_progress","model":"grok-2"}}
-
-event: response.output_text.delta
-data: {"type":"response.output_text.delta","delta":"Hello"}
-
-event: response.output_text.delta
-data: {"type":"response.output_text.delta","delta":"!"}
-
-event: response.completed
-data: {"type":"response.completed","response":{"id":"resp_123","object":"response","status":"completed","model":"grok-2"}}
-`,
+			name:          "successful streaming request",
+			statusCode:    http.StatusOK,
+			responseBody:  testfixtures.ResponsesEvents(testModel, "Hello", "!"),
 			expectedError: false,
 			checkStream: func(t *testing.T, body io.ReadCloser) {
 				if body == nil {
@@ -672,61 +486,35 @@ data: {"type":"response.completed","response":{"id":"resp_123","object":"respons
 		{
 			name:          "API error - unauthorized",
 			statusCode:    http.StatusUnauthorized,
-			responseBody:  `{"error": {"message": "Invalid API key"}}`,
+			responseBody:  testfixtures.OpenAIError("Invalid API key"),
 			expectedError: true,
 		},
 		{
 			name:          "rate limit error",
 			statusCode:    http.StatusTooManyRequests,
-			responseBody:  `{"error": {"message": "Rate limit exceeded"}}`,
+			responseBody:  testfixtures.OpenAIError("Rate limit exceeded"),
 			expectedError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify request headers
-				if r.Header.Get("Content-Type") != "application/json" {
-					t.Errorf("Content-Type = %q, want %q", r.Header.Get("Content-Type"), "application/json")
-				}
-				authHeader := r.Header.Get("Authorization")
-				if !strings.HasPrefix(authHeader, "Bearer ") {
-					t.Errorf("Authorization header should start with 'Bearer '")
-				}
-
-				// Verify request path
-				if r.URL.Path != "/responses" {
-					t.Errorf("Path = %q, want %q", r.URL.Path, "/responses")
-				}
-
-				// Verify stream is set in request body
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					t.Fatalf("failed to read request body: %v", err)
-				}
-				var req core.ResponsesRequest
-				if err := json.Unmarshal(body, &req); err != nil {
-					t.Fatalf("failed to unmarshal request: %v", err)
-				}
-				if !req.Stream {
+			route := bearerJSON
+			route.Path = "/responses"
+			// Verify stream is set in request body
+			route.Check = func(t testing.TB, _ *http.Request, body []byte) {
+				if req, ok := testfixtures.DecodeJSON[core.ResponsesRequest](t, body); ok && !req.Stream {
 					t.Error("Stream should be true in request")
 				}
-
-				w.WriteHeader(tt.statusCode)
-				_, _ = w.Write([]byte(tt.responseBody))
-			}))
-			defer server.Close()
+			}
+			route.Status = tt.statusCode
+			route.Body = tt.responseBody
+			server := testfixtures.NewServer(t, route)
 
 			provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 			provider.SetBaseURL(server.URL)
 
-			req := &core.ResponsesRequest{
-				Model: "grok-2",
-				Input: "Hello",
-			}
-
-			body, err := provider.StreamResponses(context.Background(), req)
+			body, err := provider.StreamResponses(context.Background(), testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 
 			if tt.expectedError {
 				if err == nil {
@@ -745,12 +533,7 @@ data: {"type":"response.completed","response":{"id":"resp_123","object":"respons
 }
 
 func TestResponsesWithContext(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Simulate a slow response
-		<-r.Context().Done()
-		w.WriteHeader(http.StatusRequestTimeout)
-	}))
-	defer server.Close()
+	server := testfixtures.NewServer(t, testfixtures.Route{Hang: true})
 
 	provider := NewWithHTTPClient("test-api-key", nil, llmclient.Hooks{})
 	provider.SetBaseURL(server.URL)
@@ -758,13 +541,47 @@ func TestResponsesWithContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	req := &core.ResponsesRequest{
-		Model: "grok-2",
-		Input: "Hello",
-	}
-
-	_, err := provider.Responses(ctx, req)
+	_, err := provider.Responses(ctx, testfixtures.ResponsesRequest(testfixtures.ResponsesModel(testModel)))
 	if err == nil {
 		t.Error("expected error when context is cancelled, got nil")
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "xai",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "grok-2")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "xai",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "grok-2",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/xai/v1", ChatPath: "/proxy/xai/v1/chat/completions", Prefix: "/proxy/xai/v1/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/xai/v1/", ChatPath: "/proxy/xai/v1/chat/completions", Prefix: "/proxy/xai/v1/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
@@ -21,6 +21,7 @@ var Registration = providers.Registration{
 	Discovery: providers.DiscoveryConfig{
 		DefaultBaseURL: defaultBaseURL,
 	},
+	Quirks: openai.Quirks,
 }
 
 // Provider implements the core.Provider interface for Z.ai.
@@ -49,6 +50,7 @@ func NewWithHTTPClient(apiKey string, baseURL string, httpClient *http.Client, h
 			ProviderName: "zai",
 			BaseURL:      resolvedBaseURL,
 			SetHeaders:   setHeaders,
+			Quirks:       openai.Quirks,
 		}),
 	}
 }
@@ -8,6 +8,8 @@ import (
 
 	"gomodel/internal/core"
 	"gomodel/internal/llmclient"
+	"gomodel/internal/providers"
+	"gomodel/internal/testfixtures"
 )
 
 func TestChatCompletion_UsesBearerAuthAndChatEndpoint(t *testing.T) {
@@ -96,3 +98,42 @@ func TestProvider_DoesNotExposeOptionalOpenAICompatibleInterfaces(t *testing.T)
 		t.Fatal("zai provider should not implement native file provider")
 	}
 }
+
+func TestExtraRequestParams_SentOnEveryEndpoint(t *testing.T) {
+	server := testfixtures.NewServer(t, testfixtures.Route{Body: `{}`})
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	provider, err := factory.Create(providers.ProviderConfig{
+		Type:    "zai",
+		APIKey:  "test-api-key",
+		BaseURL: server.URL,
+		Extra: llmclient.ExtraRequestParams{
+			Headers: map[string]string{"Cf-Access-Client-Id": "client-id"},
+			Query:   map[string]string{"tenant": "acme"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testfixtures.CallEveryEndpoint(t, provider, "glm-4.6")
+	testfixtures.RequireOnEveryRequest(t, server, 6, "Cf-Access-Client-Id", "client-id", "tenant", "acme")
+}
+
+func TestBaseURLJoining(t *testing.T) {
+	factory := providers.NewProviderFactory()
+	factory.Add(Registration)
+	newProvider := func(baseURL string) (core.Provider, error) {
+		return factory.Create(providers.ProviderConfig{
+			Type:    "zai",
+			APIKey:  "test-api-key",
+			BaseURL: baseURL,
+		})
+	}
+
+	testfixtures.RequireBaseURLPaths(t, newProvider, "glm-4.6",
+		testfixtures.BaseURLCase{Name: "prefixed", Suffix: "/proxy/zai/api/paas/v4", ChatPath: "/proxy/zai/api/paas/v4/chat/completions", Prefix: "/proxy/zai/api/paas/v4/"},
+		testfixtures.BaseURLCase{Name: "trailing slash", Suffix: "/proxy/zai/api/paas/v4/", ChatPath: "/proxy/zai/api/paas/v4/chat/completions", Prefix: "/proxy/zai/api/paas/v4/"},
+		testfixtures.BaseURLCase{Name: "bare host", Suffix: "", ChatPath: "/chat/completions", Prefix: "/"},
+	)
+}
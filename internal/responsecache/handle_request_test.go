@@ -33,6 +33,7 @@ func (l *recordingUsageLogger) Config() usage.Config {
 	return usage.Config{Enabled: true}
 }
 
+func (l *recordingUsageLogger) Flush(context.Context) error { return nil }
 func (l *recordingUsageLogger) Close() error {
 	return nil
 }
@@ -1183,12 +1183,40 @@ func appendResponsesItemDeltaEvents(out *bytes.Buffer, item map[string]any, item
 			if err := appendSSEJSONEvent(out, eventName, payload); err != nil {
 				return err
 			}
+			if err := appendResponsesAnnotationEvents(out, part, itemID, outputIndex, contentIndex); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// appendResponsesAnnotationEvents replays the annotations of an output_text
+// part as response.output_text.annotation.added events.
+func appendResponsesAnnotationEvents(out *bytes.Buffer, part map[string]any, itemID string, outputIndex, contentIndex int) error {
+	if partType, _ := part["type"].(string); partType != "output_text" {
+		return nil
+	}
+	annotations, _ := part["annotations"].([]any)
+	for annotationIndex, annotation := range annotations {
+		payload := map[string]any{
+			"type":             "response.output_text.annotation.added",
+			"output_index":     outputIndex,
+			"content_index":    contentIndex,
+			"annotation_index": annotationIndex,
+			"annotation":       annotation,
+		}
+		if itemID != "" {
+			payload["item_id"] = itemID
+		}
+		if err := appendSSEJSONEvent(out, "response.output_text.annotation.added", payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func responsesContentDeltaEvent(part map[string]any, itemID string, outputIndex, contentIndex int) (string, map[string]any, bool) {
 	partType, _ := part["type"].(string)
 	text, _ := part["text"].(string)
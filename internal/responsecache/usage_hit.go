@@ -56,8 +56,15 @@ func newUsageHitRecorder(logger usage.LoggerInterface, pricingResolver usage.Pri
 		if entry == nil {
 			return
 		}
+		entry.RequestedModel = plan.RequestedQualifiedModel()
 		entry.ProviderName = providerName
 		entry.UserPath = core.UserPathFromContext(ctx)
+		entry.Labels = core.GetRequestLabels(ctx)
+		template := core.GetPromptTemplate(ctx)
+		entry.TemplateName, entry.TemplateVersion = template.Name, template.Version
+		user := core.GetRequestUser(ctx)
+		entry.UserHash, entry.User = user.Hash, user.Raw
+		entry.AuthKeyID = strings.TrimSpace(core.GetAuthKeyID(ctx))
 		logger.Write(entry)
 	}
 }
@@ -0,0 +1,95 @@
+package responsetransform
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// edit replaces body[start:end] with value.
+type edit struct {
+	start, end int
+	value      []byte
+}
+
+// RewriteBody applies the pipeline to the text of a chat completion or a
+// Responses API response: every choice's message content, or every
+// output_text part. Each text is transformed on its own. The rest of the body
+// is kept byte for byte. It returns ok false, and body unchanged, for any
+// other body.
+func (p *Pipeline) RewriteBody(body []byte) ([]byte, []Applied, bool) {
+	if !gjson.ValidBytes(body) {
+		return body, nil, false
+	}
+	var paths []string
+	switch root := gjson.ParseBytes(body); {
+	case root.Get("choices").IsArray():
+		for i := range len(root.Get("choices").Array()) {
+			paths = append(paths, "choices."+strconv.Itoa(i)+".message.content")
+		}
+	case root.Get("object").String() == "response" && root.Get("output").IsArray():
+		for i, item := range root.Get("output").Array() {
+			if item.Get("type").String() != "message" {
+				continue
+			}
+			for j, part := range item.Get("content").Array() {
+				if part.Get("type").String() == "output_text" {
+					paths = append(paths, "output."+strconv.Itoa(i)+".content."+strconv.Itoa(j)+".text")
+				}
+			}
+		}
+	default:
+		return body, nil, false
+	}
+
+	applied := p.newApplied()
+	var edits []edit
+	for _, path := range paths {
+		value := gjson.GetBytes(body, path)
+		if value.Type != gjson.String || value.Index <= 0 {
+			continue
+		}
+		text, textApplied := p.Apply(value.Str)
+		for i := range applied {
+			applied[i].Replacements += textApplied[i].Replacements
+		}
+		if text != value.Str {
+			edits = append(edits, edit{start: value.Index, end: value.Index + len(value.Raw), value: marshalString(text)})
+		}
+	}
+	return applyEdits(body, edits), applied, true
+}
+
+// applyEdits returns body with non-overlapping edits applied.
+func applyEdits(body []byte, edits []edit) []byte {
+	if len(edits) == 0 {
+		return body
+	}
+	slices.SortFunc(edits, func(a, b edit) int { return a.start - b.start })
+	out := make([]byte, 0, len(body))
+	last := 0
+	for _, e := range edits {
+		out = append(out, body[last:e.start]...)
+		out = append(out, e.value...)
+		last = e.end
+	}
+	return append(out, body[last:]...)
+}
+
+// marshalString encodes s as a JSON string.
+func marshalString(s string) []byte {
+	return marshalJSON(s)
+}
+
+// marshalJSON encodes v without escaping HTML characters, so rewritten text
+// keeps the look of the provider's output.
+func marshalJSON(v any) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(v) //nolint:errcheck
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
@@ -0,0 +1,260 @@
+// Package responsetransform rewrites the text of model responses before they
+// reach clients: regex replacements, truncation and removal of XML-style
+// elements such as leaked <think> blocks.
+//
+// Transforms only exist when pipelines are configured: New returns a nil
+// Service otherwise and the server installs nothing. The first pipeline that
+// matches a request's model and provider applies its transformers in order,
+// each one to the output of the previous one. Non-streaming chat completion
+// and Responses API bodies are rewritten whole. Chat completion streams are
+// rewritten through a window of held-back characters, so a match can span
+// chunk boundaries as long as it is no longer than the window.
+package responsetransform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gomodel/config"
+)
+
+// DefaultStreamWindow is the stream window used when the config does not set
+// one.
+const DefaultStreamWindow = 256
+
+// defaultMarker ends text cut by truncate_chars.
+const defaultMarker = "…"
+
+// Kind is the operation a transformer performs.
+type Kind string
+
+const (
+	// KindRegexReplace replaces every match of a regular expression.
+	KindRegexReplace Kind = "regex_replace"
+	// KindTruncateChars cuts text after MaxChars characters and appends a
+	// marker.
+	KindTruncateChars Kind = "truncate_chars"
+	// KindStripXMLTags removes the named elements together with their
+	// content, and stray opening or closing tags of them.
+	KindStripXMLTags Kind = "strip_xml_tags"
+)
+
+// Applied reports what one transformer did to a response.
+type Applied struct {
+	Name         string `json:"name"`
+	Replacements int    `json:"replacements"`
+}
+
+// Transformer is one configured transformation.
+type Transformer struct {
+	Name string
+	Kind Kind
+
+	re          *regexp.Regexp
+	replacement string
+	maxChars    int
+	marker      string
+}
+
+// Target describes the request pipelines are matched against. Models and
+// Providers hold every name the request is known by.
+type Target struct {
+	Models    []string
+	Providers []string
+}
+
+// Pipeline is an ordered list of transformers.
+type Pipeline struct {
+	Name         string
+	Model        string
+	Provider     string
+	Transformers []*Transformer
+
+	window int
+}
+
+// Service holds the configured pipelines.
+type Service struct {
+	pipelines []*Pipeline
+}
+
+// New builds the Service for cfg. It returns nil when no pipeline is
+// configured.
+func New(cfg config.ResponseTransformsConfig) (*Service, error) {
+	if len(cfg.Pipelines) == 0 {
+		return nil, nil
+	}
+	window := cfg.StreamWindow
+	if window < 0 {
+		return nil, fmt.Errorf("stream_window must not be negative")
+	}
+	if window == 0 {
+		window = DefaultStreamWindow
+	}
+
+	service := &Service{pipelines: make([]*Pipeline, 0, len(cfg.Pipelines))}
+	names := make(map[string]struct{}, len(cfg.Pipelines))
+	for index, pipelineCfg := range cfg.Pipelines {
+		pipeline, err := newPipeline(pipelineCfg, window)
+		if err != nil {
+			return nil, fmt.Errorf("pipelines[%d]: %w", index, err)
+		}
+		if _, exists := names[pipeline.Name]; exists {
+			return nil, fmt.Errorf("pipelines[%d]: duplicate pipeline name %q", index, pipeline.Name)
+		}
+		names[pipeline.Name] = struct{}{}
+		service.pipelines = append(service.pipelines, pipeline)
+	}
+	return service, nil
+}
+
+func newPipeline(cfg config.ResponseTransformPipelineConfig, window int) (*Pipeline, error) {
+	pipeline := &Pipeline{
+		Name:     strings.TrimSpace(cfg.Name),
+		Model:    strings.TrimSpace(cfg.Model),
+		Provider: strings.TrimSpace(cfg.Provider),
+		window:   window,
+	}
+	if pipeline.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(cfg.Transformers) == 0 {
+		return nil, fmt.Errorf("pipeline %q: at least one transformer is required", pipeline.Name)
+	}
+	for index, transformerCfg := range cfg.Transformers {
+		transformer, err := newTransformer(transformerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %q: transformers[%d]: %w", pipeline.Name, index, err)
+		}
+		pipeline.Transformers = append(pipeline.Transformers, transformer)
+	}
+	return pipeline, nil
+}
+
+func newTransformer(cfg config.ResponseTransformerConfig) (*Transformer, error) {
+	kind := Kind(strings.ToLower(strings.TrimSpace(cfg.Type)))
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = string(kind)
+	}
+	transformer := &Transformer{Name: name, Kind: kind}
+
+	switch kind {
+	case KindRegexReplace:
+		if cfg.Pattern == "" {
+			return nil, fmt.Errorf("%s: pattern is required", kind)
+		}
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pattern: %w", kind, err)
+		}
+		transformer.re = re
+		transformer.replacement = cfg.Replacement
+	case KindTruncateChars:
+		if cfg.MaxChars <= 0 {
+			return nil, fmt.Errorf("%s: max_chars must be positive", kind)
+		}
+		transformer.maxChars = cfg.MaxChars
+		transformer.marker = cfg.Marker
+		if transformer.marker == "" {
+			transformer.marker = defaultMarker
+		}
+	case KindStripXMLTags:
+		tags := make([]string, 0, len(cfg.Tags))
+		for _, tag := range cfg.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			tags = append(tags, regexp.QuoteMeta(tag))
+		}
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("%s: tags are required", kind)
+		}
+		names := "(?:" + strings.Join(tags, "|") + ")"
+		transformer.re = regexp.MustCompile(`(?is)<` + names + `\b[^>]*>.*?</` + names + `\s*>\s*|</?` + names + `\b[^>]*>`)
+	default:
+		return nil, fmt.Errorf("unknown transformer type %q", cfg.Type)
+	}
+	return transformer, nil
+}
+
+// Pipelines returns the configured pipelines in match order.
+func (s *Service) Pipelines() []*Pipeline {
+	if s == nil {
+		return nil
+	}
+	return append([]*Pipeline(nil), s.pipelines...)
+}
+
+// Match returns the first pipeline matching target.
+func (s *Service) Match(target Target) (*Pipeline, bool) {
+	if s == nil {
+		return nil, false
+	}
+	for _, pipeline := range s.pipelines {
+		if matchesAny(pipeline.Model, target.Models) && matchesAny(pipeline.Provider, target.Providers) {
+			return pipeline, true
+		}
+	}
+	return nil, false
+}
+
+func matchesAny(want string, values []string) bool {
+	if want == "" {
+		return true
+	}
+	for _, value := range values {
+		if strings.TrimSpace(value) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply runs every transformer over text in order.
+func (p *Pipeline) Apply(text string) (string, []Applied) {
+	applied := p.newApplied()
+	for i, transformer := range p.Transformers {
+		var count int
+		text, count = transformer.apply(text)
+		applied[i].Replacements += count
+	}
+	return text, applied
+}
+
+func (p *Pipeline) newApplied() []Applied {
+	applied := make([]Applied, len(p.Transformers))
+	for i, transformer := range p.Transformers {
+		applied[i].Name = transformer.Name
+	}
+	return applied
+}
+
+func (t *Transformer) apply(text string) (string, int) {
+	switch t.Kind {
+	case KindTruncateChars:
+		if utf8.RuneCountInString(text) <= t.maxChars {
+			return text, 0
+		}
+		return truncateRunes(text, t.maxChars) + t.marker, 1
+	default:
+		matches := t.re.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			return text, 0
+		}
+		return t.re.ReplaceAllString(text, t.replacement), len(matches)
+	}
+}
+
+func truncateRunes(text string, n int) string {
+	for i := range text {
+		if n == 0 {
+			return text[:i]
+		}
+		n--
+	}
+	return text
+}
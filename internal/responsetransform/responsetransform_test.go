@@ -0,0 +1,275 @@
+package responsetransform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+)
+
+func newTestPipeline(t *testing.T, window int, transformers ...config.ResponseTransformerConfig) *Pipeline {
+	t.Helper()
+	service, err := New(config.ResponseTransformsConfig{
+		StreamWindow: window,
+		Pipelines:    []config.ResponseTransformPipelineConfig{{Name: "test", Transformers: transformers}},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	pipeline, ok := service.Match(Target{})
+	if !ok {
+		t.Fatal("pipeline did not match")
+	}
+	return pipeline
+}
+
+func codename() config.ResponseTransformerConfig {
+	return config.ResponseTransformerConfig{Name: "codename", Type: "regex_replace", Pattern: `Project Falcon`, Replacement: "Atlas"}
+}
+
+func TestNew_Validation(t *testing.T) {
+	service, err := New(config.ResponseTransformsConfig{})
+	if err != nil || service != nil {
+		t.Fatalf("New without pipelines = %v, %v; want nil", service, err)
+	}
+
+	for name, transformer := range map[string]config.ResponseTransformerConfig{
+		"unknown type":     {Type: "uppercase"},
+		"missing pattern":  {Type: "regex_replace"},
+		"invalid pattern":  {Type: "regex_replace", Pattern: "("},
+		"missing maxchars": {Type: "truncate_chars"},
+		"missing tags":     {Type: "strip_xml_tags", Tags: []string{" "}},
+	} {
+		_, err := New(config.ResponseTransformsConfig{Pipelines: []config.ResponseTransformPipelineConfig{{Name: "p", Transformers: []config.ResponseTransformerConfig{transformer}}}})
+		if err == nil {
+			t.Errorf("%s: New succeeded", name)
+		}
+	}
+	_, err = New(config.ResponseTransformsConfig{Pipelines: []config.ResponseTransformPipelineConfig{
+		{Name: "p", Transformers: []config.ResponseTransformerConfig{codename()}},
+		{Name: "p", Transformers: []config.ResponseTransformerConfig{codename()}},
+	}})
+	if err == nil {
+		t.Error("duplicate pipeline names accepted")
+	}
+}
+
+func TestMatch_FirstMatchingPipeline(t *testing.T) {
+	service, err := New(config.ResponseTransformsConfig{Pipelines: []config.ResponseTransformPipelineConfig{
+		{Name: "reasoning", Model: "deepseek-r1", Transformers: []config.ResponseTransformerConfig{{Type: "strip_xml_tags", Tags: []string{"think"}}}},
+		{Name: "anthropic", Provider: "anthropic", Transformers: []config.ResponseTransformerConfig{codename()}},
+		{Name: "everything", Transformers: []config.ResponseTransformerConfig{codename()}},
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for _, tt := range []struct {
+		target Target
+		want   string
+	}{
+		{Target{Models: []string{"deepseek-r1"}, Providers: []string{"anthropic"}}, "reasoning"},
+		{Target{Models: []string{"claude"}, Providers: []string{"anthropic"}}, "anthropic"},
+		{Target{Models: []string{"gpt-4o"}, Providers: []string{"openai"}}, "everything"},
+	} {
+		pipeline, ok := service.Match(tt.target)
+		if !ok || pipeline.Name != tt.want {
+			t.Errorf("Match(%+v) = %v, want %s", tt.target, pipeline, tt.want)
+		}
+	}
+}
+
+func TestApply_TransformersRunInOrder(t *testing.T) {
+	text := "<think>Project Falcon is internal</think>Project Falcon ships today."
+
+	strip := config.ResponseTransformerConfig{Type: "strip_xml_tags", Tags: []string{"think"}}
+	truncate := config.ResponseTransformerConfig{Type: "truncate_chars", MaxChars: 11, Marker: " [...]"}
+
+	got, applied := newTestPipeline(t, 0, strip, codename(), truncate).Apply(text)
+	if got != "Atlas ships [...]" {
+		t.Fatalf("strip, replace, truncate = %q", got)
+	}
+	want := []Applied{{Name: "strip_xml_tags", Replacements: 1}, {Name: "codename", Replacements: 1}, {Name: "truncate_chars", Replacements: 1}}
+	for i := range want {
+		if applied[i] != want[i] {
+			t.Fatalf("applied = %+v, want %+v", applied, want)
+		}
+	}
+
+	// Truncating first cuts the element open, so only its stray opening tag
+	// is stripped and part of its content leaks.
+	got, applied = newTestPipeline(t, 0, truncate, strip).Apply(text)
+	if got != "Proj [...]" || applied[1].Replacements != 1 {
+		t.Fatalf("truncate, strip = %q %+v", got, applied)
+	}
+}
+
+func TestTextStream_WindowSpansChunkBoundaries(t *testing.T) {
+	pipeline := newTestPipeline(t, 16, codename())
+	stream := pipeline.NewTextStream()
+
+	var out strings.Builder
+	for _, piece := range []string{"Ask about Pro", "ject Fal", "con and Project ", "Falcon again. ", "Done."} {
+		out.WriteString(stream.Write(piece))
+	}
+	out.WriteString(stream.Flush())
+
+	if got := out.String(); got != "Ask about Atlas and Atlas again. Done." {
+		t.Fatalf("streamed = %q", got)
+	}
+	if applied := stream.Applied(); applied[0].Replacements != 2 {
+		t.Fatalf("applied = %+v, want 2 replacements", applied)
+	}
+}
+
+func TestTextStream_HoldsBackOnlyTheWindow(t *testing.T) {
+	stream := newTestPipeline(t, 4, codename()).NewTextStream()
+
+	if got := stream.Write("abc"); got != "" {
+		t.Fatalf("Write within window = %q, want everything held back", got)
+	}
+	if got := stream.Write("defgh"); got != "abcd" {
+		t.Fatalf("Write past window = %q, want all but the last 4 characters", got)
+	}
+	if got := stream.Write("ééé"); got != "efg" {
+		t.Fatalf("Write multibyte = %q, want the window counted in characters", got)
+	}
+	if got := stream.Flush(); got != "hééé" {
+		t.Fatalf("Flush = %q", got)
+	}
+}
+
+func TestTextStream_MatchLongerThanWindowIsMissed(t *testing.T) {
+	stream := newTestPipeline(t, 4, codename()).NewTextStream()
+
+	var out strings.Builder
+	for _, piece := range []string{"Project ", "Falcon"} {
+		out.WriteString(stream.Write(piece))
+	}
+	out.WriteString(stream.Flush())
+
+	if got := out.String(); got != "Project Falcon" {
+		t.Fatalf("streamed = %q, want the match split by the window left alone", got)
+	}
+}
+
+func TestTextStream_TruncatesAcrossPieces(t *testing.T) {
+	stream := newTestPipeline(t, 8, codename(), config.ResponseTransformerConfig{Type: "truncate_chars", MaxChars: 10}).NewTextStream()
+
+	var out strings.Builder
+	for _, piece := range []string{"Project Falcon ", "is a very long answer", " that keeps going"} {
+		out.WriteString(stream.Write(piece))
+	}
+	out.WriteString(stream.Flush())
+
+	if got := out.String(); got != "Atlas is a…" {
+		t.Fatalf("streamed = %q", got)
+	}
+}
+
+func TestRewriteBody(t *testing.T) {
+	pipeline := newTestPipeline(t, 0, codename())
+
+	chat := `{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Project Falcon <ok>"},"finish_reason":"stop"},{"index":1,"message":{"role":"assistant","content":null,"tool_calls":[]}}],"usage":{"total_tokens":3}}`
+	body, applied, ok := pipeline.RewriteBody([]byte(chat))
+	if !ok || applied[0].Replacements != 1 {
+		t.Fatalf("RewriteBody(chat) ok = %v applied = %+v", ok, applied)
+	}
+	want := strings.Replace(chat, "Project Falcon <ok>", "Atlas <ok>", 1)
+	if string(body) != want {
+		t.Fatalf("chat body = %s\nwant %s", body, want)
+	}
+
+	responses := `{"id":"resp_1","object":"response","output":[{"type":"reasoning","summary":[]},{"type":"message","content":[{"type":"output_text","text":"Project Falcon\nProject Falcon"}]}]}`
+	body, applied, ok = pipeline.RewriteBody([]byte(responses))
+	if !ok || applied[0].Replacements != 2 || gjson.GetBytes(body, "output.1.content.0.text").String() != "Atlas\nAtlas" {
+		t.Fatalf("RewriteBody(responses) = %s, %+v, %v", body, applied, ok)
+	}
+
+	if _, _, ok := pipeline.RewriteBody([]byte(`{"object":"list","data":[]}`)); ok {
+		t.Fatal("RewriteBody accepted a body without text")
+	}
+}
+
+func rewriteStream(stream *ChatStream, upstream string) []byte {
+	var out []byte
+	for line := range strings.Lines(upstream) {
+		out = append(out, stream.RewriteLine([]byte(line))...)
+	}
+	return append(out, stream.Finish()...)
+}
+
+func TestChatStream_RewritesChunks(t *testing.T) {
+	pipeline := newTestPipeline(t, 16, codename())
+	upstream := strings.Join([]string{
+		`data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello from Pro"},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"content":"ject Falcon!"},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		``,
+		`data: [DONE]`,
+		``,
+		``,
+	}, "\n")
+
+	stream := pipeline.NewChatStream()
+	out := rewriteStream(stream, upstream)
+
+	var text strings.Builder
+	var sawFinish bool
+	for line := range strings.Lines(string(out)) {
+		payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok || payload == "[DONE]" {
+			continue
+		}
+		if !gjson.Valid(payload) {
+			t.Fatalf("invalid chunk %q", payload)
+		}
+		text.WriteString(gjson.Get(payload, "choices.0.delta.content").String())
+		if gjson.Get(payload, "choices.0.finish_reason").String() == "stop" {
+			sawFinish = true
+			if gjson.Get(payload, "choices.0.delta.content").String() == "" {
+				t.Fatalf("finish chunk carries no held-back text: %s", payload)
+			}
+		}
+	}
+	if text.String() != "Hello from Atlas!" || !sawFinish {
+		t.Fatalf("streamed text = %q (finish seen %v)\n%s", text.String(), sawFinish, out)
+	}
+	if applied := stream.Applied(); applied[0].Replacements != 1 {
+		t.Fatalf("applied = %+v", applied)
+	}
+}
+
+func TestChatStream_FlushesBeforeDoneWithoutFinishReason(t *testing.T) {
+	pipeline := newTestPipeline(t, 64, codename())
+	upstream := "data: {\"id\":\"c1\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Project Falcon\"}}]}\n\ndata: [DONE]\n\n"
+
+	out := rewriteStream(pipeline.NewChatStream(), upstream)
+
+	got := string(out)
+	flushAt := strings.Index(got, `"content":"Atlas"`)
+	doneAt := strings.Index(got, "data: [DONE]")
+	if flushAt < 0 || doneAt < flushAt {
+		t.Fatalf("stream = %q, want the held-back text in a chunk before [DONE]", got)
+	}
+	if !strings.Contains(got, `{"id":"c1","model":"m","choices":[{"index":0,"delta":{"content":"Atlas"},"finish_reason":null}]}`) {
+		t.Fatalf("flush chunk = %q", got)
+	}
+}
+
+func TestChatStream_FinishFlushesStreamWithoutDone(t *testing.T) {
+	stream := newTestPipeline(t, 64, codename()).NewChatStream()
+	out := stream.RewriteLine([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Project Falcon\"}}]}\n"))
+	if strings.Contains(string(out), "Atlas") || strings.Contains(string(out), "Falcon") {
+		t.Fatalf("first line = %q, want the text held back", out)
+	}
+	if got := string(stream.Finish()); !strings.Contains(got, `"content":"Atlas"`) {
+		t.Fatalf("Finish = %q", got)
+	}
+	if got := stream.Finish(); got != nil {
+		t.Fatalf("second Finish = %q, want nil", got)
+	}
+}
@@ -0,0 +1,177 @@
+package responsetransform
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// ChatStream rewrites the delta content of a chat completion SSE stream, line
+// by line. Text held back by the window is sent with a later chunk of the same
+// choice: at the latest with the chunk carrying its finish_reason, or in an
+// extra chunk right before [DONE].
+type ChatStream struct {
+	pipeline *Pipeline
+	choices  map[int64]*TextStream
+	last     gjson.Result
+}
+
+// NewChatStream starts rewriting one chat completion stream.
+func (p *Pipeline) NewChatStream() *ChatStream {
+	return &ChatStream{pipeline: p, choices: make(map[int64]*TextStream)}
+}
+
+// Pipeline returns the pipeline rewriting the stream.
+func (s *ChatStream) Pipeline() *Pipeline {
+	return s.pipeline
+}
+
+// Applied returns what each transformer did to the stream so far, summed over
+// its choices.
+func (s *ChatStream) Applied() []Applied {
+	applied := s.pipeline.newApplied()
+	for _, text := range s.choices {
+		for i, a := range text.applied {
+			applied[i].Replacements += a.Replacements
+		}
+	}
+	return applied
+}
+
+// Finish returns an extra chunk with the text still held back when the
+// stream ended without [DONE], or nil.
+func (s *ChatStream) Finish() []byte {
+	return s.flushEvent()
+}
+
+// RewriteLine returns one complete line of the stream, including its line
+// ending, with the delta content of a data line rewritten.
+func (s *ChatStream) RewriteLine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	content := bytes.TrimSpace(payload)
+	if string(content) == "[DONE]" {
+		return append(s.flushEvent(), line...)
+	}
+	if len(content) == 0 || content[0] != '{' || !gjson.ValidBytes(content) {
+		return line
+	}
+	rewritten := s.rewriteChunk(content)
+	out := make([]byte, 0, len(rewritten)+8)
+	out = append(out, "data: "...)
+	out = append(out, rewritten...)
+	return append(out, payload[len(bytes.TrimRight(payload, "\r\n")):]...)
+}
+
+// rewriteChunk passes the delta content of every choice in a chunk through
+// that choice's text stream.
+func (s *ChatStream) rewriteChunk(chunk []byte) []byte {
+	root := gjson.ParseBytes(chunk)
+	choices := root.Get("choices")
+	if !choices.IsArray() {
+		return chunk
+	}
+	s.last = root
+
+	var edits []edit
+	for i, choice := range choices.Array() {
+		prefix := "choices." + strconv.Itoa(i)
+		index := choice.Get("index").Int()
+		text := s.choice(index)
+
+		var out string
+		contentValue := gjson.GetBytes(chunk, prefix+".delta.content")
+		hasContent := contentValue.Type == gjson.String && contentValue.Index > 0
+		if hasContent {
+			out = text.Write(contentValue.Str)
+		}
+		if finish := choice.Get("finish_reason"); finish.Exists() && finish.Type != gjson.Null {
+			out += text.Flush()
+		}
+
+		switch {
+		case hasContent && out != contentValue.Str:
+			edits = append(edits, edit{start: contentValue.Index, end: contentValue.Index + len(contentValue.Raw), value: marshalString(out)})
+		case !hasContent && out != "":
+			if e, ok := insertContent(chunk, prefix, out); ok {
+				edits = append(edits, e)
+			}
+		}
+	}
+	return applyEdits(chunk, edits)
+}
+
+// insertContent adds a content field to the delta object of a choice.
+func insertContent(chunk []byte, prefix, content string) (edit, bool) {
+	field := append([]byte(`"content":`), marshalString(content)...)
+	delta := gjson.GetBytes(chunk, prefix+".delta")
+	if delta.IsObject() && delta.Index > 0 {
+		if len(delta.Map()) > 0 {
+			field = append(field, ',')
+		}
+		at := delta.Index + 1
+		return edit{start: at, end: at, value: field}, true
+	}
+	choice := gjson.GetBytes(chunk, prefix)
+	if !choice.IsObject() || choice.Index <= 0 {
+		return edit{}, false
+	}
+	value := append([]byte(`"delta":{`), field...)
+	value = append(value, '}')
+	if len(choice.Map()) > 0 {
+		value = append(value, ',')
+	}
+	at := choice.Index + 1
+	return edit{start: at, end: at, value: value}, true
+}
+
+func (s *ChatStream) choice(index int64) *TextStream {
+	text, ok := s.choices[index]
+	if !ok {
+		text = s.pipeline.NewTextStream()
+		s.choices[index] = text
+	}
+	return text
+}
+
+// flushEvent returns an extra chunk with the text still held back for each
+// choice, or nil when nothing is held back.
+func (s *ChatStream) flushEvent() []byte {
+	type flushed struct {
+		Index int64 `json:"index"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	}
+	var choices []flushed
+	for _, index := range slices.Sorted(maps.Keys(s.choices)) {
+		if text := s.choices[index].Flush(); text != "" {
+			choice := flushed{Index: index}
+			choice.Delta.Content = text
+			choices = append(choices, choice)
+		}
+	}
+	if len(choices) == 0 {
+		return nil
+	}
+
+	// The extra chunk repeats the identifying fields of the last one.
+	out := []byte("data: {")
+	for _, field := range []string{"id", "object", "created", "model"} {
+		if value := s.last.Get(field); value.Exists() {
+			out = append(out, strconv.Quote(field)...)
+			out = append(out, ':')
+			out = append(out, value.Raw...)
+			out = append(out, ',')
+		}
+	}
+	out = append(out, `"choices":`...)
+	out = append(out, marshalJSON(choices)...)
+	return append(out, "}\n\n"...)
+}
@@ -0,0 +1,119 @@
+package responsetransform
+
+import "unicode/utf8"
+
+// TextStream applies a pipeline to text that arrives in pieces, such as the
+// deltas of one streamed choice. Each transformer is a stage that passes its
+// output on to the next one.
+type TextStream struct {
+	stages  []*stage
+	applied []Applied
+}
+
+// stage runs one transformer over streamed text. Regex stages hold back the
+// last window characters, so a match that starts in one piece and ends in a
+// later one is still found; a match longer than the window is not.
+type stage struct {
+	transformer *Transformer
+	window      int
+
+	pending   string
+	emitted   int
+	truncated bool
+}
+
+// NewTextStream starts transforming one piece of streamed text.
+func (p *Pipeline) NewTextStream() *TextStream {
+	stream := &TextStream{applied: p.newApplied()}
+	for _, transformer := range p.Transformers {
+		stream.stages = append(stream.stages, &stage{transformer: transformer, window: p.window})
+	}
+	return stream
+}
+
+// Write adds the next piece of text and returns the text that can be sent
+// now. The rest is held back until a later Write or Flush.
+func (s *TextStream) Write(text string) string {
+	for i, st := range s.stages {
+		var count int
+		text, count = st.write(text)
+		s.applied[i].Replacements += count
+	}
+	return text
+}
+
+// Flush returns all text still held back. The stream can be written to again
+// afterwards.
+func (s *TextStream) Flush() string {
+	var text string
+	for i, st := range s.stages {
+		out, count := st.write(text)
+		rest, restCount := st.flush()
+		text = out + rest
+		s.applied[i].Replacements += count + restCount
+	}
+	return text
+}
+
+// Applied returns what each transformer did so far.
+func (s *TextStream) Applied() []Applied {
+	return append([]Applied(nil), s.applied...)
+}
+
+func (st *stage) write(text string) (string, int) {
+	if st.transformer.Kind == KindTruncateChars {
+		return st.truncate(text)
+	}
+	st.pending += text
+	cut := holdBackStart(st.pending, st.window)
+	if cut == 0 {
+		return "", 0
+	}
+	// A match crossing the cut is held back whole, so it is replaced once
+	// the text after it is known.
+	for _, match := range st.transformer.re.FindAllStringIndex(st.pending, -1) {
+		if match[0] >= cut {
+			break
+		}
+		if match[1] > cut {
+			cut = match[0]
+			break
+		}
+	}
+	head := st.pending[:cut]
+	st.pending = st.pending[cut:]
+	return st.transformer.apply(head)
+}
+
+func (st *stage) flush() (string, int) {
+	if st.transformer.Kind == KindTruncateChars || st.pending == "" {
+		return "", 0
+	}
+	text := st.pending
+	st.pending = ""
+	return st.transformer.apply(text)
+}
+
+func (st *stage) truncate(text string) (string, int) {
+	if st.truncated || text == "" {
+		return "", 0
+	}
+	n := utf8.RuneCountInString(text)
+	if st.emitted+n <= st.transformer.maxChars {
+		st.emitted += n
+		return text, 0
+	}
+	st.truncated = true
+	return truncateRunes(text, st.transformer.maxChars-st.emitted) + st.transformer.marker, 1
+}
+
+// holdBackStart returns the byte offset where the last window characters of
+// text start.
+func holdBackStart(text string, window int) int {
+	end := len(text)
+	for n := 0; n < window && end > 0; n++ {
+		_, size := utf8.DecodeLastRuneInString(text[:end])
+		end -= size
+	}
+	return end
+}
@@ -0,0 +1,217 @@
+// Package sanitize hides which upstream provider and account served a
+// response, for deployments that do not want clients to learn it.
+//
+// Sanitization only exists when it is enabled in config: New returns a nil
+// Sanitizer otherwise and the server does not install its middleware. It
+// drops provider-identifying response headers, removes system_fingerprint
+// from chat completion bodies and stream chunks, and replaces their provider
+// response IDs with gateway-issued ones. The Sanitizer remembers which
+// provider ID each gateway ID stands for, so operators can resolve it later.
+package sanitize
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+)
+
+// IDPrefix starts every gateway-issued response ID.
+const IDPrefix = "gm-"
+
+// DefaultMaxMappings is the number of ID mappings kept when the config does
+// not set one.
+const DefaultMaxMappings = 100000
+
+// identifyingHeaders are response headers that name the upstream provider,
+// its account or its infrastructure.
+var identifyingHeaders = map[string]struct{}{
+	"Request-Id":                    {},
+	"X-Request-Id":                  {},
+	"Apim-Request-Id":               {},
+	"X-Ms-Region":                   {},
+	"X-Ms-Client-Request-Id":        {},
+	"Azureml-Model-Session":         {},
+	"Server":                        {},
+	"Via":                           {},
+	"X-Gomodel-Provenance-Provider": {},
+}
+
+// identifyingHeaderPrefixes are prefixes of provider-specific header families.
+var identifyingHeaderPrefixes = []string{
+	"Openai-",
+	"Anthropic-",
+	"X-Ratelimit-",
+	"X-Amzn-",
+	"X-Goog-",
+	"Cf-",
+}
+
+// Mapping records which provider response a gateway-issued ID stands for.
+type Mapping struct {
+	GatewayID  string    `json:"gateway_id"`
+	ProviderID string    `json:"provider_id"`
+	Provider   string    `json:"provider,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Sanitizer issues gateway response IDs and keeps the most recent mappings
+// back to provider IDs in memory.
+type Sanitizer struct {
+	mu       sync.Mutex
+	mappings map[string]Mapping
+	order    []string // gateway IDs, oldest first
+	max      int
+	now      func() time.Time
+	newID    func() string
+}
+
+// New returns a Sanitizer for cfg, or nil when sanitization is disabled.
+func New(cfg config.ResponseSanitizationConfig) *Sanitizer {
+	if !cfg.Enabled {
+		return nil
+	}
+	maxMappings := cfg.MaxMappings
+	if maxMappings <= 0 {
+		maxMappings = DefaultMaxMappings
+	}
+	return &Sanitizer{
+		mappings: make(map[string]Mapping),
+		max:      maxMappings,
+		now:      time.Now,
+		newID: func() string {
+			return IDPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+		},
+	}
+}
+
+// Lookup returns the mapping of a gateway-issued ID. Mappings evicted to stay
+// within the configured maximum are no longer found.
+func (s *Sanitizer) Lookup(gatewayID string) (Mapping, bool) {
+	if s == nil {
+		return Mapping{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.mappings[strings.TrimSpace(gatewayID)]
+	return m, ok
+}
+
+func (s *Sanitizer) record(m Mapping) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.order) >= s.max {
+		delete(s.mappings, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.mappings[m.GatewayID] = m
+	s.order = append(s.order, m.GatewayID)
+}
+
+// StripHeaders removes provider-identifying headers from header.
+func StripHeaders(header http.Header) {
+	for name := range header {
+		if IsIdentifyingHeader(name) {
+			header.Del(name)
+		}
+	}
+}
+
+// IsIdentifyingHeader reports whether name is a response header that can tell
+// a client which provider or account served the request.
+func IsIdentifyingHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if _, ok := identifyingHeaders[name]; ok {
+		return true
+	}
+	for _, prefix := range identifyingHeaderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Response rewrites the bodies of one response. Every body carrying the same
+// provider ID gets the same gateway ID, so the chunks of a stream stay
+// consistent.
+type Response struct {
+	s         *Sanitizer
+	requestID string
+	provider  string
+	ids       map[string]string
+}
+
+// Response starts rewriting the response to one gateway request served by
+// provider.
+func (s *Sanitizer) Response(requestID, provider string) *Response {
+	return &Response{s: s, requestID: requestID, provider: provider, ids: make(map[string]string)}
+}
+
+// Rewrite returns a chat completion body or stream chunk with
+// system_fingerprint removed and its id replaced by a gateway-issued one. The
+// remaining bytes are kept in their original order. Bodies that are not JSON
+// objects are returned unchanged with ok false.
+func (r *Response) Rewrite(body []byte) ([]byte, bool) {
+	if !gjson.ValidBytes(body) {
+		return body, false
+	}
+	value := gjson.ParseBytes(body)
+	if !value.IsObject() {
+		return body, false
+	}
+
+	out := make([]byte, 0, len(body))
+	out = append(out, '{')
+	first := true
+	value.ForEach(func(key, item gjson.Result) bool {
+		if key.Str == "system_fingerprint" {
+			return true
+		}
+		if !first {
+			out = append(out, ',')
+		}
+		first = false
+		out = append(out, key.Raw...)
+		out = append(out, ':')
+		if key.Str == "id" && item.Type == gjson.String && item.Str != "" {
+			out = appendJSONString(out, r.gatewayID(item.Str, value.Get("model").String()))
+			return true
+		}
+		out = append(out, item.Raw...)
+		return true
+	})
+	return append(out, '}'), true
+}
+
+func (r *Response) gatewayID(providerID, model string) string {
+	if id, ok := r.ids[providerID]; ok {
+		return id
+	}
+	id := r.s.newID()
+	r.ids[providerID] = id
+	r.s.record(Mapping{
+		GatewayID:  id,
+		ProviderID: providerID,
+		Provider:   r.provider,
+		Model:      model,
+		RequestID:  r.requestID,
+		CreatedAt:  r.s.now().UTC(),
+	})
+	return id
+}
+
+// appendJSONString appends s as a JSON string. Gateway IDs are plain ASCII
+// and need no escaping.
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = append(dst, s...)
+	return append(dst, '"')
+}
@@ -0,0 +1,85 @@
+package sanitize
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+)
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	if s := New(config.ResponseSanitizationConfig{}); s != nil {
+		t.Fatalf("New() = %v, want nil when disabled", s)
+	}
+	if _, ok := (*Sanitizer)(nil).Lookup("gm-1"); ok {
+		t.Fatal("Lookup() on a nil Sanitizer found a mapping")
+	}
+}
+
+func TestRewrite_ReplacesIDAndDropsFingerprint(t *testing.T) {
+	s := New(config.ResponseSanitizationConfig{Enabled: true})
+	r := s.Response("req-1", "openai")
+
+	got, ok := r.Rewrite([]byte(`{"id":"chatcmpl-abc","object":"chat.completion.chunk","system_fingerprint":"fp_1","model":"gpt-4o","choices":[]}`))
+	if !ok {
+		t.Fatal("Rewrite() ok = false")
+	}
+	gatewayID := strings.TrimSuffix(strings.TrimPrefix(string(got), `{"id":"`), `","object":"chat.completion.chunk","model":"gpt-4o","choices":[]}`)
+	if !strings.HasPrefix(gatewayID, IDPrefix) || strings.Contains(gatewayID, `"`) {
+		t.Fatalf("Rewrite() = %s, want the id replaced in place and system_fingerprint dropped", got)
+	}
+
+	again, _ := r.Rewrite([]byte(`{"id":"chatcmpl-abc","choices":[]}`))
+	if string(again) != `{"id":"`+gatewayID+`","choices":[]}` {
+		t.Fatalf("second chunk = %s, want the same gateway id %s", again, gatewayID)
+	}
+
+	mapping, ok := s.Lookup(gatewayID)
+	want := Mapping{GatewayID: gatewayID, ProviderID: "chatcmpl-abc", Provider: "openai", Model: "gpt-4o", RequestID: "req-1", CreatedAt: mapping.CreatedAt}
+	if !ok || mapping != want {
+		t.Fatalf("Lookup() = %+v, %v; want %+v", mapping, ok, want)
+	}
+}
+
+func TestRewrite_LeavesNonObjectsUnchanged(t *testing.T) {
+	r := New(config.ResponseSanitizationConfig{Enabled: true}).Response("req-1", "openai")
+	for _, body := range []string{`[DONE]`, `["a"]`, `{"id":`} {
+		if got, ok := r.Rewrite([]byte(body)); ok || string(got) != body {
+			t.Fatalf("Rewrite(%s) = %s, %v; want it unchanged", body, got, ok)
+		}
+	}
+}
+
+func TestLookup_EvictsOldestMappings(t *testing.T) {
+	s := New(config.ResponseSanitizationConfig{Enabled: true, MaxMappings: 2})
+	var ids []string
+	for _, providerID := range []string{"a", "b", "c"} {
+		ids = append(ids, s.Response("req-"+providerID, "openai").gatewayID(providerID, ""))
+	}
+
+	if _, ok := s.Lookup(ids[0]); ok {
+		t.Fatalf("Lookup(%q) found the oldest mapping, want it evicted", ids[0])
+	}
+	for _, id := range ids[1:] {
+		if _, ok := s.Lookup(id); !ok {
+			t.Fatalf("Lookup(%q) missed a recent mapping", id)
+		}
+	}
+}
+
+func TestStripHeaders(t *testing.T) {
+	header := http.Header{
+		"Content-Type":                 {"application/json"},
+		"Openai-Organization":          {"acme"},
+		"Anthropic-Ratelimit-Requests": {"50"},
+		"Request-Id":                   {"req_1"},
+		"X-Request-Id":                 {"req_2"},
+		"X-Gomodel-Experiment":         {"exp"},
+	}
+	StripHeaders(header)
+
+	if len(header) != 2 || header.Get("Content-Type") == "" || header.Get("X-GoModel-Experiment") == "" {
+		t.Fatalf("headers = %v, want only Content-Type and X-GoModel-Experiment kept", header)
+	}
+}
@@ -0,0 +1,217 @@
+package scoreboard
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// pendingRequest collects usage reported while a request is in flight so the
+// middleware can attach output tokens to the final observation.
+type pendingRequest struct {
+	refs         int
+	outputTokens int
+}
+
+func (s *Scoreboard) beginRequest(requestID string) {
+	if requestID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[requestID]
+	if !ok {
+		p = &pendingRequest{}
+		s.pending[requestID] = p
+	}
+	p.refs++
+}
+
+func (s *Scoreboard) finishRequest(requestID string) int {
+	if requestID == "" {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pending[requestID]
+	if !ok {
+		return 0
+	}
+	p.refs--
+	if p.refs <= 0 {
+		delete(s.pending, requestID)
+	}
+	return p.outputTokens
+}
+
+func (s *Scoreboard) observeUsage(entry *usage.UsageEntry) {
+	if entry == nil || entry.RequestID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.pending[entry.RequestID]; ok {
+		p.outputTokens += entry.OutputTokens
+	}
+}
+
+// usageTap forwards usage entries to the wrapped logger and reports output
+// tokens of in-flight requests to the scoreboard.
+type usageTap struct {
+	usage.LoggerInterface
+	board *Scoreboard
+}
+
+func (t *usageTap) Write(entry *usage.UsageEntry) {
+	t.board.observeUsage(entry)
+	t.LoggerInterface.Write(entry)
+}
+
+// WrapUsageLogger returns a usage logger that also feeds token counts into
+// the scoreboard. It returns logger unchanged when either argument is nil.
+// Tokens per second are only available while usage tracking is enabled.
+func WrapUsageLogger(logger usage.LoggerInterface, board *Scoreboard) usage.LoggerInterface {
+	if logger == nil || board == nil {
+		return logger
+	}
+	return &usageTap{LoggerInterface: logger, board: board}
+}
+
+// streamStalledKey marks a request whose provider stream stalled.
+const streamStalledKey = "gomodel_scoreboard_stream_stalled"
+
+// MarkStreamStalled reports that the provider stream of the current request
+// paused past the chunk stall threshold. The middleware counts it towards the
+// stall rate of the request's provider and model.
+func MarkStreamStalled(c *echo.Context) {
+	if c != nil {
+		c.Set(streamStalledKey, true)
+	}
+}
+
+// Middleware records the outcome of every model interaction request that was
+// routed to a concrete provider. Cache hits are not recorded because they say
+// nothing about provider performance.
+func Middleware(board *Scoreboard) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if board == nil || !core.IsModelInteractionPath(c.Request().URL.Path) {
+				return next(c)
+			}
+
+			start := board.now()
+			requestID := c.Request().Header.Get("X-Request-ID")
+			board.beginRequest(requestID)
+
+			recorder := &firstWriteRecorder{ResponseWriter: c.Response(), now: board.now}
+			c.SetResponse(recorder)
+
+			err := next(c)
+
+			outputTokens := board.finishRequest(requestID)
+			provider, providerType, model := routeFromWorkflow(core.GetWorkflow(c.Request().Context()))
+			if provider == "" || model == "" || c.Response().Header().Get("X-Cache") != "" {
+				return err
+			}
+
+			_, status := echo.ResolveResponseStatus(c.Response(), err)
+			stream := strings.HasPrefix(strings.ToLower(c.Response().Header().Get("Content-Type")), "text/event-stream")
+			stalled, _ := c.Get(streamStalledKey).(bool)
+			obs := Observation{
+				At:           start,
+				Provider:     provider,
+				ProviderType: providerType,
+				Model:        model,
+				Latency:      board.now().Sub(start),
+				Stream:       stream,
+				Stalled:      stream && stalled,
+				Failed:       isProviderFailure(status),
+				OutputTokens: outputTokens,
+			}
+			if first := recorder.firstWrite(); stream && !first.IsZero() {
+				obs.TTFT = first.Sub(start)
+			}
+			board.Record(obs)
+			return err
+		}
+	}
+}
+
+// isProviderFailure reports whether a status reflects on the provider rather
+// than on the client request.
+func isProviderFailure(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
+
+func routeFromWorkflow(workflow *core.Workflow) (provider, providerType, model string) {
+	if workflow == nil || workflow.Resolution == nil {
+		return "", "", ""
+	}
+	resolution := workflow.Resolution
+	model = strings.TrimSpace(resolution.ResolvedSelector.Model)
+	providerType = strings.TrimSpace(workflow.ProviderType)
+	if providerType == "" {
+		providerType = strings.TrimSpace(resolution.ProviderType)
+	}
+	provider = strings.TrimSpace(resolution.ProviderName)
+	if provider == "" {
+		provider = strings.TrimSpace(resolution.ResolvedSelector.Provider)
+	}
+	if provider == "" {
+		provider = providerType
+	}
+	return provider, providerType, model
+}
+
+// firstWriteRecorder remembers when the first body bytes were written, which
+// for SSE responses is the time the first upstream chunk reached the client.
+type firstWriteRecorder struct {
+	http.ResponseWriter
+	now   func() time.Time
+	mu    sync.Mutex
+	first time.Time
+}
+
+func (r *firstWriteRecorder) Write(b []byte) (int, error) {
+	if len(b) > 0 {
+		r.mu.Lock()
+		if r.first.IsZero() {
+			r.first = r.now()
+		}
+		r.mu.Unlock()
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *firstWriteRecorder) firstWrite() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.first
+}
+
+// Flush implements http.Flusher so SSE streaming keeps working.
+func (r *firstWriteRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer supports it.
+func (r *firstWriteRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (r *firstWriteRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
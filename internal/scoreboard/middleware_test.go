@@ -0,0 +1,158 @@
+package scoreboard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+type recordingUsageLogger struct {
+	entries []*usage.UsageEntry
+}
+
+func (l *recordingUsageLogger) Write(entry *usage.UsageEntry) { l.entries = append(l.entries, entry) }
+func (l *recordingUsageLogger) Config() usage.Config          { return usage.Config{Enabled: true} }
+func (l *recordingUsageLogger) Flush(context.Context) error   { return nil }
+func (l *recordingUsageLogger) Close() error                  { return nil }
+
+func routedWorkflow(providerName, providerType, model string) *core.Workflow {
+	return &core.Workflow{
+		ProviderType: providerType,
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Model: model, Provider: providerType},
+			ProviderType:     providerType,
+			ProviderName:     providerName,
+		},
+	}
+}
+
+// serveThroughMiddleware runs handler behind the scoreboard middleware with
+// the given workflow already resolved, as the workflow middleware would.
+func serveThroughMiddleware(t *testing.T, board *Scoreboard, path string, workflow *core.Workflow, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	e.Use(Middleware(board))
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if workflow != nil {
+				c.SetRequest(c.Request().WithContext(core.WithWorkflow(c.Request().Context(), workflow)))
+			}
+			return next(c)
+		}
+	})
+	e.POST(path, handler)
+
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware_RecordsStreamWithTTFTAndTokens(t *testing.T) {
+	clock := newFakeClock()
+	board := New(WithClock(clock.Now))
+	usageLogger := &recordingUsageLogger{}
+	tapped := WrapUsageLogger(usageLogger, board)
+
+	rec := serveThroughMiddleware(t, board, "/v1/chat/completions", routedWorkflow("openai-eu", "openai", "gpt-5"), func(c *echo.Context) error {
+		c.Response().Header().Set("Content-Type", "text/event-stream")
+		c.Response().WriteHeader(http.StatusOK)
+		clock.Advance(200 * time.Millisecond)
+		_, _ = c.Response().Write([]byte("data: {}\n\n"))
+		clock.Advance(800 * time.Millisecond)
+		_, _ = c.Response().Write([]byte("data: [DONE]\n\n"))
+		tapped.Write(&usage.UsageEntry{RequestID: "req-1", OutputTokens: 40})
+		return nil
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if len(usageLogger.entries) != 1 {
+		t.Fatalf("wrapped logger received %d entries, want 1", len(usageLogger.entries))
+	}
+
+	stats, ok := board.Stats("openai-eu", "gpt-5", Window5m)
+	if !ok {
+		t.Fatal("request was not recorded")
+	}
+	if stats.ProviderType != "openai" || stats.Streams != 1 || stats.Errors != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if stats.LatencyP50Ms != 1000 || stats.TTFTP50Ms != 200 {
+		t.Fatalf("latency/ttft = %v/%v, want 1000/200", stats.LatencyP50Ms, stats.TTFTP50Ms)
+	}
+	if !approxEqual(stats.TokensPerSecond, 50) {
+		t.Fatalf("TokensPerSecond = %v, want 50", stats.TokensPerSecond)
+	}
+	if len(board.pending) != 0 {
+		t.Fatalf("pending requests leaked: %d", len(board.pending))
+	}
+}
+
+func TestMiddleware_CountsProviderFailures(t *testing.T) {
+	board := New()
+	workflow := routedWorkflow("anthropic", "anthropic", "claude")
+
+	serveThroughMiddleware(t, board, "/v1/chat/completions", workflow, func(c *echo.Context) error {
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": "upstream"})
+	})
+	serveThroughMiddleware(t, board, "/v1/chat/completions", workflow, func(c *echo.Context) error {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "bad input"})
+	})
+
+	stats, ok := board.Stats("anthropic", "claude", Window5m)
+	if !ok || stats.Requests != 2 || stats.Errors != 1 {
+		t.Fatalf("stats = %+v, ok %v; want 2 requests with 1 provider failure", stats, ok)
+	}
+}
+
+func TestMiddleware_SkipsUnroutedCachedAndNonModelRequests(t *testing.T) {
+	board := New()
+
+	serveThroughMiddleware(t, board, "/v1/chat/completions", nil, func(c *echo.Context) error {
+		return c.NoContent(http.StatusBadRequest)
+	})
+	serveThroughMiddleware(t, board, "/v1/chat/completions", routedWorkflow("openai", "openai", "gpt-5"), func(c *echo.Context) error {
+		c.Response().Header().Set("X-Cache", "HIT (exact)")
+		return c.JSON(http.StatusOK, map[string]string{})
+	})
+	serveThroughMiddleware(t, board, "/admin/api/v1/models", routedWorkflow("openai", "openai", "gpt-5"), func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{})
+	})
+
+	if board.Len() != 0 {
+		t.Fatalf("Len() = %d, want nothing recorded", board.Len())
+	}
+}
+
+func TestMiddleware_CountsStalledStreams(t *testing.T) {
+	board := New()
+	workflow := routedWorkflow("openai", "openai", "gpt-5")
+	for _, stalled := range []bool{true, false, false, false} {
+		serveThroughMiddleware(t, board, "/v1/chat/completions", workflow, func(c *echo.Context) error {
+			c.Response().Header().Set("Content-Type", "text/event-stream")
+			c.Response().WriteHeader(http.StatusOK)
+			_, _ = c.Response().Write([]byte("data: [DONE]\n\n"))
+			if stalled {
+				MarkStreamStalled(c)
+			}
+			return nil
+		})
+	}
+
+	stats, ok := board.Stats("openai", "gpt-5", Window5m)
+	if !ok {
+		t.Fatal("requests were not recorded")
+	}
+	if stats.Streams != 4 || stats.StalledStreams != 1 || !approxEqual(stats.StallRate, 0.25) {
+		t.Fatalf("streams = %d, stalled = %d, stall rate = %v; want 4, 1, 0.25", stats.Streams, stats.StalledStreams, stats.StallRate)
+	}
+}
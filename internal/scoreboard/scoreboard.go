@@ -0,0 +1,294 @@
+// Package scoreboard keeps rolling-window performance statistics per
+// provider and model from the gateway's own traffic.
+//
+// Statistics live in memory only. Request, error and token counters are kept
+// in one-minute buckets covering the longest window, and latency percentiles
+// are computed from a bounded ring of the most recent successful requests.
+// The number of tracked provider+model pairs is capped; the least recently
+// used pair is evicted when a new one arrives at the cap.
+package scoreboard
+
+import (
+	"cmp"
+	"container/list"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Window selects the time range a snapshot covers.
+type Window string
+
+const (
+	Window5m  Window = "5m"
+	Window1h  Window = "1h"
+	Window24h Window = "24h"
+)
+
+// DefaultWindow is used when no window is requested.
+const DefaultWindow = Window5m
+
+const (
+	// DefaultMaxModels is the default cap on tracked provider+model pairs.
+	DefaultMaxModels = 100
+	// DefaultMaxSamples is the default number of latency samples kept per pair.
+	DefaultMaxSamples = 1024
+)
+
+const (
+	// seriesBaseBytes estimates a series without its latency samples: the
+	// minute buckets of seven 8-byte counters and the bookkeeping around them.
+	seriesBaseBytes = int64(bucketCount)*56 + 256
+	// sampleBytes is the size of one latency sample.
+	sampleBytes = 32
+)
+
+// ParseWindow parses a window name. An empty string selects DefaultWindow.
+func ParseWindow(value string) (Window, bool) {
+	switch w := Window(strings.ToLower(strings.TrimSpace(value))); w {
+	case "":
+		return DefaultWindow, true
+	case Window5m, Window1h, Window24h:
+		return w, true
+	default:
+		return "", false
+	}
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	switch w {
+	case Window1h:
+		return time.Hour
+	case Window24h:
+		return 24 * time.Hour
+	default:
+		return 5 * time.Minute
+	}
+}
+
+// Observation is the outcome of one upstream request.
+type Observation struct {
+	At           time.Time
+	Provider     string // configured provider instance name
+	ProviderType string
+	Model        string
+	Latency      time.Duration // full request duration, including the whole stream
+	TTFT         time.Duration // time to first byte for streams; zero otherwise
+	Stream       bool
+	Stalled      bool // a stream whose provider paused past the chunk stall threshold
+	Failed       bool
+	OutputTokens int
+}
+
+// ModelStats summarizes one provider+model pair over a window. Latency and
+// TTFT percentiles only consider successful requests.
+type ModelStats struct {
+	Provider        string    `json:"provider"`
+	ProviderType    string    `json:"provider_type,omitempty"`
+	Model           string    `json:"model"`
+	Requests        int64     `json:"requests"`
+	Errors          int64     `json:"errors"`
+	ErrorRate       float64   `json:"error_rate"`
+	Streams         int64     `json:"streams"`
+	StalledStreams  int64     `json:"stalled_streams"`
+	StallRate       float64   `json:"stall_rate"`
+	LatencyP50Ms    float64   `json:"latency_p50_ms"`
+	LatencyP95Ms    float64   `json:"latency_p95_ms"`
+	TTFTP50Ms       float64   `json:"ttft_p50_ms"`
+	TTFTP95Ms       float64   `json:"ttft_p95_ms"`
+	TokensPerSecond float64   `json:"tokens_per_second"`
+	LastSeen        time.Time `json:"last_seen"`
+}
+
+// Snapshot is the scoreboard state for one window. Models are ordered by
+// model name, then by median latency so the fastest provider comes first.
+type Snapshot struct {
+	Window      Window       `json:"window"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Models      []ModelStats `json:"models"`
+}
+
+type seriesKey struct {
+	provider string
+	model    string
+}
+
+// Scoreboard aggregates observations. It is safe for concurrent use.
+type Scoreboard struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	maxModels  int
+	maxSamples int
+	series     map[seriesKey]*list.Element
+	lru        *list.List // front is most recently used; values are *series
+	pending    map[string]*pendingRequest
+}
+
+// Option configures a Scoreboard.
+type Option func(*Scoreboard)
+
+// WithMaxModels caps the number of tracked provider+model pairs.
+func WithMaxModels(n int) Option {
+	return func(s *Scoreboard) {
+		if n > 0 {
+			s.maxModels = n
+		}
+	}
+}
+
+// WithMaxSamples sets how many recent latency samples are kept per pair.
+func WithMaxSamples(n int) Option {
+	return func(s *Scoreboard) {
+		if n > 0 {
+			s.maxSamples = n
+		}
+	}
+}
+
+// WithClock overrides the time source. Intended for tests.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scoreboard) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// New creates an empty scoreboard.
+func New(opts ...Option) *Scoreboard {
+	s := &Scoreboard{
+		now:        time.Now,
+		maxModels:  DefaultMaxModels,
+		maxSamples: DefaultMaxSamples,
+		series:     make(map[seriesKey]*list.Element),
+		lru:        list.New(),
+		pending:    make(map[string]*pendingRequest),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Record adds one observation. Observations without a provider or model are
+// ignored.
+func (s *Scoreboard) Record(obs Observation) {
+	if s == nil {
+		return
+	}
+	key := seriesKey{provider: strings.TrimSpace(obs.Provider), model: strings.TrimSpace(obs.Model)}
+	if key.provider == "" || key.model == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if obs.At.IsZero() {
+		obs.At = s.now()
+	}
+
+	var sr *series
+	if elem, ok := s.series[key]; ok {
+		s.lru.MoveToFront(elem)
+		sr = elem.Value.(*series)
+	} else {
+		for s.lru.Len() >= s.maxModels {
+			oldest := s.lru.Back()
+			s.lru.Remove(oldest)
+			delete(s.series, oldest.Value.(*series).key)
+		}
+		sr = newSeries(key, s.maxSamples)
+		s.series[key] = s.lru.PushFront(sr)
+	}
+	sr.record(obs)
+}
+
+// Snapshot returns statistics for every pair with traffic inside the window.
+func (s *Scoreboard) Snapshot(window Window) Snapshot {
+	snapshot := Snapshot{Window: window, Models: []ModelStats{}}
+	if s == nil {
+		snapshot.GeneratedAt = time.Now().UTC()
+		return snapshot
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		if stats, ok := elem.Value.(*series).stats(now, window.Duration()); ok {
+			snapshot.Models = append(snapshot.Models, stats)
+		}
+	}
+	s.mu.Unlock()
+
+	snapshot.GeneratedAt = now.UTC()
+	slices.SortFunc(snapshot.Models, func(a, b ModelStats) int {
+		return cmp.Or(
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.LatencyP50Ms, b.LatencyP50Ms),
+			cmp.Compare(a.Provider, b.Provider),
+		)
+	})
+	return snapshot
+}
+
+// Stats returns statistics for a single provider+model pair. ok is false when
+// the pair has no traffic inside the window.
+func (s *Scoreboard) Stats(provider, model string, window Window) (ModelStats, bool) {
+	if s == nil {
+		return ModelStats{}, false
+	}
+	key := seriesKey{provider: strings.TrimSpace(provider), model: strings.TrimSpace(model)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.series[key]
+	if !ok {
+		return ModelStats{}, false
+	}
+	return elem.Value.(*series).stats(s.now(), window.Duration())
+}
+
+// MemoryUsage returns the number of tracked provider+model pairs and their
+// estimated bytes.
+func (s *Scoreboard) MemoryUsage() (int, int64) {
+	if s == nil {
+		return 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bytes := int64(s.lru.Len()) * seriesBaseBytes
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		bytes += int64(cap(elem.Value.(*series).samples)) * sampleBytes
+	}
+	return s.lru.Len(), bytes
+}
+
+// Evict drops the least recently used fraction of the tracked pairs, rounded
+// up. A dropped pair starts over with its next observation.
+func (s *Scoreboard) Evict(fraction float64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(int(math.Ceil(fraction*float64(s.lru.Len()))), s.lru.Len())
+	for range n {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.series, oldest.Value.(*series).key)
+	}
+}
+
+// Len returns the number of tracked provider+model pairs.
+func (s *Scoreboard) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
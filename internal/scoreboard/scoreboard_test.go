@@ -0,0 +1,270 @@
+package scoreboard
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 10, 12, 0, 30, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Window
+		ok   bool
+	}{
+		{"", Window5m, true},
+		{"5m", Window5m, true},
+		{" 1H ", Window1h, true},
+		{"24h", Window24h, true},
+		{"7d", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWindow(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseWindow(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScoreboard_Percentiles(t *testing.T) {
+	clock := newFakeClock()
+	board := New(WithClock(clock.Now))
+
+	// Recorded out of order so the percentile math has to sort.
+	for _, ms := range []int{100, 1, 50, 99, 2} {
+		board.Record(Observation{Provider: "openai", Model: "gpt-5", Latency: time.Duration(ms) * time.Millisecond})
+	}
+	for ms := 3; ms <= 98; ms++ {
+		if ms == 50 {
+			continue
+		}
+		board.Record(Observation{Provider: "openai", Model: "gpt-5", Latency: time.Duration(ms) * time.Millisecond})
+	}
+
+	stats, ok := board.Stats("openai", "gpt-5", Window5m)
+	if !ok {
+		t.Fatal("Stats() found no traffic")
+	}
+	if stats.Requests != 100 {
+		t.Fatalf("Requests = %d, want 100", stats.Requests)
+	}
+	if stats.LatencyP50Ms != 50 || stats.LatencyP95Ms != 95 {
+		t.Fatalf("latency p50/p95 = %v/%v, want 50/95", stats.LatencyP50Ms, stats.LatencyP95Ms)
+	}
+}
+
+func TestPercentileMs(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []time.Duration
+		p      float64
+		want   float64
+	}{
+		{"empty", nil, 0.5, 0},
+		{"single", []time.Duration{7 * time.Millisecond}, 0.95, 7},
+		{"two values median", []time.Duration{20 * time.Millisecond, 10 * time.Millisecond}, 0.5, 10},
+		{"two values p95", []time.Duration{20 * time.Millisecond, 10 * time.Millisecond}, 0.95, 20},
+		{"fractional", []time.Duration{1500 * time.Microsecond}, 0.5, 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentileMs(tt.values, tt.p); got != tt.want {
+				t.Fatalf("percentileMs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreboard_ErrorRateTTFTAndThroughput(t *testing.T) {
+	clock := newFakeClock()
+	board := New(WithClock(clock.Now))
+
+	board.Record(Observation{
+		Provider: "anthropic", ProviderType: "anthropic", Model: "claude",
+		Latency: 3 * time.Second, TTFT: time.Second, Stream: true, OutputTokens: 200,
+	})
+	board.Record(Observation{
+		Provider: "anthropic", Model: "claude",
+		Latency: 2 * time.Second, OutputTokens: 100,
+	})
+	board.Record(Observation{Provider: "anthropic", Model: "claude", Latency: 10 * time.Millisecond, Failed: true})
+	board.Record(Observation{Provider: "anthropic", Model: "claude", Latency: 20 * time.Millisecond, Failed: true})
+
+	stats, ok := board.Stats("anthropic", "claude", Window1h)
+	if !ok {
+		t.Fatal("Stats() found no traffic")
+	}
+	if stats.Requests != 4 || stats.Errors != 2 || stats.Streams != 1 {
+		t.Fatalf("counts = %d/%d/%d, want 4 requests, 2 errors, 1 stream", stats.Requests, stats.Errors, stats.Streams)
+	}
+	if !approxEqual(stats.ErrorRate, 0.5) {
+		t.Fatalf("ErrorRate = %v, want 0.5", stats.ErrorRate)
+	}
+	// Failed requests do not contribute latency samples.
+	if stats.LatencyP50Ms != 2000 || stats.LatencyP95Ms != 3000 {
+		t.Fatalf("latency p50/p95 = %v/%v, want 2000/3000", stats.LatencyP50Ms, stats.LatencyP95Ms)
+	}
+	if stats.TTFTP50Ms != 1000 || stats.TTFTP95Ms != 1000 {
+		t.Fatalf("ttft p50/p95 = %v/%v, want 1000/1000", stats.TTFTP50Ms, stats.TTFTP95Ms)
+	}
+	// 300 tokens over 2s of streamed generation plus 2s for the buffered response.
+	if !approxEqual(stats.TokensPerSecond, 75) {
+		t.Fatalf("TokensPerSecond = %v, want 75", stats.TokensPerSecond)
+	}
+	if stats.ProviderType != "anthropic" {
+		t.Fatalf("ProviderType = %q, want anthropic", stats.ProviderType)
+	}
+}
+
+func TestScoreboard_WindowExpiry(t *testing.T) {
+	clock := newFakeClock()
+	board := New(WithClock(clock.Now))
+
+	board.Record(Observation{Provider: "openai", Model: "gpt-5", Latency: 900 * time.Millisecond})
+	clock.Advance(30 * time.Minute)
+	board.Record(Observation{Provider: "openai", Model: "gpt-5", Latency: 100 * time.Millisecond})
+
+	stats, ok := board.Stats("openai", "gpt-5", Window5m)
+	if !ok || stats.Requests != 1 || stats.LatencyP95Ms != 100 {
+		t.Fatalf("5m stats = %+v, ok %v; want only the recent request", stats, ok)
+	}
+	stats, ok = board.Stats("openai", "gpt-5", Window1h)
+	if !ok || stats.Requests != 2 || stats.LatencyP95Ms != 900 {
+		t.Fatalf("1h stats = %+v, ok %v; want both requests", stats, ok)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if _, ok := board.Stats("openai", "gpt-5", Window1h); ok {
+		t.Fatal("1h window still reports traffic after two idle hours")
+	}
+	if stats, ok := board.Stats("openai", "gpt-5", Window24h); !ok || stats.Requests != 2 {
+		t.Fatalf("24h stats = %+v, ok %v; want both requests", stats, ok)
+	}
+
+	// A request recorded a day later reuses the ring slot of the first one.
+	clock.Advance(22*time.Hour - 30*time.Minute)
+	board.Record(Observation{Provider: "openai", Model: "gpt-5", Latency: 50 * time.Millisecond})
+	stats, ok = board.Stats("openai", "gpt-5", Window24h)
+	if !ok || stats.Requests != 2 {
+		t.Fatalf("24h stats after wrap = %+v, ok %v; want 2 requests", stats, ok)
+	}
+	if snapshot := board.Snapshot(Window5m); len(snapshot.Models) != 1 || snapshot.Models[0].Requests != 1 {
+		t.Fatalf("5m snapshot after wrap = %+v", snapshot.Models)
+	}
+}
+
+func TestScoreboard_SampleRingKeepsMostRecent(t *testing.T) {
+	board := New(WithMaxSamples(4))
+	for ms := 1; ms <= 10; ms++ {
+		board.Record(Observation{Provider: "p", Model: "m", Latency: time.Duration(ms) * time.Millisecond})
+	}
+	stats, _ := board.Stats("p", "m", Window5m)
+	if stats.Requests != 10 {
+		t.Fatalf("Requests = %d, want 10", stats.Requests)
+	}
+	// Samples 7..10 remain.
+	if stats.LatencyP50Ms != 8 || stats.LatencyP95Ms != 10 {
+		t.Fatalf("latency p50/p95 = %v/%v, want 8/10", stats.LatencyP50Ms, stats.LatencyP95Ms)
+	}
+}
+
+func TestScoreboard_EvictsLeastRecentlyUsed(t *testing.T) {
+	board := New(WithMaxModels(2))
+	board.Record(Observation{Provider: "p", Model: "a", Latency: time.Millisecond})
+	board.Record(Observation{Provider: "p", Model: "b", Latency: time.Millisecond})
+	board.Record(Observation{Provider: "p", Model: "a", Latency: time.Millisecond})
+	board.Record(Observation{Provider: "p", Model: "c", Latency: time.Millisecond})
+
+	if board.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", board.Len())
+	}
+	if _, ok := board.Stats("p", "b", Window5m); ok {
+		t.Fatal("least recently used pair was not evicted")
+	}
+	for _, model := range []string{"a", "c"} {
+		if _, ok := board.Stats("p", model, Window5m); !ok {
+			t.Fatalf("pair %q was evicted", model)
+		}
+	}
+}
+
+func TestScoreboard_SnapshotOrdersFastestFirst(t *testing.T) {
+	board := New()
+	board.Record(Observation{Provider: "slow", Model: "gpt-5", Latency: 300 * time.Millisecond})
+	board.Record(Observation{Provider: "fast", Model: "gpt-5", Latency: 100 * time.Millisecond})
+	board.Record(Observation{Provider: "any", Model: "claude", Latency: time.Second})
+	board.Record(Observation{Provider: "", Model: "ignored"})
+
+	snapshot := board.Snapshot(Window5m)
+	var got []string
+	for _, stats := range snapshot.Models {
+		got = append(got, stats.Provider+"/"+stats.Model)
+	}
+	want := []string{"any/claude", "fast/gpt-5", "slow/gpt-5"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("snapshot order = %v, want %v", got, want)
+	}
+}
+
+func TestScoreboard_ConcurrentRecord(t *testing.T) {
+	board := New(WithMaxModels(8))
+	var wg sync.WaitGroup
+	for worker := range 16 {
+		wg.Go(func() {
+			for i := range 200 {
+				board.Record(Observation{
+					Provider: fmt.Sprintf("p%d", worker%4),
+					Model:    fmt.Sprintf("m%d", i%4),
+					Latency:  time.Duration(i) * time.Millisecond,
+					Failed:   i%10 == 0,
+				})
+				if i%50 == 0 {
+					board.Snapshot(Window1h)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if board.Len() > 8 {
+		t.Fatalf("Len() = %d, want at most 8", board.Len())
+	}
+}
+
+func TestScoreboard_NilIsSafe(t *testing.T) {
+	var board *Scoreboard
+	board.Record(Observation{Provider: "p", Model: "m"})
+	if snapshot := board.Snapshot(Window5m); snapshot.Models == nil || len(snapshot.Models) != 0 {
+		t.Fatalf("nil Snapshot() = %+v, want empty models", snapshot)
+	}
+	if _, ok := board.Stats("p", "m", Window5m); ok {
+		t.Fatal("nil Stats() reported traffic")
+	}
+}
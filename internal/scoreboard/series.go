@@ -0,0 +1,161 @@
+package scoreboard
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	bucketWidth = time.Minute
+	bucketCount = int(24 * time.Hour / bucketWidth)
+)
+
+// bucket holds the counters for one minute of traffic. minute identifies the
+// minute the bucket currently represents so stale slots can be detected when
+// the ring wraps.
+type bucket struct {
+	minute       int64
+	requests     int64
+	errors       int64
+	streams      int64
+	stalled      int64
+	outputTokens int64
+	generation   time.Duration
+}
+
+// sample is one successful request kept for percentile calculation.
+type sample struct {
+	at      int64 // unix nanoseconds
+	latency time.Duration
+	ttft    time.Duration
+	stream  bool
+}
+
+// series is the rolling state of one provider+model pair.
+type series struct {
+	key          seriesKey
+	providerType string
+	lastSeen     time.Time
+	buckets      [bucketCount]bucket
+	samples      []sample
+	nextSample   int
+}
+
+func newSeries(key seriesKey, maxSamples int) *series {
+	return &series{key: key, samples: make([]sample, 0, maxSamples)}
+}
+
+func (s *series) record(obs Observation) {
+	if obs.ProviderType != "" {
+		s.providerType = obs.ProviderType
+	}
+	if obs.At.After(s.lastSeen) {
+		s.lastSeen = obs.At
+	}
+
+	minute := obs.At.Unix() / int64(bucketWidth/time.Second)
+	b := &s.buckets[int(minute%int64(bucketCount))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.requests++
+	if obs.Stream {
+		b.streams++
+		if obs.Stalled {
+			b.stalled++
+		}
+	}
+	if obs.Failed {
+		b.errors++
+		return
+	}
+
+	if obs.OutputTokens > 0 {
+		generation := obs.Latency
+		if obs.Stream && obs.TTFT > 0 && obs.TTFT < obs.Latency {
+			generation = obs.Latency - obs.TTFT
+		}
+		if generation > 0 {
+			b.outputTokens += int64(obs.OutputTokens)
+			b.generation += generation
+		}
+	}
+
+	smp := sample{at: obs.At.UnixNano(), latency: obs.Latency, ttft: obs.TTFT, stream: obs.Stream}
+	if len(s.samples) < cap(s.samples) {
+		s.samples = append(s.samples, smp)
+		return
+	}
+	s.samples[s.nextSample] = smp
+	s.nextSample = (s.nextSample + 1) % len(s.samples)
+}
+
+// stats aggregates the series over [now-window, now]. Counters use minute
+// granularity: the bucket containing the window start is included in full.
+func (s *series) stats(now time.Time, window time.Duration) (ModelStats, bool) {
+	stats := ModelStats{
+		Provider:     s.key.provider,
+		ProviderType: s.providerType,
+		Model:        s.key.model,
+		LastSeen:     s.lastSeen.UTC(),
+	}
+
+	since := now.Add(-window)
+	firstMinute := since.Unix() / int64(bucketWidth/time.Second)
+	lastMinute := now.Unix() / int64(bucketWidth/time.Second)
+	var tokens int64
+	var generation time.Duration
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if b.requests == 0 || b.minute < firstMinute || b.minute > lastMinute {
+			continue
+		}
+		stats.Requests += b.requests
+		stats.Errors += b.errors
+		stats.Streams += b.streams
+		stats.StalledStreams += b.stalled
+		tokens += b.outputTokens
+		generation += b.generation
+	}
+	if stats.Requests == 0 {
+		return ModelStats{}, false
+	}
+	stats.ErrorRate = float64(stats.Errors) / float64(stats.Requests)
+	if stats.Streams > 0 {
+		stats.StallRate = float64(stats.StalledStreams) / float64(stats.Streams)
+	}
+	if generation > 0 {
+		stats.TokensPerSecond = float64(tokens) / generation.Seconds()
+	}
+
+	sinceNano := since.UnixNano()
+	latencies := make([]time.Duration, 0, len(s.samples))
+	var ttfts []time.Duration
+	for _, smp := range s.samples {
+		if smp.at < sinceNano {
+			continue
+		}
+		latencies = append(latencies, smp.latency)
+		if smp.stream && smp.ttft > 0 {
+			ttfts = append(ttfts, smp.ttft)
+		}
+	}
+	stats.LatencyP50Ms = percentileMs(latencies, 0.50)
+	stats.LatencyP95Ms = percentileMs(latencies, 0.95)
+	stats.TTFTP50Ms = percentileMs(ttfts, 0.50)
+	stats.TTFTP95Ms = percentileMs(ttfts, 0.95)
+	return stats, true
+}
+
+// percentileMs returns the nearest-rank percentile of values in milliseconds.
+// It sorts values in place and returns 0 for an empty slice.
+func percentileMs(values []time.Duration, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	slices.Sort(values)
+	rank := int(math.Ceil(p * float64(len(values))))
+	rank = min(max(rank, 1), len(values))
+	return float64(values[rank-1]) / float64(time.Millisecond)
+}
@@ -0,0 +1,507 @@
+// Package secretscan finds API keys, tokens and private keys that users paste
+// into prompts, so the gateway can mask them or block the request before it
+// is audited or forwarded to a provider.
+//
+// Every detector is one alternative of a single combined regular expression,
+// so a text is scanned once however many detectors are enabled. Detectors
+// name the literals their matches start with, and the expression only runs,
+// anchored, where one of them occurs: text without any costs a few substring
+// searches, and text with secrets never pays for an unanchored regular
+// expression search. A custom pattern without a literal prefix turns this
+// off and every text is searched with the expression.
+package secretscan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+)
+
+const (
+	// ActionMask replaces the secret with a placeholder naming its detector.
+	ActionMask = "mask"
+	// ActionBlock rejects the request. The secret is masked as well, so the
+	// audit log of the rejected request does not keep it.
+	ActionBlock = "block"
+	// ActionLog forwards the request unchanged and only records the
+	// detection.
+	ActionLog = "log"
+
+	// actionOff disables a built-in detector.
+	actionOff = "off"
+)
+
+// Paths the scanner reads message content from.
+const (
+	ChatCompletionsPath = "/v1/chat/completions"
+	ResponsesPath       = "/v1/responses"
+)
+
+// Detector is a named secret format. Hints are literals every match starts
+// with. Boundary rejects matches that continue a word, like a leading \b.
+type Detector struct {
+	Name     string
+	Pattern  string
+	Hints    []string
+	Boundary bool
+}
+
+// Builtin lists the built-in detectors. Where several match at the same
+// position the first one listed wins, so specific formats come before the
+// general ones they overlap with.
+var Builtin = []Detector{
+	{
+		Name:    "private_key",
+		Pattern: `-----BEGIN[A-Z ]{0,32}PRIVATE KEY-----[\s\S]*?-----END[A-Z ]{0,32}PRIVATE KEY-----`,
+		Hints:   []string{"-----BEGIN"},
+	},
+	{
+		Name:     "anthropic_api_key",
+		Pattern:  `sk-ant-[A-Za-z0-9_-]{20,}`,
+		Hints:    []string{"sk-ant-"},
+		Boundary: true,
+	},
+	{
+		Name:     "openai_api_key",
+		Pattern:  `sk-[A-Za-z0-9_-]{20,}`,
+		Hints:    []string{"sk-"},
+		Boundary: true,
+	},
+	{
+		Name:     "aws_access_key_id",
+		Pattern:  `(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+		Hints:    []string{"AKIA", "ASIA"},
+		Boundary: true,
+	},
+	{
+		Name:     "github_token",
+		Pattern:  `(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`,
+		Hints:    []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_"},
+		Boundary: true,
+	},
+	{
+		Name:     "jwt",
+		Pattern:  `eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`,
+		Hints:    []string{"eyJ"},
+		Boundary: true,
+	},
+}
+
+type detector struct {
+	name     string
+	action   string
+	boundary bool
+	// group is the index of the detector's capture group in the combined
+	// expression.
+	group int
+}
+
+// Scanner finds secrets in request content. A nil Scanner is disabled.
+type Scanner struct {
+	re *regexp.Regexp
+	// anchored is re anchored at the start of the text, run where a hint
+	// occurs.
+	anchored  *regexp.Regexp
+	detectors []detector
+	// hints is nil when some detector has no literal to look for, in which
+	// case every text is searched with re.
+	hints []string
+}
+
+// New returns the scanner for cfg, or nil when secret redaction is disabled.
+func New(cfg config.SecretRedactionConfig) (*Scanner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	defaultAction, err := parseAction(cfg.Action, ActionMask)
+	if err != nil {
+		return nil, fmt.Errorf("action: %w", err)
+	}
+
+	overrides := make(map[string]string, len(cfg.Detectors))
+	for name, value := range cfg.Detectors {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isBuiltin(name) {
+			return nil, fmt.Errorf("detectors: unknown detector %q: must be one of %s", name, strings.Join(BuiltinNames(), ", "))
+		}
+		if strings.EqualFold(strings.TrimSpace(value), actionOff) {
+			overrides[name] = actionOff
+			continue
+		}
+		action, err := parseAction(value, defaultAction)
+		if err != nil {
+			return nil, fmt.Errorf("detectors[%q]: %w", name, err)
+		}
+		overrides[name] = action
+	}
+
+	type entry struct {
+		Detector
+		action string
+	}
+	var entries []entry
+	seen := make(map[string]struct{})
+	for _, d := range Builtin {
+		seen[d.Name] = struct{}{}
+		action := defaultAction
+		if override, ok := overrides[d.Name]; ok {
+			action = override
+		}
+		if action != actionOff {
+			entries = append(entries, entry{Detector: d, action: action})
+		}
+	}
+	for i, p := range cfg.Patterns {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			return nil, fmt.Errorf("patterns[%d]: name is required", i)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("patterns[%d]: detector %q is already defined", i, name)
+		}
+		seen[name] = struct{}{}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("patterns[%d] (%s): %w", i, name, err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("patterns[%d] (%s): pattern must not match empty text", i, name)
+		}
+		action, err := parseAction(p.Action, defaultAction)
+		if err != nil {
+			return nil, fmt.Errorf("patterns[%d] (%s): %w", i, name, err)
+		}
+		d := Detector{Name: name, Pattern: p.Pattern}
+		if prefix, _ := re.LiteralPrefix(); prefix != "" {
+			d.Hints = []string{prefix}
+		}
+		entries = append(entries, entry{Detector: d, action: action})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("every detector is off and no patterns are configured")
+	}
+
+	s := &Scanner{hints: []string{}}
+	alternatives := make([]string, 0, len(entries))
+	group := 1
+	for _, e := range entries {
+		// Compiled above or built in; only the group count is needed here.
+		sub := regexp.MustCompile(e.Pattern)
+		s.detectors = append(s.detectors, detector{name: e.Name, action: e.action, boundary: e.Boundary, group: group})
+		group += 1 + sub.NumSubexp()
+		alternatives = append(alternatives, "("+e.Pattern+")")
+		if len(e.Hints) == 0 {
+			s.hints = nil
+		} else if s.hints != nil {
+			s.hints = append(s.hints, e.Hints...)
+		}
+	}
+	s.hints = pruneHints(s.hints)
+	combined := strings.Join(alternatives, "|")
+	s.re = regexp.MustCompile(combined)
+	s.anchored = regexp.MustCompile(`^(?:` + combined + `)`)
+	return s, nil
+}
+
+// BuiltinNames returns the names of the built-in detectors.
+func BuiltinNames() []string {
+	names := make([]string, len(Builtin))
+	for i, d := range Builtin {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// Detectors returns the enabled detectors with their actions.
+func (s *Scanner) Detectors() map[string]string {
+	detectors := make(map[string]string, len(s.detectors))
+	for _, d := range s.detectors {
+		detectors[d.name] = d.action
+	}
+	return detectors
+}
+
+// Applies reports whether requests to path are scanned.
+func (s *Scanner) Applies(path string) bool {
+	return s != nil && (path == ChatCompletionsPath || path == ResponsesPath)
+}
+
+// ScanBody scans the message content of a chat completion request, or the
+// input and instructions of a Responses API request, sent to path. It
+// returns the body with masked secrets replaced, or nil when nothing was
+// masked, and the detections, or nil when there were none.
+func (s *Scanner) ScanBody(path string, body []byte) ([]byte, *core.SecretRedactionResult) {
+	if s == nil || len(body) == 0 {
+		return nil, nil
+	}
+	var texts []gjson.Result
+	switch path {
+	case ChatCompletionsPath:
+		gjson.GetBytes(body, "messages").ForEach(func(_, message gjson.Result) bool {
+			texts = appendContent(texts, message.Get("content"))
+			return true
+		})
+	case ResponsesPath:
+		if instructions := gjson.GetBytes(body, "instructions"); instructions.Type == gjson.String {
+			texts = append(texts, instructions)
+		}
+		input := gjson.GetBytes(body, "input")
+		if input.Type == gjson.String {
+			texts = append(texts, input)
+		} else {
+			input.ForEach(func(_, item gjson.Result) bool {
+				texts = appendContent(texts, item.Get("content"))
+				return true
+			})
+		}
+	}
+
+	counts := make([]int, len(s.detectors))
+	type edit struct {
+		start, end int
+		raw        []byte
+	}
+	var edits []edit
+	for _, text := range texts {
+		redacted, changed := s.redact(text.Str, counts)
+		if changed && text.Index > 0 {
+			edits = append(edits, edit{start: text.Index, end: text.Index + len(text.Raw), raw: encodeString(redacted)})
+		}
+	}
+
+	result := s.result(counts)
+	if result == nil || len(edits) == 0 {
+		return nil, result
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	out := make([]byte, 0, len(body))
+	last := 0
+	for _, e := range edits {
+		out = append(out, body[last:e.start]...)
+		out = append(out, e.raw...)
+		last = e.end
+	}
+	return append(out, body[last:]...), result
+}
+
+// Mask returns text with every secret replaced by its placeholder, whatever
+// the action of its detector, for copies of content shown to operators.
+func (s *Scanner) Mask(text string) string {
+	if s == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	s.find(text, func(start, end, i int) {
+		b.WriteString(text[last:start])
+		b.WriteString(Placeholder(s.detectors[i].name))
+		last = end
+	})
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// redact scans text, adds its detections to counts, which is indexed like
+// the scanner's detectors, and returns text with the secrets of detectors
+// that mask or block replaced. changed reports whether anything was.
+func (s *Scanner) redact(text string, counts []int) (string, bool) {
+	var b strings.Builder
+	last := 0
+	s.find(text, func(start, end, i int) {
+		counts[i]++
+		d := s.detectors[i]
+		if d.action == ActionLog {
+			return
+		}
+		if b.Len() == 0 {
+			b.Grow(len(text))
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(Placeholder(d.name))
+		last = end
+	})
+	if b.Len() == 0 {
+		return text, false
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+// find calls match with the start, end and detector index of each secret in
+// text, in order.
+func (s *Scanner) find(text string, match func(start, end, detector int)) {
+	if s.hints == nil {
+		for _, m := range s.re.FindAllStringSubmatchIndex(text, -1) {
+			if i := s.detectorOf(m); s.startsMatch(text, m[0], i) {
+				match(m[0], m[1], i)
+			}
+		}
+		return
+	}
+
+	// next holds the position of each hint's next occurrence at or after
+	// pos, or -1 once it has none.
+	next := make([]int, len(s.hints))
+	for h, hint := range s.hints {
+		next[h] = strings.Index(text, hint)
+	}
+	pos := 0
+	for {
+		start := -1
+		for h, n := range next {
+			if n >= 0 && n < pos {
+				if n = strings.Index(text[pos:], s.hints[h]); n >= 0 {
+					n += pos
+				}
+				next[h] = n
+			}
+			if n >= 0 && (start < 0 || n < start) {
+				start = n
+			}
+		}
+		if start < 0 {
+			return
+		}
+		m := s.anchored.FindStringSubmatchIndex(text[start:])
+		if m == nil {
+			pos = start + 1
+			continue
+		}
+		i := s.detectorOf(m)
+		if !s.startsMatch(text, start, i) {
+			pos = start + 1
+			continue
+		}
+		match(start, start+m[1], i)
+		pos = start + m[1]
+	}
+}
+
+// startsMatch reports whether detector i may match at start, which a
+// boundary detector may not in the middle of a word.
+func (s *Scanner) startsMatch(text string, start, i int) bool {
+	return !s.detectors[i].boundary || start == 0 || !isWordByte(text[start-1])
+}
+
+// pruneHints drops duplicate hints and hints starting with a shorter one,
+// whose occurrences the shorter one already finds.
+func pruneHints(hints []string) []string {
+	if hints == nil {
+		return nil
+	}
+	pruned := make([]string, 0, len(hints))
+	for i, hint := range hints {
+		covered := false
+		for j, other := range hints {
+			if j != i && strings.HasPrefix(hint, other) && (len(other) < len(hint) || j < i) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			pruned = append(pruned, hint)
+		}
+	}
+	return pruned
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// Placeholder is the text that replaces a masked secret.
+func Placeholder(detector string) string {
+	return "[REDACTED:" + detector + "]"
+}
+
+// detectorOf returns the index of the detector whose group matched.
+func (s *Scanner) detectorOf(match []int) int {
+	for i, d := range s.detectors {
+		if match[2*d.group] >= 0 {
+			return i
+		}
+	}
+	return len(s.detectors) - 1
+}
+
+func (s *Scanner) result(counts []int) *core.SecretRedactionResult {
+	var result *core.SecretRedactionResult
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if result == nil {
+			result = &core.SecretRedactionResult{}
+		}
+		d := s.detectors[i]
+		result.Detections = append(result.Detections, core.SecretDetection{Detector: d.name, Action: d.action, Count: count})
+		if d.action == ActionBlock {
+			result.Blocked = true
+		}
+	}
+	if result != nil {
+		sort.Slice(result.Detections, func(i, j int) bool {
+			return result.Detections[i].Detector < result.Detections[j].Detector
+		})
+	}
+	return result
+}
+
+// appendContent appends content when it is a string, or the text of each of
+// its parts when it is an array.
+func appendContent(texts []gjson.Result, content gjson.Result) []gjson.Result {
+	switch {
+	case content.Type == gjson.String:
+		return append(texts, content)
+	case content.IsArray():
+		content.ForEach(func(_, part gjson.Result) bool {
+			if text := part.Get("text"); text.Type == gjson.String {
+				texts = append(texts, text)
+			}
+			return true
+		})
+	}
+	return texts
+}
+
+// encodeString returns the JSON form of text without HTML escaping, so the
+// rest of a rewritten string keeps its original characters.
+func encodeString(text string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(text)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func parseAction(value, fallback string) (string, error) {
+	action := strings.ToLower(strings.TrimSpace(value))
+	switch action {
+	case "":
+		return fallback, nil
+	case ActionMask, ActionBlock, ActionLog:
+		return action, nil
+	default:
+		return "", fmt.Errorf("must be %q, %q or %q, got %q", ActionMask, ActionBlock, ActionLog, value)
+	}
+}
+
+func isBuiltin(name string) bool {
+	for _, d := range Builtin {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+// Package selfprotect screens model requests for prompts and tool definitions
+// that point a tool-using client at the gateway's own admin API or internal
+// hosts.
+//
+// Matching is deliberately cheap and string-only: a case-insensitive substring
+// search over the raw body rules out almost every request, and only a hit (or
+// a body with JSON escapes that could hide one) is decoded to find which
+// string value matched. Object keys are never matched, so a request is only
+// caught by what it says, not by how its fields are named.
+package selfprotect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gomodel/config"
+)
+
+// AdminAPIPattern is always matched: no model request has a reason to name
+// the gateway's admin API.
+const AdminAPIPattern = "/admin/api/"
+
+const (
+	// ActionReject answers a matching request with a 400.
+	ActionReject = "reject"
+	// ActionFlag forwards a matching request and only records the match.
+	ActionFlag = "flag"
+)
+
+// Finding describes the first pattern a request matched.
+type Finding struct {
+	// Pattern is the configured pattern, lowercased.
+	Pattern string `json:"pattern"`
+	// Location is the JSON path of the matching string value, for example
+	// "tools[0].function.description" or "messages[1].content".
+	Location string `json:"location"`
+}
+
+// Guard scans request bodies. A nil Guard is disabled.
+type Guard struct {
+	action   string
+	patterns [][]byte
+	notifier *notifier
+}
+
+// New returns the guard for cfg, or nil when self-protection is disabled.
+func New(cfg config.SelfProtectionConfig) (*Guard, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	action := strings.ToLower(strings.TrimSpace(cfg.Action))
+	switch action {
+	case "":
+		action = ActionReject
+	case ActionReject, ActionFlag:
+	default:
+		return nil, fmt.Errorf("action must be %q or %q, got %q", ActionReject, ActionFlag, cfg.Action)
+	}
+
+	seen := map[string]struct{}{AdminAPIPattern: {}}
+	patterns := [][]byte{[]byte(AdminAPIPattern)}
+	for _, pattern := range cfg.Patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		patterns = append(patterns, []byte(pattern))
+	}
+
+	guard := &Guard{action: action, patterns: patterns}
+	if webhookURL := strings.TrimSpace(cfg.WebhookURL); webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook_url must be an absolute http(s) URL, got %q", cfg.WebhookURL)
+		}
+		guard.notifier = newNotifier(webhookURL)
+	}
+	return guard, nil
+}
+
+// Action returns ActionReject or ActionFlag.
+func (g *Guard) Action() string {
+	return g.action
+}
+
+// Patterns returns the matched patterns, lowercased, the admin API path first.
+func (g *Guard) Patterns() []string {
+	patterns := make([]string, len(g.patterns))
+	for i, pattern := range g.patterns {
+		patterns[i] = string(pattern)
+	}
+	return patterns
+}
+
+// Scan reports the first pattern a string value of the JSON body contains.
+func (g *Guard) Scan(body []byte) (Finding, bool) {
+	if g == nil || len(body) == 0 {
+		return Finding{}, false
+	}
+	lower := bytes.ToLower(body)
+	if !g.matchesAny(lower) && !bytes.Contains(body, []byte(`\/`)) && !bytes.Contains(body, []byte(`\u`)) {
+		return Finding{}, false
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return Finding{}, false
+	}
+	pattern, path, ok := g.scanValue(decoded)
+	if !ok {
+		return Finding{}, false
+	}
+	return Finding{Pattern: pattern, Location: formatPath(path)}, true
+}
+
+// Notify sends the webhook event for a matching request, if a webhook is
+// configured. It never blocks the request.
+func (g *Guard) Notify(event Event) {
+	if g == nil || g.notifier == nil {
+		return
+	}
+	event.Type = eventType
+	event.Action = g.action
+	g.notifier.enqueue(event)
+}
+
+// Close stops the webhook sender after it delivers the queued events.
+func (g *Guard) Close() {
+	if g == nil || g.notifier == nil {
+		return
+	}
+	g.notifier.close()
+}
+
+func (g *Guard) matchesAny(lower []byte) bool {
+	for _, pattern := range g.patterns {
+		if bytes.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanValue walks decoded JSON and returns the first matching pattern with the
+// path to the string holding it, innermost segment first.
+func (g *Guard) scanValue(value any) (string, []string, bool) {
+	switch v := value.(type) {
+	case string:
+		lower := []byte(strings.ToLower(v))
+		for _, pattern := range g.patterns {
+			if bytes.Contains(lower, pattern) {
+				return string(pattern), nil, true
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if pattern, path, ok := g.scanValue(v[key]); ok {
+				return pattern, append(path, "."+key), true
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if pattern, path, ok := g.scanValue(item); ok {
+				return pattern, append(path, "["+strconv.Itoa(i)+"]"), true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// formatPath joins path segments collected innermost first.
+func formatPath(path []string) string {
+	var b strings.Builder
+	for i := len(path) - 1; i >= 0; i-- {
+		b.WriteString(path[i])
+	}
+	return strings.TrimPrefix(b.String(), ".")
+}
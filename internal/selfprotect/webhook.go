@@ -0,0 +1,112 @@
+package selfprotect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"gomodel/internal/httpclient"
+)
+
+const (
+	eventType = "self_protection"
+
+	// webhookQueueSize bounds the events waiting for delivery. Events beyond
+	// it are dropped so a slow receiver cannot hold request memory.
+	webhookQueueSize = 256
+	webhookTimeout   = 5 * time.Second
+)
+
+// Event is the webhook payload for a matching request. It names the pattern
+// and where it matched, never the request content.
+type Event struct {
+	Type      string    `json:"type"`
+	RequestID string    `json:"request_id,omitempty"`
+	Action    string    `json:"action"`
+	Pattern   string    `json:"pattern"`
+	Location  string    `json:"location"`
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// notifier posts events to the webhook from a single background goroutine.
+type notifier struct {
+	url    string
+	client *http.Client
+	events chan Event
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newNotifier(webhookURL string) *notifier {
+	clientCfg := httpclient.DefaultConfig()
+	clientCfg.Timeout = webhookTimeout
+	clientCfg.ResponseHeaderTimeout = webhookTimeout
+	n := &notifier{
+		url:    webhookURL,
+		client: httpclient.NewHTTPClient(&clientCfg),
+		events: make(chan Event, webhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go n.run()
+	return n
+}
+
+func (n *notifier) enqueue(event Event) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.events <- event:
+	default:
+		slog.Warn("self-protection webhook queue full; dropping event", "request_id", event.RequestID)
+	}
+}
+
+func (n *notifier) run() {
+	defer close(n.done)
+	for event := range n.events {
+		n.send(event)
+	}
+}
+
+func (n *notifier) send(event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		slog.Warn("self-protection webhook failed", "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		slog.Warn("self-protection webhook rejected event", "status", resp.StatusCode)
+	}
+}
+
+func (n *notifier) close() {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.events)
+	}
+	n.mu.Unlock()
+	<-n.done
+}
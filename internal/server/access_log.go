@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/accesslog"
+)
+
+// AccessLog writes one access log line per request. It is installed first, so
+// it covers every request including health checks, admin calls and requests
+// rejected by later middleware, and counts the bytes that actually reach the
+// client. Errors returned by the handler chain are passed to the error
+// handler before the line is written, so their status and body are counted
+// too; streamed responses are logged when the stream completes.
+func AccessLog(logger *accesslog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			start := time.Now()
+			writer := &accessLogWriter{ResponseWriter: c.Response()}
+			c.SetResponse(writer)
+
+			err := next(c)
+			if err != nil {
+				// The error handler skips responses that are already committed,
+				// so the error that bubbles up is not answered twice.
+				c.Echo().HTTPErrorHandler(c, err)
+			}
+			c.SetResponse(writer.ResponseWriter)
+
+			req := c.Request()
+			status := writer.status
+			if status == 0 {
+				_, status = echo.ResolveResponseStatus(writer.ResponseWriter, err)
+			}
+			requestID := requestIDFromContextOrHeader(req)
+			if requestID == "" {
+				requestID = writer.Header().Get("X-Request-ID")
+			}
+			logger.Log(&accesslog.Entry{
+				Time:      start,
+				RemoteIP:  c.RealIP(),
+				Host:      req.Host,
+				Method:    req.Method,
+				URI:       req.RequestURI,
+				Protocol:  req.Proto,
+				Status:    status,
+				Bytes:     writer.bytes,
+				Latency:   time.Since(start),
+				RequestID: requestID,
+				Referer:   req.Referer(),
+				UserAgent: req.UserAgent(),
+			})
+			return err
+		}
+	}
+}
+
+// accessLogWriter records the status and body size of the response written
+// through it.
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	if w.status < http.StatusOK {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = pendingResponseStatus(w.ResponseWriter)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets WebSocket upgrades through; the hijacked connection's traffic
+// is not counted.
+func (w *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/accesslog"
+	"gomodel/internal/core"
+)
+
+type accessLogLine struct {
+	Method    string  `json:"method"`
+	URI       string  `json:"uri"`
+	Status    int     `json:"status"`
+	Bytes     int64   `json:"bytes"`
+	LatencyMS float64 `json:"latency_ms"`
+	RequestID string  `json:"request_id"`
+}
+
+func newAccessLogTestServer(t *testing.T, mock *mockProvider, cfg *Config) (*Server, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	logger, err := accesslog.NewWriter(config.AccessLogConfig{Format: "json"}, &out)
+	if err != nil {
+		t.Fatalf("accesslog.NewWriter() error = %v", err)
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	cfg.AccessLog = logger
+	return New(mock, cfg), &out
+}
+
+func accessLogLines(t *testing.T, out *bytes.Buffer) []accessLogLine {
+	t.Helper()
+	var lines []accessLogLine
+	for line := range strings.Lines(out.String()) {
+		var parsed accessLogLine
+		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
+			t.Fatalf("decode access log line %q: %v", line, err)
+		}
+		lines = append(lines, parsed)
+	}
+	return lines
+}
+
+func TestAccessLog_LogsEveryRequest(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{
+		ID:      "chatcmpl-1",
+		Object:  "chat.completion",
+		Model:   "gpt-4o-mini",
+		Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "hello"}}},
+	}}
+	srv, out := newAccessLogTestServer(t, mock, &Config{MasterKey: "secret"})
+
+	health := httptest.NewRecorder()
+	srv.ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/health", nil))
+	unauthorized := httptest.NewRecorder()
+	srv.ServeHTTP(unauthorized, chaosChatRequest(false))
+	chat := httptest.NewRecorder()
+	req := chaosChatRequest(false)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Request-ID", "req-access-1")
+	srv.ServeHTTP(chat, req)
+
+	lines := accessLogLines(t, out)
+	if len(lines) != 3 {
+		t.Fatalf("access log has %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	for i, rec := range []*httptest.ResponseRecorder{health, unauthorized, chat} {
+		line := lines[i]
+		if line.Status != rec.Code || line.Bytes != int64(rec.Body.Len()) || line.RequestID == "" {
+			t.Errorf("line %d = %+v, want status %d and %d bytes with a request ID", i, line, rec.Code, rec.Body.Len())
+		}
+	}
+	if lines[0].URI != "/health" || lines[1].Status != http.StatusUnauthorized {
+		t.Fatalf("lines = %+v", lines)
+	}
+	if lines[2].Method != http.MethodPost || lines[2].RequestID != "req-access-1" {
+		t.Fatalf("chat line = %+v", lines[2])
+	}
+}
+
+func TestAccessLog_LogsStreamAtCompletion(t *testing.T) {
+	streamData := strings.Join([]string{
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hel"},"finish_reason":null}]}`,
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: streamData}
+	srv, out := newAccessLogTestServer(t, mock, nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	lines := accessLogLines(t, out)
+	if len(lines) != 1 {
+		t.Fatalf("access log has %d lines, want 1:\n%s", len(lines), out.String())
+	}
+	if lines[0].Status != http.StatusOK || lines[0].Bytes != int64(rec.Body.Len()) || rec.Body.Len() < len(streamData)/2 {
+		t.Fatalf("line = %+v, want the whole stream of %d bytes", lines[0], rec.Body.Len())
+	}
+}
+
+func TestAccessLog_LogsUnknownRoute(t *testing.T) {
+	srv, out := newAccessLogTestServer(t, &mockProvider{}, nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope?x=1", nil))
+
+	lines := accessLogLines(t, out)
+	if len(lines) != 1 || lines[0].Status != http.StatusNotFound || lines[0].URI != "/nope?x=1" || lines[0].Bytes != int64(rec.Body.Len()) {
+		t.Fatalf("lines = %+v, response %d with %d bytes", lines, rec.Code, rec.Body.Len())
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
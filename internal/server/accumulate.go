@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/gateway"
+	"gomodel/internal/streaming"
+)
+
+const accumulatedJSONObject = "accumulated_json"
+
+// accumulateJSONRequested reports whether a streaming request asked the
+// gateway to accumulate the stream into one JSON response.
+func accumulateJSONRequested(c *echo.Context) (bool, error) {
+	opts, err := requestOptions(c)
+	if err != nil {
+		return false, core.NewInvalidRequestError(err.Error(), err)
+	}
+	return opts != nil && opts.Accumulate == core.AccumulateJSON, nil
+}
+
+// handleAccumulatedStream drains an upstream SSE stream, assembles its text
+// and answers with a single core.AccumulatedJSONResponse. The audit entry is
+// recorded like a non-streaming request; usage is taken from the stream.
+func (s *translatedInferenceService) handleAccumulatedStream(
+	c *echo.Context,
+	workflow *core.Workflow,
+	meta gateway.ExecutionMeta,
+	started time.Time,
+	stream io.ReadCloser,
+) error {
+	stream = applyChaosStreamFault(c, stream)
+	auditlog.EnrichEntryWithStream(c, true)
+	auditlog.EnrichEntryWithFailover(c, meta.FailoverModel)
+	auditlog.EnrichEntryWithResolvedRoute(c, qualifyExecutedModel(workflow, meta.Model, meta.ProviderName), meta.ProviderType, meta.ProviderName)
+
+	accumulator := &jsonStreamAccumulator{}
+	observed := streaming.NewObservedSSEStream(stream,
+		accumulator,
+		s.streamUsageObserver(c, workflow, meta.Model, meta.ProviderType, meta.ProviderName),
+	)
+
+	var ttfb time.Duration
+	var readErr error
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := observed.Read(buf)
+		if n > 0 && ttfb == 0 {
+			ttfb = time.Since(started)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	// Closing flushes the final buffered event to the observers and records usage.
+	_ = observed.Close() //nolint:errcheck
+	duration := time.Since(started)
+
+	if readErr != nil {
+		return handleError(c, core.NewProviderError(meta.ProviderType, http.StatusBadGateway, "upstream stream failed: "+readErr.Error(), readErr))
+	}
+	if accumulator.err != "" {
+		return handleError(c, core.NewProviderError(meta.ProviderType, http.StatusBadGateway, accumulator.err, nil))
+	}
+
+	resp := accumulator.response()
+	auditlog.EnrichEntryWithServedModel(c, resp.Model)
+	auditlog.EnrichEntryWithResponseID(c, resp.ID, "")
+	if resp.Model == "" {
+		resp.Model = meta.Model
+	}
+	resp.Provider = meta.ProviderType
+	resp.Timing = core.AccumulationTiming{TTFBMs: ttfb.Milliseconds(), DurationMs: duration.Milliseconds()}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// jsonStreamAccumulator assembles the output text of a chat completions or
+// responses SSE stream.
+type jsonStreamAccumulator struct {
+	text         strings.Builder
+	id           string
+	model        string
+	finishReason string
+	usage        *core.Usage
+	err          string
+}
+
+func (a *jsonStreamAccumulator) OnJSONEvent(payload map[string]any) {
+	if a.err != "" {
+		return
+	}
+	if errPayload, ok := payload["error"]; ok && errPayload != nil {
+		a.err = streamErrorMessage(errPayload)
+		return
+	}
+
+	eventType, _ := payload["type"].(string)
+	switch eventType {
+	case "":
+		a.onChatChunk(payload)
+	case "response.output_text.delta":
+		if delta, ok := payload["delta"].(string); ok {
+			a.text.WriteString(delta)
+		}
+	case "response.completed", "response.incomplete":
+		response, _ := payload["response"].(map[string]any)
+		a.onResponse(response)
+	case "response.failed":
+		response, _ := payload["response"].(map[string]any)
+		a.err = streamErrorMessage(response["error"])
+	case "error":
+		a.err = streamErrorMessage(payload)
+	}
+}
+
+func (a *jsonStreamAccumulator) OnStreamClose() {}
+
+func (a *jsonStreamAccumulator) onChatChunk(payload map[string]any) {
+	if id, ok := payload["id"].(string); ok && id != "" {
+		a.id = id
+	}
+	if model, ok := payload["model"].(string); ok && model != "" {
+		a.model = model
+	}
+	if choices, ok := payload["choices"].([]any); ok && len(choices) > 0 {
+		choice, _ := choices[0].(map[string]any)
+		if delta, ok := choice["delta"].(map[string]any); ok {
+			if content, ok := delta["content"].(string); ok {
+				a.text.WriteString(content)
+			}
+		}
+		if reason, ok := choice["finish_reason"].(string); ok && reason != "" {
+			a.finishReason = reason
+		}
+	}
+	var usage core.Usage
+	if decodeStreamValue(payload["usage"], &usage) {
+		a.usage = &usage
+	}
+}
+
+func (a *jsonStreamAccumulator) onResponse(response map[string]any) {
+	if response == nil {
+		return
+	}
+	if id, ok := response["id"].(string); ok && id != "" {
+		a.id = id
+	}
+	if model, ok := response["model"].(string); ok && model != "" {
+		a.model = model
+	}
+	if status, ok := response["status"].(string); ok {
+		a.finishReason = status
+	}
+	var usage core.ResponsesUsage
+	if decodeStreamValue(response["usage"], &usage) {
+		a.usage = &core.Usage{
+			PromptTokens:            usage.InputTokens,
+			CompletionTokens:        usage.OutputTokens,
+			TotalTokens:             usage.TotalTokens,
+			PromptTokensDetails:     usage.PromptTokensDetails,
+			CompletionTokensDetails: usage.CompletionTokensDetails,
+			RawUsage:                usage.RawUsage,
+		}
+	}
+}
+
+// response builds the accumulated response. Text that does not parse as JSON
+// is returned raw with ParseFailed set.
+func (a *jsonStreamAccumulator) response() *core.AccumulatedJSONResponse {
+	resp := &core.AccumulatedJSONResponse{
+		ID:           a.id,
+		Object:       accumulatedJSONObject,
+		Model:        a.model,
+		FinishReason: a.finishReason,
+		Usage:        a.usage,
+	}
+	text := a.text.String()
+	trimmed := bytes.TrimSpace([]byte(text))
+	err := errors.New("stream produced no text")
+	if len(trimmed) > 0 {
+		var parsed any
+		err = json.Unmarshal(trimmed, &parsed)
+	}
+	if err != nil {
+		resp.Text = text
+		resp.ParseFailed = true
+		resp.ParseError = err.Error()
+		return resp
+	}
+	resp.Output = json.RawMessage(trimmed)
+	return resp
+}
+
+func decodeStreamValue(value any, dst any) bool {
+	if value == nil {
+		return false
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(raw, dst) == nil
+}
+
+func streamErrorMessage(value any) string {
+	if errMap, ok := value.(map[string]any); ok {
+		if message, ok := errMap["message"].(string); ok && message != "" {
+			return message
+		}
+	}
+	if message, ok := value.(string); ok && message != "" {
+		return message
+	}
+	return "upstream stream reported an error"
+}
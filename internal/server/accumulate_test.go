@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func newAccumulateTestServer(mock *mockProvider) (*Server, *syncAuditLogger, *syncUsageLogger) {
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	return New(mock, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger}), auditLogger, usageLogger
+}
+
+func accumulateRequest(path, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.AccumulateHeader, "json")
+	return req
+}
+
+func chatStreamChunks(usageJSON string, contents ...string) string {
+	var b strings.Builder
+	for _, content := range contents {
+		delta, _ := json.Marshal(content)
+		b.WriteString(`data: {"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":` + string(delta) + `}}]}` + "\n\n")
+	}
+	b.WriteString(`data: {"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n")
+	if usageJSON != "" {
+		b.WriteString(`data: {"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[],"usage":` + usageJSON + "}\n\n")
+	}
+	b.WriteString("data: [DONE]\n\n")
+	return b.String()
+}
+
+func decodeAccumulated(t *testing.T, rec *httptest.ResponseRecorder) core.AccumulatedJSONResponse {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var resp core.AccumulatedJSONResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v; body = %s", err, rec.Body.String())
+	}
+	return resp
+}
+
+func TestAccumulateJSON_ChatValidJSON(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		streamData:      chatStreamChunks(`{"prompt_tokens":7,"completion_tokens":5,"total_tokens":12}`, `{"city":`, ` "Paris",`, ` "rank": 1}`),
+	}
+	srv, auditLogger, usageLogger := newAccumulateTestServer(mock)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, accumulateRequest("/v1/chat/completions", `{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+
+	resp := decodeAccumulated(t, rec)
+	if resp.ParseFailed || resp.Text != "" {
+		t.Fatalf("parse_failed = %v, text = %q, want parsed output", resp.ParseFailed, resp.Text)
+	}
+	var output map[string]any
+	if err := json.Unmarshal(resp.Output, &output); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if output["city"] != "Paris" || output["rank"] != float64(1) {
+		t.Fatalf("output = %v, want the assembled object", output)
+	}
+	if resp.Object != "accumulated_json" || resp.ID != "chatcmpl-1" || resp.Model != "gpt-4o-mini" || resp.FinishReason != "stop" {
+		t.Fatalf("response = %+v, want chat metadata", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 12 {
+		t.Fatalf("usage = %+v, want 12 total tokens", resp.Usage)
+	}
+
+	usageLogger.mu.Lock()
+	defer usageLogger.mu.Unlock()
+	if len(usageLogger.entries) != 1 || usageLogger.entries[0].TotalTokens != 12 {
+		t.Fatalf("usage entries = %+v, want one entry with 12 tokens", usageLogger.entries)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 {
+		t.Fatalf("audit entries = %d, want 1", len(auditLogger.entries))
+	}
+	entry := auditLogger.entries[0]
+	if entry.StatusCode != http.StatusOK || entry.Data == nil || entry.Data.ResponseBody == nil {
+		t.Fatalf("audit entry = %+v, want a non-streaming entry with the JSON response body", entry)
+	}
+}
+
+func TestAccumulateJSON_ChatInvalidJSON(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		streamData:      chatStreamChunks("", "Sure! ", `{"city": "Par`),
+	}
+	srv, _, _ := newAccumulateTestServer(mock)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, accumulateRequest("/v1/chat/completions", `{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+
+	resp := decodeAccumulated(t, rec)
+	if !resp.ParseFailed || resp.ParseError == "" {
+		t.Fatalf("parse_failed = %v, parse_error = %q, want a flagged parse failure", resp.ParseFailed, resp.ParseError)
+	}
+	if resp.Text != `Sure! {"city": "Par` || resp.Output != nil {
+		t.Fatalf("text = %q, output = %s, want the raw text only", resp.Text, resp.Output)
+	}
+}
+
+func TestAccumulateJSON_UpstreamMidStreamFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		streamData  string
+		streamErr   error
+		wantMessage string
+	}{
+		{
+			name:        "read error",
+			streamData:  `data: {"id":"chatcmpl-1","choices":[{"delta":{"content":"{\"a\""}}]}` + "\n\n",
+			streamErr:   errors.New("connection reset by peer"),
+			wantMessage: "connection reset by peer",
+		},
+		{
+			name:        "error event",
+			streamData:  `data: {"id":"chatcmpl-1","choices":[{"delta":{"content":"{\"a\""}}]}` + "\n\n" + `data: {"error":{"message":"overloaded"}}` + "\n\n",
+			wantMessage: "overloaded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: tt.streamData, streamErr: tt.streamErr}
+			srv, auditLogger, _ := newAccumulateTestServer(mock)
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, accumulateRequest("/v1/chat/completions", `{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+
+			if rec.Code != http.StatusBadGateway {
+				t.Fatalf("status = %d, want 502; body = %s", rec.Code, rec.Body.String())
+			}
+			var body map[string]map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if message, _ := body["error"]["message"].(string); !strings.Contains(message, tt.wantMessage) {
+				t.Fatalf("error message = %q, want it to contain %q", message, tt.wantMessage)
+			}
+
+			auditLogger.mu.Lock()
+			defer auditLogger.mu.Unlock()
+			if len(auditLogger.entries) != 1 || auditLogger.entries[0].StatusCode != http.StatusBadGateway {
+				t.Fatalf("audit entries = %+v, want one 502 entry", auditLogger.entries)
+			}
+		})
+	}
+}
+
+func TestAccumulateJSON_Responses(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		streamData: `data: {"type":"response.output_text.delta","delta":"[1, "}` + "\n\n" +
+			`data: {"type":"response.output_text.delta","delta":"2]"}` + "\n\n" +
+			`data: {"type":"response.completed","response":{"id":"resp_1","model":"gpt-4o-mini","status":"completed","usage":{"input_tokens":4,"output_tokens":2,"total_tokens":6}}}` + "\n\n",
+	}
+	srv, _, _ := newAccumulateTestServer(mock)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, accumulateRequest("/v1/responses", `{"model":"gpt-4o-mini","stream":true,"input":"hi"}`))
+
+	resp := decodeAccumulated(t, rec)
+	if resp.ParseFailed || string(resp.Output) != "[1,2]" {
+		t.Fatalf("output = %s, parse_failed = %v, want [1,2]", resp.Output, resp.ParseFailed)
+	}
+	if resp.ID != "resp_1" || resp.FinishReason != "completed" {
+		t.Fatalf("response = %+v, want responses metadata", resp)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 4 || resp.Usage.CompletionTokens != 2 {
+		t.Fatalf("usage = %+v, want input/output tokens mapped", resp.Usage)
+	}
+}
+
+func TestAccumulateJSON_RejectsUnknownMode(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: chatStreamChunks("", "{}")}
+	srv, _, _ := newAccumulateTestServer(mock)
+
+	req := accumulateRequest("/v1/chat/completions", `{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	req.Header.Set(core.AccumulateHeader, "xml")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -20,6 +20,12 @@ type BearerTokenAuthenticator interface {
 	Authenticate(ctx context.Context, token string) (authkeys.AuthenticationResult, error)
 }
 
+// AuthKeyIDAuthenticator re-resolves a managed auth key by ID. Deferred
+// replays require it to run on behalf of a managed key.
+type AuthKeyIDAuthenticator interface {
+	AuthenticateID(ctx context.Context, id string) (authkeys.AuthenticationResult, error)
+}
+
 // AuthMiddleware creates an Echo middleware that validates the master key
 // if it's configured. If masterKey is empty, no authentication is required.
 // skipPaths is a list of paths that should bypass authentication.
@@ -28,13 +34,47 @@ func AuthMiddleware(masterKey string, skipPaths []string) echo.MiddlewareFunc {
 }
 
 // AuthMiddlewareWithAuthenticator validates the legacy master key and, when
-// configured, managed auth keys from the auth key service.
+// configured, managed auth keys from the auth key service. The caller's
+// authkeys.Role is stored on the Echo context under authkeys.RoleContextKey;
+// the master key and unauthenticated paths always carry authkeys.RoleAdmin.
+// A managed key's user path and data residency override the matching request
+// headers.
 func AuthMiddlewareWithAuthenticator(masterKey string, authenticator BearerTokenAuthenticator, skipPaths []string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
 			// If no auth mechanism is configured, allow all requests.
 			if masterKey == "" && (authenticator == nil || !authenticator.Enabled()) {
 				auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodNoKey)
+				c.Set(authkeys.RoleContextKey, authkeys.RoleAdmin)
+				return next(c)
+			}
+
+			// Deferred replays carry no bearer token; the origin is only set
+			// in-process, never from request headers. A replay of a managed key
+			// re-resolves that key so revoked, disabled or expired keys fail.
+			if core.GetRequestOrigin(c.Request().Context()) == core.RequestOriginDeferred {
+				authKeyID := core.GetAuthKeyID(c.Request().Context())
+				if authKeyID == "" {
+					auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodMasterKey)
+					if userPath := core.GetEffectiveUserPath(c.Request().Context()); userPath != "" {
+						auditlog.EnrichEntryWithUserPath(c, userPath)
+					}
+					c.Set(authkeys.RoleContextKey, authkeys.RoleReadUsage)
+					return next(c)
+				}
+
+				auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodAPIKey)
+				resolver, ok := authenticator.(AuthKeyIDAuthenticator)
+				if !ok || !authenticator.Enabled() {
+					authErr := authenticationErrorWithAudit(c, "deferred replay key cannot be re-resolved", "authentication failed")
+					return c.JSON(authErr.HTTPStatusCode(), authErr.ToJSON())
+				}
+				authResult, err := resolver.AuthenticateID(c.Request().Context(), authKeyID)
+				if err != nil {
+					authErr := authenticationErrorWithAudit(c, authFailureMessage(err), "authentication failed")
+					return c.JSON(authErr.HTTPStatusCode(), authErr.ToJSON())
+				}
+				applyManagedKey(c, authResult)
 				return next(c)
 			}
 
@@ -46,10 +86,12 @@ func AuthMiddlewareWithAuthenticator(masterKey string, authenticator BearerToken
 					prefix := strings.TrimSuffix(skipPath, "*")
 					if strings.HasPrefix(requestPath, prefix) {
 						auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodNoKey)
+						c.Set(authkeys.RoleContextKey, authkeys.RoleAdmin)
 						return next(c)
 					}
 				} else if requestPath == skipPath {
 					auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodNoKey)
+					c.Set(authkeys.RoleContextKey, authkeys.RoleAdmin)
 					return next(c)
 				}
 			}
@@ -71,6 +113,7 @@ func AuthMiddlewareWithAuthenticator(masterKey string, authenticator BearerToken
 			token := strings.TrimPrefix(authHeader, prefix)
 			if masterKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(masterKey)) == 1 {
 				auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodMasterKey)
+				c.Set(authkeys.RoleContextKey, authkeys.RoleAdmin)
 				return next(c)
 			}
 
@@ -78,17 +121,7 @@ func AuthMiddlewareWithAuthenticator(masterKey string, authenticator BearerToken
 				auditlog.EnrichEntryWithAuthMethod(c, auditlog.AuthMethodAPIKey)
 				authResult, err := authenticator.Authenticate(c.Request().Context(), token)
 				if err == nil {
-					ctx := core.WithAuthKeyID(c.Request().Context(), authResult.ID)
-					if userPath := strings.TrimSpace(authResult.UserPath); userPath != "" {
-						ctx = core.WithEffectiveUserPath(ctx, userPath)
-						if snapshot := core.GetRequestSnapshot(ctx); snapshot != nil {
-							ctx = core.WithRequestSnapshot(ctx, snapshot.WithUserPath(userPath))
-						}
-						c.Request().Header.Set(core.UserPathHeader, userPath)
-						auditlog.EnrichEntryWithUserPath(c, userPath)
-					}
-					c.SetRequest(c.Request().WithContext(ctx))
-					auditlog.EnrichEntryWithAuthKeyID(c, authResult.ID)
+					applyManagedKey(c, authResult)
 					return next(c)
 				}
 
@@ -102,6 +135,29 @@ func AuthMiddlewareWithAuthenticator(masterKey string, authenticator BearerToken
 	}
 }
 
+// applyManagedKey attaches an authenticated managed key to the request: its
+// ID, role, and the user path and data residency that override the matching
+// request headers.
+func applyManagedKey(c *echo.Context, authResult authkeys.AuthenticationResult) {
+	ctx := core.WithAuthKeyID(c.Request().Context(), authResult.ID)
+	if userPath := strings.TrimSpace(authResult.UserPath); userPath != "" {
+		ctx = core.WithEffectiveUserPath(ctx, userPath)
+		if snapshot := core.GetRequestSnapshot(ctx); snapshot != nil {
+			ctx = core.WithRequestSnapshot(ctx, snapshot.WithUserPath(userPath))
+		}
+		c.Request().Header.Set(core.UserPathHeader, userPath)
+		auditlog.EnrichEntryWithUserPath(c, userPath)
+	}
+	if residency := strings.TrimSpace(authResult.DataResidency); residency != "" {
+		ctx = core.WithDataResidency(ctx, residency)
+		c.Request().Header.Set(core.DataResidencyHeader, residency)
+	}
+	c.SetRequest(c.Request().WithContext(ctx))
+	auditlog.EnrichEntryWithAuthKeyID(c, authResult.ID)
+	role, _ := authkeys.ParseRole(string(authResult.Role))
+	c.Set(authkeys.RoleContextKey, role)
+}
+
 func authFailureMessage(err error) string {
 	if err == nil {
 		return "invalid API key"
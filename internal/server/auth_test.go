@@ -21,6 +21,9 @@ type mockAuthenticator struct {
 	enabled   bool
 	tokenToID map[string]string
 	tokenPath map[string]string
+	tokenRole map[string]authkeys.Role
+	residency map[string]string
+	idErr     map[string]error
 	err       error
 }
 
@@ -37,11 +40,25 @@ func (m mockAuthenticator) Authenticate(_ context.Context, token string) (authke
 		return authkeys.AuthenticationResult{}, assert.AnError
 	}
 	return authkeys.AuthenticationResult{
-		ID:       id,
-		UserPath: m.tokenPath[token],
+		ID:            id,
+		UserPath:      m.tokenPath[token],
+		Role:          m.tokenRole[token],
+		DataResidency: m.residency[token],
 	}, nil
 }
 
+func (m mockAuthenticator) AuthenticateID(_ context.Context, id string) (authkeys.AuthenticationResult, error) {
+	if err := m.idErr[id]; err != nil {
+		return authkeys.AuthenticationResult{}, err
+	}
+	for token, tokenID := range m.tokenToID {
+		if tokenID == id {
+			return m.Authenticate(context.Background(), token)
+		}
+	}
+	return authkeys.AuthenticationResult{}, authkeys.ErrNotFound
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -217,6 +234,76 @@ func TestAuthMiddlewareWithAuthenticator_ManagedKeyEnrichesContextAndAudit(t *te
 	assert.Equal(t, "ok", rec.Body.String())
 }
 
+func TestAuthMiddlewareWithAuthenticator_AttachesCallerRole(t *testing.T) {
+	authenticator := mockAuthenticator{
+		enabled:   true,
+		tokenToID: map[string]string{"sk_gom_dashboard": "key-dashboard", "sk_gom_legacy": "key-legacy"},
+		tokenRole: map[string]authkeys.Role{"sk_gom_dashboard": authkeys.RoleReadUsage},
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  authkeys.Role
+	}{
+		{name: "master key", token: "master", want: authkeys.RoleAdmin},
+		{name: "scoped managed key", token: "sk_gom_dashboard", want: authkeys.RoleReadUsage},
+		{name: "managed key without role", token: "sk_gom_legacy", want: authkeys.RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got any
+			handler := AuthMiddlewareWithAuthenticator("master", authenticator, nil)(func(c *echo.Context) error {
+				got = c.Get(authkeys.RoleContextKey)
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/api/v1/usage/summary", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler(echo.New().NewContext(req, rec)))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthMiddlewareWithAuthenticator_DeferredReplayReResolvesKey(t *testing.T) {
+	authenticator := mockAuthenticator{
+		enabled:   true,
+		tokenToID: map[string]string{"sk_gom_active": "key-active", "sk_gom_revoked": "key-revoked"},
+		tokenRole: map[string]authkeys.Role{"sk_gom_active": authkeys.RoleReadUsage},
+		idErr:     map[string]error{"key-revoked": authkeys.ErrInactive},
+	}
+	tests := []struct {
+		name     string
+		keyID    string
+		wantCode int
+	}{
+		{name: "active key", keyID: "key-active", wantCode: http.StatusOK},
+		{name: "revoked key", keyID: "key-revoked", wantCode: http.StatusUnauthorized},
+		{name: "deleted key", keyID: "key-missing", wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var role any
+			handler := AuthMiddlewareWithAuthenticator("master", authenticator, nil)(func(c *echo.Context) error {
+				role = c.Get(authkeys.RoleContextKey)
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			ctx := core.WithRequestOrigin(req.Context(), core.RequestOriginDeferred)
+			req = req.WithContext(core.WithAuthKeyID(ctx, tt.keyID))
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler(echo.New().NewContext(req, rec)))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, authkeys.RoleReadUsage, role)
+			}
+		})
+	}
+}
+
 func TestAuthMiddlewareWithAuthenticator_ManagedKeyUserPathOverridesHeader(t *testing.T) {
 	e := echo.New()
 	testHandler := func(c *echo.Context) error {
@@ -543,3 +630,61 @@ func TestAuthMiddleware_ConstantTimeComparison(t *testing.T) {
 		}
 	})
 }
+
+func TestAuthMiddlewareWithAuthenticator_ManagedKeyDataResidencyOverridesHeader(t *testing.T) {
+	var gotResidency, gotHeader string
+	handler := RequestSnapshotCapture()(AuthMiddlewareWithAuthenticator("", mockAuthenticator{
+		enabled:   true,
+		tokenToID: map[string]string{"sk_gom_eu": "key-eu", "sk_gom_open": "key-open"},
+		residency: map[string]string{"sk_gom_eu": "eu"},
+	}, nil)(func(c *echo.Context) error {
+		gotResidency = core.GetDataResidency(c.Request().Context())
+		gotHeader = c.Request().Header.Get(core.DataResidencyHeader)
+		return c.NoContent(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   string
+	}{
+		{name: "key residency wins over header", token: "sk_gom_eu", header: "us", want: "eu"},
+		{name: "header applies without key residency", token: "sk_gom_open", header: " US ", want: "us"},
+		{name: "no residency", token: "sk_gom_open", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResidency, gotHeader = "", ""
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5-mini"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			if tt.header != "" {
+				req.Header.Set(core.DataResidencyHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler(echo.New().NewContext(req, rec)))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, gotResidency)
+			if tt.token == "sk_gom_eu" {
+				assert.Equal(t, "eu", gotHeader)
+			}
+		})
+	}
+}
+
+func TestRequestSnapshotCapture_RejectsInvalidDataResidencyHeader(t *testing.T) {
+	called := false
+	handler := RequestSnapshotCapture()(func(c *echo.Context) error {
+		called = true
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-5-mini"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.DataResidencyHeader, "eu/west")
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler(echo.New().NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.False(t, called)
+}
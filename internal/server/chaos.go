@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/chaos"
+	"gomodel/internal/core"
+)
+
+// ChaosInjection applies fault injection rules to model interaction requests.
+// It runs after workflow resolution so rules can match the resolved model and
+// provider. Latency and error faults apply here; stream faults are marked on
+// the request context and applied where the response stream is written. It is
+// only installed when fault injection is enabled.
+func ChaosInjection(injector *chaos.Injector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if !injector.Active() || !core.IsModelInteractionPath(c.Request().URL.Path) {
+				return next(c)
+			}
+			rule, ok := injector.Match(chaosTarget(c))
+			if !ok {
+				return next(c)
+			}
+
+			switch rule.Effect {
+			case chaos.EffectLatency:
+				markChaosFault(c, rule)
+				timer := time.NewTimer(rule.Latency())
+				select {
+				case <-timer.C:
+				case <-c.Request().Context().Done():
+					timer.Stop()
+					return c.Request().Context().Err()
+				}
+				return next(c)
+			case chaos.EffectError:
+				markChaosFault(c, rule)
+				return handleError(c, rule.Error())
+			default:
+				ctx := chaos.WithStreamFault(c.Request().Context(), injector, rule)
+				c.SetRequest(c.Request().WithContext(ctx))
+				return next(c)
+			}
+		}
+	}
+}
+
+// applyChaosStreamFault wraps stream with the stream fault marked on the
+// request, if any. It must run before the response headers are written and
+// before the streaming audit entry is copied.
+func applyChaosStreamFault(c *echo.Context, stream io.ReadCloser) io.ReadCloser {
+	wrapped, rule, ok := chaos.WrapStream(c.Request().Context(), stream)
+	if ok {
+		markChaosFault(c, rule)
+	}
+	return wrapped
+}
+
+func markChaosFault(c *echo.Context, rule chaos.Rule) {
+	c.Response().Header().Set(chaos.Header, string(rule.Effect))
+	auditlog.EnrichEntryWithChaos(c, rule.Name, string(rule.Effect))
+	serverLogger.Info("chaos fault injected",
+		"rule", rule.Name,
+		"effect", rule.Effect,
+		"path", c.Request().URL.Path,
+		"request_id", requestIDFromContextOrHeader(c.Request()),
+	)
+}
+
+func chaosTarget(c *echo.Context) chaos.Target {
+	target := chaos.Target{Path: c.Request().URL.Path}
+	workflow := core.GetWorkflow(c.Request().Context())
+	if workflow == nil {
+		return target
+	}
+	target.Providers = append(target.Providers, workflow.ProviderType)
+	if resolution := workflow.Resolution; resolution != nil {
+		target.Models = append(target.Models,
+			resolution.Requested.Model,
+			resolution.RequestedQualifiedModel(),
+			resolution.ResolvedSelector.Model,
+			resolution.ResolvedQualifiedModel(),
+		)
+		target.Providers = append(target.Providers, resolution.ProviderType, resolution.ProviderName)
+	}
+	if passthrough := workflow.Passthrough; passthrough != nil {
+		target.Models = append(target.Models, passthrough.Model)
+		target.Providers = append(target.Providers, passthrough.Provider)
+	}
+	return target
+}
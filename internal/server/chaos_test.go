@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/chaos"
+	"gomodel/internal/core"
+)
+
+func newChaosTestServer(t *testing.T, mock *mockProvider, rules ...config.ChaosRuleConfig) (*Server, *chaos.Injector, *syncAuditLogger) {
+	t.Helper()
+	injector, err := chaos.New(config.ChaosConfig{Enabled: true, Rules: rules})
+	if err != nil {
+		t.Fatalf("chaos.New() error = %v", err)
+	}
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	return New(mock, &Config{AuditLogger: logger, Chaos: injector}), injector, logger
+}
+
+func chaosChatRequest(stream bool) *http.Request {
+	body := `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]`
+	if stream {
+		body += `,"stream":true`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body+"}"))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func lastChaosSnapshot(t *testing.T, logger *syncAuditLogger) *auditlog.ChaosSnapshot {
+	t.Helper()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) == 0 {
+		t.Fatal("no audit entry written")
+	}
+	entry := logger.entries[len(logger.entries)-1]
+	if entry.Data == nil {
+		return nil
+	}
+	return entry.Data.Chaos
+}
+
+func TestChaosInjection_ErrorEffect(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok"}}
+	srv, _, logger := newChaosTestServer(t, mock, config.ChaosRuleConfig{
+		Name: "outage", Model: "gpt-4o-mini", Effect: "error", Status: http.StatusServiceUnavailable, Message: "simulated outage",
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503; body = %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body["error"]["message"] != "simulated outage" || body["error"]["code"] != "chaos_fault_injected" {
+		t.Fatalf("error body = %v, want OpenAI-shaped chaos error", body["error"])
+	}
+	if got := rec.Header().Get(chaos.Header); got != "error" {
+		t.Fatalf("%s = %q, want error", chaos.Header, got)
+	}
+	if snapshot := lastChaosSnapshot(t, logger); snapshot == nil || snapshot.Rule != "outage" || snapshot.Effect != "error" {
+		t.Fatalf("audit chaos = %+v, want outage/error", snapshot)
+	}
+}
+
+func TestChaosInjection_LatencyEffect(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"}}
+	srv, _, logger := newChaosTestServer(t, mock, config.ChaosRuleConfig{
+		Name: "slow", Path: "/v1/chat/completions", Effect: "latency", Latency: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("request took %s, want at least the injected 50ms", elapsed)
+	}
+	if got := rec.Header().Get(chaos.Header); got != "latency" {
+		t.Fatalf("%s = %q, want latency", chaos.Header, got)
+	}
+	if snapshot := lastChaosSnapshot(t, logger); snapshot == nil || snapshot.Effect != "latency" {
+		t.Fatalf("audit chaos = %+v, want latency", snapshot)
+	}
+}
+
+func TestChaosInjection_StreamEffects(t *testing.T) {
+	streamData := "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\ndata: [DONE]\n\n"
+	tests := []struct {
+		name string
+		rule config.ChaosRuleConfig
+		want string
+	}{
+		{
+			name: "truncate",
+			rule: config.ChaosRuleConfig{Name: "cut", Effect: "truncate_stream", AfterChunks: 1},
+			want: "data: {\"id\":\"1\"}\n\n",
+		},
+		{
+			name: "corrupt",
+			rule: config.ChaosRuleConfig{Name: "garble", Effect: "corrupt_stream"},
+			want: "data: {\"id\":\"1\"}\n\ndata: {\"id\":\"2\"}\n\ndata: [DO\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: streamData}
+			srv, _, logger := newChaosTestServer(t, mock, tt.rule)
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, chaosChatRequest(true))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+			}
+			if rec.Body.String() != tt.want {
+				t.Fatalf("stream body = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if got := rec.Header().Get(chaos.Header); got != tt.rule.Effect {
+				t.Fatalf("%s = %q, want %s", chaos.Header, got, tt.rule.Effect)
+			}
+			if snapshot := lastChaosSnapshot(t, logger); snapshot == nil || snapshot.Rule != tt.rule.Name {
+				t.Fatalf("audit chaos = %+v, want rule %s", snapshot, tt.rule.Name)
+			}
+		})
+	}
+}
+
+func TestChaosInjection_StreamRuleLeavesNonStreamingRequestsUnmarked(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"}}
+	srv, _, logger := newChaosTestServer(t, mock, config.ChaosRuleConfig{Name: "cut", Effect: "truncate_stream"})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK || rec.Header().Get(chaos.Header) != "" {
+		t.Fatalf("status = %d, %s = %q, want an untouched 200", rec.Code, chaos.Header, rec.Header().Get(chaos.Header))
+	}
+	if snapshot := lastChaosSnapshot(t, logger); snapshot != nil {
+		t.Fatalf("audit chaos = %+v, want none", snapshot)
+	}
+}
+
+func TestChaosInjection_KillSwitch(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"}}
+	srv, injector, _ := newChaosTestServer(t, mock, config.ChaosRuleConfig{Name: "outage", Effect: "error", Status: http.StatusBadGateway})
+	injector.SetActive(false)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 with the kill switch on; body = %s", rec.Code, rec.Body.String())
+	}
+}
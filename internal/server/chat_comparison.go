@@ -0,0 +1,507 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+	"golang.org/x/sync/errgroup"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/gateway"
+	"gomodel/internal/streaming"
+	"gomodel/internal/usage"
+)
+
+// Chat comparison defaults applied when the corresponding limit is not configured.
+const (
+	DefaultComparisonMaxModels      = 5
+	DefaultComparisonMaxConcurrency = 4
+)
+
+const comparisonChatPath = "/v1/chat/completions"
+
+// ComparisonLimits bounds POST /v1/chat/completions/compare requests.
+type ComparisonLimits struct {
+	MaxModels        int     // Max models per request; <= 0 uses DefaultComparisonMaxModels
+	MaxConcurrency   int     // Max concurrent sub-requests; <= 0 uses DefaultComparisonMaxConcurrency
+	MaxEstimatedCost float64 // Max estimated USD cost per request; <= 0 disables the check
+}
+
+func (l ComparisonLimits) maxModels() int {
+	if l.MaxModels <= 0 {
+		return DefaultComparisonMaxModels
+	}
+	return l.MaxModels
+}
+
+func (l ComparisonLimits) maxConcurrency() int {
+	if l.MaxConcurrency <= 0 {
+		return DefaultComparisonMaxConcurrency
+	}
+	return l.MaxConcurrency
+}
+
+// chatComparisonService fans one chat request out to several models. Every
+// sub-request runs through the regular translated chat pipeline and records
+// its own usage and audit entries under the comparison ID.
+type chatComparisonService struct {
+	translated      *translatedInferenceService
+	logger          auditlog.LoggerInterface
+	usageLogger     usage.LoggerInterface
+	pricingResolver usage.PricingResolver
+	limits          ComparisonLimits
+}
+
+// comparisonTarget is the per-model state of one comparison sub-request.
+type comparisonTarget struct {
+	index    int
+	model    string
+	start    time.Time
+	ctx      context.Context
+	request  *core.ChatRequest
+	workflow *core.Workflow
+	audit    *auditlog.LogEntry
+	err      error
+}
+
+func (h *Handler) chatComparison() *chatComparisonService {
+	translated := h.translatedInference()
+	return &chatComparisonService{
+		translated:      translated,
+		logger:          translated.logger,
+		usageLogger:     translated.usageLogger,
+		pricingResolver: translated.pricingResolver,
+		limits:          h.comparisonLimits,
+	}
+}
+
+// Compare handles POST /v1/chat/completions/compare.
+func (s *chatComparisonService) Compare(c *echo.Context) error {
+	body, err := requestBodyBytes(c)
+	if err != nil {
+		return handleError(c, core.NewInvalidRequestError("failed to read request body", err))
+	}
+	var req core.ChatComparisonRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+	}
+	models, err := s.validateModels(req.Models)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	comparisonID := requestIDFromContextOrHeader(c.Request())
+	if comparisonID == "" {
+		comparisonID = uuid.NewString()
+	}
+	ctx := core.WithRequestID(c.Request().Context(), comparisonID)
+
+	targets := s.prepare(ctx, comparisonID, &req.ChatRequest, models)
+	estimatedCost, err := s.estimateCost(&req.ChatRequest, targets)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	if req.Stream {
+		return s.stream(c, comparisonID, targets)
+	}
+
+	results := make([]core.ChatComparisonResult, len(targets))
+	group := new(errgroup.Group)
+	group.SetLimit(s.limits.maxConcurrency())
+	for _, target := range targets {
+		group.Go(func() error {
+			results[target.index] = s.execute(comparisonID, target)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return c.JSON(http.StatusOK, core.ChatComparisonResponse{
+		ID:            comparisonID,
+		Object:        "chat.completion.comparison",
+		Created:       time.Now().Unix(),
+		EstimatedCost: estimatedCost,
+		Results:       results,
+	})
+}
+
+func (s *chatComparisonService) validateModels(raw []string) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, core.NewInvalidRequestError("models is required", nil).WithParam("models")
+	}
+	if limit := s.limits.maxModels(); len(raw) > limit {
+		return nil, core.NewInvalidRequestError(
+			fmt.Sprintf("too many models: got %d, at most %d allowed", len(raw), limit), nil,
+		).WithParam("models")
+	}
+	models := make([]string, len(raw))
+	for i, model := range raw {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			return nil, core.NewInvalidRequestError(fmt.Sprintf("models[%d] must not be empty", i), nil).WithParam("models")
+		}
+		models[i] = model
+	}
+	return models, nil
+}
+
+// prepare resolves and patches the shared request once per model. Preparation
+// failures are kept on the target so the other models still run.
+func (s *chatComparisonService) prepare(ctx context.Context, comparisonID string, base *core.ChatRequest, models []string) []*comparisonTarget {
+	targets := make([]*comparisonTarget, len(models))
+	group := new(errgroup.Group)
+	group.SetLimit(s.limits.maxConcurrency())
+	for i, model := range models {
+		// Each target reports its own provider call and guardrail canary
+		// decisions to its audit entry.
+		targetCtx := core.WithGuardrailCanaries(core.WithUpstreamCall(ctx, &core.UpstreamCall{}), &core.GuardrailCanaries{})
+		target := &comparisonTarget{index: i, model: model, start: time.Now(), ctx: targetCtx}
+		targets[i] = target
+		group.Go(func() error {
+			req := gateway.CloneChatRequestForSelector(base, core.ModelSelector{Model: model})
+			target.request = req
+			target.audit = newInternalChatAuditEntry(s.logger, ctx, comparisonID, core.NewRequestedModelSelector(model, ""))
+			auditlog.EnrichLogEntryWithComparison(target.audit, comparisonID, i)
+
+			prepared, err := s.translated.inference().PrepareChatRequest(targetCtx, req, gateway.RequestMeta{
+				RequestID: comparisonID,
+				Endpoint:  core.DescribeEndpoint(http.MethodPost, comparisonChatPath),
+			})
+			if err != nil {
+				target.err = err
+				return nil
+			}
+			target.ctx = prepared.Context
+			target.request = prepared.Request
+			auditlog.EnrichLogEntryWithPromptCompression(target.audit, core.GetPromptCompression(prepared.Context))
+			auditlog.EnrichLogEntryWithContextOverflow(target.audit, core.GetContextOverflow(prepared.Context))
+			auditlog.EnrichLogEntryWithModeration(target.audit, core.GetModeration(prepared.Context))
+			target.workflow = prepared.Workflow
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return targets
+}
+
+// estimateCost applies the configured cost guardrail. It prices the prompt at
+// roughly four characters per token plus max_tokens of output for every
+// prepared model; models without pricing metadata are not counted.
+func (s *chatComparisonService) estimateCost(base *core.ChatRequest, targets []*comparisonTarget) (*float64, error) {
+	limit := s.limits.MaxEstimatedCost
+	if limit <= 0 {
+		return nil, nil
+	}
+	if base.MaxTokens == nil || *base.MaxTokens <= 0 {
+		return nil, core.NewInvalidRequestError("max_tokens is required when a comparison cost limit is configured", nil).WithParam("max_tokens")
+	}
+
+	inputTokens := estimateChatInputTokens(base)
+	outputTokens := float64(*base.MaxTokens)
+	total := 0.0
+	for _, target := range targets {
+		if target.err != nil || target.workflow == nil || s.pricingResolver == nil {
+			continue
+		}
+		pricing := s.pricingResolver.ResolvePricing(resolvedModelFromWorkflow(target.workflow, target.model), target.workflow.ProviderType)
+		if pricing == nil {
+			continue
+		}
+		if pricing.InputPerMtok != nil {
+			total += inputTokens * *pricing.InputPerMtok / 1_000_000
+		}
+		if pricing.OutputPerMtok != nil {
+			total += outputTokens * *pricing.OutputPerMtok / 1_000_000
+		}
+	}
+	if total > limit {
+		return nil, core.NewInvalidRequestError(
+			fmt.Sprintf("estimated comparison cost $%.6f exceeds the configured limit of $%.6f", total, limit), nil,
+		).WithCode("comparison_cost_limit_exceeded")
+	}
+	return &total, nil
+}
+
+func estimateChatInputTokens(req *core.ChatRequest) float64 {
+	chars := 0
+	for _, msg := range req.Messages {
+		chars += len(core.ExtractTextContent(msg.Content))
+	}
+	return math.Ceil(float64(chars) / 4)
+}
+
+func (s *chatComparisonService) execute(comparisonID string, target *comparisonTarget) core.ChatComparisonResult {
+	result := core.ChatComparisonResult{Index: target.index, Model: target.model}
+	if target.err != nil {
+		finishInternalChatAuditEntry(s.logger, target.ctx, target.audit, target.start, nil, target.request, nil, target.err, "", "", "", "")
+		return s.failedResult(comparisonID, result, target, target.err)
+	}
+
+	executed, err := s.translated.inference().ExecuteChatCompletion(target.ctx, target.workflow, target.request, comparisonID, comparisonChatPath)
+	var (
+		resp *core.ChatResponse
+		meta gateway.ExecutionMeta
+	)
+	if executed != nil {
+		resp = executed.Response
+		meta = executed.Meta
+	}
+	finishInternalChatAuditEntry(s.logger, target.ctx, target.audit, target.start, target.workflow, target.request, resp, err, "", meta.ProviderType, meta.ProviderName, meta.FailoverModel)
+	result.Provider = comparisonProviderName(target.workflow, meta.ProviderName)
+	if err != nil {
+		return s.failedResult(comparisonID, result, target, err)
+	}
+
+	result.StatusCode = http.StatusOK
+	result.LatencyMs = time.Since(target.start).Milliseconds()
+	result.Response = resp
+	result.Usage = &resp.Usage
+	return result
+}
+
+func (s *chatComparisonService) failedResult(comparisonID string, result core.ChatComparisonResult, target *comparisonTarget, err error) core.ChatComparisonResult {
+	errObj, status := comparisonError(err)
+	logComparisonFailure(comparisonID, target, errObj, status)
+	result.StatusCode = status
+	result.LatencyMs = time.Since(target.start).Milliseconds()
+	result.Error = errObj
+	if result.Provider == "" {
+		result.Provider = comparisonProviderName(target.workflow, "")
+	}
+	return result
+}
+
+// stream multiplexes every model's chat chunks into one SSE response. Events
+// from different models interleave; each model ends with a done or error event.
+func (s *chatComparisonService) stream(c *echo.Context, comparisonID string, targets []*comparisonTarget) error {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	events := make(chan core.ChatComparisonStreamEvent)
+	go func() {
+		group := new(errgroup.Group)
+		group.SetLimit(s.limits.maxConcurrency())
+		for _, target := range targets {
+			group.Go(func() error {
+				s.streamTarget(ctx, comparisonID, target, events)
+				return nil
+			})
+		}
+		_ = group.Wait()
+		close(events)
+	}()
+
+	auditlog.EnrichEntryWithStream(c, true)
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+
+	var writeErr error
+	for event := range events {
+		if writeErr != nil {
+			continue
+		}
+		if writeErr = writeComparisonEvent(c.Response(), event); writeErr != nil {
+			cancel()
+			serverLogger.Warn("comparison stream terminated abnormally",
+				"error", writeErr,
+				"comparison_id", comparisonID,
+			)
+		}
+	}
+	if writeErr == nil {
+		if _, err := io.WriteString(c.Response(), "data: [DONE]\n\n"); err == nil {
+			flushResponse(c.Response())
+		}
+	}
+	return nil
+}
+
+func (s *chatComparisonService) streamTarget(ctx context.Context, comparisonID string, target *comparisonTarget, events chan<- core.ChatComparisonStreamEvent) {
+	event := core.ChatComparisonStreamEvent{ComparisonID: comparisonID, Index: target.index, Model: target.model}
+	fail := func(err error) {
+		errObj, status := comparisonError(err)
+		logComparisonFailure(comparisonID, target, errObj, status)
+		failed := event
+		failed.StatusCode = status
+		failed.LatencyMs = time.Since(target.start).Milliseconds()
+		failed.Error = errObj
+		events <- failed
+	}
+
+	if target.err != nil {
+		finishInternalChatAuditEntry(s.logger, target.ctx, target.audit, target.start, nil, target.request, nil, target.err, "", "", "", "")
+		fail(target.err)
+		return
+	}
+
+	req := target.request.WithStreaming()
+	streamCtx := ctx
+	if target.ctx != nil {
+		streamCtx = mergeCancel(target.ctx, ctx)
+	}
+	result, err := s.translated.inference().StreamChatCompletion(streamCtx, target.workflow, req)
+	if err != nil {
+		finishInternalChatAuditEntry(s.logger, target.ctx, target.audit, target.start, target.workflow, req, nil, err, "", "", "", "")
+		fail(err)
+		return
+	}
+
+	stream := streaming.NewObservedSSEStream(result.Stream, s.streamObservers(comparisonID, target, req, result.Meta)...)
+	defer func() {
+		_ = stream.Close() //nolint:errcheck
+	}()
+
+	var lastUsage json.RawMessage
+	reader := bufio.NewReader(stream)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if payload, ok := comparisonChunkPayload(line); ok {
+			if u := gjson.GetBytes(payload, "usage"); u.IsObject() {
+				lastUsage = json.RawMessage(u.Raw)
+			}
+			chunk := event
+			chunk.Chunk = payload
+			events <- chunk
+		}
+		if readErr == nil {
+			continue
+		}
+		if !errors.Is(readErr, io.EOF) {
+			recordStreamingError(target.audit, target.model, result.Meta.ProviderType, comparisonChatPath, comparisonID, readErr)
+			fail(core.NewProviderError(result.Meta.ProviderType, http.StatusBadGateway, "stream terminated abnormally", readErr))
+			return
+		}
+		break
+	}
+
+	done := event
+	done.Done = true
+	done.StatusCode = http.StatusOK
+	done.LatencyMs = time.Since(target.start).Milliseconds()
+	done.Usage = lastUsage
+	events <- done
+}
+
+func (s *chatComparisonService) streamObservers(comparisonID string, target *comparisonTarget, req *core.ChatRequest, meta gateway.ExecutionMeta) []streaming.Observer {
+	observers := make([]streaming.Observer, 0, 2)
+	workflow := target.workflow
+	if entry := target.audit; entry != nil && (workflow == nil || workflow.AuditEnabled()) {
+		entry.Stream = true
+		entry.StatusCode = http.StatusOK
+		auditlog.EnrichLogEntryWithWorkflow(entry, workflow)
+		auditlog.EnrichLogEntryWithFailover(entry, meta.FailoverModel)
+		auditlog.EnrichLogEntryWithResolvedRoute(entry, qualifyExecutedModel(workflow, meta.Model, meta.ProviderName), meta.ProviderType, meta.ProviderName)
+		auditlog.EnrichLogEntryWithRequestContext(entry, target.ctx)
+		auditlog.CaptureInternalJSONExchange(entry, target.ctx, http.MethodPost, comparisonChatPath, req, nil, nil, s.logger.Config())
+		observers = append(observers, auditlog.NewStreamLogObserver(s.logger, entry, comparisonChatPath))
+	}
+	if s.usageLogger != nil && s.usageLogger.Config().Enabled && (workflow == nil || workflow.UsageEnabled()) {
+		usageObserver := usage.NewStreamUsageObserver(s.usageLogger, meta.Model, meta.ProviderType, comparisonID, comparisonChatPath, s.pricingResolver, core.UserPathFromContext(target.ctx))
+		if usageObserver != nil {
+			usageObserver.SetProviderName(meta.ProviderName)
+			usageObserver.SetRequestedModel(workflow.RequestedQualifiedModel())
+			usageObserver.SetLabels(core.GetRequestLabels(target.ctx))
+			usageObserver.SetAuthKeyID(core.GetAuthKeyID(target.ctx))
+			observers = append(observers, usageObserver)
+		}
+	}
+	return observers
+}
+
+// comparisonChunkPayload returns the JSON payload of an SSE data line, skipping
+// comments, blank lines and the terminal [DONE] marker.
+func comparisonChunkPayload(line []byte) (json.RawMessage, bool) {
+	line = bytes.TrimSpace(line)
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return nil, false
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("[DONE]")) || !json.Valid(data) {
+		return nil, false
+	}
+	return json.RawMessage(bytes.Clone(data)), true
+}
+
+func writeComparisonEvent(w http.ResponseWriter, event core.ChatComparisonStreamEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(payload) + 8)
+	buf.WriteString("data: ")
+	buf.Write(payload)
+	buf.WriteString("\n\n")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	flushResponse(w)
+	return nil
+}
+
+func flushResponse(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// mergeCancel keeps the values of base and additionally ends when cancel ends.
+func mergeCancel(base, cancel context.Context) context.Context {
+	ctx, stop := context.WithCancel(base)
+	context.AfterFunc(cancel, stop)
+	return ctx
+}
+
+func comparisonError(err error) (*core.OpenAIErrorObject, int) {
+	gatewayErr, ok := errors.AsType[*core.GatewayError](err)
+	if !ok {
+		gatewayErr = core.NewProviderError("", http.StatusInternalServerError, "an unexpected error occurred", err)
+	}
+	return &core.OpenAIErrorObject{
+		Type:    gatewayErr.Type,
+		Message: gatewayErr.Message,
+		Param:   gatewayErr.Param,
+		Code:    gatewayErr.Code,
+	}, gatewayErr.HTTPStatusCode()
+}
+
+func logComparisonFailure(comparisonID string, target *comparisonTarget, errObj *core.OpenAIErrorObject, status int) {
+	serverLogger.Warn("comparison model failed",
+		"comparison_id", comparisonID,
+		"index", target.index,
+		"model", target.model,
+		"type", errObj.Type,
+		"status", status,
+		"message", errObj.Message,
+	)
+}
+
+func comparisonProviderName(workflow *core.Workflow, executed string) string {
+	if executed != "" {
+		return executed
+	}
+	if name := providerNameFromWorkflow(workflow); name != "" {
+		return name
+	}
+	if workflow != nil {
+		return workflow.ProviderType
+	}
+	return ""
+}
@@ -0,0 +1,393 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// comparisonProvider answers chat requests per model so one comparison can
+// mix successes and failures.
+type comparisonProvider struct {
+	mockProvider
+	errs    map[string]error
+	streams map[string]string
+}
+
+func (p *comparisonProvider) ChatCompletion(_ context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	if err := p.errs[req.Model]; err != nil {
+		return nil, err
+	}
+	return &core.ChatResponse{
+		ID:     "chatcmpl-" + req.Model,
+		Object: "chat.completion",
+		Model:  req.Model,
+		Choices: []core.Choice{{
+			Message:      core.ResponseMessage{Role: "assistant", Content: "answer from " + req.Model},
+			FinishReason: "stop",
+		}},
+		Usage: core.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func (p *comparisonProvider) StreamChatCompletion(_ context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
+	if err := p.errs[req.Model]; err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader(p.streams[req.Model])), nil
+}
+
+type syncAuditLogger struct {
+	mu      sync.Mutex
+	config  auditlog.Config
+	entries []*auditlog.LogEntry
+}
+
+func (l *syncAuditLogger) Write(entry *auditlog.LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *syncAuditLogger) Config() auditlog.Config { return l.config }
+func (l *syncAuditLogger) Close() error            { return nil }
+
+type syncUsageLogger struct {
+	mu      sync.Mutex
+	config  usage.Config
+	entries []*usage.UsageEntry
+}
+
+func (l *syncUsageLogger) Write(entry *usage.UsageEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *syncUsageLogger) Config() usage.Config        { return l.config }
+func (l *syncUsageLogger) Flush(context.Context) error { return nil }
+func (l *syncUsageLogger) Close() error                { return nil }
+
+func newComparisonProvider() *comparisonProvider {
+	return &comparisonProvider{
+		mockProvider: mockProvider{
+			supportedModels: []string{"gpt-a", "gpt-b", "gpt-c"},
+			providerTypes:   map[string]string{"gpt-a": "openai", "gpt-b": "anthropic", "gpt-c": "openai"},
+			providerNames:   map[string]string{"gpt-a": "openai-main", "gpt-b": "anthropic-main", "gpt-c": "openai-main"},
+		},
+		errs: map[string]error{},
+	}
+}
+
+func serveComparison(t *testing.T, handler *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions/compare", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "cmp-123")
+	rec := httptest.NewRecorder()
+	if err := handler.ChatCompletionCompare(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	return rec
+}
+
+func TestChatCompletionCompare_PartialFailurePreservesOrder(t *testing.T) {
+	provider := newComparisonProvider()
+	provider.errs["gpt-b"] = core.NewRateLimitError("anthropic", "slow down")
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	handler := NewHandler(provider, auditLogger, usageLogger, nil)
+
+	rec := serveComparison(t, handler, `{
+		"models": ["gpt-a", "gpt-b", "unknown-model", "gpt-c"],
+		"messages": [{"role": "user", "content": "Hi"}]
+	}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	var resp core.ChatComparisonResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ID != "cmp-123" || resp.Object != "chat.completion.comparison" {
+		t.Fatalf("unexpected envelope: id=%q object=%q", resp.ID, resp.Object)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("len(results) = %d, want 4", len(resp.Results))
+	}
+
+	wantModels := []string{"gpt-a", "gpt-b", "unknown-model", "gpt-c"}
+	for i, result := range resp.Results {
+		if result.Index != i || result.Model != wantModels[i] {
+			t.Fatalf("results[%d] = index %d model %q, want index %d model %q", i, result.Index, result.Model, i, wantModels[i])
+		}
+	}
+
+	for _, i := range []int{0, 3} {
+		result := resp.Results[i]
+		if result.StatusCode != http.StatusOK || result.Response == nil || result.Error != nil {
+			t.Fatalf("results[%d] = %+v, want success", i, result)
+		}
+		if result.Response.Model != wantModels[i] {
+			t.Fatalf("results[%d].response.model = %q, want %q", i, result.Response.Model, wantModels[i])
+		}
+		if result.Usage == nil || result.Usage.TotalTokens != 15 {
+			t.Fatalf("results[%d].usage = %+v, want total_tokens 15", i, result.Usage)
+		}
+		if result.Provider != "openai-main" {
+			t.Fatalf("results[%d].provider = %q, want openai-main", i, result.Provider)
+		}
+	}
+
+	rateLimited := resp.Results[1]
+	if rateLimited.StatusCode != http.StatusTooManyRequests || rateLimited.Error == nil || rateLimited.Response != nil {
+		t.Fatalf("results[1] = %+v, want rate limit error", rateLimited)
+	}
+	if rateLimited.Error.Type != core.ErrorTypeRateLimit || rateLimited.Error.Message != "slow down" {
+		t.Fatalf("results[1].error = %+v", rateLimited.Error)
+	}
+
+	unknown := resp.Results[2]
+	if unknown.StatusCode != http.StatusBadRequest || unknown.Error == nil {
+		t.Fatalf("results[2] = %+v, want invalid request error", unknown)
+	}
+
+	if len(usageLogger.entries) != 2 {
+		t.Fatalf("usage entries = %d, want 2", len(usageLogger.entries))
+	}
+	for _, entry := range usageLogger.entries {
+		if entry.RequestID != "cmp-123" {
+			t.Fatalf("usage request_id = %q, want cmp-123", entry.RequestID)
+		}
+	}
+
+	if len(auditLogger.entries) != 4 {
+		t.Fatalf("audit entries = %d, want 4", len(auditLogger.entries))
+	}
+	statusByIndex := map[int]int{}
+	for _, entry := range auditLogger.entries {
+		if entry.RequestID != "cmp-123" || entry.Path != "/v1/chat/completions" {
+			t.Fatalf("audit entry request_id=%q path=%q", entry.RequestID, entry.Path)
+		}
+		if entry.Data == nil || entry.Data.Comparison == nil || entry.Data.Comparison.ID != "cmp-123" {
+			t.Fatalf("audit entry missing comparison link: %+v", entry.Data)
+		}
+		statusByIndex[entry.Data.Comparison.Index] = entry.StatusCode
+	}
+	wantStatus := map[int]int{0: 200, 1: 429, 2: 400, 3: 200}
+	for index, want := range wantStatus {
+		if statusByIndex[index] != want {
+			t.Fatalf("audit status for index %d = %d, want %d", index, statusByIndex[index], want)
+		}
+	}
+}
+
+func TestChatCompletionCompare_RejectsInvalidModels(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "missing models",
+			body: `{"messages":[{"role":"user","content":"Hi"}]}`,
+			want: "models is required",
+		},
+		{
+			name: "blank model",
+			body: `{"models":["gpt-a"," "],"messages":[{"role":"user","content":"Hi"}]}`,
+			want: "models[1] must not be empty",
+		},
+		{
+			name: "too many models",
+			body: `{"models":["gpt-a","gpt-b","gpt-c"],"messages":[{"role":"user","content":"Hi"}]}`,
+			want: "too many models: got 3, at most 2 allowed",
+		},
+		{
+			name: "malformed body",
+			body: `{"models":`,
+			want: "invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newComparisonProvider()
+			handler := NewHandler(provider, nil, nil, nil)
+			handler.comparisonLimits = ComparisonLimits{MaxModels: 2}
+
+			rec := serveComparison(t, handler, tt.body)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body=%s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Fatalf("body = %s, want message containing %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestChatCompletionCompare_CostGuardrail(t *testing.T) {
+	inputPrice := 10.0
+	outputPrice := 30.0
+	pricing := &mockPricingResolver{pricing: &core.ModelPricing{InputPerMtok: &inputPrice, OutputPerMtok: &outputPrice}}
+
+	tests := []struct {
+		name       string
+		limit      float64
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "max_tokens required",
+			limit:      1,
+			body:       `{"models":["gpt-a","gpt-c"],"messages":[{"role":"user","content":"Hi"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "max_tokens is required",
+		},
+		{
+			name:       "estimate over limit",
+			limit:      0.01,
+			body:       `{"models":["gpt-a","gpt-c"],"max_tokens":1000,"messages":[{"role":"user","content":"Hi"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "comparison_cost_limit_exceeded",
+		},
+		{
+			name:       "estimate under limit",
+			limit:      1,
+			body:       `{"models":["gpt-a","gpt-c"],"max_tokens":1000,"messages":[{"role":"user","content":"Hi"}]}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"estimated_cost":0.06002`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(newComparisonProvider(), nil, nil, pricing)
+			handler.comparisonLimits = ComparisonLimits{MaxEstimatedCost: tt.limit}
+
+			rec := serveComparison(t, handler, tt.body)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %s, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestChatCompletionCompare_StreamMultiplexesModels(t *testing.T) {
+	provider := newComparisonProvider()
+	provider.errs["gpt-b"] = core.NewProviderError("anthropic", http.StatusBadGateway, "upstream down", nil)
+	provider.streams = map[string]string{
+		"gpt-a": "data: {\"id\":\"a\",\"model\":\"gpt-a\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"A\"}}]}\n\n" +
+			"data: {\"id\":\"a\",\"model\":\"gpt-a\",\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\n" +
+			"data: [DONE]\n\n",
+		"gpt-c": "data: {\"id\":\"c\",\"model\":\"gpt-c\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"C\"}}]}\n\n" +
+			"data: [DONE]\n\n",
+	}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	handler := NewHandler(provider, auditLogger, usageLogger, nil)
+
+	rec := serveComparison(t, handler, `{
+		"models": ["gpt-a", "gpt-b", "gpt-c"],
+		"stream": true,
+		"messages": [{"role": "user", "content": "Hi"}]
+	}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("content-type = %q, want text/event-stream", got)
+	}
+
+	var events []core.ChatComparisonStreamEvent
+	sawDone := false
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			sawDone = true
+			continue
+		}
+		var event core.ChatComparisonStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("failed to decode event %q: %v", data, err)
+		}
+		events = append(events, event)
+	}
+	if !sawDone {
+		t.Fatal("stream did not end with [DONE]")
+	}
+
+	chunks := map[int]int{}
+	done := map[int]core.ChatComparisonStreamEvent{}
+	failed := map[int]core.ChatComparisonStreamEvent{}
+	for _, event := range events {
+		if event.ComparisonID != "cmp-123" {
+			t.Fatalf("event comparison_id = %q, want cmp-123", event.ComparisonID)
+		}
+		switch {
+		case event.Error != nil:
+			failed[event.Index] = event
+		case event.Done:
+			done[event.Index] = event
+		default:
+			if !strings.Contains(string(event.Chunk), `"model":"`+event.Model+`"`) {
+				t.Fatalf("chunk %s tagged with model %q", event.Chunk, event.Model)
+			}
+			chunks[event.Index]++
+		}
+	}
+
+	if chunks[0] != 2 || chunks[2] != 1 || chunks[1] != 0 {
+		t.Fatalf("chunk counts = %v, want map[0:2 2:1]", chunks)
+	}
+	if len(done) != 2 || done[0].Model != "gpt-a" || done[2].Model != "gpt-c" {
+		t.Fatalf("done events = %+v", done)
+	}
+	if !strings.Contains(string(done[0].Usage), `"total_tokens":4`) {
+		t.Fatalf("done usage = %s, want total_tokens 4", done[0].Usage)
+	}
+	if len(failed) != 1 || failed[1].Model != "gpt-b" || failed[1].StatusCode != http.StatusBadGateway {
+		t.Fatalf("error events = %+v", failed)
+	}
+
+	if len(usageLogger.entries) != 1 || usageLogger.entries[0].RequestID != "cmp-123" {
+		t.Fatalf("usage entries = %+v, want one entry for cmp-123", usageLogger.entries)
+	}
+	if len(auditLogger.entries) != 3 {
+		t.Fatalf("audit entries = %d, want 3", len(auditLogger.entries))
+	}
+	for _, entry := range auditLogger.entries {
+		if entry.Data == nil || entry.Data.Comparison == nil || entry.Data.Comparison.ID != "cmp-123" {
+			t.Fatalf("audit entry missing comparison link: %+v", entry.Data)
+		}
+		if entry.Data.Comparison.Index != 1 && !entry.Stream {
+			t.Fatalf("audit entry for index %d not marked as stream", entry.Data.Comparison.Index)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+)
+
+// deferrablePaths lists the endpoints that accept X-GoModel-Deferred.
+var deferrablePaths = map[string]struct{}{
+	"/v1/chat/completions": {},
+	"/v1/responses":        {},
+	"/v1/embeddings":       {},
+}
+
+func isDeferrablePath(path string) bool {
+	_, ok := deferrablePaths[path]
+	return ok
+}
+
+// DeferredExecution queues requests that opt in with X-GoModel-Deferred when
+// they fail with a retriable provider error, and answers 202 with the
+// deferred request instead of the error. A nil service rejects opted-in
+// requests.
+func DeferredExecution(service *deferred.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if !strings.EqualFold(strings.TrimSpace(c.Request().Header.Get(core.DeferredHeader)), "true") {
+				return next(c)
+			}
+			if service == nil {
+				return handleError(c, deferredUnavailableError())
+			}
+
+			body, err := requestBodyBytes(c)
+			if err != nil {
+				return handleError(c, requestBodyReadError("failed to read request body", err))
+			}
+			if gjson.GetBytes(body, "stream").Bool() {
+				return handleError(c, core.NewInvalidRequestError("streaming requests cannot be deferred", nil).WithCode("deferred_stream_unsupported"))
+			}
+			callbackURL, err := service.CallbackURL(c.Request().Header.Get(core.DeferredCallbackHeader))
+			if err != nil {
+				return handleError(c, deferredCallbackError(err))
+			}
+
+			held := newHeldResponse()
+			original := c.Response()
+			c.SetResponse(held)
+			err = next(c)
+			c.SetResponse(original)
+			if err != nil {
+				return err
+			}
+
+			outcome := deferred.ClassifyResponse(held.status, held.body.Bytes())
+			if outcome != deferred.OutcomeRetry && outcome != deferred.OutcomeCircuitOpen {
+				return held.flush(original)
+			}
+
+			ctx := c.Request().Context()
+			queued, err := service.Enqueue(ctx, &deferred.Request{
+				Method:      c.Request().Method,
+				Path:        c.Request().URL.Path,
+				Headers:     deferredReplayHeaders(c.Request().Header),
+				Body:        bytes.Clone(body),
+				RequestID:   requestIDFromContextOrHeader(c.Request()),
+				AuthKeyID:   core.GetAuthKeyID(ctx),
+				UserPath:    core.GetEffectiveUserPath(ctx),
+				CallbackURL: callbackURL,
+			}, deferredFailureMessage(held))
+			if err != nil {
+				serverLogger.Warn("deferred enqueue failed; returning original error",
+					"request_id", requestIDFromContextOrHeader(c.Request()),
+					"error", err,
+				)
+				return held.flush(original)
+			}
+
+			c.Response().Header().Set(core.DeferredIDHeader, queued.ID)
+			return c.JSON(http.StatusAccepted, queued.Public())
+		}
+	}
+}
+
+func deferredUnavailableError() *core.GatewayError {
+	return core.NewInvalidRequestErrorWithStatus(http.StatusServiceUnavailable, "deferred execution is not enabled", nil).
+		WithCode("feature_unavailable")
+}
+
+func deferredCallbackError(err error) *core.GatewayError {
+	if errors.Is(err, deferred.ErrCallbacksDisabled) {
+		return core.NewInvalidRequestError(fmt.Sprintf("%s is not enabled", core.DeferredCallbackHeader), nil).
+			WithCode("feature_unavailable")
+	}
+	return core.NewInvalidRequestError(fmt.Sprintf("invalid %s: %s", core.DeferredCallbackHeader, err), nil)
+}
+
+// deferredReplayHeaders keeps the headers that shape gateway behavior. The
+// Authorization header is never stored; replays reuse the identity captured
+// at enqueue time instead.
+func deferredReplayHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if len(values) == 0 || !strings.HasPrefix(strings.ToLower(canonical), "x-gomodel-") {
+			continue
+		}
+		switch canonical {
+		case http.CanonicalHeaderKey(core.DeferredHeader),
+			http.CanonicalHeaderKey(core.DeferredCallbackHeader),
+			http.CanonicalHeaderKey(core.DeferredIDHeader):
+			continue
+		}
+		headers[canonical] = values[0]
+	}
+	return headers
+}
+
+func deferredFailureMessage(held *heldResponse) string {
+	if message := gjson.GetBytes(held.body.Bytes(), "error.message").String(); message != "" {
+		return message
+	}
+	return fmt.Sprintf("gateway returned status %d", held.status)
+}
+
+// heldResponse buffers a handler response so DeferredExecution can decide
+// whether to send it or replace it with a 202. Deferred replays also collect
+// their response in one.
+type heldResponse struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newHeldResponse() *heldResponse {
+	return &heldResponse{header: make(http.Header)}
+}
+
+func (r *heldResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *heldResponse) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *heldResponse) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// Flush is a no-op; the buffered response is written in full by flush.
+func (r *heldResponse) Flush() {}
+
+func (r *heldResponse) flush(w http.ResponseWriter) error {
+	for name, values := range r.header {
+		w.Header()[name] = values
+	}
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, err := w.Write(r.body.Bytes())
+	return err
+}
+
+// GetDeferred handles GET /v1/deferred/{id}.
+//
+// @Summary      Get a deferred request
+// @Tags         deferred
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Deferred request ID"
+// @Success      200  {object}  core.DeferredRequestResponse
+// @Failure      400  {object}  core.OpenAIErrorEnvelope
+// @Failure      401  {object}  core.OpenAIErrorEnvelope
+// @Failure      404  {object}  core.OpenAIErrorEnvelope
+// @Failure      500  {object}  core.OpenAIErrorEnvelope
+// @Failure      503  {object}  core.OpenAIErrorEnvelope
+// @Router       /v1/deferred/{id} [get]
+func (h *Handler) GetDeferred(c *echo.Context) error {
+	if h.deferred == nil {
+		return handleError(c, deferredUnavailableError())
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	req, err := h.deferred.Get(c.Request().Context(), id)
+	// Requests of other managed keys are reported as missing.
+	if errors.Is(err, deferred.ErrNotFound) || (err == nil && req.AuthKeyID != core.GetAuthKeyID(c.Request().Context())) {
+		return handleError(c, core.NewNotFoundError("deferred request not found: "+id))
+	}
+	if err != nil {
+		return handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, req.Public())
+}
+
+// deferredReplayExecutor replays deferred requests through the full gateway
+// handler chain so workflows, usage and audit logging apply as they would
+// for the original request.
+type deferredReplayExecutor struct {
+	handler http.Handler
+}
+
+// DeferredExecutor returns the executor the deferred worker uses to replay
+// queued requests through this server.
+func (s *Server) DeferredExecutor() deferred.Executor {
+	return deferredReplayExecutor{handler: s.echo}
+}
+
+func (e deferredReplayExecutor) Execute(ctx context.Context, req *deferred.Request) (*deferred.Replay, error) {
+	if req == nil {
+		return nil, fmt.Errorf("deferred request is required")
+	}
+	if !isDeferrablePath(req.Path) {
+		return nil, fmt.Errorf("path %q cannot be replayed", req.Path)
+	}
+
+	ctx = core.WithRequestOrigin(ctx, core.RequestOriginDeferred)
+	if req.AuthKeyID != "" {
+		ctx = core.WithAuthKeyID(ctx, req.AuthKeyID)
+	}
+	if req.UserPath != "" {
+		ctx = core.WithEffectiveUserPath(ctx, req.UserPath)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("build replay request: %w", err)
+	}
+	httpReq.RequestURI = req.Path
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if req.UserPath != "" {
+		httpReq.Header.Set(core.UserPathHeader, req.UserPath)
+	}
+
+	held := newHeldResponse()
+	e.handler.ServeHTTP(held, httpReq)
+	status := held.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &deferred.Replay{StatusCode: status, Body: held.body.Bytes()}, nil
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+)
+
+func newTestDeferredService(t *testing.T) *deferred.Service {
+	t.Helper()
+	service, err := deferred.NewService(deferred.NewMemoryStore(), config.DeferredConfig{
+		Enabled:        true,
+		TTL:            time.Hour,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		PollInterval:   time.Second,
+	})
+	if err != nil {
+		t.Fatalf("deferred.NewService() error = %v", err)
+	}
+	return service
+}
+
+func deferredChatRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.DeferredHeader, "true")
+	return req
+}
+
+const deferredChatBody = `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`
+
+func TestDeferredExecution_QueuesRetriableProviderFailure(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		err:             core.NewProviderError("openai", http.StatusBadGateway, "upstream down", nil),
+	}
+	service := newTestDeferredService(t)
+	srv := New(mock, &Config{Deferred: service})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, deferredChatRequest(deferredChatBody))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202; body = %s", rec.Code, rec.Body.String())
+	}
+	var queued core.DeferredRequestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &queued); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if queued.Status != string(deferred.StatusQueued) || queued.LastError != "upstream down" || queued.Object != "deferred_request" {
+		t.Fatalf("response = %+v", queued)
+	}
+	if got := rec.Header().Get(core.DeferredIDHeader); got != queued.ID {
+		t.Fatalf("%s = %q, want %q", core.DeferredIDHeader, got, queued.ID)
+	}
+
+	stored, err := service.Get(context.Background(), queued.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if stored.Path != "/v1/chat/completions" || string(stored.Body) != deferredChatBody {
+		t.Fatalf("stored request = %s %s", stored.Path, stored.Body)
+	}
+	if _, ok := stored.Headers[core.DeferredHeader]; ok {
+		t.Fatalf("stored headers keep %s: %v", core.DeferredHeader, stored.Headers)
+	}
+
+	getRec := httptest.NewRecorder()
+	srv.ServeHTTP(getRec, httptest.NewRequest(http.MethodGet, "/v1/deferred/"+queued.ID, nil))
+	if getRec.Code != http.StatusOK || !strings.Contains(getRec.Body.String(), queued.ID) {
+		t.Fatalf("GET status = %d body = %s", getRec.Code, getRec.Body.String())
+	}
+}
+
+func TestDeferredExecution_PassesThroughWithoutHeader(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		err:             core.NewProviderError("openai", http.StatusBadGateway, "upstream down", nil),
+	}
+	srv := New(mock, &Config{Deferred: newTestDeferredService(t)})
+
+	req := deferredChatRequest(deferredChatBody)
+	req.Header.Del(core.DeferredHeader)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502", rec.Code)
+	}
+}
+
+func TestDeferredExecution_ReturnsNonRetriableErrors(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		err:             core.NewInvalidRequestError("bad messages", nil),
+	}
+	srv := New(mock, &Config{Deferred: newTestDeferredService(t)})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, deferredChatRequest(deferredChatBody))
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "bad messages") {
+		t.Fatalf("status = %d body = %s, want original 400", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeferredExecution_ReturnsSuccessUnchanged(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response:        &core.ChatResponse{ID: "chatcmpl-1", Object: "chat.completion", Model: "gpt-4o-mini"},
+	}
+	srv := New(mock, &Config{Deferred: newTestDeferredService(t)})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, deferredChatRequest(deferredChatBody))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "chatcmpl-1") {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(core.DeferredIDHeader) != "" {
+		t.Fatalf("successful response carries %s", core.DeferredIDHeader)
+	}
+}
+
+func TestDeferredExecution_RejectsStreaming(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}}
+	srv := New(mock, &Config{Deferred: newTestDeferredService(t)})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, deferredChatRequest(`{"model":"gpt-4o-mini","stream":true,"messages":[]}`))
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "deferred_stream_unsupported") {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeferredExecution_RejectsInvalidCallbackURL(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}}
+	srv := New(mock, &Config{Deferred: newTestDeferredService(t)})
+
+	req := deferredChatRequest(deferredChatBody)
+	req.Header.Set(core.DeferredCallbackHeader, "ftp://example.com/hook")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestDeferredExecution_RejectsCallbackHostOutsideAllowList(t *testing.T) {
+	service, err := deferred.NewService(deferred.NewMemoryStore(), config.DeferredConfig{
+		Enabled:        true,
+		TTL:            time.Hour,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		PollInterval:   time.Second,
+		Callbacks:      config.DeferredCallbackConfig{Enabled: true, AllowedHosts: []string{"hooks.example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("deferred.NewService() error = %v", err)
+	}
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}}
+	srv := New(mock, &Config{Deferred: service})
+
+	req := deferredChatRequest(deferredChatBody)
+	req.Header.Set(core.DeferredCallbackHeader, "http://169.254.169.254/latest/meta-data")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "not allowed") {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeferredExecution_DisabledRejectsOptIn(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}}
+	srv := New(mock, nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, deferredChatRequest(deferredChatBody))
+
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "feature_unavailable") {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetDeferred_HidesOtherKeysRequests(t *testing.T) {
+	service := newTestDeferredService(t)
+	queued, err := service.Enqueue(context.Background(), &deferred.Request{
+		Method:    http.MethodPost,
+		Path:      "/v1/chat/completions",
+		AuthKeyID: "key-a",
+	}, "upstream down")
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	h := &Handler{deferred: service}
+
+	for _, tt := range []struct {
+		keyID string
+		want  int
+	}{
+		{keyID: "key-a", want: http.StatusOK},
+		{keyID: "key-b", want: http.StatusNotFound},
+	} {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/v1/deferred/"+queued.ID, nil)
+		req = req.WithContext(core.WithAuthKeyID(req.Context(), tt.keyID))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: queued.ID}})
+
+		if err := h.GetDeferred(c); err != nil {
+			t.Fatalf("GetDeferred() error = %v", err)
+		}
+		if rec.Code != tt.want {
+			t.Fatalf("key %s: status = %d, want %d", tt.keyID, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestDeferredExecutor_ReplaysWithStoredIdentity(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response:        &core.ChatResponse{ID: "chatcmpl-1", Object: "chat.completion", Model: "gpt-4o-mini"},
+	}
+	srv := New(mock, &Config{MasterKey: "secret", Deferred: newTestDeferredService(t)})
+
+	replay, err := srv.DeferredExecutor().Execute(context.Background(), &deferred.Request{
+		Method:  http.MethodPost,
+		Path:    "/v1/chat/completions",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    []byte(deferredChatBody),
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if replay.StatusCode != http.StatusOK || !strings.Contains(string(replay.Body), "chatcmpl-1") {
+		t.Fatalf("replay = %d %s", replay.StatusCode, replay.Body)
+	}
+
+	if _, err := srv.DeferredExecutor().Execute(context.Background(), &deferred.Request{
+		Method: http.MethodGet,
+		Path:   "/admin/api/v1/usage/summary",
+	}); err == nil {
+		t.Fatal("Execute() replayed a non-deferrable path")
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/deprecations"
+)
+
+// Deprecations warns clients that request a deprecated model. Responses get
+// Deprecation and Sunset headers, and successful non-streaming JSON responses
+// a gomodel_deprecation object, which strict OpenAI compatibility strips.
+// After the model's sunset the request is rejected with a 410 instead when
+// deprecations are configured to reject. Every matching request is counted
+// for the admin view. It runs after workflow resolution so aliases resolved
+// to a deprecated model are caught, and it is only installed when
+// deprecations are enabled.
+func Deprecations(registry *deprecations.Registry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if registry == nil || !provenanceApplies(req.Method, req.URL.Path) {
+				return next(c)
+			}
+			match, ok := registry.Lookup(deprecationTarget(c))
+			if !ok {
+				return next(c)
+			}
+			registry.Record(match, core.GetAuthKeyID(req.Context()))
+			deprecations.SetHeaders(c.Response().Header(), match)
+			if registry.Rejects(match) {
+				return handleError(c, deprecations.RejectionError(match))
+			}
+
+			writer := &deprecationWriter{ResponseWriter: c.Response(), warning: deprecations.WarningFor(match)}
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err := next(c)
+			if flushErr := writer.finish(); err == nil {
+				err = flushErr
+			}
+			return err
+		}
+	}
+}
+
+func deprecationTarget(c *echo.Context) deprecations.Target {
+	target := chaosTarget(c)
+	_, model := provenanceTarget(c)
+	return deprecations.Target{Providers: target.Providers, Models: target.Models, Model: model}
+}
+
+// deprecationWriter holds back a successful JSON body until the handler
+// returns, so the warning can be added to it.
+type deprecationWriter struct {
+	http.ResponseWriter
+	warning deprecations.Warning
+
+	wroteHeader bool
+	held        bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (w *deprecationWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK && embeddableResponse(w.Header()) {
+		w.held = true
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deprecationWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	if w.held {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *deprecationWriter) Flush() {
+	if w.held {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *deprecationWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes a held body with the warning added. Bodies that are not JSON
+// objects are written unchanged.
+func (w *deprecationWriter) finish() error {
+	if !w.held {
+		return nil
+	}
+	body := w.body.Bytes()
+	if embedded, ok := deprecations.Embed(body, w.warning); ok {
+		body = embedded
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/deprecations"
+)
+
+func newDeprecationTestServer(t *testing.T, mock *mockProvider, afterSunset, sunset string) (*Server, *deprecations.Registry) {
+	t.Helper()
+	registry, err := deprecations.New(config.DeprecationsConfig{
+		Enabled:     true,
+		AfterSunset: afterSunset,
+		Models: []config.ModelDeprecationConfig{{
+			Provider:    "mock",
+			Model:       "gpt-4o-mini",
+			Sunset:      sunset,
+			Replacement: "gpt-4.1-mini",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("deprecations.New() error = %v", err)
+	}
+	return New(mock, &Config{Deprecations: registry}), registry
+}
+
+func TestDeprecations_AddsHeadersAndWarning(t *testing.T) {
+	srv, registry := newDeprecationTestServer(t, provenanceChatMock(), "reject", "2999-01-01")
+
+	rec := httptest.NewRecorder()
+	req := chaosChatRequest(false)
+	req = req.WithContext(core.WithAuthKeyID(req.Context(), "key-1"))
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Sunset"); got != "Tue, 01 Jan 2999 00:00:00 GMT" {
+		t.Fatalf("Sunset = %q", got)
+	}
+	if got := rec.Header().Get("Deprecation"); !strings.HasPrefix(got, "@") {
+		t.Fatalf("Deprecation = %q", got)
+	}
+	var body struct {
+		ID      string               `json:"id"`
+		Warning deprecations.Warning `json:"gomodel_deprecation"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v\n%s", err, rec.Body.String())
+	}
+	if body.ID != "chatcmpl-1" || body.Warning.Model != "gpt-4o-mini" || body.Warning.Replacement != "gpt-4.1-mini" {
+		t.Fatalf("body = %+v", body)
+	}
+
+	reports := registry.Reports()
+	if len(reports) != 1 || reports[0].RequestsTotal != 1 || len(reports[0].TopKeys) != 1 || reports[0].TopKeys[0].KeyID != "key-1" {
+		t.Fatalf("reports = %+v", reports)
+	}
+}
+
+func TestDeprecations_StreamGetsHeadersOnly(t *testing.T) {
+	srv, _ := newDeprecationTestServer(t, provenanceChatMock(), "warn", "2999-01-01")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Sunset") == "" {
+		t.Fatalf("status = %d, Sunset = %q", rec.Code, rec.Header().Get("Sunset"))
+	}
+	if strings.Contains(rec.Body.String(), deprecations.Field) {
+		t.Fatalf("stream carries the warning object:\n%s", rec.Body.String())
+	}
+}
+
+func TestDeprecations_RejectsAfterSunset(t *testing.T) {
+	mock := provenanceChatMock()
+	srv, registry := newDeprecationTestServer(t, mock, "reject", "2020-01-01")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusGone {
+		t.Fatalf("status = %d, want 410; body = %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"code":"model_sunset"`) || !strings.Contains(rec.Body.String(), "gpt-4.1-mini") {
+		t.Fatalf("body = %s, want a model_sunset error naming the replacement", rec.Body.String())
+	}
+	if rec.Header().Get("Sunset") == "" {
+		t.Fatal("rejection carries no Sunset header")
+	}
+	if reports := registry.Reports(); reports[0].RequestsTotal != 1 || !reports[0].SunsetPassed {
+		t.Fatalf("reports = %+v", reports)
+	}
+}
+
+func TestDeprecations_WarnOnlyServesAfterSunset(t *testing.T) {
+	srv, _ := newDeprecationTestServer(t, provenanceChatMock(), "warn", "2020-01-01")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "was shut down on 2020-01-01") {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeprecations_IgnoresOtherModels(t *testing.T) {
+	mock := provenanceChatMock()
+	mock.supportedModels = append(mock.supportedModels, "gpt-4.1-mini")
+	srv, registry := newDeprecationTestServer(t, mock, "reject", "2020-01-01")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4.1-mini","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Sunset") != "" || strings.Contains(rec.Body.String(), deprecations.Field) {
+		t.Fatalf("status = %d, Sunset = %q; body = %s", rec.Code, rec.Header().Get("Sunset"), rec.Body.String())
+	}
+	if registry.Reports()[0].RequestsTotal != 0 {
+		t.Fatal("request for another model was counted")
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/embeddingcache"
+	"gomodel/internal/usage"
+)
+
+func TestEmbeddings_CacheServesRepeatedInputs(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"text-embedding-3-small"},
+		embeddingResponse: &core.EmbeddingResponse{
+			Object: "list",
+			Data: []core.EmbeddingData{
+				{Object: "embedding", Embedding: json.RawMessage(`[0.1,0.2]`), Index: 0},
+			},
+			Model:    "text-embedding-3-small",
+			Provider: "openai",
+			Usage:    core.EmbeddingUsage{PromptTokens: 3, TotalTokens: 3},
+		},
+	}
+	usageLog := &collectingUsageLogger{config: usage.Config{Enabled: true}}
+	handler := NewHandler(mock, nil, usageLog, nil)
+	handler.embeddingCache = embeddingcache.New(config.EmbeddingCacheConfig{Enabled: true, MaxEntries: 10})
+
+	send := func() (*httptest.ResponseRecorder, *auditlog.LogEntry) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"hello world"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		entry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+		c.Set(string(auditlog.LogEntryKey), entry)
+		if err := handler.Embeddings(c); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		return rec, entry
+	}
+
+	rec, entry := send()
+	if hits, misses := rec.Header().Get(core.EmbeddingCacheHitsHeader), rec.Header().Get(core.EmbeddingCacheMissesHeader); hits != "0" || misses != "1" {
+		t.Fatalf("first request hits = %q, misses = %q, want 0 and 1", hits, misses)
+	}
+	if got := entry.Data.EmbeddingCache; got == nil || got.Hits != 0 || got.Misses != 1 {
+		t.Fatalf("audit embedding cache = %+v", got)
+	}
+
+	mock.embeddingErr = core.NewProviderError("openai", http.StatusBadGateway, "upstream must not be called", nil)
+	rec, entry = send()
+	if hits, misses := rec.Header().Get(core.EmbeddingCacheHitsHeader), rec.Header().Get(core.EmbeddingCacheMissesHeader); hits != "1" || misses != "0" {
+		t.Fatalf("second request hits = %q, misses = %q, want 1 and 0", hits, misses)
+	}
+	if got := entry.Data.EmbeddingCache; got == nil || got.Hits != 1 || got.Misses != 0 {
+		t.Fatalf("audit embedding cache = %+v", got)
+	}
+	if entry.ResolvedModel == "" || entry.Provider == "" {
+		t.Fatalf("cached response lost its route: resolved model = %q, provider = %q", entry.ResolvedModel, entry.Provider)
+	}
+
+	var resp core.EmbeddingResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if len(resp.Data) != 1 || string(resp.Data[0].Embedding) != `[0.1,0.2]` || resp.Usage.TotalTokens != 0 {
+		t.Fatalf("cached response = %+v", resp)
+	}
+	if len(usageLog.entries) != 1 {
+		t.Fatalf("usage entries = %d, want only the upstream request", len(usageLog.entries))
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"errors"
-	"log/slog"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
@@ -35,9 +34,6 @@ func logHandledError(c *echo.Context, gatewayErr *core.GatewayError) {
 		"status", gatewayErr.HTTPStatusCode(),
 		"message", gatewayErr.Message,
 	}
-	if gatewayErr.Provider != "" {
-		attrs = append(attrs, "provider", gatewayErr.Provider)
-	}
 	if gatewayErr.Param != nil {
 		attrs = append(attrs, "param", *gatewayErr.Param)
 	}
@@ -47,18 +43,21 @@ func logHandledError(c *echo.Context, gatewayErr *core.GatewayError) {
 	if gatewayErr.Err != nil {
 		attrs = append(attrs, "error", gatewayErr.Err)
 	}
-	if c != nil && c.Request() != nil {
-		req := c.Request()
+	var req *http.Request
+	if c != nil {
+		req = c.Request()
+	}
+	if req != nil {
 		attrs = append(attrs,
 			"method", req.Method,
 			"path", req.URL.Path,
-			"request_id", requestIDFromContextOrHeader(req),
 		)
 	}
+	attrs = append(attrs, requestLogAttrs(req, gatewayErr.Provider)...)
 
 	if gatewayErr.HTTPStatusCode() >= http.StatusInternalServerError {
-		slog.Error("request failed", attrs...)
+		serverLogger.Error("request failed", attrs...)
 		return
 	}
-	slog.Warn("request failed", attrs...)
+	serverLogger.Warn("request failed", attrs...)
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/labstack/echo/v5"
 
+	"gomodel/internal/auditlog"
 	"gomodel/internal/core"
 )
 
@@ -88,3 +89,64 @@ func TestHandleError_LogsServerErrorsAtErrorLevel(t *testing.T) {
 		t.Fatalf("expected error message in log, got %q", logOutput)
 	}
 }
+
+func TestHandleError_LogsResolvedProviderAndModel(t *testing.T) {
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(original)
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ctx := core.WithRequestID(req.Context(), "resolved-req-789")
+	ctx = core.WithWorkflow(ctx, &core.Workflow{
+		Resolution: &core.RequestModelResolution{
+			ResolvedSelector: core.ModelSelector{Provider: "openai", Model: "gpt-4o"},
+			ProviderName:     "openai-primary",
+		},
+	})
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req.WithContext(ctx), rec)
+
+	if err := handleError(c, core.NewRateLimitError("openai", "slow down")); err != nil {
+		t.Fatalf("handleError() error = %v", err)
+	}
+
+	logOutput := buf.String()
+	for _, want := range []string{
+		`"component":"server"`,
+		`"request_id":"resolved-req-789"`,
+		`"provider":"openai-primary"`,
+		`"model":"openai/gpt-4o"`,
+	} {
+		if !strings.Contains(logOutput, want) {
+			t.Fatalf("expected %s in log, got %q", want, logOutput)
+		}
+	}
+}
+
+func TestHandleError_RecordsCanonicalProviderErrorType(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	entry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+	c.Set(string(auditlog.LogEntryKey), entry)
+
+	body := []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	if err := handleError(c, core.ParseProviderError("anthropic", 529, body, nil)); err != nil {
+		t.Fatalf("handleError() error = %v", err)
+	}
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if entry.ErrorType != string(core.ErrorTypeOverloaded) {
+		t.Fatalf("audit error type = %q, want %q", entry.ErrorType, core.ErrorTypeOverloaded)
+	}
+	if !strings.Contains(rec.Body.String(), `"provider_error":{"message":"Overloaded","type":"overloaded_error"}`) {
+		t.Fatalf("expected raw upstream error in body, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/gateway"
+)
+
+// slowProvider delays the first response bytes of the selectors in delays.
+// Streams of selectors in stalls send their first chunk at once and then
+// stall before the rest.
+type slowProvider struct {
+	*fallbackProvider
+	delays map[string]time.Duration
+	stalls map[string]time.Duration
+}
+
+// GetProviderName names providers after their type, so the first-token
+// provider entries apply.
+func (p *slowProvider) GetProviderName(model string) string {
+	return p.GetProviderType(model)
+}
+
+func (p *slowProvider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	resp, err := p.fallbackProvider.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleepContext(ctx, p.delays[requestSelector(req.Model, req.Provider)]); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (p *slowProvider) StreamChatCompletion(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
+	key := requestSelector(req.Model, req.Provider)
+	stream, err := p.fallbackProvider.StreamChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := io.ReadAll(stream)
+	return &slowStream{ctx: ctx, data: data, delay: p.delays[key], stall: p.stalls[key]}, nil
+}
+
+type slowStream struct {
+	ctx   context.Context
+	data  []byte
+	delay time.Duration
+	stall time.Duration
+	reads int
+}
+
+func (s *slowStream) Read(p []byte) (int, error) {
+	s.reads++
+	switch s.reads {
+	case 1:
+		if err := sleepContext(s.ctx, s.delay); err != nil {
+			return 0, err
+		}
+		// Hand out a single byte so the rest arrives after the stall.
+		n := copy(p, s.data[:1])
+		s.data = s.data[n:]
+		return n, nil
+	case 2:
+		if err := sleepContext(s.ctx, s.stall); err != nil {
+			return 0, err
+		}
+	}
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+	return n, nil
+}
+
+func (s *slowStream) Close() error { return nil }
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newSlowProvider(delays, stalls map[string]time.Duration) *slowProvider {
+	return &slowProvider{
+		fallbackProvider: &fallbackProvider{
+			chatResponses: map[string]*core.ChatResponse{
+				"gpt-4o":       {ID: "chatcmpl-primary", Object: "chat.completion", Model: "gpt-4o"},
+				"azure/gpt-4o": {ID: "chatcmpl-fallback", Object: "chat.completion", Model: "gpt-4o", Provider: "azure"},
+			},
+			chatStreams: map[string]string{
+				"gpt-4o":       "data: {\"id\":\"primary\"}\n\ndata: [DONE]\n\n",
+				"azure/gpt-4o": "data: {\"id\":\"fallback\"}\n\ndata: [DONE]\n\n",
+			},
+			supportedModels: map[string]string{
+				"gpt-4o":       "openai",
+				"azure/gpt-4o": "azure",
+			},
+		},
+		delays: delays,
+		stalls: stalls,
+	}
+}
+
+func serveFirstTokenChat(t *testing.T, handler *Handler, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header.Set(name, values[0])
+	}
+	if opts, err := core.ParseRequestOptions(req.Header, true); err == nil {
+		req = req.WithContext(core.WithRequestOptions(req.Context(), opts))
+	} else {
+		t.Fatalf("ParseRequestOptions() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	if err := handler.ChatCompletion(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("handler.ChatCompletion() error = %v", err)
+	}
+	return rec
+}
+
+const (
+	firstTokenChatBody   = `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+	firstTokenStreamBody = `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+)
+
+func TestFirstToken_NonStreamTimeout(t *testing.T) {
+	provider := newSlowProvider(map[string]time.Duration{"gpt-4o": time.Second}, nil)
+	handler := newHandler(provider, nil, nil, nil, nil, nil, nil, nil)
+	handler.firstToken = gateway.FirstTokenConfig{Timeout: 20 * time.Millisecond}
+
+	rec := serveFirstTokenChat(t, handler, firstTokenChatBody, nil)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504; body = %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"code":"first_token_timeout"`) {
+		t.Fatalf("body = %s, want a first_token_timeout error", rec.Body.String())
+	}
+}
+
+func TestFirstToken_StreamTimeout(t *testing.T) {
+	provider := newSlowProvider(map[string]time.Duration{"gpt-4o": time.Second}, nil)
+	handler := newHandler(provider, nil, nil, nil, nil, nil, nil, nil)
+	handler.firstToken = gateway.FirstTokenConfig{Models: map[string]time.Duration{"gpt-4o": 20 * time.Millisecond}}
+
+	rec := serveFirstTokenChat(t, handler, firstTokenStreamBody, nil)
+
+	if rec.Code != http.StatusGatewayTimeout || !strings.Contains(rec.Body.String(), `"code":"first_token_timeout"`) {
+		t.Fatalf("status = %d, want 504 first_token_timeout; body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFirstToken_StreamDisarmsAfterFirstChunk(t *testing.T) {
+	provider := newSlowProvider(nil, map[string]time.Duration{"gpt-4o": 100 * time.Millisecond})
+	handler := newHandler(provider, nil, nil, nil, nil, nil, nil, nil)
+	handler.firstToken = gateway.FirstTokenConfig{Timeout: 20 * time.Millisecond}
+
+	rec := serveFirstTokenChat(t, handler, firstTokenStreamBody, nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"id":"primary"`) || !strings.Contains(body, "[DONE]") {
+		t.Fatalf("body = %s, want the whole stream after the stall", body)
+	}
+}
+
+func TestFirstToken_FallsBackOnTimeout(t *testing.T) {
+	for _, body := range []string{firstTokenChatBody, firstTokenStreamBody} {
+		provider := newSlowProvider(map[string]time.Duration{"gpt-4o": time.Second}, nil)
+		handler := newHandler(provider, nil, nil, nil, nil, nil, fallbackResolverStub{
+			selectors: []core.ModelSelector{{Provider: "azure", Model: "gpt-4o"}},
+		}, nil)
+		handler.firstToken = gateway.FirstTokenConfig{Providers: map[string]time.Duration{"openai": 20 * time.Millisecond}}
+
+		rec := serveFirstTokenChat(t, handler, body, nil)
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "fallback") {
+			t.Fatalf("status = %d, want 200 from the fallback; body = %s", rec.Code, rec.Body.String())
+		}
+		if len(provider.chatCalls) != 2 || provider.chatCalls[1] != "azure/gpt-4o" {
+			t.Fatalf("chat calls = %v, want [gpt-4o azure/gpt-4o]", provider.chatCalls)
+		}
+	}
+}
+
+func TestFirstToken_RequestOptionOverrides(t *testing.T) {
+	provider := newSlowProvider(map[string]time.Duration{"gpt-4o": 50 * time.Millisecond}, nil)
+	handler := newHandler(provider, nil, nil, nil, nil, nil, nil, nil)
+	handler.firstToken = gateway.FirstTokenConfig{Timeout: 10 * time.Millisecond}
+
+	rec := serveFirstTokenChat(t, handler, firstTokenChatBody, http.Header{core.FirstTokenTimeoutHeader: {"off"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deadline off: status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+
+	handler.firstToken = gateway.FirstTokenConfig{}
+	rec = serveFirstTokenChat(t, handler, firstTokenChatBody, http.Header{core.FirstTokenTimeoutHeader: {"10ms"}})
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("deadline from header: status = %d, want 504; body = %s", rec.Code, rec.Body.String())
+	}
+}
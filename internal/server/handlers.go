@@ -3,6 +3,7 @@ package server
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/labstack/echo/v5"
@@ -10,6 +11,11 @@ import (
 	"gomodel/internal/auditlog"
 	batchstore "gomodel/internal/batch"
 	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/embeddingcache"
+	"gomodel/internal/gateway"
+	"gomodel/internal/idempotency"
+	"gomodel/internal/maintenance"
 	"gomodel/internal/responsecache"
 	"gomodel/internal/responsestore"
 	"gomodel/internal/usage"
@@ -25,6 +31,7 @@ type Handler struct {
 	translatedRequestPatcher        TranslatedRequestPatcher
 	batchRequestPreparer            BatchRequestPreparer
 	exposedModelLister              ExposedModelLister
+	modelGroupLister                ExposedModelLister
 	keepOnlyAliasesAtModelsEndpoint bool
 	logger                          auditlog.LoggerInterface
 	usageLogger                     usage.LoggerInterface
@@ -36,6 +43,24 @@ type Handler struct {
 	enabledPassthroughProviders     map[string]struct{}
 	responseCache                   *responsecache.ResponseCacheMiddleware
 	guardrailsHash                  string
+	contextOverflow                 gateway.ContextOverflowConfig
+	promptCompression               gateway.PromptCompressionConfig
+	firstToken                      gateway.FirstTokenConfig
+	moderation                      gateway.ModerationConfig
+	serverTools                     gateway.ServerToolsConfig
+	promptCache                     gateway.PromptCacheMatcher
+	maxTokensDefaults               gateway.MaxTokensDefaulter
+	deferred                        *deferred.Service
+	idempotency                     *idempotency.Service
+	comparisonLimits                ComparisonLimits
+	inlineImageLimits               core.InlineImageLimits
+	promptTemplates                 PromptTemplateRenderer
+	recordRawUser                   bool
+	streamBackpressure              StreamBackpressure
+	maintenance                     *maintenance.Mode
+	embeddingCache                  *embeddingcache.Cache
+	usageReader                     usage.UsageReader
+	keyUsageCache                   *keyUsageCache
 
 	translatedSvc     *translatedInferenceService // snapshot of handler fields at first use; server.New sets cache/hash before traffic
 	translatedSvcOnce sync.Once
@@ -91,6 +116,7 @@ func newHandlerWithAuthorizer(
 		usageLogger:              usageLogger,
 		pricingResolver:          pricingResolver,
 		batchStore:               batchstore.NewMemoryStore(),
+		keyUsageCache:            newKeyUsageCache(),
 		responseStore: responsestore.NewMemoryStore(
 			responsestore.WithTTL(responsestore.DefaultMemoryStoreTTL),
 			responsestore.WithMaxEntries(responsestore.DefaultMemoryStoreMaxEntries),
@@ -137,6 +163,18 @@ func (h *Handler) translatedInference() *translatedInferenceService {
 			pricingResolver:          h.pricingResolver,
 			responseCache:            h.responseCache,
 			guardrailsHash:           h.guardrailsHash,
+			contextOverflow:          h.contextOverflow,
+			promptCompression:        h.promptCompression,
+			firstToken:               h.firstToken,
+			moderation:               h.moderation,
+			serverTools:              h.serverTools,
+			promptCache:              h.promptCache,
+			maxTokensDefaults:        h.maxTokensDefaults,
+			inlineImageLimits:        h.inlineImageLimits,
+			promptTemplates:          h.promptTemplates,
+			recordRawUser:            h.recordRawUser,
+			streamBackpressure:       h.streamBackpressure,
+			embeddingCache:           h.embeddingCache,
 			responseStore:            h.currentResponseStore(),
 		}
 		s.initHandlers()
@@ -195,6 +233,7 @@ func (h *Handler) passthrough() *passthroughService {
 		pricingResolver:              h.pricingResolver,
 		normalizePassthroughV1Prefix: h.normalizePassthroughV1Prefix,
 		enabledPassthroughProviders:  h.enabledPassthroughProviders,
+		streamBackpressure:           h.streamBackpressure,
 	}
 }
 
@@ -232,6 +271,32 @@ func (h *Handler) ProviderPassthrough(c *echo.Context) error {
 	return h.passthrough().ProviderPassthrough(c)
 }
 
+// AssistantsPassthrough forwards OpenAI Assistants API requests under
+// /v1/assistants and /v1/threads verbatim to the OpenAI provider.
+//
+// @Summary      OpenAI Assistants passthrough
+// @Description  Registered only when server.enable_assistants_passthrough is true. Forwards assistants, threads, messages, runs and run steps verbatim (method, path, query and body) to the configured OpenAI provider with the provider API key, and relays run event streams as SSE. Returns 404 when no OpenAI provider is configured.
+// @Tags         assistants
+// @Accept       json
+// @Produce      json
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        path  path      string  true  "Assistants API path below /v1/assistants or /v1/threads"
+// @Success      200   {file}    file    "Opaque upstream response body"
+// @Failure      400   {object}  core.OpenAIErrorEnvelope
+// @Failure      401   {object}  core.OpenAIErrorEnvelope
+// @Failure      404   {object}  core.OpenAIErrorEnvelope
+// @Failure      502   {object}  core.OpenAIErrorEnvelope
+// @Router       /v1/assistants/{path} [get]
+// @Router       /v1/assistants/{path} [post]
+// @Router       /v1/assistants/{path} [delete]
+// @Router       /v1/threads/{path} [get]
+// @Router       /v1/threads/{path} [post]
+// @Router       /v1/threads/{path} [delete]
+func (h *Handler) AssistantsPassthrough(c *echo.Context) error {
+	return h.passthrough().AssistantsPassthrough(c)
+}
+
 // ChatCompletion handles POST /v1/chat/completions
 //
 // @Summary      Create a chat completion
@@ -251,15 +316,45 @@ func (h *Handler) ChatCompletion(c *echo.Context) error {
 	return h.translatedInference().ChatCompletion(c)
 }
 
+// ChatCompletionCompare handles POST /v1/chat/completions/compare
+//
+// @Summary      Compare chat completions across models
+// @Description  Sends one chat request to every listed model concurrently. Results keep the order of models and report per-model failures without failing the whole request. With stream=true, chunks from all models are multiplexed into one SSE stream of comparison events tagged with the model index.
+// @Tags         chat
+// @Accept       json
+// @Produce      json
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        request  body      core.ChatComparisonRequest  true  "Chat request plus the models to compare"
+// @Success      200      {object}  core.ChatComparisonResponse  "JSON response, or SSE stream of core.ChatComparisonStreamEvent when stream=true"
+// @Failure      400      {object}  core.OpenAIErrorEnvelope
+// @Failure      401      {object}  core.OpenAIErrorEnvelope
+// @Router       /v1/chat/completions/compare [post]
+func (h *Handler) ChatCompletionCompare(c *echo.Context) error {
+	return h.chatComparison().Compare(c)
+}
+
+// healthResponse is the body of GET /health. Status is "maintenance" while
+// maintenance mode rejects model requests.
+type healthResponse struct {
+	Status      string             `json:"status"`
+	Maintenance *maintenance.State `json:"maintenance,omitempty"`
+}
+
 // Health handles GET /health
 //
+// The process stays healthy during maintenance, so it answers 200 either way.
+//
 // @Summary      Health check
 // @Tags         system
 // @Produce      json
-// @Success      200  {object}  map[string]string
+// @Success      200  {object}  healthResponse
 // @Router       /health [get]
 func (h *Handler) Health(c *echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	if state := h.maintenance.State(); state.Active {
+		return c.JSON(http.StatusOK, healthResponse{Status: "maintenance", Maintenance: &state})
+	}
+	return c.JSON(http.StatusOK, healthResponse{Status: "ok"})
 }
 
 // ListModels handles GET /v1/models
@@ -268,7 +363,9 @@ func (h *Handler) Health(c *echo.Context) error {
 // @Tags         models
 // @Produce      json
 // @Security     BearerAuth
+// @Param        extended  query     bool  false  "Also list model groups as group:<name> entries"
 // @Success      200  {object}  core.ModelsResponse
+// @Success      304  "Not modified; the If-None-Match header matches the current listing"
 // @Failure      401  {object}  core.OpenAIErrorEnvelope
 // @Failure      502  {object}  core.OpenAIErrorEnvelope
 // @Router       /v1/models [get]
@@ -315,8 +412,13 @@ func (h *Handler) ListModels(c *echo.Context) error {
 			resp = mergeExposedModelsResponse(resp, exposed)
 		}
 	}
+	if h.modelGroupLister != nil {
+		if extended, _ := strconv.ParseBool(c.QueryParam("extended")); extended {
+			resp = mergeExposedModelsResponse(resp, h.modelGroupLister.ExposedModels())
+		}
+	}
 
-	return c.JSON(http.StatusOK, resp)
+	return core.WriteCacheableJSON(c.Response(), c.Request(), core.ModelListCacheControl, resp)
 }
 
 // CreateFile handles POST /v1/files.
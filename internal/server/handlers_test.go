@@ -13,9 +13,11 @@ import (
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/labstack/echo/v5"
@@ -23,11 +25,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gomodel/config"
 	"gomodel/internal/aliases"
 	"gomodel/internal/auditlog"
 	batchstore "gomodel/internal/batch"
 	"gomodel/internal/core"
+	"gomodel/internal/gateway"
 	"gomodel/internal/guardrails"
+	"gomodel/internal/maxtokens"
 	"gomodel/internal/observability"
 	provideradapter "gomodel/internal/providers"
 	"gomodel/internal/responsestore"
@@ -339,6 +344,7 @@ type mockProvider struct {
 	embeddingResponse *core.EmbeddingResponse
 	embeddingErr      error
 	streamData        string
+	streamErr         error
 	supportedModels   []string
 	providerTypes     map[string]string
 	providerNames     map[string]string
@@ -696,7 +702,15 @@ func (m *mockProvider) StreamChatCompletion(_ context.Context, _ *core.ChatReque
 	if m.err != nil {
 		return nil, m.err
 	}
-	return io.NopCloser(strings.NewReader(m.streamData)), nil
+	return m.stream(), nil
+}
+
+// stream returns streamData, failing with streamErr once it is drained.
+func (m *mockProvider) stream() io.ReadCloser {
+	if m.streamErr != nil {
+		return io.NopCloser(io.MultiReader(strings.NewReader(m.streamData), iotest.ErrReader(m.streamErr)))
+	}
+	return io.NopCloser(strings.NewReader(m.streamData))
 }
 
 func (m *mockProvider) ListModels(_ context.Context) (*core.ModelsResponse, error) {
@@ -717,7 +731,7 @@ func (m *mockProvider) StreamResponses(_ context.Context, _ *core.ResponsesReque
 	if m.err != nil {
 		return nil, m.err
 	}
-	return io.NopCloser(strings.NewReader(m.streamData)), nil
+	return m.stream(), nil
 }
 
 func (m *mockProvider) Embeddings(_ context.Context, _ *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
@@ -1116,6 +1130,230 @@ func TestChatCompletion(t *testing.T) {
 	}
 }
 
+type contextWindowResolverFunc func(model, providerType string) int
+
+func (f contextWindowResolverFunc) ResolveContextWindow(model, providerType string) int {
+	return f(model, providerType)
+}
+
+func TestChatCompletion_ContextOverflow(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:      "chatcmpl-overflow",
+			Object:  "chat.completion",
+			Model:   "gpt-4o-mini",
+			Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "ok"}, FinishReason: "stop"}},
+		},
+	}
+	long := strings.Repeat("x", 400)
+	reqBody := `{"model":"gpt-4o-mini","messages":[` +
+		`{"role":"system","content":"be brief"},` +
+		`{"role":"user","content":"` + long + `"},` +
+		`{"role":"assistant","content":"` + long + `"},` +
+		`{"role":"user","content":"Hi"}]}`
+
+	serve := func(strategyHeader string) (*httptest.ResponseRecorder, *auditlog.LogEntry, error) {
+		handler := NewHandler(mock, nil, nil, nil)
+		handler.contextOverflow = gateway.ContextOverflowConfig{
+			Strategy: core.ContextOverflowTruncateOldest,
+			Resolver: contextWindowResolverFunc(func(string, string) int { return 100 }),
+		}
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if strategyHeader != "" {
+			req.Header.Set(core.ContextOverflowHeader, strategyHeader)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		entry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+		c.Set(string(auditlog.LogEntryKey), entry)
+		return rec, entry, handler.ChatCompletion(c)
+	}
+
+	rec, entry, err := serve("")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "truncate_oldest", rec.Header().Get(core.ContextOverflowHeader))
+	assert.Equal(t, "2", rec.Header().Get(core.ContextOverflowDroppedHeader))
+	require.NotNil(t, entry.Data.ContextOverflow)
+	assert.Equal(t, auditlog.ContextOverflowSnapshot{
+		Strategy:        "truncate_oldest",
+		EstimatedTokens: entry.Data.ContextOverflow.EstimatedTokens,
+		Limit:           100,
+		DroppedMessages: 2,
+	}, *entry.Data.ContextOverflow)
+	assert.Greater(t, entry.Data.ContextOverflow.EstimatedTokens, 100)
+
+	rec, entry, err = serve("reject")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "context_length_exceeded")
+	assert.Empty(t, rec.Header().Get(core.ContextOverflowHeader))
+	assert.Nil(t, entry.Data.ContextOverflow)
+}
+
+func TestChatCompletion_PromptCompression(t *testing.T) {
+	provider := &capturingProvider{
+		mockProvider: mockProvider{
+			supportedModels: []string{"gpt-4o-mini"},
+			response: &core.ChatResponse{
+				ID:      "chatcmpl-compressed",
+				Object:  "chat.completion",
+				Model:   "gpt-4o-mini",
+				Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "ok"}, FinishReason: "stop"}},
+			},
+		},
+	}
+	reqBody := `{"model":"gpt-4o-mini","messages":[` +
+		`{"role":"system","content":"be    brief"},` +
+		`{"role":"user","content":"Context:\n\n\n\n    refunds     take    five    days    "},` +
+		`{"role":"user","content":"How    long?"}]}`
+
+	serve := func(passesHeader string) (*httptest.ResponseRecorder, *auditlog.LogEntry, error) {
+		handler := NewHandler(provider, nil, nil, nil)
+		handler.promptCompression = gateway.PromptCompressionConfig{
+			Passes: core.PromptCompressionPasses{core.PromptCompressionWhitespace: true},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if passesHeader != "" {
+			req.Header.Set(core.PromptCompressionHeader, passesHeader)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		entry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+		c.Set(string(auditlog.LogEntryKey), entry)
+		return rec, entry, handler.ChatCompletion(c)
+	}
+
+	rec, entry, err := serve("")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "whitespace", rec.Header().Get(core.PromptCompressionHeader))
+	saved, err := strconv.Atoi(rec.Header().Get(core.PromptTokensSavedHeader))
+	require.NoError(t, err)
+	assert.Positive(t, saved)
+	require.NotNil(t, entry.Data.PromptCompression)
+	assert.Equal(t, []string{"whitespace"}, entry.Data.PromptCompression.Passes)
+	assert.Equal(t, saved, entry.Data.PromptCompression.TokensSaved)
+
+	require.NotNil(t, provider.capturedChatReq)
+	messages := provider.capturedChatReq.Messages
+	assert.Equal(t, "be    brief", messages[0].Content)
+	assert.Equal(t, "Context:\n\nrefunds take five days", messages[1].Content)
+	assert.Equal(t, "How    long?", messages[2].Content)
+
+	rec, entry, err = serve("off")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(core.PromptCompressionHeader))
+	assert.Nil(t, entry.Data.PromptCompression)
+
+	rec, _, err = serve("zip")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestChatCompletion_MaxTokensDefault(t *testing.T) {
+	provider := &capturingProvider{
+		mockProvider: mockProvider{
+			supportedModels: []string{"gpt-4o-mini"},
+			response: &core.ChatResponse{
+				ID:      "chatcmpl-default",
+				Object:  "chat.completion",
+				Model:   "gpt-4o-mini",
+				Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "ok"}, FinishReason: "stop"}},
+			},
+		},
+	}
+	serve := func(reqBody string) *httptest.ResponseRecorder {
+		handler := NewHandler(provider, nil, nil, nil)
+		handler.maxTokensDefaults = maxtokens.New(config.DefaultMaxTokensConfig{
+			Default: 1000,
+			Models:  map[string]int{"gpt-4o-mini": 512},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.ChatCompletion(echo.New().NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return rec
+	}
+
+	rec := serve(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"Hi"}]}`)
+	assert.Equal(t, "512", rec.Header().Get(core.DefaultMaxTokensHeader))
+	assert.Equal(t, "model", rec.Header().Get(core.DefaultMaxTokensSourceHeader))
+	require.NotNil(t, provider.capturedChatReq.MaxTokens)
+	assert.Equal(t, 512, *provider.capturedChatReq.MaxTokens)
+
+	rec = serve(`{"model":"gpt-4o-mini","max_tokens":20,"messages":[{"role":"user","content":"Hi"}]}`)
+	assert.Empty(t, rec.Header().Get(core.DefaultMaxTokensHeader))
+	require.NotNil(t, provider.capturedChatReq.MaxTokens)
+	assert.Equal(t, 20, *provider.capturedChatReq.MaxTokens)
+}
+
+type moderatorFunc func(context.Context, string) (map[string]float64, error)
+
+func (f moderatorFunc) Moderate(ctx context.Context, text string) (map[string]float64, error) {
+	return f(ctx, text)
+}
+
+func TestChatCompletion_Moderation(t *testing.T) {
+	provider := &capturingProvider{
+		mockProvider: mockProvider{
+			supportedModels: []string{"gpt-4o-mini"},
+			response: &core.ChatResponse{
+				ID:      "chatcmpl-moderated",
+				Object:  "chat.completion",
+				Model:   "gpt-4o-mini",
+				Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "ok"}, FinishReason: "stop"}},
+			},
+		},
+	}
+	serve := func(cfg gateway.ModerationConfig) (*httptest.ResponseRecorder, *auditlog.LogEntry) {
+		handler := NewHandler(provider, nil, nil, nil)
+		handler.moderation = cfg
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+			strings.NewReader(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"fight club rules"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		entry := &auditlog.LogEntry{Data: &auditlog.LogData{}}
+		c.Set(string(auditlog.LogEntryKey), entry)
+		require.NoError(t, handler.ChatCompletion(c))
+		return rec, entry
+	}
+	violent := moderatorFunc(func(_ context.Context, text string) (map[string]float64, error) {
+		assert.Equal(t, "fight club rules", text)
+		return map[string]float64{"violence": 0.8, "hate": 0.1}, nil
+	})
+
+	rec, _ := serve(gateway.ModerationConfig{Moderator: violent})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":{"type":"invalid_request_error","message":"request blocked by content moderation: violence","param":null,"code":"content_policy_violation"}}`, rec.Body.String())
+
+	rec, entry := serve(gateway.ModerationConfig{Moderator: violent, DefaultAction: core.ModerationFlag, ExposeScores: true})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "flagged", rec.Header().Get(core.ModerationHeader))
+	assert.Equal(t, "violence=0.80", rec.Header().Get(core.ModerationCategoriesHeader))
+	require.NotNil(t, entry.Data.Moderation)
+	assert.Equal(t, []string{"violence"}, entry.Data.Moderation.Flagged)
+	assert.Equal(t, 0.1, entry.Data.Moderation.Scores["hate"])
+
+	down := moderatorFunc(func(context.Context, string) (map[string]float64, error) {
+		return nil, errors.New("guard model down")
+	})
+	rec, _ = serve(gateway.ModerationConfig{Moderator: down})
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec, entry = serve(gateway.ModerationConfig{Moderator: down, FailOpen: true})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "unavailable", rec.Header().Get(core.ModerationHeader))
+	require.NotNil(t, entry.Data.Moderation)
+	assert.True(t, entry.Data.Moderation.Unavailable)
+}
+
 func TestChatCompletion_BindsMultimodalContent(t *testing.T) {
 	provider := &capturingProvider{
 		mockProvider: mockProvider{
@@ -2506,24 +2744,24 @@ func TestHandleStreamingResponse_FlushesEachChunk(t *testing.T) {
 	}
 }
 
-func TestFlushStream_ReturnsReadError(t *testing.T) {
+func TestPumpStream_ReturnsReadError(t *testing.T) {
 	expectedErr := errors.New("stream read failed")
 	stream := &erroringReadCloser{
 		data: []byte("data: {\"id\":\"1\"}\n\n"),
 		err:  expectedErr,
 	}
 
-	err := flushStream(io.Discard, stream)
+	_, err := pumpStream(io.Discard, stream, StreamBackpressure{})
 	if !errors.Is(err, expectedErr) {
 		t.Fatalf("expected read error %v, got %v", expectedErr, err)
 	}
 }
 
-func TestFlushStream_ReturnsWriteError(t *testing.T) {
+func TestPumpStream_ReturnsWriteError(t *testing.T) {
 	expectedErr := errors.New("client write failed")
 	stream := io.NopCloser(strings.NewReader("data: {\"id\":\"1\"}\n\n"))
 
-	err := flushStream(&erroringWriter{err: expectedErr}, stream)
+	_, err := pumpStream(&erroringWriter{err: expectedErr}, stream, StreamBackpressure{})
 	if !errors.Is(err, expectedErr) {
 		t.Fatalf("expected write error %v, got %v", expectedErr, err)
 	}
@@ -2756,6 +2994,58 @@ func TestListModels(t *testing.T) {
 	}
 }
 
+func TestListModels_ConditionalRequest(t *testing.T) {
+	mock := &mockProvider{
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "gpt-4o-mini", Object: "model", OwnedBy: "system"}},
+		},
+	}
+	e := echo.New()
+	handler := NewHandler(mock, nil, nil, nil)
+
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		if err := handler.ListModels(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return rec
+	}
+
+	first := list("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q; want 200 with an ETag", first.Code, etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != core.ModelListCacheControl {
+		t.Errorf("Cache-Control = %q, want %q", got, core.ModelListCacheControl)
+	}
+	if got := list("").Header().Get("ETag"); got != etag {
+		t.Errorf("ETag changed between identical listings: %q != %q", got, etag)
+	}
+
+	rec := list(etag)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want 304", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("304 body = %q, want empty", rec.Body.String())
+	}
+
+	mock.modelsResponse = &core.ModelsResponse{
+		Object: "list",
+		Data:   []core.Model{{ID: "gpt-4-turbo", Object: "model", OwnedBy: "system"}},
+	}
+	if rec := list(etag); rec.Code != http.StatusOK {
+		t.Fatalf("status after models changed = %d, want 200", rec.Code)
+	}
+}
+
 func TestListModels_MergesExposedModelsWithoutAliasProviderDecorator(t *testing.T) {
 	catalog := &aliasesTestCatalog{
 		supported: map[string]bool{
@@ -2890,6 +3180,35 @@ func TestListModels_FiltersExposedModelsWhenAuthorizerIsPresent(t *testing.T) {
 	require.NotContains(t, body, `"id":"openai/gpt-5"`)
 }
 
+func TestListModels_ListsModelGroupsWhenExtended(t *testing.T) {
+	mock := &mockProvider{
+		modelsResponse: &core.ModelsResponse{
+			Object: "list",
+			Data:   []core.Model{{ID: "gpt-4o", Object: "model", OwnedBy: "openai"}},
+		},
+	}
+	handler := NewHandler(mock, nil, nil, nil)
+	handler.modelGroupLister = staticExposedModelLister{
+		models: []core.Model{{ID: "group:coding", Object: "model", OwnedBy: "gomodel"}},
+	}
+
+	for _, tt := range []struct {
+		target    string
+		wantGroup bool
+	}{
+		{"/v1/models", false},
+		{"/v1/models?extended=false", false},
+		{"/v1/models?extended=true", true},
+	} {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, tt.target, nil), rec)
+		require.NoError(t, handler.ListModels(c))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `"id":"gpt-4o"`)
+		require.Equal(t, tt.wantGroup, strings.Contains(rec.Body.String(), `"id":"group:coding"`), tt.target)
+	}
+}
+
 func TestListModelsError(t *testing.T) {
 	mock := &mockProvider{
 		err: io.EOF, // Simulate an error
@@ -4932,9 +5251,10 @@ type mockUsageLogger struct {
 	config usage.Config
 }
 
-func (m *mockUsageLogger) Write(_ *usage.UsageEntry) {}
-func (m *mockUsageLogger) Config() usage.Config      { return m.config }
-func (m *mockUsageLogger) Close() error              { return nil }
+func (m *mockUsageLogger) Write(_ *usage.UsageEntry)   {}
+func (m *mockUsageLogger) Config() usage.Config        { return m.config }
+func (m *mockUsageLogger) Flush(context.Context) error { return nil }
+func (m *mockUsageLogger) Close() error                { return nil }
 
 type capturingUsageLogger struct {
 	config   usage.Config
@@ -4943,6 +5263,7 @@ type capturingUsageLogger struct {
 
 func (c *capturingUsageLogger) Write(entry *usage.UsageEntry) { *c.captured = entry }
 func (c *capturingUsageLogger) Config() usage.Config          { return c.config }
+func (c *capturingUsageLogger) Flush(context.Context) error   { return nil }
 func (c *capturingUsageLogger) Close() error                  { return nil }
 
 type collectingUsageLogger struct {
@@ -4957,8 +5278,9 @@ func (c *collectingUsageLogger) Write(entry *usage.UsageEntry) {
 	c.entries = append(c.entries, entry)
 }
 
-func (c *collectingUsageLogger) Config() usage.Config { return c.config }
-func (c *collectingUsageLogger) Close() error         { return nil }
+func (c *collectingUsageLogger) Config() usage.Config        { return c.config }
+func (c *collectingUsageLogger) Flush(context.Context) error { return nil }
+func (c *collectingUsageLogger) Close() error                { return nil }
 
 type mockPricingResolver struct {
 	pricing *core.ModelPricing
@@ -6098,6 +6420,7 @@ func TestProviderPassthrough_OpenAI(t *testing.T) {
 	req.Header.Set("X-Debug", "secret")
 	req.Header.Set("X-Request-ID", "req_123")
 	req.Header.Set(core.UserPathHeader, "/team/a/user")
+	req.Header.Set(core.LabelHeaderPrefix+"team", "growth")
 
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
@@ -6153,6 +6476,9 @@ func TestProviderPassthrough_OpenAI(t *testing.T) {
 	if got := provider.lastPassthroughReq.Headers.Get(core.UserPathHeader); got != "" {
 		t.Fatalf("%s should not be forwarded, got %q", core.UserPathHeader, got)
 	}
+	if got := provider.lastPassthroughReq.Headers.Get(core.LabelHeaderPrefix + "team"); got != "" {
+		t.Fatalf("label header should not be forwarded, got %q", got)
+	}
 }
 
 func TestProviderPassthrough_PrefersContextRequestID(t *testing.T) {
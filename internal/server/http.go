@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"gomodel/config"
+	"gomodel/internal/accesslog"
 
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
@@ -21,14 +22,35 @@ import (
 	"gomodel/internal/admin/dashboard"
 	"gomodel/internal/auditlog"
 	batchstore "gomodel/internal/batch"
+	"gomodel/internal/chaos"
 	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/deprecations"
+	"gomodel/internal/embeddingcache"
+	"gomodel/internal/gateway"
+	"gomodel/internal/idempotency"
+	"gomodel/internal/inspect"
+	"gomodel/internal/logging"
+	"gomodel/internal/maintenance"
+	"gomodel/internal/maxtokens"
+	"gomodel/internal/playground"
+	"gomodel/internal/promptcache"
+	"gomodel/internal/provenance"
 	"gomodel/internal/responsecache"
 	"gomodel/internal/responsestore"
+	"gomodel/internal/responsetransform"
+	"gomodel/internal/sanitize"
+	"gomodel/internal/scoreboard"
+	"gomodel/internal/secretscan"
+	"gomodel/internal/selfprotect"
 	"gomodel/internal/usage"
 
 	echoswagger "github.com/swaggo/echo-swagger"
 )
 
+// serverLogger logs request handling and server lifecycle events.
+var serverLogger = logging.Component(logging.ComponentServer)
+
 // Server wraps the Echo server
 type Server struct {
 	echo                    *echo.Echo
@@ -53,6 +75,7 @@ type Config struct {
 	PprofEnabled                    bool                                   // Whether to expose debug profiling routes at /debug/pprof/*
 	AuditLogger                     auditlog.LoggerInterface               // Optional: Audit logger for request/response logging
 	UsageLogger                     usage.LoggerInterface                  // Optional: Usage logger for token tracking
+	UsageReader                     usage.UsageReader                      // Optional: serves GET /v1/usage; nil reports usage tracking as disabled
 	PricingResolver                 usage.PricingResolver                  // Optional: Resolves pricing for cost calculation
 	ModelResolver                   RequestModelResolver                   // Optional: explicit model resolver used during workflow resolution
 	ModelAuthorizer                 RequestModelAuthorizer                 // Optional: request-scoped concrete model access controller
@@ -61,22 +84,56 @@ type Config struct {
 	TranslatedRequestPatcher        TranslatedRequestPatcher               // Optional: request patcher for translated routes after workflow resolution
 	BatchRequestPreparer            BatchRequestPreparer                   // Optional: batch request preparer before native provider submission
 	ExposedModelLister              ExposedModelLister                     // Optional: additional public models to merge into GET /v1/models
+	ModelGroupLister                ExposedModelLister                     // Optional: model groups merged into GET /v1/models?extended=true
 	KeepOnlyAliasesAtModelsEndpoint bool                                   // Whether GET /v1/models should hide concrete provider models
 	PassthroughSemanticEnrichers    []core.PassthroughSemanticEnricher     // Optional: provider-owned passthrough semantic enrichers before workflow resolution
 	BatchStore                      batchstore.Store                       // Optional: Batch lifecycle persistence store
 	ResponseStore                   responsestore.Store                    // Optional: Responses lifecycle persistence store
 	LogOnlyModelInteractions        bool                                   // Only log AI model endpoints (default: true)
+	AccessLog                       *accesslog.Logger                      // Optional: writes one access log line per request; nil keeps it uninstalled
 	DisablePassthroughRoutes        bool                                   // Disable /p/{provider}/{endpoint} route registration
 	EnabledPassthroughProviders     []string                               // Provider types enabled on /p/{provider}/... passthrough routes
 	AllowPassthroughV1Alias         *bool                                  // Allow /p/{provider}/v1/... aliases; nil defaults to true
+	EnableAssistantsPassthrough     bool                                   // Register /v1/assistants and /v1/threads passthrough routes to the OpenAI provider
 	AdminEndpointsEnabled           bool                                   // Whether admin API endpoints are enabled
 	AdminUIEnabled                  bool                                   // Whether admin dashboard UI is enabled
 	AdminHandler                    *admin.Handler                         // Admin API handler (nil if disabled)
 	DashboardHandler                *dashboard.Handler                     // Dashboard UI handler (nil if disabled)
+	PlaygroundHandler               *playground.Handler                    // Playground UI handler (nil if disabled)
 	SwaggerEnabled                  bool                                   // Whether to expose the Swagger UI at /swagger/index.html
 	ResponseCacheMiddleware         *responsecache.ResponseCacheMiddleware // Optional: response cache middleware for cacheable endpoints
 	GuardrailsHash                  string                                 // Optional: SHA-256 hash of active guardrail rules; stored in context post-patch for semantic cache
 	IPExtractor                     echo.IPExtractor                       // Optional: trusted client IP extraction strategy for proxied deployments
+	ComparisonLimits                ComparisonLimits                       // Limits for POST /v1/chat/completions/compare; zero values use defaults
+	ContextOverflow                 gateway.ContextOverflowConfig          // Optional: context window overflow handling for translated chat requests
+	PromptCompression               gateway.PromptCompressionConfig        // Optional: prompt compression passes for translated chat requests
+	FirstToken                      gateway.FirstTokenConfig               // Optional: deadline for providers to start answering translated chat and Responses requests
+	Moderation                      gateway.ModerationConfig               // Optional: moderation pre-check for translated chat and Responses requests
+	ServerTools                     gateway.ServerToolsConfig              // Optional: server-side tools granted to translated chat and Responses requests
+	PromptCache                     *promptcache.Tracker                   // Optional: prompt caching of shared system prompts with hit tracking; nil adds no cache directives
+	MaxTokensDefaults               *maxtokens.Policy                      // Optional: max_tokens defaults for translated chat and Responses requests without a limit
+	InlineImageLimits               core.InlineImageLimits                 // Limits for inline base64 images in translated requests; zero values disable them
+	PromptTemplates                 PromptTemplateRenderer                 // Optional: renders the template field of chat and responses requests
+	RecordRawUser                   bool                                   // Record the raw user request field on usage and audit entries next to its hash
+	StreamBackpressure              StreamBackpressure                     // Per-connection send buffer limits for streamed responses; zero values use defaults
+	RequestBodyStreaming            RequestBodyStreaming                   // Large chat and responses bodies piped upstream without buffering; zero Threshold always buffers
+	StrictOpenAICompat              bool                                   // Strip gateway extensions from /v1 responses unless a request opts out
+	StrictRequestOptions            bool                                   // Reject unknown X-GoModel-Options keys with a 400 instead of ignoring them
+	Scoreboard                      *scoreboard.Scoreboard                 // Optional: in-memory provider+model performance stats fed from model interactions
+	Deferred                        *deferred.Service                      // Optional: queue for requests sent with X-GoModel-Deferred
+	Idempotency                     *idempotency.Service                   // Optional: replays duplicates of requests sent with Idempotency-Key; nil ignores the header
+	UpstreamResponseHeaders         *core.UpstreamHeaderPolicy             // Optional: provider response headers passed through on the model endpoints; nil passes none
+	Chaos                           *chaos.Injector                        // Optional: fault injection for resilience testing; nil keeps it uninstalled
+	Provenance                      *provenance.Signer                     // Optional: provenance headers and signed embedding on model responses; nil keeps it uninstalled
+	SelfProtection                  *selfprotect.Guard                     // Optional: rejects or flags model requests naming the admin API or internal hosts; nil keeps it uninstalled
+	SecretRedaction                 *secretscan.Scanner                    // Optional: masks or blocks secrets pasted into chat and Responses prompts; nil keeps it uninstalled
+	ResponseSanitization            *sanitize.Sanitizer                    // Optional: hides the serving provider from clients; nil keeps it uninstalled
+	ResponseTransforms              *responsetransform.Service             // Optional: rewrites chat and Responses API output text; nil keeps it uninstalled
+	Maintenance                     *maintenance.Mode                      // Optional: maintenance mode switch; nil never rejects requests
+	Inspector                       *inspect.Inspector                     // Optional: live request inspector fed with redacted previews while an inspection runs
+	Deprecations                    *deprecations.Registry                 // Optional: deprecation headers, warnings and post-sunset rejection; nil keeps it uninstalled
+	EmbeddingCache                  *embeddingcache.Cache                  // Optional: per-input cache for /v1/embeddings; nil sends every input upstream
+	WebSocket                       *WebSocketConfig                       // Optional: enables GET /v1/chat/completions/ws; nil leaves it unregistered
 }
 
 // New creates a new HTTP server
@@ -99,6 +156,8 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		auditLogger = cfg.AuditLogger
 		usageLogger = cfg.UsageLogger
 		pricingResolver = cfg.PricingResolver
+		usageLogger = scoreboard.WrapUsageLogger(usageLogger, cfg.Scoreboard)
+		usageLogger = promptcache.WrapUsageLogger(usageLogger, cfg.PromptCache)
 	}
 
 	var modelResolver RequestModelResolver
@@ -118,9 +177,31 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	if cfg != nil {
 		handler.batchRequestPreparer = cfg.BatchRequestPreparer
 		handler.exposedModelLister = cfg.ExposedModelLister
+		handler.modelGroupLister = cfg.ModelGroupLister
 		handler.keepOnlyAliasesAtModelsEndpoint = cfg.KeepOnlyAliasesAtModelsEndpoint
 		handler.responseCache = cfg.ResponseCacheMiddleware
 		handler.guardrailsHash = cfg.GuardrailsHash
+		handler.comparisonLimits = cfg.ComparisonLimits
+		handler.contextOverflow = cfg.ContextOverflow
+		handler.promptCompression = cfg.PromptCompression
+		handler.firstToken = cfg.FirstToken
+		handler.moderation = cfg.Moderation
+		handler.serverTools = cfg.ServerTools
+		if cfg.PromptCache != nil {
+			handler.promptCache = cfg.PromptCache
+		}
+		if cfg.MaxTokensDefaults != nil {
+			handler.maxTokensDefaults = cfg.MaxTokensDefaults
+		}
+		handler.inlineImageLimits = cfg.InlineImageLimits
+		handler.promptTemplates = cfg.PromptTemplates
+		handler.recordRawUser = cfg.RecordRawUser
+		handler.streamBackpressure = cfg.StreamBackpressure
+		handler.embeddingCache = cfg.EmbeddingCache
+		handler.deferred = cfg.Deferred
+		handler.idempotency = cfg.Idempotency
+		handler.maintenance = cfg.Maintenance
+		handler.usageReader = cfg.UsageReader
 	}
 	if cfg != nil && cfg.EnabledPassthroughProviders != nil {
 		handler.setEnabledPassthroughProviders(cfg.EnabledPassthroughProviders)
@@ -148,7 +229,7 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		// Prevent metrics endpoint from shadowing API routes (security: auth bypass)
 		if metricsPath == "/v1" || strings.HasPrefix(metricsPath, "/v1/") ||
 			metricsPath == "/p" || strings.HasPrefix(metricsPath, "/p/") {
-			slog.Warn("metrics endpoint conflicts with API routes, using /metrics instead",
+			serverLogger.Warn("metrics endpoint conflicts with API routes, using /metrics instead",
 				"configured", cfg.MetricsEndpoint,
 				"normalized", metricsPath)
 			metricsPath = "/metrics"
@@ -160,6 +241,11 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	if cfg != nil && cfg.AdminUIEnabled && cfg.DashboardHandler != nil {
 		authSkipPaths = append(authSkipPaths, "/admin/dashboard", "/admin/dashboard/*", "/admin/static/*")
 	}
+	// The playground page and assets skip auth; its API calls carry the key
+	// the user pastes.
+	if cfg != nil && cfg.PlaygroundHandler != nil {
+		authSkipPaths = append(authSkipPaths, "/playground", "/playground/static/*")
+	}
 	// When no bootstrap master key is configured, keep admin APIs reachable so
 	// the dashboard can recover managed-key access instead of locking itself out.
 	if cfg != nil && cfg.MasterKey == "" && cfg.AdminEndpointsEnabled && cfg.AdminHandler != nil {
@@ -173,6 +259,11 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	}
 
 	// Global middleware stack (order matters)
+	// The access log wraps everything else so it sees every request and the
+	// bytes that reach the client.
+	if cfg != nil && cfg.AccessLog != nil {
+		e.Use(AccessLog(cfg.AccessLog))
+	}
 	// Request logger with optional filtering for model-only interactions
 	if cfg != nil && cfg.LogOnlyModelInteractions {
 		e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
@@ -192,7 +283,7 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 			LogContentLength: true,
 			LogResponseSize:  true,
 			LogValuesFunc: func(c *echo.Context, v middleware.RequestLoggerValues) error {
-				slog.Info("REQUEST",
+				attrs := []any{
 					"method", v.Method,
 					"uri", v.URI,
 					"status", v.Status,
@@ -202,8 +293,9 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 					"bytes_out", v.ResponseSize,
 					"user_agent", v.UserAgent,
 					"remote_ip", v.RemoteIP,
-					"request_id", v.RequestID,
-				)
+				}
+				attrs = append(attrs, requestLogAttrs(c.Request(), "")...)
+				serverLogger.Info("REQUEST", attrs...)
 				return nil
 			},
 		}))
@@ -212,6 +304,25 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	}
 	e.Use(middleware.Recover())
 
+	// Request options are parsed once, before every middleware that reads
+	// them.
+	e.Use(RequestOptions(cfg != nil && cfg.StrictRequestOptions))
+
+	// Stream format negotiation re-encodes event streams last, so every
+	// rewriter below it works on SSE.
+	e.Use(StreamFormat())
+
+	// Strict OpenAI compatibility wraps everything below so no extension added
+	// by a later middleware or handler escapes it. Requests can turn it on even
+	// when it is off by default, so it is always installed.
+	e.Use(StrictCompat(cfg != nil && cfg.StrictOpenAICompat))
+
+	// Response sanitization sits outside audit logging so audit entries keep
+	// the provider's original headers and response IDs.
+	if cfg != nil && cfg.ResponseSanitization != nil {
+		e.Use(ResponseSanitization(cfg.ResponseSanitization))
+	}
+
 	// Body size limit (default: 10MB)
 	bodySizeLimit := "10M"
 	if cfg != nil && cfg.BodySizeLimit != "" {
@@ -220,10 +331,16 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	e.Use(middleware.BodyLimit(parseBodySizeLimitBytes(bodySizeLimit)))
 
 	// Request ID middleware (always active — ensures every request has a unique ID
-	// for usage tracking, audit logging, and response correlation)
+	// for usage tracking, audit logging, and response correlation). Model
+	// requests also get a cancelable context here, so a stream that ends early
+	// can abort its upstream call.
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
-			req, id := ensureRequestID(c.Request())
+			req, id, cancel := ensureRequestContext(c.Request(), core.IsModelInteractionPath(c.Request().URL.Path))
+			if cancel != nil {
+				defer cancel()
+				c.Set(streamAbortKey, cancel)
+			}
 			c.SetRequest(req)
 			c.Response().Header().Set("X-Request-ID", id)
 			return next(c)
@@ -231,8 +348,21 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 	})
 	e.Use(modelInteractionWriteDeadlineMiddleware())
 
+	// Secret redaction rewrites the request body before ingress capture, so
+	// nothing downstream, the audit log included, holds the masked secrets.
+	if cfg != nil && cfg.SecretRedaction != nil {
+		e.Use(SecretRedaction(cfg.SecretRedaction, parseBodySizeLimitBytes(bodySizeLimit)))
+	}
+
 	// Ingress capture (before auth/audit/model validation so they can consume shared raw request state)
-	e.Use(RequestSnapshotCapture())
+	var bodyCapture auditlog.BodyCaptureConfig
+	if cfg != nil && cfg.AuditLogger != nil {
+		bodyCapture = cfg.AuditLogger.Config().BodyCapture
+	}
+	e.Use(RequestSnapshotCaptureWithBodyCapture(bodyCapture))
+	if cfg != nil && cfg.RequestBodyStreaming.Threshold > 0 {
+		e.Use(LargeRequestBodies(cfg.RequestBodyStreaming))
+	}
 
 	if cfg != nil && len(cfg.PassthroughSemanticEnrichers) > 0 {
 		e.Use(PassthroughSemanticEnrichment(provider, cfg.PassthroughSemanticEnrichers, passthroughV1PrefixNormalizationEnabled(cfg)))
@@ -246,16 +376,75 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		e.Use(auditlog.Middleware(cfg.AuditLogger))
 	}
 
+	// Maintenance mode rejects model requests after audit logging so the
+	// rejections are recorded, and before auth so it never reaches providers.
+	if cfg != nil && cfg.Maintenance != nil {
+		e.Use(Maintenance(cfg.Maintenance))
+	}
+
+	// Scoreboard timing wraps the handler chain so workflow resolution and
+	// usage reported during the request are visible once it completes.
+	if cfg != nil && cfg.Scoreboard != nil {
+		e.Use(scoreboard.Middleware(cfg.Scoreboard))
+	}
+
+	// The live request inspector wraps the handler chain like the
+	// scoreboard, so the resolved workflow is visible once the request
+	// completes. It costs one atomic load per request while no inspection
+	// runs.
+	if cfg != nil && cfg.Inspector != nil {
+		e.Use(inspect.Middleware(cfg.Inspector))
+	}
+
 	// Authentication (skips public paths)
 	if cfg != nil && (cfg.MasterKey != "" || cfg.Authenticator != nil) {
 		e.Use(AuthMiddlewareWithAuthenticator(cfg.MasterKey, cfg.Authenticator, authSkipPaths))
 	}
 
+	// Self-protection screens model requests after auth and before any
+	// workflow resolution or provider work. It is not installed unless
+	// enabled in config.
+	if cfg != nil && cfg.SelfProtection != nil {
+		e.Use(SelfProtection(cfg.SelfProtection, parseBodySizeLimitBytes(bodySizeLimit)))
+	}
+	// Requests holding a secret whose detector blocks are rejected here,
+	// after auth, so the audit entry records the rejection.
+	if cfg != nil && cfg.SecretRedaction != nil {
+		e.Use(SecretRedactionEnforcement())
+	}
+
 	// Workflow resolution resolves the request-scoped workflow after auth so
 	// managed auth key user-path overrides are visible to policy resolution while
 	// still keeping workflow resolution failures loggable through the audit middleware.
 	e.Use(WorkflowResolutionWithResolverAndPolicy(provider, modelResolver, workflowPolicyResolver))
 
+	// Fault injection needs the resolved workflow to match rules by model and
+	// provider. It is not installed at all unless enabled in config.
+	if cfg != nil && cfg.Chaos != nil {
+		e.Use(ChaosInjection(cfg.Chaos))
+	}
+
+	// Provenance runs after workflow resolution so it can name the resolved
+	// provider and model, and outside the handlers so the response cache
+	// stores responses without it.
+	if cfg != nil && cfg.Provenance != nil {
+		e.Use(ProvenanceHeaders(cfg.Provenance))
+	}
+
+	// Response transforms run inside provenance so signatures cover the
+	// rewritten text, and outside the handlers so the response cache stores
+	// responses as the provider returned them.
+	if cfg != nil && cfg.ResponseTransforms != nil {
+		e.Use(ResponseTransforms(cfg.ResponseTransforms))
+	}
+
+	// Deprecation warnings run inside provenance so signatures cover the
+	// warning, and outside the handlers so the response cache stores
+	// responses without it.
+	if cfg != nil && cfg.Deprecations != nil {
+		e.Use(Deprecations(cfg.Deprecations))
+	}
+
 	// Public routes
 	e.GET("/health", handler.Health)
 	if cfg != nil && cfg.SwaggerEnabled {
@@ -287,21 +476,41 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		e.HEAD("/p/:provider/*", handler.ProviderPassthrough)
 		e.OPTIONS("/p/:provider/*", handler.ProviderPassthrough)
 	}
+	deferredExecution := DeferredExecution(handler.deferred)
+	idempotent := Idempotency(handler.idempotency)
+	var upstreamHeaders *core.UpstreamHeaderPolicy
+	if cfg != nil {
+		upstreamHeaders = cfg.UpstreamResponseHeaders
+	}
+	passUpstreamHeaders := UpstreamResponseHeaders(upstreamHeaders)
 	e.GET("/v1/models", handler.ListModels)
-	e.POST("/v1/chat/completions", handler.ChatCompletion)
+	e.POST("/v1/chat/completions", handler.ChatCompletion, idempotent, passUpstreamHeaders, deferredExecution)
+	if cfg != nil && cfg.WebSocket != nil {
+		e.GET("/v1/chat/completions/ws", ChatCompletionWebSocket(e, *cfg.WebSocket, parseBodySizeLimitBytes(bodySizeLimit)))
+	}
+	e.POST("/v1/chat/completions/compare", handler.ChatCompletionCompare)
 	e.POST("/v1/responses/input_tokens", handler.ResponseInputTokens)
 	e.POST("/v1/responses/compact", handler.CompactResponse)
 	e.GET("/v1/responses/:id/input_items", handler.ListResponseInputItems)
 	e.POST("/v1/responses/:id/cancel", handler.CancelResponse)
 	e.GET("/v1/responses/:id", handler.GetResponse)
 	e.DELETE("/v1/responses/:id", handler.DeleteResponse)
-	e.POST("/v1/responses", handler.Responses)
-	e.POST("/v1/embeddings", handler.Embeddings)
+	e.POST("/v1/responses", handler.Responses, idempotent, passUpstreamHeaders, deferredExecution)
+	e.POST("/v1/embeddings", handler.Embeddings, idempotent, passUpstreamHeaders, deferredExecution)
+	e.GET("/v1/deferred/:id", handler.GetDeferred)
+	e.GET("/v1/usage", handler.KeyUsage)
 	e.POST("/v1/files", handler.CreateFile)
 	e.GET("/v1/files", handler.ListFiles)
 	e.GET("/v1/files/:id", handler.GetFile)
 	e.DELETE("/v1/files/:id", handler.DeleteFile)
 	e.GET("/v1/files/:id/content", handler.GetFileContent)
+	if cfg != nil && cfg.EnableAssistantsPassthrough {
+		for _, path := range []string{"/v1/assistants", "/v1/assistants/*", "/v1/threads", "/v1/threads/*"} {
+			e.GET(path, handler.AssistantsPassthrough)
+			e.POST(path, handler.AssistantsPassthrough)
+			e.DELETE(path, handler.AssistantsPassthrough)
+		}
+	}
 	e.POST("/v1/batches", handler.Batches)
 	e.GET("/v1/batches", handler.ListBatches)
 	e.GET("/v1/batches/:id", handler.GetBatch)
@@ -310,18 +519,58 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 
 	// Admin API routes (behind ADMIN_ENDPOINTS_ENABLED flag)
 	if cfg != nil && cfg.AdminEndpointsEnabled && cfg.AdminHandler != nil {
-		adminAPI := e.Group("/admin/api/v1")
+		adminAPI := e.Group("/admin/api/v1", admin.RequireRole)
 		adminAPI.GET("/dashboard/config", cfg.AdminHandler.DashboardConfig)
+		adminAPI.GET("/config/effective", cfg.AdminHandler.EffectiveConfig)
+		adminAPI.GET("/cache", cfg.AdminHandler.ModelCacheFiles)
+		adminAPI.DELETE("/cache", cfg.AdminHandler.PurgeModelCache)
 		adminAPI.GET("/cache/overview", cfg.AdminHandler.CacheOverview)
 		adminAPI.GET("/usage/summary", cfg.AdminHandler.UsageSummary)
 		adminAPI.GET("/usage/daily", cfg.AdminHandler.DailyUsage)
 		adminAPI.GET("/usage/models", cfg.AdminHandler.UsageByModel)
 		adminAPI.GET("/usage/user-paths", cfg.AdminHandler.UsageByUserPath)
+		adminAPI.GET("/usage/groups", cfg.AdminHandler.UsageGroups)
 		adminAPI.GET("/usage/log", cfg.AdminHandler.UsageLog)
+		adminAPI.GET("/usage/prompt-cache", cfg.AdminHandler.PromptCacheUsage)
+		adminAPI.GET("/usage/content-stats", cfg.AdminHandler.UsageContentStats)
+		adminAPI.POST("/usage/recompute-costs", cfg.AdminHandler.RecomputeUsageCosts)
+		adminAPI.POST("/usage/reattribute", cfg.AdminHandler.ReattributeUsage)
+		adminAPI.GET("/jobs/:id", cfg.AdminHandler.UsageJob)
 		adminAPI.GET("/audit/log", cfg.AdminHandler.AuditLog)
 		adminAPI.GET("/audit/conversation", cfg.AdminHandler.AuditConversation)
+		adminAPI.GET("/audit/by-response-id/:id", cfg.AdminHandler.AuditLogByResponseID)
+		adminAPI.GET("/requests/:request_id", cfg.AdminHandler.RequestTrace)
+		adminAPI.POST("/audit/re-encrypt", cfg.AdminHandler.ReEncryptAuditLogs)
+		adminAPI.GET("/audit/:id", cfg.AdminHandler.AuditLogEntry)
+		adminAPI.POST("/audit/:id/redact", cfg.AdminHandler.RedactAuditLog)
+		adminAPI.DELETE("/audit/:id", cfg.AdminHandler.DeleteAuditLog)
+		adminAPI.GET("/stream-samples", cfg.AdminHandler.StreamSamples)
+		adminAPI.GET("/stream-samples/:id", cfg.AdminHandler.StreamSample)
+		adminAPI.DELETE("/stream-samples/:id", cfg.AdminHandler.DeleteStreamSample)
+		adminAPI.GET("/errors/summary", cfg.AdminHandler.ErrorSummary)
+		adminAPI.GET("/scoreboard", cfg.AdminHandler.Scoreboard)
+		adminAPI.GET("/experiments", cfg.AdminHandler.Experiments)
+		adminAPI.GET("/model-groups", cfg.AdminHandler.ModelGroups)
+		adminAPI.GET("/deferred", cfg.AdminHandler.Deferred)
+		adminAPI.GET("/chaos/rules", cfg.AdminHandler.ChaosRules)
+		adminAPI.PUT("/chaos/rules", cfg.AdminHandler.UpdateChaosRules)
+		adminAPI.POST("/provenance/verify", cfg.AdminHandler.VerifyProvenance)
+		adminAPI.GET("/response-ids/:id", cfg.AdminHandler.ResponseIDMapping)
+		adminAPI.GET("/anomalies", cfg.AdminHandler.Anomalies)
+		adminAPI.POST("/anomalies/:id/acknowledge", cfg.AdminHandler.AcknowledgeAnomaly)
+		adminAPI.GET("/maintenance", cfg.AdminHandler.Maintenance)
+		adminAPI.PUT("/maintenance", cfg.AdminHandler.UpdateMaintenance)
+		adminAPI.GET("/inspect", cfg.AdminHandler.Inspection)
+		adminAPI.POST("/inspect/start", cfg.AdminHandler.StartInspection)
+		adminAPI.POST("/inspect/stop", cfg.AdminHandler.StopInspection)
+		adminAPI.GET("/inspect/samples", cfg.AdminHandler.InspectionSamples)
+		adminAPI.GET("/deprecations", cfg.AdminHandler.Deprecations)
 		adminAPI.GET("/providers/status", cfg.AdminHandler.ProviderStatus)
+		adminAPI.GET("/providers/:name/quota", cfg.AdminHandler.ProviderQuota)
+		adminAPI.POST("/providers/:name/probe", cfg.AdminHandler.ProbeProvider)
+		adminAPI.GET("/diagnostics", cfg.AdminHandler.Diagnostics)
 		adminAPI.POST("/runtime/refresh", cfg.AdminHandler.RefreshRuntime)
+		adminAPI.PUT("/logging/level", cfg.AdminHandler.SetLogLevel)
 		adminAPI.GET("/models", cfg.AdminHandler.ListModels)
 		adminAPI.GET("/models/categories", cfg.AdminHandler.ListCategories)
 		adminAPI.GET("/model-overrides", cfg.AdminHandler.ListModelOverrides)
@@ -333,15 +582,24 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		adminAPI.GET("/aliases", cfg.AdminHandler.ListAliases)
 		adminAPI.PUT("/aliases/:name", cfg.AdminHandler.UpsertAlias)
 		adminAPI.DELETE("/aliases/:name", cfg.AdminHandler.DeleteAlias)
+		adminAPI.GET("/templates", cfg.AdminHandler.ListTemplates)
+		adminAPI.GET("/templates/:name", cfg.AdminHandler.ListTemplateVersions)
+		adminAPI.POST("/templates/:name", cfg.AdminHandler.CreateTemplateVersion)
+		adminAPI.DELETE("/templates/:name", cfg.AdminHandler.DeleteTemplate)
+		adminAPI.GET("/templates/:name/versions/:version", cfg.AdminHandler.GetTemplateVersion)
+		adminAPI.DELETE("/templates/:name/versions/:version", cfg.AdminHandler.DeleteTemplateVersion)
 		adminAPI.GET("/guardrails/types", cfg.AdminHandler.ListGuardrailTypes)
 		adminAPI.GET("/guardrails", cfg.AdminHandler.ListGuardrails)
 		adminAPI.PUT("/guardrails/:name", cfg.AdminHandler.UpsertGuardrail)
 		adminAPI.DELETE("/guardrails/:name", cfg.AdminHandler.DeleteGuardrail)
+		adminAPI.GET("/guardrails/:name/canary", cfg.AdminHandler.GuardrailCanary)
 		adminAPI.GET("/workflows", cfg.AdminHandler.ListWorkflows)
 		adminAPI.GET("/workflows/guardrails", cfg.AdminHandler.ListWorkflowGuardrails)
 		adminAPI.GET("/workflows/:id", cfg.AdminHandler.GetWorkflow)
 		adminAPI.POST("/workflows", cfg.AdminHandler.CreateWorkflow)
 		adminAPI.POST("/workflows/:id/deactivate", cfg.AdminHandler.DeactivateWorkflow)
+		adminAPI.GET("/state/export", cfg.AdminHandler.ExportState)
+		adminAPI.POST("/state/import", cfg.AdminHandler.ImportState)
 	}
 
 	// Admin dashboard UI routes (behind ADMIN_UI_ENABLED flag)
@@ -351,6 +609,12 @@ func New(provider core.RoutableProvider, cfg *Config) *Server {
 		e.GET("/admin/static/*", cfg.DashboardHandler.Static)
 	}
 
+	// Developer playground UI routes (behind PLAYGROUND_ENABLED flag)
+	if cfg != nil && cfg.PlaygroundHandler != nil {
+		e.GET("/playground", cfg.PlaygroundHandler.Index)
+		e.GET("/playground/static/*", cfg.PlaygroundHandler.Static)
+	}
+
 	var rcm *responsecache.ResponseCacheMiddleware
 	if cfg != nil {
 		rcm = cfg.ResponseCacheMiddleware
@@ -400,7 +664,7 @@ func (s *Server) Shutdown(_ context.Context) error {
 			if firstErr == nil {
 				firstErr = err
 			} else {
-				slog.Warn("response store close failed during shutdown", "error", err)
+				serverLogger.Warn("response store close failed during shutdown", "error", err)
 			}
 		}
 	}
@@ -442,7 +706,7 @@ func modelInteractionWriteDeadlineMiddleware() echo.MiddlewareFunc {
 				return next(c)
 			}
 			if err := http.NewResponseController(c.Response()).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
-				slog.Warn("failed to clear write deadline for model interaction",
+				serverLogger.Warn("failed to clear write deadline for model interaction",
 					"path", c.Request().URL.Path,
 					"request_id", requestIDFromContextOrHeader(c.Request()),
 					"error", err,
@@ -461,7 +725,7 @@ func parseBodySizeLimitBytes(limit string) int64 {
 
 	value, err := config.ParseBodySizeLimitBytes(limit)
 	if err != nil {
-		slog.Warn("invalid body size limit, falling back to default", "configured", limit)
+		serverLogger.Warn("invalid body size limit, falling back to default", "configured", limit)
 		return config.DefaultBodySizeLimit
 	}
 
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +15,7 @@ import (
 	"gomodel/internal/admin"
 	"gomodel/internal/admin/dashboard"
 	"gomodel/internal/core"
+	"gomodel/internal/playground"
 
 	_ "gomodel/cmd/gomodel/docs"
 
@@ -432,7 +435,7 @@ func TestAdminEndpoints_Enabled(t *testing.T) {
 		AdminHandler:          adminHandler,
 	})
 
-	for _, path := range []string{"/admin/api/v1/models", "/admin/api/v1/providers/status", "/admin/api/v1/audit/log", "/admin/api/v1/audit/conversation?log_id=abc"} {
+	for _, path := range []string{"/admin/api/v1/models", "/admin/api/v1/providers/status", "/admin/api/v1/audit/log", "/admin/api/v1/audit/conversation?log_id=abc", "/admin/api/v1/errors/summary", "/admin/api/v1/scoreboard"} {
 		req := httptest.NewRequest(http.MethodGet, path, nil)
 		rec := httptest.NewRecorder()
 		srv.ServeHTTP(rec, req)
@@ -461,6 +464,8 @@ func TestAdminWorkflowEndpoints_AreRegistered(t *testing.T) {
 		{method: http.MethodGet, path: "/admin/api/v1/auth-keys"},
 		{method: http.MethodPost, path: "/admin/api/v1/auth-keys"},
 		{method: http.MethodPost, path: "/admin/api/v1/auth-keys/test-key/deactivate"},
+		{method: http.MethodPost, path: "/admin/api/v1/audit/test-log/redact"},
+		{method: http.MethodDelete, path: "/admin/api/v1/audit/test-log"},
 		{method: http.MethodGet, path: "/admin/api/v1/workflows"},
 		{method: http.MethodGet, path: "/admin/api/v1/workflows/guardrails"},
 		{method: http.MethodPost, path: "/admin/api/v1/workflows"},
@@ -757,6 +762,75 @@ func TestSwaggerDocJson_ReturnsExpectedContent(t *testing.T) {
 	}
 }
 
+func TestPlaygroundEndpoint_DisabledByDefault(t *testing.T) {
+	for name, cfg := range map[string]*Config{"nil config": nil, "empty config": {}} {
+		srv := New(&mockProvider{}, cfg)
+		req := httptest.NewRequest(http.MethodGet, "/playground", nil)
+		rec := httptest.NewRecorder()
+
+		srv.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestPlaygroundEndpoint_ServedWithoutAuthAndKeepsKeysOutOfLogs(t *testing.T) {
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(original)
+	})
+
+	playgroundHandler, err := playground.New()
+	if err != nil {
+		t.Fatalf("playground.New: %v", err)
+	}
+	const apiKey = "sk-playground-secret-123"
+	srv := New(&mockProvider{}, &Config{
+		MasterKey:         apiKey,
+		PlaygroundHandler: playgroundHandler,
+	})
+
+	for _, path := range []string{"/playground", "/playground/static/playground.js"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200 without auth, got %d", path, rec.Code)
+		}
+		if rec.Header().Get("Content-Security-Policy") == "" {
+			t.Errorf("%s: missing Content-Security-Policy", path)
+		}
+	}
+
+	// The page's API calls are ordinary authenticated data-plane requests.
+	for _, key := range []string{apiKey, "sk-wrong-key-456"} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if key == apiKey && rec.Code != http.StatusOK {
+			t.Fatalf("/v1/models with key: expected 200, got %d", rec.Code)
+		}
+		if key != apiKey && rec.Code != http.StatusUnauthorized {
+			t.Fatalf("/v1/models with wrong key: expected 401, got %d", rec.Code)
+		}
+	}
+
+	logs := buf.String()
+	if logs == "" {
+		t.Fatal("expected request logs")
+	}
+	for _, key := range []string{apiKey, "sk-wrong-key-456"} {
+		if strings.Contains(logs, key) {
+			t.Errorf("server logs contain API key %q:\n%s", key, logs)
+		}
+	}
+}
+
 func TestPprofEndpoint_Enabled(t *testing.T) {
 	mock := &mockProvider{}
 	srv := New(mock, &Config{PprofEnabled: true})
@@ -873,6 +947,37 @@ func TestProviderPassthroughRoute_EnabledByDefault(t *testing.T) {
 	}
 }
 
+func TestAssistantsPassthroughRoutes_RegisteredOnlyWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		mock := &mockProvider{
+			providerTypes: map[string]string{"openai/gpt-4o": "openai"},
+			providerNames: map[string]string{"openai/gpt-4o": "openai"},
+			passthroughResponse: &core.PassthroughResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string][]string{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"id":"thread_1","object":"thread"}`)),
+			},
+		}
+		srv := New(mock, &Config{EnableAssistantsPassthrough: enabled})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/threads", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		want := http.StatusNotFound
+		if enabled {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Fatalf("enabled=%v: expected status %d, got %d: %s", enabled, want, rec.Code, rec.Body.String())
+		}
+		if invoked := mock.lastPassthroughReq != nil; invoked != enabled {
+			t.Fatalf("enabled=%v: passthrough invoked = %v", enabled, invoked)
+		}
+	}
+}
+
 func TestProviderPassthroughRoute_DisabledRequiresAuthBefore404(t *testing.T) {
 	mock := &mockProvider{}
 	srv := New(mock, &Config{
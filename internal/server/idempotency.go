@@ -0,0 +1,171 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/idempotency"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header value.
+const maxIdempotencyKeyLength = 255
+
+// Idempotency answers requests that repeat the Idempotency-Key and body of an
+// earlier request from that request's response, without calling the provider
+// again. A key reused with a different body is rejected with 409. Keys are
+// scoped to the Authorization header, so callers never see each other's
+// responses. A nil service ignores the header.
+func Idempotency(service *idempotency.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		var handle echo.HandlerFunc
+		handle = func(c *echo.Context) error {
+			idempotencyKey := strings.TrimSpace(c.Request().Header.Get(core.IdempotencyKeyHeader))
+			if service == nil || idempotencyKey == "" {
+				return next(c)
+			}
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				return handleError(c, core.NewInvalidRequestError("Idempotency-Key must be at most 255 characters", nil))
+			}
+
+			body, err := requestBodyBytes(c)
+			if err != nil {
+				return handleError(c, requestBodyReadError("failed to read request body", err))
+			}
+			req := c.Request()
+			result, err := service.Begin(req.Context(),
+				idempotency.Key(req.Header.Get("Authorization"), idempotencyKey),
+				idempotency.Fingerprint(req.Method, req.URL.Path, body),
+				requestIDFromContextOrHeader(req),
+				gjson.GetBytes(body, "stream").Bool(),
+			)
+			if err != nil {
+				return handleError(c, err)
+			}
+
+			switch result.Decision {
+			case idempotency.DecisionReplay:
+				auditlog.EnrichEntryWithIdempotentReplay(c, result.OriginalRequestID)
+				return replayIdempotentResponse(c.Response(), result.Record)
+			case idempotency.DecisionAttach:
+				auditlog.EnrichEntryWithIdempotentReplay(c, result.OriginalRequestID)
+				aborted, err := followIdempotentStream(c, result.Execution)
+				if aborted {
+					return handle(c)
+				}
+				return err
+			case idempotency.DecisionConflict:
+				return handleError(c, core.NewInvalidRequestErrorWithStatus(http.StatusConflict,
+					"Idempotency-Key was already used with a different request", nil).WithCode("idempotency_key_conflict"))
+			case idempotency.DecisionInProgress:
+				return handleError(c, core.NewInvalidRequestErrorWithStatus(http.StatusConflict,
+					"a streaming request with this Idempotency-Key is still running", nil).WithCode("idempotency_request_in_progress"))
+			}
+
+			exec := result.Execution
+			finished := false
+			defer func() {
+				if !finished {
+					exec.Abort()
+				}
+			}()
+			original := c.Response()
+			c.SetResponse(&idempotentRecorder{ResponseWriter: original, exec: exec})
+			err = next(c)
+			c.SetResponse(original)
+			if err != nil {
+				return err
+			}
+			finished = true
+			exec.Finish(req.Context())
+			return nil
+		}
+		return handle
+	}
+}
+
+// skippedReplayHeaders are response headers that belong to the original
+// request and are not copied onto replays.
+var skippedReplayHeaders = map[string]struct{}{
+	"Content-Length": {},
+	"X-Request-Id":   {},
+}
+
+func copyReplayHeaders(dst, src http.Header) {
+	for name, values := range src {
+		if _, skip := skippedReplayHeaders[http.CanonicalHeaderKey(name)]; skip {
+			continue
+		}
+		dst[name] = append([]string(nil), values...)
+	}
+	dst.Set(core.IdempotentReplayedHeader, "true")
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, record *idempotency.Record) error {
+	copyReplayHeaders(w.Header(), record.Header)
+	w.WriteHeader(record.Status)
+	_, err := w.Write(record.Body)
+	return err
+}
+
+func followIdempotentStream(c *echo.Context, exec *idempotency.Execution) (bool, error) {
+	w := c.Response()
+	return exec.Follow(c.Request().Context(),
+		func(status int, header http.Header) {
+			copyReplayHeaders(w.Header(), header)
+			w.WriteHeader(status)
+		},
+		func(chunk []byte) error {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			_ = http.NewResponseController(w).Flush()
+			return nil
+		},
+	)
+}
+
+// idempotentRecorder writes the response to the client and records it on the
+// execution for duplicates.
+type idempotentRecorder struct {
+	http.ResponseWriter
+	exec        *idempotency.Execution
+	wroteHeader bool
+}
+
+func (r *idempotentRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.exec.WriteHeader(code, r.ResponseWriter.Header())
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *idempotentRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		// c.JSON sets the status on the echo.Response and writes without
+		// calling WriteHeader.
+		status := http.StatusOK
+		if resp, err := echo.UnwrapResponse(r.ResponseWriter); err == nil && resp.Status != 0 {
+			status = resp.Status
+		}
+		r.WriteHeader(status)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	if n > 0 {
+		r.exec.Write(b[:n])
+	}
+	return n, err
+}
+
+func (r *idempotentRecorder) Flush() {
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+func (r *idempotentRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/idempotency"
+)
+
+func newIdempotencyTestServer(handler echo.HandlerFunc) *echo.Echo {
+	service := idempotency.New(config.IdempotencyConfig{
+		Enabled:          true,
+		TTL:              time.Hour,
+		MaxEntries:       10,
+		MaxBodyBytes:     1 << 20,
+		StreamDuplicates: config.IdempotencyStreamAttach,
+	})
+	e := echo.New()
+	e.POST("/v1/chat/completions", handler, Idempotency(service))
+	return e
+}
+
+func serveIdempotent(e *echo.Echo, body, key, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.IdempotencyKeyHeader, key)
+	req.Header.Set("Authorization", authorization)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysResponseWithoutCallingHandler(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotencyTestServer(func(c *echo.Context) error {
+		n := calls.Add(1)
+		c.Response().Header().Set("X-Provider", "mock")
+		return c.JSON(http.StatusOK, map[string]int32{"call": n})
+	})
+
+	first := serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+	second := serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusOK || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q, want %q", second.Code, second.Body.String(), first.Body.String())
+	}
+	if second.Header().Get(core.IdempotentReplayedHeader) != "true" || second.Header().Get("X-Provider") != "mock" {
+		t.Fatalf("replay headers = %v", second.Header())
+	}
+	if first.Header().Get(core.IdempotentReplayedHeader) != "" {
+		t.Fatal("first response marked as replayed")
+	}
+}
+
+func TestIdempotency_KeyReusedWithDifferentBodyConflicts(t *testing.T) {
+	e := newIdempotencyTestServer(func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"ok": "yes"})
+	})
+
+	serveIdempotent(e, `{"model":"a"}`, "key-1", "Bearer a")
+	rec := serveIdempotent(e, `{"model":"b"}`, "key-1", "Bearer a")
+
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "idempotency_key_conflict") {
+		t.Fatalf("response = %d %s, want 409 idempotency_key_conflict", rec.Code, rec.Body.String())
+	}
+}
+
+func TestIdempotency_KeysAreScopedToCaller(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotencyTestServer(func(c *echo.Context) error {
+		calls.Add(1)
+		return c.JSON(http.StatusOK, map[string]string{"ok": "yes"})
+	})
+
+	serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+	rec := serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer b")
+
+	if calls.Load() != 2 || rec.Header().Get(core.IdempotentReplayedHeader) != "" {
+		t.Fatalf("handler called %d times, want a fresh execution for another caller", calls.Load())
+	}
+}
+
+func TestIdempotency_ConcurrentDuplicatesCallHandlerOnce(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := newIdempotencyTestServer(func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		return c.JSON(http.StatusOK, map[string]string{"id": "chatcmpl-1"})
+	})
+
+	const duplicates = 10
+	var wg sync.WaitGroup
+	recs := make([]*httptest.ResponseRecorder, duplicates)
+	for i := range duplicates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recs[i] = serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	replayed := 0
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "chatcmpl-1") {
+			t.Fatalf("response = %d %q, want the first response", rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get(core.IdempotentReplayedHeader) == "true" {
+			replayed++
+		}
+	}
+	if replayed != duplicates-1 {
+		t.Fatalf("replayed = %d, want %d", replayed, duplicates-1)
+	}
+}
+
+func TestIdempotency_HandlerErrorIsNotReplayed(t *testing.T) {
+	var calls atomic.Int32
+	e := newIdempotencyTestServer(func(c *echo.Context) error {
+		if calls.Add(1) == 1 {
+			return handleError(c, core.NewProviderError("mock", http.StatusBadGateway, "upstream failed", nil))
+		}
+		return c.JSON(http.StatusOK, map[string]string{"ok": "yes"})
+	})
+
+	serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+	rec := serveIdempotent(e, `{"model":"m"}`, "key-1", "Bearer a")
+
+	if calls.Load() != 2 || rec.Code != http.StatusOK {
+		t.Fatalf("handler called %d times with final status %d, want a retry after the server error", calls.Load(), rec.Code)
+	}
+}
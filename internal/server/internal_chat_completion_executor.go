@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -79,18 +78,22 @@ func (e *InternalChatCompletionExecutor) ChatCompletion(ctx context.Context, req
 		ctx = context.Background()
 	}
 	ctx = core.WithRequestOrigin(ctx, core.RequestOriginGuardrail)
+	// Fresh recorders keep this call from overwriting the upstream outcome
+	// and guardrail canary decisions of the request that triggered it.
+	ctx = core.WithUpstreamCall(ctx, &core.UpstreamCall{})
+	ctx = core.WithGuardrailCanaries(ctx, &core.GuardrailCanaries{})
 
 	requestID := strings.TrimSpace(core.GetRequestID(ctx))
 	requested := core.NewRequestedModelSelector(req.Model, req.Provider)
 	start := time.Now()
-	entry := e.newAuditEntry(ctx, requestID, requested)
+	entry := newInternalChatAuditEntry(e.logger, ctx, requestID, requested)
 	var workflow *core.Workflow
 	var cacheType string
 	var providerType string
 	var providerName string
 	var failoverModel string
 	defer func() {
-		e.finishAuditEntry(ctx, entry, start, workflow, req, resp, err, cacheType, providerType, providerName, failoverModel)
+		finishInternalChatAuditEntry(e.logger, ctx, entry, start, workflow, req, resp, err, cacheType, providerType, providerName, failoverModel)
 	}()
 
 	resolution, err := resolveRequestModelWithAuthorizer(ctx, e.provider, e.modelResolver, e.modelAuthorizer, requested)
@@ -118,7 +121,7 @@ func (e *InternalChatCompletionExecutor) ChatCompletion(ctx context.Context, req
 	if cacheType == "" {
 		e.orchestrator.LogUsage(ctx, workflow, resp.Model, providerType, providerName, func(pricing *core.ModelPricing) *usage.UsageEntry {
 			return usage.ExtractFromChatResponse(resp, requestID, providerType, "/v1/chat/completions", pricing)
-		})
+		}, usage.ChatResponseContent(resp))
 	}
 	return resp, nil
 }
@@ -134,7 +137,7 @@ func (e *InternalChatCompletionExecutor) executeChatCompletion(
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		slog.Warn("json.Marshal(req) failed; bypassing cache", "err", err)
+		serverLogger.Warn("json.Marshal(req) failed; bypassing cache", "err", err)
 		return e.dispatchChatCompletionNoCache(ctx, workflow, req)
 	}
 
@@ -184,12 +187,15 @@ func (e *InternalChatCompletionExecutor) dispatchChatCompletionNoCache(
 	return resp, providerType, providerName, failoverModel, usedFallback, "", err
 }
 
-func (e *InternalChatCompletionExecutor) newAuditEntry(
+// newInternalChatAuditEntry starts the audit entry for a chat request that the
+// gateway executes on its own behalf rather than through the HTTP middleware.
+func newInternalChatAuditEntry(
+	logger auditlog.LoggerInterface,
 	ctx context.Context,
 	requestID string,
 	requested core.RequestedModelSelector,
 ) *auditlog.LogEntry {
-	if e.logger == nil || !e.logger.Config().Enabled {
+	if logger == nil || !logger.Config().Enabled {
 		return nil
 	}
 
@@ -213,7 +219,8 @@ func (e *InternalChatCompletionExecutor) newAuditEntry(
 	return entry
 }
 
-func (e *InternalChatCompletionExecutor) finishAuditEntry(
+func finishInternalChatAuditEntry(
+	logger auditlog.LoggerInterface,
 	ctx context.Context,
 	entry *auditlog.LogEntry,
 	start time.Time,
@@ -226,7 +233,7 @@ func (e *InternalChatCompletionExecutor) finishAuditEntry(
 	providerName string,
 	failoverModel string,
 ) {
-	if entry == nil || e.logger == nil || !e.logger.Config().Enabled {
+	if entry == nil || logger == nil || !logger.Config().Enabled {
 		return
 	}
 
@@ -234,12 +241,14 @@ func (e *InternalChatCompletionExecutor) finishAuditEntry(
 	auditlog.EnrichLogEntryWithWorkflow(entry, workflow)
 	auditlog.EnrichLogEntryWithFailover(entry, failoverModel)
 	auditlog.EnrichLogEntryWithResolvedRoute(entry, qualifyExecutedModel(workflow, chatResponseModel(resp), providerName), providerType, providerName)
+	auditlog.EnrichLogEntryWithServedModel(entry, chatResponseModel(resp))
+	auditlog.EnrichLogEntryWithResponseID(entry, chatResponseID(resp), "")
 	auditlog.EnrichLogEntryWithRequestContext(entry, ctx)
 	if workflow != nil && !workflow.AuditEnabled() {
 		return
 	}
 
-	cfg := e.logger.Config()
+	cfg := logger.Config()
 	auditlog.CaptureInternalJSONExchange(entry, ctx, http.MethodPost, "/v1/chat/completions", req, resp, err, cfg)
 	if cacheType != "" {
 		entry.CacheType = cacheType
@@ -264,7 +273,7 @@ func (e *InternalChatCompletionExecutor) finishAuditEntry(
 		entry.StatusCode = http.StatusOK
 	}
 
-	e.logger.Write(entry)
+	logger.Write(entry)
 }
 
 func chatResponseModel(resp *core.ChatResponse) string {
@@ -273,3 +282,10 @@ func chatResponseModel(resp *core.ChatResponse) string {
 	}
 	return resp.Model
 }
+
+func chatResponseID(resp *core.ChatResponse) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.ID
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+const (
+	keyUsageDefaultDays = 30
+	keyUsageMaxDays     = 90
+	// keyUsageCacheTTL keeps polling clients from turning every request into
+	// two aggregate queries.
+	keyUsageCacheTTL = 30 * time.Second
+	// keyUsageCacheMaxEntries bounds the cache; expired entries are dropped
+	// once it is reached.
+	keyUsageCacheMaxEntries = 1024
+)
+
+// keyUsageNow is replaced in tests.
+var keyUsageNow = time.Now
+
+// Key usage statuses reported by GET /v1/usage.
+const (
+	keyUsageStatusOK               = "ok"
+	keyUsageStatusTrackingDisabled = "usage_tracking_disabled"
+)
+
+// keyUsageResponse is the body of GET /v1/usage: the usage of the managed auth
+// key that authenticated the request. Summary and Daily are omitted when
+// Status is "usage_tracking_disabled".
+type keyUsageResponse struct {
+	Object    string              `json:"object"`
+	Status    string              `json:"status"`
+	AuthKeyID string              `json:"auth_key_id"`
+	StartDate string              `json:"start_date,omitempty"`
+	EndDate   string              `json:"end_date,omitempty"`
+	Summary   *usage.UsageSummary `json:"summary,omitempty"`
+	Daily     []usage.DailyUsage  `json:"daily,omitempty"`
+}
+
+type keyUsageCacheEntry struct {
+	response  keyUsageResponse
+	expiresAt time.Time
+}
+
+// keyUsageCache holds recent GET /v1/usage responses per auth key and window.
+type keyUsageCache struct {
+	mu      sync.Mutex
+	entries map[string]keyUsageCacheEntry
+}
+
+func newKeyUsageCache() *keyUsageCache {
+	return &keyUsageCache{entries: make(map[string]keyUsageCacheEntry)}
+}
+
+func (c *keyUsageCache) get(key string) (keyUsageResponse, bool) {
+	if c == nil {
+		return keyUsageResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !keyUsageNow().Before(entry.expiresAt) {
+		return keyUsageResponse{}, false
+	}
+	return entry.response, true
+}
+
+func (c *keyUsageCache) put(key string, response keyUsageResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := keyUsageNow()
+	if len(c.entries) >= keyUsageCacheMaxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= keyUsageCacheMaxEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = keyUsageCacheEntry{response: response, expiresAt: now.Add(keyUsageCacheTTL)}
+}
+
+// KeyUsage handles GET /v1/usage
+//
+// @Summary      Get usage of the calling API key
+// @Description  Returns the token and cost usage recorded for the managed API key that authenticates the request. Only that key's usage is ever returned; the key cannot be chosen by query parameter. Responses are cached for 30 seconds.
+// @Tags         usage
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Number of days including today, 1-90 (default 30)"
+// @Success      200  {object}  keyUsageResponse
+// @Failure      400  {object}  core.OpenAIErrorEnvelope
+// @Failure      401  {object}  core.OpenAIErrorEnvelope
+// @Failure      403  {object}  core.OpenAIErrorEnvelope
+// @Failure      500  {object}  core.OpenAIErrorEnvelope
+// @Router       /v1/usage [get]
+func (h *Handler) KeyUsage(c *echo.Context) error {
+	ctx := c.Request().Context()
+	authKeyID := strings.TrimSpace(core.GetAuthKeyID(ctx))
+	if authKeyID == "" {
+		return handleError(c, core.NewForbiddenError("usage is only available to managed API keys").WithCode("managed_key_required"))
+	}
+	if h.usageReader == nil {
+		return c.JSON(http.StatusOK, keyUsageResponse{Object: "usage", Status: keyUsageStatusTrackingDisabled, AuthKeyID: authKeyID})
+	}
+
+	days, err := keyUsageDays(c.QueryParam("days"))
+	if err != nil {
+		return handleError(c, err)
+	}
+	cacheKey := authKeyID + "|" + strconv.Itoa(days)
+	if cached, ok := h.keyUsageCache.get(cacheKey); ok {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	now := keyUsageNow().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// Every query parameter other than days is ignored: the key filter comes
+	// from authentication only.
+	params := usage.UsageQueryParams{
+		StartDate: today.AddDate(0, 0, -(days - 1)),
+		EndDate:   today,
+		Interval:  "daily",
+		TimeZone:  "UTC",
+		CacheMode: usage.CacheModeAll,
+		AuthKeyID: authKeyID,
+	}
+	response, err := readKeyUsage(ctx, h.usageReader, params)
+	if err != nil {
+		return handleError(c, err)
+	}
+	h.keyUsageCache.put(cacheKey, response)
+	return c.JSON(http.StatusOK, response)
+}
+
+func readKeyUsage(ctx context.Context, reader usage.UsageReader, params usage.UsageQueryParams) (keyUsageResponse, error) {
+	summary, err := reader.GetSummary(ctx, params)
+	if err != nil {
+		return keyUsageResponse{}, err
+	}
+	daily, err := reader.GetDailyUsage(ctx, params)
+	if err != nil {
+		return keyUsageResponse{}, err
+	}
+	if daily == nil {
+		daily = []usage.DailyUsage{}
+	}
+	return keyUsageResponse{
+		Object:    "usage",
+		Status:    keyUsageStatusOK,
+		AuthKeyID: params.AuthKeyID,
+		StartDate: params.StartDate.Format("2006-01-02"),
+		EndDate:   params.EndDate.Format("2006-01-02"),
+		Summary:   summary,
+		Daily:     daily,
+	}, nil
+}
+
+func keyUsageDays(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return keyUsageDefaultDays, nil
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 || days > keyUsageMaxDays {
+		return 0, core.NewInvalidRequestError("days must be an integer between 1 and "+strconv.Itoa(keyUsageMaxDays), nil)
+	}
+	return days, nil
+}
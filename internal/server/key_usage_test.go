@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// keyUsageReader records the params of summary and daily usage queries.
+type keyUsageReader struct {
+	usage.UsageReader
+	params []usage.UsageQueryParams
+}
+
+func (r *keyUsageReader) GetSummary(_ context.Context, params usage.UsageQueryParams) (*usage.UsageSummary, error) {
+	r.params = append(r.params, params)
+	return &usage.UsageSummary{TotalRequests: 2, TotalTokens: 30}, nil
+}
+
+func (r *keyUsageReader) GetDailyUsage(_ context.Context, params usage.UsageQueryParams) ([]usage.DailyUsage, error) {
+	r.params = append(r.params, params)
+	return []usage.DailyUsage{{Date: "2026-04-07", Requests: 2, TotalTokens: 30}}, nil
+}
+
+func serveKeyUsage(t *testing.T, h *Handler, target, authKeyID string) (*httptest.ResponseRecorder, keyUsageResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if authKeyID != "" {
+		req = req.WithContext(core.WithAuthKeyID(req.Context(), authKeyID))
+	}
+	rec := httptest.NewRecorder()
+	if err := h.KeyUsage(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("KeyUsage() error = %v", err)
+	}
+	var body keyUsageResponse
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+	}
+	return rec, body
+}
+
+func stubKeyUsageNow(t *testing.T) {
+	t.Helper()
+	original := keyUsageNow
+	keyUsageNow = func() time.Time { return time.Date(2026, 4, 7, 15, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { keyUsageNow = original })
+}
+
+func TestKeyUsage_ForcesAuthenticatedKeyFilter(t *testing.T) {
+	stubKeyUsageNow(t)
+	reader := &keyUsageReader{}
+	h := &Handler{usageReader: reader, keyUsageCache: newKeyUsageCache()}
+
+	rec, body := serveKeyUsage(t, h, "/v1/usage?days=7&auth_key_id=key-b&key_hash=key-b&user_path=/team-b", "key-a")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if len(reader.params) != 2 {
+		t.Fatalf("reader called %d times, want summary and daily", len(reader.params))
+	}
+	for _, params := range reader.params {
+		if params.AuthKeyID != "key-a" || params.UserPath != "" || params.CacheMode != usage.CacheModeAll {
+			t.Fatalf("params = %+v, want only the authenticated key filter", params)
+		}
+	}
+	if got := reader.params[0].StartDate.Format("2006-01-02"); got != "2026-04-01" {
+		t.Errorf("StartDate = %s, want 2026-04-01", got)
+	}
+	if body.Status != keyUsageStatusOK || body.AuthKeyID != "key-a" || body.Summary == nil || body.Summary.TotalRequests != 2 || len(body.Daily) != 1 {
+		t.Fatalf("body = %+v, want key-a usage", body)
+	}
+}
+
+func TestKeyUsage_CachesPerKey(t *testing.T) {
+	stubKeyUsageNow(t)
+	reader := &keyUsageReader{}
+	h := &Handler{usageReader: reader, keyUsageCache: newKeyUsageCache()}
+
+	serveKeyUsage(t, h, "/v1/usage", "key-a")
+	serveKeyUsage(t, h, "/v1/usage", "key-a")
+	if len(reader.params) != 2 {
+		t.Fatalf("reader called %d times, want the second request served from cache", len(reader.params))
+	}
+
+	_, body := serveKeyUsage(t, h, "/v1/usage", "key-b")
+	if len(reader.params) != 4 || body.AuthKeyID != "key-b" {
+		t.Fatalf("reader called %d times for key-b with body %+v, want a fresh key-b query", len(reader.params), body)
+	}
+}
+
+func TestKeyUsage_NilReaderReportsTrackingDisabled(t *testing.T) {
+	h := &Handler{}
+
+	rec, body := serveKeyUsage(t, h, "/v1/usage", "key-a")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if body.Status != keyUsageStatusTrackingDisabled || body.AuthKeyID != "key-a" || body.Summary != nil {
+		t.Fatalf("body = %+v, want usage_tracking_disabled", body)
+	}
+}
+
+func TestKeyUsage_RejectsRequestsWithoutManagedKey(t *testing.T) {
+	reader := &keyUsageReader{}
+	h := &Handler{usageReader: reader}
+
+	rec, _ := serveKeyUsage(t, h, "/v1/usage?auth_key_id=key-a", "")
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+	if len(reader.params) != 0 {
+		t.Fatalf("reader called %d times, want none", len(reader.params))
+	}
+}
+
+func TestKeyUsage_RejectsInvalidDays(t *testing.T) {
+	h := &Handler{usageReader: &keyUsageReader{}}
+
+	for _, days := range []string{"0", "91", "abc"} {
+		rec, _ := serveKeyUsage(t, h, "/v1/usage?days="+days, "key-a")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("days=%s: status = %d, want 400", days, rec.Code)
+		}
+	}
+}
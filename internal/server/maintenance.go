@@ -0,0 +1,32 @@
+package server
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/maintenance"
+)
+
+// Maintenance answers model interaction requests with a 503 and a Retry-After
+// header while maintenance mode is on. Requests it lets through count as in
+// flight until they finish, streams included, so enabling maintenance with
+// drain can wait for them. Admin and public routes are never rejected.
+func Maintenance(mode *maintenance.Mode) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if mode == nil || !core.IsModelInteractionPath(c.Request().URL.Path) {
+				return next(c)
+			}
+			done, rejection, retryAfter := mode.Admit()
+			if rejection != nil {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				return handleError(c, rejection)
+			}
+			defer done()
+			return next(c)
+		}
+	}
+}
@@ -137,6 +137,7 @@ func storeWorkflow(c *echo.Context, workflow *core.Workflow) {
 		return
 	}
 	auditlog.EnrichEntryWithWorkflow(c, workflow)
+	setResolutionHeaders(c, workflow.Resolution)
 	ctx := core.WithWorkflow(c.Request().Context(), workflow)
 	c.SetRequest(c.Request().WithContext(ctx))
 }
@@ -155,6 +156,11 @@ func selectorHintsForValidation(c *echo.Context) (model, provider string, parsed
 	if hints := peekRequestBodySelectorHints(c.Request(), requestSelectorPeekLimit); hints.parsed && (hints.complete || hints.provider != "") {
 		return hints.model, hints.provider, true, nil
 	}
+	if _, large := largeRequestBody(c); large {
+		// Leave large bodies unbuffered; the handler resolves the workflow
+		// from the look-ahead or from the decoded request.
+		return "", "", false, nil
+	}
 
 	bodyBytes, err := requestBodyBytes(c)
 	if err != nil {
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+)
+
+// assistantsProviderType is the only provider type serving the Assistants API.
+const assistantsProviderType = "openai"
+
+// AssistantsPassthrough forwards /v1/assistants/... and /v1/threads/... to
+// the OpenAI provider without translation. Run event streams reuse the
+// passthrough SSE path, so they are audited and their usage is recorded.
+func (s *passthroughService) AssistantsPassthrough(c *echo.Context) error {
+	passthroughProvider, ok := s.provider.(core.RoutablePassthrough)
+	if !ok {
+		return handleError(c, core.NewInvalidRequestError("provider passthrough is not supported by the current provider router", nil))
+	}
+	providerName := workflowProviderNameForType(s.provider, assistantsProviderType)
+	if providerName == "" {
+		return handleError(c, core.NewNotFoundError("the assistants API requires a configured openai provider"))
+	}
+
+	requestPath := c.Request().URL.Path
+	endpoint := strings.TrimPrefix(requestPath, "/v1/")
+	info := &core.PassthroughRouteInfo{
+		Provider:           assistantsProviderType,
+		RawEndpoint:        endpoint,
+		NormalizedEndpoint: endpoint,
+		AuditPath:          requestPath,
+	}
+	if rawQuery := strings.TrimSpace(c.Request().URL.RawQuery); rawQuery != "" {
+		endpoint += "?" + rawQuery
+	}
+
+	ctx, _ := requestContextWithRequestID(c.Request())
+	c.SetRequest(c.Request().WithContext(ctx))
+	resp, err := passthroughProvider.Passthrough(ctx, assistantsProviderType, &core.PassthroughRequest{
+		Method:   c.Request().Method,
+		Endpoint: endpoint,
+		Body:     c.Request().Body,
+		Headers:  buildPassthroughHeaders(ctx, c.Request().Header),
+	})
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	auditlog.EnrichEntry(c, "", assistantsProviderType)
+	return s.proxyPassthroughResponse(c, assistantsProviderType, providerName, endpoint, info, resp)
+}
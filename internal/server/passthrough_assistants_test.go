@@ -0,0 +1,130 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func newAssistantsTestProvider(resp *core.PassthroughResponse) *mockProvider {
+	return &mockProvider{
+		providerTypes:       map[string]string{"openai-main/gpt-4o": "openai"},
+		providerNames:       map[string]string{"openai-main/gpt-4o": "openai-main"},
+		passthroughResponse: resp,
+	}
+}
+
+func TestAssistantsPassthrough_ForwardsVerbatim(t *testing.T) {
+	provider := newAssistantsTestProvider(&core.PassthroughResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"object":"list","data":[]}`)),
+	})
+
+	e := echo.New()
+	handler := NewHandler(provider, nil, nil, nil)
+	e.POST("/v1/threads/*", handler.AssistantsPassthrough)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/threads/thread_1/messages?limit=2&order=asc", strings.NewReader(`{"role":"user","content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer user-secret")
+	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Body.String(); got != `{"object":"list","data":[]}` {
+		t.Fatalf("body = %q", got)
+	}
+	if provider.lastPassthroughProvider != "openai" {
+		t.Fatalf("providerType = %q, want openai", provider.lastPassthroughProvider)
+	}
+	if got := provider.lastPassthroughReq.Endpoint; got != "threads/thread_1/messages?limit=2&order=asc" {
+		t.Fatalf("endpoint = %q", got)
+	}
+	if got := readPassthroughRequestBody(t, provider.lastPassthroughReq.Body); got != `{"role":"user","content":"hi"}` {
+		t.Fatalf("body = %q", got)
+	}
+	if got := provider.lastPassthroughReq.Headers.Get("OpenAI-Beta"); got != "assistants=v2" {
+		t.Fatalf("OpenAI-Beta = %q, want assistants=v2", got)
+	}
+	if got := provider.lastPassthroughReq.Headers.Get("Authorization"); got != "" {
+		t.Fatalf("authorization header should not be forwarded, got %q", got)
+	}
+}
+
+func TestAssistantsPassthrough_StreamsRunEventsWithUsage(t *testing.T) {
+	stream := "event: thread.run.created\n" +
+		"data: {\"id\":\"run_1\",\"object\":\"thread.run\",\"status\":\"queued\",\"model\":\"gpt-4o\",\"usage\":null}\n\n" +
+		"event: thread.run.step.completed\n" +
+		"data: {\"id\":\"step_1\",\"object\":\"thread.run.step\",\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n" +
+		"event: thread.run.completed\n" +
+		"data: {\"id\":\"run_1\",\"object\":\"thread.run\",\"status\":\"completed\",\"model\":\"gpt-4o\",\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3,\"total_tokens\":15}}\n\n" +
+		"event: done\n" +
+		"data: [DONE]\n\n"
+	provider := newAssistantsTestProvider(&core.PassthroughResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string][]string{"Content-Type": {"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader(stream)),
+	})
+	usageLogger := &collectingUsageLogger{config: usage.Config{Enabled: true}}
+
+	e := echo.New()
+	handler := NewHandler(provider, nil, usageLogger, nil)
+	e.POST("/v1/threads/*", handler.AssistantsPassthrough)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/threads/thread_1/runs", strings.NewReader(`{"assistant_id":"asst_1","stream":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Body.String() != stream {
+		t.Fatalf("stream body was not relayed verbatim: %q", rec.Body.String())
+	}
+	if rec.flushes == 0 {
+		t.Fatal("expected run events to be flushed")
+	}
+	if len(usageLogger.entries) != 1 {
+		t.Fatalf("usage entries = %d, want 1", len(usageLogger.entries))
+	}
+	entry := usageLogger.entries[0]
+	if entry.InputTokens != 12 || entry.OutputTokens != 3 || entry.TotalTokens != 15 {
+		t.Fatalf("unexpected usage %+v", entry)
+	}
+	if entry.Endpoint != "/v1/threads/thread_1/runs" || entry.ProviderName != "openai-main" || entry.Model != "gpt-4o" {
+		t.Fatalf("unexpected usage attribution: endpoint=%q provider=%q model=%q", entry.Endpoint, entry.ProviderName, entry.Model)
+	}
+}
+
+func TestAssistantsPassthrough_NotFoundWithoutOpenAIProvider(t *testing.T) {
+	provider := &mockProvider{
+		providerTypes: map[string]string{"anthropic/claude-sonnet-4-5": "anthropic"},
+		providerNames: map[string]string{"anthropic/claude-sonnet-4-5": "anthropic"},
+	}
+
+	e := echo.New()
+	handler := NewHandler(provider, nil, nil, nil)
+	e.GET("/v1/assistants", handler.AssistantsPassthrough)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/assistants", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+	if provider.lastPassthroughReq != nil {
+		t.Fatal("passthrough should not be invoked without an openai provider")
+	}
+}
@@ -16,6 +16,7 @@ type passthroughService struct {
 	pricingResolver              usage.PricingResolver
 	normalizePassthroughV1Prefix bool
 	enabledPassthroughProviders  map[string]struct{}
+	streamBackpressure           StreamBackpressure
 }
 
 func (s *passthroughService) ProviderPassthrough(c *echo.Context) error {
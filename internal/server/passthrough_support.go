@@ -125,6 +125,9 @@ func skipPassthroughRequestHeader(key string) bool {
 	if http.CanonicalHeaderKey(strings.TrimSpace(key)) == http.CanonicalHeaderKey(core.UserPathHeader) {
 		return true
 	}
+	if core.IsRequestLabelHeader(strings.TrimSpace(key)) {
+		return true
+	}
 	return skipPassthroughHeader(key)
 }
 
@@ -245,8 +248,11 @@ func (s *passthroughService) proxyPassthroughResponse(c *echo.Context, providerT
 	copyPassthroughResponseHeaders(c.Response().Header(), http.Header(resp.Headers))
 
 	if isSSEContentType(resp.Headers) {
+		resp.Body = applyChaosStreamFault(c, resp.Body)
 		auditlog.MarkEntryAsStreaming(c, true)
 		auditlog.EnrichEntryWithStream(c, true)
+		auditlog.EnrichEntryWithUpstreamCall(c)
+		recordStreamFormat(c)
 		workflow := core.GetWorkflow(c.Request().Context())
 		auditEnabled := s.logger != nil && s.logger.Config().Enabled && (workflow == nil || workflow.AuditEnabled())
 
@@ -268,11 +274,11 @@ func (s *passthroughService) proxyPassthroughResponse(c *echo.Context, providerT
 		if requestPath := strings.TrimSpace(c.Request().URL.Path); requestPath != "" {
 			usagePath = requestPath
 		}
-		model := ""
+		requestedModel := ""
 		if info != nil {
-			model = strings.TrimSpace(info.Model)
+			requestedModel = strings.TrimSpace(info.Model)
 		}
-		model = resolvedModelFromWorkflow(workflow, model)
+		model := resolvedModelFromWorkflow(workflow, requestedModel)
 
 		observers := make([]streaming.Observer, 0, 2)
 		if auditEnabled && streamEntry != nil {
@@ -283,6 +289,9 @@ func (s *passthroughService) proxyPassthroughResponse(c *echo.Context, providerT
 		if s.usageLogger != nil && s.usageLogger.Config().Enabled && (workflow == nil || workflow.UsageEnabled()) {
 			if observer := usage.NewStreamUsageObserver(s.usageLogger, model, providerType, requestID, usagePath, s.pricingResolver, core.UserPathFromContext(c.Request().Context())); observer != nil {
 				observer.SetProviderName(providerName)
+				observer.SetRequestedModel(requestedModel)
+				observer.SetLabels(core.GetRequestLabels(c.Request().Context()))
+				observer.SetAuthKeyID(core.GetAuthKeyID(c.Request().Context()))
 				observers = append(observers, observer)
 			}
 		}
@@ -294,8 +303,13 @@ func (s *passthroughService) proxyPassthroughResponse(c *echo.Context, providerT
 		}
 
 		c.Response().WriteHeader(resp.StatusCode)
-		if err := flushStream(c.Response(), wrappedStream); err != nil {
+		stats, err := pumpStream(c.Response(), wrappedStream, s.streamBackpressure.forRequest(c))
+		recordStreamBackpressure(c, streamEntry, stats)
+		recordStreamLimit(c, streamEntry, stats)
+		recordStreamTiming(c, streamEntry, stats, providerType, model)
+		if err != nil {
 			recordStreamingError(streamEntry, model, providerType, c.Request().URL.Path, requestID, err)
+			finishFailedStream(c, err)
 			return err
 		}
 		return nil
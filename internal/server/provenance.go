@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+	"gomodel/internal/provenance"
+)
+
+// ProvenanceHeaders marks model responses with provenance headers and, when
+// embedding is enabled, adds a signed gomodel_provenance object to successful
+// non-streaming JSON responses. Streams pass through untouched, and clients
+// that send X-GoModel-Provenance: off get the headers only. It wraps the
+// handlers, so cached responses are stored without provenance and stamped
+// again when served, while audit logging records the body the client
+// received. It is only installed when provenance is enabled.
+func ProvenanceHeaders(signer *provenance.Signer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if signer == nil || !provenanceApplies(req.Method, req.URL.Path) {
+				return next(c)
+			}
+			writer := &provenanceWriter{
+				ResponseWriter: c.Response(),
+				c:              c,
+				signer:         signer,
+				embed:          signer.Embeds() && !provenance.OptedOut(req.Header.Get(provenance.OptOutHeader)),
+			}
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err := next(c)
+			if flushErr := writer.finish(); err == nil {
+				err = flushErr
+			}
+			return err
+		}
+	}
+}
+
+// provenanceApplies reports whether a request produces a model response that
+// carries provenance. Batch and file management responses do not.
+func provenanceApplies(method, path string) bool {
+	if method != http.MethodPost {
+		return false
+	}
+	switch core.DescribeEndpoint(method, path).Operation {
+	case core.OperationChatCompletions,
+		core.OperationChatComparison,
+		core.OperationResponses,
+		core.OperationEmbeddings,
+		core.OperationProviderPassthrough:
+		return true
+	default:
+		return false
+	}
+}
+
+// provenanceWriter sets provenance headers when the response starts and
+// holds back an embeddable body until the handler returns.
+type provenanceWriter struct {
+	http.ResponseWriter
+	c      *echo.Context
+	signer *provenance.Signer
+	embed  bool
+
+	wroteHeader bool
+	held        bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (w *provenanceWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.embed && code == http.StatusOK && embeddableResponse(w.Header()) {
+		w.held = true
+		w.status = code
+		return
+	}
+	w.setHeaders(w.stamp(""))
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *provenanceWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	if w.held {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *provenanceWriter) Flush() {
+	if w.held {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *provenanceWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes a held body with the provenance object embedded. Bodies that
+// are not JSON objects are written unchanged.
+func (w *provenanceWriter) finish() error {
+	if !w.held {
+		return nil
+	}
+	body := w.body.Bytes()
+	p := w.stamp(gjson.GetBytes(body, "model").String())
+	if embedded, ok := w.signer.Embed(body, p); ok {
+		body = embedded
+	}
+	w.setHeaders(p)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+// stamp returns the provenance of the response. The model the provider
+// reported in the body, when known, wins over the resolved model.
+func (w *provenanceWriter) stamp(servedModel string) provenance.Provenance {
+	providerName, model := provenanceTarget(w.c)
+	if servedModel != "" {
+		model = servedModel
+	}
+	return w.signer.Stamp(requestIDFromContextOrHeader(w.c.Request()), providerName, model)
+}
+
+func (w *provenanceWriter) setHeaders(p provenance.Provenance) {
+	header := w.Header()
+	header.Set(provenance.RequestIDHeader, p.RequestID)
+	header.Set(provenance.TimestampHeader, p.Timestamp)
+	if p.Provider != "" {
+		header.Set(provenance.ProviderHeader, p.Provider)
+	}
+	if p.Model != "" {
+		header.Set(provenance.ModelHeader, p.Model)
+	}
+}
+
+func provenanceTarget(c *echo.Context) (providerName, model string) {
+	workflow := core.GetWorkflow(c.Request().Context())
+	if workflow == nil {
+		return "", ""
+	}
+	if passthrough := workflow.Passthrough; passthrough != nil {
+		return passthrough.Provider, passthrough.Model
+	}
+	providerName = workflow.ProviderType
+	if resolution := workflow.Resolution; resolution != nil {
+		if resolution.ProviderName != "" {
+			providerName = resolution.ProviderName
+		}
+		model = resolution.ResolvedSelector.Model
+	}
+	return providerName, model
+}
+
+// embeddableResponse reports whether a response is plain JSON the provenance
+// object can be added to. Encoded bodies are left alone.
+func embeddableResponse(header http.Header) bool {
+	if strings.TrimSpace(header.Get("Content-Encoding")) != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
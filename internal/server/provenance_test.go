@@ -0,0 +1,165 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/provenance"
+)
+
+func newProvenanceTestServer(mock *mockProvider, embed bool) (*Server, *provenance.Signer, *syncAuditLogger) {
+	signer := provenance.New(config.ProvenanceConfig{Enabled: true, Embed: embed, Secret: "test-secret"})
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	return New(mock, &Config{AuditLogger: logger, Provenance: signer}), signer, logger
+}
+
+func provenanceChatMock() *mockProvider {
+	return &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: "gpt-4o-mini-2024-07-18",
+			Choices: []core.Choice{{
+				Message: core.ResponseMessage{Role: "assistant", Content: "hello"},
+			}},
+		},
+		streamData: "data: {\"id\":\"1\"}\n\ndata: [DONE]\n\n",
+	}
+}
+
+func assertProvenanceHeaders(t *testing.T, rec *httptest.ResponseRecorder, wantModel string) {
+	t.Helper()
+	if got := rec.Header().Get(provenance.RequestIDHeader); got == "" || got != rec.Header().Get("X-Request-ID") {
+		t.Fatalf("%s = %q, want the request ID %q", provenance.RequestIDHeader, got, rec.Header().Get("X-Request-ID"))
+	}
+	if got := rec.Header().Get(provenance.ProviderHeader); got != "mock" {
+		t.Fatalf("%s = %q, want mock", provenance.ProviderHeader, got)
+	}
+	if got := rec.Header().Get(provenance.ModelHeader); got != wantModel {
+		t.Fatalf("%s = %q, want %q", provenance.ModelHeader, got, wantModel)
+	}
+	if rec.Header().Get(provenance.TimestampHeader) == "" {
+		t.Fatalf("%s is missing", provenance.TimestampHeader)
+	}
+}
+
+func TestProvenance_HeadersOnly(t *testing.T) {
+	srv, _, _ := newProvenanceTestServer(provenanceChatMock(), false)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	assertProvenanceHeaders(t, rec, "gpt-4o-mini")
+	if strings.Contains(rec.Body.String(), provenance.Field) {
+		t.Fatalf("body = %s, want no embedded provenance", rec.Body.String())
+	}
+}
+
+func TestProvenance_EmbedsSignedObject(t *testing.T) {
+	srv, signer, logger := newProvenanceTestServer(provenanceChatMock(), true)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	assertProvenanceHeaders(t, rec, "gpt-4o-mini-2024-07-18")
+	body := rec.Body.Bytes()
+	p, err := provenance.Extract(body)
+	if err != nil {
+		t.Fatalf("Extract() error = %v; body = %s", err, body)
+	}
+	if p.RequestID != rec.Header().Get("X-Request-ID") || p.Provider != "mock" || p.Model != "gpt-4o-mini-2024-07-18" {
+		t.Fatalf("embedded provenance = %+v", p)
+	}
+	if rec.Header().Get("Content-Length") != "" && rec.Header().Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Fatalf("Content-Length = %s, want %d", rec.Header().Get("Content-Length"), len(body))
+	}
+	if result := signer.Verify(body, nil); !result.Valid {
+		t.Fatalf("Verify() = %+v, want valid", result)
+	}
+
+	tampered := []byte(strings.Replace(string(body), `"hello"`, `"goodbye"`, 1))
+	if result := signer.Verify(tampered, nil); result.Valid {
+		t.Fatal("Verify() accepted tampered content")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 || logger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %+v, want one entry with data", logger.entries)
+	}
+	if logged, ok := logger.entries[0].Data.ResponseBody.(map[string]any); !ok || logged[provenance.Field] == nil {
+		t.Fatalf("audit response body = %#v, want the body the client received", logger.entries[0].Data.ResponseBody)
+	}
+}
+
+func TestProvenance_OptOutSkipsEmbedding(t *testing.T) {
+	srv, _, _ := newProvenanceTestServer(provenanceChatMock(), true)
+
+	req := chaosChatRequest(false)
+	req.Header.Set(provenance.OptOutHeader, "off")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	assertProvenanceHeaders(t, rec, "gpt-4o-mini")
+	if strings.Contains(rec.Body.String(), provenance.Field) {
+		t.Fatalf("body = %s, want no embedded provenance after opting out", rec.Body.String())
+	}
+}
+
+func TestProvenance_StreamsAreNotEmbedded(t *testing.T) {
+	mock := provenanceChatMock()
+	srv, _, _ := newProvenanceTestServer(mock, true)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	assertProvenanceHeaders(t, rec, "gpt-4o-mini")
+	if rec.Body.String() != mock.streamData {
+		t.Fatalf("stream body = %q, want %q", rec.Body.String(), mock.streamData)
+	}
+}
+
+func TestProvenance_SkipsNonModelRoutes(t *testing.T) {
+	srv, _, _ := newProvenanceTestServer(provenanceChatMock(), true)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	if rec.Header().Get(provenance.RequestIDHeader) != "" || strings.Contains(rec.Body.String(), provenance.Field) {
+		t.Fatalf("GET /v1/models got provenance: headers = %v", rec.Header())
+	}
+}
+
+func TestProvenance_ErrorsAreNotEmbedded(t *testing.T) {
+	mock := provenanceChatMock()
+	mock.err = core.NewProviderError("mock", http.StatusBadGateway, "upstream failed", nil)
+	srv, _, _ := newProvenanceTestServer(mock, true)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502; body = %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), provenance.Field) {
+		t.Fatalf("body = %s, want no embedded provenance on errors", rec.Body.String())
+	}
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// RequestBodyStreaming configures how large chat and Responses request bodies
+// are handled.
+type RequestBodyStreaming struct {
+	Threshold     int64 // Bodies above this size, or without a Content-Length, are piped upstream when possible; 0 always buffers
+	BufferedLimit int64 // Max size of a large body that still has to be buffered; 0 leaves only the body size limit
+}
+
+// largeRequestBodyKey marks a request as large and stores the buffered limit
+// that applies to it.
+const largeRequestBodyKey = "gomodel_large_request_body"
+
+// LargeRequestBodies marks chat and Responses requests whose body is above
+// cfg.Threshold or of unknown length. Their handlers pipe the body upstream
+// when nothing would change it, and otherwise buffer it only up to
+// cfg.BufferedLimit. Workflow resolution does not buffer a marked body to
+// find its selectors.
+func LargeRequestBodies(cfg RequestBodyStreaming) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if cfg.Threshold > 0 && req.Body != nil && req.Body != http.NoBody &&
+				(req.ContentLength < 0 || req.ContentLength > cfg.Threshold) {
+				switch core.DescribeEndpoint(req.Method, req.URL.Path).Operation {
+				case core.OperationChatCompletions, core.OperationResponses:
+					c.Set(largeRequestBodyKey, cfg.BufferedLimit)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+func largeRequestBody(c *echo.Context) (bufferedLimit int64, large bool) {
+	if c == nil {
+		return 0, false
+	}
+	bufferedLimit, large = c.Get(largeRequestBodyKey).(int64)
+	return bufferedLimit, large
+}
+
+// readRequestBody reads the whole request body. A large body above its
+// buffered limit fails with a 413.
+func readRequestBody(c *echo.Context, body io.Reader) ([]byte, error) {
+	limit, large := largeRequestBody(c)
+	if !large || limit <= 0 {
+		return io.ReadAll(body)
+	}
+	bodyBytes, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(bodyBytes)) > limit {
+		return nil, core.NewInvalidRequestErrorWithStatus(
+			http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body exceeds the %d byte limit for requests that cannot be streamed to the provider", limit),
+			nil,
+		).WithCode("request_body_too_large")
+	}
+	return bodyBytes, nil
+}
+
+// requestBodyTooLargeError is the 413 of a body read by a middleware that
+// has to see all of it, such as a scanner, above the body size limit.
+func requestBodyTooLargeError(limit int64) error {
+	return core.NewInvalidRequestErrorWithStatus(
+		http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("request body exceeds the %d byte limit", limit),
+		nil,
+	).WithCode("request_body_too_large")
+}
+
+// requestBodyReadError keeps the gateway error of a body that could not be
+// buffered, such as the 413 of readRequestBody, and otherwise reports a 400
+// with message.
+func requestBodyReadError(message string, err error) error {
+	var gatewayErr *core.GatewayError
+	if errors.As(err, &gatewayErr) {
+		return gatewayErr
+	}
+	return core.NewInvalidRequestError(message, err)
+}
+
+// requestBodyDigest counts and hashes a request body as the provider reads it.
+type requestBodyDigest struct {
+	body io.ReadCloser
+
+	mu   sync.Mutex
+	size int64
+	hash hash.Hash
+	done bool
+}
+
+func newRequestBodyDigest(body io.ReadCloser) *requestBodyDigest {
+	return &requestBodyDigest{body: body, hash: sha256.New()}
+}
+
+func (d *requestBodyDigest) Read(p []byte) (int, error) {
+	n, err := d.body.Read(p)
+	d.mu.Lock()
+	d.size += int64(n)
+	d.hash.Write(p[:n])
+	if err == io.EOF {
+		d.done = true
+	}
+	d.mu.Unlock()
+	return n, err
+}
+
+func (d *requestBodyDigest) Close() error {
+	return d.body.Close()
+}
+
+// sum returns the bytes read so far and, once the body was read to the end,
+// its hex-encoded SHA-256 hash.
+func (d *requestBodyDigest) sum() (int64, string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.done {
+		return d.size, ""
+	}
+	return d.size, hex.EncodeToString(d.hash.Sum(nil))
+}
+
+// tryStreamRequestBody pipes a large chat or Responses request body to the
+// provider as received when nothing on the translated path would change it.
+// Only the selectors are read ahead, from the first requestSelectorPeekLimit
+// bytes, so a provider field after the model reaches the provider as sent.
+// It reports false, leaving the body unread, when the request has to be
+// buffered instead.
+func (s *translatedInferenceService) tryStreamRequestBody(c *echo.Context, endpoint string) (bool, error) {
+	if _, large := largeRequestBody(c); !large {
+		return false, nil
+	}
+	if s.promptTemplates != nil || s.inlineImageLimits != (core.InlineImageLimits{}) {
+		return false, nil
+	}
+	passthroughProvider, ok := s.provider.(core.RoutablePassthrough)
+	if !ok {
+		return false, nil
+	}
+
+	hints := peekRequestBodySelectorHints(c.Request(), requestSelectorPeekLimit)
+	if hints.model == "" {
+		return false, nil
+	}
+	workflow, ok := s.inference().StreamableRequestWorkflow(c.Request().Context(), translatedRequestMeta(c), hints.model, hints.provider)
+	if !ok {
+		return false, nil
+	}
+
+	ctx, _ := requestContextWithRequestID(c.Request())
+	attachPreparedWorkflow(c, core.WithWorkflow(ctx, workflow), workflow)
+	ctx = c.Request().Context()
+
+	body := newRequestBodyDigest(c.Request().Body)
+	providerType := strings.TrimSpace(workflow.ProviderType)
+	resp, err := passthroughProvider.Passthrough(ctx, providerType, &core.PassthroughRequest{
+		Method:   c.Request().Method,
+		Endpoint: endpoint,
+		Body:     body,
+		Headers:  buildPassthroughHeaders(ctx, c.Request().Header),
+	})
+	size, digest := body.sum()
+	auditlog.EnrichEntryWithRequestBodyDigest(c, size, digest)
+	if err != nil {
+		return true, handleError(c, err)
+	}
+	if resp != nil && resp.Body != nil && resp.StatusCode < http.StatusBadRequest && !isSSEContentType(resp.Headers) {
+		return true, s.relayStreamedBodyResponse(c, workflow, endpoint, resp)
+	}
+	return true, s.proxyRawRequestResponse(c, workflow, endpoint, hints.model, resp)
+}
+
+// proxyRawRequestResponse relays the provider response to a chat or Responses
+// request sent upstream without translation, like a passthrough response.
+func (s *translatedInferenceService) proxyRawRequestResponse(c *echo.Context, workflow *core.Workflow, endpoint, model string, resp *core.PassthroughResponse) error {
+	providerType := strings.TrimSpace(workflow.ProviderType)
+	info := &core.PassthroughRouteInfo{
+		Provider:    providerType,
+		RawEndpoint: strings.TrimPrefix(endpoint, "/"),
+		AuditPath:   c.Request().URL.Path,
+		Model:       resolvedModelFromWorkflow(workflow, model),
+	}
+	passthrough := passthroughService{
+		provider:           s.provider,
+		logger:             s.logger,
+		usageLogger:        s.usageLogger,
+		pricingResolver:    s.pricingResolver,
+		streamBackpressure: s.streamBackpressure,
+	}
+	return passthrough.proxyPassthroughResponse(c, providerType, providerNameFromWorkflow(workflow), endpoint, info, resp)
+}
+
+// relayStreamedBodyResponse relays a complete JSON response to a request whose
+// body was piped upstream, recording its usage, audit route and, for
+// Responses, the stored response like the translated path does.
+func (s *translatedInferenceService) relayStreamedBodyResponse(c *echo.Context, workflow *core.Workflow, endpoint string, resp *core.PassthroughResponse) error {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	providerType := strings.TrimSpace(workflow.ProviderType)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return handleError(c, core.NewProviderError(providerType, http.StatusBadGateway, "failed to read provider response", err))
+	}
+
+	ctx := c.Request().Context()
+	requestID := requestIDFromContextOrHeader(c.Request())
+	providerName := providerNameFromWorkflow(workflow)
+	usagePath := c.Request().URL.Path
+	var model, responseID string
+	switch endpoint {
+	case "/chat/completions":
+		var decoded core.ChatResponse
+		if json.Unmarshal(body, &decoded) == nil {
+			model, responseID = decoded.Model, decoded.ID
+			s.inference().LogUsage(ctx, workflow, decoded.Model, providerType, providerName, func(pricing *core.ModelPricing) *usage.UsageEntry {
+				return usage.ExtractFromChatResponse(&decoded, requestID, providerType, usagePath, pricing)
+			}, usage.ChatResponseContent(&decoded))
+		}
+	case "/responses":
+		var decoded core.ResponsesResponse
+		if json.Unmarshal(body, &decoded) == nil {
+			model, responseID = decoded.Model, decoded.ID
+			s.inference().LogUsage(ctx, workflow, decoded.Model, providerType, providerName, func(pricing *core.ModelPricing) *usage.UsageEntry {
+				return usage.ExtractFromResponsesResponse(&decoded, requestID, providerType, usagePath, pricing)
+			}, usage.ResponsesContent(&decoded))
+			if err := s.storeResponseSnapshot(ctx, workflow, nil, &decoded, providerType, providerName, requestID); err != nil {
+				s.recordResponseSnapshotStoreFailure(workflow, &decoded, providerType, providerName, requestID, err)
+			}
+		}
+	}
+	if model != "" {
+		auditlog.EnrichEntryWithResolvedRoute(c, qualifyExecutedModel(workflow, model, providerName), providerType, providerName)
+		auditlog.EnrichEntryWithServedModel(c, model)
+		auditlog.EnrichEntryWithResponseID(c, responseID, "")
+	}
+
+	copyPassthroughResponseHeaders(c.Response().Header(), http.Header(resp.Headers))
+	c.Response().WriteHeader(resp.StatusCode)
+	_, err = c.Response().Write(body)
+	return err
+}
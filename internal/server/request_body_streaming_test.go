@@ -0,0 +1,217 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// bodyReadingProvider reads passthrough request bodies to the end, like an
+// upstream HTTP client does.
+type bodyReadingProvider struct {
+	*mockProvider
+	received []byte
+}
+
+func (p *bodyReadingProvider) Passthrough(ctx context.Context, providerType string, req *core.PassthroughRequest) (*core.PassthroughResponse, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	p.received = body
+	resp, err := p.mockProvider.Passthrough(ctx, providerType, req)
+	if resp != nil {
+		copied := *resp
+		copied.Body = io.NopCloser(strings.NewReader(streamedChatResponse))
+		resp = &copied
+	}
+	return resp, err
+}
+
+const streamedChatResponse = `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":900,"completion_tokens":2,"total_tokens":902}}`
+
+func newBodyStreamingServer(providerType string, streaming RequestBodyStreaming) (*Server, *bodyReadingProvider, *syncAuditLogger, *syncUsageLogger) {
+	provider := &bodyReadingProvider{mockProvider: &mockProvider{
+		supportedModels: []string{"gpt-4o"},
+		providerTypes:   map[string]string{"gpt-4o": providerType},
+		response:        &core.ChatResponse{ID: "translated", Model: "gpt-4o"},
+		passthroughResponse: &core.PassthroughResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		},
+	}}
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	srv := New(provider, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger, RequestBodyStreaming: streaming})
+	return srv, provider, auditLogger, usageLogger
+}
+
+func largeChatBody(size int) []byte {
+	content := strings.Repeat("a", size)
+	return []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"` + content + `"}]}`)
+}
+
+func largeChatRequest(body []byte, chunked bool) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if chunked {
+		req.ContentLength = -1
+	}
+	return req
+}
+
+func TestLargeRequestBodies_PipesBodyToProvider(t *testing.T) {
+	for _, chunked := range []bool{false, true} {
+		t.Run(map[bool]string{false: "content length", true: "chunked"}[chunked], func(t *testing.T) {
+			srv, provider, auditLogger, usageLogger := newBodyStreamingServer("openai", RequestBodyStreaming{Threshold: 64 * 1024})
+			body := largeChatBody(256 * 1024)
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, largeChatRequest(body, chunked))
+
+			if rec.Code != http.StatusOK || rec.Body.String() != streamedChatResponse {
+				t.Fatalf("response = %d %s, want the provider response", rec.Code, rec.Body.String())
+			}
+			if !bytes.Equal(provider.received, body) {
+				t.Fatalf("provider received %d bytes, want the %d byte body unchanged", len(provider.received), len(body))
+			}
+			if provider.lastPassthroughReq.Endpoint != "/chat/completions" || provider.lastPassthroughProvider != "openai" {
+				t.Fatalf("passthrough = %s %q", provider.lastPassthroughProvider, provider.lastPassthroughReq.Endpoint)
+			}
+
+			auditLogger.mu.Lock()
+			defer auditLogger.mu.Unlock()
+			if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+				t.Fatalf("audit entries = %d, want one with data", len(auditLogger.entries))
+			}
+			data := auditLogger.entries[0].Data
+			hash := sha256.Sum256(body)
+			if !data.RequestBodyTooBigToHandle || data.RequestBody != nil ||
+				data.RequestBodyBytes != int64(len(body)) || data.RequestBodySHA256 != hex.EncodeToString(hash[:]) {
+				t.Fatalf("audit data = too big %v, %d bytes, sha256 %q; want metadata only", data.RequestBodyTooBigToHandle, data.RequestBodyBytes, data.RequestBodySHA256)
+			}
+
+			usageLogger.mu.Lock()
+			defer usageLogger.mu.Unlock()
+			if len(usageLogger.entries) != 1 || usageLogger.entries[0].InputTokens != 900 {
+				t.Fatalf("usage entries = %+v, want the provider usage", usageLogger.entries)
+			}
+		})
+	}
+}
+
+func TestLargeRequestBodies_BuffersRequestsThatNeedConversion(t *testing.T) {
+	body := largeChatBody(256 * 1024)
+	tests := []struct {
+		name       string
+		streaming  RequestBodyStreaming
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "within buffered limit", streaming: RequestBodyStreaming{Threshold: 64 * 1024, BufferedLimit: 1024 * 1024}, wantStatus: http.StatusOK},
+		{name: "no buffered limit", streaming: RequestBodyStreaming{Threshold: 64 * 1024}, wantStatus: http.StatusOK},
+		{
+			name:       "over buffered limit",
+			streaming:  RequestBodyStreaming{Threshold: 64 * 1024, BufferedLimit: 128 * 1024},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   "request_body_too_large",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, provider, _, _ := newBodyStreamingServer("anthropic", tt.streaming)
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, largeChatRequest(body, true))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if provider.lastPassthroughReq != nil {
+				t.Fatal("body was piped to a provider that needs conversion")
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var errBody map[string]map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &errBody); err != nil || errBody["error"]["code"] != tt.wantCode {
+				t.Fatalf("error body = %s, want code %s", rec.Body.String(), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestLargeRequestBodies_SmallBodiesAreTranslated(t *testing.T) {
+	srv, provider, _, _ := newBodyStreamingServer("openai", RequestBodyStreaming{Threshold: 64 * 1024})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, largeChatRequest(largeChatBody(1024), false))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"translated"`) {
+		t.Fatalf("response = %d %s, want the translated response", rec.Code, rec.Body.String())
+	}
+	if provider.lastPassthroughReq != nil {
+		t.Fatal("small body was piped to the provider")
+	}
+}
+
+// discardingProvider drains passthrough request bodies without keeping them.
+type discardingProvider struct {
+	*mockProvider
+}
+
+func (p discardingProvider) Passthrough(_ context.Context, _ string, req *core.PassthroughRequest) (*core.PassthroughResponse, error) {
+	if _, err := io.Copy(io.Discard, req.Body); err != nil {
+		return nil, err
+	}
+	return &core.PassthroughResponse{
+		StatusCode: http.StatusOK,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(streamedChatResponse)),
+	}, nil
+}
+
+// BenchmarkLargeRequestBodies compares the memory used by concurrent 4 MiB
+// chat requests when they are piped to the provider and when they are
+// buffered and translated.
+func BenchmarkLargeRequestBodies(b *testing.B) {
+	body := largeChatBody(4 * 1024 * 1024)
+	for _, bench := range []struct {
+		name      string
+		streaming RequestBodyStreaming
+	}{
+		{name: "piped", streaming: RequestBodyStreaming{Threshold: 1024 * 1024}},
+		{name: "buffered"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			provider := discardingProvider{&mockProvider{
+				supportedModels: []string{"gpt-4o"},
+				providerTypes:   map[string]string{"gpt-4o": "openai"},
+				response:        &core.ChatResponse{ID: "translated", Model: "gpt-4o"},
+			}}
+			srv := New(provider, &Config{RequestBodyStreaming: bench.streaming})
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					rec := httptest.NewRecorder()
+					srv.ServeHTTP(rec, largeChatRequest(body, true))
+					if rec.Code != http.StatusOK {
+						b.Fatalf("status = %d", rec.Code)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+)
+
+// applyInlineImages enforces the inline image limits on a translated request,
+// stores the image count and decoded size on the request context for usage
+// accounting and attaches the image summaries to the audit entry. The request
+// itself is left untouched so providers receive the original payload.
+func applyInlineImages(c *echo.Context, req any, limits core.InlineImageLimits) error {
+	images := core.RequestInlineImages(req)
+	if len(images) == 0 {
+		return nil
+	}
+	if err := limits.Check(images); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	c.SetRequest(c.Request().WithContext(core.WithInlineImageStats(ctx, core.InlineImageStatsOf(images))))
+	auditlog.EnrichEntryWithInlineImages(c, images)
+	return nil
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func inlineImageURI(mediaType string, size int) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(make([]byte, size))
+}
+
+func inlineImagesChatBody(uris ...string) string {
+	parts := []map[string]any{{"type": "text", "text": "compare these"}}
+	for _, uri := range uris {
+		parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]any{"url": uri}})
+	}
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4o-mini",
+		"messages": []map[string]any{{"role": "user", "content": parts}},
+	})
+	return string(body)
+}
+
+func newInlineImagesTestServer(provider core.RoutableProvider, limits core.InlineImageLimits) (*Server, *syncAuditLogger, *syncUsageLogger) {
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	return New(provider, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger, InlineImageLimits: limits}), auditLogger, usageLogger
+}
+
+func TestInlineImages_RecordedOnUsageAndAudit(t *testing.T) {
+	provider := &capturingProvider{mockProvider: mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: "gpt-4o-mini",
+			Usage: core.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		},
+	}}
+	srv, auditLogger, usageLogger := newInlineImagesTestServer(provider, core.InlineImageLimits{})
+
+	png, jpeg := inlineImageURI("image/png", 3000), inlineImageURI("image/jpeg", 1500)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(inlineImagesChatBody(png, jpeg)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+
+	if provider.capturedChatReq == nil {
+		t.Fatal("capturedChatReq = nil")
+	}
+	parts, _ := core.NormalizeContentParts(provider.capturedChatReq.Messages[0].Content)
+	if len(parts) != 3 || parts[1].ImageURL.URL != png || parts[2].ImageURL.URL != jpeg {
+		t.Fatalf("provider content = %+v, want the original image payloads", parts)
+	}
+
+	usageLogger.mu.Lock()
+	defer usageLogger.mu.Unlock()
+	if len(usageLogger.entries) != 1 {
+		t.Fatalf("usage entries = %d, want 1", len(usageLogger.entries))
+	}
+	if entry := usageLogger.entries[0]; entry.ImageCount != 2 || entry.ImageBytes != 4500 {
+		t.Fatalf("usage images = %d (%d bytes), want 2 (4500 bytes)", entry.ImageCount, entry.ImageBytes)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %+v, want one entry with data", auditLogger.entries)
+	}
+	data := auditLogger.entries[0].Data
+	if len(data.RequestImages) != 2 || data.RequestImages[0].MediaType != "image/png" || data.RequestImages[1].Bytes != 1500 {
+		t.Fatalf("audit request images = %+v, want png and 1500-byte jpeg summaries", data.RequestImages)
+	}
+	body, err := json.Marshal(data.RequestBody)
+	if err != nil {
+		t.Fatalf("marshal audit request body: %v", err)
+	}
+	if strings.Contains(string(body), "base64,") {
+		t.Fatalf("audit request body keeps the image payload: %s", body)
+	}
+	if !strings.Contains(string(body), "[inline image omitted: image/png, 3000 bytes, sha256:") {
+		t.Fatalf("audit request body = %s, want image placeholders", body)
+	}
+}
+
+func TestInlineImages_EnforcesLimits(t *testing.T) {
+	limits := core.InlineImageLimits{MaxImages: 2, MaxImageBytes: 2048}
+	tests := []struct {
+		name     string
+		uris     []string
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "at the limits",
+			uris:     []string{inlineImageURI("image/png", 2048), inlineImageURI("image/png", 2048)},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "one byte over the size limit",
+			uris:     []string{inlineImageURI("image/png", 100), inlineImageURI("image/png", 2049)},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "inline image 2 is 2049 bytes, at most 2048 bytes are allowed per image",
+		},
+		{
+			name:     "one image over the count limit",
+			uris:     []string{inlineImageURI("image/png", 10), inlineImageURI("image/png", 10), inlineImageURI("image/png", 10)},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "request contains 3 inline images, at most 2 are allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "chatcmpl-1", Model: "gpt-4o-mini"}}
+			srv, _, _ := newInlineImagesTestServer(provider, limits)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(inlineImagesChatBody(tt.uris...)))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantErr != "" && !strings.Contains(rec.Body.String(), tt.wantErr) {
+				t.Fatalf("body = %s, want error %q", rec.Body.String(), tt.wantErr)
+			}
+		})
+	}
+}
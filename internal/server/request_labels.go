@@ -0,0 +1,41 @@
+package server
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+)
+
+// applyRequestLabels merges the metadata object of a translated request body
+// into the labels parsed from X-GoModel-Label-* headers, stores the result on
+// the request context and attaches it to the audit entry.
+func applyRequestLabels(c *echo.Context, req any) error {
+	var metadataLabels map[string]string
+	var err error
+	switch typed := req.(type) {
+	case *core.ChatRequest:
+		if typed != nil {
+			metadataLabels, err = core.RequestLabelsFromMetadata(typed.ExtraFields.Lookup("metadata"))
+		}
+	case *core.ResponsesRequest:
+		if typed != nil {
+			metadataLabels, err = core.NormalizeRequestLabels(typed.Metadata)
+		}
+	}
+	if err != nil {
+		return core.NewInvalidRequestError("invalid request metadata: "+err.Error(), err)
+	}
+
+	ctx := c.Request().Context()
+	labels, err := core.MergeRequestLabels(core.GetRequestLabels(ctx), metadataLabels)
+	if err != nil {
+		return core.NewInvalidRequestError("invalid request labels: "+err.Error(), err)
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	c.SetRequest(c.Request().WithContext(core.WithRequestLabels(ctx, labels)))
+	auditlog.EnrichEntryWithLabels(c, labels)
+	return nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func TestRequestLabels_RecordedOnUsageAndAudit(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: "gpt-4o-mini",
+			Usage: core.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		},
+	}
+	srv, auditLogger, usageLogger := newAccumulateTestServer(mock)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(
+		`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}],"metadata":{"team":"search","env":"prod"}}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GoModel-Label-Team", "growth")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	want := map[string]string{"team": "growth", "env": "prod"}
+
+	usageLogger.mu.Lock()
+	defer usageLogger.mu.Unlock()
+	if len(usageLogger.entries) != 1 || !maps.Equal(usageLogger.entries[0].Labels, want) {
+		t.Fatalf("usage entries = %+v, want one entry labelled %v", usageLogger.entries, want)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil || !maps.Equal(auditLogger.entries[0].Data.Labels, want) {
+		t.Fatalf("audit entries = %+v, want one entry labelled %v", auditLogger.entries, want)
+	}
+}
+
+func TestRequestLabels_RejectsInvalidLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   string
+	}{
+		{name: "invalid header value", header: "growth team", body: `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`},
+		{name: "non-string metadata value", body: `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}],"metadata":{"team":1}}`},
+		{name: "too many labels", header: "growth", body: `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}],"metadata":{"a":"1","b":"2","c":"3","d":"4","e":"5"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "chatcmpl-1"}}
+			srv, _, _ := newAccumulateTestServer(mock)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("X-GoModel-Label-Team", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -53,9 +53,27 @@ func storeRequestModelResolution(c *echo.Context, resolution *core.RequestModelR
 		env.RouteHints.Model = resolution.ResolvedSelector.Model
 		env.RouteHints.Provider = resolution.ResolvedSelector.Provider
 	}
+	setResolutionHeaders(c, resolution)
 	c.SetRequest(c.Request().WithContext(ctx))
 }
 
+// setResolutionHeaders tells the client which model group member and
+// experiment variant served the request.
+func setResolutionHeaders(c *echo.Context, resolution *core.RequestModelResolution) {
+	if resolution == nil {
+		return
+	}
+	header := c.Response().Header()
+	if group := resolution.ModelGroup; group != nil {
+		header.Set(core.ModelGroupHeader, group.Group)
+		header.Set(core.ModelGroupMemberHeader, group.Member)
+	}
+	if experiment := resolution.Experiment; experiment != nil {
+		header.Set(core.ExperimentHeader, experiment.Experiment)
+		header.Set(core.ExperimentVariantHeader, experiment.Variant)
+	}
+}
+
 func ensureRequestModelResolution(c *echo.Context, provider core.RoutableProvider, resolver RequestModelResolver) (*core.RequestModelResolution, bool, error) {
 	if c == nil {
 		return nil, false, nil
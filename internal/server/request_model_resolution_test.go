@@ -3,9 +3,13 @@ package server
 import (
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/labstack/echo/v5"
+
 	"gomodel/internal/core"
 )
 
@@ -169,3 +173,92 @@ func TestResolveRequestModel_CanonicalizesAliasOutputThroughProviderResolver(t *
 		t.Fatalf("ProviderName = %q, want %q", got, "openai_test")
 	}
 }
+
+type experimentAssignerStub struct {
+	aliasResolverStub
+}
+
+func (experimentAssignerStub) AssignExperiment(_ context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ExperimentAssignment) {
+	if requested.RequestedQualifiedModel() != "smart" {
+		return requested, nil
+	}
+	return core.NewRequestedModelSelector("gpt-5-nano", "openai"), &core.ExperimentAssignment{Experiment: "smart-model", Variant: "candidate"}
+}
+
+func TestResolveAndStoreRequestModelResolution_AppliesExperimentVariant(t *testing.T) {
+	provider := &canonicalizingProvider{
+		types: map[string]string{"openai/gpt-5-nano": "openai"},
+		names: map[string]string{"openai/gpt-5-nano": "openai"},
+	}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req = req.WithContext(core.WithWorkflow(req.Context(), &core.Workflow{}))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	resolution, err := resolveAndStoreRequestModelResolution(c, provider, experimentAssignerStub{}, nil, "smart", "")
+	if err != nil {
+		t.Fatalf("resolveAndStoreRequestModelResolution() error = %v", err)
+	}
+
+	if got := resolution.Requested.RequestedQualifiedModel(); got != "smart" {
+		t.Fatalf("Requested = %q, want the client's selector", got)
+	}
+	if got := resolution.ResolvedQualifiedModel(); got != "openai/gpt-5-nano" {
+		t.Fatalf("ResolvedQualifiedModel = %q, want %q", got, "openai/gpt-5-nano")
+	}
+	if resolution.Experiment == nil || resolution.Experiment.Variant != "candidate" {
+		t.Fatalf("Experiment = %+v, want candidate assignment", resolution.Experiment)
+	}
+	if got := rec.Header().Get(core.ExperimentHeader); got != "smart-model" {
+		t.Fatalf("%s = %q, want %q", core.ExperimentHeader, got, "smart-model")
+	}
+	if got := rec.Header().Get(core.ExperimentVariantHeader); got != "candidate" {
+		t.Fatalf("%s = %q, want %q", core.ExperimentVariantHeader, got, "candidate")
+	}
+}
+
+type modelGroupResolverStub struct {
+	experimentAssignerStub
+}
+
+func (modelGroupResolverStub) ResolveModelGroup(_ context.Context, requested core.RequestedModelSelector) (core.RequestedModelSelector, *core.ModelGroupSelection, error) {
+	if requested.RequestedQualifiedModel() != "group:coding" {
+		return requested, nil, nil
+	}
+	return core.NewRequestedModelSelector("smart", ""), &core.ModelGroupSelection{Group: "coding", Member: "smart"}, nil
+}
+
+func TestResolveAndStoreRequestModelResolution_AppliesModelGroup(t *testing.T) {
+	provider := &canonicalizingProvider{
+		types: map[string]string{"openai/gpt-5-nano": "openai"},
+		names: map[string]string{"openai/gpt-5-nano": "openai"},
+	}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req = req.WithContext(core.WithWorkflow(req.Context(), &core.Workflow{}))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	resolution, err := resolveAndStoreRequestModelResolution(c, provider, modelGroupResolverStub{}, nil, "group:coding", "")
+	if err != nil {
+		t.Fatalf("resolveAndStoreRequestModelResolution() error = %v", err)
+	}
+
+	if got := resolution.Requested.RequestedQualifiedModel(); got != "group:coding" {
+		t.Fatalf("Requested = %q, want the group", got)
+	}
+	// The group member is itself an experiment target, so both apply.
+	if got := resolution.ResolvedQualifiedModel(); got != "openai/gpt-5-nano" {
+		t.Fatalf("ResolvedQualifiedModel = %q, want %q", got, "openai/gpt-5-nano")
+	}
+	if resolution.ModelGroup == nil || resolution.ModelGroup.Member != "smart" || resolution.Experiment == nil {
+		t.Fatalf("resolution = %+v, want the group member and its experiment", resolution)
+	}
+	if got := rec.Header().Get(core.ModelGroupHeader); got != "coding" {
+		t.Fatalf("%s = %q, want %q", core.ModelGroupHeader, got, "coding")
+	}
+	if got := rec.Header().Get(core.ModelGroupMemberHeader); got != "smart" {
+		t.Fatalf("%s = %q, want %q", core.ModelGroupMemberHeader, got, "smart")
+	}
+}
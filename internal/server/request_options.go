@@ -0,0 +1,42 @@
+package server
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+)
+
+// RequestOptions parses the per-request gateway options of model endpoints
+// once, from the X-GoModel-Options header and the standalone option headers,
+// and stores them on the request context for the middleware and handlers
+// below. Malformed options are rejected with a 400; unknown keys are too when
+// strict, and ignored otherwise so clients can send options newer gateways
+// understand.
+func RequestOptions(strict bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !core.IsModelInteractionPath(req.URL.Path) {
+				return next(c)
+			}
+			opts, err := core.ParseRequestOptions(req.Header, strict)
+			if err != nil {
+				return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+			}
+			if opts != nil {
+				c.SetRequest(req.WithContext(core.WithRequestOptions(req.Context(), opts)))
+			}
+			return next(c)
+		}
+	}
+}
+
+// requestOptions returns the options of the request. Handlers served without
+// the RequestOptions middleware read the standalone option headers.
+func requestOptions(c *echo.Context) (*core.RequestOptions, error) {
+	req := c.Request()
+	if opts := core.GetRequestOptions(req.Context()); opts != nil {
+		return opts, nil
+	}
+	return core.ParseRequestOptions(req.Header, false)
+}
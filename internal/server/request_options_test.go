@@ -0,0 +1,112 @@
+package server
+
+import (
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func TestRequestOptions_StrictCompatOption(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		options      string
+		legacy       string
+		wantProvider bool
+	}{
+		{name: "request opts in", options: "strict_compat=true"},
+		{name: "request opts out", enabled: true, options: "strict_compat=false", wantProvider: true},
+		{name: "explicit default keeps config", enabled: true, options: "strict_compat=default"},
+		{name: "options header wins over standalone header", options: "strict_compat=false", legacy: "true", wantProvider: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(strictCompatMock(), &Config{StrictOpenAICompat: tt.enabled})
+			req := chaosChatRequest(false)
+			req.Header.Set(core.RequestOptionsHeader, tt.options)
+			if tt.legacy != "" {
+				req.Header.Set(core.StrictCompatHeader, tt.legacy)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if got := strings.Contains(rec.Body.String(), `"provider"`); got != tt.wantProvider {
+				t.Fatalf("body = %s, want provider field %v", rec.Body.String(), tt.wantProvider)
+			}
+		})
+	}
+}
+
+func TestRequestOptions_AccumulateOption(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: chatStreamChunks("", `{"ok":`, ` true}`)}
+	srv, auditLogger, _ := newAccumulateTestServer(mock)
+
+	req := streamFormatRequest("/v1/chat/completions", streamFormatChatBody, "")
+	req.Header.Set(core.RequestOptionsHeader, "accumulate=json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if resp := decodeAccumulated(t, rec); string(resp.Output) != `{"ok":true}` {
+		t.Fatalf("output = %s, want the accumulated object", resp.Output)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %d, want one with data", len(auditLogger.entries))
+	}
+	want := map[string]string{"strict_compat": "default", "accumulate": "json", "prompt_compression": "default", "first_token_timeout": "default", "server_tools": "off"}
+	if got := auditLogger.entries[0].Data.Options; !maps.Equal(got, want) {
+		t.Fatalf("audit options = %v, want %v", got, want)
+	}
+}
+
+func TestRequestOptions_UnknownKeys(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+		usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+		srv := New(strictCompatMock(), &Config{StrictRequestOptions: strict, AuditLogger: auditLogger, UsageLogger: usageLogger})
+
+		req := chaosChatRequest(false)
+		req.Header.Set(core.RequestOptionsHeader, "future_flag=on")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		if strict {
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "future_flag") {
+				t.Fatalf("strict: response = %d %s, want a 400 naming the unknown option", rec.Code, rec.Body.String())
+			}
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("lenient: status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+		}
+		auditLogger.mu.Lock()
+		if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data.Options != nil {
+			t.Fatalf("lenient: audit entries = %d, want one without options", len(auditLogger.entries))
+		}
+		auditLogger.mu.Unlock()
+	}
+}
+
+func TestRequestOptions_RejectsMalformedHeader(t *testing.T) {
+	for _, value := range []string{"accumulate=json,accumulate=off", `prompt_compression="whitespace`, "accumulate=xml"} {
+		mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: chatStreamChunks("", "{}")}
+		srv, _, _ := newAccumulateTestServer(mock)
+
+		req := streamFormatRequest("/v1/chat/completions", streamFormatChatBody, "")
+		req.Header.Set(core.RequestOptionsHeader, value)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), core.RequestOptionsHeader) {
+			t.Fatalf("%s: response = %d %s, want a 400 naming the header", value, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"strings"
@@ -15,11 +16,23 @@ import (
 
 const requestSnapshotInlineBodyLimit int64 = 64 * 1024
 
+// requestBodyCaptureLimitKey stores the resolved per-path request body capture
+// limit so late body reads snapshot against the same bound as the middleware.
+const requestBodyCaptureLimitKey = "gomodel_request_body_capture_limit"
+
 // RequestSnapshotCapture captures immutable transport-level request data for
 // model-facing endpoints. Known-small JSON bodies are captured once for the
 // hot path; larger or unknown-size bodies only get a bounded selector peek and
 // stay on the live request stream until the handler actually decodes them.
 func RequestSnapshotCapture() echo.MiddlewareFunc {
+	return RequestSnapshotCaptureWithBodyCapture(auditlog.BodyCaptureConfig{})
+}
+
+// RequestSnapshotCaptureWithBodyCapture is RequestSnapshotCapture with
+// configured body capture limits: request bodies above the per-path request
+// limit are marked as not captured instead of using auditlog.MaxBodyCapture.
+func RequestSnapshotCaptureWithBodyCapture(bodyCapture auditlog.BodyCaptureConfig) echo.MiddlewareFunc {
+	bodyCapture = bodyCapture.Clone()
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
 			req, requestID := ensureRequestID(c.Request())
@@ -29,6 +42,8 @@ func RequestSnapshotCapture() echo.MiddlewareFunc {
 				c.SetRequest(req)
 				return next(c)
 			}
+			captureLimit := bodyCapture.LimitsFor(req.URL.Path).Request
+			c.Set(requestBodyCaptureLimitKey, captureLimit)
 
 			userPath, err := core.NormalizeUserPath(req.Header.Get(core.UserPathHeader))
 			if err != nil {
@@ -37,8 +52,16 @@ func RequestSnapshotCapture() echo.MiddlewareFunc {
 			if userPath != "" {
 				req.Header.Set(core.UserPathHeader, userPath)
 			}
+			residency, err := core.NormalizeDataResidency(req.Header.Get(core.DataResidencyHeader))
+			if err != nil {
+				return handleError(c, core.NewInvalidRequestError("invalid X-GoModel-Data-Residency header", err))
+			}
+			labels, err := core.RequestLabelsFromHeaders(req.Header)
+			if err != nil {
+				return handleError(c, core.NewInvalidRequestError("invalid X-GoModel-Label header", err))
+			}
 
-			bodyBytes, bodyNotCaptured, bodyCaptured, err := captureSmallRequestBodyForSnapshot(req, desc.BodyMode)
+			bodyBytes, bodyNotCaptured, bodyCaptured, err := captureSmallRequestBodyForSnapshot(req, desc.BodyMode, captureLimit)
 			if err != nil {
 				return handleError(c, core.NewInvalidRequestError("failed to read request body", err))
 			}
@@ -58,6 +81,12 @@ func RequestSnapshotCapture() echo.MiddlewareFunc {
 			)
 
 			ctx := core.WithRequestSnapshot(req.Context(), snapshot)
+			if residency != "" {
+				ctx = core.WithDataResidency(ctx, residency)
+			}
+			if len(labels) > 0 {
+				ctx = core.WithRequestLabels(ctx, labels)
+			}
 			if semantics := core.DeriveWhiteBoxPrompt(snapshot); semantics != nil {
 				if !bodyCaptured {
 					seedRequestBodySelectorHints(req, desc.BodyMode, semantics)
@@ -72,6 +101,14 @@ func RequestSnapshotCapture() echo.MiddlewareFunc {
 }
 
 func ensureRequestID(req *http.Request) (*http.Request, string) {
+	req, requestID, _ := ensureRequestContext(req, false)
+	return req, requestID
+}
+
+// ensureRequestContext is ensureRequestID that, with abortable set, also
+// gives the request a cancelable context and returns its cancel func. Both
+// share one request copy, which keeps the model request hot path cheap.
+func ensureRequestContext(req *http.Request, abortable bool) (*http.Request, string, context.CancelFunc) {
 	if req.Header == nil {
 		req.Header = make(http.Header)
 	}
@@ -84,10 +121,18 @@ func ensureRequestID(req *http.Request) (*http.Request, string) {
 	}
 
 	req.Header.Set("X-Request-ID", requestID)
-	if current := strings.TrimSpace(core.GetRequestID(req.Context())); current != requestID {
-		req = req.WithContext(core.WithRequestID(req.Context(), requestID))
+	ctx := req.Context()
+	if current := strings.TrimSpace(core.GetRequestID(ctx)); current != requestID {
+		ctx = core.WithRequestID(ctx, requestID)
 	}
-	return req, requestID
+	var cancel context.CancelFunc
+	if abortable {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	if ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+	return req, requestID, cancel
 }
 
 func snapshotRouteParams(path string, params map[string]string) map[string]string {
@@ -122,9 +167,9 @@ func extractTraceMetadata(headers map[string][]string) map[string]string {
 	return metadata
 }
 
-func captureSmallRequestBodyForSnapshot(req *http.Request, bodyMode core.BodyMode) ([]byte, bool, bool, error) {
+func captureSmallRequestBodyForSnapshot(req *http.Request, bodyMode core.BodyMode, captureLimit int64) ([]byte, bool, bool, error) {
 	if !shouldCaptureSmallRequestBody(req, bodyMode) {
-		return nil, snapshotBodyNotCaptured(req, bodyMode), false, nil
+		return nil, snapshotBodyNotCaptured(req, bodyMode, captureLimit), false, nil
 	}
 
 	originalBody := req.Body
@@ -138,7 +183,7 @@ func captureSmallRequestBodyForSnapshot(req *http.Request, bodyMode core.BodyMod
 			Reader: io.MultiReader(bytes.NewReader(bodyBytes), originalBody),
 			rc:     originalBody,
 		}
-		return nil, snapshotBodyNotCaptured(req, bodyMode), false, nil
+		return nil, snapshotBodyNotCaptured(req, bodyMode, captureLimit), false, nil
 	}
 
 	if bodyBytes == nil {
@@ -160,13 +205,13 @@ func shouldCaptureSmallRequestBody(req *http.Request, bodyMode core.BodyMode) bo
 	return req.ContentLength >= 0 && req.ContentLength <= requestSnapshotInlineBodyLimit
 }
 
-func snapshotBodyNotCaptured(req *http.Request, bodyMode core.BodyMode) bool {
+func snapshotBodyNotCaptured(req *http.Request, bodyMode core.BodyMode, captureLimit int64) bool {
 	if req == nil {
 		return false
 	}
 	switch bodyMode {
 	case core.BodyModeJSON, core.BodyModeOpaque:
-		return req.ContentLength > auditlog.MaxBodyCapture
+		return req.ContentLength > captureLimit
 	default:
 		return false
 	}
@@ -193,7 +238,7 @@ func requestBodyBytes(c *echo.Context) ([]byte, error) {
 		return []byte{}, nil
 	}
 
-	bodyBytes, err := io.ReadAll(req.Body)
+	bodyBytes, err := readRequestBody(c, req.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +250,13 @@ func requestBodyBytes(c *echo.Context) ([]byte, error) {
 	return bodyBytes, nil
 }
 
+func requestBodyCaptureLimit(c *echo.Context) int64 {
+	if limit, ok := c.Get(requestBodyCaptureLimitKey).(int64); ok && limit > 0 {
+		return limit
+	}
+	return auditlog.MaxBodyCapture
+}
+
 func storeRequestBodySnapshot(c *echo.Context, bodyBytes []byte) {
 	if c == nil {
 		return
@@ -215,7 +267,7 @@ func storeRequestBodySnapshot(c *echo.Context, bodyBytes []byte) {
 		return
 	}
 
-	bodyNotCaptured := int64(len(bodyBytes)) > auditlog.MaxBodyCapture
+	bodyNotCaptured := int64(len(bodyBytes)) > requestBodyCaptureLimit(c)
 	capturedBody := bodyBytes
 	if bodyNotCaptured {
 		capturedBody = nil
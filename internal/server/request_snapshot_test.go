@@ -173,6 +173,48 @@ func TestSemanticJSONBodyRefreshesPromptFromFullBody(t *testing.T) {
 	assert.Nil(t, updated.CapturedBodyView())
 }
 
+func TestRequestSnapshotCaptureWithBodyCapture_UsesPerPathRequestLimit(t *testing.T) {
+	bodyCapture := auditlog.BodyCaptureConfig{Paths: map[string]auditlog.BodyCaptureLimits{
+		"/v1/chat/completions": {Request: 96 * 1024},
+	}}
+	content := strings.Repeat("x", 128*1024)
+	reqBody := `{"messages":[{"role":"user","content":"` + content + `"}],"model":"gpt-5-mini"}`
+
+	for _, tt := range []struct {
+		name        string
+		bodyCapture auditlog.BodyCaptureConfig
+		notCaptured bool
+	}{
+		{name: "default limit", notCaptured: false},
+		{name: "per-path limit", bodyCapture: bodyCapture, notCaptured: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var middlewareFrame, handlerFrame *core.RequestSnapshot
+			handler := RequestSnapshotCaptureWithBodyCapture(tt.bodyCapture)(func(c *echo.Context) error {
+				middlewareFrame = core.GetRequestSnapshot(c.Request().Context())
+				if _, _, err := semanticJSONBody(c); err != nil {
+					return err
+				}
+				handlerFrame = core.GetRequestSnapshot(c.Request().Context())
+				return c.String(http.StatusOK, "ok")
+			})
+
+			require.NoError(t, handler(c))
+			require.NotNil(t, middlewareFrame)
+			require.NotNil(t, handlerFrame)
+			assert.Equal(t, tt.notCaptured, middlewareFrame.BodyNotCaptured)
+			assert.Equal(t, tt.notCaptured, handlerFrame.BodyNotCaptured)
+			assert.Equal(t, tt.notCaptured, handlerFrame.CapturedBodyView() == nil)
+		})
+	}
+}
+
 func TestRequestSnapshotCapture_NormalizesUserPathHeader(t *testing.T) {
 	e := echo.New()
 
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"gomodel/internal/core"
+	"gomodel/internal/gateway"
 )
 
 func requestIDFromContextOrHeader(req *http.Request) string {
@@ -21,6 +22,29 @@ func requestIDFromContextOrHeader(req *http.Request) string {
 	return strings.TrimSpace(req.Header.Get("X-Request-ID"))
 }
 
+// requestLogAttrs returns the request_id, provider and model attributes shared by
+// request-path log lines. Provider falls back to fallbackProvider and model is
+// empty until the workflow has resolved the request.
+func requestLogAttrs(req *http.Request, fallbackProvider string) []any {
+	provider := fallbackProvider
+	var model string
+	if req != nil {
+		workflow := core.GetWorkflow(req.Context())
+		if name := gateway.ProviderNameFromWorkflow(workflow); name != "" {
+			provider = name
+		}
+		model = workflow.ResolvedQualifiedModel()
+		if model == "" {
+			model = workflow.RequestedQualifiedModel()
+		}
+	}
+	return []any{
+		"request_id", requestIDFromContextOrHeader(req),
+		"provider", provider,
+		"model", model,
+	}
+}
+
 func requestContextWithRequestID(req *http.Request) (context.Context, string) {
 	if req == nil {
 		requestID := uuid.NewString()
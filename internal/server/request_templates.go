@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+)
+
+// PromptTemplateRenderer renders the stored prompt template a request refers
+// to into chat messages. Errors are returned as gateway errors.
+type PromptTemplateRenderer interface {
+	RenderPromptTemplate(ref core.PromptTemplateRef) (core.PromptTemplateUse, []core.Message, error)
+}
+
+// applyPromptTemplate renders the template field of a translated request,
+// prepends the rendered messages to the conversation and strips the field so
+// providers never see it. The template name and version are stored on the
+// request context for usage accounting and attached to the audit entry.
+func applyPromptTemplate(c *echo.Context, req any, renderer PromptTemplateRenderer) error {
+	ref, err := core.RequestPromptTemplate(req)
+	if err != nil || ref == nil {
+		return err
+	}
+	if renderer == nil {
+		return core.NewInvalidRequestError("prompt templates are not available", nil).WithParam(core.PromptTemplateField)
+	}
+
+	use, messages, err := renderer.RenderPromptTemplate(*ref)
+	if err != nil {
+		return err
+	}
+	core.ApplyPromptTemplateMessages(req, messages)
+
+	ctx := c.Request().Context()
+	c.SetRequest(c.Request().WithContext(core.WithPromptTemplate(ctx, use)))
+	auditlog.EnrichEntryWithPromptTemplate(c, use)
+	return nil
+}
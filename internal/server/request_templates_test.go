@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+type stubPromptTemplates struct {
+	lastRef core.PromptTemplateRef
+}
+
+func (s *stubPromptTemplates) RenderPromptTemplate(ref core.PromptTemplateRef) (core.PromptTemplateUse, []core.Message, error) {
+	s.lastRef = ref
+	if _, ok := ref.Variables["product"]; !ok {
+		return core.PromptTemplateUse{}, nil, core.NewInvalidRequestError("missing required template variables: product", nil)
+	}
+	version := ref.Version
+	if version == 0 {
+		version = 2
+	}
+	return core.PromptTemplateUse{Name: ref.Name, Version: version}, []core.Message{
+		{Role: "system", Content: "You support " + ref.Variables["product"].(string) + "."},
+	}, nil
+}
+
+func postTemplateRequest(srv *Server, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPromptTemplate_RenderedIntoChatMessages(t *testing.T) {
+	provider := &capturingProvider{mockProvider: mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: "gpt-4o-mini",
+			Usage: core.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		},
+	}}
+	templates := &stubPromptTemplates{}
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	srv := New(provider, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger, PromptTemplates: templates})
+
+	rec := postTemplateRequest(srv, "/v1/chat/completions", `{
+		"model":"gpt-4o-mini",
+		"template":{"name":"support","version":1,"variables":{"product":"GoModel"}},
+		"messages":[{"role":"user","content":"hello"}]
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+
+	if templates.lastRef.Name != "support" || templates.lastRef.Version != 1 {
+		t.Fatalf("rendered ref = %+v, want support v1", templates.lastRef)
+	}
+	got := provider.capturedChatReq
+	if got == nil || len(got.Messages) != 2 {
+		t.Fatalf("provider request = %+v, want rendered system message and user message", got)
+	}
+	if got.Messages[0].Role != "system" || got.Messages[0].Content != "You support GoModel." || got.Messages[1].Content != "hello" {
+		t.Fatalf("provider messages = %+v", got.Messages)
+	}
+	if raw := got.ExtraFields.Lookup(core.PromptTemplateField); raw != nil {
+		t.Fatalf("template field forwarded to provider: %s", raw)
+	}
+
+	usageLogger.mu.Lock()
+	defer usageLogger.mu.Unlock()
+	if len(usageLogger.entries) != 1 {
+		t.Fatalf("usage entries = %d, want 1", len(usageLogger.entries))
+	}
+	if entry := usageLogger.entries[0]; entry.TemplateName != "support" || entry.TemplateVersion != 1 {
+		t.Fatalf("usage template = %q v%d, want support v1", entry.TemplateName, entry.TemplateVersion)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %+v, want one entry with data", auditLogger.entries)
+	}
+	if snapshot := auditLogger.entries[0].Data.PromptTemplate; snapshot == nil || *snapshot != (auditlog.PromptTemplateSnapshot{Name: "support", Version: 1}) {
+		t.Fatalf("audit prompt template = %+v, want support v1", snapshot)
+	}
+}
+
+func TestPromptTemplate_PrependedToResponsesInput(t *testing.T) {
+	provider := &capturingProvider{mockProvider: mockProvider{
+		supportedModels:   []string{"gpt-4o-mini"},
+		responsesResponse: &core.ResponsesResponse{ID: "resp-1", Model: "gpt-4o-mini"},
+	}}
+	srv := New(provider, &Config{PromptTemplates: &stubPromptTemplates{}})
+
+	rec := postTemplateRequest(srv, "/v1/responses", `{
+		"model":"gpt-4o-mini",
+		"template":{"name":"support","variables":{"product":"GoModel"}},
+		"input":"hello"
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+
+	input, ok := provider.capturedResponsesReq.Input.([]core.ResponsesInputElement)
+	if !ok || len(input) != 2 {
+		t.Fatalf("provider input = %#v, want two message elements", provider.capturedResponsesReq.Input)
+	}
+	if input[0].Role != "system" || input[0].Content != "You support GoModel." || input[1].Role != "user" || input[1].Content != "hello" {
+		t.Fatalf("provider input = %+v", input)
+	}
+}
+
+func TestPromptTemplate_RejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates PromptTemplateRenderer
+		body      string
+		wantErr   string
+	}{
+		{
+			name:      "missing variables",
+			templates: &stubPromptTemplates{},
+			body:      `{"model":"gpt-4o-mini","template":{"name":"support"},"messages":[]}`,
+			wantErr:   "missing required template variables: product",
+		},
+		{
+			name:      "missing name",
+			templates: &stubPromptTemplates{},
+			body:      `{"model":"gpt-4o-mini","template":{"version":1},"messages":[]}`,
+			wantErr:   "template name is required",
+		},
+		{
+			name:    "templates unavailable",
+			body:    `{"model":"gpt-4o-mini","template":{"name":"support"},"messages":[]}`,
+			wantErr: "prompt templates are not available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &capturingProvider{mockProvider: mockProvider{supportedModels: []string{"gpt-4o-mini"}}}
+			srv := New(provider, &Config{PromptTemplates: tt.templates})
+
+			rec := postTemplateRequest(srv, "/v1/chat/completions", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantErr) {
+				t.Fatalf("body = %s, want error %q", rec.Body.String(), tt.wantErr)
+			}
+			if provider.capturedChatReq != nil {
+				t.Fatal("provider was called for a rejected template request")
+			}
+		})
+	}
+}
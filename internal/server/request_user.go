@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+)
+
+// applyRequestUser stores the user field of a translated request on the
+// request context for usage accounting and attaches it to the audit entry.
+// Only its hash is kept unless recordRaw is set. The field itself is left in
+// the request so providers that support it still receive it.
+func applyRequestUser(c *echo.Context, req any, recordRaw bool) error {
+	value, err := core.RequestUserValue(req)
+	if err != nil || value == "" {
+		return err
+	}
+
+	user := core.RequestUser{Hash: core.HashRequestUser(value)}
+	if recordRaw {
+		user.Raw = value
+	}
+	ctx := c.Request().Context()
+	c.SetRequest(c.Request().WithContext(core.WithRequestUser(ctx, user)))
+	auditlog.EnrichEntryWithUser(c, user)
+	return nil
+}
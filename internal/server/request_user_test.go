@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+func TestRequestUser_RecordedOnUsageAndAudit(t *testing.T) {
+	for _, recordRaw := range []bool{false, true} {
+		provider := &capturingProvider{mockProvider: mockProvider{
+			supportedModels: []string{"gpt-4o-mini"},
+			response: &core.ChatResponse{
+				ID:    "chatcmpl-1",
+				Model: "gpt-4o-mini",
+				Usage: core.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+			},
+		}}
+		auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+		usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+		srv := New(provider, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger, RecordRawUser: recordRaw})
+
+		rec := postTemplateRequest(srv, "/v1/chat/completions", `{"model":"gpt-4o-mini","user":"user-1234","messages":[{"role":"user","content":"hello"}]}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+		}
+		if raw := provider.capturedChatReq.ExtraFields.Lookup(core.RequestUserField); string(raw) != `"user-1234"` {
+			t.Fatalf("forwarded user = %s, want the original value", raw)
+		}
+
+		wantHash := core.HashRequestUser("user-1234")
+		wantRaw := ""
+		if recordRaw {
+			wantRaw = "user-1234"
+		}
+
+		usageLogger.mu.Lock()
+		if len(usageLogger.entries) != 1 {
+			t.Fatalf("usage entries = %d, want 1", len(usageLogger.entries))
+		}
+		if entry := usageLogger.entries[0]; entry.UserHash != wantHash || entry.User != wantRaw {
+			t.Fatalf("recordRaw=%v: usage user = %q/%q, want %q/%q", recordRaw, entry.UserHash, entry.User, wantHash, wantRaw)
+		}
+		usageLogger.mu.Unlock()
+
+		auditLogger.mu.Lock()
+		if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+			t.Fatalf("audit entries = %+v, want one entry with data", auditLogger.entries)
+		}
+		if data := auditLogger.entries[0].Data; data.UserHash != wantHash || data.User != wantRaw {
+			t.Fatalf("recordRaw=%v: audit user = %q/%q, want %q/%q", recordRaw, data.UserHash, data.User, wantHash, wantRaw)
+		}
+		auditLogger.mu.Unlock()
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/sanitize"
+)
+
+// ResponseSanitization hides which provider and account served /v1 and
+// provider passthrough responses. Identifying headers are dropped from what
+// the client receives, and chat completion bodies and stream chunks lose
+// system_fingerprint and get gateway-issued IDs. It is installed outside
+// audit logging: handlers and the audit entry still see the original headers
+// and bodies. It is only installed when sanitization is enabled.
+func ResponseSanitization(s *sanitize.Sanitizer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if s == nil || !sanitizationApplies(req.URL.Path) {
+				return next(c)
+			}
+			writer := &sanitizeWriter{
+				ResponseWriter: c.Response(),
+				c:              c,
+				sanitizer:      s,
+				header:         c.Response().Header().Clone(),
+				rewriteBody:    req.Method == http.MethodPost && strings.TrimSuffix(req.URL.Path, "/") == "/v1/chat/completions",
+			}
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err := next(c)
+			if finishErr := writer.finish(); err == nil {
+				err = finishErr
+			}
+			return err
+		}
+	}
+}
+
+func sanitizationApplies(path string) bool {
+	return path == "/v1" || strings.HasPrefix(path, "/v1/") || strings.HasPrefix(path, "/p/")
+}
+
+type sanitizeMode int
+
+const (
+	sanitizeWritten sanitizeMode = iota
+	sanitizeHeldBody
+	sanitizeFilteredStream
+)
+
+// sanitizeWriter gives the handlers below it a header map of their own and
+// copies it without identifying headers when the response starts. Successful
+// chat completion bodies are held until the handler returns; stream events
+// are rewritten line by line as they are written.
+type sanitizeWriter struct {
+	http.ResponseWriter
+	c           *echo.Context
+	sanitizer   *sanitize.Sanitizer
+	header      http.Header
+	rewriteBody bool
+
+	wroteHeader bool
+	mode        sanitizeMode
+	status      int
+	response    *sanitize.Response
+	buf         bytes.Buffer
+}
+
+func (w *sanitizeWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *sanitizeWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	mediaType, _, _ := mime.ParseMediaType(w.header.Get("Content-Type"))
+	encoded := strings.TrimSpace(w.header.Get("Content-Encoding")) != ""
+	switch {
+	case !w.rewriteBody || encoded || code != http.StatusOK:
+	case mediaType == "application/json":
+		w.mode = sanitizeHeldBody
+	case mediaType == "text/event-stream":
+		w.mode = sanitizeFilteredStream
+	}
+	if w.mode != sanitizeWritten {
+		providerName, _ := provenanceTarget(w.c)
+		w.response = w.sanitizer.Response(requestIDFromContextOrHeader(w.c.Request()), providerName)
+	}
+	if w.mode == sanitizeHeldBody {
+		w.status = code
+		return
+	}
+	w.copyHeaders()
+	if w.mode == sanitizeFilteredStream {
+		w.ResponseWriter.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// copyHeaders replaces the client's headers with the handler's, minus the
+// identifying ones. X-Request-ID always carries the gateway request ID, even
+// when a passthrough response copied the provider's over it.
+func (w *sanitizeWriter) copyHeaders() {
+	dst := w.ResponseWriter.Header()
+	clear(dst)
+	for name, values := range w.header {
+		dst[name] = append([]string(nil), values...)
+	}
+	sanitize.StripHeaders(dst)
+	if requestID := requestIDFromContextOrHeader(w.c.Request()); requestID != "" {
+		dst.Set("X-Request-ID", requestID)
+	}
+}
+
+func (w *sanitizeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	switch w.mode {
+	case sanitizeHeldBody:
+		return w.buf.Write(b)
+	case sanitizeFilteredStream:
+		w.buf.Write(b)
+		if err := w.writeEvents(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *sanitizeWriter) Flush() {
+	if w.mode == sanitizeHeldBody {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *sanitizeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeEvents writes the complete lines buffered so far with their event
+// data rewritten. With final set, a trailing partial line is written too.
+func (w *sanitizeWriter) writeEvents(final bool) error {
+	data := w.buf.Bytes()
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if final {
+		end = len(data)
+	}
+	if end == 0 {
+		return nil
+	}
+	out := make([]byte, 0, end)
+	for line := range bytes.Lines(data[:end]) {
+		out = append(out, w.rewriteEventLine(line)...)
+	}
+	w.buf.Next(end)
+	_, err := w.ResponseWriter.Write(out)
+	return err
+}
+
+func (w *sanitizeWriter) rewriteEventLine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	content := bytes.TrimSpace(payload)
+	if len(content) == 0 || content[0] != '{' {
+		return line
+	}
+	rewritten, ok := w.response.Rewrite(content)
+	if !ok {
+		return line
+	}
+	out := make([]byte, 0, len(rewritten)+8)
+	out = append(out, "data: "...)
+	out = append(out, rewritten...)
+	if newline := payload[len(bytes.TrimRight(payload, "\r\n")):]; len(newline) > 0 {
+		out = append(out, newline...)
+	}
+	return out
+}
+
+// finish writes a held body with its identifying fields rewritten, any
+// partial stream line left over, and the headers of a response the handler
+// never wrote.
+func (w *sanitizeWriter) finish() error {
+	switch {
+	case !w.wroteHeader:
+		// Echo writes bodiless responses and errors after the middleware
+		// chain returns; make sure they go out without identifying headers.
+		w.copyHeaders()
+		return nil
+	case w.mode == sanitizeFilteredStream:
+		return w.writeEvents(true)
+	case w.mode != sanitizeHeldBody:
+		return nil
+	}
+	body, _ := w.response.Rewrite(w.buf.Bytes())
+	w.copyHeaders()
+	w.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
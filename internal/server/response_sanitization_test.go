@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/sanitize"
+)
+
+func TestResponseSanitization_StreamHidesProvider(t *testing.T) {
+	streamData := "data: {\"id\":\"chatcmpl-upstream42\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-4o-mini\",\"system_fingerprint\":\"fp_abc123\",\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+		"data: {\"id\":\"chatcmpl-upstream42\",\"object\":\"chat.completion.chunk\",\"model\":\"gpt-4o-mini\",\"system_fingerprint\":\"fp_abc123\",\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+		"data: [DONE]\n\n"
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		providerTypes:   map[string]string{"gpt-4o-mini": "openai"},
+		passthroughResponse: &core.PassthroughResponse{
+			StatusCode: http.StatusOK,
+			Headers: map[string][]string{
+				"Content-Type":             {"text/event-stream"},
+				"Openai-Organization":      {"acme-org"},
+				"Openai-Processing-Ms":     {"12"},
+				"X-Request-Id":             {"req_upstream99"},
+				"X-Ratelimit-Remaining-Rq": {"99"},
+			},
+			Body: io.NopCloser(strings.NewReader(streamData)),
+		},
+	}
+	sanitizer := sanitize.New(config.ResponseSanitizationConfig{Enabled: true})
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogHeaders: true}}
+	srv := New(mock, &Config{AuditLogger: logger, ResponseSanitization: sanitizer})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, leak := range []string{"chatcmpl-upstream42", "fp_abc123", "system_fingerprint"} {
+		if strings.Contains(body, leak) {
+			t.Fatalf("body = %s, want no %q", body, leak)
+		}
+	}
+	for name, values := range rec.Header() {
+		for _, value := range values {
+			for _, leak := range []string{"acme-org", "req_upstream99"} {
+				if strings.Contains(value, leak) {
+					t.Fatalf("header %s = %q, want no %q", name, value, leak)
+				}
+			}
+		}
+		if sanitize.IsIdentifyingHeader(name) && !strings.EqualFold(name, "X-Request-ID") {
+			t.Fatalf("header %s was not stripped", name)
+		}
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("X-Request-ID is missing, want the gateway request ID")
+	}
+
+	var ids []string
+	for line := range strings.Lines(body) {
+		payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok || payload == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			t.Fatalf("chunk %q is not JSON: %v", payload, err)
+		}
+		ids = append(ids, chunk.ID)
+	}
+	if len(ids) != 2 || ids[0] != ids[1] || !strings.HasPrefix(ids[0], sanitize.IDPrefix) {
+		t.Fatalf("chunk ids = %v, want one gateway-issued id on every chunk", ids)
+	}
+	mapping, ok := sanitizer.Lookup(ids[0])
+	if !ok || mapping.ProviderID != "chatcmpl-upstream42" || mapping.RequestID != rec.Header().Get("X-Request-ID") {
+		t.Fatalf("Lookup(%q) = %+v, %v; want the upstream id and request id", ids[0], mapping, ok)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 || logger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %d, want 1 with data", len(logger.entries))
+	}
+	if got := logger.entries[0].Data.ResponseHeaders["Openai-Organization"]; got != "acme-org" {
+		t.Fatalf("audit Openai-Organization = %q, want the original header kept", got)
+	}
+}
+
+func TestResponseSanitization_RewritesJSONBody(t *testing.T) {
+	mock := provenanceChatMock()
+	mock.response.SystemFingerprint = "fp_abc123"
+	sanitizer := sanitize.New(config.ResponseSanitizationConfig{Enabled: true})
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	srv := New(mock, &Config{AuditLogger: logger, ResponseSanitization: sanitizer})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var got core.ChatResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if !strings.HasPrefix(got.ID, sanitize.IDPrefix) || got.SystemFingerprint != "" {
+		t.Fatalf("id = %q, system_fingerprint = %q; want a gateway id and no fingerprint", got.ID, got.SystemFingerprint)
+	}
+	if len(got.Choices) != 1 || got.Choices[0].Message.Content != "hello" {
+		t.Fatalf("choices = %+v, want the content kept", got.Choices)
+	}
+	if mapping, ok := sanitizer.Lookup(got.ID); !ok || mapping.ProviderID != "chatcmpl-1" || mapping.Provider != "mock" {
+		t.Fatalf("Lookup(%q) = %+v, %v; want chatcmpl-1 served by mock", got.ID, mapping, ok)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logged, err := json.Marshal(logger.entries[0].Data.ResponseBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(logged), "chatcmpl-1") || !strings.Contains(string(logged), "fp_abc123") {
+		t.Fatalf("audit response body = %s, want the original id and fingerprint", logged)
+	}
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/responsetransform"
+)
+
+// responseTransformStreamKey holds the *responsetransform.ChatStream of a
+// rewritten stream, so the stream's audit entry can record what it did.
+const responseTransformStreamKey = "response_transform_stream"
+
+// ResponseTransforms rewrites the text of chat completion and Responses API
+// responses with the first pipeline matching the resolved model and provider.
+// Successful JSON bodies are held until the handler returns and rewritten
+// whole; chat completion streams are rewritten line by line through the
+// pipeline's window. It runs after workflow resolution and inside audit
+// logging, which records the pipeline and its replacement counts but keeps
+// the response as the provider returned it, and inside provenance, so
+// signatures cover what the client receives. It is only installed when
+// pipelines are configured.
+func ResponseTransforms(service *responsetransform.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			operation := core.DescribeEndpoint(req.Method, req.URL.Path).Operation
+			if req.Method != http.MethodPost || (operation != core.OperationChatCompletions && operation != core.OperationResponses) {
+				return next(c)
+			}
+			pipeline, ok := service.Match(responseTransformTarget(c))
+			if !ok {
+				return next(c)
+			}
+			writer := &responseTransformWriter{
+				ResponseWriter: c.Response(),
+				c:              c,
+				pipeline:       pipeline,
+				streams:        operation == core.OperationChatCompletions,
+			}
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err := next(c)
+			if finishErr := writer.finish(); err == nil {
+				err = finishErr
+			}
+			return err
+		}
+	}
+}
+
+// responseTransformTarget names the request by its requested and resolved
+// models and its provider, like fault injection rules match it.
+func responseTransformTarget(c *echo.Context) responsetransform.Target {
+	target := chaosTarget(c)
+	return responsetransform.Target{Models: target.Models, Providers: target.Providers}
+}
+
+type responseTransformMode int
+
+const (
+	responseTransformWritten responseTransformMode = iota
+	responseTransformHeldBody
+	responseTransformFilteredStream
+)
+
+// responseTransformWriter holds successful JSON bodies until the handler
+// returns and rewrites chat completion streams as they are written.
+type responseTransformWriter struct {
+	http.ResponseWriter
+	c        *echo.Context
+	pipeline *responsetransform.Pipeline
+	streams  bool
+
+	wroteHeader bool
+	mode        responseTransformMode
+	status      int
+	stream      *responsetransform.ChatStream
+	buf         bytes.Buffer
+}
+
+func (w *responseTransformWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	header := w.Header()
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch {
+	case code != http.StatusOK || strings.TrimSpace(header.Get("Content-Encoding")) != "":
+	case mediaType == "application/json":
+		w.mode = responseTransformHeldBody
+		w.status = code
+		return
+	case mediaType == "text/event-stream" && w.streams:
+		w.mode = responseTransformFilteredStream
+		w.stream = w.pipeline.NewChatStream()
+		w.c.Set(responseTransformStreamKey, w.stream)
+		header.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseTransformWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	switch w.mode {
+	case responseTransformHeldBody:
+		return w.buf.Write(b)
+	case responseTransformFilteredStream:
+		w.buf.Write(b)
+		if err := w.writeLines(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *responseTransformWriter) Flush() {
+	if w.mode == responseTransformHeldBody {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseTransformWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeLines writes the complete stream lines buffered so far, rewritten.
+// With final set, a trailing partial line and the text still held back are
+// written too.
+func (w *responseTransformWriter) writeLines(final bool) error {
+	data := w.buf.Bytes()
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if final {
+		end = len(data)
+	}
+	out := make([]byte, 0, end)
+	for line := range bytes.Lines(data[:end]) {
+		out = append(out, w.stream.RewriteLine(line)...)
+	}
+	w.buf.Next(end)
+	if final {
+		out = append(out, w.stream.Finish()...)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(out)
+	return err
+}
+
+// finish writes a held body with its text rewritten, or the rest of a
+// rewritten stream.
+func (w *responseTransformWriter) finish() error {
+	switch w.mode {
+	case responseTransformFilteredStream:
+		err := w.writeLines(true)
+		// Cached stream replays are audited on the request's own entry;
+		// live streams record the transforms on their stream entry.
+		auditlog.EnrichEntryWithResponseTransforms(w.c, responseTransformSnapshot(w.pipeline, w.stream.Applied()), nil)
+		return err
+	case responseTransformHeldBody:
+	default:
+		return nil
+	}
+
+	original := w.buf.Bytes()
+	body, applied, ok := w.pipeline.RewriteBody(original)
+	if ok {
+		auditlog.EnrichEntryWithResponseTransforms(w.c, responseTransformSnapshot(w.pipeline, applied), original)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
+
+// recordStreamResponseTransforms records the transforms applied to a live
+// stream on its audit entry. It must run after the stream was written and
+// before the entry is.
+func recordStreamResponseTransforms(c *echo.Context, streamEntry *auditlog.LogEntry) {
+	stream, ok := c.Get(responseTransformStreamKey).(*responsetransform.ChatStream)
+	if !ok || streamEntry == nil {
+		return
+	}
+	auditlog.EnrichLogEntryWithResponseTransforms(streamEntry, responseTransformSnapshot(stream.Pipeline(), stream.Applied()))
+}
+
+func responseTransformSnapshot(pipeline *responsetransform.Pipeline, applied []responsetransform.Applied) auditlog.ResponseTransformSnapshot {
+	snapshot := auditlog.ResponseTransformSnapshot{
+		Pipeline:     pipeline.Name,
+		Transformers: make([]auditlog.ResponseTransformerSnapshot, len(applied)),
+	}
+	for i, a := range applied {
+		snapshot.Transformers[i] = auditlog.ResponseTransformerSnapshot{Name: a.Name, Replacements: a.Replacements}
+	}
+	return snapshot
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/responsetransform"
+)
+
+func newResponseTransformTestServer(t *testing.T, mock *mockProvider, pipelines ...config.ResponseTransformPipelineConfig) (*Server, *syncAuditLogger) {
+	t.Helper()
+	service, err := responsetransform.New(config.ResponseTransformsConfig{StreamWindow: 32, Pipelines: pipelines})
+	if err != nil {
+		t.Fatalf("responsetransform.New() error = %v", err)
+	}
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	return New(mock, &Config{AuditLogger: logger, ResponseTransforms: service}), logger
+}
+
+func publicNamesPipeline() config.ResponseTransformPipelineConfig {
+	return config.ResponseTransformPipelineConfig{
+		Name:  "public-names",
+		Model: "gpt-4o-mini",
+		Transformers: []config.ResponseTransformerConfig{
+			{Type: "strip_xml_tags", Tags: []string{"think"}},
+			{Name: "codename", Type: "regex_replace", Pattern: `Project Falcon`, Replacement: "Atlas"},
+		},
+	}
+}
+
+func lastAuditEntry(t *testing.T, logger *syncAuditLogger) *auditlog.LogEntry {
+	t.Helper()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) == 0 {
+		t.Fatal("no audit entry written")
+	}
+	return logger.entries[len(logger.entries)-1]
+}
+
+func TestResponseTransforms_RewritesBodyAndAuditsOriginal(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{
+		ID:     "chatcmpl-1",
+		Object: "chat.completion",
+		Model:  "gpt-4o-mini",
+		Choices: []core.Choice{{
+			Message:      core.ResponseMessage{Role: "assistant", Content: "<think>internal notes</think>Project Falcon is ready."},
+			FinishReason: "stop",
+		}},
+	}}
+	srv, logger := newResponseTransformTestServer(t, mock, publicNamesPipeline())
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var resp core.ChatResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "Atlas is ready." {
+		t.Fatalf("content = %q, want the transformed text", got)
+	}
+
+	entry := lastAuditEntry(t, logger)
+	snapshot := entry.Data.ResponseTransforms
+	if snapshot == nil || snapshot.Pipeline != "public-names" || len(snapshot.Transformers) != 2 ||
+		snapshot.Transformers[0] != (auditlog.ResponseTransformerSnapshot{Name: "strip_xml_tags", Replacements: 1}) ||
+		snapshot.Transformers[1] != (auditlog.ResponseTransformerSnapshot{Name: "codename", Replacements: 1}) {
+		t.Fatalf("audit response_transforms = %+v", snapshot)
+	}
+	logged, _ := json.Marshal(entry.Data.ResponseBody)
+	if !strings.Contains(string(logged), "Project Falcon") || !strings.Contains(string(logged), "internal notes") {
+		t.Fatalf("audit response body = %s, want the original response", logged)
+	}
+}
+
+func TestResponseTransforms_RewritesStreamAcrossChunks(t *testing.T) {
+	streamData := strings.Join([]string{
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Meet Proj"},"finish_reason":null}]}`,
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"ect Falcon."},"finish_reason":null}]}`,
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: streamData}
+	srv, logger := newResponseTransformTestServer(t, mock, publicNamesPipeline())
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var text strings.Builder
+	for line := range strings.Lines(rec.Body.String()) {
+		payload, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok || payload == "[DONE]" {
+			continue
+		}
+		if !gjson.Valid(payload) {
+			t.Fatalf("invalid chunk %q", payload)
+		}
+		text.WriteString(gjson.Get(payload, "choices.0.delta.content").String())
+	}
+	if text.String() != "Meet Atlas." {
+		t.Fatalf("streamed text = %q\n%s", text.String(), rec.Body.String())
+	}
+
+	snapshot := lastAuditEntry(t, logger).Data.ResponseTransforms
+	if snapshot == nil || snapshot.Transformers[1].Replacements != 1 {
+		t.Fatalf("audit response_transforms = %+v", snapshot)
+	}
+}
+
+func TestResponseTransforms_UnmatchedModelUntouched(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{
+		ID:      "chatcmpl-1",
+		Object:  "chat.completion",
+		Model:   "gpt-4o-mini",
+		Choices: []core.Choice{{Message: core.ResponseMessage{Role: "assistant", Content: "Project Falcon"}}},
+	}}
+	pipeline := publicNamesPipeline()
+	pipeline.Model = "gpt-4o"
+	srv, logger := newResponseTransformTestServer(t, mock, pipeline)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if !strings.Contains(rec.Body.String(), "Project Falcon") {
+		t.Fatalf("body = %s, want it untouched", rec.Body.String())
+	}
+	if snapshot := lastAuditEntry(t, logger).Data.ResponseTransforms; snapshot != nil {
+		t.Fatalf("audit response_transforms = %+v, want none", snapshot)
+	}
+}
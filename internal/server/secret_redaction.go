@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/compression"
+	"gomodel/internal/core"
+	"gomodel/internal/observability"
+	"gomodel/internal/secretscan"
+)
+
+// SecretRedaction scans chat completion and Responses API requests for
+// secrets users pasted into their prompts, and masks them in the request
+// body before the request snapshot is captured, so neither the audit log nor
+// the provider ever sees them. The detections, never the matched text, are
+// logged, counted in metrics and kept in the request context for the audit
+// entry. Blocking is left to SecretRedactionEnforcement, which runs after
+// audit logging so blocked requests are still recorded. It is only installed
+// when secret redaction is enabled.
+//
+// Scanned bodies are read into memory in full, ahead of LargeRequestBodies,
+// so they are bounded by maxBodySize rather than streamed. A body sent with a
+// Content-Encoding is decoded, up to maxBodySize decoded bytes, and forwarded
+// decoded; one in an encoding that cannot be decoded is rejected, as it could
+// not be scanned.
+func SecretRedaction(scanner *secretscan.Scanner, maxBodySize int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if req.Method != http.MethodPost || req.Body == nil || !scanner.Applies(req.URL.Path) {
+				return next(c)
+			}
+
+			body, err := readScannedBody(req, maxBodySize)
+			if err != nil {
+				return handleError(c, requestBodyReadError("failed to read request body", err))
+			}
+			req.Header.Del("Content-Encoding")
+			redacted, result := scanner.ScanBody(req.URL.Path, body)
+			if redacted != nil {
+				body = redacted
+			}
+			req.ContentLength = int64(len(body))
+			req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			if result == nil {
+				c.SetRequest(req)
+				return next(c)
+			}
+
+			detectors := make([]string, 0, len(result.Detections))
+			for _, d := range result.Detections {
+				detectors = append(detectors, d.Detector)
+				observability.SecretDetections.WithLabelValues(d.Detector, d.Action).Add(float64(d.Count))
+			}
+			serverLogger.Warn("secrets detected in request",
+				"detectors", strings.Join(detectors, ","),
+				"blocked", result.Blocked,
+				"path", req.URL.Path,
+				"request_id", requestIDFromContextOrHeader(req),
+			)
+			c.SetRequest(req.WithContext(core.WithSecretRedaction(req.Context(), result)))
+			return next(c)
+		}
+	}
+}
+
+// readScannedBody reads the request body, decoding its Content-Encoding, and
+// fails with a 413 once more than maxBodySize decoded bytes are read.
+func readScannedBody(req *http.Request, maxBodySize int64) ([]byte, error) {
+	var body io.Reader = req.Body
+	contentEncoding := req.Header.Get("Content-Encoding")
+	if encoding := compression.ParseEncoding(contentEncoding); encoding != "" {
+		if !compression.Supported(encoding) || strings.Contains(contentEncoding, ",") {
+			return nil, core.NewInvalidRequestErrorWithStatus(
+				http.StatusUnsupportedMediaType,
+				fmt.Sprintf("request body with Content-Encoding %q cannot be scanned for secrets", contentEncoding),
+				nil,
+			)
+		}
+		decoded, err := compression.NewReader(req.Body, encoding)
+		if err != nil {
+			return nil, err
+		}
+		defer decoded.Close()
+		body = decoded
+	}
+	if maxBodySize <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBodySize {
+		return nil, requestBodyTooLargeError(maxBodySize)
+	}
+	return data, nil
+}
+
+// SecretRedactionEnforcement rejects the requests in which SecretRedaction
+// found a secret whose detector blocks. It runs after audit logging and auth,
+// so the rejection is audited with the masked body.
+func SecretRedactionEnforcement() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			result := core.GetSecretRedaction(c.Request().Context())
+			if result == nil || !result.Blocked {
+				return next(c)
+			}
+			var blocking []string
+			for _, d := range result.Detections {
+				if d.Action == secretscan.ActionBlock {
+					blocking = append(blocking, d.Detector)
+				}
+			}
+			return handleError(c, core.NewInvalidRequestError(
+				"request contains secrets ("+strings.Join(blocking, ", ")+"); remove them and retry",
+				nil,
+			).WithCode(core.SecretDetectedCode))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/secretscan"
+)
+
+const secretRedactionTestKey = "sk-proj-Abcdefghijklmnopqrstuvwx0123"
+
+type secretCaptureProvider struct {
+	mockProvider
+	chat *core.ChatRequest
+}
+
+func (p *secretCaptureProvider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	p.chat = req
+	return p.mockProvider.ChatCompletion(ctx, req)
+}
+
+func newSecretRedactionTestServer(t *testing.T, cfg config.SecretRedactionConfig) (*Server, *secretCaptureProvider, *syncAuditLogger) {
+	t.Helper()
+	cfg.Enabled = true
+	scanner, err := secretscan.New(cfg)
+	if err != nil {
+		t.Fatalf("secretscan.New() error = %v", err)
+	}
+	provider := &secretCaptureProvider{mockProvider: mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response:        &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"},
+	}}
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	return New(provider, &Config{AuditLogger: logger, SecretRedaction: scanner}), provider, logger
+}
+
+func lastSecretRedactionEntry(t *testing.T, logger *syncAuditLogger) *auditlog.LogEntry {
+	t.Helper()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) == 0 {
+		t.Fatal("no audit entry written")
+	}
+	return logger.entries[len(logger.entries)-1]
+}
+
+func TestSecretRedaction_MasksBeforeProviderAndAudit(t *testing.T) {
+	srv, provider, logger := newSecretRedactionTestServer(t, config.SecretRedactionConfig{})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"my key is `+secretRedactionTestKey+`, why does it fail?"}]}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if provider.chat == nil || provider.chat.Messages[0].Content != "my key is [REDACTED:openai_api_key], why does it fail?" {
+		t.Fatalf("provider request = %+v, want the key masked", provider.chat)
+	}
+
+	entry := lastSecretRedactionEntry(t, logger)
+	captured, _ := json.Marshal(entry.Data.RequestBody)
+	if strings.Contains(string(captured), secretRedactionTestKey) || !strings.Contains(string(captured), "[REDACTED:openai_api_key]") {
+		t.Fatalf("audit request body = %s, want the key masked", captured)
+	}
+	want := core.SecretDetection{Detector: "openai_api_key", Action: secretscan.ActionMask, Count: 1}
+	if got := entry.Data.SecretRedaction; got == nil || got.Blocked || len(got.Detections) != 1 || got.Detections[0] != want {
+		t.Fatalf("audit secret_redaction = %+v, want %+v", got, want)
+	}
+}
+
+func TestSecretRedaction_BlockRejectsAndAudits(t *testing.T) {
+	srv, provider, logger := newSecretRedactionTestServer(t, config.SecretRedactionConfig{
+		Detectors: map[string]string{"openai_api_key": "block"},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"use `+secretRedactionTestKey+`"}]}`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if code, _ := body["error"]["code"].(string); code != core.SecretDetectedCode {
+		t.Fatalf("error = %v, want code %s", body["error"], core.SecretDetectedCode)
+	}
+	if strings.Contains(rec.Body.String(), secretRedactionTestKey) {
+		t.Fatalf("error echoes the secret: %s", rec.Body.String())
+	}
+	if provider.chat != nil {
+		t.Fatal("blocked request reached the provider")
+	}
+
+	entry := lastSecretRedactionEntry(t, logger)
+	captured, _ := json.Marshal(entry.Data.RequestBody)
+	if entry.StatusCode != http.StatusBadRequest || strings.Contains(string(captured), secretRedactionTestKey) {
+		t.Fatalf("audit entry status = %d, body = %s; want 400 without the key", entry.StatusCode, captured)
+	}
+	if got := entry.Data.SecretRedaction; got == nil || !got.Blocked {
+		t.Fatalf("audit secret_redaction = %+v, want blocked", got)
+	}
+}
+
+func TestSecretRedaction_ScansGzipBody(t *testing.T) {
+	srv, provider, _ := newSecretRedactionTestServer(t, config.SecretRedactionConfig{})
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write([]byte(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"key ` + secretRedactionTestKey + `"}]}`))
+	_ = zw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", &compressed)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if provider.chat == nil || provider.chat.Messages[0].Content != "key [REDACTED:openai_api_key]" {
+		t.Fatalf("provider request = %+v, want the key masked", provider.chat)
+	}
+}
+
+func TestSecretRedaction_RejectsUndecodableEncoding(t *testing.T) {
+	srv, provider, _ := newSecretRedactionTestServer(t, config.SecretRedactionConfig{})
+
+	req := selfProtectionRequest(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"key ` + secretRedactionTestKey + `"}]}`)
+	req.Header.Set("Content-Encoding", "compress")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("status = %d, want 415; body = %s", rec.Code, rec.Body.String())
+	}
+	if provider.chat != nil {
+		t.Fatal("unscanned request reached the provider")
+	}
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"io"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/selfprotect"
+)
+
+// SelfProtection screens model interaction requests for content that points
+// a tool-using client at the gateway's admin API or internal hosts. It runs
+// after auth so unauthenticated probes never cost a scan. The whole body is
+// read, up to maxBodySize, whatever its length or Content-Length; a body that
+// cannot be read is rejected rather than forwarded unscanned. Matches are
+// audited, logged and sent to the webhook; the reject action answers with a
+// 400. It is only installed when self-protection is enabled.
+func SelfProtection(guard *selfprotect.Guard, maxBodySize int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !core.IsModelInteractionPath(req.URL.Path) {
+				return next(c)
+			}
+			body, err := selfProtectionBody(c, maxBodySize)
+			if err != nil {
+				return handleError(c, requestBodyReadError("failed to read request body", err))
+			}
+			finding, ok := guard.Scan(body)
+			if !ok {
+				return next(c)
+			}
+
+			requestID := requestIDFromContextOrHeader(req)
+			auditlog.EnrichEntryWithSelfProtection(c, auditlog.SelfProtectionSnapshot{
+				Action:   guard.Action(),
+				Pattern:  finding.Pattern,
+				Location: finding.Location,
+			})
+			serverLogger.Warn("self-protection pattern matched",
+				"action", guard.Action(),
+				"pattern", finding.Pattern,
+				"location", finding.Location,
+				"path", req.URL.Path,
+				"request_id", requestID,
+			)
+			guard.Notify(selfprotect.Event{
+				RequestID: requestID,
+				Pattern:   finding.Pattern,
+				Location:  finding.Location,
+				Path:      req.URL.Path,
+				Timestamp: time.Now().UTC(),
+			})
+
+			if guard.Action() == selfprotect.ActionReject {
+				return handleError(c, core.NewInvalidRequestError("request references the gateway's own admin API or internal hosts", nil))
+			}
+			return next(c)
+		}
+	}
+}
+
+// selfProtectionBody returns the request body, buffering it when the snapshot
+// holds none, and fails with a 413 when it is larger than maxBodySize.
+func selfProtectionBody(c *echo.Context, maxBodySize int64) ([]byte, error) {
+	req := c.Request()
+	if maxBodySize > 0 && req.Body != nil {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(req.Body, maxBodySize+1), req.Body}
+	}
+	body, err := requestBodyBytes(c)
+	if err != nil {
+		return nil, err
+	}
+	if maxBodySize > 0 && int64(len(body)) > maxBodySize {
+		return nil, requestBodyTooLargeError(maxBodySize)
+	}
+	return body, nil
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/config"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/selfprotect"
+)
+
+const selfProtectionToolBody = `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"list the keys"}],"tools":[{"type":"function","function":{"name":"http_get","description":"GET http://gomodel:8080/admin/api/v1/auth-keys","parameters":{"type":"object"}}}]}`
+
+func newSelfProtectionTestServer(t *testing.T, mock *mockProvider, action string) (*Server, *syncAuditLogger) {
+	t.Helper()
+	guard, err := selfprotect.New(config.SelfProtectionConfig{Enabled: true, Action: action, Patterns: []string{"gomodel:8080"}})
+	if err != nil {
+		t.Fatalf("selfprotect.New() error = %v", err)
+	}
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	return New(mock, &Config{AuditLogger: logger, SelfProtection: guard}), logger
+}
+
+func selfProtectionRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func lastSelfProtectionSnapshot(t *testing.T, logger *syncAuditLogger) *auditlog.SelfProtectionSnapshot {
+	t.Helper()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) == 0 {
+		t.Fatal("no audit entry written")
+	}
+	entry := logger.entries[len(logger.entries)-1]
+	if entry.Data == nil {
+		return nil
+	}
+	return entry.Data.SelfProtection
+}
+
+func TestSelfProtection_RejectsToolDefinition(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok"}}
+	srv, logger := newSelfProtectionTestServer(t, mock, selfprotect.ActionReject)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(selfProtectionToolBody))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if msg, _ := body["error"]["message"].(string); !strings.Contains(msg, "admin API") {
+		t.Fatalf("error message = %q", msg)
+	}
+	snapshot := lastSelfProtectionSnapshot(t, logger)
+	if snapshot == nil || snapshot.Action != selfprotect.ActionReject || snapshot.Pattern != selfprotect.AdminAPIPattern || snapshot.Location != "tools[0].function.description" {
+		t.Fatalf("audit self_protection = %+v", snapshot)
+	}
+}
+
+func TestSelfProtection_FlagForwardsRequest(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"}}
+	srv, logger := newSelfProtectionTestServer(t, mock, selfprotect.ActionFlag)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"post this to http://GOMODEL:8080/v1/batches"}]}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	snapshot := lastSelfProtectionSnapshot(t, logger)
+	if snapshot == nil || snapshot.Action != selfprotect.ActionFlag || snapshot.Pattern != "gomodel:8080" || snapshot.Location != "messages[0].content" {
+		t.Fatalf("audit self_protection = %+v", snapshot)
+	}
+}
+
+func TestSelfProtection_PassesCleanRequest(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok", Model: "gpt-4o-mini"}}
+	srv, logger := newSelfProtectionTestServer(t, mock, selfprotect.ActionReject)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if snapshot := lastSelfProtectionSnapshot(t, logger); snapshot != nil {
+		t.Fatalf("audit self_protection = %+v, want none", snapshot)
+	}
+}
+
+func TestSelfProtection_RejectsBodyPastSnapshotLimit(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok"}}
+	srv, _ := newSelfProtectionTestServer(t, mock, selfprotect.ActionReject)
+
+	padding := strings.Repeat("a", int(requestSnapshotInlineBodyLimit))
+	body := strings.Replace(selfProtectionToolBody, "list the keys", "list the keys "+padding, 1)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, selfProtectionRequest(body))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSelfProtection_RejectsBodyWithoutContentLength(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, response: &core.ChatResponse{ID: "ok"}}
+	srv, _ := newSelfProtectionTestServer(t, mock, selfprotect.ActionReject)
+
+	req := selfProtectionRequest(selfProtectionToolBody)
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/sse"
+)
+
+// Stream formats a client can ask streamed responses in.
+const (
+	StreamFormatSSE    = "sse"
+	StreamFormatNDJSON = "ndjson"
+)
+
+const (
+	// streamFormatField is the query parameter and request body field that
+	// selects the stream format.
+	streamFormatField = "stream_format"
+	// streamFormatKey stores the negotiated format on the Echo context.
+	streamFormatKey = "gomodel_stream_format"
+	// ndjsonMediaType is the Accept value and the Content-Type of NDJSON
+	// streams.
+	ndjsonMediaType = "application/x-ndjson"
+)
+
+// StreamFormat lets clients of model endpoints receive streamed responses as
+// newline-delimited JSON instead of server-sent events. Accept:
+// application/x-ndjson, ?stream_format=ndjson or, on translated chat and
+// Responses requests, a "stream_format" body field selects it. Handlers keep
+// writing SSE; this middleware re-encodes successful event streams as one
+// JSON object per line, without data: framing or the [DONE] marker, so the
+// end of the stream is the end of the response. It sits outside every other
+// response rewriter, which all see SSE, and the negotiation is never sent
+// upstream.
+func StreamFormat() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !core.IsModelInteractionPath(req.URL.Path) {
+				return next(c)
+			}
+			format, err := requestedStreamFormat(req)
+			if err != nil {
+				return handleError(c, err)
+			}
+			if format != "" {
+				c.Set(streamFormatKey, format)
+			}
+
+			writer := &ndjsonStreamWriter{ResponseWriter: c.Response(), c: c}
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err = next(c)
+			if finishErr := writer.finish(); err == nil {
+				err = finishErr
+			}
+			return err
+		}
+	}
+}
+
+// requestedStreamFormat reads the stream format from the query, which wins,
+// or the Accept header, and removes both so they are not forwarded upstream.
+func requestedStreamFormat(req *http.Request) (string, error) {
+	if !strings.Contains(req.URL.RawQuery, streamFormatField) {
+		return requestedStreamFormatFromAccept(req), nil
+	}
+	query := req.URL.Query()
+	if query.Has(streamFormatField) {
+		format, err := parseStreamFormat(query.Get(streamFormatField))
+		if err != nil {
+			return "", err
+		}
+		query.Del(streamFormatField)
+		req.URL.RawQuery = query.Encode()
+		return format, nil
+	}
+	return requestedStreamFormatFromAccept(req), nil
+}
+
+// requestedStreamFormatFromAccept returns StreamFormatNDJSON when the Accept
+// header asks for NDJSON, removing the header.
+func requestedStreamFormatFromAccept(req *http.Request) string {
+	for _, accept := range req.Header.Values("Accept") {
+		for mediaRange := range strings.SplitSeq(accept, ",") {
+			mediaType, _, _ := mime.ParseMediaType(mediaRange)
+			if mediaType == ndjsonMediaType {
+				req.Header.Del("Accept")
+				return StreamFormatNDJSON
+			}
+		}
+	}
+	return ""
+}
+
+func parseStreamFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case StreamFormatSSE, StreamFormatNDJSON:
+		return format, nil
+	default:
+		return "", core.NewInvalidRequestError(`stream_format must be "sse" or "ndjson"`, nil).WithParam(streamFormatField)
+	}
+}
+
+// applyStreamFormat takes the stream_format field out of a translated request
+// body, so it never reaches the provider, and stores the format it selects.
+func applyStreamFormat(c *echo.Context, req any) error {
+	var fields *core.UnknownJSONFields
+	switch typed := req.(type) {
+	case *core.ChatRequest:
+		if typed != nil {
+			fields = &typed.ExtraFields
+		}
+	case *core.ResponsesRequest:
+		if typed != nil {
+			fields = &typed.ExtraFields
+		}
+	}
+	if fields == nil {
+		return nil
+	}
+	raw := fields.Lookup(streamFormatField)
+	if raw == nil {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return core.NewInvalidRequestError(`stream_format must be "sse" or "ndjson"`, err).WithParam(streamFormatField)
+	}
+	format, err := parseStreamFormat(value)
+	if err != nil {
+		return err
+	}
+	*fields = fields.Without(streamFormatField)
+	c.Set(streamFormatKey, format)
+	return nil
+}
+
+// negotiatedStreamFormat returns the stream format the client asked for,
+// StreamFormatSSE by default.
+func negotiatedStreamFormat(c *echo.Context) string {
+	if format, ok := c.Get(streamFormatKey).(string); ok && format != "" {
+		return format
+	}
+	return StreamFormatSSE
+}
+
+// recordStreamFormat notes an NDJSON stream on the audit entry. Audit capture
+// reads the stream as SSE before it is re-encoded, so only the format the
+// client received needs recording.
+func recordStreamFormat(c *echo.Context) {
+	if format := negotiatedStreamFormat(c); format != StreamFormatSSE {
+		auditlog.EnrichEntryWithStreamFormat(c, format)
+	}
+}
+
+// streamErrorEvent is the final event written when an upstream stream fails
+// after it started.
+var streamErrorEvent = []byte("event: error\ndata: {\"error\":{\"message\":\"upstream stream ended abnormally\",\"type\":\"server_error\",\"code\":\"stream_error\"}}\n\n")
+
+// finishFailedStream ends an NDJSON stream whose upstream failed with a final
+// error object, since NDJSON has no [DONE] marker whose absence would tell
+// the client. SSE streams and streams already ended by the stall policy are
+// left as they are.
+func finishFailedStream(c *echo.Context, err error) {
+	if err == nil || errors.Is(err, errStreamStalled) || errors.Is(err, errStreamLimitExceeded) || negotiatedStreamFormat(c) != StreamFormatNDJSON {
+		return
+	}
+	if _, writeErr := c.Response().Write(streamErrorEvent); writeErr != nil {
+		return
+	}
+	if flusher, ok := c.Response().(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ndjsonStreamWriter re-encodes a successful event stream as NDJSON when the
+// client negotiated it. Every other response passes through unchanged.
+type ndjsonStreamWriter struct {
+	http.ResponseWriter
+	c *echo.Context
+
+	wroteHeader bool
+	// encoder is set once the response turns out to be a negotiated NDJSON
+	// stream, so other responses pay for nothing but the wrapper.
+	encoder *ndjsonEncoder
+}
+
+type ndjsonEncoder struct {
+	parser sse.Parser
+	out    []byte
+}
+
+func (w *ndjsonStreamWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK && negotiatedStreamFormat(w.c) == StreamFormatNDJSON && isEventStream(w.Header()) {
+		header := w.Header()
+		w.encoder = &ndjsonEncoder{}
+		header.Set("Content-Type", ndjsonMediaType)
+		header.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// isEventStream reports whether header describes an uncompressed event stream.
+func isEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream" && strings.TrimSpace(header.Get("Content-Encoding")) == ""
+}
+
+func (w *ndjsonStreamWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	if w.encoder == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	w.encoder.parser.Feed(b, w.encoder.appendEvent)
+	if err := w.writeOut(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *ndjsonStreamWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *ndjsonStreamWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes the last event of a stream that did not end with a blank
+// line.
+func (w *ndjsonStreamWriter) finish() error {
+	if w.encoder == nil {
+		return nil
+	}
+	w.encoder.parser.Flush(w.encoder.appendEvent)
+	return w.writeOut()
+}
+
+func (w *ndjsonStreamWriter) writeOut() error {
+	if len(w.encoder.out) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.encoder.out)
+	w.encoder.out = w.encoder.out[:0]
+	return err
+}
+
+func (e *ndjsonEncoder) appendEvent(event sse.Event) {
+	e.out = appendNDJSONLine(e.out, event)
+}
+
+// appendNDJSONLine appends the data of event to dst as one compact JSON line.
+// Empty events and [DONE] are dropped, data that is not JSON is carried as a
+// JSON string, and error events are wrapped in an "error" object unless
+// their data already has one.
+func appendNDJSONLine(dst []byte, event sse.Event) []byte {
+	data := bytes.TrimSpace(event.Data)
+	if len(data) == 0 || sse.IsDone(data) {
+		return dst
+	}
+	if !json.Valid(data) {
+		quoted, err := json.Marshal(string(data))
+		if err != nil {
+			return dst
+		}
+		data = quoted
+	}
+	if event.Type == "error" && !gjson.GetBytes(data, "error").Exists() {
+		data = append(append([]byte(`{"error":`), data...), '}')
+	}
+	var line bytes.Buffer
+	if err := json.Compact(&line, data); err != nil {
+		return dst
+	}
+	dst = append(dst, line.Bytes()...)
+	return append(dst, '\n')
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+)
+
+// streamFormatFixture is the upstream stream every framing test starts from.
+const streamFormatFixture = ": keepalive\n\n" +
+	`data: {"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n" +
+	`data: {"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}` + "\n\n" +
+	"data: [DONE]\n\n"
+
+const streamFormatNDJSONFixture = `{"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n" +
+	`{"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}` + "\n"
+
+// streamCapturingProvider records the chat request it was asked to stream.
+type streamCapturingProvider struct {
+	*mockProvider
+	streamed *core.ChatRequest
+}
+
+func (p *streamCapturingProvider) StreamChatCompletion(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
+	p.streamed = req
+	return p.mockProvider.StreamChatCompletion(ctx, req)
+}
+
+func newStreamFormatServer(streamData string, streamErr error) (*Server, *streamCapturingProvider, *syncAuditLogger) {
+	provider := &streamCapturingProvider{mockProvider: &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		streamData:      streamData,
+		streamErr:       streamErr,
+	}}
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	return New(provider, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger}), provider, auditLogger
+}
+
+func streamFormatRequest(target, body, accept string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	return req
+}
+
+const streamFormatChatBody = `{"model":"gpt-4o-mini","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+
+func TestStreamFormat_Framing(t *testing.T) {
+	tests := []struct {
+		name            string
+		target          string
+		body            string
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "default sse", target: "/v1/chat/completions", body: streamFormatChatBody, wantContentType: "text/event-stream", wantBody: streamFormatFixture},
+		{name: "accept sse", target: "/v1/chat/completions", body: streamFormatChatBody, accept: "text/event-stream", wantContentType: "text/event-stream", wantBody: streamFormatFixture},
+		{name: "accept ndjson", target: "/v1/chat/completions", body: streamFormatChatBody, accept: "application/x-ndjson", wantContentType: "application/x-ndjson", wantBody: streamFormatNDJSONFixture},
+		{name: "query ndjson", target: "/v1/chat/completions?stream_format=ndjson", body: streamFormatChatBody, wantContentType: "application/x-ndjson", wantBody: streamFormatNDJSONFixture},
+		{name: "query sse overrides accept", target: "/v1/chat/completions?stream_format=sse", body: streamFormatChatBody, accept: "application/x-ndjson", wantContentType: "text/event-stream", wantBody: streamFormatFixture},
+		{
+			name:            "body ndjson",
+			target:          "/v1/chat/completions",
+			body:            `{"model":"gpt-4o-mini","stream":true,"stream_format":"ndjson","messages":[{"role":"user","content":"hi"}]}`,
+			wantContentType: "application/x-ndjson",
+			wantBody:        streamFormatNDJSONFixture,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _, _ := newStreamFormatServer(streamFormatFixture, nil)
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, streamFormatRequest(tt.target, tt.body, tt.accept))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Fatalf("body =\n%q\nwant\n%q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStreamFormat_NDJSONErrors(t *testing.T) {
+	t.Run("upstream error event", func(t *testing.T) {
+		stream := `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n" +
+			"event: error\ndata: {\"error\":{\"message\":\"overloaded\",\ndata: \"type\":\"server_error\"}}\n\n" +
+			"event: error\ndata: upstream reset\n\n"
+		srv, _, _ := newStreamFormatServer(stream, nil)
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, streamFormatRequest("/v1/chat/completions", streamFormatChatBody, "application/x-ndjson"))
+
+		want := `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n" +
+			`{"error":{"message":"overloaded","type":"server_error"}}` + "\n" +
+			`{"error":"upstream reset"}` + "\n"
+		if got := rec.Body.String(); got != want {
+			t.Fatalf("body =\n%q\nwant\n%q", got, want)
+		}
+	})
+
+	t.Run("upstream read failure", func(t *testing.T) {
+		stream := `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"
+		for _, format := range []string{StreamFormatSSE, StreamFormatNDJSON} {
+			srv, _, _ := newStreamFormatServer(stream, errors.New("connection reset"))
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, streamFormatRequest("/v1/chat/completions?stream_format="+format, streamFormatChatBody, ""))
+
+			want := stream
+			if format == StreamFormatNDJSON {
+				want = `{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n" +
+					`{"error":{"message":"upstream stream ended abnormally","type":"server_error","code":"stream_error"}}` + "\n"
+			}
+			if got := rec.Body.String(); got != want {
+				t.Fatalf("%s body =\n%q\nwant\n%q", format, got, want)
+			}
+		}
+	})
+}
+
+func TestStreamFormat_BodyFieldIsNotForwarded(t *testing.T) {
+	srv, provider, auditLogger := newStreamFormatServer(streamFormatFixture, nil)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, streamFormatRequest("/v1/chat/completions",
+		`{"model":"gpt-4o-mini","stream":true,"stream_format":"ndjson","messages":[{"role":"user","content":"hi"}]}`, ""))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if provider.streamed == nil || provider.streamed.ExtraFields.Lookup("stream_format") != nil {
+		t.Fatalf("provider request = %+v, want stream_format removed", provider.streamed)
+	}
+
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Data == nil {
+		t.Fatalf("audit entries = %d, want one with data", len(auditLogger.entries))
+	}
+	data := auditLogger.entries[0].Data
+	if data.StreamFormat != StreamFormatNDJSON || data.ResponseBody == nil {
+		t.Fatalf("audit data = format %q, response body %v; want ndjson with the captured stream", data.StreamFormat, data.ResponseBody)
+	}
+}
+
+func TestStreamFormat_RejectsUnknownFormat(t *testing.T) {
+	for _, tt := range []struct {
+		target string
+		body   string
+	}{
+		{target: "/v1/chat/completions?stream_format=jsonl", body: streamFormatChatBody},
+		{target: "/v1/chat/completions", body: `{"model":"gpt-4o-mini","stream":true,"stream_format":1,"messages":[{"role":"user","content":"hi"}]}`},
+	} {
+		srv, provider, _ := newStreamFormatServer(streamFormatFixture, nil)
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, streamFormatRequest(tt.target, tt.body, ""))
+
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "stream_format") {
+			t.Fatalf("%s: response = %d %s, want a 400 naming stream_format", tt.target, rec.Code, rec.Body.String())
+		}
+		if provider.streamed != nil {
+			t.Fatalf("%s: request reached the provider", tt.target)
+		}
+	}
+}
+
+func TestStreamFormat_LeavesJSONResponsesAlone(t *testing.T) {
+	srv := New(&mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response:        &core.ChatResponse{ID: "chatcmpl-1", Model: "gpt-4o-mini"},
+	}, &Config{})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, streamFormatRequest("/v1/chat/completions", `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`, "application/x-ndjson"))
+
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") || !strings.Contains(rec.Body.String(), `"chatcmpl-1"`) {
+		t.Fatalf("response = %d %q %s, want the JSON completion", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/observability"
+)
+
+// StreamLimits caps the upstream bytes relayed by a streamed response and how
+// long it may run. Zero values disable a limit.
+type StreamLimits struct {
+	MaxBytes    int64
+	MaxDuration time.Duration
+	// Grace is how long after a stream starts no limit is enforced.
+	Grace time.Duration
+	// Models overrides MaxBytes and MaxDuration by model name; zero values
+	// keep the global limit.
+	Models map[string]StreamModelLimits
+}
+
+// StreamModelLimits overrides the stream limits of one model.
+type StreamModelLimits struct {
+	MaxBytes    int64
+	MaxDuration time.Duration
+}
+
+// Stream limits that can end a stream, as recorded in audit entries and
+// metrics.
+const (
+	streamLimitBytes    = "bytes"
+	streamLimitDuration = "duration"
+)
+
+// streamAbortKey holds the context.CancelFunc of a model request, set by the
+// request ID middleware, so a stream that ends early through a stream limit,
+// a stalled client or a failed write can abort its upstream call instead of
+// waiting on a silent provider.
+const streamAbortKey = "stream_abort"
+
+var errStreamLimitExceeded = errors.New("stream limit exceeded; stream terminated")
+
+// streamLimitEvents are the final SSE events sent to a client whose stream
+// was ended by a limit, followed by [DONE].
+var streamLimitEvents = map[string][]byte{
+	streamLimitBytes:    []byte("event: error\ndata: {\"error\":{\"message\":\"stream exceeded the maximum size\",\"type\":\"server_error\",\"code\":\"stream_limit_exceeded\"}}\n\ndata: [DONE]\n\n"),
+	streamLimitDuration: []byte("event: error\ndata: {\"error\":{\"message\":\"stream exceeded the maximum duration\",\"type\":\"server_error\",\"code\":\"stream_limit_exceeded\"}}\n\ndata: [DONE]\n\n"),
+}
+
+// streamLimit is the limit applied to one stream.
+type streamLimit struct {
+	maxBytes    int64
+	maxDuration time.Duration
+	grace       time.Duration
+}
+
+func (l StreamLimits) enabled() bool {
+	if l.MaxBytes > 0 || l.MaxDuration > 0 {
+		return true
+	}
+	for _, model := range l.Models {
+		if model.MaxBytes > 0 || model.MaxDuration > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// forRequest returns the backpressure settings for one stream, with the
+// stream limits of the request's model resolved.
+func (b StreamBackpressure) forRequest(c *echo.Context) StreamBackpressure {
+	if cancel, ok := c.Get(streamAbortKey).(context.CancelFunc); ok {
+		b.abort = cancel
+	}
+	limits := b.Limits
+	if !limits.enabled() {
+		return b
+	}
+	limit := streamLimit{maxBytes: limits.MaxBytes, maxDuration: limits.MaxDuration, grace: limits.Grace}
+	for _, model := range chaosTarget(c).Models {
+		override, ok := limits.Models[model]
+		if !ok {
+			continue
+		}
+		if override.MaxBytes > 0 {
+			limit.maxBytes = override.MaxBytes
+		}
+		if override.MaxDuration > 0 {
+			limit.maxDuration = override.MaxDuration
+		}
+		break
+	}
+	b.limit = limit
+	return b
+}
+
+// lastEventEnd returns the length of the longest prefix of chunk that ends
+// with a complete SSE event, or 0.
+func lastEventEnd(chunk []byte) int {
+	end := bytes.LastIndex(chunk, []byte("\n\n"))
+	if crlf := bytes.LastIndex(chunk, []byte("\r\n\r\n")); crlf >= 0 && crlf+4 > end+2 {
+		return crlf + 4
+	}
+	if end < 0 {
+		return 0
+	}
+	return end + 2
+}
+
+// recordStreamLimit meters a stream ended by a limit and records the cutoff
+// on its audit entry.
+func recordStreamLimit(c *echo.Context, streamEntry *auditlog.LogEntry, stats streamStats) {
+	if stats.LimitExceeded == "" {
+		return
+	}
+	observability.StreamLimitExceeded.WithLabelValues(c.Path(), stats.LimitExceeded).Inc()
+
+	if streamEntry == nil {
+		return
+	}
+	if streamEntry.Data == nil {
+		streamEntry.Data = &auditlog.LogData{}
+	}
+	streamEntry.Data.StreamLimit = &auditlog.StreamLimitSnapshot{
+		Limit:      stats.LimitExceeded,
+		Bytes:      stats.Bytes,
+		DurationMs: stats.Duration.Milliseconds(),
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/observability"
+)
+
+// infiniteStream is a synthetic upstream that never ends: every Read returns
+// one complete SSE event after interval.
+type infiniteStream struct {
+	event    []byte
+	interval time.Duration
+}
+
+func (s *infiniteStream) Read(p []byte) (int, error) {
+	time.Sleep(s.interval)
+	return copy(p, s.event), nil
+}
+
+// silentStream is an upstream that sends nothing until ctx is canceled.
+type silentStream struct {
+	ctx context.Context
+}
+
+func (s *silentStream) Read([]byte) (int, error) {
+	<-s.ctx.Done()
+	return 0, s.ctx.Err()
+}
+
+const limitTestEvent = "data: {\"choices\":[{\"delta\":{\"content\":\"tick\"}}]}\n\n"
+
+func limitedBackpressure(limit streamLimit) StreamBackpressure {
+	return StreamBackpressure{limit: limit}
+}
+
+func TestPumpStream_ByteLimitCutsInfiniteStream(t *testing.T) {
+	upstream := &infiniteStream{event: []byte(limitTestEvent)}
+	rec := httptest.NewRecorder()
+
+	maxBytes := int64(10*len(limitTestEvent) + 7)
+	stats, err := pumpStream(rec, upstream, limitedBackpressure(streamLimit{maxBytes: maxBytes}))
+	if !errors.Is(err, errStreamLimitExceeded) {
+		t.Fatalf("pumpStream() error = %v, want errStreamLimitExceeded", err)
+	}
+	if stats.LimitExceeded != streamLimitBytes || stats.Bytes != int64(10*len(limitTestEvent)) {
+		t.Fatalf("stats = %+v, want the bytes limit after 10 whole events", stats)
+	}
+	body := rec.Body.String()
+	if got := strings.Count(body, "tick"); got != 10 {
+		t.Fatalf("relayed %d events, want 10", got)
+	}
+	if !strings.HasSuffix(body, string(streamLimitEvents[streamLimitBytes])) || !strings.Contains(body, `"code":"stream_limit_exceeded"`) {
+		t.Fatalf("body does not end with the limit event and [DONE]:\n%s", body)
+	}
+}
+
+func TestPumpStream_DurationLimitCutsInfiniteStream(t *testing.T) {
+	upstream := &infiniteStream{event: []byte(limitTestEvent), interval: 5 * time.Millisecond}
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	stats, err := pumpStream(rec, upstream, limitedBackpressure(streamLimit{maxDuration: 100 * time.Millisecond}))
+	if !errors.Is(err, errStreamLimitExceeded) {
+		t.Fatalf("pumpStream() error = %v, want errStreamLimitExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("pumpStream() took %s, want it cut at the 100ms limit", elapsed)
+	}
+	if stats.LimitExceeded != streamLimitDuration || stats.Duration < 100*time.Millisecond || stats.Bytes == 0 {
+		t.Fatalf("stats = %+v, want the duration limit after some bytes", stats)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "data: [DONE]\n\n") || !strings.Contains(rec.Body.String(), "maximum duration") {
+		t.Fatalf("body does not end with the duration limit event:\n%s", rec.Body.String())
+	}
+}
+
+func TestPumpStream_DurationLimitAbortsSilentUpstream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := httptest.NewRecorder()
+
+	cfg := limitedBackpressure(streamLimit{maxDuration: 50 * time.Millisecond})
+	cfg.abort = cancel
+	stats, err := pumpStream(rec, &silentStream{ctx: ctx}, cfg)
+	if !errors.Is(err, errStreamLimitExceeded) || stats.LimitExceeded != streamLimitDuration {
+		t.Fatalf("pumpStream() = %+v, %v; want the duration limit", stats, err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("upstream was not aborted")
+	}
+}
+
+func TestPumpStream_LimitsWaitForGrace(t *testing.T) {
+	upstream := &infiniteStream{event: []byte(limitTestEvent), interval: 5 * time.Millisecond}
+	rec := httptest.NewRecorder()
+
+	// Both limits are passed long before the grace ends; neither may fire
+	// until it does.
+	stats, err := pumpStream(rec, upstream, limitedBackpressure(streamLimit{
+		maxBytes:    int64(len(limitTestEvent)),
+		maxDuration: 10 * time.Millisecond,
+		grace:       150 * time.Millisecond,
+	}))
+	if !errors.Is(err, errStreamLimitExceeded) {
+		t.Fatalf("pumpStream() error = %v, want errStreamLimitExceeded", err)
+	}
+	if stats.Duration < 150*time.Millisecond || stats.Bytes <= int64(len(limitTestEvent)) {
+		t.Fatalf("stats = %+v, want a cutoff only after the 150ms grace", stats)
+	}
+}
+
+func TestPumpStream_EndsNormallyWithinLimits(t *testing.T) {
+	upstream := &chunkReader{chunks: sseChunks(3, 64)}
+	rec := httptest.NewRecorder()
+
+	stats, err := pumpStream(rec, upstream, limitedBackpressure(streamLimit{maxBytes: 1024, maxDuration: time.Minute}))
+	if err != nil || stats.LimitExceeded != "" || stats.Bytes != 192 {
+		t.Fatalf("pumpStream() = %+v, %v; want a clean 192-byte stream", stats, err)
+	}
+}
+
+func TestStreamBackpressure_ForRequestResolvesModelLimits(t *testing.T) {
+	b := StreamBackpressure{Limits: StreamLimits{
+		MaxBytes:    1000,
+		MaxDuration: time.Minute,
+		Grace:       time.Second,
+		Models:      map[string]StreamModelLimits{"gpt-4o-mini": {MaxDuration: time.Hour}},
+	}}
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req = req.WithContext(core.WithWorkflow(req.Context(), &core.Workflow{
+		Resolution: &core.RequestModelResolution{
+			Requested:        core.NewRequestedModelSelector("gpt-4o-mini", ""),
+			ResolvedSelector: core.ModelSelector{Model: "gpt-4o-mini"},
+		},
+	}))
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	got := b.forRequest(c).limit
+	if got.maxBytes != 1000 || got.maxDuration != time.Hour || got.grace != time.Second {
+		t.Fatalf("limit = %+v, want the global byte limit and the model's duration", got)
+	}
+	if limit := (StreamBackpressure{}).forRequest(c).limit; limit.maxBytes != 0 || limit.maxDuration != 0 {
+		t.Fatalf("limit without limits = %+v", limit)
+	}
+}
+
+func TestRecordStreamLimit_SetsAuditSnapshotAndMetrics(t *testing.T) {
+	observability.ResetMetrics()
+	t.Cleanup(observability.ResetMetrics)
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil), httptest.NewRecorder())
+	entry := &auditlog.LogEntry{}
+
+	recordStreamLimit(c, entry, streamStats{LimitExceeded: streamLimitBytes, Bytes: 4096, Duration: 1500 * time.Millisecond})
+
+	got := entry.Data.StreamLimit
+	if got == nil || *got != (auditlog.StreamLimitSnapshot{Limit: "bytes", Bytes: 4096, DurationMs: 1500}) {
+		t.Fatalf("StreamLimit = %+v", got)
+	}
+	if v := testutil.ToFloat64(observability.StreamLimitExceeded.WithLabelValues(c.Path(), "bytes")); v != 1 {
+		t.Fatalf("stream limit counter = %v, want 1", v)
+	}
+}
+
+func TestStreamLimits_CutsServedStreamAndAudits(t *testing.T) {
+	var events []string
+	for range 50 {
+		events = append(events, `data: {"id":"c1","object":"chat.completion.chunk","model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"tick"},"finish_reason":null}]}`)
+	}
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: strings.Join(append(events, "data: [DONE]"), "\n\n") + "\n\n"}
+	logger := &syncAuditLogger{config: auditlog.Config{Enabled: true, LogBodies: true}}
+	srv := New(mock, &Config{
+		AuditLogger: logger,
+		StreamBackpressure: StreamBackpressure{Limits: StreamLimits{
+			Models: map[string]StreamModelLimits{"gpt-4o-mini": {MaxBytes: 1024}},
+		}},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"code":"stream_limit_exceeded"`) || !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Fatalf("body does not end with the limit event:\n%s", body)
+	}
+	if strings.Count(body, "tick") >= 50 {
+		t.Fatal("stream was not cut")
+	}
+	entry := lastAuditEntry(t, logger)
+	if entry.ErrorType != "stream_limit_exceeded" || entry.Data.StreamLimit == nil ||
+		entry.Data.StreamLimit.Limit != "bytes" || entry.Data.StreamLimit.Bytes > 1024 {
+		t.Fatalf("audit entry error type %q, stream_limit %+v", entry.ErrorType, entry.Data.StreamLimit)
+	}
+}
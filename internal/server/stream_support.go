@@ -1,32 +1,509 @@
 package server
 
 import (
+	"errors"
 	"io"
+	"math"
 	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/observability"
+	"gomodel/internal/scoreboard"
 )
 
-func flushStream(w io.Writer, stream io.Reader) error {
-	flusher, canFlush := w.(http.Flusher)
-	if canFlush {
-		flusher.Flush()
+// StreamStallPolicy selects what happens to a streamed response whose client
+// keeps the send buffer full past the stall timeout.
+type StreamStallPolicy string
+
+const (
+	// StreamStallPause keeps upstream reads paused until the client catches
+	// up, or terminates the stream as StreamStallTerminate does once the pause
+	// exceeds MaxPause.
+	StreamStallPause StreamStallPolicy = "pause"
+	// StreamStallTerminate ends the client stream with a final error event and
+	// stops reading from upstream.
+	StreamStallTerminate StreamStallPolicy = "terminate"
+)
+
+// StreamBackpressure bounds the per-connection send buffer that sits between
+// the upstream reader and the client writer of streamed responses, and
+// through Limits the size and duration of each stream. Zero values use the
+// defaults.
+type StreamBackpressure struct {
+	BufferSize   int               // Max buffered bytes per connection; default 256 KiB
+	StallTimeout time.Duration     // How long the buffer may stay full before Policy applies; default 30s
+	Policy       StreamStallPolicy // Default: StreamStallPause
+	MaxPause     time.Duration     // How long StreamStallPause waits past StallTimeout before terminating; default 5m
+	// ChunkStallThreshold is the gap between two upstream chunks counted as an
+	// upstream stall; default 10s
+	ChunkStallThreshold time.Duration
+	// Limits caps the bytes and duration of each stream; zero values disable
+	// them
+	Limits StreamLimits
+
+	// limit is Limits resolved for one request by forRequest.
+	limit streamLimit
+	// abort cancels the upstream request of one stream, set by forRequest.
+	abort func()
+}
+
+const (
+	defaultStreamBufferSize   = 256 * 1024
+	defaultStreamStallTimeout = 30 * time.Second
+	defaultStreamMaxPause     = 5 * time.Minute
+	streamReadChunkSize       = 32 * 1024
+	// defaultChunkStallThreshold is the default ChunkStallThreshold.
+	defaultChunkStallThreshold = 10 * time.Second
+	// maxStreamGapSamples bounds the inter-chunk gaps kept per stream for the
+	// p95; the count, max and stalls still cover every gap.
+	maxStreamGapSamples = 4096
+	// streamTerminateGrace is how long a terminated stream may spend finishing
+	// the write in progress and sending its final error event.
+	streamTerminateGrace = time.Second
+)
+
+var errStreamStalled = errors.New("client read the stream too slowly; stream terminated")
+
+// streamStalledEvent is the final SSE event sent to a client whose stream was
+// terminated under StreamStallTerminate.
+var streamStalledEvent = []byte("event: error\ndata: {\"error\":{\"message\":\"client read the stream too slowly\",\"type\":\"server_error\",\"code\":\"stream_stalled\"}}\n\n")
+
+func (b StreamBackpressure) withDefaults() StreamBackpressure {
+	if b.BufferSize <= 0 {
+		b.BufferSize = defaultStreamBufferSize
+	}
+	if b.StallTimeout <= 0 {
+		b.StallTimeout = defaultStreamStallTimeout
 	}
+	if b.Policy != StreamStallTerminate {
+		b.Policy = StreamStallPause
+	}
+	if b.MaxPause <= 0 {
+		b.MaxPause = defaultStreamMaxPause
+	}
+	if b.ChunkStallThreshold <= 0 {
+		b.ChunkStallThreshold = defaultChunkStallThreshold
+	}
+	return b
+}
+
+// streamStats describes how a streamed response used its send buffer and how
+// regularly its upstream chunks arrived.
+type streamStats struct {
+	HighWaterBytes int
+	Stalls         int
+	Terminated     bool
+
+	// Chunks counts the non-empty upstream reads. Gaps holds the time spent
+	// waiting on upstream between consecutive chunks, without the wait for
+	// the first chunk or time paused for a slow client.
+	Chunks         int
+	Gaps           []time.Duration
+	MaxGap         time.Duration
+	P95Gap         time.Duration
+	UpstreamStalls int // gaps of at least the chunk stall threshold
+
+	// Bytes counts the upstream bytes passed on to the client writer.
+	// LimitExceeded names the stream limit that ended the stream, if any, and
+	// Duration is the time the stream had run when it did.
+	Bytes         int64
+	LimitExceeded string
+	Duration      time.Duration
+}
 
-	buf := make([]byte, 32*1024)
+// recordChunk accounts for one upstream chunk that took gap to arrive.
+func (s *streamStats) recordChunk(gap, stallThreshold time.Duration) {
+	s.Chunks++
+	if s.Chunks == 1 {
+		return
+	}
+	if len(s.Gaps) < maxStreamGapSamples {
+		s.Gaps = append(s.Gaps, gap)
+	}
+	s.MaxGap = max(s.MaxGap, gap)
+	if gap >= stallThreshold {
+		s.UpstreamStalls++
+	}
+}
+
+// gapPercentile returns the nearest-rank percentile of the kept gaps.
+func (s *streamStats) gapPercentile(p float64) time.Duration {
+	if len(s.Gaps) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(s.Gaps)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// streamPump copies an upstream stream to a client through a byte-bounded
+// queue. A reader goroutine fills the queue and pauses upstream reads while it
+// is full; the calling goroutine writes and flushes queued chunks to the
+// client. The pump returns only after the reader goroutine has exited, so the
+// caller may close the upstream stream without racing an in-flight Read. When
+// the writer stops first, the pump aborts the upstream request, which closes
+// the upstream response body, so a Read blocked on a silent provider returns.
+type streamPump struct {
+	w      io.Writer
+	stream io.Reader
+	cfg    StreamBackpressure
+
+	start time.Time
+
+	mu      sync.Mutex
+	chunks  [][]byte
+	size    int
+	readErr error
+	eof     bool
+	stats   streamStats
+
+	ready chan struct{} // a chunk was queued, or reading ended
+	space chan struct{} // a chunk was written to the client
+	stop  chan struct{} // the writer gave up; closed once
+	done  chan struct{} // the reader goroutine exited
+}
+
+// pumpStream streams upstream bytes to w under the given backpressure limits.
+// It returns nil once the upstream stream ends cleanly, the write or read
+// error otherwise, errStreamStalled when the stall policy terminated the
+// stream and errStreamLimitExceeded when a stream limit ended it.
+func pumpStream(w io.Writer, stream io.Reader, cfg StreamBackpressure) (streamStats, error) {
+	p := &streamPump{
+		w:      w,
+		stream: stream,
+		cfg:    cfg.withDefaults(),
+		start:  time.Now(),
+		ready:  make(chan struct{}, 1),
+		space:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	var durationTimer *time.Timer
+	if limit := p.cfg.limit; limit.maxDuration > 0 {
+		durationTimer = time.AfterFunc(max(limit.maxDuration, limit.grace), p.durationExceeded)
+	}
+	go p.read()
+	err := p.write()
+	if durationTimer != nil {
+		durationTimer.Stop()
+	}
+	close(p.stop)
+	select {
+	case <-p.done:
+	default:
+		// The reader may be blocked in Read; abort the upstream request so
+		// net/http closes its body instead of waiting for the next chunk.
+		if abort := p.cfg.abort; abort != nil {
+			abort()
+		}
+		<-p.done
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.P95Gap = p.stats.gapPercentile(0.95)
+	return p.stats, err
+}
+
+func (p *streamPump) read() {
+	defer close(p.done)
+
+	buf := make([]byte, min(streamReadChunkSize, p.cfg.BufferSize))
+	// waited is the time spent in Read since the last chunk. Time blocked in
+	// waitForSpace is the client's, not upstream's, and is left out.
+	var waited time.Duration
 	for {
-		n, err := stream.Read(buf)
+		if !p.waitForSpace(len(buf)) {
+			return
+		}
+		readStart := time.Now()
+		n, err := p.stream.Read(buf)
+		waited += time.Since(readStart)
 		if n > 0 {
-			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
-				return writeErr
+			p.mu.Lock()
+			if p.stats.LimitExceeded != "" {
+				// The duration limit fired during the read.
+				p.mu.Unlock()
+				return
+			}
+			p.stats.recordChunk(waited, p.cfg.ChunkStallThreshold)
+			chunk, cut := p.limitBytes(buf[:n])
+			p.stats.Bytes += int64(len(chunk))
+			p.mu.Unlock()
+			waited = 0
+			if len(chunk) > 0 {
+				p.push(append([]byte(nil), chunk...))
 			}
-			if canFlush {
-				flusher.Flush()
+			if cut {
+				notify(p.ready)
+				return
 			}
 		}
 		if err != nil {
-			if err == io.EOF {
-				return nil
+			p.mu.Lock()
+			p.eof = true
+			if err != io.EOF {
+				p.readErr = err
+			}
+			p.mu.Unlock()
+			notify(p.ready)
+			return
+		}
+	}
+}
+
+// waitForSpace blocks until n more bytes fit in the queue. It applies the
+// stall policy when the queue stays full for the stall timeout, terminates a
+// paused stream whose queue is still full MaxPause later, and reports false
+// when reading must stop.
+func (p *streamPump) waitForSpace(n int) bool {
+	var timer *time.Timer
+	paused := false
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		p.mu.Lock()
+		fits := p.size+n <= p.cfg.BufferSize
+		limited := p.stats.LimitExceeded != ""
+		p.mu.Unlock()
+		if limited {
+			return false
+		}
+		if fits {
+			return true
+		}
+		if timer == nil {
+			timer = time.NewTimer(p.cfg.StallTimeout)
+		}
+
+		select {
+		case <-p.space:
+		case <-p.stop:
+			return false
+		case <-timer.C:
+			if p.cfg.Policy == StreamStallPause && !paused {
+				paused = true
+				p.mu.Lock()
+				p.stats.Stalls++
+				p.mu.Unlock()
+				timer.Reset(p.cfg.MaxPause)
+				continue
 			}
+			p.terminate()
+			return false
+		}
+	}
+}
+
+// terminate ends a stream whose client stayed too slow. It bounds the write
+// blocked on the client so the writer can send the final event or give up.
+func (p *streamPump) terminate() {
+	p.mu.Lock()
+	p.stats.Terminated = true
+	p.mu.Unlock()
+	if rw, ok := p.w.(http.ResponseWriter); ok {
+		_ = http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(streamTerminateGrace)) //nolint:errcheck
+	}
+	notify(p.ready)
+}
+
+// limitBytes applies the byte limit to a chunk just read. Past the limit it
+// returns the part of the chunk that ends with its last complete event within
+// the limit, and reports the cut. The caller holds p.mu.
+func (p *streamPump) limitBytes(chunk []byte) ([]byte, bool) {
+	limit := p.cfg.limit
+	if limit.maxBytes <= 0 || p.stats.Bytes+int64(len(chunk)) <= limit.maxBytes || time.Since(p.start) < limit.grace {
+		return chunk, false
+	}
+	allowed := max(limit.maxBytes-p.stats.Bytes, 0)
+	chunk = chunk[:lastEventEnd(chunk[:min(int64(len(chunk)), allowed)])]
+	p.exceed(streamLimitBytes)
+	return chunk, true
+}
+
+// durationExceeded ends a stream that is still running at the duration
+// limit. It aborts the upstream call, so a read waiting on a silent provider
+// returns.
+func (p *streamPump) durationExceeded() {
+	p.mu.Lock()
+	if p.eof || p.stats.Terminated || p.stats.LimitExceeded != "" {
+		p.mu.Unlock()
+		return
+	}
+	p.exceed(streamLimitDuration)
+	p.mu.Unlock()
+	if abort := p.cfg.abort; abort != nil {
+		abort()
+	}
+	notify(p.ready)
+	notify(p.space)
+}
+
+// exceed records that limit ended the stream. The caller holds p.mu.
+func (p *streamPump) exceed(limit string) {
+	p.stats.LimitExceeded = limit
+	p.stats.Duration = time.Since(p.start)
+}
+
+func (p *streamPump) push(chunk []byte) {
+	p.mu.Lock()
+	p.chunks = append(p.chunks, chunk)
+	p.size += len(chunk)
+	p.stats.HighWaterBytes = max(p.stats.HighWaterBytes, p.size)
+	p.mu.Unlock()
+	notify(p.ready)
+}
+
+func (p *streamPump) write() error {
+	flusher, canFlush := p.w.(http.Flusher)
+	if canFlush {
+		flusher.Flush()
+	}
+
+	for {
+		chunk, err := p.next()
+		if chunk == nil {
 			return err
 		}
+		if _, err := p.w.Write(chunk); err != nil {
+			if p.terminated() {
+				return errStreamStalled
+			}
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+
+		p.mu.Lock()
+		p.chunks = p.chunks[1:]
+		p.size -= len(chunk)
+		p.mu.Unlock()
+		notify(p.space)
+	}
+}
+
+// next returns the chunk to write, or a nil chunk and the terminal error once
+// reading ended and the queue is drained. A terminated stream drops its queue
+// and ends with the final error event.
+func (p *streamPump) next() ([]byte, error) {
+	for {
+		p.mu.Lock()
+		switch {
+		case p.stats.Terminated:
+			p.chunks = nil
+			p.size = 0
+			p.mu.Unlock()
+			p.writeFinalEvent(streamStalledEvent)
+			return nil, errStreamStalled
+		case len(p.chunks) > 0:
+			chunk := p.chunks[0]
+			p.mu.Unlock()
+			return chunk, nil
+		case p.stats.LimitExceeded != "":
+			// Chunks queued before the cutoff were delivered first.
+			limit := p.stats.LimitExceeded
+			p.mu.Unlock()
+			p.writeFinalEvent(streamLimitEvents[limit])
+			return nil, errStreamLimitExceeded
+		case p.eof:
+			err := p.readErr
+			p.mu.Unlock()
+			return nil, err
+		}
+		p.mu.Unlock()
+		<-p.ready
+	}
+}
+
+func (p *streamPump) writeFinalEvent(event []byte) {
+	if _, err := p.w.Write(event); err != nil {
+		return
+	}
+	if flusher, ok := p.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (p *streamPump) terminated() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats.Terminated
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// recordStreamBackpressure meters the send buffer use of one streamed
+// response and records it on its audit entry.
+func recordStreamBackpressure(c *echo.Context, streamEntry *auditlog.LogEntry, stats streamStats) {
+	route := c.Path()
+	observability.StreamBufferHighWaterBytes.WithLabelValues(route).Observe(float64(stats.HighWaterBytes))
+	if stats.Stalls > 0 {
+		observability.StreamBackpressureEvents.WithLabelValues(route, "stall").Add(float64(stats.Stalls))
+	}
+	if stats.Terminated {
+		observability.StreamBackpressureEvents.WithLabelValues(route, "terminated").Inc()
+	}
+
+	if streamEntry == nil {
+		return
+	}
+	if streamEntry.Data == nil {
+		streamEntry.Data = &auditlog.LogData{}
+	}
+	streamEntry.Data.StreamBackpressure = &auditlog.StreamBackpressureSnapshot{
+		BufferHighWaterBytes: stats.HighWaterBytes,
+		Stalls:               stats.Stalls,
+		Terminated:           stats.Terminated,
+	}
+}
+
+// recordStreamTiming meters the upstream inter-chunk gaps of one streamed
+// response by provider and model, records them on its audit entry, and
+// reports an upstream stall to the log and the scoreboard.
+func recordStreamTiming(c *echo.Context, streamEntry *auditlog.LogEntry, stats streamStats, provider, model string) {
+	gaps := observability.StreamChunkGapSeconds.WithLabelValues(provider, model)
+	for _, gap := range stats.Gaps {
+		gaps.Observe(gap.Seconds())
+	}
+	if stats.UpstreamStalls > 0 {
+		observability.StreamUpstreamStalls.WithLabelValues(provider, model).Add(float64(stats.UpstreamStalls))
+		scoreboard.MarkStreamStalled(c)
+		serverLogger.Warn("provider stream stalled",
+			"request_id", requestIDFromContextOrHeader(c.Request()),
+			"provider", provider,
+			"model", model,
+			"stalls", stats.UpstreamStalls,
+			"max_gap", stats.MaxGap,
+			"chunks", stats.Chunks,
+		)
+	}
+
+	if streamEntry == nil || stats.Chunks == 0 {
+		return
+	}
+	if streamEntry.Data == nil {
+		streamEntry.Data = &auditlog.LogData{}
+	}
+	streamEntry.Data.StreamTiming = &auditlog.StreamTimingSnapshot{
+		Chunks:   stats.Chunks,
+		MaxGapMs: stats.MaxGap.Milliseconds(),
+		P95GapMs: stats.P95Gap.Milliseconds(),
+		Stalls:   stats.UpstreamStalls,
 	}
 }
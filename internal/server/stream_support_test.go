@@ -0,0 +1,305 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/observability"
+)
+
+// slowClient stands in for a client that stopped reading: writes block until
+// the test releases them. A write deadline releases them too, as if the write
+// in progress finished within the grace period.
+type slowClient struct {
+	*httptest.ResponseRecorder
+	release     chan struct{}
+	releaseOnce sync.Once
+	deadlines   int
+}
+
+func newSlowClient() *slowClient {
+	return &slowClient{ResponseRecorder: httptest.NewRecorder(), release: make(chan struct{})}
+}
+
+func (w *slowClient) Write(p []byte) (int, error) {
+	<-w.release
+	return w.ResponseRecorder.Write(p)
+}
+
+func (w *slowClient) Release() {
+	w.releaseOnce.Do(func() { close(w.release) })
+}
+
+func (w *slowClient) SetWriteDeadline(time.Time) error {
+	w.deadlines++
+	w.Release()
+	return nil
+}
+
+// chunkReader returns one chunk per Read and counts how many were read.
+type chunkReader struct {
+	chunks [][]byte
+	reads  int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.reads >= len(r.chunks) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[r.reads])
+	r.reads++
+	return n, nil
+}
+
+func sseChunks(count, size int) [][]byte {
+	chunks := make([][]byte, count)
+	for i := range chunks {
+		chunks[i] = append(bytes.Repeat([]byte{'a' + byte(i)}, size-1), '\n')
+	}
+	return chunks
+}
+
+func TestPumpStream_PausesUpstreamForSlowClient(t *testing.T) {
+	client := newSlowClient()
+	upstream := &chunkReader{chunks: sseChunks(4, 1024)}
+	time.AfterFunc(100*time.Millisecond, client.Release)
+
+	stats, err := pumpStream(client, upstream, StreamBackpressure{
+		BufferSize:   1024,
+		StallTimeout: 20 * time.Millisecond,
+		Policy:       StreamStallPause,
+	})
+	if err != nil {
+		t.Fatalf("pumpStream() error = %v", err)
+	}
+	if want := bytes.Join(upstream.chunks, nil); !bytes.Equal(client.Body.Bytes(), want) {
+		t.Fatalf("body length = %d, want all %d upstream bytes", client.Body.Len(), len(want))
+	}
+	if stats.HighWaterBytes != 1024 || stats.Stalls != 1 || stats.Terminated {
+		t.Fatalf("stats = %+v, want high water 1024, 1 stall, not terminated", stats)
+	}
+	if client.deadlines != 0 {
+		t.Fatalf("write deadlines = %d, want none under the pause policy", client.deadlines)
+	}
+}
+
+func TestPumpStream_TerminatesSlowClient(t *testing.T) {
+	client := newSlowClient()
+	upstream := &chunkReader{chunks: sseChunks(4, 1024)}
+	// Fails the test instead of hanging if the pump never sets a deadline.
+	safety := time.AfterFunc(5*time.Second, client.Release)
+	defer safety.Stop()
+
+	stats, err := pumpStream(client, upstream, StreamBackpressure{
+		BufferSize:   1024,
+		StallTimeout: 20 * time.Millisecond,
+		Policy:       StreamStallTerminate,
+	})
+	if !errors.Is(err, errStreamStalled) {
+		t.Fatalf("pumpStream() error = %v, want errStreamStalled", err)
+	}
+	if !stats.Terminated || stats.Stalls != 0 {
+		t.Fatalf("stats = %+v, want terminated without pause stalls", stats)
+	}
+	if client.deadlines != 1 {
+		t.Fatalf("write deadlines = %d, want 1", client.deadlines)
+	}
+	if upstream.reads != 1 {
+		t.Fatalf("upstream reads = %d, want reading to stop after the first chunk", upstream.reads)
+	}
+	want := append(append([]byte(nil), upstream.chunks[0]...), streamStalledEvent...)
+	if got := client.Body.Bytes(); !bytes.Equal(got, want) {
+		t.Fatalf("body = %q, want the first chunk followed by the stalled event", got)
+	}
+}
+
+func TestPumpStream_PauseTerminatesAfterMaxPause(t *testing.T) {
+	client := newSlowClient()
+	upstream := &chunkReader{chunks: sseChunks(4, 1024)}
+	// Fails the test instead of hanging if the pause never ends.
+	safety := time.AfterFunc(5*time.Second, client.Release)
+	defer safety.Stop()
+
+	stats, err := pumpStream(client, upstream, StreamBackpressure{
+		BufferSize:   1024,
+		StallTimeout: 20 * time.Millisecond,
+		Policy:       StreamStallPause,
+		MaxPause:     40 * time.Millisecond,
+	})
+	if !errors.Is(err, errStreamStalled) {
+		t.Fatalf("pumpStream() error = %v, want errStreamStalled", err)
+	}
+	if !stats.Terminated || stats.Stalls != 1 {
+		t.Fatalf("stats = %+v, want one pause stall, then terminated", stats)
+	}
+	if client.deadlines != 1 {
+		t.Fatalf("write deadlines = %d, want 1", client.deadlines)
+	}
+}
+
+// failingClient stands in for a client that went away: every write fails.
+type failingClient struct {
+	*httptest.ResponseRecorder
+}
+
+func (failingClient) Write([]byte) (int, error) {
+	return 0, errors.New("client disconnected")
+}
+
+// stallingStream returns one chunk, then blocks until ctx is canceled.
+type stallingStream struct {
+	ctx  context.Context
+	sent bool
+}
+
+func (s *stallingStream) Read(p []byte) (int, error) {
+	if !s.sent {
+		s.sent = true
+		return copy(p, "data: {}\n\n"), nil
+	}
+	<-s.ctx.Done()
+	return 0, s.ctx.Err()
+}
+
+func TestPumpStream_AbortsUpstreamBlockedInReadWhenWriteFails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := StreamBackpressure{}
+	cfg.abort = cancel
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := pumpStream(failingClient{httptest.NewRecorder()}, &stallingStream{ctx: ctx}, cfg)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("pumpStream() error = nil, want the write error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("pumpStream() waited on an upstream Read after the client write failed")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("upstream was not aborted")
+	}
+}
+
+func TestPumpStream_BoundsBufferedBytes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	upstream := &chunkReader{chunks: sseChunks(16, 512)}
+
+	stats, err := pumpStream(rec, upstream, StreamBackpressure{BufferSize: 2048})
+	if err != nil {
+		t.Fatalf("pumpStream() error = %v", err)
+	}
+	if rec.Body.Len() != 16*512 {
+		t.Fatalf("body length = %d, want %d", rec.Body.Len(), 16*512)
+	}
+	if stats.HighWaterBytes == 0 || stats.HighWaterBytes > 2048 {
+		t.Fatalf("high water = %d, want within the 2048 byte buffer", stats.HighWaterBytes)
+	}
+}
+
+func TestRecordStreamBackpressure_SetsAuditSnapshot(t *testing.T) {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil), httptest.NewRecorder())
+	entry := &auditlog.LogEntry{}
+
+	recordStreamBackpressure(c, entry, streamStats{HighWaterBytes: 4096, Terminated: true})
+
+	got := entry.Data.StreamBackpressure
+	if got == nil || got.BufferHighWaterBytes != 4096 || got.Stalls != 0 || !got.Terminated {
+		t.Fatalf("StreamBackpressure = %+v, want 4096 bytes and terminated", got)
+	}
+}
+
+// delayedReader returns one chunk per Read after that chunk's delay.
+type delayedReader struct {
+	chunks [][]byte
+	delays []time.Duration
+	reads  int
+}
+
+func (r *delayedReader) Read(p []byte) (int, error) {
+	if r.reads >= len(r.chunks) {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delays[r.reads])
+	n := copy(p, r.chunks[r.reads])
+	r.reads++
+	return n, nil
+}
+
+func TestPumpStream_MeasuresInterChunkGaps(t *testing.T) {
+	upstream := &delayedReader{
+		chunks: sseChunks(5, 64),
+		// The first delay is time to first chunk and is not a gap.
+		delays: []time.Duration{150 * time.Millisecond, 0, 100 * time.Millisecond, 0, 0},
+	}
+
+	stats, err := pumpStream(httptest.NewRecorder(), upstream, StreamBackpressure{ChunkStallThreshold: 80 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("pumpStream() error = %v", err)
+	}
+	if stats.Chunks != 5 || len(stats.Gaps) != 4 {
+		t.Fatalf("chunks = %d, gaps = %d; want 5 chunks and 4 gaps", stats.Chunks, len(stats.Gaps))
+	}
+	if stats.UpstreamStalls != 1 {
+		t.Fatalf("upstream stalls = %d, want 1", stats.UpstreamStalls)
+	}
+	if stats.MaxGap < 100*time.Millisecond || stats.MaxGap >= 150*time.Millisecond {
+		t.Fatalf("max gap = %s, want the 100ms pause", stats.MaxGap)
+	}
+	if stats.P95Gap != stats.MaxGap {
+		t.Fatalf("p95 gap = %s, want the max gap %s of four gaps", stats.P95Gap, stats.MaxGap)
+	}
+}
+
+func TestPumpStream_GapsExcludeClientBackpressure(t *testing.T) {
+	client := newSlowClient()
+	upstream := &chunkReader{chunks: sseChunks(4, 1024)}
+	time.AfterFunc(100*time.Millisecond, client.Release)
+
+	stats, err := pumpStream(client, upstream, StreamBackpressure{BufferSize: 1024, ChunkStallThreshold: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("pumpStream() error = %v", err)
+	}
+	if stats.Chunks != 4 || stats.UpstreamStalls != 0 {
+		t.Fatalf("chunks = %d, upstream stalls = %d; want 4 chunks and no stall from a slow client", stats.Chunks, stats.UpstreamStalls)
+	}
+}
+
+func TestRecordStreamTiming_SetsAuditSnapshotAndMetrics(t *testing.T) {
+	observability.ResetMetrics()
+	t.Cleanup(observability.ResetMetrics)
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil), httptest.NewRecorder())
+	entry := &auditlog.LogEntry{}
+	stats := streamStats{Chunks: 3, Gaps: []time.Duration{20 * time.Millisecond, 12 * time.Second}, MaxGap: 12 * time.Second, P95Gap: 12 * time.Second, UpstreamStalls: 1}
+
+	recordStreamTiming(c, entry, stats, "openai", "gpt-4o")
+
+	got := entry.Data.StreamTiming
+	if got == nil || got.Chunks != 3 || got.MaxGapMs != 12000 || got.P95GapMs != 12000 || got.Stalls != 1 {
+		t.Fatalf("StreamTiming = %+v, want 3 chunks, 12s max and p95 gap, 1 stall", got)
+	}
+	if stalled, _ := c.Get("gomodel_scoreboard_stream_stalled").(bool); !stalled {
+		t.Fatal("stalled stream was not reported to the scoreboard")
+	}
+	if n := testutil.CollectAndCount(observability.StreamChunkGapSeconds); n != 1 {
+		t.Fatalf("gap histogram series = %d, want 1", n)
+	}
+	if v := testutil.ToFloat64(observability.StreamUpstreamStalls.WithLabelValues("openai", "gpt-4o")); v != 1 {
+		t.Fatalf("upstream stalls counter = %v, want 1", v)
+	}
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+	"gomodel/internal/strictcompat"
+)
+
+// StrictCompat strips gateway extensions from /v1 responses when strict
+// OpenAI compatibility is on, either by config or per request through the
+// strict_compat request option or X-GoModel-Strict-Compat header. JSON bodies and stream events are filtered
+// through the OpenAI allow-lists, errors are rewritten to OpenAI's envelope
+// and extension headers are dropped. It runs outside every other handler so
+// nothing added later escapes the filter; admin and passthrough routes are
+// never touched.
+func StrictCompat(enabled bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !strictcompat.Applies(req.URL.Path) || !strictCompatActive(enabled, core.GetRequestOptions(req.Context())) {
+				return next(c)
+			}
+			writer := &strictCompatWriter{ResponseWriter: c.Response()}
+			writer.body, writer.event, _ = strictcompat.Route(req.Method, req.URL.Path)
+			c.SetResponse(writer)
+			defer c.SetResponse(writer.ResponseWriter)
+
+			err := next(c)
+			if err != nil && !writer.wroteHeader {
+				// Errors left to Echo's error handler would be written in its
+				// own shape after this middleware returns.
+				err = handleError(c, strictCompatError(err))
+			}
+			if finishErr := writer.finish(); err == nil {
+				err = finishErr
+			}
+			return err
+		}
+	}
+}
+
+// strictCompatActive reports whether strict compatibility applies, given the
+// configured default and the request's strict_compat option.
+func strictCompatActive(enabled bool, opts *core.RequestOptions) bool {
+	if opts != nil && opts.StrictCompat != nil {
+		return *opts.StrictCompat
+	}
+	return enabled
+}
+
+// strictCompatError maps errors Echo raises itself, such as unknown routes,
+// to gateway errors so they are written as OpenAI error envelopes.
+func strictCompatError(err error) error {
+	if _, ok := errors.AsType[*core.GatewayError](err); ok {
+		return err
+	}
+	status := echo.StatusCode(err)
+	switch {
+	case status == http.StatusNotFound:
+		return core.NewNotFoundError(http.StatusText(status))
+	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
+		return core.NewInvalidRequestErrorWithStatus(status, http.StatusText(status), err)
+	default:
+		return err
+	}
+}
+
+type strictCompatMode int
+
+const (
+	strictCompatWritten strictCompatMode = iota
+	strictCompatHeldBody
+	strictCompatHeldError
+	strictCompatFilteredStream
+)
+
+// strictCompatWriter drops extension headers when the response starts and
+// filters the body: JSON bodies are held until the handler returns, stream
+// events are filtered line by line as they are written.
+type strictCompatWriter struct {
+	http.ResponseWriter
+	body  strictcompat.Schema
+	event strictcompat.Schema
+
+	wroteHeader bool
+	mode        strictCompatMode
+	status      int
+	buf         bytes.Buffer
+}
+
+func (w *strictCompatWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	strictcompat.StripHeaders(header)
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	encoded := strings.TrimSpace(header.Get("Content-Encoding")) != ""
+	switch {
+	case encoded:
+	case mediaType == "application/json" && code >= http.StatusBadRequest:
+		w.mode = strictCompatHeldError
+	case mediaType == "application/json" && w.body != nil:
+		w.mode = strictCompatHeldBody
+	case mediaType == "text/event-stream" && w.event != nil:
+		w.mode = strictCompatFilteredStream
+		header.Del("Content-Length")
+	}
+	if w.held() {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *strictCompatWriter) held() bool {
+	return w.mode == strictCompatHeldBody || w.mode == strictCompatHeldError
+}
+
+func (w *strictCompatWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(pendingResponseStatus(w.ResponseWriter))
+	}
+	switch w.mode {
+	case strictCompatHeldBody, strictCompatHeldError:
+		return w.buf.Write(b)
+	case strictCompatFilteredStream:
+		w.buf.Write(b)
+		if err := w.writeEvents(false); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	default:
+		return w.ResponseWriter.Write(b)
+	}
+}
+
+func (w *strictCompatWriter) Flush() {
+	if w.held() {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *strictCompatWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeEvents writes the complete lines buffered so far with their event
+// data filtered. With final set, a trailing partial line is written too.
+func (w *strictCompatWriter) writeEvents(final bool) error {
+	data := w.buf.Bytes()
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if final {
+		end = len(data)
+	}
+	if end == 0 {
+		return nil
+	}
+	out := make([]byte, 0, end)
+	for line := range bytes.Lines(data[:end]) {
+		out = append(out, w.filterEventLine(line)...)
+	}
+	w.buf.Next(end)
+	_, err := w.ResponseWriter.Write(out)
+	return err
+}
+
+func (w *strictCompatWriter) filterEventLine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	content := bytes.TrimSpace(payload)
+	if len(content) == 0 || content[0] != '{' {
+		return line
+	}
+	var filtered []byte
+	if gjson.GetBytes(content, "error").IsObject() && !gjson.GetBytes(content, "type").Exists() {
+		filtered, ok = strictcompat.ErrorBody(content)
+	} else {
+		filtered, ok = strictcompat.Filter(content, w.event)
+	}
+	if !ok {
+		return line
+	}
+	out := make([]byte, 0, len(filtered)+8)
+	out = append(out, "data: "...)
+	out = append(out, filtered...)
+	if newline := payload[len(bytes.TrimRight(payload, "\r\n")):]; len(newline) > 0 {
+		out = append(out, newline...)
+	}
+	return out
+}
+
+// pendingResponseStatus returns the status Echo will send when a handler
+// writes a body without calling WriteHeader: c.JSON records it on the
+// underlying *echo.Response instead of writing it through wrappers.
+func pendingResponseStatus(w http.ResponseWriter) int {
+	if resp, err := echo.UnwrapResponse(w); err == nil && resp.Status != 0 {
+		return resp.Status
+	}
+	return http.StatusOK
+}
+
+// finish writes a held body with its extensions removed and any partial
+// stream line left over.
+func (w *strictCompatWriter) finish() error {
+	if w.mode == strictCompatFilteredStream {
+		return w.writeEvents(true)
+	}
+	if !w.held() {
+		return nil
+	}
+	body := w.buf.Bytes()
+	if w.mode == strictCompatHeldError {
+		body, _ = strictcompat.ErrorBody(body)
+	} else {
+		body, _ = strictcompat.Filter(body, w.body)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+	"gomodel/internal/strictcompat"
+)
+
+func strictCompatMock() *mockProvider {
+	return &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		response: &core.ChatResponse{
+			ID:    "chatcmpl-1",
+			Model: "gpt-4o-mini",
+			Choices: []core.Choice{{
+				Message:      core.ResponseMessage{Role: "assistant", Content: "hello"},
+				FinishReason: "stop",
+			}},
+			Usage: core.Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2, RawUsage: map[string]any{"cost": 0.1}},
+		},
+		streamData: "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"provider\":\"mock\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\",\"reasoning_content\":\"x\"}}]}\n\ndata: [DONE]\n\n",
+	}
+}
+
+func TestStrictCompat_StripsExtensions(t *testing.T) {
+	srv := New(strictCompatMock(), &Config{StrictOpenAICompat: true})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(false))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, extension := range []string{`"provider"`, `"raw_usage"`} {
+		if strings.Contains(body, extension) {
+			t.Fatalf("body = %s, want no %s", body, extension)
+		}
+	}
+	if !strings.Contains(body, `"content":"hello"`) {
+		t.Fatalf("body = %s, want the message content", body)
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("X-Request-ID was stripped")
+	}
+}
+
+func TestStrictCompat_HeaderOverridesConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		enabled      bool
+		header       string
+		wantProvider bool
+	}{
+		{name: "off by default", wantProvider: true},
+		{name: "request opts in", header: "true"},
+		{name: "request opts out", enabled: true, header: "false", wantProvider: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(strictCompatMock(), &Config{StrictOpenAICompat: tt.enabled})
+			req := chaosChatRequest(false)
+			if tt.header != "" {
+				req.Header.Set(strictcompat.Header, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if got := strings.Contains(rec.Body.String(), `"provider"`); got != tt.wantProvider {
+				t.Fatalf("body = %s, want provider field %v", rec.Body.String(), tt.wantProvider)
+			}
+		})
+	}
+}
+
+func TestStrictCompat_FiltersStreamEvents(t *testing.T) {
+	srv := New(strictCompatMock(), &Config{StrictOpenAICompat: true})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, chaosChatRequest(true))
+
+	want := "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"
+	if rec.Body.String() != want {
+		t.Fatalf("stream body = %q, want %q", rec.Body.String(), want)
+	}
+}
+
+func TestStrictCompat_ModelListing(t *testing.T) {
+	mock := strictCompatMock()
+	mock.modelsResponse = &core.ModelsResponse{Object: "list", Data: []core.Model{{
+		ID: "gpt-4o-mini", Object: "model", OwnedBy: "openai",
+		Metadata: &core.ModelMetadata{DisplayName: "GPT-4o mini"},
+	}}}
+	srv := New(mock, &Config{StrictOpenAICompat: true})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "metadata") || !strings.Contains(rec.Body.String(), `"id":"gpt-4o-mini"`) {
+		t.Fatalf("body = %s, want the model without metadata", rec.Body.String())
+	}
+}
+
+func TestStrictCompat_ErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *http.Request
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "provider error",
+			req:      chaosChatRequest(false),
+			wantCode: http.StatusBadGateway,
+			wantBody: `{"error":{"message":"upstream exploded","type":"server_error","param":null,"code":null}}`,
+		},
+		{
+			name:     "unknown route",
+			req:      httptest.NewRequest(http.MethodGet, "/v1/unknown", nil),
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"message":"Not Found","type":"invalid_request_error","param":null,"code":null}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := strictCompatMock()
+			mock.err = core.NewProviderError("mock", http.StatusBadGateway, "upstream exploded", nil)
+			mock.err.(*core.GatewayError).ProviderError = map[string]any{"detail": "boom"}
+			srv := New(mock, &Config{StrictOpenAICompat: true})
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, tt.req)
+
+			if rec.Code != tt.wantCode || strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Fatalf("got %d %s, want %d %s", rec.Code, rec.Body.String(), tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+}
@@ -5,15 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/labstack/echo/v5"
 
 	"gomodel/internal/auditlog"
 	"gomodel/internal/core"
+	"gomodel/internal/embeddingcache"
 	"gomodel/internal/gateway"
 	"gomodel/internal/observability"
 	"gomodel/internal/responsecache"
@@ -36,6 +38,18 @@ type translatedInferenceService struct {
 	pricingResolver          usage.PricingResolver
 	responseCache            *responsecache.ResponseCacheMiddleware
 	guardrailsHash           string
+	contextOverflow          gateway.ContextOverflowConfig
+	promptCompression        gateway.PromptCompressionConfig
+	firstToken               gateway.FirstTokenConfig
+	moderation               gateway.ModerationConfig
+	serverTools              gateway.ServerToolsConfig
+	promptCache              gateway.PromptCacheMatcher
+	maxTokensDefaults        gateway.MaxTokensDefaulter
+	inlineImageLimits        core.InlineImageLimits
+	promptTemplates          PromptTemplateRenderer
+	recordRawUser            bool
+	streamBackpressure       StreamBackpressure
+	embeddingCache           *embeddingcache.Cache
 	responseStore            responsestore.Store
 	responseStoreMu          sync.RWMutex
 
@@ -66,6 +80,13 @@ func (s *translatedInferenceService) newInferenceOrchestrator() *gateway.Inferen
 		UsageLogger:              s.usageLogger,
 		PricingResolver:          s.pricingResolver,
 		GuardrailsHash:           s.guardrailsHash,
+		ContextOverflow:          s.contextOverflow,
+		PromptCompression:        s.promptCompression,
+		FirstToken:               s.firstToken,
+		Moderation:               s.moderation,
+		ServerTools:              s.serverTools,
+		PromptCache:              s.promptCache,
+		MaxTokensDefaults:        s.maxTokensDefaults,
 	})
 }
 
@@ -74,6 +95,9 @@ func (s *translatedInferenceService) ChatCompletion(c *echo.Context) error {
 }
 
 func (s *translatedInferenceService) handleChatCompletion(c *echo.Context) error {
+	if handled, err := s.tryStreamRequestBody(c, "/chat/completions"); handled {
+		return err
+	}
 	return handleTranslatedJSON(s, c, core.DecodeChatRequest, prepareChatCompletionRequest, s.dispatchChatCompletion)
 }
 
@@ -82,11 +106,16 @@ func (s *translatedInferenceService) dispatchChatCompletion(c *echo.Context, req
 	requestID := requestIDFromContextOrHeader(c.Request())
 
 	if req.Stream {
-		if len(s.inference().FallbackSelectors(workflow)) == 0 {
+		accumulate, err := accumulateJSONRequested(c)
+		if err != nil {
+			return handleError(c, err)
+		}
+		if !accumulate && len(s.inference().FallbackSelectors(workflow)) == 0 {
 			if handled, err := s.tryFastPathStreamingChatPassthrough(c, workflow, req); handled {
 				return err
 			}
 		}
+		started := time.Now()
 		result, err := s.inference().StreamChatCompletion(ctx, workflow, req)
 		if err != nil {
 			return handleError(c, err)
@@ -94,6 +123,10 @@ func (s *translatedInferenceService) dispatchChatCompletion(c *echo.Context, req
 		if result.Meta.UsedFallback {
 			markRequestFallbackUsed(c)
 		}
+		reportToolLoop(c, result.Meta.ToolLoop)
+		if accumulate {
+			return s.handleAccumulatedStream(c, workflow, result.Meta, started, result.Stream)
+		}
 		return s.handleStreamingReadCloser(
 			c,
 			workflow,
@@ -119,6 +152,9 @@ func (s *translatedInferenceService) dispatchChatCompletion(c *echo.Context, req
 		result.Meta.ProviderType,
 		result.Meta.ProviderName,
 	)
+	auditlog.EnrichEntryWithServedModel(c, result.Response.Model)
+	auditlog.EnrichEntryWithResponseID(c, result.Response.ID, "")
+	reportToolLoop(c, result.Meta.ToolLoop)
 
 	return c.JSON(http.StatusOK, result.Response)
 }
@@ -128,6 +164,9 @@ func (s *translatedInferenceService) Responses(c *echo.Context) error {
 }
 
 func (s *translatedInferenceService) handleResponses(c *echo.Context) error {
+	if handled, err := s.tryStreamRequestBody(c, "/responses"); handled {
+		return err
+	}
 	return handleTranslatedJSON(s, c, core.DecodeResponsesRequest, prepareResponsesRequest, s.dispatchResponses)
 }
 
@@ -140,7 +179,22 @@ func handleTranslatedJSON[Req any](
 ) error {
 	req, err := canonicalJSONRequestFromSemantics[Req](c, decode)
 	if err != nil {
-		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
+		return handleError(c, requestBodyReadError("invalid request body: "+err.Error(), err))
+	}
+	if err := applyPromptTemplate(c, req, s.promptTemplates); err != nil {
+		return handleError(c, err)
+	}
+	if err := applyRequestLabels(c, req); err != nil {
+		return handleError(c, err)
+	}
+	if err := applyStreamFormat(c, req); err != nil {
+		return handleError(c, err)
+	}
+	if err := applyRequestUser(c, req, s.recordRawUser); err != nil {
+		return handleError(c, err)
+	}
+	if err := applyInlineImages(c, req, s.inlineImageLimits); err != nil {
+		return handleError(c, err)
 	}
 
 	ctx, preparedReq, workflow, err := prepare(s, c.Request().Context(), req, translatedRequestMeta(c))
@@ -148,6 +202,10 @@ func handleTranslatedJSON[Req any](
 		return handleError(c, err)
 	}
 	attachPreparedWorkflow(c, ctx, workflow)
+	reportPromptCompression(c, core.GetPromptCompression(ctx))
+	reportContextOverflow(c, core.GetContextOverflow(ctx))
+	reportMaxTokensDefault(c, core.GetMaxTokensDefault(ctx))
+	reportModeration(c, s.inference(), core.GetModeration(ctx))
 
 	return handleWithCache(s, c, preparedReq, workflow, dispatch)
 }
@@ -206,7 +264,7 @@ func handleWithCache[R any](
 	if s.responseCache != nil && (workflow == nil || workflow.CacheEnabled()) {
 		body, marshalErr := marshalRequestBody(req)
 		if marshalErr != nil {
-			slog.Debug("marshalRequestBody failed", "err", marshalErr)
+			serverLogger.Debug("marshalRequestBody failed", "err", marshalErr)
 		} else {
 			return s.responseCache.HandleRequest(c, body, func() error {
 				return dispatch(c, req, workflow)
@@ -222,6 +280,11 @@ func (s *translatedInferenceService) dispatchResponses(c *echo.Context, req *cor
 	requestID := requestIDFromContextOrHeader(c.Request())
 
 	if req.Stream {
+		accumulate, err := accumulateJSONRequested(c)
+		if err != nil {
+			return handleError(c, err)
+		}
+		started := time.Now()
 		result, err := s.inference().StreamResponses(ctx, workflow, req)
 		if err != nil {
 			return handleError(c, err)
@@ -229,6 +292,10 @@ func (s *translatedInferenceService) dispatchResponses(c *echo.Context, req *cor
 		if result.Meta.UsedFallback {
 			markRequestFallbackUsed(c)
 		}
+		reportToolLoop(c, result.Meta.ToolLoop)
+		if accumulate {
+			return s.handleAccumulatedStream(c, workflow, result.Meta, started, result.Stream)
+		}
 		return s.handleStreamingReadCloser(
 			c,
 			workflow,
@@ -254,6 +321,9 @@ func (s *translatedInferenceService) dispatchResponses(c *echo.Context, req *cor
 		result.Meta.ProviderType,
 		result.Meta.ProviderName,
 	)
+	auditlog.EnrichEntryWithServedModel(c, result.Response.Model)
+	auditlog.EnrichEntryWithResponseID(c, result.Response.ID, "")
+	reportToolLoop(c, result.Meta.ToolLoop)
 
 	if err := s.storeResponseSnapshot(ctx, workflow, req, result.Response, result.Meta.ProviderType, result.Meta.ProviderName, requestID); err != nil {
 		s.recordResponseSnapshotStoreFailure(workflow, result.Response, result.Meta.ProviderType, result.Meta.ProviderName, requestID, err)
@@ -307,7 +377,7 @@ func (s *translatedInferenceService) recordResponseSnapshotStoreFailure(workflow
 		"store",
 	).Inc()
 
-	slog.Warn("response snapshot store failed",
+	serverLogger.Warn("response snapshot store failed",
 		"request_id", requestID,
 		"provider_type", providerType,
 		"provider_name", providerName,
@@ -325,7 +395,7 @@ func responseIDForLog(resp *core.ResponsesResponse) string {
 }
 
 func (s *translatedInferenceService) tryFastPathStreamingChatPassthrough(c *echo.Context, workflow *core.Workflow, req *core.ChatRequest) (bool, error) {
-	if !s.inference().CanFastPathStreamingChatPassthrough(workflow, req) {
+	if !s.inference().CanFastPathStreamingChatPassthrough(c.Request().Context(), workflow, req) {
 		return false, nil
 	}
 
@@ -348,20 +418,7 @@ func (s *translatedInferenceService) tryFastPathStreamingChatPassthrough(c *echo
 	if err != nil {
 		return true, handleError(c, err)
 	}
-
-	info := &core.PassthroughRouteInfo{
-		Provider:    providerType,
-		RawEndpoint: strings.TrimPrefix(endpoint, "/"),
-		AuditPath:   c.Request().URL.Path,
-		Model:       resolvedModelFromWorkflow(workflow, req.Model),
-	}
-	passthrough := passthroughService{
-		provider:        s.provider,
-		logger:          s.logger,
-		usageLogger:     s.usageLogger,
-		pricingResolver: s.pricingResolver,
-	}
-	return true, passthrough.proxyPassthroughResponse(c, providerType, providerNameFromWorkflow(workflow), endpoint, info, resp)
+	return true, s.proxyRawRequestResponse(c, workflow, endpoint, req.Model, resp)
 }
 
 func (s *translatedInferenceService) Embeddings(c *echo.Context) error {
@@ -370,6 +427,8 @@ func (s *translatedInferenceService) Embeddings(c *echo.Context) error {
 		return handleError(c, core.NewInvalidRequestError("invalid request body: "+err.Error(), err))
 	}
 
+	requestedModel := req.Model
+
 	prepared, err := s.inference().PrepareEmbeddingRequest(c.Request().Context(), req, translatedRequestMeta(c))
 	if err != nil {
 		return handleError(c, err)
@@ -377,6 +436,9 @@ func (s *translatedInferenceService) Embeddings(c *echo.Context) error {
 	attachPreparedWorkflow(c, prepared.Context, prepared.Workflow)
 
 	requestID := requestIDFromContextOrHeader(c.Request())
+	if handled, err := s.cachedEmbeddings(c, requestedModel, prepared.Workflow, prepared.Request, requestID); handled {
+		return err
+	}
 	result, err := s.inference().ExecuteEmbeddings(c.Request().Context(), prepared.Workflow, prepared.Request, requestID, "/v1/embeddings")
 	if err != nil {
 		return handleError(c, err)
@@ -387,16 +449,149 @@ func (s *translatedInferenceService) Embeddings(c *echo.Context) error {
 		result.Meta.ProviderType,
 		result.Meta.ProviderName,
 	)
+	auditlog.EnrichEntryWithServedModel(c, result.Response.Model)
 
 	return c.JSON(http.StatusOK, result.Response)
 }
 
+// cachedEmbeddings serves an embeddings request through the embeddings
+// cache, sending only the uncached inputs upstream. It reports false when the
+// cache does not apply to the request or the client bypasses caching with
+// Cache-Control: no-cache or no-store.
+func (s *translatedInferenceService) cachedEmbeddings(c *echo.Context, requestedModel string, workflow *core.Workflow, req *core.EmbeddingRequest, requestID string) (bool, error) {
+	if s.embeddingCache == nil || responsecache.ShouldSkipAllCache(c.Request()) {
+		return false, nil
+	}
+	providerType := gateway.ProviderTypeFromWorkflow(workflow)
+	providerName := providerNameFromWorkflow(workflow)
+	resolvedModel := resolvedModelFromWorkflow(workflow, req.Model)
+	qualifiedModel := qualifyExecutedModel(workflow, resolvedModel, providerName)
+
+	var meta gateway.ExecutionMeta
+	execute := func(ctx context.Context, missReq *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+		executed, err := s.inference().ExecuteEmbeddings(ctx, workflow, missReq, requestID, "/v1/embeddings")
+		if err != nil {
+			return nil, err
+		}
+		meta = executed.Meta
+		return executed.Response, nil
+	}
+	selectors := []string{requestedModel, qualifiedModel, resolvedModel}
+	result, ok, err := s.embeddingCache.Embed(c.Request().Context(), qualifiedModel, selectors, req, execute)
+	if !ok {
+		return false, nil
+	}
+	if err != nil {
+		return true, handleError(c, err)
+	}
+
+	if result.Upstream() {
+		providerType, providerName = meta.ProviderType, meta.ProviderName
+	}
+	servedModel := result.Response.Model
+	if servedModel == "" {
+		servedModel = resolvedModel
+	}
+	auditlog.EnrichEntryWithResolvedRoute(c, qualifyExecutedModel(workflow, servedModel, providerName), providerType, providerName)
+	auditlog.EnrichEntryWithServedModel(c, servedModel)
+	auditlog.EnrichEntryWithEmbeddingCache(c, result.Hits, result.Misses)
+
+	header := c.Response().Header()
+	header.Set(core.EmbeddingCacheHitsHeader, strconv.Itoa(result.Hits))
+	header.Set(core.EmbeddingCacheMissesHeader, strconv.Itoa(result.Misses))
+	return true, c.JSON(http.StatusOK, result.Response)
+}
+
 func translatedRequestMeta(c *echo.Context) gateway.RequestMeta {
 	return gateway.RequestMeta{
-		RequestID: requestIDFromContextOrHeader(c.Request()),
-		Endpoint:  core.DescribeEndpoint(c.Request().Method, c.Request().URL.Path),
-		Workflow:  core.GetWorkflow(c.Request().Context()),
+		RequestID:         requestIDFromContextOrHeader(c.Request()),
+		Endpoint:          core.DescribeEndpoint(c.Request().Method, c.Request().URL.Path),
+		Workflow:          core.GetWorkflow(c.Request().Context()),
+		ContextOverflow:   core.ContextOverflowStrategy(c.Request().Header.Get(core.ContextOverflowHeader)),
+		PromptCompression: promptCompressionOption(c),
+	}
+}
+
+// promptCompressionOption returns the prompt compression passes the request
+// selected, empty for the configured ones.
+func promptCompressionOption(c *echo.Context) string {
+	opts, err := requestOptions(c)
+	if err != nil {
+		// Left to the gateway, which rejects an invalid header with its own
+		// error.
+		return c.Request().Header.Get(core.PromptCompressionHeader)
+	}
+	if opts == nil {
+		return ""
+	}
+	return opts.PromptCompression
+}
+
+// reportPromptCompression exposes the applied prompt compression passes and
+// the estimated tokens saved through response headers and the audit entry.
+func reportPromptCompression(c *echo.Context, result *core.PromptCompressionResult) {
+	if result == nil {
+		return
+	}
+	passes := make([]string, len(result.Passes))
+	for i, pass := range result.Passes {
+		passes[i] = string(pass)
+	}
+	header := c.Response().Header()
+	header.Set(core.PromptCompressionHeader, strings.Join(passes, ","))
+	header.Set(core.PromptTokensSavedHeader, strconv.Itoa(result.TokensSaved))
+	auditlog.EnrichEntryWithPromptCompression(c, result)
+}
+
+// reportContextOverflow exposes the applied context overflow strategy through
+// response headers and the audit entry.
+func reportContextOverflow(c *echo.Context, result *core.ContextOverflowResult) {
+	if result == nil {
+		return
+	}
+	header := c.Response().Header()
+	header.Set(core.ContextOverflowHeader, string(result.Strategy))
+	header.Set(core.ContextOverflowDroppedHeader, strconv.Itoa(result.DroppedMessages))
+	auditlog.EnrichEntryWithContextOverflow(c, result)
+}
+
+// reportMaxTokensDefault exposes the max_tokens default set on a request
+// without a limit through response headers.
+func reportMaxTokensDefault(c *echo.Context, applied *core.MaxTokensDefault) {
+	if applied == nil {
+		return
 	}
+	header := c.Response().Header()
+	header.Set(core.DefaultMaxTokensHeader, strconv.Itoa(applied.Value))
+	header.Set(core.DefaultMaxTokensSourceHeader, string(applied.Source))
+}
+
+// reportModeration exposes the moderation pre-check outcome of a request
+// that was let through via response headers and the audit entry.
+func reportModeration(c *echo.Context, orchestrator *gateway.InferenceOrchestrator, result *core.ModerationResult) {
+	if result == nil {
+		return
+	}
+	header := c.Response().Header()
+	if result.Unavailable {
+		header.Set(core.ModerationHeader, "unavailable")
+	} else {
+		header.Set(core.ModerationHeader, "flagged")
+		header.Set(core.ModerationCategoriesHeader, orchestrator.DescribeModerationCategories(result))
+	}
+	auditlog.EnrichEntryWithModeration(c, result)
+}
+
+// reportToolLoop exposes the server-side tool loop that answered a request
+// via a response header and the audit entry. The loop is also attached to
+// the request context so the stream usage observer records its iterations.
+func reportToolLoop(c *echo.Context, result *core.ToolLoopResult) {
+	if result == nil {
+		return
+	}
+	c.Response().Header().Set(core.ToolIterationsHeader, strconv.Itoa(result.Iterations))
+	c.SetRequest(c.Request().WithContext(core.WithToolLoop(c.Request().Context(), result)))
+	auditlog.EnrichEntryWithToolLoop(c, result)
 }
 
 func attachPreparedWorkflow(c *echo.Context, ctx context.Context, workflow *core.Workflow) {
@@ -424,10 +619,13 @@ func (s *translatedInferenceService) handleStreamingReadCloser(
 	failoverModel string,
 	stream io.ReadCloser,
 ) error {
+	stream = applyChaosStreamFault(c, stream)
 	auditlog.MarkEntryAsStreaming(c, true)
 	auditlog.EnrichEntryWithStream(c, true)
 	auditlog.EnrichEntryWithFailover(c, failoverModel)
 	auditlog.EnrichEntryWithResolvedRoute(c, qualifyExecutedModel(workflow, model, providerName), provider, providerName)
+	auditlog.EnrichEntryWithUpstreamCall(c)
+	recordStreamFormat(c)
 
 	entry := auditlog.GetStreamEntryFromContext(c)
 	auditEnabled := s.logger != nil && s.logger.Config().Enabled && (workflow == nil || workflow.AuditEnabled())
@@ -445,12 +643,8 @@ func (s *translatedInferenceService) handleStreamingReadCloser(
 	if auditEnabled && streamEntry != nil {
 		observers = append(observers, auditlog.NewStreamLogObserver(s.logger, streamEntry, endpoint))
 	}
-	if s.usageLogger != nil && s.usageLogger.Config().Enabled && (workflow == nil || workflow.UsageEnabled()) {
-		usageObserver := usage.NewStreamUsageObserver(s.usageLogger, model, provider, requestID, endpoint, s.pricingResolver, core.UserPathFromContext(c.Request().Context()))
-		if usageObserver != nil {
-			usageObserver.SetProviderName(providerName)
-			observers = append(observers, usageObserver)
-		}
+	if usageObserver := s.streamUsageObserver(c, workflow, model, provider, providerName); usageObserver != nil {
+		observers = append(observers, usageObserver)
 	}
 	wrappedStream := streaming.NewObservedSSEStream(stream, observers...)
 
@@ -467,12 +661,44 @@ func (s *translatedInferenceService) handleStreamingReadCloser(
 	}
 
 	c.Response().WriteHeader(http.StatusOK)
-	if err := flushStream(c.Response(), wrappedStream); err != nil {
+	stats, err := pumpStream(c.Response(), wrappedStream, s.streamBackpressure.forRequest(c))
+	recordStreamBackpressure(c, streamEntry, stats)
+	recordStreamLimit(c, streamEntry, stats)
+	recordStreamTiming(c, streamEntry, stats, provider, model)
+	recordStreamResponseTransforms(c, streamEntry)
+	if err != nil {
 		recordStreamingError(streamEntry, model, provider, c.Request().URL.Path, requestID, err)
+		finishFailedStream(c, err)
 	}
 	return nil
 }
 
+// streamUsageObserver returns the observer that records usage from a stream,
+// or nil when usage tracking is off for the request.
+func (s *translatedInferenceService) streamUsageObserver(c *echo.Context, workflow *core.Workflow, model, provider, providerName string) streaming.Observer {
+	if s.usageLogger == nil || !s.usageLogger.Config().Enabled || (workflow != nil && !workflow.UsageEnabled()) {
+		return nil
+	}
+	usageObserver := usage.NewStreamUsageObserver(s.usageLogger, model, provider, requestIDFromContextOrHeader(c.Request()), c.Request().URL.Path, s.pricingResolver, core.UserPathFromContext(c.Request().Context()))
+	if usageObserver == nil {
+		return nil
+	}
+	usageObserver.SetProviderName(providerName)
+	usageObserver.SetRequestedModel(workflow.RequestedQualifiedModel())
+	usageObserver.SetLabels(core.GetRequestLabels(c.Request().Context()))
+	usageObserver.SetInlineImages(core.GetInlineImageStats(c.Request().Context()))
+	usageObserver.SetPromptTemplate(core.GetPromptTemplate(c.Request().Context()))
+	usageObserver.SetUser(core.GetRequestUser(c.Request().Context()))
+	usageObserver.SetAuthKeyID(core.GetAuthKeyID(c.Request().Context()))
+	if loop := core.GetToolLoop(c.Request().Context()); loop != nil {
+		usageObserver.SetToolLoopIterations(loop.Iterations)
+	}
+	if workflow != nil && workflow.Resolution != nil && workflow.Resolution.Experiment != nil {
+		usageObserver.SetExperiment(workflow.Resolution.Experiment.Experiment, workflow.Resolution.Experiment.Variant)
+	}
+	return usageObserver
+}
+
 func (s *translatedInferenceService) handleStreamingResponse(
 	c *echo.Context,
 	workflow *core.Workflow,
@@ -489,13 +715,16 @@ func (s *translatedInferenceService) handleStreamingResponse(
 func recordStreamingError(streamEntry *auditlog.LogEntry, model, provider, path, requestID string, err error) {
 	if streamEntry != nil {
 		streamEntry.ErrorType = "stream_error"
+		if errors.Is(err, errStreamLimitExceeded) {
+			streamEntry.ErrorType = "stream_limit_exceeded"
+		}
 		if streamEntry.Data == nil {
 			streamEntry.Data = &auditlog.LogData{}
 		}
 		streamEntry.Data.ErrorMessage = err.Error()
 	}
 
-	slog.Warn("stream terminated abnormally",
+	serverLogger.Warn("stream terminated abnormally",
 		"error", err,
 		"model", model,
 		"provider", provider,
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+)
+
+// UpstreamResponseHeaders passes the provider response headers selected by
+// policy through to the client. Provider clients record the headers of each
+// response in the request context; they are set on the client response
+// before its status line is written, so streaming responses carry them ahead
+// of the first event. Headers the gateway set itself are never replaced. A
+// nil policy passes nothing through.
+func UpstreamResponseHeaders(policy *core.UpstreamHeaderPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if policy == nil {
+			return next
+		}
+		return func(c *echo.Context) error {
+			headers := core.NewUpstreamResponseHeaders(*policy)
+			req := c.Request()
+			c.SetRequest(req.WithContext(core.WithUpstreamResponseHeaders(req.Context(), headers)))
+
+			original := c.Response()
+			w := &upstreamHeaderWriter{ResponseWriter: original, headers: headers}
+			c.SetResponse(w)
+			err := next(c)
+			// Errors returned unwritten are rendered by the error handler
+			// after this middleware, so apply the headers now.
+			w.apply()
+			c.SetResponse(original)
+			return err
+		}
+	}
+}
+
+// upstreamHeaderWriter copies the collected upstream headers onto the
+// response until it is committed. They are refreshed whenever the handler
+// reads the header map, so handlers that capture headers for the audit log
+// before writing the status see them too.
+type upstreamHeaderWriter struct {
+	http.ResponseWriter
+	headers   *core.UpstreamResponseHeaders
+	applied   map[string]struct{}
+	committed bool
+}
+
+func (w *upstreamHeaderWriter) apply() {
+	if w.committed {
+		return
+	}
+	upstream := w.headers.Header()
+	header := w.ResponseWriter.Header()
+	for name := range w.applied {
+		if _, ok := upstream[name]; !ok {
+			header.Del(name)
+			delete(w.applied, name)
+		}
+	}
+	for name, values := range upstream {
+		if _, ours := w.applied[name]; !ours && len(header.Values(name)) > 0 {
+			continue
+		}
+		header[name] = values
+		if w.applied == nil {
+			w.applied = make(map[string]struct{})
+		}
+		w.applied[name] = struct{}{}
+	}
+}
+
+func (w *upstreamHeaderWriter) Header() http.Header {
+	w.apply()
+	return w.ResponseWriter.Header()
+}
+
+func (w *upstreamHeaderWriter) WriteHeader(code int) {
+	w.apply()
+	w.committed = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *upstreamHeaderWriter) Write(b []byte) (int, error) {
+	w.apply()
+	w.committed = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *upstreamHeaderWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *upstreamHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+)
+
+var testUpstreamHeader = http.Header{
+	"X-Ratelimit-Remaining-Tokens": {"9000"},
+	"Openai-Processing-Ms":         {"42"},
+	"Anthropic-Ratelimit-Requests": {"10"},
+	"Set-Cookie":                   {"session=secret"},
+	"Authorization":                {"Bearer upstream"},
+	"X-Request-Id":                 {"upstream-req"},
+	"X-Other":                      {"not allowed"},
+}
+
+func serveUpstreamHeaders(policy *core.UpstreamHeaderPolicy, handler echo.HandlerFunc) *http.Response {
+	e := echo.New()
+	e.POST("/v1/chat/completions", handler, UpstreamResponseHeaders(policy))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+	// Result reports the headers as they were when the status was written.
+	return rec.Result()
+}
+
+func TestUpstreamResponseHeaders_PassesAllowedHeadersOnJSONResponse(t *testing.T) {
+	policy := &core.UpstreamHeaderPolicy{Allow: []string{"x-ratelimit-remaining-tokens", "set-cookie", "authorization"}, Prefix: "X-Upstream-"}
+
+	resp := serveUpstreamHeaders(policy, func(c *echo.Context) error {
+		core.RecordUpstreamResponseHeaders(c.Request().Context(), testUpstreamHeader, []string{"anthropic-ratelimit-*"})
+		return c.JSON(http.StatusOK, map[string]string{"id": "1"})
+	})
+
+	for name, want := range map[string]string{
+		"X-Upstream-X-Ratelimit-Remaining-Tokens": "9000",
+		"X-Upstream-Anthropic-Ratelimit-Requests": "10",
+	} {
+		if got := resp.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"X-Upstream-Set-Cookie", "Set-Cookie", "X-Upstream-Authorization", "X-Upstream-Openai-Processing-Ms", "X-Upstream-X-Other"} {
+		if got := resp.Header.Get(name); got != "" {
+			t.Errorf("%s = %q, want it not passed through", name, got)
+		}
+	}
+}
+
+func TestUpstreamResponseHeaders_StreamCarriesHeadersBeforeFirstByte(t *testing.T) {
+	policy := &core.UpstreamHeaderPolicy{Allow: []string{"openai-processing-ms"}}
+	var auditHeaders http.Header
+
+	resp := serveUpstreamHeaders(policy, func(c *echo.Context) error {
+		core.RecordUpstreamResponseHeaders(c.Request().Context(), testUpstreamHeader, nil)
+		c.Response().Header().Set("Content-Type", "text/event-stream")
+		// Streaming handlers capture audit headers before writing the status.
+		auditHeaders = c.Response().Header().Clone()
+		c.Response().WriteHeader(http.StatusOK)
+		if _, err := c.Response().Write([]byte("data: {}\n\n")); err != nil {
+			return err
+		}
+		_ = http.NewResponseController(c.Response()).Flush()
+		core.RecordUpstreamResponseHeaders(c.Request().Context(), http.Header{"Openai-Processing-Ms": {"late"}}, nil)
+		return nil
+	})
+
+	if got := resp.Header.Get("Openai-Processing-Ms"); got != "42" {
+		t.Fatalf("Openai-Processing-Ms at first byte = %q, want 42", got)
+	}
+	if got := auditHeaders.Get("Openai-Processing-Ms"); got != "42" {
+		t.Fatalf("audit Openai-Processing-Ms = %q, want 42", got)
+	}
+}
+
+func TestUpstreamResponseHeaders_DoesNotReplaceGatewayHeaders(t *testing.T) {
+	policy := &core.UpstreamHeaderPolicy{Allow: []string{"x-request-id"}}
+
+	resp := serveUpstreamHeaders(policy, func(c *echo.Context) error {
+		c.Response().Header().Set("X-Request-Id", "gateway-req")
+		core.RecordUpstreamResponseHeaders(c.Request().Context(), testUpstreamHeader, nil)
+		return c.JSON(http.StatusOK, map[string]string{})
+	})
+
+	if got := resp.Header.Get("X-Request-Id"); got != "gateway-req" {
+		t.Fatalf("X-Request-Id = %q, want the gateway's", got)
+	}
+}
+
+func TestUpstreamResponseHeaders_AppliesToErrorsRenderedAfterHandler(t *testing.T) {
+	policy := &core.UpstreamHeaderPolicy{Allow: []string{"x-ratelimit-*"}}
+
+	resp := serveUpstreamHeaders(policy, func(c *echo.Context) error {
+		core.RecordUpstreamResponseHeaders(c.Request().Context(), testUpstreamHeader, nil)
+		return echo.NewHTTPError(http.StatusTooManyRequests, "rate limited")
+	})
+
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("X-Ratelimit-Remaining-Tokens") != "9000" {
+		t.Fatalf("response = %d %v, want 429 with the upstream rate limit header", resp.StatusCode, resp.Header)
+	}
+}
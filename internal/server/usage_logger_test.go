@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"sync"
 
 	"gomodel/internal/usage"
@@ -18,8 +19,9 @@ func (l *usageCaptureLogger) Write(entry *usage.UsageEntry) {
 	l.entries = append(l.entries, entry)
 }
 
-func (l *usageCaptureLogger) Config() usage.Config { return l.config }
-func (l *usageCaptureLogger) Close() error         { return nil }
+func (l *usageCaptureLogger) Config() usage.Config        { return l.config }
+func (l *usageCaptureLogger) Flush(context.Context) error { return nil }
+func (l *usageCaptureLogger) Close() error                { return nil }
 
 func (l *usageCaptureLogger) Entries() []*usage.UsageEntry {
 	l.mu.Lock()
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"gomodel/internal/core"
+	"gomodel/internal/sse"
+	"gomodel/internal/websocket"
+)
+
+// WebSocketConfig enables GET /v1/chat/completions/ws, which streams chat
+// completions over a WebSocket for clients that cannot consume SSE. Zero
+// values use the defaults.
+type WebSocketConfig struct {
+	PingInterval time.Duration // Keepalive ping interval; a connection silent for two intervals is closed. Default: 30s
+	MaxDuration  time.Duration // Longest a connection may stay open. Default: 30m
+}
+
+const (
+	defaultWebSocketPingInterval = 30 * time.Second
+	defaultWebSocketMaxDuration  = 30 * time.Minute
+	// webSocketCloseGrace is how long the server waits for the client to
+	// answer its close frame before dropping the connection.
+	webSocketCloseGrace = 2 * time.Second
+	// webSocketHTTPErrorBase is added to the HTTP status of a rejected request
+	// to form its close code, so a 401 closes with 4401.
+	webSocketHTTPErrorBase = 4000
+)
+
+func (cfg WebSocketConfig) withDefaults() WebSocketConfig {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultWebSocketPingInterval
+	}
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = defaultWebSocketMaxDuration
+	}
+	return cfg
+}
+
+// webSocketHopHeaders describe the upgrade and are not copied to the chat
+// request the WebSocket carries.
+var webSocketHopHeaders = []string{
+	"Connection",
+	"Upgrade",
+	"Sec-WebSocket-Key",
+	"Sec-WebSocket-Version",
+	"Sec-WebSocket-Extensions",
+	"Sec-WebSocket-Protocol",
+}
+
+// ChatCompletionWebSocket streams one chat completion over a WebSocket. The
+// client sends the chat request as the first text message; each chunk
+// arrives as a text message with the JSON payload of the matching SSE data
+// line, followed by a final "[DONE]" message and a normal close.
+//
+// The request is replayed through e as POST /v1/chat/completions with the
+// upgrade request's headers and "stream": true, so authentication, routing,
+// guardrails, usage and audit capture behave exactly as for SSE. Rejected
+// requests send their JSON error as a message and close with 4000 plus the
+// HTTP status; errors after the stream started close with 1011.
+func ChatCompletionWebSocket(e *echo.Echo, cfg WebSocketConfig, maxMessageBytes int64) echo.HandlerFunc {
+	cfg = cfg.withDefaults()
+	return func(c *echo.Context) error {
+		req := c.Request()
+		if !websocket.IsUpgrade(req) {
+			return handleError(c, core.NewInvalidRequestErrorWithStatus(http.StatusUpgradeRequired,
+				"this endpoint requires a WebSocket upgrade; use POST /v1/chat/completions for SSE", nil))
+		}
+		conn, err := websocket.Upgrade(c.Response(), req)
+		if err != nil {
+			return handleError(c, core.NewInvalidRequestError(err.Error(), err))
+		}
+		serveChatWebSocket(e, conn, req, cfg, maxMessageBytes)
+		return nil
+	}
+}
+
+func serveChatWebSocket(e *echo.Echo, conn *websocket.Conn, upgrade *http.Request, cfg WebSocketConfig, maxMessageBytes int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MaxDuration)
+	defer cancel()
+
+	conn.SetIdleTimeout(2 * cfg.PingInterval)
+	_, body, err := conn.ReadMessage(maxMessageBytes)
+	if err != nil {
+		_ = conn.Close(websocket.ClosePolicyViolation, "no chat request received")
+		return
+	}
+
+	// The reader watches for the client closing or going silent; the request
+	// is canceled when it does. The client sends nothing else.
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			if _, _, err := conn.ReadMessage(maxMessageBytes); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(cfg.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if conn.Ping() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	inner, err := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/chat/completions", bytes.NewReader(forceStream(body)))
+	if err != nil {
+		_ = conn.Close(websocket.CloseInternalError, "")
+		return
+	}
+	inner.Header = upgrade.Header.Clone()
+	for _, name := range webSocketHopHeaders {
+		inner.Header.Del(name)
+	}
+	inner.Header.Set("Content-Type", "application/json")
+	inner.URL.RawQuery = upgrade.URL.RawQuery
+	inner.RequestURI = inner.URL.RequestURI()
+	inner.RemoteAddr = upgrade.RemoteAddr
+	inner.Host = upgrade.Host
+
+	w := &webSocketStreamWriter{conn: conn, header: make(http.Header)}
+	e.ServeHTTP(w, inner)
+
+	code, reason := w.closeCode()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		code, reason = websocket.CloseGoingAway, "maximum connection duration reached"
+	}
+	_ = conn.WriteClose(code, reason)
+	select {
+	case <-readerDone:
+	case <-time.After(webSocketCloseGrace):
+	}
+	_ = conn.Close(code, reason)
+}
+
+// forceStream sets "stream": true on a JSON object request. Anything else is
+// passed on unchanged for the chat handler to reject.
+func forceStream(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return body
+	}
+	fields["stream"] = json.RawMessage("true")
+	patched, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return patched
+}
+
+// webSocketStreamWriter receives the SSE response of the replayed chat
+// request and sends the data of each event as a WebSocket text message.
+// Responses that are not event streams, such as errors written before the
+// stream started, are buffered and sent as one message.
+type webSocketStreamWriter struct {
+	conn   *websocket.Conn
+	header http.Header
+	status int
+
+	mu       sync.Mutex
+	parser   sse.Parser
+	buffered bytes.Buffer
+	done     bool
+	errored  bool
+	writeErr error
+}
+
+func (w *webSocketStreamWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *webSocketStreamWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *webSocketStreamWriter) streaming() bool {
+	return w.status == http.StatusOK && strings.HasPrefix(w.header.Get("Content-Type"), "text/event-stream")
+}
+
+func (w *webSocketStreamWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+	if !w.streaming() {
+		return w.buffered.Write(b)
+	}
+	w.parser.Feed(b, w.send)
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+	return len(b), nil
+}
+
+func (w *webSocketStreamWriter) send(event sse.Event) {
+	if w.writeErr != nil || len(event.Data) == 0 {
+		return
+	}
+	if event.Type == "error" {
+		w.errored = true
+	}
+	if sse.IsDone(event.Data) {
+		w.done = true
+		w.writeErr = w.conn.WriteText([]byte(sse.DoneData))
+		return
+	}
+	w.writeErr = w.conn.WriteText(event.Data)
+}
+
+func (w *webSocketStreamWriter) Flush() {}
+
+// closeCode sends any buffered response and returns the close code that
+// describes how the request ended.
+func (w *webSocketStreamWriter) closeCode() (int, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.streaming() {
+		w.parser.Flush(w.send)
+		if w.done && !w.errored {
+			return websocket.CloseNormal, ""
+		}
+		return websocket.CloseInternalError, "stream ended with an error"
+	}
+	if w.buffered.Len() > 0 && w.writeErr == nil {
+		_ = w.conn.WriteText(w.buffered.Bytes())
+	}
+	status := w.status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	if status < http.StatusBadRequest {
+		return websocket.CloseNormal, ""
+	}
+	return webSocketHTTPErrorBase + min(status, 999), http.StatusText(status)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/core"
+	"gomodel/internal/usage"
+	"gomodel/internal/websocket"
+)
+
+func newWebSocketTestServer(t *testing.T, mock *mockProvider, cfg *Config) string {
+	t.Helper()
+	cfg.WebSocket = &WebSocketConfig{PingInterval: time.Second, MaxDuration: time.Minute}
+	ts := httptest.NewServer(New(mock, cfg))
+	t.Cleanup(ts.Close)
+	return "ws" + strings.TrimPrefix(ts.URL, "http") + "/v1/chat/completions/ws"
+}
+
+// readAll reads messages until the server closes the connection.
+func readAll(t *testing.T, conn *websocket.Conn) ([]string, *websocket.CloseError) {
+	t.Helper()
+	var messages []string
+	for {
+		_, data, err := conn.ReadMessage(0)
+		if err != nil {
+			closeErr, ok := errors.AsType[*websocket.CloseError](err)
+			if !ok {
+				t.Fatalf("ReadMessage() error = %v, want a close frame", err)
+			}
+			return messages, closeErr
+		}
+		messages = append(messages, string(data))
+	}
+}
+
+func TestChatCompletionWebSocket_StreamsSSEChunks(t *testing.T) {
+	stream := chatStreamChunks(`{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}`, "Hel", "lo")
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: stream}
+	auditLogger := &syncAuditLogger{config: auditlog.Config{Enabled: true}}
+	usageLogger := &syncUsageLogger{config: usage.Config{Enabled: true}}
+	url := newWebSocketTestServer(t, mock, &Config{AuditLogger: auditLogger, UsageLogger: usageLogger})
+
+	conn, _, err := websocket.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if err := conn.WriteText([]byte(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`)); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	messages, closeErr := readAll(t, conn)
+
+	var want []string
+	for _, line := range strings.Split(stream, "\n\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			want = append(want, data)
+		}
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("messages =\n%s\nwant the SSE data lines\n%s", strings.Join(messages, "\n"), strings.Join(want, "\n"))
+	}
+	if closeErr.Code != websocket.CloseNormal {
+		t.Fatalf("close code = %d, want %d", closeErr.Code, websocket.CloseNormal)
+	}
+
+	usageLogger.mu.Lock()
+	defer usageLogger.mu.Unlock()
+	if len(usageLogger.entries) != 1 || usageLogger.entries[0].TotalTokens != 5 {
+		t.Fatalf("usage entries = %+v, want one with 5 tokens", usageLogger.entries)
+	}
+	auditLogger.mu.Lock()
+	defer auditLogger.mu.Unlock()
+	if len(auditLogger.entries) != 1 || auditLogger.entries[0].Path != "/v1/chat/completions" || !auditLogger.entries[0].Stream {
+		t.Fatalf("audit entries = %+v, want one streamed chat completion", auditLogger.entries)
+	}
+}
+
+func TestChatCompletionWebSocket_RequiresAuthBeforeUpgrade(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, streamData: chatStreamChunks("", "hi")}
+	url := newWebSocketTestServer(t, mock, &Config{MasterKey: "secret"})
+
+	if _, resp, err := websocket.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Dial() without a key = %v, %v, want a 401 handshake", resp, err)
+	}
+
+	conn, _, err := websocket.Dial(url, http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Dial() with the master key error = %v", err)
+	}
+	_ = conn.WriteText([]byte(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`))
+	if _, closeErr := readAll(t, conn); closeErr.Code != websocket.CloseNormal {
+		t.Fatalf("close code = %d, want %d", closeErr.Code, websocket.CloseNormal)
+	}
+}
+
+func TestChatCompletionWebSocket_RejectedRequestClosesWithHTTPStatus(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}, err: core.NewRateLimitError("openai", "slow down")}
+	url := newWebSocketTestServer(t, mock, &Config{})
+
+	conn, _, err := websocket.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	_ = conn.WriteText([]byte(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`))
+	messages, closeErr := readAll(t, conn)
+
+	if len(messages) != 1 || !strings.Contains(messages[0], `"slow down"`) {
+		t.Fatalf("messages = %q, want the JSON error", messages)
+	}
+	if closeErr.Code != 4429 {
+		t.Fatalf("close code = %d, want 4429", closeErr.Code)
+	}
+}
+
+func TestChatCompletionWebSocket_StreamErrorClosesWithInternalError(t *testing.T) {
+	mock := &mockProvider{
+		supportedModels: []string{"gpt-4o-mini"},
+		streamData:      `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hel"}}]}` + "\n\n",
+		streamErr:       errors.New("upstream reset"),
+	}
+	url := newWebSocketTestServer(t, mock, &Config{})
+
+	conn, _, err := websocket.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	_ = conn.WriteText([]byte(`{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`))
+	messages, closeErr := readAll(t, conn)
+
+	if len(messages) == 0 || !strings.Contains(messages[0], "Hel") {
+		t.Fatalf("messages = %q, want the chunk sent before the error", messages)
+	}
+	if closeErr.Code != websocket.CloseInternalError {
+		t.Fatalf("close code = %d, want %d", closeErr.Code, websocket.CloseInternalError)
+	}
+}
+
+func TestChatCompletionWebSocket_PlainGETNeedsUpgrade(t *testing.T) {
+	mock := &mockProvider{supportedModels: []string{"gpt-4o-mini"}}
+	url := newWebSocketTestServer(t, mock, &Config{})
+
+	resp, err := http.Get("http" + strings.TrimPrefix(url, "ws"))
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUpgradeRequired)
+	}
+}
+
+func TestChatCompletionWebSocket_NotRegisteredByDefault(t *testing.T) {
+	srv := New(&mockProvider{}, &Config{})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/chat/completions/ws", nil))
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want the route to be missing", rec.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package smoke
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteTable prints results as an aligned table followed by a summary line.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tMODEL\tCHECK\tSTATUS\tLATENCY\tTOKENS\tMESSAGE")
+	passed, failed, skipped := 0, 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case StatusPass:
+			passed++
+		case StatusFail:
+			failed++
+		case StatusSkip:
+			skipped++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			result.Provider,
+			result.Model,
+			result.Check,
+			result.Status,
+			result.Latency.Round(time.Millisecond),
+			result.Tokens,
+			result.Message,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes results as a JUnit XML report with one test case per
+// check, grouped under the provider as class name.
+func WriteJUnit(w io.Writer, results []Result) error {
+	suite := junitTestSuite{Name: "gomodel-smoke", Tests: len(results)}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Latency
+		tc := junitTestCase{
+			ClassName: result.Provider,
+			Name:      result.Model + " " + result.Check,
+			Time:      junitSeconds(result.Latency),
+		}
+		switch result.Status {
+		case StatusFail:
+			suite.Failures++
+			tc.Failure = &junitMessage{Message: result.Message}
+		case StatusSkip:
+			suite.Skipped++
+			tc.Skipped = &junitMessage{Message: result.Message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
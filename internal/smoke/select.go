@@ -0,0 +1,139 @@
+package smoke
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gomodel/internal/core"
+)
+
+// nonChatHints mark model IDs that cannot answer a chat request.
+var nonChatHints = []string{"embed", "rerank", "moderation", "whisper", "transcribe", "tts", "dall-e", "image", "audio", "realtime", "search"}
+
+// smallModelHints mark model IDs that are usually the cheapest in a family.
+var smallModelHints = []string{"nano", "mini", "tiny", "small", "lite", "flash", "haiku", "instant"}
+
+// SelectTargets picks the models a smoke run exercises. Explicit selectors
+// (provider/model) are used as given after checking the gateway lists them;
+// otherwise the cheapest chat model of every provider is chosen, by listed
+// pricing when known and by name hints when not.
+func SelectTargets(models []core.Model, explicit []string) ([]Target, error) {
+	if len(explicit) > 0 {
+		return explicitTargets(models, explicit)
+	}
+
+	byProvider := make(map[string][]core.Model)
+	for _, model := range models {
+		if !isChatModel(model) {
+			continue
+		}
+		provider := modelProvider(model)
+		byProvider[provider] = append(byProvider[provider], model)
+	}
+	if len(byProvider) == 0 {
+		return nil, fmt.Errorf("gateway lists no chat models")
+	}
+
+	targets := make([]Target, 0, len(byProvider))
+	for provider, candidates := range byProvider {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return cheaper(candidates[i], candidates[j])
+		})
+		targets = append(targets, Target{Provider: provider, Model: candidates[0].ID})
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Provider < targets[j].Provider })
+	return targets, nil
+}
+
+func explicitTargets(models []core.Model, explicit []string) ([]Target, error) {
+	listed := make(map[string]core.Model, len(models))
+	for _, model := range models {
+		listed[model.ID] = model
+	}
+	targets := make([]Target, 0, len(explicit))
+	for _, selector := range explicit {
+		selector = strings.TrimSpace(selector)
+		if selector == "" {
+			continue
+		}
+		model, ok := listed[selector]
+		if !ok {
+			return nil, fmt.Errorf("model %q is not listed by the gateway", selector)
+		}
+		targets = append(targets, Target{Provider: modelProvider(model), Model: model.ID})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no models selected")
+	}
+	return targets, nil
+}
+
+func modelProvider(model core.Model) string {
+	if model.OwnedBy != "" {
+		return model.OwnedBy
+	}
+	if provider, _, ok := strings.Cut(model.ID, "/"); ok {
+		return provider
+	}
+	return "unknown"
+}
+
+func isChatModel(model core.Model) bool {
+	if model.Metadata != nil && len(model.Metadata.Categories) > 0 {
+		chat := false
+		for _, category := range model.Metadata.Categories {
+			if category == core.CategoryTextGeneration {
+				chat = true
+				break
+			}
+		}
+		if !chat {
+			return false
+		}
+	}
+	id := strings.ToLower(model.ID)
+	for _, hint := range nonChatHints {
+		if strings.Contains(id, hint) {
+			return false
+		}
+	}
+	return true
+}
+
+// modelCost returns input plus output price per million tokens, or +Inf when
+// pricing is not listed.
+func modelCost(model core.Model) float64 {
+	if model.Metadata == nil || model.Metadata.Pricing == nil {
+		return math.Inf(1)
+	}
+	pricing := model.Metadata.Pricing
+	if pricing.InputPerMtok == nil || pricing.OutputPerMtok == nil {
+		return math.Inf(1)
+	}
+	return *pricing.InputPerMtok + *pricing.OutputPerMtok
+}
+
+func hasSmallHint(model core.Model) bool {
+	id := strings.ToLower(model.ID)
+	for _, hint := range smallModelHints {
+		if strings.Contains(id, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func cheaper(a, b core.Model) bool {
+	if costA, costB := modelCost(a), modelCost(b); costA != costB {
+		return costA < costB
+	}
+	if smallA, smallB := hasSmallHint(a), hasSmallHint(b); smallA != smallB {
+		return smallA
+	}
+	if len(a.ID) != len(b.ID) {
+		return len(a.ID) < len(b.ID)
+	}
+	return a.ID < b.ID
+}
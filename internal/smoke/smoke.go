@@ -0,0 +1,401 @@
+// Package smoke runs a short request matrix against a running gateway to
+// confirm every configured provider answers end-to-end.
+package smoke
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+)
+
+// Check names of the request matrix, in execution order.
+const (
+	CheckChat       = "chat"
+	CheckChatStream = "chat_stream"
+	CheckResponses  = "responses"
+)
+
+// DefaultChecks is the matrix run for every target when Config.Checks is empty.
+var DefaultChecks = []string{CheckChat, CheckChatStream, CheckResponses}
+
+const (
+	defaultMaxTokens  = 64
+	defaultMaxLatency = 30 * time.Second
+	defaultTimeout    = 2 * time.Minute
+
+	// Prompt is the user message every check sends.
+	Prompt = "Reply with the single word: pong"
+)
+
+// Status is the outcome of one smoke check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Config controls a smoke run.
+type Config struct {
+	// BaseURL is the gateway root, e.g. http://localhost:8080.
+	BaseURL string
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// Models pins the targets to explicit provider/model selectors instead of
+	// picking one model per provider.
+	Models []string
+	// Checks limits the matrix; empty runs DefaultChecks.
+	Checks []string
+	// MaxTokens caps the output tokens of every request (default 64).
+	MaxTokens int
+	// MaxLatency fails a check whose full response takes longer (default 30s).
+	MaxLatency time.Duration
+	// Budget caps the total estimated tokens of the run; 0 is unlimited.
+	// Checks that would exceed it are skipped.
+	Budget int
+	// HTTPClient overrides the client used for gateway calls.
+	HTTPClient *http.Client
+}
+
+// Target is one model the matrix runs against.
+type Target struct {
+	Provider string
+	Model    string
+}
+
+// Result is the outcome of one check against one target.
+type Result struct {
+	Provider string
+	Model    string
+	Check    string
+	Status   Status
+	Latency  time.Duration
+	Tokens   int
+	Message  string
+}
+
+// Runner executes smoke checks against a gateway.
+type Runner struct {
+	cfg    Config
+	client *http.Client
+	spent  int
+}
+
+// NewRunner validates cfg and returns a Runner.
+func NewRunner(cfg Config) (*Runner, error) {
+	cfg.BaseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("gateway URL is required")
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = defaultMaxTokens
+	}
+	if cfg.MaxLatency <= 0 {
+		cfg.MaxLatency = defaultMaxLatency
+	}
+	if cfg.Budget < 0 {
+		return nil, fmt.Errorf("budget must be non-negative")
+	}
+	if len(cfg.Checks) == 0 {
+		cfg.Checks = DefaultChecks
+	}
+	for _, check := range cfg.Checks {
+		switch check {
+		case CheckChat, CheckChatStream, CheckResponses:
+		default:
+			return nil, fmt.Errorf("unknown check %q", check)
+		}
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Runner{cfg: cfg, client: client}, nil
+}
+
+// Spent returns the tokens consumed so far, using reported usage when the
+// gateway returns it and the request estimate otherwise.
+func (r *Runner) Spent() int {
+	return r.spent
+}
+
+// Discover lists the models the gateway exposes on /v1/models.
+func (r *Runner) Discover(ctx context.Context) ([]core.Model, error) {
+	req, err := r.newRequest(ctx, http.MethodGet, "/v1/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read models: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list models: status %d: %s", resp.StatusCode, errorMessage(body))
+	}
+	var models core.ModelsResponse
+	if err := json.Unmarshal(body, &models); err != nil {
+		return nil, fmt.Errorf("decode models: %w", err)
+	}
+	return models.Data, nil
+}
+
+// Targets discovers the gateway models and selects the run targets: the
+// configured Models when set, otherwise one model per provider.
+func (r *Runner) Targets(ctx context.Context) ([]Target, error) {
+	models, err := r.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return SelectTargets(models, r.cfg.Models)
+}
+
+// Run executes the configured checks against every target in order.
+func (r *Runner) Run(ctx context.Context, targets []Target) []Result {
+	results := make([]Result, 0, len(targets)*len(r.cfg.Checks))
+	for _, target := range targets {
+		for _, check := range r.cfg.Checks {
+			results = append(results, r.RunCheck(ctx, target, check))
+		}
+	}
+	return results
+}
+
+// RunCheck executes one check against one target.
+func (r *Runner) RunCheck(ctx context.Context, target Target, check string) Result {
+	result := Result{Provider: target.Provider, Model: target.Model, Check: check}
+
+	estimate := estimateTokens(r.cfg.MaxTokens)
+	if r.cfg.Budget > 0 && r.spent+estimate > r.cfg.Budget {
+		result.Status = StatusSkip
+		result.Message = fmt.Sprintf("token budget exhausted (%d of %d used)", r.spent, r.cfg.Budget)
+		return result
+	}
+
+	var (
+		path    string
+		payload any
+	)
+	maxTokens := r.cfg.MaxTokens
+	switch check {
+	case CheckChat, CheckChatStream:
+		path = "/v1/chat/completions"
+		payload = core.ChatRequest{
+			Model:     target.Model,
+			Messages:  []core.Message{{Role: "user", Content: Prompt}},
+			MaxTokens: &maxTokens,
+			Stream:    check == CheckChatStream,
+		}
+	case CheckResponses:
+		path = "/v1/responses"
+		payload = core.ResponsesRequest{
+			Model:           target.Model,
+			Input:           Prompt,
+			MaxOutputTokens: &maxTokens,
+		}
+	default:
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("unknown check %q", check)
+		return result
+	}
+
+	started := time.Now()
+	status, body, err := r.post(ctx, path, payload)
+	result.Latency = time.Since(started)
+	r.spent += estimate
+	result.Tokens = estimate
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		return result
+	}
+	if check == CheckResponses && status == http.StatusNotImplemented {
+		result.Status = StatusSkip
+		result.Message = "responses not supported: " + errorMessage(body)
+		return result
+	}
+	if status != http.StatusOK {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("status %d: %s", status, errorMessage(body))
+		return result
+	}
+
+	var verr error
+	switch check {
+	case CheckChat:
+		verr = verifyChat(body)
+	case CheckChatStream:
+		verr = verifyChatStream(body)
+	case CheckResponses:
+		verr = verifyResponses(body)
+	}
+	if verr != nil {
+		result.Status = StatusFail
+		result.Message = verr.Error()
+		return result
+	}
+	if used := int(gjson.GetBytes(body, "usage.total_tokens").Int()); used > 0 {
+		r.spent += used - estimate
+		result.Tokens = used
+	}
+	if result.Latency > r.cfg.MaxLatency {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("latency %s exceeds %s", result.Latency.Round(time.Millisecond), r.cfg.MaxLatency)
+		return result
+	}
+	result.Status = StatusPass
+	return result
+}
+
+// Failed reports whether any result failed.
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Runner) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.cfg.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if r.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.APIKey)
+	}
+	return req, nil
+}
+
+func (r *Runner) post(ctx context.Context, path string, payload any) (int, []byte, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := r.newRequest(ctx, http.MethodPost, path, bytes.NewReader(encoded))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("read response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// estimateTokens approximates the cost of one check: the prompt at roughly
+// four characters per token plus the full output allowance.
+func estimateTokens(maxTokens int) int {
+	return (len(Prompt)+3)/4 + maxTokens
+}
+
+func errorMessage(body []byte) string {
+	if message := gjson.GetBytes(body, "error.message").String(); message != "" {
+		return message
+	}
+	text := strings.TrimSpace(string(body))
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
+func verifyChat(body []byte) error {
+	if object := gjson.GetBytes(body, "object").String(); object != "chat.completion" {
+		return fmt.Errorf("unexpected object %q", object)
+	}
+	choices := gjson.GetBytes(body, "choices")
+	if len(choices.Array()) == 0 {
+		return fmt.Errorf("response has no choices")
+	}
+	if strings.TrimSpace(choices.Get("0.message.content").String()) == "" {
+		return fmt.Errorf("response content is empty")
+	}
+	return nil
+}
+
+func verifyChatStream(body []byte) error {
+	content, done, err := ChatStreamContent(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if !done {
+		return fmt.Errorf("stream ended without [DONE]")
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("stream content is empty")
+	}
+	return nil
+}
+
+func verifyResponses(body []byte) error {
+	if object := gjson.GetBytes(body, "object").String(); object != "response" {
+		return fmt.Errorf("unexpected object %q", object)
+	}
+	if status := gjson.GetBytes(body, "status").String(); status != "" && status != "completed" && status != "incomplete" {
+		return fmt.Errorf("response status %q", status)
+	}
+	if strings.TrimSpace(ResponsesOutputText(body)) == "" {
+		return fmt.Errorf("response output text is empty")
+	}
+	return nil
+}
+
+// ChatStreamContent reads a chat completion SSE stream and returns the
+// concatenated delta content and whether the [DONE] marker was seen.
+func ChatStreamContent(r io.Reader) (string, bool, error) {
+	var content strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			return content.String(), true, nil
+		}
+		if message := gjson.Get(data, "error.message").String(); message != "" {
+			return content.String(), false, fmt.Errorf("stream error: %s", message)
+		}
+		content.WriteString(gjson.Get(data, "choices.0.delta.content").String())
+	}
+	return content.String(), false, scanner.Err()
+}
+
+// ResponsesOutputText concatenates the output_text parts of a Responses API
+// response body.
+func ResponsesOutputText(body []byte) string {
+	var text strings.Builder
+	for _, item := range gjson.GetBytes(body, "output").Array() {
+		for _, part := range item.Get("content").Array() {
+			if part.Get("type").String() == "output_text" {
+				text.WriteString(part.Get("text").String())
+			}
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,189 @@
+package smoke
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+func price(input, output float64) *core.ModelMetadata {
+	return &core.ModelMetadata{Pricing: &core.ModelPricing{InputPerMtok: &input, OutputPerMtok: &output}}
+}
+
+func TestSelectTargets_PicksCheapestChatModelPerProvider(t *testing.T) {
+	models := []core.Model{
+		{ID: "openai/gpt-4o", OwnedBy: "openai", Metadata: price(2.5, 10)},
+		{ID: "openai/gpt-4o-mini", OwnedBy: "openai", Metadata: price(0.15, 0.6)},
+		{ID: "openai/text-embedding-3-small", OwnedBy: "openai", Metadata: price(0.02, 0)},
+		{ID: "anthropic/claude-sonnet-4", OwnedBy: "anthropic"},
+		{ID: "anthropic/claude-haiku-4", OwnedBy: "anthropic"},
+		{ID: "ollama/llama3.2", OwnedBy: "ollama"},
+	}
+
+	targets, err := SelectTargets(models, nil)
+	if err != nil {
+		t.Fatalf("SelectTargets() error = %v", err)
+	}
+	want := []Target{
+		{Provider: "anthropic", Model: "anthropic/claude-haiku-4"},
+		{Provider: "ollama", Model: "ollama/llama3.2"},
+		{Provider: "openai", Model: "openai/gpt-4o-mini"},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("targets = %+v, want %+v", targets, want)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Fatalf("targets[%d] = %+v, want %+v", i, targets[i], want[i])
+		}
+	}
+}
+
+func TestSelectTargets_ExplicitModels(t *testing.T) {
+	models := []core.Model{{ID: "openai/gpt-4o", OwnedBy: "openai"}}
+
+	targets, err := SelectTargets(models, []string{"openai/gpt-4o"})
+	if err != nil || len(targets) != 1 || targets[0].Model != "openai/gpt-4o" {
+		t.Fatalf("SelectTargets() = %+v, %v", targets, err)
+	}
+	if _, err := SelectTargets(models, []string{"openai/gpt-missing"}); err == nil {
+		t.Fatal("SelectTargets() accepted a model the gateway does not list")
+	}
+}
+
+// fakeGateway answers the smoke matrix like a healthy gateway and counts the
+// model requests it receives.
+func fakeGateway(t *testing.T, responsesStatus int) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"openai/gpt-4o-mini","owned_by":"openai"}]}`))
+		case "/v1/chat/completions":
+			calls++
+			body := new(bytes.Buffer)
+			_, _ = body.ReadFrom(r.Body)
+			if strings.Contains(body.String(), `"stream":true`) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"po\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"ng\"}}]}\n\ndata: [DONE]\n\n"))
+				return
+			}
+			_, _ = w.Write([]byte(`{"object":"chat.completion","choices":[{"message":{"role":"assistant","content":"pong"}}],"usage":{"total_tokens":12}}`))
+		case "/v1/responses":
+			calls++
+			if responsesStatus != http.StatusOK {
+				w.WriteHeader(responsesStatus)
+				_, _ = w.Write([]byte(`{"error":{"message":"responses unsupported"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"object":"response","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"pong"}]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRunner_RunsMatrix(t *testing.T) {
+	srv, _ := fakeGateway(t, http.StatusNotImplemented)
+	runner, err := NewRunner(Config{BaseURL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	targets, err := runner.Targets(context.Background())
+	if err != nil {
+		t.Fatalf("Targets() error = %v", err)
+	}
+	results := runner.Run(context.Background(), targets)
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	want := map[string]Status{CheckChat: StatusPass, CheckChatStream: StatusPass, CheckResponses: StatusSkip}
+	for _, result := range results {
+		if result.Status != want[result.Check] {
+			t.Errorf("%s status = %s (%s), want %s", result.Check, result.Status, result.Message, want[result.Check])
+		}
+	}
+	if results[0].Tokens != 12 {
+		t.Errorf("chat tokens = %d, want reported usage 12", results[0].Tokens)
+	}
+	if Failed(results) {
+		t.Fatal("Failed() = true for passing and skipped results")
+	}
+}
+
+func TestRunner_FailsOnErrorsAndSlowResponses(t *testing.T) {
+	srv, _ := fakeGateway(t, http.StatusBadGateway)
+	runner, err := NewRunner(Config{BaseURL: srv.URL, APIKey: "secret", MaxLatency: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	target := Target{Provider: "openai", Model: "openai/gpt-4o-mini"}
+
+	chat := runner.RunCheck(context.Background(), target, CheckChat)
+	if chat.Status != StatusFail || !strings.Contains(chat.Message, "latency") {
+		t.Fatalf("chat = %+v, want latency failure", chat)
+	}
+	responses := runner.RunCheck(context.Background(), target, CheckResponses)
+	if responses.Status != StatusFail || !strings.Contains(responses.Message, "responses unsupported") {
+		t.Fatalf("responses = %+v, want upstream failure", responses)
+	}
+}
+
+func TestRunner_BudgetSkipsRemainingChecks(t *testing.T) {
+	srv, calls := fakeGateway(t, http.StatusOK)
+	runner, err := NewRunner(Config{BaseURL: srv.URL, APIKey: "secret", MaxTokens: 16, Budget: 40})
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+
+	results := runner.Run(context.Background(), []Target{{Provider: "openai", Model: "openai/gpt-4o-mini"}})
+	// Each check is estimated at 24 tokens (8 prompt + 16 output). The chat
+	// check reports 12, so the stream check fits in the budget and the
+	// responses check does not.
+	statuses := []Status{results[0].Status, results[1].Status, results[2].Status}
+	if statuses[0] != StatusPass || statuses[1] != StatusPass || statuses[2] != StatusSkip {
+		t.Fatalf("statuses = %v, want pass, pass, skip", statuses)
+	}
+	if *calls != 2 {
+		t.Fatalf("gateway calls = %d, want 2", *calls)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []Result{
+		{Provider: "openai", Model: "openai/gpt-4o-mini", Check: CheckChat, Status: StatusPass, Latency: time.Second},
+		{Provider: "openai", Model: "openai/gpt-4o-mini", Check: CheckResponses, Status: StatusFail, Message: "status 502"},
+		{Provider: "openai", Model: "openai/gpt-4o-mini", Check: CheckChatStream, Status: StatusSkip, Message: "budget"},
+	}
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, results); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+	suite := report.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Fatalf("suite = %+v", suite)
+	}
+	if suite.Cases[1].Failure == nil || suite.Cases[1].Failure.Message != "status 502" {
+		t.Fatalf("failure case = %+v", suite.Cases[1])
+	}
+}
@@ -0,0 +1,83 @@
+package sse
+
+import "encoding/json"
+
+// ChatCompletionChunk is the payload of an OpenAI chat.completion.chunk event.
+type ChatCompletionChunk struct {
+	ID       string                      `json:"id"`
+	Object   string                      `json:"object"`
+	Created  int64                       `json:"created"`
+	Model    string                      `json:"model"`
+	Provider string                      `json:"provider,omitempty"`
+	Choices  []ChatCompletionChunkChoice `json:"choices"`
+	// Usage stays untyped so providers' extra usage fields survive a round trip.
+	Usage map[string]any `json:"usage,omitempty"`
+}
+
+// ChatCompletionChunkChoice is one choice of a chat completion chunk.
+type ChatCompletionChunkChoice struct {
+	Index        int                      `json:"index"`
+	Delta        ChatCompletionChunkDelta `json:"delta"`
+	FinishReason string                   `json:"finish_reason,omitempty"`
+}
+
+// ChatCompletionChunkDelta is the incremental message content of a choice.
+type ChatCompletionChunkDelta struct {
+	Role             string                        `json:"role,omitempty"`
+	Content          string                        `json:"content,omitempty"`
+	ReasoningContent string                        `json:"reasoning_content,omitempty"`
+	ToolCalls        []ChatCompletionChunkToolCall `json:"tool_calls,omitempty"`
+	// Annotations stay raw; core.ParseChatAnnotations decodes them.
+	Annotations json.RawMessage `json:"annotations,omitempty"`
+}
+
+// ChatCompletionChunkToolCall is a tool call fragment. Index is nil when the
+// upstream omitted it.
+type ChatCompletionChunkToolCall struct {
+	Index    *int                        `json:"index,omitempty"`
+	ID       string                      `json:"id,omitempty"`
+	Type     string                      `json:"type,omitempty"`
+	Function ChatCompletionChunkFunction `json:"function"`
+}
+
+// ChatCompletionChunkFunction carries the tool name and an arguments fragment.
+type ChatCompletionChunkFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// DecodeChatCompletionChunk decodes the data of a chat completion stream
+// event. Callers should check IsDone first.
+func DecodeChatCompletionChunk(data []byte) (*ChatCompletionChunk, error) {
+	var chunk ChatCompletionChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// ResponsesStreamEvent is the payload of a Responses API stream event. Nested
+// objects stay raw because their shape depends on Type.
+type ResponsesStreamEvent struct {
+	Type           string          `json:"type"`
+	SequenceNumber int64           `json:"sequence_number"`
+	OutputIndex    int             `json:"output_index,omitempty"`
+	ContentIndex   int             `json:"content_index,omitempty"`
+	ItemID         string          `json:"item_id,omitempty"`
+	Delta          string          `json:"delta,omitempty"`
+	Response       json.RawMessage `json:"response,omitempty"`
+	Item           json.RawMessage `json:"item,omitempty"`
+}
+
+// DecodeResponsesStreamEvent decodes a Responses API stream event. The SSE
+// event name fills in Type when the payload omits it.
+func DecodeResponsesStreamEvent(event Event) (*ResponsesStreamEvent, error) {
+	var decoded ResponsesStreamEvent
+	if err := json.Unmarshal(event.Data, &decoded); err != nil {
+		return nil, err
+	}
+	if decoded.Type == "" {
+		decoded.Type = event.Type
+	}
+	return &decoded, nil
+}
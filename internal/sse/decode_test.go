@@ -0,0 +1,61 @@
+package sse
+
+import "testing"
+
+func TestDecodeChatCompletionChunk(t *testing.T) {
+	chunk, err := DecodeChatCompletionChunk([]byte(`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":null,"tool_calls":[{"index":1,"id":"call_1","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}},{"function":{"arguments":"x"}}]},"finish_reason":null}],"usage":{"prompt_tokens":3,"prompt_tokens_details":{"cached_tokens":1}}}`))
+	if err != nil {
+		t.Fatalf("DecodeChatCompletionChunk() error = %v", err)
+	}
+	if chunk.ID != "chatcmpl-1" || len(chunk.Choices) != 1 {
+		t.Fatalf("chunk = %+v", chunk)
+	}
+	choice := chunk.Choices[0]
+	if choice.Delta.Content != "" || choice.FinishReason != "" {
+		t.Fatalf("null content/finish_reason decoded as %q/%q", choice.Delta.Content, choice.FinishReason)
+	}
+	if len(choice.Delta.ToolCalls) != 2 {
+		t.Fatalf("tool calls = %d, want 2", len(choice.Delta.ToolCalls))
+	}
+	call := choice.Delta.ToolCalls[0]
+	if call.Index == nil || *call.Index != 1 || call.ID != "call_1" || call.Function.Name != "get_time" || call.Function.Arguments != `{"tz":` {
+		t.Fatalf("tool call = %+v", call)
+	}
+	if choice.Delta.ToolCalls[1].Index != nil {
+		t.Fatal("missing tool call index should decode as nil")
+	}
+	if _, ok := chunk.Usage["prompt_tokens_details"].(map[string]any); !ok {
+		t.Fatalf("usage = %v, want nested details preserved", chunk.Usage)
+	}
+
+	if _, err := DecodeChatCompletionChunk([]byte(DoneData)); err == nil {
+		t.Fatal("DecodeChatCompletionChunk([DONE]) error = nil")
+	}
+}
+
+func TestDecodeResponsesStreamEvent(t *testing.T) {
+	event, err := DecodeResponsesStreamEvent(Event{
+		Type: "response.output_text.delta",
+		Data: []byte(`{"type":"response.output_text.delta","sequence_number":4,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"Hi"}`),
+	})
+	if err != nil {
+		t.Fatalf("DecodeResponsesStreamEvent() error = %v", err)
+	}
+	if event.Type != "response.output_text.delta" || event.SequenceNumber != 4 || event.ItemID != "msg_1" || event.Delta != "Hi" {
+		t.Fatalf("event = %+v", event)
+	}
+
+	event, err = DecodeResponsesStreamEvent(Event{
+		Type: "response.completed",
+		Data: []byte(`{"response":{"id":"resp_1","status":"completed"}}`),
+	})
+	if err != nil {
+		t.Fatalf("DecodeResponsesStreamEvent() error = %v", err)
+	}
+	if event.Type != "response.completed" {
+		t.Fatalf("Type = %q, want the SSE event name", event.Type)
+	}
+	if string(event.Response) != `{"id":"resp_1","status":"completed"}` {
+		t.Fatalf("Response = %s", event.Response)
+	}
+}
@@ -0,0 +1,69 @@
+package sse
+
+import "bytes"
+
+// DoneData is the data payload OpenAI-compatible streams end with.
+const DoneData = "[DONE]"
+
+// DoneMessage is the framed terminal [DONE] event.
+const DoneMessage = "data: " + DoneData + "\n\n"
+
+// IsDone reports whether data is the terminal [DONE] marker.
+func IsDone(data []byte) bool {
+	return string(bytes.TrimSpace(data)) == DoneData
+}
+
+// AppendEvent appends the wire form of event to dst. Line breaks in Data are
+// split across data lines and line breaks in Type and ID are dropped, so the
+// output always frames exactly one event that parses back to the same Data.
+func AppendEvent(dst []byte, event Event) []byte {
+	if event.Type != "" {
+		dst = append(dst, "event: "...)
+		dst = appendFieldValue(dst, event.Type)
+		dst = append(dst, '\n')
+	}
+	if event.ID != "" {
+		dst = append(dst, "id: "...)
+		dst = appendFieldValue(dst, event.ID)
+		dst = append(dst, '\n')
+	}
+
+	data := event.Data
+	for {
+		dst = append(dst, "data: "...)
+		idx := bytes.IndexAny(data, "\r\n")
+		if idx == -1 {
+			dst = append(dst, data...)
+			dst = append(dst, '\n')
+			break
+		}
+		dst = append(dst, data[:idx]...)
+		dst = append(dst, '\n')
+		if data[idx] == '\r' && idx+1 < len(data) && data[idx+1] == '\n' {
+			idx++
+		}
+		data = data[idx+1:]
+	}
+	return append(dst, '\n')
+}
+
+// FormatEvent returns the wire form of event.
+func FormatEvent(event Event) string {
+	return string(AppendEvent(nil, event))
+}
+
+// FormatData returns the wire form of an unnamed event carrying data.
+func FormatData(data []byte) string {
+	return FormatEvent(Event{Data: data})
+}
+
+func appendFieldValue(dst []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\r', '\n', 0:
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{name: "data only", event: Event{Data: []byte(`{"a":1}`)}, want: "data: {\"a\":1}\n\n"},
+		{name: "named event", event: Event{Type: "response.created", Data: []byte("{}")}, want: "event: response.created\ndata: {}\n\n"},
+		{name: "id", event: Event{ID: "7", Data: []byte("x")}, want: "id: 7\ndata: x\n\n"},
+		{name: "empty data", event: Event{}, want: "data: \n\n"},
+		{name: "multi-line data", event: Event{Data: []byte("a\nb\r\nc\rd")}, want: "data: a\ndata: b\ndata: c\ndata: d\n\n"},
+		{name: "trailing newline", event: Event{Data: []byte("a\n")}, want: "data: a\ndata: \n\n"},
+		{name: "line breaks dropped from fields", event: Event{Type: "a\nb", ID: "1\r\n\x002", Data: []byte("x")}, want: "event: ab\nid: 12\ndata: x\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatEvent(tt.event); got != tt.want {
+				t.Fatalf("FormatEvent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatData_MatchesDoneMessage(t *testing.T) {
+	if got := FormatData([]byte(DoneData)); got != DoneMessage {
+		t.Fatalf("FormatData([DONE]) = %q, want %q", got, DoneMessage)
+	}
+	if !IsDone([]byte(" [DONE] ")) || IsDone([]byte("[DONE]x")) {
+		t.Fatal("IsDone() did not match the [DONE] marker exactly")
+	}
+}
+
+func FuzzEncoderRoundTrip(f *testing.F) {
+	f.Add("response.created", "1", []byte(`{"type":"response.created"}`))
+	f.Add("", "", []byte("a\r\nb\rc\n"))
+	f.Add("x\ny", "\x00", []byte(" leading space"))
+	f.Add("", "", []byte{})
+
+	f.Fuzz(func(t *testing.T, eventType, id string, data []byte) {
+		encoded := AppendEvent(nil, Event{Type: eventType, ID: id, Data: data})
+
+		var events []Event
+		p := &Parser{}
+		p.Feed(encoded, func(event Event) { events = append(events, event) })
+		if p.Buffered() != 0 {
+			t.Fatalf("encoded event left %d bytes buffered: %q", p.Buffered(), encoded)
+		}
+		p.Flush(func(event Event) { events = append(events, event) })
+		if len(events) != 1 {
+			t.Fatalf("encoded event parsed into %d events: %q", len(events), encoded)
+		}
+
+		stripped := func(s string) string { return strings.NewReplacer("\r", "", "\n", "", "\x00", "").Replace(s) }
+		wantData := bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\r"), []byte("\n"))
+		want := Event{Type: stripped(eventType), ID: stripped(id), Data: wantData}
+		if len(want.Data) == 0 {
+			want.Data = []byte{}
+		}
+		if !reflect.DeepEqual(events[0], want) {
+			t.Fatalf("round trip = %q, want %q", events[0], want)
+		}
+	})
+}
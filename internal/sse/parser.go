@@ -0,0 +1,170 @@
+// Package sse parses and encodes server-sent event streams.
+//
+// The parser follows the WHATWG event-stream rules: lines end in LF, CRLF or
+// a lone CR; event, data and id fields are recognised; lines starting with a
+// colon are comments; and a blank line dispatches the buffered event. Input
+// may arrive in arbitrary fragments.
+package sse
+
+import "bytes"
+
+// Event is one dispatched server-sent event.
+type Event struct {
+	// Type is the event field. Empty means the default "message" type.
+	Type string
+	// ID is the last event ID seen on the stream when the event dispatched.
+	ID string
+	// Data holds the event's data lines joined by "\n".
+	Data []byte
+}
+
+// Parser incrementally splits a byte stream into events. The zero value is
+// ready to use.
+type Parser struct {
+	// MaxEventBytes caps the bytes buffered for one event, counting field
+	// lines and their terminators. Oversized events are dropped whole and
+	// parsing resumes after the next blank line. Zero means no limit.
+	MaxEventBytes int
+
+	line        []byte
+	lineStarted bool
+	sawCR       bool
+	eventType   string
+	lastID      string
+	data        []byte
+	size        int
+	discarding  bool
+}
+
+// Feed parses chunk and calls emit for every event it completes. Events own
+// their Data slices.
+func (p *Parser) Feed(chunk []byte, emit func(Event)) {
+	for len(chunk) > 0 {
+		if p.sawCR {
+			p.sawCR = false
+			if chunk[0] == '\n' {
+				chunk = chunk[1:]
+				continue
+			}
+		}
+
+		idx := bytes.IndexAny(chunk, "\r\n")
+		if idx == -1 {
+			p.appendLine(chunk)
+			return
+		}
+		p.appendLine(chunk[:idx])
+		p.sawCR = chunk[idx] == '\r'
+		chunk = chunk[idx+1:]
+		p.endLine(emit)
+	}
+}
+
+// Flush ends the stream. A final unterminated line is parsed and the pending
+// event, if any, is dispatched, so streams that omit the trailing blank line
+// still yield their last event.
+func (p *Parser) Flush(emit func(Event)) {
+	p.sawCR = false
+	if p.lineStarted {
+		p.endLine(emit)
+	}
+	if p.discarding {
+		p.discarding = false
+		p.reset()
+		return
+	}
+	p.dispatch(emit)
+}
+
+// Buffered reports the bytes held for the event being assembled.
+func (p *Parser) Buffered() int {
+	return len(p.line) + len(p.data)
+}
+
+// Discarding reports whether the parser is skipping the rest of an oversized
+// event.
+func (p *Parser) Discarding() bool {
+	return p.discarding
+}
+
+func (p *Parser) appendLine(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	p.lineStarted = true
+	if p.discarding {
+		return
+	}
+	if p.MaxEventBytes > 0 && p.size+len(p.line)+len(b) > p.MaxEventBytes {
+		p.reset()
+		p.discarding = true
+		return
+	}
+	p.line = append(p.line, b...)
+}
+
+func (p *Parser) endLine(emit func(Event)) {
+	started := p.lineStarted
+	p.lineStarted = false
+	if p.discarding {
+		if !started {
+			p.discarding = false
+		}
+		return
+	}
+	if len(p.line) == 0 {
+		p.dispatch(emit)
+		return
+	}
+
+	p.size += len(p.line) + 1
+	p.processField(p.line)
+	p.line = p.line[:0]
+}
+
+func (p *Parser) processField(line []byte) {
+	if line[0] == ':' {
+		return
+	}
+
+	field, value := line, []byte(nil)
+	if idx := bytes.IndexByte(line, ':'); idx != -1 {
+		field, value = line[:idx], line[idx+1:]
+		if len(value) > 0 && value[0] == ' ' {
+			value = value[1:]
+		}
+	}
+
+	switch string(field) {
+	case "data":
+		p.data = append(p.data, value...)
+		p.data = append(p.data, '\n')
+	case "event":
+		p.eventType = string(value)
+	case "id":
+		if bytes.IndexByte(value, 0) == -1 {
+			p.lastID = string(value)
+		}
+	}
+}
+
+func (p *Parser) dispatch(emit func(Event)) {
+	if len(p.data) == 0 {
+		p.eventType = ""
+		p.size = 0
+		return
+	}
+
+	event := Event{Type: p.eventType, ID: p.lastID, Data: p.data[:len(p.data)-1]}
+	p.data = nil
+	p.eventType = ""
+	p.size = 0
+	emit(event)
+}
+
+func (p *Parser) reset() {
+	p.line = nil
+	p.data = nil
+	p.eventType = ""
+	p.size = 0
+}
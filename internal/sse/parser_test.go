@@ -0,0 +1,253 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseAll(t *testing.T, p *Parser, chunks ...string) []Event {
+	t.Helper()
+
+	var events []Event
+	emit := func(event Event) { events = append(events, event) }
+	for _, chunk := range chunks {
+		p.Feed([]byte(chunk), emit)
+	}
+	p.Flush(emit)
+	return events
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []Event
+	}{
+		{
+			name:   "data only",
+			chunks: []string{"data: {\"a\":1}\n\n"},
+			want:   []Event{{Data: []byte(`{"a":1}`)}},
+		},
+		{
+			name:   "event type and id",
+			chunks: []string{"event: response.created\nid: 7\ndata: {}\n\n"},
+			want:   []Event{{Type: "response.created", ID: "7", Data: []byte("{}")}},
+		},
+		{
+			name:   "multi-line data joins with LF",
+			chunks: []string{"data: first\ndata:second\ndata:  third\n\n"},
+			want:   []Event{{Data: []byte("first\nsecond\n third")}},
+		},
+		{
+			name:   "CRLF and lone CR terminators",
+			chunks: []string{"data: a\r\n\r\ndata: b\r\rdata: c\n\n"},
+			want:   []Event{{Data: []byte("a")}, {Data: []byte("b")}, {Data: []byte("c")}},
+		},
+		{
+			name:   "CRLF split across chunks",
+			chunks: []string{"data: a\r", "\n\r", "\ndata: b\r\n\r\n"},
+			want:   []Event{{Data: []byte("a")}, {Data: []byte("b")}},
+		},
+		{
+			name:   "comments and unknown fields are ignored",
+			chunks: []string{": keepalive\nretry: 10\nfoo\ndata: x\n\n: bye\n\n"},
+			want:   []Event{{Data: []byte("x")}},
+		},
+		{
+			name:   "event without data is not dispatched",
+			chunks: []string{"event: ping\n\ndata: x\n\n"},
+			want:   []Event{{Data: []byte("x")}},
+		},
+		{
+			name:   "empty data field dispatches empty data",
+			chunks: []string{"data\n\n"},
+			want:   []Event{{Data: []byte{}}},
+		},
+		{
+			name:   "id persists and ignores NUL",
+			chunks: []string{"id: 1\ndata: a\n\nid: 2\x00\ndata: b\n\n"},
+			want:   []Event{{ID: "1", Data: []byte("a")}, {ID: "1", Data: []byte("b")}},
+		},
+		{
+			name:   "pending event dispatches on flush",
+			chunks: []string{"data: a\n\ndata: b"},
+			want:   []Event{{Data: []byte("a")}, {Data: []byte("b")}},
+		},
+		{
+			name:   "byte at a time",
+			chunks: strings.Split("event: e\ndata: x\r\n\r\n", ""),
+			want:   []Event{{Type: "e", Data: []byte("x")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAll(t, &Parser{}, tt.chunks...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_DropsOversizedEvents(t *testing.T) {
+	p := &Parser{MaxEventBytes: 64}
+	oversized := "data: " + strings.Repeat("x", 100)
+
+	var events []Event
+	emit := func(event Event) { events = append(events, event) }
+	p.Feed([]byte(oversized[:40]), emit)
+	p.Feed([]byte(oversized[40:]), emit)
+	if !p.Discarding() {
+		t.Fatal("Discarding() = false, want true")
+	}
+	if got := p.Buffered(); got != 0 {
+		t.Fatalf("Buffered() = %d, want 0", got)
+	}
+
+	p.Feed([]byte("\ndata: more\n\r"), emit)
+	p.Feed([]byte("\ndata: fresh\n\n"), emit)
+	p.Flush(emit)
+
+	if p.Discarding() {
+		t.Fatal("Discarding() = true, want false")
+	}
+	if want := []Event{{Data: []byte("fresh")}}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+}
+
+func TestParser_DropsEventGrowingPastLimitAcrossLines(t *testing.T) {
+	p := &Parser{MaxEventBytes: 32}
+	events := parseAll(t, p, "data: 0123456789\ndata: 0123456789\ndata: 0123456789\n\ndata: ok\n\n")
+	if want := []Event{{Data: []byte("ok")}}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %q, want %q", events, want)
+	}
+}
+
+type chunkedReader struct {
+	chunks []string
+	err    error
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	if r.chunks[0] == "" {
+		r.chunks = r.chunks[1:]
+	}
+	return n, nil
+}
+
+func TestReader(t *testing.T) {
+	reader := NewReader(&chunkedReader{
+		chunks: []string{"event: a\nda", "ta: 1\n\ndata: 2\n\ndata: ", "3"},
+		err:    io.EOF,
+	})
+
+	var got []Event
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, event)
+	}
+
+	want := []Event{{Type: "a", Data: []byte("1")}, {Data: []byte("2")}, {Data: []byte("3")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("Next() after EOF error = %v, want io.EOF", err)
+	}
+}
+
+func TestReader_ReturnsErrorAfterParsedEvents(t *testing.T) {
+	readErr := errors.New("connection reset")
+	reader := NewReader(&chunkedReader{chunks: []string{"data: 1\n\ndata: partial"}, err: readErr})
+
+	event, err := reader.Next()
+	if err != nil || string(event.Data) != "1" {
+		t.Fatalf("Next() = %q, %v, want data 1", event.Data, err)
+	}
+	if _, err := reader.Next(); !errors.Is(err, readErr) {
+		t.Fatalf("Next() error = %v, want %v", err, readErr)
+	}
+}
+
+func TestReader_StopsOnReaderWithoutProgress(t *testing.T) {
+	reader := NewReader(&chunkedReader{})
+	if _, err := reader.Next(); !errors.Is(err, io.ErrNoProgress) {
+		t.Fatalf("Next() error = %v, want io.ErrNoProgress", err)
+	}
+}
+
+func FuzzParser(f *testing.F) {
+	f.Add([]byte("event: a\nid: 1\ndata: x\ndata: y\n\n: c\ndata: z"), uint8(3), uint16(0))
+	f.Add([]byte("data: a\r\n\r\ndata: b\r\rdata:c\n\n"), uint8(1), uint16(0))
+	f.Add([]byte("data: "+strings.Repeat("x", 80)+"\n\ndata: ok\n\n"), uint8(7), uint16(32))
+	f.Add([]byte("\r\r\n\n::\x00data\r"), uint8(2), uint16(4))
+
+	f.Fuzz(func(t *testing.T, input []byte, chunkSize uint8, maxEventBytes uint16) {
+		whole := &Parser{MaxEventBytes: int(maxEventBytes)}
+		var want []Event
+		whole.Feed(input, func(event Event) { want = append(want, event) })
+		whole.Flush(func(event Event) { want = append(want, event) })
+
+		size := max(int(chunkSize), 1)
+		chunked := &Parser{MaxEventBytes: int(maxEventBytes)}
+		var got []Event
+		emit := func(event Event) {
+			if chunked.MaxEventBytes > 0 && len(event.Data) > chunked.MaxEventBytes {
+				t.Fatalf("event data %d bytes exceeds limit %d", len(event.Data), chunked.MaxEventBytes)
+			}
+			got = append(got, event)
+		}
+		for chunk := input; len(chunk) > 0; {
+			n := min(size, len(chunk))
+			chunked.Feed(chunk[:n], emit)
+			if chunked.MaxEventBytes > 0 && chunked.Buffered() > chunked.MaxEventBytes {
+				t.Fatalf("Buffered() = %d exceeds limit %d", chunked.Buffered(), chunked.MaxEventBytes)
+			}
+			chunk = chunk[n:]
+		}
+		chunked.Flush(emit)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("chunked events differ from whole input:\n got %q\nwant %q", got, want)
+		}
+		for _, event := range got {
+			if strings.ContainsAny(event.Type, "\r\n") || strings.ContainsAny(event.ID, "\r\n\x00") {
+				t.Fatalf("event fields contain line breaks: %q", event)
+			}
+		}
+
+		reader := NewReader(bytes.NewReader(input))
+		reader.parser.MaxEventBytes = int(maxEventBytes)
+		var read []Event
+		for {
+			event, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			read = append(read, event)
+		}
+		if !reflect.DeepEqual(read, want) {
+			t.Fatalf("Reader events differ from Parser:\n got %q\nwant %q", read, want)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package sse
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	readChunkSize = 4096
+	// maxEmptyReads matches bufio's guard against readers that keep
+	// returning zero bytes without an error.
+	maxEmptyReads = 100
+)
+
+// readBufferPool recycles read buffers. A Reader returns its buffer once the
+// stream ends.
+var readBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, readChunkSize)
+		return &buf
+	},
+}
+
+// Reader iterates over the events of an SSE stream.
+type Reader struct {
+	src    io.Reader
+	parser Parser
+	buf    *[]byte
+	queue  []Event
+	head   int
+	err    error
+}
+
+// NewReader returns a Reader that parses events from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{src: r}
+}
+
+// Next returns the next event. At the end of the stream the pending event is
+// dispatched even without a trailing blank line, then Next returns io.EOF.
+// Read errors are returned once the events parsed before them are drained.
+func (r *Reader) Next() (Event, error) {
+	emptyReads := 0
+	for {
+		if r.head < len(r.queue) {
+			event := r.queue[r.head]
+			r.queue[r.head] = Event{}
+			r.head++
+			if r.head == len(r.queue) {
+				r.queue = r.queue[:0]
+				r.head = 0
+			}
+			return event, nil
+		}
+		if r.err != nil {
+			return Event{}, r.err
+		}
+
+		if r.buf == nil {
+			r.buf = readBufferPool.Get().(*[]byte)
+		}
+		n, err := r.src.Read(*r.buf)
+		if n > 0 {
+			emptyReads = 0
+			r.parser.Feed((*r.buf)[:n], r.push)
+		}
+		switch {
+		case err == io.EOF:
+			r.parser.Flush(r.push)
+			r.err = io.EOF
+		case err != nil:
+			r.err = err
+		case n == 0:
+			emptyReads++
+			if emptyReads >= maxEmptyReads {
+				r.err = io.ErrNoProgress
+			}
+		}
+		if r.err != nil {
+			readBufferPool.Put(r.buf)
+			r.buf = nil
+		}
+	}
+}
+
+func (r *Reader) push(event Event) {
+	r.queue = append(r.queue, event)
+}
@@ -0,0 +1,182 @@
+// Package statebundle exports the admin-managed gateway state kept in storage
+// as a versioned JSON bundle and imports such bundles back, so an environment
+// can be recreated without replaying admin API calls.
+//
+// Forward-compatibility rules for the bundle format:
+//   - SchemaVersion is bumped only when the meaning of an existing field
+//     changes or a field is removed. New sections and new optional fields are
+//     added without a bump.
+//   - Importers reject bundles with a schema version newer than their own and
+//     accept every older one.
+//   - Unknown top-level sections and unknown fields are ignored; unknown
+//     sections are reported as warnings.
+//   - A section that is absent or null is left untouched by an import, even
+//     in replace mode, so a bundle written before a section existed never
+//     wipes it. An empty array is an explicit "no entries".
+package statebundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gomodel/internal/aliases"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/guardrails"
+	"gomodel/internal/modeloverrides"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/workflows"
+)
+
+// SchemaVersion is the bundle format version this gateway writes.
+const SchemaVersion = 1
+
+// Section names, as used for the bundle's JSON keys and in import reports.
+const (
+	SectionGuardrails      = "guardrails"
+	SectionAliases         = "aliases"
+	SectionModelOverrides  = "model_overrides"
+	SectionPromptTemplates = "prompt_templates"
+	SectionWorkflows       = "workflows"
+	SectionAuthKeys        = "auth_keys"
+)
+
+// sections lists the bundle sections in the order imports apply them:
+// workflows reference guardrails by name.
+var sections = []string{
+	SectionGuardrails,
+	SectionAliases,
+	SectionModelOverrides,
+	SectionPromptTemplates,
+	SectionWorkflows,
+	SectionAuthKeys,
+}
+
+var envelopeFields = []string{"schema_version", "exported_at", "gateway_version", "encryption"}
+
+// Bundle is the exported admin-managed state. A nil section was not exported,
+// because its feature is disabled or the bundle predates it.
+type Bundle struct {
+	SchemaVersion  int         `json:"schema_version"`
+	ExportedAt     time.Time   `json:"exported_at"`
+	GatewayVersion string      `json:"gateway_version,omitempty"`
+	Encryption     *Encryption `json:"encryption,omitempty"`
+
+	Guardrails      []guardrails.Definition    `json:"guardrails"`
+	Aliases         []aliases.Alias            `json:"aliases"`
+	ModelOverrides  []modeloverrides.Override  `json:"model_overrides"`
+	PromptTemplates []prompttemplates.Template `json:"prompt_templates"`
+	Workflows       []Workflow                 `json:"workflows"`
+	AuthKeys        []AuthKey                  `json:"auth_keys"`
+}
+
+// Workflow is an active workflow version. Bundles carry its content, not its
+// storage ID, which a restore assigns anew.
+type Workflow struct {
+	Scope        workflows.Scope   `json:"scope"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description,omitempty"`
+	Payload      workflows.Payload `json:"workflow_payload"`
+	WorkflowHash string            `json:"workflow_hash,omitempty"`
+}
+
+// AuthKey is a managed auth key. The token itself is never stored; the
+// bundle carries its SHA-256 hash, in the clear or, when the export was given
+// a passphrase, sealed in EncryptedSecretHash.
+type AuthKey struct {
+	ID                  string        `json:"id"`
+	Name                string        `json:"name"`
+	Description         string        `json:"description,omitempty"`
+	UserPath            string        `json:"user_path,omitempty"`
+	Role                authkeys.Role `json:"role"`
+	DataResidency       string        `json:"data_residency,omitempty"`
+	RedactedValue       string        `json:"redacted_value"`
+	SecretHash          string        `json:"secret_hash,omitempty"`
+	EncryptedSecretHash string        `json:"encrypted_secret_hash,omitempty"`
+	Enabled             bool          `json:"enabled"`
+	ExpiresAt           *time.Time    `json:"expires_at,omitempty"`
+	DeactivatedAt       *time.Time    `json:"deactivated_at,omitempty"`
+	CreatedAt           time.Time     `json:"created_at"`
+	UpdatedAt           time.Time     `json:"updated_at"`
+}
+
+// ValidationError reports a bundle that cannot be imported.
+type ValidationError struct {
+	Message string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+func newValidationError(message string, err error) error {
+	return &ValidationError{Message: message, Err: err}
+}
+
+// IsValidationError reports whether err is a *ValidationError.
+func IsValidationError(err error) bool {
+	_, ok := errors.AsType[*ValidationError](err)
+	return ok
+}
+
+// Decode parses a bundle and checks its schema version. It returns the
+// unknown top-level sections as warnings.
+func Decode(data []byte) (*Bundle, []string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, newValidationError("invalid state bundle: "+err.Error(), err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, nil, newValidationError("invalid state bundle: "+err.Error(), err)
+	}
+	switch {
+	case bundle.SchemaVersion <= 0:
+		return nil, nil, newValidationError("state bundle schema_version is required", nil)
+	case bundle.SchemaVersion > SchemaVersion:
+		return nil, nil, newValidationError(fmt.Sprintf(
+			"state bundle schema_version %d is newer than the supported version %d; import it with a newer gateway",
+			bundle.SchemaVersion, SchemaVersion), nil)
+	}
+
+	var warnings []string
+	for name := range fields {
+		if !slices.Contains(sections, name) && !slices.Contains(envelopeFields, name) {
+			warnings = append(warnings, fmt.Sprintf("unknown section %q was ignored", name))
+		}
+	}
+	slices.Sort(warnings)
+	return &bundle, warnings, nil
+}
+
+// scopeLabel names a workflow scope in import reports.
+func scopeLabel(scope workflows.Scope) string {
+	var parts []string
+	if provider := strings.TrimSpace(scope.Provider); provider != "" {
+		parts = append(parts, "provider:"+provider)
+	}
+	if model := strings.TrimSpace(scope.Model); model != "" {
+		parts = append(parts, "model:"+model)
+	}
+	if userPath := strings.TrimSpace(scope.UserPath); userPath != "" {
+		parts = append(parts, "path:"+userPath)
+	}
+	if len(parts) == 0 {
+		return "global"
+	}
+	return strings.Join(parts, " ")
+}
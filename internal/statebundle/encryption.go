@@ -0,0 +1,122 @@
+package statebundle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	encryptionAlgorithm = "aes-256-gcm"
+	encryptionKDF       = "pbkdf2-sha256"
+	kdfIterations       = 600_000
+	keyBytes            = 32
+	saltBytes           = 16
+
+	// maxKDFIterations caps the count read from a bundle, so a crafted
+	// bundle cannot make an import spend minutes deriving a key.
+	maxKDFIterations = 10 * kdfIterations
+)
+
+// Encryption describes the envelope that seals secrets in a bundle exported
+// with a passphrase. A random data key seals each secret; the data key itself
+// is sealed with a key derived from the passphrase.
+type Encryption struct {
+	Algorithm  string `json:"algorithm"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	WrappedKey string `json:"wrapped_key"`
+}
+
+// newEnvelope creates the envelope for passphrase and returns it with the
+// data key that seals the bundle's secrets.
+func newEnvelope(passphrase string) (*Encryption, []byte, error) {
+	salt := make([]byte, saltBytes)
+	dataKey := make([]byte, keyBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("generate salt: %w", err)
+	}
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("generate data key: %w", err)
+	}
+	kek, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive key: %w", err)
+	}
+	wrapped, err := seal(kek, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Encryption{
+		Algorithm:  encryptionAlgorithm,
+		KDF:        encryptionKDF,
+		Iterations: kdfIterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		WrappedKey: wrapped,
+	}, dataKey, nil
+}
+
+// dataKey unwraps the data key with passphrase.
+func (e *Encryption) dataKey(passphrase string) ([]byte, error) {
+	if e.Algorithm != encryptionAlgorithm || e.KDF != encryptionKDF {
+		return nil, fmt.Errorf("unsupported encryption %s with %s", e.Algorithm, e.KDF)
+	}
+	if e.Iterations < kdfIterations || e.Iterations > maxKDFIterations {
+		return nil, fmt.Errorf("invalid encryption iterations %d", e.Iterations)
+	}
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption salt: %w", err)
+	}
+	kek, err := pbkdf2.Key(sha256.New, passphrase, salt, e.Iterations, keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	dataKey, err := open(kek, e.WrappedKey)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or corrupted bundle")
+	}
+	return dataKey, nil
+}
+
+// seal encrypts plaintext with key and returns base64(nonce || ciphertext).
+func seal(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func open(key []byte, sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("sealed value is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
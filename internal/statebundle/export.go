@@ -0,0 +1,150 @@
+package statebundle
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gomodel/internal/aliases"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/guardrails"
+	"gomodel/internal/modeloverrides"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/version"
+	"gomodel/internal/workflows"
+)
+
+// Catalog is the provider and model inventory imports validate references
+// against.
+type Catalog interface {
+	Supports(model string) bool
+	ProviderNames() []string
+}
+
+// Services are the stores of admin-managed state. Nil services belong to
+// disabled features: their sections are neither exported nor imported.
+type Services struct {
+	Guardrails      *guardrails.Service
+	Aliases         *aliases.Service
+	ModelOverrides  *modeloverrides.Service
+	PromptTemplates *prompttemplates.Service
+	Workflows       *workflows.Service
+	AuthKeys        *authkeys.Service
+	Catalog         Catalog
+}
+
+// Export collects the current state. With a passphrase, auth key hashes are
+// sealed in an envelope that only the same passphrase opens.
+func (s Services) Export(ctx context.Context, passphrase string) (*Bundle, error) {
+	bundle := &Bundle{
+		SchemaVersion:  SchemaVersion,
+		ExportedAt:     time.Now().UTC(),
+		GatewayVersion: version.Version,
+	}
+	if s.Guardrails != nil {
+		bundle.Guardrails = append([]guardrails.Definition{}, s.Guardrails.List()...)
+		slices.SortFunc(bundle.Guardrails, func(a, b guardrails.Definition) int { return cmp.Compare(a.Name, b.Name) })
+	}
+	if s.Aliases != nil {
+		bundle.Aliases = append([]aliases.Alias{}, s.Aliases.List()...)
+		slices.SortFunc(bundle.Aliases, func(a, b aliases.Alias) int { return cmp.Compare(a.Name, b.Name) })
+	}
+	if s.ModelOverrides != nil {
+		bundle.ModelOverrides = append([]modeloverrides.Override{}, s.ModelOverrides.List()...)
+		slices.SortFunc(bundle.ModelOverrides, func(a, b modeloverrides.Override) int { return cmp.Compare(a.Selector, b.Selector) })
+	}
+	if s.PromptTemplates != nil {
+		bundle.PromptTemplates = exportTemplates(s.PromptTemplates)
+	}
+	if s.Workflows != nil {
+		exported, err := exportWorkflows(ctx, s.Workflows)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Workflows = exported
+	}
+	if s.AuthKeys != nil {
+		exported, encryption, err := exportAuthKeys(s.AuthKeys, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		bundle.AuthKeys = exported
+		bundle.Encryption = encryption
+	}
+	return bundle, nil
+}
+
+func exportTemplates(service *prompttemplates.Service) []prompttemplates.Template {
+	result := []prompttemplates.Template{}
+	for _, view := range service.List() {
+		versions, _ := service.Versions(view.Name)
+		result = append(result, versions...)
+	}
+	return result
+}
+
+// exportWorkflows returns the active workflows except the managed default,
+// which every gateway seeds from its own configuration.
+func exportWorkflows(ctx context.Context, service *workflows.Service) ([]Workflow, error) {
+	views, err := service.ListViews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list workflows: %w", err)
+	}
+	result := []Workflow{}
+	for _, view := range views {
+		if view.Managed {
+			continue
+		}
+		result = append(result, Workflow{
+			Scope:        view.Scope,
+			Name:         view.Name,
+			Description:  view.Description,
+			Payload:      view.Payload,
+			WorkflowHash: view.WorkflowHash,
+		})
+	}
+	slices.SortFunc(result, func(a, b Workflow) int { return cmp.Compare(scopeLabel(a.Scope), scopeLabel(b.Scope)) })
+	return result, nil
+}
+
+func exportAuthKeys(service *authkeys.Service, passphrase string) ([]AuthKey, *Encryption, error) {
+	var encryption *Encryption
+	var dataKey []byte
+	if passphrase != "" {
+		var err error
+		if encryption, dataKey, err = newEnvelope(passphrase); err != nil {
+			return nil, nil, fmt.Errorf("encrypt auth keys: %w", err)
+		}
+	}
+
+	result := []AuthKey{}
+	for _, view := range service.ListViews() {
+		key := AuthKey{
+			ID:            view.ID,
+			Name:          view.Name,
+			Description:   view.Description,
+			UserPath:      view.UserPath,
+			Role:          view.Role,
+			DataResidency: view.DataResidency,
+			RedactedValue: view.RedactedValue,
+			SecretHash:    view.SecretHash,
+			Enabled:       view.Enabled,
+			ExpiresAt:     view.ExpiresAt,
+			DeactivatedAt: view.DeactivatedAt,
+			CreatedAt:     view.CreatedAt,
+			UpdatedAt:     view.UpdatedAt,
+		}
+		if dataKey != nil {
+			sealed, err := seal(dataKey, []byte(key.SecretHash))
+			if err != nil {
+				return nil, nil, fmt.Errorf("encrypt auth keys: %w", err)
+			}
+			key.SecretHash, key.EncryptedSecretHash = "", sealed
+		}
+		result = append(result, key)
+	}
+	slices.SortFunc(result, func(a, b AuthKey) int { return cmp.Compare(a.ID, b.ID) })
+	return result, encryption, nil
+}
@@ -0,0 +1,702 @@
+package statebundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gomodel/internal/aliases"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/guardrails"
+	"gomodel/internal/modeloverrides"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/workflows"
+)
+
+// Mode selects how an import treats entities missing from the bundle.
+type Mode string
+
+const (
+	// ModeMerge creates and updates the bundle's entities and keeps the rest.
+	ModeMerge Mode = "merge"
+	// ModeReplace additionally removes the entities of every section in the
+	// bundle that the bundle does not contain. Auth keys are deactivated
+	// rather than removed, and the global workflow is never removed.
+	ModeReplace Mode = "replace"
+)
+
+// ParseMode parses an import mode. An empty value selects ModeMerge.
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ModeMerge, nil
+	case ModeMerge, ModeReplace:
+		return mode, nil
+	default:
+		return "", newValidationError(fmt.Sprintf("invalid import mode %q: must be merge or replace", value), nil)
+	}
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	Mode Mode
+	// DryRun reports the changes without applying them.
+	DryRun bool
+	// Passphrase opens the secrets of a bundle exported with one.
+	Passphrase string
+}
+
+// Report describes the changes an import applied, or would apply in a dry
+// run. Problems are referential integrity violations; an import with
+// problems applies nothing.
+type Report struct {
+	SchemaVersion int              `json:"schema_version"`
+	Mode          Mode             `json:"mode"`
+	DryRun        bool             `json:"dry_run"`
+	Applied       bool             `json:"applied"`
+	Sections      []*SectionReport `json:"sections"`
+	Problems      []string         `json:"problems,omitempty"`
+	Warnings      []string         `json:"warnings,omitempty"`
+}
+
+// SectionReport lists the changed entities of one section by key.
+type SectionReport struct {
+	Section   string   `json:"section"`
+	Created   []string `json:"created,omitempty"`
+	Updated   []string `json:"updated,omitempty"`
+	Deleted   []string `json:"deleted,omitempty"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// plan holds the writes an import performs, in application order.
+type plan struct {
+	report *Report
+
+	guardrailUpserts   []guardrails.Definition
+	aliasUpserts       []aliases.Alias
+	overrideUpserts    []modeloverrides.Override
+	templateReplaces   []prompttemplates.Template
+	templateImports    []prompttemplates.Template
+	workflowCreates    []Workflow
+	workflowDeactivate []string
+	aliasDeletes       []string
+	overrideDeletes    []string
+	templateDeletes    []prompttemplates.Template
+	guardrailDeletes   []string
+	authKeyImports     []authkeys.AuthKey
+	authKeyDeactivate  []string
+}
+
+// Import validates bundle against the current state and, unless it is a dry
+// run or the bundle has problems, applies it. A *ValidationError is returned
+// for bundles that cannot be applied; the report is returned with it.
+func (s Services) Import(ctx context.Context, bundle *Bundle, opts ImportOptions) (*Report, error) {
+	if bundle == nil {
+		return nil, newValidationError("state bundle is required", nil)
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeMerge
+	}
+	p := &plan{report: &Report{
+		SchemaVersion: bundle.SchemaVersion,
+		Mode:          opts.Mode,
+		DryRun:        opts.DryRun,
+		Sections:      []*SectionReport{},
+	}}
+
+	if err := s.planSections(ctx, bundle, opts, p); err != nil {
+		return nil, err
+	}
+	if len(p.report.Problems) > 0 {
+		if opts.DryRun {
+			return p.report, nil
+		}
+		return p.report, newValidationError("state bundle failed validation: "+strings.Join(p.report.Problems, "; "), nil)
+	}
+	if opts.DryRun {
+		return p.report, nil
+	}
+	if err := s.apply(ctx, p); err != nil {
+		return p.report, err
+	}
+	p.report.Applied = true
+	return p.report, nil
+}
+
+func (s Services) planSections(ctx context.Context, bundle *Bundle, opts ImportOptions, p *plan) error {
+	present := map[string]bool{
+		SectionGuardrails:      bundle.Guardrails != nil,
+		SectionAliases:         bundle.Aliases != nil,
+		SectionModelOverrides:  bundle.ModelOverrides != nil,
+		SectionPromptTemplates: bundle.PromptTemplates != nil,
+		SectionWorkflows:       bundle.Workflows != nil,
+		SectionAuthKeys:        bundle.AuthKeys != nil,
+	}
+	enabled := map[string]bool{
+		SectionGuardrails:      s.Guardrails != nil,
+		SectionAliases:         s.Aliases != nil,
+		SectionModelOverrides:  s.ModelOverrides != nil,
+		SectionPromptTemplates: s.PromptTemplates != nil,
+		SectionWorkflows:       s.Workflows != nil,
+		SectionAuthKeys:        s.AuthKeys != nil,
+	}
+	for _, section := range sections {
+		if present[section] && !enabled[section] {
+			p.report.Warnings = append(p.report.Warnings, fmt.Sprintf("section %q was skipped: its feature is disabled", section))
+			present[section] = false
+		}
+	}
+	replace := opts.Mode == ModeReplace
+
+	guardrailNames := map[string]bool{}
+	if s.Guardrails != nil {
+		for _, name := range s.Guardrails.Names() {
+			guardrailNames[name] = true
+		}
+	}
+	if present[SectionGuardrails] {
+		guardrailNames = p.planGuardrails(s.Guardrails, bundle.Guardrails, replace, guardrailNames)
+	}
+	if present[SectionAliases] {
+		p.planAliases(s.Aliases, s.Catalog, bundle.Aliases, replace)
+	}
+	if present[SectionModelOverrides] {
+		p.planModelOverrides(s.ModelOverrides, s.Catalog, bundle.ModelOverrides, replace)
+	}
+	if present[SectionPromptTemplates] {
+		p.planTemplates(s.PromptTemplates, bundle.PromptTemplates, replace)
+	}
+	if s.Workflows != nil {
+		var incoming []Workflow
+		if present[SectionWorkflows] {
+			incoming = bundle.Workflows
+		}
+		if err := p.planWorkflows(ctx, s.Workflows, s.Catalog, incoming, present[SectionWorkflows], replace, guardrailNames); err != nil {
+			return err
+		}
+	}
+	if present[SectionAuthKeys] {
+		p.planAuthKeys(s.AuthKeys, bundle, opts.Passphrase, replace)
+	}
+	return nil
+}
+
+func (p *plan) section(name string) *SectionReport {
+	report := &SectionReport{Section: name}
+	p.report.Sections = append(p.report.Sections, report)
+	return report
+}
+
+func (p *plan) problem(format string, args ...any) {
+	p.report.Problems = append(p.report.Problems, fmt.Sprintf(format, args...))
+}
+
+func (p *plan) warn(format string, args ...any) {
+	p.report.Warnings = append(p.report.Warnings, fmt.Sprintf(format, args...))
+}
+
+// planGuardrails plans the guardrail writes and returns the guardrail names
+// that exist after the import.
+func (p *plan) planGuardrails(service *guardrails.Service, incoming []guardrails.Definition, replace bool, existing map[string]bool) map[string]bool {
+	report := p.section(SectionGuardrails)
+	current := map[string]guardrails.Definition{}
+	for _, definition := range service.List() {
+		current[definition.Name] = definition
+	}
+	result := maps.Clone(existing)
+	if replace {
+		result = map[string]bool{}
+	}
+	seen := map[string]bool{}
+	for _, definition := range incoming {
+		name := strings.TrimSpace(definition.Name)
+		if seen[name] {
+			p.problem("guardrail %q appears more than once", name)
+			continue
+		}
+		seen[name] = true
+		result[name] = true
+		previous, ok := current[name]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, name)
+		case sameGuardrail(previous, definition):
+			report.Unchanged++
+			continue
+		default:
+			report.Updated = append(report.Updated, name)
+		}
+		p.guardrailUpserts = append(p.guardrailUpserts, definition)
+	}
+	if replace {
+		for _, name := range slices.Sorted(maps.Keys(current)) {
+			if !seen[name] {
+				report.Deleted = append(report.Deleted, name)
+				p.guardrailDeletes = append(p.guardrailDeletes, name)
+			}
+		}
+	}
+	return result
+}
+
+func sameGuardrail(a, b guardrails.Definition) bool {
+	return a.Type == strings.TrimSpace(b.Type) &&
+		a.Description == strings.TrimSpace(b.Description) &&
+		a.UserPath == strings.TrimSpace(b.UserPath) &&
+		sameJSON(a.Config, b.Config)
+}
+
+func (p *plan) planAliases(service *aliases.Service, catalog Catalog, incoming []aliases.Alias, replace bool) {
+	report := p.section(SectionAliases)
+	current := map[string]aliases.Alias{}
+	for _, alias := range service.List() {
+		current[alias.Name] = alias
+	}
+	seen := map[string]bool{}
+	for _, alias := range incoming {
+		name := strings.TrimSpace(alias.Name)
+		if seen[name] {
+			p.problem("alias %q appears more than once", name)
+			continue
+		}
+		seen[name] = true
+		target, err := alias.TargetSelector()
+		if err != nil {
+			p.problem("alias %q has an invalid target: %v", name, err)
+			continue
+		}
+		if catalog != nil && !catalog.Supports(target.QualifiedModel()) {
+			p.problem("alias %q targets unknown model %q", name, target.QualifiedModel())
+			continue
+		}
+		previous, ok := current[name]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, name)
+		case previous.TargetModel == strings.TrimSpace(alias.TargetModel) &&
+			previous.TargetProvider == strings.TrimSpace(alias.TargetProvider) &&
+			previous.Description == strings.TrimSpace(alias.Description) &&
+			previous.Enabled == alias.Enabled:
+			report.Unchanged++
+			continue
+		default:
+			report.Updated = append(report.Updated, name)
+		}
+		p.aliasUpserts = append(p.aliasUpserts, alias)
+	}
+	if replace {
+		for _, name := range slices.Sorted(maps.Keys(current)) {
+			if !seen[name] {
+				report.Deleted = append(report.Deleted, name)
+				p.aliasDeletes = append(p.aliasDeletes, name)
+			}
+		}
+	}
+}
+
+func (p *plan) planModelOverrides(service *modeloverrides.Service, catalog Catalog, incoming []modeloverrides.Override, replace bool) {
+	report := p.section(SectionModelOverrides)
+	current := map[string]modeloverrides.Override{}
+	for _, override := range service.List() {
+		current[override.Selector] = override
+	}
+	var providerNames []string
+	if catalog != nil {
+		providerNames = catalog.ProviderNames()
+	}
+	seen := map[string]bool{}
+	for _, override := range incoming {
+		selector := strings.TrimSpace(override.Selector)
+		if seen[selector] {
+			p.problem("model override %q appears more than once", selector)
+			continue
+		}
+		seen[selector] = true
+		if provider := strings.TrimSpace(override.ProviderName); catalog != nil && provider != "" && !slices.Contains(providerNames, provider) {
+			p.problem("model override %q references unknown provider %q", selector, provider)
+			continue
+		}
+		previous, ok := current[selector]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, selector)
+		case slices.Equal(sortedStrings(previous.UserPaths), sortedStrings(override.UserPaths)):
+			report.Unchanged++
+			continue
+		default:
+			report.Updated = append(report.Updated, selector)
+		}
+		p.overrideUpserts = append(p.overrideUpserts, override)
+	}
+	if replace {
+		for _, selector := range slices.Sorted(maps.Keys(current)) {
+			if !seen[selector] {
+				report.Deleted = append(report.Deleted, selector)
+				p.overrideDeletes = append(p.overrideDeletes, selector)
+			}
+		}
+	}
+}
+
+func templateKey(tpl prompttemplates.Template) string {
+	return fmt.Sprintf("%s@%d", strings.TrimSpace(tpl.Name), tpl.Version)
+}
+
+func (p *plan) planTemplates(service *prompttemplates.Service, incoming []prompttemplates.Template, replace bool) {
+	report := p.section(SectionPromptTemplates)
+	current := map[string]prompttemplates.Template{}
+	for _, view := range service.List() {
+		versions, _ := service.Versions(view.Name)
+		for _, tpl := range versions {
+			current[templateKey(tpl)] = tpl
+		}
+	}
+	seen := map[string]bool{}
+	for _, tpl := range incoming {
+		key := templateKey(tpl)
+		if seen[key] {
+			p.problem("prompt template %q appears more than once", key)
+			continue
+		}
+		seen[key] = true
+		previous, ok := current[key]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, key)
+			p.templateImports = append(p.templateImports, tpl)
+		case sameTemplate(previous, tpl):
+			report.Unchanged++
+		default:
+			// Versions are immutable; a changed version is stored anew.
+			report.Updated = append(report.Updated, key)
+			p.templateReplaces = append(p.templateReplaces, tpl)
+		}
+	}
+	if replace {
+		for _, key := range slices.Sorted(maps.Keys(current)) {
+			if !seen[key] {
+				report.Deleted = append(report.Deleted, key)
+				p.templateDeletes = append(p.templateDeletes, current[key])
+			}
+		}
+	}
+}
+
+func sameTemplate(a, b prompttemplates.Template) bool {
+	type content struct {
+		Description string
+		Messages    []prompttemplates.Message
+		Variables   []prompttemplates.Variable
+	}
+	left, _ := json.Marshal(content{a.Description, a.Messages, a.Variables})
+	right, _ := json.Marshal(content{strings.TrimSpace(b.Description), b.Messages, b.Variables})
+	return bytes.Equal(left, right)
+}
+
+// planWorkflows plans the workflow writes and checks that every workflow
+// active after the import references existing guardrails. It runs even when
+// the bundle has no workflows section, since a guardrail import may remove
+// guardrails active workflows use.
+func (p *plan) planWorkflows(ctx context.Context, service *workflows.Service, catalog Catalog, incoming []Workflow, present, replace bool, guardrailNames map[string]bool) error {
+	views, err := service.ListViews(ctx)
+	if err != nil {
+		return fmt.Errorf("list workflows: %w", err)
+	}
+	current := map[workflows.Scope]workflows.View{}
+	for _, view := range views {
+		current[trimScope(view.Scope)] = view
+	}
+
+	var providerNames []string
+	if catalog != nil {
+		providerNames = catalog.ProviderNames()
+	}
+	checkRefs := func(label string, payload workflows.Payload) {
+		for _, step := range payload.Guardrails {
+			if ref := strings.TrimSpace(step.Ref); !guardrailNames[ref] {
+				p.problem("workflow %s references unknown guardrail %q", label, ref)
+			}
+		}
+	}
+
+	seen := map[workflows.Scope]bool{}
+	if present {
+		report := p.section(SectionWorkflows)
+		for _, workflow := range incoming {
+			scope := trimScope(workflow.Scope)
+			label := scopeLabel(scope)
+			if seen[scope] {
+				p.problem("workflow %s appears more than once", label)
+				continue
+			}
+			seen[scope] = true
+			if catalog != nil && scope.Provider != "" && !slices.Contains(providerNames, scope.Provider) {
+				p.problem("workflow %s references unknown provider %q", label, scope.Provider)
+			}
+			checkRefs(label, workflow.Payload)
+			previous, ok := current[scope]
+			switch {
+			case !ok:
+				report.Created = append(report.Created, label)
+			case !previous.Managed && sameWorkflow(previous, workflow):
+				report.Unchanged++
+				continue
+			default:
+				report.Updated = append(report.Updated, label)
+			}
+			p.workflowCreates = append(p.workflowCreates, workflow)
+		}
+		if replace {
+			for _, view := range views {
+				scope := trimScope(view.Scope)
+				if seen[scope] || view.Managed {
+					continue
+				}
+				if scope == (workflows.Scope{}) {
+					p.warn("the global workflow is missing from the bundle and was kept")
+					continue
+				}
+				report.Deleted = append(report.Deleted, scopeLabel(scope))
+				p.workflowDeactivate = append(p.workflowDeactivate, view.ID)
+			}
+		}
+	}
+
+	// Workflows the import keeps must not lose their guardrails.
+	for _, view := range views {
+		scope := trimScope(view.Scope)
+		if seen[scope] || (present && replace && !view.Managed && scope != (workflows.Scope{})) {
+			continue
+		}
+		checkRefs(scopeLabel(scope), view.Payload)
+	}
+	return nil
+}
+
+func trimScope(scope workflows.Scope) workflows.Scope {
+	return workflows.Scope{
+		Provider: strings.TrimSpace(scope.Provider),
+		Model:    strings.TrimSpace(scope.Model),
+		UserPath: strings.TrimSpace(scope.UserPath),
+	}
+}
+
+func sameWorkflow(view workflows.View, workflow Workflow) bool {
+	left, _ := json.Marshal(view.Payload)
+	right, _ := json.Marshal(workflow.Payload)
+	return view.Name == strings.TrimSpace(workflow.Name) &&
+		view.Description == strings.TrimSpace(workflow.Description) &&
+		sameJSON(left, right)
+}
+
+func (p *plan) planAuthKeys(service *authkeys.Service, bundle *Bundle, passphrase string, replace bool) {
+	report := p.section(SectionAuthKeys)
+	var dataKey []byte
+	if bundle.Encryption != nil {
+		if passphrase == "" {
+			p.problem("the bundle's auth keys are encrypted; a passphrase is required")
+			return
+		}
+		var err error
+		if dataKey, err = bundle.Encryption.dataKey(passphrase); err != nil {
+			p.problem("cannot decrypt auth keys: %v", err)
+			return
+		}
+	}
+
+	current := map[string]authkeys.AuthKey{}
+	for _, view := range service.ListViews() {
+		current[view.ID] = view.AuthKey
+	}
+	seen := map[string]bool{}
+	for _, entry := range bundle.AuthKeys {
+		id := strings.TrimSpace(entry.ID)
+		if seen[id] {
+			p.problem("auth key %q appears more than once", id)
+			continue
+		}
+		seen[id] = true
+		key, err := entry.authKey(dataKey)
+		if err != nil {
+			p.problem("auth key %q: %v", id, err)
+			continue
+		}
+		previous, ok := current[id]
+		switch {
+		case !ok:
+			report.Created = append(report.Created, id)
+			p.authKeyImports = append(p.authKeyImports, key)
+		case !sameAuthKey(previous, key):
+			p.warn("auth key %q differs from the bundle and was left unchanged: auth keys are immutable", id)
+			report.Unchanged++
+		case keyLive(previous) && !keyLive(key):
+			report.Updated = append(report.Updated, id)
+			p.authKeyDeactivate = append(p.authKeyDeactivate, id)
+		default:
+			report.Unchanged++
+		}
+	}
+	if replace {
+		for _, id := range slices.Sorted(maps.Keys(current)) {
+			if !seen[id] && keyLive(current[id]) {
+				report.Deleted = append(report.Deleted, id)
+				p.authKeyDeactivate = append(p.authKeyDeactivate, id)
+			}
+		}
+	}
+}
+
+// authKey returns the stored form of a bundle entry, opening its sealed
+// secret hash with dataKey.
+func (k AuthKey) authKey(dataKey []byte) (authkeys.AuthKey, error) {
+	secretHash := k.SecretHash
+	switch {
+	case k.EncryptedSecretHash != "" && dataKey == nil:
+		return authkeys.AuthKey{}, fmt.Errorf("encrypted secret hash without bundle encryption")
+	case k.EncryptedSecretHash != "":
+		opened, err := open(dataKey, k.EncryptedSecretHash)
+		if err != nil {
+			return authkeys.AuthKey{}, fmt.Errorf("cannot decrypt secret hash")
+		}
+		secretHash = string(opened)
+	case secretHash == "":
+		return authkeys.AuthKey{}, fmt.Errorf("secret_hash is required")
+	}
+	return authkeys.AuthKey{
+		ID:            strings.TrimSpace(k.ID),
+		Name:          k.Name,
+		Description:   k.Description,
+		UserPath:      k.UserPath,
+		Role:          k.Role,
+		DataResidency: k.DataResidency,
+		RedactedValue: k.RedactedValue,
+		SecretHash:    secretHash,
+		Enabled:       k.Enabled,
+		ExpiresAt:     k.ExpiresAt,
+		DeactivatedAt: k.DeactivatedAt,
+		CreatedAt:     k.CreatedAt,
+		UpdatedAt:     k.UpdatedAt,
+	}, nil
+}
+
+// sameAuthKey compares the immutable identity of two keys.
+func sameAuthKey(a, b authkeys.AuthKey) bool {
+	return a.SecretHash == strings.ToLower(strings.TrimSpace(b.SecretHash)) &&
+		a.Name == strings.TrimSpace(b.Name) &&
+		a.UserPath == strings.TrimSpace(b.UserPath) &&
+		a.Role == b.Role
+}
+
+func keyLive(key authkeys.AuthKey) bool {
+	return key.Enabled && key.DeactivatedAt == nil
+}
+
+func (s Services) apply(ctx context.Context, p *plan) error {
+	if len(p.guardrailUpserts) > 0 {
+		if err := s.Guardrails.UpsertDefinitions(ctx, p.guardrailUpserts); err != nil {
+			return applyError(SectionGuardrails, "", err)
+		}
+	}
+	for _, alias := range p.aliasUpserts {
+		if err := s.Aliases.Upsert(ctx, alias); err != nil {
+			return applyError(SectionAliases, alias.Name, err)
+		}
+	}
+	for _, override := range p.overrideUpserts {
+		if err := s.ModelOverrides.Upsert(ctx, override); err != nil {
+			return applyError(SectionModelOverrides, override.Selector, err)
+		}
+	}
+	for _, tpl := range p.templateReplaces {
+		if err := s.PromptTemplates.Delete(ctx, tpl.Name, tpl.Version); err != nil {
+			return applyError(SectionPromptTemplates, templateKey(tpl), err)
+		}
+	}
+	for _, tpl := range slices.Concat(p.templateReplaces, p.templateImports) {
+		if err := s.PromptTemplates.Import(ctx, tpl); err != nil {
+			return applyError(SectionPromptTemplates, templateKey(tpl), err)
+		}
+	}
+	for _, workflow := range p.workflowCreates {
+		if _, err := s.Workflows.Create(ctx, workflows.CreateInput{
+			Scope:       workflow.Scope,
+			Activate:    true,
+			Name:        workflow.Name,
+			Description: workflow.Description,
+			Payload:     workflow.Payload,
+		}); err != nil {
+			return applyError(SectionWorkflows, scopeLabel(workflow.Scope), err)
+		}
+	}
+	for _, id := range p.workflowDeactivate {
+		if err := s.Workflows.Deactivate(ctx, id); err != nil {
+			return applyError(SectionWorkflows, id, err)
+		}
+	}
+	for _, name := range p.aliasDeletes {
+		if err := s.Aliases.Delete(ctx, name); err != nil {
+			return applyError(SectionAliases, name, err)
+		}
+	}
+	for _, selector := range p.overrideDeletes {
+		if err := s.ModelOverrides.Delete(ctx, selector); err != nil {
+			return applyError(SectionModelOverrides, selector, err)
+		}
+	}
+	for _, tpl := range p.templateDeletes {
+		if err := s.PromptTemplates.Delete(ctx, tpl.Name, tpl.Version); err != nil {
+			return applyError(SectionPromptTemplates, templateKey(tpl), err)
+		}
+	}
+	for _, name := range p.guardrailDeletes {
+		if err := s.Guardrails.Delete(ctx, name); err != nil {
+			return applyError(SectionGuardrails, name, err)
+		}
+	}
+	for _, key := range p.authKeyImports {
+		if err := s.AuthKeys.Import(ctx, key); err != nil {
+			return applyError(SectionAuthKeys, key.ID, err)
+		}
+	}
+	for _, id := range p.authKeyDeactivate {
+		if err := s.AuthKeys.Deactivate(ctx, id); err != nil {
+			return applyError(SectionAuthKeys, id, err)
+		}
+	}
+	return nil
+}
+
+// applyError reports a failed write. Entities rejected by their service's
+// validation surface as a *ValidationError; earlier writes stay applied.
+func applyError(section, key string, err error) error {
+	target := section
+	if key != "" {
+		target = fmt.Sprintf("%s %q", section, key)
+	}
+	if guardrails.IsValidationError(err) || aliases.IsValidationError(err) || modeloverrides.IsValidationError(err) ||
+		prompttemplates.IsValidationError(err) || workflows.IsValidationError(err) || authkeys.IsValidationError(err) {
+		return newValidationError(fmt.Sprintf("import %s: %v", target, err), err)
+	}
+	return fmt.Errorf("import %s: %w", target, err)
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	var left, right bytes.Buffer
+	if json.Compact(&left, a) != nil || json.Compact(&right, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(left.Bytes(), right.Bytes())
+}
+
+func sortedStrings(values []string) []string {
+	result := slices.Clone(values)
+	for i := range result {
+		result[i] = strings.TrimSpace(result[i])
+	}
+	slices.Sort(result)
+	return result
+}
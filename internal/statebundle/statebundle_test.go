@@ -0,0 +1,388 @@
+package statebundle
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"gomodel/internal/aliases"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/core"
+	"gomodel/internal/guardrails"
+	"gomodel/internal/modeloverrides"
+	"gomodel/internal/prompttemplates"
+	"gomodel/internal/workflows"
+)
+
+type testCatalog struct{}
+
+func (testCatalog) Supports(model string) bool {
+	return model == "gpt-4o" || model == "openai/gpt-4o"
+}
+
+func (c testCatalog) GetProviderType(model string) string {
+	if c.Supports(model) {
+		return "openai"
+	}
+	return ""
+}
+
+func (c testCatalog) LookupModel(model string) (*core.Model, bool) {
+	if !c.Supports(model) {
+		return nil, false
+	}
+	return &core.Model{ID: "gpt-4o"}, true
+}
+
+func (testCatalog) ProviderNames() []string { return []string{"openai"} }
+
+// newTestServices opens a fresh environment backed by an in-memory database.
+func newTestServices(t *testing.T) Services {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	guardrailStore, err := guardrails.NewSQLiteStore(ctx, db)
+	if err != nil {
+		t.Fatalf("guardrails.NewSQLiteStore() error = %v", err)
+	}
+	guardrailService, err := guardrails.NewService(guardrailStore)
+	if err != nil {
+		t.Fatalf("guardrails.NewService() error = %v", err)
+	}
+	aliasStore, err := aliases.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("aliases.NewSQLiteStore() error = %v", err)
+	}
+	aliasService, err := aliases.NewService(aliasStore, testCatalog{})
+	if err != nil {
+		t.Fatalf("aliases.NewService() error = %v", err)
+	}
+	overrideStore, err := modeloverrides.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("modeloverrides.NewSQLiteStore() error = %v", err)
+	}
+	overrideService, err := modeloverrides.NewService(overrideStore, testCatalog{}, true)
+	if err != nil {
+		t.Fatalf("modeloverrides.NewService() error = %v", err)
+	}
+	templateStore, err := prompttemplates.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("prompttemplates.NewSQLiteStore() error = %v", err)
+	}
+	templateService, err := prompttemplates.NewService(templateStore)
+	if err != nil {
+		t.Fatalf("prompttemplates.NewService() error = %v", err)
+	}
+	workflowStore, err := workflows.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("workflows.NewSQLiteStore() error = %v", err)
+	}
+	workflowService, err := workflows.NewService(workflowStore, workflows.NewCompiler(guardrailService))
+	if err != nil {
+		t.Fatalf("workflows.NewService() error = %v", err)
+	}
+	if err := workflowService.EnsureDefaultGlobal(ctx, workflows.CreateInput{
+		Name:    "default",
+		Payload: workflows.Payload{SchemaVersion: 1, Features: workflows.FeatureFlags{Audit: true, Usage: true}},
+	}); err != nil {
+		t.Fatalf("EnsureDefaultGlobal() error = %v", err)
+	}
+	authKeyStore, err := authkeys.NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("authkeys.NewSQLiteStore() error = %v", err)
+	}
+	authKeyService, err := authkeys.NewService(authKeyStore)
+	if err != nil {
+		t.Fatalf("authkeys.NewService() error = %v", err)
+	}
+
+	return Services{
+		Guardrails:      guardrailService,
+		Aliases:         aliasService,
+		ModelOverrides:  overrideService,
+		PromptTemplates: templateService,
+		Workflows:       workflowService,
+		AuthKeys:        authKeyService,
+		Catalog:         testCatalog{},
+	}
+}
+
+// seed fills services with one entity of every section and returns the
+// token of the issued auth key.
+func seed(t *testing.T, services Services) string {
+	t.Helper()
+	ctx := context.Background()
+	if err := services.Guardrails.Upsert(ctx, guardrails.Definition{
+		Name:   "safety",
+		Type:   "system_prompt",
+		Config: json.RawMessage(`{"mode":"inject","content":"be safe"}`),
+	}); err != nil {
+		t.Fatalf("guardrails.Upsert() error = %v", err)
+	}
+	if err := services.Aliases.Upsert(ctx, aliases.Alias{Name: "smart", TargetModel: "gpt-4o", Enabled: true}); err != nil {
+		t.Fatalf("aliases.Upsert() error = %v", err)
+	}
+	if err := services.ModelOverrides.Upsert(ctx, modeloverrides.Override{Selector: "openai/gpt-4o", UserPaths: []string{"/team"}}); err != nil {
+		t.Fatalf("modeloverrides.Upsert() error = %v", err)
+	}
+	for _, content := range []string{"Hello {{.name}}", "Hi {{.name}}"} {
+		if _, err := services.PromptTemplates.Create(ctx, prompttemplates.Template{
+			Name:      "greeting",
+			Messages:  []prompttemplates.Message{{Role: "system", Content: content}},
+			Variables: []prompttemplates.Variable{{Name: "name", Required: true}},
+		}); err != nil {
+			t.Fatalf("prompttemplates.Create() error = %v", err)
+		}
+	}
+	if _, err := services.Workflows.Create(ctx, workflows.CreateInput{
+		Scope:    workflows.Scope{Provider: "openai"},
+		Activate: true,
+		Name:     "openai",
+		Payload: workflows.Payload{
+			SchemaVersion: 1,
+			Features:      workflows.FeatureFlags{Audit: true, Usage: true, Guardrails: true},
+			Guardrails:    []workflows.GuardrailStep{{Ref: "safety", Step: 10}},
+		},
+	}); err != nil {
+		t.Fatalf("workflows.Create() error = %v", err)
+	}
+	issued, err := services.AuthKeys.Create(ctx, authkeys.CreateInput{Name: "ci", UserPath: "/team"})
+	if err != nil {
+		t.Fatalf("authkeys.Create() error = %v", err)
+	}
+	return issued.Value
+}
+
+// comparableBundle returns the bundle as JSON with the timestamps that restores
+// assign anew removed.
+func comparableBundle(t *testing.T, bundle *Bundle) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	delete(decoded, "exported_at")
+	delete(decoded, "encryption")
+	for _, section := range []string{SectionGuardrails, SectionAliases, SectionModelOverrides} {
+		entries, _ := decoded[section].([]any)
+		for _, entry := range entries {
+			delete(entry.(map[string]any), "created_at")
+			delete(entry.(map[string]any), "updated_at")
+		}
+	}
+	return decoded
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newTestServices(t)
+	token := seed(t, source)
+
+	first, err := source.Export(ctx, "")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	decoded, warnings, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("Decode() warnings = %v, want none", warnings)
+	}
+
+	target := newTestServices(t)
+	report, err := target.Import(ctx, decoded, ImportOptions{Mode: ModeReplace})
+	if err != nil {
+		t.Fatalf("Import() error = %v, report = %+v", err, report)
+	}
+	if !report.Applied {
+		t.Fatalf("report.Applied = false, want true")
+	}
+
+	second, err := target.Export(ctx, "")
+	if err != nil {
+		t.Fatalf("Export() after import error = %v", err)
+	}
+	if got, want := comparableBundle(t, second), comparableBundle(t, first); !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Fatalf("round trip mismatch\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+	}
+
+	if _, err := target.AuthKeys.Authenticate(ctx, token); err != nil {
+		t.Fatalf("Authenticate() with the original token error = %v", err)
+	}
+
+	again, err := target.Import(ctx, decoded, ImportOptions{Mode: ModeReplace, DryRun: true})
+	if err != nil {
+		t.Fatalf("second Import() error = %v", err)
+	}
+	for _, section := range again.Sections {
+		if len(section.Created)+len(section.Updated)+len(section.Deleted) > 0 {
+			t.Fatalf("second import of %s reports changes: %+v", section.Section, section)
+		}
+	}
+}
+
+func TestExportWithPassphraseSealsSecretHashes(t *testing.T) {
+	ctx := context.Background()
+	source := newTestServices(t)
+	token := seed(t, source)
+
+	bundle, err := source.Export(ctx, "correct horse")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if bundle.Encryption == nil {
+		t.Fatal("bundle.Encryption = nil, want envelope")
+	}
+	for _, key := range bundle.AuthKeys {
+		if key.SecretHash != "" || key.EncryptedSecretHash == "" {
+			t.Fatalf("auth key %q exported in the clear", key.ID)
+		}
+	}
+
+	target := newTestServices(t)
+	report, err := target.Import(ctx, bundle, ImportOptions{DryRun: true, Passphrase: "wrong"})
+	if err != nil {
+		t.Fatalf("dry run Import() error = %v", err)
+	}
+	if len(report.Problems) == 0 {
+		t.Fatal("dry run with a wrong passphrase reported no problems")
+	}
+
+	if _, err := target.Import(ctx, bundle, ImportOptions{Passphrase: "correct horse"}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if _, err := target.AuthKeys.Authenticate(ctx, token); err != nil {
+		t.Fatalf("Authenticate() with the original token error = %v", err)
+	}
+}
+
+func TestImportReportsReferentialProblems(t *testing.T) {
+	ctx := context.Background()
+	services := newTestServices(t)
+	bundle := &Bundle{
+		SchemaVersion: SchemaVersion,
+		Aliases:       []aliases.Alias{{Name: "smart", TargetModel: "missing-model", Enabled: true}},
+		Workflows: []Workflow{{
+			Scope: workflows.Scope{Provider: "openai"},
+			Name:  "openai",
+			Payload: workflows.Payload{
+				SchemaVersion: 1,
+				Features:      workflows.FeatureFlags{Guardrails: true},
+				Guardrails:    []workflows.GuardrailStep{{Ref: "missing", Step: 10}},
+			},
+		}},
+	}
+
+	report, err := services.Import(ctx, bundle, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run Import() error = %v", err)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("report.Problems = %v, want 2", report.Problems)
+	}
+
+	report, err = services.Import(ctx, bundle, ImportOptions{})
+	if !IsValidationError(err) {
+		t.Fatalf("Import() error = %v, want validation error", err)
+	}
+	if report == nil || report.Applied {
+		t.Fatalf("report = %+v, want unapplied report", report)
+	}
+	if len(services.Aliases.List()) != 0 {
+		t.Fatal("an import with problems created aliases")
+	}
+}
+
+func TestImportReplaceRemovesMissingEntities(t *testing.T) {
+	ctx := context.Background()
+	services := newTestServices(t)
+	seed(t, services)
+
+	bundle := &Bundle{
+		SchemaVersion: SchemaVersion,
+		Aliases:       []aliases.Alias{},
+		Workflows:     []Workflow{},
+	}
+	report, err := services.Import(ctx, bundle, ImportOptions{Mode: ModeMerge})
+	if err != nil {
+		t.Fatalf("merge Import() error = %v", err)
+	}
+	if len(services.Aliases.List()) != 1 {
+		t.Fatalf("merge import removed aliases: report = %+v", report)
+	}
+
+	if _, err := services.Import(ctx, bundle, ImportOptions{Mode: ModeReplace}); err != nil {
+		t.Fatalf("replace Import() error = %v", err)
+	}
+	if len(services.Aliases.List()) != 0 {
+		t.Fatal("replace import kept aliases missing from the bundle")
+	}
+	if len(services.ModelOverrides.List()) != 1 {
+		t.Fatal("replace import touched the model overrides section absent from the bundle")
+	}
+	views, err := services.Workflows.ListViews(ctx)
+	if err != nil {
+		t.Fatalf("ListViews() error = %v", err)
+	}
+	if len(views) != 1 || views[0].Scope != (workflows.Scope{}) {
+		t.Fatalf("workflows after replace = %+v, want the global workflow only", views)
+	}
+}
+
+func TestDecodeChecksSchemaVersion(t *testing.T) {
+	if _, _, err := Decode([]byte(`{"schema_version": 2}`)); !IsValidationError(err) || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("Decode() newer version error = %v", err)
+	}
+	if _, _, err := Decode([]byte(`{"aliases": []}`)); !IsValidationError(err) {
+		t.Fatalf("Decode() without version error = %v", err)
+	}
+	bundle, warnings, err := Decode([]byte(`{"schema_version": 1, "budgets": [], "aliases": []}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "budgets") {
+		t.Fatalf("Decode() warnings = %v, want the unknown section", warnings)
+	}
+	if bundle.Aliases == nil || bundle.Guardrails != nil {
+		t.Fatalf("Decode() sections: aliases = %v, guardrails = %v", bundle.Aliases, bundle.Guardrails)
+	}
+}
+
+func TestEncryptionRejectsIterationsOutOfRange(t *testing.T) {
+	envelope, _, err := newEnvelope("correct horse")
+	if err != nil {
+		t.Fatalf("newEnvelope() error = %v", err)
+	}
+	for _, iterations := range []int{0, 1, kdfIterations - 1, maxKDFIterations + 1, 1 << 40} {
+		tampered := *envelope
+		tampered.Iterations = iterations
+		if _, err := tampered.dataKey("correct horse"); err == nil {
+			t.Errorf("dataKey() with %d iterations error = nil, want error", iterations)
+		}
+	}
+	if _, err := envelope.dataKey("correct horse"); err != nil {
+		t.Fatalf("dataKey() error = %v", err)
+	}
+}
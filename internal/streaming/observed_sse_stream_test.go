@@ -3,10 +3,136 @@ package streaming
 import (
 	"bytes"
 	"io"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
+
+	"gomodel/internal/sse"
 )
 
+// toolCallStreamFixture is a recorded OpenAI chat stream with two parallel tool
+// calls whose argument fragments split JSON strings, escapes and multi-byte
+// runes at awkward offsets.
+const toolCallStreamFixture = `data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":null,"tool_calls":[{"index":0,"id":"call_weather","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Krak"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_time","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"ów \\\"old town\\\"\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"Europe/Warsaw\"}"}}]},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: {"id":"chatcmpl-tc","object":"chat.completion.chunk","created":1735689600,"model":"gpt-4o-mini","choices":[],"usage":{"prompt_tokens":42,"completion_tokens":17,"total_tokens":59}}
+
+data: [DONE]
+
+`
+
+// randomChunkReader returns the underlying bytes in reads of random sizes
+// between 1 and maxChunk.
+type randomChunkReader struct {
+	data     []byte
+	rng      *rand.Rand
+	maxChunk int
+}
+
+func (r *randomChunkReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	size := min(1+r.rng.Intn(r.maxChunk), len(p), len(r.data))
+	n := copy(p, r.data[:size])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+type recordingObserver struct {
+	payloads []map[string]any
+	closed   bool
+}
+
+func (o *recordingObserver) OnJSONEvent(payload map[string]any) {
+	o.payloads = append(o.payloads, payload)
+}
+
+func (o *recordingObserver) OnStreamClose() {
+	o.closed = true
+}
+
+func FuzzObservedSSEStream_ToolCallStreamReadBoundaries(f *testing.F) {
+	for _, seed := range []int64{1, 2, 3, 42, 1337} {
+		for _, maxChunk := range []uint8{1, 2, 3, 7, 64} {
+			f.Add(seed, maxChunk, false, false)
+			f.Add(seed, maxChunk, true, false)
+			f.Add(seed, maxChunk, false, true)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64, maxChunk uint8, crlf, compactData bool) {
+		if maxChunk == 0 {
+			maxChunk = 1
+		}
+		fixture := toolCallStreamFixture
+		if crlf {
+			fixture = strings.ReplaceAll(fixture, "\n", "\r\n")
+		}
+		if compactData {
+			fixture = strings.ReplaceAll(fixture, "data: ", "data:")
+		}
+
+		want := &recordingObserver{}
+		wholeStream := NewObservedSSEStream(io.NopCloser(strings.NewReader(fixture)), want)
+		if _, err := io.ReadAll(wholeStream); err != nil {
+			t.Fatalf("ReadAll(whole) error: %v", err)
+		}
+		_ = wholeStream.Close()
+		if len(want.payloads) != 7 {
+			t.Fatalf("whole-stream payload count = %d, want 7", len(want.payloads))
+		}
+
+		got := &recordingObserver{}
+		reader := &randomChunkReader{data: []byte(fixture), rng: rand.New(rand.NewSource(seed)), maxChunk: int(maxChunk)}
+		stream := NewObservedSSEStream(io.NopCloser(reader), got)
+
+		var out bytes.Buffer
+		buf := make([]byte, 1+int(maxChunk)*2)
+		for {
+			n, err := stream.Read(buf)
+			out.Write(buf[:n])
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Read error: %v", err)
+			}
+		}
+		if err := stream.Close(); err != nil {
+			t.Fatalf("Close error: %v", err)
+		}
+
+		if out.String() != fixture {
+			t.Fatalf("passthrough bytes differ from upstream")
+		}
+		if !got.closed {
+			t.Fatal("observer was not closed")
+		}
+		if !reflect.DeepEqual(got.payloads, want.payloads) {
+			t.Fatalf("observed payloads differ across read boundaries:\n got %v\nwant %v", got.payloads, want.payloads)
+		}
+	})
+}
+
+func newTestObservedSSEStream(observers ...Observer) *ObservedSSEStream {
+	return &ObservedSSEStream{
+		observers: observers,
+		parser:    sse.Parser{MaxEventBytes: maxPendingEventBytes},
+	}
+}
+
 type trackingObserver struct {
 	eventCount  int
 	lastID      string
@@ -123,9 +249,7 @@ func TestObservedSSEStream_ReassemblesMultilineDataEvent(t *testing.T) {
 
 func TestObservedSSEStream_DetectsBoundarySplitAcrossReads(t *testing.T) {
 	observer := &trackingObserver{}
-	s := &ObservedSSEStream{
-		observers: []Observer{observer},
-	}
+	s := newTestObservedSSEStream(observer)
 
 	s.processChunk([]byte("data:{\"id\":\"chatcmpl-1\"}\r\n\r"))
 	s.processChunk([]byte("\ndata:{\"id\":\"chatcmpl-2\"}\r\n\r\n"))
@@ -136,36 +260,33 @@ func TestObservedSSEStream_DetectsBoundarySplitAcrossReads(t *testing.T) {
 	if observer.lastID != "chatcmpl-2" {
 		t.Fatalf("lastID = %q, want chatcmpl-2", observer.lastID)
 	}
-	if len(s.pending) != 0 {
-		t.Fatalf("pending length = %d, want 0", len(s.pending))
+	if got := s.parser.Buffered(); got != 0 {
+		t.Fatalf("buffered length = %d, want 0", got)
 	}
 }
 
 func TestObservedSSEStream_DiscardsOversizedPendingDataWithoutTailCapping(t *testing.T) {
-	s := &ObservedSSEStream{
-		pending: bytes.Repeat([]byte("a"), maxPendingEventBytes),
-	}
+	s := newTestObservedSSEStream()
+	s.processChunk(bytes.Repeat([]byte("a"), maxPendingEventBytes))
 	data := bytes.Repeat([]byte("b"), maxPendingEventBytes+1024)
 
 	s.processChunk(data)
 
-	if got := len(s.pending); got != 0 {
-		t.Fatalf("pending length = %d, want 0", got)
+	if got := s.parser.Buffered(); got != 0 {
+		t.Fatalf("buffered length = %d, want 0", got)
 	}
-	if !s.discarding {
+	if !s.parser.Discarding() {
 		t.Fatal("discarding = false, want true")
 	}
 }
 
 func TestObservedSSEStream_DropsOversizedBufferedEventAndResumesWithinSameChunk(t *testing.T) {
 	observer := &trackingObserver{}
-	s := &ObservedSSEStream{
-		observers: []Observer{observer},
-		pending: append(
-			[]byte("data: {\"id\":\"too-big\",\"payload\":\""),
-			bytes.Repeat([]byte("a"), maxPendingEventBytes/2)...,
-		),
-	}
+	s := newTestObservedSSEStream(observer)
+	s.processChunk(append(
+		[]byte("data: {\"id\":\"too-big\",\"payload\":\""),
+		bytes.Repeat([]byte("a"), maxPendingEventBytes/2)...,
+	))
 	data := append(
 		append(
 			append(
@@ -189,9 +310,7 @@ func TestObservedSSEStream_DropsOversizedBufferedEventAndResumesWithinSameChunk(
 
 func TestObservedSSEStream_ResumesAfterDiscardWhenBoundarySplitsAcrossReads(t *testing.T) {
 	observer := &trackingObserver{}
-	s := &ObservedSSEStream{
-		observers: []Observer{observer},
-	}
+	s := newTestObservedSSEStream(observer)
 
 	oversized := append(
 		append(
@@ -210,20 +329,18 @@ func TestObservedSSEStream_ResumesAfterDiscardWhenBoundarySplitsAcrossReads(t *t
 	if observer.lastID != "fresh" {
 		t.Fatalf("lastID = %q, want fresh", observer.lastID)
 	}
-	if s.discarding {
+	if s.parser.Discarding() {
 		t.Fatal("discarding = true, want false")
 	}
 }
 
 func TestObservedSSEStream_DropsOversizedPendingPrefixBeforeCombining(t *testing.T) {
 	observer := &trackingObserver{}
-	s := &ObservedSSEStream{
-		observers: []Observer{observer},
-		pending: append(
-			[]byte("data: {\"id\":\"stale\"}\n\n"),
-			bytes.Repeat([]byte("x"), maxPendingEventBytes)...,
-		),
-	}
+	s := newTestObservedSSEStream(observer)
+	s.processChunk(append(
+		[]byte("data: {\"id\":\"stale\""),
+		bytes.Repeat([]byte("x"), maxPendingEventBytes)...,
+	))
 
 	s.processChunk([]byte("\n\ndata: {\"id\":\"fresh\"}\n\n"))
 
@@ -233,8 +350,8 @@ func TestObservedSSEStream_DropsOversizedPendingPrefixBeforeCombining(t *testing
 	if observer.lastID != "fresh" {
 		t.Fatalf("lastID = %q, want fresh", observer.lastID)
 	}
-	if len(s.pending) != 0 {
-		t.Fatalf("pending length = %d, want 0", len(s.pending))
+	if got := s.parser.Buffered(); got != 0 {
+		t.Fatalf("buffered length = %d, want 0", got)
 	}
 }
 
@@ -289,49 +406,3 @@ func TestObservedSSEStream_ParsesCRLFBufferedEventsOnClose(t *testing.T) {
 		t.Fatal("observer was not closed")
 	}
 }
-
-func TestJoinedSuffix(t *testing.T) {
-	tests := []struct {
-		name   string
-		prefix []byte
-		data   []byte
-		n      int
-		want   []byte
-	}{
-		{
-			name: "returns nil for non-positive length",
-			data: []byte("abc"),
-			n:    0,
-			want: nil,
-		},
-		{
-			name: "returns suffix from data when data is long enough",
-			data: []byte("abcdef"),
-			n:    3,
-			want: []byte("def"),
-		},
-		{
-			name:   "combines prefix tail and data",
-			prefix: []byte("abcd"),
-			data:   []byte("ef"),
-			n:      4,
-			want:   []byte("cdef"),
-		},
-		{
-			name:   "uses available prefix bytes only",
-			prefix: []byte("ab"),
-			data:   []byte("cd"),
-			n:      5,
-			want:   []byte("abcd"),
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := joinedSuffix(tt.prefix, tt.data, tt.n)
-			if !bytes.Equal(got, tt.want) {
-				t.Fatalf("joinedSuffix() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
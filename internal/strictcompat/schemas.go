@@ -0,0 +1,288 @@
+package strictcompat
+
+// The allow-lists below mirror the official OpenAI response schemas. The
+// contract suite (tests/contract/strict_compat_test.go) checks them against
+// the recorded OpenAI payloads in tests/contract/testdata/openai: every key of
+// a recording must be allowed, and a filtered gateway response must not carry
+// keys the recording lacks. Update both when OpenAI adds a field.
+
+var chatUsageSchema = Schema{
+	"prompt_tokens":     nil,
+	"completion_tokens": nil,
+	"total_tokens":      nil,
+	"prompt_tokens_details": {
+		"cached_tokens": nil,
+		"audio_tokens":  nil,
+	},
+	"completion_tokens_details": {
+		"reasoning_tokens":           nil,
+		"audio_tokens":               nil,
+		"accepted_prediction_tokens": nil,
+		"rejected_prediction_tokens": nil,
+	},
+}
+
+var functionCallSchema = Schema{
+	"name":      nil,
+	"arguments": nil,
+}
+
+// ChatCompletion is the allow-list of a chat.completion object.
+var ChatCompletion = Schema{
+	"id":                 nil,
+	"object":             nil,
+	"created":            nil,
+	"model":              nil,
+	"service_tier":       nil,
+	"system_fingerprint": nil,
+	"choices": {
+		"index":         nil,
+		"finish_reason": nil,
+		"logprobs":      nil,
+		"message": {
+			"role":        nil,
+			"content":     nil,
+			"refusal":     nil,
+			"annotations": nil,
+			"audio":       nil,
+			"tool_calls": {
+				"id":       nil,
+				"type":     nil,
+				"function": functionCallSchema,
+			},
+			"function_call": functionCallSchema,
+		},
+	},
+	"usage": chatUsageSchema,
+}
+
+// ChatCompletionChunk is the allow-list of a chat.completion.chunk event.
+var ChatCompletionChunk = Schema{
+	"id":                 nil,
+	"object":             nil,
+	"created":            nil,
+	"model":              nil,
+	"service_tier":       nil,
+	"system_fingerprint": nil,
+	"obfuscation":        nil,
+	"choices": {
+		"index":         nil,
+		"finish_reason": nil,
+		"logprobs":      nil,
+		"delta": {
+			"role":    nil,
+			"content": nil,
+			"refusal": nil,
+			"tool_calls": {
+				"index":    nil,
+				"id":       nil,
+				"type":     nil,
+				"function": functionCallSchema,
+			},
+			"function_call": functionCallSchema,
+		},
+	},
+	"usage": chatUsageSchema,
+}
+
+// Model is the allow-list of a model object.
+var Model = Schema{
+	"id":       nil,
+	"object":   nil,
+	"created":  nil,
+	"owned_by": nil,
+}
+
+// ModelList is the allow-list of GET /v1/models.
+var ModelList = Schema{
+	"object": nil,
+	"data":   Model,
+}
+
+// EmbeddingList is the allow-list of an embeddings response.
+var EmbeddingList = Schema{
+	"object": nil,
+	"model":  nil,
+	"data": {
+		"object":    nil,
+		"embedding": nil,
+		"index":     nil,
+	},
+	"usage": {
+		"prompt_tokens": nil,
+		"total_tokens":  nil,
+	},
+}
+
+var responseContentSchema = Schema{
+	"type":        nil,
+	"text":        nil,
+	"annotations": nil,
+	"logprobs":    nil,
+	"refusal":     nil,
+}
+
+var responseOutputItemSchema = Schema{
+	"id":                nil,
+	"type":              nil,
+	"status":            nil,
+	"role":              nil,
+	"content":           responseContentSchema,
+	"call_id":           nil,
+	"name":              nil,
+	"arguments":         nil,
+	"summary":           nil,
+	"encrypted_content": nil,
+	"action":            nil,
+	"queries":           nil,
+	"results":           nil,
+	"server_label":      nil,
+	"output":            nil,
+	"error":             nil,
+}
+
+// Response is the allow-list of a Responses API response object.
+var Response = Schema{
+	"id":                     nil,
+	"object":                 nil,
+	"created_at":             nil,
+	"completed_at":           nil,
+	"status":                 nil,
+	"background":             nil,
+	"billing":                nil,
+	"conversation":           nil,
+	"error":                  nil,
+	"incomplete_details":     nil,
+	"instructions":           nil,
+	"max_output_tokens":      nil,
+	"max_tool_calls":         nil,
+	"metadata":               nil,
+	"model":                  nil,
+	"output":                 responseOutputItemSchema,
+	"parallel_tool_calls":    nil,
+	"frequency_penalty":      nil,
+	"presence_penalty":       nil,
+	"previous_response_id":   nil,
+	"prompt":                 nil,
+	"prompt_cache_key":       nil,
+	"prompt_cache_retention": nil,
+	"reasoning":              nil,
+	"safety_identifier":      nil,
+	"service_tier":           nil,
+	"store":                  nil,
+	"temperature":            nil,
+	"text":                   nil,
+	"tool_choice":            nil,
+	"tools":                  nil,
+	"top_logprobs":           nil,
+	"top_p":                  nil,
+	"truncation":             nil,
+	"user":                   nil,
+	"usage": {
+		"input_tokens": nil,
+		"input_tokens_details": {
+			"cached_tokens": nil,
+		},
+		"output_tokens": nil,
+		"output_tokens_details": {
+			"reasoning_tokens": nil,
+		},
+		"total_tokens": nil,
+	},
+}
+
+// ResponseStreamEvent is the allow-list of a Responses API stream event.
+var ResponseStreamEvent = Schema{
+	"type":             nil,
+	"sequence_number":  nil,
+	"response":         Response,
+	"item":             responseOutputItemSchema,
+	"item_id":          nil,
+	"output_index":     nil,
+	"content_index":    nil,
+	"summary_index":    nil,
+	"annotation_index": nil,
+	"part":             responseContentSchema,
+	"delta":            nil,
+	"text":             nil,
+	"refusal":          nil,
+	"arguments":        nil,
+	"name":             nil,
+	"annotation":       nil,
+	"logprobs":         nil,
+	"obfuscation":      nil,
+	"code":             nil,
+	"message":          nil,
+	"param":            nil,
+}
+
+// File is the allow-list of a file object.
+var File = Schema{
+	"id":             nil,
+	"object":         nil,
+	"bytes":          nil,
+	"created_at":     nil,
+	"expires_at":     nil,
+	"filename":       nil,
+	"purpose":        nil,
+	"status":         nil,
+	"status_details": nil,
+}
+
+// Batch is the allow-list of a batch object.
+var Batch = Schema{
+	"id":                nil,
+	"object":            nil,
+	"endpoint":          nil,
+	"model":             nil,
+	"errors":            nil,
+	"input_file_id":     nil,
+	"completion_window": nil,
+	"status":            nil,
+	"output_file_id":    nil,
+	"error_file_id":     nil,
+	"created_at":        nil,
+	"in_progress_at":    nil,
+	"expires_at":        nil,
+	"finalizing_at":     nil,
+	"completed_at":      nil,
+	"failed_at":         nil,
+	"expired_at":        nil,
+	"cancelling_at":     nil,
+	"cancelled_at":      nil,
+	"request_counts": {
+		"total":     nil,
+		"completed": nil,
+		"failed":    nil,
+	},
+	"metadata": nil,
+	"usage":    nil,
+}
+
+// Deleted is the allow-list of a deletion confirmation.
+var Deleted = Schema{
+	"id":      nil,
+	"object":  nil,
+	"deleted": nil,
+}
+
+// List returns the allow-list of a cursor-paginated list of items.
+func List(item Schema) Schema {
+	return Schema{
+		"object":   nil,
+		"data":     item,
+		"first_id": nil,
+		"last_id":  nil,
+		"has_more": nil,
+	}
+}
+
+// Error is the allow-list of an error envelope.
+var Error = Schema{
+	"error": {
+		"message": nil,
+		"type":    nil,
+		"param":   nil,
+		"code":    nil,
+	},
+}
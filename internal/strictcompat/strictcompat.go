@@ -0,0 +1,221 @@
+// Package strictcompat strips gateway extensions from OpenAI-compatible
+// responses for clients whose parsers reject unknown fields.
+//
+// Responses are filtered through allow-lists of the fields in the official
+// OpenAI schemas: unknown keys are dropped, the remaining bytes are kept in
+// their original order, and error bodies are rewritten to OpenAI's exact
+// error envelope. Gateway extension headers are dropped as well, except
+// X-Request-ID.
+package strictcompat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"gomodel/internal/core"
+)
+
+// Header overrides the configured mode for one request: "true" turns strict
+// compatibility on, "false" turns it off. It is the standalone form of the
+// strict_compat request option.
+const Header = core.StrictCompatHeader
+
+// Schema is the allow-list of a JSON object: each allowed key maps to the
+// schema of its value. A nil schema keeps the value as it is. Arrays are
+// filtered element by element with the schema of the array.
+type Schema map[string]Schema
+
+// Active reports whether strict compatibility applies to a request, given
+// the configured default and the request's Header value.
+func Active(enabled bool, headerValue string) bool {
+	if override, err := strconv.ParseBool(strings.TrimSpace(headerValue)); err == nil {
+		return override
+	}
+	return enabled
+}
+
+// Applies reports whether path is an OpenAI-compatible endpoint that strict
+// compatibility covers. Admin and provider passthrough routes never are.
+func Applies(path string) bool {
+	return path == "/v1" || strings.HasPrefix(path, "/v1/")
+}
+
+// Route returns the allow-lists of a successful response to method and path:
+// body for JSON responses and event for the data of stream events. ok is
+// false for endpoints with no OpenAI counterpart, whose bodies are left as
+// they are.
+func Route(method, path string) (body, event Schema, ok bool) {
+	path = strings.TrimSuffix(path, "/")
+	rest, found := strings.CutPrefix(path, "/v1/")
+	if !found {
+		return nil, nil, false
+	}
+	segments := strings.Split(rest, "/")
+	switch segments[0] {
+	case "models":
+		if len(segments) == 1 {
+			return ModelList, nil, true
+		}
+		return Model, nil, true
+	case "chat":
+		if rest == "chat/completions" {
+			return ChatCompletion, ChatCompletionChunk, true
+		}
+	case "embeddings":
+		if len(segments) == 1 {
+			return EmbeddingList, nil, true
+		}
+	case "responses":
+		switch {
+		case len(segments) == 1:
+			return Response, ResponseStreamEvent, true
+		case segments[1] == "input_tokens" || segments[1] == "compact":
+			return nil, nil, false
+		case len(segments) == 2 && method == http.MethodDelete:
+			return Deleted, nil, true
+		case len(segments) == 2 || (len(segments) == 3 && segments[2] == "cancel"):
+			return Response, ResponseStreamEvent, true
+		case len(segments) == 3 && segments[2] == "input_items":
+			return List(nil), nil, true
+		}
+	case "files":
+		switch {
+		case len(segments) == 1 && method == http.MethodGet:
+			return List(File), nil, true
+		case len(segments) == 1:
+			return File, nil, true
+		case len(segments) == 2 && method == http.MethodDelete:
+			return Deleted, nil, true
+		case len(segments) == 2:
+			return File, nil, true
+		}
+	case "batches":
+		switch {
+		case len(segments) == 1 && method == http.MethodGet:
+			return List(Batch), nil, true
+		case len(segments) == 1:
+			return Batch, nil, true
+		case len(segments) == 2 || (len(segments) == 3 && segments[2] == "cancel"):
+			return Batch, nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Filter returns body with every key the schema does not allow removed.
+// Bodies that are not valid JSON are returned unchanged with ok false.
+func Filter(body []byte, schema Schema) ([]byte, bool) {
+	if !gjson.ValidBytes(body) {
+		return body, false
+	}
+	return schema.filter(make([]byte, 0, len(body)), gjson.ParseBytes(body)), true
+}
+
+func (s Schema) filter(dst []byte, value gjson.Result) []byte {
+	switch {
+	case s == nil:
+		return append(dst, value.Raw...)
+	case value.IsArray():
+		dst = append(dst, '[')
+		first := true
+		value.ForEach(func(_, item gjson.Result) bool {
+			if !first {
+				dst = append(dst, ',')
+			}
+			first = false
+			dst = s.filter(dst, item)
+			return true
+		})
+		return append(dst, ']')
+	case value.IsObject():
+		dst = append(dst, '{')
+		first := true
+		value.ForEach(func(key, item gjson.Result) bool {
+			child, allowed := s[key.Str]
+			if !allowed {
+				return true
+			}
+			if !first {
+				dst = append(dst, ',')
+			}
+			first = false
+			dst = append(dst, key.Raw...)
+			dst = append(dst, ':')
+			dst = child.filter(dst, item)
+			return true
+		})
+		return append(dst, '}')
+	default:
+		return append(dst, value.Raw...)
+	}
+}
+
+// errorEnvelope is OpenAI's error envelope, with its fields in OpenAI's order.
+type errorEnvelope struct {
+	Error struct {
+		Message string  `json:"message"`
+		Type    string  `json:"type"`
+		Param   *string `json:"param"`
+		Code    *string `json:"code"`
+	} `json:"error"`
+}
+
+// ErrorBody rewrites a gateway error body to OpenAI's error envelope. Error
+// types only the gateway uses are mapped to the OpenAI type clients expect
+// for the same failure. Bodies that are not error envelopes are returned
+// unchanged with ok false.
+func ErrorBody(body []byte) ([]byte, bool) {
+	errValue := gjson.GetBytes(body, "error")
+	if !errValue.IsObject() {
+		return body, false
+	}
+	var envelope errorEnvelope
+	envelope.Error.Message = errValue.Get("message").String()
+	envelope.Error.Type = openAIErrorType(core.ErrorType(errValue.Get("type").String()))
+	envelope.Error.Param = optionalString(errValue.Get("param"))
+	envelope.Error.Code = optionalString(errValue.Get("code"))
+	out, err := json.Marshal(envelope)
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+func openAIErrorType(errorType core.ErrorType) string {
+	switch errorType {
+	case core.ErrorTypeProvider, core.ErrorTypeOverloaded:
+		return "server_error"
+	case core.ErrorTypeNotFound, core.ErrorTypeContentFilter:
+		return string(core.ErrorTypeInvalidRequest)
+	default:
+		return string(errorType)
+	}
+}
+
+func optionalString(value gjson.Result) *string {
+	if !value.Exists() || value.Type == gjson.Null {
+		return nil
+	}
+	s := value.String()
+	return &s
+}
+
+// StripHeaders removes the gateway's extension headers, keeping X-Request-ID.
+func StripHeaders(header http.Header) {
+	for name := range header {
+		if IsExtensionHeader(name) {
+			header.Del(name)
+		}
+	}
+}
+
+// IsExtensionHeader reports whether name is a response header the gateway
+// adds on top of the OpenAI API.
+func IsExtensionHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return strings.HasPrefix(name, "X-Gomodel-") || name == "X-Cache"
+}
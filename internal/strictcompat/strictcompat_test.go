@@ -0,0 +1,111 @@
+package strictcompat
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	body := `{"id":"chatcmpl-1","provider":"openai","object":"chat.completion","choices":[` +
+		`{"index":0,"message":{"role":"assistant","content":"hi","reasoning_content":"hmm","tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{\"a\":1}"},"extra":true}]},"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3,"prompt_tokens_details":{"cached_tokens":0,"text_tokens":1},"raw_usage":{"x":1}}}`
+	want := `{"id":"chatcmpl-1","object":"chat.completion","choices":[` +
+		`{"index":0,"message":{"role":"assistant","content":"hi","tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{\"a\":1}"}}]},"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3,"prompt_tokens_details":{"cached_tokens":0}}}`
+
+	got, ok := Filter([]byte(body), ChatCompletion)
+	if !ok || string(got) != want {
+		t.Fatalf("Filter() = %s, %v\nwant %s", got, ok, want)
+	}
+
+	if got, ok := Filter([]byte("not json"), ChatCompletion); ok || string(got) != "not json" {
+		t.Fatalf("Filter(invalid) = %s, %v, want the body unchanged", got, ok)
+	}
+}
+
+func TestErrorBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "provider error",
+			body: `{"error":{"type":"provider_error","message":"upstream failed","param":null,"code":null,"provider_error":{"detail":"x"}}}`,
+			want: `{"error":{"message":"upstream failed","type":"server_error","param":null,"code":null}}`,
+		},
+		{
+			name: "not found with code",
+			body: `{"error":{"type":"not_found_error","message":"model not found","code":"model_not_found"}}`,
+			want: `{"error":{"message":"model not found","type":"invalid_request_error","param":null,"code":"model_not_found"}}`,
+		},
+		{
+			name: "openai type kept",
+			body: `{"error":{"type":"rate_limit_error","message":"slow down","param":"model","code":"rate_limit_exceeded"}}`,
+			want: `{"error":{"message":"slow down","type":"rate_limit_error","param":"model","code":"rate_limit_exceeded"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ErrorBody([]byte(tt.body))
+			if !ok || string(got) != tt.want {
+				t.Fatalf("ErrorBody() = %s, %v\nwant %s", got, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := ErrorBody([]byte(`{"message":"Not Found"}`)); ok {
+		t.Fatal("ErrorBody() rewrote a body without an error object")
+	}
+}
+
+func TestActive(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		header  string
+		want    bool
+	}{
+		{enabled: false, header: "", want: false},
+		{enabled: true, header: "", want: true},
+		{enabled: false, header: "true", want: true},
+		{enabled: true, header: "false", want: false},
+		{enabled: true, header: "maybe", want: true},
+	}
+	for _, tt := range tests {
+		if got := Active(tt.enabled, tt.header); got != tt.want {
+			t.Errorf("Active(%v, %q) = %v, want %v", tt.enabled, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		method    string
+		path      string
+		wantBody  Schema
+		wantEvent bool
+		wantOK    bool
+	}{
+		{method: http.MethodGet, path: "/v1/models", wantBody: ModelList, wantOK: true},
+		{method: http.MethodGet, path: "/v1/models/gpt-4o", wantBody: Model, wantOK: true},
+		{method: http.MethodPost, path: "/v1/chat/completions", wantBody: ChatCompletion, wantEvent: true, wantOK: true},
+		{method: http.MethodPost, path: "/v1/responses", wantBody: Response, wantEvent: true, wantOK: true},
+		{method: http.MethodDelete, path: "/v1/responses/resp_1", wantBody: Deleted, wantOK: true},
+		{method: http.MethodPost, path: "/v1/embeddings", wantBody: EmbeddingList, wantOK: true},
+		{method: http.MethodPost, path: "/v1/files", wantBody: File, wantOK: true},
+		{method: http.MethodGet, path: "/v1/batches/batch_1", wantBody: Batch, wantOK: true},
+		{method: http.MethodPost, path: "/v1/chat/completions/compare"},
+		{method: http.MethodGet, path: "/v1/deferred/d_1"},
+		{method: http.MethodGet, path: "/admin/api/v1/models"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			body, event, ok := Route(tt.method, tt.path)
+			if ok != tt.wantOK || (event != nil) != tt.wantEvent {
+				t.Fatalf("Route() ok = %v, event = %v, want ok %v and event %v", ok, event != nil, tt.wantOK, tt.wantEvent)
+			}
+			if tt.wantBody != nil && len(body) != len(tt.wantBody) {
+				t.Fatalf("Route() body schema has %d keys, want %d", len(body), len(tt.wantBody))
+			}
+		})
+	}
+}
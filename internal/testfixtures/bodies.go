@@ -0,0 +1,174 @@
+package testfixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChatCompletion describes an OpenAI-style chat completion body. Zero fields
+// take defaults: ID "chatcmpl-123", DefaultModel, DefaultReply, finish reason
+// "stop", and 10 prompt and 20 completion tokens when both counts are zero.
+type ChatCompletion struct {
+	ID               string
+	Model            string
+	Content          string
+	FinishReason     string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// JSON renders the body.
+func (c ChatCompletion) JSON() string {
+	if c.ID == "" {
+		c.ID = "chatcmpl-123"
+	}
+	if c.Model == "" {
+		c.Model = DefaultModel
+	}
+	if c.Content == "" {
+		c.Content = DefaultReply
+	}
+	if c.FinishReason == "" {
+		c.FinishReason = "stop"
+	}
+	if c.PromptTokens == 0 && c.CompletionTokens == 0 {
+		c.PromptTokens, c.CompletionTokens = 10, 20
+	}
+	return mustJSON(map[string]any{
+		"id":      c.ID,
+		"object":  "chat.completion",
+		"created": DefaultCreated,
+		"model":   c.Model,
+		"choices": []any{map[string]any{
+			"index":         0,
+			"message":       map[string]any{"role": "assistant", "content": c.Content},
+			"finish_reason": c.FinishReason,
+		}},
+		"usage": map[string]any{
+			"prompt_tokens":     c.PromptTokens,
+			"completion_tokens": c.CompletionTokens,
+			"total_tokens":      c.PromptTokens + c.CompletionTokens,
+		},
+	})
+}
+
+// ChatCompletionChunks renders an OpenAI-style chat completion event stream
+// with one chunk per delta, terminated by "data: [DONE]".
+func ChatCompletionChunks(model string, deltas ...string) string {
+	var b strings.Builder
+	for _, delta := range deltas {
+		fmt.Fprintf(&b, "data: %s\n\n", mustJSON(map[string]any{
+			"id":      "chatcmpl-123",
+			"object":  "chat.completion.chunk",
+			"created": DefaultCreated,
+			"model":   model,
+			"choices": []any{map[string]any{
+				"index":         0,
+				"delta":         map[string]any{"content": delta},
+				"finish_reason": nil,
+			}},
+		}))
+	}
+	b.WriteString("data: [DONE]\n")
+	return b.String()
+}
+
+// ModelsList renders an OpenAI-style model list owned by ownedBy.
+func ModelsList(ownedBy string, ids ...string) string {
+	data := make([]any, 0, len(ids))
+	for i, id := range ids {
+		data = append(data, map[string]any{
+			"id":       id,
+			"object":   "model",
+			"created":  DefaultCreated - i,
+			"owned_by": ownedBy,
+		})
+	}
+	return mustJSON(map[string]any{"object": "list", "data": data})
+}
+
+// ResponsesResponse describes an OpenAI Responses API body with one output
+// message. Zero fields take defaults: ID "resp_123", DefaultModel,
+// DefaultReply, and 10 input and 20 output tokens when both are zero.
+type ResponsesResponse struct {
+	ID           string
+	Model        string
+	Content      string
+	InputTokens  int
+	OutputTokens int
+}
+
+// JSON renders the body.
+func (r ResponsesResponse) JSON() string {
+	if r.ID == "" {
+		r.ID = "resp_123"
+	}
+	if r.Model == "" {
+		r.Model = DefaultModel
+	}
+	if r.Content == "" {
+		r.Content = DefaultReply
+	}
+	if r.InputTokens == 0 && r.OutputTokens == 0 {
+		r.InputTokens, r.OutputTokens = 10, 20
+	}
+	return mustJSON(map[string]any{
+		"id":         r.ID,
+		"object":     "response",
+		"created_at": DefaultCreated,
+		"model":      r.Model,
+		"status":     "completed",
+		"output": []any{map[string]any{
+			"id":      "msg_123",
+			"type":    "message",
+			"role":    "assistant",
+			"status":  "completed",
+			"content": []any{map[string]any{"type": "output_text", "text": r.Content}},
+		}},
+		"usage": map[string]any{
+			"input_tokens":  r.InputTokens,
+			"output_tokens": r.OutputTokens,
+			"total_tokens":  r.InputTokens + r.OutputTokens,
+		},
+	})
+}
+
+// ResponsesEvents renders an OpenAI Responses API event stream: a created
+// event, one output text delta per delta, and a completed event.
+func ResponsesEvents(model string, deltas ...string) string {
+	var b strings.Builder
+	writeEvent := func(event string, data map[string]any) {
+		fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", event, mustJSON(data))
+	}
+	response := func(status string) map[string]any {
+		return map[string]any{"id": "resp_123", "object": "response", "status": status, "model": model}
+	}
+	writeEvent("response.created", map[string]any{"type": "response.created", "response": response("in_progress")})
+	for _, delta := range deltas {
+		writeEvent("response.output_text.delta", map[string]any{"type": "response.output_text.delta", "delta": delta})
+	}
+	writeEvent("response.completed", map[string]any{"type": "response.completed", "response": response("completed")})
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// OpenAIError renders an OpenAI-style error body.
+func OpenAIError(message string) string {
+	return mustJSON(map[string]any{"error": map[string]any{"message": message}})
+}
+
+// AnthropicError renders an Anthropic-style error body.
+func AnthropicError(errType, message string) string {
+	return mustJSON(map[string]any{
+		"type":  "error",
+		"error": map[string]any{"type": errType, "message": message},
+	})
+}
+
+func mustJSON(v any) string {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("testfixtures: marshal fixture: %v", err))
+	}
+	return string(body)
+}
@@ -0,0 +1,571 @@
+package testfixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"gomodel/internal/core"
+)
+
+// conversionChecks is the number of random cases each property runs.
+const conversionChecks = 300
+
+// ConvertedMessage is one non-system message of a converted request.
+type ConvertedMessage struct {
+	Role string
+	Text string
+}
+
+// ConvertedRequest is the provider-neutral view of a converted request that
+// CheckConversionProperties compares with its source. System holds the
+// system text in order; a provider with a single system field reports it as
+// one entry.
+type ConvertedRequest struct {
+	System      []string
+	Messages    []ConvertedMessage
+	Temperature *float64
+	MaxTokens   *int
+	Stream      bool
+}
+
+// GeneratedResponse is a random completion that ConversionSuite.Response
+// renders in the provider's native shape before converting it.
+type GeneratedResponse struct {
+	ID           string
+	Model        string
+	Text         string
+	InputTokens  int
+	OutputTokens int
+	// OmitTotal leaves the total token count out, as some upstreams do.
+	OmitTotal bool
+}
+
+// ConversionSuite opts a provider into the conversion properties. Nil
+// functions are skipped.
+type ConversionSuite struct {
+	// ChatRequest converts a chat request into the provider's payload.
+	ChatRequest func(*core.ChatRequest) (ConvertedRequest, error)
+	// ResponsesRequest converts a Responses request into the provider's payload.
+	ResponsesRequest func(*core.ResponsesRequest) (ConvertedRequest, error)
+	// Response converts a native completion; either result is nil when the
+	// provider has no such conversion.
+	Response func(GeneratedResponse) (*core.ChatResponse, *core.ResponsesResponse)
+}
+
+// ChatAdapterSuite is the suite of a provider that serves the Responses API
+// through the shared chat adapter and forwards chat requests unchanged.
+func ChatAdapterSuite(
+	toChat func(*core.ResponsesRequest) (*core.ChatRequest, error),
+	toResponses func(*core.ChatResponse) *core.ResponsesResponse,
+) ConversionSuite {
+	return ConversionSuite{
+		ResponsesRequest: func(req *core.ResponsesRequest) (ConvertedRequest, error) {
+			chatReq, err := toChat(req)
+			if err != nil {
+				return ConvertedRequest{}, err
+			}
+			return ChatRequestView(chatReq), nil
+		},
+		Response: func(gen GeneratedResponse) (*core.ChatResponse, *core.ResponsesResponse) {
+			return nil, toResponses(ChatResponseFor(gen))
+		},
+	}
+}
+
+// ChatRequestView returns the neutral view of an OpenAI-style chat request.
+// System and developer messages both count as system text.
+func ChatRequestView(req *core.ChatRequest) ConvertedRequest {
+	if req == nil {
+		return ConvertedRequest{}
+	}
+	view := ConvertedRequest{Temperature: req.Temperature, MaxTokens: req.MaxTokens, Stream: req.Stream}
+	for _, msg := range req.Messages {
+		text := core.ExtractTextContent(msg.Content)
+		if isSystemRole(msg.Role) {
+			view.System = append(view.System, text)
+			continue
+		}
+		view.Messages = append(view.Messages, ConvertedMessage{Role: msg.Role, Text: text})
+	}
+	return view
+}
+
+// ChatResponseFor renders gen as an OpenAI-style chat response.
+func ChatResponseFor(gen GeneratedResponse) *core.ChatResponse {
+	resp := &core.ChatResponse{
+		ID:     gen.ID,
+		Object: "chat.completion",
+		Model:  gen.Model,
+		Choices: []core.Choice{{
+			Message:      core.ResponseMessage{Role: "assistant", Content: gen.Text},
+			FinishReason: "stop",
+		}},
+		Usage: core.Usage{PromptTokens: gen.InputTokens, CompletionTokens: gen.OutputTokens},
+	}
+	if !gen.OmitTotal {
+		resp.Usage.TotalTokens = gen.InputTokens + gen.OutputTokens
+	}
+	return resp
+}
+
+// CheckConversionProperties checks the conversions of suite against random
+// requests and responses:
+//
+//   - messages keep their count, order, role and text; only system text may
+//     be merged, and it keeps its order
+//   - temperature and stream are never changed, and max tokens only default
+//     when unset
+//   - usage totals equal input plus output tokens
+//   - the output text equals the source completion text
+//   - nil and empty values do not panic
+//
+// Failing cases are shrunk to a minimal one before they are reported.
+// Reasoning settings are not generated: how they map is provider-specific.
+func CheckConversionProperties(t *testing.T, suite ConversionSuite) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+
+	t.Run("nil and empty values", func(t *testing.T) {
+		if suite.ChatRequest != nil {
+			for _, req := range []*core.ChatRequest{nil, {}} {
+				if err := recovered(func() { _, _ = suite.ChatRequest(req) }); err != nil {
+					t.Errorf("ChatRequest(%#v): %v", req, err)
+				}
+			}
+		}
+		if suite.ResponsesRequest != nil {
+			for _, req := range []*core.ResponsesRequest{nil, {}} {
+				if err := recovered(func() { _, _ = suite.ResponsesRequest(req) }); err != nil {
+					t.Errorf("ResponsesRequest(%#v): %v", req, err)
+				}
+			}
+		}
+		if suite.Response != nil {
+			if err := recovered(func() { _, _ = suite.Response(GeneratedResponse{}) }); err != nil {
+				t.Errorf("Response(zero value): %v", err)
+			}
+		}
+	})
+
+	if suite.ChatRequest != nil {
+		t.Run("chat request", func(t *testing.T) {
+			checkProperty(t, seed, func(c chatCase) error { return c.violation(suite.ChatRequest) }, chatCase.shrink)
+		})
+	}
+	if suite.ResponsesRequest != nil {
+		t.Run("responses request", func(t *testing.T) {
+			checkProperty(t, seed, func(c responsesCase) error { return c.violation(suite.ResponsesRequest) }, responsesCase.shrink)
+		})
+	}
+	if suite.Response != nil {
+		t.Run("response", func(t *testing.T) {
+			checkProperty(t, seed, func(c responseCase) error { return c.violation(suite.Response) }, responseCase.shrink)
+		})
+	}
+}
+
+// checkProperty runs property against random cases and reports the first
+// failure after shrinking it.
+func checkProperty[C any](t *testing.T, seed int64, property func(C) error, shrink func(C) []C) {
+	t.Helper()
+	check := func(c C) (err error) {
+		if panicErr := recovered(func() { err = property(c) }); panicErr != nil {
+			return panicErr
+		}
+		return err
+	}
+	config := &quick.Config{MaxCount: conversionChecks, Rand: rand.New(rand.NewSource(seed))}
+	failure := quick.Check(func(c C) bool { return check(c) == nil }, config)
+	if failure == nil {
+		return
+	}
+	checkErr, ok := failure.(*quick.CheckError)
+	if !ok {
+		t.Fatalf("quick.Check: %v", failure)
+	}
+	minimal := checkErr.In[0].(C)
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range shrink(minimal) {
+			if check(candidate) != nil {
+				minimal, shrunk = candidate, true
+				break
+			}
+		}
+	}
+	encoded, _ := json.MarshalIndent(minimal, "", "  ")
+	t.Errorf("property failed (seed %d): %v\nminimal case: %s", seed, check(minimal), encoded)
+}
+
+func recovered(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	fn()
+	return nil
+}
+
+type chatCase struct {
+	Request *core.ChatRequest
+}
+
+// Generate implements quick.Generator.
+func (chatCase) Generate(r *rand.Rand, _ int) reflect.Value {
+	req := &core.ChatRequest{
+		Model:       DefaultModel,
+		Temperature: randomTemperature(r),
+		MaxTokens:   randomMaxTokens(r),
+		Stream:      r.Intn(2) == 0,
+	}
+	for range 1 + r.Intn(6) {
+		role := randomRole(r)
+		req.Messages = append(req.Messages, core.Message{Role: role, Content: randomContent(r, "text")})
+	}
+	return reflect.ValueOf(chatCase{Request: req})
+}
+
+func (c chatCase) violation(convert func(*core.ChatRequest) (ConvertedRequest, error)) error {
+	got, err := convert(c.Request)
+	if err != nil {
+		return fmt.Errorf("conversion failed: %w", err)
+	}
+	return compareConvertedRequest(ChatRequestView(c.Request), got)
+}
+
+func (c chatCase) shrink() []chatCase {
+	var out []chatCase
+	with := func(edit func(*core.ChatRequest)) {
+		req := *c.Request
+		req.Messages = append([]core.Message(nil), c.Request.Messages...)
+		edit(&req)
+		out = append(out, chatCase{Request: &req})
+	}
+	for i := range c.Request.Messages {
+		with(func(req *core.ChatRequest) { req.Messages = append(req.Messages[:i], req.Messages[i+1:]...) })
+	}
+	for i, msg := range c.Request.Messages {
+		if core.HasStructuredContent(msg.Content) {
+			with(func(req *core.ChatRequest) { req.Messages[i].Content = core.ExtractTextContent(msg.Content) })
+		} else if text, ok := msg.Content.(string); ok && shorterText(text) != text {
+			with(func(req *core.ChatRequest) { req.Messages[i].Content = shorterText(text) })
+		}
+	}
+	if c.Request.Temperature != nil {
+		with(func(req *core.ChatRequest) { req.Temperature = nil })
+	}
+	if c.Request.MaxTokens != nil {
+		with(func(req *core.ChatRequest) { req.MaxTokens = nil })
+	}
+	if c.Request.Stream {
+		with(func(req *core.ChatRequest) { req.Stream = false })
+	}
+	return out
+}
+
+type responsesCase struct {
+	Request *core.ResponsesRequest
+}
+
+// Generate implements quick.Generator.
+func (responsesCase) Generate(r *rand.Rand, _ int) reflect.Value {
+	req := &core.ResponsesRequest{
+		Model:           DefaultModel,
+		Temperature:     randomTemperature(r),
+		MaxOutputTokens: randomMaxTokens(r),
+		Stream:          r.Intn(2) == 0,
+	}
+	if r.Intn(2) == 0 {
+		req.Instructions = randomText(r)
+	}
+	if r.Intn(5) == 0 {
+		req.Input = randomText(r)
+		return reflect.ValueOf(responsesCase{Request: req})
+	}
+	var input []core.ResponsesInputElement
+	for range 1 + r.Intn(6) {
+		role := randomRole(r)
+		partType := "input_text"
+		if role == "assistant" {
+			partType = "output_text"
+		}
+		input = append(input, core.ResponsesInputElement{Type: "message", Role: role, Content: randomContent(r, partType)})
+	}
+	req.Input = input
+	return reflect.ValueOf(responsesCase{Request: req})
+}
+
+func (c responsesCase) violation(convert func(*core.ResponsesRequest) (ConvertedRequest, error)) error {
+	got, err := convert(c.Request)
+	if err != nil {
+		return fmt.Errorf("conversion failed: %w", err)
+	}
+	return compareConvertedRequest(c.expected(), got)
+}
+
+// expected is the view of the request as a chat request: the instructions
+// come first, followed by the input in order.
+func (c responsesCase) expected() ConvertedRequest {
+	req := c.Request
+	view := ConvertedRequest{Temperature: req.Temperature, MaxTokens: req.MaxOutputTokens, Stream: req.Stream}
+	if req.Instructions != "" {
+		view.System = append(view.System, req.Instructions)
+	}
+	switch input := req.Input.(type) {
+	case string:
+		view.Messages = append(view.Messages, ConvertedMessage{Role: "user", Text: input})
+	case []core.ResponsesInputElement:
+		for _, element := range input {
+			text := core.ExtractTextContent(element.Content)
+			if parts, ok := element.Content.([]core.ContentPart); ok {
+				text = joinPartTexts(parts)
+			}
+			if isSystemRole(element.Role) {
+				view.System = append(view.System, text)
+				continue
+			}
+			view.Messages = append(view.Messages, ConvertedMessage{Role: element.Role, Text: text})
+		}
+	}
+	return view
+}
+
+func (c responsesCase) shrink() []responsesCase {
+	var out []responsesCase
+	with := func(edit func(*core.ResponsesRequest)) {
+		req := *c.Request
+		if input, ok := c.Request.Input.([]core.ResponsesInputElement); ok {
+			req.Input = append([]core.ResponsesInputElement(nil), input...)
+		}
+		edit(&req)
+		out = append(out, responsesCase{Request: &req})
+	}
+	if input, ok := c.Request.Input.([]core.ResponsesInputElement); ok {
+		for i := range input {
+			if len(input) > 1 {
+				with(func(req *core.ResponsesRequest) {
+					elements := req.Input.([]core.ResponsesInputElement)
+					req.Input = append(elements[:i], elements[i+1:]...)
+				})
+			}
+			if parts, ok := input[i].Content.([]core.ContentPart); ok {
+				with(func(req *core.ResponsesRequest) {
+					req.Input.([]core.ResponsesInputElement)[i].Content = joinPartTexts(parts)
+				})
+			} else if text, ok := input[i].Content.(string); ok && shorterText(text) != text {
+				with(func(req *core.ResponsesRequest) {
+					req.Input.([]core.ResponsesInputElement)[i].Content = shorterText(text)
+				})
+			}
+		}
+	}
+	if c.Request.Instructions != "" {
+		with(func(req *core.ResponsesRequest) { req.Instructions = "" })
+	}
+	if text, ok := c.Request.Input.(string); ok && shorterText(text) != text {
+		with(func(req *core.ResponsesRequest) { req.Input = shorterText(text) })
+	}
+	if c.Request.Temperature != nil {
+		with(func(req *core.ResponsesRequest) { req.Temperature = nil })
+	}
+	if c.Request.MaxOutputTokens != nil {
+		with(func(req *core.ResponsesRequest) { req.MaxOutputTokens = nil })
+	}
+	if c.Request.Stream {
+		with(func(req *core.ResponsesRequest) { req.Stream = false })
+	}
+	return out
+}
+
+type responseCase struct {
+	Response GeneratedResponse
+}
+
+// Generate implements quick.Generator.
+func (responseCase) Generate(r *rand.Rand, _ int) reflect.Value {
+	gen := GeneratedResponse{
+		ID:           fmt.Sprintf("resp-%d", r.Intn(1000)),
+		Model:        DefaultModel,
+		InputTokens:  r.Intn(5000),
+		OutputTokens: r.Intn(5000),
+		OmitTotal:    r.Intn(3) == 0,
+	}
+	if r.Intn(5) > 0 {
+		gen.Text = randomText(r)
+	}
+	return reflect.ValueOf(responseCase{Response: gen})
+}
+
+func (c responseCase) violation(convert func(GeneratedResponse) (*core.ChatResponse, *core.ResponsesResponse)) error {
+	gen := c.Response
+	wantTotal := gen.InputTokens + gen.OutputTokens
+	chat, responses := convert(gen)
+	if chat != nil {
+		if len(chat.Choices) == 0 {
+			return fmt.Errorf("chat response has no choices")
+		}
+		if text := core.ExtractTextContent(chat.Choices[0].Message.Content); text != gen.Text {
+			return fmt.Errorf("chat content = %q, want %q", text, gen.Text)
+		}
+		usage := chat.Usage
+		if usage.PromptTokens != gen.InputTokens || usage.CompletionTokens != gen.OutputTokens || usage.TotalTokens != wantTotal {
+			return fmt.Errorf("chat usage = %d+%d=%d, want %d+%d=%d",
+				usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens, gen.InputTokens, gen.OutputTokens, wantTotal)
+		}
+	}
+	if responses != nil {
+		if text := responsesOutputText(responses); text != gen.Text {
+			return fmt.Errorf("responses output text = %q, want %q", text, gen.Text)
+		}
+		usage := responses.Usage
+		if usage == nil {
+			return fmt.Errorf("responses usage is missing")
+		}
+		if usage.InputTokens != gen.InputTokens || usage.OutputTokens != gen.OutputTokens || usage.TotalTokens != wantTotal {
+			return fmt.Errorf("responses usage = %d+%d=%d, want %d+%d=%d",
+				usage.InputTokens, usage.OutputTokens, usage.TotalTokens, gen.InputTokens, gen.OutputTokens, wantTotal)
+		}
+	}
+	return nil
+}
+
+func (c responseCase) shrink() []responseCase {
+	var out []responseCase
+	with := func(edit func(*GeneratedResponse)) {
+		gen := c.Response
+		edit(&gen)
+		out = append(out, responseCase{Response: gen})
+	}
+	if c.Response.Text != "" {
+		with(func(gen *GeneratedResponse) { gen.Text = "" })
+		if shorterText(c.Response.Text) != c.Response.Text {
+			with(func(gen *GeneratedResponse) { gen.Text = shorterText(gen.Text) })
+		}
+	}
+	if c.Response.InputTokens != 0 {
+		with(func(gen *GeneratedResponse) { gen.InputTokens = 0 })
+	}
+	if c.Response.OutputTokens != 0 {
+		with(func(gen *GeneratedResponse) { gen.OutputTokens = 0 })
+	}
+	if c.Response.OmitTotal {
+		with(func(gen *GeneratedResponse) { gen.OmitTotal = false })
+	}
+	return out
+}
+
+func compareConvertedRequest(want, got ConvertedRequest) error {
+	if w, g := strings.Join(want.System, "\n\n"), strings.Join(got.System, "\n\n"); w != g {
+		return fmt.Errorf("system text = %q, want %q", g, w)
+	}
+	if len(got.Messages) != len(want.Messages) {
+		return fmt.Errorf("got %d messages %v, want %d %v", len(got.Messages), got.Messages, len(want.Messages), want.Messages)
+	}
+	for i := range want.Messages {
+		if got.Messages[i] != want.Messages[i] {
+			return fmt.Errorf("message %d = %+v, want %+v", i, got.Messages[i], want.Messages[i])
+		}
+	}
+	if (got.Temperature == nil) != (want.Temperature == nil) ||
+		(want.Temperature != nil && *got.Temperature != *want.Temperature) {
+		return fmt.Errorf("temperature = %s, want %s", formatOptional(got.Temperature), formatOptional(want.Temperature))
+	}
+	if want.MaxTokens != nil && (got.MaxTokens == nil || *got.MaxTokens != *want.MaxTokens) {
+		return fmt.Errorf("max tokens = %s, want %d", formatOptional(got.MaxTokens), *want.MaxTokens)
+	}
+	if got.Stream != want.Stream {
+		return fmt.Errorf("stream = %v, want %v", got.Stream, want.Stream)
+	}
+	return nil
+}
+
+func formatOptional[T any](value *T) string {
+	if value == nil {
+		return "unset"
+	}
+	return fmt.Sprint(*value)
+}
+
+func responsesOutputText(resp *core.ResponsesResponse) string {
+	var sb strings.Builder
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, content := range item.Content {
+			if content.Type == "output_text" {
+				sb.WriteString(content.Text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func isSystemRole(role string) bool {
+	return role == "system" || role == "developer"
+}
+
+// joinPartTexts joins text parts the way text-only content is flattened.
+func joinPartTexts(parts []core.ContentPart) string {
+	texts := make([]string, len(parts))
+	for i, part := range parts {
+		texts[i] = part.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// shorterText keeps the first word of text.
+func shorterText(text string) string {
+	first, _, _ := strings.Cut(text, " ")
+	return first
+}
+
+var conversionWords = []string{"hello", "world", "naïve", "日本語", "{\"k\":1}", "line\nbreak", "tab\there", "🙂", "<b>", "42"}
+
+func randomText(r *rand.Rand) string {
+	words := make([]string, 1+r.Intn(4))
+	for i := range words {
+		words[i] = conversionWords[r.Intn(len(conversionWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+// randomContent returns a string or an array of one to three text parts.
+func randomContent(r *rand.Rand, partType string) any {
+	if r.Intn(2) == 0 {
+		return randomText(r)
+	}
+	parts := make([]core.ContentPart, 1+r.Intn(3))
+	for i := range parts {
+		parts[i] = core.ContentPart{Type: partType, Text: randomText(r)}
+	}
+	return parts
+}
+
+func randomRole(r *rand.Rand) string {
+	roles := []string{"system", "developer", "user", "user", "assistant", "assistant"}
+	return roles[r.Intn(len(roles))]
+}
+
+func randomTemperature(r *rand.Rand) *float64 {
+	if r.Intn(3) == 0 {
+		return nil
+	}
+	temperature := float64(r.Intn(21)) / 10
+	return &temperature
+}
+
+func randomMaxTokens(r *rand.Rand) *int {
+	if r.Intn(3) == 0 {
+		return nil
+	}
+	maxTokens := 1 + r.Intn(8192)
+	return &maxTokens
+}
@@ -0,0 +1,108 @@
+package testfixtures
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+// CallEveryEndpoint sends one request of each kind through provider for
+// model, streaming ones included, and closes the streams. Results and errors
+// are discarded: it serves tests that assert on what the provider sent
+// upstream, such as headers every request must carry.
+func CallEveryEndpoint(t testing.TB, provider core.Provider, model string) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, _ = provider.ChatCompletion(ctx, ChatRequest(ChatModel(model)))
+	if stream, err := provider.StreamChatCompletion(ctx, ChatRequest(ChatModel(model), ChatStream())); err == nil {
+		_ = stream.Close()
+	}
+	_, _ = provider.ListModels(ctx)
+	_, _ = provider.Embeddings(ctx, EmbeddingRequest(EmbeddingModel(model)))
+	_, _ = provider.Responses(ctx, ResponsesRequest(ResponsesModel(model)))
+	if stream, err := provider.StreamResponses(ctx, ResponsesRequest(ResponsesModel(model), ResponsesStream())); err == nil {
+		_ = stream.Close()
+	}
+}
+
+// RequireOnEveryRequest fails the test unless the server received at least
+// want requests, one of them streaming, and each carried the header and
+// query parameter.
+func RequireOnEveryRequest(t testing.TB, server *Server, want int, header, headerValue, param, paramValue string) {
+	t.Helper()
+	requests := server.Requests()
+	if len(requests) < want {
+		t.Fatalf("server received %d requests, want at least %d", len(requests), want)
+	}
+	streamed := false
+	for _, req := range requests {
+		if got := req.Header.Get(header); got != headerValue {
+			t.Errorf("%s %s: %s = %q, want %q", req.Method, req.Path, header, got, headerValue)
+		}
+		if got := req.Query.Get(param); got != paramValue {
+			t.Errorf("%s %s: query %s = %q, want %q", req.Method, req.Path, param, got, paramValue)
+		}
+		if streaming, ok := DecodeJSON[struct {
+			Stream bool `json:"stream"`
+		}](t, nonEmptyJSON(req.Body)); ok && streaming.Stream {
+			streamed = true
+		}
+	}
+	if !streamed {
+		t.Error("no streaming request reached the server")
+	}
+}
+
+// BaseURLCase is one base URL configuration of a provider: the fixture
+// server URL followed by Suffix, such as "/proxy/v1/", or the bare host when
+// Suffix is empty.
+type BaseURLCase struct {
+	Name   string
+	Suffix string
+	// ChatPath is the path the chat completion request must reach.
+	ChatPath string
+	// Prefix is the path every request must start with, models listing and
+	// streaming included.
+	Prefix string
+}
+
+// RequireBaseURLPaths creates a provider for each case with newProvider and
+// sends one request of each kind through it. It fails the test unless the
+// chat completion reached ChatPath and every request stayed under Prefix
+// without a doubled slash.
+func RequireBaseURLPaths(t *testing.T, newProvider func(baseURL string) (core.Provider, error), model string, cases ...BaseURLCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			server := NewServer(t, Route{Body: `{}`})
+			provider, err := newProvider(server.URL + tc.Suffix)
+			if err != nil {
+				t.Fatalf("create provider: %v", err)
+			}
+			CallEveryEndpoint(t, provider, model)
+
+			requests := server.Requests()
+			if len(requests) == 0 {
+				t.Fatal("server received no requests")
+			}
+			if requests[0].Path != tc.ChatPath {
+				t.Errorf("chat completion path = %q, want %q", requests[0].Path, tc.ChatPath)
+			}
+			for _, req := range requests {
+				if !strings.HasPrefix(req.Path, tc.Prefix) || strings.Contains(req.Path, "//") {
+					t.Errorf("%s %s: want a path under %q without doubled slashes", req.Method, req.Path, tc.Prefix)
+				}
+			}
+		})
+	}
+}
+
+func nonEmptyJSON(body []byte) []byte {
+	if len(body) == 0 {
+		return []byte(`{}`)
+	}
+	return body
+}
@@ -0,0 +1,120 @@
+// Package testfixtures provides shared test data for provider tests: request
+// builders with sensible defaults, canned upstream response bodies, and an
+// httptest server configured from a declarative list of routes. It is only
+// imported from _test.go files.
+package testfixtures
+
+import "gomodel/internal/core"
+
+// Defaults used by the request builders and canned bodies.
+const (
+	DefaultModel          = "test-model"
+	DefaultEmbeddingModel = "test-embedding-model"
+	DefaultPrompt         = "Hello"
+	DefaultReply          = "Hello! How can I help you today?"
+	// DefaultCreated is the Unix timestamp stamped on canned bodies.
+	DefaultCreated = 1677652288
+)
+
+// ChatOption overrides a default of ChatRequest.
+type ChatOption func(*core.ChatRequest)
+
+// ChatRequest returns a one-message chat request for DefaultModel saying
+// DefaultPrompt, with opts applied in order.
+func ChatRequest(opts ...ChatOption) *core.ChatRequest {
+	req := &core.ChatRequest{
+		Model:    DefaultModel,
+		Messages: []core.Message{{Role: "user", Content: DefaultPrompt}},
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// ChatModel sets the model.
+func ChatModel(model string) ChatOption {
+	return func(req *core.ChatRequest) { req.Model = model }
+}
+
+// ChatMessages replaces the messages.
+func ChatMessages(messages ...core.Message) ChatOption {
+	return func(req *core.ChatRequest) { req.Messages = messages }
+}
+
+// ChatStream marks the request as streaming.
+func ChatStream() ChatOption {
+	return func(req *core.ChatRequest) { req.Stream = true }
+}
+
+// ChatMaxTokens caps the completion length.
+func ChatMaxTokens(maxTokens int) ChatOption {
+	return func(req *core.ChatRequest) { req.MaxTokens = &maxTokens }
+}
+
+// ResponsesOption overrides a default of ResponsesRequest.
+type ResponsesOption func(*core.ResponsesRequest)
+
+// ResponsesRequest returns a Responses API request for DefaultModel with
+// DefaultPrompt as its string input, with opts applied in order.
+func ResponsesRequest(opts ...ResponsesOption) *core.ResponsesRequest {
+	req := &core.ResponsesRequest{
+		Model: DefaultModel,
+		Input: DefaultPrompt,
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// ResponsesModel sets the model.
+func ResponsesModel(model string) ResponsesOption {
+	return func(req *core.ResponsesRequest) { req.Model = model }
+}
+
+// ResponsesInput replaces the input, a string or a list of input elements.
+func ResponsesInput(input any) ResponsesOption {
+	return func(req *core.ResponsesRequest) { req.Input = input }
+}
+
+// ResponsesInstructions sets the instructions.
+func ResponsesInstructions(instructions string) ResponsesOption {
+	return func(req *core.ResponsesRequest) { req.Instructions = instructions }
+}
+
+// ResponsesStream marks the request as streaming.
+func ResponsesStream() ResponsesOption {
+	return func(req *core.ResponsesRequest) { req.Stream = true }
+}
+
+// EmbeddingOption overrides a default of EmbeddingRequest.
+type EmbeddingOption func(*core.EmbeddingRequest)
+
+// EmbeddingRequest returns an embeddings request for DefaultEmbeddingModel
+// with DefaultPrompt as its input, with opts applied in order.
+func EmbeddingRequest(opts ...EmbeddingOption) *core.EmbeddingRequest {
+	req := &core.EmbeddingRequest{
+		Model: DefaultEmbeddingModel,
+		Input: DefaultPrompt,
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// EmbeddingModel sets the model.
+func EmbeddingModel(model string) EmbeddingOption {
+	return func(req *core.EmbeddingRequest) { req.Model = model }
+}
+
+// EmbeddingInput replaces the input, a string or a list of strings.
+func EmbeddingInput(input any) EmbeddingOption {
+	return func(req *core.EmbeddingRequest) { req.Input = input }
+}
+
+// EmbeddingDimensions requests a vector size.
+func EmbeddingDimensions(dimensions int) EmbeddingOption {
+	return func(req *core.EmbeddingRequest) { req.Dimensions = &dimensions }
+}
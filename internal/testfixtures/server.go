@@ -0,0 +1,142 @@
+package testfixtures
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Route is one request a Server expects and the response it sends back.
+type Route struct {
+	// Method and Path select the route; empty matches any value.
+	Method string
+	Path   string
+	// Headers must be present with exactly these values.
+	Headers map[string]string
+	// HeaderPrefixes must be present and start with these values, such as
+	// "Bearer " for Authorization.
+	HeaderPrefixes map[string]string
+	// Check inspects the request and its body. It runs on the server
+	// goroutine, so it must report failures with t.Error, not t.Fatal.
+	Check func(t testing.TB, r *http.Request, body []byte)
+
+	// Status defaults to 200.
+	Status          int
+	Body            string
+	ResponseHeaders map[string]string
+	// Hang blocks until the client gives up instead of responding, for
+	// cancellation tests.
+	Hang bool
+}
+
+// RecordedRequest is a request a Server received.
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Server is an httptest server that answers from a list of routes and
+// fails the test on requests that match none of them or miss an expected
+// header. It is closed when the test ends.
+type Server struct {
+	*httptest.Server
+
+	t      testing.TB
+	routes []Route
+
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// NewServer starts a Server. Requests are matched against routes in order.
+func NewServer(t testing.TB, routes ...Route) *Server {
+	t.Helper()
+	s := &Server{t: t, routes: routes}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.t.Errorf("read request body: %v", err)
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+	s.mu.Unlock()
+
+	route, ok := s.match(r)
+	if !ok {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(OpenAIError("no fixture route for " + r.Method + " " + r.URL.Path)))
+		return
+	}
+	for name, want := range route.Headers {
+		if got := r.Header.Get(name); got != want {
+			s.t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	for name, prefix := range route.HeaderPrefixes {
+		if got := r.Header.Get(name); !strings.HasPrefix(got, prefix) {
+			s.t.Errorf("%s = %q, want prefix %q", name, got, prefix)
+		}
+	}
+	if route.Check != nil {
+		route.Check(s.t, r, body)
+	}
+
+	if route.Hang {
+		<-r.Context().Done()
+		return
+	}
+	for name, value := range route.ResponseHeaders {
+		w.Header().Set(name, value)
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(route.Body))
+}
+
+func (s *Server) match(r *http.Request) (Route, bool) {
+	for _, route := range s.routes {
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+		if route.Path != "" && route.Path != r.URL.Path {
+			continue
+		}
+		return route, true
+	}
+	return Route{}, false
+}
+
+// DecodeJSON unmarshals a request body inside a Route.Check, reporting a
+// failure with t.Error. ok is false when the body is not valid JSON for T.
+func DecodeJSON[T any](t testing.TB, body []byte) (value T, ok bool) {
+	t.Helper()
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Errorf("unmarshal request body: %v", err)
+		return value, false
+	}
+	return value, true
+}
@@ -0,0 +1,65 @@
+package testfixtures
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gomodel/internal/core"
+)
+
+func TestChatCompletion_DecodesWithDefaults(t *testing.T) {
+	var resp core.ChatResponse
+	if err := json.Unmarshal([]byte(ChatCompletion{}.JSON()), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Model != DefaultModel || resp.Choices[0].Message.Content != DefaultReply {
+		t.Errorf("got model %q content %q, want defaults", resp.Model, resp.Choices[0].Message.Content)
+	}
+	if resp.Usage.TotalTokens != 30 {
+		t.Errorf("TotalTokens = %d, want 30", resp.Usage.TotalTokens)
+	}
+}
+
+func TestChatCompletionChunks_EndsWithDone(t *testing.T) {
+	stream := ChatCompletionChunks("m", "a", "b")
+	if got := strings.Count(stream, "data: {"); got != 2 {
+		t.Errorf("chunk count = %d, want 2", got)
+	}
+	if !strings.HasSuffix(stream, "data: [DONE]\n") {
+		t.Errorf("stream does not end with [DONE]: %q", stream)
+	}
+}
+
+func TestServer_MatchesRoutesAndRecordsRequests(t *testing.T) {
+	server := NewServer(t,
+		Route{Method: http.MethodGet, Path: "/models", Body: ModelsList("test", "a")},
+		Route{Path: "/chat/completions", Status: http.StatusTeapot, ResponseHeaders: map[string]string{"X-Test": "1"}},
+	)
+
+	resp, err := http.Get(server.URL + "/models")
+	if err != nil {
+		t.Fatalf("GET /models: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"id":"a"`) {
+		t.Errorf("GET /models = %d %s", resp.StatusCode, body)
+	}
+
+	resp, err = http.Post(server.URL+"/chat/completions", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST /chat/completions: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot || resp.Header.Get("X-Test") != "1" {
+		t.Errorf("POST /chat/completions = %d, X-Test %q", resp.StatusCode, resp.Header.Get("X-Test"))
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 || requests[1].Method != http.MethodPost || string(requests[1].Body) != `{}` {
+		t.Errorf("Requests() = %+v", requests)
+	}
+}
@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the built-in tools.
+const (
+	CurrentTimeTool = "current_time"
+	CalculatorTool  = "calculator"
+)
+
+func init() {
+	Register(CurrentTimeTool, currentTime{now: time.Now})
+	Register(CalculatorTool, calculator{})
+}
+
+// currentTime reports the current date and time, in UTC or an IANA time zone.
+type currentTime struct {
+	now func() time.Time
+}
+
+func (currentTime) Definition() Definition {
+	return Definition{
+		Description: "Returns the current date and time. Use it whenever the answer depends on today's date or the time of day.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"timezone": map[string]any{
+					"type":        "string",
+					"description": "IANA time zone name such as Europe/Warsaw. Defaults to UTC.",
+				},
+			},
+		},
+	}
+}
+
+func (t currentTime) Call(_ context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Timezone string `json:"timezone"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	location := time.UTC
+	if name := strings.TrimSpace(args.Timezone); name != "" {
+		loaded, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("unknown time zone %q", name)
+		}
+		location = loaded
+	}
+	now := t.now().In(location)
+	encoded, err := json.Marshal(map[string]string{
+		"time":     now.Format(time.RFC3339),
+		"weekday":  now.Weekday().String(),
+		"timezone": location.String(),
+	})
+	return string(encoded), err
+}
+
+// calculator evaluates arithmetic expressions with + - * / ^, parentheses
+// and unary minus, so the model does not have to do arithmetic itself.
+type calculator struct{}
+
+// maxExpressionLength bounds the expressions the calculator parses.
+const maxExpressionLength = 1024
+
+func (calculator) Definition() Definition {
+	return Definition{
+		Description: "Evaluates an arithmetic expression with + - * / ^ and parentheses and returns the exact result.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"expression": map[string]any{
+					"type":        "string",
+					"description": "The expression to evaluate, for example (12.5 + 7) * 3.",
+				},
+			},
+			"required": []string{"expression"},
+		},
+	}
+}
+
+func (calculator) Call(_ context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	expression := strings.TrimSpace(args.Expression)
+	if expression == "" {
+		return "", errors.New("expression is required")
+	}
+	if len(expression) > maxExpressionLength {
+		return "", fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+	value, err := evaluate(expression)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(map[string]string{
+		"expression": expression,
+		"result":     strconv.FormatFloat(value, 'g', -1, 64),
+	})
+	return string(encoded), err
+}
+
+// evaluate parses and computes an arithmetic expression by recursive
+// descent: sums of products of powers of signed factors.
+func evaluate(expression string) (float64, error) {
+	p := &exprParser{input: expression}
+	value, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, errors.New("result is not a finite number")
+	}
+	return value, nil
+}
+
+type exprParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+// maxExpressionDepth bounds parenthesis and sign nesting.
+const maxExpressionDepth = 64
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *exprParser) sum() (float64, error) {
+	value, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '+', '-':
+			op := p.input[p.pos]
+			p.pos++
+			right, err := p.product()
+			if err != nil {
+				return 0, err
+			}
+			if op == '+' {
+				value += right
+			} else {
+				value -= right
+			}
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *exprParser) product() (float64, error) {
+	value, err := p.power()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch p.peek() {
+		case '*', '/':
+			op := p.input[p.pos]
+			p.pos++
+			right, err := p.power()
+			if err != nil {
+				return 0, err
+			}
+			if op == '*' {
+				value *= right
+			} else {
+				if right == 0 {
+					return 0, errors.New("division by zero")
+				}
+				value /= right
+			}
+		default:
+			return value, nil
+		}
+	}
+}
+
+// power is right-associative: 2^3^2 is 2^9.
+func (p *exprParser) power() (float64, error) {
+	base, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.nested(p.power)
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *exprParser) unary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err := p.nested(p.unary)
+		return -value, err
+	case '+':
+		p.pos++
+		return p.nested(p.unary)
+	}
+	return p.factor()
+}
+
+func (p *exprParser) factor() (float64, error) {
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		value, err := p.nested(p.sum)
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ) at position %d", p.pos+1)
+		}
+		p.pos++
+		return value, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] == '.' || (p.input[p.pos] >= '0' && p.input[p.pos] <= '9')) {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return value, nil
+	case c == 0:
+		return 0, errors.New("unexpected end of expression")
+	default:
+		return 0, fmt.Errorf("unexpected %q at position %d", c, p.pos+1)
+	}
+}
+
+// nested runs parse one level deeper, failing past maxExpressionDepth so a
+// pathological expression cannot exhaust the stack.
+func (p *exprParser) nested(parse func() (float64, error)) (float64, error) {
+	if p.depth >= maxExpressionDepth {
+		return 0, errors.New("expression is nested too deeply")
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	return parse()
+}
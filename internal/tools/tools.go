@@ -0,0 +1,151 @@
+// Package tools holds the server-side tools the gateway runs for a model.
+//
+// A tool is a Go handler registered by name with Register, usually from an
+// init function. Registered tools are offered to a model only where the
+// server_tools config grants them and the request opts in; when the model
+// calls one, the gateway runs the handler and sends its output back to the
+// model in the next turn of the conversation.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Handler runs one server-side tool.
+type Handler interface {
+	// Definition describes the tool to the model.
+	Definition() Definition
+	// Call runs the tool with the JSON arguments the model sent and returns
+	// the text handed back to the model. Handlers should return when ctx is
+	// done; the gateway stops waiting for them either way.
+	Call(ctx context.Context, arguments json.RawMessage) (string, error)
+}
+
+// Definition is the description and JSON schema of a tool's arguments, sent
+// to the model with the request.
+type Definition struct {
+	Description string
+	Parameters  map[string]any
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Handler{}
+)
+
+// Register makes a tool available under name. Like database/sql drivers it
+// panics when name is empty, the handler is nil or the name is taken, since
+// those are programming errors.
+func Register(name string, handler Handler) {
+	if name == "" {
+		panic("tools: Register with an empty name")
+	}
+	if handler == nil {
+		panic("tools: Register of a nil handler for " + name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, taken := registry[name]; taken {
+		panic("tools: Register called twice for " + name)
+	}
+	registry[name] = handler
+}
+
+// Lookup returns the handler registered under name.
+func Lookup(name string) (Handler, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	handler, ok := registry[name]
+	return handler, ok
+}
+
+// Names returns the registered tool names, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ErrTimeout is the error of a tool call that did not return in time.
+var ErrTimeout = errors.New("tool call timed out")
+
+// Result is the outcome of one tool call. Output is what the model sees:
+// the handler's text, cut to the output limit, or an error description.
+type Result struct {
+	Output    string
+	Truncated bool
+	Err       error
+	Duration  time.Duration
+}
+
+// Run calls handler with a timeout and cuts its output to maxBytes, on a
+// UTF-8 boundary. A handler that outlives the timeout is abandoned; its
+// context is cancelled so it can stop. Errors are reported to the model as
+// a JSON object with an "error" field.
+func Run(ctx context.Context, handler Handler, arguments string, timeout time.Duration, maxBytes int) Result {
+	start := time.Now()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if arguments == "" {
+		arguments = "{}"
+	}
+
+	type outcome struct {
+		output string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- outcome{err: fmt.Errorf("tool panicked: %v", recovered)}
+			}
+		}()
+		output, err := handler.Call(ctx, json.RawMessage(arguments))
+		done <- outcome{output: output, err: err}
+	}()
+
+	var result Result
+	select {
+	case out := <-done:
+		result.Output, result.Err = out.output, out.err
+	case <-ctx.Done():
+		result.Err = ctx.Err()
+		if errors.Is(result.Err, context.DeadlineExceeded) {
+			result.Err = ErrTimeout
+		}
+	}
+	result.Duration = time.Since(start)
+	if result.Err != nil {
+		encoded, _ := json.Marshal(map[string]string{"error": result.Err.Error()}) //nolint:errcheck
+		result.Output = string(encoded)
+	}
+	if maxBytes > 0 && len(result.Output) > maxBytes {
+		result.Output, result.Truncated = truncate(result.Output, maxBytes), true
+	}
+	return result
+}
+
+// truncate cuts text to at most maxBytes without splitting a UTF-8 sequence.
+func truncate(text string, maxBytes int) string {
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type handlerFunc func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+func (handlerFunc) Definition() Definition { return Definition{} }
+
+func (f handlerFunc) Call(ctx context.Context, arguments json.RawMessage) (string, error) {
+	return f(ctx, arguments)
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       handlerFunc
+		maxBytes      int
+		wantOutput    string
+		wantTruncated bool
+		wantErr       error
+	}{
+		{
+			name:       "output",
+			handler:    func(context.Context, json.RawMessage) (string, error) { return "ok", nil },
+			wantOutput: "ok",
+		},
+		{
+			name:          "truncated on a rune boundary",
+			handler:       func(context.Context, json.RawMessage) (string, error) { return "abcżółw", nil },
+			maxBytes:      4,
+			wantOutput:    "abc",
+			wantTruncated: true,
+		},
+		{
+			name:       "error",
+			handler:    func(context.Context, json.RawMessage) (string, error) { return "", errors.New("boom") },
+			wantOutput: `{"error":"boom"}`,
+		},
+		{
+			name:       "panic",
+			handler:    func(context.Context, json.RawMessage) (string, error) { panic("bad tool") },
+			wantOutput: `{"error":"tool panicked: bad tool"}`,
+		},
+		{
+			name: "timeout",
+			handler: func(ctx context.Context, _ json.RawMessage) (string, error) {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return "late", nil
+			},
+			wantOutput: `{"error":"tool call timed out"}`,
+			wantErr:    ErrTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Run(context.Background(), tt.handler, "", 20*time.Millisecond, tt.maxBytes)
+			if result.Output != tt.wantOutput || result.Truncated != tt.wantTruncated {
+				t.Fatalf("Run() = %q (truncated %v), want %q (truncated %v)", result.Output, result.Truncated, tt.wantOutput, tt.wantTruncated)
+			}
+			if tt.wantErr != nil && !errors.Is(result.Err, tt.wantErr) {
+				t.Fatalf("Run() error = %v, want %v", result.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegister_RejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a taken name did not panic")
+		}
+	}()
+	Register(CalculatorTool, calculator{})
+}
+
+func TestCalculator(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+		wantErr    string
+	}{
+		{expression: "6*7", want: "42"},
+		{expression: "(12.5 + 7) * 3", want: "58.5"},
+		{expression: "2^3^2", want: "512"},
+		{expression: "-2 - -3", want: "1"},
+		{expression: "10 / 4", want: "2.5"},
+		{expression: "1/0", wantErr: "division by zero"},
+		{expression: "2 +", wantErr: "unexpected end of expression"},
+		{expression: "(1", wantErr: "missing )"},
+		{expression: "2 x 3", wantErr: `unexpected 'x'`},
+		{expression: strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), wantErr: "nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			arguments, _ := json.Marshal(map[string]string{"expression": tt.expression})
+			output, err := calculator{}.Call(context.Background(), arguments)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Call() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			var result struct {
+				Result string `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(output), &result); err != nil || result.Result != tt.want {
+				t.Fatalf("Call() = %s, want result %s", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)
+	tool := currentTime{now: func() time.Time { return now }}
+
+	output, err := tool.Call(context.Background(), json.RawMessage(`{"timezone":"Europe/Warsaw"}`))
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if want := `{"time":"2026-03-02T11:30:00+01:00","timezone":"Europe/Warsaw","weekday":"Monday"}`; output != want {
+		t.Fatalf("Call() = %s, want %s", output, want)
+	}
+	if _, err := tool.Call(context.Background(), json.RawMessage(`{"timezone":"Mars/Olympus"}`)); err == nil {
+		t.Fatal("unknown time zone was accepted")
+	}
+}
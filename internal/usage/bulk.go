@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"gomodel/internal/storage"
+)
+
+// MaxBulkBatchSize caps the rows one bulk operation batch touches. SQLite
+// binds every ID of a reattributed batch as a parameter.
+const MaxBulkBatchSize = 900
+
+// BulkFilter selects the usage rows a bulk operation touches. Cached and
+// uncached rows are both included.
+type BulkFilter struct {
+	StartDate time.Time // Inclusive start (day precision, UTC); zero for no bound
+	EndDate   time.Time // Inclusive end (day precision, UTC); zero for no bound
+	Model     string    // exact model filter (optional)
+	Provider  string    // provider name or provider type filter (optional)
+	AuthKeyID string    // exact managed auth key filter (optional)
+}
+
+func (f BulkFilter) queryParams() UsageQueryParams {
+	return UsageQueryParams{
+		StartDate: f.StartDate,
+		EndDate:   f.EndDate,
+		CacheMode: CacheModeAll,
+		AuthKeyID: f.AuthKeyID,
+	}
+}
+
+// CostRow is a stored usage row with the fields cost calculation reads and
+// the costs it currently holds.
+type CostRow struct {
+	ID           string
+	Model        string
+	Provider     string
+	Endpoint     string
+	InputTokens  int
+	OutputTokens int
+	RawData      map[string]any
+	InputCost    *float64
+	OutputCost   *float64
+	TotalCost    *float64
+	Caveat       string
+}
+
+// BulkStore runs the batched corrections of the admin usage jobs. Batches are
+// walked in ID order, so a job that stopped can resume after the last ID it
+// processed.
+type BulkStore interface {
+	// CountRows returns the number of rows matching filter.
+	CountRows(ctx context.Context, filter BulkFilter) (int64, error)
+
+	// ListCostRows returns up to limit rows matching filter with an ID after
+	// afterID, in ID order.
+	ListCostRows(ctx context.Context, filter BulkFilter, afterID string, limit int) ([]CostRow, error)
+
+	// UpdateCosts stores the costs and caveats of rows.
+	UpdateCosts(ctx context.Context, rows []CostRow) error
+
+	// Reattribute moves up to limit rows matching filter with an ID after
+	// afterID to toAuthKeyID. filter.AuthKeyID must be set. It returns the
+	// number of rows moved and the last ID it looked at, which is empty when
+	// no rows were left.
+	Reattribute(ctx context.Context, filter BulkFilter, toAuthKeyID, afterID string, limit int) (int, string, error)
+}
+
+// NewBulkStore creates a BulkStore for the storage backend.
+// Returns nil if the storage is nil (usage data not available).
+func NewBulkStore(store storage.Storage) (BulkStore, error) {
+	if store == nil {
+		return nil, nil
+	}
+
+	return storage.ResolveBackend[BulkStore](
+		store,
+		func(db *sql.DB) (BulkStore, error) { return NewSQLiteBulkStore(db) },
+		func(pool *pgxpool.Pool) (BulkStore, error) { return NewPostgreSQLBulkStore(pool) },
+		func(db *mongo.Database) (BulkStore, error) { return NewMongoDBBulkStore(db) },
+	)
+}
+
+// RecomputeCost prices row again with the current pricing of its model. It
+// reports whether the costs or caveat changed. Rows whose model has no
+// pricing get no costs, as when they were first recorded.
+func RecomputeCost(row CostRow, resolver PricingResolver) (CostRow, bool) {
+	updated := row
+	updated.InputCost, updated.OutputCost, updated.TotalCost, updated.Caveat = nil, nil, nil, ""
+	if resolver != nil {
+		if pricing := resolver.ResolvePricing(row.Model, row.Provider); pricing != nil {
+			result := CalculateGranularCost(row.InputTokens, row.OutputTokens, row.RawData, row.Provider, pricingForEndpoint(pricing, row.Endpoint))
+			updated.InputCost, updated.OutputCost, updated.TotalCost, updated.Caveat = result.InputCost, result.OutputCost, result.TotalCost, result.Caveat
+		}
+	}
+	changed := !sameCost(row.InputCost, updated.InputCost) ||
+		!sameCost(row.OutputCost, updated.OutputCost) ||
+		!sameCost(row.TotalCost, updated.TotalCost) ||
+		row.Caveat != updated.Caveat
+	return updated, changed
+}
+
+func sameCost(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,179 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoDBBulkStore implements BulkStore for MongoDB.
+type MongoDBBulkStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBBulkStore creates a new MongoDB usage bulk store.
+func NewMongoDBBulkStore(database *mongo.Database) (*MongoDBBulkStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	return &MongoDBBulkStore{collection: database.Collection("usage")}, nil
+}
+
+func mongoBulkFilter(filter BulkFilter, afterID string) (bson.D, error) {
+	matchFilters, err := mongoUsageLogMatchFilters(UsageLogParams{
+		UsageQueryParams: filter.queryParams(),
+		Model:            filter.Model,
+		Provider:         filter.Provider,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if afterID != "" {
+		matchFilters = mongoAndFilters(matchFilters, bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: afterID}}}})
+	}
+	if matchFilters == nil {
+		matchFilters = bson.D{}
+	}
+	return matchFilters, nil
+}
+
+// CountRows returns the number of rows matching filter.
+func (s *MongoDBBulkStore) CountRows(ctx context.Context, filter BulkFilter) (int64, error) {
+	match, err := mongoBulkFilter(filter, "")
+	if err != nil {
+		return 0, err
+	}
+	count, err := s.collection.CountDocuments(ctx, match)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count usage rows: %w", err)
+	}
+	return count, nil
+}
+
+// ListCostRows returns the next batch of rows to price again.
+func (s *MongoDBBulkStore) ListCostRows(ctx context.Context, filter BulkFilter, afterID string, limit int) ([]CostRow, error) {
+	match, err := mongoBulkFilter(filter, afterID)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := s.collection.Find(ctx, match, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage cost rows: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]CostRow, 0, limit)
+	for cursor.Next(ctx) {
+		var entry UsageEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode usage cost row: %w", err)
+		}
+		result = append(result, CostRow{
+			ID:           entry.ID,
+			Model:        entry.Model,
+			Provider:     entry.Provider,
+			Endpoint:     entry.Endpoint,
+			InputTokens:  entry.InputTokens,
+			OutputTokens: entry.OutputTokens,
+			RawData:      entry.RawData,
+			InputCost:    entry.InputCost,
+			OutputCost:   entry.OutputCost,
+			TotalCost:    entry.TotalCost,
+			Caveat:       entry.CostsCalculationCaveat,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage cost rows: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateCosts stores the costs of rows in one unordered bulk write. Unknown
+// costs are removed, as the store omits them on insert.
+func (s *MongoDBBulkStore) UpdateCosts(ctx context.Context, rows []CostRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(rows))
+	for _, row := range rows {
+		set := bson.D{}
+		unset := bson.D{}
+		for _, field := range []struct {
+			name  string
+			value *float64
+		}{
+			{"input_cost", row.InputCost},
+			{"output_cost", row.OutputCost},
+			{"total_cost", row.TotalCost},
+		} {
+			if field.value != nil {
+				set = append(set, bson.E{Key: field.name, Value: *field.value})
+			} else {
+				unset = append(unset, bson.E{Key: field.name, Value: ""})
+			}
+		}
+		if row.Caveat != "" {
+			set = append(set, bson.E{Key: "costs_calculation_caveat", Value: row.Caveat})
+		} else {
+			unset = append(unset, bson.E{Key: "costs_calculation_caveat", Value: ""})
+		}
+		update := bson.D{}
+		if len(set) > 0 {
+			update = append(update, bson.E{Key: "$set", Value: set})
+		}
+		if len(unset) > 0 {
+			update = append(update, bson.E{Key: "$unset", Value: unset})
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.D{{Key: "_id", Value: row.ID}}).SetUpdate(update))
+	}
+	if _, err := s.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to update usage costs: %w", err)
+	}
+	return nil
+}
+
+// Reattribute moves the next batch of rows to toAuthKeyID.
+func (s *MongoDBBulkStore) Reattribute(ctx context.Context, filter BulkFilter, toAuthKeyID, afterID string, limit int) (int, string, error) {
+	if filter.AuthKeyID == "" {
+		return 0, "", fmt.Errorf("source auth key is required")
+	}
+	match, err := mongoBulkFilter(filter, afterID)
+	if err != nil {
+		return 0, "", err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, match, opts)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to query usage rows to reattribute: %w", err)
+	}
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, "", fmt.Errorf("failed to read usage row ids: %w", err)
+	}
+	if len(docs) == 0 {
+		return 0, "", nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	// The auth key condition skips rows that changed since they were listed.
+	result, err := s.collection.UpdateMany(ctx,
+		bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}, {Key: "auth_key_id", Value: filter.AuthKeyID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "auth_key_id", Value: toAuthKeyID}}}},
+	)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to reattribute usage rows: %w", err)
+	}
+	return int(result.ModifiedCount), docs[len(docs)-1].ID, nil
+}
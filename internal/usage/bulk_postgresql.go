@@ -0,0 +1,159 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLBulkStore implements BulkStore for PostgreSQL databases.
+type PostgreSQLBulkStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLBulkStore creates a new PostgreSQL usage bulk store.
+func NewPostgreSQLBulkStore(pool *pgxpool.Pool) (*PostgreSQLBulkStore, error) {
+	if pool == nil {
+		return nil, fmt.Errorf("connection pool is required")
+	}
+	return &PostgreSQLBulkStore{pool: pool}, nil
+}
+
+func pgBulkConditions(filter BulkFilter, afterID string) ([]string, []any, int, error) {
+	conditions, args, argIdx, err := pgUsageConditions(filter.queryParams(), 1)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if filter.Model != "" {
+		conditions = append(conditions, fmt.Sprintf("model = $%d", argIdx))
+		args = append(args, filter.Model)
+		argIdx++
+	}
+	if filter.Provider != "" {
+		conditions = append(conditions, fmt.Sprintf("(provider = $%d OR provider_name = $%d)", argIdx, argIdx+1))
+		args = append(args, filter.Provider, filter.Provider)
+		argIdx += 2
+	}
+	if afterID != "" {
+		conditions = append(conditions, fmt.Sprintf("id > $%d", argIdx))
+		args = append(args, afterID)
+		argIdx++
+	}
+	return conditions, args, argIdx, nil
+}
+
+// CountRows returns the number of rows matching filter.
+func (s *PostgreSQLBulkStore) CountRows(ctx context.Context, filter BulkFilter) (int64, error) {
+	conditions, args, _, err := pgBulkConditions(filter, "")
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "usage"`+buildWhereClause(conditions), args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count usage rows: %w", err)
+	}
+	return count, nil
+}
+
+// ListCostRows returns the next batch of rows to price again.
+func (s *PostgreSQLBulkStore) ListCostRows(ctx context.Context, filter BulkFilter, afterID string, limit int) ([]CostRow, error) {
+	conditions, args, argIdx, err := pgBulkConditions(filter, afterID)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT id, model, provider, endpoint, input_tokens, output_tokens, raw_data, input_cost, output_cost, total_cost, COALESCE(costs_calculation_caveat, '')
+		FROM "usage"` + buildWhereClause(conditions) + fmt.Sprintf(` ORDER BY id LIMIT $%d`, argIdx)
+
+	rows, err := s.pool.Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage cost rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]CostRow, 0, limit)
+	for rows.Next() {
+		var row CostRow
+		var rawDataJSON []byte
+		if err := rows.Scan(&row.ID, &row.Model, &row.Provider, &row.Endpoint, &row.InputTokens, &row.OutputTokens, &rawDataJSON,
+			&row.InputCost, &row.OutputCost, &row.TotalCost, &row.Caveat); err != nil {
+			return nil, fmt.Errorf("failed to scan usage cost row: %w", err)
+		}
+		if len(rawDataJSON) > 0 {
+			if err := json.Unmarshal(rawDataJSON, &row.RawData); err != nil {
+				usageLogger.Warn("failed to unmarshal raw_data JSON", "id", row.ID, "error", err)
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage cost rows: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateCosts stores the costs of rows in one transaction.
+func (s *PostgreSQLBulkStore) UpdateCosts(ctx context.Context, rows []CostRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, row := range rows {
+		batch.Queue(`UPDATE "usage" SET input_cost = $1, output_cost = $2, total_cost = $3, costs_calculation_caveat = $4 WHERE id = $5`,
+			row.InputCost, row.OutputCost, row.TotalCost, row.Caveat, row.ID)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin usage cost update: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to update usage costs: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit usage cost update: %w", err)
+	}
+	return nil
+}
+
+// Reattribute moves the next batch of rows to toAuthKeyID.
+func (s *PostgreSQLBulkStore) Reattribute(ctx context.Context, filter BulkFilter, toAuthKeyID, afterID string, limit int) (int, string, error) {
+	if filter.AuthKeyID == "" {
+		return 0, "", fmt.Errorf("source auth key is required")
+	}
+	conditions, args, argIdx, err := pgBulkConditions(filter, afterID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT id FROM "usage"`+buildWhereClause(conditions)+fmt.Sprintf(` ORDER BY id LIMIT $%d`, argIdx), append(args, limit)...)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to query usage rows to reattribute: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read usage row ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, "", nil
+	}
+
+	updateArgs := make([]any, 0, len(ids)+2)
+	updateArgs = append(updateArgs, toAuthKeyID, filter.AuthKeyID)
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+3)
+		updateArgs = append(updateArgs, id)
+	}
+	// The auth key condition skips rows that changed since they were listed.
+	cmd, err := s.pool.Exec(ctx, `UPDATE "usage" SET auth_key_id = $1 WHERE auth_key_id = $2 AND id IN (`+strings.Join(placeholders, ", ")+`)`, updateArgs...)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to reattribute usage rows: %w", err)
+	}
+	return int(cmd.RowsAffected()), ids[len(ids)-1], nil
+}
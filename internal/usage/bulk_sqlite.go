@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SQLiteBulkStore implements BulkStore for SQLite databases.
+type SQLiteBulkStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteBulkStore creates a new SQLite usage bulk store.
+func NewSQLiteBulkStore(db *sql.DB) (*SQLiteBulkStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is required")
+	}
+	return &SQLiteBulkStore{db: db}, nil
+}
+
+func sqliteBulkConditions(filter BulkFilter) ([]string, []any, error) {
+	conditions, args, err := sqliteUsageConditions(filter.queryParams())
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter.Model != "" {
+		conditions = append(conditions, "model = ?")
+		args = append(args, filter.Model)
+	}
+	if filter.Provider != "" {
+		conditions = append(conditions, "(provider = ? OR provider_name = ?)")
+		args = append(args, filter.Provider, filter.Provider)
+	}
+	return conditions, args, nil
+}
+
+// CountRows returns the number of rows matching filter.
+func (s *SQLiteBulkStore) CountRows(ctx context.Context, filter BulkFilter) (int64, error) {
+	conditions, args, err := sqliteBulkConditions(filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM usage"+buildWhereClause(conditions), args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count usage rows: %w", err)
+	}
+	return count, nil
+}
+
+// ListCostRows returns the next batch of rows to price again.
+func (s *SQLiteBulkStore) ListCostRows(ctx context.Context, filter BulkFilter, afterID string, limit int) ([]CostRow, error) {
+	conditions, args, err := sqliteBulkConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	if afterID != "" {
+		conditions = append(conditions, "id > ?")
+		args = append(args, afterID)
+	}
+	query := `SELECT id, model, provider, endpoint, input_tokens, output_tokens, raw_data, input_cost, output_cost, total_cost, COALESCE(costs_calculation_caveat, '')
+		FROM usage` + buildWhereClause(conditions) + ` ORDER BY id LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage cost rows: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]CostRow, 0, limit)
+	for rows.Next() {
+		var row CostRow
+		var rawDataJSON *string
+		if err := rows.Scan(&row.ID, &row.Model, &row.Provider, &row.Endpoint, &row.InputTokens, &row.OutputTokens, &rawDataJSON,
+			&row.InputCost, &row.OutputCost, &row.TotalCost, &row.Caveat); err != nil {
+			return nil, fmt.Errorf("failed to scan usage cost row: %w", err)
+		}
+		if rawDataJSON != nil && *rawDataJSON != "" {
+			if err := json.Unmarshal([]byte(*rawDataJSON), &row.RawData); err != nil {
+				usageLogger.Warn("failed to unmarshal raw_data JSON", "id", row.ID, "error", err)
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage cost rows: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateCosts stores the costs of rows in one transaction.
+func (s *SQLiteBulkStore) UpdateCosts(ctx context.Context, rows []CostRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin usage cost update: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, "UPDATE usage SET input_cost = ?, output_cost = ?, total_cost = ?, costs_calculation_caveat = ? WHERE id = ?")
+	if err != nil {
+		return fmt.Errorf("failed to prepare usage cost update: %w", err)
+	}
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row.InputCost, row.OutputCost, row.TotalCost, row.Caveat, row.ID); err != nil {
+			return fmt.Errorf("failed to update usage costs of %s: %w", row.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage cost update: %w", err)
+	}
+	return nil
+}
+
+// Reattribute moves the next batch of rows to toAuthKeyID.
+func (s *SQLiteBulkStore) Reattribute(ctx context.Context, filter BulkFilter, toAuthKeyID, afterID string, limit int) (int, string, error) {
+	if filter.AuthKeyID == "" {
+		return 0, "", fmt.Errorf("source auth key is required")
+	}
+	conditions, args, err := sqliteBulkConditions(filter)
+	if err != nil {
+		return 0, "", err
+	}
+	if afterID != "" {
+		conditions = append(conditions, "id > ?")
+		args = append(args, afterID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM usage"+buildWhereClause(conditions)+" ORDER BY id LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to query usage rows to reattribute: %w", err)
+	}
+	var ids []any
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, "", fmt.Errorf("failed to scan usage row id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", fmt.Errorf("error iterating usage row ids: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, "", nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	// The auth key condition skips rows that changed since they were listed.
+	result, err := s.db.ExecContext(ctx, "UPDATE usage SET auth_key_id = ? WHERE auth_key_id = ? AND id IN ("+placeholders+")",
+		append([]any{toAuthKeyID, filter.AuthKeyID}, ids...)...)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to reattribute usage rows: %w", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read reattributed row count: %w", err)
+	}
+	return int(moved), ids[len(ids)-1].(string), nil
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"gomodel/internal/contentstats"
+	"gomodel/internal/core"
+)
+
+// ContentFunc writes the assistant output of a response to an analyzer. It
+// is only called when content analysis is on.
+type ContentFunc func(*contentstats.Analyzer)
+
+// ChatResponseContent writes the text of the first choice of resp, the
+// choice streams are analyzed from as well.
+func ChatResponseContent(resp *core.ChatResponse) ContentFunc {
+	return func(analyzer *contentstats.Analyzer) {
+		if resp == nil {
+			return
+		}
+		for _, choice := range resp.Choices {
+			if choice.Index == 0 {
+				analyzer.Write(core.ExtractTextContent(choice.Message.Content))
+				return
+			}
+		}
+	}
+}
+
+// ResponsesContent writes the output_text parts of the message items of resp.
+func ResponsesContent(resp *core.ResponsesResponse) ContentFunc {
+	return func(analyzer *contentstats.Analyzer) {
+		if resp == nil {
+			return
+		}
+		for _, item := range resp.Output {
+			if item.Type != "message" {
+				continue
+			}
+			for _, part := range item.Content {
+				if part.Type == "output_text" {
+					analyzer.Write(part.Text)
+				}
+			}
+		}
+	}
+}
+
+// AnalyzeContent sets the content fields of entry from the output content
+// writes, when cfg enables content analysis.
+func AnalyzeContent(entry *UsageEntry, cfg Config, content ContentFunc) {
+	if entry == nil || content == nil || !cfg.ContentAnalysis {
+		return
+	}
+	analyzer := contentstats.NewAnalyzer(cfg.ContentSampleChars)
+	content(analyzer)
+	entry.setContentStats(analyzer.Stats())
+}
+
+func (e *UsageEntry) setContentStats(stats contentstats.Stats) {
+	e.ContentLanguage = stats.Language
+	e.ContentChars = stats.Chars
+	e.ContentWords = stats.Words
+}
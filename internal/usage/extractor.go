@@ -70,6 +70,7 @@ func ExtractFromChatResponse(resp *core.ChatResponse, requestID, provider, endpo
 		ProviderID:   resp.ID,
 		Timestamp:    time.Now().UTC(),
 		Model:        resp.Model,
+		ServedModel:  resp.Model,
 		Provider:     provider,
 		Endpoint:     endpoint,
 		InputTokens:  resp.Usage.PromptTokens,
@@ -123,13 +124,14 @@ func ExtractFromResponsesResponse(resp *core.ResponsesResponse, requestID, provi
 	}
 
 	entry := &UsageEntry{
-		ID:         uuid.New().String(),
-		RequestID:  requestID,
-		ProviderID: resp.ID,
-		Timestamp:  time.Now().UTC(),
-		Model:      resp.Model,
-		Provider:   provider,
-		Endpoint:   endpoint,
+		ID:          uuid.New().String(),
+		RequestID:   requestID,
+		ProviderID:  resp.ID,
+		Timestamp:   time.Now().UTC(),
+		Model:       resp.Model,
+		ServedModel: resp.Model,
+		Provider:    provider,
+		Endpoint:    endpoint,
 	}
 
 	// Extract usage if available
@@ -176,6 +178,7 @@ func ExtractFromEmbeddingResponse(resp *core.EmbeddingResponse, requestID, provi
 		RequestID:   requestID,
 		Timestamp:   time.Now().UTC(),
 		Model:       resp.Model,
+		ServedModel: resp.Model,
 		Provider:    provider,
 		Endpoint:    endpoint,
 		InputTokens: resp.Usage.PromptTokens,
@@ -106,6 +106,9 @@ func TestExtractFromChatResponse(t *testing.T) {
 			if entry.Model != tt.wantModel {
 				t.Errorf("Model = %s, want %s", entry.Model, tt.wantModel)
 			}
+			if entry.ServedModel != tt.resp.Model {
+				t.Errorf("ServedModel = %s, want %s", entry.ServedModel, tt.resp.Model)
+			}
 			if entry.RequestID != tt.requestID {
 				t.Errorf("RequestID = %s, want %s", entry.RequestID, tt.requestID)
 			}
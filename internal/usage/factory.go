@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"gomodel/config"
+	"gomodel/internal/contentstats"
 	"gomodel/internal/storage"
 )
 
@@ -144,6 +145,10 @@ func buildLoggerConfig(usageCfg config.UsageConfig) Config {
 		BufferSize:                usageCfg.BufferSize,
 		FlushInterval:             time.Duration(usageCfg.FlushInterval) * time.Second,
 		RetentionDays:             usageCfg.RetentionDays,
+		DrainTimeout:              time.Duration(usageCfg.DrainTimeout) * time.Second,
+		WriteLagWarning:           time.Duration(usageCfg.WriteLagWarning) * time.Second,
+		ContentAnalysis:           usageCfg.ContentAnalysis,
+		ContentSampleChars:        usageCfg.ContentSampleChars,
 	}
 
 	// Apply defaults
@@ -153,6 +158,12 @@ func buildLoggerConfig(usageCfg config.UsageConfig) Config {
 	if cfg.FlushInterval <= 0 {
 		cfg.FlushInterval = 5 * time.Second
 	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = DefaultDrainTimeout
+	}
+	if cfg.ContentSampleChars <= 0 {
+		cfg.ContentSampleChars = contentstats.DefaultSampleChars
+	}
 
 	return cfg
 }
@@ -0,0 +1,53 @@
+package usage
+
+import (
+	"fmt"
+	"slices"
+
+	"gomodel/internal/core"
+)
+
+// usageLabelFilter is one label.<key>=<value> usage filter.
+type usageLabelFilter struct {
+	Key   string
+	Value string
+}
+
+// normalizeUsageLabelFilters validates label filters and returns them sorted
+// by key so generated queries are deterministic.
+func normalizeUsageLabelFilters(labels map[string]string) ([]usageLabelFilter, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	filters := make([]usageLabelFilter, 0, len(labels))
+	for rawKey, value := range labels {
+		key, err := normalizeUsageLabelKey(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, usageLabelFilter{Key: key, Value: value})
+	}
+	slices.SortFunc(filters, func(a, b usageLabelFilter) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	})
+	return filters, nil
+}
+
+func normalizeUsageLabelKey(raw string) (string, error) {
+	key, err := core.NormalizeRequestLabelKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("normalize usage label filter: %w", err)
+	}
+	return key, nil
+}
+
+// sqliteLabelJSONPath returns the json_extract path of a validated label key.
+func sqliteLabelJSONPath(key string) string {
+	return `$."` + key + `"`
+}
@@ -2,24 +2,108 @@ package usage
 
 import (
 	"context"
-	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"gomodel/internal/logging"
+	"gomodel/internal/pipelinestats"
+)
+
+// usageLogger logs usage buffering, flushing and storage events.
+var usageLogger = logging.Component(logging.ComponentUsage)
+
+// Prometheus metrics for the usage write path.
+var (
+	usageBufferDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gomodel_usage_buffer_depth",
+			Help: "Number of usage entries waiting in the in-memory buffer",
+		},
+	)
+	usageDroppedEntries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomodel_usage_dropped_entries_total",
+			Help: "Total number of usage entries dropped, by reason (buffer_full, write_failed)",
+		},
+		[]string{"reason"},
+	)
+	usageWrittenEntries = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gomodel_usage_written_entries_total",
+			Help: "Total number of usage entries written to the store",
+		},
+	)
+	usageFlushBatchSize = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "gomodel_usage_flush_batch_size",
+			Help:    "Number of usage entries per batch write",
+			Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+		},
+	)
+	usageFlushDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "gomodel_usage_flush_duration_seconds",
+			Help:    "Latency of usage batch writes to the store",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+)
+
+const (
+	dropReasonBufferFull  = "buffer_full"
+	dropReasonWriteFailed = "write_failed"
+
+	// batchWriteTimeout bounds a single batch write outside of Flush and Close.
+	batchWriteTimeout = 30 * time.Second
 )
 
 // Logger provides async buffered logging with batch writes.
-// It collects usage entries in a channel and flushes them to storage
-// either when the buffer is full or at regular intervals.
+// It collects usage entries in a bounded channel and flushes them to storage
+// either when a batch fills up or at regular intervals. Write never blocks:
+// entries that do not fit in the buffer are dropped and counted.
 type Logger struct {
 	store         UsageStore
 	config        Config
-	buffer        chan *UsageEntry
+	buffer        chan queuedEntry
 	done          chan struct{}
+	stopping      chan struct{} // closed when Close starts; releases pending Flush calls
+	flushReqs     chan flushRequest
 	wg            sync.WaitGroup
 	writes        sync.WaitGroup // tracks in-flight Write calls
 	flushInterval time.Duration
 	closed        atomic.Bool
+	pipeline      *pipelinestats.Tracker
+
+	droppedFull   atomic.Int64
+	droppedWrite  atomic.Int64
+	reportedDrops int64 // owned by the flush loop
+}
+
+// queuedEntry is a buffered entry and the time Write queued it (unix nanos).
+type queuedEntry struct {
+	entry      *UsageEntry
+	enqueuedAt int64
+}
+
+type flushRequest struct {
+	ctx    context.Context
+	result chan error
+}
+
+// LoggerStats is a snapshot of the logger's counters.
+type LoggerStats struct {
+	// Buffered is the number of entries waiting in the buffer.
+	Buffered int
+	// Written is the number of entries the store accepted.
+	Written int64
+	// DroppedBufferFull counts entries dropped because the buffer was full.
+	DroppedBufferFull int64
+	// DroppedWriteFailed counts entries lost to failed batch writes.
+	DroppedWriteFailed int64
 }
 
 // NewLogger creates a new async buffered Logger.
@@ -31,24 +115,35 @@ func NewLogger(store UsageStore, cfg Config) *Logger {
 	if cfg.FlushInterval <= 0 {
 		cfg.FlushInterval = 5 * time.Second
 	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = DefaultDrainTimeout
+	}
 
 	l := &Logger{
 		store:         store,
 		config:        cfg,
-		buffer:        make(chan *UsageEntry, cfg.BufferSize),
+		buffer:        make(chan queuedEntry, cfg.BufferSize),
 		done:          make(chan struct{}),
+		stopping:      make(chan struct{}),
+		flushReqs:     make(chan flushRequest),
 		flushInterval: cfg.FlushInterval,
+		pipeline:      pipelinestats.NewTracker(cfg.WriteLagWarning),
 	}
 
-	l.wg.Add(1)
+	l.wg.Add(2)
 	go l.flushLoop()
+	go func() {
+		defer l.wg.Done()
+		pipelinestats.MonitorLag(l.stopping, cfg.WriteLagWarning, l.PipelineStats, usageLogger, "usage")
+	}()
 
 	return l
 }
 
 // Write queues a usage entry for async writing.
-// This method is non-blocking. If the buffer is full or the logger is closed,
-// the entry is dropped and a warning is logged.
+// This method never blocks. If the buffer is full or the logger is closed,
+// the entry is dropped; buffer-full drops are counted and reported by the
+// flush loop rather than logged on the request path.
 func (l *Logger) Write(entry *UsageEntry) {
 	if entry == nil {
 		return
@@ -69,18 +164,12 @@ func (l *Logger) Write(entry *UsageEntry) {
 	}
 
 	select {
-	case l.buffer <- entry:
+	case l.buffer <- queuedEntry{entry: entry, enqueuedAt: time.Now().UnixNano()}:
 		// Entry queued successfully
 	default:
-		// Buffer full - drop entry and log warning
-		requestID := entry.RequestID
-		if requestID == "" {
-			requestID = "unknown"
-		}
-		slog.Warn("usage log buffer full, dropping entry",
-			"request_id", requestID,
-			"model", entry.Model,
-		)
+		l.droppedFull.Add(1)
+		l.pipeline.Dropped(1)
+		usageDroppedEntries.WithLabelValues(dropReasonBufferFull).Inc()
 	}
 }
 
@@ -89,8 +178,51 @@ func (l *Logger) Config() Config {
 	return l.config
 }
 
+// Stats returns a snapshot of the logger's counters.
+func (l *Logger) Stats() LoggerStats {
+	return LoggerStats{
+		Buffered:           len(l.buffer),
+		Written:            l.pipeline.Written(),
+		DroppedBufferFull:  l.droppedFull.Load(),
+		DroppedWriteFailed: l.droppedWrite.Load(),
+	}
+}
+
+// PipelineStats returns a snapshot of the write pipeline: buffer fill level,
+// write lag, flush outcomes and drops.
+func (l *Logger) PipelineStats() pipelinestats.Snapshot {
+	return l.pipeline.Snapshot(time.Now(), len(l.buffer), cap(l.buffer))
+}
+
+// Flush writes every buffered entry and flushes the store, giving up when ctx
+// ends or Config.DrainTimeout elapses.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.closed.Load() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.config.DrainTimeout)
+	defer cancel()
+
+	req := flushRequest{ctx: ctx, result: make(chan error, 1)}
+	select {
+	case l.flushReqs <- req:
+	case <-l.stopping:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Close stops the logger and flushes remaining entries.
-// This should be called during graceful shutdown.
+// This should be called during graceful shutdown. The final drain is bounded
+// by Config.DrainTimeout so a slow store cannot hang shutdown.
 // Close is idempotent - calling it multiple times is safe.
 func (l *Logger) Close() error {
 	// Make Close idempotent - if already closed, return immediately
@@ -98,6 +230,9 @@ func (l *Logger) Close() error {
 		return nil
 	}
 
+	// Release Flush calls that have not reached the flush loop
+	close(l.stopping)
+
 	// Wait for any in-flight Write calls to complete
 	l.writes.Wait()
 
@@ -122,63 +257,173 @@ func (l *Logger) flushLoop() {
 
 	for {
 		select {
-		case entry := <-l.buffer:
-			batch = append(batch, entry)
+		case queued := <-l.buffer:
+			batch = l.appendQueued(batch, queued)
 			// Flush when batch reaches threshold
 			if len(batch) >= BatchFlushThreshold {
-				l.flushBatch(batch)
+				l.flushBatch(context.Background(), batch)
 				batch = make([]*UsageEntry, 0, BatchFlushThreshold)
 			}
 
 		case <-ticker.C:
 			// Periodic flush
 			if len(batch) > 0 {
-				l.flushBatch(batch)
+				l.flushBatch(context.Background(), batch)
 				batch = make([]*UsageEntry, 0, BatchFlushThreshold)
 			}
+			l.reportDrops()
+
+		case req := <-l.flushReqs:
+			// Entries left over when the caller's deadline passes stay queued
+			// for the next flush.
+			var err error
+			batch, err = l.drain(req.ctx, batch)
+			req.result <- err
 
 		case <-l.done:
 			// Shutdown: drain remaining entries from buffer using non-blocking loop.
 			// Note: l.closed is already set by Close() before sending on l.done.
 			// We do NOT close(l.buffer) — closing is unnecessary since flushLoop
 			// exits via l.done, and closing creates a race with concurrent Write() calls.
-			for {
-				select {
-				case entry := <-l.buffer:
-					batch = append(batch, entry)
-				default:
-					goto drainComplete
-				}
-			}
-		drainComplete:
-			// Final flush
-			if len(batch) > 0 {
-				l.flushBatch(batch)
-			}
-			// Flush the store
-			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			if err := l.store.Flush(ctx); err != nil {
-				slog.Error("failed to flush usage store", "error", err)
-			}
+			ctx, cancel := context.WithTimeout(context.Background(), l.config.DrainTimeout)
+			leftover, err := l.drain(ctx, batch)
 			cancel()
+			if err != nil {
+				usageLogger.Error("failed to drain usage buffer before shutdown", "error", err)
+			}
+			l.dropUnwritten(leftover)
 			return
 		}
 	}
 }
 
-// flushBatch writes a batch of entries to the store.
-func (l *Logger) flushBatch(batch []*UsageEntry) {
+// drain writes batch and everything queued in the buffer in batches of at
+// most BatchFlushThreshold entries, then flushes the store. When ctx ends
+// first it returns the entries it has not written yet.
+func (l *Logger) drain(ctx context.Context, batch []*UsageEntry) ([]*UsageEntry, error) {
+	var firstErr error
+	for {
+		batch = l.fillBatch(batch)
+		if len(batch) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			l.reportDrops()
+			return batch, err
+		}
+		if err := l.flushBatch(ctx, batch); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		batch = make([]*UsageEntry, 0, BatchFlushThreshold)
+	}
+	l.reportDrops()
+
+	if err := l.store.Flush(ctx); err != nil {
+		usageLogger.Error("failed to flush usage store", "error", err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return batch, firstErr
+}
+
+// fillBatch moves queued entries into batch without blocking until it holds
+// BatchFlushThreshold entries or the buffer is empty.
+func (l *Logger) fillBatch(batch []*UsageEntry) []*UsageEntry {
+	for len(batch) < BatchFlushThreshold {
+		select {
+		case queued := <-l.buffer:
+			batch = l.appendQueued(batch, queued)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// appendQueued adds a queued entry to batch, tracking when the batch's oldest
+// entry was queued.
+func (l *Logger) appendQueued(batch []*UsageEntry, queued queuedEntry) []*UsageEntry {
 	if len(batch) == 0 {
-		return
+		l.pipeline.Hold(queued.enqueuedAt)
+	}
+	return append(batch, queued.entry)
+}
+
+// flushBatch writes a batch of entries to the store and records its size,
+// latency and outcome.
+func (l *Logger) flushBatch(ctx context.Context, batch []*UsageEntry) error {
+	if len(batch) == 0 {
+		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, batchWriteTimeout)
 	defer cancel()
+	defer l.pipeline.Release()
+
+	start := time.Now()
+	err := l.store.WriteBatch(ctx, batch)
+	elapsed := time.Since(start)
+	l.pipeline.Flushed(start, len(batch), err)
 
-	if err := l.store.WriteBatch(ctx, batch); err != nil {
-		slog.Error("failed to write usage batch",
+	usageFlushBatchSize.Observe(float64(len(batch)))
+	usageFlushDuration.Observe(elapsed.Seconds())
+	usageBufferDepth.Set(float64(len(l.buffer)))
+
+	if err != nil {
+		usageLogger.Error("failed to write usage batch",
 			"error", err,
 			"count", len(batch),
+			"duration", elapsed,
+		)
+		l.droppedWrite.Add(int64(len(batch)))
+		l.pipeline.Dropped(len(batch))
+		usageDroppedEntries.WithLabelValues(dropReasonWriteFailed).Add(float64(len(batch)))
+		return err
+	}
+
+	usageWrittenEntries.Add(float64(len(batch)))
+	usageLogger.Debug("flushed usage batch",
+		"count", len(batch),
+		"duration", elapsed,
+		"buffer_depth", len(l.buffer),
+	)
+	return nil
+}
+
+// dropUnwritten counts batch and every entry still queued as lost. It runs
+// only at shutdown, after Write has stopped accepting entries.
+func (l *Logger) dropUnwritten(batch []*UsageEntry) {
+	count := len(batch)
+	for drained := false; !drained; {
+		select {
+		case <-l.buffer:
+			count++
+		default:
+			drained = true
+		}
+	}
+	if count == 0 {
+		return
+	}
+	l.droppedWrite.Add(int64(count))
+	l.pipeline.Dropped(count)
+	usageDroppedEntries.WithLabelValues(dropReasonWriteFailed).Add(float64(count))
+	usageLogger.Error("usage drain timed out, dropping unwritten entries", "count", count)
+}
+
+// reportDrops logs buffer-full drops since the last report. Write only counts
+// them so a load spike does not turn into a log flood on the request path.
+func (l *Logger) reportDrops() {
+	usageBufferDepth.Set(float64(len(l.buffer)))
+
+	total := l.droppedFull.Load()
+	if dropped := total - l.reportedDrops; dropped > 0 {
+		l.reportedDrops = total
+		usageLogger.Warn("usage log buffer full, dropped entries",
+			"dropped", dropped,
+			"dropped_total", total,
+			"buffer_size", cap(l.buffer),
 		)
 	}
 }
@@ -209,6 +454,11 @@ func (l *NoopLogger) Config() Config {
 	return cfg
 }
 
+// Flush does nothing
+func (l *NoopLogger) Flush(_ context.Context) error {
+	return nil
+}
+
 // Close does nothing
 func (l *NoopLogger) Close() error {
 	return nil
@@ -218,5 +468,7 @@ func (l *NoopLogger) Close() error {
 type LoggerInterface interface {
 	Write(entry *UsageEntry)
 	Config() Config
+	// Flush writes buffered entries, returning once they are stored or ctx ends.
+	Flush(ctx context.Context) error
 	Close() error
 }
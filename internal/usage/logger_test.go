@@ -0,0 +1,291 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomodel/internal/pipelinestats"
+)
+
+// gatedStore blocks WriteBatch until release is closed or the write context
+// ends, recording what it stored.
+type gatedStore struct {
+	mockStore
+	started   chan struct{}
+	release   chan struct{}
+	startOnce sync.Once
+	maxBatch  atomic.Int64
+}
+
+func newGatedStore() *gatedStore {
+	return &gatedStore{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (s *gatedStore) WriteBatch(ctx context.Context, entries []*UsageEntry) error {
+	s.startOnce.Do(func() { close(s.started) })
+	for {
+		current := s.maxBatch.Load()
+		if int64(len(entries)) <= current || s.maxBatch.CompareAndSwap(current, int64(len(entries))) {
+			break
+		}
+	}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.mockStore.WriteBatch(ctx, entries)
+}
+
+func TestLoggerCountsBufferFullDrops(t *testing.T) {
+	store := newGatedStore()
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 200, FlushInterval: time.Hour})
+
+	for i := range BatchFlushThreshold {
+		logger.Write(&UsageEntry{ID: fmt.Sprintf("first-%d", i)})
+	}
+	select {
+	case <-store.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("threshold batch was not flushed")
+	}
+
+	// The flush loop is stuck in WriteBatch, so only the buffer absorbs writes.
+	for i := range 205 {
+		logger.Write(&UsageEntry{ID: fmt.Sprintf("second-%d", i)})
+	}
+	stats := logger.Stats()
+	if stats.DroppedBufferFull != 5 {
+		t.Fatalf("DroppedBufferFull = %d, want 5", stats.DroppedBufferFull)
+	}
+	if stats.Buffered != 200 {
+		t.Fatalf("Buffered = %d, want 200", stats.Buffered)
+	}
+
+	close(store.release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := len(store.getEntries()); got != 300 {
+		t.Fatalf("stored entries = %d, want 300", got)
+	}
+	if got := logger.Stats().Written; got != 300 {
+		t.Fatalf("Written = %d, want 300", got)
+	}
+}
+
+func TestLoggerFlushWritesBufferedEntries(t *testing.T) {
+	store := &mockStore{}
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 500, FlushInterval: time.Hour})
+	defer func() { _ = logger.Close() }()
+
+	for i := range 250 {
+		logger.Write(&UsageEntry{ID: fmt.Sprintf("entry-%d", i)})
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := len(store.getEntries()); got != 250 {
+		t.Fatalf("stored entries = %d, want 250", got)
+	}
+}
+
+func TestLoggerFlushHonorsContextDeadline(t *testing.T) {
+	store := newGatedStore()
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 10, FlushInterval: time.Hour, DrainTimeout: time.Minute})
+
+	logger.Write(&UsageEntry{ID: "slow"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := logger.Flush(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Flush() took %v despite a 50ms deadline", elapsed)
+	}
+
+	close(store.release)
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestLoggerCloseIsBoundedByDrainTimeout(t *testing.T) {
+	store := newGatedStore()
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 1000, FlushInterval: time.Hour, DrainTimeout: 100 * time.Millisecond})
+
+	for i := range 250 {
+		logger.Write(&UsageEntry{ID: fmt.Sprintf("entry-%d", i)})
+	}
+
+	start := time.Now()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Close() took %v with a 100ms drain timeout", elapsed)
+	}
+
+	stats := logger.Stats()
+	if stats.Written != 0 || stats.DroppedWriteFailed != 250 {
+		t.Fatalf("stats = %+v, want all 250 entries dropped as write_failed", stats)
+	}
+}
+
+func TestLoggerConcurrentWritesStress(t *testing.T) {
+	const (
+		writers          = 50
+		entriesPerWriter = 1000
+		bufferSize       = 1000
+	)
+
+	store := newGatedStore()
+	close(store.release)
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: bufferSize, FlushInterval: 10 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	var maxBuffered atomic.Int64
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range entriesPerWriter {
+				logger.Write(&UsageEntry{
+					ID:          fmt.Sprintf("w%d-%d", w, i),
+					TotalTokens: 1,
+				})
+				if i%100 == 0 {
+					if buffered := int64(logger.Stats().Buffered); buffered > maxBuffered.Load() {
+						maxBuffered.Store(buffered)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	stats := logger.Stats()
+	total := int64(writers * entriesPerWriter)
+	if stats.Written+stats.DroppedBufferFull != total {
+		t.Fatalf("written %d + dropped %d != %d written entries", stats.Written, stats.DroppedBufferFull, total)
+	}
+	if stats.DroppedWriteFailed != 0 {
+		t.Fatalf("DroppedWriteFailed = %d, want 0 with a healthy store", stats.DroppedWriteFailed)
+	}
+
+	entries := store.getEntries()
+	if int64(len(entries)) != stats.Written {
+		t.Fatalf("stored entries = %d, want Written = %d", len(entries), stats.Written)
+	}
+	seen := make(map[string]struct{}, len(entries))
+	var tokens int64
+	for _, entry := range entries {
+		if _, dup := seen[entry.ID]; dup {
+			t.Fatalf("entry %s stored twice", entry.ID)
+		}
+		seen[entry.ID] = struct{}{}
+		tokens += int64(entry.TotalTokens)
+	}
+	if tokens != stats.Written {
+		t.Fatalf("stored tokens = %d, want %d", tokens, stats.Written)
+	}
+
+	if got := maxBuffered.Load(); got > bufferSize {
+		t.Fatalf("buffer held %d entries, want <= %d", got, bufferSize)
+	}
+	if got := store.maxBatch.Load(); got > BatchFlushThreshold {
+		t.Fatalf("largest batch = %d entries, want <= %d", got, BatchFlushThreshold)
+	}
+}
+
+func TestLoggerPipelineStatsTrackLag(t *testing.T) {
+	store := newGatedStore()
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 10, FlushInterval: 5 * time.Millisecond, WriteLagWarning: 20 * time.Millisecond})
+
+	logger.Write(&UsageEntry{ID: "slow"})
+	select {
+	case <-store.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("batch was not flushed")
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	stats := logger.PipelineStats()
+	if !stats.Lagging || stats.OldestPending() < 20*time.Millisecond || stats.BufferSize != 10 {
+		t.Fatalf("stats = %+v, want the held entry reported as lagging", stats)
+	}
+
+	close(store.release)
+	waitForStats(t, logger, func(s pipelinestats.Snapshot) bool { return s.Written == 1 })
+	stats = logger.PipelineStats()
+	if stats.Lagging || stats.OldestPendingSeconds != 0 || stats.Flushes != 1 || stats.LastFlushAt == nil {
+		t.Fatalf("stats = %+v, want a caught-up pipeline", stats)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestLoggerPipelineStatsUnderConcurrentWrites(t *testing.T) {
+	const (
+		writers          = 20
+		entriesPerWriter = 500
+	)
+
+	store := newGatedStore()
+	close(store.release)
+	logger := NewLogger(store, Config{Enabled: true, BufferSize: 200, FlushInterval: 5 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range entriesPerWriter {
+				logger.Write(&UsageEntry{ID: fmt.Sprintf("w%d-%d", w, i)})
+				if i%50 == 0 {
+					_ = logger.PipelineStats()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	stats := logger.PipelineStats()
+	legacy := logger.Stats()
+	if stats.Written+stats.Dropped != writers*entriesPerWriter {
+		t.Fatalf("written %d + dropped %d != %d", stats.Written, stats.Dropped, writers*entriesPerWriter)
+	}
+	if stats.Written != legacy.Written || stats.Dropped != legacy.DroppedBufferFull+legacy.DroppedWriteFailed {
+		t.Fatalf("pipeline stats %+v disagree with logger stats %+v", stats, legacy)
+	}
+	if int64(len(store.getEntries())) != stats.Written {
+		t.Fatalf("stored entries = %d, want %d", len(store.getEntries()), stats.Written)
+	}
+}
+
+func waitForStats(t *testing.T, logger *Logger, cond func(pipelinestats.Snapshot) bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond(logger.PipelineStats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, condition not met before deadline", logger.PipelineStats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -8,14 +8,25 @@ import (
 
 // UsageQueryParams specifies the query parameters for usage data retrieval.
 type UsageQueryParams struct {
-	StartDate time.Time // Inclusive start (day precision)
-	EndDate   time.Time // Inclusive end (day precision)
-	Interval  string    // "daily", "weekly", "monthly", "yearly"
-	TimeZone  string    // IANA timezone used for day-boundary interpretation and grouping
-	UserPath  string    // subtree filter on tracked user path
-	CacheMode string    // "uncached" (default), "cached", or "all"
+	StartDate time.Time         // Inclusive start (day precision)
+	EndDate   time.Time         // Inclusive end (day precision)
+	Interval  string            // "daily", "weekly", "monthly", "yearly"
+	TimeZone  string            // IANA timezone used for day-boundary interpretation and grouping
+	WeekStart string            // "monday" (default, ISO weeks) or "sunday"; only affects weekly grouping
+	UserPath  string            // subtree filter on tracked user path
+	CacheMode string            // "uncached" (default), "cached", or "all"
+	Labels    map[string]string // exact-match filters on request labels
+	ModelBy   string            // "served" (default) or "requested"; model grouping for GetUsageByModel
+	AuthKeyID string            // exact-match filter on the managed auth key that made the request
 }
 
+// Model groupings accepted by UsageQueryParams.ModelBy. Rows recorded before
+// requested and served models were tracked fall back to the model column.
+const (
+	ModelByServed    = "served"
+	ModelByRequested = "requested"
+)
+
 // UsageSummary holds aggregated usage statistics over a time period.
 type UsageSummary struct {
 	TotalRequests   int      `json:"total_requests"`
@@ -50,9 +61,39 @@ type UserPathUsage struct {
 	TotalCost    *float64 `json:"total_cost" extensions:"x-nullable"`
 }
 
+// ExperimentVariantUsage holds per-variant usage aggregates for one A/B experiment.
+type ExperimentVariantUsage struct {
+	Experiment   string   `json:"experiment"`
+	Variant      string   `json:"variant"`
+	Requests     int      `json:"requests"`
+	InputTokens  int64    `json:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens"`
+	TotalTokens  int64    `json:"total_tokens"`
+	TotalCost    *float64 `json:"total_cost" extensions:"x-nullable"`
+}
+
+// UserGroupLabel is the LabelUsage.Label of usage grouped by end user, where
+// Value is the user hash.
+const UserGroupLabel = "user"
+
+// LabelUsage holds usage aggregates for one value of a request label. Value is
+// empty for requests that did not carry the label.
+type LabelUsage struct {
+	Label        string   `json:"label"`
+	Value        string   `json:"value"`
+	Requests     int      `json:"requests"`
+	InputTokens  int64    `json:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens"`
+	TotalTokens  int64    `json:"total_tokens"`
+	InputCost    *float64 `json:"input_cost" extensions:"x-nullable"`
+	OutputCost   *float64 `json:"output_cost" extensions:"x-nullable"`
+	TotalCost    *float64 `json:"total_cost" extensions:"x-nullable"`
+}
+
 // DailyUsage holds usage statistics for a single period.
 // Date holds the period label: YYYY-MM-DD for daily, YYYY-Www for weekly,
-// YYYY-MM for monthly, or YYYY for yearly intervals.
+// YYYY-MM for monthly, or YYYY for yearly intervals. Sunday-start weeks are
+// labelled with the ISO week of the Monday that follows their Sunday.
 type DailyUsage struct {
 	Date         string   `json:"date"`
 	Requests     int      `json:"requests"`
@@ -64,36 +105,86 @@ type DailyUsage struct {
 	TotalCost    *float64 `json:"total_cost"`
 }
 
+// Upper bounds, in characters, of the short, medium and long buckets of
+// ContentLengthBuckets.
+const (
+	contentShortChars  = 200
+	contentMediumChars = 1000
+	contentLongChars   = 5000
+)
+
+// ContentLengthBuckets counts responses by output length in characters:
+// short is under 200, medium under 1000, long under 5000 and very long the
+// rest.
+type ContentLengthBuckets struct {
+	Short    int `json:"short"`
+	Medium   int `json:"medium"`
+	Long     int `json:"long"`
+	VeryLong int `json:"very_long"`
+}
+
+// ContentStats holds the content analysis aggregates of one day, model,
+// provider and output language. Language is "und" for output too short or
+// too mixed to identify.
+type ContentStats struct {
+	Date         string               `json:"date"`
+	Model        string               `json:"model"`
+	Provider     string               `json:"provider"`
+	ProviderName string               `json:"provider_name,omitempty"`
+	Language     string               `json:"language"`
+	Responses    int                  `json:"responses"`
+	TotalChars   int64                `json:"total_chars"`
+	TotalWords   int64                `json:"total_words"`
+	MaxChars     int                  `json:"max_chars"`
+	Lengths      ContentLengthBuckets `json:"lengths"`
+}
+
 // UsageLogParams specifies query parameters for paginated usage log retrieval.
 type UsageLogParams struct {
 	UsageQueryParams        // embed date range
 	Model            string // filter by model (optional)
 	Provider         string // filter by provider name or provider type (optional)
 	Search           string // free-text search on model/provider/request_id
+	UserHash         string // filter by hash of the user request field (optional)
+	RequestID        string // filter by exact gateway request ID (optional)
 	Limit            int    // page size (default 50, max 200)
 	Offset           int    // pagination offset
+	Cursor           string // NextCursor of the previous page; replaces Offset
 }
 
 // UsageLogEntry represents a single usage record in the request log.
 type UsageLogEntry struct {
-	ID                     string         `json:"id"`
-	RequestID              string         `json:"request_id"`
-	ProviderID             string         `json:"provider_id"`
-	Timestamp              time.Time      `json:"timestamp"`
-	Model                  string         `json:"model"`
-	Provider               string         `json:"provider"`
-	ProviderName           string         `json:"provider_name,omitempty"`
-	Endpoint               string         `json:"endpoint"`
-	UserPath               string         `json:"user_path,omitempty"`
-	CacheType              string         `json:"cache_type,omitempty"`
-	InputTokens            int            `json:"input_tokens"`
-	OutputTokens           int            `json:"output_tokens"`
-	TotalTokens            int            `json:"total_tokens"`
-	InputCost              *float64       `json:"input_cost"`
-	OutputCost             *float64       `json:"output_cost"`
-	TotalCost              *float64       `json:"total_cost"`
-	RawData                map[string]any `json:"raw_data,omitempty"`
-	CostsCalculationCaveat string         `json:"costs_calculation_caveat,omitempty"`
+	ID                     string            `json:"id"`
+	RequestID              string            `json:"request_id"`
+	ProviderID             string            `json:"provider_id"`
+	Timestamp              time.Time         `json:"timestamp"`
+	Model                  string            `json:"model"`
+	Provider               string            `json:"provider"`
+	ProviderName           string            `json:"provider_name,omitempty"`
+	RequestedModel         string            `json:"requested_model,omitempty"`
+	ServedModel            string            `json:"served_model,omitempty"`
+	Endpoint               string            `json:"endpoint"`
+	UserPath               string            `json:"user_path,omitempty"`
+	CacheType              string            `json:"cache_type,omitempty"`
+	InputTokens            int               `json:"input_tokens"`
+	OutputTokens           int               `json:"output_tokens"`
+	TotalTokens            int               `json:"total_tokens"`
+	ImageCount             int               `json:"image_count,omitempty"`
+	ImageBytes             int64             `json:"image_bytes,omitempty"`
+	TemplateName           string            `json:"template_name,omitempty"`
+	TemplateVersion        int               `json:"template_version,omitempty"`
+	UserHash               string            `json:"user_hash,omitempty"`
+	User                   string            `json:"user,omitempty"`
+	AuthKeyID              string            `json:"auth_key_id,omitempty"`
+	ContentLanguage        string            `json:"content_language,omitempty"`
+	ContentChars           int               `json:"content_chars,omitempty"`
+	ContentWords           int               `json:"content_words,omitempty"`
+	InputCost              *float64          `json:"input_cost"`
+	OutputCost             *float64          `json:"output_cost"`
+	TotalCost              *float64          `json:"total_cost"`
+	RawData                map[string]any    `json:"raw_data,omitempty"`
+	Labels                 map[string]string `json:"labels,omitempty"`
+	CostsCalculationCaveat string            `json:"costs_calculation_caveat,omitempty"`
 }
 
 // UsageLogResult holds a paginated list of usage log entries.
@@ -102,6 +193,10 @@ type UsageLogResult struct {
 	Total   int             `json:"total"`
 	Limit   int             `json:"limit"`
 	Offset  int             `json:"offset"`
+	// NextCursor fetches the next page when passed as cursor, and is empty
+	// on the last page. Unlike offset it stays stable while new entries
+	// arrive, so it is the preferred way to page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // CacheOverviewSummary holds cached-only aggregate statistics over a time period.
@@ -149,11 +244,28 @@ type UsageReader interface {
 	// GetUsageByUserPath returns per-user-path token usage aggregates for the given date range.
 	GetUsageByUserPath(ctx context.Context, params UsageQueryParams) ([]UserPathUsage, error)
 
+	// GetUsageByExperiment returns per-variant usage aggregates for requests that
+	// were assigned to an A/B experiment in the given date range.
+	GetUsageByExperiment(ctx context.Context, params UsageQueryParams) ([]ExperimentVariantUsage, error)
+
+	// GetUsageByLabel returns usage aggregates grouped by the values of one
+	// request label in the given date range.
+	GetUsageByLabel(ctx context.Context, params UsageQueryParams, label string) ([]LabelUsage, error)
+
+	// GetUsageByUser returns usage aggregates grouped by the hash of the user
+	// request field in the given date range, labelled UserGroupLabel.
+	GetUsageByUser(ctx context.Context, params UsageQueryParams) ([]LabelUsage, error)
+
 	// GetUsageLog returns a paginated list of individual usage entries with optional filtering.
 	GetUsageLog(ctx context.Context, params UsageLogParams) (*UsageLogResult, error)
 
 	// GetCacheOverview returns cached-only aggregates for the admin dashboard.
 	GetCacheOverview(ctx context.Context, params UsageQueryParams) (*CacheOverview, error)
+
+	// GetContentStats returns the content analysis aggregates of analyzed
+	// entries in the given date range, grouped by day, model, provider and
+	// output language.
+	GetContentStats(ctx context.Context, params UsageQueryParams) ([]ContentStats, error)
 }
 
 func displayUsageProviderName(providerName, provider string) string {
@@ -1,7 +1,11 @@
 package usage
 
 import (
+	"strconv"
 	"strings"
+
+	"gomodel/internal/core"
+	"gomodel/internal/pagination"
 )
 
 // escapeLikeWildcards escapes SQL LIKE/ILIKE wildcard characters in user input
@@ -28,12 +32,38 @@ func usageGroupedProviderNameSQL(providerNameColumn, providerColumn string) stri
 	return "COALESCE(NULLIF(TRIM(" + providerNameColumn + "), ''), " + providerColumn + ")"
 }
 
+func groupedModelColumn(modelBy string) string {
+	if strings.EqualFold(strings.TrimSpace(modelBy), ModelByRequested) {
+		return "requested_model"
+	}
+	return "served_model"
+}
+
+// usageGroupedModelSQL returns a SQL expression that groups by the requested
+// or served model, falling back to the model column when it is blank.
+func usageGroupedModelSQL(modelBy string) string {
+	return "COALESCE(NULLIF(TRIM(" + groupedModelColumn(modelBy) + "), ''), model)"
+}
+
 // usageGroupedUserPathSQL returns a SQL expression that collapses blank
 // user_path values to the tracked root path before grouping.
 func usageGroupedUserPathSQL(userPathColumn string) string {
 	return "COALESCE(NULLIF(TRIM(" + userPathColumn + "), ''), '/')"
 }
 
+// contentAnalyzedSQL matches the entries content analysis described.
+const contentAnalyzedSQL = "content_language IS NOT NULL AND content_language != ''"
+
+// contentLengthBucketsSQL returns the SQL counting rows per
+// ContentLengthBuckets bucket of the content_chars column.
+func contentLengthBucketsSQL() string {
+	short, medium, long := strconv.Itoa(contentShortChars), strconv.Itoa(contentMediumChars), strconv.Itoa(contentLongChars)
+	return `COALESCE(SUM(CASE WHEN content_chars < ` + short + ` THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN content_chars >= ` + short + ` AND content_chars < ` + medium + ` THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN content_chars >= ` + medium + ` AND content_chars < ` + long + ` THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN content_chars >= ` + long + ` THEN 1 ELSE 0 END), 0)`
+}
+
 // clampLimitOffset normalises pagination parameters:
 //   - limit defaults to 50 and is capped at 200
 //   - offset floors at 0
@@ -49,3 +79,31 @@ func clampLimitOffset(limit, offset int) (int, int) {
 	}
 	return limit, offset
 }
+
+// usageLogCursor returns the position params.Cursor continues from, or nil
+// without a cursor, and the fingerprint of params' filters that the next
+// cursor is issued for.
+func usageLogCursor(params UsageLogParams) (*pagination.Cursor, string, error) {
+	encoded := params.Cursor
+	params.Limit, params.Offset, params.Cursor = 0, 0, ""
+	filter := pagination.Fingerprint(params)
+	if encoded == "" {
+		return nil, filter, nil
+	}
+	cursor, err := pagination.Decode(encoded, filter)
+	if err != nil {
+		return nil, "", core.NewInvalidRequestError(err.Error(), err)
+	}
+	return &cursor, filter, nil
+}
+
+// nextUsageLogCursor trims entries fetched with one extra row to limit and
+// returns the cursor after the last one kept, which is empty when nothing
+// followed it. keys holds the sort key of each entry.
+func nextUsageLogCursor(entries []UsageLogEntry, keys []string, limit int, filter string) ([]UsageLogEntry, string) {
+	if len(entries) <= limit {
+		return entries, ""
+	}
+	entries = entries[:limit]
+	return entries, pagination.Cursor{Key: keys[limit-1], ID: entries[limit-1].ID}.Encode(filter)
+}
@@ -8,6 +8,8 @@ import (
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"gomodel/internal/core"
 )
 
 // MongoDBReader implements UsageReader for MongoDB.
@@ -99,7 +101,7 @@ func (r *MongoDBReader) GetUsageByModel(ctx context.Context, params UsageQueryPa
 	providerNameExpr := mongoUsageGroupedProviderNameExpr()
 	pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.D{
 		{Key: "_id", Value: bson.D{
-			{Key: "model", Value: "$model"},
+			{Key: "model", Value: mongoUsageGroupedModelExpr(params.ModelBy)},
 			{Key: "provider", Value: "$provider"},
 			{Key: "provider_name", Value: providerNameExpr},
 		}},
@@ -252,6 +254,17 @@ func mongoUsageGroupedProviderNameExpr() bson.D {
 	}}}
 }
 
+func mongoUsageGroupedModelExpr(modelBy string) bson.D {
+	trimmedModel := bson.D{{Key: "$trim", Value: bson.D{
+		{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + groupedModelColumn(modelBy), ""}}}},
+	}}}
+	return bson.D{{Key: "$cond", Value: bson.A{
+		bson.D{{Key: "$ne", Value: bson.A{trimmedModel, ""}}},
+		trimmedModel,
+		"$model",
+	}}}
+}
+
 func mongoUsageGroupedUserPathExpr() bson.D {
 	trimmedUserPath := bson.D{{Key: "$trim", Value: bson.D{
 		{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$user_path", ""}}}},
@@ -263,9 +276,169 @@ func mongoUsageGroupedUserPathExpr() bson.D {
 	}}}
 }
 
+// GetUsageByExperiment returns request, token, and cost totals grouped by
+// experiment variant.
+func (r *MongoDBReader) GetUsageByExperiment(ctx context.Context, params UsageQueryParams) ([]ExperimentVariantUsage, error) {
+	matchFilters, err := mongoUsageMatchFilters(params)
+	if err != nil {
+		return nil, err
+	}
+	matchFilters = append(matchFilters, bson.E{Key: "experiment", Value: bson.D{{Key: "$nin", Value: bson.A{nil, ""}}}})
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: matchFilters}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "experiment", Value: "$experiment"},
+				{Key: "variant", Value: "$experiment_variant"},
+			}},
+			{Key: "requests", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "input_tokens", Value: bson.D{{Key: "$sum", Value: "$input_tokens"}}},
+			{Key: "output_tokens", Value: bson.D{{Key: "$sum", Value: "$output_tokens"}}},
+			{Key: "total_tokens", Value: bson.D{{Key: "$sum", Value: "$total_tokens"}}},
+			{Key: "total_cost", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$total_cost", 0}}}}}},
+			{Key: "has_costs", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$gt", Value: bson.A{"$total_cost", nil}}}, 1, 0}}}}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id.experiment", Value: 1}, {Key: "_id.variant", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage by experiment: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]ExperimentVariantUsage, 0)
+	for cursor.Next(ctx) {
+		var row struct {
+			ID struct {
+				Experiment string `bson:"experiment"`
+				Variant    string `bson:"variant"`
+			} `bson:"_id"`
+			Requests     int     `bson:"requests"`
+			InputTokens  int64   `bson:"input_tokens"`
+			OutputTokens int64   `bson:"output_tokens"`
+			TotalTokens  int64   `bson:"total_tokens"`
+			TotalCost    float64 `bson:"total_cost"`
+			HasCosts     int     `bson:"has_costs"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode usage by experiment row: %w", err)
+		}
+		u := ExperimentVariantUsage{
+			Experiment:   row.ID.Experiment,
+			Variant:      row.ID.Variant,
+			Requests:     row.Requests,
+			InputTokens:  row.InputTokens,
+			OutputTokens: row.OutputTokens,
+			TotalTokens:  row.TotalTokens,
+		}
+		if row.HasCosts > 0 {
+			u.TotalCost = &row.TotalCost
+		}
+		result = append(result, u)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by experiment cursor: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetUsageByLabel returns request, token, and cost totals grouped by the value
+// of one request label. Requests without the label are grouped under "".
+func (r *MongoDBReader) GetUsageByLabel(ctx context.Context, params UsageQueryParams, label string) ([]LabelUsage, error) {
+	key, err := normalizeUsageLabelKey(label)
+	if err != nil {
+		return nil, err
+	}
+	return r.aggregateLabelUsage(ctx, params, key, "$labels."+key)
+}
+
+// GetUsageByUser returns request, token, and cost totals grouped by the hash
+// of the user request field. Requests without a user are grouped under "".
+func (r *MongoDBReader) GetUsageByUser(ctx context.Context, params UsageQueryParams) ([]LabelUsage, error) {
+	return r.aggregateLabelUsage(ctx, params, UserGroupLabel, "$user_hash")
+}
+
+func (r *MongoDBReader) aggregateLabelUsage(ctx context.Context, params UsageQueryParams, label, field string) ([]LabelUsage, error) {
+	matchFilters, err := mongoUsageMatchFilters(params)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := bson.A{}
+	if len(matchFilters) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchFilters}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{field, ""}}}},
+			{Key: "requests", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "input_tokens", Value: bson.D{{Key: "$sum", Value: "$input_tokens"}}},
+			{Key: "output_tokens", Value: bson.D{{Key: "$sum", Value: "$output_tokens"}}},
+			{Key: "total_tokens", Value: bson.D{{Key: "$sum", Value: "$total_tokens"}}},
+			{Key: "input_cost", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$input_cost", 0}}}}}},
+			{Key: "output_cost", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$output_cost", 0}}}}}},
+			{Key: "total_cost", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$total_cost", 0}}}}}},
+			{Key: "has_costs", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$gt", Value: bson.A{"$total_cost", nil}}}, 1, 0}}}}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage by %s: %w", label, err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]LabelUsage, 0)
+	for cursor.Next(ctx) {
+		var row struct {
+			Value        string  `bson:"_id"`
+			Requests     int     `bson:"requests"`
+			InputTokens  int64   `bson:"input_tokens"`
+			OutputTokens int64   `bson:"output_tokens"`
+			TotalTokens  int64   `bson:"total_tokens"`
+			InputCost    float64 `bson:"input_cost"`
+			OutputCost   float64 `bson:"output_cost"`
+			TotalCost    float64 `bson:"total_cost"`
+			HasCosts     int     `bson:"has_costs"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode usage by %s row: %w", label, err)
+		}
+		u := LabelUsage{
+			Label:        label,
+			Value:        row.Value,
+			Requests:     row.Requests,
+			InputTokens:  row.InputTokens,
+			OutputTokens: row.OutputTokens,
+			TotalTokens:  row.TotalTokens,
+		}
+		if row.HasCosts > 0 {
+			u.InputCost = &row.InputCost
+			u.OutputCost = &row.OutputCost
+			u.TotalCost = &row.TotalCost
+		}
+		result = append(result, u)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by %s cursor: %w", label, err)
+	}
+
+	return result, nil
+}
+
 // GetUsageLog returns a paginated list of individual usage log entries.
 func (r *MongoDBReader) GetUsageLog(ctx context.Context, params UsageLogParams) (*UsageLogResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	after, filter, err := usageLogCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	matchFilters, err := mongoUsageLogMatchFilters(params)
 	if err != nil {
@@ -277,12 +450,27 @@ func (r *MongoDBReader) GetUsageLog(ctx context.Context, params UsageLogParams)
 		pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchFilters}})
 	}
 
+	// The cursor only narrows the page, not the total.
+	dataStages := bson.A{}
+	if after != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, after.Key)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid cursor", err)
+		}
+		offset = 0
+		dataStages = append(dataStages, bson.D{{Key: "$match", Value: bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "timestamp", Value: bson.D{{Key: "$lt", Value: cursorTime}}}},
+			bson.D{{Key: "timestamp", Value: cursorTime}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: after.ID}}}},
+		}}}}})
+	}
+	dataStages = append(dataStages,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit + 1}},
+	)
+
 	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.D{
-		{Key: "data", Value: bson.A{
-			bson.D{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: -1}}}},
-			bson.D{{Key: "$skip", Value: offset}},
-			bson.D{{Key: "$limit", Value: limit}},
-		}},
+		{Key: "data", Value: dataStages},
 		{Key: "total", Value: bson.A{
 			bson.D{{Key: "$count", Value: "count"}},
 		}},
@@ -296,24 +484,37 @@ func (r *MongoDBReader) GetUsageLog(ctx context.Context, params UsageLogParams)
 
 	var facetResult struct {
 		Data []struct {
-			ID                     string         `bson:"_id"`
-			RequestID              string         `bson:"request_id"`
-			ProviderID             string         `bson:"provider_id"`
-			Timestamp              time.Time      `bson:"timestamp"`
-			Model                  string         `bson:"model"`
-			Provider               string         `bson:"provider"`
-			ProviderName           string         `bson:"provider_name"`
-			Endpoint               string         `bson:"endpoint"`
-			UserPath               string         `bson:"user_path"`
-			CacheType              string         `bson:"cache_type"`
-			InputTokens            int            `bson:"input_tokens"`
-			OutputTokens           int            `bson:"output_tokens"`
-			TotalTokens            int            `bson:"total_tokens"`
-			InputCost              *float64       `bson:"input_cost"`
-			OutputCost             *float64       `bson:"output_cost"`
-			TotalCost              *float64       `bson:"total_cost"`
-			RawData                map[string]any `bson:"raw_data"`
-			CostsCalculationCaveat string         `bson:"costs_calculation_caveat"`
+			ID                     string            `bson:"_id"`
+			RequestID              string            `bson:"request_id"`
+			ProviderID             string            `bson:"provider_id"`
+			Timestamp              time.Time         `bson:"timestamp"`
+			Model                  string            `bson:"model"`
+			Provider               string            `bson:"provider"`
+			ProviderName           string            `bson:"provider_name"`
+			RequestedModel         string            `bson:"requested_model"`
+			ServedModel            string            `bson:"served_model"`
+			Endpoint               string            `bson:"endpoint"`
+			UserPath               string            `bson:"user_path"`
+			CacheType              string            `bson:"cache_type"`
+			InputTokens            int               `bson:"input_tokens"`
+			OutputTokens           int               `bson:"output_tokens"`
+			TotalTokens            int               `bson:"total_tokens"`
+			ImageCount             int               `bson:"image_count"`
+			ImageBytes             int64             `bson:"image_bytes"`
+			TemplateName           string            `bson:"template_name"`
+			TemplateVersion        int               `bson:"template_version"`
+			UserHash               string            `bson:"user_hash"`
+			User                   string            `bson:"user"`
+			AuthKeyID              string            `bson:"auth_key_id"`
+			ContentLanguage        string            `bson:"content_language"`
+			ContentChars           int               `bson:"content_chars"`
+			ContentWords           int               `bson:"content_words"`
+			InputCost              *float64          `bson:"input_cost"`
+			OutputCost             *float64          `bson:"output_cost"`
+			TotalCost              *float64          `bson:"total_cost"`
+			RawData                map[string]any    `bson:"raw_data"`
+			Labels                 map[string]string `bson:"labels"`
+			CostsCalculationCaveat string            `bson:"costs_calculation_caveat"`
 		} `bson:"data"`
 		Total []struct {
 			Count int `bson:"count"`
@@ -336,7 +537,9 @@ func (r *MongoDBReader) GetUsageLog(ctx context.Context, params UsageLogParams)
 	}
 
 	entries := make([]UsageLogEntry, 0, len(facetResult.Data))
+	keys := make([]string, 0, len(facetResult.Data))
 	for _, row := range facetResult.Data {
+		keys = append(keys, row.Timestamp.UTC().Format(time.RFC3339Nano))
 		entries = append(entries, UsageLogEntry{
 			ID:                     row.ID,
 			RequestID:              row.RequestID,
@@ -345,25 +548,40 @@ func (r *MongoDBReader) GetUsageLog(ctx context.Context, params UsageLogParams)
 			Model:                  row.Model,
 			Provider:               row.Provider,
 			ProviderName:           displayUsageProviderName(row.ProviderName, row.Provider),
+			RequestedModel:         row.RequestedModel,
+			ServedModel:            row.ServedModel,
 			Endpoint:               row.Endpoint,
 			UserPath:               row.UserPath,
 			CacheType:              normalizeCacheType(row.CacheType),
 			InputTokens:            row.InputTokens,
 			OutputTokens:           row.OutputTokens,
 			TotalTokens:            row.TotalTokens,
+			ImageCount:             row.ImageCount,
+			ImageBytes:             row.ImageBytes,
+			TemplateName:           row.TemplateName,
+			TemplateVersion:        row.TemplateVersion,
+			UserHash:               row.UserHash,
+			User:                   row.User,
+			AuthKeyID:              row.AuthKeyID,
+			ContentLanguage:        row.ContentLanguage,
+			ContentChars:           row.ContentChars,
+			ContentWords:           row.ContentWords,
 			InputCost:              row.InputCost,
 			OutputCost:             row.OutputCost,
 			TotalCost:              row.TotalCost,
 			RawData:                row.RawData,
+			Labels:                 row.Labels,
 			CostsCalculationCaveat: row.CostsCalculationCaveat,
 		})
 	}
 
+	entries, next := nextUsageLogCursor(entries, keys, limit, filter)
 	return &UsageLogResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
@@ -398,6 +616,26 @@ func mongoDateFormat(interval string) string {
 	}
 }
 
+// mongoPeriodExpr formats $timestamp as the period label for interval in the
+// requested time zone.
+func mongoPeriodExpr(interval string, params UsageQueryParams) bson.D {
+	timeZone := usageTimeZone(params)
+	var date any = "$timestamp"
+	if interval == "weekly" && usageSundayWeeks(params) {
+		date = bson.D{{Key: "$dateAdd", Value: bson.D{
+			{Key: "startDate", Value: "$timestamp"},
+			{Key: "unit", Value: "day"},
+			{Key: "amount", Value: 1},
+			{Key: "timezone", Value: timeZone},
+		}}}
+	}
+	return bson.D{{Key: "$dateToString", Value: bson.D{
+		{Key: "format", Value: mongoDateFormat(interval)},
+		{Key: "date", Value: date},
+		{Key: "timezone", Value: timeZone},
+	}}}
+}
+
 // GetDailyUsage returns usage statistics grouped by time period (daily, weekly, monthly, yearly).
 func (r *MongoDBReader) GetDailyUsage(ctx context.Context, params UsageQueryParams) ([]DailyUsage, error) {
 	interval := params.Interval
@@ -414,15 +652,9 @@ func (r *MongoDBReader) GetDailyUsage(ctx context.Context, params UsageQueryPara
 		pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchFilters}})
 	}
 
-	dateFormat := mongoDateFormat(interval)
-
 	pipeline = append(pipeline,
 		bson.D{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-				{Key: "format", Value: dateFormat},
-				{Key: "date", Value: "$timestamp"},
-				{Key: "timezone", Value: usageTimeZone(params)},
-			}}}},
+			{Key: "_id", Value: mongoPeriodExpr(interval, params)},
 			{Key: "requests", Value: bson.D{{Key: "$sum", Value: 1}}},
 			{Key: "input_tokens", Value: bson.D{{Key: "$sum", Value: "$input_tokens"}}},
 			{Key: "output_tokens", Value: bson.D{{Key: "$sum", Value: "$output_tokens"}}},
@@ -479,6 +711,107 @@ func (r *MongoDBReader) GetDailyUsage(ctx context.Context, params UsageQueryPara
 	return result, nil
 }
 
+// GetContentStats returns content analysis aggregates grouped by day, model,
+// provider and output language.
+func (r *MongoDBReader) GetContentStats(ctx context.Context, params UsageQueryParams) ([]ContentStats, error) {
+	matchFilters, err := mongoUsageMatchFilters(params)
+	if err != nil {
+		return nil, err
+	}
+	matchFilters = append(matchFilters, bson.E{Key: "content_language", Value: bson.D{{Key: "$nin", Value: bson.A{nil, ""}}}})
+
+	chars := bson.D{{Key: "$ifNull", Value: bson.A{"$content_chars", 0}}}
+	countWhere := func(condition bson.D) bson.D {
+		return bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{condition, 1, 0}}}}}
+	}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: matchFilters}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "date", Value: mongoPeriodExpr("daily", params)},
+				{Key: "model", Value: mongoUsageGroupedModelExpr(params.ModelBy)},
+				{Key: "provider", Value: "$provider"},
+				{Key: "provider_name", Value: mongoUsageGroupedProviderNameExpr()},
+				{Key: "language", Value: "$content_language"},
+			}},
+			{Key: "responses", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "total_chars", Value: bson.D{{Key: "$sum", Value: chars}}},
+			{Key: "total_words", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$content_words", 0}}}}}},
+			{Key: "max_chars", Value: bson.D{{Key: "$max", Value: chars}}},
+			{Key: "short", Value: countWhere(bson.D{{Key: "$lt", Value: bson.A{chars, contentShortChars}}})},
+			{Key: "medium", Value: countWhere(bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "$gte", Value: bson.A{chars, contentShortChars}}},
+				bson.D{{Key: "$lt", Value: bson.A{chars, contentMediumChars}}},
+			}}})},
+			{Key: "long", Value: countWhere(bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "$gte", Value: bson.A{chars, contentMediumChars}}},
+				bson.D{{Key: "$lt", Value: bson.A{chars, contentLongChars}}},
+			}}})},
+			{Key: "very_long", Value: countWhere(bson.D{{Key: "$gte", Value: bson.A{chars, contentLongChars}}})},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "_id.date", Value: 1},
+			{Key: "_id.model", Value: 1},
+			{Key: "_id.provider", Value: 1},
+			{Key: "_id.provider_name", Value: 1},
+			{Key: "_id.language", Value: 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate content stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]ContentStats, 0)
+	for cursor.Next(ctx) {
+		var row struct {
+			ID struct {
+				Date         string `bson:"date"`
+				Model        string `bson:"model"`
+				Provider     string `bson:"provider"`
+				ProviderName string `bson:"provider_name"`
+				Language     string `bson:"language"`
+			} `bson:"_id"`
+			Responses  int   `bson:"responses"`
+			TotalChars int64 `bson:"total_chars"`
+			TotalWords int64 `bson:"total_words"`
+			MaxChars   int   `bson:"max_chars"`
+			Short      int   `bson:"short"`
+			Medium     int   `bson:"medium"`
+			Long       int   `bson:"long"`
+			VeryLong   int   `bson:"very_long"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode content stats row: %w", err)
+		}
+		result = append(result, ContentStats{
+			Date:         row.ID.Date,
+			Model:        row.ID.Model,
+			Provider:     row.ID.Provider,
+			ProviderName: displayUsageProviderName(row.ID.ProviderName, row.ID.Provider),
+			Language:     row.ID.Language,
+			Responses:    row.Responses,
+			TotalChars:   row.TotalChars,
+			TotalWords:   row.TotalWords,
+			MaxChars:     row.MaxChars,
+			Lengths: ContentLengthBuckets{
+				Short:    row.Short,
+				Medium:   row.Medium,
+				Long:     row.Long,
+				VeryLong: row.VeryLong,
+			},
+		})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content stats cursor: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetCacheOverview returns cached-only aggregates for the admin dashboard.
 func (r *MongoDBReader) GetCacheOverview(ctx context.Context, params UsageQueryParams) (*CacheOverview, error) {
 	params.CacheMode = CacheModeCached
@@ -513,11 +846,7 @@ func (r *MongoDBReader) GetCacheOverview(ctx context.Context, params UsageQueryP
 		}},
 		{Key: "daily", Value: bson.A{
 			bson.D{{Key: "$group", Value: bson.D{
-				{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
-					{Key: "format", Value: mongoDateFormat(interval)},
-					{Key: "date", Value: "$timestamp"},
-					{Key: "timezone", Value: usageTimeZone(params)},
-				}}}},
+				{Key: "_id", Value: mongoPeriodExpr(interval, params)},
 				{Key: "hits", Value: bson.D{{Key: "$sum", Value: 1}}},
 				{Key: "exact_hits", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$cache_type", CacheTypeExact}}}, 1, 0}}}}}},
 				{Key: "semantic_hits", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$cond", Value: bson.A{bson.D{{Key: "$eq", Value: bson.A{"$cache_type", CacheTypeSemantic}}}, 1, 0}}}}}},
@@ -625,6 +954,16 @@ func mongoUsageMatchFilters(params UsageQueryParams) (bson.D, error) {
 	if filter := mongoCacheModeFilter(params.CacheMode); len(filter) > 0 {
 		matchFilters = append(matchFilters, filter...)
 	}
+	if params.AuthKeyID != "" {
+		matchFilters = append(matchFilters, bson.E{Key: "auth_key_id", Value: params.AuthKeyID})
+	}
+	labelFilters, err := normalizeUsageLabelFilters(params.Labels)
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range labelFilters {
+		matchFilters = append(matchFilters, bson.E{Key: "labels." + filter.Key, Value: filter.Value})
+	}
 	return matchFilters, nil
 }
 
@@ -643,6 +982,12 @@ func mongoUsageLogMatchFilters(params UsageLogParams) (bson.D, error) {
 			bson.D{{Key: "provider_name", Value: params.Provider}},
 		}}})
 	}
+	if params.UserHash != "" {
+		matchFilters = append(matchFilters, bson.E{Key: "user_hash", Value: params.UserHash})
+	}
+	if params.RequestID != "" {
+		matchFilters = append(matchFilters, bson.E{Key: "request_id", Value: params.RequestID})
+	}
 	if params.Search != "" {
 		regex := bson.D{{Key: "$regex", Value: regexp.QuoteMeta(params.Search)}, {Key: "$options", Value: "i"}}
 		searchFilter := bson.D{{Key: "$or", Value: bson.A{
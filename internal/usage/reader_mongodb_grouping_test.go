@@ -26,3 +26,32 @@ func TestMongoUsageGroupedProviderNameExpr_CollapsesBlankProviderName(t *testing
 		t.Fatalf("mongoUsageGroupedProviderNameExpr() = %#v, want %#v", got, want)
 	}
 }
+
+func TestMongoPeriodExpr_SundayWeeksShiftByOneLocalDay(t *testing.T) {
+	params := UsageQueryParams{TimeZone: "America/New_York", WeekStart: WeekStartSunday}
+
+	got := mongoPeriodExpr("weekly", params)
+	want := bson.D{{Key: "$dateToString", Value: bson.D{
+		{Key: "format", Value: "%G-W%V"},
+		{Key: "date", Value: bson.D{{Key: "$dateAdd", Value: bson.D{
+			{Key: "startDate", Value: "$timestamp"},
+			{Key: "unit", Value: "day"},
+			{Key: "amount", Value: 1},
+			{Key: "timezone", Value: "America/New_York"},
+		}}}},
+		{Key: "timezone", Value: "America/New_York"},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mongoPeriodExpr(weekly) = %#v, want %#v", got, want)
+	}
+
+	got = mongoPeriodExpr("daily", params)
+	want = bson.D{{Key: "$dateToString", Value: bson.D{
+		{Key: "format", Value: "%Y-%m-%d"},
+		{Key: "date", Value: "$timestamp"},
+		{Key: "timezone", Value: "America/New_York"},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mongoPeriodExpr(daily) = %#v, want %#v", got, want)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"gomodel/internal/core"
 )
 
 // PostgreSQLReader implements UsageReader for PostgreSQL databases.
@@ -55,10 +57,11 @@ func (r *PostgreSQLReader) GetUsageByModel(ctx context.Context, params UsageQuer
 	}
 	where := buildWhereClause(conditions)
 	providerNameExpr := usageGroupedProviderNameSQL("provider_name", "provider")
+	modelExpr := usageGroupedModelSQL(params.ModelBy)
 
 	costCols := `, SUM(input_cost), SUM(output_cost), SUM(total_cost)`
-	query := `SELECT model, provider, ` + providerNameExpr + ` AS provider_name, COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)` + costCols + `
-			FROM "usage"` + where + ` GROUP BY model, provider, ` + providerNameExpr
+	query := `SELECT ` + modelExpr + ` AS model, provider, ` + providerNameExpr + ` AS provider_name, COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)` + costCols + `
+			FROM "usage"` + where + ` GROUP BY ` + modelExpr + `, provider, ` + providerNameExpr
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -117,9 +120,95 @@ func (r *PostgreSQLReader) GetUsageByUserPath(ctx context.Context, params UsageQ
 	return result, nil
 }
 
+// GetUsageByExperiment returns request, token, and cost totals grouped by
+// experiment variant.
+func (r *PostgreSQLReader) GetUsageByExperiment(ctx context.Context, params UsageQueryParams) ([]ExperimentVariantUsage, error) {
+	conditions, args, _, err := pgUsageConditions(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, "experiment IS NOT NULL AND experiment <> ''")
+	where := buildWhereClause(conditions)
+
+	query := `SELECT experiment, COALESCE(experiment_variant, ''), COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(total_cost)
+			FROM "usage"` + where + ` GROUP BY experiment, experiment_variant ORDER BY experiment, experiment_variant`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage by experiment: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]ExperimentVariantUsage, 0)
+	for rows.Next() {
+		var u ExperimentVariantUsage
+		if err := rows.Scan(&u.Experiment, &u.Variant, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.TotalTokens, &u.TotalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage by experiment row: %w", err)
+		}
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by experiment rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetUsageByLabel returns request, token, and cost totals grouped by the value
+// of one request label. Requests without the label are grouped under "".
+func (r *PostgreSQLReader) GetUsageByLabel(ctx context.Context, params UsageQueryParams, label string) ([]LabelUsage, error) {
+	key, err := normalizeUsageLabelKey(label)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := pgUsageByLabelQuery(params, key)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLabelUsage(ctx, key, query, args)
+}
+
+// GetUsageByUser returns request, token, and cost totals grouped by the hash
+// of the user request field. Requests without a user are grouped under "".
+func (r *PostgreSQLReader) GetUsageByUser(ctx context.Context, params UsageQueryParams) ([]LabelUsage, error) {
+	query, args, err := pgUsageByUserQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLabelUsage(ctx, UserGroupLabel, query, args)
+}
+
+func (r *PostgreSQLReader) queryLabelUsage(ctx context.Context, label, query string, args []any) ([]LabelUsage, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage by %s: %w", label, err)
+	}
+	defer rows.Close()
+
+	result := make([]LabelUsage, 0)
+	for rows.Next() {
+		u := LabelUsage{Label: label}
+		if err := rows.Scan(&u.Value, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.TotalTokens, &u.InputCost, &u.OutputCost, &u.TotalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage by %s row: %w", label, err)
+		}
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by %s rows: %w", label, err)
+	}
+
+	return result, nil
+}
+
 // GetUsageLog returns a paginated list of individual usage log entries.
 func (r *PostgreSQLReader) GetUsageLog(ctx context.Context, params UsageLogParams) (*UsageLogResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	cursor, filter, err := usageLogCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	conditions, args, argIdx, err := pgUsageConditions(params.UsageQueryParams, 1)
 	if err != nil {
@@ -136,6 +225,16 @@ func (r *PostgreSQLReader) GetUsageLog(ctx context.Context, params UsageLogParam
 		args = append(args, params.Provider, params.Provider)
 		argIdx += 2
 	}
+	if params.UserHash != "" {
+		conditions = append(conditions, fmt.Sprintf("user_hash = $%d", argIdx))
+		args = append(args, params.UserHash)
+		argIdx++
+	}
+	if params.RequestID != "" {
+		conditions = append(conditions, fmt.Sprintf("request_id = $%d", argIdx))
+		args = append(args, params.RequestID)
+		argIdx++
+	}
 	if params.Search != "" {
 		s := "%" + escapeLikeWildcards(params.Search) + "%"
 		conditions = append(conditions, fmt.Sprintf("(model ILIKE $%d ESCAPE '\\' OR provider ILIKE $%d ESCAPE '\\' OR provider_name ILIKE $%d ESCAPE '\\' OR request_id ILIKE $%d ESCAPE '\\' OR provider_id ILIKE $%d ESCAPE '\\')", argIdx, argIdx, argIdx, argIdx, argIdx))
@@ -153,10 +252,21 @@ func (r *PostgreSQLReader) GetUsageLog(ctx context.Context, params UsageLogParam
 	}
 
 	// Fetch page
-	dataQuery := fmt.Sprintf(`SELECT id, request_id, provider_id, timestamp, model, provider, provider_name, endpoint, user_path, cache_type,
-		input_tokens, output_tokens, total_tokens, COALESCE(input_cost, 0), COALESCE(output_cost, 0), COALESCE(total_cost, 0), raw_data, COALESCE(costs_calculation_caveat, '')
-		FROM "usage"%s ORDER BY timestamp DESC LIMIT $%d OFFSET $%d`, where, argIdx, argIdx+1)
-	dataArgs := append(append([]any(nil), args...), limit, offset)
+	dataWhere, dataArgs := where, append([]any(nil), args...)
+	if cursor != nil {
+		cursorTime, err := time.Parse(time.RFC3339Nano, cursor.Key)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid cursor", err)
+		}
+		offset = 0
+		dataWhere = buildWhereClause(append(append([]string(nil), conditions...), fmt.Sprintf("(timestamp, id) < ($%d, $%d)", argIdx, argIdx+1)))
+		dataArgs = append(dataArgs, cursorTime, cursor.ID)
+		argIdx += 2
+	}
+	dataQuery := fmt.Sprintf(`SELECT id, request_id, provider_id, timestamp, model, provider, provider_name, COALESCE(requested_model, ''), COALESCE(served_model, ''), endpoint, user_path, cache_type,
+		input_tokens, output_tokens, total_tokens, COALESCE(image_count, 0), COALESCE(image_bytes, 0), COALESCE(template_name, ''), COALESCE(template_version, 0), COALESCE(user_hash, ''), COALESCE(raw_user, ''), COALESCE(auth_key_id, ''), COALESCE(content_language, ''), COALESCE(content_chars, 0), COALESCE(content_words, 0), COALESCE(input_cost, 0), COALESCE(output_cost, 0), COALESCE(total_cost, 0), raw_data, labels, COALESCE(costs_calculation_caveat, '')
+		FROM "usage"%s ORDER BY timestamp DESC, id DESC LIMIT $%d OFFSET $%d`, dataWhere, argIdx, argIdx+1)
+	dataArgs = append(dataArgs, limit+1, offset)
 
 	rows, err := r.pool.Query(ctx, dataQuery, dataArgs...)
 	if err != nil {
@@ -165,19 +275,26 @@ func (r *PostgreSQLReader) GetUsageLog(ctx context.Context, params UsageLogParam
 	defer rows.Close()
 
 	entries := make([]UsageLogEntry, 0)
+	var keys []string
 	for rows.Next() {
 		var e UsageLogEntry
 		var rawDataJSON *string
+		var labelsJSON *string
 		var providerName *string
 		var userPath *string
 		var cacheType *string
-		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProviderID, &e.Timestamp, &e.Model, &e.Provider, &providerName, &e.Endpoint, &userPath, &cacheType,
-			&e.InputTokens, &e.OutputTokens, &e.TotalTokens, &e.InputCost, &e.OutputCost, &e.TotalCost, &rawDataJSON, &e.CostsCalculationCaveat); err != nil {
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProviderID, &e.Timestamp, &e.Model, &e.Provider, &providerName, &e.RequestedModel, &e.ServedModel, &e.Endpoint, &userPath, &cacheType,
+			&e.InputTokens, &e.OutputTokens, &e.TotalTokens, &e.ImageCount, &e.ImageBytes, &e.TemplateName, &e.TemplateVersion, &e.UserHash, &e.User, &e.AuthKeyID, &e.ContentLanguage, &e.ContentChars, &e.ContentWords, &e.InputCost, &e.OutputCost, &e.TotalCost, &rawDataJSON, &labelsJSON, &e.CostsCalculationCaveat); err != nil {
 			return nil, fmt.Errorf("failed to scan usage log row: %w", err)
 		}
 		if rawDataJSON != nil && *rawDataJSON != "" {
 			if err := json.Unmarshal([]byte(*rawDataJSON), &e.RawData); err != nil {
-				slog.Warn("failed to unmarshal raw_data JSON", "request_id", e.RequestID, "error", err)
+				usageLogger.Warn("failed to unmarshal raw_data JSON", "request_id", e.RequestID, "error", err)
+			}
+		}
+		if labelsJSON != nil && *labelsJSON != "" {
+			if err := json.Unmarshal([]byte(*labelsJSON), &e.Labels); err != nil {
+				usageLogger.Warn("failed to unmarshal labels JSON", "request_id", e.RequestID, "error", err)
 			}
 		}
 		if userPath != nil {
@@ -192,17 +309,20 @@ func (r *PostgreSQLReader) GetUsageLog(ctx context.Context, params UsageLogParam
 			e.CacheType = normalizeCacheType(*cacheType)
 		}
 		entries = append(entries, e)
+		keys = append(keys, e.Timestamp.UTC().Format(time.RFC3339Nano))
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating usage log rows: %w", err)
 	}
 
+	entries, next := nextUsageLogCursor(entries, keys, limit, filter)
 	return &UsageLogResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
@@ -223,11 +343,14 @@ func pgDateRangeConditions(params UsageQueryParams, argIdx int) (conditions []st
 	return conditions, args, nextIdx
 }
 
-func pgGroupExpr(interval string, timeZone string) string {
+func pgGroupExpr(interval string, timeZone string, sundayWeeks bool) string {
 	zoneLiteral := pgQuoteLiteral(timeZone)
 
 	switch interval {
 	case "weekly":
+		if sundayWeeks {
+			return fmt.Sprintf(`to_char(DATE_TRUNC('week', (timestamp AT TIME ZONE %s) + INTERVAL '1 day'), 'IYYY-"W"IW')`, zoneLiteral)
+		}
 		return fmt.Sprintf(`to_char(DATE_TRUNC('week', timestamp AT TIME ZONE %s), 'IYYY-"W"IW')`, zoneLiteral)
 	case "monthly":
 		return fmt.Sprintf(`to_char(DATE_TRUNC('month', timestamp AT TIME ZONE %s), 'YYYY-MM')`, zoneLiteral)
@@ -244,7 +367,7 @@ func (r *PostgreSQLReader) GetDailyUsage(ctx context.Context, params UsageQueryP
 	if interval == "" {
 		interval = "daily"
 	}
-	groupExpr := pgGroupExpr(interval, usageTimeZone(params))
+	groupExpr := pgGroupExpr(interval, usageTimeZone(params), usageSundayWeeks(params))
 
 	conditions, args, _, err := pgUsageConditions(params, 1)
 	if err != nil {
@@ -278,6 +401,50 @@ func (r *PostgreSQLReader) GetDailyUsage(ctx context.Context, params UsageQueryP
 	return result, nil
 }
 
+// GetContentStats returns content analysis aggregates grouped by day, model,
+// provider and output language.
+func (r *PostgreSQLReader) GetContentStats(ctx context.Context, params UsageQueryParams) ([]ContentStats, error) {
+	groupExpr := pgGroupExpr("daily", usageTimeZone(params), false)
+
+	conditions, args, _, err := pgUsageConditions(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, contentAnalyzedSQL)
+	where := buildWhereClause(conditions)
+	providerNameExpr := usageGroupedProviderNameSQL("provider_name", "provider")
+	modelExpr := usageGroupedModelSQL(params.ModelBy)
+
+	query := `SELECT ` + groupExpr + ` AS period, ` + modelExpr + ` AS model, provider, ` + providerNameExpr + ` AS provider_name, content_language, COUNT(*),
+		COALESCE(SUM(content_chars), 0), COALESCE(SUM(content_words), 0), COALESCE(MAX(content_chars), 0),
+		` + contentLengthBucketsSQL() + `
+		FROM "usage"` + where + ` GROUP BY ` + groupExpr + `, ` + modelExpr + `, provider, ` + providerNameExpr + `, content_language
+		ORDER BY 1, 2, 3, 4, 5`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]ContentStats, 0)
+	for rows.Next() {
+		var c ContentStats
+		if err := rows.Scan(&c.Date, &c.Model, &c.Provider, &c.ProviderName, &c.Language, &c.Responses,
+			&c.TotalChars, &c.TotalWords, &c.MaxChars,
+			&c.Lengths.Short, &c.Lengths.Medium, &c.Lengths.Long, &c.Lengths.VeryLong); err != nil {
+			return nil, fmt.Errorf("failed to scan content stats row: %w", err)
+		}
+		result = append(result, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content stats rows: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetCacheOverview returns cached-only aggregates for the admin dashboard.
 func (r *PostgreSQLReader) GetCacheOverview(ctx context.Context, params UsageQueryParams) (*CacheOverview, error) {
 	params.CacheMode = CacheModeCached
@@ -315,7 +482,7 @@ func (r *PostgreSQLReader) GetCacheOverview(ctx context.Context, params UsageQue
 	if interval == "" {
 		interval = "daily"
 	}
-	groupExpr := pgGroupExpr(interval, usageTimeZone(params))
+	groupExpr := pgGroupExpr(interval, usageTimeZone(params), usageSundayWeeks(params))
 	dailyQuery := fmt.Sprintf(`SELECT %s as period,
 		COUNT(*),
 		COALESCE(SUM(CASE WHEN cache_type = $1 THEN 1 ELSE 0 END), 0),
@@ -365,6 +532,20 @@ func pgUsageConditions(params UsageQueryParams, argIdx int) (conditions []string
 	if condition := pgCacheModeCondition(params.CacheMode); condition != "" {
 		conditions = append(conditions, condition)
 	}
+	if params.AuthKeyID != "" {
+		conditions = append(conditions, fmt.Sprintf("auth_key_id = $%d", nextIdx))
+		args = append(args, params.AuthKeyID)
+		nextIdx++
+	}
+	labelFilters, err := normalizeUsageLabelFilters(params.Labels)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	for _, filter := range labelFilters {
+		conditions = append(conditions, fmt.Sprintf("labels ->> $%d = $%d", nextIdx, nextIdx+1))
+		args = append(args, filter.Key, filter.Value)
+		nextIdx += 2
+	}
 	return conditions, args, nextIdx, nil
 }
 
@@ -382,9 +563,49 @@ func pgUsageByUserPathConditions(params UsageQueryParams, userPathExpr string, a
 	if condition := pgCacheModeCondition(params.CacheMode); condition != "" {
 		conditions = append(conditions, condition)
 	}
+	if params.AuthKeyID != "" {
+		conditions = append(conditions, fmt.Sprintf("auth_key_id = $%d", nextIdx))
+		args = append(args, params.AuthKeyID)
+		nextIdx++
+	}
+	labelFilters, err := normalizeUsageLabelFilters(params.Labels)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	for _, filter := range labelFilters {
+		conditions = append(conditions, fmt.Sprintf("labels ->> $%d = $%d", nextIdx, nextIdx+1))
+		args = append(args, filter.Key, filter.Value)
+		nextIdx += 2
+	}
 	return conditions, args, nextIdx, nil
 }
 
+// pgUsageByLabelQuery builds the label grouping query for a validated label
+// key. The key is bound as $1.
+func pgUsageByLabelQuery(params UsageQueryParams, key string) (string, []any, error) {
+	conditions, args, _, err := pgUsageConditions(params, 2)
+	if err != nil {
+		return "", nil, err
+	}
+	where := buildWhereClause(conditions)
+
+	query := `SELECT COALESCE(labels ->> $1, '') AS label_value, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(input_cost), SUM(output_cost), SUM(total_cost)
+			FROM "usage"` + where + ` GROUP BY label_value ORDER BY label_value`
+	return query, append([]any{key}, args...), nil
+}
+
+func pgUsageByUserQuery(params UsageQueryParams) (string, []any, error) {
+	conditions, args, _, err := pgUsageConditions(params, 1)
+	if err != nil {
+		return "", nil, err
+	}
+	where := buildWhereClause(conditions)
+
+	query := `SELECT COALESCE(user_hash, '') AS user_value, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(input_cost), SUM(output_cost), SUM(total_cost)
+			FROM "usage"` + where + ` GROUP BY user_value ORDER BY user_value`
+	return query, args, nil
+}
+
 func pgCacheModeCondition(mode string) string {
 	switch normalizeCacheMode(mode) {
 	case CacheModeCached:
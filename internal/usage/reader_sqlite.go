@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log/slog"
+	"strconv"
 	"strings"
 	"time"
+
+	"gomodel/internal/core"
 )
 
 // SQLiteReader implements UsageReader for SQLite databases.
@@ -55,10 +57,11 @@ func (r *SQLiteReader) GetUsageByModel(ctx context.Context, params UsageQueryPar
 	}
 	where := buildWhereClause(conditions)
 	providerNameExpr := usageGroupedProviderNameSQL("provider_name", "provider")
+	modelExpr := usageGroupedModelSQL(params.ModelBy)
 
 	costCols := `, SUM(input_cost), SUM(output_cost), SUM(total_cost)`
-	query := `SELECT model, provider, ` + providerNameExpr + ` AS provider_name, COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)` + costCols + `
-			FROM usage` + where + ` GROUP BY model, provider, ` + providerNameExpr
+	query := `SELECT ` + modelExpr + ` AS model, provider, ` + providerNameExpr + ` AS provider_name, COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)` + costCols + `
+			FROM usage` + where + ` GROUP BY ` + modelExpr + `, provider, ` + providerNameExpr
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -117,9 +120,95 @@ func (r *SQLiteReader) GetUsageByUserPath(ctx context.Context, params UsageQuery
 	return result, nil
 }
 
+// GetUsageByExperiment returns request, token, and cost totals grouped by
+// experiment variant.
+func (r *SQLiteReader) GetUsageByExperiment(ctx context.Context, params UsageQueryParams) ([]ExperimentVariantUsage, error) {
+	conditions, args, err := sqliteUsageConditions(params)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, "experiment IS NOT NULL AND experiment != ''")
+	where := buildWhereClause(conditions)
+
+	query := `SELECT experiment, COALESCE(experiment_variant, ''), COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(total_cost)
+			FROM usage` + where + ` GROUP BY experiment, experiment_variant ORDER BY experiment, experiment_variant`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage by experiment: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]ExperimentVariantUsage, 0)
+	for rows.Next() {
+		var u ExperimentVariantUsage
+		if err := rows.Scan(&u.Experiment, &u.Variant, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.TotalTokens, &u.TotalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage by experiment row: %w", err)
+		}
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by experiment rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetUsageByLabel returns request, token, and cost totals grouped by the value
+// of one request label. Requests without the label are grouped under "".
+func (r *SQLiteReader) GetUsageByLabel(ctx context.Context, params UsageQueryParams, label string) ([]LabelUsage, error) {
+	key, err := normalizeUsageLabelKey(label)
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := sqliteUsageByLabelQuery(params, key)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLabelUsage(ctx, key, query, args)
+}
+
+// GetUsageByUser returns request, token, and cost totals grouped by the hash
+// of the user request field. Requests without a user are grouped under "".
+func (r *SQLiteReader) GetUsageByUser(ctx context.Context, params UsageQueryParams) ([]LabelUsage, error) {
+	query, args, err := sqliteUsageByUserQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryLabelUsage(ctx, UserGroupLabel, query, args)
+}
+
+func (r *SQLiteReader) queryLabelUsage(ctx context.Context, label, query string, args []any) ([]LabelUsage, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage by %s: %w", label, err)
+	}
+	defer rows.Close()
+
+	result := make([]LabelUsage, 0)
+	for rows.Next() {
+		u := LabelUsage{Label: label}
+		if err := rows.Scan(&u.Value, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.TotalTokens, &u.InputCost, &u.OutputCost, &u.TotalCost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage by %s row: %w", label, err)
+		}
+		result = append(result, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage by %s rows: %w", label, err)
+	}
+
+	return result, nil
+}
+
 // GetUsageLog returns a paginated list of individual usage log entries.
 func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (*UsageLogResult, error) {
 	limit, offset := clampLimitOffset(params.Limit, params.Offset)
+	cursor, filter, err := usageLogCursor(params)
+	if err != nil {
+		return nil, err
+	}
 
 	conditions, args, err := sqliteUsageConditions(params.UsageQueryParams)
 	if err != nil {
@@ -134,6 +223,14 @@ func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (
 		conditions = append(conditions, "(provider = ? OR provider_name = ?)")
 		args = append(args, params.Provider, params.Provider)
 	}
+	if params.UserHash != "" {
+		conditions = append(conditions, "user_hash = ?")
+		args = append(args, params.UserHash)
+	}
+	if params.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, params.RequestID)
+	}
 	if params.Search != "" {
 		conditions = append(conditions, "(model LIKE ? ESCAPE '\\' OR provider LIKE ? ESCAPE '\\' OR provider_name LIKE ? ESCAPE '\\' OR request_id LIKE ? ESCAPE '\\' OR provider_id LIKE ? ESCAPE '\\')")
 		s := "%" + escapeLikeWildcards(params.Search) + "%"
@@ -149,11 +246,23 @@ func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (
 		return nil, fmt.Errorf("failed to count usage log entries: %w", err)
 	}
 
-	// Fetch page
-	dataQuery := `SELECT id, request_id, provider_id, timestamp, model, provider, provider_name, endpoint, user_path, cache_type,
-		input_tokens, output_tokens, total_tokens, COALESCE(input_cost, 0), COALESCE(output_cost, 0), COALESCE(total_cost, 0), raw_data, COALESCE(costs_calculation_caveat, '')
-		FROM usage` + where + ` ORDER BY ` + sqliteTimestampEpochExpr() + ` DESC, id DESC LIMIT ? OFFSET ?`
-	dataArgs := append(append([]any(nil), args...), limit, offset)
+	// Fetch page. Keyset pagination compares the epoch seconds the page is
+	// ordered by.
+	dataWhere, dataArgs := where, append([]any(nil), args...)
+	if cursor != nil {
+		epoch, err := strconv.ParseInt(cursor.Key, 10, 64)
+		if err != nil {
+			return nil, core.NewInvalidRequestError("invalid cursor", err)
+		}
+		offset = 0
+		epochExpr := sqliteTimestampEpochExpr()
+		dataWhere = buildWhereClause(append(append([]string(nil), conditions...), "("+epochExpr+" < ? OR ("+epochExpr+" = ? AND id < ?))"))
+		dataArgs = append(dataArgs, epoch, epoch, cursor.ID)
+	}
+	dataQuery := `SELECT id, request_id, provider_id, timestamp, model, provider, provider_name, COALESCE(requested_model, ''), COALESCE(served_model, ''), endpoint, user_path, cache_type,
+		input_tokens, output_tokens, total_tokens, COALESCE(image_count, 0), COALESCE(image_bytes, 0), COALESCE(template_name, ''), COALESCE(template_version, 0), COALESCE(user_hash, ''), COALESCE(raw_user, ''), COALESCE(auth_key_id, ''), COALESCE(content_language, ''), COALESCE(content_chars, 0), COALESCE(content_words, 0), COALESCE(input_cost, 0), COALESCE(output_cost, 0), COALESCE(total_cost, 0), raw_data, labels, COALESCE(costs_calculation_caveat, ''), COALESCE(` + sqliteTimestampEpochExpr() + `, 0)
+		FROM usage` + dataWhere + ` ORDER BY ` + sqliteTimestampEpochExpr() + ` DESC, id DESC LIMIT ? OFFSET ?`
+	dataArgs = append(dataArgs, limit+1, offset)
 
 	rows, err := r.db.QueryContext(ctx, dataQuery, dataArgs...)
 	if err != nil {
@@ -162,16 +271,19 @@ func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (
 	defer rows.Close()
 
 	entries := make([]UsageLogEntry, 0)
+	var keys []string
 	for rows.Next() {
 		var e UsageLogEntry
 		var ts string
+		var epoch int64
 		var caveat *string
 		var rawDataJSON *string
+		var labelsJSON *string
 		var providerName sql.NullString
 		var userPath sql.NullString
 		var cacheType sql.NullString
-		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProviderID, &ts, &e.Model, &e.Provider, &providerName, &e.Endpoint, &userPath, &cacheType,
-			&e.InputTokens, &e.OutputTokens, &e.TotalTokens, &e.InputCost, &e.OutputCost, &e.TotalCost, &rawDataJSON, &caveat); err != nil {
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.ProviderID, &ts, &e.Model, &e.Provider, &providerName, &e.RequestedModel, &e.ServedModel, &e.Endpoint, &userPath, &cacheType,
+			&e.InputTokens, &e.OutputTokens, &e.TotalTokens, &e.ImageCount, &e.ImageBytes, &e.TemplateName, &e.TemplateVersion, &e.UserHash, &e.User, &e.AuthKeyID, &e.ContentLanguage, &e.ContentChars, &e.ContentWords, &e.InputCost, &e.OutputCost, &e.TotalCost, &rawDataJSON, &labelsJSON, &caveat, &epoch); err != nil {
 			return nil, fmt.Errorf("failed to scan usage log row: %w", err)
 		}
 		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
@@ -181,11 +293,16 @@ func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (
 		} else if t, err := time.Parse("2006-01-02T15:04:05Z", ts); err == nil {
 			e.Timestamp = t
 		} else {
-			slog.Warn("failed to parse timestamp", "request_id", e.RequestID, "raw_timestamp", ts)
+			usageLogger.Warn("failed to parse timestamp", "request_id", e.RequestID, "raw_timestamp", ts)
 		}
 		if rawDataJSON != nil && *rawDataJSON != "" {
 			if err := json.Unmarshal([]byte(*rawDataJSON), &e.RawData); err != nil {
-				slog.Warn("failed to unmarshal raw_data JSON", "request_id", e.RequestID, "error", err)
+				usageLogger.Warn("failed to unmarshal raw_data JSON", "request_id", e.RequestID, "error", err)
+			}
+		}
+		if labelsJSON != nil && *labelsJSON != "" {
+			if err := json.Unmarshal([]byte(*labelsJSON), &e.Labels); err != nil {
+				usageLogger.Warn("failed to unmarshal labels JSON", "request_id", e.RequestID, "error", err)
 			}
 		}
 		if userPath.Valid {
@@ -203,17 +320,20 @@ func (r *SQLiteReader) GetUsageLog(ctx context.Context, params UsageLogParams) (
 			e.CostsCalculationCaveat = *caveat
 		}
 		entries = append(entries, e)
+		keys = append(keys, strconv.FormatInt(epoch, 10))
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating usage log rows: %w", err)
 	}
 
+	entries, next := nextUsageLogCursor(entries, keys, limit, filter)
 	return &UsageLogResult{
-		Entries: entries,
-		Total:   total,
-		Limit:   limit,
-		Offset:  offset,
+		Entries:    entries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: next,
 	}, nil
 }
 
@@ -240,35 +360,29 @@ func sqliteDateRangeConditions(params UsageQueryParams) (conditions []string, ar
 	return conditions, args
 }
 
-func sqliteGroupExpr(interval string) string {
-	return sqliteGroupExprWithOffset(interval, 0)
+func sqliteGroupExpr(interval string, sundayWeeks bool) string {
+	return sqliteGroupExprWithOffset(interval, 0, sundayWeeks)
 }
 
-func sqliteGroupExprWithOffset(interval string, offsetMinutes int) string {
-	modifier := sqliteOffsetModifier(offsetMinutes)
+func sqliteGroupExprWithOffset(interval string, offsetMinutes int, sundayWeeks bool) string {
+	modifiers := ""
+	if modifier := sqliteOffsetModifier(offsetMinutes); modifier != "" {
+		modifiers = fmt.Sprintf(", '%s'", modifier)
+	}
 	timestampExpr := sqliteTimestampTextExpr()
 
 	switch interval {
 	case "weekly":
-		if modifier == "" {
-			return fmt.Sprintf(`strftime('%%G-W%%V', %s)`, timestampExpr)
+		if sundayWeeks {
+			modifiers += ", '+1 day'"
 		}
-		return fmt.Sprintf(`strftime('%%G-W%%V', %s, '%s')`, timestampExpr, modifier)
+		return fmt.Sprintf(`strftime('%%G-W%%V', %s%s)`, timestampExpr, modifiers)
 	case "monthly":
-		if modifier == "" {
-			return fmt.Sprintf(`strftime('%%Y-%%m', %s)`, timestampExpr)
-		}
-		return fmt.Sprintf(`strftime('%%Y-%%m', %s, '%s')`, timestampExpr, modifier)
+		return fmt.Sprintf(`strftime('%%Y-%%m', %s%s)`, timestampExpr, modifiers)
 	case "yearly":
-		if modifier == "" {
-			return fmt.Sprintf(`strftime('%%Y', %s)`, timestampExpr)
-		}
-		return fmt.Sprintf(`strftime('%%Y', %s, '%s')`, timestampExpr, modifier)
+		return fmt.Sprintf(`strftime('%%Y', %s%s)`, timestampExpr, modifiers)
 	default:
-		if modifier == "" {
-			return fmt.Sprintf(`DATE(%s)`, timestampExpr)
-		}
-		return fmt.Sprintf(`DATE(%s, '%s')`, timestampExpr, modifier)
+		return fmt.Sprintf(`DATE(%s%s)`, timestampExpr, modifiers)
 	}
 }
 
@@ -318,6 +432,61 @@ func (r *SQLiteReader) GetDailyUsage(ctx context.Context, params UsageQueryParam
 	return result, nil
 }
 
+// GetContentStats returns content analysis aggregates grouped by day, model,
+// provider and output language.
+func (r *SQLiteReader) GetContentStats(ctx context.Context, params UsageQueryParams) ([]ContentStats, error) {
+	params.Interval = "daily"
+	groupExpr, groupArgs, err := r.sqliteGroupExpr(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, args, err := sqliteUsageConditions(params)
+	if err != nil {
+		return nil, err
+	}
+	conditions = append(conditions, contentAnalyzedSQL)
+	where := buildWhereClause(conditions)
+	providerNameExpr := usageGroupedProviderNameSQL("provider_name", "provider")
+	modelExpr := usageGroupedModelSQL(params.ModelBy)
+
+	query := `WITH content_periods AS (
+		SELECT ` + groupExpr + ` AS period, ` + modelExpr + ` AS model, provider, ` + providerNameExpr + ` AS provider_name,
+			content_language, content_chars, content_words
+		FROM usage` + where + `
+	)
+	SELECT period, model, provider, provider_name, content_language, COUNT(*),
+		COALESCE(SUM(content_chars), 0), COALESCE(SUM(content_words), 0), COALESCE(MAX(content_chars), 0),
+		` + contentLengthBucketsSQL() + `
+		FROM content_periods GROUP BY period, model, provider, provider_name, content_language
+		ORDER BY period, model, provider, provider_name, content_language`
+
+	queryArgs := append(groupArgs, args...)
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]ContentStats, 0)
+	for rows.Next() {
+		var c ContentStats
+		if err := rows.Scan(&c.Date, &c.Model, &c.Provider, &c.ProviderName, &c.Language, &c.Responses,
+			&c.TotalChars, &c.TotalWords, &c.MaxChars,
+			&c.Lengths.Short, &c.Lengths.Medium, &c.Lengths.Long, &c.Lengths.VeryLong); err != nil {
+			return nil, fmt.Errorf("failed to scan content stats row: %w", err)
+		}
+		result = append(result, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content stats rows: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetCacheOverview returns cached-only aggregates for the admin dashboard.
 func (r *SQLiteReader) GetCacheOverview(ctx context.Context, params UsageQueryParams) (*CacheOverview, error) {
 	params.CacheMode = CacheModeCached
@@ -411,6 +580,18 @@ func sqliteUsageConditions(params UsageQueryParams) ([]string, []any, error) {
 	if condition := sqliteCacheModeCondition(params.CacheMode); condition != "" {
 		conditions = append(conditions, condition)
 	}
+	if params.AuthKeyID != "" {
+		conditions = append(conditions, "auth_key_id = ?")
+		args = append(args, params.AuthKeyID)
+	}
+	labelFilters, err := normalizeUsageLabelFilters(params.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, filter := range labelFilters {
+		conditions = append(conditions, "json_extract(labels, ?) = ?")
+		args = append(args, sqliteLabelJSONPath(filter.Key), filter.Value)
+	}
 	return conditions, args, nil
 }
 
@@ -427,9 +608,47 @@ func sqliteUsageByUserPathConditions(params UsageQueryParams, userPathExpr strin
 	if condition := sqliteCacheModeCondition(params.CacheMode); condition != "" {
 		conditions = append(conditions, condition)
 	}
+	if params.AuthKeyID != "" {
+		conditions = append(conditions, "auth_key_id = ?")
+		args = append(args, params.AuthKeyID)
+	}
+	labelFilters, err := normalizeUsageLabelFilters(params.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, filter := range labelFilters {
+		conditions = append(conditions, "json_extract(labels, ?) = ?")
+		args = append(args, sqliteLabelJSONPath(filter.Key), filter.Value)
+	}
 	return conditions, args, nil
 }
 
+// sqliteUsageByLabelQuery builds the label grouping query for a validated
+// label key.
+func sqliteUsageByLabelQuery(params UsageQueryParams, key string) (string, []any, error) {
+	conditions, args, err := sqliteUsageConditions(params)
+	if err != nil {
+		return "", nil, err
+	}
+	where := buildWhereClause(conditions)
+
+	query := `SELECT COALESCE(json_extract(labels, ?), '') AS label_value, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(input_cost), SUM(output_cost), SUM(total_cost)
+			FROM usage` + where + ` GROUP BY label_value ORDER BY label_value`
+	return query, append([]any{sqliteLabelJSONPath(key)}, args...), nil
+}
+
+func sqliteUsageByUserQuery(params UsageQueryParams) (string, []any, error) {
+	conditions, args, err := sqliteUsageConditions(params)
+	if err != nil {
+		return "", nil, err
+	}
+	where := buildWhereClause(conditions)
+
+	query := `SELECT COALESCE(user_hash, '') AS user_value, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(input_cost), SUM(output_cost), SUM(total_cost)
+			FROM usage` + where + ` GROUP BY user_value ORDER BY user_value`
+	return query, args, nil
+}
+
 func sqliteCacheModeCondition(mode string) string {
 	switch normalizeCacheMode(mode) {
 	case CacheModeCached:
@@ -451,10 +670,11 @@ func (r *SQLiteReader) sqliteGroupExpr(ctx context.Context, params UsageQueryPar
 	if interval == "" {
 		interval = "daily"
 	}
+	sundayWeeks := usageSundayWeeks(params)
 
 	location := usageLocation(params)
 	if location == time.UTC {
-		return sqliteGroupExpr(interval), nil, nil
+		return sqliteGroupExpr(interval, sundayWeeks), nil, nil
 	}
 
 	rangeStart, rangeEnd, ok, err := r.sqliteGroupingRange(ctx, params)
@@ -462,22 +682,22 @@ func (r *SQLiteReader) sqliteGroupExpr(ctx context.Context, params UsageQueryPar
 		return "", nil, err
 	}
 	if !ok {
-		return sqliteGroupExpr(interval), nil, nil
+		return sqliteGroupExpr(interval, sundayWeeks), nil, nil
 	}
 
 	segments := sqliteTimeZoneSegments(rangeStart, rangeEnd, location)
 	if len(segments) == 0 {
-		return sqliteGroupExpr(interval), nil, nil
+		return sqliteGroupExpr(interval, sundayWeeks), nil, nil
 	}
 	if len(segments) == 1 {
-		return sqliteGroupExprWithOffset(interval, segments[0].OffsetMinutes), nil, nil
+		return sqliteGroupExprWithOffset(interval, segments[0].OffsetMinutes, sundayWeeks), nil, nil
 	}
 
 	var builder strings.Builder
 	args := make([]any, 0, len(segments)-1)
 	builder.WriteString("CASE")
 	for _, segment := range segments {
-		expr := sqliteGroupExprWithOffset(interval, segment.OffsetMinutes)
+		expr := sqliteGroupExprWithOffset(interval, segment.OffsetMinutes, sundayWeeks)
 		if segment.Until.IsZero() {
 			builder.WriteString(" ELSE ")
 			builder.WriteString(expr)
@@ -510,6 +730,10 @@ func (r *SQLiteReader) sqliteGroupingRange(ctx context.Context, params UsageQuer
 		conditions = append(conditions, "(user_path = ? OR user_path LIKE ? ESCAPE '\\')")
 		args = append(args, userPath, usageUserPathSubtreePattern(userPath))
 	}
+	if params.AuthKeyID != "" {
+		conditions = append(conditions, "auth_key_id = ?")
+		args = append(args, params.AuthKeyID)
+	}
 	query := `SELECT MIN(` + sqliteTimestampEpochExpr() + `), MAX(` + sqliteTimestampEpochExpr() + `) FROM usage` + buildWhereClause(conditions)
 	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&minTS, &maxTS); err != nil {
 		return time.Time{}, time.Time{}, false, fmt.Errorf("failed to determine sqlite usage range: %w", err)
@@ -559,6 +559,94 @@ func TestSQLiteReaderGetUsageByModel_CollapsesBlankProviderNameIntoProviderGroup
 	}
 }
 
+func TestSQLiteReaderGetUsageByModel_GroupsByServedOrRequestedModel(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		{
+			ID:             "usage-1",
+			RequestID:      "req-1",
+			Timestamp:      time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC),
+			Model:          "gpt-4o",
+			RequestedModel: "smart",
+			ServedModel:    "gpt-4o-2024-08-06",
+			Provider:       "openai",
+			Endpoint:       "/v1/chat/completions",
+			InputTokens:    10,
+		},
+		{
+			ID:             "usage-2",
+			RequestID:      "req-2",
+			Timestamp:      time.Date(2026, 4, 7, 10, 1, 0, 0, time.UTC),
+			Model:          "gpt-4o",
+			RequestedModel: "gpt-4o",
+			ServedModel:    "gpt-4o-2024-08-06",
+			Provider:       "openai",
+			Endpoint:       "/v1/chat/completions",
+			InputTokens:    20,
+		},
+		{
+			ID:          "legacy",
+			RequestID:   "req-3",
+			Timestamp:   time.Date(2026, 4, 7, 10, 2, 0, 0, time.UTC),
+			Model:       "gpt-4o",
+			Provider:    "openai",
+			Endpoint:    "/v1/chat/completions",
+			InputTokens: 40,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	tokensByModel := func(modelBy string) map[string]int64 {
+		t.Helper()
+		rows, err := reader.GetUsageByModel(ctx, UsageQueryParams{ModelBy: modelBy})
+		if err != nil {
+			t.Fatalf("GetUsageByModel(%q) returned error: %v", modelBy, err)
+		}
+		got := make(map[string]int64, len(rows))
+		for _, row := range rows {
+			got[row.Model] = row.InputTokens
+		}
+		return got
+	}
+
+	served := tokensByModel("")
+	if len(served) != 2 || served["gpt-4o-2024-08-06"] != 30 || served["gpt-4o"] != 40 {
+		t.Fatalf("served grouping = %v, want gpt-4o-2024-08-06:30 gpt-4o:40", served)
+	}
+	requested := tokensByModel(ModelByRequested)
+	if len(requested) != 2 || requested["smart"] != 10 || requested["gpt-4o"] != 60 {
+		t.Fatalf("requested grouping = %v, want smart:10 gpt-4o:60", requested)
+	}
+
+	log, err := reader.GetUsageLog(ctx, UsageLogParams{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetUsageLog returned error: %v", err)
+	}
+	for _, entry := range log.Entries {
+		if entry.ID == "usage-1" && (entry.RequestedModel != "smart" || entry.ServedModel != "gpt-4o-2024-08-06") {
+			t.Fatalf("usage-1 models = %q/%q, want smart/gpt-4o-2024-08-06", entry.RequestedModel, entry.ServedModel)
+		}
+	}
+}
+
 func TestSQLiteReaderGetUsageByUserPath_GroupsByTrackedPath(t *testing.T) {
 	db, err := sql.Open("sqlite", ":memory:")
 	if err != nil {
@@ -859,3 +947,359 @@ func TestSQLiteReader_GetUsageLogFiltersByUserPathSubtree(t *testing.T) {
 		t.Fatalf("expected match-team, got %s", log.Entries[0].ID)
 	}
 }
+
+func TestSQLiteReaderGetUsageByExperiment_GroupsByVariant(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	entry := func(id, experiment, variant string, tokens int) *UsageEntry {
+		return &UsageEntry{
+			ID:                id,
+			RequestID:         "req-" + id,
+			ProviderID:        "provider-" + id,
+			Timestamp:         time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC),
+			Model:             "gpt-5",
+			Provider:          "openai",
+			Endpoint:          "/v1/chat/completions",
+			Experiment:        experiment,
+			ExperimentVariant: variant,
+			InputTokens:       tokens,
+			OutputTokens:      tokens,
+			TotalTokens:       2 * tokens,
+		}
+	}
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		entry("control-1", "smart-model", "control", 10),
+		entry("control-2", "smart-model", "control", 20),
+		entry("candidate-1", "smart-model", "candidate", 5),
+		entry("outside", "", "", 100),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	got, err := reader.GetUsageByExperiment(ctx, UsageQueryParams{})
+	if err != nil {
+		t.Fatalf("GetUsageByExperiment returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d, want 2: %+v", len(got), got)
+	}
+	byVariant := map[string]ExperimentVariantUsage{}
+	for _, row := range got {
+		if row.Experiment != "smart-model" {
+			t.Fatalf("unexpected experiment %q", row.Experiment)
+		}
+		byVariant[row.Variant] = row
+	}
+	if control := byVariant["control"]; control.Requests != 2 || control.InputTokens != 30 || control.TotalTokens != 60 {
+		t.Fatalf("control = %+v, want 2 requests and 30 input tokens", control)
+	}
+	if candidate := byVariant["candidate"]; candidate.Requests != 1 || candidate.OutputTokens != 5 {
+		t.Fatalf("candidate = %+v, want 1 request and 5 output tokens", candidate)
+	}
+}
+
+func TestSQLiteReaderGetUsageByLabel_GroupsAndFilters(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	entry := func(id string, labels map[string]string, tokens int) *UsageEntry {
+		cost := float64(tokens) / 10
+		return &UsageEntry{
+			ID:           id,
+			RequestID:    "req-" + id,
+			ProviderID:   "provider-" + id,
+			Timestamp:    time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC),
+			Model:        "gpt-5",
+			Provider:     "openai",
+			Endpoint:     "/v1/chat/completions",
+			Labels:       labels,
+			InputTokens:  tokens,
+			OutputTokens: tokens,
+			TotalTokens:  2 * tokens,
+			TotalCost:    &cost,
+		}
+	}
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		entry("growth-prod", map[string]string{"team": "growth", "env": "prod"}, 10),
+		entry("growth-dev", map[string]string{"team": "growth", "env": "dev"}, 20),
+		entry("search-prod", map[string]string{"team": "search", "env": "prod"}, 5),
+		entry("unlabeled", nil, 100),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	got, err := reader.GetUsageByLabel(ctx, UsageQueryParams{}, "team")
+	if err != nil {
+		t.Fatalf("GetUsageByLabel returned error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("len(got) = %d, want 3: %+v", len(got), got)
+	}
+	if got[0].Value != "" || got[0].Requests != 1 || got[0].TotalTokens != 200 {
+		t.Fatalf("unlabeled group = %+v, want 1 request and 200 tokens", got[0])
+	}
+	if growth := got[1]; growth.Label != "team" || growth.Value != "growth" || growth.Requests != 2 || growth.InputTokens != 30 || growth.TotalCost == nil || *growth.TotalCost != 3 {
+		t.Fatalf("growth group = %+v, want 2 requests, 30 input tokens and 3.0 cost", growth)
+	}
+	if search := got[2]; search.Value != "search" || search.Requests != 1 {
+		t.Fatalf("search group = %+v, want 1 request", search)
+	}
+
+	filtered, err := reader.GetUsageByLabel(ctx, UsageQueryParams{Labels: map[string]string{"env": "prod"}}, "team")
+	if err != nil {
+		t.Fatalf("GetUsageByLabel with filter returned error: %v", err)
+	}
+	if len(filtered) != 2 || filtered[0].Value != "growth" || filtered[0].Requests != 1 || filtered[1].Value != "search" {
+		t.Fatalf("filtered = %+v, want one prod request per team", filtered)
+	}
+
+	summary, err := reader.GetSummary(ctx, UsageQueryParams{Labels: map[string]string{"team": "growth"}})
+	if err != nil {
+		t.Fatalf("GetSummary returned error: %v", err)
+	}
+	if summary.TotalRequests != 2 || summary.TotalInput != 30 {
+		t.Fatalf("summary = %+v, want the two growth requests", summary)
+	}
+
+	log, err := reader.GetUsageLog(ctx, UsageLogParams{UsageQueryParams: UsageQueryParams{Labels: map[string]string{"team": "search"}}})
+	if err != nil {
+		t.Fatalf("GetUsageLog returned error: %v", err)
+	}
+	if log.Total != 1 || log.Entries[0].Labels["env"] != "prod" {
+		t.Fatalf("log = %+v, want the search entry with its labels", log)
+	}
+
+	if _, err := reader.GetUsageByLabel(ctx, UsageQueryParams{}, "bad key"); err == nil {
+		t.Fatal("GetUsageByLabel accepted an invalid label key")
+	}
+}
+
+func TestSQLiteReaderGetUsageByUser_GroupsAndFiltersLog(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	entry := func(id, userHash, user string, tokens int) *UsageEntry {
+		return &UsageEntry{
+			ID:           id,
+			RequestID:    "req-" + id,
+			ProviderID:   "provider-" + id,
+			Timestamp:    time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC),
+			Model:        "gpt-5",
+			Provider:     "openai",
+			Endpoint:     "/v1/chat/completions",
+			UserHash:     userHash,
+			User:         user,
+			InputTokens:  tokens,
+			OutputTokens: tokens,
+			TotalTokens:  2 * tokens,
+		}
+	}
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		entry("alice-1", "aaaaaaaaaaaaaaaa", "alice", 10),
+		entry("alice-2", "aaaaaaaaaaaaaaaa", "alice", 20),
+		entry("bob", "bbbbbbbbbbbbbbbb", "", 5),
+		entry("anonymous", "", "", 100),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	got, err := reader.GetUsageByUser(ctx, UsageQueryParams{})
+	if err != nil {
+		t.Fatalf("GetUsageByUser returned error: %v", err)
+	}
+	if len(got) != 3 || got[0].Value != "" || got[0].Requests != 1 {
+		t.Fatalf("got = %+v, want anonymous, alice and bob groups", got)
+	}
+	if alice := got[1]; alice.Label != UserGroupLabel || alice.Value != "aaaaaaaaaaaaaaaa" || alice.Requests != 2 || alice.InputTokens != 30 {
+		t.Fatalf("alice group = %+v, want 2 requests and 30 input tokens", alice)
+	}
+
+	log, err := reader.GetUsageLog(ctx, UsageLogParams{UserHash: "aaaaaaaaaaaaaaaa"})
+	if err != nil {
+		t.Fatalf("GetUsageLog returned error: %v", err)
+	}
+	if log.Total != 2 || log.Entries[0].UserHash != "aaaaaaaaaaaaaaaa" || log.Entries[0].User != "alice" {
+		t.Fatalf("log = %+v, want the two alice entries with the raw user", log)
+	}
+
+	for requestID, want := range map[string]int{"req-alice-1": 1, "req-alice": 0} {
+		log, err := reader.GetUsageLog(ctx, UsageLogParams{RequestID: requestID})
+		if err != nil {
+			t.Fatalf("GetUsageLog(%q) returned error: %v", requestID, err)
+		}
+		if log.Total != want {
+			t.Fatalf("GetUsageLog(%q) total = %d, want %d exact request ID matches", requestID, log.Total, want)
+		}
+	}
+}
+
+func TestSQLiteReader_AuthKeyIDFilter(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	entry := func(id, authKeyID string, tokens int) *UsageEntry {
+		return &UsageEntry{
+			ID:           id,
+			RequestID:    "req-" + id,
+			ProviderID:   "provider-" + id,
+			Timestamp:    time.Date(2026, 4, 7, 10, 0, 0, 0, time.UTC),
+			Model:        "gpt-5",
+			Provider:     "openai",
+			Endpoint:     "/v1/chat/completions",
+			AuthKeyID:    authKeyID,
+			InputTokens:  tokens,
+			OutputTokens: tokens,
+			TotalTokens:  2 * tokens,
+		}
+	}
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		entry("a-1", "key-a", 10),
+		entry("a-2", "key-a", 20),
+		entry("b", "key-b", 5),
+		entry("master", "", 100),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	params := UsageQueryParams{CacheMode: CacheModeAll, AuthKeyID: "key-a"}
+	summary, err := reader.GetSummary(ctx, params)
+	if err != nil {
+		t.Fatalf("GetSummary returned error: %v", err)
+	}
+	if summary.TotalRequests != 2 || summary.TotalInput != 30 {
+		t.Fatalf("summary = %+v, want only the two key-a entries", summary)
+	}
+
+	daily, err := reader.GetDailyUsage(ctx, params)
+	if err != nil {
+		t.Fatalf("GetDailyUsage returned error: %v", err)
+	}
+	if len(daily) != 1 || daily[0].Requests != 2 {
+		t.Fatalf("daily = %+v, want one day with the two key-a entries", daily)
+	}
+}
+
+func TestSQLiteReader_GetContentStats(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+
+	ctx := context.Background()
+	entry := func(id string, day int, model, language string, chars, words int) *UsageEntry {
+		return &UsageEntry{
+			ID:              id,
+			RequestID:       "req-" + id,
+			ProviderID:      "provider-" + id,
+			Timestamp:       time.Date(2026, 4, day, 10, 0, 0, 0, time.UTC),
+			Model:           model,
+			Provider:        "openai",
+			Endpoint:        "/v1/chat/completions",
+			ContentLanguage: language,
+			ContentChars:    chars,
+			ContentWords:    words,
+		}
+	}
+	err = store.WriteBatch(ctx, []*UsageEntry{
+		entry("en-1", 7, "gpt-5", "en", 120, 20),
+		entry("en-2", 7, "gpt-5", "en", 6000, 1000),
+		entry("de-1", 7, "gpt-5", "de", 800, 110),
+		entry("en-3", 8, "gpt-5", "en", 2500, 400),
+		entry("mini", 8, "gpt-5-mini", "und", 0, 0),
+		entry("not-analyzed", 8, "gpt-5", "", 0, 0),
+	})
+	if err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+
+	stats, err := reader.GetContentStats(ctx, UsageQueryParams{CacheMode: CacheModeAll})
+	if err != nil {
+		t.Fatalf("GetContentStats returned error: %v", err)
+	}
+	want := []ContentStats{
+		{Date: "2026-04-07", Model: "gpt-5", Provider: "openai", ProviderName: "openai", Language: "de", Responses: 1, TotalChars: 800, TotalWords: 110, MaxChars: 800, Lengths: ContentLengthBuckets{Medium: 1}},
+		{Date: "2026-04-07", Model: "gpt-5", Provider: "openai", ProviderName: "openai", Language: "en", Responses: 2, TotalChars: 6120, TotalWords: 1020, MaxChars: 6000, Lengths: ContentLengthBuckets{Short: 1, VeryLong: 1}},
+		{Date: "2026-04-08", Model: "gpt-5", Provider: "openai", ProviderName: "openai", Language: "en", Responses: 1, TotalChars: 2500, TotalWords: 400, MaxChars: 2500, Lengths: ContentLengthBuckets{Long: 1}},
+		{Date: "2026-04-08", Model: "gpt-5-mini", Provider: "openai", ProviderName: "openai", Language: "und", Responses: 1, Lengths: ContentLengthBuckets{Short: 1}},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("GetContentStats() = %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Fatalf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package usage
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -98,3 +99,139 @@ func TestSQLiteReaderGetDailyUsage_GroupsByConfiguredTimeZone(t *testing.T) {
 		t.Errorf("expected 70 total tokens in grouped period, got %d", daily[0].TotalTokens)
 	}
 }
+
+func newTimeZoneTestReader(t *testing.T, timestamps ...time.Time) *SQLiteReader {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store, err := NewSQLiteStore(db, 0)
+	if err != nil {
+		t.Fatalf("failed to create sqlite store: %v", err)
+	}
+	entries := make([]*UsageEntry, 0, len(timestamps))
+	for i, ts := range timestamps {
+		id := fmt.Sprintf("entry-%d", i)
+		entries = append(entries, &UsageEntry{
+			ID:          id,
+			RequestID:   "req-" + id,
+			ProviderID:  "provider-" + id,
+			Timestamp:   ts,
+			Model:       "gpt-5",
+			Provider:    "openai",
+			Endpoint:    "/v1/chat/completions",
+			TotalTokens: 1,
+		})
+	}
+	if err := store.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("failed to seed usage entries: %v", err)
+	}
+
+	reader, err := NewSQLiteReader(db)
+	if err != nil {
+		t.Fatalf("failed to create sqlite reader: %v", err)
+	}
+	return reader
+}
+
+func usagePeriods(t *testing.T, reader *SQLiteReader, params UsageQueryParams) map[string]int {
+	t.Helper()
+	daily, err := reader.GetDailyUsage(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetDailyUsage returned error: %v", err)
+	}
+	periods := make(map[string]int, len(daily))
+	for _, period := range daily {
+		periods[period.Date] = period.Requests
+	}
+	return periods
+}
+
+func TestSQLiteReaderGetDailyUsage_PositiveOffsetKeepsLocalDayTogether(t *testing.T) {
+	// 01:00 and 19:00 on 2026-01-15 in Tokyo fall on different UTC days.
+	reader := newTimeZoneTestReader(t,
+		time.Date(2026, 1, 14, 16, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC),
+	)
+	location, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	periods := usagePeriods(t, reader, UsageQueryParams{
+		StartDate: time.Date(2026, 1, 15, 0, 0, 0, 0, location),
+		EndDate:   time.Date(2026, 1, 15, 0, 0, 0, 0, location),
+		Interval:  "daily",
+		TimeZone:  "Asia/Tokyo",
+	})
+	if len(periods) != 1 || periods["2026-01-15"] != 2 {
+		t.Fatalf("periods = %v, want both requests on 2026-01-15", periods)
+	}
+}
+
+func TestSQLiteReaderGetDailyUsage_WeekStartAcrossYearBoundary(t *testing.T) {
+	// Sunday 2025-12-28 and Saturday 2026-01-03 in New York: one Sunday-start
+	// week, but two ISO weeks.
+	reader := newTimeZoneTestReader(t,
+		time.Date(2025, 12, 28, 15, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 4, 1, 0, 0, 0, time.UTC),
+	)
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	params := UsageQueryParams{
+		StartDate: time.Date(2025, 12, 28, 0, 0, 0, 0, location),
+		EndDate:   time.Date(2026, 1, 3, 0, 0, 0, 0, location),
+		Interval:  "weekly",
+		TimeZone:  "America/New_York",
+	}
+
+	periods := usagePeriods(t, reader, params)
+	if len(periods) != 2 || periods["2025-W52"] != 1 || periods["2026-W01"] != 1 {
+		t.Fatalf("monday weeks = %v, want one request each in 2025-W52 and 2026-W01", periods)
+	}
+
+	params.WeekStart = WeekStartSunday
+	periods = usagePeriods(t, reader, params)
+	if len(periods) != 1 || periods["2026-W01"] != 2 {
+		t.Fatalf("sunday weeks = %v, want both requests in 2026-W01", periods)
+	}
+}
+
+func TestSQLiteReaderGetDailyUsage_SundayWeeksAcrossDSTTransition(t *testing.T) {
+	// New York springs forward at 02:00 on Sunday 2026-03-08.
+	reader := newTimeZoneTestReader(t,
+		time.Date(2026, 3, 8, 4, 30, 0, 0, time.UTC), // Sat 23:30 EST
+		time.Date(2026, 3, 8, 5, 30, 0, 0, time.UTC), // Sun 00:30 EST
+		time.Date(2026, 3, 9, 3, 30, 0, 0, time.UTC), // Sun 23:30 EDT
+	)
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	periods := usagePeriods(t, reader, UsageQueryParams{
+		StartDate: time.Date(2026, 3, 1, 0, 0, 0, 0, location),
+		EndDate:   time.Date(2026, 3, 14, 0, 0, 0, 0, location),
+		Interval:  "weekly",
+		TimeZone:  "America/New_York",
+		WeekStart: WeekStartSunday,
+	})
+	if len(periods) != 2 || periods["2026-W10"] != 1 || periods["2026-W11"] != 2 {
+		t.Fatalf("periods = %v, want Saturday in 2026-W10 and both Sunday requests in 2026-W11", periods)
+	}
+
+	daily := usagePeriods(t, reader, UsageQueryParams{
+		StartDate: time.Date(2026, 3, 7, 0, 0, 0, 0, location),
+		EndDate:   time.Date(2026, 3, 8, 0, 0, 0, 0, location),
+		Interval:  "daily",
+		TimeZone:  "America/New_York",
+	})
+	if len(daily) != 2 || daily["2026-03-07"] != 1 || daily["2026-03-08"] != 2 {
+		t.Fatalf("daily periods = %v, want 1 on 2026-03-07 and 2 on 2026-03-08", daily)
+	}
+}
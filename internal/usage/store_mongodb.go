@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -88,6 +87,19 @@ func NewMongoDBStore(database *mongo.Database, retentionDays int) (*MongoDBStore
 		{
 			Keys: bson.D{{Key: "cache_type", Value: 1}, {Key: "timestamp", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "experiment", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_hash", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "auth_key_id", Value: 1}},
+		},
+		{
+			// Keyset pagination of the log, newest first.
+			Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}},
+		},
 	}
 
 	// Add timestamp index - use TTL index if retention is configured,
@@ -108,7 +120,7 @@ func NewMongoDBStore(database *mongo.Database, retentionDays int) (*MongoDBStore
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	if err != nil {
 		// Log warning but don't fail - indexes may already exist
-		slog.Warn("failed to create some MongoDB indexes for usage", "error", err)
+		usageLogger.Warn("failed to create some MongoDB indexes for usage", "error", err)
 	}
 
 	return &MongoDBStore{
@@ -137,7 +149,7 @@ func (s *MongoDBStore) WriteBatch(ctx context.Context, entries []*UsageEntry) er
 		if bulkErr, ok := errors.AsType[*mongo.BulkWriteException](err); ok {
 			failedCount := len(bulkErr.WriteErrors)
 			// Log for visibility
-			slog.Warn("partial usage insert failure",
+			usageLogger.Warn("partial usage insert failure",
 				"total", len(entries),
 				"failed", failedCount,
 				"succeeded", len(entries)-failedCount,
@@ -3,7 +3,6 @@ package usage
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,7 +13,7 @@ import (
 )
 
 const (
-	usageInsertColumnCount     = 18
+	usageInsertColumnCount     = 33
 	postgresMaxBindParameters  = 65535
 	usageInsertMaxRowsPerQuery = postgresMaxBindParameters / usageInsertColumnCount
 )
@@ -22,7 +21,9 @@ const (
 const usageInsertPrefix = `
 		INSERT INTO usage (id, request_id, provider_id, timestamp, model, provider, provider_name,
 			endpoint, user_path, cache_type, input_tokens, output_tokens, total_tokens, raw_data,
-			input_cost, output_cost, total_cost, costs_calculation_caveat)
+			input_cost, output_cost, total_cost, costs_calculation_caveat, experiment, experiment_variant, labels,
+			requested_model, served_model, image_count, image_bytes, template_name, template_version, user_hash, raw_user, auth_key_id,
+			content_language, content_chars, content_words)
 		VALUES `
 
 const usageInsertSuffix = `
@@ -49,8 +50,28 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
-	ctx := context.Background()
+	if err := CreatePostgreSQLSchema(context.Background(), pool); err != nil {
+		return nil, err
+	}
+
+	store := &PostgreSQLStore{
+		pool:          pool,
+		retentionDays: retentionDays,
+		stopCleanup:   make(chan struct{}),
+	}
+
+	// Start background cleanup if retention is configured
+	if retentionDays > 0 {
+		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	}
+
+	return store, nil
+}
 
+// CreatePostgreSQLSchema creates or upgrades the usage table and its indexes.
+// It is idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	// Create table for usage tracking
 	_, err := pool.Exec(ctx, `
 		CREATE TABLE IF NOT EXISTS usage (
@@ -71,7 +92,7 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create usage table: %w", err)
+		return fmt.Errorf("failed to create usage table: %w", err)
 	}
 
 	// Add cost columns (idempotent via IF NOT EXISTS)
@@ -83,16 +104,32 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS provider_name TEXT",
 		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS user_path TEXT",
 		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS cache_type TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS experiment TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS experiment_variant TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS labels JSONB",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS requested_model TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS served_model TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS image_count INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS image_bytes BIGINT NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS template_name TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS template_version INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS user_hash TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS raw_user TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS auth_key_id TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS content_language TEXT",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS content_chars INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN IF NOT EXISTS content_words INTEGER NOT NULL DEFAULT 0",
 	}
 	for _, migration := range costMigrations {
 		if _, err := pool.Exec(ctx, migration); err != nil {
-			return nil, fmt.Errorf("failed to run migration: %w", err)
+			return fmt.Errorf("failed to run migration: %w", err)
 		}
 	}
 
 	// Create indexes for common queries
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_usage_timestamp ON usage(timestamp)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_timestamp_id ON usage(timestamp, id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_request_id ON usage(request_id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_provider_id ON usage(provider_id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_model ON usage(model)",
@@ -100,26 +137,19 @@ func NewPostgreSQLStore(pool *pgxpool.Pool, retentionDays int) (*PostgreSQLStore
 		"CREATE INDEX IF NOT EXISTS idx_usage_provider_name ON usage(provider_name)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_user_path ON usage(user_path)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_cache_type ON usage(cache_type)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_experiment ON usage(experiment)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_served_model ON usage(served_model)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_user_hash ON usage(user_hash)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_auth_key_id ON usage(auth_key_id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_raw_data_gin ON usage USING GIN (raw_data)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_labels_gin ON usage USING GIN (labels)",
 	}
 	for _, idx := range indexes {
 		if _, err := pool.Exec(ctx, idx); err != nil {
-			slog.Warn("failed to create index", "error", err)
+			usageLogger.Warn("failed to create index", "error", err)
 		}
 	}
-
-	store := &PostgreSQLStore{
-		pool:          pool,
-		retentionDays: retentionDays,
-		stopCleanup:   make(chan struct{}),
-	}
-
-	// Start background cleanup if retention is configured
-	if retentionDays > 0 {
-		go RunCleanupLoop(store.stopCleanup, store.cleanup)
-	}
-
-	return store, nil
+	return nil
 }
 
 // WriteBatch writes multiple usage entries to PostgreSQL using batch insert.
@@ -140,7 +170,7 @@ func (s *PostgreSQLStore) WriteBatch(ctx context.Context, entries []*UsageEntry)
 // writeBatchSmall uses INSERT for small batches
 func (s *PostgreSQLStore) writeBatchSmall(ctx context.Context, entries []*UsageEntry) error {
 	if err := writeUsageInsertChunks(ctx, s.pool, entries); err != nil {
-		slog.Warn("failed to insert usage batch", "error", err, "count", len(entries))
+		usageLogger.Warn("failed to insert usage batch", "error", err, "count", len(entries))
 		return fmt.Errorf("failed to insert %d usage entries: %w", len(entries), err)
 	}
 	return nil
@@ -155,7 +185,7 @@ func (s *PostgreSQLStore) writeBatchLarge(ctx context.Context, entries []*UsageE
 	defer tx.Rollback(ctx) //nolint:errcheck
 
 	if err := writeUsageInsertChunks(ctx, tx, entries); err != nil {
-		slog.Warn("failed to insert usage batch in transaction", "error", err, "count", len(entries))
+		usageLogger.Warn("failed to insert usage batch in transaction", "error", err, "count", len(entries))
 		return fmt.Errorf("failed to insert %d usage entries: %w", len(entries), err)
 	}
 
@@ -221,6 +251,21 @@ func buildUsageInsert(entries []*UsageEntry) (string, []any) {
 			entry.OutputCost,
 			entry.TotalCost,
 			entry.CostsCalculationCaveat,
+			nullableUsageString(entry.Experiment),
+			nullableUsageString(entry.ExperimentVariant),
+			marshalUsageLabels(entry.Labels, entry.ID),
+			nullableUsageString(entry.RequestedModel),
+			nullableUsageString(entry.ServedModel),
+			entry.ImageCount,
+			entry.ImageBytes,
+			nullableUsageString(entry.TemplateName),
+			entry.TemplateVersion,
+			nullableUsageString(entry.UserHash),
+			nullableUsageString(entry.User),
+			nullableUsageString(entry.AuthKeyID),
+			nullableUsageString(entry.ContentLanguage),
+			entry.ContentChars,
+			entry.ContentWords,
 		)
 	}
 
@@ -258,11 +303,11 @@ func (s *PostgreSQLStore) cleanup() {
 
 	result, err := s.pool.Exec(ctx, "DELETE FROM usage WHERE timestamp < $1", cutoff)
 	if err != nil {
-		slog.Error("failed to cleanup old usage entries", "error", err)
+		usageLogger.Error("failed to cleanup old usage entries", "error", err)
 		return
 	}
 
 	if result.RowsAffected() > 0 {
-		slog.Info("cleaned up old usage entries", "deleted", result.RowsAffected())
+		usageLogger.Info("cleaned up old usage entries", "deleted", result.RowsAffected())
 	}
 }
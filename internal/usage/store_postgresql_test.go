@@ -31,6 +31,20 @@ func TestBuildUsageInsert(t *testing.T) {
 			OutputCost:             &outputCost,
 			TotalCost:              &totalCost,
 			CostsCalculationCaveat: "none",
+			Experiment:             "assistant-ramp",
+			ExperimentVariant:      "candidate",
+			Labels:                 map[string]string{"team": "growth"},
+			RequestedModel:         "gpt-4o-mini",
+			ServedModel:            "gpt-4o-mini-2024-07-18",
+			ImageCount:             2,
+			ImageBytes:             48_000,
+			TemplateName:           "support",
+			TemplateVersion:        3,
+			UserHash:               "0123456789abcdef",
+			AuthKeyID:              "key-1",
+			ContentLanguage:        "en",
+			ContentChars:           420,
+			ContentWords:           71,
 		},
 		{
 			ID:                     "usage-2",
@@ -53,12 +67,12 @@ func TestBuildUsageInsert(t *testing.T) {
 	})
 
 	normalized := strings.Join(strings.Fields(query), " ")
-	wantQuery := "INSERT INTO usage (id, request_id, provider_id, timestamp, model, provider, provider_name, endpoint, user_path, cache_type, input_tokens, output_tokens, total_tokens, raw_data, input_cost, output_cost, total_cost, costs_calculation_caveat) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18), ($19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36) ON CONFLICT (id) DO NOTHING"
+	wantQuery := "INSERT INTO usage (id, request_id, provider_id, timestamp, model, provider, provider_name, endpoint, user_path, cache_type, input_tokens, output_tokens, total_tokens, raw_data, input_cost, output_cost, total_cost, costs_calculation_caveat, experiment, experiment_variant, labels, requested_model, served_model, image_count, image_bytes, template_name, template_version, user_hash, raw_user, auth_key_id, content_language, content_chars, content_words) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33), ($34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49, $50, $51, $52, $53, $54, $55, $56, $57, $58, $59, $60, $61, $62, $63, $64, $65, $66) ON CONFLICT (id) DO NOTHING"
 	if normalized != wantQuery {
 		t.Fatalf("query = %q, want %q", normalized, wantQuery)
 	}
 
-	if got, want := len(args), 36; got != want {
+	if got, want := len(args), 66; got != want {
 		t.Fatalf("len(args) = %d, want %d", got, want)
 	}
 	if got := args[0]; got != "usage-1" {
@@ -67,8 +81,8 @@ func TestBuildUsageInsert(t *testing.T) {
 	if got := args[6]; got != "primary-openai" {
 		t.Fatalf("args[6] = %v, want primary-openai", got)
 	}
-	if got := args[18]; got != "usage-2" {
-		t.Fatalf("args[18] = %v, want usage-2", got)
+	if got := args[33]; got != "usage-2" {
+		t.Fatalf("args[33] = %v, want usage-2", got)
 	}
 	if got := args[9]; got != CacheTypeExact {
 		t.Fatalf("args[9] = %v, want %q", got, CacheTypeExact)
@@ -76,15 +90,78 @@ func TestBuildUsageInsert(t *testing.T) {
 	if got := string(args[13].([]byte)); got != `{"cached_tokens":3}` {
 		t.Fatalf("args[13] = %q, want %q", got, `{"cached_tokens":3}`)
 	}
-	if got := args[27]; got != nil {
-		t.Fatalf("args[27] = %v, want nil cache_type", got)
+	if got := args[18]; got != "assistant-ramp" {
+		t.Fatalf("args[18] = %v, want assistant-ramp", got)
 	}
-	rawData, ok := args[31].([]byte)
+	if got := args[19]; got != "candidate" {
+		t.Fatalf("args[19] = %v, want candidate", got)
+	}
+	if got := string(args[20].([]byte)); got != `{"team":"growth"}` {
+		t.Fatalf("args[20] = %q, want %q", got, `{"team":"growth"}`)
+	}
+	if got := args[21]; got != "gpt-4o-mini" {
+		t.Fatalf("args[21] = %v, want gpt-4o-mini", got)
+	}
+	if got := args[22]; got != "gpt-4o-mini-2024-07-18" {
+		t.Fatalf("args[22] = %v, want gpt-4o-mini-2024-07-18", got)
+	}
+	if got := args[23]; got != 2 {
+		t.Fatalf("args[23] = %v, want 2 images", got)
+	}
+	if got := args[24]; got != int64(48_000) {
+		t.Fatalf("args[24] = %v, want 48000 image bytes", got)
+	}
+	if got := args[25]; got != "support" {
+		t.Fatalf("args[25] = %v, want support template", got)
+	}
+	if got := args[26]; got != 3 {
+		t.Fatalf("args[26] = %v, want template version 3", got)
+	}
+	if got := args[27]; got != "0123456789abcdef" {
+		t.Fatalf("args[27] = %v, want user hash", got)
+	}
+	if got := args[28]; got != nil {
+		t.Fatalf("args[28] = %v, want nil raw user", got)
+	}
+	if got := args[29]; got != "key-1" {
+		t.Fatalf("args[29] = %v, want auth key id", got)
+	}
+	if got := args[30]; got != "en" {
+		t.Fatalf("args[30] = %v, want content language", got)
+	}
+	if got := args[31]; got != 420 {
+		t.Fatalf("args[31] = %v, want 420 content chars", got)
+	}
+	if got := args[32]; got != 71 {
+		t.Fatalf("args[32] = %v, want 71 content words", got)
+	}
+	if got := args[63]; got != nil {
+		t.Fatalf("args[63] = %v, want nil content_language", got)
+	}
+	if got := args[56]; got != 0 {
+		t.Fatalf("args[56] = %v, want 0 images", got)
+	}
+	if got := args[58]; got != nil {
+		t.Fatalf("args[58] = %v, want nil template_name", got)
+	}
+	if got := args[42]; got != nil {
+		t.Fatalf("args[42] = %v, want nil cache_type", got)
+	}
+	rawData, ok := args[46].([]byte)
 	if !ok {
-		t.Fatalf("args[31] has type %T, want []byte", args[31])
+		t.Fatalf("args[46] has type %T, want []byte", args[46])
 	}
 	if rawData != nil {
-		t.Fatalf("args[31] = %v, want nil raw_data", rawData)
+		t.Fatalf("args[46] = %v, want nil raw_data", rawData)
+	}
+	if got := args[51]; got != nil {
+		t.Fatalf("args[51] = %v, want nil experiment", got)
+	}
+	if labels := args[53].([]byte); labels != nil {
+		t.Fatalf("args[53] = %q, want nil labels", labels)
+	}
+	if got := args[54]; got != nil {
+		t.Fatalf("args[54] = %v, want nil requested_model", got)
 	}
 }
 
@@ -93,3 +170,44 @@ func TestUsageInsertMaxRowsPerQueryRespectsPostgresLimit(t *testing.T) {
 		t.Fatalf("bind parameters = %d, want <= %d", got, postgresMaxBindParameters)
 	}
 }
+
+func TestPGGroupExpr_WeekStart(t *testing.T) {
+	monday := pgGroupExpr("weekly", "Asia/Tokyo", false)
+	if want := `to_char(DATE_TRUNC('week', timestamp AT TIME ZONE 'Asia/Tokyo'), 'IYYY-"W"IW')`; monday != want {
+		t.Fatalf("monday weeks = %s, want %s", monday, want)
+	}
+	sunday := pgGroupExpr("weekly", "Asia/Tokyo", true)
+	if want := `to_char(DATE_TRUNC('week', (timestamp AT TIME ZONE 'Asia/Tokyo') + INTERVAL '1 day'), 'IYYY-"W"IW')`; sunday != want {
+		t.Fatalf("sunday weeks = %s, want %s", sunday, want)
+	}
+	if daily := pgGroupExpr("daily", "UTC", true); daily != `to_char(DATE(timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD')` {
+		t.Fatalf("week start changed daily grouping: %s", daily)
+	}
+}
+
+func TestPGUsageByLabelQuery(t *testing.T) {
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	query, args, err := pgUsageByLabelQuery(UsageQueryParams{
+		StartDate: start,
+		Labels:    map[string]string{"env": "prod", "Region": "eu"},
+		CacheMode: CacheModeAll,
+	}, "team")
+	if err != nil {
+		t.Fatalf("pgUsageByLabelQuery returned error: %v", err)
+	}
+
+	normalized := strings.Join(strings.Fields(query), " ")
+	wantQuery := `SELECT COALESCE(labels ->> $1, '') AS label_value, COUNT(*), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(total_tokens), 0), SUM(input_cost), SUM(output_cost), SUM(total_cost) FROM "usage" WHERE timestamp >= $2 AND labels ->> $3 = $4 AND labels ->> $5 = $6 GROUP BY label_value ORDER BY label_value`
+	if normalized != wantQuery {
+		t.Fatalf("query = %q, want %q", normalized, wantQuery)
+	}
+	wantArgs := []any{"team", start, "env", "prod", "region", "eu"}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Fatalf("args[%d] = %v, want %v", i, args[i], wantArgs[i])
+		}
+	}
+}
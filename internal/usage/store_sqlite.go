@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +14,8 @@ import (
 // maxEntriesPerBatch derives from maxSQLiteParams / columnsPerUsageEntry.
 const (
 	maxSQLiteParams      = 999
-	columnsPerUsageEntry = 18
-	maxEntriesPerBatch   = maxSQLiteParams / columnsPerUsageEntry // 55 entries
+	columnsPerUsageEntry = 33
+	maxEntriesPerBatch   = maxSQLiteParams / columnsPerUsageEntry // 30 entries
 )
 
 // SQLiteStore implements UsageStore for SQLite databases.
@@ -35,8 +34,30 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	store := &SQLiteStore{
+		db:            db,
+		retentionDays: retentionDays,
+		stopCleanup:   make(chan struct{}),
+	}
+
+	// Start background cleanup if retention is configured
+	if retentionDays > 0 {
+		go RunCleanupLoop(store.stopCleanup, store.cleanup)
+	}
+
+	return store, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the usage table and its indexes. It
+// is idempotent: store constructors run it, and so does the baseline schema
+// migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
 	// Create table for usage tracking
-	_, err := db.Exec(`
+	_, err := db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS usage (
 			id TEXT PRIMARY KEY,
 			request_id TEXT NOT NULL,
@@ -55,7 +76,7 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		)
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create usage table: %w", err)
+		return fmt.Errorf("failed to create usage table: %w", err)
 	}
 
 	// Add cost columns (idempotent: SQLite lacks IF NOT EXISTS for ALTER TABLE ADD COLUMN)
@@ -67,12 +88,27 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		"ALTER TABLE usage ADD COLUMN provider_name TEXT",
 		"ALTER TABLE usage ADD COLUMN user_path TEXT",
 		"ALTER TABLE usage ADD COLUMN cache_type TEXT",
+		"ALTER TABLE usage ADD COLUMN experiment TEXT",
+		"ALTER TABLE usage ADD COLUMN experiment_variant TEXT",
+		"ALTER TABLE usage ADD COLUMN labels JSON",
+		"ALTER TABLE usage ADD COLUMN requested_model TEXT",
+		"ALTER TABLE usage ADD COLUMN served_model TEXT",
+		"ALTER TABLE usage ADD COLUMN image_count INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN image_bytes INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN template_name TEXT",
+		"ALTER TABLE usage ADD COLUMN template_version INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN user_hash TEXT",
+		"ALTER TABLE usage ADD COLUMN raw_user TEXT",
+		"ALTER TABLE usage ADD COLUMN auth_key_id TEXT",
+		"ALTER TABLE usage ADD COLUMN content_language TEXT",
+		"ALTER TABLE usage ADD COLUMN content_chars INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE usage ADD COLUMN content_words INTEGER NOT NULL DEFAULT 0",
 	}
 	for _, migration := range costMigrations {
-		if _, err := db.Exec(migration); err != nil {
+		if _, err := db.ExecContext(ctx, migration); err != nil {
 			// "duplicate column name" means the column already exists — safe to ignore
 			if !strings.Contains(err.Error(), "duplicate column") {
-				return nil, fmt.Errorf("failed to run migration %q: %w", migration, err)
+				return fmt.Errorf("failed to run migration %q: %w", migration, err)
 			}
 		}
 	}
@@ -81,6 +117,7 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_usage_timestamp ON usage(timestamp)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_timestamp_epoch ON usage(unixepoch(REPLACE(timestamp, ' ', 'T')))",
+		"CREATE INDEX IF NOT EXISTS idx_usage_timestamp_epoch_id ON usage(unixepoch(REPLACE(timestamp, ' ', 'T')), id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_request_id ON usage(request_id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_provider_id ON usage(provider_id)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_model ON usage(model)",
@@ -88,25 +125,17 @@ func NewSQLiteStore(db *sql.DB, retentionDays int) (*SQLiteStore, error) {
 		"CREATE INDEX IF NOT EXISTS idx_usage_provider_name ON usage(provider_name)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_user_path ON usage(user_path)",
 		"CREATE INDEX IF NOT EXISTS idx_usage_cache_type ON usage(cache_type)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_experiment ON usage(experiment)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_served_model ON usage(served_model)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_user_hash ON usage(user_hash)",
+		"CREATE INDEX IF NOT EXISTS idx_usage_auth_key_id ON usage(auth_key_id)",
 	}
 	for _, idx := range indexes {
-		if _, err := db.Exec(idx); err != nil {
-			slog.Warn("failed to create index", "error", err)
+		if _, err := db.ExecContext(ctx, idx); err != nil {
+			usageLogger.Warn("failed to create index", "error", err)
 		}
 	}
-
-	store := &SQLiteStore{
-		db:            db,
-		retentionDays: retentionDays,
-		stopCleanup:   make(chan struct{}),
-	}
-
-	// Start background cleanup if retention is configured
-	if retentionDays > 0 {
-		go RunCleanupLoop(store.stopCleanup, store.cleanup)
-	}
-
-	return store, nil
+	return nil
 }
 
 // WriteBatch writes multiple usage entries to SQLite using batch insert.
@@ -127,7 +156,7 @@ func (s *SQLiteStore) WriteBatch(ctx context.Context, entries []*UsageEntry) err
 
 		for j, e := range chunk {
 			e = normalizedUsageEntryForStorage(e)
-			placeholders[j] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+			placeholders[j] = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 			rawDataJSON := marshalRawData(e.RawData, e.ID)
 
@@ -156,12 +185,29 @@ func (s *SQLiteStore) WriteBatch(ctx context.Context, entries []*UsageEntry) err
 				e.OutputCost,
 				e.TotalCost,
 				e.CostsCalculationCaveat,
+				nullableUsageString(e.Experiment),
+				nullableUsageString(e.ExperimentVariant),
+				nullableUsageString(string(marshalUsageLabels(e.Labels, e.ID))),
+				nullableUsageString(e.RequestedModel),
+				nullableUsageString(e.ServedModel),
+				e.ImageCount,
+				e.ImageBytes,
+				nullableUsageString(e.TemplateName),
+				e.TemplateVersion,
+				nullableUsageString(e.UserHash),
+				nullableUsageString(e.User),
+				nullableUsageString(e.AuthKeyID),
+				nullableUsageString(e.ContentLanguage),
+				e.ContentChars,
+				e.ContentWords,
 			)
 		}
 
 		query := `INSERT OR IGNORE INTO usage (id, request_id, provider_id, timestamp, model, provider, provider_name,
 			endpoint, user_path, cache_type, input_tokens, output_tokens, total_tokens, raw_data,
-			input_cost, output_cost, total_cost, costs_calculation_caveat) VALUES ` +
+			input_cost, output_cost, total_cost, costs_calculation_caveat, experiment, experiment_variant, labels,
+			requested_model, served_model, image_count, image_bytes, template_name, template_version, user_hash, raw_user, auth_key_id,
+			content_language, content_chars, content_words) VALUES ` +
 			strings.Join(placeholders, ",")
 
 		_, err := s.db.ExecContext(ctx, query, values...)
@@ -200,12 +246,12 @@ func (s *SQLiteStore) cleanup() {
 
 	result, err := s.db.Exec("DELETE FROM usage WHERE "+sqliteTimestampEpochExpr()+" < unixepoch(?)", cutoff)
 	if err != nil {
-		slog.Error("failed to cleanup old usage entries", "error", err)
+		usageLogger.Error("failed to cleanup old usage entries", "error", err)
 		return
 	}
 
 	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
-		slog.Info("cleaned up old usage entries", "deleted", rowsAffected)
+		usageLogger.Info("cleaned up old usage entries", "deleted", rowsAffected)
 	}
 }
 
@@ -217,8 +263,30 @@ func marshalRawData(data map[string]any, entryID string) []byte {
 	}
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
-		slog.Warn("failed to marshal usage raw_data", "error", err, "id", entryID)
+		usageLogger.Warn("failed to marshal usage raw_data", "error", err, "id", entryID)
 		return []byte("{}")
 	}
 	return dataJSON
 }
+
+// marshalUsageLabels encodes request labels as a JSON object, or nil when the
+// entry has none.
+func marshalUsageLabels(labels map[string]string, entryID string) []byte {
+	if len(labels) == 0 {
+		return nil
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		usageLogger.Warn("failed to marshal usage labels", "error", err, "id", entryID)
+		return nil
+	}
+	return labelsJSON
+}
+
+// nullableUsageString stores empty optional text columns as NULL.
+func nullableUsageString(value string) any {
+	if value = strings.TrimSpace(value); value != "" {
+		return value
+	}
+	return nil
+}
@@ -1,9 +1,9 @@
 package usage
 
 import (
-	"log/slog"
 	"strings"
 
+	"gomodel/internal/contentstats"
 	"gomodel/internal/core"
 )
 
@@ -13,12 +13,33 @@ type StreamUsageObserver struct {
 	pricingResolver PricingResolver
 	cachedEntry     *UsageEntry
 	model           string
+	requestedModel  string
+	servedModel     string
 	provider        string
 	providerName    string
 	requestID       string
 	endpoint        string
 	userPath        string
-	closed          bool
+	experiment      string
+	variant         string
+	labels          map[string]string
+	images          core.InlineImageStats
+	promptTemplate  core.PromptTemplateUse
+	user            core.RequestUser
+	authKeyID       string
+	toolIterations  int
+	runSteps        runStepUsage
+	// content analyzes the assistant text of the stream when content
+	// analysis is on; contentSeen records that the stream carried chat or
+	// Responses output at all.
+	content     *contentstats.Analyzer
+	contentSeen bool
+	closed      bool
+}
+
+// runStepUsage sums the token usage of Assistants run steps in one stream.
+type runStepUsage struct {
+	input, output, total int
 }
 
 func NewStreamUsageObserver(logger LoggerInterface, model, provider, requestID, endpoint string, pricingResolver PricingResolver, userPath ...string) *StreamUsageObserver {
@@ -32,11 +53,11 @@ func NewStreamUsageObserver(logger LoggerInterface, model, provider, requestID,
 				normalizedUserPath = normalized
 			}
 		} else {
-			slog.Warn("stream usage observer received invalid user_path; using root fallback", "error", err)
+			usageLogger.Warn("stream usage observer received invalid user_path; using root fallback", "error", err)
 			normalizedUserPath = "/"
 		}
 	}
-	return &StreamUsageObserver{
+	observer := &StreamUsageObserver{
 		logger:          logger,
 		pricingResolver: pricingResolver,
 		model:           model,
@@ -45,6 +66,10 @@ func NewStreamUsageObserver(logger LoggerInterface, model, provider, requestID,
 		endpoint:        endpoint,
 		userPath:        normalizedUserPath,
 	}
+	if cfg := logger.Config(); cfg.ContentAnalysis {
+		observer.content = contentstats.NewAnalyzer(cfg.ContentSampleChars)
+	}
+	return observer
 }
 
 func (o *StreamUsageObserver) SetProviderName(providerName string) {
@@ -54,7 +79,77 @@ func (o *StreamUsageObserver) SetProviderName(providerName string) {
 	o.providerName = strings.TrimSpace(providerName)
 }
 
+// SetRequestedModel records the model string the client sent.
+func (o *StreamUsageObserver) SetRequestedModel(model string) {
+	if o == nil {
+		return
+	}
+	o.requestedModel = strings.TrimSpace(model)
+}
+
+// SetExperiment tags the usage entry with the request's A/B experiment assignment.
+func (o *StreamUsageObserver) SetExperiment(experiment, variant string) {
+	if o == nil {
+		return
+	}
+	o.experiment = strings.TrimSpace(experiment)
+	o.variant = strings.TrimSpace(variant)
+}
+
+// SetLabels tags the usage entry with the request's cost allocation labels.
+func (o *StreamUsageObserver) SetLabels(labels map[string]string) {
+	if o == nil {
+		return
+	}
+	o.labels = labels
+}
+
+// SetPromptTemplate records the prompt template version rendered into the request.
+func (o *StreamUsageObserver) SetPromptTemplate(use core.PromptTemplateUse) {
+	if o == nil {
+		return
+	}
+	o.promptTemplate = use
+}
+
+// SetUser records the end user named by the user request field.
+func (o *StreamUsageObserver) SetUser(user core.RequestUser) {
+	if o == nil {
+		return
+	}
+	o.user = user
+}
+
+// SetAuthKeyID records the managed auth key that authenticated the request.
+func (o *StreamUsageObserver) SetAuthKeyID(authKeyID string) {
+	if o == nil {
+		return
+	}
+	o.authKeyID = strings.TrimSpace(authKeyID)
+}
+
+// SetInlineImages records the inline image count and decoded size of the request.
+func (o *StreamUsageObserver) SetInlineImages(stats core.InlineImageStats) {
+	if o == nil {
+		return
+	}
+	o.images = stats
+}
+
+// SetToolLoopIterations records how many model calls the server-side tool
+// loop of the request made. The stream carries their summed usage.
+func (o *StreamUsageObserver) SetToolLoopIterations(iterations int) {
+	if o == nil {
+		return
+	}
+	o.toolIterations = iterations
+}
+
 func (o *StreamUsageObserver) OnJSONEvent(chunk map[string]any) {
+	if served := servedModelFromEvent(chunk); served != "" {
+		o.servedModel = served
+	}
+	o.observeContent(chunk)
 	entry := o.extractUsageFromEvent(chunk)
 	if entry != nil {
 		o.cachedEntry = entry
@@ -66,11 +161,65 @@ func (o *StreamUsageObserver) OnStreamClose() {
 		return
 	}
 	o.closed = true
+	if o.cachedEntry != nil {
+		// Usage chunks may precede the last chunk that names the model.
+		o.cachedEntry.ServedModel = o.servedModel
+		if o.content != nil && o.contentSeen {
+			o.cachedEntry.setContentStats(o.content.Stats())
+		}
+	}
 	if o.cachedEntry != nil && o.logger != nil {
 		o.logger.Write(o.cachedEntry)
 	}
 }
 
+// observeContent writes the assistant text of a chat completion chunk, from
+// its first choice, or of a Responses output_text delta to the content
+// analyzer. The text is analyzed once the stream closes and not kept.
+func (o *StreamUsageObserver) observeContent(chunk map[string]any) {
+	if o.content == nil {
+		return
+	}
+	if choices, ok := chunk["choices"].([]any); ok {
+		o.contentSeen = true
+		for _, raw := range choices {
+			choice, _ := raw.(map[string]any)
+			if index, _ := choice["index"].(float64); index != 0 {
+				continue
+			}
+			if delta, ok := choice["delta"].(map[string]any); ok {
+				if text, ok := delta["content"].(string); ok {
+					o.content.Write(text)
+				}
+			}
+		}
+		return
+	}
+	switch eventType, _ := chunk["type"].(string); eventType {
+	case "response.output_text.delta":
+		o.contentSeen = true
+		if delta, ok := chunk["delta"].(string); ok {
+			o.content.Write(delta)
+		}
+	case "response.completed", "response.done":
+		o.contentSeen = true
+	}
+}
+
+// servedModelFromEvent returns the model a provider reported in a chat
+// completion chunk or in the response object of a Responses API event.
+func servedModelFromEvent(chunk map[string]any) string {
+	if m, ok := chunk["model"].(string); ok && m != "" {
+		return m
+	}
+	if response, ok := chunk["response"].(map[string]any); ok {
+		if m, ok := response["model"].(string); ok && m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
 func (o *StreamUsageObserver) extractUsageFromEvent(chunk map[string]any) *UsageEntry {
 	providerID, _ := chunk["id"].(string)
 
@@ -142,6 +291,19 @@ func (o *StreamUsageObserver) extractUsageFromEvent(chunk map[string]any) *Usage
 		}
 	}
 
+	// Assistants streams report usage per run step before the run's total
+	// arrives in thread.run.completed; a stream that stops earlier (for
+	// example at requires_action) is charged the sum of its steps.
+	if object, _ := chunk["object"].(string); object == "thread.run.step" {
+		o.runSteps.input += inputTokens
+		o.runSteps.output += outputTokens
+		o.runSteps.total += totalTokens
+		inputTokens, outputTokens, totalTokens = o.runSteps.input, o.runSteps.output, o.runSteps.total
+		if model == "" {
+			model = o.servedModel
+		}
+	}
+
 	if inputTokens == 0 && outputTokens == 0 && totalTokens == 0 {
 		return nil
 	}
@@ -164,8 +326,20 @@ func (o *StreamUsageObserver) extractUsageFromEvent(chunk map[string]any) *Usage
 		pricingArgs...,
 	)
 	if entry != nil {
+		entry.RequestedModel = o.requestedModel
+		entry.ServedModel = o.servedModel
 		entry.ProviderName = o.providerName
 		entry.UserPath = o.userPath
+		entry.Experiment = o.experiment
+		entry.ExperimentVariant = o.variant
+		entry.Labels = o.labels
+		entry.ImageCount, entry.ImageBytes = o.images.Count, o.images.Bytes
+		entry.TemplateName, entry.TemplateVersion = o.promptTemplate.Name, o.promptTemplate.Version
+		entry.UserHash, entry.User = o.user.Hash, o.user.Raw
+		entry.AuthKeyID = o.authKeyID
+		if o.toolIterations > 0 {
+			entry.RawData = WithToolLoopIterations(entry.RawData, o.toolIterations)
+		}
 	}
 	return entry
 }
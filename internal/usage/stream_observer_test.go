@@ -1,6 +1,7 @@
 package usage
 
 import (
+	"context"
 	"io"
 	"strings"
 	"sync"
@@ -11,9 +12,10 @@ import (
 
 // trackingLogger tracks written entries for testing.
 type trackingLogger struct {
-	entries []*UsageEntry
-	mu      sync.Mutex
-	enabled bool
+	entries         []*UsageEntry
+	mu              sync.Mutex
+	enabled         bool
+	contentAnalysis bool
 }
 
 func (l *trackingLogger) Write(entry *UsageEntry) {
@@ -23,9 +25,10 @@ func (l *trackingLogger) Write(entry *UsageEntry) {
 }
 
 func (l *trackingLogger) Config() Config {
-	return Config{Enabled: l.enabled}
+	return Config{Enabled: l.enabled, ContentAnalysis: l.contentAnalysis}
 }
 
+func (l *trackingLogger) Flush(context.Context) error { return nil }
 func (l *trackingLogger) Close() error {
 	return nil
 }
@@ -332,6 +335,122 @@ data: [DONE]
 	}
 }
 
+func TestStreamUsageObserverAssistantsRunSteps(t *testing.T) {
+	steps := `event: thread.run.created
+data: {"id":"run_1","object":"thread.run","status":"queued","model":"gpt-4o","usage":null}
+
+event: thread.run.step.completed
+data: {"id":"step_1","object":"thread.run.step","run_id":"run_1","status":"completed","usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}
+
+event: thread.run.step.completed
+data: {"id":"step_2","object":"thread.run.step","run_id":"run_1","status":"completed","usage":{"prompt_tokens":20,"completion_tokens":6,"total_tokens":26}}
+
+`
+	completed := `event: thread.run.completed
+data: {"id":"run_1","object":"thread.run","status":"completed","model":"gpt-4o","usage":{"prompt_tokens":31,"completion_tokens":10,"total_tokens":41}}
+
+event: done
+data: [DONE]
+
+`
+	tests := []struct {
+		name                 string
+		stream               string
+		input, output, total int
+		providerID           string
+	}{
+		{name: "steps only", stream: steps, input: 30, output: 10, total: 40, providerID: "step_2"},
+		{name: "run completed", stream: steps + completed, input: 31, output: 10, total: 41, providerID: "run_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &trackingLogger{enabled: true}
+			stream := streaming.NewObservedSSEStream(
+				io.NopCloser(strings.NewReader(tt.stream)),
+				NewStreamUsageObserver(logger, "", "openai", "req-run-1", "/v1/threads/thread_1/runs", nil),
+			)
+			if _, err := io.ReadAll(stream); err != nil {
+				t.Fatalf("ReadAll error: %v", err)
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close error: %v", err)
+			}
+
+			entries := logger.getEntries()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.InputTokens != tt.input || entry.OutputTokens != tt.output || entry.TotalTokens != tt.total {
+				t.Errorf("tokens = %d/%d/%d, want %d/%d/%d", entry.InputTokens, entry.OutputTokens, entry.TotalTokens, tt.input, tt.output, tt.total)
+			}
+			if entry.ProviderID != tt.providerID {
+				t.Errorf("ProviderID = %s, want %s", entry.ProviderID, tt.providerID)
+			}
+			if entry.Model != "gpt-4o" {
+				t.Errorf("Model = %s, want gpt-4o", entry.Model)
+			}
+		})
+	}
+}
+
+func TestStreamUsageObserverRecordsRequestedAndServedModels(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		streamData string
+	}{
+		{
+			name:     "chat completions",
+			endpoint: "/v1/chat/completions",
+			streamData: `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"content":"Hi"}}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4o-2024-08-06","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}
+
+data: [DONE]
+
+`,
+		},
+		{
+			name:     "responses",
+			endpoint: "/v1/responses",
+			streamData: `data: {"type":"response.output_text.delta","delta":"Hi"}
+
+data: {"type":"response.completed","response":{"id":"resp-1","model":"gpt-4o-2024-08-06","usage":{"input_tokens":3,"output_tokens":1,"total_tokens":4}}}
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &trackingLogger{enabled: true}
+			observer := NewStreamUsageObserver(logger, "gpt-4o", "openai", "req-1", tt.endpoint, nil)
+			observer.SetRequestedModel("smart")
+			stream := streaming.NewObservedSSEStream(io.NopCloser(strings.NewReader(tt.streamData)), observer)
+
+			if _, err := io.ReadAll(stream); err != nil {
+				t.Fatalf("ReadAll error: %v", err)
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close error: %v", err)
+			}
+
+			entries := logger.getEntries()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.RequestedModel != "smart" {
+				t.Errorf("RequestedModel = %s, want smart", entry.RequestedModel)
+			}
+			if entry.ServedModel != "gpt-4o-2024-08-06" {
+				t.Errorf("ServedModel = %s, want gpt-4o-2024-08-06", entry.ServedModel)
+			}
+		})
+	}
+}
+
 func TestStreamUsageObserverLargeResponsesDone(t *testing.T) {
 	largeText := strings.Repeat("This is a long response from the model. ", 300)
 	streamData := `event: response.created
@@ -438,3 +557,65 @@ data: [DONE]
 		t.Errorf("TotalTokens = %d, want 8", entry.TotalTokens)
 	}
 }
+
+func TestStreamUsageObserverContentStats(t *testing.T) {
+	chatStream := `data: {"id":"chatcmpl-1","model":"gpt-4","choices":[{"index":0,"delta":{"content":"The cache keeps the "},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"gpt-4","choices":[{"index":1,"delta":{"content":"ignored second choice"},"finish_reason":null}]}
+
+data: {"id":"chatcmpl-1","model":"gpt-4","choices":[{"index":0,"delta":{"content":"answers of earlier requests."},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":8,"total_tokens":18}}
+
+data: [DONE]
+
+`
+	responsesStream := `event: response.output_text.delta
+data: {"type":"response.output_text.delta","delta":"The cache keeps the "}
+
+event: response.output_text.delta
+data: {"type":"response.output_text.delta","delta":"answers of earlier requests."}
+
+event: response.completed
+data: {"type":"response.completed","response":{"id":"resp-1","model":"gpt-5","usage":{"input_tokens":10,"output_tokens":8,"total_tokens":18}}}
+
+data: [DONE]
+
+`
+	tests := []struct {
+		name            string
+		stream          string
+		endpoint        string
+		contentAnalysis bool
+		wantLanguage    string
+		wantChars       int
+		wantWords       int
+	}{
+		{name: "chat", stream: chatStream, endpoint: "/v1/chat/completions", contentAnalysis: true, wantLanguage: "en", wantChars: 48, wantWords: 8},
+		{name: "responses", stream: responsesStream, endpoint: "/v1/responses", contentAnalysis: true, wantLanguage: "en", wantChars: 48, wantWords: 8},
+		{name: "disabled", stream: chatStream, endpoint: "/v1/chat/completions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &trackingLogger{enabled: true, contentAnalysis: tt.contentAnalysis}
+			stream := streaming.NewObservedSSEStream(
+				io.NopCloser(strings.NewReader(tt.stream)),
+				NewStreamUsageObserver(logger, "gpt-4", "openai", "req-content", tt.endpoint, nil),
+			)
+			if _, err := io.ReadAll(stream); err != nil {
+				t.Fatalf("ReadAll error: %v", err)
+			}
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close error: %v", err)
+			}
+
+			entries := logger.getEntries()
+			if len(entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.ContentLanguage != tt.wantLanguage || entry.ContentChars != tt.wantChars || entry.ContentWords != tt.wantWords {
+				t.Errorf("content = %q/%d chars/%d words, want %q/%d/%d",
+					entry.ContentLanguage, entry.ContentChars, entry.ContentWords, tt.wantLanguage, tt.wantChars, tt.wantWords)
+			}
+		})
+	}
+}
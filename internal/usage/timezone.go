@@ -7,6 +7,19 @@ import (
 
 const defaultUsageTimeZone = "UTC"
 
+// Week starts accepted by UsageQueryParams.WeekStart.
+const (
+	WeekStartMonday = "monday"
+	WeekStartSunday = "sunday"
+)
+
+// usageSundayWeeks reports whether weekly buckets start on Sunday. Sunday-start
+// weeks keep the YYYY-Www label of the ISO week their Monday belongs to, which
+// is the same as grouping each timestamp one local day later.
+func usageSundayWeeks(params UsageQueryParams) bool {
+	return strings.EqualFold(strings.TrimSpace(params.WeekStart), WeekStartSunday)
+}
+
 func usageTimeZone(params UsageQueryParams) string {
 	if strings.TrimSpace(params.TimeZone) == "" {
 		return defaultUsageTimeZone
@@ -5,6 +5,8 @@ package usage
 import (
 	"context"
 	"time"
+
+	"gomodel/internal/contentstats"
 )
 
 // UsageStore defines the interface for usage storage backends.
@@ -44,11 +46,57 @@ type UsageEntry struct {
 	UserPath     string `json:"user_path,omitempty" bson:"user_path,omitempty"`
 	CacheType    string `json:"cache_type,omitempty" bson:"cache_type,omitempty"`
 
+	// RequestedModel is the model string the client sent, before aliases,
+	// defaults and workflow resolution. ServedModel is the model the provider
+	// reported in its response body or final stream chunk (for example
+	// "gpt-4o-2024-08-06" for a "gpt-4o" request). Either is empty when unknown.
+	RequestedModel string `json:"requested_model,omitempty" bson:"requested_model,omitempty"`
+	ServedModel    string `json:"served_model,omitempty" bson:"served_model,omitempty"`
+
+	// Experiment and ExperimentVariant record the A/B experiment assignment
+	// that selected the model, when the request was part of one.
+	Experiment        string `json:"experiment,omitempty" bson:"experiment,omitempty"`
+	ExperimentVariant string `json:"experiment_variant,omitempty" bson:"experiment_variant,omitempty"`
+
+	// Labels holds the cost allocation labels of the request.
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+
 	// Standard token counts (normalized across providers)
 	InputTokens  int `json:"input_tokens" bson:"input_tokens"`
 	OutputTokens int `json:"output_tokens" bson:"output_tokens"`
 	TotalTokens  int `json:"total_tokens" bson:"total_tokens"`
 
+	// ImageCount and ImageBytes count the inline base64 images of the request
+	// and their decoded size. Images are billed by the provider as input
+	// tokens; these fields only make image-heavy requests visible.
+	ImageCount int   `json:"image_count,omitempty" bson:"image_count,omitempty"`
+	ImageBytes int64 `json:"image_bytes,omitempty" bson:"image_bytes,omitempty"`
+
+	// TemplateName and TemplateVersion identify the stored prompt template
+	// rendered into the request, so prompt changes can be correlated with
+	// usage and quality shifts.
+	TemplateName    string `json:"template_name,omitempty" bson:"template_name,omitempty"`
+	TemplateVersion int    `json:"template_version,omitempty" bson:"template_version,omitempty"`
+
+	// UserHash is the hash of the user request field, used to attribute usage
+	// to end users. User holds the raw value and is only recorded when the
+	// gateway is configured to keep it.
+	UserHash string `json:"user_hash,omitempty" bson:"user_hash,omitempty"`
+	User     string `json:"user,omitempty" bson:"user,omitempty"`
+
+	// AuthKeyID is the managed auth key that authenticated the request, so
+	// key holders can read their own usage. Empty for master-key requests.
+	AuthKeyID string `json:"auth_key_id,omitempty" bson:"auth_key_id,omitempty"`
+
+	// ContentLanguage, ContentChars and ContentWords describe the assistant
+	// output of chat and Responses requests when content analysis is on:
+	// its detected language ("und" when undetermined) and its length. The
+	// text itself is never stored. ContentLanguage is empty for entries that
+	// were not analyzed.
+	ContentLanguage string `json:"content_language,omitempty" bson:"content_language,omitempty"`
+	ContentChars    int    `json:"content_chars,omitempty" bson:"content_chars,omitempty"`
+	ContentWords    int    `json:"content_words,omitempty" bson:"content_words,omitempty"`
+
 	// RawData contains provider-specific extended usage data (JSONB)
 	// Examples:
 	//   OpenAI: {"cached_tokens": 100, "reasoning_tokens": 50}
@@ -66,6 +114,21 @@ type UsageEntry struct {
 	CostsCalculationCaveat string `json:"costs_calculation_caveat,omitempty" bson:"costs_calculation_caveat,omitempty"`
 }
 
+// ToolLoopIterationsKey is the RawData key holding how many model calls the
+// server-side tool loop of a request made. The entry's token counts are the
+// sum over those calls.
+const ToolLoopIterationsKey = "tool_loop_iterations"
+
+// WithToolLoopIterations returns rawData with the tool loop iteration count
+// set, allocating the map when needed.
+func WithToolLoopIterations(rawData map[string]any, iterations int) map[string]any {
+	if rawData == nil {
+		rawData = make(map[string]any, 1)
+	}
+	rawData[ToolLoopIterationsKey] = iterations
+	return rawData
+}
+
 // Config holds usage tracking configuration
 type Config struct {
 	// Enabled controls whether usage tracking is active
@@ -84,8 +147,30 @@ type Config struct {
 
 	// RetentionDays is how long to keep usage data (0 = forever)
 	RetentionDays int
+
+	// DrainTimeout bounds how long Flush and Close wait for buffered entries
+	// to reach the store
+	DrainTimeout time.Duration
+
+	// WriteLagWarning logs a warning while the oldest unwritten entry is
+	// older than this (0 = disabled)
+	WriteLagWarning time.Duration
+
+	// ContentAnalysis records the language and length of assistant output
+	// on chat and Responses usage entries
+	ContentAnalysis bool
+
+	// ContentSampleChars is how many leading characters of the output the
+	// language is detected from
+	ContentSampleChars int
 }
 
+// Defaults for Config.DrainTimeout and Config.WriteLagWarning.
+const (
+	DefaultDrainTimeout    = 10 * time.Second
+	DefaultWriteLagWarning = time.Minute
+)
+
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() Config {
 	return Config{
@@ -94,5 +179,9 @@ func DefaultConfig() Config {
 		BufferSize:                1000,
 		FlushInterval:             5 * time.Second,
 		RetentionDays:             90,
+		DrainTimeout:              DefaultDrainTimeout,
+		WriteLagWarning:           DefaultWriteLagWarning,
+		ContentAnalysis:           true,
+		ContentSampleChars:        contentstats.DefaultSampleChars,
 	}
 }
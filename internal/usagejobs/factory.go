@@ -0,0 +1,92 @@
+package usagejobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"gomodel/config"
+	"gomodel/internal/storage"
+	"gomodel/internal/usage"
+)
+
+// Result holds the initialized usage job service and owned resources.
+type Result struct {
+	Service *Service
+	Store   Store
+
+	stopWorker func()
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+// StartWorker starts running queued and interrupted jobs. The worker stops
+// when the result is closed.
+func (r *Result) StartWorker() {
+	if r == nil || r.Service == nil || r.stopWorker != nil {
+		return
+	}
+	r.stopWorker = r.Service.Start()
+}
+
+// Close stops the worker and releases resources held by the job store.
+func (r *Result) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.closeOnce.Do(func() {
+		if r.stopWorker != nil {
+			r.stopWorker()
+			r.stopWorker = nil
+		}
+
+		var errs []error
+		if r.Store != nil {
+			if err := r.Store.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("store close: %w", err))
+			}
+		}
+		if len(errs) > 0 {
+			r.closeErr = fmt.Errorf("close errors: %w", errors.Join(errs...))
+		}
+	})
+	return r.closeErr
+}
+
+// NewWithSharedStorage creates a usage job service on the storage connection
+// that holds the usage data. The job table lives next to the usage table.
+func NewWithSharedStorage(ctx context.Context, shared storage.Storage, pricing usage.PricingResolver, cfg config.UsageConfig) (*Result, error) {
+	if shared == nil {
+		return nil, fmt.Errorf("shared storage is required")
+	}
+	store, err := createStore(ctx, shared)
+	if err != nil {
+		return nil, err
+	}
+	bulk, err := usage.NewBulkStore(shared)
+	if err != nil {
+		return nil, err
+	}
+	service, err := NewService(store, bulk, pricing, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Service: service,
+		Store:   store,
+	}, nil
+}
+
+func createStore(ctx context.Context, store storage.Storage) (Store, error) {
+	return storage.ResolveBackend[Store](
+		store,
+		func(db *sql.DB) (Store, error) { return NewSQLiteStore(db) },
+		func(pool *pgxpool.Pool) (Store, error) { return NewPostgreSQLStore(ctx, pool) },
+		func(db *mongo.Database) (Store, error) { return NewMongoDBStore(db) },
+	)
+}
@@ -0,0 +1,272 @@
+package usagejobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gomodel/config"
+	"gomodel/internal/usage"
+)
+
+// Service persists usage jobs and runs them one at a time in the background.
+//
+// A job persists its cursor after every batch. A job interrupted by a
+// shutdown or crash resumes after the last persisted batch when the worker
+// starts again; a batch that ran but was not persisted runs again, which both
+// corrections tolerate.
+type Service struct {
+	store   Store
+	bulk    usage.BulkStore
+	pricing usage.PricingResolver
+	cfg     config.UsageConfig
+	now     func() time.Time
+	wake    chan struct{}
+}
+
+// NewService creates a usage job service. pricing may be nil, in which case
+// recomputed rows get no costs.
+func NewService(store Store, bulk usage.BulkStore, pricing usage.PricingResolver, cfg config.UsageConfig) (*Service, error) {
+	if store == nil {
+		return nil, fmt.Errorf("usage job store is required")
+	}
+	if bulk == nil {
+		return nil, fmt.Errorf("usage bulk store is required")
+	}
+	return &Service{
+		store:   store,
+		bulk:    bulk,
+		pricing: pricing,
+		cfg:     cfg,
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+	}, nil
+}
+
+// validate checks params for a job of kind and returns its row filter.
+func validate(kind Kind, params Params) (usage.BulkFilter, error) {
+	switch kind {
+	case KindRecomputeCosts:
+		if params.StartDate == "" || params.EndDate == "" {
+			return usage.BulkFilter{}, newValidationError("start_date and end_date are required")
+		}
+		if params.ToAuthKeyID != "" {
+			return usage.BulkFilter{}, newValidationError("to_auth_key_id only applies to reattribute_usage jobs")
+		}
+	case KindReattribute:
+		if params.FromAuthKeyID == "" || params.ToAuthKeyID == "" {
+			return usage.BulkFilter{}, newValidationError("from_auth_key_id and to_auth_key_id are required")
+		}
+		if params.FromAuthKeyID == params.ToAuthKeyID {
+			return usage.BulkFilter{}, newValidationError("from_auth_key_id and to_auth_key_id must differ")
+		}
+	default:
+		return usage.BulkFilter{}, newValidationError(fmt.Sprintf("unknown usage job kind %q", kind))
+	}
+	return params.filter()
+}
+
+// DryRun returns the number of usage rows a job of kind with params would
+// process, without changing anything.
+func (s *Service) DryRun(ctx context.Context, kind Kind, params Params) (int64, error) {
+	filter, err := validate(kind, params)
+	if err != nil {
+		return 0, err
+	}
+	return s.bulk.CountRows(ctx, filter)
+}
+
+// Submit persists a queued job of kind and wakes the worker. actor
+// identifies the admin credential that submitted it.
+func (s *Service) Submit(ctx context.Context, kind Kind, params Params, actor string) (*Job, error) {
+	filter, err := validate(kind, params)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.bulk.CountRows(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	job := &Job{
+		ID:        "usagejob_" + uuid.NewString(),
+		Object:    "usage_job",
+		Kind:      kind,
+		Status:    StatusQueued,
+		Params:    params,
+		Actor:     actor,
+		Total:     total,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	slog.Info("usage job submitted", "id", job.ID, "kind", kind, "actor", actor, "params", params, "total_rows", total)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns a usage job by id.
+func (s *Service) Get(ctx context.Context, id string) (*Job, error) {
+	return s.store.Get(ctx, id)
+}
+
+// Start runs the worker until the returned stop function is called. It first
+// resumes the jobs left unfinished by an earlier run.
+func (s *Service) Start() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(done)
+		for {
+			s.runUnfinished(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake:
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+func (s *Service) runUnfinished(ctx context.Context) {
+	jobs, err := s.store.ListUnfinished(ctx)
+	if err != nil {
+		slog.Warn("usage jobs: list unfinished jobs failed", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, job)
+	}
+}
+
+func (s *Service) batchSize() int {
+	if s.cfg.JobBatchSize <= 0 || s.cfg.JobBatchSize > usage.MaxBulkBatchSize {
+		return usage.MaxBulkBatchSize
+	}
+	return s.cfg.JobBatchSize
+}
+
+// run processes job batch by batch until no matching rows are left. On
+// shutdown it returns with the job still running, to resume from its cursor.
+func (s *Service) run(ctx context.Context, job *Job) {
+	filter, err := validate(job.Kind, job.Params)
+	if err != nil {
+		s.finish(ctx, job, err)
+		return
+	}
+
+	if job.Status == StatusQueued {
+		now := s.now().UTC()
+		job.Status = StatusRunning
+		job.StartedAt = &now
+		job.UpdatedAt = now
+		if err := s.store.Update(ctx, job); err != nil {
+			slog.Warn("usage jobs: update failed", "id", job.ID, "error", err)
+			return
+		}
+	} else {
+		slog.Info("usage job resumed", "id", job.ID, "kind", job.Kind, "cursor", job.Cursor)
+	}
+
+	for {
+		processed, changed, cursor, err := s.runBatch(ctx, job, filter)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.finish(ctx, job, err)
+			return
+		}
+		if cursor == "" {
+			s.finish(ctx, job, nil)
+			return
+		}
+
+		job.Processed += int64(processed)
+		job.Changed += int64(changed)
+		job.Cursor = cursor
+		job.UpdatedAt = s.now().UTC()
+		if err := s.store.Update(ctx, job); err != nil {
+			slog.Warn("usage jobs: update failed", "id", job.ID, "error", err)
+			return
+		}
+
+		if s.cfg.JobBatchDelay > 0 {
+			timer := time.NewTimer(s.cfg.JobBatchDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// runBatch processes the batch after job.Cursor. It returns the rows it
+// walked and changed, and the new cursor, which is empty when no rows were
+// left.
+func (s *Service) runBatch(ctx context.Context, job *Job, filter usage.BulkFilter) (int, int, string, error) {
+	switch job.Kind {
+	case KindRecomputeCosts:
+		rows, err := s.bulk.ListCostRows(ctx, filter, job.Cursor, s.batchSize())
+		if err != nil || len(rows) == 0 {
+			return 0, 0, "", err
+		}
+		var changed []usage.CostRow
+		for _, row := range rows {
+			if updated, ok := usage.RecomputeCost(row, s.pricing); ok {
+				changed = append(changed, updated)
+			}
+		}
+		if err := s.bulk.UpdateCosts(ctx, changed); err != nil {
+			return 0, 0, "", err
+		}
+		return len(rows), len(changed), rows[len(rows)-1].ID, nil
+	case KindReattribute:
+		moved, cursor, err := s.bulk.Reattribute(ctx, filter, job.Params.ToAuthKeyID, job.Cursor, s.batchSize())
+		return moved, moved, cursor, err
+	default:
+		return 0, 0, "", fmt.Errorf("unknown usage job kind %q", job.Kind)
+	}
+}
+
+// finish marks job succeeded, or failed with err, and logs the outcome.
+func (s *Service) finish(ctx context.Context, job *Job, err error) {
+	now := s.now().UTC()
+	job.Status = StatusSucceeded
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	}
+	job.UpdatedAt = now
+	job.CompletedAt = &now
+	if err := s.store.Update(context.WithoutCancel(ctx), job); err != nil {
+		slog.Warn("usage jobs: update failed", "id", job.ID, "status", job.Status, "error", err)
+	}
+	slog.Info("usage job finished", "id", job.ID, "kind", job.Kind, "actor", job.Actor, "status", job.Status,
+		"processed_rows", job.Processed, "changed_rows", job.Changed, "error", job.Error)
+}
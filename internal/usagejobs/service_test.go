@@ -0,0 +1,241 @@
+package usagejobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/storage"
+	"gomodel/internal/usage"
+)
+
+type fixedPricing map[string]*core.ModelPricing
+
+func (p fixedPricing) ResolvePricing(model, _ string) *core.ModelPricing {
+	return p[model]
+}
+
+func float64Ptr(v float64) *float64 { return &v }
+
+// gpt4oPricing prices the seeded rows at 0.002 input plus 0.008 output.
+var gpt4oPricing = fixedPricing{"gpt-4o": {InputPerMtok: float64Ptr(2), OutputPerMtok: float64Ptr(8)}}
+
+// newTestDB returns a SQLite database holding 20 January usage rows, which
+// alternate between gpt-4o on key-a and gpt-4o-mini on key-b, and 4 February
+// gpt-4o rows on key-a.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	st, err := storage.NewSQLite(storage.SQLiteConfig{Path: filepath.Join(t.TempDir(), "usage.db")})
+	if err != nil {
+		t.Fatalf("new sqlite storage: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+
+	usageStore, err := usage.NewSQLiteStore(st.DB(), 0)
+	if err != nil {
+		t.Fatalf("new sqlite usage store: %v", err)
+	}
+	var entries []*usage.UsageEntry
+	for i := range 24 {
+		entry := &usage.UsageEntry{
+			ID:           fmt.Sprintf("usage-%02d", i),
+			RequestID:    fmt.Sprintf("req-%02d", i),
+			Timestamp:    time.Date(2026, 1, 10, 12, 0, i, 0, time.UTC),
+			Model:        "gpt-4o",
+			Provider:     "openai",
+			Endpoint:     "/v1/chat/completions",
+			InputTokens:  1000,
+			OutputTokens: 1000,
+			AuthKeyID:    "key-a",
+		}
+		if i%2 == 1 {
+			entry.Model, entry.AuthKeyID = "gpt-4o-mini", "key-b"
+		}
+		if i >= 20 {
+			entry.Timestamp = time.Date(2026, 2, 10, 12, 0, i, 0, time.UTC)
+			entry.Model, entry.AuthKeyID = "gpt-4o", "key-a"
+		}
+		entries = append(entries, entry)
+	}
+	if err := usageStore.WriteBatch(context.Background(), entries); err != nil {
+		t.Fatalf("write usage entries: %v", err)
+	}
+	return st.DB()
+}
+
+func newTestService(t *testing.T, db *sql.DB, batchSize int, batchDelay time.Duration) *Service {
+	t.Helper()
+	store, err := NewSQLiteStore(db)
+	if err != nil {
+		t.Fatalf("new sqlite job store: %v", err)
+	}
+	bulk, err := usage.NewSQLiteBulkStore(db)
+	if err != nil {
+		t.Fatalf("new sqlite bulk store: %v", err)
+	}
+	service, err := NewService(store, bulk, gpt4oPricing, config.UsageConfig{JobBatchSize: batchSize, JobBatchDelay: batchDelay})
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	return service
+}
+
+// waitForJob polls job id until done reports true for it.
+func waitForJob(t *testing.T, service *Service, id string, done func(*Job) bool) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := service.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", id, err)
+		}
+		if done(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not get there in time: %+v", id, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func finished(job *Job) bool { return job.Status.Final() }
+
+func countRows(t *testing.T, db *sql.DB, where string, args ...any) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM usage WHERE "+where, args...).Scan(&count); err != nil {
+		t.Fatalf("count usage rows: %v", err)
+	}
+	return count
+}
+
+var januaryGPT4o = Params{StartDate: "2026-01-01", EndDate: "2026-01-31", Model: "gpt-4o"}
+
+func TestRecomputeCosts_BatchesOnlyFilteredRows(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestService(t, db, 3, 0)
+	defer service.Start()()
+
+	job, err := service.Submit(context.Background(), KindRecomputeCosts, januaryGPT4o, "admin-hash")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if job.Total != 10 || job.Actor != "admin-hash" {
+		t.Fatalf("submitted job = %+v, want 10 rows by admin-hash", job)
+	}
+
+	job = waitForJob(t, service, job.ID, finished)
+	if job.Status != StatusSucceeded || job.Processed != 10 || job.Changed != 10 || job.CompletedAt == nil {
+		t.Fatalf("finished job = %+v", job)
+	}
+	if got := countRows(t, db, "ABS(total_cost - 0.01) < 1e-9"); got != 10 {
+		t.Fatalf("priced rows = %d, want the 10 January gpt-4o rows", got)
+	}
+	if got := countRows(t, db, "total_cost IS NOT NULL AND (model != 'gpt-4o' OR timestamp >= '2026-02-01')"); got != 0 {
+		t.Fatalf("%d rows outside the filter were priced", got)
+	}
+
+	// A second run finds nothing left to change.
+	again, err := service.Submit(context.Background(), KindRecomputeCosts, januaryGPT4o, "admin-hash")
+	if err != nil {
+		t.Fatalf("Submit again: %v", err)
+	}
+	again = waitForJob(t, service, again.ID, finished)
+	if again.Status != StatusSucceeded || again.Processed != 10 || again.Changed != 0 {
+		t.Fatalf("second job = %+v, want 10 processed and none changed", again)
+	}
+}
+
+func TestJobResumesAfterRestart(t *testing.T) {
+	db := newTestDB(t)
+	// The long pause holds the job after its first batch.
+	first := newTestService(t, db, 4, time.Hour)
+	stop := first.Start()
+
+	job, err := first.Submit(context.Background(), KindRecomputeCosts, januaryGPT4o, "admin-hash")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	job = waitForJob(t, first, job.ID, func(job *Job) bool { return job.Processed > 0 })
+	stop()
+
+	job, err = first.Get(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if job.Status != StatusRunning || job.Processed != 4 || job.Cursor == "" {
+		t.Fatalf("interrupted job = %+v, want running after one batch of 4", job)
+	}
+
+	second := newTestService(t, db, 4, 0)
+	defer second.Start()()
+	job = waitForJob(t, second, job.ID, finished)
+	if job.Status != StatusSucceeded || job.Processed != 10 || job.Changed != 10 {
+		t.Fatalf("resumed job = %+v, want all 10 rows processed once", job)
+	}
+	if got := countRows(t, db, "total_cost IS NOT NULL"); got != 10 {
+		t.Fatalf("priced rows = %d, want 10", got)
+	}
+}
+
+func TestReattribute_DryRunMatchesMovedRows(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestService(t, db, 3, 0)
+	defer service.Start()()
+
+	params := Params{StartDate: "2026-01-01", EndDate: "2026-01-31", FromAuthKeyID: "key-a", ToAuthKeyID: "key-c"}
+	dryRun, err := service.DryRun(context.Background(), KindReattribute, params)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if got := countRows(t, db, "auth_key_id = 'key-c'"); got != 0 {
+		t.Fatalf("dry run moved %d rows", got)
+	}
+
+	job, err := service.Submit(context.Background(), KindReattribute, params, "admin-hash")
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	job = waitForJob(t, service, job.ID, finished)
+	if job.Status != StatusSucceeded || job.Changed != dryRun || dryRun != 10 {
+		t.Fatalf("job = %+v, dry run = %d; want both 10", job, dryRun)
+	}
+	if got := countRows(t, db, "auth_key_id = 'key-c'"); int64(got) != dryRun {
+		t.Fatalf("moved rows = %d, dry run = %d", got, dryRun)
+	}
+	if got := countRows(t, db, "auth_key_id = 'key-a'"); got != 4 {
+		t.Fatalf("key-a rows = %d, want the 4 February rows left", got)
+	}
+}
+
+func TestSubmit_Validation(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestService(t, db, 3, 0)
+
+	tests := []struct {
+		name   string
+		kind   Kind
+		params Params
+	}{
+		{"recompute without dates", KindRecomputeCosts, Params{Model: "gpt-4o"}},
+		{"recompute with target key", KindRecomputeCosts, Params{StartDate: "2026-01-01", EndDate: "2026-01-31", ToAuthKeyID: "key-c"}},
+		{"bad date", KindRecomputeCosts, Params{StartDate: "January", EndDate: "2026-01-31"}},
+		{"reversed dates", KindRecomputeCosts, Params{StartDate: "2026-02-01", EndDate: "2026-01-31"}},
+		{"reattribute without target", KindReattribute, Params{FromAuthKeyID: "key-a"}},
+		{"reattribute to itself", KindReattribute, Params{FromAuthKeyID: "key-a", ToAuthKeyID: "key-a"}},
+		{"unknown kind", Kind("delete_usage"), Params{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Submit(context.Background(), tt.kind, tt.params, ""); !IsValidationError(err) {
+				t.Fatalf("Submit error = %v, want a validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package usagejobs runs the batched admin corrections of stored usage data:
+// recomputing costs after a pricing fix and moving usage between API keys.
+// Jobs persist their progress, so they resume after a restart.
+package usagejobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gomodel/internal/usage"
+)
+
+// ErrNotFound indicates a requested usage job was not found.
+var ErrNotFound = errors.New("usage job not found")
+
+// ValidationError indicates invalid usage job input.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+func newValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// IsValidationError reports whether err is a validation error.
+func IsValidationError(err error) bool {
+	_, ok := errors.AsType[*ValidationError](err)
+	return ok
+}
+
+// Kind names the correction a job runs.
+type Kind string
+
+const (
+	// KindRecomputeCosts prices the matching usage rows again with the
+	// current model pricing.
+	KindRecomputeCosts Kind = "recompute_costs"
+	// KindReattribute moves the matching usage rows to another API key.
+	KindReattribute Kind = "reattribute_usage"
+)
+
+// Status is the lifecycle state of a usage job.
+type Status string
+
+const (
+	// StatusQueued waits for the worker.
+	StatusQueued Status = "queued"
+	// StatusRunning is being processed, or was when the gateway stopped.
+	StatusRunning Status = "running"
+	// StatusSucceeded processed every matching row.
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed stopped on an error.
+	StatusFailed Status = "failed"
+)
+
+// Final reports whether the worker is done with a job in status s.
+func (s Status) Final() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Params selects the usage rows a job touches.
+type Params struct {
+	StartDate     string `json:"start_date,omitempty"` // YYYY-MM-DD, inclusive
+	EndDate       string `json:"end_date,omitempty"`   // YYYY-MM-DD, inclusive
+	Model         string `json:"model,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	FromAuthKeyID string `json:"from_auth_key_id,omitempty"`
+	ToAuthKeyID   string `json:"to_auth_key_id,omitempty"`
+}
+
+// filter converts p into the usage bulk filter.
+func (p Params) filter() (usage.BulkFilter, error) {
+	filter := usage.BulkFilter{
+		Model:     p.Model,
+		Provider:  p.Provider,
+		AuthKeyID: p.FromAuthKeyID,
+	}
+	var err error
+	if p.StartDate != "" {
+		if filter.StartDate, err = time.Parse("2006-01-02", p.StartDate); err != nil {
+			return usage.BulkFilter{}, newValidationError("invalid start_date, expected YYYY-MM-DD")
+		}
+	}
+	if p.EndDate != "" {
+		if filter.EndDate, err = time.Parse("2006-01-02", p.EndDate); err != nil {
+			return usage.BulkFilter{}, newValidationError("invalid end_date, expected YYYY-MM-DD")
+		}
+	}
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.EndDate.Before(filter.StartDate) {
+		return usage.BulkFilter{}, newValidationError("end_date must not be before start_date")
+	}
+	return filter, nil
+}
+
+// Job is one persisted usage correction together with its progress.
+type Job struct {
+	ID     string `json:"id"`
+	Object string `json:"object"`
+	Kind   Kind   `json:"kind"`
+	Status Status `json:"status"`
+	Params Params `json:"params"`
+	// Actor is the hash of the admin credential that submitted the job.
+	Actor string `json:"actor,omitempty"`
+
+	// Total is the number of matching rows when the job was submitted.
+	Total int64 `json:"total_rows"`
+	// Processed counts the rows the job walked; Changed counts the rows whose
+	// costs changed or that moved to the new key.
+	Processed int64 `json:"processed_rows"`
+	Changed   int64 `json:"changed_rows"`
+	// Cursor is the last usage row ID processed. A resumed job continues after it.
+	Cursor string `json:"cursor,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Store defines persistence operations for usage jobs.
+type Store interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+	// ListUnfinished returns queued and running jobs, oldest first.
+	ListUnfinished(ctx context.Context) ([]*Job, error)
+	Close() error
+}
+
+func serializeJob(job *Job) ([]byte, error) {
+	if job == nil {
+		return nil, fmt.Errorf("usage job is nil")
+	}
+	if job.ID == "" {
+		return nil, fmt.Errorf("usage job ID is empty")
+	}
+	b, err := json.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("marshal usage job: %w", err)
+	}
+	return b, nil
+}
+
+func deserializeJob(raw []byte) (*Job, error) {
+	var job Job
+	if err := json.Unmarshal(raw, &job); err != nil {
+		return nil, fmt.Errorf("unmarshal usage job: %w", err)
+	}
+	return &job, nil
+}
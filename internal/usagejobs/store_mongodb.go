@@ -0,0 +1,138 @@
+package usagejobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoJobDocument struct {
+	ID        string `bson:"_id"`
+	CreatedAt int64  `bson:"created_at"`
+	UpdatedAt int64  `bson:"updated_at"`
+	Status    string `bson:"status"`
+	Data      []byte `bson:"data"`
+}
+
+// MongoDBStore stores usage jobs in MongoDB.
+type MongoDBStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDBStore creates collection indexes if needed.
+func NewMongoDBStore(database *mongo.Database) (*MongoDBStore, error) {
+	if database == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+
+	coll := database.Collection("usage_jobs")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	index := mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}}
+	if _, err := coll.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, fmt.Errorf("create usage_jobs indexes: %w", err)
+	}
+
+	return &MongoDBStore{collection: coll}, nil
+}
+
+// Create inserts a new usage job.
+func (s *MongoDBStore) Create(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	doc := mongoJobDocument{
+		ID:        job.ID,
+		CreatedAt: job.CreatedAt.UnixMilli(),
+		UpdatedAt: time.Now().UnixMilli(),
+		Status:    string(job.Status),
+		Data:      payload,
+	}
+	if _, err := s.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("insert usage job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a usage job by id.
+func (s *MongoDBStore) Get(ctx context.Context, id string) (*Job, error) {
+	var doc mongoJobDocument
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query usage job: %w", err)
+	}
+
+	job, err := deserializeJob(doc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode usage job: %w", err)
+	}
+	return job, nil
+}
+
+// Update replaces a stored usage job.
+func (s *MongoDBStore) Update(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": job.ID},
+		bson.M{"$set": bson.M{
+			"updated_at": time.Now().UnixMilli(),
+			"status":     string(job.Status),
+			"data":       payload,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("update usage job: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (s *MongoDBStore) ListUnfinished(ctx context.Context) ([]*Job, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx,
+		bson.M{"status": bson.M{"$in": bson.A{string(StatusQueued), string(StatusRunning)}}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished usage jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*Job
+	for cursor.Next(ctx) {
+		var doc mongoJobDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode usage job document: %w", err)
+		}
+		job, err := deserializeJob(doc.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode usage job payload: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage jobs cursor: %w", err)
+	}
+	return jobs, nil
+}
+
+// Close is a no-op; Mongo client lifecycle is managed by storage layer.
+func (s *MongoDBStore) Close() error {
+	return nil
+}
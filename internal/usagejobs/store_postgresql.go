@@ -0,0 +1,143 @@
+package usagejobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgreSQLStore stores usage jobs in PostgreSQL.
+type PostgreSQLStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLStore creates the usage_jobs table and indexes if needed.
+func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLStore, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is required")
+	}
+	if pool == nil {
+		return nil, fmt.Errorf("connection pool is required")
+	}
+
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates the usage_jobs table and its index. It is
+// idempotent: store constructors run it, and so does the usage jobs migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS usage_jobs (
+			id TEXT PRIMARY KEY,
+			created_at BIGINT NOT NULL,
+			updated_at BIGINT NOT NULL,
+			status TEXT NOT NULL,
+			data JSONB NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create usage_jobs table: %w", err)
+	}
+
+	if _, err := pool.Exec(ctx, "CREATE INDEX IF NOT EXISTS idx_usage_jobs_status ON usage_jobs(status, created_at)"); err != nil {
+		return fmt.Errorf("failed to create usage_jobs status index: %w", err)
+	}
+	return nil
+}
+
+// Create inserts a new usage job.
+func (s *PostgreSQLStore) Create(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO usage_jobs (id, created_at, updated_at, status, data)
+		VALUES ($1, $2, $3, $4, $5::jsonb)
+	`, job.ID, job.CreatedAt.UnixMilli(), time.Now().UnixMilli(), string(job.Status), payload)
+	if err != nil {
+		return fmt.Errorf("insert usage job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a usage job by id.
+func (s *PostgreSQLStore) Get(ctx context.Context, id string) (*Job, error) {
+	var payload []byte
+	err := s.pool.QueryRow(ctx, "SELECT data FROM usage_jobs WHERE id = $1", id).Scan(&payload)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query usage job: %w", err)
+	}
+
+	job, err := deserializeJob(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode usage job: %w", err)
+	}
+	return job, nil
+}
+
+// Update replaces a stored usage job.
+func (s *PostgreSQLStore) Update(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := s.pool.Exec(ctx, `
+		UPDATE usage_jobs SET updated_at = $1, status = $2, data = $3::jsonb WHERE id = $4
+	`, time.Now().UnixMilli(), string(job.Status), payload, job.ID)
+	if err != nil {
+		return fmt.Errorf("update usage job: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (s *PostgreSQLStore) ListUnfinished(ctx context.Context) ([]*Job, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT data FROM usage_jobs
+		WHERE status IN ($1, $2)
+		ORDER BY created_at ASC, id ASC
+	`, string(StatusQueued), string(StatusRunning))
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished usage jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("scan usage job row: %w", err)
+		}
+		job, err := deserializeJob(payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode usage job row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage job rows: %w", err)
+	}
+	return jobs, nil
+}
+
+// Close is a no-op; pool lifecycle is managed by storage layer.
+func (s *PostgreSQLStore) Close() error {
+	return nil
+}
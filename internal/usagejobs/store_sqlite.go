@@ -0,0 +1,142 @@
+package usagejobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLiteStore stores usage jobs in SQLite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates the usage_jobs table and indexes if needed.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection is required")
+	}
+
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates the usage_jobs table and its index. It is
+// idempotent: store constructors run it, and so does the usage jobs migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS usage_jobs (
+			id TEXT PRIMARY KEY,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			data TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create usage_jobs table: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_usage_jobs_status ON usage_jobs(status, created_at)"); err != nil {
+		return fmt.Errorf("failed to create usage_jobs status index: %w", err)
+	}
+	return nil
+}
+
+// Create inserts a new usage job.
+func (s *SQLiteStore) Create(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO usage_jobs (id, created_at, updated_at, status, data)
+		VALUES (?, ?, ?, ?, ?)
+	`, job.ID, job.CreatedAt.UnixMilli(), time.Now().UnixMilli(), string(job.Status), string(payload))
+	if err != nil {
+		return fmt.Errorf("insert usage job: %w", err)
+	}
+	return nil
+}
+
+// Get returns a usage job by id.
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Job, error) {
+	var payload string
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM usage_jobs WHERE id = ?", id).Scan(&payload)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("query usage job: %w", err)
+	}
+
+	job, err := deserializeJob([]byte(payload))
+	if err != nil {
+		return nil, fmt.Errorf("decode usage job: %w", err)
+	}
+	return job, nil
+}
+
+// Update replaces a stored usage job.
+func (s *SQLiteStore) Update(ctx context.Context, job *Job) error {
+	payload, err := serializeJob(job)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE usage_jobs SET updated_at = ?, status = ?, data = ? WHERE id = ?
+	`, time.Now().UnixMilli(), string(job.Status), string(payload), job.ID)
+	if err != nil {
+		return fmt.Errorf("update usage job: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read update rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUnfinished returns queued and running jobs, oldest first.
+func (s *SQLiteStore) ListUnfinished(ctx context.Context) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT data FROM usage_jobs
+		WHERE status IN (?, ?)
+		ORDER BY created_at ASC, id ASC
+	`, string(StatusQueued), string(StatusRunning))
+	if err != nil {
+		return nil, fmt.Errorf("list unfinished usage jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		var payload string
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("scan usage job row: %w", err)
+		}
+		job, err := deserializeJob([]byte(payload))
+		if err != nil {
+			return nil, fmt.Errorf("decode usage job row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage job rows: %w", err)
+	}
+	return jobs, nil
+}
+
+// Close is a no-op; DB lifecycle is managed by storage layer.
+func (s *SQLiteStore) Close() error {
+	return nil
+}
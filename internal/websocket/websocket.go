@@ -0,0 +1,413 @@
+// Package websocket implements the subset of RFC 6455 the gateway needs to
+// stream responses over WebSocket: the server handshake, a test client
+// handshake, unfragmented writes, fragmented reads, ping/pong and the
+// closing handshake. Extensions and subprotocols are not negotiated.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the RFC 6455 handshake
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opcodes of the frames this package reads and writes.
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// Close codes defined by RFC 6455.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// handshakeGUID is appended to the client key to compute the accept key.
+const handshakeGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxControlPayload is the largest payload a control frame may carry.
+const maxControlPayload = 125
+
+// writeTimeout bounds each frame write so a stuck client cannot block the
+// writer forever.
+const writeTimeout = 10 * time.Second
+
+var (
+	// ErrMessageTooBig is returned by ReadMessage for messages over the limit.
+	ErrMessageTooBig = errors.New("websocket: message too big")
+	// ErrClosed is returned by writes after the closing handshake started.
+	ErrClosed = errors.New("websocket: connection closed")
+)
+
+// CloseError is returned by ReadMessage when the peer sent a close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed by peer with code %d %s", e.Code, e.Reason)
+}
+
+// IsUpgrade reports whether r asks to be upgraded to a WebSocket.
+func IsUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade completes the server handshake for r and takes over the
+// connection. On error nothing has been written to w, so the caller may
+// still answer with a normal HTTP error.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !IsUpgrade(r) {
+		return nil, errors.New("not a websocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("unsupported Sec-WebSocket-Version, want 13")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, errors.New("invalid Sec-WebSocket-Key")
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("hijack connection: %w", err)
+	}
+	// Clear any deadline the HTTP server set for the request.
+	_ = netConn.SetDeadline(time.Time{})
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	return newConn(netConn, rw.Reader, false), nil
+}
+
+// Dial opens a client connection to a ws:// URL. It is meant for tests and
+// tools; header is sent with the handshake request.
+func Dial(url string, header http.Header) (*Conn, *http.Response, error) {
+	addr, path, ok := strings.Cut(strings.TrimPrefix(url, "ws://"), "/")
+	if !ok || !strings.HasPrefix(url, "ws://") {
+		return nil, nil, fmt.Errorf("websocket: unsupported URL %q", url)
+	}
+	netConn, err := net.DialTimeout("tcp", addr, writeTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/"+path, nil)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := req.Write(netConn); err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = netConn.Close()
+		return nil, resp, fmt.Errorf("websocket: handshake failed with status %d", resp.StatusCode)
+	}
+	return newConn(netConn, br, true), resp, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + handshakeGUID)) //nolint:gosec // required by the RFC 6455 handshake
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for part := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Conn is an open WebSocket connection. ReadMessage must be called from one
+// goroutine at a time; writes are safe for concurrent use.
+type Conn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // clients mask the frames they send
+
+	idleTimeout time.Duration
+
+	writeMu    sync.Mutex
+	closeSent  bool
+	closedOnce sync.Once
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, client bool) *Conn {
+	return &Conn{conn: conn, br: br, client: client}
+}
+
+// SetIdleTimeout makes reads fail when no frame, including pongs, arrives
+// for d. Zero disables it.
+func (c *Conn) SetIdleTimeout(d time.Duration) {
+	c.idleTimeout = d
+	c.refreshReadDeadline()
+}
+
+func (c *Conn) refreshReadDeadline() {
+	if c.idleTimeout > 0 {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+	} else {
+		_ = c.conn.SetReadDeadline(time.Time{})
+	}
+}
+
+// ReadMessage returns the next text or binary message, joining fragments.
+// Pings are answered and pongs skipped. A close frame from the peer is
+// answered and returned as a *CloseError. Messages over maxBytes fail with
+// ErrMessageTooBig; zero means no limit.
+func (c *Conn) ReadMessage(maxBytes int64) (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame(maxBytes)
+		if err != nil {
+			return 0, nil, err
+		}
+		c.refreshReadDeadline()
+
+		switch op {
+		case OpPing:
+			if err := c.writeFrame(OpPong, payload); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			reply := closeErr.Code
+			if reply == CloseNoStatus {
+				reply = CloseNormal
+			}
+			_ = c.Close(reply, "")
+			return 0, nil, closeErr
+		case OpText, OpBinary:
+			if opcode != 0 {
+				return 0, nil, c.fail("new message before the previous one finished")
+			}
+			opcode = op
+		case OpContinuation:
+			if opcode == 0 {
+				return 0, nil, c.fail("continuation frame without a message")
+			}
+		default:
+			return 0, nil, c.fail("unknown opcode")
+		}
+
+		if maxBytes > 0 && int64(len(message)+len(payload)) > maxBytes {
+			_ = c.Close(CloseMessageTooBig, "message too big")
+			return 0, nil, ErrMessageTooBig
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame(maxBytes int64) (fin bool, opcode int, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail("reserved bits set")
+	}
+	opcode = int(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+	if masked == c.client {
+		return false, 0, nil, c.fail("wrong frame masking")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= OpClose && (length > maxControlPayload || !fin) {
+		return false, 0, nil, c.fail("invalid control frame")
+	}
+	if maxBytes > 0 && length > uint64(maxBytes) {
+		_ = c.Close(CloseMessageTooBig, "message too big")
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail closes the connection with a protocol error.
+func (c *Conn) fail(reason string) error {
+	_ = c.Close(CloseProtocolError, reason)
+	return errors.New("websocket: " + reason)
+}
+
+// WriteText sends data as one text message.
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(OpText, data)
+}
+
+// Ping sends a ping frame.
+func (c *Conn) Ping() error {
+	return c.writeFrame(OpPing, nil)
+}
+
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	if opcode == OpClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|byte(opcode))
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		_, _ = rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, payload...)
+		for i := range payload {
+			frame[start+i] ^= mask[i%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// WriteClose starts the closing handshake by sending a close frame with code
+// and reason, unless one was already sent. The peer answers with its own
+// close frame, which ReadMessage returns. Reasons are cut to fit a control
+// frame.
+func (c *Conn) WriteClose(code int, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+	err := c.writeFrame(OpClose, payload)
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close sends a close frame like WriteClose and closes the connection
+// without waiting for the peer's answer.
+func (c *Conn) Close(code int, reason string) error {
+	err := c.WriteClose(code, reason)
+	c.closedOnce.Do(func() {
+		if closeErr := c.conn.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
@@ -0,0 +1,117 @@
+package websocket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve starts a server that upgrades every request and hands the
+// connection to handle.
+func serve(t *testing.T, handle func(*Conn)) string {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handle(conn)
+	}))
+	t.Cleanup(ts.Close)
+	return "ws" + strings.TrimPrefix(ts.URL, "http") + "/"
+}
+
+func TestConn_EchoAndClose(t *testing.T) {
+	url := serve(t, func(conn *Conn) {
+		_, data, err := conn.ReadMessage(0)
+		if err != nil {
+			return
+		}
+		_ = conn.WriteText(append([]byte("echo: "), data...))
+		_ = conn.Close(CloseNormal, "bye")
+	})
+
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	long := strings.Repeat("x", 70000) // needs the 64-bit length form
+	if err := conn.WriteText([]byte(long)); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	op, data, err := conn.ReadMessage(0)
+	if err != nil || op != OpText || string(data) != "echo: "+long {
+		t.Fatalf("ReadMessage() = %d, %d bytes, %v", op, len(data), err)
+	}
+	_, _, err = conn.ReadMessage(0)
+	closeErr, ok := errors.AsType[*CloseError](err)
+	if !ok || closeErr.Code != CloseNormal || closeErr.Reason != "bye" {
+		t.Fatalf("ReadMessage() error = %v, want close 1000 bye", err)
+	}
+}
+
+func TestConn_AnswersPingsAndRejectsLargeMessages(t *testing.T) {
+	result := make(chan error, 1)
+	url := serve(t, func(conn *Conn) {
+		_, _, err := conn.ReadMessage(8)
+		result <- err
+	})
+
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if err := conn.writeFrame(OpPing, []byte("p")); err != nil {
+		t.Fatalf("ping error = %v", err)
+	}
+	if err := conn.WriteText([]byte("far more than eight bytes")); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if err := <-result; !errors.Is(err, ErrMessageTooBig) {
+		t.Fatalf("server ReadMessage() error = %v, want ErrMessageTooBig", err)
+	}
+	_, _, err = conn.ReadMessage(0)
+	if closeErr, ok := errors.AsType[*CloseError](err); !ok || closeErr.Code != CloseMessageTooBig {
+		t.Fatalf("client ReadMessage() error = %v, want close 1009", err)
+	}
+}
+
+func TestConn_IdleTimeout(t *testing.T) {
+	result := make(chan error, 1)
+	url := serve(t, func(conn *Conn) {
+		conn.SetIdleTimeout(50 * time.Millisecond)
+		_, _, err := conn.ReadMessage(0)
+		result <- err
+		_ = conn.Close(CloseGoingAway, "")
+	})
+
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close(CloseNormal, "") }()
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Fatal("ReadMessage() returned a message from a silent client")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle connection was not timed out")
+	}
+}
+
+func TestUpgrade_RejectsPlainRequests(t *testing.T) {
+	url := serve(t, func(*Conn) {})
+	resp, err := http.Get("http" + strings.TrimPrefix(url, "ws"))
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -28,6 +28,17 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 		return nil, fmt.Errorf("connection pool is required")
 	}
 
+	if err := CreatePostgreSQLSchema(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	return &PostgreSQLStore{pool: pool}, nil
+}
+
+// CreatePostgreSQLSchema creates or upgrades the workflow_versions table and
+// its indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreatePostgreSQLSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	statements := []string{
 		`CREATE TABLE IF NOT EXISTS workflow_versions (
 			id UUID PRIMARY KEY,
@@ -62,11 +73,10 @@ func NewPostgreSQLStore(ctx context.Context, pool *pgxpool.Pool) (*PostgreSQLSto
 	}
 	for _, statement := range statements {
 		if _, err := pool.Exec(ctx, statement); err != nil {
-			return nil, fmt.Errorf("initialize workflow versions table: %w", err)
+			return fmt.Errorf("initialize workflow versions table: %w", err)
 		}
 	}
-
-	return &PostgreSQLStore{pool: pool}, nil
+	return nil
 }
 
 func (s *PostgreSQLStore) ListActive(ctx context.Context) ([]Version, error) {
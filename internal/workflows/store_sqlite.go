@@ -23,6 +23,17 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		return nil, fmt.Errorf("database connection is required")
 	}
 
+	if err := CreateSQLiteSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// CreateSQLiteSchema creates or upgrades the workflow_versions table and its
+// indexes. It is idempotent: store constructors run it, and so does the
+// baseline schema migration.
+func CreateSQLiteSchema(ctx context.Context, db *sql.DB) error {
 	statements := []string{
 		`CREATE TABLE IF NOT EXISTS workflow_versions (
 			id TEXT PRIMARY KEY,
@@ -48,17 +59,17 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 			ON workflow_versions(active, created_at DESC)`,
 	}
 	for _, statement := range statements {
-		if _, err := db.Exec(statement); err != nil {
-			return nil, fmt.Errorf("initialize workflow versions table: %w", err)
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("initialize workflow versions table: %w", err)
 		}
 	}
-	if _, err := db.Exec(`ALTER TABLE workflow_versions ADD COLUMN scope_user_path TEXT`); err != nil && !isSQLiteDuplicateColumnError(err) {
-		return nil, fmt.Errorf("initialize workflow versions table: %w", err)
+	if _, err := db.ExecContext(ctx, `ALTER TABLE workflow_versions ADD COLUMN scope_user_path TEXT`); err != nil && !isSQLiteDuplicateColumnError(err) {
+		return fmt.Errorf("initialize workflow versions table: %w", err)
 	}
-	if _, err := db.Exec(`ALTER TABLE workflow_versions ADD COLUMN managed_default INTEGER NOT NULL DEFAULT 0`); err != nil && !isSQLiteDuplicateColumnError(err) {
-		return nil, fmt.Errorf("initialize workflow versions table: %w", err)
+	if _, err := db.ExecContext(ctx, `ALTER TABLE workflow_versions ADD COLUMN managed_default INTEGER NOT NULL DEFAULT 0`); err != nil && !isSQLiteDuplicateColumnError(err) {
+		return fmt.Errorf("initialize workflow versions table: %w", err)
 	}
-	if _, err := db.Exec(`
+	if _, err := db.ExecContext(ctx, `
 		UPDATE workflow_versions
 		SET managed_default = 1
 		WHERE managed_default = 0
@@ -66,10 +77,9 @@ func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
 		  AND name = ?
 		  AND description = ?
 	`, ManagedDefaultGlobalName, ManagedDefaultGlobalDescription); err != nil {
-		return nil, fmt.Errorf("initialize workflow versions table: %w", err)
+		return fmt.Errorf("initialize workflow versions table: %w", err)
 	}
-
-	return &SQLiteStore{db: db}, nil
+	return nil
 }
 
 func isSQLiteDuplicateColumnError(err error) bool {
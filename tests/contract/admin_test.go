@@ -0,0 +1,353 @@
+//go:build contract
+
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/admin"
+	"gomodel/internal/auditlog"
+	"gomodel/internal/authkeys"
+	"gomodel/internal/core"
+	"gomodel/internal/providers"
+	"gomodel/internal/usage"
+)
+
+// adminFixtureDir holds the inputs the admin contract tests seed their mock
+// readers from. They are not provider payloads, so the coverage report
+// skips them.
+const adminFixtureDir = "admin"
+
+// adminFixture is the reader data one admin contract case is served from.
+// Fields a case leaves out stay nil, as a store returning no rows would.
+type adminFixture struct {
+	Summary     *usage.UsageSummary     `json:"summary"`
+	Daily       []usage.DailyUsage      `json:"daily"`
+	ModelUsage  []usage.ModelUsage      `json:"model_usage"`
+	UserPaths   []usage.UserPathUsage   `json:"user_paths"`
+	UsageLog    *usage.UsageLogResult   `json:"usage_log"`
+	AuditLog    *auditlog.LogListResult `json:"audit_log"`
+	AuditEntry  *auditlog.LogEntry      `json:"audit_entry"`
+	Models      *core.ModelsResponse    `json:"models"`
+	NoRegistry  bool                    `json:"no_registry"`
+	NoAudit     bool                    `json:"no_audit"`
+	NoUsage     bool                    `json:"no_usage"`
+	Description string                  `json:"description"`
+}
+
+type adminUsageReader struct {
+	usage.UsageReader
+	fixture *adminFixture
+}
+
+func (r *adminUsageReader) GetSummary(context.Context, usage.UsageQueryParams) (*usage.UsageSummary, error) {
+	if r.fixture.Summary == nil {
+		return &usage.UsageSummary{}, nil
+	}
+	return r.fixture.Summary, nil
+}
+
+func (r *adminUsageReader) GetDailyUsage(context.Context, usage.UsageQueryParams) ([]usage.DailyUsage, error) {
+	return r.fixture.Daily, nil
+}
+
+func (r *adminUsageReader) GetUsageByModel(context.Context, usage.UsageQueryParams) ([]usage.ModelUsage, error) {
+	return r.fixture.ModelUsage, nil
+}
+
+func (r *adminUsageReader) GetUsageByUserPath(context.Context, usage.UsageQueryParams) ([]usage.UserPathUsage, error) {
+	return r.fixture.UserPaths, nil
+}
+
+func (r *adminUsageReader) GetUsageLog(context.Context, usage.UsageLogParams) (*usage.UsageLogResult, error) {
+	if r.fixture.UsageLog == nil {
+		return &usage.UsageLogResult{}, nil
+	}
+	result := *r.fixture.UsageLog
+	return &result, nil
+}
+
+type adminAuditReader struct {
+	auditlog.Reader
+	fixture *adminFixture
+}
+
+func (r *adminAuditReader) GetLogs(context.Context, auditlog.LogQueryParams) (*auditlog.LogListResult, error) {
+	if r.fixture.AuditLog == nil {
+		return &auditlog.LogListResult{}, nil
+	}
+	result := *r.fixture.AuditLog
+	result.Entries = slices.Clone(result.Entries)
+	return &result, nil
+}
+
+func (r *adminAuditReader) GetLogByID(_ context.Context, id string) (*auditlog.LogEntry, error) {
+	if r.fixture.AuditEntry == nil || r.fixture.AuditEntry.ID != id {
+		return nil, nil
+	}
+	entry := *r.fixture.AuditEntry
+	return &entry, nil
+}
+
+// adminModelsProvider serves the fixture model list to the registry; the
+// registry calls nothing else during Initialize.
+type adminModelsProvider struct {
+	core.Provider
+	models *core.ModelsResponse
+}
+
+func (p *adminModelsProvider) ListModels(context.Context) (*core.ModelsResponse, error) {
+	return p.models, nil
+}
+
+// loadAdminFixture reads testdata/admin/<name>. An empty name is a gateway
+// without usage, audit or model storage configured.
+func loadAdminFixture(t *testing.T, name string) *adminFixture {
+	t.Helper()
+
+	if name == "" {
+		return &adminFixture{NoUsage: true, NoAudit: true, NoRegistry: true}
+	}
+	fullPath := filepath.Join(testdataDir, adminFixtureDir, name)
+	data, err := os.ReadFile(fullPath)
+	require.NoError(t, err, "failed to read admin fixture %s", fullPath)
+
+	var fixture adminFixture
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	require.NoError(t, decoder.Decode(&fixture), "admin fixture %s does not match the reader types", fullPath)
+	return &fixture
+}
+
+func newAdminContractHandler(t *testing.T, fixture *adminFixture) *admin.Handler {
+	t.Helper()
+
+	var reader usage.UsageReader
+	if !fixture.NoUsage {
+		reader = &adminUsageReader{fixture: fixture}
+	}
+
+	var registry *providers.ModelRegistry
+	if !fixture.NoRegistry {
+		registry = providers.NewModelRegistry()
+		models := fixture.Models
+		if models == nil {
+			models = &core.ModelsResponse{Object: "list"}
+		}
+		registry.RegisterProviderWithType(&adminModelsProvider{models: models}, "openai")
+		if len(models.Data) > 0 {
+			require.NoError(t, registry.Initialize(context.Background()))
+		}
+	}
+
+	var options []admin.Option
+	if !fixture.NoAudit {
+		options = append(options, admin.WithAuditReader(&adminAuditReader{fixture: fixture}))
+	}
+	return admin.NewHandler(reader, registry, options...)
+}
+
+type adminContractCase struct {
+	name string
+	// fixture is the input under testdata/admin; empty runs the handler
+	// without readers.
+	fixture string
+	target  string
+	// pathID is the {id} path parameter, for single-entry endpoints.
+	pathID string
+	// role is the caller's auth key role; empty is a master key.
+	role    authkeys.Role
+	handler func(*admin.Handler, *echo.Context) error
+}
+
+// TestAdminAPIContract locks the JSON the admin dashboard reads. Each case
+// serves a handler from fixture readers and compares the response body to
+// testdata/golden/admin/<name>.golden.json. A struct tag rename, a dropped
+// field or a null that becomes 0 fails here; refresh intended changes with
+// RECORD=1 and review the golden diff.
+func TestAdminAPIContract(t *testing.T) {
+	cases := []adminContractCase{
+		{name: "usage_summary", fixture: "usage_summary.json", target: "/admin/api/v1/usage/summary?days=30", handler: (*admin.Handler).UsageSummary},
+		{name: "usage_summary_null_costs", fixture: "usage_summary_null_costs.json", target: "/admin/api/v1/usage/summary?days=30", handler: (*admin.Handler).UsageSummary},
+		{name: "usage_summary_nil_reader", target: "/admin/api/v1/usage/summary", handler: (*admin.Handler).UsageSummary},
+		{name: "usage_daily", fixture: "usage_daily.json", target: "/admin/api/v1/usage/daily?days=7", handler: (*admin.Handler).DailyUsage},
+		{name: "usage_daily_empty", fixture: "empty.json", target: "/admin/api/v1/usage/daily", handler: (*admin.Handler).DailyUsage},
+		{name: "usage_daily_nil_reader", target: "/admin/api/v1/usage/daily", handler: (*admin.Handler).DailyUsage},
+		{name: "usage_models", fixture: "usage_models.json", target: "/admin/api/v1/usage/models", handler: (*admin.Handler).UsageByModel},
+		{name: "usage_models_nil_reader", target: "/admin/api/v1/usage/models", handler: (*admin.Handler).UsageByModel},
+		{name: "usage_user_paths", fixture: "usage_user_paths.json", target: "/admin/api/v1/usage/user-paths", handler: (*admin.Handler).UsageByUserPath},
+		{name: "usage_log", fixture: "usage_log.json", target: "/admin/api/v1/usage/log?limit=2", handler: (*admin.Handler).UsageLog},
+		{name: "usage_log_empty", fixture: "empty.json", target: "/admin/api/v1/usage/log", handler: (*admin.Handler).UsageLog},
+		{name: "usage_log_nil_reader", target: "/admin/api/v1/usage/log", handler: (*admin.Handler).UsageLog},
+		{name: "audit_log", fixture: "audit_log.json", target: "/admin/api/v1/audit/log", handler: (*admin.Handler).AuditLog},
+		{name: "audit_log_metadata_only", fixture: "audit_log.json", target: "/admin/api/v1/audit/log", role: authkeys.RoleReadAuditMetadata, handler: (*admin.Handler).AuditLog},
+		{name: "audit_log_empty", fixture: "empty.json", target: "/admin/api/v1/audit/log", handler: (*admin.Handler).AuditLog},
+		{name: "audit_log_nil_reader", target: "/admin/api/v1/audit/log", handler: (*admin.Handler).AuditLog},
+		{name: "audit_entry", fixture: "audit_log.json", target: "/admin/api/v1/audit/log-1", pathID: "log-1", handler: (*admin.Handler).AuditLogEntry},
+		{name: "audit_entry_not_found", fixture: "audit_log.json", target: "/admin/api/v1/audit/missing", pathID: "missing", handler: (*admin.Handler).AuditLogEntry},
+		{name: "audit_entry_nil_reader", target: "/admin/api/v1/audit/log-1", pathID: "log-1", handler: (*admin.Handler).AuditLogEntry},
+		{name: "models", fixture: "models.json", target: "/admin/api/v1/models", handler: (*admin.Handler).ListModels},
+		{name: "models_by_category", fixture: "models.json", target: "/admin/api/v1/models?category=embedding", handler: (*admin.Handler).ListModels},
+		{name: "models_nil_registry", target: "/admin/api/v1/models", handler: (*admin.Handler).ListModels},
+		{name: "categories", fixture: "models.json", target: "/admin/api/v1/models/categories", handler: (*admin.Handler).ListCategories},
+		{name: "categories_nil_registry", target: "/admin/api/v1/models/categories", handler: (*admin.Handler).ListCategories},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newAdminContractHandler(t, loadAdminFixture(t, tc.fixture))
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			if tc.pathID != "" {
+				c.SetPathValues(echo.PathValues{{Name: "id", Value: tc.pathID}})
+			}
+			if tc.role != "" {
+				c.Set(authkeys.RoleContextKey, tc.role)
+			}
+			require.NoError(t, tc.handler(h, c))
+
+			compareAdminGolden(t, tc.name, rec)
+		})
+	}
+}
+
+// adminGoldenResponse is what a golden file records: the status and the
+// decoded body, indented so the file diffs cleanly when the contract changes.
+type adminGoldenResponse struct {
+	Status int `json:"status"`
+	Body   any `json:"body"`
+}
+
+func compareAdminGolden(t *testing.T, name string, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	var body any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), "response is not JSON: %s", rec.Body.String())
+	actual, err := json.MarshalIndent(adminGoldenResponse{Status: rec.Code, Body: body}, "", "  ")
+	require.NoError(t, err)
+	actual = append(actual, '\n')
+
+	path := filepath.Join(goldenOutputDir, adminFixtureDir, name+".golden.json")
+	fullPath := filepath.Join(testdataDir, path)
+	if shouldRecordGoldenOutputs() {
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, actual, 0644))
+	}
+
+	expected, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) {
+		t.Fatalf("missing golden file %s; run `RECORD=1 go test -tags=contract -run TestAdminAPIContract ./tests/contract/...`", path)
+	}
+	require.NoError(t, err)
+
+	var want, got any
+	require.NoError(t, json.Unmarshal(expected, &want), "golden file %s is not JSON", path)
+	require.NoError(t, json.Unmarshal(actual, &got))
+	if diffs := jsonFieldDiff("$", want, got); len(diffs) > 0 {
+		t.Fatalf("admin API contract changed for %s; if intended, refresh it with RECORD=1 and review the golden diff:\n%s",
+			path, strings.Join(diffs, "\n"))
+	}
+}
+
+// jsonFieldDiff lists the differences between two decoded JSON values, one
+// line per field, addressed by a JSONPath-like path. Missing, added and
+// retyped fields are reported separately from changed values, so a renamed
+// tag shows up as one missing and one added field.
+func jsonFieldDiff(path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: golden %s, got %s", path, describeJSON(want), describeJSON(got))}
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for key := range w {
+			keys = append(keys, key)
+		}
+		for key := range g {
+			if _, ok := w[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		var diffs []string
+		for _, key := range keys {
+			field := path + "." + key
+			wv, inWant := w[key]
+			gv, inGot := g[key]
+			switch {
+			case !inGot:
+				diffs = append(diffs, fmt.Sprintf("%s: missing (golden %s)", field, describeJSON(wv)))
+			case !inWant:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected field (got %s)", field, describeJSON(gv)))
+			default:
+				diffs = append(diffs, jsonFieldDiff(field, wv, gv)...)
+			}
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: golden %s, got %s", path, describeJSON(want), describeJSON(got))}
+		}
+		var diffs []string
+		if len(w) != len(g) {
+			diffs = append(diffs, fmt.Sprintf("%s: golden has %d items, got %d", path, len(w), len(g)))
+		}
+		for i := range min(len(w), len(g)) {
+			diffs = append(diffs, jsonFieldDiff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i])...)
+		}
+		return diffs
+	default:
+		if want != got {
+			return []string{fmt.Sprintf("%s: golden %s, got %s", path, describeJSON(want), describeJSON(got))}
+		}
+		return nil
+	}
+}
+
+// describeJSON renders a decoded JSON value for a diff line, naming the type
+// of containers so that null-versus-zero and object-versus-array changes
+// read clearly.
+func describeJSON(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return fmt.Sprintf("object with %d fields", len(val))
+	case []any:
+		return fmt.Sprintf("array of %d", len(val))
+	case string:
+		return fmt.Sprintf("%q", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func TestJSONFieldDiff(t *testing.T) {
+	var want, got any
+	require.NoError(t, json.Unmarshal([]byte(`{"total_cost":null,"entries":[{"model":"a","input_tokens":1}],"old":1}`), &want))
+	require.NoError(t, json.Unmarshal([]byte(`{"total_cost":0,"entries":[{"model":"b","input_tokens":1}],"new":1}`), &got))
+
+	require.Equal(t, []string{
+		`$.entries[0].model: golden "a", got "b"`,
+		`$.new: unexpected field (got 1)`,
+		`$.old: missing (golden 1)`,
+		`$.total_cost: golden null, got 0`,
+	}, jsonFieldDiff("$", want, got))
+	require.Empty(t, jsonFieldDiff("$", want, want))
+}
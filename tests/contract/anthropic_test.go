@@ -21,7 +21,7 @@ func newAnthropicReplayProvider(t *testing.T, routes map[string]replayRoute) cor
 	client := newReplayHTTPClient(t, routes)
 	provider := anthropic.NewWithHTTPClient("sk-ant-test", client, llmclient.Hooks{})
 	provider.SetBaseURL("https://replay.local")
-	return provider
+	return instrumentReplayProvider("anthropic", provider)
 }
 
 func TestAnthropicReplayChatCompletion(t *testing.T) {
@@ -127,6 +127,7 @@ func TestAnthropicReplayStreamResponses(t *testing.T) {
 		}
 	}
 	require.True(t, hasDone, "responses stream should terminate with [DONE]")
+	requireResponsesEnvelopeMatchesOpenAI(t, events)
 
 	compareGoldenJSON(t, "anthropic/responses_stream.golden.json", map[string]any{
 		"events": events,
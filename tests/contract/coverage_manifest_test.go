@@ -0,0 +1,132 @@
+//go:build contract
+
+package contract
+
+// Endpoints of the contract coverage matrix.
+const (
+	coverageEndpointChat      = "chat"
+	coverageEndpointResponses = "responses"
+	coverageEndpointModels    = "models"
+)
+
+// Scenarios of the contract coverage matrix.
+const (
+	coverageScenarioSuccess       = "success"
+	coverageScenarioAuthError     = "auth_error"
+	coverageScenarioRateLimit     = "rate_limit"
+	coverageScenarioUpstreamError = "upstream_error"
+	coverageScenarioToolCall      = "tool_call"
+	coverageScenarioVision        = "vision"
+	coverageScenarioStreaming     = "streaming"
+	coverageScenarioStreamingTool = "streaming_tool_call"
+)
+
+// coverageCell is one provider × endpoint × scenario combination we intend to
+// cover with recorded fixtures. Fixtures lists the testdata paths that cover
+// it; a cell is covered when at least one of them exists.
+type coverageCell struct {
+	Provider string
+	Endpoint string
+	Scenario string
+	Fixtures []string
+}
+
+// adapterMethod is the core.Provider method that replays the cell's fixtures.
+func (c coverageCell) adapterMethod() string {
+	streaming := c.Scenario == coverageScenarioStreaming || c.Scenario == coverageScenarioStreamingTool
+	switch c.Endpoint {
+	case coverageEndpointResponses:
+		if streaming {
+			return "StreamResponses"
+		}
+		return "Responses"
+	case coverageEndpointModels:
+		return "ListModels"
+	default:
+		if streaming {
+			return "StreamChatCompletion"
+		}
+		return "ChatCompletion"
+	}
+}
+
+// providersWithoutFixtures are providers under internal/providers that are
+// known to have no recorded fixtures yet. Any other provider without fixtures
+// fails the coverage report, so a new provider cannot land without any.
+var providersWithoutFixtures = []string{"azure", "ollama", "openrouter", "oracle", "zai"}
+
+// coverageManifest declares the provider × endpoint × scenario matrix the
+// contract suite intends to cover. Cells without fixtures are reported as gaps.
+var coverageManifest = []coverageCell{
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{
+		"openai/chat_completion.json",
+		"openai/chat_completion_reasoning.json",
+		"openai/chat_json_mode.json",
+		"openai/chat_multi_turn.json",
+		"openai/chat_with_params.json",
+	}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioAuthError, Fixtures: []string{"openai/error_invalid_api_key.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioRateLimit, Fixtures: []string{"openai/error_rate_limit.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioUpstreamError, Fixtures: []string{"openai/error_content_policy.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioToolCall, Fixtures: []string{"openai/chat_with_tools.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioVision, Fixtures: []string{"openai/chat_multimodal.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"openai/chat_completion_stream.txt"}},
+	{Provider: "openai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreamingTool, Fixtures: []string{"openai/chat_with_tools_stream.txt"}},
+	{Provider: "openai", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioSuccess, Fixtures: []string{"openai/responses.json"}},
+	{Provider: "openai", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioStreaming, Fixtures: []string{"openai/responses_stream.txt"}},
+	{Provider: "openai", Endpoint: coverageEndpointModels, Scenario: coverageScenarioSuccess, Fixtures: []string{"openai/models.json"}},
+
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{
+		"anthropic/messages.json",
+		"anthropic/messages_extended_thinking.json",
+		"anthropic/messages_multi_turn.json",
+		"anthropic/messages_with_params.json",
+	}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioAuthError, Fixtures: []string{"anthropic/error_authentication.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioRateLimit, Fixtures: []string{"anthropic/error_rate_limit.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioUpstreamError, Fixtures: []string{"anthropic/error_overloaded.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioToolCall, Fixtures: []string{"anthropic/messages_with_tools.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioVision, Fixtures: []string{"anthropic/messages_multimodal.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"anthropic/messages_stream.txt"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreamingTool, Fixtures: []string{"anthropic/messages_with_tools_stream.txt"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioSuccess, Fixtures: []string{"anthropic/messages.json"}},
+	{Provider: "anthropic", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioStreaming, Fixtures: []string{"anthropic/messages_stream.txt"}},
+
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{
+		"gemini/chat_completion.json",
+		"gemini/chat_with_params.json",
+	}},
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioAuthError, Fixtures: []string{"gemini/error_invalid_api_key.json"}},
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioRateLimit, Fixtures: []string{"gemini/error_resource_exhausted.json"}},
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioToolCall, Fixtures: []string{"gemini/chat_with_tools.json"}},
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioVision, Fixtures: []string{"gemini/chat_multimodal.json"}},
+	{Provider: "gemini", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"gemini/chat_completion_stream.txt"}},
+	{Provider: "gemini", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioSuccess, Fixtures: []string{"gemini/chat_completion.json"}},
+	{Provider: "gemini", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioStreaming, Fixtures: []string{"gemini/chat_completion_stream.txt"}},
+	{Provider: "gemini", Endpoint: coverageEndpointModels, Scenario: coverageScenarioSuccess, Fixtures: []string{"gemini/models.json"}},
+
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{
+		"groq/chat_completion.json",
+		"groq/chat_with_params.json",
+	}},
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioAuthError, Fixtures: []string{"groq/error_invalid_api_key.json"}},
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioRateLimit, Fixtures: []string{"groq/error_rate_limit.json"}},
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioUpstreamError, Fixtures: []string{"groq/error_capacity_exceeded.json"}},
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioToolCall, Fixtures: []string{"groq/chat_with_tools.json"}},
+	{Provider: "groq", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"groq/chat_completion_stream.txt"}},
+	{Provider: "groq", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioSuccess, Fixtures: []string{"groq/chat_completion.json"}},
+	{Provider: "groq", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioStreaming, Fixtures: []string{"groq/chat_completion_stream.txt"}},
+	{Provider: "groq", Endpoint: coverageEndpointModels, Scenario: coverageScenarioSuccess, Fixtures: []string{"groq/models.json"}},
+
+	{Provider: "xai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{
+		"xai/chat_completion.json",
+		"xai/chat_with_params.json",
+	}},
+	{Provider: "xai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioAuthError, Fixtures: []string{"xai/error_invalid_api_key.json"}},
+	{Provider: "xai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioRateLimit, Fixtures: []string{"xai/error_rate_limit.json"}},
+	{Provider: "xai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioToolCall, Fixtures: []string{"xai/chat_with_tools.json"}},
+	{Provider: "xai", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"xai/chat_completion_stream.txt"}},
+	{Provider: "xai", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioSuccess, Fixtures: []string{"xai/responses.json"}},
+	{Provider: "xai", Endpoint: coverageEndpointResponses, Scenario: coverageScenarioStreaming, Fixtures: []string{"xai/responses_stream.txt"}},
+	{Provider: "xai", Endpoint: coverageEndpointModels, Scenario: coverageScenarioSuccess, Fixtures: []string{"xai/models.json"}},
+}
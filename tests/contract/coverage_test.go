@@ -0,0 +1,402 @@
+//go:build contract
+
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+
+	"gomodel/internal/core"
+)
+
+const (
+	// coverageReportEnv names a file the JSON coverage report is written to.
+	coverageReportEnv = "CONTRACT_COVERAGE_REPORT"
+	// coverageModeEnv set to "warn" reports providers without fixtures
+	// instead of failing the suite.
+	coverageModeEnv = "CONTRACT_COVERAGE_MODE"
+
+	providersSourceDir = "../../internal/providers"
+)
+
+// replayCoverage records which fixtures the tests loaded, which of them the
+// replay transport served to a provider adapter, and which adapter methods
+// were called per provider.
+type replayCoverage struct {
+	mu       sync.Mutex
+	fixtures map[string]struct{}
+	served   map[string]struct{}
+	methods  map[string]struct{}
+}
+
+var contractCoverage = newReplayCoverage()
+
+func newReplayCoverage() *replayCoverage {
+	return &replayCoverage{
+		fixtures: make(map[string]struct{}),
+		served:   make(map[string]struct{}),
+		methods:  make(map[string]struct{}),
+	}
+}
+
+func (c *replayCoverage) recordFixture(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fixtures[filepath.ToSlash(path)] = struct{}{}
+}
+
+func (c *replayCoverage) recordServed(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.served[filepath.ToSlash(path)] = struct{}{}
+}
+
+func (c *replayCoverage) recordMethod(provider, method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods[provider+"."+method] = struct{}{}
+}
+
+func (c *replayCoverage) fixtureReplayed(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.fixtures[path]
+	return ok
+}
+
+func (c *replayCoverage) fixtureServed(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.served[path]
+	return ok
+}
+
+func (c *replayCoverage) providerMethods(provider string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var methods []string
+	for key := range c.methods {
+		if name, ok := strings.CutPrefix(key, provider+"."); ok {
+			methods = append(methods, name)
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// instrumentedProvider is the shim replay providers are wrapped in so the
+// coverage report knows which adapter code paths the suite exercised.
+type instrumentedProvider struct {
+	core.Provider
+	name string
+}
+
+func instrumentReplayProvider(name string, provider core.Provider) core.Provider {
+	return &instrumentedProvider{Provider: provider, name: name}
+}
+
+func (p *instrumentedProvider) ChatCompletion(ctx context.Context, req *core.ChatRequest) (*core.ChatResponse, error) {
+	contractCoverage.recordMethod(p.name, "ChatCompletion")
+	return p.Provider.ChatCompletion(ctx, req)
+}
+
+func (p *instrumentedProvider) StreamChatCompletion(ctx context.Context, req *core.ChatRequest) (io.ReadCloser, error) {
+	contractCoverage.recordMethod(p.name, "StreamChatCompletion")
+	return p.Provider.StreamChatCompletion(ctx, req)
+}
+
+func (p *instrumentedProvider) ListModels(ctx context.Context) (*core.ModelsResponse, error) {
+	contractCoverage.recordMethod(p.name, "ListModels")
+	return p.Provider.ListModels(ctx)
+}
+
+func (p *instrumentedProvider) Responses(ctx context.Context, req *core.ResponsesRequest) (*core.ResponsesResponse, error) {
+	contractCoverage.recordMethod(p.name, "Responses")
+	return p.Provider.Responses(ctx, req)
+}
+
+func (p *instrumentedProvider) StreamResponses(ctx context.Context, req *core.ResponsesRequest) (io.ReadCloser, error) {
+	contractCoverage.recordMethod(p.name, "StreamResponses")
+	return p.Provider.StreamResponses(ctx, req)
+}
+
+func (p *instrumentedProvider) Embeddings(ctx context.Context, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	contractCoverage.recordMethod(p.name, "Embeddings")
+	return p.Provider.Embeddings(ctx, req)
+}
+
+// coverageReport is the machine-readable contract coverage report.
+type coverageReport struct {
+	Providers        []providerCoverage `json:"providers"`
+	Cells            []cellCoverage     `json:"cells"`
+	UnmappedFixtures []string           `json:"unmapped_fixtures"`
+	Violations       []string           `json:"violations"`
+}
+
+type providerCoverage struct {
+	Provider         string   `json:"provider"`
+	Fixtures         int      `json:"fixtures"`
+	CoveredCells     int      `json:"covered_cells"`
+	TotalCells       int      `json:"total_cells"`
+	ExercisedMethods []string `json:"exercised_methods"`
+}
+
+type cellCoverage struct {
+	Provider        string   `json:"provider"`
+	Endpoint        string   `json:"endpoint"`
+	Scenario        string   `json:"scenario"`
+	Covered         bool     `json:"covered"`
+	Fixtures        []string `json:"fixtures"`
+	MissingFixtures []string `json:"missing_fixtures,omitempty"`
+	// Replayed is set when a test loaded one of the fixtures.
+	Replayed      bool   `json:"replayed"`
+	AdapterMethod string `json:"adapter_method"`
+	// Exercised is set when the replay transport served one of the fixtures
+	// to a provider adapter, rather than a test reading it directly.
+	Exercised bool `json:"exercised"`
+}
+
+// buildCoverageReport compares the manifest with the fixtures on disk and
+// with what the replay tests recorded.
+func buildCoverageReport(manifest []coverageCell, fixtures, providers []string, recorded *replayCoverage) coverageReport {
+	present := make(map[string]bool, len(fixtures))
+	fixturesPerProvider := make(map[string]int)
+	for _, fixture := range fixtures {
+		present[fixture] = true
+		provider, _, _ := strings.Cut(fixture, "/")
+		fixturesPerProvider[provider]++
+	}
+
+	report := coverageReport{
+		Providers:        []providerCoverage{},
+		Cells:            make([]cellCoverage, 0, len(manifest)),
+		UnmappedFixtures: []string{},
+		Violations:       []string{},
+	}
+	mapped := make(map[string]bool)
+	perProvider := make(map[string]*providerCoverage)
+	for _, cell := range manifest {
+		result := cellCoverage{
+			Provider:      cell.Provider,
+			Endpoint:      cell.Endpoint,
+			Scenario:      cell.Scenario,
+			Fixtures:      []string{},
+			AdapterMethod: cell.adapterMethod(),
+		}
+		for _, fixture := range cell.Fixtures {
+			mapped[fixture] = true
+			if !present[fixture] {
+				result.MissingFixtures = append(result.MissingFixtures, fixture)
+				continue
+			}
+			result.Fixtures = append(result.Fixtures, fixture)
+			result.Covered = true
+			result.Replayed = result.Replayed || recorded.fixtureReplayed(fixture)
+			result.Exercised = result.Exercised || recorded.fixtureServed(fixture)
+		}
+		report.Cells = append(report.Cells, result)
+
+		summary, ok := perProvider[cell.Provider]
+		if !ok {
+			summary = &providerCoverage{Provider: cell.Provider}
+			perProvider[cell.Provider] = summary
+		}
+		summary.TotalCells++
+		if result.Covered {
+			summary.CoveredCells++
+		}
+	}
+
+	for _, fixture := range fixtures {
+		if !mapped[fixture] {
+			report.UnmappedFixtures = append(report.UnmappedFixtures, fixture)
+		}
+	}
+
+	for _, provider := range providers {
+		summary, ok := perProvider[provider]
+		if !ok {
+			summary = &providerCoverage{Provider: provider}
+		}
+		summary.Fixtures = fixturesPerProvider[provider]
+		summary.ExercisedMethods = recorded.providerMethods(provider)
+		if summary.ExercisedMethods == nil {
+			summary.ExercisedMethods = []string{}
+		}
+		report.Providers = append(report.Providers, *summary)
+
+		if summary.Fixtures == 0 && !slices.Contains(providersWithoutFixtures, provider) {
+			report.Violations = append(report.Violations, fmt.Sprintf(
+				"provider %q has no fixtures in testdata/%s; record some or list it in providersWithoutFixtures", provider, provider))
+		}
+	}
+	return report
+}
+
+// writeCoverageTable prints the report as a console table.
+func writeCoverageTable(w io.Writer, report coverageReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tENDPOINT\tSCENARIO\tFIXTURE\tREPLAYED\tEXERCISED")
+	for _, cell := range report.Cells {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			cell.Provider, cell.Endpoint, cell.Scenario,
+			coverageMark(cell.Covered), coverageMark(cell.Replayed), coverageMark(cell.Exercised))
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tFIXTURES\tCELLS\tEXERCISED METHODS")
+	for _, provider := range report.Providers {
+		fmt.Fprintf(tw, "%s\t%d\t%d/%d\t%s\n",
+			provider.Provider, provider.Fixtures, provider.CoveredCells, provider.TotalCells,
+			strings.Join(provider.ExercisedMethods, ","))
+	}
+	_ = tw.Flush()
+
+	for _, fixture := range report.UnmappedFixtures {
+		fmt.Fprintf(w, "unmapped fixture: %s\n", fixture)
+	}
+}
+
+func coverageMark(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "-"
+}
+
+// listFixtures returns the recorded provider payloads under testdata, relative
+// to it. Normalized goldens and admin contract inputs are not fixtures.
+func listFixtures(root string) ([]string, error) {
+	var fixtures []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel == goldenOutputDir || rel == adminFixtureDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.Contains(rel, string(filepath.Separator)) {
+			fixtures = append(fixtures, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	slices.Sort(fixtures)
+	return fixtures, err
+}
+
+// listProviders returns the provider packages under internal/providers.
+func listProviders(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var providers []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "testdata" {
+			providers = append(providers, entry.Name())
+		}
+	}
+	return providers, nil
+}
+
+// reportContractCoverage prints the coverage table, writes the JSON report
+// when requested and returns a non-zero exit code for violations.
+func reportContractCoverage(w io.Writer) int {
+	fixtures, err := listFixtures(testdataDir)
+	if err != nil {
+		fmt.Fprintf(w, "contract coverage: list fixtures: %v\n", err)
+		return 1
+	}
+	providers, err := listProviders(providersSourceDir)
+	if err != nil {
+		fmt.Fprintf(w, "contract coverage: list providers: %v\n", err)
+		return 1
+	}
+	report := buildCoverageReport(coverageManifest, fixtures, providers, contractCoverage)
+
+	fmt.Fprintln(w, "\nContract fixture coverage:")
+	writeCoverageTable(w, report)
+
+	if path := os.Getenv(coverageReportEnv); path != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			fmt.Fprintf(w, "contract coverage: write %s: %v\n", path, err)
+			return 1
+		}
+	}
+
+	for _, violation := range report.Violations {
+		fmt.Fprintf(w, "contract coverage: %s\n", violation)
+	}
+	if len(report.Violations) > 0 && os.Getenv(coverageModeEnv) != "warn" {
+		return 1
+	}
+	return 0
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code == 0 {
+		code = reportContractCoverage(os.Stdout)
+	}
+	os.Exit(code)
+}
+
+func TestBuildCoverageReport(t *testing.T) {
+	manifest := []coverageCell{
+		{Provider: "alpha", Endpoint: coverageEndpointChat, Scenario: coverageScenarioSuccess, Fixtures: []string{"alpha/chat.json"}},
+		{Provider: "alpha", Endpoint: coverageEndpointChat, Scenario: coverageScenarioStreaming, Fixtures: []string{"alpha/chat_stream.txt"}},
+	}
+	recorded := newReplayCoverage()
+	recorded.recordFixture("alpha/chat.json")
+	recorded.recordServed("alpha/chat.json")
+	recorded.recordMethod("alpha", "ChatCompletion")
+
+	report := buildCoverageReport(manifest,
+		[]string{"alpha/chat.json", "alpha/extra.json"},
+		[]string{"alpha", "azure", "newprovider"},
+		recorded)
+
+	if len(report.Cells) != 2 {
+		t.Fatalf("cells = %d, want 2", len(report.Cells))
+	}
+	chat, stream := report.Cells[0], report.Cells[1]
+	if !chat.Covered || !chat.Replayed || !chat.Exercised || chat.AdapterMethod != "ChatCompletion" {
+		t.Errorf("chat cell = %+v, want covered, replayed and exercised", chat)
+	}
+	if stream.Covered || stream.Exercised || stream.AdapterMethod != "StreamChatCompletion" ||
+		!slices.Equal(stream.MissingFixtures, []string{"alpha/chat_stream.txt"}) {
+		t.Errorf("stream cell = %+v, want a gap", stream)
+	}
+	if !slices.Equal(report.UnmappedFixtures, []string{"alpha/extra.json"}) {
+		t.Errorf("unmapped = %v", report.UnmappedFixtures)
+	}
+	if len(report.Violations) != 1 || !strings.Contains(report.Violations[0], `"newprovider"`) {
+		t.Errorf("violations = %v, want only the provider without fixtures that is not exempt", report.Violations)
+	}
+	if got := report.Providers[0]; got.Fixtures != 2 || got.CoveredCells != 1 || got.TotalCells != 2 ||
+		!slices.Equal(got.ExercisedMethods, []string{"ChatCompletion"}) {
+		t.Errorf("alpha summary = %+v", got)
+	}
+}
@@ -124,6 +124,7 @@ func TestGeminiReplayStreamResponses(t *testing.T) {
 		}
 	}
 	require.True(t, hasDone, "responses stream should terminate with [DONE]")
+	requireResponsesEnvelopeMatchesOpenAI(t, events)
 
 	compareGoldenJSON(t, "gemini/responses_stream.golden.json", map[string]any{
 		"events": events,
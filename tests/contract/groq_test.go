@@ -21,7 +21,7 @@ func newGroqReplayProvider(t *testing.T, routes map[string]replayRoute) core.Pro
 	client := newReplayHTTPClient(t, routes)
 	provider := groq.NewWithHTTPClient("gsk-test", client, llmclient.Hooks{})
 	provider.SetBaseURL("https://replay.local")
-	return provider
+	return instrumentReplayProvider("groq", provider)
 }
 
 func TestGroqReplayChatCompletion(t *testing.T) {
@@ -131,6 +131,7 @@ func TestGroqReplayStreamResponses(t *testing.T) {
 		}
 	}
 	require.True(t, hasDone, "responses stream should terminate with [DONE]")
+	requireResponsesEnvelopeMatchesOpenAI(t, events)
 
 	compareGoldenJSON(t, "groq/responses_stream.golden.json", map[string]any{
 		"events": events,
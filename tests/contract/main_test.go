@@ -23,6 +23,7 @@ func loadGoldenFileRaw(t *testing.T, path string) []byte {
 	fullPath := filepath.Join(testdataDir, path)
 	data, err := os.ReadFile(fullPath)
 	require.NoError(t, err, "failed to read golden file %s", fullPath)
+	contractCoverage.recordFixture(path)
 
 	return data
 }
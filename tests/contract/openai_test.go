@@ -21,7 +21,7 @@ func newOpenAIReplayProvider(t *testing.T, routes map[string]replayRoute) core.P
 	client := newReplayHTTPClient(t, routes)
 	provider := openai.NewWithHTTPClient("sk-test", client, llmclient.Hooks{})
 	provider.SetBaseURL("https://replay.local")
-	return provider
+	return instrumentReplayProvider("openai", provider)
 }
 
 func TestOpenAIReplayChatCompletion(t *testing.T) {
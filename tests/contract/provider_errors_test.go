@@ -0,0 +1,78 @@
+//go:build contract
+
+// Contract tests in this file are intended to run with: -tags=contract -timeout=5m.
+package contract
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/core"
+)
+
+// TestProviderErrorTaxonomy replays recorded provider error bodies through the
+// error mapping table and checks each lands on its canonical type.
+func TestProviderErrorTaxonomy(t *testing.T) {
+	testCases := []struct {
+		provider       string
+		fixturePath    string
+		statusCode     int
+		expectedType   core.ErrorType
+		expectedStatus int
+	}{
+		{provider: "openai", fixturePath: "openai/error_content_policy.json", statusCode: http.StatusBadRequest, expectedType: core.ErrorTypeContentFilter, expectedStatus: http.StatusBadRequest},
+		{provider: "openai", fixturePath: "openai/error_invalid_api_key.json", statusCode: http.StatusUnauthorized, expectedType: core.ErrorTypeAuthentication, expectedStatus: http.StatusUnauthorized},
+		{provider: "anthropic", fixturePath: "anthropic/error_overloaded.json", statusCode: 529, expectedType: core.ErrorTypeOverloaded, expectedStatus: http.StatusServiceUnavailable},
+		{provider: "anthropic", fixturePath: "anthropic/error_authentication.json", statusCode: http.StatusUnauthorized, expectedType: core.ErrorTypeAuthentication, expectedStatus: http.StatusUnauthorized},
+		{provider: "gemini", fixturePath: "gemini/error_invalid_api_key.json", statusCode: http.StatusBadRequest, expectedType: core.ErrorTypeAuthentication, expectedStatus: http.StatusUnauthorized},
+		{provider: "gemini", fixturePath: "gemini/error_resource_exhausted.json", statusCode: http.StatusTooManyRequests, expectedType: core.ErrorTypeRateLimit, expectedStatus: http.StatusTooManyRequests},
+		{provider: "groq", fixturePath: "groq/error_rate_limit.json", statusCode: http.StatusTooManyRequests, expectedType: core.ErrorTypeRateLimit, expectedStatus: http.StatusTooManyRequests},
+		{provider: "groq", fixturePath: "groq/error_capacity_exceeded.json", statusCode: 498, expectedType: core.ErrorTypeOverloaded, expectedStatus: http.StatusServiceUnavailable},
+		{provider: "xai", fixturePath: "xai/error_invalid_api_key.json", statusCode: http.StatusBadRequest, expectedType: core.ErrorTypeAuthentication, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixturePath, func(t *testing.T) {
+			body := loadGoldenFileRaw(t, tc.fixturePath)
+
+			err := core.ParseProviderError(tc.provider, tc.statusCode, body, nil)
+			require.Equal(t, tc.expectedType, err.Type)
+			require.Equal(t, tc.expectedStatus, err.HTTPStatusCode())
+			require.Equal(t, tc.provider, err.Provider)
+			require.NotEmpty(t, err.Message)
+			require.NotNil(t, err.ProviderError, "raw upstream error must be preserved")
+
+			errorBody, ok := err.ToJSON()["error"].(map[string]any)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedType, errorBody["type"])
+			require.Contains(t, errorBody, "provider_error")
+		})
+	}
+}
+
+// TestProviderErrorTaxonomy_ThroughAdapter checks that a non-retryable upstream
+// error surfaced by a real adapter carries the canonical type.
+func TestProviderErrorTaxonomy_ThroughAdapter(t *testing.T) {
+	provider := newAnthropicReplayProvider(t, map[string]replayRoute{
+		replayKey(http.MethodPost, "/messages"): {
+			statusCode:  529,
+			contentType: "application/json",
+			body:        loadGoldenFileRaw(t, "anthropic/error_overloaded.json"),
+			fixture:     "anthropic/error_overloaded.json",
+		},
+	})
+
+	_, err := provider.ChatCompletion(t.Context(), &core.ChatRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []core.Message{{Role: "user", Content: "hello"}},
+	})
+	require.Error(t, err)
+
+	var gatewayErr *core.GatewayError
+	require.ErrorAs(t, err, &gatewayErr)
+	require.Equal(t, core.ErrorTypeOverloaded, gatewayErr.Type)
+	require.Equal(t, http.StatusServiceUnavailable, gatewayErr.HTTPStatusCode())
+	require.NotNil(t, gatewayErr.ProviderError)
+}
@@ -19,6 +19,9 @@ type replayRoute struct {
 	statusCode  int
 	contentType string
 	body        []byte
+	// fixture is the testdata path of body, recorded for the coverage report
+	// when the route is served.
+	fixture string
 }
 
 type replayTransport struct {
@@ -48,6 +51,10 @@ func (rt *replayTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		}, nil
 	}
 
+	if route.fixture != "" {
+		contractCoverage.recordServed(route.fixture)
+	}
+
 	statusCode := route.statusCode
 	if statusCode == 0 {
 		statusCode = http.StatusOK
@@ -84,6 +91,7 @@ func jsonFixtureRoute(t *testing.T, path string) replayRoute {
 		statusCode:  http.StatusOK,
 		contentType: "application/json",
 		body:        loadGoldenFileRaw(t, path),
+		fixture:     path,
 	}
 }
 
@@ -93,6 +101,7 @@ func sseFixtureRoute(t *testing.T, path string) replayRoute {
 		statusCode:  http.StatusOK,
 		contentType: "text/event-stream",
 		body:        loadGoldenFileRaw(t, path),
+		fixture:     path,
 	}
 }
 
@@ -260,3 +269,56 @@ func extractResponsesStreamText(events []responsesStreamEvent) string {
 	}
 	return b.String()
 }
+
+// responsesEnvelopeFields lists the per-event envelope fields clients use to
+// assemble Responses stream state, keyed by event name.
+var responsesEnvelopeFields = map[string][]string{
+	"response.output_item.added":  {"output_index"},
+	"response.content_part.added": {"item_id", "output_index", "content_index"},
+	"response.output_text.delta":  {"item_id", "output_index", "content_index"},
+	"response.output_text.done":   {"item_id", "output_index", "content_index"},
+	"response.content_part.done":  {"item_id", "output_index", "content_index"},
+	"response.output_item.done":   {"output_index"},
+}
+
+// requireResponsesEnvelopeMatchesOpenAI asserts that a converted Responses
+// stream is structurally equivalent to the recorded OpenAI responses stream:
+// the same event ordering (with repeated deltas and the [DONE] marker
+// ignored), strictly increasing
+// sequence numbers, and the envelope fields SDKs rely on.
+func requireResponsesEnvelopeMatchesOpenAI(t *testing.T, events []responsesStreamEvent) {
+	t.Helper()
+
+	reference := parseResponsesStream(t, loadGoldenFileRaw(t, "openai/responses_stream.txt"))
+	require.Equal(t, collapsedResponsesEventNames(reference), collapsedResponsesEventNames(events))
+
+	lastSequence := -1.0
+	for _, event := range events {
+		if event.Done {
+			continue
+		}
+		sequence, ok := event.Payload["sequence_number"].(float64)
+		require.True(t, ok, "%s missing sequence_number", event.Name)
+		require.Greater(t, sequence, lastSequence, "%s sequence_number must increase", event.Name)
+		lastSequence = sequence
+
+		for _, field := range responsesEnvelopeFields[event.Name] {
+			require.Contains(t, event.Payload, field, "%s missing %s", event.Name, field)
+		}
+	}
+}
+
+func collapsedResponsesEventNames(events []responsesStreamEvent) []string {
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.Done {
+			continue
+		}
+		name := event.Name
+		if len(names) > 0 && names[len(names)-1] == name && strings.HasSuffix(name, ".delta") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -17,5 +17,5 @@ func newGeminiReplayProvider(t *testing.T, routes map[string]replayRoute) core.P
 	provider := gemini.NewWithHTTPClient("test-api-key", client, llmclient.Hooks{})
 	provider.SetBaseURL("https://replay.local")
 	provider.SetModelsURL("https://replay.local")
-	return provider
+	return instrumentReplayProvider("gemini", provider)
 }
@@ -0,0 +1,181 @@
+//go:build contract
+
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/core"
+	"gomodel/internal/server"
+	"gomodel/internal/strictcompat"
+)
+
+// replayRoutableProvider routes every model to one replay provider.
+type replayRoutableProvider struct {
+	core.Provider
+}
+
+func (replayRoutableProvider) Supports(string) bool          { return true }
+func (replayRoutableProvider) GetProviderType(string) string { return "openai" }
+
+// jsonKeyPaths returns the dotted key paths of a JSON document, with "[]"
+// marking array elements.
+func jsonKeyPaths(t *testing.T, raw []byte) []string {
+	t.Helper()
+
+	var value any
+	require.NoError(t, json.Unmarshal(raw, &value))
+	paths := map[string]struct{}{}
+	collectJSONKeyPaths(value, "", paths)
+	out := make([]string, 0, len(paths))
+	for path := range paths {
+		out = append(out, path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func collectJSONKeyPaths(value any, prefix string, paths map[string]struct{}) {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			paths[path] = struct{}{}
+			collectJSONKeyPaths(child, path, paths)
+		}
+	case []any:
+		for _, child := range typed {
+			collectJSONKeyPaths(child, prefix+"[]", paths)
+		}
+	}
+}
+
+func requireKeysSubset(t *testing.T, got, allowed []string) {
+	t.Helper()
+
+	allowedSet := make(map[string]struct{}, len(allowed))
+	for _, path := range allowed {
+		allowedSet[path] = struct{}{}
+	}
+	var extra []string
+	for _, path := range got {
+		if _, ok := allowedSet[path]; !ok {
+			extra = append(extra, path)
+		}
+	}
+	require.Empty(t, extra, "strict-mode response has keys absent from the recorded OpenAI response")
+}
+
+func TestStrictCompatAllowListsCoverOpenAIRecordings(t *testing.T) {
+	testCases := []struct {
+		fixture string
+		schema  strictcompat.Schema
+	}{
+		{fixture: "openai/chat_completion.json", schema: strictcompat.ChatCompletion},
+		{fixture: "openai/chat_completion_reasoning.json", schema: strictcompat.ChatCompletion},
+		{fixture: "openai/chat_with_tools.json", schema: strictcompat.ChatCompletion},
+		{fixture: "openai/models.json", schema: strictcompat.ModelList},
+		{fixture: "openai/responses.json", schema: strictcompat.Response},
+		{fixture: "openai/error_content_policy.json", schema: strictcompat.Error},
+		{fixture: "openai/error_invalid_api_key.json", schema: strictcompat.Error},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			raw := loadGoldenFileRaw(t, tc.fixture)
+			filtered, ok := strictcompat.Filter(raw, tc.schema)
+			require.True(t, ok)
+			require.Equal(t, jsonKeyPaths(t, raw), jsonKeyPaths(t, filtered), "allow-list drops fields OpenAI returns")
+		})
+	}
+
+	for _, fixture := range []string{"openai/chat_completion_stream.txt", "openai/responses_stream.txt"} {
+		t.Run(fixture, func(t *testing.T) {
+			schema := strictcompat.ChatCompletionChunk
+			if strings.Contains(fixture, "responses") {
+				schema = strictcompat.ResponseStreamEvent
+			}
+			for _, event := range parseSSEEvents(t, loadGoldenFileRaw(t, fixture)) {
+				if event.Data == "[DONE]" {
+					continue
+				}
+				filtered, ok := strictcompat.Filter([]byte(event.Data), schema)
+				require.True(t, ok)
+				require.Equal(t, jsonKeyPaths(t, []byte(event.Data)), jsonKeyPaths(t, filtered), "allow-list drops stream fields OpenAI returns")
+			}
+		})
+	}
+}
+
+func TestStrictCompatGatewayResponsesMatchOpenAIRecordings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		upstream string
+		fixture  string
+	}{
+		{
+			name:     "chat",
+			method:   http.MethodPost,
+			path:     "/v1/chat/completions",
+			body:     `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hello"}]}`,
+			upstream: "/chat/completions",
+			fixture:  "openai/chat_completion.json",
+		},
+		{
+			name:     "chat tools",
+			method:   http.MethodPost,
+			path:     "/v1/chat/completions",
+			body:     `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"weather?"}]}`,
+			upstream: "/chat/completions",
+			fixture:  "openai/chat_with_tools.json",
+		},
+		{
+			name:     "models",
+			method:   http.MethodGet,
+			path:     "/v1/models",
+			upstream: "/models",
+			fixture:  "openai/models.json",
+		},
+		{
+			name:     "responses",
+			method:   http.MethodPost,
+			path:     "/v1/responses",
+			body:     `{"model":"gpt-4o-mini","input":"hello"}`,
+			upstream: "/responses",
+			fixture:  "openai/responses.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := replayRoutableProvider{Provider: newOpenAIReplayProvider(t, map[string]replayRoute{
+				replayKey(tc.method, tc.upstream): jsonFixtureRoute(t, tc.fixture),
+			})}
+			srv := server.New(provider, &server.Config{StrictOpenAICompat: true})
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			requireKeysSubset(t, jsonKeyPaths(t, rec.Body.Bytes()), jsonKeyPaths(t, loadGoldenFileRaw(t, tc.fixture)))
+			for name := range rec.Header() {
+				require.False(t, strictcompat.IsExtensionHeader(name), "strict-mode response has extension header %s", name)
+			}
+		})
+	}
+}
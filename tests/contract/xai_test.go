@@ -21,7 +21,7 @@ func newXAIReplayProvider(t *testing.T, routes map[string]replayRoute) core.Prov
 	client := newReplayHTTPClient(t, routes)
 	provider := xai.NewWithHTTPClient("xai-test", client, llmclient.Hooks{})
 	provider.SetBaseURL("https://replay.local")
-	return provider
+	return instrumentReplayProvider("xai", provider)
 }
 
 func TestXAIReplayChatCompletion(t *testing.T) {
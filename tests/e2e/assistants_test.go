@@ -0,0 +1,163 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/auditlog"
+	"gomodel/internal/providers"
+	"gomodel/internal/providers/openai"
+	"gomodel/internal/server"
+	"gomodel/internal/usage"
+)
+
+// usageCollector is an in-memory usage logger for e2e assertions.
+type usageCollector struct {
+	mu      sync.Mutex
+	entries []*usage.UsageEntry
+}
+
+func (u *usageCollector) Write(entry *usage.UsageEntry) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.entries = append(u.entries, entry)
+}
+
+func (u *usageCollector) Config() usage.Config        { return usage.Config{Enabled: true} }
+func (u *usageCollector) Flush(context.Context) error { return nil }
+func (u *usageCollector) Close() error                { return nil }
+
+func (u *usageCollector) Entries() []*usage.UsageEntry {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]*usage.UsageEntry(nil), u.entries...)
+}
+
+// setupAssistantsServer starts a gateway with the Assistants passthrough
+// enabled and an OpenAI provider pointed at the mock LLM server.
+func setupAssistantsServer(t *testing.T, store *mockLogStore, usageLogger usage.LoggerInterface) *httptest.Server {
+	t.Helper()
+
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithType(openai.New(providers.ProviderConfig{
+		APIKey:  "sk-test-key-12345",
+		BaseURL: mockLLMURL,
+	}, providers.ProviderOptions{}), "openai")
+	require.NoError(t, registry.Initialize(context.Background()))
+
+	router, err := providers.NewRouter(registry)
+	require.NoError(t, err)
+
+	logger := auditlog.NewLogger(store, auditlog.Config{
+		Enabled:       true,
+		LogBodies:     true,
+		BufferSize:    100,
+		FlushInterval: 100 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = logger.Close() })
+
+	ts := httptest.NewServer(server.New(router, &server.Config{
+		AuditLogger:                 logger,
+		UsageLogger:                 usageLogger,
+		EnableAssistantsPassthrough: true,
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAssistantsPassthrough_ThreadAndRunLifecycle(t *testing.T) {
+	store := newMockLogStore()
+	usageLogger := &usageCollector{}
+	ts := setupAssistantsServer(t, store, usageLogger)
+	mockServer.ResetRequests()
+
+	post := func(path, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("OpenAI-Beta", "assistants=v2")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post("/v1/threads", `{}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var thread map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&thread))
+	closeBody(resp)
+	threadID, _ := thread["id"].(string)
+	require.Equal(t, "thread_mock", threadID)
+
+	resp = post("/v1/threads/"+threadID+"/runs", `{"assistant_id":"asst_mock","stream":true}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	stream, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	closeBody(resp)
+	for _, event := range []string{"thread.run.created", "thread.message.delta", "thread.run.step.completed", "thread.run.completed", "data: [DONE]"} {
+		assert.Contains(t, string(stream), event)
+	}
+
+	getResp, err := http.Get(ts.URL + "/v1/threads/" + threadID + "/runs/run_mock")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, getResp.StatusCode)
+	var run map[string]any
+	require.NoError(t, json.NewDecoder(getResp.Body).Decode(&run))
+	closeBody(getResp)
+	assert.Equal(t, "completed", run["status"])
+
+	getResp, err = http.Get(ts.URL + "/v1/threads/" + threadID + "/messages?order=asc")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, getResp.StatusCode)
+	closeBody(getResp)
+
+	// The upstream saw the verbatim requests with the provider key.
+	upstream := mockServer.Requests()
+	require.Len(t, upstream, 4)
+	assert.Equal(t, "/threads", upstream[0].Path)
+	assert.Equal(t, "/threads/thread_mock/runs", upstream[1].Path)
+	assert.JSONEq(t, `{"assistant_id":"asst_mock","stream":true}`, string(upstream[1].Body))
+	assert.Equal(t, "/threads/thread_mock/messages", upstream[3].Path)
+	for _, req := range upstream {
+		assert.Equal(t, "Bearer sk-test-key-12345", req.Headers.Get("Authorization"))
+	}
+	assert.Equal(t, "assistants=v2", upstream[1].Headers.Get("OpenAI-Beta"))
+
+	// Only the streamed run reports usage.
+	entries := usageLogger.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, 21, entries[0].InputTokens)
+	assert.Equal(t, 7, entries[0].OutputTokens)
+	assert.Equal(t, "/v1/threads/thread_mock/runs", entries[0].Endpoint)
+
+	logs := store.WaitForAPIEntries(4, 2*time.Second)
+	require.Len(t, logs, 4)
+	paths := make(map[string]int)
+	for _, entry := range logs {
+		paths[entry.Path] = entry.StatusCode
+		assert.Equal(t, "openai", entry.Provider)
+	}
+	assert.Equal(t, http.StatusOK, paths["/v1/threads"])
+	assert.Equal(t, http.StatusOK, paths["/v1/threads/thread_mock/runs"])
+	assert.Equal(t, http.StatusOK, paths["/v1/threads/thread_mock/runs/run_mock"])
+}
+
+func TestAssistantsPassthrough_DisabledByDefault(t *testing.T) {
+	resp, err := http.Post(gatewayURL+"/v1/threads", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	defer closeBody(resp)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -4,6 +4,7 @@ package e2e
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
@@ -374,6 +375,77 @@ func TestChatCompletionErrors(t *testing.T) {
 	})
 }
 
+func TestChatCompletionCompare(t *testing.T) {
+	t.Run("partial failure keeps model order", func(t *testing.T) {
+		resp := sendJSONRequest(t, gatewayURL+chatComparePath, map[string]any{
+			"models":   []string{"gpt-4", "invalid-model-xyz", "gpt-3.5-turbo"},
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		defer closeBody(resp)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var compareResp core.ChatComparisonResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&compareResp))
+		assert.NotEmpty(t, compareResp.ID)
+		assert.Equal(t, "chat.completion.comparison", compareResp.Object)
+		require.Len(t, compareResp.Results, 3)
+
+		assert.Equal(t, "gpt-4", compareResp.Results[0].Model)
+		assert.Equal(t, http.StatusOK, compareResp.Results[0].StatusCode)
+		require.NotNil(t, compareResp.Results[0].Response)
+		assert.Equal(t, "gpt-4", compareResp.Results[0].Response.Model)
+
+		assert.Equal(t, "invalid-model-xyz", compareResp.Results[1].Model)
+		assert.Equal(t, http.StatusBadRequest, compareResp.Results[1].StatusCode)
+		require.NotNil(t, compareResp.Results[1].Error)
+		assert.Nil(t, compareResp.Results[1].Response)
+
+		assert.Equal(t, "gpt-3.5-turbo", compareResp.Results[2].Model)
+		assert.Equal(t, http.StatusOK, compareResp.Results[2].StatusCode)
+		require.NotNil(t, compareResp.Results[2].Response)
+	})
+
+	t.Run("streaming multiplexes models", func(t *testing.T) {
+		resp := sendJSONRequest(t, gatewayURL+chatComparePath, map[string]any{
+			"models":   []string{"gpt-4", "gpt-3.5-turbo"},
+			"stream":   true,
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		defer closeBody(resp)
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		done := map[int]bool{}
+		for line := range strings.SplitSeq(string(body), "\n") {
+			data, ok := strings.CutPrefix(line, "data: ")
+			if !ok || data == "[DONE]" {
+				continue
+			}
+			var event core.ChatComparisonStreamEvent
+			require.NoError(t, json.Unmarshal([]byte(data), &event))
+			if event.Done {
+				done[event.Index] = true
+			}
+		}
+		assert.Equal(t, map[int]bool{0: true, 1: true}, done)
+		assert.True(t, strings.HasSuffix(strings.TrimSpace(string(body)), "data: [DONE]"))
+	})
+
+	t.Run("missing models", func(t *testing.T) {
+		resp := sendJSONRequest(t, gatewayURL+chatComparePath, map[string]any{
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		defer closeBody(resp)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
 func TestHealthAndModels(t *testing.T) {
 	t.Run("health endpoint", func(t *testing.T) {
 		resp, err := http.Get(gatewayURL + healthPath)
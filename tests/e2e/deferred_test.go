@@ -0,0 +1,195 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/config"
+	"gomodel/internal/core"
+	"gomodel/internal/deferred"
+	"gomodel/internal/providers"
+	"gomodel/internal/server"
+)
+
+func setupDeferredTestServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	testProvider := NewTestProvider(mockLLMURL, "sk-test-key-12345")
+	registry := providers.NewModelRegistry()
+	registry.RegisterProvider(testProvider)
+	require.NoError(t, registry.Initialize(context.Background()))
+
+	router, err := providers.NewRouter(registry)
+	require.NoError(t, err)
+
+	service, err := deferred.NewService(deferred.NewMemoryStore(), config.DeferredConfig{
+		Enabled:        true,
+		TTL:            time.Minute,
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		PollInterval:   50 * time.Millisecond,
+		Callbacks:      config.DeferredCallbackConfig{Enabled: true, AllowedHosts: []string{"127.0.0.1"}},
+	})
+	require.NoError(t, err)
+
+	srv := server.New(router, &server.Config{Deferred: service})
+	stopWorker := service.Start(srv.DeferredExecutor())
+
+	serverURL := "http://" + listener.Addr().String()
+	serverCtx, cancel := context.WithCancel(context.Background())
+	serverDone := make(chan error, 1)
+	go func() {
+		serverDone <- srv.StartWithListener(serverCtx, listener)
+	}()
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	for i := 0; i < 30; i++ {
+		resp, err := client.Get(serverURL + "/health")
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Cleanup(func() {
+		stopWorker()
+		cancel()
+		select {
+		case err := <-serverDone:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("server shutdown timed out")
+		}
+	})
+	return serverURL
+}
+
+func postDeferredChat(t *testing.T, serverURL string, payload core.ChatRequest, headers map[string]string) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, serverURL+"/v1/chat/completions", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.DeferredHeader, "true")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func getDeferred(t *testing.T, serverURL, id string) core.DeferredRequestResponse {
+	t.Helper()
+	resp, err := http.Get(serverURL + "/v1/deferred/" + id)
+	require.NoError(t, err)
+	defer closeBody(resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result core.DeferredRequestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+func TestDeferredExecution_E2E(t *testing.T) {
+	serverURL := setupDeferredTestServer(t)
+	payload := core.ChatRequest{
+		Model:    "gpt-4",
+		Messages: []core.Message{{Role: "user", Content: "Hello after recovery"}},
+	}
+
+	t.Run("provider failure is queued and replayed after recovery", func(t *testing.T) {
+		mockServer.FailNext(http.StatusServiceUnavailable, "upstream unavailable")
+
+		resp := postDeferredChat(t, serverURL, payload, nil)
+		defer closeBody(resp)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		var queued core.DeferredRequestResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&queued))
+		assert.Equal(t, string(deferred.StatusQueued), queued.Status)
+		assert.Equal(t, queued.ID, resp.Header.Get(core.DeferredIDHeader))
+
+		var result core.DeferredRequestResponse
+		require.Eventually(t, func() bool {
+			result = getDeferred(t, serverURL, queued.ID)
+			return result.Status == string(deferred.StatusSucceeded)
+		}, 5*time.Second, 50*time.Millisecond)
+
+		assert.Equal(t, http.StatusOK, result.StatusCode)
+		assert.Equal(t, 1, result.Attempts)
+		assert.NotNil(t, result.CompletedAt)
+
+		var chat core.ChatResponse
+		require.NoError(t, json.Unmarshal(result.Response, &chat))
+		require.NotEmpty(t, chat.Choices)
+	})
+
+	t.Run("callbacks to internal addresses are refused", func(t *testing.T) {
+		delivered := make(chan struct{}, 1)
+		callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			delivered <- struct{}{}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer callback.Close()
+
+		mockServer.FailNext(http.StatusBadGateway, "upstream unavailable")
+		resp := postDeferredChat(t, serverURL, payload, map[string]string{core.DeferredCallbackHeader: callback.URL})
+		defer closeBody(resp)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		var queued core.DeferredRequestResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&queued))
+		require.Eventually(t, func() bool {
+			return getDeferred(t, serverURL, queued.ID).Status == string(deferred.StatusSucceeded)
+		}, 5*time.Second, 50*time.Millisecond)
+
+		select {
+		case <-delivered:
+			t.Fatal("callback was delivered to a loopback address")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("callback hosts outside the allow-list are rejected", func(t *testing.T) {
+		resp := postDeferredChat(t, serverURL, payload, map[string]string{core.DeferredCallbackHeader: "https://hooks.example.com/done"})
+		defer closeBody(resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("successful requests are returned directly", func(t *testing.T) {
+		resp := postDeferredChat(t, serverURL, payload, nil)
+		defer closeBody(resp)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(core.DeferredIDHeader))
+	})
+
+	t.Run("streaming requests are rejected", func(t *testing.T) {
+		streaming := payload
+		streaming.Stream = true
+		resp := postDeferredChat(t, serverURL, streaming, nil)
+		defer closeBody(resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -3,7 +3,6 @@
 package e2e
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"io"
@@ -15,13 +14,16 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"gomodel/internal/core"
+	"gomodel/internal/sse"
 )
 
 // API endpoints
 const (
 	chatCompletionsPath = "/v1/chat/completions"
+	chatComparePath     = "/v1/chat/completions/compare"
 	responsesPath       = "/v1/responses"
 	modelsPath          = "/v1/models"
+	embeddingsPath      = "/v1/embeddings"
 	healthPath          = "/health"
 )
 
@@ -102,22 +104,20 @@ type StreamChunk struct {
 func readStreamingResponse(t *testing.T, body io.Reader) []StreamChunk {
 	t.Helper()
 	chunks := make([]StreamChunk, 0)
-	scanner := bufio.NewScanner(body)
+	events := sse.NewReader(body)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "data: ") {
-			continue
+	for {
+		event, err := events.Next()
+		if err != nil {
+			break
 		}
-
-		data := strings.TrimPrefix(line, "data: ")
-		if data == "[DONE]" {
+		if sse.IsDone(event.Data) {
 			chunks = append(chunks, StreamChunk{Done: true})
 			break
 		}
 
 		var chunk StreamChunk
-		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		if err := json.Unmarshal(event.Data, &chunk); err != nil {
 			continue
 		}
 		chunks = append(chunks, chunk)
@@ -137,36 +137,27 @@ type ResponsesStreamEvent struct {
 func readResponsesStream(t *testing.T, body io.Reader) []ResponsesStreamEvent {
 	t.Helper()
 	events := make([]ResponsesStreamEvent, 0)
-	scanner := bufio.NewScanner(body)
+	reader := sse.NewReader(body)
 
-	var currentEvent ResponsesStreamEvent
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if strings.HasPrefix(line, "event: ") {
-			currentEvent.Type = strings.TrimPrefix(line, "event: ")
-			continue
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			break
+		}
+		if sse.IsDone(event.Data) {
+			events = append(events, ResponsesStreamEvent{Done: true})
+			break
 		}
 
-		if strings.HasPrefix(line, "data: ") {
-			data := strings.TrimPrefix(line, "data: ")
-			if data == "[DONE]" {
-				events = append(events, ResponsesStreamEvent{Done: true})
-				break
-			}
-
+		current := ResponsesStreamEvent{Type: event.Type}
+		if decoded, err := sse.DecodeResponsesStreamEvent(event); err == nil {
+			current.Type = decoded.Type
 			var eventData map[string]interface{}
-			if err := json.Unmarshal([]byte(data), &eventData); err == nil {
-				currentEvent.Data = eventData
-				if currentEvent.Type == "" {
-					if typ, ok := eventData["type"].(string); ok {
-						currentEvent.Type = typ
-					}
-				}
+			if err := json.Unmarshal(event.Data, &eventData); err == nil {
+				current.Data = eventData
 			}
-			events = append(events, currentEvent)
-			currentEvent = ResponsesStreamEvent{}
 		}
+		events = append(events, current)
 	}
 
 	return events
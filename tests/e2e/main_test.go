@@ -68,7 +68,7 @@ func TestMain(m *testing.M) {
 
 	// 5. Start the gateway server (bind to loopback only)
 	// Note: No master key for e2e tests (tests run in unsafe mode)
-	testServer = server.New(router, &server.Config{})
+	testServer = server.New(router, &server.Config{WebSocket: &server.WebSocketConfig{}})
 	serverDone = make(chan error, 1)
 	go func() {
 		serverDone <- testServer.StartWithListener(testContext, listener)
@@ -171,6 +171,7 @@ func (p *TestProvider) StreamResponses(ctx context.Context, req *core.ResponsesR
 	return forwardResponsesStreamRequest(ctx, p.httpClient, p.baseURL, p.apiKey, req)
 }
 
-func (p *TestProvider) Embeddings(_ context.Context, _ *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
-	return nil, core.NewInvalidRequestError("test provider does not support embeddings", nil)
+// Embeddings forwards the embeddings request to the mock server.
+func (p *TestProvider) Embeddings(ctx context.Context, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	return forwardEmbeddingsRequest(ctx, p.httpClient, p.baseURL, p.apiKey, req)
 }
@@ -0,0 +1,166 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/config"
+	"gomodel/internal/admin"
+	"gomodel/internal/core"
+	"gomodel/internal/maintenance"
+	"gomodel/internal/providers"
+	"gomodel/internal/server"
+)
+
+// setupMaintenanceServer starts a gateway whose upstream holds every request
+// until release is closed. arrived receives one value per upstream request.
+func setupMaintenanceServer(t *testing.T) (gateway string, arrived chan struct{}, release chan struct{}) {
+	t.Helper()
+
+	target, err := url.Parse(mockLLMURL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	arrived = make(chan struct{}, 16)
+	release = make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(upstream.Close)
+
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithType(NewTestProvider(upstream.URL, "sk-test-key-12345"), "test")
+	require.NoError(t, registry.Initialize(context.Background()))
+	router, err := providers.NewRouter(registry)
+	require.NoError(t, err)
+
+	mode := maintenance.New(config.MaintenanceConfig{})
+	srv := server.New(router, &server.Config{
+		AdminEndpointsEnabled: true,
+		AdminHandler:          admin.NewHandler(nil, registry, admin.WithMaintenance(mode)),
+		Maintenance:           mode,
+	})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts.URL, arrived, release
+}
+
+func putMaintenance(t *testing.T, gateway string, payload map[string]any) admin.MaintenanceResponse {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, gateway+"/admin/api/v1/maintenance", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer closeBody(resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state admin.MaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	return state
+}
+
+func getHealth(t *testing.T, gateway string) map[string]any {
+	t.Helper()
+	resp, err := http.Get(gateway + "/health")
+	require.NoError(t, err)
+	defer closeBody(resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var health map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+	return health
+}
+
+func maintenanceChatPayload() core.ChatRequest {
+	return core.ChatRequest{
+		Model:    "gpt-4",
+		Messages: []core.Message{{Role: "user", Content: "hi"}},
+	}
+}
+
+func TestMaintenance_EnableMidTrafficDrainsAndExpires_E2E(t *testing.T) {
+	gateway, arrived, release := setupMaintenanceServer(t)
+
+	// A request admitted before maintenance starts.
+	inFlight := make(chan int, 1)
+	go func() {
+		resp, err := sendJSONRequestNoT(gateway+chatCompletionsPath, maintenanceChatPayload())
+		if err != nil {
+			inFlight <- 0
+			return
+		}
+		defer closeBody(resp)
+		inFlight <- resp.StatusCode
+	}()
+	select {
+	case <-arrived:
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request never reached the upstream")
+	}
+
+	state := putMaintenance(t, gateway, map[string]any{"enabled": true, "message": "rotating provider keys", "duration_seconds": 2})
+	assert.True(t, state.Active)
+	assert.Equal(t, 1, state.InFlight)
+	require.NotNil(t, state.Until)
+
+	// New model requests are rejected with an OpenAI-shaped 503.
+	resp := sendJSONRequest(t, gateway+chatCompletionsPath, maintenanceChatPayload())
+	var rejected core.OpenAIErrorEnvelope
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rejected))
+	closeBody(resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Contains(t, []string{"1", "2"}, resp.Header.Get("Retry-After"))
+	assert.Equal(t, "rotating provider keys", rejected.Error.Message)
+	assert.Equal(t, core.ErrorTypeOverloaded, rejected.Error.Type)
+	require.NotNil(t, rejected.Error.Code)
+	assert.Equal(t, maintenance.ErrorCode, *rejected.Error.Code)
+
+	// Health and admin endpoints keep answering.
+	health := getHealth(t, gateway)
+	assert.Equal(t, "maintenance", health["status"])
+	adminResp, err := http.Get(gateway + "/admin/api/v1/maintenance")
+	require.NoError(t, err)
+	closeBody(adminResp)
+	assert.Equal(t, http.StatusOK, adminResp.StatusCode)
+
+	// Draining waits for the in-flight request, which finishes normally.
+	drained := make(chan admin.MaintenanceResponse, 1)
+	go func() {
+		drained <- putMaintenance(t, gateway, map[string]any{"enabled": true, "message": "rotating provider keys", "duration_seconds": 2, "drain": true})
+	}()
+	select {
+	case <-drained:
+		t.Fatal("drain returned while a request was still in flight")
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(release)
+	assert.Equal(t, http.StatusOK, <-inFlight)
+	drainState := <-drained
+	require.NotNil(t, drainState.Drained)
+	assert.True(t, *drainState.Drained)
+	assert.Equal(t, 0, drainState.InFlight)
+
+	// Maintenance ends by itself once its duration has passed.
+	require.Eventually(t, func() bool {
+		return getHealth(t, gateway)["status"] == "ok"
+	}, 5*time.Second, 100*time.Millisecond)
+	resp = sendJSONRequest(t, gateway+chatCompletionsPath, maintenanceChatPayload())
+	closeBody(resp)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
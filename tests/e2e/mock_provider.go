@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"time"
 
@@ -62,6 +63,15 @@ func (m *MockLLMServer) ResetRequests() {
 	m.mu.Unlock()
 }
 
+// FailNext makes the next upstream request fail with code and message.
+func (m *MockLLMServer) FailNext(code int, message string) {
+	m.mu.Lock()
+	m.failNext = true
+	m.failWithCode = code
+	m.failMessage = message
+	m.mu.Unlock()
+}
+
 // NewMockLLMServer creates a new mock LLM server.
 func NewMockLLMServer() *MockLLMServer {
 	m := &MockLLMServer{
@@ -134,7 +144,15 @@ func (m *MockLLMServer) handleRequest(w http.ResponseWriter, r *http.Request, bo
 		m.handleResponses(w, r, body)
 	case "/models":
 		m.handleListModels(w)
+	case "/embeddings":
+		m.handleEmbeddings(w, body)
+	case "/threads":
+		m.handleCreateThread(w, r)
 	default:
+		if strings.HasPrefix(r.URL.Path, "/threads/") {
+			m.handleThreadResource(w, r, body)
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 		_, _ = w.Write([]byte(`{"error": {"message": "Not found", "type": "invalid_request_error"}}`))
 	}
@@ -176,7 +194,7 @@ func (m *MockLLMServer) handleChatCompletion(w http.ResponseWriter, r *http.Requ
 								Type: "function",
 								Function: core.FunctionCall{
 									Name:      toolName,
-									Arguments: `{"city":"Warsaw"}`,
+									Arguments: mockToolArguments(req, toolName),
 								},
 							},
 						},
@@ -290,7 +308,7 @@ func (m *MockLLMServer) handleStreamingToolResponse(w http.ResponseWriter, req c
 		Type: "function",
 		Function: core.FunctionCall{
 			Name:      toolName,
-			Arguments: `{"city":"Warsaw"}`,
+			Arguments: mockToolArguments(req, toolName),
 		},
 	}
 
@@ -347,6 +365,38 @@ func (m *MockLLMServer) handleStreamingToolResponse(w http.ResponseWriter, req c
 	flusher.Flush()
 }
 
+// handleEmbeddings returns a deterministic three-dimensional embedding per input.
+func (m *MockLLMServer) handleEmbeddings(w http.ResponseWriter, body []byte) {
+	var req core.EmbeddingRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "Invalid request body", "type": "invalid_request_error"}}`))
+		return
+	}
+
+	var inputs []string
+	switch input := req.Input.(type) {
+	case string:
+		inputs = []string{input}
+	case []interface{}:
+		for _, item := range input {
+			text, _ := item.(string)
+			inputs = append(inputs, text)
+		}
+	}
+
+	response := core.EmbeddingResponse{Object: "list", Model: req.Model}
+	for i, input := range inputs {
+		vector, _ := json.Marshal([]float64{float64(len(input)), float64(i), 0.5})
+		response.Data = append(response.Data, core.EmbeddingData{Object: "embedding", Embedding: vector, Index: i})
+		response.Usage.PromptTokens += len(strings.Fields(input))
+	}
+	response.Usage.TotalTokens = response.Usage.PromptTokens
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
 // handleListModels handles the models list endpoint.
 func (m *MockLLMServer) handleListModels(w http.ResponseWriter) {
 	response := core.ModelsResponse{
@@ -491,6 +541,118 @@ func (m *MockLLMServer) handleResponsesStreaming(w http.ResponseWriter, req core
 }
 
 // extractInputText extracts text content from the input field.
+// handleCreateThread handles Assistants API thread creation.
+func (m *MockLLMServer) handleCreateThread(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":         "thread_mock",
+		"object":     "thread",
+		"created_at": time.Now().Unix(),
+		"metadata":   map[string]any{},
+	})
+}
+
+// handleThreadResource handles runs and messages below /threads/{thread_id}.
+func (m *MockLLMServer) handleThreadResource(w http.ResponseWriter, r *http.Request, body []byte) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/threads/"), "/")
+	threadID := parts[0]
+	switch {
+	case len(parts) == 2 && parts[1] == "runs" && r.Method == http.MethodPost:
+		var req struct {
+			AssistantID string `json:"assistant_id"`
+			Stream      bool   `json:"stream"`
+		}
+		_ = json.Unmarshal(body, &req)
+		if req.Stream {
+			m.handleRunStream(w, threadID, req.AssistantID)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(mockRun(threadID, req.AssistantID, "queued", nil))
+	case len(parts) == 3 && parts[1] == "runs" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(mockRun(threadID, "asst_mock", "completed", mockRunUsage()))
+	case len(parts) == 2 && parts[1] == "messages" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"data": []any{map[string]any{
+				"id":        "msg_mock",
+				"object":    "thread.message",
+				"thread_id": threadID,
+				"role":      "assistant",
+				"content":   []any{map[string]any{"type": "text", "text": map[string]any{"value": "Hello from the assistant", "annotations": []any{}}}},
+			}},
+			"has_more": false,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"message": "Not found", "type": "invalid_request_error"}}`))
+	}
+}
+
+// handleRunStream streams the run lifecycle events of an Assistants run.
+func (m *MockLLMServer) handleRunStream(w http.ResponseWriter, threadID, assistantID string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	step := map[string]any{
+		"id":     "step_mock",
+		"object": "thread.run.step",
+		"run_id": "run_mock",
+		"type":   "message_creation",
+		"status": "completed",
+		"usage":  mockRunUsage(),
+	}
+	delta := map[string]any{
+		"id":     "msg_mock",
+		"object": "thread.message.delta",
+		"delta":  map[string]any{"content": []any{map[string]any{"index": 0, "type": "text", "text": map[string]any{"value": "Hello from the assistant"}}}},
+	}
+	events := []struct {
+		name string
+		data any
+	}{
+		{"thread.run.created", mockRun(threadID, assistantID, "queued", nil)},
+		{"thread.run.in_progress", mockRun(threadID, assistantID, "in_progress", nil)},
+		{"thread.message.delta", delta},
+		{"thread.run.step.completed", step},
+		{"thread.run.completed", mockRun(threadID, assistantID, "completed", mockRunUsage())},
+	}
+	for _, event := range events {
+		data, _ := json.Marshal(event.data)
+		_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	_, _ = fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func mockRun(threadID, assistantID, status string, usage map[string]any) map[string]any {
+	return map[string]any{
+		"id":           "run_mock",
+		"object":       "thread.run",
+		"thread_id":    threadID,
+		"assistant_id": assistantID,
+		"status":       status,
+		"model":        "gpt-4",
+		"usage":        usage,
+	}
+}
+
+func mockRunUsage() map[string]any {
+	return map[string]any{"prompt_tokens": 21, "completion_tokens": 7, "total_tokens": 28}
+}
+
 func extractInputText(input interface{}) string {
 	switch v := input.(type) {
 	case string:
@@ -555,6 +717,24 @@ func forcedToolName(req core.ChatRequest) string {
 	return ""
 }
 
+// mockToolArguments returns the arguments of a forced tool call: an
+// arithmetic expression for tools taking one, such as the gateway's
+// calculator server tool, and a city otherwise.
+func mockToolArguments(req core.ChatRequest, toolName string) string {
+	for _, tool := range req.Tools {
+		function, _ := tool["function"].(map[string]interface{})
+		if name, _ := function["name"].(string); name != toolName {
+			continue
+		}
+		parameters, _ := function["parameters"].(map[string]interface{})
+		properties, _ := parameters["properties"].(map[string]interface{})
+		if _, ok := properties["expression"]; ok {
+			return `{"expression":"6*7"}`
+		}
+	}
+	return `{"city":"Warsaw"}`
+}
+
 // splitIntoChunks splits a string into chunks of approximately n characters.
 func splitIntoChunks(s string, n int) []string {
 	if len(s) == 0 {
@@ -711,3 +891,37 @@ func forwardResponsesStreamRequest(ctx context.Context, client *http.Client, bas
 
 	return resp.Body, nil
 }
+
+// forwardEmbeddingsRequest forwards an embeddings request to the mock server.
+func forwardEmbeddingsRequest(ctx context.Context, client *http.Client, baseURL, apiKey string, req *core.EmbeddingRequest) (*core.EmbeddingResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("upstream error: %s", string(respBody))
+	}
+
+	var embeddingResp core.EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
+		return nil, err
+	}
+
+	return &embeddingResp, nil
+}
@@ -0,0 +1,126 @@
+#!/usr/bin/env python3
+"""SDK conformance runner for the official OpenAI Python client.
+
+Run through the e2e suite, which starts the gateway and sets OPENAI_BASE_URL,
+OPENAI_API_KEY and GOMODEL_SDK_MODEL:
+
+    pip install openai
+    GOMODEL_SDK_CONFORMANCE_CMD="python3 tests/e2e/sdk/openai_python.py" make test-e2e
+
+Each scenario mirrors one in TestSDKConformance; the first failure exits
+non-zero.
+"""
+
+import json
+import os
+import sys
+
+import openai
+
+MODEL = os.environ.get("GOMODEL_SDK_MODEL", "gpt-4")
+TOOLS = [
+    {
+        "type": "function",
+        "function": {
+            "name": "get_weather",
+            "parameters": {"type": "object", "properties": {"city": {"type": "string"}}},
+        },
+    }
+]
+TOOL_CHOICE = {"type": "function", "function": {"name": "get_weather"}}
+
+client = openai.OpenAI()
+
+
+def chat_completion():
+    completion = client.chat.completions.create(
+        model=MODEL, messages=[{"role": "user", "content": "SDK round trip"}]
+    )
+    choice = completion.choices[0]
+    assert completion.object == "chat.completion"
+    assert choice.finish_reason == "stop", choice.finish_reason
+    assert "SDK round trip" in choice.message.content
+    assert completion.usage.total_tokens == completion.usage.prompt_tokens + completion.usage.completion_tokens
+
+
+def chat_completion_with_tool_calls():
+    completion = client.chat.completions.create(
+        model=MODEL,
+        messages=[{"role": "user", "content": "What is the weather in Warsaw?"}],
+        tools=TOOLS,
+        tool_choice=TOOL_CHOICE,
+    )
+    choice = completion.choices[0]
+    assert choice.finish_reason == "tool_calls", choice.finish_reason
+    call = choice.message.tool_calls[0]
+    assert call.function.name == "get_weather"
+    json.loads(call.function.arguments)
+
+
+def streaming_chat_completion():
+    content, finish_reason = "", None
+    with client.chat.completions.stream(
+        model=MODEL, messages=[{"role": "user", "content": "SDK stream"}]
+    ) as stream:
+        for event in stream:
+            if event.type == "content.delta":
+                content += event.delta
+        completion = stream.get_final_completion()
+        finish_reason = completion.choices[0].finish_reason
+    assert "SDK stream" in content, content
+    assert finish_reason == "stop", finish_reason
+
+
+def streaming_chat_completion_with_tool_calls():
+    with client.chat.completions.stream(
+        model=MODEL,
+        messages=[{"role": "user", "content": "What is the weather in Warsaw?"}],
+        tools=TOOLS,
+        tool_choice=TOOL_CHOICE,
+    ) as stream:
+        completion = stream.get_final_completion()
+    choice = completion.choices[0]
+    assert choice.finish_reason == "tool_calls", choice.finish_reason
+    assert choice.message.tool_calls[0].function.name == "get_weather"
+
+
+def models():
+    listed = list(client.models.list())
+    assert listed and all(model.object == "model" for model in listed)
+
+
+def embeddings():
+    response = client.embeddings.create(model=MODEL, input=["first input", "second"])
+    assert [item.index for item in response.data] == [0, 1]
+    assert all(item.embedding for item in response.data)
+
+
+def error_envelope():
+    try:
+        client.chat.completions.create(
+            model="sdk-conformance-unknown-model", messages=[{"role": "user", "content": "hello"}]
+        )
+    except openai.BadRequestError as err:
+        assert err.message
+        return
+    raise AssertionError("unknown model did not raise BadRequestError")
+
+
+SCENARIOS = [
+    chat_completion,
+    chat_completion_with_tool_calls,
+    streaming_chat_completion,
+    streaming_chat_completion_with_tool_calls,
+    models,
+    embeddings,
+    error_envelope,
+]
+
+if __name__ == "__main__":
+    for scenario in SCENARIOS:
+        try:
+            scenario()
+        except Exception as err:  # noqa: BLE001 - report any SDK failure
+            print(f"FAIL {scenario.__name__}: {err!r}")
+            sys.exit(1)
+        print(f"ok   {scenario.__name__}")
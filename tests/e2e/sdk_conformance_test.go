@@ -0,0 +1,363 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/sse"
+)
+
+// The SDK conformance suite checks the gateway's OpenAI-compatible responses
+// against what the official client libraries require to decode them: the
+// fields each SDK reads without a fallback, their JSON types, and the values
+// its enums accept. The Go leg runs in-process against the mock provider;
+// other-language SDKs plug in through GOMODEL_SDK_CONFORMANCE_CMD (see
+// runExternalSDKConformance).
+
+// sdkConformanceModel is the model every SDK scenario requests.
+const sdkConformanceModel = "gpt-4"
+
+// sdkFinishReasons are the finish_reason values the OpenAI SDKs accept.
+var sdkFinishReasons = map[string]bool{
+	"stop":           true,
+	"length":         true,
+	"tool_calls":     true,
+	"content_filter": true,
+	"function_call":  true,
+}
+
+// sdkObject is a decoded JSON object whose fields are checked by path, so a
+// failure names the exact field an SDK would have choked on.
+type sdkObject struct {
+	t      *testing.T
+	path   string
+	fields map[string]json.RawMessage
+}
+
+func decodeSDKObject(t *testing.T, path string, raw []byte) sdkObject {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(raw, &fields), "%s is not a JSON object: %s", path, raw)
+	return sdkObject{t: t, path: path, fields: fields}
+}
+
+func (o sdkObject) raw(name string) json.RawMessage {
+	o.t.Helper()
+	raw, ok := o.fields[name]
+	require.True(o.t, ok, "%s.%s is missing", o.path, name)
+	return raw
+}
+
+func (o sdkObject) has(name string) bool {
+	raw, ok := o.fields[name]
+	return ok && string(raw) != "null"
+}
+
+func (o sdkObject) string(name string) string {
+	o.t.Helper()
+	var value string
+	require.NoError(o.t, json.Unmarshal(o.raw(name), &value), "%s.%s must be a string", o.path, name)
+	return value
+}
+
+func (o sdkObject) int(name string) int64 {
+	o.t.Helper()
+	var value int64
+	require.NoError(o.t, json.Unmarshal(o.raw(name), &value), "%s.%s must be an integer", o.path, name)
+	return value
+}
+
+func (o sdkObject) object(name string) sdkObject {
+	o.t.Helper()
+	return decodeSDKObject(o.t, o.path+"."+name, o.raw(name))
+}
+
+func (o sdkObject) array(name string) []sdkObject {
+	o.t.Helper()
+	var items []json.RawMessage
+	require.NoError(o.t, json.Unmarshal(o.raw(name), &items), "%s.%s must be an array", o.path, name)
+	objects := make([]sdkObject, len(items))
+	for i, item := range items {
+		objects[i] = decodeSDKObject(o.t, o.path+"."+name+"["+strconv.Itoa(i)+"]", item)
+	}
+	return objects
+}
+
+// nullableString returns a field the SDKs type as an optional string: it
+// must be absent, null or a string.
+func (o sdkObject) nullableString(name string) string {
+	o.t.Helper()
+	if !o.has(name) {
+		return ""
+	}
+	return o.string(name)
+}
+
+func requireSDKUsage(t *testing.T, usage sdkObject) {
+	t.Helper()
+	prompt, completion, total := usage.int("prompt_tokens"), usage.int("completion_tokens"), usage.int("total_tokens")
+	require.Equal(t, prompt+completion, total, "%s totals do not add up", usage.path)
+}
+
+func requireSDKToolCall(t *testing.T, call sdkObject, streamed bool) {
+	t.Helper()
+	if streamed {
+		call.int("index")
+	}
+	require.NotEmpty(t, call.string("id"))
+	require.Equal(t, "function", call.string("type"))
+	function := call.object("function")
+	require.Equal(t, "get_weather", function.string("name"))
+	var arguments map[string]any
+	require.NoError(t, json.Unmarshal([]byte(function.string("arguments")), &arguments), "%s.function.arguments must hold a JSON object", call.path)
+}
+
+func sdkToolRequest(stream bool) map[string]any {
+	return map[string]any{
+		"model":    sdkConformanceModel,
+		"stream":   stream,
+		"messages": []map[string]any{{"role": "user", "content": "What is the weather in Warsaw?"}},
+		"tools": []map[string]any{{
+			"type": "function",
+			"function": map[string]any{
+				"name":       "get_weather",
+				"parameters": map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+			},
+		}},
+		"tool_choice": map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}},
+	}
+}
+
+func readSDKBody(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "body: %s", body)
+	return body
+}
+
+// readSDKStream returns the chunk objects of a chat stream and fails unless it
+// ends with [DONE], the terminator the SDK stream iterators wait for.
+func readSDKStream(t *testing.T, resp *http.Response) []sdkObject {
+	t.Helper()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"), "content type %q", resp.Header.Get("Content-Type"))
+
+	var chunks []sdkObject
+	events := sse.NewReader(resp.Body)
+	for {
+		event, err := events.Next()
+		require.NoError(t, err, "stream ended without [DONE]")
+		if sse.IsDone(event.Data) {
+			return chunks
+		}
+		require.Empty(t, event.Type, "unexpected %q event: %s", event.Type, event.Data)
+		chunks = append(chunks, decodeSDKObject(t, "chunk["+strconv.Itoa(len(chunks))+"]", event.Data))
+	}
+}
+
+func requireSDKChatCompletion(t *testing.T, completion sdkObject) sdkObject {
+	t.Helper()
+	require.NotEmpty(t, completion.string("id"))
+	require.Equal(t, "chat.completion", completion.string("object"))
+	require.Positive(t, completion.int("created"))
+	require.Equal(t, sdkConformanceModel, completion.string("model"))
+	requireSDKUsage(t, completion.object("usage"))
+
+	choices := completion.array("choices")
+	require.Len(t, choices, 1)
+	choice := choices[0]
+	require.Zero(t, choice.int("index"))
+	require.True(t, sdkFinishReasons[choice.string("finish_reason")], "finish_reason %q", choice.string("finish_reason"))
+	message := choice.object("message")
+	require.Equal(t, "assistant", message.string("role"))
+	message.nullableString("content")
+	return choice
+}
+
+// requireSDKChunks checks every chunk of a chat stream and returns the
+// concatenated content and the finish reason of the last choice.
+func requireSDKChunks(t *testing.T, chunks []sdkObject) (string, string, []sdkObject) {
+	t.Helper()
+	require.NotEmpty(t, chunks)
+	var content strings.Builder
+	var finishReason string
+	var toolCalls []sdkObject
+	id := chunks[0].string("id")
+	for _, chunk := range chunks {
+		require.Equal(t, id, chunk.string("id"), "%s changes the completion id", chunk.path)
+		require.Equal(t, "chat.completion.chunk", chunk.string("object"))
+		require.Positive(t, chunk.int("created"))
+		require.Equal(t, sdkConformanceModel, chunk.string("model"))
+		for _, choice := range chunk.array("choices") {
+			require.Zero(t, choice.int("index"))
+			delta := choice.object("delta")
+			content.WriteString(delta.nullableString("content"))
+			if delta.has("tool_calls") {
+				toolCalls = append(toolCalls, delta.array("tool_calls")...)
+			}
+			if reason := choice.nullableString("finish_reason"); reason != "" {
+				require.True(t, sdkFinishReasons[reason], "finish_reason %q", reason)
+				finishReason = reason
+			}
+		}
+	}
+	return content.String(), finishReason, toolCalls
+}
+
+func TestSDKConformance(t *testing.T) {
+	t.Run("chat completion", func(t *testing.T) {
+		resp := sendRawChatRequest(t, map[string]any{
+			"model":    sdkConformanceModel,
+			"messages": []map[string]any{{"role": "user", "content": "SDK round trip"}},
+		})
+		defer closeBody(resp)
+
+		choice := requireSDKChatCompletion(t, decodeSDKObject(t, "completion", readSDKBody(t, resp)))
+		require.Equal(t, "stop", choice.string("finish_reason"))
+		require.Contains(t, choice.object("message").string("content"), "SDK round trip")
+	})
+
+	t.Run("chat completion with tool calls", func(t *testing.T) {
+		resp := sendRawChatRequest(t, sdkToolRequest(false))
+		defer closeBody(resp)
+
+		choice := requireSDKChatCompletion(t, decodeSDKObject(t, "completion", readSDKBody(t, resp)))
+		require.Equal(t, "tool_calls", choice.string("finish_reason"))
+		calls := choice.object("message").array("tool_calls")
+		require.Len(t, calls, 1)
+		requireSDKToolCall(t, calls[0], false)
+	})
+
+	t.Run("streaming chat completion", func(t *testing.T) {
+		resp := sendRawChatRequest(t, map[string]any{
+			"model":          sdkConformanceModel,
+			"stream":         true,
+			"stream_options": map[string]any{"include_usage": true},
+			"messages":       []map[string]any{{"role": "user", "content": "SDK stream"}},
+		})
+		defer closeBody(resp)
+
+		chunks := readSDKStream(t, resp)
+		content, finishReason, _ := requireSDKChunks(t, chunks)
+		require.Contains(t, content, "SDK stream")
+		require.Equal(t, "stop", finishReason)
+		for _, chunk := range chunks {
+			if chunk.has("usage") {
+				requireSDKUsage(t, chunk.object("usage"))
+			}
+		}
+	})
+
+	t.Run("streaming chat completion with tool calls", func(t *testing.T) {
+		resp := sendRawChatRequest(t, sdkToolRequest(true))
+		defer closeBody(resp)
+
+		_, finishReason, toolCalls := requireSDKChunks(t, readSDKStream(t, resp))
+		require.Equal(t, "tool_calls", finishReason)
+		require.Len(t, toolCalls, 1)
+		requireSDKToolCall(t, toolCalls[0], true)
+	})
+
+	t.Run("models", func(t *testing.T) {
+		resp, err := http.Get(gatewayURL + modelsPath)
+		require.NoError(t, err)
+		defer closeBody(resp)
+
+		list := decodeSDKObject(t, "models", readSDKBody(t, resp))
+		require.Equal(t, "list", list.string("object"))
+		models := list.array("data")
+		require.NotEmpty(t, models)
+		for _, model := range models {
+			require.NotEmpty(t, model.string("id"))
+			require.Equal(t, "model", model.string("object"))
+			model.int("created")
+			model.string("owned_by")
+		}
+	})
+
+	t.Run("embeddings", func(t *testing.T) {
+		resp := sendJSONRequest(t, gatewayURL+embeddingsPath, map[string]any{
+			"model": sdkConformanceModel,
+			"input": []string{"first input", "second"},
+		})
+		defer closeBody(resp)
+
+		list := decodeSDKObject(t, "embeddings", readSDKBody(t, resp))
+		require.Equal(t, "list", list.string("object"))
+		require.Equal(t, sdkConformanceModel, list.string("model"))
+		usage := list.object("usage")
+		require.Equal(t, usage.int("prompt_tokens"), usage.int("total_tokens"))
+		data := list.array("data")
+		require.Len(t, data, 2)
+		for i, item := range data {
+			require.Equal(t, "embedding", item.string("object"))
+			require.Equal(t, int64(i), item.int("index"))
+			var vector []float64
+			require.NoError(t, json.Unmarshal(item.raw("embedding"), &vector), "%s.embedding must be a float array", item.path)
+			require.NotEmpty(t, vector)
+		}
+	})
+
+	t.Run("error envelope", func(t *testing.T) {
+		resp := sendRawChatRequest(t, map[string]any{
+			"model":    "sdk-conformance-unknown-model",
+			"messages": []map[string]any{{"role": "user", "content": "hello"}},
+		})
+		defer closeBody(resp)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		apiErr := decodeSDKObject(t, "response", body).object("error")
+		require.NotEmpty(t, apiErr.string("message"))
+		require.NotEmpty(t, apiErr.string("type"))
+	})
+}
+
+// TestSDKConformance_ExternalClient runs an official client library written in
+// another language against the gateway. GOMODEL_SDK_CONFORMANCE_CMD is a shell
+// command that exercises the same scenarios as TestSDKConformance and exits
+// non-zero on the first incompatibility; it is given the gateway through the
+// standard OPENAI_BASE_URL and OPENAI_API_KEY variables, for example:
+//
+//	GOMODEL_SDK_CONFORMANCE_CMD="python3 tests/e2e/sdk/openai_python.py" make test-e2e
+func TestSDKConformance_ExternalClient(t *testing.T) {
+	command := os.Getenv("GOMODEL_SDK_CONFORMANCE_CMD")
+	if command == "" {
+		t.Skip("GOMODEL_SDK_CONFORMANCE_CMD is not set")
+	}
+	runExternalSDKConformance(t, command)
+}
+
+func runExternalSDKConformance(t *testing.T, command string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(testContext, 2*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = "../.."
+	cmd.Env = append(os.Environ(),
+		"OPENAI_BASE_URL="+gatewayURL+"/v1",
+		"OPENAI_API_KEY=sk-gomodel-conformance",
+		"GOMODEL_SDK_MODEL="+sdkConformanceModel,
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	require.NoError(t, cmd.Run(), "SDK conformance command failed:\n%s", output.String())
+	t.Logf("SDK conformance output:\n%s", output.String())
+}
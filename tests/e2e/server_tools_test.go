@@ -0,0 +1,127 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/core"
+	"gomodel/internal/gateway"
+	"gomodel/internal/providers"
+	"gomodel/internal/server"
+	"gomodel/internal/tools"
+)
+
+// setupServerToolsServer starts a gateway that grants the calculator server
+// tool to every request against the mock provider.
+func setupServerToolsServer(t *testing.T) string {
+	t.Helper()
+
+	registry := providers.NewModelRegistry()
+	registry.RegisterProviderWithType(NewTestProvider(mockLLMURL, "sk-test-key-12345"), "test")
+	require.NoError(t, registry.Initialize(context.Background()))
+	router, err := providers.NewRouter(registry)
+	require.NoError(t, err)
+
+	srv := server.New(router, &server.Config{
+		ServerTools: gateway.ServerToolsConfig{
+			Grants: []gateway.ServerToolGrant{{Tools: []string{tools.CalculatorTool}}},
+		},
+	})
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+// sendServerToolsRequest sends a chat request that opts into the calculator
+// and forces the model to call it, as the mock only calls forced tools.
+func sendServerToolsRequest(t *testing.T, gatewayURL string, stream bool) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(core.ChatRequest{
+		Model:    "gpt-4",
+		Messages: []core.Message{{Role: "user", Content: "What is 6 times 7?"}},
+		ToolChoice: map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": tools.CalculatorTool},
+		},
+		Stream: stream,
+	})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, gatewayURL+chatCompletionsPath, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.ServerToolsHeader, tools.CalculatorTool)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestServerTools_TwoIterationLoop_E2E(t *testing.T) {
+	gatewayURL := setupServerToolsServer(t)
+
+	resp := sendServerToolsRequest(t, gatewayURL, false)
+	defer closeBody(resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(core.ToolIterationsHeader))
+
+	var chat core.ChatResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&chat))
+	require.Len(t, chat.Choices, 1)
+	// The mock echoes the last message, which is the calculator's output.
+	content := core.ExtractTextContent(chat.Choices[0].Message.Content)
+	assert.Contains(t, content, `"result":"42"`)
+	assert.Empty(t, chat.Choices[0].Message.ToolCalls)
+	assert.Equal(t, 20, chat.Usage.PromptTokens, "usage sums both model calls")
+	assert.Equal(t, 40, chat.Usage.CompletionTokens)
+	assert.Equal(t, 60, chat.Usage.TotalTokens)
+}
+
+func TestServerTools_StreamsFinalTurn_E2E(t *testing.T) {
+	gatewayURL := setupServerToolsServer(t)
+
+	resp := sendServerToolsRequest(t, gatewayURL, true)
+	defer closeBody(resp)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get(core.ToolIterationsHeader))
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	chunks := readStreamingResponse(t, resp.Body)
+	var content strings.Builder
+	for _, chunk := range chunks {
+		for _, choice := range chunk.Choices {
+			delta, _ := choice["delta"].(map[string]interface{})
+			text, _ := delta["content"].(string)
+			content.WriteString(text)
+			assert.Nil(t, delta["tool_calls"], "server tool calls are not streamed to the client")
+		}
+	}
+	require.NotEmpty(t, chunks)
+	assert.True(t, chunks[len(chunks)-1].Done)
+	assert.Contains(t, content.String(), `"result":"42"`)
+}
+
+func TestServerTools_UngrantedToolRejected_E2E(t *testing.T) {
+	gatewayURL := setupServerToolsServer(t)
+
+	body, err := json.Marshal(maintenanceChatPayload())
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, gatewayURL+chatCompletionsPath, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(core.ServerToolsHeader, tools.CurrentTimeTool)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer closeBody(resp)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(core.ToolIterationsHeader))
+}
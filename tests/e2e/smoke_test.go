@@ -0,0 +1,57 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/smoke"
+)
+
+// TestSmokeMatrix runs the same request matrix as cmd/smoketest against the
+// in-process gateway.
+func TestSmokeMatrix(t *testing.T) {
+	runner, err := smoke.NewRunner(smoke.Config{
+		BaseURL:    gatewayURL,
+		MaxLatency: 5 * time.Second,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	targets, err := runner.Targets(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, targets)
+
+	results := runner.Run(ctx, targets)
+	require.Len(t, results, len(targets)*len(smoke.DefaultChecks))
+	for _, result := range results {
+		assert.Equal(t, smoke.StatusPass, result.Status, "%s %s: %s", result.Model, result.Check, result.Message)
+	}
+
+	var report bytes.Buffer
+	require.NoError(t, smoke.WriteTable(&report, results))
+	assert.Contains(t, report.String(), "0 failed")
+}
+
+func TestSmokeMatrix_BudgetCapsRequests(t *testing.T) {
+	runner, err := smoke.NewRunner(smoke.Config{
+		BaseURL:   gatewayURL,
+		MaxTokens: 10,
+		Budget:    1,
+	})
+	require.NoError(t, err)
+
+	targets, err := runner.Targets(context.Background())
+	require.NoError(t, err)
+	results := runner.Run(context.Background(), targets)
+	for _, result := range results {
+		assert.Equal(t, smoke.StatusSkip, result.Status)
+	}
+	assert.Zero(t, runner.Spent())
+}
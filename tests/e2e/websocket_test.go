@@ -0,0 +1,77 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/core"
+	"gomodel/internal/sse"
+	"gomodel/internal/websocket"
+)
+
+// withoutCreated drops the per-chunk timestamp so chunks from two runs compare.
+func withoutCreated(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var chunk map[string]any
+	require.NoError(t, json.Unmarshal(data, &chunk))
+	delete(chunk, "created")
+	return chunk
+}
+
+func TestChatCompletionWebSocket_ChunkParityWithSSE(t *testing.T) {
+	payload := core.ChatRequest{
+		Model:    "gpt-4",
+		Stream:   true,
+		Messages: []core.Message{{Role: "user", Content: "Count from 1 to 5"}},
+	}
+
+	resp := sendChatRequest(t, payload)
+	defer closeBody(resp)
+	var sseChunks []map[string]any
+	events := sse.NewReader(resp.Body)
+	for {
+		event, err := events.Next()
+		if err != nil {
+			break
+		}
+		if sse.IsDone(event.Data) {
+			break
+		}
+		sseChunks = append(sseChunks, withoutCreated(t, event.Data))
+	}
+	require.NotEmpty(t, sseChunks)
+
+	conn, _, err := websocket.Dial("ws://"+strings.TrimPrefix(gatewayURL, "http://")+chatCompletionsPath+"/ws", nil)
+	require.NoError(t, err)
+	payload.Stream = false // the WebSocket endpoint always streams
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteText(body))
+
+	var wsChunks []map[string]any
+	sawDone := false
+	for {
+		_, data, err := conn.ReadMessage(0)
+		if err != nil {
+			closeErr, ok := errors.AsType[*websocket.CloseError](err)
+			require.True(t, ok, "want a close frame, got %v", err)
+			assert.Equal(t, websocket.CloseNormal, closeErr.Code)
+			break
+		}
+		if sse.IsDone(data) {
+			sawDone = true
+			continue
+		}
+		wsChunks = append(wsChunks, withoutCreated(t, data))
+	}
+
+	assert.True(t, sawDone, "WebSocket stream should end with [DONE]")
+	assert.Equal(t, sseChunks, wsChunks)
+}
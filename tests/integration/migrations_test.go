@@ -0,0 +1,90 @@
+//go:build integration
+
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gomodel/internal/migrations"
+)
+
+// newEmptyPostgreSQLDatabase creates a database of its own next to the shared
+// test database and returns a pool for it.
+func newEmptyPostgreSQLDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	name := fmt.Sprintf("gomodel_migrations_%d", time.Now().UnixNano())
+	_, err := pgPool.Exec(testCtx, "CREATE DATABASE "+name)
+	require.NoError(t, err)
+
+	u, err := url.Parse(pgURL)
+	require.NoError(t, err)
+	u.Path = "/" + name
+	pool, err := pgxpool.New(testCtx, u.String())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = pgPool.Exec(testCtx, "DROP DATABASE IF EXISTS "+name)
+	})
+	return pool
+}
+
+func TestMigrations_PostgreSQL_ApplyAllToEmptyDatabase(t *testing.T) {
+	pool := newEmptyPostgreSQLDatabase(t)
+	migrator := migrations.NewPostgreSQL(pool, migrations.All())
+
+	applied, err := migrator.Up(testCtx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrations.All()))
+
+	for _, table := range []string{migrations.Table, "audit_logs", "audit_log_redactions", "usage", "batches", "deferred_requests", "aliases", "model_overrides", "auth_keys", "guardrail_definitions", "workflow_versions", "prompt_templates"} {
+		var exists bool
+		require.NoError(t, pool.QueryRow(testCtx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists))
+		assert.True(t, exists, "table %s should exist", table)
+	}
+
+	applied, err = migrator.Up(testCtx)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "re-running Up must be a no-op")
+
+	status, err := migrator.Check(testCtx)
+	require.NoError(t, err)
+	assert.Equal(t, status.Latest, status.Current)
+}
+
+func TestMigrations_PostgreSQL_ReversibleStepsAndRefusal(t *testing.T) {
+	pool := newEmptyPostgreSQLDatabase(t)
+	withNotes := append(migrations.All(), migrations.Migration{
+		Version: 1000,
+		Name:    "notes",
+		Up:      migrations.Script{SQL: "CREATE TABLE notes (id TEXT PRIMARY KEY)"},
+		Down:    migrations.Script{SQL: "DROP TABLE notes"},
+	})
+
+	_, err := migrations.NewPostgreSQL(pool, withNotes).Up(testCtx)
+	require.NoError(t, err)
+
+	older := migrations.NewPostgreSQL(pool, migrations.All())
+	_, err = older.Up(testCtx)
+	assert.True(t, errors.Is(err, migrations.ErrDatabaseNewer), "Up error = %v", err)
+	_, err = older.Check(testCtx)
+	assert.True(t, errors.Is(err, migrations.ErrDatabaseNewer), "Check error = %v", err)
+
+	reverted, err := migrations.NewPostgreSQL(pool, withNotes).Down(testCtx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	var exists bool
+	require.NoError(t, pool.QueryRow(testCtx, "SELECT to_regclass('notes') IS NOT NULL").Scan(&exists))
+	assert.False(t, exists)
+
+	_, err = older.Check(testCtx)
+	assert.NoError(t, err)
+}
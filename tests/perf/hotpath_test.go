@@ -157,9 +157,10 @@ type benchUsageLogger struct {
 	cfg usage.Config
 }
 
-func (l benchUsageLogger) Write(_ *usage.UsageEntry) {}
-func (l benchUsageLogger) Config() usage.Config      { return l.cfg }
-func (l benchUsageLogger) Close() error              { return nil }
+func (l benchUsageLogger) Write(_ *usage.UsageEntry)   {}
+func (l benchUsageLogger) Config() usage.Config        { return l.cfg }
+func (l benchUsageLogger) Flush(context.Context) error { return nil }
+func (l benchUsageLogger) Close() error                { return nil }
 
 func TestMain(m *testing.M) {
 	original := slog.Default()
@@ -298,19 +299,19 @@ func TestHotPathPerfGuard(t *testing.T) {
 			name:      "gateway_chat_completion_hot_path",
 			bench:     BenchmarkGatewayHotPathChatCompletion,
 			maxAllocs: 125,
-			maxBytes:  15 * 1024,
+			maxBytes:  16 * 1024,
 		},
 		{
 			name:      "openai_responses_stream_converter",
 			bench:     BenchmarkOpenAIResponsesStreamConverter,
-			maxAllocs: 310,
-			maxBytes:  25 * 1024,
+			maxAllocs: 360,
+			maxBytes:  32 * 1024,
 		},
 		{
 			name:      "shared_stream_audit_and_usage_observers",
 			bench:     BenchmarkSharedStreamingAuditAndUsageObservers,
 			maxAllocs: 170,
-			maxBytes:  9 * 1024,
+			maxBytes:  10 * 1024,
 		},
 	}
 